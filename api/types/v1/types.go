@@ -1,10 +1,12 @@
-// Package types defines shared API types for signaling servers and UI.
+// Package types defines shared API types for signaling servers, the UI
+// and the Go client (pkg/client).
 package types
 
 // HealthResponse is the response from /api/v1/health
 type HealthResponse struct {
-	Status string `json:"status"`
-	Uptime int64  `json:"uptime"`
+	Status  string `json:"status"`
+	Uptime  int64  `json:"uptime"`
+	Version string `json:"version,omitempty"`
 }
 
 // StatsResponse is the response from /api/v1/stats
@@ -27,9 +29,13 @@ type Registration struct {
 	Expires      int      `json:"expires"`
 	ExpiresAt    string   `json:"expires_at"`
 	RegisteredAt string   `json:"registered_at"`
+	CreatedAt    string   `json:"created_at"`
 	QValue       float32  `json:"q,omitempty"`
 	UserAgent    string   `json:"user_agent,omitempty"`
 	InstanceID   string   `json:"instance_id,omitempty"`
+	RegID        int      `json:"reg_id,omitempty"`
+	Outbound     bool     `json:"outbound,omitempty"`
+	FlowToken    string   `json:"flow_token,omitempty"`
 	Path         []string `json:"path,omitempty"`
 }
 
@@ -47,6 +53,146 @@ type Dialog struct {
 	TerminateReason string `json:"terminate_reason,omitempty"`
 }
 
+// Call is one logical call: its inbound leg, the legs dialed for it and
+// their media sessions
+type Call struct {
+	CallID    string    `json:"call_id"`
+	State     string    `json:"state"` // ringing, answered, bridged or terminating; failed or ended for test calls
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	StartedAt string    `json:"started_at"`
+	Duration  int       `json:"duration"`
+	ALeg      *CallLeg  `json:"a_leg,omitempty"`
+	BLegs     []CallLeg `json:"b_legs,omitempty"`
+
+	// Progress of a test call placed with POST /api/v1/calls
+	Progress *CallProgress `json:"progress,omitempty"`
+}
+
+// CallProgress is the progress of a test call, also posted to its
+// callback URL on every change
+type CallProgress struct {
+	CallID    string `json:"call_id"`
+	Target    string `json:"target"`
+	State     string `json:"state"` // trying, ringing, early_media, answered, retrying, failed or ended
+	SIPCode   int    `json:"sip_code,omitempty"`
+	SIPReason string `json:"sip_reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+	StartedAt string `json:"started_at"`
+	UpdatedAt string `json:"updated_at"`
+
+	// Attempts are the dials of the call, the current one last
+	Attempts []CallAttempt `json:"attempts"`
+}
+
+// CallAttempt is one dial of a test call
+type CallAttempt struct {
+	CallID    string `json:"call_id"`
+	Target    string `json:"target"`
+	SIPCode   int    `json:"sip_code,omitempty"`
+	SIPReason string `json:"sip_reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// CallLeg is one SIP leg of a call
+type CallLeg struct {
+	CallID     string `json:"call_id"`
+	Direction  string `json:"direction"`
+	State      string `json:"state"`
+	RemoteURI  string `json:"remote_uri,omitempty"`
+	SessionID  string `json:"session_id,omitempty"`
+	RTPManager string `json:"rtp_manager,omitempty"`
+	RemoteRTP  string `json:"remote_rtp,omitempty"`
+	Codec      string `json:"codec,omitempty"`
+	SIPCode    int    `json:"sip_code,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	AnsweredAt string `json:"answered_at,omitempty"`
+}
+
+// Bridge is an active B2BUA bridge between two answered legs
+type Bridge struct {
+	ID            string `json:"id"`
+	State         string `json:"state"`
+	LegAID        string `json:"leg_a_id"`
+	LegBID        string `json:"leg_b_id"`
+	LegACallID    string `json:"leg_a_call_id,omitempty"`
+	LegBCallID    string `json:"leg_b_call_id,omitempty"`
+	Codec         string `json:"codec,omitempty"`
+	MediaBridgeID string `json:"media_bridge_id,omitempty"`
+	SessionAID    string `json:"session_a_id,omitempty"`
+	SessionBID    string `json:"session_b_id,omitempty"`
+	RTPManager    string `json:"rtp_manager,omitempty"`
+	CreatedAt     string `json:"created_at"`
+	StartedAt     string `json:"started_at,omitempty"`
+	Duration      int    `json:"duration"`
+	PacketsAToB   int64  `json:"packets_a_to_b"`
+	PacketsBToA   int64  `json:"packets_b_to_a"`
+	BytesAToB     int64  `json:"bytes_a_to_b"`
+	BytesBToA     int64  `json:"bytes_b_to_a"`
+}
+
+// KPIs are the call KPIs of a route over a period
+type KPIs struct {
+	Attempts  int     `json:"attempts"`
+	Answered  int     `json:"answered"`
+	Effective int     `json:"effective"` // Answered, busy, declined or not answered
+	ASR       float64 `json:"asr"`       // Answer-seizure ratio, percent
+	NER       float64 `json:"ner"`       // Network effectiveness ratio, percent
+	ACD       float64 `json:"acd"`       // Average call duration, seconds
+	PDD       float64 `json:"pdd_ms"`    // Average post-dial delay, milliseconds
+}
+
+// KPIBucket holds the KPIs of the attempts started in one period
+type KPIBucket struct {
+	Start string `json:"start"`
+	KPIs
+}
+
+// RouteKPIs holds a route's KPIs over the whole report and per bucket
+type RouteKPIs struct {
+	Route   string      `json:"route"`
+	Total   KPIs        `json:"total"`
+	Buckets []KPIBucket `json:"buckets"`
+}
+
+// KPIReport holds the KPIs of every route with attempts in a period
+type KPIReport struct {
+	Since  string      `json:"since"`
+	Until  string      `json:"until"`
+	Bucket string      `json:"bucket"`
+	Routes []RouteKPIs `json:"routes"`
+}
+
+// RouteWindow counts the outcomes of a route over the recent window
+type RouteWindow struct {
+	Attempts    int64   `json:"attempts"`
+	Failed      int64   `json:"failed"`
+	FailureRate float64 `json:"failure_rate"` // Percent of attempts, cancels excluded
+}
+
+// RouteStats are the call outcomes of a dialplan rule or trunk since the
+// start or the last reset
+type RouteStats struct {
+	Kind            string           `json:"kind"` // "rule" or "trunk"
+	Name            string           `json:"name"` // Route ID or trunk host
+	Attempts        int64            `json:"attempts"`
+	Succeeded       int64            `json:"succeeded"`
+	Failed          int64            `json:"failed"`
+	Canceled        int64            `json:"canceled"`
+	FailureRate     float64          `json:"failure_rate"`
+	FailureCodes    map[string]int64 `json:"failure_codes,omitempty"` // SIP code -> count
+	Recent          RouteWindow      `json:"recent"`
+	LastSuccess     string           `json:"last_success"`
+	LastFailure     string           `json:"last_failure"`
+	LastFailureCode int              `json:"last_failure_code,omitempty"`
+}
+
+// RouteStatsList lists the routing statistics
+type RouteStatsList struct {
+	Window string       `json:"window"`
+	Routes []RouteStats `json:"routes"`
+}
+
 // Session represents an RTP session
 type Session struct {
 	CallID     string `json:"call_id"`
@@ -64,7 +210,11 @@ type RtpManager struct {
 	Address      string `json:"address"`
 	Healthy      bool   `json:"healthy"`
 	DrainState   string `json:"drain_state"`
+	Circuit      string `json:"circuit,omitempty"` // "closed", "open" or "half-open"
 	SessionCount int    `json:"session_count"`
+	PortsTotal   int    `json:"ports_total,omitempty"`
+	PortsUsed    int    `json:"ports_used,omitempty"`
+	Version      string `json:"version,omitempty"`
 }
 
 // RtpManagersResponse is the response from /api/v1/rtpmanagers
@@ -74,3 +224,188 @@ type RtpManagersResponse struct {
 	ActiveSessions int          `json:"active_sessions"`
 	Members        []RtpManager `json:"members"`
 }
+
+// ScreeningEntry is a caller blocklist entry
+type ScreeningEntry struct {
+	Match   string `json:"match"` // "exact", "prefix" or "regex"
+	Pattern string `json:"pattern"`
+	Comment string `json:"comment,omitempty"`
+}
+
+// ScreeningList is a caller blocklist from /api/v1/screening/lists
+type ScreeningList struct {
+	Name         string           `json:"name"`
+	Action       string           `json:"action"` // "reject", "drop" or "announcement"
+	Announcement string           `json:"announcement,omitempty"`
+	Entries      []ScreeningEntry `json:"entries"`
+}
+
+// UserFeatures are a user's call features from /api/v1/users
+type UserFeatures struct {
+	User            string `json:"user"`
+	RejectAnonymous bool   `json:"reject_anonymous,omitempty"`
+	AnonymousAction string `json:"anonymous_action,omitempty"` // "reject" or "voicemail"
+	Voicemail       string `json:"voicemail,omitempty"`
+	DND             bool   `json:"dnd,omitempty"`
+	ForwardAlways   string `json:"forward_always,omitempty"`
+	ForwardBusy     string `json:"forward_busy,omitempty"`
+	ForwardNoAnswer string `json:"forward_no_answer,omitempty"`
+	NoAnswerTimeout int    `json:"no_answer_timeout,omitempty"`
+	FollowMeMode    string `json:"follow_me_mode,omitempty"`   // "simultaneous" or "sequential"
+	ClassOfService  string `json:"class_of_service,omitempty"` // "internal", "national" or "international"
+}
+
+// LogLevels is the log configuration from /api/v1/logging
+type LogLevels struct {
+	Level   string            `json:"level"`             // Global level
+	Modules map[string]string `json:"modules,omitempty"` // Overrides by message prefix, e.g. "dialog": "debug"
+}
+
+// DrainStatus is the status of an RTP manager drain from
+// /api/v1/rtpmanagers/{node}/drain
+type DrainStatus struct {
+	NodeID          string         `json:"node_id"`
+	State           string         `json:"state"`
+	Mode            string         `json:"mode"`
+	TotalSessions   int            `json:"total_sessions"`
+	WaitingPlayback int            `json:"waiting_playback"`
+	MigratedCount   int            `json:"migrated_count"`
+	FailedCount     int            `json:"failed_count"`
+	StartedAt       string         `json:"started_at,omitempty"`
+	ElapsedSeconds  int            `json:"elapsed_seconds,omitempty"`
+	Errors          []DrainError   `json:"errors,omitempty"`
+	Sessions        []DrainSession `json:"sessions,omitempty"`
+}
+
+// DrainError is a session that failed to migrate during a drain
+type DrainError struct {
+	SessionID string `json:"session_id"`
+	Error     string `json:"error"`
+	Timestamp string `json:"timestamp"`
+}
+
+// DrainSession is the migration state of one session of a drain: "pending",
+// "migrating", "migrated", "failed" or "skipped" (a B-leg moved with its
+// A-leg)
+type DrainSession struct {
+	SessionID string `json:"session_id"`
+	State     string `json:"state"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DrainEvent is a step of a drain from
+// /api/v1/rtpmanagers/{node}/drain/events, with the drain's status after it
+type DrainEvent struct {
+	Type       string      `json:"type"` // "status", "started", "session_migrating", "session_migrated", "session_failed", "completed", "incomplete", "failed" or "canceled"
+	NodeID     string      `json:"node_id"`
+	SessionID  string      `json:"session_id,omitempty"`
+	TargetNode string      `json:"target_node,omitempty"`
+	Error      string      `json:"error,omitempty"`
+	Timestamp  string      `json:"timestamp"`
+	Status     DrainStatus `json:"status"`
+}
+
+// OriginateRequest is the body of POST /api/v1/calls, which places a test
+// call that plays a tone or file once answered and then hangs up
+type OriginateRequest struct {
+	Target   string `json:"target"`
+	CallerID string `json:"caller_id,omitempty"`
+	Timeout  int    `json:"timeout,omitempty"`  // Ring time in seconds (default 30)
+	Duration int    `json:"duration,omitempty"` // Seconds the answered call is held (default 10)
+	Tone     string `json:"tone,omitempty"`     // Tone name or spec (default "1004")
+	File     string `json:"file,omitempty"`     // Audio file played instead of a tone
+
+	CallbackURL string `json:"callback_url,omitempty"` // Receives the call's CallProgress on every change
+	Async       bool   `json:"async,omitempty"`        // Return the CallProgress at once instead of waiting for the answer
+
+	Retry *OriginateRetry `json:"retry,omitempty"` // Redial on some failures
+}
+
+// OriginateRetry redials a test call that fails with some codes
+type OriginateRetry struct {
+	Codes    []int    `json:"codes"`             // Failure codes redialed, e.g. 480, 503; 408 when the ring time runs out
+	Attempts int      `json:"attempts"`          // Redials after the first attempt, at most 10
+	Backoff  int      `json:"backoff,omitempty"` // Seconds before the first redial, doubled after each (default 5)
+	Targets  []string `json:"targets,omitempty"` // Alternate targets dialed by the redials in turn
+}
+
+// OriginateResponse is the response from POST /api/v1/calls
+type OriginateResponse struct {
+	CallID   string `json:"call_id"`
+	Target   string `json:"target"`
+	Duration int    `json:"duration"`
+}
+
+// Event is a call event from the /api/v1/events stream. The fields
+// specific to each event type are only in Raw.
+type Event struct {
+	EventID   string `json:"event_id"`
+	EventType string `json:"event_type"`
+	EventTime string `json:"event_time"`
+	CallUUID  string `json:"call_uuid"`
+	SIPCallID string `json:"sip_call_id"`
+	NodeID    string `json:"node_id,omitempty"`
+
+	Raw []byte `json:"-"` // The event as received
+}
+
+// Fleet states
+const (
+	FleetOK       = "ok"       // Every backend and RTP manager is healthy
+	FleetDegraded = "degraded" // Something is unhealthy or unreachable
+	FleetDown     = "down"     // No backend answers
+)
+
+// FleetResponse is the response from the UI server's /api/v1/fleet:
+// the health of every configured backend and of the RTP managers each
+// one reports
+type FleetResponse struct {
+	Status      string            `json:"status"` // FleetOK, FleetDegraded or FleetDown
+	GeneratedAt string            `json:"generated_at"`
+	UI          FleetUI           `json:"ui"`
+	Totals      FleetTotals       `json:"totals"`
+	Backends    []FleetBackend    `json:"backends"`
+	RtpManagers []FleetRtpManager `json:"rtp_managers"`
+}
+
+// FleetUI describes the UI server answering the fleet request
+type FleetUI struct {
+	Version string `json:"version"`
+	Uptime  int64  `json:"uptime"`
+}
+
+// FleetTotals sums the fleet
+type FleetTotals struct {
+	Backends           int `json:"backends"`
+	BackendsUp         int `json:"backends_up"`
+	RtpManagers        int `json:"rtp_managers"`
+	RtpManagersHealthy int `json:"rtp_managers_healthy"`
+	ActiveDialogs      int `json:"active_dialogs"`
+	ActiveSessions     int `json:"active_sessions"`
+}
+
+// FleetBackend is one signaling server
+type FleetBackend struct {
+	Name           string `json:"name"`
+	Address        string `json:"address"`
+	Status         string `json:"status"` // Backend health status, or "offline"
+	Version        string `json:"version,omitempty"`
+	Uptime         int64  `json:"uptime,omitempty"`
+	ActiveDialogs  int    `json:"active_dialogs"`
+	ActiveSessions int    `json:"active_sessions"`
+	Error          string `json:"error,omitempty"`
+}
+
+// FleetRtpManager is an RTP manager as seen by one backend
+type FleetRtpManager struct {
+	Backend string `json:"backend"`
+	RtpManager
+}
+
+// ConfigResponse is a signaling server's effective configuration, secrets
+// redacted
+type ConfigResponse struct {
+	Version  string            `json:"version"`
+	Settings map[string]string `json:"settings"`
+	Files    map[string]string `json:"files"` // SHA-256 of each configuration file by path setting
+}
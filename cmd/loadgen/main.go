@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/sebas/switchboard/internal/banner"
+	"github.com/sebas/switchboard/internal/loadgen"
+	"github.com/sebas/switchboard/internal/loadgen/config"
+)
+
+func main() {
+	cfg := config.Load()
+
+	// Logs go to stderr so the report on stdout can be piped
+	logLevel := slog.LevelInfo
+	switch cfg.LogLevel {
+	case "debug":
+		logLevel = slog.LevelDebug
+	case "warn":
+		logLevel = slog.LevelWarn
+	case "error":
+		logLevel = slog.LevelError
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: logLevel})))
+
+	if !cfg.JSON {
+		banner.Print("LOAD GENERATOR", []banner.ConfigLine{
+			{Label: "Target", Value: fmt.Sprintf("%s (%s)", cfg.Target, cfg.Destination)},
+			{Label: "Rate", Value: rateLabel(cfg)},
+			{Label: "Duration", Value: durationLabel(cfg)},
+			{Label: "Hold Time", Value: cfg.HoldTime.String()},
+			{Label: "Media", Value: cfg.Media},
+		})
+	}
+
+	gen, err := loadgen.New(cfg)
+	if err != nil {
+		slog.Error("Failed to create load generator", "error", err)
+		os.Exit(1)
+	}
+	defer func() { _ = gen.Close() }()
+
+	// The first signal stops the run and hangs up the calls in progress
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	report := gen.Run(ctx)
+
+	if cfg.JSON {
+		if err := report.WriteJSON(os.Stdout); err != nil {
+			slog.Error("Failed to write report", "error", err)
+			os.Exit(1)
+		}
+		return
+	}
+	fmt.Println()
+	report.WriteText(os.Stdout)
+}
+
+func rateLabel(cfg *config.Config) string {
+	label := fmt.Sprintf("%g calls/s", cfg.Rate)
+	if cfg.RampStep > 0 {
+		label += fmt.Sprintf(", +%g every %s", cfg.RampStep, cfg.RampInterval)
+		if cfg.MaxRate > 0 {
+			label += fmt.Sprintf(" up to %g", cfg.MaxRate)
+		}
+	}
+	if cfg.Concurrency > 0 {
+		label += fmt.Sprintf(", max %d concurrent", cfg.Concurrency)
+	}
+	return label
+}
+
+func durationLabel(cfg *config.Config) string {
+	if cfg.Calls > 0 {
+		return fmt.Sprintf("%d calls or %s", cfg.Calls, cfg.Duration)
+	}
+	return cfg.Duration.String()
+}
@@ -0,0 +1,95 @@
+package main
+
+import (
+	"encoding/json"
+	"net"
+	"net/http"
+	"time"
+)
+
+// drainPollInterval is how often POST /drain?wait= checks for remaining sessions
+const drainPollInterval = time.Second
+
+// drainResponse is the body of the /drain endpoint
+type drainResponse struct {
+	DrainRequested bool `json:"drain_requested"`
+	Sessions       int  `json:"sessions"`
+}
+
+// handleDrain lets the node request its own drain, typically from a
+// Kubernetes pre-stop hook:
+//
+//   - POST /drain flags the node as draining; signaling servers pick the
+//     flag up with their next health check and move their sessions off
+//     it. With ?wait=<duration> the call returns once no session is left
+//     or the wait is over, whichever comes first.
+//   - GET /drain reports the flag and the sessions still on the node.
+//
+// Only loopback callers are served, as the health port is often exposed.
+func handleDrain(srv rtpServer) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if !fromLoopback(r) {
+			http.Error(w, "forbidden", http.StatusForbidden)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPost:
+			var wait time.Duration
+			if v := r.URL.Query().Get("wait"); v != "" {
+				d, err := time.ParseDuration(v)
+				if err != nil || d < 0 {
+					http.Error(w, "invalid wait duration", http.StatusBadRequest)
+					return
+				}
+				wait = d
+			}
+			srv.RequestDrain()
+			waitDrained(r, srv, wait)
+		default:
+			w.Header().Set("Allow", "GET, POST")
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		requested, sessions := srv.DrainStatus()
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(drainResponse{DrainRequested: requested, Sessions: sessions})
+	}
+}
+
+// waitDrained blocks until srv holds no sessions, wait has passed or the
+// caller went away.
+func waitDrained(r *http.Request, srv rtpServer, wait time.Duration) {
+	if wait <= 0 {
+		return
+	}
+	deadline := time.NewTimer(wait)
+	defer deadline.Stop()
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+
+	for {
+		if _, sessions := srv.DrainStatus(); sessions == 0 {
+			return
+		}
+		select {
+		case <-r.Context().Done():
+			return
+		case <-deadline.C:
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// fromLoopback reports whether the request came from the node itself
+func fromLoopback(r *http.Request) bool {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return false
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
@@ -2,22 +2,37 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
+	"sync/atomic"
 	"syscall"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 	"google.golang.org/grpc/peer"
 
 	"github.com/sebas/switchboard/internal/banner"
+	"github.com/sebas/switchboard/internal/certs"
+	"github.com/sebas/switchboard/internal/debug"
+	"github.com/sebas/switchboard/internal/health"
 	"github.com/sebas/switchboard/internal/logger"
+	"github.com/sebas/switchboard/internal/preflight"
+	"github.com/sebas/switchboard/internal/rtpmanager/audiocache"
 	"github.com/sebas/switchboard/internal/rtpmanager/config"
 	"github.com/sebas/switchboard/internal/rtpmanager/server"
+	"github.com/sebas/switchboard/internal/rtpmanager/simulator"
+	"github.com/sebas/switchboard/internal/s3"
+	"github.com/sebas/switchboard/internal/secrets"
+	"github.com/sebas/switchboard/internal/version"
 	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
 )
 
@@ -28,34 +43,91 @@ func main() {
 	// Print startup banner
 	banner.Print("RTP MANAGER", []banner.ConfigLine{
 		{Label: "gRPC Listen", Value: fmt.Sprintf("%s:%d", cfg.GRPCBindAddr, cfg.GRPCPort)},
+		{Label: "gRPC TLS", Value: tlsLabel(cfg)},
+		{Label: "Health Probes", Value: healthLabel(cfg)},
 		{Label: "Advertise", Value: cfg.AdvertiseAddr},
-		{Label: "RTP Range", Value: fmt.Sprintf("%d-%d", cfg.RTPPortMin, cfg.RTPPortMax)},
+		{Label: "Advertise IPv6", Value: advertise6Label(cfg)},
+		{Label: "Advertise Rules", Value: advertiseRulesLabel(cfg)},
+		{Label: "RTP Range", Value: rtpRangeLabel(cfg)},
 		{Label: "Audio Path", Value: cfg.AudioBasePath},
+		{Label: "Audio Cache", Value: fmt.Sprintf("%s (%d MB)", cfg.AudioCacheDir, cfg.AudioCacheSizeMB)},
+		{Label: "Jitter Buffer", Value: jitterBufferLabel(cfg)},
+		{Label: "RTP Timeout", Value: cfg.RTPTimeout.String()},
+		{Label: "Orphan Grace", Value: cfg.OrphanGrace.String()},
+		{Label: "Tone Plan", Value: cfg.TonePlan},
+		{Label: "Media", Value: mediaLabel(cfg)},
 		{Label: "Log Level", Value: cfg.LogLevel},
 	})
 
 	// Initialize logger
-	logger.InitLogger(os.Stdout)
+	logFile, err := logger.InitLoggerWithOptions(logger.Options{
+		Level:       cfg.LogLevel,
+		Format:      cfg.LogFormat,
+		File:        cfg.LogFile,
+		MaxSizeMB:   cfg.LogMaxSizeMB,
+		RotateEvery: cfg.LogRotateEvery,
+		MaxBackups:  cfg.LogMaxBackups,
+		Modules:     cfg.LogModules,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log settings: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = logFile.Close() }()
 
-	// Create RTP Manager server
-	srvCfg := &server.Config{
-		GRPCPort:      cfg.GRPCPort,
-		GRPCBindAddr:  cfg.GRPCBindAddr,
-		AdvertiseAddr: cfg.AdvertiseAddr,
-		RTPPortMin:    cfg.RTPPortMin,
-		RTPPortMax:    cfg.RTPPortMax,
-		AudioBasePath: cfg.AudioBasePath,
+	// Validate ports, paths and addresses before serving anything
+	if !cfg.SkipPreflight {
+		if err := preflight.Run(context.Background(), preflightChecks(cfg)); err != nil {
+			slog.Error("Preflight failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
+	// Resolve settings held in a secret store and keep them current
+	secretsCtx, stopSecrets := context.WithCancel(context.Background())
+	defer stopSecrets()
+	debugToken, resolver, err := loadSecrets(secretsCtx, cfg)
+	if err != nil {
+		slog.Error("Failed to load secrets", "error", err)
+		os.Exit(1)
 	}
+	defer func() { _ = resolver.Close() }()
 
-	rtpSrv, err := server.NewServer(srvCfg)
+	// Create RTP Manager server
+	rtpSrv, err := newRTPServer(cfg)
 	if err != nil {
 		slog.Error("Failed to create RTP Manager server", "error", err)
 		os.Exit(1)
 	}
 	defer func() { _ = rtpSrv.Close() }()
 
+	// Push metrics to a StatsD agent
+	metricsCtx, stopMetrics := context.WithCancel(context.Background())
+	defer stopMetrics()
+	metrics, err := startMetrics(metricsCtx, cfg, rtpSrv)
+	if err != nil {
+		slog.Error("Failed to start metrics exporter", "error", err)
+		os.Exit(1)
+	}
+	if metrics != nil {
+		defer func() {
+			stopMetrics()
+			_ = metrics.Close()
+		}()
+	}
+
+	// Serve gRPC over TLS, reloading the certificate when it is renewed
+	certCtx, stopCerts := context.WithCancel(context.Background())
+	defer stopCerts()
+	creds, err := grpcCredentials(certCtx, cfg)
+	if err != nil {
+		slog.Error("Invalid TLS settings", "error", err)
+		os.Exit(1)
+	}
+
 	// Create gRPC server with logging interceptors and keepalive settings
 	grpcServer := grpc.NewServer(
+		grpc.Creds(creds),
 		grpc.KeepaliveParams(keepalive.ServerParameters{
 			Time:    30 * time.Second, // Ping client if idle for 30s
 			Timeout: 10 * time.Second, // Wait 10s for ping ack
@@ -80,30 +152,236 @@ func main() {
 	slog.Info("gRPC server listening", "address", listenAddr)
 
 	// Start server in background
+	var serving atomic.Bool
+	serving.Store(true)
 	go func() {
+		defer serving.Store(false)
 		if err := grpcServer.Serve(listener); err != nil {
 			slog.Error("gRPC server error", "error", err)
 		}
 	}()
 
+	// Liveness and readiness probes
+	var healthServer *http.Server
+	if cfg.HealthPort > 0 {
+		checker := health.NewChecker()
+		checker.Add("grpc", func(ctx context.Context) error {
+			if !serving.Load() {
+				return errors.New("gRPC server not serving")
+			}
+			return nil
+		})
+		checker.Add("ports", rtpSrv.Ready)
+		checker.Add("drain", func(ctx context.Context) error {
+			if requested, _ := rtpSrv.DrainStatus(); requested {
+				return errors.New("drain requested")
+			}
+			return nil
+		})
+
+		mux := http.NewServeMux()
+		checker.Register(mux)
+		mux.HandleFunc("/drain", handleDrain(rtpSrv))
+		if debug.RegisterFunc(mux, debugToken.Value) {
+			slog.Info("Debug endpoints enabled", "path", "/debug/")
+		}
+		healthServer = &http.Server{
+			Addr:              fmt.Sprintf("%s:%d", cfg.GRPCBindAddr, cfg.HealthPort),
+			Handler:           mux,
+			ReadHeaderTimeout: 5 * time.Second,
+		}
+		go func() {
+			slog.Info("Health server listening", "address", healthServer.Addr)
+			if err := healthServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+				slog.Error("Health server error", "error", err)
+			}
+		}()
+	}
+
+	if healthServer == nil && cfg.DebugToken != "" {
+		slog.Warn("Debug endpoints need the health port, not served", "health_port", cfg.HealthPort)
+	}
+
 	// Wait for signal
 	sigChan := make(chan os.Signal, 1)
 	signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 	sig := <-sigChan
 	slog.Info("Received signal, shutting down", "signal", sig)
 
-	// Graceful shutdown
+	// Graceful shutdown; stop reporting ready before draining gRPC
+	serving.Store(false)
 	grpcServer.GracefulStop()
+	if healthServer != nil {
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		_ = healthServer.Shutdown(shutdownCtx)
+		cancel()
+	}
 	slog.Info("RTP Manager stopped")
 }
 
-// loggingUnaryInterceptor logs incoming unary RPC calls with peer info
+// rtpServer is the RTP Manager gRPC service, real or simulated
+type rtpServer interface {
+	rtpv1.RTPManagerServiceServer
+	Ready(ctx context.Context) error
+	RequestDrain()
+	DrainStatus() (requested bool, sessions int)
+	Gauges() map[string]float64
+	Close() error
+}
+
+// newRTPServer creates the RTP Manager server, or the simulator with -simulate
+func newRTPServer(cfg *config.Config) (rtpServer, error) {
+	if cfg.Simulate {
+		return simulator.NewServer(simulator.Config{
+			AdvertiseAddr:  cfg.AdvertiseAddr,
+			AdvertiseRules: cfg.AdvertiseRules,
+			AdvertiseAddr6: cfg.AdvertiseAddr6,
+			RTPPortMin:     cfg.RTPPortMin,
+			RTPPortMax:     cfg.RTPPortMax,
+			RTPPorts:       cfg.RTPPorts,
+			TonePlan:       cfg.TonePlan,
+		})
+	}
+
+	return server.NewServer(&server.Config{
+		GRPCPort:      cfg.GRPCPort,
+		GRPCBindAddr:  cfg.GRPCBindAddr,
+		AdvertiseAddr: cfg.AdvertiseAddr,
+		RTPPortMin:    cfg.RTPPortMin,
+		RTPPortMax:    cfg.RTPPortMax,
+		RTPPorts:      cfg.RTPPorts,
+		AudioBasePath: cfg.AudioBasePath,
+
+		AdvertiseRules: cfg.AdvertiseRules,
+		AdvertiseAddr6: cfg.AdvertiseAddr6,
+
+		JitterBufferEnabled: cfg.JitterBufferEnabled,
+		JitterMinDelay:      cfg.JitterMinDelay,
+		JitterMaxDelay:      cfg.JitterMaxDelay,
+
+		RTPWorkers: cfg.RTPWorkers,
+		RTPPinCPUs: cfg.RTPPinCPUs,
+
+		RTPTimeout:  cfg.RTPTimeout,
+		OrphanGrace: cfg.OrphanGrace,
+		JitterAlert: cfg.JitterAlert,
+		TonePlan:    cfg.TonePlan,
+
+		AudioCache: audiocache.Config{
+			Dir:          cfg.AudioCacheDir,
+			MaxBytes:     int64(cfg.AudioCacheSizeMB) << 20,
+			TTL:          cfg.AudioCacheTTL,
+			FetchTimeout: 30 * time.Second,
+			S3:           s3.ConfigFromEnv(),
+		},
+	})
+}
+
+// loadSecrets resolves the debug token and replaces TLS file settings
+// that are secret store references with the paths of the files they are
+// written to. The secrets are refreshed until ctx is done.
+func loadSecrets(ctx context.Context, cfg *config.Config) (*secrets.Secret, *secrets.Resolver, error) {
+	resolver := secrets.New(secrets.ConfigFromEnv(), nil)
+	loadCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	token, err := resolver.Load(loadCtx, "debug-token", cfg.DebugToken)
+	if err != nil {
+		return nil, nil, err
+	}
+	files, err := resolver.Files(loadCtx, cfg.SecretsDir, map[string]*string{
+		"tls-cert":      &cfg.TLSCertFile,
+		"tls-key":       &cfg.TLSKeyFile,
+		"tls-client-ca": &cfg.TLSClientCA,
+	})
+	if err != nil {
+		_ = resolver.Close()
+		return nil, nil, err
+	}
+	go secrets.Watch(ctx, cfg.SecretsRefresh, append(files, token)...)
+	return token, resolver, nil
+}
+
+// grpcCredentials returns the gRPC server's transport credentials: TLS,
+// mutual with a client CA, when a certificate is configured. The files
+// are watched until ctx is done.
+func grpcCredentials(ctx context.Context, cfg *config.Config) (credentials.TransportCredentials, error) {
+	if cfg.TLSCertFile == "" {
+		if cfg.TLSClientCA != "" {
+			return nil, errors.New("--tls-client-ca needs --tls-cert")
+		}
+		return insecure.NewCredentials(), nil
+	}
+	files, err := certs.LoadFiles(cfg.TLSCertFile, cfg.TLSKeyFile, cfg.TLSClientCA)
+	if err != nil {
+		return nil, err
+	}
+	go files.Watch(ctx, cfg.CertReloadInterval)
+	return credentials.NewTLS(files.ServerConfig()), nil
+}
+
+func tlsLabel(cfg *config.Config) string {
+	switch {
+	case cfg.TLSCertFile == "":
+		return "disabled"
+	case cfg.TLSClientCA != "":
+		return "mutual"
+	}
+	return "server certificate"
+}
+
+func healthLabel(cfg *config.Config) string {
+	if cfg.HealthPort <= 0 {
+		return "disabled"
+	}
+	return fmt.Sprintf("%s:%d (/healthz, /readyz)", cfg.GRPCBindAddr, cfg.HealthPort)
+}
+
+func mediaLabel(cfg *config.Config) string {
+	if cfg.Simulate {
+		return "simulated (no RTP)"
+	}
+	return "RTP"
+}
+
+// jitterBufferLabel formats the jitter buffer setting for the startup banner
+func jitterBufferLabel(cfg *config.Config) string {
+	if !cfg.JitterBufferEnabled {
+		return "disabled"
+	}
+	return fmt.Sprintf("%s-%s", cfg.JitterMinDelay, cfg.JitterMaxDelay)
+}
+
+func rtpRangeLabel(cfg *config.Config) string {
+	if cfg.RTPPorts != "" {
+		return cfg.RTPPorts
+	}
+	return fmt.Sprintf("%d-%d", cfg.RTPPortMin, cfg.RTPPortMax)
+}
+
+func advertise6Label(cfg *config.Config) string {
+	if cfg.AdvertiseAddr6 == "" {
+		return "disabled"
+	}
+	return cfg.AdvertiseAddr6
+}
+
+func advertiseRulesLabel(cfg *config.Config) string {
+	if cfg.AdvertiseRules == "" {
+		return "none"
+	}
+	return cfg.AdvertiseRules
+}
+
+// loggingUnaryInterceptor logs incoming unary RPC calls with peer info and
+// returns the build version in a response header
 func loggingUnaryInterceptor(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
 	peerAddr := "unknown"
 	if p, ok := peer.FromContext(ctx); ok {
 		peerAddr = p.Addr.String()
 	}
 	slog.Debug("[gRPC] Incoming request", "method", info.FullMethod, "peer", peerAddr)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(rtpv1.VersionHeader, version.Get()))
 	return handler(ctx, req)
 }
 
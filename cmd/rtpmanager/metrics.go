@@ -0,0 +1,48 @@
+package main
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"github.com/sebas/switchboard/internal/rtpmanager/config"
+	"github.com/sebas/switchboard/internal/statsd"
+)
+
+// startMetrics pushes the server's gauges to a StatsD agent every
+// interval until ctx is done. Returns nil when no exporter is configured.
+func startMetrics(ctx context.Context, cfg *config.Config, srv rtpServer) (*statsd.Client, error) {
+	if cfg.MetricsExporter == "" {
+		return nil, nil
+	}
+	client, err := statsd.New(statsd.Config{
+		Addr:   cfg.StatsDAddr,
+		Flavor: cfg.MetricsExporter,
+		Prefix: cfg.StatsDPrefix,
+		Tags:   statsd.ParseTags(cfg.StatsDTags),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	interval := cfg.MetricsInterval
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				for name, v := range srv.Gauges() {
+					client.Gauge(name, v)
+				}
+			}
+		}
+	}()
+	slog.Info("Metrics exporter enabled", "exporter", cfg.MetricsExporter, "addr", cfg.StatsDAddr, "interval", interval)
+	return client, nil
+}
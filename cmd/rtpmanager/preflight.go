@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"strconv"
+
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/preflight"
+	"github.com/sebas/switchboard/internal/rtpmanager/config"
+	"github.com/sebas/switchboard/internal/rtpmanager/portpool"
+)
+
+// preflightChecks validates the configuration before any port is opened
+func preflightChecks(cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{
+			Name: "grpc port",
+			Hint: "stop the process using it or choose another port with --grpc-port",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("tcp", net.JoinHostPort(cfg.GRPCBindAddr, strconv.Itoa(cfg.GRPCPort)))
+			},
+		},
+		{
+			Name: "rtp range",
+			Hint: "set --rtp-ports (or --rtp-port-min/--rtp-port-max) to unprivileged ports clear of the gRPC and health ports",
+			Run:  func(ctx context.Context) error { return checkRTPRange(cfg) },
+		},
+		{
+			Name: "advertise",
+			Hint: "set --advertise to an address peers can send RTP to",
+			Run: func(ctx context.Context) error {
+				if _, err := advertise.Parse(cfg.AdvertiseAddr, cfg.AdvertiseRules); err != nil {
+					return err
+				}
+				return preflight.Routable(ctx, cfg.AdvertiseAddr)
+			},
+		},
+	}
+	if cfg.HealthPort > 0 {
+		checks = append(checks, preflight.Check{
+			Name: "health port",
+			Hint: "choose another port with --health-port, or 0 to disable probes",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("tcp", net.JoinHostPort(cfg.GRPCBindAddr, strconv.Itoa(cfg.HealthPort)))
+			},
+		})
+	}
+	if !cfg.Simulate {
+		checks = append(checks, preflight.Check{
+			Name: "audio path",
+			Hint: "create the directory or point --audio-path at the audio files",
+			Run:  func(ctx context.Context) error { return preflight.Dir(cfg.AudioBasePath) },
+		})
+	}
+	return checks
+}
+
+// checkRTPRange checks that the RTP ports hold at least one pair and
+// overlap neither privileged ports nor the service's own TCP ports
+func checkRTPRange(cfg *config.Config) error {
+	ranges := []portpool.Range{{Min: cfg.RTPPortMin, Max: cfg.RTPPortMax}}
+	if cfg.RTPPorts != "" {
+		var err error
+		if ranges, err = portpool.ParseRanges(cfg.RTPPorts); err != nil {
+			return err
+		}
+	}
+	for _, r := range ranges {
+		if r.Min < 1024 || r.Max > 65535 || r.Min >= r.Max {
+			return fmt.Errorf("invalid RTP range %s (want 1024-65535, min below max)", r)
+		}
+		for _, port := range []int{cfg.GRPCPort, cfg.HealthPort} {
+			if port >= r.Min && port <= r.Max {
+				return fmt.Errorf("RTP range %s contains port %d used by this service", r, port)
+			}
+		}
+	}
+	if portpool.NewVirtualPortPool(ranges...).Available() == 0 {
+		return fmt.Errorf("RTP range %s holds no even/odd port pair", rtpRangeLabel(cfg))
+	}
+	return nil
+}
@@ -13,6 +13,7 @@ import (
 
 	"github.com/sebas/switchboard/internal/banner"
 	"github.com/sebas/switchboard/internal/logger"
+	"github.com/sebas/switchboard/internal/preflight"
 	"github.com/sebas/switchboard/internal/signaling/app"
 	"github.com/sebas/switchboard/internal/signaling/config"
 )
@@ -24,14 +25,38 @@ func main() {
 	// Print startup banner
 	banner.Print("SIGNALING SERVER", []banner.ConfigLine{
 		{Label: "Listen", Value: fmt.Sprintf("%s:%d", cfg.BindAddr, cfg.Port)},
+		{Label: "SIP-TLS", Value: tlsLabel(cfg)},
 		{Label: "Advertise", Value: cfg.AdvertiseAddr},
 		{Label: "RTP Manager", Value: strings.Join(cfg.RTPManagerAddrs, ", ")},
 		{Label: "Dialplan", Value: cfg.DialplanPath},
+		{Label: "Media Timeout", Value: mediaTimeoutLabel(cfg)},
+		{Label: "TTS", Value: ttsLabel(cfg)},
 		{Label: "Log Level", Value: cfg.LogLevel},
 	})
 
 	// Initialize logger
-	logger.InitLogger(os.Stdout)
+	logFile, err := logger.InitLoggerWithOptions(logger.Options{
+		Level:       cfg.LogLevel,
+		Format:      cfg.LogFormat,
+		File:        cfg.LogFile,
+		MaxSizeMB:   cfg.LogMaxSizeMB,
+		RotateEvery: cfg.LogRotateEvery,
+		MaxBackups:  cfg.LogMaxBackups,
+		Modules:     cfg.LogModules,
+	})
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid log settings: %v\n", err)
+		os.Exit(1)
+	}
+	defer func() { _ = logFile.Close() }()
+
+	// Validate ports, files and RTP managers before serving anything
+	if !cfg.SkipPreflight {
+		if err := preflight.Run(context.Background(), preflightChecks(cfg)); err != nil {
+			slog.Error("Preflight failed", "error", err)
+			os.Exit(1)
+		}
+	}
 
 	// Create server
 	swboard, err := app.NewServer(cfg)
@@ -50,7 +75,11 @@ func run(proxy *app.SwitchBoard, cfg *config.Config) {
 		"rtpmanagers", cfg.RTPManagerAddrs,
 	)
 
-	slog.Info("API available at http://0.0.0.0:8080")
+	scheme := "http"
+	if cfg.APITLS {
+		scheme = "https"
+	}
+	slog.Info("API available at " + scheme + "://" + app.APIListenAddr)
 	logNetworkInterfaces()
 
 	ctx, cancel := context.WithCancel(context.Background())
@@ -93,3 +122,30 @@ func logNetworkInterfaces() {
 		}
 	}
 }
+
+// mediaTimeoutLabel describes how RTP inactivity reports are handled
+func mediaTimeoutLabel(cfg *config.Config) string {
+	if cfg.MediaTimeoutHangup {
+		return "hangup"
+	}
+	return "log only"
+}
+
+// tlsLabel describes where the SIP-TLS certificate comes from
+func tlsLabel(cfg *config.Config) string {
+	switch {
+	case len(cfg.ACMEDomains) > 0:
+		return fmt.Sprintf("%s:%d (ACME %s)", cfg.BindAddr, cfg.TLSPort, strings.Join(cfg.ACMEDomains, ", "))
+	case cfg.TLSCertFile != "":
+		return fmt.Sprintf("%s:%d (%s)", cfg.BindAddr, cfg.TLSPort, cfg.TLSCertFile)
+	}
+	return "disabled"
+}
+
+// ttsLabel describes the configured text-to-speech provider
+func ttsLabel(cfg *config.Config) string {
+	if cfg.TTSProvider == "" {
+		return "disabled"
+	}
+	return cfg.TTSProvider
+}
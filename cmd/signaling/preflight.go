@@ -0,0 +1,160 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/preflight"
+	"github.com/sebas/switchboard/internal/secrets"
+	"github.com/sebas/switchboard/internal/signaling/app"
+	"github.com/sebas/switchboard/internal/signaling/config"
+)
+
+// preflightChecks validates the configuration before any port is opened
+func preflightChecks(cfg *config.Config) []preflight.Check {
+	checks := []preflight.Check{
+		{
+			Name: "sip port",
+			Hint: "stop the process using it or choose another port with --port",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("udp", net.JoinHostPort(cfg.BindAddr, strconv.Itoa(cfg.Port)))
+			},
+		},
+		{
+			Name: "api port",
+			Hint: "stop the process using port 8080",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("tcp", app.APIListenAddr)
+			},
+		},
+		{
+			Name: "advertise",
+			Hint: "set --advertise to an address SIP peers can reach",
+			Run: func(ctx context.Context) error {
+				if _, err := advertise.Parse(cfg.AdvertiseAddr, cfg.AdvertiseRules); err != nil {
+					return err
+				}
+				return preflight.Routable(ctx, cfg.AdvertiseAddr)
+			},
+		},
+		{
+			Name: "sip timers",
+			Hint: "fix --sip-t1, --sip-t2, --sip-t4, --sip-timer-b, --sip-timer-f or --ack-timeout",
+			Run:  func(ctx context.Context) error { return cfg.Timers.Validate() },
+		},
+		{
+			Name: "dialplan",
+			Hint: "point --dialplan at the dialplan file",
+			Run:  func(ctx context.Context) error { return preflight.File(cfg.DialplanPath) },
+		},
+		{
+			Name: "rtp managers",
+			Hint: "start the RTP managers first or fix --rtpmanager",
+			Run:  func(ctx context.Context) error { return checkRTPManagers(ctx, cfg) },
+		},
+	}
+
+	if cfg.TLS() {
+		checks = append(checks, preflight.Check{
+			Name: "sip-tls port",
+			Hint: "stop the process using it or choose another port with --tls-port",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("tcp", net.JoinHostPort(cfg.BindAddr, strconv.Itoa(cfg.TLSPort)))
+			},
+		})
+	}
+	if len(cfg.ACMEDomains) > 0 {
+		checks = append(checks, preflight.Check{
+			Name: "acme challenge port",
+			Hint: "free port 80 for HTTP-01 challenges or forward it to --acme-http-addr",
+			Run:  func(ctx context.Context) error { return preflight.PortFree("tcp", cfg.ACMEHTTPAddr) },
+		})
+	}
+
+	// Optional config files, checked only when set
+	files := []struct{ name, flag, path string }{
+		{"moh config", "--moh-config", cfg.MOHConfigPath},
+		{"ivr config", "--ivr-config", cfg.IVRConfigPath},
+		{"dialer config", "--dialer-config", cfg.DialerConfigPath},
+		{"agents config", "--agents-config", cfg.AgentsConfigPath},
+		{"screening config", "--screening-config", cfg.ScreeningConfigPath},
+		{"trunks config", "--trunks-config", cfg.TrunksConfigPath},
+		{"credentials config", "--credentials-config", cfg.CredentialsPath},
+		{"features config", "--features-config", cfg.FeaturesConfigPath},
+		{"header policy", "--header-policy", cfg.HeaderPolicyPath},
+		{"alerts config", "--alerts-config", cfg.AlertsConfigPath},
+		{"tls certificate", "--tls-cert", cfg.TLSCertFile},
+		{"tls key", "--tls-key", cfg.TLSKeyFile},
+		{"rtp manager tls certificate", "--rtpmanager-tls-cert", cfg.RTPManagerTLSCert},
+		{"rtp manager tls key", "--rtpmanager-tls-key", cfg.RTPManagerTLSKey},
+		{"rtp manager tls ca", "--rtpmanager-tls-ca", cfg.RTPManagerTLSCA},
+	}
+	resolver := secrets.New(secrets.ConfigFromEnv(), nil)
+	for _, f := range files {
+		if f.path == "" {
+			continue
+		}
+		path := f.path
+		if secrets.IsRef(path) {
+			checks = append(checks, secretCheck(resolver, f.name, f.flag, path))
+			continue
+		}
+		checks = append(checks, preflight.Check{
+			Name: f.name,
+			Hint: "fix " + f.flag + " or leave it empty to disable the feature",
+			Run:  func(ctx context.Context) error { return preflight.File(path) },
+		})
+	}
+	if secrets.IsRef(cfg.DebugToken) {
+		checks = append(checks, secretCheck(resolver, "debug token", "--debug-token", cfg.DebugToken))
+	}
+	return checks
+}
+
+// secretCheck resolves a secret store reference
+func secretCheck(resolver *secrets.Resolver, name, flag, ref string) preflight.Check {
+	return preflight.Check{
+		Name: name,
+		Hint: "fix the reference in " + flag + " or the secret store settings (VAULT_ADDR, VAULT_TOKEN, AWS_*)",
+		Run: func(ctx context.Context) error {
+			_, err := resolver.Resolve(ctx, ref)
+			return err
+		},
+	}
+}
+
+// checkRTPManagers dials every configured RTP manager. Startup needs at
+// least one; the pool reconnects to the others once they come up.
+func checkRTPManagers(ctx context.Context, cfg *config.Config) error {
+	addrs := cfg.RTPManagerAddrs
+	if len(cfg.RTPManagerNodes) > 0 {
+		addrs = make([]string, 0, len(cfg.RTPManagerNodes))
+		for _, addr := range cfg.RTPManagerNodes {
+			addrs = append(addrs, addr)
+		}
+		sort.Strings(addrs)
+	}
+	if len(addrs) == 0 {
+		return errors.New("no RTP manager addresses configured")
+	}
+
+	var unreachable []string
+	for _, addr := range addrs {
+		if err := preflight.Reachable(ctx, addr); err != nil {
+			unreachable = append(unreachable, addr)
+		}
+	}
+	switch {
+	case len(unreachable) == len(addrs):
+		return fmt.Errorf("no RTP manager reachable (%s)", strings.Join(unreachable, ", "))
+	case len(unreachable) > 0:
+		return preflight.Warn(fmt.Errorf("RTP managers unreachable: %s", strings.Join(unreachable, ", ")))
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/spf13/cobra"
+)
+
+func newCallsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "calls",
+		Aliases: []string{"call", "dialogs"},
+		Short:   "List, inspect, hang up and place calls",
+	}
+	cmd.AddCommand(
+		newCallsListCommand(),
+		newCallsShowCommand(),
+		newCallsHangupCommand(),
+		newCallsOriginateCommand(),
+	)
+	return cmd
+}
+
+func newCallsListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List active calls",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			calls, err := newClient().Calls(ctx)
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(calls)
+			}
+			rows := make([][]string, 0, len(calls))
+			for _, call := range calls {
+				rows = append(rows, callRow(call))
+			}
+			return printTable(callHeader, rows)
+		},
+	}
+}
+
+var callHeader = []string{"CALL-ID", "STATE", "FROM", "TO", "B-LEG", "RTP MANAGER", "DURATION"}
+
+func callRow(call types.Call) []string {
+	var bLeg, node string
+	if call.ALeg != nil {
+		node = call.ALeg.RTPManager
+	}
+	if len(call.BLegs) > 0 {
+		bLeg = call.BLegs[0].CallID
+		if len(call.BLegs) > 1 {
+			bLeg += fmt.Sprintf(" (+%d)", len(call.BLegs)-1)
+		}
+		if node == "" {
+			node = call.BLegs[0].RTPManager
+		}
+	}
+	return []string{call.CallID, call.State, orDash(call.From), orDash(call.To), orDash(bLeg), orDash(node), formatSeconds(call.Duration)}
+}
+
+func newCallsShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show CALL-ID",
+		Short: "Show one call",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			d, err := newClient().Dialog(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(d)
+			}
+			return printTable([]string{"FIELD", "VALUE"}, [][]string{
+				{"Call-ID", d.CallID},
+				{"Direction", d.Direction},
+				{"State", d.State},
+				{"Local", d.LocalURI},
+				{"Remote", d.RemoteURI},
+				{"Remote address", fmt.Sprintf("%s:%d", d.RemoteAddr, d.RemotePort)},
+				{"Created", d.CreatedAt},
+				{"Duration", formatSeconds(d.Duration)},
+				{"Terminate reason", orDash(d.TerminateReason)},
+			})
+		},
+	}
+}
+
+func newCallsHangupCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "hangup CALL-ID...",
+		Short: "Hang up answered calls",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c := newClient()
+			var failed bool
+			for _, callID := range args {
+				ctx, cancel := requestContext(cmd)
+				err := c.Hangup(ctx, callID)
+				cancel()
+				if err != nil {
+					fmt.Fprintf(os.Stderr, "%s: %v\n", callID, err)
+					failed = true
+					continue
+				}
+				fmt.Printf("%s: hung up\n", callID)
+			}
+			if failed {
+				return errors.New("some calls were not hung up")
+			}
+			return nil
+		},
+	}
+}
+
+func newCallsOriginateCommand() *cobra.Command {
+	var req types.OriginateRequest
+	var ring, hold time.Duration
+	var retry types.OriginateRetry
+	var retryBackoff time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "originate TARGET",
+		Short: "Place a test call that plays a tone, then hangs up",
+		Long: `Place a test call from the signaling server to TARGET ("1001",
+"user/1001", "gateway/carrier" or a SIP URI). Once answered, the call
+plays a tone or audio file and is hung up after --duration. The command
+returns when the call is answered or the dial fails. With --retries, a
+dial failing with one of --retry-codes is redialed after a backoff,
+to the --retry-target alternates in turn if given.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			req.Target = args[0]
+			req.Timeout = int(ring.Seconds())
+			req.Duration = int(hold.Seconds())
+
+			// The request lasts as long as the target rings, for every
+			// attempt, plus the waits between them
+			wait := ring
+			if retry.Attempts > 0 {
+				retry.Backoff = int(retryBackoff.Seconds())
+				req.Retry = &retry
+				for i, backoff := 0, retryBackoff; i < retry.Attempts; i, backoff = i+1, min(2*backoff, 5*time.Minute) {
+					wait += backoff + ring
+				}
+			}
+			ctx, cancel := context.WithTimeout(cmd.Context(), timeout+wait)
+			defer cancel()
+
+			call, err := newClientWithTimeout(timeout+wait).Originate(ctx, req)
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(call)
+			}
+			fmt.Printf("%s: answered, hanging up in %s\n", call.CallID, formatSeconds(call.Duration))
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&req.CallerID, "caller-id", "", "Caller ID (From user) of the call")
+	cmd.Flags().DurationVar(&ring, "ring", 30*time.Second, "How long to let the target ring")
+	cmd.Flags().DurationVar(&hold, "duration", 10*time.Second, "How long to hold the answered call")
+	cmd.Flags().StringVar(&req.Tone, "tone", "", `Tone to play: plan tone name or spec (default "1004")`)
+	cmd.Flags().StringVar(&req.File, "file", "", "Audio file to play instead of a tone")
+	cmd.Flags().StringVar(&req.CallbackURL, "callback-url", "", "URL to POST the call's progress to as it changes")
+	cmd.Flags().IntVar(&retry.Attempts, "retries", 0, "Redial a failed call up to this many times")
+	cmd.Flags().IntSliceVar(&retry.Codes, "retry-codes", []int{480, 503}, "Failure codes redialed (408 when the ring time runs out)")
+	cmd.Flags().DurationVar(&retryBackoff, "retry-backoff", 5*time.Second, "Wait before the first redial, doubled after each")
+	cmd.Flags().StringSliceVar(&retry.Targets, "retry-target", nil, "Alternate targets dialed by the redials in turn")
+	return cmd
+}
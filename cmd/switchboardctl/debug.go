@@ -0,0 +1,76 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/sebas/switchboard/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+func newDebugCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "debug",
+		Short: "Take runtime diagnostics from a server",
+		Long: `Take runtime diagnostics from a server started with --debug-token.
+The same token must be given with --token (env SWITCHBOARD_DEBUG_TOKEN).
+--server may point at any of the services: signaling, the RTP manager's
+health port or the UI.`,
+	}
+	cmd.AddCommand(newDebugDumpCommand())
+	return cmd
+}
+
+func newDebugDumpCommand() *cobra.Command {
+	token := os.Getenv("SWITCHBOARD_DEBUG_TOKEN")
+	var file string
+
+	cmd := &cobra.Command{
+		Use:   "dump goroutine|heap",
+		Short: "Dump goroutine stacks or a heap profile",
+		Long: `Dump full goroutine stacks (text) or a heap profile (pprof format,
+e.g. for "go tool pprof heap.pprof"). The dump is written to --file, or
+to stdout.`,
+		Args:      cobra.ExactArgs(1),
+		ValidArgs: []string{client.DumpGoroutine, client.DumpHeap},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			kind := args[0]
+			if kind != client.DumpGoroutine && kind != client.DumpHeap {
+				return fmt.Errorf("invalid dump kind %q (use %s or %s)", kind, client.DumpGoroutine, client.DumpHeap)
+			}
+			if token == "" {
+				return fmt.Errorf("--token is required")
+			}
+
+			var w io.Writer = os.Stdout
+			if file != "" {
+				f, err := os.Create(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+
+			// A heap dump starts with a full GC, which can take a while
+			d := max(timeout, 30*time.Second)
+			ctx, cancel := context.WithTimeout(cmd.Context(), d)
+			defer cancel()
+			c := newClientWithTimeout(d)
+			c.SetToken(token)
+			if err := c.Dump(ctx, kind, w); err != nil {
+				return err
+			}
+			if file != "" {
+				fmt.Fprintf(os.Stderr, "Wrote %s dump to %s\n", kind, file)
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&token, "token", token, "Debug token of the server (env SWITCHBOARD_DEBUG_TOKEN)")
+	cmd.Flags().StringVarP(&file, "file", "f", "", "Write the dump to this file instead of stdout")
+	return cmd
+}
@@ -0,0 +1,226 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/sebas/switchboard/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// Drain states reported by the API
+const (
+	drainStateActive   = "active"   // Not draining, or the drain was canceled or failed
+	drainStateDisabled = "disabled" // Fully drained
+)
+
+func newRtpManagersCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "rtpmanagers",
+		Aliases: []string{"rtpmanager", "nodes"},
+		Short:   "Show the RTP manager pool",
+	}
+	cmd.AddCommand(&cobra.Command{
+		Use:     "list",
+		Aliases: []string{"ls"},
+		Short:   "List RTP managers with health, drain state and sessions",
+		Args:    cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			pool, err := newClient().RtpManagers(ctx)
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(pool)
+			}
+			rows := make([][]string, 0, len(pool.Members))
+			for _, m := range pool.Members {
+				health := "healthy"
+				switch {
+				case !m.Healthy:
+					health = "unhealthy"
+				case m.Circuit == "open" || m.Circuit == "half-open":
+					health = "circuit " + m.Circuit
+				}
+				rows = append(rows, []string{m.NodeID, m.Address, health, m.DrainState, fmt.Sprint(m.SessionCount)})
+			}
+			return printTable([]string{"NODE", "ADDRESS", "HEALTH", "STATE", "SESSIONS"}, rows)
+		},
+	})
+	return cmd
+}
+
+func newDrainCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "drain",
+		Short: "Drain RTP managers for maintenance",
+		Long: `Drain an RTP manager: it stops taking new sessions and its sessions
+are migrated to the other nodes. A fully drained node is disabled.`,
+	}
+	cmd.AddCommand(newDrainStartCommand(), newDrainStatusCommand(), newDrainCancelCommand())
+	return cmd
+}
+
+func newDrainStartCommand() *cobra.Command {
+	var mode string
+	var wait bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "start NODE",
+		Short: "Start draining an RTP manager",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if mode != client.DrainGraceful && mode != client.DrainAggressive {
+				return fmt.Errorf("invalid mode %q (use %s or %s)", mode, client.DrainGraceful, client.DrainAggressive)
+			}
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			status, err := newClient().StartDrain(ctx, args[0], mode)
+			if err != nil {
+				return err
+			}
+			if !wait {
+				if output == outputJSON {
+					return printJSON(status)
+				}
+				fmt.Printf("%s: drain started (%s, %d sessions)\n", status.NodeID, status.Mode, status.TotalSessions)
+				return nil
+			}
+			return watchDrain(cmd, args[0], interval)
+		},
+	}
+	cmd.Flags().StringVar(&mode, "mode", client.DrainGraceful, "Drain mode: graceful or aggressive")
+	cmd.Flags().BoolVarP(&wait, "wait", "w", false, "Follow the drain until it ends")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Status poll interval with --wait")
+	return cmd
+}
+
+func newDrainStatusCommand() *cobra.Command {
+	var watch bool
+	var interval time.Duration
+
+	cmd := &cobra.Command{
+		Use:   "status NODE",
+		Short: "Show the drain status of an RTP manager",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if watch {
+				return watchDrain(cmd, args[0], interval)
+			}
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			status, err := newClient().GetDrainStatus(ctx, args[0])
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(status)
+			}
+			return printDrainStatus(status)
+		},
+	}
+	cmd.Flags().BoolVarP(&watch, "watch", "w", false, "Follow the drain until it ends")
+	cmd.Flags().DurationVar(&interval, "interval", 2*time.Second, "Status poll interval with --watch")
+	return cmd
+}
+
+func newDrainCancelCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "cancel NODE",
+		Short: "Cancel a drain; the node takes new sessions again",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			if err := newClient().CancelDrain(ctx, args[0]); err != nil {
+				return err
+			}
+			fmt.Printf("%s: drain canceled\n", args[0])
+			return nil
+		},
+	}
+}
+
+// watchDrain prints drain progress each interval until the node leaves
+// the draining state. A drain that ends with the node active was
+// canceled or failed and is reported as an error.
+func watchDrain(cmd *cobra.Command, nodeID string, interval time.Duration) error {
+	c := newClient()
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		ctx, cancel := requestContext(cmd)
+		status, err := c.GetDrainStatus(ctx, nodeID)
+		cancel()
+		if err != nil {
+			return err
+		}
+
+		if output == outputJSON {
+			if err := printJSON(status); err != nil {
+				return err
+			}
+		} else {
+			fmt.Printf("%s  %-8s  migrated %d/%d  failed %d  waiting for playback %d\n",
+				time.Now().Format("15:04:05"), status.State, status.MigratedCount,
+				status.TotalSessions, status.FailedCount, status.WaitingPlayback)
+		}
+
+		switch status.State {
+		case drainStateDisabled:
+			if output != outputJSON {
+				fmt.Printf("%s: drained in %s\n", nodeID, formatSeconds(status.ElapsedSeconds))
+			}
+			return nil
+		case drainStateActive:
+			for _, e := range status.Errors {
+				fmt.Printf("  %s: %s\n", orDash(e.SessionID), e.Error)
+			}
+			return errors.New("drain did not complete")
+		}
+
+		select {
+		case <-cmd.Context().Done():
+			return nil // Interrupted; the drain goes on
+		case <-ticker.C:
+		}
+	}
+}
+
+func printDrainStatus(status *types.DrainStatus) error {
+	rows := [][]string{
+		{"Node", status.NodeID},
+		{"State", status.State},
+		{"Mode", status.Mode},
+		{"Sessions", fmt.Sprint(status.TotalSessions)},
+		{"Migrated", fmt.Sprint(status.MigratedCount)},
+		{"Failed", fmt.Sprint(status.FailedCount)},
+		{"Waiting for playback", fmt.Sprint(status.WaitingPlayback)},
+		{"Started", orDash(status.StartedAt)},
+	}
+	if status.StartedAt != "" {
+		rows = append(rows, []string{"Elapsed", formatSeconds(status.ElapsedSeconds)})
+	}
+	if err := printTable([]string{"FIELD", "VALUE"}, rows); err != nil {
+		return err
+	}
+	if len(status.Errors) > 0 {
+		fmt.Println()
+		errRows := make([][]string, 0, len(status.Errors))
+		for _, e := range status.Errors {
+			errRows = append(errRows, []string{orDash(e.SessionID), e.Timestamp, e.Error})
+		}
+		return printTable([]string{"SESSION", "TIME", "ERROR"}, errRows)
+	}
+	return nil
+}
@@ -0,0 +1,63 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/spf13/cobra"
+)
+
+func newEventsCommand() *cobra.Command {
+	var eventTypes []string
+	var callID string
+
+	cmd := &cobra.Command{
+		Use:   "events",
+		Short: "Tail call events as they happen",
+		Long: `Print call events (call.ended, call.emergency, ...) as the server
+publishes them, until interrupted. With --output json each event is
+printed as one JSON line, as published.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputJSON {
+				fmt.Fprintf(os.Stderr, "Tailing events from %s (Ctrl-C to stop)\n", serverURL)
+			}
+			err := newClient().Events(cmd.Context(), eventTypes, func(event types.Event) {
+				if callID != "" && event.SIPCallID != callID && event.CallUUID != callID {
+					return
+				}
+				if output == outputJSON {
+					fmt.Println(string(event.Raw))
+					return
+				}
+				fmt.Println(formatEvent(event))
+			})
+			if cmd.Context().Err() != nil {
+				return nil // Interrupted
+			}
+			if err == nil {
+				return fmt.Errorf("server closed the event stream")
+			}
+			return err
+		},
+	}
+	cmd.Flags().StringSliceVarP(&eventTypes, "type", "t", nil, "Only show these event types (repeatable, e.g. call.ended)")
+	cmd.Flags().StringVar(&callID, "call-id", "", "Only show events of this call")
+	return cmd
+}
+
+// formatEvent renders an event as one line: time, type, call and node.
+func formatEvent(event types.Event) string {
+	ts := event.EventTime
+	if t, err := time.Parse(time.RFC3339Nano, event.EventTime); err == nil {
+		ts = t.Local().Format("15:04:05.000")
+	}
+	parts := []string{ts, fmt.Sprintf("%-15s", event.EventType), event.SIPCallID}
+	if event.NodeID != "" {
+		parts = append(parts, "node="+event.NodeID)
+	}
+	return strings.Join(parts, "  ")
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"maps"
+	"slices"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/spf13/cobra"
+)
+
+func newLogCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log",
+		Short: "Show and change log levels of a running server",
+		Long: `Show and change log levels without a restart. A module is the
+[Module] prefix of log messages (dialog, pool, register, ...); b2bua,
+mediaclient, registration, drain and media cover a whole package.`,
+	}
+	cmd.AddCommand(newLogShowCommand(), newLogSetCommand(), newLogResetCommand())
+	return cmd
+}
+
+func newLogShowCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "show",
+		Short: "Show the global log level and module overrides",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			levels, err := newClient().LogLevels(ctx)
+			if err != nil {
+				return err
+			}
+			return printLogLevels(levels)
+		},
+	}
+}
+
+func newLogSetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "set [MODULE] LEVEL",
+		Short: "Set the global log level, or override one module's",
+		Example: `  switchboardctl log set info
+  switchboardctl log set dialog debug`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			var levels *types.LogLevels
+			var err error
+			if len(args) == 2 {
+				levels, err = newClient().SetModuleLogLevel(ctx, args[0], args[1])
+			} else {
+				levels, err = newClient().SetLogLevel(ctx, args[0])
+			}
+			if err != nil {
+				return err
+			}
+			return printLogLevels(levels)
+		},
+	}
+}
+
+func newLogResetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "reset MODULE...",
+		Short: "Remove module overrides, back to the global level",
+		Args:  cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			var levels *types.LogLevels
+			for _, module := range args {
+				var err error
+				if levels, err = newClient().ResetModuleLogLevel(ctx, module); err != nil {
+					return err
+				}
+			}
+			return printLogLevels(levels)
+		},
+	}
+}
+
+func printLogLevels(levels *types.LogLevels) error {
+	if output == outputJSON {
+		return printJSON(levels)
+	}
+	rows := [][]string{{"(global)", levels.Level}}
+	for _, module := range slices.Sorted(maps.Keys(levels.Modules)) {
+		rows = append(rows, []string{module, levels.Modules[module]})
+	}
+	return printTable([]string{"MODULE", "LEVEL"}, rows)
+}
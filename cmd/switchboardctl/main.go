@@ -0,0 +1,124 @@
+// Command switchboardctl is the administrative CLI for switchboard. It
+// wraps the signaling server HTTP API through pkg/client: listing and
+// hanging up calls, managing registrations, draining RTP managers,
+// placing test calls, tailing call events, changing log levels and
+// taking runtime dumps.
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/sebas/switchboard/pkg/client"
+	"github.com/spf13/cobra"
+)
+
+// Global flags
+var (
+	serverURL string
+	timeout   time.Duration
+	output    string
+)
+
+func main() {
+	// Interrupting a command that follows a drain or tails events ends it
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	if err := newRootCommand().ExecuteContext(ctx); err != nil {
+		stop()
+		os.Exit(1)
+	}
+}
+
+func newRootCommand() *cobra.Command {
+	defaultServer := os.Getenv("SWITCHBOARD_SERVER")
+	if defaultServer == "" {
+		defaultServer = "http://localhost:8080"
+	}
+
+	root := &cobra.Command{
+		Use:          "switchboardctl",
+		Short:        "Administer switchboard signaling servers",
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			if output != outputTable && output != outputJSON {
+				return fmt.Errorf("invalid output format %q (use %s or %s)", output, outputTable, outputJSON)
+			}
+			return nil
+		},
+	}
+	root.PersistentFlags().StringVarP(&serverURL, "server", "s", defaultServer, "Signaling server API address (env SWITCHBOARD_SERVER)")
+	root.PersistentFlags().DurationVar(&timeout, "timeout", client.DefaultTimeout, "Timeout of each API request")
+	root.PersistentFlags().StringVarP(&output, "output", "o", outputTable, "Output format: table or json")
+
+	root.AddCommand(
+		newStatusCommand(),
+		newCallsCommand(),
+		newRegistrationsCommand(),
+		newRtpManagersCommand(),
+		newDrainCommand(),
+		newEventsCommand(),
+		newLogCommand(),
+		newDebugCommand(),
+	)
+	return root
+}
+
+// newClient returns an API client for the --server address.
+func newClient() *client.Client {
+	return newClientWithTimeout(timeout)
+}
+
+// newClientWithTimeout returns an API client for requests that may take
+// longer than --timeout.
+func newClientWithTimeout(d time.Duration) *client.Client {
+	c := client.NewClient("switchboard", serverURL)
+	c.SetHTTPClient(&http.Client{Timeout: d})
+	return c
+}
+
+// requestContext bounds a single API request by --timeout.
+func requestContext(cmd *cobra.Command) (context.Context, context.CancelFunc) {
+	return context.WithTimeout(cmd.Context(), timeout)
+}
+
+func newStatusCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "status",
+		Short: "Show server health and counters",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+			c := newClient()
+
+			health, err := c.Health(ctx)
+			if err != nil {
+				return err
+			}
+			stats, err := c.Stats(ctx)
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(map[string]any{"health": health, "stats": stats})
+			}
+			return printTable([]string{"FIELD", "VALUE"}, [][]string{
+				{"Server", c.BaseURL()},
+				{"Status", health.Status},
+				{"Version", health.Version},
+				{"Uptime", formatSeconds(int(health.Uptime))},
+				{"Active dialogs", fmt.Sprint(stats.ActiveDialogs)},
+				{"Active sessions", fmt.Sprint(stats.ActiveSessions)},
+				{"Registrations", fmt.Sprint(stats.TotalRegistrations)},
+				{"Bindings", fmt.Sprint(stats.TotalBindings)},
+			})
+		},
+	}
+}
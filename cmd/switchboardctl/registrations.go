@@ -0,0 +1,83 @@
+package main
+
+import (
+	"fmt"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/spf13/cobra"
+)
+
+func newRegistrationsCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:     "registrations",
+		Aliases: []string{"registration", "regs"},
+		Short:   "List and remove SIP registrations",
+	}
+	cmd.AddCommand(newRegistrationsListCommand(), newRegistrationsDeleteCommand())
+	return cmd
+}
+
+func newRegistrationsListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:     "list [AOR]",
+		Aliases: []string{"ls"},
+		Short:   "List registration bindings, of every AOR or of one",
+		Args:    cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			var regs []types.Registration
+			var err error
+			if len(args) == 1 {
+				regs, err = newClient().Bindings(ctx, args[0])
+			} else {
+				regs, err = newClient().Registrations(ctx)
+			}
+			if err != nil {
+				return err
+			}
+			if output == outputJSON {
+				return printJSON(regs)
+			}
+			rows := make([][]string, 0, len(regs))
+			for _, r := range regs {
+				rows = append(rows, []string{
+					r.AOR,
+					r.BindingID,
+					r.ContactURI,
+					r.Transport,
+					formatSeconds(r.Expires),
+					orDash(r.UserAgent),
+				})
+			}
+			return printTable([]string{"AOR", "BINDING", "CONTACT", "TRANSPORT", "EXPIRES", "USER-AGENT"}, rows)
+		},
+	}
+}
+
+func newRegistrationsDeleteCommand() *cobra.Command {
+	var bindingID string
+	cmd := &cobra.Command{
+		Use:     "delete AOR",
+		Aliases: []string{"rm"},
+		Short:   "Remove the bindings of an AOR, or one binding with --binding",
+		Args:    cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := requestContext(cmd)
+			defer cancel()
+
+			if err := newClient().RemoveBinding(ctx, args[0], bindingID); err != nil {
+				return err
+			}
+			if bindingID != "" {
+				fmt.Printf("%s: binding %s removed\n", args[0], bindingID)
+			} else {
+				fmt.Printf("%s: all bindings removed\n", args[0])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().StringVar(&bindingID, "binding", "", "Binding ID to remove (default: all bindings)")
+	return cmd
+}
@@ -10,7 +10,9 @@ import (
 	"syscall"
 
 	"github.com/sebas/switchboard/internal/banner"
+	"github.com/sebas/switchboard/internal/preflight"
 	"github.com/sebas/switchboard/internal/ui/config"
+	"github.com/sebas/switchboard/internal/ui/discovery"
 	"github.com/sebas/switchboard/internal/ui/server"
 )
 
@@ -32,10 +34,19 @@ func main() {
 		backendStrs[i] = fmt.Sprintf("%s (%s)", b.Name, b.Address)
 	}
 
+	discoveryStr := "off"
+	switch {
+	case cfg.DiscoverSRV != "":
+		discoveryStr = "DNS SRV " + cfg.DiscoverSRV
+	case cfg.DiscoverK8sSelector != "":
+		discoveryStr = "Kubernetes pods " + cfg.DiscoverK8sSelector
+	}
+
 	// Print startup banner
 	banner.Print("UI SERVER", []banner.ConfigLine{
 		{Label: "HTTP Listen", Value: fmt.Sprintf("%s:%d", cfg.BindAddr, cfg.Port)},
 		{Label: "Backends", Value: strings.Join(backendStrs, ", ")},
+		{Label: "Discovery", Value: discoveryStr},
 		{Label: "Log Level", Value: cfg.LogLevel},
 	})
 
@@ -53,6 +64,20 @@ func main() {
 	logger = slog.New(slog.NewTextHandler(os.Stdout, &slog.HandlerOptions{Level: logLevel}))
 	slog.SetDefault(logger)
 
+	discoverer, err := discovery.New(cfg)
+	if err != nil {
+		slog.Error("Invalid backend discovery", "error", err)
+		os.Exit(1)
+	}
+
+	// Validate the HTTP port and backends before serving anything
+	if !cfg.SkipPreflight {
+		if err := preflight.Run(context.Background(), preflightChecks(cfg, discoverer)); err != nil {
+			slog.Error("Preflight failed", "error", err)
+			os.Exit(1)
+		}
+	}
+
 	// Create and start server
 	srv, err := server.NewServer(cfg)
 	if err != nil {
@@ -71,6 +96,14 @@ func main() {
 	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
 	defer stop()
 
+	// Secret store references are resolved again as they are rotated
+	go srv.WatchSecrets(ctx, cfg.SecretsRefresh)
+
+	// Backends found by discovery come and go on the dashboard
+	if discoverer != nil {
+		go discovery.Watch(ctx, discoverer, cfg.DiscoverInterval, srv.SetBackends)
+	}
+
 	<-ctx.Done()
 
 	slog.Info("Shutting down UI server...")
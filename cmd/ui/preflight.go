@@ -0,0 +1,89 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/preflight"
+	"github.com/sebas/switchboard/internal/ui/config"
+	"github.com/sebas/switchboard/internal/ui/discovery"
+)
+
+// preflightChecks validates the configuration before the HTTP server starts
+func preflightChecks(cfg *config.Config, discoverer discovery.Discoverer) []preflight.Check {
+	checks := []preflight.Check{
+		{
+			Name: "http port",
+			Hint: "stop the process using it or choose another port with --port",
+			Run: func(ctx context.Context) error {
+				return preflight.PortFree("tcp", net.JoinHostPort(cfg.BindAddr, strconv.Itoa(cfg.Port)))
+			},
+		},
+		{
+			Name: "backends",
+			Hint: "set --backends to the signaling API addresses, e.g. http://localhost:8080",
+			Run: func(ctx context.Context) error {
+				if len(cfg.Backends) == 0 && discoverer != nil {
+					return nil
+				}
+				return checkBackends(ctx, cfg.Backends)
+			},
+		},
+	}
+	if discoverer != nil {
+		checks = append(checks, preflight.Check{
+			Name: "backend discovery",
+			Hint: "check the SRV records of --discover-srv, or the selector of --discover-k8s-selector and the service account's permission to list pods",
+			Run:  func(ctx context.Context) error { return checkDiscovery(ctx, discoverer) },
+		})
+	}
+	return checks
+}
+
+// checkDiscovery runs a first discovery. Failures are only reported, as
+// discovery is retried while the UI runs.
+func checkDiscovery(ctx context.Context, d discovery.Discoverer) error {
+	backends, err := d.Discover(ctx)
+	if err != nil {
+		return preflight.Warn(err)
+	}
+	if len(backends) == 0 {
+		return preflight.Warn(errors.New("no backends discovered yet"))
+	}
+	return nil
+}
+
+// checkBackends rejects malformed backend URLs. Unreachable backends are
+// only reported: the UI shows them as down until they come up.
+func checkBackends(ctx context.Context, backends []config.Backend) error {
+	if len(backends) == 0 {
+		return errors.New("no backends configured")
+	}
+	var unreachable []string
+	for _, b := range backends {
+		u, err := url.Parse(b.Address)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("backend %s has invalid address %q (want http://host:port)", b.Name, b.Address)
+		}
+		host := u.Host
+		if u.Port() == "" {
+			port := "80"
+			if u.Scheme == "https" {
+				port = "443"
+			}
+			host = net.JoinHostPort(u.Hostname(), port)
+		}
+		if err := preflight.Reachable(ctx, host); err != nil {
+			unreachable = append(unreachable, b.Name)
+		}
+	}
+	if len(unreachable) > 0 {
+		return preflight.Warn(fmt.Errorf("backends unreachable: %s", strings.Join(unreachable, ", ")))
+	}
+	return nil
+}
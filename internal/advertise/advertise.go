@@ -0,0 +1,126 @@
+// Package advertise picks the address to advertise to a peer.
+//
+// Split-horizon deployments reach internal peers on a private address and
+// public peers on a NAT address. A Selector maps destination networks to
+// the address to put in SDP and SIP headers; the most specific matching
+// network wins and peers matching no rule get the default address.
+package advertise
+
+import (
+	"fmt"
+	"net"
+	"strings"
+)
+
+// Rule advertises Addr to peers inside Network.
+type Rule struct {
+	Network *net.IPNet
+	Addr    string
+}
+
+func (r Rule) String() string {
+	return r.Network.String() + "=" + r.Addr
+}
+
+// ParseRules parses comma-separated CIDR=address rules such as
+// "10.0.0.0/8=10.0.0.5,0.0.0.0/0=203.0.113.5".
+func ParseRules(s string) ([]Rule, error) {
+	var rules []Rule
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		cidr, addr, ok := strings.Cut(part, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid advertise rule %q (want cidr=address)", part)
+		}
+		_, network, err := net.ParseCIDR(strings.TrimSpace(cidr))
+		if err != nil {
+			return nil, fmt.Errorf("invalid advertise rule %q: %w", part, err)
+		}
+		addr = strings.TrimSpace(addr)
+		if addr == "" {
+			return nil, fmt.Errorf("invalid advertise rule %q: address required", part)
+		}
+		rules = append(rules, Rule{Network: network, Addr: addr})
+	}
+	return rules, nil
+}
+
+// Selector chooses an advertised address by peer address.
+type Selector struct {
+	defaultAddr  string
+	defaultAddr6 string // Default for IPv6 peers; empty uses defaultAddr
+	rules        []Rule
+}
+
+// New creates a selector that falls back to defaultAddr.
+func New(defaultAddr string, rules []Rule) *Selector {
+	return &Selector{defaultAddr: defaultAddr, rules: rules}
+}
+
+// Parse creates a selector from a default address and a rule string (see ParseRules).
+func Parse(defaultAddr, rules string) (*Selector, error) {
+	parsed, err := ParseRules(rules)
+	if err != nil {
+		return nil, err
+	}
+	return New(defaultAddr, parsed), nil
+}
+
+// SetIPv6Default sets the address advertised to IPv6 peers matching no
+// rule, so dual-stack hosts answer each peer in its own address family.
+func (s *Selector) SetIPv6Default(addr string) {
+	s.defaultAddr6 = addr
+}
+
+// Select returns the address to advertise to peer. Peer may be an IP,
+// an IP:port pair or a hostname; hostnames and empty peers get the default.
+func (s *Selector) Select(peer string) string {
+	if s == nil {
+		return ""
+	}
+	ip := parseIP(peer)
+	if ip == nil {
+		return s.defaultAddr
+	}
+
+	addr, bestLen := s.defaultAddr, -1
+	if ip.To4() == nil && s.defaultAddr6 != "" {
+		addr = s.defaultAddr6
+	}
+	for _, r := range s.rules {
+		if !r.Network.Contains(ip) {
+			continue
+		}
+		if ones, _ := r.Network.Mask.Size(); ones > bestLen {
+			addr, bestLen = r.Addr, ones
+		}
+	}
+	return addr
+}
+
+// Default returns the address advertised to IPv4 peers matching no rule.
+func (s *Selector) Default() string {
+	if s == nil {
+		return ""
+	}
+	return s.defaultAddr
+}
+
+// Rules returns the configured rules.
+func (s *Selector) Rules() []Rule {
+	if s == nil {
+		return nil
+	}
+	return s.rules
+}
+
+func parseIP(peer string) net.IP {
+	peer = strings.TrimSpace(peer)
+	if host, _, err := net.SplitHostPort(peer); err == nil {
+		peer = host
+	}
+	return net.ParseIP(strings.Trim(peer, "[]"))
+}
@@ -3,6 +3,8 @@ package banner
 import (
 	"fmt"
 	"strings"
+
+	"github.com/sebas/switchboard/internal/version"
 )
 
 const logo = `
@@ -25,7 +27,7 @@ type ConfigLine struct {
 // Print displays the startup banner with the service name and configuration
 func Print(serviceName string, config []ConfigLine) {
 	fmt.Println(logo)
-	fmt.Printf("%s\n", serviceName)
+	fmt.Printf("%s %s\n", serviceName, version.Get())
 
 	// Find max label length for alignment
 	maxLen := 0
@@ -0,0 +1,57 @@
+// Package bufpool provides pooled buffers for the media and message hot
+// paths, so steady-state calls do not allocate for every RTP frame or
+// message body they handle.
+package bufpool
+
+import (
+	"bytes"
+	"sync"
+)
+
+// FrameSize fits any datagram that arrives unfragmented on an Ethernet MTU.
+const FrameSize = 1500
+
+// maxPooledBuffer keeps the odd very large body from pinning memory in the pool
+const maxPooledBuffer = 64 << 10
+
+var frames = sync.Pool{
+	New: func() any {
+		b := make([]byte, FrameSize)
+		return &b
+	},
+}
+
+var buffers = sync.Pool{
+	New: func() any { return new(bytes.Buffer) },
+}
+
+// GetFrame returns a FrameSize buffer for reading or marshaling an RTP
+// packet. Return it with PutFrame once nothing refers to it.
+func GetFrame() *[]byte {
+	return frames.Get().(*[]byte)
+}
+
+// PutFrame returns a buffer obtained from GetFrame to the pool.
+func PutFrame(b *[]byte) {
+	if cap(*b) < FrameSize {
+		return
+	}
+	*b = (*b)[:FrameSize]
+	frames.Put(b)
+}
+
+// GetBuffer returns an empty buffer for building a message body. Return it
+// with PutBuffer, after copying out anything that outlives it.
+func GetBuffer() *bytes.Buffer {
+	b := buffers.Get().(*bytes.Buffer)
+	b.Reset()
+	return b
+}
+
+// PutBuffer returns a buffer obtained from GetBuffer to the pool.
+func PutBuffer(b *bytes.Buffer) {
+	if b.Cap() > maxPooledBuffer {
+		return
+	}
+	buffers.Put(b)
+}
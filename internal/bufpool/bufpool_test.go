@@ -0,0 +1,70 @@
+package bufpool
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestPutFrameRestoresLength(t *testing.T) {
+	b := GetFrame()
+	*b = (*b)[:12]
+	PutFrame(b)
+
+	got := GetFrame()
+	defer PutFrame(got)
+	if len(*got) != FrameSize {
+		t.Fatalf("len = %d, want %d", len(*got), FrameSize)
+	}
+}
+
+func TestGetBufferIsEmpty(t *testing.T) {
+	b := GetBuffer()
+	b.WriteString("v=0\r\n")
+	PutBuffer(b)
+
+	got := GetBuffer()
+	defer PutBuffer(got)
+	if got.Len() != 0 {
+		t.Fatalf("Len = %d, want 0", got.Len())
+	}
+}
+
+var sink []byte
+
+func BenchmarkFrameMake(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		buf := make([]byte, FrameSize)
+		sink = buf
+	}
+}
+
+func BenchmarkFramePool(b *testing.B) {
+	b.ReportAllocs()
+	for b.Loop() {
+		buf := GetFrame()
+		sink = *buf
+		PutFrame(buf)
+	}
+}
+
+func BenchmarkBodyBuffer(b *testing.B) {
+	body := bytes.Repeat([]byte("a=rtpmap:0 PCMU/8000\r\n"), 16)
+	b.Run("new", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			var buf bytes.Buffer
+			buf.Write(body)
+			sink = bytes.Clone(buf.Bytes())
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			buf := GetBuffer()
+			buf.Write(body)
+			sink = bytes.Clone(buf.Bytes())
+			PutBuffer(buf)
+		}
+	})
+}
@@ -0,0 +1,100 @@
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"golang.org/x/crypto/acme"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// ACMEConfig configures certificates obtained from an ACME CA
+type ACMEConfig struct {
+	Domains      []string // Names the certificate is for; the first is used for clients without SNI
+	Email        string   // Contact for expiry notices; optional
+	CacheDir     string   // Keeps the account key and certificates across restarts
+	DirectoryURL string   // ACME directory; Let's Encrypt if empty
+}
+
+// ACME obtains certificates for the configured domains when first needed
+// and renews them before they expire. Domain ownership is proven with
+// HTTP-01 challenges, so ServeChallenges must be reachable on port 80 of
+// every domain.
+type ACME struct {
+	manager *autocert.Manager
+	domains []string
+}
+
+// NewACME returns an ACME certificate source
+func NewACME(cfg ACMEConfig) (*ACME, error) {
+	if len(cfg.Domains) == 0 {
+		return nil, errors.New("ACME needs at least one domain")
+	}
+	if cfg.CacheDir == "" {
+		return nil, errors.New("ACME needs a cache directory, or every restart requests new certificates")
+	}
+	m := &autocert.Manager{
+		Prompt:     autocert.AcceptTOS,
+		Cache:      autocert.DirCache(cfg.CacheDir),
+		HostPolicy: autocert.HostWhitelist(cfg.Domains...),
+		Email:      cfg.Email,
+	}
+	if cfg.DirectoryURL != "" {
+		m.Client = &acme.Client{DirectoryURL: cfg.DirectoryURL}
+	}
+	return &ACME{manager: m, domains: cfg.Domains}, nil
+}
+
+// GetCertificate implements tls.Config.GetCertificate. SIP clients often
+// connect by address without SNI; they get the first domain's certificate.
+func (a *ACME) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if hello.ServerName == "" {
+		h := *hello
+		h.ServerName = a.domains[0]
+		hello = &h
+	}
+	return a.manager.GetCertificate(hello)
+}
+
+// ServerConfig returns the configuration of a server presenting the
+// ACME certificates
+func (a *ACME) ServerConfig() *tls.Config {
+	return &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: a.GetCertificate,
+	}
+}
+
+// ServeChallenges answers HTTP-01 challenges on addr (e.g. ":80") until
+// ctx is done. Other requests are redirected to HTTPS.
+func (a *ACME) ServeChallenges(ctx context.Context, addr string) error {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           a.manager.HTTPHandler(nil),
+		ReadHeaderTimeout: 5 * time.Second,
+	}
+	go func() {
+		<-ctx.Done()
+		_ = srv.Close()
+	}()
+	slog.Info("[TLS] Serving ACME challenges", "addr", addr, "domains", a.domains)
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return err
+	}
+	return nil
+}
+
+// Prefetch obtains the first domain's certificate now, so the first client
+// does not wait for it. Failures are logged; the next handshake retries.
+func (a *ACME) Prefetch() {
+	hello := &tls.ClientHelloInfo{ServerName: a.domains[0]}
+	if _, err := a.manager.GetCertificate(hello); err != nil {
+		slog.Warn("[TLS] Could not obtain ACME certificate yet", "domain", a.domains[0], "error", err)
+		return
+	}
+	slog.Info("[TLS] ACME certificate ready", "domain", a.domains[0])
+}
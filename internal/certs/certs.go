@@ -0,0 +1,218 @@
+// Package certs provides the certificates of the TLS listeners and gRPC
+// connections, reloaded without a restart: from PEM files that are polled
+// for changes, such as a mounted Kubernetes secret or a certbot renewal, or
+// obtained and renewed through ACME for public-facing listeners.
+package certs
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+)
+
+// DefaultReloadInterval is how often certificate files are checked for
+// changes
+const DefaultReloadInterval = time.Minute
+
+// Source provides the TLS configuration of a listener. The certificate is
+// looked up on every handshake, so renewals apply to new connections.
+type Source interface {
+	ServerConfig() *tls.Config
+}
+
+// Files is a certificate and key, and optionally a CA bundle to verify
+// peers against, read from PEM files. A certificate without a CA bundle
+// serves TLS; with one, it is mutual TLS.
+type Files struct {
+	CertFile string
+	KeyFile  string
+	CAFile   string
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime map[string]time.Time
+}
+
+// LoadFiles reads the certificate, key and CA bundle. The certificate and
+// key may be left empty for a client that only verifies its server.
+func LoadFiles(certFile, keyFile, caFile string) (*Files, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("a TLS certificate needs both a certificate and a key file")
+	}
+	if certFile == "" && caFile == "" {
+		return nil, errors.New("no TLS certificate or CA file")
+	}
+	f := &Files{CertFile: certFile, KeyFile: keyFile, CAFile: caFile}
+	if err := f.load(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// load reads all files, replacing the certificate and pool only if every
+// file is valid
+func (f *Files) load() error {
+	modTime := make(map[string]time.Time)
+	for _, path := range []string{f.CertFile, f.KeyFile, f.CAFile} {
+		if path == "" {
+			continue
+		}
+		info, err := os.Stat(path)
+		if err != nil {
+			return err
+		}
+		modTime[path] = info.ModTime()
+	}
+
+	var cert *tls.Certificate
+	if f.CertFile != "" {
+		c, err := tls.LoadX509KeyPair(f.CertFile, f.KeyFile)
+		if err != nil {
+			return fmt.Errorf("load certificate %s: %w", f.CertFile, err)
+		}
+		cert = &c
+		slog.Info("[TLS] Certificate loaded", "file", f.CertFile,
+			"subject", cert.Leaf.Subject.CommonName, "expires", cert.Leaf.NotAfter.Format(time.RFC3339))
+	}
+	var pool *x509.CertPool
+	if f.CAFile != "" {
+		pem, err := os.ReadFile(f.CAFile)
+		if err != nil {
+			return err
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificate in %s", f.CAFile)
+		}
+	}
+
+	f.mu.Lock()
+	f.cert, f.pool, f.modTime = cert, pool, modTime
+	f.mu.Unlock()
+	return nil
+}
+
+// changed reports whether a file was modified since it was loaded
+func (f *Files) changed() bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	for path, loaded := range f.modTime {
+		info, err := os.Stat(path)
+		if err != nil || !info.ModTime().Equal(loaded) {
+			return true
+		}
+	}
+	return false
+}
+
+// Watch reloads the files every interval they changed, until ctx is done.
+// A failed reload, such as a key written after its certificate, keeps the
+// previous certificate and is retried at the next interval.
+func (f *Files) Watch(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		if !f.changed() {
+			continue
+		}
+		if err := f.load(); err != nil {
+			slog.Warn("[TLS] Certificate reload failed, keeping the current one", "file", f.CertFile, "error", err)
+		}
+	}
+}
+
+// Certificate returns the current certificate, or nil if there is none
+func (f *Files) Certificate() *tls.Certificate {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+	return f.cert
+}
+
+// GetCertificate implements tls.Config.GetCertificate
+func (f *Files) GetCertificate(*tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if cert := f.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return nil, errors.New("no TLS certificate configured")
+}
+
+// GetClientCertificate implements tls.Config.GetClientCertificate. Without
+// a certificate, none is sent.
+func (f *Files) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	if cert := f.Certificate(); cert != nil {
+		return cert, nil
+	}
+	return &tls.Certificate{}, nil
+}
+
+// ServerConfig returns the configuration of a server presenting the
+// certificate. With a CA bundle, clients must present a certificate it
+// signed.
+func (f *Files) ServerConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: f.GetCertificate,
+	}
+	if f.CAFile != "" {
+		// Verified against the current bundle rather than ClientCAs, which
+		// would keep the bundle of startup
+		cfg.ClientAuth = tls.RequireAnyClientCert
+		cfg.VerifyConnection = f.verify(x509.ExtKeyUsageClientAuth)
+	}
+	return cfg
+}
+
+// ClientConfig returns the configuration of a client presenting the
+// certificate, if any. With a CA bundle, the server must present a
+// certificate it signed; without, the system roots are used.
+func (f *Files) ClientConfig() *tls.Config {
+	cfg := &tls.Config{
+		MinVersion:           tls.VersionTLS12,
+		GetClientCertificate: f.GetClientCertificate,
+	}
+	if f.CAFile != "" {
+		// Verification is done by VerifyConnection against the current bundle
+		cfg.InsecureSkipVerify = true
+		cfg.VerifyConnection = f.verify(x509.ExtKeyUsageServerAuth)
+	}
+	return cfg
+}
+
+// verify checks the peer's certificate chain against the CA bundle, and
+// for servers the name dialed
+func (f *Files) verify(usage x509.ExtKeyUsage) func(tls.ConnectionState) error {
+	return func(cs tls.ConnectionState) error {
+		if len(cs.PeerCertificates) == 0 {
+			return errors.New("peer presented no certificate")
+		}
+		f.mu.RLock()
+		pool := f.pool
+		f.mu.RUnlock()
+
+		opts := x509.VerifyOptions{
+			Roots:         pool,
+			Intermediates: x509.NewCertPool(),
+			KeyUsages:     []x509.ExtKeyUsage{usage},
+		}
+		if usage == x509.ExtKeyUsageServerAuth {
+			opts.DNSName = cs.ServerName
+		}
+		for _, cert := range cs.PeerCertificates[1:] {
+			opts.Intermediates.AddCert(cert)
+		}
+		_, err := cs.PeerCertificates[0].Verify(opts)
+		return err
+	}
+}
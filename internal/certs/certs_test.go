@@ -0,0 +1,154 @@
+package certs
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// issue writes a certificate for name, signed by ca (self-signed if nil),
+// and its key to dir
+func issue(t *testing.T, dir, name string, ca *tls.Certificate) *tls.Certificate {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name},
+		DNSNames:     []string{name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+	parent, signer := tmpl, any(key)
+	if ca == nil {
+		tmpl.IsCA, tmpl.BasicConstraintsValid = true, true
+		tmpl.KeyUsage = x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature
+	} else {
+		parent, signer = ca.Leaf, ca.PrivateKey
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, parent, &key.PublicKey, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	if err := os.WriteFile(filepath.Join(dir, name+".crt"), certPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, name+".key"), keyPEM, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cert, err := tls.X509KeyPair(certPEM, keyPEM)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &cert
+}
+
+func TestWatchReloadsChangedCertificate(t *testing.T) {
+	dir := t.TempDir()
+	issue(t, dir, "old.example.com", nil)
+	f, err := LoadFiles(filepath.Join(dir, "old.example.com.crt"), filepath.Join(dir, "old.example.com.key"), "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.Watch(ctx, 10*time.Millisecond)
+
+	// Replace the files the way a renewal does
+	issue(t, dir, "new.example.com", nil)
+	later := time.Now().Add(time.Minute)
+	for _, ext := range []string{".crt", ".key"} {
+		path := filepath.Join(dir, "old.example.com"+ext)
+		if err := os.Rename(filepath.Join(dir, "new.example.com"+ext), path); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.Chtimes(path, later, later); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for f.Certificate().Leaf.Subject.CommonName != "new.example.com" {
+		if time.Now().After(deadline) {
+			t.Fatal("certificate not reloaded")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+func TestMutualTLS(t *testing.T) {
+	dir := t.TempDir()
+	ca := issue(t, dir, "ca", nil)
+	issue(t, dir, "localhost", ca)
+	issue(t, dir, "client", ca)
+	rogueCA := issue(t, dir, "rogue-ca", nil)
+	issue(t, dir, "rogue", rogueCA)
+
+	path := func(name string) string { return filepath.Join(dir, name) }
+	server, err := LoadFiles(path("localhost.crt"), path("localhost.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// handshake returns the server's verdict on the client
+	handshake := func(client *Files) error {
+		l, err := tls.Listen("tcp", "127.0.0.1:0", server.ServerConfig())
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() { _ = l.Close() }()
+		result := make(chan error, 1)
+		go func() {
+			conn, err := l.Accept()
+			if err != nil {
+				result <- err
+				return
+			}
+			defer func() { _ = conn.Close() }()
+			result <- conn.(*tls.Conn).Handshake()
+		}()
+
+		cfg := client.ClientConfig()
+		cfg.ServerName = "localhost"
+		if conn, err := tls.Dial("tcp", l.Addr().String(), cfg); err == nil {
+			defer func() { _ = conn.Close() }()
+		}
+		return <-result
+	}
+
+	trusted, err := LoadFiles(path("client.crt"), path("client.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(trusted); err != nil {
+		t.Errorf("trusted client: %v", err)
+	}
+
+	rogue, err := LoadFiles(path("rogue.crt"), path("rogue.key"), path("ca.crt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := handshake(rogue); err == nil {
+		t.Error("client with a certificate of another CA was accepted")
+	}
+}
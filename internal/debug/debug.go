@@ -0,0 +1,107 @@
+// Package debug serves runtime diagnostics for the switchboard services,
+// so performance problems can be looked into on a running node:
+//
+//   - /debug/pprof/ serves the net/http/pprof profiles (CPU, heap,
+//     goroutine, block, mutex, trace).
+//   - /debug/vars serves expvar counters (memstats, cmdline, goroutines).
+//   - POST /debug/dump?kind=goroutine|heap takes a one-off dump: full
+//     goroutine stacks as text, or a heap profile after a GC.
+//
+// Every endpoint requires "Authorization: Bearer <token>", and nothing is
+// served unless a token is configured.
+package debug
+
+import (
+	"crypto/subtle"
+	"expvar"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/pprof"
+	"runtime"
+	rpprof "runtime/pprof"
+	"strings"
+	"time"
+)
+
+// Dump kinds for /debug/dump
+const (
+	DumpGoroutine = "goroutine"
+	DumpHeap      = "heap"
+)
+
+func init() {
+	expvar.Publish("goroutines", expvar.Func(func() any { return runtime.NumGoroutine() }))
+}
+
+// Register adds the diagnostics endpoints to mux behind token. It does
+// nothing and returns false when token is empty.
+func Register(mux *http.ServeMux, token string) bool {
+	return RegisterFunc(mux, func() string { return token })
+}
+
+// RegisterFunc is Register for a token that may change, such as one
+// rotated in a secret store. The token is read on every request.
+func RegisterFunc(mux *http.ServeMux, token func() string) bool {
+	if token() == "" {
+		return false
+	}
+	auth := func(h http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if !authorized(r, token()) {
+				w.Header().Set("WWW-Authenticate", `Bearer realm="debug"`)
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			h(w, r)
+		}
+	}
+
+	// pprof.Index also serves the named profiles (heap, goroutine, ...)
+	mux.HandleFunc("/debug/pprof/", auth(pprof.Index))
+	mux.HandleFunc("/debug/pprof/cmdline", auth(pprof.Cmdline))
+	mux.HandleFunc("/debug/pprof/profile", auth(pprof.Profile))
+	mux.HandleFunc("/debug/pprof/symbol", auth(pprof.Symbol))
+	mux.HandleFunc("/debug/pprof/trace", auth(pprof.Trace))
+	mux.HandleFunc("/debug/vars", auth(expvar.Handler().ServeHTTP))
+	mux.HandleFunc("/debug/dump", auth(handleDump))
+	return true
+}
+
+// authorized compares the bearer token in constant time. An empty token,
+// as a rotated secret may briefly be, authorizes nothing.
+func authorized(r *http.Request, token string) bool {
+	got, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	return ok && token != "" && subtle.ConstantTimeCompare([]byte(got), []byte(token)) == 1
+}
+
+// handleDump writes a goroutine or heap dump as an attachment.
+func handleDump(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	if kind == "" {
+		kind = DumpGoroutine
+	}
+	name := fmt.Sprintf("%s-%s", kind, time.Now().UTC().Format("20060102T150405Z"))
+
+	switch kind {
+	case DumpGoroutine:
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.txt"`)
+		_ = rpprof.Lookup("goroutine").WriteTo(w, 2)
+	case DumpHeap:
+		// Collect first so the profile reflects live memory
+		runtime.GC()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		w.Header().Set("Content-Disposition", `attachment; filename="`+name+`.pprof"`)
+		_ = rpprof.Lookup("heap").WriteTo(w, 0)
+	default:
+		http.Error(w, fmt.Sprintf("unknown dump kind %q (use %s or %s)", kind, DumpGoroutine, DumpHeap), http.StatusBadRequest)
+		return
+	}
+	slog.Info("[Debug] Dump taken", "kind", kind, "remote", r.RemoteAddr)
+}
@@ -0,0 +1,41 @@
+package debug
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRegisterRequiresToken(t *testing.T) {
+	mux := http.NewServeMux()
+	if Register(mux, "") {
+		t.Fatal("Register() with no token = true, want false")
+	}
+	if !Register(mux, "secret") {
+		t.Fatal("Register() = false, want true")
+	}
+
+	cases := []struct {
+		auth string
+		want int
+	}{
+		{"", http.StatusUnauthorized},
+		{"Bearer wrong", http.StatusUnauthorized},
+		{"Bearer secret", http.StatusOK},
+	}
+	for _, c := range cases {
+		req := httptest.NewRequest(http.MethodPost, "/debug/dump?kind=goroutine", nil)
+		if c.auth != "" {
+			req.Header.Set("Authorization", c.auth)
+		}
+		rec := httptest.NewRecorder()
+		mux.ServeHTTP(rec, req)
+		if rec.Code != c.want {
+			t.Errorf("Authorization %q: status %d, want %d", c.auth, rec.Code, c.want)
+		}
+		if c.want == http.StatusOK && !strings.Contains(rec.Body.String(), "goroutine ") {
+			t.Errorf("goroutine dump missing stacks: %.100s", rec.Body.String())
+		}
+	}
+}
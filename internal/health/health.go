@@ -0,0 +1,112 @@
+// Package health serves liveness and readiness probes for the switchboard
+// services, in the form Kubernetes expects:
+//
+//   - GET /healthz answers 200 while the process can serve HTTP at all.
+//     Failing it gets the process restarted.
+//   - GET /readyz runs the registered checks and answers 200 only if all
+//     pass, or 503 listing the failures. Failing it takes the node out of
+//     rotation without restarting it.
+//
+// Both answer a JSON body, e.g. {"status":"not_ready","checks":{"sip":"ok",
+// "rtpmanagers":"no healthy RTP manager"}}.
+package health
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// CheckTimeout bounds how long one readiness check may take
+const CheckTimeout = 2 * time.Second
+
+// Statuses reported in probe responses
+const (
+	StatusOK       = "ok"
+	StatusReady    = "ready"
+	StatusNotReady = "not_ready"
+)
+
+// CheckFunc reports why a dependency is not ready, or nil when it is.
+type CheckFunc func(ctx context.Context) error
+
+type check struct {
+	name string
+	fn   CheckFunc
+}
+
+// Checker holds the readiness checks of a service. Safe for concurrent use.
+type Checker struct {
+	mu     sync.RWMutex
+	checks []check
+}
+
+// NewChecker creates a checker without checks; it is ready until checks
+// are added.
+func NewChecker() *Checker {
+	return &Checker{}
+}
+
+// Add registers a named readiness check. Checks run in the order added.
+func (c *Checker) Add(name string, fn CheckFunc) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.checks = append(c.checks, check{name: name, fn: fn})
+}
+
+// Check runs every check and returns each result by name ("ok" or the
+// error text) and whether all passed.
+func (c *Checker) Check(ctx context.Context) (map[string]string, bool) {
+	c.mu.RLock()
+	checks := c.checks
+	c.mu.RUnlock()
+
+	results := make(map[string]string, len(checks))
+	ready := true
+	for _, chk := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+		err := chk.fn(checkCtx)
+		cancel()
+		if err != nil {
+			results[chk.name] = err.Error()
+			ready = false
+			continue
+		}
+		results[chk.name] = StatusOK
+	}
+	return results, ready
+}
+
+// Register installs /healthz and /readyz on mux.
+func (c *Checker) Register(mux *http.ServeMux) {
+	mux.HandleFunc("/healthz", c.HandleLiveness)
+	mux.HandleFunc("/readyz", c.HandleReadiness)
+}
+
+// HandleLiveness answers the liveness probe.
+func (c *Checker) HandleLiveness(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]any{"status": StatusOK})
+}
+
+// HandleReadiness answers the readiness probe.
+func (c *Checker) HandleReadiness(w http.ResponseWriter, r *http.Request) {
+	results, ready := c.Check(r.Context())
+	if !ready {
+		slog.Debug("[Health] Not ready", "checks", results)
+		writeJSON(w, http.StatusServiceUnavailable, map[string]any{"status": StatusNotReady, "checks": results})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"status": StatusReady, "checks": results})
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Error("[Health] Failed to encode response", "error", err)
+	}
+}
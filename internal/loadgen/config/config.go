@@ -0,0 +1,111 @@
+package config
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"time"
+)
+
+// Media modes
+const (
+	MediaNone = "none" // Offer SDP but send no RTP
+	MediaTone = "tone" // Send a tone as RTP on answered calls
+)
+
+// Config holds the load generator configuration
+type Config struct {
+	// Target is the SIP address of the switchboard under test (host:port)
+	Target string
+
+	// Destination is the user part of the called URI
+	Destination string
+
+	// Caller is the user part of the From URI
+	Caller string
+
+	// Local SIP settings
+	BindAddr      string
+	Port          int    // 0 picks a free port
+	AdvertiseAddr string // Address in Contact and SDP
+
+	// Rate is the initial call attempt rate (calls per second)
+	Rate float64
+
+	// RampStep raises the rate every RampInterval, up to MaxRate, to find
+	// the concurrent call ceiling; zero keeps the rate constant
+	RampStep     float64
+	RampInterval time.Duration
+	MaxRate      float64
+
+	// Calls stops after this many attempts (0: until Duration elapses)
+	Calls int
+
+	// Duration stops placing calls after this long
+	Duration time.Duration
+
+	// Concurrency caps calls in progress (0: no limit)
+	Concurrency int
+
+	// HoldTime is how long answered calls stay up before BYE
+	HoldTime time.Duration
+
+	// SetupTimeout cancels calls not answered in time
+	SetupTimeout time.Duration
+
+	// Media is MediaNone or MediaTone
+	Media string
+
+	// Tone is the tone sent with MediaTone, in indications.conf syntax
+	Tone string
+
+	// JSON prints the report as JSON
+	JSON bool
+
+	LogLevel string
+}
+
+// Load loads configuration from command line flags and environment variables
+func Load() *Config {
+	cfg := &Config{}
+
+	flag.StringVar(&cfg.Target, "target", "127.0.0.1:5060", "SIP address of the switchboard under test")
+	flag.StringVar(&cfg.Destination, "destination", "500", "Number to call")
+	flag.StringVar(&cfg.Caller, "caller", "loadgen", "Caller user in From")
+	flag.StringVar(&cfg.BindAddr, "bind", "0.0.0.0", "Local SIP bind address")
+	flag.IntVar(&cfg.Port, "port", 0, "Local SIP port (0 picks a free port)")
+	flag.StringVar(&cfg.AdvertiseAddr, "advertise", "127.0.0.1", "Address advertised in Contact and SDP")
+	flag.Float64Var(&cfg.Rate, "rate", 10, "Call attempts per second")
+	flag.Float64Var(&cfg.RampStep, "ramp-step", 0, "Calls per second added every ramp interval (0 keeps the rate constant)")
+	flag.DurationVar(&cfg.RampInterval, "ramp-interval", 10*time.Second, "How often the rate is raised")
+	flag.Float64Var(&cfg.MaxRate, "max-rate", 0, "Highest rate when ramping (0: no limit)")
+	flag.IntVar(&cfg.Calls, "calls", 0, "Total call attempts (0: until duration elapses)")
+	flag.DurationVar(&cfg.Duration, "duration", time.Minute, "How long to place calls")
+	flag.IntVar(&cfg.Concurrency, "concurrency", 0, "Maximum calls in progress (0: no limit)")
+	flag.DurationVar(&cfg.HoldTime, "hold", 30*time.Second, "How long answered calls stay up")
+	flag.DurationVar(&cfg.SetupTimeout, "setup-timeout", 32*time.Second, "Cancel calls not answered in time")
+	flag.StringVar(&cfg.Media, "media", MediaNone, "Media on answered calls (none, tone)")
+	flag.StringVar(&cfg.Tone, "tone", "1000", "Tone sent with -media tone (e.g. 1000 or 440+480/2000,0/4000)")
+	flag.BoolVar(&cfg.JSON, "json", false, "Print the report as JSON")
+	flag.StringVar(&cfg.LogLevel, "loglevel", "info", "Log level (debug, info, warn, error)")
+
+	flag.Parse()
+
+	// Override with environment variables if set
+	if target := os.Getenv("LOADGEN_TARGET"); target != "" {
+		cfg.Target = target
+	}
+	if dest := os.Getenv("LOADGEN_DESTINATION"); dest != "" {
+		cfg.Destination = dest
+	}
+	if addr := os.Getenv("LOADGEN_ADVERTISE"); addr != "" {
+		cfg.AdvertiseAddr = addr
+	}
+	if rate := os.Getenv("LOADGEN_RATE"); rate != "" {
+		if r, err := strconv.ParseFloat(rate, 64); err == nil && r > 0 {
+			cfg.Rate = r
+		}
+	}
+
+	return cfg
+}
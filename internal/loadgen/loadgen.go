@@ -0,0 +1,369 @@
+// Package loadgen originates SIP calls against a switchboard at a
+// configurable rate, holds the answered ones for a while, and reports
+// setup latency, failures by status code and the concurrent call counts
+// reached, for capacity planning.
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/loadgen/config"
+	"github.com/sebas/switchboard/internal/rtpmanager/media"
+)
+
+// FailureCanceled counts calls still ringing when the run was interrupted.
+const FailureCanceled = "canceled"
+
+// progressInterval is how often progress is logged
+const progressInterval = 5 * time.Second
+
+// discardPort is advertised in SDP when no RTP is sent (RFC 863 discard)
+const discardPort = 9
+
+// Generator places calls and collects their outcomes.
+type Generator struct {
+	cfg     *config.Config
+	ua      *sipgo.UserAgent
+	conn    net.PacketConn
+	dialogs *sipgo.DialogClient
+	target  sip.Uri
+	tone    *toneSource
+
+	mu       sync.Mutex
+	rate     float64
+	active   int
+	report   Report
+	progress []time.Duration
+	setup    []time.Duration
+}
+
+// New creates a generator listening for SIP on the configured address.
+func New(cfg *config.Config) (*Generator, error) {
+	host, portStr, err := net.SplitHostPort(cfg.Target)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target %q: %w", cfg.Target, err)
+	}
+	port, err := strconv.Atoi(portStr)
+	if err != nil {
+		return nil, fmt.Errorf("invalid target port %q", portStr)
+	}
+	if cfg.Rate <= 0 {
+		return nil, errors.New("rate must be positive")
+	}
+
+	g := &Generator{
+		cfg:    cfg,
+		target: sip.Uri{Scheme: "sip", User: cfg.Destination, Host: host, Port: port},
+		rate:   cfg.Rate,
+		report: Report{Failures: make(map[string]int), ConcurrentAtFirstFailure: -1},
+	}
+
+	switch cfg.Media {
+	case config.MediaNone:
+	case config.MediaTone:
+		tone, err := media.ParseTone(cfg.Tone)
+		if err != nil {
+			return nil, err
+		}
+		g.tone = newToneSource(tone)
+	default:
+		return nil, fmt.Errorf("unknown media mode %q", cfg.Media)
+	}
+
+	g.conn, err = net.ListenPacket("udp", net.JoinHostPort(cfg.BindAddr, strconv.Itoa(cfg.Port)))
+	if err != nil {
+		return nil, fmt.Errorf("listen: %w", err)
+	}
+	localPort := g.conn.LocalAddr().(*net.UDPAddr).Port
+
+	g.ua, err = sipgo.NewUA(sipgo.WithUserAgent(cfg.Caller), sipgo.WithUserAgentHostname(cfg.AdvertiseAddr))
+	if err != nil {
+		_ = g.conn.Close()
+		return nil, fmt.Errorf("create user agent: %w", err)
+	}
+	client, err := sipgo.NewClient(g.ua, sipgo.WithClientHostname(cfg.AdvertiseAddr))
+	if err != nil {
+		_ = g.Close()
+		return nil, fmt.Errorf("create client: %w", err)
+	}
+	srv, err := sipgo.NewServer(g.ua)
+	if err != nil {
+		_ = g.Close()
+		return nil, fmt.Errorf("create server: %w", err)
+	}
+
+	contact := sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: cfg.Caller, Host: cfg.AdvertiseAddr, Port: localPort}}
+	g.dialogs = sipgo.NewDialogClient(client, contact)
+
+	srv.OnBye(func(req *sip.Request, tx sip.ServerTransaction) {
+		if err := g.dialogs.ReadBye(req, tx); err != nil {
+			_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil))
+		}
+	})
+	go func() { _ = srv.ServeUDP(g.conn) }()
+
+	slog.Info("[LoadGen] Listening", "addr", g.conn.LocalAddr().String(), "target", g.target.String())
+	return g, nil
+}
+
+// Close stops the SIP stack.
+func (g *Generator) Close() error {
+	_ = g.conn.Close()
+	return g.ua.Close()
+}
+
+// Run places calls until the configured duration or call count is
+// reached, waits for them to end, and returns the report. Canceling ctx
+// stops placing calls and hangs up the calls in progress.
+func (g *Generator) Run(ctx context.Context) *Report {
+	start := time.Now()
+	var wg sync.WaitGroup
+
+	var slots chan struct{}
+	if g.cfg.Concurrency > 0 {
+		slots = make(chan struct{}, g.cfg.Concurrency)
+	}
+
+	stopLog := make(chan struct{})
+	go g.logProgress(stopLog)
+
+	deadline := start.Add(g.cfg.Duration)
+	next := start
+	for attempts := 0; g.cfg.Calls == 0 || attempts < g.cfg.Calls; attempts++ {
+		rate := g.rampedRate(time.Since(start))
+		next = next.Add(time.Duration(float64(time.Second) / rate))
+		if next.After(deadline) {
+			break
+		}
+		if !sleepUntil(ctx, next) {
+			break
+		}
+		if slots != nil {
+			select {
+			case slots <- struct{}{}:
+			case <-ctx.Done():
+			}
+			if ctx.Err() != nil {
+				break
+			}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if slots != nil {
+				defer func() { <-slots }()
+			}
+			g.call(ctx)
+		}()
+	}
+
+	wg.Wait()
+	close(stopLog)
+	return g.finish(time.Since(start))
+}
+
+// rampedRate returns the attempt rate after elapsed time.
+func (g *Generator) rampedRate(elapsed time.Duration) float64 {
+	rate := g.cfg.Rate
+	if g.cfg.RampStep > 0 && g.cfg.RampInterval > 0 {
+		rate += g.cfg.RampStep * float64(elapsed/g.cfg.RampInterval)
+		if g.cfg.MaxRate > 0 && rate > g.cfg.MaxRate {
+			rate = g.cfg.MaxRate
+		}
+	}
+	g.mu.Lock()
+	if rate != g.rate {
+		slog.Info("[LoadGen] Rate raised", "rate", rate)
+	}
+	g.rate = rate
+	g.mu.Unlock()
+	return rate
+}
+
+// call places one call and holds it if answered.
+func (g *Generator) call(ctx context.Context) {
+	g.mu.Lock()
+	g.report.Attempts++
+	g.mu.Unlock()
+
+	var rtpConn net.PacketConn
+	sdpPort := discardPort
+	if g.tone != nil {
+		conn, err := net.ListenPacket("udp", net.JoinHostPort(g.cfg.BindAddr, "0"))
+		if err != nil {
+			g.fail(FailureError)
+			slog.Warn("[LoadGen] Failed to open RTP socket", "error", err)
+			return
+		}
+		defer func() { _ = conn.Close() }()
+		rtpConn = conn
+		sdpPort = conn.LocalAddr().(*net.UDPAddr).Port
+	}
+
+	setupCtx, cancel := context.WithTimeout(ctx, g.cfg.SetupTimeout)
+	defer cancel()
+
+	sent := time.Now()
+	session, err := g.dialogs.Invite(setupCtx, g.target, offerSDP(g.cfg.AdvertiseAddr, sdpPort),
+		sip.NewHeader("Content-Type", "application/sdp"))
+	if err != nil {
+		g.fail(FailureError)
+		slog.Debug("[LoadGen] INVITE failed", "error", err)
+		return
+	}
+	defer func() { _ = session.Close() }()
+
+	progressed := false
+	err = session.WaitAnswer(setupCtx, sipgo.AnswerOptions{
+		OnResponse: func(res *sip.Response) error {
+			if !progressed && res.IsProvisional() && res.StatusCode != sip.StatusTrying {
+				progressed = true
+				g.record(&g.progress, time.Since(sent))
+			}
+			return nil
+		},
+	})
+	if err != nil {
+		var rejected *sipgo.ErrDialogResponse
+		switch {
+		case errors.As(err, &rejected):
+			g.fail(strconv.Itoa(int(rejected.Res.StatusCode)))
+		case ctx.Err() != nil:
+			g.fail(FailureCanceled)
+		case setupCtx.Err() != nil:
+			g.fail(FailureTimeout)
+		default:
+			g.fail(FailureError)
+		}
+		slog.Debug("[LoadGen] Call failed", "call_id", session.InviteRequest.CallID().Value(), "error", err)
+		return
+	}
+	g.record(&g.setup, time.Since(sent))
+	if err := session.Ack(ctx); err != nil {
+		slog.Debug("[LoadGen] ACK failed", "error", err)
+	}
+
+	g.answered()
+	defer g.ended()
+
+	holdCtx, stopMedia := context.WithCancel(session.Context())
+	defer stopMedia()
+	if g.tone != nil {
+		if addr, err := answerAddr(session.InviteResponse.Body()); err != nil {
+			slog.Warn("[LoadGen] No media address in answer", "error", err)
+		} else {
+			go g.tone.send(holdCtx, rtpConn, addr)
+		}
+	}
+
+	select {
+	case <-time.After(g.cfg.HoldTime):
+	case <-ctx.Done():
+	case <-session.Context().Done():
+		g.mu.Lock()
+		g.report.RemoteHangups++
+		g.mu.Unlock()
+		return
+	}
+
+	byeCtx, cancelBye := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancelBye()
+	if err := session.Bye(byeCtx); err != nil {
+		slog.Debug("[LoadGen] BYE failed", "error", err)
+	}
+}
+
+func (g *Generator) record(samples *[]time.Duration, d time.Duration) {
+	g.mu.Lock()
+	*samples = append(*samples, d)
+	g.mu.Unlock()
+}
+
+func (g *Generator) fail(key string) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.report.Failed++
+	g.report.Failures[key]++
+	if g.report.ConcurrentAtFirstFailure < 0 && key != FailureCanceled {
+		g.report.ConcurrentAtFirstFailure = g.active
+		g.report.RateAtFirstFailure = g.rate
+		slog.Warn("[LoadGen] First failure", "reason", key, "concurrent", g.active, "rate", g.rate)
+	}
+}
+
+func (g *Generator) answered() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.report.Answered++
+	g.active++
+	g.report.MaxConcurrent = max(g.report.MaxConcurrent, g.active)
+}
+
+func (g *Generator) ended() {
+	g.mu.Lock()
+	g.active--
+	g.mu.Unlock()
+}
+
+// logProgress logs counters until stop is closed.
+func (g *Generator) logProgress(stop <-chan struct{}) {
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			g.mu.Lock()
+			slog.Info("[LoadGen] Progress",
+				"rate", g.rate,
+				"attempts", g.report.Attempts,
+				"answered", g.report.Answered,
+				"failed", g.report.Failed,
+				"active", g.active,
+			)
+			g.mu.Unlock()
+		}
+	}
+}
+
+// finish computes the final report.
+func (g *Generator) finish(elapsed time.Duration) *Report {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	r := g.report
+	r.Elapsed = elapsed
+	r.Seconds = elapsed.Seconds()
+	if r.Seconds > 0 {
+		r.Rate = float64(r.Attempts) / r.Seconds
+	}
+	r.Progress = newLatency(g.progress)
+	r.Setup = newLatency(g.setup)
+	return &r
+}
+
+// sleepUntil waits until t, returning false if ctx is canceled first.
+func sleepUntil(ctx context.Context, t time.Time) bool {
+	d := time.Until(t)
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
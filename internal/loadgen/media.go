@@ -0,0 +1,118 @@
+package loadgen
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/bufpool"
+	"github.com/sebas/switchboard/internal/rtpmanager/media"
+)
+
+const (
+	// frameDuration and frameSamples are the RTP packetization of PCMU
+	frameDuration = 20 * time.Millisecond
+	frameSamples  = 160
+)
+
+// offerSDP returns an audio offer for PCMU with telephone-event.
+func offerSDP(addr string, port int) []byte {
+	return []byte(fmt.Sprintf("v=0\r\n"+
+		"o=loadgen %d 1 IN IP4 %s\r\n"+
+		"s=loadgen\r\n"+
+		"c=IN IP4 %s\r\n"+
+		"t=0 0\r\n"+
+		"m=audio %d RTP/AVP 0 101\r\n"+
+		"a=rtpmap:0 PCMU/8000\r\n"+
+		"a=rtpmap:101 telephone-event/8000\r\n"+
+		"a=fmtp:101 0-16\r\n"+
+		"a=sendrecv\r\n",
+		rand.Uint32(), addr, addr, port))
+}
+
+// answerAddr returns the RTP address of the audio stream in an SDP answer.
+func answerAddr(body []byte) (*net.UDPAddr, error) {
+	var desc sdp.SessionDescription
+	if err := desc.Unmarshal(body); err != nil {
+		return nil, err
+	}
+	host := ""
+	if desc.ConnectionInformation != nil && desc.ConnectionInformation.Address != nil {
+		host = desc.ConnectionInformation.Address.Address
+	}
+	for _, m := range desc.MediaDescriptions {
+		if m.MediaName.Media != "audio" {
+			continue
+		}
+		if m.ConnectionInformation != nil && m.ConnectionInformation.Address != nil {
+			host = m.ConnectionInformation.Address.Address
+		}
+		if host == "" || m.MediaName.Port.Value == 0 {
+			break
+		}
+		return net.ResolveUDPAddr("udp", net.JoinHostPort(host, strconv.Itoa(m.MediaName.Port.Value)))
+	}
+	return nil, errors.New("no audio stream")
+}
+
+// toneSource holds a tone encoded as PCMU, shared by all calls.
+type toneSource struct {
+	pcmu     []byte
+	loopFrom int
+}
+
+func newToneSource(tone *media.Tone) *toneSource {
+	pcm, loopFrom := tone.Render()
+	// One PCMU byte per 16-bit PCM sample
+	return &toneSource{pcmu: media.PCMToPCMU(pcm), loopFrom: loopFrom / 2}
+}
+
+// send streams the tone as RTP to addr until ctx is done.
+func (t *toneSource) send(ctx context.Context, conn net.PacketConn, addr *net.UDPAddr) {
+	pkt := rtp.Packet{Header: rtp.Header{
+		Version:        2,
+		PayloadType:    0,
+		SequenceNumber: uint16(rand.Uint32()),
+		Timestamp:      rand.Uint32(),
+		SSRC:           rand.Uint32(),
+	}}
+
+	out := bufpool.GetFrame()
+	defer bufpool.PutFrame(out)
+
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+	pos := 0
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if pos+frameSamples > len(t.pcmu) {
+			pos = t.loopFrom
+			if pos+frameSamples > len(t.pcmu) {
+				return // Play-once tone finished
+			}
+		}
+		pkt.Payload = t.pcmu[pos : pos+frameSamples]
+		pos += frameSamples
+
+		n, err := pkt.MarshalTo(*out)
+		if err != nil {
+			return
+		}
+		if _, err := conn.WriteTo((*out)[:n], addr); err != nil {
+			return
+		}
+		pkt.SequenceNumber++
+		pkt.Timestamp += frameSamples
+	}
+}
@@ -0,0 +1,120 @@
+package loadgen
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"slices"
+	"sort"
+	"time"
+)
+
+// Failure keys for calls without a final SIP response
+const (
+	FailureTimeout = "timeout" // Not answered within the setup timeout
+	FailureError   = "error"   // Transport or transaction error
+)
+
+// Report summarizes a load test.
+type Report struct {
+	Elapsed  time.Duration `json:"-"`
+	Seconds  float64       `json:"elapsed_seconds"`
+	Rate     float64       `json:"attempt_rate"` // Achieved attempts per second
+	Attempts int           `json:"attempts"`
+	Answered int           `json:"answered"`
+	Failed   int           `json:"failed"`
+
+	// Failures counts failed calls by final status code, FailureTimeout or FailureError
+	Failures map[string]int `json:"failures"`
+
+	// RemoteHangups counts answered calls the switchboard hung up before the hold time
+	RemoteHangups int `json:"remote_hangups"`
+
+	// Progress is the time from INVITE to the first provisional response
+	// other than 100 Trying (post-dial delay); Setup to the 2xx answer.
+	Progress Latency `json:"progress_latency"`
+	Setup    Latency `json:"setup_latency"`
+
+	// MaxConcurrent is the most answered calls up at once
+	MaxConcurrent int `json:"max_concurrent"`
+
+	// ConcurrentAtFirstFailure is the number of answered calls up when the
+	// first call failed (-1: no failures), a hint of the capacity ceiling
+	ConcurrentAtFirstFailure int `json:"concurrent_at_first_failure"`
+
+	// RateAtFirstFailure is the attempt rate when the first call failed
+	RateAtFirstFailure float64 `json:"rate_at_first_failure,omitempty"`
+}
+
+// Latency holds latency percentiles in milliseconds.
+type Latency struct {
+	Count int     `json:"count"`
+	Min   float64 `json:"min_ms"`
+	P50   float64 `json:"p50_ms"`
+	P90   float64 `json:"p90_ms"`
+	P99   float64 `json:"p99_ms"`
+	Max   float64 `json:"max_ms"`
+}
+
+// newLatency computes percentiles of samples, which it sorts.
+func newLatency(samples []time.Duration) Latency {
+	if len(samples) == 0 {
+		return Latency{}
+	}
+	slices.Sort(samples)
+	at := func(p float64) float64 {
+		i := int(p * float64(len(samples)-1))
+		return ms(samples[i])
+	}
+	return Latency{
+		Count: len(samples),
+		Min:   ms(samples[0]),
+		P50:   at(0.50),
+		P90:   at(0.90),
+		P99:   at(0.99),
+		Max:   ms(samples[len(samples)-1]),
+	}
+}
+
+func ms(d time.Duration) float64 {
+	return float64(d.Microseconds()) / 1000
+}
+
+// WriteText writes the report for terminals.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "Elapsed:        %s\n", r.Elapsed.Round(time.Millisecond))
+	fmt.Fprintf(w, "Attempts:       %d (%.1f/s)\n", r.Attempts, r.Rate)
+	fmt.Fprintf(w, "Answered:       %d\n", r.Answered)
+	fmt.Fprintf(w, "Failed:         %d\n", r.Failed)
+	if len(r.Failures) > 0 {
+		keys := make([]string, 0, len(r.Failures))
+		for k := range r.Failures {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			fmt.Fprintf(w, "  %-12s  %d\n", k, r.Failures[k])
+		}
+	}
+	fmt.Fprintf(w, "Remote hangups: %d\n", r.RemoteHangups)
+	fmt.Fprintf(w, "Max concurrent: %d\n", r.MaxConcurrent)
+	if r.ConcurrentAtFirstFailure >= 0 {
+		fmt.Fprintf(w, "First failure:  %d concurrent, %.1f attempts/s\n", r.ConcurrentAtFirstFailure, r.RateAtFirstFailure)
+	}
+	fmt.Fprintln(w)
+	fmt.Fprintf(w, "%-10s %7s %9s %9s %9s %9s %9s\n", "latency", "count", "min", "p50", "p90", "p99", "max")
+	for _, row := range []struct {
+		name string
+		l    Latency
+	}{{"progress", r.Progress}, {"setup", r.Setup}} {
+		fmt.Fprintf(w, "%-10s %7d %7.1fms %7.1fms %7.1fms %7.1fms %7.1fms\n",
+			row.name, row.l.Count, row.l.Min, row.l.P50, row.l.P90, row.l.P99, row.l.Max)
+	}
+}
+
+// WriteJSON writes the report as JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
@@ -88,8 +88,12 @@ func SetLevel(levelStr string) {
 func GetLevel() string {
 	handlerMutex.RLock()
 	defer handlerMutex.RUnlock()
+	return levelString(globalLevel)
+}
 
-	switch globalLevel {
+// levelString names an slog level as accepted by ParseLevel
+func levelString(level slog.Level) string {
+	switch level {
 	case slog.LevelDebug:
 		return "debug"
 	case slog.LevelInfo:
@@ -103,19 +107,34 @@ func GetLevel() string {
 	}
 }
 
+// ValidateLevel reports an error for a string ParseLevel does not know
+func ValidateLevel(s string) error {
+	if _, ok := parseLevel(s); !ok {
+		return fmt.Errorf("unknown log level %q (use debug, info, warn or error)", s)
+	}
+	return nil
+}
+
 // ParseLevel parses a string to an slog level
 func ParseLevel(s string) slog.Level {
+	level, _ := parseLevel(s)
+	return level
+}
+
+// parseLevel parses a string to an slog level, reporting whether it was
+// known; unknown strings give debug
+func parseLevel(s string) (slog.Level, bool) {
 	switch strings.ToLower(strings.TrimSpace(s)) {
 	case "debug":
-		return slog.LevelDebug
+		return slog.LevelDebug, true
 	case "info":
-		return slog.LevelInfo
+		return slog.LevelInfo, true
 	case "warn", "warning":
-		return slog.LevelWarn
+		return slog.LevelWarn, true
 	case "error":
-		return slog.LevelError
+		return slog.LevelError, true
 	default:
-		return slog.LevelDebug
+		return slog.LevelDebug, false
 	}
 }
 
@@ -126,9 +145,11 @@ func AddTUIHandler(handler TUIHandler) {
 	tuiHandler = handler
 }
 
-// customHandler supports multiple outputs with level filtering
+// customHandler supports multiple outputs with level filtering. Levels
+// apply per module (see SetModuleLevel) on top of the global level.
 type customHandler struct {
-	outs []io.Writer // Can write to multiple outputs (stdout, file, etc.)
+	outs []io.Writer  // Can write to multiple outputs (stdout, file, etc.)
+	json slog.Handler // Writes JSON instead of the text format when set
 	mu   sync.Mutex
 }
 
@@ -216,17 +237,24 @@ func (h *MultiLevelHandler) Enabled(ctx context.Context, level slog.Level) bool
 
 // Handle implements slog.Handler
 func (h *customHandler) Handle(ctx context.Context, record slog.Record) error {
-	h.mu.Lock()
-	defer h.mu.Unlock()
-
-	// Check if we should log this level
+	// Check if we should log this level for the message's module
 	handlerMutex.RLock()
-	if record.Level < globalLevel {
+	if record.Level < levelFor(record.Message) {
 		handlerMutex.RUnlock()
 		return nil
 	}
 	handlerMutex.RUnlock()
 
+	if h.json != nil {
+		if module := moduleOf(record.Message); module != "" {
+			record.AddAttrs(slog.String("module", module))
+		}
+		return h.json.Handle(ctx, record)
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
 	// Format the log message
 	timestamp := record.Time.Format("15:04:05")
 	levelStr := record.Level.String()
@@ -277,11 +305,12 @@ func (h *customHandler) WithGroup(name string) slog.Handler {
 	return h
 }
 
-// Enabled implements slog.Handler
+// Enabled implements slog.Handler. The module is only known in Handle,
+// so any level some module logs at is enabled here.
 func (h *customHandler) Enabled(ctx context.Context, level slog.Level) bool {
 	handlerMutex.RLock()
 	defer handlerMutex.RUnlock()
-	return level >= globalLevel
+	return level >= minLevel()
 }
 
 // InitLogger initializes the global logger with one or more output writers
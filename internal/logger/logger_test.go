@@ -0,0 +1,61 @@
+package logger
+
+import (
+	"bytes"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRotatingFileRotatesBySize(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "test.log")
+	f, err := OpenRotatingFile(path, 0, 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	f.maxSize = 10 // bytes, to rotate on every second line
+
+	for range 5 {
+		if _, err := f.Write([]byte("0123456789\n")); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	backups, _ := filepath.Glob(path + ".*")
+	if len(backups) != 2 {
+		t.Fatalf("got %d rotated files, want 2 (max backups)", len(backups))
+	}
+	data, _ := os.ReadFile(path)
+	if string(data) != "0123456789\n" {
+		t.Fatalf("current file = %q, want the last line only", data)
+	}
+}
+
+func TestModuleLevels(t *testing.T) {
+	defer SetLevel(GetLevel())
+	defer SetModuleLevels("")
+
+	var buf bytes.Buffer
+	InitLogger(&buf)
+	SetLevel("info")
+	if err := SetModuleLevels("dialog=debug,b2bua=error"); err != nil {
+		t.Fatal(err)
+	}
+
+	slog.Debug("[Dialog] shown")
+	slog.Debug("[Pool] hidden")
+	slog.Warn("[Originator] hidden")
+	slog.Info("[Pool] shown")
+
+	out := buf.String()
+	if strings.Contains(out, "hidden") || strings.Count(out, "shown") != 2 {
+		t.Fatalf("unexpected output:\n%s", out)
+	}
+
+	if _, err := ParseModuleLevels("dialog=verbose"); err == nil {
+		t.Error("ParseModuleLevels accepted an unknown level")
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"log/slog"
+	"maps"
+	"strings"
+)
+
+// moduleLevels overrides the global level for the messages of one module,
+// named by the "[Module]" prefix every message in the codebase starts with
+// and matched case-insensitively (e.g. "dialog" for "[Dialog] ..."). Guarded
+// by handlerMutex.
+var moduleLevels = map[string]slog.Level{}
+
+// moduleAliases names the packages whose messages use several prefixes,
+// so that e.g. "b2bua=debug" covers [CallService], [Originator] and [Leg].
+var moduleAliases = map[string][]string{
+	"b2bua":        {"b2bua", "callservice", "originator", "originate", "leg", "bridge"},
+	"mediaclient":  {"mediaclient", "pool", "grpc"},
+	"registration": {"registration", "register", "location", "regevent"},
+	"drain":        {"drain", "draincoordinator", "migrator"},
+	"media":        {"media", "audio", "wav", "codecmgr"},
+}
+
+// modulePrefixes returns the message prefixes a module name stands for.
+func modulePrefixes(module string) []string {
+	module = strings.ToLower(strings.TrimSpace(module))
+	if prefixes, ok := moduleAliases[module]; ok {
+		return prefixes
+	}
+	return []string{module}
+}
+
+// SetModuleLevel overrides the log level of one module.
+func SetModuleLevel(module, level string) error {
+	if err := ValidateLevel(level); err != nil {
+		return err
+	}
+	lvl := ParseLevel(level)
+	if strings.TrimSpace(module) == "" {
+		return fmt.Errorf("empty module name")
+	}
+
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	for _, prefix := range modulePrefixes(module) {
+		moduleLevels[prefix] = lvl
+	}
+	return nil
+}
+
+// ClearModuleLevel removes the override of one module, which then logs at
+// the global level again.
+func ClearModuleLevel(module string) {
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	for _, prefix := range modulePrefixes(module) {
+		delete(moduleLevels, prefix)
+	}
+}
+
+// SetModuleLevels replaces every module override with those in spec, a
+// comma-separated list of module=level pairs (e.g. "dialog=debug,b2bua=warn").
+func SetModuleLevels(spec string) error {
+	levels, err := ParseModuleLevels(spec)
+	if err != nil {
+		return err
+	}
+
+	handlerMutex.Lock()
+	defer handlerMutex.Unlock()
+	moduleLevels = levels
+	return nil
+}
+
+// ModuleLevels returns the overrides by message prefix, with aliases
+// expanded.
+func ModuleLevels() map[string]string {
+	handlerMutex.RLock()
+	defer handlerMutex.RUnlock()
+
+	out := make(map[string]string, len(moduleLevels))
+	for module, lvl := range moduleLevels {
+		out[module] = levelString(lvl)
+	}
+	return out
+}
+
+// ParseModuleLevels parses a comma-separated list of module=level pairs.
+func ParseModuleLevels(spec string) (map[string]slog.Level, error) {
+	levels := make(map[string]slog.Level)
+	for _, pair := range strings.Split(spec, ",") {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		module, level, ok := strings.Cut(pair, "=")
+		if !ok || strings.TrimSpace(module) == "" {
+			return nil, fmt.Errorf("invalid module level %q (want module=level)", pair)
+		}
+		lvl, ok := parseLevel(level)
+		if !ok {
+			return nil, fmt.Errorf("invalid module level %q: unknown level %q", pair, level)
+		}
+		for _, prefix := range modulePrefixes(module) {
+			levels[prefix] = lvl
+		}
+	}
+	return levels, nil
+}
+
+// moduleOf returns the lowercased module of a "[Module] ..." message, or
+// "" for messages without one.
+func moduleOf(msg string) string {
+	if !strings.HasPrefix(msg, "[") {
+		return ""
+	}
+	end := strings.IndexByte(msg, ']')
+	if end < 0 {
+		return ""
+	}
+	return strings.ToLower(msg[1:end])
+}
+
+// levelFor returns the level that applies to msg. Must hold handlerMutex.
+func levelFor(msg string) slog.Level {
+	if len(moduleLevels) > 0 {
+		if lvl, ok := moduleLevels[moduleOf(msg)]; ok {
+			return lvl
+		}
+	}
+	return globalLevel
+}
+
+// minLevel returns the lowest level any module logs at. Must hold
+// handlerMutex.
+func minLevel() slog.Level {
+	lowest := globalLevel
+	for lvl := range maps.Values(moduleLevels) {
+		lowest = min(lowest, lvl)
+	}
+	return lowest
+}
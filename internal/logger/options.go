@@ -0,0 +1,70 @@
+package logger
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"time"
+)
+
+// Log formats for Options.Format
+const (
+	FormatText = "text" // "[15:04:05] [INFO] message key=value"
+	FormatJSON = "json" // One slog JSON object per line
+)
+
+// Options configures the logger set up by InitLoggerWithOptions.
+type Options struct {
+	Level  string // Global level: debug, info, warn, error
+	Format string // FormatText (default) or FormatJSON
+
+	// File is the log file; empty logs to stdout
+	File        string
+	MaxSizeMB   int           // Rotate the file past this size (0 disables)
+	RotateEvery time.Duration // Rotate the file after this long (0 disables)
+	MaxBackups  int           // Rotated files to keep (0 keeps all)
+
+	// Modules overrides the level per module, e.g. "dialog=debug,b2bua=warn"
+	Modules string
+}
+
+// InitLoggerWithOptions initializes the global logger from opts. The
+// returned closer closes the log file, if any.
+func InitLoggerWithOptions(opts Options) (io.Closer, error) {
+	if opts.Format != "" && opts.Format != FormatText && opts.Format != FormatJSON {
+		return nil, fmt.Errorf("unknown log format %q (use %s or %s)", opts.Format, FormatText, FormatJSON)
+	}
+	if opts.Level != "" {
+		if err := ValidateLevel(opts.Level); err != nil {
+			return nil, err
+		}
+		SetLevel(opts.Level)
+	}
+	if err := SetModuleLevels(opts.Modules); err != nil {
+		return nil, err
+	}
+
+	var out io.Writer = os.Stdout
+	var closer io.Closer = nopCloser{}
+	if opts.File != "" {
+		file, err := OpenRotatingFile(opts.File, opts.MaxSizeMB, opts.RotateEvery, opts.MaxBackups)
+		if err != nil {
+			return nil, err
+		}
+		out, closer = file, file
+	}
+
+	if opts.Format == FormatJSON {
+		// Levels are filtered by the handler, so the JSON handler takes all
+		json := slog.NewJSONHandler(out, &slog.HandlerOptions{Level: slog.Level(-8)})
+		slog.SetDefault(slog.New(&customHandler{json: json}))
+		return closer, nil
+	}
+	InitLogger(out)
+	return closer, nil
+}
+
+type nopCloser struct{}
+
+func (nopCloser) Close() error { return nil }
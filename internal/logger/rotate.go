@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+	"time"
+)
+
+// rotatedSuffix is appended to rotated files, e.g. "signaling.log.20261016-150405.000"
+const rotatedSuffix = "20060102-150405.000"
+
+// RotatingFile is a log file that is rotated when it grows past a size or
+// has been written for longer than an interval. Rotated files are renamed
+// with a timestamp suffix; the oldest are removed beyond a backup count.
+// Safe for concurrent use.
+type RotatingFile struct {
+	path       string
+	maxSize    int64         // 0 disables size rotation
+	interval   time.Duration // 0 disables time rotation
+	maxBackups int           // 0 keeps every rotated file
+
+	mu     sync.Mutex
+	file   *os.File
+	size   int64
+	opened time.Time
+}
+
+// OpenRotatingFile opens (appending to) the log file at path, creating it
+// and its directory if needed.
+func OpenRotatingFile(path string, maxSizeMB int, interval time.Duration, maxBackups int) (*RotatingFile, error) {
+	f := &RotatingFile{
+		path:       path,
+		maxSize:    int64(maxSizeMB) << 20,
+		interval:   interval,
+		maxBackups: maxBackups,
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+// Write implements io.Writer, rotating first when the file is due.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return 0, os.ErrClosed
+	}
+	if f.due(len(p)) {
+		if err := f.rotate(); err != nil {
+			// Keep logging to the current file rather than losing lines
+			fmt.Fprintf(os.Stderr, "log rotation failed: %v\n", err)
+		}
+	}
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	return n, err
+}
+
+// Close closes the current file.
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
+
+// due reports whether writing n more bytes should start a new file.
+func (f *RotatingFile) due(n int) bool {
+	if f.size == 0 {
+		return false
+	}
+	if f.maxSize > 0 && f.size+int64(n) > f.maxSize {
+		return true
+	}
+	return f.interval > 0 && time.Since(f.opened) >= f.interval
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		_ = file.Close()
+		return fmt.Errorf("stat log file: %w", err)
+	}
+	f.file = file
+	f.size = info.Size()
+	f.opened = time.Now()
+	return nil
+}
+
+// rotate renames the current file aside, opens a fresh one and prunes old
+// backups. Must hold f.mu.
+func (f *RotatingFile) rotate() error {
+	if err := f.file.Close(); err != nil {
+		return err
+	}
+	rotated := f.path + "." + time.Now().Format(rotatedSuffix)
+	if _, err := os.Stat(rotated); err == nil {
+		// Several rotations within a second share a timestamp
+		rotated += fmt.Sprintf(".%d", time.Now().UnixNano()%1e9)
+	}
+	renameErr := os.Rename(f.path, rotated)
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return renameErr
+	}
+	f.prune()
+	return nil
+}
+
+// prune removes the oldest rotated files beyond maxBackups.
+func (f *RotatingFile) prune() {
+	if f.maxBackups <= 0 {
+		return
+	}
+	backups, _ := filepath.Glob(f.path + ".*")
+	if len(backups) <= f.maxBackups {
+		return
+	}
+	// Timestamp suffixes sort chronologically
+	slices.Sort(backups)
+	for _, old := range backups[:len(backups)-f.maxBackups] {
+		_ = os.Remove(old)
+	}
+}
@@ -0,0 +1,178 @@
+// Package preflight validates a service's configuration and environment at
+// startup, so a bad port, path or address stops the process with an
+// actionable error instead of failing the first call that needs it.
+//
+// Each service builds a list of checks from its config and runs them
+// before creating its servers:
+//
+//	if err := preflight.Run(ctx, checks); err != nil {
+//		slog.Error("Preflight failed", "error", err)
+//		os.Exit(1)
+//	}
+//
+// A check that returns an error wrapped with Warn is logged but does not
+// stop startup.
+package preflight
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"time"
+)
+
+// CheckTimeout bounds how long one check may take
+const CheckTimeout = 5 * time.Second
+
+// Check is one startup validation.
+type Check struct {
+	Name string
+	// Hint tells the operator how to fix a failure, e.g. which flag to set
+	Hint string
+	Run  func(ctx context.Context) error
+}
+
+type warning struct{ error }
+
+func (w warning) Unwrap() error { return w.error }
+
+// Warn marks a check failure as non-fatal.
+func Warn(err error) error {
+	if err == nil {
+		return nil
+	}
+	return warning{err}
+}
+
+// Run runs every check, logging each failure with its hint, and returns an
+// error if any check failed without Warn.
+func Run(ctx context.Context, checks []Check) error {
+	failed := 0
+	for _, c := range checks {
+		checkCtx, cancel := context.WithTimeout(ctx, CheckTimeout)
+		err := c.Run(checkCtx)
+		cancel()
+
+		var w warning
+		switch {
+		case err == nil:
+			slog.Debug("[Preflight] Check passed", "check", c.Name)
+		case errors.As(err, &w):
+			slog.Warn("[Preflight] "+c.Name+": "+err.Error(), "hint", c.Hint)
+		default:
+			slog.Error("[Preflight] "+c.Name+": "+err.Error(), "hint", c.Hint)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d preflight checks failed", failed, len(checks))
+	}
+	slog.Info("[Preflight] Checks passed", "checks", len(checks))
+	return nil
+}
+
+// PortFree checks that a host:port address can be bound ("tcp" or "udp").
+func PortFree(network, addr string) error {
+	_, portStr, err := net.SplitHostPort(addr)
+	if err != nil {
+		return fmt.Errorf("invalid address %s: %w", addr, err)
+	}
+	if port, err := strconv.Atoi(portStr); err != nil || port < 1 || port > 65535 {
+		return fmt.Errorf("invalid %s port %s", network, portStr)
+	}
+	switch network {
+	case "udp":
+		pc, err := net.ListenPacket(network, addr)
+		if err != nil {
+			return fmt.Errorf("cannot bind %s %s: %w", network, addr, err)
+		}
+		return pc.Close()
+	default:
+		l, err := net.Listen(network, addr)
+		if err != nil {
+			return fmt.Errorf("cannot bind %s %s: %w", network, addr, err)
+		}
+		return l.Close()
+	}
+}
+
+// Dir checks that path is an existing, readable directory.
+func Dir(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("directory %s does not exist", path)
+		}
+		return err
+	}
+	if !info.IsDir() {
+		return fmt.Errorf("%s is not a directory", path)
+	}
+	f, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("directory %s is not readable: %w", path, err)
+	}
+	return f.Close()
+}
+
+// File checks that path is an existing, readable regular file.
+func File(path string) error {
+	f, err := os.Open(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return fmt.Errorf("file %s does not exist", path)
+		}
+		return fmt.Errorf("file %s is not readable: %w", path, err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if info.IsDir() {
+		return fmt.Errorf("%s is a directory, not a file", path)
+	}
+	return nil
+}
+
+// Reachable checks that a TCP connection to addr (host:port) can be opened.
+func Reachable(ctx context.Context, addr string) error {
+	var d net.Dialer
+	conn, err := d.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("cannot connect to %s: %w", addr, err)
+	}
+	return conn.Close()
+}
+
+// Routable checks that an address advertised to peers can be reached by
+// them. An unspecified or unresolvable address is an error; a loopback or
+// link-local address only works from this host or link, so it is a warning.
+func Routable(ctx context.Context, addr string) error {
+	if addr == "" {
+		return errors.New("no address to advertise")
+	}
+	ip := net.ParseIP(addr)
+	if ip == nil {
+		ips, err := net.DefaultResolver.LookupIP(ctx, "ip", addr)
+		if err != nil || len(ips) == 0 {
+			return fmt.Errorf("advertised host %s does not resolve", addr)
+		}
+		ip = ips[0]
+	}
+	switch {
+	case ip.IsUnspecified():
+		return fmt.Errorf("advertised address %s is unspecified; peers cannot reach it", addr)
+	case ip.IsMulticast():
+		return fmt.Errorf("advertised address %s is a multicast address", addr)
+	case ip.IsLoopback():
+		return Warn(fmt.Errorf("advertised address %s is loopback; only peers on this host can reach it", addr))
+	case ip.IsLinkLocalUnicast():
+		return Warn(fmt.Errorf("advertised address %s is link-local; peers beyond this link cannot reach it", addr))
+	}
+	return nil
+}
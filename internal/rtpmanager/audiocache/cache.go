@@ -0,0 +1,463 @@
+// Package audiocache resolves remote audio sources (http, https and s3 URLs)
+// to local files, keeping downloads in a size-bounded on-disk LRU cache so
+// prompts can be managed centrally and still played from local disk.
+package audiocache
+
+import (
+	"container/list"
+	"context"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+// metaSuffix is appended to cached audio file names for their metadata sidecar
+const metaSuffix = ".json"
+
+// ErrChecksumMismatch is returned when downloaded audio fails validation.
+var ErrChecksumMismatch = errors.New("audio checksum mismatch")
+
+// Config holds audio cache configuration.
+type Config struct {
+	// Dir is where downloaded audio is stored.
+	Dir string
+
+	// MaxBytes bounds the total size of cached files.
+	MaxBytes int64
+
+	// TTL is how long a download is used before it is revalidated with the
+	// origin. Sources pinned with a checksum fragment never expire.
+	TTL time.Duration
+
+	// FetchTimeout bounds a single download.
+	FetchTimeout time.Duration
+
+	// S3 configures access to s3:// sources.
+	S3 s3.Config
+}
+
+// Stats holds cache statistics.
+type Stats struct {
+	Entries int
+	Bytes   int64
+	Hits    int64
+	Misses  int64
+}
+
+// Cache resolves audio sources to local file paths.
+type Cache struct {
+	cfg   Config
+	http  *http.Client
+	s3    *s3.Client
+	group singleflight.Group
+
+	mu      sync.Mutex
+	bytes   int64
+	order   *list.List               // front = most recently used
+	entries map[string]*list.Element // key -> element holding *entry
+	hits    int64
+	misses  int64
+}
+
+// entry is the metadata stored next to each cached file.
+type entry struct {
+	Key          string    `json:"-"`
+	Source       string    `json:"source"`
+	ETag         string    `json:"etag,omitempty"`
+	LastModified string    `json:"last_modified,omitempty"`
+	SHA256       string    `json:"sha256"`
+	MD5          string    `json:"md5"`
+	Size         int64     `json:"size"`
+	Fetched      time.Time `json:"fetched"`
+}
+
+// New creates a cache, loading any entries left in Dir by a previous run.
+func New(cfg Config) (*Cache, error) {
+	if cfg.Dir == "" {
+		return nil, fmt.Errorf("audio cache directory required")
+	}
+	if err := os.MkdirAll(cfg.Dir, 0o755); err != nil {
+		return nil, fmt.Errorf("create audio cache directory: %w", err)
+	}
+
+	client := &http.Client{Timeout: cfg.FetchTimeout}
+	c := &Cache{
+		cfg:     cfg,
+		http:    client,
+		s3:      s3.New(cfg.S3, client),
+		order:   list.New(),
+		entries: make(map[string]*list.Element),
+	}
+	c.load()
+	return c, nil
+}
+
+// IsRemote reports whether a play source is a URL handled by the cache.
+func IsRemote(source string) bool {
+	return strings.HasPrefix(source, "http://") ||
+		strings.HasPrefix(source, "https://") ||
+		strings.HasPrefix(source, "s3://")
+}
+
+// Resolve returns a local path for the given source. Local paths are returned
+// unchanged; remote sources are downloaded on first use and served from disk
+// afterwards.
+//
+// A "#sha256=<hex>" or "#md5=<hex>" fragment pins the expected content.
+// Without one, downloads are checked against Content-MD5 or the S3 ETag
+// when the origin provides them.
+func (c *Cache) Resolve(ctx context.Context, source string) (string, error) {
+	if !IsRemote(source) {
+		return source, nil
+	}
+
+	src, sum, err := parseSource(source)
+	if err != nil {
+		return "", err
+	}
+	key := cacheKey(src)
+
+	if e, ok := c.lookup(key); ok {
+		fresh := c.cfg.TTL <= 0 || time.Since(e.Fetched) < c.cfg.TTL
+		if sum.matches(e) || (sum.empty() && fresh) {
+			return c.path(key), nil
+		}
+	}
+
+	// Collapse concurrent fetches of the same source; the download must not
+	// be tied to the first caller's context
+	ch := c.group.DoChan(key, func() (interface{}, error) {
+		fetchCtx, cancel := context.WithTimeout(context.Background(), c.fetchTimeout())
+		defer cancel()
+		return nil, c.fetch(fetchCtx, key, src, sum)
+	})
+
+	select {
+	case res := <-ch:
+		if res.Err != nil {
+			return "", res.Err
+		}
+		return c.path(key), nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Stats returns a snapshot of cache statistics.
+func (c *Cache) Stats() Stats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Stats{
+		Entries: len(c.entries),
+		Bytes:   c.bytes,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
+
+// lookup returns a copy of the entry for key and marks it recently used.
+func (c *Cache) lookup(key string) (entry, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return entry{}, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return *elem.Value.(*entry), true
+}
+
+// fetch downloads src into the cache, revalidating an existing entry when possible.
+func (c *Cache) fetch(ctx context.Context, key, src string, sum checksum) error {
+	header := http.Header{}
+	var stale *entry
+	if e, ok := c.lookup(key); ok && sum.empty() {
+		stale = &e
+		if e.ETag != "" {
+			header.Set("If-None-Match", e.ETag)
+		}
+		if e.LastModified != "" {
+			header.Set("If-Modified-Since", e.LastModified)
+		}
+	}
+
+	resp, err := c.get(ctx, src, header)
+	if err != nil {
+		if stale != nil {
+			// Keep playing the last good copy while the origin is unreachable
+			slog.Warn("[AudioCache] Revalidation failed, using cached copy", "source", src, "error", err)
+			return nil
+		}
+		return fmt.Errorf("fetch %s: %w", src, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotModified && stale != nil {
+		c.touch(key)
+		return nil
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("fetch %s: %s", src, resp.Status)
+	}
+	if resp.ContentLength > c.cfg.MaxBytes {
+		return fmt.Errorf("fetch %s: %d bytes exceeds cache size", src, resp.ContentLength)
+	}
+
+	tmp, err := os.CreateTemp(c.cfg.Dir, "download-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	shaHash := sha256.New()
+	md5Hash := md5.New()
+	size, err := io.Copy(io.MultiWriter(tmp, shaHash, md5Hash), io.LimitReader(resp.Body, c.cfg.MaxBytes+1))
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("fetch %s: %w", src, err)
+	}
+	if size > c.cfg.MaxBytes {
+		return fmt.Errorf("fetch %s: exceeds cache size", src)
+	}
+
+	digest := hex.EncodeToString(shaHash.Sum(nil))
+	md5Hex := hex.EncodeToString(md5Hash.Sum(nil))
+	if err := verify(sum, resp, digest, md5Hex); err != nil {
+		return fmt.Errorf("fetch %s: %w", src, err)
+	}
+
+	if err := os.Rename(tmp.Name(), c.path(key)); err != nil {
+		return err
+	}
+
+	e := &entry{
+		Key:          key,
+		Source:       src,
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+		SHA256:       digest,
+		MD5:          md5Hex,
+		Size:         size,
+		Fetched:      time.Now(),
+	}
+	if meta, err := json.Marshal(e); err == nil {
+		_ = os.WriteFile(c.path(key)+metaSuffix, meta, 0o644)
+	}
+	c.add(e)
+
+	slog.Info("[AudioCache] Downloaded audio", "source", src, "bytes", size)
+	return nil
+}
+
+// get issues the request for an http(s) or s3 source.
+func (c *Cache) get(ctx context.Context, src string, header http.Header) (*http.Response, error) {
+	if strings.HasPrefix(src, "s3://") {
+		bucket, key, err := s3.ParseURL(src)
+		if err != nil {
+			return nil, err
+		}
+		return c.s3.Get(ctx, bucket, key, header)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, src, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header = header
+	return c.http.Do(req)
+}
+
+// verify checks a download against the pinned checksum, or failing that
+// against Content-MD5 or a single-part S3 ETag.
+func verify(sum checksum, resp *http.Response, sha256Hex, md5Hex string) error {
+	switch sum.algo {
+	case "sha256":
+		if sum.value != sha256Hex {
+			return ErrChecksumMismatch
+		}
+		return nil
+	case "md5":
+		if sum.value != md5Hex {
+			return ErrChecksumMismatch
+		}
+		return nil
+	}
+
+	if v := resp.Header.Get("Content-MD5"); v != "" {
+		want, err := base64.StdEncoding.DecodeString(v)
+		if err == nil && hex.EncodeToString(want) != md5Hex {
+			return ErrChecksumMismatch
+		}
+		return nil
+	}
+
+	// S3 ETags are the MD5 of the object unless it was a multipart upload ("<md5>-<parts>")
+	if resp.Header.Get("X-Amz-Request-Id") != "" {
+		etag := strings.Trim(resp.Header.Get("ETag"), `"`)
+		if len(etag) == 32 && !strings.Contains(etag, "-") && etag != md5Hex {
+			return ErrChecksumMismatch
+		}
+	}
+	return nil
+}
+
+// add inserts or replaces an entry, evicting least recently used files to
+// stay within budget.
+func (c *Cache) add(e *entry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[e.Key]; ok {
+		c.bytes -= elem.Value.(*entry).Size
+		elem.Value = e
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[e.Key] = c.order.PushFront(e)
+	}
+	c.bytes += e.Size
+
+	for c.bytes > c.cfg.MaxBytes && c.order.Len() > 1 {
+		oldest := c.order.Back()
+		old := oldest.Value.(*entry)
+		c.order.Remove(oldest)
+		delete(c.entries, old.Key)
+		c.bytes -= old.Size
+		_ = os.Remove(c.path(old.Key))
+		_ = os.Remove(c.path(old.Key) + metaSuffix)
+		slog.Debug("[AudioCache] Evicted audio", "source", old.Source)
+	}
+}
+
+// touch restarts an entry's TTL after successful revalidation.
+func (c *Cache) touch(key string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		return
+	}
+	e := *elem.Value.(*entry)
+	e.Fetched = time.Now()
+	elem.Value = &e
+	if meta, err := json.Marshal(&e); err == nil {
+		_ = os.WriteFile(c.path(key)+metaSuffix, meta, 0o644)
+	}
+}
+
+// load rebuilds the index from metadata sidecars, oldest first so the most
+// recently fetched files end up at the front of the LRU list.
+func (c *Cache) load() {
+	paths, _ := filepath.Glob(filepath.Join(c.cfg.Dir, "*"+metaSuffix))
+
+	var loaded []*entry
+	for _, metaPath := range paths {
+		key := strings.TrimSuffix(filepath.Base(metaPath), ".wav"+metaSuffix)
+		data, err := os.ReadFile(metaPath)
+		if err != nil {
+			continue
+		}
+		var e entry
+		info, statErr := os.Stat(c.path(key))
+		if json.Unmarshal(data, &e) != nil || statErr != nil || info.Size() != e.Size {
+			_ = os.Remove(metaPath)
+			_ = os.Remove(c.path(key))
+			continue
+		}
+		e.Key = key
+		loaded = append(loaded, &e)
+	}
+
+	sort.Slice(loaded, func(i, j int) bool {
+		return loaded[i].Fetched.Before(loaded[j].Fetched)
+	})
+	for _, e := range loaded {
+		c.add(e)
+	}
+
+	if len(loaded) > 0 {
+		slog.Info("[AudioCache] Loaded cached audio", "entries", len(loaded), "dir", c.cfg.Dir)
+	}
+}
+
+// path returns the on-disk location of a cached file.
+func (c *Cache) path(key string) string {
+	return filepath.Join(c.cfg.Dir, key+".wav")
+}
+
+func (c *Cache) fetchTimeout() time.Duration {
+	if c.cfg.FetchTimeout > 0 {
+		return c.cfg.FetchTimeout
+	}
+	return 30 * time.Second
+}
+
+// checksum is an expected digest taken from a source URL fragment.
+type checksum struct {
+	algo  string
+	value string
+}
+
+func (s checksum) empty() bool {
+	return s.algo == ""
+}
+
+// matches reports whether a cached file satisfies the pinned checksum.
+func (s checksum) matches(e entry) bool {
+	switch s.algo {
+	case "sha256":
+		return s.value == e.SHA256
+	case "md5":
+		return s.value == e.MD5
+	}
+	return false
+}
+
+// parseSource strips the checksum fragment from a source URL.
+func parseSource(source string) (string, checksum, error) {
+	u, err := url.Parse(source)
+	if err != nil {
+		return "", checksum{}, fmt.Errorf("invalid audio source: %w", err)
+	}
+
+	var sum checksum
+	if u.Fragment != "" {
+		algo, value, ok := strings.Cut(u.Fragment, "=")
+		algo = strings.ToLower(algo)
+		if !ok || (algo != "sha256" && algo != "md5") {
+			return "", checksum{}, fmt.Errorf("unsupported checksum fragment: #%s", u.Fragment)
+		}
+		sum = checksum{algo: algo, value: strings.ToLower(value)}
+		u.Fragment = ""
+	}
+	return u.String(), sum, nil
+}
+
+// cacheKey derives a file name from a source URL.
+func cacheKey(src string) string {
+	sum := sha256.Sum256([]byte(src))
+	return hex.EncodeToString(sum[:16])
+}
@@ -0,0 +1,101 @@
+package bridge
+
+import (
+	"net"
+
+	"golang.org/x/net/ipv4"
+)
+
+// batchSize is how many datagrams one recvmmsg/sendmmsg call moves. A
+// single call leg sends 50 packets/s, so batches stay small on an idle
+// node; under load, packets queue between reads and each syscall carries
+// several of them.
+const batchSize = 16
+
+// maxDatagram is the size of each receive buffer (one Ethernet MTU).
+const maxDatagram = 1500
+
+// batchReader receives datagrams in batches: recvmmsg on Linux, one
+// datagram per call elsewhere (golang.org/x/net/ipv4). Its buffers are
+// allocated once and reused, so received data is only valid until the
+// next read. The ipv4 wrapper also serves the dual-stack sockets bridges
+// bind; it only adds the batch calls.
+type batchReader struct {
+	pc   *ipv4.PacketConn
+	msgs []ipv4.Message
+}
+
+func newBatchReader(conn *net.UDPConn) *batchReader {
+	r := &batchReader{
+		pc:   ipv4.NewPacketConn(conn),
+		msgs: make([]ipv4.Message, batchSize),
+	}
+	bufs := make([]byte, batchSize*maxDatagram)
+	for i := range r.msgs {
+		r.msgs[i].Buffers = [][]byte{bufs[i*maxDatagram : (i+1)*maxDatagram]}
+	}
+	return r
+}
+
+// read blocks until at least one datagram arrives and returns those
+// received. The data of each is msg.Buffers[0][:msg.N].
+func (r *batchReader) read() ([]ipv4.Message, error) {
+	n, err := r.pc.ReadBatch(r.msgs, 0)
+	if err != nil {
+		return nil, err
+	}
+	return r.msgs[:n], nil
+}
+
+// batchWriter sends queued datagrams in batches (sendmmsg on Linux). A
+// writer belongs to one goroutine; several may share a socket.
+type batchWriter struct {
+	pc   *ipv4.PacketConn
+	msgs []ipv4.Message
+	n    int // Queued messages
+}
+
+func newBatchWriter(conn *net.UDPConn) *batchWriter {
+	w := &batchWriter{
+		pc:   ipv4.NewPacketConn(conn),
+		msgs: make([]ipv4.Message, batchSize),
+	}
+	for i := range w.msgs {
+		w.msgs[i].Buffers = make([][]byte, 1)
+	}
+	return w
+}
+
+// queue adds a datagram to the next flush. data must stay unchanged
+// until then.
+func (w *batchWriter) queue(data []byte, addr net.Addr) {
+	w.msgs[w.n].Buffers[0] = data
+	w.msgs[w.n].Addr = addr
+	w.n++
+}
+
+// flush sends the queued datagrams and returns how many were sent and
+// their size. On error the rest of the batch is dropped.
+func (w *batchWriter) flush() (int, int64, error) {
+	var sent int
+	var err error
+	for sent < w.n {
+		var n int
+		n, err = w.pc.WriteBatch(w.msgs[sent:w.n], 0)
+		if err != nil {
+			break
+		}
+		sent += n
+	}
+
+	var bytes int64
+	for i := range w.msgs[:sent] {
+		bytes += int64(len(w.msgs[i].Buffers[0]))
+	}
+	for i := range w.msgs[:w.n] {
+		w.msgs[i].Buffers[0] = nil
+		w.msgs[i].Addr = nil
+	}
+	w.n = 0
+	return sent, bytes, err
+}
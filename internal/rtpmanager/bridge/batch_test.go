@@ -0,0 +1,47 @@
+package bridge
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// Bridges bind dual-stack sockets and send to IPv4 and IPv6 peers alike.
+func TestBatchRoundTrip(t *testing.T) {
+	recv, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer recv.Close()
+	send, err := net.ListenUDP("udp", &net.UDPAddr{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer send.Close()
+
+	dest := &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1), Port: recv.LocalAddr().(*net.UDPAddr).Port}
+	w := newBatchWriter(send)
+	for _, p := range []string{"one", "two", "three"} {
+		w.queue([]byte(p), dest)
+	}
+	sent, bytes, err := w.flush()
+	if err != nil || sent != 3 || bytes != 11 {
+		t.Fatalf("flush() = %d, %d, %v; want 3, 11, nil", sent, bytes, err)
+	}
+
+	r := newBatchReader(recv)
+	_ = recv.SetReadDeadline(time.Now().Add(2 * time.Second))
+	var got []string
+	for len(got) < 3 {
+		msgs, err := r.read()
+		if err != nil {
+			t.Fatalf("read: %v (got %v)", err, got)
+		}
+		for _, msg := range msgs {
+			got = append(got, string(msg.Buffers[0][:msg.N]))
+		}
+	}
+	if got[0] != "one" || got[1] != "two" || got[2] != "three" {
+		t.Fatalf("received %q", got)
+	}
+}
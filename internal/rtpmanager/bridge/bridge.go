@@ -7,6 +7,7 @@ import (
 	"net"
 	"sync"
 	"sync/atomic"
+	"time"
 
 	"github.com/google/uuid"
 )
@@ -18,7 +19,8 @@ type Endpoint struct {
 	LocalPort  int
 	RemoteAddr string
 	RemotePort int
-	conn       *net.UDPConn
+	conn       *net.UDPConn   // Sends, and receives with the first worker
+	conns      []*net.UDPConn // Receiving sockets, one per worker (conn first)
 }
 
 // Bridge represents a bidirectional RTP relay between two sessions.
@@ -31,6 +33,22 @@ type Bridge struct {
 	cancel context.CancelFunc
 	active atomic.Bool
 
+	// Jitter buffers per direction (nil when buffering is disabled)
+	jitterA2B *jitterBuffer // packets received from A, played out to B
+	jitterB2A *jitterBuffer // packets received from B, played out to A
+
+	// Forwarding workers per direction (nil with one worker: readers
+	// forward themselves)
+	fanA2B *fanOut
+	fanB2A *fanOut
+
+	// Last RTP arrival per side (unix nanos), for inactivity detection
+	lastRecvA atomic.Int64
+	lastRecvB atomic.Int64
+
+	// Media taps for capture (see tap.go)
+	tapRegistry
+
 	// Statistics
 	packetsA2B atomic.Int64
 	packetsB2A atomic.Int64
@@ -44,23 +62,40 @@ type Stats struct {
 	PacketsB2A int64
 	BytesA2B   int64
 	BytesB2A   int64
+
+	// Jitter buffer stats (nil when buffering is disabled)
+	JitterA2B *JitterStats
+	JitterB2A *JitterStats
 }
 
 // Manager manages active bridges.
 type Manager struct {
 	bridges    map[string]*Bridge // bridgeID -> Bridge
 	sessionMap map[string]string  // sessionID -> bridgeID
+	jitterCfg  JitterConfig
+	workerCfg  WorkerConfig
 	mu         sync.RWMutex
 }
 
 // NewManager creates a new bridge manager.
-func NewManager() *Manager {
+// If jitterCfg.Enabled is set, every bridge buffers media in both directions.
+func NewManager(jitterCfg JitterConfig) *Manager {
 	return &Manager{
 		bridges:    make(map[string]*Bridge),
 		sessionMap: make(map[string]string),
+		jitterCfg:  jitterCfg.withDefaults(),
+		workerCfg:  WorkerConfig{}.withDefaults(),
 	}
 }
 
+// SetWorkers sets how many goroutines receive on each bridged port. It
+// applies to bridges created afterwards.
+func (m *Manager) SetWorkers(cfg WorkerConfig) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.workerCfg = cfg.withDefaults()
+}
+
 // CreateBridge establishes bidirectional RTP forwarding between two sessions.
 func (m *Manager) CreateBridge(endpointA, endpointB *Endpoint) (string, error) {
 	m.mu.Lock()
@@ -92,18 +127,43 @@ func (m *Manager) CreateBridge(endpointA, endpointB *Endpoint) (string, error) {
 		ctx:      ctx,
 		cancel:   cancel,
 	}
+	bridge.taps = make(map[uint64]*Tap)
+
+	if m.jitterCfg.Enabled {
+		bridge.jitterA2B = newJitterBuffer(endpointA.SessionID, m.jitterCfg)
+		bridge.jitterB2A = newJitterBuffer(endpointB.SessionID, m.jitterCfg)
+	}
 
 	// Bind UDP sockets for each endpoint
-	if err := bridge.bindSockets(); err != nil {
+	if err := bridge.bindSockets(m.workerCfg.Workers); err != nil {
 		cancel()
 		return "", fmt.Errorf("failed to bind sockets: %w", err)
 	}
 
 	bridge.active.Store(true)
-
-	// Start relay goroutines
-	go bridge.relayAtoB()
-	go bridge.relayBtoA()
+	now := time.Now().UnixNano()
+	bridge.lastRecvA.Store(now)
+	bridge.lastRecvB.Store(now)
+
+	// Start forwarding workers, then relay goroutines, one per receiving
+	// socket
+	if workers := m.workerCfg.Workers; workers > 1 {
+		bridge.fanA2B, bridge.fanB2A = newFanOut(workers), newFanOut(workers)
+		for i := range workers {
+			go bridge.forwardWorker("A->B", bridge.fanA2B.queues[i], bridge.SessionB, true, bridge.jitterA2B, &bridge.packetsA2B, &bridge.bytesA2B, m.workerCfg.PinCPUs)
+			go bridge.forwardWorker("B->A", bridge.fanB2A.queues[i], bridge.SessionA, false, bridge.jitterB2A, &bridge.packetsB2A, &bridge.bytesB2A, m.workerCfg.PinCPUs)
+		}
+	}
+	for _, conn := range endpointA.conns {
+		go bridge.relayAtoB(conn, m.workerCfg.PinCPUs)
+	}
+	for _, conn := range endpointB.conns {
+		go bridge.relayBtoA(conn, m.workerCfg.PinCPUs)
+	}
+	if bridge.jitterA2B != nil {
+		go bridge.playout(bridge.jitterA2B, bridge.SessionB, &bridge.packetsA2B, &bridge.bytesA2B)
+		go bridge.playout(bridge.jitterB2A, bridge.SessionA, &bridge.packetsB2A, &bridge.bytesB2A)
+	}
 
 	m.bridges[bridgeID] = bridge
 	m.sessionMap[endpointA.SessionID] = bridgeID
@@ -117,14 +177,17 @@ func (m *Manager) CreateBridge(endpointA, endpointB *Endpoint) (string, error) {
 		"session_b", endpointB.SessionID,
 		"session_b_local", fmt.Sprintf("%s:%d", endpointB.LocalAddr, endpointB.LocalPort),
 		"session_b_remote", fmt.Sprintf("%s:%d", endpointB.RemoteAddr, endpointB.RemotePort),
+		"jitter_buffer", m.jitterCfg.Enabled,
+		"sockets", len(endpointA.conns),
+		"workers", m.workerCfg.Workers,
 	)
 
 	return bridgeID, nil
 }
 
-// bindSockets binds UDP sockets for both endpoints.
+// bindSockets binds UDP sockets for both endpoints, workers per port.
 // Note: These sockets listen on the same ports allocated for the sessions.
-func (b *Bridge) bindSockets() error {
+func (b *Bridge) bindSockets(workers int) error {
 	// Validate remote endpoints before binding - ParseIP returns nil for invalid IPs
 	remoteIPA := net.ParseIP(b.SessionA.RemoteAddr)
 	if remoteIPA == nil {
@@ -135,148 +198,247 @@ func (b *Bridge) bindSockets() error {
 		return fmt.Errorf("session B has invalid remote IP: %q", b.SessionB.RemoteAddr)
 	}
 
-	// Bind A's local port (receives packets from A's remote party)
-	addrA := &net.UDPAddr{Port: b.SessionA.LocalPort, IP: net.IPv4zero}
-	connA, err := net.ListenUDP("udp", addrA)
+	// Bind A's local port (receives packets from A's remote party).
+	// Sockets are dual-stack, so either leg may be IPv4 or IPv6.
+	connsA, err := listenWorkers(b.SessionA.LocalPort, workers)
 	if err != nil {
 		return fmt.Errorf("bind A port %d: %w", b.SessionA.LocalPort, err)
 	}
-	b.SessionA.conn = connA
+	b.SessionA.conn, b.SessionA.conns = connsA[0], connsA
 
 	// Bind B's local port (receives packets from B's remote party)
-	addrB := &net.UDPAddr{Port: b.SessionB.LocalPort, IP: net.IPv4zero}
-	connB, err := net.ListenUDP("udp", addrB)
+	connsB, err := listenWorkers(b.SessionB.LocalPort, workers)
 	if err != nil {
-		_ = connA.Close()
+		closeAll(connsA)
 		return fmt.Errorf("bind B port %d: %w", b.SessionB.LocalPort, err)
 	}
-	b.SessionB.conn = connB
+	b.SessionB.conn, b.SessionB.conns = connsB[0], connsB
 
 	return nil
 }
 
-// relayAtoB forwards packets from A's remote party to B's remote party.
-func (b *Bridge) relayAtoB() {
-	buf := make([]byte, 1500) // MTU-sized buffer
+// relayAtoB forwards packets received on conn, one of A's sockets, to B's
+// remote party.
+func (b *Bridge) relayAtoB(conn *net.UDPConn, pin bool) {
+	b.relay("A->B", conn, b.SessionA, b.SessionB, pin, true, &b.lastRecvA, b.jitterA2B, b.fanA2B, &b.packetsA2B, &b.bytesA2B)
+}
+
+// relayBtoA forwards packets received on conn, one of B's sockets, to A's
+// remote party.
+func (b *Bridge) relayBtoA(conn *net.UDPConn, pin bool) {
+	b.relay("B->A", conn, b.SessionB, b.SessionA, pin, false, &b.lastRecvB, b.jitterB2A, b.fanB2A, &b.packetsB2A, &b.bytesB2A)
+}
+
+// relay forwards packets received on conn (a socket of src's local port)
+// to dst's remote party, sending from dst's socket so the source is dst's
+// local port. Packets are read and written in batches (see batch.go); RTP
+// goes through the jitter buffer jb instead when buffering is enabled.
+// With fan, packets are handed to its forwarding workers instead of being
+// forwarded here. With pin, the goroutine runs on a thread bound to one
+// CPU.
+func (b *Bridge) relay(dir string, conn *net.UDPConn, src, dst *Endpoint, pin, fromA bool, lastRecv *atomic.Int64, jb *jitterBuffer, fan *fanOut, packets, bytes *atomic.Int64) {
+	if pin {
+		pinWorker()
+	}
 
 	// Parse destination IP once at start (validated in bindSockets)
-	destIP := net.ParseIP(b.SessionB.RemoteAddr)
 	destAddr := &net.UDPAddr{
-		IP:   destIP,
-		Port: b.SessionB.RemotePort,
+		IP:   net.ParseIP(dst.RemoteAddr),
+		Port: dst.RemotePort,
 	}
+	reader := newBatchReader(conn)
+	writer := newBatchWriter(dst.conn)
 
-	slog.Debug("[Bridge] Relay A->B started",
+	slog.Debug("[Bridge] Relay started",
 		"bridge_id", b.ID,
-		"read_from", fmt.Sprintf("0.0.0.0:%d", b.SessionA.LocalPort),
+		"direction", dir,
+		"read_from", fmt.Sprintf("0.0.0.0:%d", src.LocalPort),
 		"write_to", destAddr.String(),
 	)
 
+	first := true
 	for b.active.Load() {
 		select {
 		case <-b.ctx.Done():
-			slog.Debug("[Bridge] Relay A->B context done", "bridge_id", b.ID)
+			slog.Debug("[Bridge] Relay context done", "bridge_id", b.ID, "direction", dir)
 			return
 		default:
 		}
 
-		// Read from A's local port (packets from A's remote party)
-		n, srcAddr, err := b.SessionA.conn.ReadFromUDP(buf)
+		// Read whatever has arrived on src's local port, up to a batch
+		msgs, err := reader.read()
 		if err != nil {
 			if b.ctx.Err() != nil {
 				return // Context canceled
 			}
-			slog.Debug("[Bridge] Read error A->B", "bridge_id", b.ID, "error", err)
+			slog.Debug("[Bridge] Read error", "bridge_id", b.ID, "direction", dir, "error", err)
 			continue
 		}
+		now := time.Now()
+		lastRecv.Store(now.UnixNano())
+
+		for _, msg := range msgs {
+			data := msg.Buffers[0][:msg.N]
+
+			// Log first packet for debugging
+			if first {
+				first = false
+				slog.Info("[Bridge] First packet",
+					"bridge_id", b.ID,
+					"direction", dir,
+					"from", msg.Addr.String(),
+					"to", destAddr.String(),
+					"size", msg.N,
+				)
+			}
 
-		// Log first packet for debugging
-		count := b.packetsA2B.Load()
-		if count == 0 {
-			slog.Info("[Bridge] First packet A->B",
-				"bridge_id", b.ID,
-				"from", srcAddr.String(),
-				"to", destAddr.String(),
-				"size", n,
-			)
+			if fan != nil {
+				fan.push(b.ctx, data, now)
+				continue
+			}
+			b.forward(fromA, data, now, jb, writer, destAddr)
 		}
-
-		// Forward to B's remote party using B's socket (so source is B's local port)
-		if _, err := b.SessionB.conn.WriteToUDP(buf[:n], destAddr); err != nil {
-			slog.Debug("[Bridge] Write error A->B", "bridge_id", b.ID, "error", err)
+		if fan != nil {
 			continue
 		}
 
-		b.packetsA2B.Add(1)
-		b.bytesA2B.Add(int64(n))
+		sent, n, err := writer.flush()
+		if err != nil {
+			slog.Debug("[Bridge] Write error", "bridge_id", b.ID, "direction", dir, "error", err)
+		}
+		packets.Add(int64(sent))
+		bytes.Add(n)
 	}
 }
 
-// relayBtoA forwards packets from B's remote party to A's remote party.
-func (b *Bridge) relayBtoA() {
-	buf := make([]byte, 1500)
-
-	// Parse destination IP once at start (validated in bindSockets)
-	destIP := net.ParseIP(b.SessionA.RemoteAddr)
-	destAddr := &net.UDPAddr{
-		IP:   destIP,
-		Port: b.SessionA.RemotePort,
+// forward delivers a received packet to the taps and queues it on writer,
+// or hands RTP to the jitter buffer jb, whose playout forwards it when
+// due. data is copied by the jitter buffer but must stay unchanged until
+// writer is flushed.
+func (b *Bridge) forward(fromA bool, data []byte, arrival time.Time, jb *jitterBuffer, writer *batchWriter, destAddr *net.UDPAddr) {
+	b.deliverTaps(fromA, data)
+	if jb != nil && isBufferable(data) {
+		jb.Push(data, arrival)
+		return
 	}
+	writer.queue(data, destAddr)
+}
 
-	slog.Debug("[Bridge] Relay B->A started",
-		"bridge_id", b.ID,
-		"read_from", fmt.Sprintf("0.0.0.0:%d", b.SessionB.LocalPort),
-		"write_to", destAddr.String(),
-	)
+// forwardWorker forwards the packets a fanOut queues for it to dst's
+// remote party, a batch at a time, until the bridge stops.
+func (b *Bridge) forwardWorker(dir string, queue <-chan *fanPacket, dst *Endpoint, fromA bool, jb *jitterBuffer, packets, bytes *atomic.Int64, pin bool) {
+	if pin {
+		pinWorker()
+	}
 
-	for b.active.Load() {
+	destAddr := &net.UDPAddr{
+		IP:   net.ParseIP(dst.RemoteAddr),
+		Port: dst.RemotePort,
+	}
+	writer := newBatchWriter(dst.conn)
+	batch := make([]*fanPacket, 0, batchSize)
+	for {
 		select {
 		case <-b.ctx.Done():
-			slog.Debug("[Bridge] Relay B->A context done", "bridge_id", b.ID)
 			return
-		default:
+		case p := <-queue:
+			batch = append(batch, p)
+		}
+		// Take whatever else is queued, up to a batch
+	more:
+		for len(batch) < batchSize {
+			select {
+			case p := <-queue:
+				batch = append(batch, p)
+			default:
+				break more
+			}
 		}
 
-		// Read from B's local port (packets from B's remote party)
-		n, srcAddr, err := b.SessionB.conn.ReadFromUDP(buf)
+		for _, p := range batch {
+			b.forward(fromA, p.data, p.arrival, jb, writer, destAddr)
+		}
+		sent, n, err := writer.flush()
 		if err != nil {
-			if b.ctx.Err() != nil {
-				return
-			}
-			slog.Debug("[Bridge] Read error B->A", "bridge_id", b.ID, "error", err)
-			continue
+			slog.Debug("[Bridge] Write error", "bridge_id", b.ID, "direction", dir, "error", err)
 		}
+		packets.Add(int64(sent))
+		bytes.Add(n)
 
-		// Log first packet for debugging
-		count := b.packetsB2A.Load()
-		if count == 0 {
-			slog.Info("[Bridge] First packet B->A",
-				"bridge_id", b.ID,
-				"from", srcAddr.String(),
-				"to", destAddr.String(),
-				"size", n,
-			)
+		for i, p := range batch {
+			fanPacketPool.Put(p)
+			batch[i] = nil
+		}
+		batch = batch[:0]
+	}
+}
+
+// playout forwards buffered packets to the destination endpoint as they become due.
+// The destination socket is used for sending so the source is its local port.
+func (b *Bridge) playout(jb *jitterBuffer, dest *Endpoint, packets, bytes *atomic.Int64) {
+	destAddr := &net.UDPAddr{
+		IP:   net.ParseIP(dest.RemoteAddr),
+		Port: dest.RemotePort,
+	}
+
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+
+	for {
+		now := time.Now()
+		for {
+			pkt, ok := jb.Pop(now)
+			if !ok {
+				break
+			}
+			_, err := dest.conn.WriteToUDP(pkt.data, destAddr)
+			n := len(pkt.data)
+			pkt.release()
+			if err != nil {
+				slog.Debug("[Bridge] Playout write error", "bridge_id", b.ID, "session_id", jb.sessionID, "error", err)
+				continue
+			}
+			packets.Add(1)
+			bytes.Add(int64(n))
 		}
 
-		// Forward to A's remote party using A's socket (so source is A's local port)
-		if _, err := b.SessionA.conn.WriteToUDP(buf[:n], destAddr); err != nil {
-			slog.Debug("[Bridge] Write error B->A", "bridge_id", b.ID, "error", err)
-			continue
+		// Sleep until the head packet is due or a new packet arrives
+		wait, ok := jb.NextDue(time.Now())
+		if !ok {
+			wait = time.Hour
+		}
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
 		}
+		timer.Reset(wait)
 
-		b.packetsB2A.Add(1)
-		b.bytesB2A.Add(int64(n))
+		select {
+		case <-b.ctx.Done():
+			return
+		case <-jb.notify:
+		case <-timer.C:
+		}
 	}
 }
 
 // GetStats returns the current statistics for a bridge.
 func (b *Bridge) GetStats() Stats {
-	return Stats{
+	stats := Stats{
 		PacketsA2B: b.packetsA2B.Load(),
 		PacketsB2A: b.packetsB2A.Load(),
 		BytesA2B:   b.bytesA2B.Load(),
 		BytesB2A:   b.bytesB2A.Load(),
 	}
+	if b.jitterA2B != nil {
+		a2b := b.jitterA2B.Stats()
+		b2a := b.jitterB2A.Stats()
+		stats.JitterA2B = &a2b
+		stats.JitterB2A = &b2a
+	}
+	return stats
 }
 
 // DestroyBridge tears down an active bridge.
@@ -317,12 +479,8 @@ func (m *Manager) destroyBridgeLocked(bridge *Bridge) {
 	bridge.active.Store(false)
 	bridge.cancel()
 
-	if bridge.SessionA.conn != nil {
-		_ = bridge.SessionA.conn.Close()
-	}
-	if bridge.SessionB.conn != nil {
-		_ = bridge.SessionB.conn.Close()
-	}
+	closeAll(bridge.SessionA.conns)
+	closeAll(bridge.SessionB.conns)
 
 	delete(m.sessionMap, bridge.SessionA.SessionID)
 	delete(m.sessionMap, bridge.SessionB.SessionID)
@@ -336,6 +494,19 @@ func (m *Manager) destroyBridgeLocked(bridge *Bridge) {
 		"bytes_a2b", stats.BytesA2B,
 		"bytes_b2a", stats.BytesB2A,
 	)
+	if stats.JitterA2B != nil {
+		for _, js := range []*JitterStats{stats.JitterA2B, stats.JitterB2A} {
+			slog.Info("[Bridge] Jitter buffer stats",
+				"bridge_id", bridge.ID,
+				"session_id", js.SessionID,
+				"max_depth", js.MaxDepth,
+				"target_delay", js.TargetDelay,
+				"jitter", js.Jitter,
+				"late", js.Late,
+				"overflow", js.Overflow,
+			)
+		}
+	}
 }
 
 // GetBridge returns a bridge by ID.
@@ -346,6 +517,17 @@ func (m *Manager) GetBridge(bridgeID string) (*Bridge, bool) {
 	return bridge, ok
 }
 
+// List returns the active bridges.
+func (m *Manager) List() []*Bridge {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	bridges := make([]*Bridge, 0, len(m.bridges))
+	for _, bridge := range m.bridges {
+		bridges = append(bridges, bridge)
+	}
+	return bridges
+}
+
 // GetBridgeBySession returns the bridge containing a session.
 func (m *Manager) GetBridgeBySession(sessionID string) (*Bridge, bool) {
 	m.mu.RLock()
@@ -359,6 +541,47 @@ func (m *Manager) GetBridgeBySession(sessionID string) (*Bridge, bool) {
 	return bridge, ok
 }
 
+// AllJitterStats returns the jitter buffer stats for media received on
+// every bridged session. Empty when buffering is disabled.
+func (m *Manager) AllJitterStats() []JitterStats {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var stats []JitterStats
+	for _, bridge := range m.bridges {
+		if bridge.jitterA2B == nil {
+			continue
+		}
+		stats = append(stats, bridge.jitterA2B.Stats(), bridge.jitterB2A.Stats())
+	}
+	return stats
+}
+
+// IdleSession describes a bridged session that has stopped receiving RTP.
+type IdleSession struct {
+	SessionID string
+	BridgeID  string
+	Idle      time.Duration
+}
+
+// IdleSessions returns bridged sessions that have received no RTP for at least timeout.
+func (m *Manager) IdleSessions(timeout time.Duration) []IdleSession {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	now := time.Now()
+	var idle []IdleSession
+	for _, bridge := range m.bridges {
+		if d := now.Sub(time.Unix(0, bridge.lastRecvA.Load())); d >= timeout {
+			idle = append(idle, IdleSession{SessionID: bridge.SessionA.SessionID, BridgeID: bridge.ID, Idle: d})
+		}
+		if d := now.Sub(time.Unix(0, bridge.lastRecvB.Load())); d >= timeout {
+			idle = append(idle, IdleSession{SessionID: bridge.SessionB.SessionID, BridgeID: bridge.ID, Idle: d})
+		}
+	}
+	return idle
+}
+
 // IsSessionBridged checks if a session is part of a bridge.
 func (m *Manager) IsSessionBridged(sessionID string) bool {
 	m.mu.RLock()
@@ -0,0 +1,342 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"sync"
+	"time"
+)
+
+// Jitter buffer defaults
+const (
+	// DefaultJitterMinDelay is the lowest playout delay the buffer adapts down to
+	DefaultJitterMinDelay = 20 * time.Millisecond
+	// DefaultJitterMaxDelay caps the playout delay on very bursty links
+	DefaultJitterMaxDelay = 200 * time.Millisecond
+	// DefaultJitterMaxPackets bounds buffer memory per direction
+	DefaultJitterMaxPackets = 50
+
+	// jitterClockRate is the RTP clock rate used for jitter estimation (PCMU/PCMA)
+	jitterClockRate = 8000
+	// jitterDelayFactor scales the interarrival jitter estimate into a target delay
+	jitterDelayFactor = 3
+	// jitterMaxMisorder is how far behind the last played sequence number a
+	// packet is taken as late rather than as a restarted stream (RFC 3550
+	// Appendix A.1)
+	jitterMaxMisorder = 100
+)
+
+// JitterConfig configures the jitter buffer in the bridge forwarding path.
+// A zero value disables buffering (straight relay).
+type JitterConfig struct {
+	// Enabled turns on buffering for bridged media.
+	Enabled bool
+
+	// MinDelay is the minimum playout delay.
+	// Default: 20ms
+	MinDelay time.Duration
+
+	// MaxDelay is the maximum playout delay.
+	// Default: 200ms
+	MaxDelay time.Duration
+
+	// MaxPackets is the maximum number of packets held per direction.
+	// Oldest packets are dropped when exceeded.
+	// Default: 50
+	MaxPackets int
+}
+
+// withDefaults returns a copy of the config with zero fields filled in.
+func (c JitterConfig) withDefaults() JitterConfig {
+	if c.MinDelay <= 0 {
+		c.MinDelay = DefaultJitterMinDelay
+	}
+	if c.MaxDelay <= 0 {
+		c.MaxDelay = DefaultJitterMaxDelay
+	}
+	if c.MaxDelay < c.MinDelay {
+		c.MaxDelay = c.MinDelay
+	}
+	if c.MaxPackets <= 0 {
+		c.MaxPackets = DefaultJitterMaxPackets
+	}
+	return c
+}
+
+// JitterStats holds jitter buffer statistics for one direction of a bridge.
+// The direction is identified by the session whose packets are being buffered.
+type JitterStats struct {
+	SessionID   string
+	Depth       int           // Packets currently buffered
+	MaxDepth    int           // Highest depth observed
+	TargetDelay time.Duration // Current adaptive playout delay
+	Jitter      time.Duration // RFC 3550 interarrival jitter estimate
+	Late        int64         // Packets dropped for arriving after their playout slot
+	Overflow    int64         // Packets dropped because the buffer was full
+	Duplicates  int64         // Duplicate packets dropped
+}
+
+// bufferedPacket is an RTP packet waiting for playout.
+type bufferedPacket struct {
+	seq  uint16
+	ssrc uint32
+	data []byte
+	due  time.Time // Playout time
+}
+
+// packetPool recycles buffered packets and their payload storage, which
+// would otherwise be allocated for every packet relayed through a buffer.
+var packetPool = sync.Pool{
+	New: func() any { return &bufferedPacket{data: make([]byte, 0, maxDatagram)} },
+}
+
+// release returns a packet to the pool once it has been sent or dropped.
+func (p *bufferedPacket) release() {
+	p.data = p.data[:0]
+	packetPool.Put(p)
+}
+
+// jitterBuffer reorders and delays RTP packets for one bridge direction.
+// Packets are played out on the sender's clock: each is due at the wall
+// time the stream's first packet was due, plus its RTP timestamp's offset
+// from that packet's, so bursts leave paced as they were sent. The delay
+// adapts to the observed interarrival jitter, clamped between MinDelay and
+// MaxDelay, whenever the schedule is rebased: on a new SSRC, when a packet
+// arrives after its playout time, or when the timestamps jump ahead.
+type jitterBuffer struct {
+	mu  sync.Mutex
+	cfg JitterConfig
+
+	sessionID string
+	packets   []*bufferedPacket // ordered by sequence number within a stream
+
+	// Playout schedule: baseTS is played at baseTime
+	ssrc     uint32
+	baseTS   uint32
+	baseTime time.Time
+	hasBase  bool
+
+	// Last packet played
+	lastSeq  uint16
+	lastSSRC uint32
+	released bool
+
+	// Jitter estimation (RFC 3550 Section 6.4.1)
+	jitter      float64 // seconds
+	lastTransit float64
+	hasTransit  bool
+	targetDelay time.Duration
+
+	// Statistics
+	maxDepth   int
+	late       int64
+	overflow   int64
+	duplicates int64
+
+	// notify wakes the playout goroutine when a packet is pushed
+	notify chan struct{}
+}
+
+// newJitterBuffer creates a jitter buffer for packets received on sessionID.
+func newJitterBuffer(sessionID string, cfg JitterConfig) *jitterBuffer {
+	cfg = cfg.withDefaults()
+	return &jitterBuffer{
+		cfg:         cfg,
+		sessionID:   sessionID,
+		packets:     make([]*bufferedPacket, 0, cfg.MaxPackets),
+		targetDelay: cfg.MinDelay,
+		notify:      make(chan struct{}, 1),
+	}
+}
+
+// isBufferable reports whether a datagram is an RTP packet that can be
+// reordered. RTCP (muxed on the same port per RFC 5761) and malformed
+// packets are passed through without buffering.
+func isBufferable(data []byte) bool {
+	if len(data) < 12 || data[0]>>6 != 2 {
+		return false
+	}
+	// RTCP packet types 200-204 occupy the marker+payload type byte
+	pt := data[1]
+	return pt < 200 || pt > 204
+}
+
+// seqLess reports whether a precedes b, accounting for 16-bit wraparound.
+func seqLess(a, b uint16) bool {
+	return a != b && b-a < 0x8000
+}
+
+// Push adds a packet to the buffer. The data is copied.
+// Returns false if the packet was dropped.
+func (jb *jitterBuffer) Push(data []byte, arrival time.Time) bool {
+	seq := binary.BigEndian.Uint16(data[2:4])
+	ts := binary.BigEndian.Uint32(data[4:8])
+	ssrc := binary.BigEndian.Uint32(data[8:12])
+
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	if ssrc != jb.ssrc {
+		// A new stream: its timestamps and jitter say nothing of the old one's
+		jb.ssrc = ssrc
+		jb.hasBase = false
+		jb.hasTransit = false
+	}
+	jb.updateJitter(ts, arrival)
+
+	// Drop packets whose playout slot has already passed; one far behind
+	// is a restarted stream
+	if jb.released && jb.lastSSRC == ssrc && !seqLess(jb.lastSeq, seq) {
+		if jb.lastSeq-seq <= jitterMaxMisorder {
+			jb.late++
+			return false
+		}
+		jb.released = false
+		jb.hasBase = false
+	}
+
+	// Find insertion point (search from the tail, packets usually arrive in
+	// order); packets of an earlier stream stay ahead
+	idx := len(jb.packets)
+	for idx > 0 {
+		prev := jb.packets[idx-1]
+		if prev.ssrc != ssrc {
+			break
+		}
+		if prev.seq == seq {
+			jb.duplicates++
+			return false
+		}
+		if seqLess(prev.seq, seq) {
+			break
+		}
+		idx--
+	}
+
+	// Make room by dropping the oldest packet
+	if len(jb.packets) >= jb.cfg.MaxPackets {
+		jb.overflow++
+		jb.packets[0].release()
+		jb.packets = jb.packets[1:]
+		if idx > 0 {
+			idx--
+		}
+	}
+
+	pkt := packetPool.Get().(*bufferedPacket)
+	pkt.seq = seq
+	pkt.ssrc = ssrc
+	pkt.data = append(pkt.data[:0], data...)
+	pkt.due = jb.schedule(ts, arrival)
+	jb.packets = append(jb.packets, nil)
+	copy(jb.packets[idx+1:], jb.packets[idx:])
+	jb.packets[idx] = pkt
+
+	if len(jb.packets) > jb.maxDepth {
+		jb.maxDepth = len(jb.packets)
+	}
+
+	select {
+	case jb.notify <- struct{}{}:
+	default:
+	}
+	return true
+}
+
+// schedule returns the playout time of a packet with RTP timestamp ts
+// (must hold lock). The schedule is rebased on the packet, due the target
+// delay after its arrival, when there is none yet, when the packet would be
+// due before it arrived, or later than MaxDelay after it.
+func (jb *jitterBuffer) schedule(ts uint32, arrival time.Time) time.Time {
+	if jb.hasBase {
+		offset := time.Duration(int64(int32(ts-jb.baseTS))) * time.Second / jitterClockRate
+		due := jb.baseTime.Add(offset)
+		if delay := due.Sub(arrival); delay >= 0 && delay <= jb.cfg.MaxDelay {
+			return due
+		}
+	}
+	jb.baseTS = ts
+	jb.baseTime = arrival.Add(jb.targetDelay)
+	jb.hasBase = true
+	return jb.baseTime
+}
+
+// updateJitter updates the interarrival jitter estimate and target delay (must hold lock).
+func (jb *jitterBuffer) updateJitter(ts uint32, arrival time.Time) {
+	arrivalSec := float64(arrival.UnixNano()) / float64(time.Second)
+	transit := arrivalSec - float64(ts)/jitterClockRate
+
+	if jb.hasTransit {
+		d := transit - jb.lastTransit
+		if d < 0 {
+			d = -d
+		}
+		// Ignore timestamp discontinuities (e.g. source change)
+		if d < 1 {
+			jb.jitter += (d - jb.jitter) / 16
+		}
+	}
+	jb.lastTransit = transit
+	jb.hasTransit = true
+
+	target := time.Duration(jb.jitter * jitterDelayFactor * float64(time.Second))
+	if target < jb.cfg.MinDelay {
+		target = jb.cfg.MinDelay
+	}
+	if target > jb.cfg.MaxDelay {
+		target = jb.cfg.MaxDelay
+	}
+	jb.targetDelay = target
+}
+
+// Pop returns the next packet if its playout time has been reached. The
+// caller releases the packet once it has been sent.
+func (jb *jitterBuffer) Pop(now time.Time) (*bufferedPacket, bool) {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	if len(jb.packets) == 0 {
+		return nil, false
+	}
+
+	// A missing packet leaves its slot empty: the next one waits for its own
+	head := jb.packets[0]
+	if now.Before(head.due) {
+		return nil, false
+	}
+
+	jb.packets[0] = nil
+	jb.packets = jb.packets[1:]
+	jb.lastSeq = head.seq
+	jb.lastSSRC = head.ssrc
+	jb.released = true
+	return head, true
+}
+
+// NextDue returns how long until the head packet is due for playout.
+// Returns false if the buffer is empty.
+func (jb *jitterBuffer) NextDue(now time.Time) (time.Duration, bool) {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	if len(jb.packets) == 0 {
+		return 0, false
+	}
+	return jb.packets[0].due.Sub(now), true
+}
+
+// Stats returns a snapshot of the buffer statistics.
+func (jb *jitterBuffer) Stats() JitterStats {
+	jb.mu.Lock()
+	defer jb.mu.Unlock()
+
+	return JitterStats{
+		SessionID:   jb.sessionID,
+		Depth:       len(jb.packets),
+		MaxDepth:    jb.maxDepth,
+		TargetDelay: jb.targetDelay,
+		Jitter:      time.Duration(jb.jitter * float64(time.Second)),
+		Late:        jb.late,
+		Overflow:    jb.overflow,
+		Duplicates:  jb.duplicates,
+	}
+}
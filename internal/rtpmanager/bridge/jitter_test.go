@@ -0,0 +1,123 @@
+package bridge
+
+import (
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+func rtpPacket(seq uint16, ts uint32) []byte {
+	data := make([]byte, 172)
+	data[0] = 0x80
+	binary.BigEndian.PutUint16(data[2:4], seq)
+	binary.BigEndian.PutUint32(data[4:8], ts)
+	data[12] = byte(seq)
+	return data
+}
+
+// Recycled packets must not share storage with packets still buffered.
+func TestJitterBufferReordersPooledPackets(t *testing.T) {
+	jb := newJitterBuffer("s1", JitterConfig{Enabled: true})
+	now := time.Now()
+	for _, seq := range []uint16{2, 1, 3} {
+		jb.Push(rtpPacket(seq, uint32(seq)*160), now)
+	}
+
+	later := now.Add(time.Second)
+	for want := uint16(1); want <= 3; want++ {
+		pkt, ok := jb.Pop(later)
+		if !ok {
+			t.Fatalf("Pop() returned nothing, want seq %d", want)
+		}
+		if got := binary.BigEndian.Uint16(pkt.data[2:4]); got != want || pkt.data[12] != byte(want) {
+			t.Fatalf("Pop() = seq %d payload %d, want %d", got, pkt.data[12], want)
+		}
+		pkt.release()
+		jb.Push(rtpPacket(want+10, uint32(want+10)*160), now)
+	}
+}
+
+// A bursty link delivers 100ms of audio at once; playout restores the
+// 20ms spacing the sender timestamped.
+func TestJitterBufferPacesBursts(t *testing.T) {
+	jb := newJitterBuffer("s1", JitterConfig{Enabled: true})
+	start := time.Now()
+	const bursts, perBurst = 4, 5
+
+	var played []time.Time
+	seq := uint16(0)
+	for ms := 0; ms < 1000; ms++ {
+		now := start.Add(time.Duration(ms) * time.Millisecond)
+		// Each burst arrives once its last packet was sent, 5ms late
+		if ms%100 == 85 && seq < bursts*perBurst {
+			for range perBurst {
+				jb.Push(rtpPacket(seq, uint32(seq)*160), now)
+				seq++
+			}
+		}
+		for {
+			pkt, ok := jb.Pop(now)
+			if !ok {
+				break
+			}
+			if want := uint16(len(played)); pkt.seq != want {
+				t.Fatalf("played seq %d, want %d", pkt.seq, want)
+			}
+			pkt.release()
+			played = append(played, now)
+		}
+	}
+
+	if len(played) != bursts*perBurst {
+		t.Fatalf("played %d packets, want %d", len(played), bursts*perBurst)
+	}
+	for i := 1; i < len(played); i++ {
+		if gap := played[i].Sub(played[i-1]); gap != 20*time.Millisecond {
+			t.Fatalf("packet %d played %v after the previous one, want 20ms", i, gap)
+		}
+	}
+	if s := jb.Stats(); s.Late != 0 {
+		t.Errorf("%d packets late, want 0", s.Late)
+	}
+}
+
+// A new SSRC starts a new schedule instead of being dropped as late.
+func TestJitterBufferRebasesNewStream(t *testing.T) {
+	jb := newJitterBuffer("s1", JitterConfig{Enabled: true})
+	now := time.Now()
+	jb.Push(streamPacket(5000, 1), now)
+	if _, ok := jb.Pop(now.Add(time.Second)); !ok {
+		t.Fatal("Pop() returned nothing")
+	}
+
+	later := now.Add(2 * time.Second)
+	for _, data := range [][]byte{streamPacket(10, 2), streamPacket(20, 2)} {
+		if !jb.Push(data, later) {
+			t.Fatalf("Push(seq %d) dropped", binary.BigEndian.Uint16(data[2:4]))
+		}
+	}
+	// Due the target delay after arrival, not on the old stream's clock
+	if wait, ok := jb.NextDue(later); !ok || wait != DefaultJitterMinDelay {
+		t.Errorf("NextDue = %v, %v; want %v", wait, ok, DefaultJitterMinDelay)
+	}
+	if jb.Stats().Late != 0 {
+		t.Error("packets of the new stream counted late")
+	}
+}
+
+func BenchmarkJitterBufferPushPop(b *testing.B) {
+	jb := newJitterBuffer("s1", JitterConfig{Enabled: true})
+	now := time.Now()
+	data := rtpPacket(0, 0)
+	b.ReportAllocs()
+	var seq uint16
+	for b.Loop() {
+		binary.BigEndian.PutUint16(data[2:4], seq)
+		binary.BigEndian.PutUint32(data[4:8], uint32(seq)*160)
+		jb.Push(data, now)
+		if pkt, ok := jb.Pop(now.Add(time.Second)); ok {
+			pkt.release()
+		}
+		seq++
+	}
+}
@@ -0,0 +1,16 @@
+//go:build !linux
+
+package bridge
+
+import (
+	"errors"
+	"net"
+)
+
+func listenReusePort(port int) (*net.UDPConn, error) {
+	return nil, errNoReusePort
+}
+
+func pinThread(cpu int) error {
+	return errors.New("CPU pinning not supported")
+}
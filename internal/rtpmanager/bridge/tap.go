@@ -0,0 +1,102 @@
+package bridge
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// TapDirection identifies which way tapped media flows relative to a session.
+type TapDirection int
+
+const (
+	// TapInbound is media received from the session's remote party
+	TapInbound TapDirection = iota
+	// TapOutbound is media sent to the session's remote party
+	TapOutbound
+)
+
+// String returns the string representation of TapDirection
+func (d TapDirection) String() string {
+	switch d {
+	case TapInbound:
+		return "inbound"
+	case TapOutbound:
+		return "outbound"
+	default:
+		return "unknown"
+	}
+}
+
+// TapFunc receives RTP packets relayed through a bridge.
+// data is only valid for the duration of the call and must be copied if
+// retained. Called from the relay goroutines, so it must not block.
+type TapFunc func(dir TapDirection, data []byte)
+
+// Tap is a registered media tap on a bridged session.
+type Tap struct {
+	id        uint64
+	sessionID string
+	fn        TapFunc
+	bridge    *Bridge
+}
+
+// Done returns a channel that is closed when the bridge is torn down.
+func (t *Tap) Done() <-chan struct{} {
+	return t.bridge.ctx.Done()
+}
+
+// Close removes the tap. Safe to call more than once.
+func (t *Tap) Close() {
+	t.bridge.tapMu.Lock()
+	delete(t.bridge.taps, t.id)
+	t.bridge.tapMu.Unlock()
+}
+
+// tapRegistry holds the taps attached to a bridge.
+type tapRegistry struct {
+	tapMu  sync.RWMutex
+	taps   map[uint64]*Tap
+	nextID atomic.Uint64
+}
+
+// deliverTaps passes a packet received on one side to matching taps.
+// fromA reports whether the packet was received from A's remote party.
+func (b *Bridge) deliverTaps(fromA bool, data []byte) {
+	b.tapMu.RLock()
+	defer b.tapMu.RUnlock()
+
+	if len(b.taps) == 0 || !isBufferable(data) {
+		return
+	}
+	for _, t := range b.taps {
+		receivedOnTapSession := (t.sessionID == b.SessionA.SessionID) == fromA
+		if receivedOnTapSession {
+			t.fn(TapInbound, data)
+		} else {
+			t.fn(TapOutbound, data)
+		}
+	}
+}
+
+// AddTap attaches a media tap to a bridged session.
+// The tap sees RTP in both directions; callers filter by TapDirection.
+func (m *Manager) AddTap(sessionID string, fn TapFunc) (*Tap, error) {
+	bridge, ok := m.GetBridgeBySession(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("session %s is not bridged", sessionID)
+	}
+
+	t := &Tap{
+		id:        bridge.nextID.Add(1),
+		sessionID: sessionID,
+		fn:        fn,
+		bridge:    bridge,
+	}
+
+	bridge.tapMu.Lock()
+	bridge.taps[t.id] = t
+	bridge.tapMu.Unlock()
+
+	return t, nil
+}
@@ -0,0 +1,144 @@
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"log/slog"
+	"net"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// WorkerConfig sets how many goroutines receive and forward each bridged
+// port's packets.
+type WorkerConfig struct {
+	// Workers is the number of goroutines forwarding each direction of a
+	// bridge, and of sockets bound to each bridged port with SO_REUSEPORT,
+	// each read by its own goroutine. Readers only receive: they hand
+	// packets to the forwarding goroutines by SSRC, so each stream stays
+	// in order while a busy port's taps, jitter buffering and sends run on
+	// several CPUs, even when one peer sends it all. The kernel spreads
+	// datagrams over the sockets by source address. 1 (the default) binds
+	// a single plain socket and forwards on the reading goroutine.
+	Workers int
+
+	// PinCPUs locks each worker goroutine, reading or forwarding, to an OS
+	// thread bound to one CPU, spreading workers over the CPUs in turn.
+	// Every worker then holds a thread of its own, so this suits nodes
+	// with few busy ports.
+	PinCPUs bool
+}
+
+func (c WorkerConfig) withDefaults() WorkerConfig {
+	if c.Workers < 1 {
+		c.Workers = 1
+	}
+	return c
+}
+
+// fanQueueSize is how many packets wait for each forwarding worker before
+// the readers handing them over block
+const fanQueueSize = 256
+
+// fanPacket is a packet handed from a reader to a forwarding worker
+type fanPacket struct {
+	data    []byte
+	arrival time.Time
+}
+
+var fanPacketPool = sync.Pool{
+	New: func() any { return &fanPacket{data: make([]byte, 0, maxDatagram)} },
+}
+
+// fanOut spreads the packets read on one port over forwarding workers, by
+// SSRC so the packets of a stream keep their order.
+type fanOut struct {
+	queues []chan *fanPacket
+}
+
+func newFanOut(workers int) *fanOut {
+	f := &fanOut{queues: make([]chan *fanPacket, workers)}
+	for i := range f.queues {
+		f.queues[i] = make(chan *fanPacket, fanQueueSize)
+	}
+	return f
+}
+
+// push copies a packet to the queue of its stream's worker. It blocks
+// while that queue is full, until ctx is done.
+func (f *fanOut) push(ctx context.Context, data []byte, arrival time.Time) {
+	p := fanPacketPool.Get().(*fanPacket)
+	p.data = append(p.data[:0], data...)
+	p.arrival = arrival
+	select {
+	case f.queues[ssrcOf(data)%uint32(len(f.queues))] <- p:
+	case <-ctx.Done():
+		fanPacketPool.Put(p)
+	}
+}
+
+// ssrcOf returns the SSRC of an RTP or RTCP packet (RFC 3550), or 0 for
+// anything else, which then all goes to the first worker.
+func ssrcOf(data []byte) uint32 {
+	switch {
+	case len(data) >= 12 && isBufferable(data):
+		return binary.BigEndian.Uint32(data[8:12])
+	case len(data) >= 8 && data[0]>>6 == 2:
+		return binary.BigEndian.Uint32(data[4:8])
+	}
+	return 0
+}
+
+// nextCPU hands out CPUs to pinned workers in turn
+var nextCPU atomic.Uint32
+
+// listenWorkers binds the sockets receiving on port: one plain socket for
+// a single worker, otherwise workers sockets sharing the port with
+// SO_REUSEPORT. Where SO_REUSEPORT is unavailable, a single socket is
+// bound.
+func listenWorkers(port, workers int) ([]*net.UDPConn, error) {
+	if workers <= 1 {
+		conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: port})
+		if err != nil {
+			return nil, err
+		}
+		return []*net.UDPConn{conn}, nil
+	}
+
+	conns := make([]*net.UDPConn, 0, workers)
+	for range workers {
+		conn, err := listenReusePort(port)
+		if err != nil {
+			if len(conns) == 0 && errors.Is(err, errNoReusePort) {
+				slog.Warn("[Bridge] SO_REUSEPORT unavailable, using one worker", "port", port)
+				return listenWorkers(port, 1)
+			}
+			closeAll(conns)
+			return nil, err
+		}
+		conns = append(conns, conn)
+	}
+	return conns, nil
+}
+
+// pinWorker locks the calling goroutine to its thread and binds the
+// thread to the next CPU. The goroutine keeps the thread until it exits.
+func pinWorker() {
+	runtime.LockOSThread()
+	cpu := int(nextCPU.Add(1)-1) % runtime.NumCPU()
+	if err := pinThread(cpu); err != nil {
+		slog.Debug("[Bridge] CPU pinning failed", "cpu", cpu, "error", err)
+	}
+}
+
+func closeAll(conns []*net.UDPConn) {
+	for _, conn := range conns {
+		_ = conn.Close()
+	}
+}
+
+// errNoReusePort is returned where SO_REUSEPORT is not supported
+var errNoReusePort = errors.New("SO_REUSEPORT not supported on " + runtime.GOOS)
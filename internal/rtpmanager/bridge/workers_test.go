@@ -0,0 +1,52 @@
+package bridge
+
+import (
+	"context"
+	"encoding/binary"
+	"testing"
+	"time"
+)
+
+// streamPacket builds an RTP packet of a stream
+func streamPacket(seq uint16, ssrc uint32) []byte {
+	data := rtpPacket(seq, uint32(seq)*160)
+	binary.BigEndian.PutUint32(data[8:12], ssrc)
+	return data
+}
+
+// Packets of one stream land on one worker, in the order they were read.
+func TestFanOutKeepsStreamOrder(t *testing.T) {
+	f := newFanOut(4)
+	ctx := context.Background()
+	now := time.Now()
+	for seq := range uint16(20) {
+		f.push(ctx, streamPacket(seq, 1001), now)
+		f.push(ctx, streamPacket(seq, 2002), now)
+	}
+
+	for _, ssrc := range []uint32{1001, 2002} {
+		queue := f.queues[ssrc%4]
+		var want uint16
+		for len(queue) > 0 {
+			p := <-queue
+			got := ssrcOf(p.data)
+			if got != ssrc {
+				// The other stream may share the queue
+				continue
+			}
+			if seq := binary.BigEndian.Uint16(p.data[2:4]); seq != want {
+				t.Fatalf("SSRC %d: seq %d, want %d", ssrc, seq, want)
+			}
+			want++
+		}
+		if want != 20 {
+			t.Errorf("SSRC %d: %d packets on its worker, want 20", ssrc, want)
+		}
+	}
+
+	// RTCP goes to the worker of its sender's SSRC
+	rtcp := []byte{0x80, 200, 0, 6, 0, 0, 0x03, 0xe9}
+	if got := ssrcOf(rtcp); got != 1001 {
+		t.Errorf("ssrcOf(RTCP) = %d, want 1001", got)
+	}
+}
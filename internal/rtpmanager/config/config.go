@@ -4,18 +4,104 @@ import (
 	"flag"
 	"net"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 )
 
 // Config holds the RTP Manager configuration
 type Config struct {
 	GRPCPort      int
 	GRPCBindAddr  string
+	HealthPort    int    // HTTP port for /healthz and /readyz (0 disables)
 	AdvertiseAddr string // Address to advertise in SDP
 	RTPPortMin    int
 	RTPPortMax    int
+	RTPPorts      string // Port ranges ("10000-20000,30000-30999"); overrides min/max
 	AudioBasePath string
 	LogLevel      string
+
+	// TLS for the gRPC server, reloaded when the files change; with
+	// TLSClientCA, signaling servers must present a certificate it signed
+	TLSCertFile        string
+	TLSKeyFile         string
+	TLSClientCA        string
+	CertReloadInterval time.Duration
+
+	// Log output: format (text, json), optional rotated file and
+	// per-module level overrides ("dialog=debug,b2bua=warn")
+	LogFormat      string
+	LogFile        string
+	LogMaxSizeMB   int
+	LogRotateEvery time.Duration
+	LogMaxBackups  int
+	LogModules     string
+
+	// AdvertiseRules advertises other addresses to peers in given networks
+	// ("10.0.0.0/8=10.0.0.5,0.0.0.0/0=203.0.113.5")
+	AdvertiseRules string
+
+	// AdvertiseAddr6 is advertised to IPv6 peers ("auto" detects a global
+	// address; empty answers IPv6 offers with AdvertiseAddr)
+	AdvertiseAddr6 string
+
+	// Jitter buffer for bridged media
+	JitterBufferEnabled bool
+	JitterMinDelay      time.Duration
+	JitterMaxDelay      time.Duration
+
+	// RTPWorkers is the number of receive sockets (SO_REUSEPORT) per
+	// bridged port and of goroutines forwarding each direction, packets
+	// spread over them by SSRC; RTPPinCPUs binds each to one CPU
+	RTPWorkers int
+	RTPPinCPUs bool
+
+	// RTPTimeout is how long a bridged session may go without RTP before
+	// signaling is notified (0 disables)
+	RTPTimeout time.Duration
+
+	// OrphanGrace is how long a session may stay unbridged before its
+	// signaling server is asked whether it still uses it (0 disables)
+	OrphanGrace time.Duration
+
+	// JitterAlert raises a quality alert for bridged sessions with more
+	// jitter than this (0 disables; needs the jitter buffer)
+	JitterAlert time.Duration
+
+	// Remote audio (http, https, s3) download cache
+	AudioCacheDir    string
+	AudioCacheSizeMB int
+	AudioCacheTTL    time.Duration
+
+	// TonePlan is the default country for generated tones (e.g. "us", "uk")
+	TonePlan string
+
+	// Simulate serves the gRPC API without media: no RTP ports are opened
+	// and playback only reports timed events
+	Simulate bool
+
+	// MetricsExporter pushes metrics to an agent: "statsd" or "dogstatsd";
+	// empty disables it
+	MetricsExporter string
+	StatsDAddr      string        // Agent address
+	StatsDPrefix    string        // Metric name prefix
+	StatsDTags      string        // Comma-separated tags added to every metric (dogstatsd)
+	MetricsInterval time.Duration // How often gauges are sampled
+
+	// DebugToken enables the /debug/ diagnostics endpoints on the health
+	// port for requests bearing it; empty disables them
+	DebugToken string
+
+	// DebugToken and the TLS files may be references to a secret store
+	// (see package secrets), resolved again every SecretsRefresh.
+	// Referenced files are written to SecretsDir, a private temporary
+	// directory if empty.
+	SecretsDir     string
+	SecretsRefresh time.Duration
+
+	// SkipPreflight starts without the startup checks of ports, RTP range,
+	// audio path and advertise address
+	SkipPreflight bool
 }
 
 // Load loads configuration from command line flags and environment variables
@@ -24,11 +110,47 @@ func Load() *Config {
 
 	flag.IntVar(&cfg.GRPCPort, "grpc-port", 9090, "gRPC server port")
 	flag.StringVar(&cfg.GRPCBindAddr, "bind", "0.0.0.0", "gRPC bind address")
+	flag.StringVar(&cfg.TLSCertFile, "tls-cert", "", "PEM certificate for the gRPC server, reloaded when it changes; empty serves plaintext")
+	flag.StringVar(&cfg.TLSKeyFile, "tls-key", "", "PEM private key of --tls-cert")
+	flag.StringVar(&cfg.TLSClientCA, "tls-client-ca", "", "CA bundle signaling client certificates must chain to (mutual TLS)")
+	flag.DurationVar(&cfg.CertReloadInterval, "cert-reload-interval", time.Minute, "How often certificate files are checked for changes")
+	flag.IntVar(&cfg.HealthPort, "health-port", 8090, "HTTP port for liveness and readiness probes (0 disables)")
 	flag.StringVar(&cfg.AdvertiseAddr, "advertise", "", "Address to advertise in SDP (auto-detected if not set)")
+	flag.StringVar(&cfg.AdvertiseRules, "advertise-rules", "", "Per-network SDP addresses, e.g. \"10.0.0.0/8=10.0.0.5,0.0.0.0/0=203.0.113.5\"")
+	flag.StringVar(&cfg.AdvertiseAddr6, "advertise6", "", "IPv6 address to advertise to IPv6 peers (\"auto\" to detect)")
 	flag.IntVar(&cfg.RTPPortMin, "rtp-port-min", 10000, "Minimum RTP port")
 	flag.IntVar(&cfg.RTPPortMax, "rtp-port-max", 20000, "Maximum RTP port")
+	flag.StringVar(&cfg.RTPPorts, "rtp-ports", "", "RTP port ranges, e.g. \"10000-20000,30000-30999\" (overrides -rtp-port-min/max)")
 	flag.StringVar(&cfg.AudioBasePath, "audio-path", "./audio", "Audio files base path")
+	flag.StringVar(&cfg.AudioCacheDir, "audio-cache-dir", filepath.Join(os.TempDir(), "switchboard-audio"), "Directory for downloaded audio")
+	flag.IntVar(&cfg.AudioCacheSizeMB, "audio-cache-mb", 256, "Maximum size of the downloaded audio cache in MB")
+	flag.DurationVar(&cfg.AudioCacheTTL, "audio-cache-ttl", time.Hour, "Revalidate downloaded audio after this long")
+	flag.StringVar(&cfg.TonePlan, "tone-plan", "us", "Default country tone plan for generated tones")
 	flag.StringVar(&cfg.LogLevel, "loglevel", "debug", "Log level")
+	flag.StringVar(&cfg.LogFormat, "log-format", "text", "Log format (text, json)")
+	flag.StringVar(&cfg.LogFile, "log-file", "", "Log to this file instead of stdout")
+	flag.IntVar(&cfg.LogMaxSizeMB, "log-max-size-mb", 100, "Rotate the log file past this size in MB (0 disables)")
+	flag.DurationVar(&cfg.LogRotateEvery, "log-rotate-every", 24*time.Hour, "Rotate the log file after this long (0 disables)")
+	flag.IntVar(&cfg.LogMaxBackups, "log-max-backups", 7, "Rotated log files to keep (0 keeps all)")
+	flag.StringVar(&cfg.LogModules, "log-modules", "", "Per-module log levels, e.g. \"dialog=debug,b2bua=warn\"")
+	flag.BoolVar(&cfg.JitterBufferEnabled, "jitter-buffer", false, "Enable adaptive jitter buffer for bridged media")
+	flag.DurationVar(&cfg.JitterMinDelay, "jitter-min-delay", 20*time.Millisecond, "Minimum jitter buffer playout delay")
+	flag.DurationVar(&cfg.JitterMaxDelay, "jitter-max-delay", 200*time.Millisecond, "Maximum jitter buffer playout delay")
+	flag.IntVar(&cfg.RTPWorkers, "rtp-workers", 1, "Receive sockets (SO_REUSEPORT) and forwarding goroutines per bridged port")
+	flag.BoolVar(&cfg.RTPPinCPUs, "rtp-pin-cpus", false, "Pin each bridged RTP receive and forwarding worker to a CPU")
+	flag.DurationVar(&cfg.RTPTimeout, "rtp-timeout", 60*time.Second, "Report bridged sessions with no RTP for this long (0 disables)")
+	flag.DurationVar(&cfg.JitterAlert, "jitter-alert", 0, "Raise a quality alert for bridged sessions with more jitter than this (0 disables; needs --jitter-buffer)")
+	flag.DurationVar(&cfg.OrphanGrace, "orphan-grace", 2*time.Minute, "Reap sessions unbridged for this long once signaling confirms them unused (0 disables)")
+	flag.BoolVar(&cfg.Simulate, "simulate", false, "Simulate media without opening RTP ports (development and CI)")
+	flag.StringVar(&cfg.MetricsExporter, "metrics-exporter", "", "Push metrics to a StatsD agent (statsd, dogstatsd); empty disables")
+	flag.StringVar(&cfg.StatsDAddr, "statsd-addr", "127.0.0.1:8125", "StatsD agent address")
+	flag.StringVar(&cfg.StatsDPrefix, "statsd-prefix", "switchboard.rtpmanager.", "Prefix for StatsD metric names")
+	flag.StringVar(&cfg.StatsDTags, "statsd-tags", "", "Tags added to every metric, e.g. \"env:prod,region:eu\" (dogstatsd only)")
+	flag.DurationVar(&cfg.MetricsInterval, "metrics-interval", 10*time.Second, "How often gauges are sent to the metrics exporter")
+	flag.StringVar(&cfg.DebugToken, "debug-token", "", "Bearer token for the /debug/ diagnostics endpoints on the health port; empty disables them")
+	flag.StringVar(&cfg.SecretsDir, "secrets-dir", "", "Directory for TLS files resolved from a secret store; empty uses a private temporary directory")
+	flag.DurationVar(&cfg.SecretsRefresh, "secrets-refresh", 5*time.Minute, "How often secret store references are resolved again; 0 disables")
+	flag.BoolVar(&cfg.SkipPreflight, "skip-preflight", false, "Start without checking ports, RTP range and audio path first")
 
 	flag.Parse()
 
@@ -39,23 +161,154 @@ func Load() *Config {
 	if v := os.Getenv("BIND"); v != "" {
 		cfg.GRPCBindAddr = v
 	}
+	if v := os.Getenv("TLS_CERT"); v != "" {
+		cfg.TLSCertFile = v
+	}
+	if v := os.Getenv("TLS_KEY"); v != "" {
+		cfg.TLSKeyFile = v
+	}
+	if v := os.Getenv("TLS_CLIENT_CA"); v != "" {
+		cfg.TLSClientCA = v
+	}
+	if v := os.Getenv("CERT_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.CertReloadInterval = d
+		}
+	}
+	if v := os.Getenv("HEALTH_PORT"); v != "" {
+		cfg.HealthPort, _ = strconv.Atoi(v)
+	}
 	if v := os.Getenv("ADVERTISE"); v != "" {
 		cfg.AdvertiseAddr = v
 	} else if cfg.AdvertiseAddr == "" {
 		cfg.AdvertiseAddr = getPrimaryInterfaceIP()
 	}
+	if v := os.Getenv("ADVERTISE_RULES"); v != "" {
+		cfg.AdvertiseRules = v
+	}
+	if v := os.Getenv("ADVERTISE6"); v != "" {
+		cfg.AdvertiseAddr6 = v
+	}
+	if cfg.AdvertiseAddr6 == "auto" {
+		cfg.AdvertiseAddr6 = getPrimaryInterfaceIPv6()
+	}
 	if v := os.Getenv("RTP_PORT_MIN"); v != "" {
 		cfg.RTPPortMin, _ = strconv.Atoi(v)
 	}
 	if v := os.Getenv("RTP_PORT_MAX"); v != "" {
 		cfg.RTPPortMax, _ = strconv.Atoi(v)
 	}
+	if v := os.Getenv("RTP_PORTS"); v != "" {
+		cfg.RTPPorts = v
+	}
 	if v := os.Getenv("AUDIO_PATH"); v != "" {
 		cfg.AudioBasePath = v
 	}
+	if v := os.Getenv("AUDIO_CACHE_DIR"); v != "" {
+		cfg.AudioCacheDir = v
+	}
+	if v := os.Getenv("AUDIO_CACHE_MB"); v != "" {
+		cfg.AudioCacheSizeMB, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("AUDIO_CACHE_TTL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.AudioCacheTTL = d
+		}
+	}
+	if v := os.Getenv("TONE_PLAN"); v != "" {
+		cfg.TonePlan = v
+	}
 	if v := os.Getenv("LOGLEVEL"); v != "" {
 		cfg.LogLevel = v
 	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		cfg.LogFormat = v
+	}
+	if v := os.Getenv("LOG_FILE"); v != "" {
+		cfg.LogFile = v
+	}
+	if v := os.Getenv("LOG_MAX_SIZE_MB"); v != "" {
+		cfg.LogMaxSizeMB, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("LOG_ROTATE_EVERY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.LogRotateEvery = d
+		}
+	}
+	if v := os.Getenv("LOG_MAX_BACKUPS"); v != "" {
+		cfg.LogMaxBackups, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("LOG_MODULES"); v != "" {
+		cfg.LogModules = v
+	}
+	if v := os.Getenv("JITTER_BUFFER"); v != "" {
+		cfg.JitterBufferEnabled, _ = strconv.ParseBool(v)
+	}
+	if v := os.Getenv("JITTER_MIN_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.JitterMinDelay = d
+		}
+	}
+	if v := os.Getenv("JITTER_MAX_DELAY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.JitterMaxDelay = d
+		}
+	}
+	if v := os.Getenv("RTP_WORKERS"); v != "" {
+		cfg.RTPWorkers, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("RTP_PIN_CPUS"); v != "" {
+		cfg.RTPPinCPUs, _ = strconv.ParseBool(v)
+	}
+	if v := os.Getenv("RTP_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.RTPTimeout = d
+		}
+	}
+	if v := os.Getenv("JITTER_ALERT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.JitterAlert = d
+		}
+	}
+	if v := os.Getenv("ORPHAN_GRACE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OrphanGrace = d
+		}
+	}
+	if v := os.Getenv("RTP_SIMULATE"); v != "" {
+		cfg.Simulate, _ = strconv.ParseBool(v)
+	}
+	if v := os.Getenv("METRICS_EXPORTER"); v != "" {
+		cfg.MetricsExporter = v
+	}
+	if v := os.Getenv("STATSD_ADDR"); v != "" {
+		cfg.StatsDAddr = v
+	}
+	if v := os.Getenv("STATSD_PREFIX"); v != "" {
+		cfg.StatsDPrefix = v
+	}
+	if v := os.Getenv("STATSD_TAGS"); v != "" {
+		cfg.StatsDTags = v
+	}
+	if v := os.Getenv("METRICS_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MetricsInterval = d
+		}
+	}
+	if v := os.Getenv("DEBUG_TOKEN"); v != "" {
+		cfg.DebugToken = v
+	}
+	if v := os.Getenv("SECRETS_DIR"); v != "" {
+		cfg.SecretsDir = v
+	}
+	if v := os.Getenv("SECRETS_REFRESH"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.SecretsRefresh = d
+		}
+	}
+	if v := os.Getenv("SKIP_PREFLIGHT"); v != "" {
+		cfg.SkipPreflight, _ = strconv.ParseBool(v)
+	}
 
 	return cfg
 }
@@ -86,3 +339,31 @@ func getPrimaryInterfaceIP() string {
 
 	return "127.0.0.1"
 }
+
+// getPrimaryInterfaceIPv6 detects a global unicast IPv6 address, or "" if
+// the host has none
+func getPrimaryInterfaceIPv6() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() == nil && ipnet.IP.IsGlobalUnicast() {
+				return ipnet.IP.String()
+			}
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,166 @@
+package media
+
+import (
+	"encoding/binary"
+	"time"
+)
+
+// Answering machine detection from the timing of the remote party's
+// greeting. A person answers with a few short words ("Hello?") and waits;
+// a machine starts late, talks for longer or says more words.
+
+// AnsweredBy is who answered a call
+type AnsweredBy string
+
+const (
+	AnsweredByHuman   AnsweredBy = "human"
+	AnsweredByMachine AnsweredBy = "machine"
+	AnsweredByUnknown AnsweredBy = "unknown" // No decision within the analysis time
+)
+
+// Machine detection defaults
+const (
+	DefaultInitialSilence       = 2500 * time.Millisecond
+	DefaultGreeting             = 1500 * time.Millisecond
+	DefaultAfterGreetingSilence = 800 * time.Millisecond
+	DefaultTotalAnalysis        = 5000 * time.Millisecond
+	DefaultMinWordLength        = 100 * time.Millisecond
+	DefaultBetweenWordsSilence  = 50 * time.Millisecond
+	DefaultMaximumWords         = 3
+	DefaultSilenceThreshold     = 256
+)
+
+// MachineDetectionConfig tunes the detector; zero fields take the defaults.
+type MachineDetectionConfig struct {
+	InitialSilence       time.Duration // Silence before any voice that makes the call a machine
+	Greeting             time.Duration // Voice longer than this makes the call a machine
+	AfterGreetingSilence time.Duration // Silence after the greeting that makes the call a person
+	TotalAnalysis        time.Duration // Analysis time after which no decision is made
+	MinWordLength        time.Duration // Shortest voice counted as a word
+	BetweenWordsSilence  time.Duration // Silence that separates words
+	MaximumWords         int           // Words that make the call a machine
+	SilenceThreshold     int           // Average sample magnitude below which a frame is silence
+}
+
+func (c MachineDetectionConfig) withDefaults() MachineDetectionConfig {
+	if c.InitialSilence <= 0 {
+		c.InitialSilence = DefaultInitialSilence
+	}
+	if c.Greeting <= 0 {
+		c.Greeting = DefaultGreeting
+	}
+	if c.AfterGreetingSilence <= 0 {
+		c.AfterGreetingSilence = DefaultAfterGreetingSilence
+	}
+	if c.TotalAnalysis <= 0 {
+		c.TotalAnalysis = DefaultTotalAnalysis
+	}
+	if c.MinWordLength <= 0 {
+		c.MinWordLength = DefaultMinWordLength
+	}
+	if c.BetweenWordsSilence <= 0 {
+		c.BetweenWordsSilence = DefaultBetweenWordsSilence
+	}
+	if c.MaximumWords <= 0 {
+		c.MaximumWords = DefaultMaximumWords
+	}
+	if c.SilenceThreshold <= 0 {
+		c.SilenceThreshold = DefaultSilenceThreshold
+	}
+	return c
+}
+
+// MachineDetection is the decision of a MachineDetector
+type MachineDetection struct {
+	AnsweredBy AnsweredBy
+	Reason     string        // "initial_silence", "long_greeting", "max_words", "after_greeting_silence" or "max_analysis_time"
+	Duration   time.Duration // Audio analyzed until the decision
+}
+
+// MachineDetector decides from 8 kHz audio whether a person or an
+// answering machine answered. Not safe for concurrent use.
+type MachineDetector struct {
+	cfg MachineDetectionConfig
+
+	elapsed  time.Duration // Audio analyzed
+	voice    time.Duration // Current run of voice
+	silence  time.Duration // Current run of silence
+	greeting time.Duration // Voice since the first word
+	words    int
+	inWord   bool
+	done     bool
+}
+
+// NewMachineDetector creates a detector.
+func NewMachineDetector(cfg MachineDetectionConfig) *MachineDetector {
+	return &MachineDetector{cfg: cfg.withDefaults()}
+}
+
+// ProcessPCMU feeds one frame of PCMU audio. It returns the decision once,
+// on the frame that decides it.
+func (d *MachineDetector) ProcessPCMU(payload []byte) (MachineDetection, bool) {
+	if d.done || len(payload) == 0 {
+		return MachineDetection{}, false
+	}
+
+	pcm := PCMUToPCM(payload)
+	var sum int
+	for i := 0; i+1 < len(pcm); i += 2 {
+		sample := int(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		if sample < 0 {
+			sample = -sample
+		}
+		sum += sample
+	}
+	samples := len(pcm) / 2
+	frame := time.Duration(samples) * time.Second / dtmfSampleRate
+	return d.process(frame, sum/samples < d.cfg.SilenceThreshold)
+}
+
+// process times one frame of voice or silence.
+func (d *MachineDetector) process(frame time.Duration, silent bool) (MachineDetection, bool) {
+	d.elapsed += frame
+	if d.elapsed >= d.cfg.TotalAnalysis {
+		return d.decide(AnsweredByUnknown, "max_analysis_time")
+	}
+
+	if silent {
+		d.silence += frame
+		if !d.inWord || d.silence >= d.cfg.BetweenWordsSilence {
+			d.inWord = false
+			d.voice = 0
+		}
+		if d.words == 0 && d.silence >= d.cfg.InitialSilence {
+			return d.decide(AnsweredByMachine, "initial_silence")
+		}
+		if d.words > 0 && d.silence >= d.cfg.AfterGreetingSilence {
+			return d.decide(AnsweredByHuman, "after_greeting_silence")
+		}
+		return MachineDetection{}, false
+	}
+
+	d.silence = 0
+	d.voice += frame
+	if !d.inWord && d.voice >= d.cfg.MinWordLength {
+		d.inWord = true
+		d.words++
+		if d.words == 1 {
+			d.greeting = d.voice - frame // The word's earlier frames
+		}
+		if d.words >= d.cfg.MaximumWords {
+			return d.decide(AnsweredByMachine, "max_words")
+		}
+	}
+	if d.words > 0 {
+		d.greeting += frame
+		if d.greeting >= d.cfg.Greeting {
+			return d.decide(AnsweredByMachine, "long_greeting")
+		}
+	}
+	return MachineDetection{}, false
+}
+
+func (d *MachineDetector) decide(by AnsweredBy, reason string) (MachineDetection, bool) {
+	d.done = true
+	return MachineDetection{AnsweredBy: by, Reason: reason, Duration: d.elapsed}, true
+}
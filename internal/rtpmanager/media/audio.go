@@ -200,3 +200,8 @@ func PCMToPCMU(pcm []byte) []byte {
 	// Use the battle-tested g711 library which handles the conversion properly
 	return g711.EncodeUlaw(pcm)
 }
+
+// PCMUToPCM converts PCMU (µ-law) samples to 16-bit little-endian PCM
+func PCMUToPCM(pcmu []byte) []byte {
+	return g711.DecodeUlaw(pcmu)
+}
@@ -0,0 +1,156 @@
+package media
+
+import (
+	"encoding/binary"
+	"math"
+)
+
+// In-band DTMF detection (ITU-T Q.23/Q.24) with the Goertzel algorithm.
+// Sessions only negotiate PCMU, so endpoints send DTMF as audio.
+
+var (
+	dtmfRowFreqs = [4]float64{697, 770, 852, 941}
+	dtmfColFreqs = [4]float64{1209, 1336, 1477, 1633}
+	dtmfDigits   = [4][4]byte{
+		{'1', '2', '3', 'A'},
+		{'4', '5', '6', 'B'},
+		{'7', '8', '9', 'C'},
+		{'*', '0', '#', 'D'},
+	}
+)
+
+const (
+	dtmfSampleRate = 8000
+
+	// dtmfMinEnergy is the minimum tone power (per sample, 16-bit PCM)
+	// for a row or column tone, about -30 dBm0
+	dtmfMinEnergy = 2e4
+
+	// dtmfMaxTwist is the largest allowed power ratio between the row
+	// and column tones (about 8 dB either way)
+	dtmfMaxTwist = 6.3
+
+	// dtmfMinRatio is how much stronger the detected tone must be than
+	// the other tones of its group
+	dtmfMinRatio = 6.0
+
+	// dtmfOnBlocks and dtmfOffBlocks debounce digits: a digit is reported
+	// after this many consecutive 20 ms blocks with it, and may repeat
+	// after this many blocks without it
+	dtmfOnBlocks  = 2
+	dtmfOffBlocks = 2
+)
+
+// DTMFDetector detects in-band DTMF digits in 8 kHz PCM audio.
+// Not safe for concurrent use.
+type DTMFDetector struct {
+	rowCoeffs [4]float64
+	colCoeffs [4]float64
+
+	candidate byte // Digit seen in the last block(s), 0 for none
+	count     int  // Consecutive blocks with candidate
+	silent    int  // Consecutive blocks without a digit
+	reported  byte // Digit reported and not yet released
+}
+
+// NewDTMFDetector creates a detector.
+func NewDTMFDetector() *DTMFDetector {
+	d := &DTMFDetector{}
+	for i := range 4 {
+		d.rowCoeffs[i] = goertzelCoeff(dtmfRowFreqs[i])
+		d.colCoeffs[i] = goertzelCoeff(dtmfColFreqs[i])
+	}
+	return d
+}
+
+// ProcessPCMU feeds one frame of PCMU audio. It returns a digit once per
+// key press, when the digit is first confirmed.
+func (d *DTMFDetector) ProcessPCMU(payload []byte) (byte, bool) {
+	pcm := PCMUToPCM(payload)
+	samples := make([]float64, len(pcm)/2)
+	for i := range samples {
+		samples[i] = float64(int16(binary.LittleEndian.Uint16(pcm[2*i:])))
+	}
+	return d.process(d.detect(samples))
+}
+
+// detect returns the digit present in a block of samples, or 0.
+func (d *DTMFDetector) detect(samples []float64) byte {
+	if len(samples) == 0 {
+		return 0
+	}
+
+	var rows, cols [4]float64
+	for i := range 4 {
+		rows[i] = goertzel(samples, d.rowCoeffs[i])
+		cols[i] = goertzel(samples, d.colCoeffs[i])
+	}
+	row, rowPower := strongest(rows)
+	col, colPower := strongest(cols)
+
+	// Normalize to power per sample so the threshold is independent of
+	// the block length
+	n := float64(len(samples))
+	if rowPower/n < dtmfMinEnergy || colPower/n < dtmfMinEnergy {
+		return 0
+	}
+	if rowPower > colPower*dtmfMaxTwist || colPower > rowPower*dtmfMaxTwist {
+		return 0
+	}
+	for i := range 4 {
+		if i != row && rows[i]*dtmfMinRatio > rowPower {
+			return 0
+		}
+		if i != col && cols[i]*dtmfMinRatio > colPower {
+			return 0
+		}
+	}
+	return dtmfDigits[row][col]
+}
+
+// process debounces per-block detections into key presses.
+func (d *DTMFDetector) process(digit byte) (byte, bool) {
+	if digit == 0 {
+		d.candidate, d.count = 0, 0
+		d.silent++
+		if d.silent >= dtmfOffBlocks {
+			d.reported = 0
+		}
+		return 0, false
+	}
+
+	d.silent = 0
+	if digit != d.candidate {
+		d.candidate, d.count = digit, 0
+	}
+	d.count++
+	if d.count < dtmfOnBlocks || digit == d.reported {
+		return 0, false
+	}
+	d.reported = digit
+	return digit, true
+}
+
+func goertzelCoeff(freq float64) float64 {
+	return 2 * math.Cos(2*math.Pi*freq/dtmfSampleRate)
+}
+
+// goertzel returns the signal power at the frequency of coeff.
+func goertzel(samples []float64, coeff float64) float64 {
+	var s1, s2 float64
+	for _, x := range samples {
+		s0 := x + coeff*s1 - s2
+		s2, s1 = s1, s0
+	}
+	return s1*s1 + s2*s2 - coeff*s1*s2
+}
+
+func strongest(powers [4]float64) (int, float64) {
+	best := 0
+	for i := 1; i < 4; i++ {
+		if powers[i] > powers[best] {
+			best = i
+		}
+	}
+	return best, powers[best]
+}
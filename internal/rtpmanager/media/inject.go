@@ -0,0 +1,305 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"sync"
+	"time"
+
+	"github.com/pion/rtp"
+	"github.com/sebas/switchboard/internal/bufpool"
+)
+
+const (
+	// injectBufferFrames bounds buffered audio per injection (10s at 20ms/frame).
+	// Write blocks when full so a fast producer is paced by gRPC flow control.
+	injectBufferFrames = 500
+
+	// pcmuSilence is the µ-law encoding of a zero sample
+	pcmuSilence = 0xFF
+)
+
+// Encoding identifies the format of injected audio
+type Encoding int
+
+const (
+	EncodingPCM16 Encoding = iota // Signed 16-bit little-endian mono PCM
+	EncodingPCMU                  // G.711 µ-law at 8000 Hz
+)
+
+// ErrInjectionClosed is returned by Write after the injection has ended
+var ErrInjectionClosed = errors.New("injection closed")
+
+// InjectRequest is a request to stream live audio to a client
+type InjectRequest struct {
+	CallID     string   // SIP Call-ID for tracking
+	Codec      string   // Selected codec (payload type string)
+	Encoding   Encoding // Format of data passed to Write
+	SampleRate int      // Sample rate of PCM input (default 8000)
+	LocalAddr  string   // Local IP address to send from
+	LocalPort  int      // Local RTP port to send from (as advertised in SDP)
+	Endpoint   string   // Client IP address
+	Port       int      // Client RTP port
+}
+
+// Injection streams externally produced audio to a client in real time.
+// Audio passed to Write is encoded, split into 20ms frames and paced out
+// as RTP. Close flushes buffered audio and waits for it to be sent.
+// Write and Close must be called from a single goroutine.
+type Injection struct {
+	req      InjectRequest
+	codecCfg *CodecConfig
+	conn     *net.UDPConn
+	remote   *net.UDPAddr
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	pending []byte // encoded bytes not yet forming a full frame
+	closed  bool
+
+	frames chan []byte
+	done   chan struct{}
+
+	framesSent int
+	err        error
+}
+
+// Inject starts a live audio injection for a call. Injection and file
+// playback are mutually exclusive, and Stop cancels either.
+func (s *LocalService) Inject(ctx context.Context, req InjectRequest) (*Injection, error) {
+	if req.CallID == "" || req.Codec == "" || req.Port == 0 {
+		return nil, fmt.Errorf("invalid inject request: missing required fields")
+	}
+
+	codecCfg, err := s.codecs.GetByPayloadTypeString(req.Codec)
+	if err != nil {
+		return nil, fmt.Errorf("unsupported codec: %s", req.Codec)
+	}
+	if req.SampleRate == 0 {
+		req.SampleRate = codecCfg.SampleRate
+	}
+
+	s.mu.Lock()
+	if _, exists := s.activeCalls[req.CallID]; exists {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("playback already active for call %s", req.CallID)
+	}
+
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{Port: req.LocalPort})
+	if err != nil {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("failed to bind to local RTP port %d: %w", req.LocalPort, err)
+	}
+
+	injCtx, cancel := context.WithCancel(ctx)
+	call := &activeCall{cancel: cancel, done: make(chan struct{})}
+	s.activeCalls[req.CallID] = call
+	s.mu.Unlock()
+
+	inj := &Injection{
+		req:      req,
+		codecCfg: codecCfg,
+		conn:     conn,
+		remote:   &net.UDPAddr{Port: req.Port, IP: net.ParseIP(req.Endpoint)},
+		ctx:      injCtx,
+		cancel:   cancel,
+		frames:   make(chan []byte, injectBufferFrames),
+		done:     make(chan struct{}),
+	}
+
+	go func() {
+		defer s.release(req.CallID, call)
+		inj.run()
+	}()
+
+	slog.Info("[Media] Injection started",
+		"call_id", req.CallID,
+		"sample_rate", req.SampleRate,
+		"remote", fmt.Sprintf("%s:%d", req.Endpoint, req.Port))
+
+	return inj, nil
+}
+
+// Write encodes audio and queues it for playout.
+// Blocks while the buffer is full. Returns ErrInjectionClosed once the
+// injection has been closed, stopped or the session has ended.
+func (inj *Injection) Write(data []byte) error {
+	encoded, err := inj.encode(data)
+	if err != nil {
+		return err
+	}
+
+	inj.mu.Lock()
+	if inj.closed {
+		inj.mu.Unlock()
+		return ErrInjectionClosed
+	}
+	inj.pending = append(inj.pending, encoded...)
+	var frames [][]byte
+	for len(inj.pending) >= frameSize {
+		frames = append(frames, inj.pending[:frameSize:frameSize])
+		inj.pending = inj.pending[frameSize:]
+	}
+	inj.pending = append([]byte(nil), inj.pending...)
+	inj.mu.Unlock()
+
+	for _, frame := range frames {
+		select {
+		case inj.frames <- frame:
+		case <-inj.done:
+			return ErrInjectionClosed
+		}
+	}
+	return nil
+}
+
+// Close flushes any partial frame (padded with silence), waits for buffered
+// audio to be sent and returns the number of frames sent.
+func (inj *Injection) Close() (int, error) {
+	inj.mu.Lock()
+	if inj.closed {
+		inj.mu.Unlock()
+		<-inj.done
+		return inj.framesSent, inj.err
+	}
+	inj.closed = true
+	var tail []byte
+	if len(inj.pending) > 0 {
+		tail = make([]byte, frameSize)
+		n := copy(tail, inj.pending)
+		for i := n; i < frameSize; i++ {
+			tail[i] = pcmuSilence
+		}
+		inj.pending = nil
+	}
+	inj.mu.Unlock()
+
+	if tail != nil {
+		select {
+		case inj.frames <- tail:
+		case <-inj.done:
+		}
+	}
+	close(inj.frames)
+
+	<-inj.done
+	return inj.framesSent, inj.err
+}
+
+// Abort stops the injection immediately, discarding buffered audio.
+func (inj *Injection) Abort() {
+	inj.cancel()
+	<-inj.done
+}
+
+// encode converts input audio to the session codec.
+func (inj *Injection) encode(data []byte) ([]byte, error) {
+	switch inj.req.Encoding {
+	case EncodingPCMU:
+		return append([]byte(nil), data...), nil
+	case EncodingPCM16:
+		if len(data)%2 != 0 {
+			return nil, fmt.Errorf("PCM payload length %d is not a whole number of samples", len(data))
+		}
+		if inj.req.SampleRate == inj.codecCfg.SampleRate {
+			return PCMToPCMU(data), nil
+		}
+		pcm, err := ResampleAudio(&AudioFile{
+			AudioFormat:   1,
+			SampleRate:    uint32(inj.req.SampleRate),
+			NumChannels:   1,
+			BitsPerSample: 16,
+			PCMData:       data,
+		})
+		if err != nil {
+			return nil, err
+		}
+		return PCMToPCMU(pcm), nil
+	default:
+		return nil, fmt.Errorf("unsupported encoding: %d", inj.req.Encoding)
+	}
+}
+
+// run paces queued frames out as RTP until the queue is drained or canceled.
+func (inj *Injection) run() {
+	defer close(inj.done)
+	defer func() { _ = inj.conn.Close() }()
+
+	rtpSeq := GenerateSequenceStart()
+	rtpTs := GenerateTimestampStart()
+	ssrc := GenerateSSRC()
+	marker := true // first packet of a talkspurt
+
+	out := bufpool.GetFrame()
+	defer bufpool.PutFrame(out)
+
+	ticker := time.NewTicker(frameDuration)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-inj.ctx.Done():
+			slog.Info("[Media] Injection canceled", "call_id", inj.req.CallID, "frames_sent", inj.framesSent)
+			inj.markClosed()
+			return
+		case <-ticker.C:
+		}
+
+		var frame []byte
+		select {
+		case f, ok := <-inj.frames:
+			if !ok {
+				slog.Info("[Media] Injection complete", "call_id", inj.req.CallID, "frames_sent", inj.framesSent)
+				return
+			}
+			frame = f
+		default:
+			// Underrun: keep the timestamp in step with wall clock and
+			// flag the next packet as the start of a new talkspurt
+			rtpTs += frameSize
+			marker = true
+			continue
+		}
+
+		packet := rtp.Packet{
+			Header: rtp.Header{
+				Version:        2,
+				Marker:         marker,
+				PayloadType:    uint8(inj.codecCfg.PayloadType),
+				SequenceNumber: rtpSeq,
+				Timestamp:      rtpTs,
+				SSRC:           ssrc,
+			},
+			Payload: frame,
+		}
+
+		n, err := packet.MarshalTo(*out)
+		if err != nil {
+			inj.err = fmt.Errorf("failed to marshal RTP packet: %w", err)
+			inj.markClosed()
+			return
+		}
+		if _, err := inj.conn.WriteToUDP((*out)[:n], inj.remote); err != nil {
+			inj.err = fmt.Errorf("failed to send RTP packet to %s: %w", inj.remote, err)
+			inj.markClosed()
+			return
+		}
+
+		inj.framesSent++
+		rtpSeq++
+		rtpTs += frameSize
+		marker = false
+	}
+}
+
+// markClosed rejects further writes after the pacer has stopped.
+func (inj *Injection) markClosed() {
+	inj.mu.Lock()
+	inj.closed = true
+	inj.mu.Unlock()
+}
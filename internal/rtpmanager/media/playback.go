@@ -0,0 +1,184 @@
+package media
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+// ErrNoPlayback is returned when a call has no active file playback
+var ErrNoPlayback = errors.New("no active playback")
+
+// PlaybackPosition reports where an active playback is
+type PlaybackPosition struct {
+	Position time.Duration
+	Duration time.Duration
+	Paused   bool
+}
+
+// playback holds the control state of an active file playback.
+// The streaming goroutine pulls frame indexes from next(); control calls
+// from gRPC handlers change what it returns.
+type playback struct {
+	mu     sync.Mutex
+	frame  int // next frame to send
+	total  int
+	loop   int // frame to wrap to at the end, -1 to stop
+	paused bool
+	seekTo int           // pending seek target frame, -1 if none
+	wake   chan struct{} // signaled on resume and seek while paused
+}
+
+func newPlayback(total, loop int, start time.Duration) *playback {
+	return &playback{
+		frame:  clampFrame(int(start/frameDuration), total),
+		total:  total,
+		loop:   loop,
+		seekTo: -1,
+		wake:   make(chan struct{}, 1),
+	}
+}
+
+// next returns the index of the next frame to send, blocking while paused.
+// jumped is true when the frame does not follow the previous one (after a
+// seek or pause). ok is false at the end of the audio or when ctx is done.
+func (p *playback) next(ctx context.Context) (frame int, jumped bool, ok bool) {
+	for {
+		p.mu.Lock()
+		if p.seekTo >= 0 {
+			p.frame = p.seekTo
+			p.seekTo = -1
+			jumped = true
+		}
+		if !p.paused {
+			if p.frame >= p.total && p.loop >= 0 && p.loop < p.total {
+				p.frame = p.loop
+			}
+			frame = p.frame
+			if frame >= p.total {
+				p.mu.Unlock()
+				return frame, jumped, false
+			}
+			p.frame++
+			p.mu.Unlock()
+			return frame, jumped, true
+		}
+		p.mu.Unlock()
+
+		jumped = true
+		select {
+		case <-ctx.Done():
+			return 0, jumped, false
+		case <-p.wake:
+		}
+	}
+}
+
+// position returns the current playback position
+func (p *playback) position() PlaybackPosition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	frame := p.frame
+	if p.seekTo >= 0 {
+		frame = p.seekTo
+	}
+	return PlaybackPosition{
+		Position: time.Duration(frame) * frameDuration,
+		Duration: time.Duration(p.total) * frameDuration,
+		Paused:   p.paused,
+	}
+}
+
+func (p *playback) setPaused(paused bool) {
+	p.mu.Lock()
+	p.paused = paused
+	p.mu.Unlock()
+	p.signal()
+}
+
+// seek moves playback to an absolute position, or relative to the current
+// position when relative is set. Targets are clamped to the audio.
+func (p *playback) seek(offset time.Duration, relative bool) {
+	p.mu.Lock()
+	target := int(offset / frameDuration)
+	if relative {
+		current := p.frame
+		if p.seekTo >= 0 {
+			current = p.seekTo
+		}
+		target += current
+	}
+	p.seekTo = clampFrame(target, p.total)
+	p.mu.Unlock()
+	p.signal()
+}
+
+func (p *playback) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func clampFrame(frame, total int) int {
+	if frame < 0 {
+		return 0
+	}
+	if frame > total {
+		return total
+	}
+	return frame
+}
+
+// Pause suspends the active playback for a call. Nothing is sent while paused.
+func (s *LocalService) Pause(callID string) (PlaybackPosition, error) {
+	pb, err := s.playback(callID)
+	if err != nil {
+		return PlaybackPosition{}, err
+	}
+	pb.setPaused(true)
+	return pb.position(), nil
+}
+
+// Resume continues a paused playback.
+func (s *LocalService) Resume(callID string) (PlaybackPosition, error) {
+	pb, err := s.playback(callID)
+	if err != nil {
+		return PlaybackPosition{}, err
+	}
+	pb.setPaused(false)
+	return pb.position(), nil
+}
+
+// Seek moves the active playback to offset from the start, or by offset from
+// the current position when relative is set.
+func (s *LocalService) Seek(callID string, offset time.Duration, relative bool) (PlaybackPosition, error) {
+	pb, err := s.playback(callID)
+	if err != nil {
+		return PlaybackPosition{}, err
+	}
+	pb.seek(offset, relative)
+	return pb.position(), nil
+}
+
+// Position reports the position of the active playback for a call.
+func (s *LocalService) Position(callID string) (PlaybackPosition, error) {
+	pb, err := s.playback(callID)
+	if err != nil {
+		return PlaybackPosition{}, err
+	}
+	return pb.position(), nil
+}
+
+func (s *LocalService) playback(callID string) (*playback, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	pb, ok := s.playbacks[callID]
+	if !ok {
+		return nil, ErrNoPlayback
+	}
+	return pb, nil
+}
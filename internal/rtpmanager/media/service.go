@@ -9,31 +9,46 @@ import (
 	"time"
 
 	"github.com/pion/rtp"
+	"github.com/sebas/switchboard/internal/bufpool"
 )
 
 const (
 	frameSize     = 160 // 160 samples per 20ms frame at 8000 Hz
 	frameDuration = 20 * time.Millisecond
+
+	// stopTimeout bounds how long Stop waits for the RTP port to be released
+	stopTimeout = time.Second
+
+	// progressInterval is how many frames are sent between progress callbacks (1s)
+	progressInterval = 50
 )
 
 // LocalService implements MediaService for in-process media handling
 type LocalService struct {
 	codecs      *CodecManager
-	activeCalls map[string]context.CancelFunc // Track active playback by call ID
+	activeCalls map[string]*activeCall // Track active playback by call ID
+	playbacks   map[string]*playback   // Pause/seek state of file playbacks by call ID
 	mu          sync.RWMutex
 }
 
+// activeCall is a running playback or injection holding a call's RTP port
+type activeCall struct {
+	cancel context.CancelFunc
+	done   chan struct{} // closed once the port is released
+}
+
 // NewLocalService creates a new local media service
 func NewLocalService() *LocalService {
 	return &LocalService{
 		codecs:      NewCodecManager(),
-		activeCalls: make(map[string]context.CancelFunc),
+		activeCalls: make(map[string]*activeCall),
+		playbacks:   make(map[string]*playback),
 	}
 }
 
 // Play implements MediaService.Play - streams audio to client endpoint
 func (s *LocalService) Play(ctx context.Context, req PlayRequest) error {
-	if req.CallID == "" || req.File == "" || req.Codec == "" || req.Port == 0 {
+	if req.CallID == "" || (req.File == "" && req.Tone == nil) || req.Codec == "" || req.Port == 0 {
 		return fmt.Errorf("invalid play request: missing required fields")
 	}
 
@@ -52,16 +67,13 @@ func (s *LocalService) Play(ctx context.Context, req PlayRequest) error {
 
 	// Create cancellation context for this playback
 	playCtx, cancel := context.WithCancel(ctx)
-	s.activeCalls[req.CallID] = cancel
+	call := &activeCall{cancel: cancel, done: make(chan struct{})}
+	s.activeCalls[req.CallID] = call
 	s.mu.Unlock()
 
 	// Start playback asynchronously (returns immediately)
 	go func() {
-		defer func() {
-			s.mu.Lock()
-			delete(s.activeCalls, req.CallID)
-			s.mu.Unlock()
-		}()
+		defer s.release(req.CallID, call)
 
 		if err := s.streamAudio(playCtx, req, codecCfg); err != nil {
 			slog.Error("[Media] Playback failed", "call_id", req.CallID, "error", err)
@@ -75,51 +87,104 @@ func (s *LocalService) Play(ctx context.Context, req PlayRequest) error {
 }
 
 // Stop implements MediaService.Stop - cancels active playback for a call
+// and waits briefly for its RTP port to be released, so the port can be
+// reused straight away (e.g. by a bridge)
 func (s *LocalService) Stop(callID string) error {
 	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	cancel, exists := s.activeCalls[callID]
+	call, exists := s.activeCalls[callID]
 	if !exists {
+		s.mu.Unlock()
 		return fmt.Errorf("no active playback for call %s", callID)
 	}
-
-	cancel()
 	delete(s.activeCalls, callID)
+	s.mu.Unlock()
+
+	call.cancel()
+	select {
+	case <-call.done:
+	case <-time.After(stopTimeout):
+		slog.Warn("[Media] Timed out waiting for playback to stop", "call_id", callID)
+	}
 	return nil
 }
 
+// release unregisters a finished playback or injection.
+func (s *LocalService) release(callID string, call *activeCall) {
+	s.mu.Lock()
+	if s.activeCalls[callID] == call {
+		delete(s.activeCalls, callID)
+	}
+	s.mu.Unlock()
+	call.cancel()
+	close(call.done)
+}
+
 // Ready implements MediaService.Ready - checks if service is ready
 func (s *LocalService) Ready() bool {
 	return s.codecs != nil
 }
 
+// renderAudio decodes and encodes the audio for a play request. Every file
+// segment is decoded up front so a bad file fails before any audio is sent,
+// and segments join without gaps. loopFrame is the frame playback wraps to
+// at the end, or -1 to stop there.
+func renderAudio(req PlayRequest, codecCfg *CodecConfig) (encoded []byte, loopFrame int, err error) {
+	if req.Tone != nil {
+		pcm, loopFrom := req.Tone.Render()
+		encoded, err := codecCfg.Resampler(&AudioFile{
+			AudioFormat:   1,
+			SampleRate:    8000,
+			NumChannels:   1,
+			BitsPerSample: 16,
+			PCMData:       pcm,
+		})
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode tone: %w", err)
+		}
+		loopFrame = -1
+		if loopFrom < len(pcm) {
+			loopFrame = loopFrom / (frameSize * 2)
+		}
+		return encoded, loopFrame, nil
+	}
+
+	for _, file := range append([]string{req.File}, req.Playlist...) {
+		audioFile, err := ReadWAVFile(file)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to read audio file %s: %w", file, err)
+		}
+
+		// Resample to codec's format using codec's resampler function
+		segment, err := codecCfg.Resampler(audioFile)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to encode audio %s: %w", file, err)
+		}
+		encoded = append(encoded, segment...)
+	}
+	return encoded, -1, nil
+}
+
 // streamAudio handles the actual RTP streaming to the client
 func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCfg *CodecConfig) error {
 	slog.Info("[Media] Starting playback",
 		"call_id", req.CallID,
 		"file", req.File,
+		"segments", 1+len(req.Playlist),
+		"tone", req.Tone != nil,
 		"codec", req.Codec,
 		"local", fmt.Sprintf("%s:%d", req.LocalAddr, req.LocalPort),
 		"remote", fmt.Sprintf("%s:%d", req.Endpoint, req.Port))
 
-	// Read and parse WAV file
-	audioFile, err := ReadWAVFile(req.File)
+	encodedAudio, loopFrame, err := renderAudio(req, codecCfg)
 	if err != nil {
-		return fmt.Errorf("failed to read audio file: %w", err)
-	}
-
-	// Resample to codec's format using codec's resampler function
-	encodedAudio, err := codecCfg.Resampler(audioFile)
-	if err != nil {
-		return fmt.Errorf("failed to encode audio: %w", err)
+		return err
 	}
 
 	// Bind to local RTP port (the one advertised in SDP)
-	// Use 0.0.0.0 to bind to all interfaces, but use the specific port
+	// The unspecified address binds all interfaces of both families, so the
+	// same socket serves IPv4 and IPv6 remotes
 	localAddr := &net.UDPAddr{
 		Port: req.LocalPort,
-		IP:   net.IPv4zero,
 	}
 
 	conn, err := net.ListenUDP("udp", localAddr)
@@ -128,6 +193,15 @@ func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCf
 	}
 	defer func() { _ = conn.Close() }()
 
+	// Listen for DTMF from the remote party, and analyze its greeting, on
+	// the same port. The listener is stopped before any completion
+	// callback so nothing is reported after the playback has ended.
+	stopDTMF := func() {}
+	if (req.OnDTMF != nil || req.DetectMachine != nil) && codecCfg.PayloadType == 0 {
+		stopDTMF = watchDTMF(conn, req)
+	}
+	defer stopDTMF()
+
 	// Remote client's RTP endpoint
 	clientAddr := &net.UDPAddr{
 		Port: req.Port,
@@ -137,6 +211,19 @@ func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCf
 	// Calculate frame parameters
 	// PCMU uses 8 bits per sample (µ-law encoded), so 160 samples = 160 bytes
 	bytesPerFrame := frameSize // 160 bytes for PCMU (8-bit encoded)
+	frameCount := len(encodedAudio) / bytesPerFrame
+
+	// Register controls for pause/resume/seek
+	pb := newPlayback(frameCount, loopFrame, req.Start)
+	maxFrames := int(req.Duration / frameDuration)
+	s.mu.Lock()
+	s.playbacks[req.CallID] = pb
+	s.mu.Unlock()
+	defer func() {
+		s.mu.Lock()
+		delete(s.playbacks, req.CallID)
+		s.mu.Unlock()
+	}()
 
 	// Initialize RTP header fields per RFC 3550 recommendations:
 	// - Random sequence number to prevent known-plaintext attacks
@@ -146,30 +233,48 @@ func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCf
 	rtpTs := GenerateTimestampStart()
 	ssrc := GenerateSSRC()
 
-	frameCount := (len(encodedAudio) + bytesPerFrame - 1) / bytesPerFrame
 	framesSent := 0
+	lastSent := time.Now()
 
-	slog.Debug("[Media] Streaming setup", "frames_total", frameCount, "bytes_per_frame", bytesPerFrame)
+	slog.Debug("[Media] Streaming setup", "frames_total", frameCount, "bytes_per_frame", bytesPerFrame, "start", req.Start)
+
+	// Every packet is marshaled into the same pooled buffer
+	out := bufpool.GetFrame()
+	defer bufpool.PutFrame(out)
 
 	// Stream frames
-	for i := 0; i+bytesPerFrame <= len(encodedAudio); i += bytesPerFrame {
-		// Check for cancellation (BYE received or Stop() called)
-		select {
-		case <-ctx.Done():
+	for {
+		// Blocks while paused; ends at the last frame or on cancellation
+		// (BYE received or Stop() called)
+		index, jumped, ok := pb.next(ctx)
+		if ctx.Err() != nil {
 			slog.Info("[Media] Playback canceled", "call_id", req.CallID, "frames_sent", framesSent)
+			stopDTMF()
+			if req.OnStopped != nil {
+				req.OnStopped(req.CallID, pb.position())
+			}
 			return nil
-		default:
+		}
+		if !ok || (maxFrames > 0 && framesSent >= maxFrames) {
+			break
 		}
 
-		frame := encodedAudio[i : i+bytesPerFrame]
+		if jumped {
+			// Keep the RTP clock in step with wall time across the gap
+			if gap := time.Since(lastSent) - frameDuration; gap > 0 {
+				rtpTs += uint32(gap / time.Millisecond * 8)
+			}
+		}
+
+		frame := encodedAudio[index*bytesPerFrame : (index+1)*bytesPerFrame]
 
-		// Create RTP packet
-		packet := &rtp.Packet{
+		// Create RTP packet; the marker flags the discontinuity after a seek or pause
+		packet := rtp.Packet{
 			Header: rtp.Header{
 				Version:        2,
 				Padding:        false,
 				Extension:      false,
-				Marker:         false,
+				Marker:         jumped,
 				PayloadType:    uint8(codecCfg.PayloadType),
 				SequenceNumber: rtpSeq,
 				Timestamp:      rtpTs,
@@ -179,24 +284,30 @@ func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCf
 		}
 
 		// Marshal and send
-		data, err := packet.Marshal()
+		n, err := packet.MarshalTo(*out)
 		if err != nil {
 			return fmt.Errorf("failed to marshal RTP packet: %w", err)
 		}
 
-		if _, err := conn.WriteToUDP(data, clientAddr); err != nil {
+		if _, err := conn.WriteToUDP((*out)[:n], clientAddr); err != nil {
 			return fmt.Errorf("failed to send RTP packet to %s:%d: %w", req.Endpoint, req.Port, err)
 		}
 
 		framesSent++
 		rtpSeq++
 		rtpTs += frameSize
+		lastSent = time.Now()
+
+		if req.OnProgress != nil && framesSent%progressInterval == 0 {
+			req.OnProgress(req.CallID, pb.position())
+		}
 
 		// Rate-limit to real-time playback speed (20ms per frame)
 		time.Sleep(frameDuration)
 	}
 
 	slog.Info("[Media] Playback complete", "call_id", req.CallID, "frames_sent", framesSent, "total_frames", frameCount)
+	stopDTMF()
 
 	// Call the completion callback if provided
 	if req.OnComplete != nil {
@@ -208,3 +319,56 @@ func (s *LocalService) streamAudio(ctx context.Context, req PlayRequest, codecCf
 
 	return nil
 }
+
+// watchDTMF reads the remote party's PCMU audio from conn and reports
+// in-band DTMF digits, and whether a person or a machine answered if
+// asked to. The returned function closes conn and waits for the reader to
+// exit; it is safe to call more than once.
+func watchDTMF(conn *net.UDPConn, req PlayRequest) (stop func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		detector := NewDTMFDetector()
+		var machine *MachineDetector
+		if req.DetectMachine != nil && req.OnMachine != nil {
+			machine = NewMachineDetector(*req.DetectMachine)
+		}
+		frame := bufpool.GetFrame()
+		defer bufpool.PutFrame(frame)
+		buf := *frame
+		var packet rtp.Packet
+		for {
+			n, _, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				return // Closed by stop
+			}
+			if err := packet.Unmarshal(buf[:n]); err != nil || packet.PayloadType != 0 {
+				continue
+			}
+			if machine != nil {
+				if result, ok := machine.ProcessPCMU(packet.Payload); ok {
+					slog.Info("[Media] Machine detection",
+						"call_id", req.CallID,
+						"answered_by", result.AnsweredBy,
+						"reason", result.Reason,
+						"duration", result.Duration,
+					)
+					req.OnMachine(req.CallID, result)
+				}
+			}
+			if req.OnDTMF == nil {
+				continue
+			}
+			if digit, ok := detector.ProcessPCMU(packet.Payload); ok {
+				slog.Debug("[Media] DTMF received", "call_id", req.CallID, "digit", string(digit))
+				req.OnDTMF(req.CallID, string(digit))
+			}
+		}
+	}()
+
+	return sync.OnceFunc(func() {
+		_ = conn.Close()
+		<-done
+	})
+}
@@ -0,0 +1,259 @@
+package media
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// toneAmplitude is the peak level of a generated tone (about -10 dBFS,
+	// split across its frequencies)
+	toneAmplitude = 10000
+
+	// dtmfDigitDuration and dtmfGapDuration pace generated DTMF strings
+	dtmfDigitDuration = 100 * time.Millisecond
+	dtmfGapDuration   = 100 * time.Millisecond
+)
+
+// DefaultTonePlan is used when no country is given
+const DefaultTonePlan = "us"
+
+// tonePlans holds call progress tones by country, in indications.conf
+// syntax (see ParseTone). Sources: ITU-T E.180 supplement 2.
+var tonePlans = map[string]map[string]string{
+	"us": {
+		"dial":        "350+440",
+		"ringback":    "440+480/2000,0/4000",
+		"busy":        "480+620/500,0/500",
+		"congestion":  "480+620/250,0/250",
+		"callwaiting": "440/300,0/10000",
+	},
+	"uk": {
+		"dial":        "350+440",
+		"ringback":    "400+450/400,0/200,400+450/400,0/2000",
+		"busy":        "400/375,0/375",
+		"congestion":  "400/400,0/350,400/225,0/525",
+		"callwaiting": "400/100,0/4000",
+	},
+	"de": {
+		"dial":        "425",
+		"ringback":    "425/1000,0/4000",
+		"busy":        "425/480,0/480",
+		"congestion":  "425/240,0/240",
+		"callwaiting": "425/200,0/200,425/200,0/5000",
+	},
+	"fr": {
+		"dial":        "440",
+		"ringback":    "440/1500,0/3500",
+		"busy":        "440/500,0/500",
+		"congestion":  "440/250,0/250",
+		"callwaiting": "440/300,0/10000",
+	},
+	"au": {
+		"dial":        "413+438",
+		"ringback":    "400+450/400,0/200,400+450/400,0/2000",
+		"busy":        "425/375,0/375",
+		"congestion":  "425/375,0/375,420/375,0/375",
+		"callwaiting": "425/200,0/200,425/200,0/4400",
+	},
+	"jp": {
+		"dial":        "400",
+		"ringback":    "400/1000,0/2000",
+		"busy":        "400/500,0/500",
+		"congestion":  "400/500,0/500",
+		"callwaiting": "400/500,0/100,400/500,0/3500",
+	},
+	"itu": {
+		"dial":        "425",
+		"ringback":    "425/1000,0/4000",
+		"busy":        "425/500,0/500",
+		"congestion":  "425/250,0/250",
+		"callwaiting": "425/200,0/200,425/200,0/4400",
+	},
+}
+
+// dtmfFrequencies maps DTMF digits to their low and high frequencies
+var dtmfFrequencies = map[rune][2]float64{
+	'1': {697, 1209}, '2': {697, 1336}, '3': {697, 1477}, 'A': {697, 1633},
+	'4': {770, 1209}, '5': {770, 1336}, '6': {770, 1477}, 'B': {770, 1633},
+	'7': {852, 1209}, '8': {852, 1336}, '9': {852, 1477}, 'C': {852, 1633},
+	'*': {941, 1209}, '0': {941, 1336}, '#': {941, 1477}, 'D': {941, 1633},
+}
+
+// ToneSegment is one step of a tone cadence. Freqs are summed; an empty
+// Freqs is silence. A zero Duration plays the segment continuously.
+type ToneSegment struct {
+	Freqs    []float64
+	Duration time.Duration
+}
+
+// Tone is a generated tone: Intro is played once, then Cadence repeats.
+type Tone struct {
+	Intro   []ToneSegment
+	Cadence []ToneSegment
+}
+
+// TonePlans returns the names of the built-in country tone plans.
+func TonePlans() []string {
+	names := make([]string, 0, len(tonePlans))
+	for name := range tonePlans {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// LookupTone resolves a tone by name in a country's plan. name may be a
+// plan tone ("ringback", "busy", "congestion", "dial", "callwaiting"),
+// a DTMF string ("dtmf:123#"), or a custom spec ("440+480/2000,0/4000").
+func LookupTone(name, country string) (*Tone, error) {
+	if digits, ok := strings.CutPrefix(name, "dtmf:"); ok {
+		return dtmfTone(digits)
+	}
+
+	if country == "" {
+		country = DefaultTonePlan
+	}
+	plan, ok := tonePlans[strings.ToLower(country)]
+	if !ok {
+		return nil, fmt.Errorf("unknown tone plan: %s", country)
+	}
+	if spec, ok := plan[strings.ToLower(name)]; ok {
+		return ParseTone(spec)
+	}
+
+	return ParseTone(name)
+}
+
+// ParseTone parses a tone in indications.conf syntax: comma-separated
+// segments of "freq[+freq...][/duration_ms]", where frequency 0 is silence
+// and a leading "!" marks a segment played only once before the cadence
+// repeats, e.g. "440+480/2000,0/4000".
+func ParseTone(spec string) (*Tone, error) {
+	tone := &Tone{}
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		once := strings.HasPrefix(part, "!")
+		part = strings.TrimPrefix(part, "!")
+
+		freqPart, durPart, hasDur := strings.Cut(part, "/")
+		var seg ToneSegment
+		if hasDur {
+			ms, err := strconv.Atoi(durPart)
+			if err != nil || ms <= 0 {
+				return nil, fmt.Errorf("invalid tone duration %q in %q", durPart, spec)
+			}
+			seg.Duration = time.Duration(ms) * time.Millisecond
+		}
+		for _, f := range strings.Split(freqPart, "+") {
+			freq, err := strconv.ParseFloat(f, 64)
+			if err != nil || freq < 0 || freq >= 4000 {
+				return nil, fmt.Errorf("invalid tone frequency %q in %q", f, spec)
+			}
+			if freq > 0 {
+				seg.Freqs = append(seg.Freqs, freq)
+			}
+		}
+
+		if once {
+			if len(tone.Cadence) > 0 {
+				return nil, fmt.Errorf("play-once segments must come first in %q", spec)
+			}
+			tone.Intro = append(tone.Intro, seg)
+		} else {
+			tone.Cadence = append(tone.Cadence, seg)
+		}
+	}
+	return tone, nil
+}
+
+// dtmfTone builds a play-once tone sounding each digit in turn.
+func dtmfTone(digits string) (*Tone, error) {
+	if digits == "" {
+		return nil, fmt.Errorf("dtmf tone requires digits")
+	}
+	tone := &Tone{}
+	for _, d := range strings.ToUpper(digits) {
+		freqs, ok := dtmfFrequencies[d]
+		if !ok {
+			return nil, fmt.Errorf("invalid DTMF digit: %c", d)
+		}
+		tone.Intro = append(tone.Intro,
+			ToneSegment{Freqs: freqs[:], Duration: dtmfDigitDuration},
+			ToneSegment{Duration: dtmfGapDuration},
+		)
+	}
+	return tone, nil
+}
+
+// Render generates 8 kHz 16-bit mono PCM for the tone. It returns the
+// intro followed by one cycle of the cadence; loopFrom is the byte offset
+// where the cadence starts. The cycle is repeated as needed to end on an
+// RTP frame boundary so it can be looped without gaps.
+func (t *Tone) Render() (pcm []byte, loopFrom int) {
+	for _, seg := range t.Intro {
+		pcm = appendSegment(pcm, seg)
+	}
+	loopFrom = len(pcm)
+
+	var cycle []byte
+	for _, seg := range t.Cadence {
+		cycle = appendSegment(cycle, seg)
+	}
+	if len(cycle) == 0 {
+		return pcm, loopFrom
+	}
+
+	// Repeat the cycle until it fills a whole number of frames
+	frameBytes := frameSize * 2
+	repeats := frameBytes / gcd(len(cycle), frameBytes)
+	for i := 0; i < repeats; i++ {
+		pcm = append(pcm, cycle...)
+	}
+
+	// Pad the intro to a frame boundary so the loop point is frame aligned
+	if pad := loopFrom % frameBytes; pad != 0 {
+		padding := make([]byte, frameBytes-pad)
+		pcm = append(pcm[:loopFrom], append(padding, pcm[loopFrom:]...)...)
+		loopFrom += len(padding)
+	}
+	return pcm, loopFrom
+}
+
+// appendSegment renders one segment. Continuous segments render one
+// second, which holds a whole number of cycles of any integer frequency.
+func appendSegment(pcm []byte, seg ToneSegment) []byte {
+	duration := seg.Duration
+	if duration == 0 {
+		duration = time.Second
+	}
+	samples := int(duration * 8000 / time.Second)
+
+	start := len(pcm)
+	pcm = append(pcm, make([]byte, samples*2)...)
+	if len(seg.Freqs) == 0 {
+		return pcm
+	}
+
+	amplitude := toneAmplitude / float64(len(seg.Freqs))
+	for i := 0; i < samples; i++ {
+		var v float64
+		for _, f := range seg.Freqs {
+			v += math.Sin(2 * math.Pi * f * float64(i) / 8000)
+		}
+		binary.LittleEndian.PutUint16(pcm[start+i*2:], uint16(int16(v*amplitude)))
+	}
+	return pcm
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
@@ -1,9 +1,15 @@
 package media
 
+import "time"
+
 // PlayRequest is a request to play audio to a client
 type PlayRequest struct {
 	CallID     string                                      // SIP Call-ID for tracking
 	File       string                                      // Path to audio file (e.g., "audio/demo.wav")
+	Playlist   []string                                    // Further files played gaplessly after File
+	Start      time.Duration                               // Offset into the audio to start from
+	Tone       *Tone                                       // Generated tone played instead of File
+	Duration   time.Duration                               // Stop after this long (0 = end of audio, or until stopped for looping tones)
 	Codec      string                                      // Selected codec (PCMU, PCMA, Opus, G729)
 	LocalAddr  string                                      // Local IP address to send from
 	LocalPort  int                                         // Local RTP port to send from (as advertised in SDP)
@@ -11,4 +17,13 @@ type PlayRequest struct {
 	Port       int                                         // Client RTP port (e.g., 50162)
 	OnComplete func(callID string, data interface{}) error // Optional callback when playback finishes
 	OnError    func(callID string, err error)              // Optional callback when playback fails
+	OnProgress func(callID string, pos PlaybackPosition)   // Optional callback about once a second
+	OnStopped  func(callID string, pos PlaybackPosition)   // Optional callback when playback is canceled
+	OnDTMF     func(callID string, digit string)           // Optional callback for in-band DTMF received during playback (PCMU only)
+
+	// DetectMachine, if set, analyzes the remote party's audio during
+	// playback and reports whether a person or a machine answered to
+	// OnMachine, once (PCMU only)
+	DetectMachine *MachineDetectionConfig
+	OnMachine     func(callID string, result MachineDetection)
 }
@@ -2,39 +2,122 @@ package portpool
 
 import (
 	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
 	"sync"
+	"time"
 )
 
-// PortPool manages a pool of RTP ports for media sessions.
-// Ports are allocated in pairs (even for RTP, odd for RTCP).
+const (
+	// busyCooldown is how long a pair found in use by another process is
+	// skipped before it is probed again
+	busyCooldown = 30 * time.Second
+
+	// pressureWarning is the utilization at which allocation logs a warning
+	pressureWarning = 0.9
+)
+
+// Range is an inclusive span of UDP ports.
+type Range struct {
+	Min int
+	Max int
+}
+
+func (r Range) String() string {
+	return fmt.Sprintf("%d-%d", r.Min, r.Max)
+}
+
+// ParseRanges parses comma-separated port ranges such as "10000-20000,30000-30999".
+func ParseRanges(s string) ([]Range, error) {
+	var ranges []Range
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		lo, hi, ok := strings.Cut(part, "-")
+		if !ok {
+			return nil, fmt.Errorf("invalid port range %q (want min-max)", part)
+		}
+		minPort, err1 := strconv.Atoi(strings.TrimSpace(lo))
+		maxPort, err2 := strconv.Atoi(strings.TrimSpace(hi))
+		if err1 != nil || err2 != nil || minPort < 1 || maxPort > 65535 || minPort >= maxPort {
+			return nil, fmt.Errorf("invalid port range %q", part)
+		}
+		ranges = append(ranges, Range{Min: minPort, Max: maxPort})
+	}
+	if len(ranges) == 0 {
+		return nil, fmt.Errorf("no port ranges given")
+	}
+	return ranges, nil
+}
+
+// Stats reports allocation pressure.
+type Stats struct {
+	Total       int     // Port pairs across all ranges
+	Allocated   int     // Pairs held by sessions
+	Available   int     // Pairs ready to allocate
+	Busy        int     // Pairs skipped because another process holds a port
+	Utilization float64 // Allocated / Total
+	Conflicts   int64   // Pairs found in use by another process
+	Exhausted   int64   // Allocations that failed for lack of ports
+}
+
+// PortPool manages RTP/RTCP port pairs for media sessions.
+// Each pair is an even RTP port and the odd RTCP port above it. Pairs are
+// handed out least recently used first, so a released port is not reused
+// while stray packets for its old session may still arrive. Before a pair
+// is handed out both ports are test-bound; pairs held by another process
+// are set aside for busyCooldown instead of causing bind failures later.
 type PortPool struct {
 	mu        sync.Mutex
-	minPort   int
-	maxPort   int
-	available map[int]bool // port -> available
-	allocated map[int]bool // port -> allocated
-}
-
-// NewPortPool creates a new port pool with the given range.
-// minPort should be even, maxPort should be odd.
-func NewPortPool(minPort, maxPort int) *PortPool {
-	// Ensure minPort is even
-	if minPort%2 != 0 {
-		minPort++
-	}
+	ranges    []Range
+	total     int
+	free      []int             // RTP ports ready to allocate, oldest release first
+	allocated map[int]bool      // RTP port -> allocated
+	busy      map[int]time.Time // RTP port -> when to probe again
+	conflicts int64
+	exhausted int64
 
-	available := make(map[int]bool)
-	// Add even ports (RTP ports) to available pool
-	for port := minPort; port < maxPort; port += 2 {
-		available[port] = true
-	}
+	// probe reports whether both ports of a pair can be bound
+	probe func(rtpPort int) bool
+}
 
-	return &PortPool{
-		minPort:   minPort,
-		maxPort:   maxPort,
-		available: available,
+// NewPortPool creates a port pool over one or more ranges. Range bounds are
+// narrowed to whole even/odd pairs; overlapping ranges are merged.
+func NewPortPool(ranges ...Range) *PortPool {
+	p := &PortPool{
+		ranges:    ranges,
 		allocated: make(map[int]bool),
+		busy:      make(map[int]time.Time),
+		probe:     probeUDPPair,
 	}
+
+	seen := make(map[int]bool)
+	for _, r := range ranges {
+		start := r.Min
+		if start%2 != 0 {
+			start++ // RTP ports are even
+		}
+		for port := start; port+1 <= r.Max; port += 2 {
+			if !seen[port] {
+				seen[port] = true
+				p.free = append(p.free, port)
+			}
+		}
+	}
+	p.total = len(p.free)
+	return p
+}
+
+// NewVirtualPortPool creates a port pool that does not check whether ports
+// are in use, for simulated sessions that never bind them.
+func NewVirtualPortPool(ranges ...Range) *PortPool {
+	p := NewPortPool(ranges...)
+	p.probe = func(int) bool { return true }
+	return p
 }
 
 // Allocate returns a pair of ports (RTP, RTCP) or an error if none available.
@@ -42,17 +125,34 @@ func (p *PortPool) Allocate() (rtpPort, rtcpPort int, err error) {
 	p.mu.Lock()
 	defer p.mu.Unlock()
 
-	// Find first available port
-	for port := range p.available {
-		delete(p.available, port)
-		p.allocated[port] = true
+	p.reviveBusy(time.Now())
+
+	for len(p.free) > 0 {
+		port := p.free[0]
+		p.free = p.free[1:]
 
-		rtpPort = port
-		rtcpPort = port + 1
-		return rtpPort, rtcpPort, nil
+		if !p.probe(port) {
+			p.conflicts++
+			p.busy[port] = time.Now().Add(busyCooldown)
+			slog.Warn("[PortPool] Port pair in use by another process",
+				"rtp_port", port,
+				"rtcp_port", port+1,
+			)
+			continue
+		}
+
+		p.allocated[port] = true
+		if p.total > 0 && float64(len(p.allocated))/float64(p.total) >= pressureWarning {
+			slog.Warn("[PortPool] Port pool under pressure",
+				"allocated", len(p.allocated),
+				"total", p.total,
+			)
+		}
+		return port, port + 1, nil
 	}
 
-	return 0, 0, fmt.Errorf("no ports available in pool (range %d-%d)", p.minPort, p.maxPort)
+	p.exhausted++
+	return 0, 0, fmt.Errorf("no ports available in pool (ranges %s, %d busy)", p.rangesString(), len(p.busy))
 }
 
 // Release returns a port pair to the pool.
@@ -62,7 +162,7 @@ func (p *PortPool) Release(rtpPort int) {
 
 	if _, ok := p.allocated[rtpPort]; ok {
 		delete(p.allocated, rtpPort)
-		p.available[rtpPort] = true
+		p.free = append(p.free, rtpPort)
 	}
 }
 
@@ -70,7 +170,7 @@ func (p *PortPool) Release(rtpPort int) {
 func (p *PortPool) Available() int {
 	p.mu.Lock()
 	defer p.mu.Unlock()
-	return len(p.available)
+	return len(p.free)
 }
 
 // Allocated returns the number of allocated port pairs.
@@ -79,3 +179,63 @@ func (p *PortPool) Allocated() int {
 	defer p.mu.Unlock()
 	return len(p.allocated)
 }
+
+// Stats returns allocation pressure metrics.
+func (p *PortPool) Stats() Stats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	p.reviveBusy(time.Now())
+	stats := Stats{
+		Total:     p.total,
+		Allocated: len(p.allocated),
+		Available: len(p.free),
+		Busy:      len(p.busy),
+		Conflicts: p.conflicts,
+		Exhausted: p.exhausted,
+	}
+	if p.total > 0 {
+		stats.Utilization = float64(stats.Allocated) / float64(p.total)
+	}
+	return stats
+}
+
+// Ranges returns the configured port ranges.
+func (p *PortPool) Ranges() []Range {
+	return p.ranges
+}
+
+// reviveBusy returns busy pairs whose cooldown has passed to the free list (must hold lock).
+func (p *PortPool) reviveBusy(now time.Time) {
+	for port, retryAt := range p.busy {
+		if now.After(retryAt) {
+			delete(p.busy, port)
+			p.free = append(p.free, port)
+		}
+	}
+}
+
+func (p *PortPool) rangesString() string {
+	parts := make([]string, len(p.ranges))
+	for i, r := range p.ranges {
+		parts[i] = r.String()
+	}
+	return strings.Join(parts, ",")
+}
+
+// probeUDPPair test-binds the RTP and RTCP ports. The sockets are closed
+// straight away; media and bridges bind the ports themselves.
+func probeUDPPair(rtpPort int) bool {
+	rtp, err := net.ListenUDP("udp", &net.UDPAddr{Port: rtpPort})
+	if err != nil {
+		return false
+	}
+	defer rtp.Close()
+
+	rtcp, err := net.ListenUDP("udp", &net.UDPAddr{Port: rtpPort + 1})
+	if err != nil {
+		return false
+	}
+	rtcp.Close()
+	return true
+}
@@ -1,9 +1,12 @@
 package sdp
 
 import (
-	"log/slog"
+	"bytes"
+	"net"
+	"strconv"
 
 	"github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/bufpool"
 )
 
 // RTPEndpointInfo contains RTP server endpoint details
@@ -22,7 +25,9 @@ func BuildResponseSDP(serverAddr string, serverPort int, selectedCodec string) [
 	return createResponseSDP(rtpInfo, selectedCodec)
 }
 
-// createResponseSDP creates an SDP response with the selected codec
+// createResponseSDP creates an SDP response with the selected codec. The
+// description is written straight into a pooled buffer, in the layout
+// pion's marshaler produces, since answers are built for every call.
 func createResponseSDP(rtpInfo *RTPEndpointInfo, selectedCodec string) []byte {
 	if rtpInfo == nil {
 		return nil
@@ -33,72 +38,73 @@ func createResponseSDP(rtpInfo *RTPEndpointInfo, selectedCodec string) []byte {
 		selectedCodec = "0"
 	}
 	formats := []string{selectedCodec}
-
-	// Create a basic SDP response
-	sessionDesc := &sdp.SessionDescription{
-		Origin: sdp.Origin{
-			Username:       "switchboard",
-			SessionID:      1,
-			SessionVersion: 1,
-			NetworkType:    "IN",
-			AddressType:    "IP4",
-			UnicastAddress: rtpInfo.ServerAddr,
-		},
-		SessionName: "Switchboard Media Session",
-		ConnectionInformation: &sdp.ConnectionInformation{
-			NetworkType: "IN",
-			AddressType: "IP4",
-			Address: &sdp.Address{
-				Address: rtpInfo.ServerAddr,
-			},
-		},
-		TimeDescriptions: []sdp.TimeDescription{
-			{
-				Timing: sdp.Timing{
-					StartTime: 0,
-					StopTime:  0,
-				},
-			},
-		},
-		MediaDescriptions: []*sdp.MediaDescription{
-			{
-				MediaName: sdp.MediaName{
-					Media:   "audio",
-					Port:    sdp.RangedPort{Value: rtpInfo.ServerPort},
-					Protos:  []string{"RTP", "AVP"},
-					Formats: formats,
-				},
-				Attributes: getResponseAttributes(formats),
-			},
-		},
+	addrType := AddressType(rtpInfo.ServerAddr)
+
+	buf := bufpool.GetBuffer()
+	defer bufpool.PutBuffer(buf)
+
+	buf.WriteString("v=0\r\n")
+	buf.WriteString("o=switchboard 1 1 IN ")
+	writeAddress(buf, addrType, rtpInfo.ServerAddr)
+	buf.WriteString("s=Switchboard Media Session\r\n")
+	buf.WriteString("c=IN ")
+	writeAddress(buf, addrType, rtpInfo.ServerAddr)
+	buf.WriteString("t=0 0\r\n")
+
+	buf.WriteString("m=audio ")
+	buf.Write(strconv.AppendInt(buf.AvailableBuffer(), int64(rtpInfo.ServerPort), 10))
+	buf.WriteString(" RTP/AVP")
+	for _, format := range formats {
+		buf.WriteByte(' ')
+		buf.WriteString(format)
+	}
+	buf.WriteString("\r\n")
+	for _, attr := range getResponseAttributes(formats) {
+		buf.WriteString("a=")
+		buf.WriteString(attr.Key)
+		if attr.Value != "" {
+			buf.WriteByte(':')
+			buf.WriteString(attr.Value)
+		}
+		buf.WriteString("\r\n")
 	}
 
-	// Marshal to bytes
-	sdpBytes, err := sessionDesc.Marshal()
-	if err != nil {
-		slog.Error("Failed to create response SDP", "error", err)
-		return nil
+	return bytes.Clone(buf.Bytes())
+}
+
+// writeAddress writes the address type and address ending an o= or c= line.
+func writeAddress(buf *bytes.Buffer, addrType, addr string) {
+	buf.WriteString(addrType)
+	buf.WriteByte(' ')
+	buf.WriteString(addr)
+	buf.WriteString("\r\n")
+}
+
+// AddressType returns the SDP address type ("IP4" or "IP6") for an address.
+// Hostnames are assumed to be IPv4.
+func AddressType(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return "IP6"
 	}
+	return "IP4"
+}
 
-	return sdpBytes
+// rtpmapMap maps standard codec payload types to rtpmap strings
+var rtpmapMap = map[string]string{
+	"0":   "PCMU/8000",
+	"8":   "PCMA/8000",
+	"18":  "G729/8000",
+	"96":  "opus/48000/2",
+	"97":  "iLBC/8000",
+	"98":  "speex/8000",
+	"101": "telephone-event/8000",
+	"99":  "G723/8000",
+	"100": "G726-32/8000",
 }
 
 // GetCodecAttributes returns SDP attributes for codec rtpmap and fmtp
 func GetCodecAttributes(formats []string) []sdp.Attribute {
-	// Map of standard codec payload types to rtpmap strings
-	rtpmapMap := map[string]string{
-		"0":   "PCMU/8000",
-		"8":   "PCMA/8000",
-		"18":  "G729/8000",
-		"96":  "opus/48000/2",
-		"97":  "iLBC/8000",
-		"98":  "speex/8000",
-		"101": "telephone-event/8000",
-		"99":  "G723/8000",
-		"100": "G726-32/8000",
-	}
-
-	attrs := []sdp.Attribute{}
+	attrs := make([]sdp.Attribute, 0, len(formats)+4)
 
 	// Add rtpmap attributes for each codec
 	for _, format := range formats {
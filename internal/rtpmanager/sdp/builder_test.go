@@ -0,0 +1,83 @@
+package sdp
+
+import (
+	"testing"
+
+	"github.com/pion/sdp/v3"
+)
+
+// pionResponseSDP marshals the response with pion, as the builder did
+// before it wrote into pooled buffers.
+func pionResponseSDP(addr string, port int, codec string) []byte {
+	formats := []string{codec}
+	desc := &sdp.SessionDescription{
+		Origin: sdp.Origin{
+			Username:       "switchboard",
+			SessionID:      1,
+			SessionVersion: 1,
+			NetworkType:    "IN",
+			AddressType:    AddressType(addr),
+			UnicastAddress: addr,
+		},
+		SessionName: "Switchboard Media Session",
+		ConnectionInformation: &sdp.ConnectionInformation{
+			NetworkType: "IN",
+			AddressType: AddressType(addr),
+			Address:     &sdp.Address{Address: addr},
+		},
+		TimeDescriptions: []sdp.TimeDescription{{}},
+		MediaDescriptions: []*sdp.MediaDescription{
+			{
+				MediaName: sdp.MediaName{
+					Media:   "audio",
+					Port:    sdp.RangedPort{Value: port},
+					Protos:  []string{"RTP", "AVP"},
+					Formats: formats,
+				},
+				Attributes: getResponseAttributes(formats),
+			},
+		},
+	}
+	data, _ := desc.Marshal()
+	return data
+}
+
+func TestBuildResponseSDPMatchesPion(t *testing.T) {
+	cases := []struct {
+		addr  string
+		port  int
+		codec string
+	}{
+		{"203.0.113.5", 10000, "0"},
+		{"203.0.113.5", 20000, "8"},
+		{"2001:db8::5", 10002, "101"},
+	}
+	for _, c := range cases {
+		got := string(BuildResponseSDP(c.addr, c.port, c.codec))
+		want := string(pionResponseSDP(c.addr, c.port, c.codec))
+		if got != want {
+			t.Errorf("BuildResponseSDP(%s, %d, %s) =\n%s\nwant\n%s", c.addr, c.port, c.codec, got, want)
+		}
+		var desc sdp.SessionDescription
+		if err := desc.Unmarshal([]byte(got)); err != nil {
+			t.Errorf("BuildResponseSDP(%s, %d, %s) does not parse: %v", c.addr, c.port, c.codec, err)
+		}
+	}
+}
+
+var sink []byte
+
+func BenchmarkBuildResponseSDP(b *testing.B) {
+	b.Run("pion", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			sink = pionResponseSDP("203.0.113.5", 10000, "0")
+		}
+	})
+	b.Run("pooled", func(b *testing.B) {
+		b.ReportAllocs()
+		for b.Loop() {
+			sink = BuildResponseSDP("203.0.113.5", 10000, "0")
+		}
+	})
+}
@@ -0,0 +1,107 @@
+package server
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// eventBuffer is how many events a subscriber may fall behind by before
+// events are dropped for it
+const eventBuffer = 256
+
+// eventSubscriber is one SubscribeEvents stream.
+type eventSubscriber struct {
+	owner   string // Only events of this owner's sessions; all when empty
+	ch      chan *rtpv1.SessionEvent
+	dropped int64 // Guarded by eventHub.mu
+}
+
+// eventHub fans session events out to SubscribeEvents streams. A slow
+// subscriber loses events rather than holding up call handling.
+type eventHub struct {
+	mu   sync.Mutex
+	subs map[*eventSubscriber]struct{}
+}
+
+func newEventHub() *eventHub {
+	return &eventHub{subs: make(map[*eventSubscriber]struct{})}
+}
+
+// subscribe registers a subscriber for the events of owner's sessions.
+func (h *eventHub) subscribe(owner string) *eventSubscriber {
+	sub := &eventSubscriber{owner: owner, ch: make(chan *rtpv1.SessionEvent, eventBuffer)}
+	h.mu.Lock()
+	h.subs[sub] = struct{}{}
+	h.mu.Unlock()
+	return sub
+}
+
+// unsubscribe removes a subscriber.
+func (h *eventHub) unsubscribe(sub *eventSubscriber) {
+	h.mu.Lock()
+	delete(h.subs, sub)
+	h.mu.Unlock()
+	if sub.dropped > 0 {
+		slog.Warn("[Events] Subscriber fell behind, events were dropped", "owner", sub.owner, "dropped", sub.dropped)
+	}
+}
+
+// publish sends an event of a session created by owner to the interested
+// subscribers and returns how many received it.
+func (h *eventHub) publish(owner string, ev *rtpv1.SessionEvent) int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	delivered := 0
+	for sub := range h.subs {
+		if sub.owner != "" && sub.owner != owner {
+			continue
+		}
+		select {
+		case sub.ch <- ev:
+			delivered++
+		default:
+			sub.dropped++
+		}
+	}
+	return delivered
+}
+
+// newSessionEvent returns an event for a session, with its call ID, and
+// the session's owner.
+func (s *Server) newSessionEvent(sessionID string) (*rtpv1.SessionEvent, string) {
+	ev := &rtpv1.SessionEvent{SessionId: sessionID, TimestampMs: time.Now().UnixMilli()}
+	var owner string
+	if sess, ok := s.sessionMgr.GetSession(sessionID); ok {
+		ev.CallId = sess.CallID
+		owner = s.sessionMgr.Owner(sessionID)
+	}
+	return ev, owner
+}
+
+// emitPlayback publishes the playback events that matter outside the
+// PlayAudio or PlayTone stream: DTMF and the end of playback.
+func (s *Server) emitPlayback(pe *rtpv1.PlaybackEvent) {
+	var finished *rtpv1.PlaybackFinished
+	switch e := pe.Event.(type) {
+	case *rtpv1.PlaybackEvent_Dtmf:
+		ev, owner := s.newSessionEvent(pe.SessionId)
+		ev.Event = &rtpv1.SessionEvent_Dtmf{Dtmf: e.Dtmf}
+		s.events.publish(owner, ev)
+		return
+	case *rtpv1.PlaybackEvent_Completed:
+		finished = &rtpv1.PlaybackFinished{Outcome: "completed", PositionMs: e.Completed.DurationMs}
+	case *rtpv1.PlaybackEvent_Stopped:
+		finished = &rtpv1.PlaybackFinished{Outcome: "stopped", PositionMs: e.Stopped.PositionMs}
+	case *rtpv1.PlaybackEvent_Error:
+		finished = &rtpv1.PlaybackFinished{Outcome: "error", Error: e.Error.Message}
+	default:
+		return
+	}
+	ev, owner := s.newSessionEvent(pe.SessionId)
+	ev.Event = &rtpv1.SessionEvent_PlaybackFinished{PlaybackFinished: finished}
+	s.events.publish(owner, ev)
+}
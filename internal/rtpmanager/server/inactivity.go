@@ -0,0 +1,150 @@
+package server
+
+import (
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// inactivityMonitor detects bridged sessions that stop receiving RTP and
+// reports a MediaTimeout to signaling. Reports are streamed to event
+// subscribers; those no subscriber received are queued for the Health
+// RPC of the session's owner, which the signaling pool polls
+// periodically. A queued report is returned on every Health call of its
+// owner until the next call acknowledges it, so a lost response does not
+// lose it.
+type inactivityMonitor struct {
+	server  *Server
+	timeout time.Duration
+
+	mu       sync.Mutex
+	pending  map[string][]*rtpv1.MediaTimeout // owner -> reports not yet acknowledged
+	reported map[string]struct{}              // sessionID -> already queued
+
+	done chan struct{}
+}
+
+// newInactivityMonitor creates a monitor for the given timeout.
+func newInactivityMonitor(s *Server, timeout time.Duration) *inactivityMonitor {
+	return &inactivityMonitor{
+		server:   s,
+		timeout:  timeout,
+		pending:  make(map[string][]*rtpv1.MediaTimeout),
+		reported: make(map[string]struct{}),
+		done:     make(chan struct{}),
+	}
+}
+
+// run checks for idle sessions until stop is called.
+func (im *inactivityMonitor) run() {
+	// Check often enough that detection lags the timeout by at most ~25%
+	interval := im.timeout / 4
+	if interval < time.Second {
+		interval = time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-im.done:
+			return
+		case <-ticker.C:
+			im.check()
+		}
+	}
+}
+
+// check queues a report for each newly idle session.
+func (im *inactivityMonitor) check() {
+	idle := im.server.bridgeMgr.IdleSessions(im.timeout)
+
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	// Sessions receiving RTP again, such as a held call resumed, are
+	// reported anew when they next fall silent
+	silent := make(map[string]bool, len(idle))
+	for _, is := range idle {
+		silent[is.SessionID] = true
+	}
+	for sessionID := range im.reported {
+		if !silent[sessionID] {
+			delete(im.reported, sessionID)
+		}
+	}
+
+	for _, is := range idle {
+		if _, ok := im.reported[is.SessionID]; ok {
+			continue
+		}
+		im.reported[is.SessionID] = struct{}{}
+
+		callID := ""
+		if sess, ok := im.server.sessionMgr.GetSession(is.SessionID); ok {
+			callID = sess.CallID
+		}
+
+		slog.Warn("[Inactivity] RTP timeout",
+			"session_id", is.SessionID,
+			"call_id", callID,
+			"bridge_id", is.BridgeID,
+			"idle", is.Idle.Round(time.Second),
+		)
+
+		mt := &rtpv1.MediaTimeout{
+			SessionId:   is.SessionID,
+			CallId:      callID,
+			IdleSeconds: int32(is.Idle.Seconds()),
+		}
+		ev, owner := im.server.newSessionEvent(is.SessionID)
+		ev.Event = &rtpv1.SessionEvent_MediaTimeout{MediaTimeout: mt}
+		if im.server.events.publish(owner, ev) == 0 {
+			im.pending[owner] = append(im.pending[owner], mt)
+		}
+	}
+}
+
+// health handles an owner's Health call: the reports it acknowledges,
+// received in an earlier response, are cleared, and those still queued
+// for it are returned.
+func (im *inactivityMonitor) health(owner string, acked []string) []*rtpv1.MediaTimeout {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+
+	pending := slices.DeleteFunc(im.pending[owner], func(mt *rtpv1.MediaTimeout) bool {
+		return slices.Contains(acked, mt.SessionId)
+	})
+	if len(pending) == 0 {
+		delete(im.pending, owner)
+		return nil
+	}
+	im.pending[owner] = pending
+	return slices.Clone(pending)
+}
+
+// forget clears state for a destroyed session so a reused ID can be
+// reported again; a report not yet acknowledged is dropped.
+func (im *inactivityMonitor) forget(sessionID string) {
+	im.mu.Lock()
+	defer im.mu.Unlock()
+	delete(im.reported, sessionID)
+	for owner, pending := range im.pending {
+		pending = slices.DeleteFunc(pending, func(mt *rtpv1.MediaTimeout) bool {
+			return mt.SessionId == sessionID
+		})
+		if len(pending) == 0 {
+			delete(im.pending, owner)
+		} else {
+			im.pending[owner] = pending
+		}
+	}
+}
+
+// stop terminates the monitor goroutine.
+func (im *inactivityMonitor) stop() {
+	close(im.done)
+}
@@ -0,0 +1,88 @@
+package server
+
+import (
+	"log/slog"
+	"time"
+
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// qualityInterval is how often bridged media is checked against the
+// quality thresholds
+const qualityInterval = 5 * time.Second
+
+// qualityMonitor raises QualityAlert events for bridged sessions whose
+// received media has more jitter than the threshold. It reads the jitter
+// buffers, so it only sees sessions while buffering is enabled.
+type qualityMonitor struct {
+	server    *Server
+	threshold time.Duration
+
+	alerted map[string]struct{} // Session IDs over the threshold; only used by run
+	done    chan struct{}
+}
+
+// newQualityMonitor creates a monitor alerting on jitter above threshold.
+func newQualityMonitor(s *Server, threshold time.Duration) *qualityMonitor {
+	return &qualityMonitor{
+		server:    s,
+		threshold: threshold,
+		alerted:   make(map[string]struct{}),
+		done:      make(chan struct{}),
+	}
+}
+
+// run checks the jitter buffers until stop is called.
+func (qm *qualityMonitor) run() {
+	ticker := time.NewTicker(qualityInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-qm.done:
+			return
+		case <-ticker.C:
+			qm.check()
+		}
+	}
+}
+
+// check alerts once for each session that crossed the threshold and
+// re-arms sessions that fell back below it or are gone.
+func (qm *qualityMonitor) check() {
+	seen := make(map[string]struct{})
+	for _, js := range qm.server.bridgeMgr.AllJitterStats() {
+		if js.Jitter < qm.threshold {
+			continue
+		}
+		seen[js.SessionID] = struct{}{}
+		if _, ok := qm.alerted[js.SessionID]; ok {
+			continue
+		}
+		qm.alerted[js.SessionID] = struct{}{}
+
+		ev, owner := qm.server.newSessionEvent(js.SessionID)
+		slog.Warn("[Quality] Jitter above threshold",
+			"session_id", js.SessionID,
+			"call_id", ev.CallId,
+			"jitter", js.Jitter,
+			"threshold", qm.threshold,
+		)
+		ev.Event = &rtpv1.SessionEvent_QualityAlert{QualityAlert: &rtpv1.QualityAlert{
+			Metric:    "jitter",
+			Value:     float64(js.Jitter) / float64(time.Millisecond),
+			Threshold: float64(qm.threshold) / float64(time.Millisecond),
+		}}
+		qm.server.events.publish(owner, ev)
+	}
+	for id := range qm.alerted {
+		if _, ok := seen[id]; !ok {
+			delete(qm.alerted, id)
+		}
+	}
+}
+
+// stop terminates the monitor goroutine.
+func (qm *qualityMonitor) stop() {
+	close(qm.done)
+}
@@ -0,0 +1,124 @@
+package server
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// orphanReaper destroys sessions their signaling server lost track of,
+// such as those whose CreateSession response never arrived, before they
+// exhaust the port range.
+//
+// Sessions never bridged or given a remote endpoint within the grace
+// period are checked with their owner: they are returned in its next
+// Health response, and the owner lists the ones it no longer knows in
+// the Health call after that. Only those are destroyed; sessions without
+// an owner (older signaling servers) are left alone.
+type orphanReaper struct {
+	server *Server
+	grace  time.Duration
+
+	mu      sync.Mutex
+	pending map[string][]*rtpv1.SessionCheck // owner -> checks not yet sent
+	sent    map[string]map[string]struct{}   // owner -> session IDs awaiting an answer
+	queued  map[string]struct{}              // Session IDs pending or sent
+
+	done chan struct{}
+}
+
+// newOrphanReaper creates a reaper checking sessions unsettled for grace.
+func newOrphanReaper(s *Server, grace time.Duration) *orphanReaper {
+	return &orphanReaper{
+		server:  s,
+		grace:   grace,
+		pending: make(map[string][]*rtpv1.SessionCheck),
+		sent:    make(map[string]map[string]struct{}),
+		queued:  make(map[string]struct{}),
+		done:    make(chan struct{}),
+	}
+}
+
+// run sweeps for unsettled sessions until stop is called.
+func (r *orphanReaper) run() {
+	interval := r.grace / 2
+	if interval < 5*time.Second {
+		interval = 5 * time.Second
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.done:
+			return
+		case <-ticker.C:
+			r.sweep()
+		}
+	}
+}
+
+// sweep queues a check for each unsettled session not already queued.
+func (r *orphanReaper) sweep() {
+	unsettled := r.server.sessionMgr.Unsettled(r.grace)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, sess := range unsettled {
+		if _, ok := r.queued[sess.ID]; ok {
+			continue
+		}
+		r.queued[sess.ID] = struct{}{}
+		r.pending[sess.Owner] = append(r.pending[sess.Owner], &rtpv1.SessionCheck{
+			SessionId:  sess.ID,
+			CallId:     sess.CallID,
+			AgeSeconds: int32(sess.Age.Seconds()),
+		})
+	}
+}
+
+// health handles an owner's Health call: the sessions it reports as
+// orphaned are destroyed, the rest of those sent to it last time are
+// considered in use, and the checks queued for it are returned.
+func (r *orphanReaper) health(owner string, orphaned []string) []*rtpv1.SessionCheck {
+	if owner == "" {
+		return nil
+	}
+
+	r.mu.Lock()
+	asked := r.sent[owner]
+	delete(r.sent, owner)
+	for id := range asked {
+		delete(r.queued, id)
+	}
+	checks := r.pending[owner]
+	delete(r.pending, owner)
+	if len(checks) > 0 {
+		sent := make(map[string]struct{}, len(checks))
+		for _, c := range checks {
+			sent[c.SessionId] = struct{}{}
+		}
+		r.sent[owner] = sent
+	}
+	r.mu.Unlock()
+
+	for _, id := range orphaned {
+		if _, ok := asked[id]; !ok {
+			continue
+		}
+		ev, _ := r.server.newSessionEvent(id)
+		if r.server.sessionMgr.DestroyUnsettled(id, owner) {
+			slog.Warn("[Reaper] Destroyed orphaned session", "session_id", id, "owner", owner)
+			ev.Event = &rtpv1.SessionEvent_Destroyed{Destroyed: &rtpv1.SessionDestroyed{Reason: rtpv1.TerminateReason_TERMINATE_REASON_TIMEOUT}}
+			r.server.events.publish(owner, ev)
+		}
+	}
+	return checks
+}
+
+// stop terminates the reaper goroutine.
+func (r *orphanReaper) stop() {
+	close(r.done)
+}
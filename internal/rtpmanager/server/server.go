@@ -2,14 +2,25 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
 	"log/slog"
-
+	"slices"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/pion/rtp"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/rtpmanager/audiocache"
 	"github.com/sebas/switchboard/internal/rtpmanager/bridge"
 	"github.com/sebas/switchboard/internal/rtpmanager/media"
 	"github.com/sebas/switchboard/internal/rtpmanager/portpool"
 	"github.com/sebas/switchboard/internal/rtpmanager/session"
 	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+	"google.golang.org/grpc/metadata"
 )
 
 // Config holds RTP Manager configuration
@@ -19,7 +30,41 @@ type Config struct {
 	AdvertiseAddr string
 	RTPPortMin    int
 	RTPPortMax    int
+	RTPPorts      string // Comma-separated port ranges; overrides RTPPortMin/RTPPortMax
 	AudioBasePath string
+
+	// AdvertiseRules maps peer networks to advertised addresses
+	// ("10.0.0.0/8=10.0.0.5,..."); unmatched peers get AdvertiseAddr
+	AdvertiseRules string
+
+	// AdvertiseAddr6 is advertised to unmatched IPv6 peers (empty uses AdvertiseAddr)
+	AdvertiseAddr6 string
+
+	// Jitter buffer for bridged media
+	JitterBufferEnabled bool
+	JitterMinDelay      time.Duration
+	JitterMaxDelay      time.Duration
+
+	// Receive workers per bridged port
+	RTPWorkers int
+	RTPPinCPUs bool
+
+	// RTPTimeout reports bridged sessions that receive no RTP for this long (0 disables)
+	RTPTimeout time.Duration
+
+	// OrphanGrace is how long a session may stay unbridged before its
+	// owner is asked whether it is still in use (0 disables)
+	OrphanGrace time.Duration
+
+	// JitterAlert raises a quality alert event for bridged sessions with
+	// more jitter than this (0 disables; needs the jitter buffer)
+	JitterAlert time.Duration
+
+	// AudioCache configures downloads of http, https and s3 play sources
+	AudioCache audiocache.Config
+
+	// TonePlan is the default country tone plan for PlayTone
+	TonePlan string
 }
 
 // Server implements the RTPManagerService gRPC server
@@ -28,29 +73,89 @@ type Server struct {
 	sessionMgr *session.Manager
 	bridgeMgr  *bridge.Manager
 	portPool   *portpool.PortPool
+	inactivity *inactivityMonitor // nil when RTP timeout is disabled
+	reaper     *orphanReaper      // nil when the orphan grace is 0
+	quality    *qualityMonitor    // nil when the jitter alert is 0
+	events     *eventHub
+	audioCache *audiocache.Cache
 	config     *Config
+	instanceID string // Reported in Health so signaling notices restarts
+	draining   atomic.Bool
 }
 
 // NewServer creates a new RTP Manager gRPC server
 func NewServer(cfg *Config) (*Server, error) {
+	if cfg.TonePlan != "" && !slices.Contains(media.TonePlans(), strings.ToLower(cfg.TonePlan)) {
+		return nil, fmt.Errorf("unknown tone plan %q (available: %s)", cfg.TonePlan, strings.Join(media.TonePlans(), ", "))
+	}
+
 	// Create port pool
-	pool := portpool.NewPortPool(cfg.RTPPortMin, cfg.RTPPortMax)
+	ranges := []portpool.Range{{Min: cfg.RTPPortMin, Max: cfg.RTPPortMax}}
+	if cfg.RTPPorts != "" {
+		var err error
+		if ranges, err = portpool.ParseRanges(cfg.RTPPorts); err != nil {
+			return nil, err
+		}
+	}
+	pool := portpool.NewPortPool(ranges...)
 
 	// Create media service
 	mediaService := media.NewLocalService()
 
 	// Create session manager
-	sessionMgr := session.NewManager(pool, mediaService, cfg.AdvertiseAddr)
+	selector, err := advertise.Parse(cfg.AdvertiseAddr, cfg.AdvertiseRules)
+	if err != nil {
+		return nil, err
+	}
+	selector.SetIPv6Default(cfg.AdvertiseAddr6)
+	sessionMgr := session.NewManager(pool, mediaService, selector)
 
 	// Create bridge manager
-	bridgeMgr := bridge.NewManager()
+	bridgeMgr := bridge.NewManager(bridge.JitterConfig{
+		Enabled:  cfg.JitterBufferEnabled,
+		MinDelay: cfg.JitterMinDelay,
+		MaxDelay: cfg.JitterMaxDelay,
+	})
+	bridgeMgr.SetWorkers(bridge.WorkerConfig{
+		Workers: cfg.RTPWorkers,
+		PinCPUs: cfg.RTPPinCPUs,
+	})
+
+	// Create remote audio cache
+	audioCache, err := audiocache.New(cfg.AudioCache)
+	if err != nil {
+		return nil, err
+	}
 
-	return &Server{
+	s := &Server{
+		audioCache: audioCache,
 		sessionMgr: sessionMgr,
 		bridgeMgr:  bridgeMgr,
 		portPool:   pool,
 		config:     cfg,
-	}, nil
+		instanceID: uuid.New().String(),
+		events:     newEventHub(),
+	}
+
+	// Start RTP inactivity monitor
+	if cfg.RTPTimeout > 0 {
+		s.inactivity = newInactivityMonitor(s, cfg.RTPTimeout)
+		go s.inactivity.run()
+	}
+
+	// Start orphaned session reaper
+	if cfg.OrphanGrace > 0 {
+		s.reaper = newOrphanReaper(s, cfg.OrphanGrace)
+		go s.reaper.run()
+	}
+
+	// Start media quality monitor
+	if cfg.JitterAlert > 0 {
+		s.quality = newQualityMonitor(s, cfg.JitterAlert)
+		go s.quality.run()
+	}
+
+	return s, nil
 }
 
 // CreateSession implements RTPManagerService.CreateSession
@@ -58,12 +163,15 @@ func (s *Server) CreateSession(ctx context.Context, req *rtpv1.CreateSessionRequ
 	slog.Info("[gRPC] CreateSession",
 		"call_id", req.CallId,
 		"remote", fmt.Sprintf("%s:%d", req.RemoteAddr, req.RemotePort),
-		"codecs", req.OfferedCodecs)
+		"peer", req.PeerAddr,
+		"codecs", req.OfferedCodecs,
+		"owner", req.Owner)
 
 	sess, sdpBody, err := s.sessionMgr.CreateSession(
 		req.CallId,
 		req.RemoteAddr,
 		int(req.RemotePort),
+		req.PeerAddr,
 		req.OfferedCodecs,
 	)
 	if err != nil {
@@ -72,9 +180,20 @@ func (s *Server) CreateSession(ctx context.Context, req *rtpv1.CreateSessionRequ
 			Status: &rtpv1.SessionStatus{
 				State:        rtpv1.SessionState_SESSION_STATE_ERROR,
 				ErrorMessage: err.Error(),
+				Rejected:     errors.Is(err, session.ErrNoSupportedCodec),
 			},
 		}, nil
 	}
+	if req.Owner != "" {
+		s.sessionMgr.SetOwner(sess.ID, req.Owner)
+	}
+	ev, _ := s.newSessionEvent(sess.ID)
+	ev.Event = &rtpv1.SessionEvent_Created{Created: &rtpv1.SessionCreated{
+		LocalAddr: sess.LocalAddr,
+		LocalPort: int32(sess.LocalPort),
+		Codec:     sess.Codec,
+	}}
+	s.events.publish(req.Owner, ev)
 
 	return &rtpv1.CreateSessionResponse{
 		SessionId:     sess.ID,
@@ -92,6 +211,11 @@ func (s *Server) CreateSession(ctx context.Context, req *rtpv1.CreateSessionRequ
 func (s *Server) DestroySession(ctx context.Context, req *rtpv1.DestroySessionRequest) (*rtpv1.DestroySessionResponse, error) {
 	slog.Info("[gRPC] DestroySession", "session_id", req.SessionId, "reason", req.Reason)
 
+	if s.inactivity != nil {
+		s.inactivity.forget(req.SessionId)
+	}
+
+	ev, owner := s.newSessionEvent(req.SessionId)
 	err := s.sessionMgr.DestroySession(req.SessionId)
 	if err != nil {
 		slog.Warn("[gRPC] DestroySession failed", "error", err)
@@ -104,6 +228,9 @@ func (s *Server) DestroySession(ctx context.Context, req *rtpv1.DestroySessionRe
 		}, nil
 	}
 
+	ev.Event = &rtpv1.SessionEvent_Destroyed{Destroyed: &rtpv1.SessionDestroyed{Reason: req.Reason}}
+	s.events.publish(owner, ev)
+
 	return &rtpv1.DestroySessionResponse{
 		SessionId: req.SessionId,
 		Status: &rtpv1.SessionStatus{
@@ -112,15 +239,63 @@ func (s *Server) DestroySession(ctx context.Context, req *rtpv1.DestroySessionRe
 	}, nil
 }
 
+// SubscribeEvents implements RTPManagerService.SubscribeEvents (server streaming)
+func (s *Server) SubscribeEvents(req *rtpv1.SubscribeEventsRequest, stream rtpv1.RTPManagerService_SubscribeEventsServer) error {
+	slog.Info("[gRPC] SubscribeEvents", "owner", req.Owner)
+
+	sub := s.events.subscribe(req.Owner)
+	defer s.events.unsubscribe(sub)
+
+	// Tell the client it is subscribed before the first event
+	if err := stream.SendHeader(metadata.MD{}); err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case ev := <-sub.ch:
+			if err := stream.Send(ev); err != nil {
+				return err
+			}
+		}
+	}
+}
+
 // PlayAudio implements RTPManagerService.PlayAudio (server streaming)
 func (s *Server) PlayAudio(req *rtpv1.PlayAudioRequest, stream rtpv1.RTPManagerService_PlayAudioServer) error {
-	slog.Info("[gRPC] PlayAudio", "session_id", req.SessionId, "file", req.FilePath)
+	slog.Info("[gRPC] PlayAudio", "session_id", req.SessionId, "file", req.FilePath, "playlist", len(req.Playlist))
+
+	sources := req.Playlist
+	if req.FilePath != "" {
+		sources = append([]string{req.FilePath}, req.Playlist...)
+	}
+
+	// Download remote sources before playback starts
+	files := make([]string, 0, len(sources))
+	for _, source := range sources {
+		filePath, err := s.audioCache.Resolve(stream.Context(), source)
+		if err != nil {
+			slog.Error("[gRPC] PlayAudio source unavailable", "file", source, "error", err)
+			return stream.Send(&rtpv1.PlaybackEvent{
+				SessionId: req.SessionId,
+				Event: &rtpv1.PlaybackEvent_Error{
+					Error: &rtpv1.PlaybackError{
+						Code:    "SOURCE_UNAVAILABLE",
+						Message: err.Error(),
+					},
+				},
+			})
+		}
+		files = append(files, filePath)
+	}
 
 	// Create event channel
 	eventCh := make(chan *rtpv1.PlaybackEvent, 10)
 
 	// Start playback in background
-	if err := s.sessionMgr.PlayAudio(req.SessionId, req.FilePath, eventCh); err != nil {
+	if err := s.sessionMgr.PlayAudio(stream.Context(), req.SessionId, files, time.Duration(req.StartMs)*time.Millisecond, req.ReportDtmf, eventCh); err != nil {
 		return err
 	}
 
@@ -130,30 +305,395 @@ func (s *Server) PlayAudio(req *rtpv1.PlayAudioRequest, stream rtpv1.RTPManagerS
 			slog.Error("[gRPC] Failed to send playback event", "error", err)
 			return err
 		}
+		s.emitPlayback(event)
 	}
 
 	return nil
 }
 
+// PlayTone implements RTPManagerService.PlayTone (server streaming)
+func (s *Server) PlayTone(req *rtpv1.PlayToneRequest, stream rtpv1.RTPManagerService_PlayToneServer) error {
+	slog.Info("[gRPC] PlayTone", "session_id", req.SessionId, "tone", req.Tone, "country", req.Country, "duration_ms", req.DurationMs, "detect_machine", req.DetectMachine)
+
+	country := req.Country
+	if country == "" {
+		country = s.config.TonePlan
+	}
+
+	tone, err := media.LookupTone(req.Tone, country)
+	if err != nil {
+		return stream.Send(&rtpv1.PlaybackEvent{
+			SessionId: req.SessionId,
+			Event: &rtpv1.PlaybackEvent_Error{
+				Error: &rtpv1.PlaybackError{
+					Code:    "INVALID_TONE",
+					Message: err.Error(),
+				},
+			},
+		})
+	}
+
+	var machine *media.MachineDetectionConfig
+	if req.DetectMachine {
+		machine = machineDetectionConfig(req.MachineParams)
+	}
+
+	eventCh := make(chan *rtpv1.PlaybackEvent, 10)
+	if err := s.sessionMgr.PlayTone(stream.Context(), req.SessionId, tone, time.Duration(req.DurationMs)*time.Millisecond, req.ReportDtmf, machine, eventCh); err != nil {
+		return err
+	}
+
+	for event := range eventCh {
+		if err := stream.Send(event); err != nil {
+			slog.Error("[gRPC] Failed to send playback event", "error", err)
+			return err
+		}
+		s.emitPlayback(event)
+	}
+
+	return nil
+}
+
+// machineDetectionConfig converts machine detection settings; zero fields
+// take the detector's defaults
+func machineDetectionConfig(p *rtpv1.MachineDetectionParams) *media.MachineDetectionConfig {
+	ms := func(v int32) time.Duration { return time.Duration(v) * time.Millisecond }
+	return &media.MachineDetectionConfig{
+		InitialSilence:       ms(p.GetInitialSilenceMs()),
+		Greeting:             ms(p.GetGreetingMs()),
+		AfterGreetingSilence: ms(p.GetAfterGreetingSilenceMs()),
+		TotalAnalysis:        ms(p.GetTotalAnalysisMs()),
+		MinWordLength:        ms(p.GetMinWordLengthMs()),
+		BetweenWordsSilence:  ms(p.GetBetweenWordsSilenceMs()),
+		MaximumWords:         int(p.GetMaximumWords()),
+		SilenceThreshold:     int(p.GetSilenceThreshold()),
+	}
+}
+
 // StopAudio implements RTPManagerService.StopAudio
 func (s *Server) StopAudio(ctx context.Context, req *rtpv1.StopAudioRequest) (*rtpv1.StopAudioResponse, error) {
 	slog.Info("[gRPC] StopAudio", "session_id", req.SessionId)
 
-	wasPlaying, _ := s.sessionMgr.StopAudio(req.SessionId)
+	wasPlaying, pos, _ := s.sessionMgr.StopAudio(req.SessionId)
 
 	return &rtpv1.StopAudioResponse{
 		SessionId:  req.SessionId,
 		WasPlaying: wasPlaying,
+		PositionMs: int32(pos.Position / time.Millisecond),
 	}, nil
 }
 
+// ControlPlayback implements RTPManagerService.ControlPlayback
+func (s *Server) ControlPlayback(ctx context.Context, req *rtpv1.ControlPlaybackRequest) (*rtpv1.ControlPlaybackResponse, error) {
+	slog.Info("[gRPC] ControlPlayback", "session_id", req.SessionId, "control", req.Control.String(), "position_ms", req.PositionMs)
+
+	pos, err := s.sessionMgr.ControlPlayback(req.SessionId, req.Control, time.Duration(req.PositionMs)*time.Millisecond)
+	if err != nil {
+		return &rtpv1.ControlPlaybackResponse{
+			SessionId: req.SessionId,
+			Status: &rtpv1.SessionStatus{
+				State:        rtpv1.SessionState_SESSION_STATE_ERROR,
+				ErrorMessage: err.Error(),
+			},
+		}, nil
+	}
+
+	return &rtpv1.ControlPlaybackResponse{
+		SessionId:  req.SessionId,
+		Paused:     pos.Paused,
+		PositionMs: int32(pos.Position / time.Millisecond),
+		DurationMs: int32(pos.Duration / time.Millisecond),
+		Status: &rtpv1.SessionStatus{
+			State: rtpv1.SessionState_SESSION_STATE_ACTIVE,
+		},
+	}, nil
+}
+
+// InjectAudio implements RTPManagerService.InjectAudio (client streaming)
+func (s *Server) InjectAudio(stream rtpv1.RTPManagerService_InjectAudioServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	slog.Info("[gRPC] InjectAudio",
+		"session_id", first.SessionId,
+		"encoding", first.Encoding.String(),
+		"sample_rate", first.SampleRate,
+	)
+
+	var encoding media.Encoding
+	switch first.Encoding {
+	case rtpv1.AudioEncoding_AUDIO_ENCODING_PCM_S16LE:
+		encoding = media.EncodingPCM16
+	case rtpv1.AudioEncoding_AUDIO_ENCODING_PCMU:
+		encoding = media.EncodingPCMU
+	default:
+		return stream.SendAndClose(injectErrorResponse(first.SessionId, fmt.Errorf("unsupported encoding: %s", first.Encoding)))
+	}
+
+	inj, err := s.sessionMgr.InjectAudio(first.SessionId, encoding, int(first.SampleRate))
+	if err != nil {
+		slog.Error("[gRPC] InjectAudio failed", "error", err)
+		return stream.SendAndClose(injectErrorResponse(first.SessionId, err))
+	}
+
+	// Forward payloads until the client closes its side of the stream
+	req := first
+	for {
+		if len(req.Payload) > 0 {
+			if err := inj.Write(req.Payload); err != nil {
+				if errors.Is(err, media.ErrInjectionClosed) {
+					break // stopped or session ended
+				}
+				inj.Abort()
+				return stream.SendAndClose(injectErrorResponse(first.SessionId, err))
+			}
+		}
+
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			// Client went away; discard whatever is still buffered
+			inj.Abort()
+			return err
+		}
+	}
+
+	framesSent, err := inj.Close()
+	if err != nil {
+		slog.Error("[gRPC] InjectAudio playout failed", "session_id", first.SessionId, "error", err)
+		return stream.SendAndClose(injectErrorResponse(first.SessionId, err))
+	}
+
+	return stream.SendAndClose(&rtpv1.InjectAudioResponse{
+		SessionId:  first.SessionId,
+		FramesSent: int32(framesSent),
+		DurationMs: int32(framesSent * 20),
+		Status: &rtpv1.SessionStatus{
+			State: rtpv1.SessionState_SESSION_STATE_ACTIVE,
+		},
+	})
+}
+
+// captureBufferFrames bounds frames queued for a slow capture consumer (2s at 20ms/frame)
+const captureBufferFrames = 100
+
+// CaptureAudio implements RTPManagerService.CaptureAudio (server streaming)
+func (s *Server) CaptureAudio(req *rtpv1.CaptureAudioRequest, stream rtpv1.RTPManagerService_CaptureAudioServer) error {
+	slog.Info("[gRPC] CaptureAudio",
+		"session_id", req.SessionId,
+		"direction", req.Direction.String(),
+		"encoding", req.Encoding.String(),
+	)
+
+	wantInbound := req.Direction != rtpv1.CaptureDirection_CAPTURE_DIRECTION_OUTBOUND
+	wantOutbound := req.Direction != rtpv1.CaptureDirection_CAPTURE_DIRECTION_INBOUND
+	decode := req.Encoding != rtpv1.AudioEncoding_AUDIO_ENCODING_PCMU
+
+	frameCh := make(chan *rtpv1.AudioFrame, captureBufferFrames)
+	var dropped atomic.Int64
+
+	tap, err := s.bridgeMgr.AddTap(req.SessionId, func(dir bridge.TapDirection, data []byte) {
+		if (dir == bridge.TapInbound && !wantInbound) || (dir == bridge.TapOutbound && !wantOutbound) {
+			return
+		}
+
+		var pkt rtp.Packet
+		if err := pkt.Unmarshal(data); err != nil {
+			return
+		}
+
+		payload := pkt.Payload
+		if decode {
+			// Only PCMU is negotiated; skip telephone-event and other payloads
+			if pkt.PayloadType != 0 {
+				return
+			}
+			payload = media.PCMUToPCM(payload)
+		} else {
+			payload = append([]byte(nil), payload...)
+		}
+
+		frame := &rtpv1.AudioFrame{
+			SessionId:      req.SessionId,
+			Direction:      rtpv1.CaptureDirection_CAPTURE_DIRECTION_INBOUND,
+			SequenceNumber: uint32(pkt.SequenceNumber),
+			Timestamp:      pkt.Timestamp,
+			Payload:        payload,
+		}
+		if dir == bridge.TapOutbound {
+			frame.Direction = rtpv1.CaptureDirection_CAPTURE_DIRECTION_OUTBOUND
+		}
+
+		// Never block the relay; drop frames if the consumer falls behind
+		select {
+		case frameCh <- frame:
+		default:
+			dropped.Add(1)
+		}
+	})
+	if err != nil {
+		slog.Warn("[gRPC] CaptureAudio failed", "session_id", req.SessionId, "error", err)
+		return err
+	}
+	defer tap.Close()
+
+	for {
+		select {
+		case <-stream.Context().Done():
+			slog.Info("[gRPC] CaptureAudio canceled", "session_id", req.SessionId, "dropped", dropped.Load())
+			return nil
+		case <-tap.Done():
+			slog.Info("[gRPC] CaptureAudio ended, bridge closed", "session_id", req.SessionId, "dropped", dropped.Load())
+			return nil
+		case frame := <-frameCh:
+			if err := stream.Send(frame); err != nil {
+				slog.Error("[gRPC] Failed to send audio frame", "error", err)
+				return err
+			}
+		}
+	}
+}
+
+// injectErrorResponse builds an InjectAudio response carrying an error status
+func injectErrorResponse(sessionID string, err error) *rtpv1.InjectAudioResponse {
+	return &rtpv1.InjectAudioResponse{
+		SessionId: sessionID,
+		Status: &rtpv1.SessionStatus{
+			State:        rtpv1.SessionState_SESSION_STATE_ERROR,
+			ErrorMessage: err.Error(),
+		},
+	}
+}
+
 // Health implements RTPManagerService.Health
+// Pending RTP inactivity timeouts are included and cleared on each call,
+// and the caller's answers to orphan checks are applied.
 func (s *Server) Health(ctx context.Context, req *rtpv1.HealthRequest) (*rtpv1.HealthResponse, error) {
-	return &rtpv1.HealthResponse{
-		Healthy:        true,
-		ActiveSessions: int32(s.sessionMgr.Count()),
-		AvailablePorts: int32(s.portPool.Available()),
-	}, nil
+	ports := s.portPool.Stats()
+	resp := &rtpv1.HealthResponse{
+		Healthy:         true,
+		ActiveSessions:  int32(s.sessionMgr.Count()),
+		AvailablePorts:  int32(ports.Available),
+		TotalPorts:      int32(ports.Total),
+		AllocatedPorts:  int32(ports.Allocated),
+		BusyPorts:       int32(ports.Busy),
+		PortConflicts:   ports.Conflicts,
+		PortExhaustions: ports.Exhausted,
+		InstanceId:      s.instanceID,
+		DrainRequested:  s.draining.Load(),
+	}
+	if s.inactivity != nil {
+		resp.MediaTimeouts = s.inactivity.health(req.Owner, req.AckedMediaTimeouts)
+	}
+	if s.reaper != nil {
+		resp.SessionChecks = s.reaper.health(req.Owner, req.OrphanedSessionIds)
+	}
+	return resp, nil
+}
+
+// Gauges returns the values pushed to a metrics exporter. Names ending
+// in _total are running totals.
+func (s *Server) Gauges() map[string]float64 {
+	ports := s.portPool.Stats()
+	cache := s.audioCache.Stats()
+	return map[string]float64{
+		"sessions":                 float64(s.sessionMgr.Count()),
+		"bridges":                  float64(s.bridgeMgr.Count()),
+		"ports.total":              float64(ports.Total),
+		"ports.allocated":          float64(ports.Allocated),
+		"ports.available":          float64(ports.Available),
+		"ports.busy":               float64(ports.Busy),
+		"ports.utilization":        ports.Utilization,
+		"ports.conflicts_total":    float64(ports.Conflicts),
+		"ports.exhaustions_total":  float64(ports.Exhausted),
+		"audio_cache.entries":      float64(cache.Entries),
+		"audio_cache.bytes":        float64(cache.Bytes),
+		"audio_cache.hits_total":   float64(cache.Hits),
+		"audio_cache.misses_total": float64(cache.Misses),
+	}
+}
+
+// ListSessions implements RTPManagerService.ListSessions
+func (s *Server) ListSessions(ctx context.Context, req *rtpv1.ListSessionsRequest) (*rtpv1.ListSessionsResponse, error) {
+	sessions := s.sessionMgr.List(req.Owner)
+	resp := &rtpv1.ListSessionsResponse{Sessions: make([]*rtpv1.SessionSummary, 0, len(sessions))}
+	for _, sess := range sessions {
+		resp.Sessions = append(resp.Sessions, &rtpv1.SessionSummary{
+			SessionId:  sess.ID,
+			CallId:     sess.CallID,
+			State:      sess.State,
+			Owner:      sess.Owner,
+			AgeSeconds: int32(sess.Age.Seconds()),
+		})
+	}
+	return resp, nil
+}
+
+// RequestDrain asks the signaling servers to move their sessions off this
+// node. The request is reported with every Health call from then on; it
+// cannot be withdrawn short of a restart.
+func (s *Server) RequestDrain() {
+	if !s.draining.Swap(true) {
+		slog.Info("[Drain] Drain requested, reporting it to signaling", "sessions", s.sessionMgr.Count())
+	}
+}
+
+// DrainStatus returns whether a drain was requested and the sessions
+// still on the node.
+func (s *Server) DrainStatus() (requested bool, sessions int) {
+	return s.draining.Load(), s.sessionMgr.Count()
+}
+
+// ListBridges implements RTPManagerService.ListBridges
+func (s *Server) ListBridges(ctx context.Context, req *rtpv1.ListBridgesRequest) (*rtpv1.ListBridgesResponse, error) {
+	bridges := s.bridgeMgr.List()
+	resp := &rtpv1.ListBridgesResponse{Bridges: make([]*rtpv1.BridgeSummary, 0, len(bridges))}
+	for _, b := range bridges {
+		stats := b.GetStats()
+		resp.Bridges = append(resp.Bridges, &rtpv1.BridgeSummary{
+			BridgeId:    b.ID,
+			SessionAId:  b.SessionA.SessionID,
+			SessionBId:  b.SessionB.SessionID,
+			PacketsAToB: stats.PacketsA2B,
+			PacketsBToA: stats.PacketsB2A,
+			BytesAToB:   stats.BytesA2B,
+			BytesBToA:   stats.BytesB2A,
+			JitterAToB:  jitterBufferStats(stats.JitterA2B),
+			JitterBToA:  jitterBufferStats(stats.JitterB2A),
+		})
+	}
+	return resp, nil
+}
+
+// jitterBufferStats converts the stats of one direction's jitter buffer;
+// nil when buffering is disabled
+func jitterBufferStats(js *bridge.JitterStats) *rtpv1.JitterBufferStats {
+	if js == nil {
+		return nil
+	}
+	return &rtpv1.JitterBufferStats{
+		SessionId:     js.SessionID,
+		Depth:         int32(js.Depth),
+		MaxDepth:      int32(js.MaxDepth),
+		TargetDelayMs: int32(js.TargetDelay.Milliseconds()),
+		JitterMs:      int32(js.Jitter.Milliseconds()),
+		Late:          js.Late,
+		Overflow:      js.Overflow,
+		Duplicates:    js.Duplicates,
+	}
+}
+
+// Ready reports whether the server can take new sessions: it is not
+// ready while every RTP port pair is allocated.
+func (s *Server) Ready(ctx context.Context) error {
+	if s.portPool.Available() == 0 {
+		return errors.New("no RTP ports available")
+	}
+	return nil
 }
 
 // UpdateSessionRemote implements RTPManagerService.UpdateSessionRemote
@@ -229,6 +769,11 @@ func (s *Server) BridgeMedia(ctx context.Context, req *rtpv1.BridgeMediaRequest)
 		RemotePort: remotePortB,
 	}
 
+	// Playback (e.g. ringback) holds a session's RTP port; end it so the
+	// bridge can bind
+	_, _, _ = s.sessionMgr.StopAudio(req.SessionAId)
+	_, _, _ = s.sessionMgr.StopAudio(req.SessionBId)
+
 	bridgeID, err := s.bridgeMgr.CreateBridge(endpointA, endpointB)
 	if err != nil {
 		slog.Error("[gRPC] BridgeMedia failed", "error", err)
@@ -302,6 +847,15 @@ func (s *Server) UnbridgeMedia(ctx context.Context, req *rtpv1.UnbridgeMediaRequ
 
 // Close cleans up resources
 func (s *Server) Close() error {
+	if s.inactivity != nil {
+		s.inactivity.stop()
+	}
+	if s.reaper != nil {
+		s.reaper.stop()
+	}
+	if s.quality != nil {
+		s.quality.stop()
+	}
 	s.bridgeMgr.CloseAll()
 	s.sessionMgr.CloseAll()
 	return nil
@@ -2,18 +2,24 @@ package session
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"sync"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/sebas/switchboard/internal/advertise"
 	"github.com/sebas/switchboard/internal/rtpmanager/media"
 	"github.com/sebas/switchboard/internal/rtpmanager/portpool"
 	"github.com/sebas/switchboard/internal/rtpmanager/sdp"
 	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
 )
 
+// ErrNoSupportedCodec is returned when an offer names no codec the RTP
+// manager handles
+var ErrNoSupportedCodec = errors.New("no supported codec offered (PCMU required)")
+
 // Session represents an active media session
 type Session struct {
 	ID           string
@@ -26,6 +32,7 @@ type Session struct {
 	Codec        string
 	State        rtpv1.SessionState
 	CreatedAt    time.Time
+	Owner        string // Signaling node that created the session; empty if unknown
 	ctx          context.Context
 	cancel       context.CancelFunc
 	playbackDone chan struct{}
@@ -39,22 +46,25 @@ type Manager struct {
 	callToSession map[string]string   // callID -> sessionID
 	portPool      *portpool.PortPool
 	mediaService  *media.LocalService
-	advertiseAddr string
+	advertise     *advertise.Selector
 }
 
-// NewManager creates a new session manager
-func NewManager(portPool *portpool.PortPool, mediaService *media.LocalService, advertiseAddr string) *Manager {
+// NewManager creates a new session manager. The advertise selector picks
+// the SDP address for each session from the peer's network.
+func NewManager(portPool *portpool.PortPool, mediaService *media.LocalService, selector *advertise.Selector) *Manager {
 	return &Manager{
 		sessions:      make(map[string]*Session),
 		callToSession: make(map[string]string),
 		portPool:      portPool,
 		mediaService:  mediaService,
-		advertiseAddr: advertiseAddr,
+		advertise:     selector,
 	}
 }
 
-// CreateSession creates a new media session
-func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, offeredCodecs []string) (*Session, []byte, error) {
+// CreateSession creates a new media session. The advertised address is
+// chosen from remoteAddr, or from peerAddr when the remote endpoint is not
+// known yet.
+func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, peerAddr string, offeredCodecs []string) (*Session, []byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -62,11 +72,16 @@ func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, offer
 	if sessionID, exists := m.callToSession[callID]; exists {
 		if sess, ok := m.sessions[sessionID]; ok {
 			slog.Warn("[SessionMgr] Session already exists for call", "call_id", callID, "session_id", sessionID)
-			sdpBody := sdp.BuildResponseSDP(m.advertiseAddr, sess.LocalPort, sess.Codec)
+			sdpBody := sdp.BuildResponseSDP(sess.LocalAddr, sess.LocalPort, sess.Codec)
 			return sess, sdpBody, nil
 		}
 	}
 
+	if remoteAddr != "" {
+		peerAddr = remoteAddr
+	}
+	localAddr := m.advertise.Select(peerAddr)
+
 	// Allocate ports
 	rtpPort, rtcpPort, err := m.portPool.Allocate()
 	if err != nil {
@@ -83,7 +98,7 @@ func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, offer
 	}
 	if selectedCodec == "" {
 		m.portPool.Release(rtpPort)
-		return nil, nil, fmt.Errorf("no supported codec offered (PCMU required)")
+		return nil, nil, ErrNoSupportedCodec
 	}
 
 	// Create session
@@ -91,7 +106,7 @@ func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, offer
 	sess := &Session{
 		ID:           uuid.New().String(),
 		CallID:       callID,
-		LocalAddr:    m.advertiseAddr,
+		LocalAddr:    localAddr,
 		LocalPort:    rtpPort,
 		RTCPPort:     rtcpPort,
 		RemoteAddr:   remoteAddr,
@@ -108,12 +123,12 @@ func (m *Manager) CreateSession(callID, remoteAddr string, remotePort int, offer
 	m.callToSession[callID] = sess.ID
 
 	// Build SDP
-	sdpBody := sdp.BuildResponseSDP(m.advertiseAddr, rtpPort, selectedCodec)
+	sdpBody := sdp.BuildResponseSDP(localAddr, rtpPort, selectedCodec)
 
 	slog.Info("[SessionMgr] Session created",
 		"session_id", sess.ID,
 		"call_id", callID,
-		"local_port", rtpPort,
+		"local", fmt.Sprintf("%s:%d", localAddr, rtpPort),
 		"remote", fmt.Sprintf("%s:%d", remoteAddr, remotePort))
 
 	return sess, sdpBody, nil
@@ -172,7 +187,8 @@ func (m *Manager) GetSessionEndpoint(sessionID string) (localAddr string, localP
 
 // CreateSessionPendingRemote creates a session without remote endpoint info.
 // Used for B2BUA B-leg where remote is set later via UpdateRemoteEndpoint.
-func (m *Manager) CreateSessionPendingRemote(callID string, offeredCodecs []string) (*Session, []byte, error) {
+// peerAddr, the SIP destination, selects the advertised address.
+func (m *Manager) CreateSessionPendingRemote(callID, peerAddr string, offeredCodecs []string) (*Session, []byte, error) {
 	m.mu.Lock()
 	defer m.mu.Unlock()
 
@@ -180,11 +196,13 @@ func (m *Manager) CreateSessionPendingRemote(callID string, offeredCodecs []stri
 	if sessionID, exists := m.callToSession[callID]; exists {
 		if sess, ok := m.sessions[sessionID]; ok {
 			slog.Warn("[SessionMgr] Session already exists for call", "call_id", callID, "session_id", sessionID)
-			sdpBody := sdp.BuildResponseSDP(m.advertiseAddr, sess.LocalPort, sess.Codec)
+			sdpBody := sdp.BuildResponseSDP(sess.LocalAddr, sess.LocalPort, sess.Codec)
 			return sess, sdpBody, nil
 		}
 	}
 
+	localAddr := m.advertise.Select(peerAddr)
+
 	// Allocate ports
 	rtpPort, rtcpPort, err := m.portPool.Allocate()
 	if err != nil {
@@ -201,7 +219,7 @@ func (m *Manager) CreateSessionPendingRemote(callID string, offeredCodecs []stri
 	}
 	if selectedCodec == "" {
 		m.portPool.Release(rtpPort)
-		return nil, nil, fmt.Errorf("no supported codec offered (PCMU required)")
+		return nil, nil, ErrNoSupportedCodec
 	}
 
 	// Create session with empty remote endpoint (pending)
@@ -209,7 +227,7 @@ func (m *Manager) CreateSessionPendingRemote(callID string, offeredCodecs []stri
 	sess := &Session{
 		ID:           uuid.New().String(),
 		CallID:       callID,
-		LocalAddr:    m.advertiseAddr,
+		LocalAddr:    localAddr,
 		LocalPort:    rtpPort,
 		RTCPPort:     rtcpPort,
 		RemoteAddr:   "", // Empty - to be set later
@@ -226,12 +244,12 @@ func (m *Manager) CreateSessionPendingRemote(callID string, offeredCodecs []stri
 	m.callToSession[callID] = sess.ID
 
 	// Build SDP (for outgoing INVITE)
-	sdpBody := sdp.BuildResponseSDP(m.advertiseAddr, rtpPort, selectedCodec)
+	sdpBody := sdp.BuildResponseSDP(localAddr, rtpPort, selectedCodec)
 
 	slog.Info("[SessionMgr] Session created (pending remote)",
 		"session_id", sess.ID,
 		"call_id", callID,
-		"local_port", rtpPort)
+		"local", fmt.Sprintf("%s:%d", localAddr, rtpPort))
 
 	return sess, sdpBody, nil
 }
@@ -253,6 +271,116 @@ func (m *Manager) SetSessionBridged(sessionID string) error {
 	return nil
 }
 
+// SetOwner records the signaling node that owns a session
+func (m *Manager) SetOwner(sessionID, owner string) {
+	m.mu.RLock()
+	sess, ok := m.sessions[sessionID]
+	m.mu.RUnlock()
+	if !ok {
+		return
+	}
+	sess.mu.Lock()
+	sess.Owner = owner
+	sess.mu.Unlock()
+}
+
+// Owner returns the signaling node that owns a session, or "".
+func (m *Manager) Owner(sessionID string) string {
+	m.mu.RLock()
+	sess, ok := m.sessions[sessionID]
+	m.mu.RUnlock()
+	if !ok {
+		return ""
+	}
+	sess.mu.RLock()
+	defer sess.mu.RUnlock()
+	return sess.Owner
+}
+
+// Summary describes a session for listings (see List and Unsettled)
+type Summary struct {
+	ID     string
+	CallID string
+	State  rtpv1.SessionState
+	Owner  string
+	Age    time.Duration
+}
+
+// summary returns the session's Summary, with sess.mu held.
+func (sess *Session) summary(now time.Time) Summary {
+	return Summary{
+		ID:     sess.ID,
+		CallID: sess.CallID,
+		State:  sess.State,
+		Owner:  sess.Owner,
+		Age:    now.Sub(sess.CreatedAt),
+	}
+}
+
+// unsettled reports whether a session was never bridged or given a
+// remote endpoint, with sess.mu held.
+func (sess *Session) unsettled() bool {
+	return sess.State == rtpv1.SessionState_SESSION_STATE_CREATED ||
+		sess.State == rtpv1.SessionState_SESSION_STATE_PENDING_REMOTE
+}
+
+// Unsettled returns the owned sessions created more than olderThan ago
+// that were never bridged or given a remote endpoint. These are the
+// sessions a signaling server may have lost track of.
+func (m *Manager) Unsettled(olderThan time.Duration) []Summary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var result []Summary
+	now := time.Now()
+	for _, sess := range m.sessions {
+		sess.mu.RLock()
+		if sess.Owner != "" && sess.unsettled() && now.Sub(sess.CreatedAt) > olderThan {
+			result = append(result, sess.summary(now))
+		}
+		sess.mu.RUnlock()
+	}
+	return result
+}
+
+// List returns the sessions created by owner, or all sessions when owner
+// is empty.
+func (m *Manager) List(owner string) []Summary {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	result := make([]Summary, 0, len(m.sessions))
+	now := time.Now()
+	for _, sess := range m.sessions {
+		sess.mu.RLock()
+		if owner == "" || sess.Owner == owner {
+			result = append(result, sess.summary(now))
+		}
+		sess.mu.RUnlock()
+	}
+	return result
+}
+
+// DestroyUnsettled destroys a session if it belongs to owner and is still
+// unsettled, and reports whether it did.
+func (m *Manager) DestroyUnsettled(sessionID, owner string) bool {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	sess, ok := m.sessions[sessionID]
+	if !ok {
+		return false
+	}
+	sess.mu.RLock()
+	orphan := sess.Owner == owner && sess.unsettled()
+	sess.mu.RUnlock()
+	if !orphan {
+		return false
+	}
+	m.destroyLocked(sess)
+	return true
+}
+
 // DestroySession destroys a session and releases resources
 func (m *Manager) DestroySession(sessionID string) error {
 	m.mu.Lock()
@@ -262,7 +390,12 @@ func (m *Manager) DestroySession(sessionID string) error {
 	if !ok {
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
+	m.destroyLocked(sess)
+	return nil
+}
 
+// destroyLocked releases a session's resources with m.mu held
+func (m *Manager) destroyLocked(sess *Session) {
 	// Cancel context to stop any playback
 	sess.cancel()
 
@@ -278,15 +411,40 @@ func (m *Manager) DestroySession(sessionID string) error {
 	sess.mu.Unlock()
 
 	// Remove from maps
-	delete(m.sessions, sessionID)
+	delete(m.sessions, sess.ID)
 	delete(m.callToSession, sess.CallID)
 
-	slog.Info("[SessionMgr] Session destroyed", "session_id", sessionID, "call_id", sess.CallID)
-	return nil
+	slog.Info("[SessionMgr] Session destroyed", "session_id", sess.ID, "call_id", sess.CallID)
+}
+
+// PlayAudio starts audio playback for a session. Multiple files are played
+// back-to-back as a single playback, starting start into the audio. With
+// reportDTMF, digits the remote party presses are sent on eventCh. ctx is
+// that of the stream reading eventCh: events are dropped once it is done.
+func (m *Manager) PlayAudio(ctx context.Context, sessionID string, files []string, start time.Duration, reportDTMF bool, eventCh chan<- *rtpv1.PlaybackEvent) error {
+	m.mu.RLock()
+	sess, ok := m.sessions[sessionID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return fmt.Errorf("session not found: %s", sessionID)
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("no audio files to play")
+	}
+
+	return m.play(ctx, sess, media.PlayRequest{
+		File:     files[0],
+		Playlist: files[1:],
+		Start:    start,
+	}, reportDTMF, eventCh)
 }
 
-// PlayAudio starts audio playback for a session
-func (m *Manager) PlayAudio(sessionID, filePath string, eventCh chan<- *rtpv1.PlaybackEvent) error {
+// PlayTone plays a generated tone for a session. Cadenced tones repeat
+// until stopped unless duration is set. With machine, whether a person or
+// a machine answered is sent on eventCh once decided. ctx is that of the
+// stream reading eventCh, as for PlayAudio.
+func (m *Manager) PlayTone(ctx context.Context, sessionID string, tone *media.Tone, duration time.Duration, reportDTMF bool, machine *media.MachineDetectionConfig, eventCh chan<- *rtpv1.PlaybackEvent) error {
 	m.mu.RLock()
 	sess, ok := m.sessions[sessionID]
 	m.mu.RUnlock()
@@ -295,46 +453,104 @@ func (m *Manager) PlayAudio(sessionID, filePath string, eventCh chan<- *rtpv1.Pl
 		return fmt.Errorf("session not found: %s", sessionID)
 	}
 
+	return m.play(ctx, sess, media.PlayRequest{
+		Tone:          tone,
+		Duration:      duration,
+		DetectMachine: machine,
+	}, reportDTMF, eventCh)
+}
+
+// play fills in the session's media endpoints and event callbacks and starts
+// playback, reporting progress on eventCh until it is closed.
+func (m *Manager) play(ctx context.Context, sess *Session, playReq media.PlayRequest, reportDTMF bool, eventCh chan<- *rtpv1.PlaybackEvent) error {
+	sessionID := sess.ID
+
 	// Update state
 	sess.mu.Lock()
 	sess.State = rtpv1.SessionState_SESSION_STATE_ACTIVE
 	sess.mu.Unlock()
 
-	// Create play request
-	playReq := media.PlayRequest{
-		CallID:    sess.CallID,
-		File:      filePath,
-		Codec:     sess.Codec,
-		LocalAddr: sess.LocalAddr,
-		LocalPort: sess.LocalPort,
-		Endpoint:  sess.RemoteAddr,
-		Port:      sess.RemotePort,
-		OnComplete: func(callID string, data interface{}) error {
-			// Send completion event
-			eventCh <- &rtpv1.PlaybackEvent{
+	// Complete play request
+	playReq.CallID = sess.CallID
+	playReq.Codec = sess.Codec
+	playReq.LocalAddr = sess.LocalAddr
+	playReq.LocalPort = sess.LocalPort
+	playReq.Endpoint = sess.RemoteAddr
+	playReq.Port = sess.RemotePort
+	playReq.OnComplete = func(callID string, data interface{}) error {
+		// Send completion event
+		sendEvent(ctx, eventCh, &rtpv1.PlaybackEvent{
+			SessionId: sessionID,
+			Event: &rtpv1.PlaybackEvent_Completed{
+				Completed: &rtpv1.PlaybackCompleted{
+					TotalFramesSent: 0, // TODO: track actual frames
+				},
+			},
+		})
+		close(eventCh)
+		return nil
+	}
+	playReq.OnError = func(callID string, err error) {
+		// Send error event and close channel
+		sendEvent(ctx, eventCh, &rtpv1.PlaybackEvent{
+			SessionId: sessionID,
+			Event: &rtpv1.PlaybackEvent_Error{
+				Error: &rtpv1.PlaybackError{
+					Code:    "PLAYBACK_FAILED",
+					Message: err.Error(),
+				},
+			},
+		})
+		close(eventCh)
+	}
+	playReq.OnProgress = func(callID string, pos media.PlaybackPosition) {
+		// Progress is advisory; drop it rather than stall the media loop
+		select {
+		case eventCh <- &rtpv1.PlaybackEvent{
+			SessionId: sessionID,
+			Event: &rtpv1.PlaybackEvent_Progress{
+				Progress: playbackProgress(pos),
+			},
+		}:
+		default:
+		}
+	}
+	playReq.OnStopped = func(callID string, pos media.PlaybackPosition) {
+		sendEvent(ctx, eventCh, &rtpv1.PlaybackEvent{
+			SessionId: sessionID,
+			Event: &rtpv1.PlaybackEvent_Stopped{
+				Stopped: &rtpv1.PlaybackStopped{
+					Reason:     "stopped",
+					PositionMs: int32(pos.Position / time.Millisecond),
+				},
+			},
+		})
+		close(eventCh)
+	}
+	if reportDTMF {
+		playReq.OnDTMF = func(callID string, digit string) {
+			sendEvent(ctx, eventCh, &rtpv1.PlaybackEvent{
 				SessionId: sessionID,
-				Event: &rtpv1.PlaybackEvent_Completed{
-					Completed: &rtpv1.PlaybackCompleted{
-						TotalFramesSent: 0, // TODO: track actual frames
-					},
+				Event: &rtpv1.PlaybackEvent_Dtmf{
+					Dtmf: &rtpv1.DTMFReceived{Digit: digit},
 				},
-			}
-			close(eventCh)
-			return nil
-		},
-		OnError: func(callID string, err error) {
-			// Send error event and close channel
-			eventCh <- &rtpv1.PlaybackEvent{
+			})
+		}
+	}
+
+	if playReq.DetectMachine != nil {
+		playReq.OnMachine = func(callID string, result media.MachineDetection) {
+			sendEvent(ctx, eventCh, &rtpv1.PlaybackEvent{
 				SessionId: sessionID,
-				Event: &rtpv1.PlaybackEvent_Error{
-					Error: &rtpv1.PlaybackError{
-						Code:    "PLAYBACK_FAILED",
-						Message: err.Error(),
+				Event: &rtpv1.PlaybackEvent_Machine{
+					Machine: &rtpv1.MachineDetected{
+						AnsweredBy: answeredBy(result.AnsweredBy),
+						Reason:     result.Reason,
+						DurationMs: int32(result.Duration / time.Millisecond),
 					},
 				},
-			}
-			close(eventCh)
-		},
+			})
+		}
 	}
 
 	// Send started event
@@ -363,18 +579,109 @@ func (m *Manager) PlayAudio(sessionID, filePath string, eventCh chan<- *rtpv1.Pl
 	return nil
 }
 
-// StopAudio stops audio playback for a session
-func (m *Manager) StopAudio(sessionID string) (bool, error) {
+// sendEvent sends a playback event on eventCh unless ctx, that of the
+// stream reading it, is done: its handler stops reading when the client
+// goes away, and a blocked send would stall the media loop.
+func sendEvent(ctx context.Context, eventCh chan<- *rtpv1.PlaybackEvent, event *rtpv1.PlaybackEvent) {
+	select {
+	case eventCh <- event:
+	case <-ctx.Done():
+	}
+}
+
+// InjectAudio starts a live audio injection for a session.
+// The caller writes audio to the returned Injection and must Close or Abort it.
+func (m *Manager) InjectAudio(sessionID string, encoding media.Encoding, sampleRate int) (*media.Injection, error) {
+	m.mu.RLock()
+	sess, ok := m.sessions[sessionID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	sess.mu.Lock()
+	sess.State = rtpv1.SessionState_SESSION_STATE_ACTIVE
+	injReq := media.InjectRequest{
+		CallID:     sess.CallID,
+		Codec:      sess.Codec,
+		Encoding:   encoding,
+		SampleRate: sampleRate,
+		LocalAddr:  sess.LocalAddr,
+		LocalPort:  sess.LocalPort,
+		Endpoint:   sess.RemoteAddr,
+		Port:       sess.RemotePort,
+	}
+	sess.mu.Unlock()
+
+	return m.mediaService.Inject(sess.ctx, injReq)
+}
+
+// StopAudio stops audio playback for a session, returning the position
+// a file playback was stopped at
+func (m *Manager) StopAudio(sessionID string) (bool, media.PlaybackPosition, error) {
 	m.mu.RLock()
 	sess, ok := m.sessions[sessionID]
 	m.mu.RUnlock()
 
 	if !ok {
-		return false, nil // Idempotent
+		return false, media.PlaybackPosition{}, nil // Idempotent
 	}
 
+	pos, _ := m.mediaService.Position(sess.CallID)
 	err := m.mediaService.Stop(sess.CallID)
-	return err == nil, err
+	return err == nil, pos, err
+}
+
+// ControlPlayback pauses, resumes or seeks the active playback for a session.
+// An unspecified control only reports the position.
+func (m *Manager) ControlPlayback(sessionID string, control rtpv1.PlaybackControl, offset time.Duration) (media.PlaybackPosition, error) {
+	m.mu.RLock()
+	sess, ok := m.sessions[sessionID]
+	m.mu.RUnlock()
+
+	if !ok {
+		return media.PlaybackPosition{}, fmt.Errorf("session not found: %s", sessionID)
+	}
+
+	switch control {
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_PAUSE:
+		return m.mediaService.Pause(sess.CallID)
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_RESUME:
+		return m.mediaService.Resume(sess.CallID)
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_SEEK:
+		return m.mediaService.Seek(sess.CallID, offset, false)
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_SKIP:
+		return m.mediaService.Seek(sess.CallID, offset, true)
+	default:
+		return m.mediaService.Position(sess.CallID)
+	}
+}
+
+// playbackProgress converts a playback position to a progress event
+func playbackProgress(pos media.PlaybackPosition) *rtpv1.PlaybackProgress {
+	progress := &rtpv1.PlaybackProgress{
+		FramesSent: int32(pos.Position / (20 * time.Millisecond)),
+		PositionMs: int32(pos.Position / time.Millisecond),
+		DurationMs: int32(pos.Duration / time.Millisecond),
+		Paused:     pos.Paused,
+	}
+	if pos.Duration > 0 {
+		progress.PercentComplete = float32(pos.Position) / float32(pos.Duration) * 100
+	}
+	return progress
+}
+
+// answeredBy converts a machine detection result to its proto form
+func answeredBy(by media.AnsweredBy) rtpv1.AnsweredBy {
+	switch by {
+	case media.AnsweredByHuman:
+		return rtpv1.AnsweredBy_ANSWERED_BY_HUMAN
+	case media.AnsweredByMachine:
+		return rtpv1.AnsweredBy_ANSWERED_BY_MACHINE
+	default:
+		return rtpv1.AnsweredBy_ANSWERED_BY_UNKNOWN
+	}
 }
 
 // Count returns the number of active sessions
@@ -0,0 +1,143 @@
+package simulator
+
+import (
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/rtpmanager/media"
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// player tracks the position of a simulated playback on the wall clock.
+type player struct {
+	mu       sync.Mutex
+	duration time.Duration
+	loop     bool
+	offset   time.Duration // Position when last resumed or seeked
+	resumed  time.Time
+	paused   bool
+	timer    *time.Timer
+	done     chan struct{} // Closed when playback reaches the end
+	stopped  chan string   // Receives the reason when stopped
+	ended    bool
+}
+
+func newPlayer(duration, start time.Duration, loop bool) *player {
+	p := &player{
+		duration: duration,
+		loop:     loop,
+		offset:   start,
+		resumed:  time.Now(),
+		done:     make(chan struct{}),
+		stopped:  make(chan string, 1),
+	}
+	p.schedule()
+	return p
+}
+
+// schedule arms the completion timer with p.mu held; loops never complete.
+func (p *player) schedule() {
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	if p.loop || p.paused || p.ended {
+		return
+	}
+	p.timer = time.AfterFunc(max(p.duration-p.offset, 0), func() {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if !p.ended {
+			p.ended = true
+			close(p.done)
+		}
+	})
+}
+
+func (p *player) finished() <-chan struct{} {
+	return p.done
+}
+
+// stop ends the playback early.
+func (p *player) stop(reason string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.ended {
+		return
+	}
+	p.ended = true
+	if p.timer != nil {
+		p.timer.Stop()
+	}
+	p.stopped <- reason
+}
+
+// elapsed returns the position with p.mu held.
+func (p *player) elapsed() time.Duration {
+	pos := p.offset
+	if !p.paused {
+		pos += time.Since(p.resumed)
+	}
+	if p.duration <= 0 {
+		return 0
+	}
+	if p.loop {
+		return pos % p.duration
+	}
+	return min(pos, p.duration)
+}
+
+func (p *player) position() media.PlaybackPosition {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return media.PlaybackPosition{Position: p.elapsed(), Duration: p.duration, Paused: p.paused}
+}
+
+func (p *player) progress() *rtpv1.PlaybackProgress {
+	pos := p.position()
+	progress := &rtpv1.PlaybackProgress{
+		FramesSent: int32(pos.Position / (20 * time.Millisecond)),
+		PositionMs: int32(pos.Position / time.Millisecond),
+		DurationMs: int32(pos.Duration / time.Millisecond),
+		Paused:     pos.Paused,
+	}
+	if pos.Duration > 0 {
+		progress.PercentComplete = float32(pos.Position) / float32(pos.Duration) * 100
+	}
+	return progress
+}
+
+func (p *player) pause() media.PlaybackPosition {
+	p.mu.Lock()
+	if !p.paused {
+		p.offset = p.elapsed()
+		p.paused = true
+		p.schedule()
+	}
+	p.mu.Unlock()
+	return p.position()
+}
+
+func (p *player) resume() media.PlaybackPosition {
+	p.mu.Lock()
+	if p.paused {
+		p.paused = false
+		p.resumed = time.Now()
+		p.schedule()
+	}
+	p.mu.Unlock()
+	return p.position()
+}
+
+// seek moves to offset from the start, or by offset when relative.
+func (p *player) seek(offset time.Duration, relative bool) media.PlaybackPosition {
+	p.mu.Lock()
+	if relative {
+		offset += p.elapsed()
+	}
+	p.offset = min(max(offset, 0), p.duration)
+	p.resumed = time.Now()
+	p.schedule()
+	p.mu.Unlock()
+	return p.position()
+}
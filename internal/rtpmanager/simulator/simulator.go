@@ -0,0 +1,708 @@
+// Package simulator implements the RTP Manager gRPC API without media, for
+// running the full stack on a laptop or in CI.
+//
+// Sessions are given ports from the configured range that are never
+// opened, so nothing is bound and nothing conflicts with other processes.
+// Playback sends no RTP but reports started, progress and completed events
+// on the real timeline (a WAV file's length, a tone's duration), so
+// dialplans and IVRs behave as with real media. Bridges only record their
+// legs. No DTMF or audio is ever received.
+package simulator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/rtpmanager/media"
+	"github.com/sebas/switchboard/internal/rtpmanager/portpool"
+	"github.com/sebas/switchboard/internal/rtpmanager/sdp"
+	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
+)
+
+// DefaultPlayDuration is how long files play when their length cannot be read
+const DefaultPlayDuration = 3 * time.Second
+
+// progressInterval paces progress events, as the real playback does
+const progressInterval = time.Second
+
+// Config holds simulator configuration
+type Config struct {
+	AdvertiseAddr  string
+	AdvertiseRules string
+	AdvertiseAddr6 string
+	RTPPortMin     int
+	RTPPortMax     int
+	RTPPorts       string // Comma-separated port ranges; overrides RTPPortMin/RTPPortMax
+
+	// PlayDuration is used for files whose length cannot be read
+	// (missing, remote or not WAV); zero uses DefaultPlayDuration
+	PlayDuration time.Duration
+
+	// TonePlan is the default country tone plan for PlayTone
+	TonePlan string
+}
+
+// session is a simulated media session
+type session struct {
+	id         string
+	callID     string
+	localAddr  string
+	localPort  int
+	remoteAddr string
+	remotePort int
+	codec      string
+	bridgeID   string
+	owner      string
+	created    time.Time
+	player     *player
+	done       chan struct{} // Closed when the session is destroyed
+}
+
+// Server implements RTPManagerService with simulated media
+type Server struct {
+	rtpv1.UnimplementedRTPManagerServiceServer
+	cfg       Config
+	advertise *advertise.Selector
+
+	mu       sync.Mutex
+	sessions map[string]*session // sessionID -> session
+	byCall   map[string]string   // callID -> sessionID
+	bridges  map[string][2]string
+	ports    *portpool.PortPool
+	instance string // Reported in Health so signaling notices restarts
+	draining bool
+}
+
+// NewServer creates a simulated RTP Manager server
+func NewServer(cfg Config) (*Server, error) {
+	if cfg.TonePlan != "" && !slices.Contains(media.TonePlans(), strings.ToLower(cfg.TonePlan)) {
+		return nil, fmt.Errorf("unknown tone plan %q (available: %s)", cfg.TonePlan, strings.Join(media.TonePlans(), ", "))
+	}
+	ranges := []portpool.Range{{Min: cfg.RTPPortMin, Max: cfg.RTPPortMax}}
+	if cfg.RTPPorts != "" {
+		var err error
+		if ranges, err = portpool.ParseRanges(cfg.RTPPorts); err != nil {
+			return nil, err
+		}
+	}
+	if cfg.PlayDuration <= 0 {
+		cfg.PlayDuration = DefaultPlayDuration
+	}
+	selector, err := advertise.Parse(cfg.AdvertiseAddr, cfg.AdvertiseRules)
+	if err != nil {
+		return nil, err
+	}
+	selector.SetIPv6Default(cfg.AdvertiseAddr6)
+
+	return &Server{
+		cfg:       cfg,
+		advertise: selector,
+		sessions:  make(map[string]*session),
+		byCall:    make(map[string]string),
+		bridges:   make(map[string][2]string),
+		ports:     portpool.NewVirtualPortPool(ranges...),
+		instance:  uuid.New().String(),
+	}, nil
+}
+
+// errorStatus builds a SessionStatus carrying an error
+func errorStatus(err error) *rtpv1.SessionStatus {
+	return &rtpv1.SessionStatus{
+		State:        rtpv1.SessionState_SESSION_STATE_ERROR,
+		ErrorMessage: err.Error(),
+	}
+}
+
+func (s *Server) get(sessionID string) (*session, error) {
+	sess, ok := s.sessions[sessionID]
+	if !ok {
+		return nil, fmt.Errorf("session not found: %s", sessionID)
+	}
+	return sess, nil
+}
+
+// CreateSession implements RTPManagerService.CreateSession
+func (s *Server) CreateSession(ctx context.Context, req *rtpv1.CreateSessionRequest) (*rtpv1.CreateSessionResponse, error) {
+	slog.Info("[Simulator] CreateSession",
+		"call_id", req.CallId,
+		"remote", fmt.Sprintf("%s:%d", req.RemoteAddr, req.RemotePort),
+		"codecs", req.OfferedCodecs)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if id, ok := s.byCall[req.CallId]; ok {
+		sess := s.sessions[id]
+		return createResponse(sess), nil
+	}
+
+	if !slices.Contains(req.OfferedCodecs, "0") {
+		status := errorStatus(errors.New("no supported codec offered (PCMU required)"))
+		status.Rejected = true
+		return &rtpv1.CreateSessionResponse{Status: status}, nil
+	}
+	port, _, err := s.ports.Allocate()
+	if err != nil {
+		return &rtpv1.CreateSessionResponse{Status: errorStatus(fmt.Errorf("failed to allocate ports: %w", err))}, nil
+	}
+
+	peer := req.PeerAddr
+	if req.RemoteAddr != "" {
+		peer = req.RemoteAddr
+	}
+	sess := &session{
+		id:         uuid.New().String(),
+		callID:     req.CallId,
+		localAddr:  s.advertise.Select(peer),
+		localPort:  port,
+		remoteAddr: req.RemoteAddr,
+		remotePort: int(req.RemotePort),
+		codec:      "0",
+		owner:      req.Owner,
+		created:    time.Now(),
+		done:       make(chan struct{}),
+	}
+	s.sessions[sess.id] = sess
+	s.byCall[sess.callID] = sess.id
+
+	return createResponse(sess), nil
+}
+
+func createResponse(sess *session) *rtpv1.CreateSessionResponse {
+	return &rtpv1.CreateSessionResponse{
+		SessionId:     sess.id,
+		LocalAddr:     sess.localAddr,
+		LocalPort:     int32(sess.localPort),
+		SelectedCodec: sess.codec,
+		SdpBody:       sdp.BuildResponseSDP(sess.localAddr, sess.localPort, sess.codec),
+		Status:        &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_CREATED},
+	}
+}
+
+// DestroySession implements RTPManagerService.DestroySession
+func (s *Server) DestroySession(ctx context.Context, req *rtpv1.DestroySessionRequest) (*rtpv1.DestroySessionResponse, error) {
+	slog.Info("[Simulator] DestroySession", "session_id", req.SessionId, "reason", req.Reason)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, err := s.get(req.SessionId)
+	if err != nil {
+		return &rtpv1.DestroySessionResponse{SessionId: req.SessionId, Status: errorStatus(err)}, nil
+	}
+	s.destroy(sess)
+
+	return &rtpv1.DestroySessionResponse{
+		SessionId: req.SessionId,
+		Status:    &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_TERMINATED},
+	}, nil
+}
+
+// destroy removes a session with s.mu held
+func (s *Server) destroy(sess *session) {
+	if sess.player != nil {
+		sess.player.stop("session destroyed")
+	}
+	if sess.bridgeID != "" {
+		s.unbridge(sess.bridgeID)
+	}
+	close(sess.done)
+	s.ports.Release(sess.localPort)
+	delete(s.sessions, sess.id)
+	delete(s.byCall, sess.callID)
+}
+
+// UpdateSessionRemote implements RTPManagerService.UpdateSessionRemote
+func (s *Server) UpdateSessionRemote(ctx context.Context, req *rtpv1.UpdateSessionRemoteRequest) (*rtpv1.UpdateSessionRemoteResponse, error) {
+	slog.Info("[Simulator] UpdateSessionRemote",
+		"session_id", req.SessionId,
+		"remote", fmt.Sprintf("%s:%d", req.RemoteAddr, req.RemotePort))
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, err := s.get(req.SessionId)
+	if err != nil {
+		return &rtpv1.UpdateSessionRemoteResponse{SessionId: req.SessionId, Status: errorStatus(err)}, nil
+	}
+	sess.remoteAddr = req.RemoteAddr
+	sess.remotePort = int(req.RemotePort)
+
+	return &rtpv1.UpdateSessionRemoteResponse{
+		SessionId: req.SessionId,
+		Status:    &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_ACTIVE},
+	}, nil
+}
+
+// PlayAudio implements RTPManagerService.PlayAudio (server streaming)
+func (s *Server) PlayAudio(req *rtpv1.PlayAudioRequest, stream rtpv1.RTPManagerService_PlayAudioServer) error {
+	slog.Info("[Simulator] PlayAudio", "session_id", req.SessionId, "file", req.FilePath, "playlist", len(req.Playlist))
+
+	sources := req.Playlist
+	if req.FilePath != "" {
+		sources = append([]string{req.FilePath}, req.Playlist...)
+	}
+	var duration time.Duration
+	for _, source := range sources {
+		duration += s.fileDuration(source)
+	}
+	start := min(time.Duration(req.StartMs)*time.Millisecond, duration)
+
+	return s.play(req.SessionId, stream, duration, start, req.Loop)
+}
+
+// PlayTone implements RTPManagerService.PlayTone (server streaming)
+func (s *Server) PlayTone(req *rtpv1.PlayToneRequest, stream rtpv1.RTPManagerService_PlayToneServer) error {
+	slog.Info("[Simulator] PlayTone", "session_id", req.SessionId, "tone", req.Tone, "duration_ms", req.DurationMs)
+
+	country := req.Country
+	if country == "" {
+		country = s.cfg.TonePlan
+	}
+	tone, err := media.LookupTone(req.Tone, country)
+	if err != nil {
+		return stream.Send(&rtpv1.PlaybackEvent{
+			SessionId: req.SessionId,
+			Event: &rtpv1.PlaybackEvent_Error{
+				Error: &rtpv1.PlaybackError{Code: "INVALID_TONE", Message: err.Error()},
+			},
+		})
+	}
+
+	duration := time.Duration(req.DurationMs) * time.Millisecond
+	loop := false
+	if duration == 0 {
+		if len(tone.Cadence) > 0 {
+			loop = true // Cadenced tones repeat until stopped
+		}
+		pcm, _ := tone.Render()
+		duration = time.Duration(len(pcm)/2) * time.Second / 8000
+	}
+	return s.play(req.SessionId, stream, duration, 0, loop)
+}
+
+// play runs a simulated playback, replacing the session's current one,
+// and streams its events until it ends.
+func (s *Server) play(sessionID string, stream rtpv1.RTPManagerService_PlayAudioServer, duration, start time.Duration, loop bool) error {
+	s.mu.Lock()
+	sess, err := s.get(sessionID)
+	if err != nil {
+		s.mu.Unlock()
+		return err
+	}
+	if sess.player != nil {
+		sess.player.stop("stopped")
+	}
+	p := newPlayer(duration, start, loop)
+	sess.player = p
+	s.mu.Unlock()
+
+	defer func() {
+		s.mu.Lock()
+		if sess.player == p {
+			sess.player = nil
+		}
+		s.mu.Unlock()
+	}()
+
+	send := func(event *rtpv1.PlaybackEvent) error {
+		event.SessionId = sessionID
+		return stream.Send(event)
+	}
+	if err := send(&rtpv1.PlaybackEvent{Event: &rtpv1.PlaybackEvent_Started{
+		Started: &rtpv1.PlaybackStarted{
+			TotalFrames: int32(duration / (20 * time.Millisecond)),
+			DurationMs:  int32(duration / time.Millisecond),
+		},
+	}}); err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(progressInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			p.stop("canceled")
+			return nil
+		case <-ticker.C:
+			_ = send(&rtpv1.PlaybackEvent{Event: &rtpv1.PlaybackEvent_Progress{Progress: p.progress()}})
+		case <-p.finished():
+			pos := p.position()
+			return send(&rtpv1.PlaybackEvent{Event: &rtpv1.PlaybackEvent_Completed{
+				Completed: &rtpv1.PlaybackCompleted{
+					TotalFramesSent: int32(pos.Position / (20 * time.Millisecond)),
+					DurationMs:      int32(pos.Duration / time.Millisecond),
+				},
+			}})
+		case reason := <-p.stopped:
+			pos := p.position()
+			return send(&rtpv1.PlaybackEvent{Event: &rtpv1.PlaybackEvent_Stopped{
+				Stopped: &rtpv1.PlaybackStopped{
+					Reason:     reason,
+					FramesSent: int32(pos.Position / (20 * time.Millisecond)),
+					PositionMs: int32(pos.Position / time.Millisecond),
+				},
+			}})
+		}
+	}
+}
+
+// fileDuration returns the length of a local WAV file, or the configured
+// play duration
+func (s *Server) fileDuration(path string) time.Duration {
+	audio, err := media.ReadWAVFile(path)
+	if err != nil || audio.SampleRate == 0 || audio.NumChannels == 0 || audio.BitsPerSample < 8 {
+		return s.cfg.PlayDuration
+	}
+	bytesPerSecond := int(audio.SampleRate) * int(audio.NumChannels) * int(audio.BitsPerSample/8)
+	return time.Duration(len(audio.PCMData)) * time.Second / time.Duration(bytesPerSecond)
+}
+
+// StopAudio implements RTPManagerService.StopAudio
+func (s *Server) StopAudio(ctx context.Context, req *rtpv1.StopAudioRequest) (*rtpv1.StopAudioResponse, error) {
+	slog.Info("[Simulator] StopAudio", "session_id", req.SessionId)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &rtpv1.StopAudioResponse{SessionId: req.SessionId}
+	sess, ok := s.sessions[req.SessionId]
+	if !ok || sess.player == nil {
+		return resp, nil
+	}
+	resp.WasPlaying = true
+	resp.PositionMs = int32(sess.player.position().Position / time.Millisecond)
+	sess.player.stop("stopped")
+	sess.player = nil
+	return resp, nil
+}
+
+// ControlPlayback implements RTPManagerService.ControlPlayback
+func (s *Server) ControlPlayback(ctx context.Context, req *rtpv1.ControlPlaybackRequest) (*rtpv1.ControlPlaybackResponse, error) {
+	slog.Info("[Simulator] ControlPlayback", "session_id", req.SessionId, "control", req.Control.String(), "position_ms", req.PositionMs)
+
+	s.mu.Lock()
+	sess, err := s.get(req.SessionId)
+	if err == nil && sess.player == nil {
+		err = errors.New("no active playback")
+	}
+	if err != nil {
+		s.mu.Unlock()
+		return &rtpv1.ControlPlaybackResponse{SessionId: req.SessionId, Status: errorStatus(err)}, nil
+	}
+	p := sess.player
+	s.mu.Unlock()
+
+	offset := time.Duration(req.PositionMs) * time.Millisecond
+	var pos media.PlaybackPosition
+	switch req.Control {
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_PAUSE:
+		pos = p.pause()
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_RESUME:
+		pos = p.resume()
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_SEEK:
+		pos = p.seek(offset, false)
+	case rtpv1.PlaybackControl_PLAYBACK_CONTROL_SKIP:
+		pos = p.seek(offset, true)
+	default:
+		pos = p.position()
+	}
+
+	return &rtpv1.ControlPlaybackResponse{
+		SessionId:  req.SessionId,
+		Paused:     pos.Paused,
+		PositionMs: int32(pos.Position / time.Millisecond),
+		DurationMs: int32(pos.Duration / time.Millisecond),
+		Status:     &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_ACTIVE},
+	}, nil
+}
+
+// InjectAudio implements RTPManagerService.InjectAudio (client streaming).
+// Audio is discarded after the time it would take to play out.
+func (s *Server) InjectAudio(stream rtpv1.RTPManagerService_InjectAudioServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	slog.Info("[Simulator] InjectAudio", "session_id", first.SessionId, "encoding", first.Encoding.String())
+
+	bytesPerFrame := 160 // PCMU: 8 kHz, 20ms
+	switch first.Encoding {
+	case rtpv1.AudioEncoding_AUDIO_ENCODING_PCMU:
+	case rtpv1.AudioEncoding_AUDIO_ENCODING_PCM_S16LE:
+		rate := int(first.SampleRate)
+		if rate == 0 {
+			rate = 8000
+		}
+		bytesPerFrame = rate * 2 / 50
+	default:
+		return stream.SendAndClose(&rtpv1.InjectAudioResponse{
+			SessionId: first.SessionId,
+			Status:    errorStatus(fmt.Errorf("unsupported encoding: %s", first.Encoding)),
+		})
+	}
+
+	s.mu.Lock()
+	sess, err := s.get(first.SessionId)
+	s.mu.Unlock()
+	if err != nil {
+		return stream.SendAndClose(&rtpv1.InjectAudioResponse{SessionId: first.SessionId, Status: errorStatus(err)})
+	}
+
+	started := time.Now()
+	total := 0
+	for req := first; ; {
+		total += len(req.Payload)
+		req, err = stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return err
+		}
+	}
+	frames := (total + bytesPerFrame - 1) / bytesPerFrame
+
+	// Finish when playout would have
+	select {
+	case <-time.After(time.Until(started.Add(time.Duration(frames) * 20 * time.Millisecond))):
+	case <-sess.done:
+	case <-stream.Context().Done():
+		return stream.Context().Err()
+	}
+
+	return stream.SendAndClose(&rtpv1.InjectAudioResponse{
+		SessionId:  first.SessionId,
+		FramesSent: int32(frames),
+		DurationMs: int32(frames * 20),
+		Status:     &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_ACTIVE},
+	})
+}
+
+// CaptureAudio implements RTPManagerService.CaptureAudio (server
+// streaming). No audio is received, so no frames are sent; the stream ends
+// with the bridge, as the real capture does.
+func (s *Server) CaptureAudio(req *rtpv1.CaptureAudioRequest, stream rtpv1.RTPManagerService_CaptureAudioServer) error {
+	slog.Info("[Simulator] CaptureAudio", "session_id", req.SessionId)
+
+	s.mu.Lock()
+	sess, err := s.get(req.SessionId)
+	if err == nil && sess.bridgeID == "" {
+		err = fmt.Errorf("session %s is not bridged", req.SessionId)
+	}
+	s.mu.Unlock()
+	if err != nil {
+		return err
+	}
+
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stream.Context().Done():
+			return nil
+		case <-sess.done:
+			return nil
+		case <-ticker.C:
+			s.mu.Lock()
+			bridged := sess.bridgeID != ""
+			s.mu.Unlock()
+			if !bridged {
+				return nil
+			}
+		}
+	}
+}
+
+// BridgeMedia implements RTPManagerService.BridgeMedia
+func (s *Server) BridgeMedia(ctx context.Context, req *rtpv1.BridgeMediaRequest) (*rtpv1.BridgeMediaResponse, error) {
+	slog.Info("[Simulator] BridgeMedia", "session_a", req.SessionAId, "session_b", req.SessionBId)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	a, err := s.get(req.SessionAId)
+	if err != nil {
+		return &rtpv1.BridgeMediaResponse{Status: errorStatus(fmt.Errorf("session A: %w", err))}, nil
+	}
+	b, err := s.get(req.SessionBId)
+	if err != nil {
+		return &rtpv1.BridgeMediaResponse{Status: errorStatus(fmt.Errorf("session B: %w", err))}, nil
+	}
+	for _, sess := range []*session{a, b} {
+		if sess.bridgeID != "" {
+			return &rtpv1.BridgeMediaResponse{Status: errorStatus(fmt.Errorf("session %s already bridged", sess.id))}, nil
+		}
+	}
+
+	bridgeID := uuid.New().String()
+	s.bridges[bridgeID] = [2]string{a.id, b.id}
+	for _, sess := range []*session{a, b} {
+		if sess.player != nil {
+			sess.player.stop("stopped")
+			sess.player = nil
+		}
+		sess.bridgeID = bridgeID
+	}
+
+	return &rtpv1.BridgeMediaResponse{
+		BridgeId: bridgeID,
+		Status:   &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_BRIDGED},
+	}, nil
+}
+
+// UnbridgeMedia implements RTPManagerService.UnbridgeMedia
+func (s *Server) UnbridgeMedia(ctx context.Context, req *rtpv1.UnbridgeMediaRequest) (*rtpv1.UnbridgeMediaResponse, error) {
+	slog.Info("[Simulator] UnbridgeMedia", "bridge_id", req.BridgeId, "session_id", req.SessionId)
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	bridgeID := req.BridgeId
+	if bridgeID == "" && req.SessionId != "" {
+		if sess, ok := s.sessions[req.SessionId]; ok {
+			bridgeID = sess.bridgeID
+		}
+	}
+	if bridgeID == "" {
+		return &rtpv1.UnbridgeMediaResponse{Status: errorStatus(errors.New("bridge_id or session_id of a bridged session required"))}, nil
+	}
+	if _, ok := s.bridges[bridgeID]; !ok {
+		return &rtpv1.UnbridgeMediaResponse{BridgeId: bridgeID, Status: errorStatus(fmt.Errorf("bridge not found: %s", bridgeID))}, nil
+	}
+	s.unbridge(bridgeID)
+
+	return &rtpv1.UnbridgeMediaResponse{
+		BridgeId: bridgeID,
+		Status:   &rtpv1.SessionStatus{State: rtpv1.SessionState_SESSION_STATE_TERMINATED},
+	}, nil
+}
+
+// unbridge removes a bridge with s.mu held
+func (s *Server) unbridge(bridgeID string) {
+	for _, id := range s.bridges[bridgeID] {
+		if sess, ok := s.sessions[id]; ok {
+			sess.bridgeID = ""
+		}
+	}
+	delete(s.bridges, bridgeID)
+}
+
+// Gauges returns the values pushed to a metrics exporter. Names ending
+// in _total are running totals.
+func (s *Server) Gauges() map[string]float64 {
+	ports := s.ports.Stats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return map[string]float64{
+		"sessions":                float64(len(s.sessions)),
+		"bridges":                 float64(len(s.bridges)),
+		"ports.total":             float64(ports.Total),
+		"ports.allocated":         float64(ports.Allocated),
+		"ports.available":         float64(ports.Available),
+		"ports.utilization":       ports.Utilization,
+		"ports.exhaustions_total": float64(ports.Exhausted),
+	}
+}
+
+// Health implements RTPManagerService.Health
+func (s *Server) Health(ctx context.Context, req *rtpv1.HealthRequest) (*rtpv1.HealthResponse, error) {
+	ports := s.ports.Stats()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &rtpv1.HealthResponse{
+		Healthy:         true,
+		ActiveSessions:  int32(len(s.sessions)),
+		AvailablePorts:  int32(ports.Available),
+		TotalPorts:      int32(ports.Total),
+		AllocatedPorts:  int32(ports.Allocated),
+		PortExhaustions: ports.Exhausted,
+		InstanceId:      s.instance,
+		DrainRequested:  s.draining,
+	}, nil
+}
+
+// ListSessions implements RTPManagerService.ListSessions
+func (s *Server) ListSessions(ctx context.Context, req *rtpv1.ListSessionsRequest) (*rtpv1.ListSessionsResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &rtpv1.ListSessionsResponse{}
+	for _, sess := range s.sessions {
+		if req.Owner != "" && sess.owner != req.Owner {
+			continue
+		}
+		state := rtpv1.SessionState_SESSION_STATE_ACTIVE
+		switch {
+		case sess.bridgeID != "":
+			state = rtpv1.SessionState_SESSION_STATE_BRIDGED
+		case sess.remoteAddr == "":
+			state = rtpv1.SessionState_SESSION_STATE_PENDING_REMOTE
+		}
+		resp.Sessions = append(resp.Sessions, &rtpv1.SessionSummary{
+			SessionId:  sess.id,
+			CallId:     sess.callID,
+			State:      state,
+			Owner:      sess.owner,
+			AgeSeconds: int32(time.Since(sess.created).Seconds()),
+		})
+	}
+	return resp, nil
+}
+
+// RequestDrain asks the signaling servers to move their sessions off the
+// simulator
+func (s *Server) RequestDrain() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if !s.draining {
+		s.draining = true
+		slog.Info("[Simulator] Drain requested", "sessions", len(s.sessions))
+	}
+}
+
+// DrainStatus returns whether a drain was requested and the sessions
+// still held
+func (s *Server) DrainStatus() (requested bool, sessions int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.draining, len(s.sessions)
+}
+
+// ListBridges implements RTPManagerService.ListBridges. Simulated bridges
+// forward no media, so their counters stay at zero.
+func (s *Server) ListBridges(ctx context.Context, req *rtpv1.ListBridgesRequest) (*rtpv1.ListBridgesResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	resp := &rtpv1.ListBridgesResponse{}
+	for id, sessions := range s.bridges {
+		resp.Bridges = append(resp.Bridges, &rtpv1.BridgeSummary{
+			BridgeId:   id,
+			SessionAId: sessions[0],
+			SessionBId: sessions[1],
+		})
+	}
+	return resp, nil
+}
+
+// Ready reports whether the simulator can take new sessions
+func (s *Server) Ready(ctx context.Context) error {
+	if s.ports.Available() == 0 {
+		return errors.New("no RTP ports available")
+	}
+	return nil
+}
+
+// Close destroys all sessions
+func (s *Server) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, sess := range s.sessions {
+		s.destroy(sess)
+	}
+	return nil
+}
@@ -0,0 +1,362 @@
+// Package s3 is a minimal Amazon S3 client (and S3-compatible stores such
+// as MinIO) using AWS Signature Version 4. It covers the few object
+// operations switchboard needs without pulling in the AWS SDK.
+package s3
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// emptyPayloadHash is the SHA-256 of an empty body
+const emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+
+// Config holds S3 connection settings.
+type Config struct {
+	// Region is the bucket region.
+	// Default: us-east-1
+	Region string
+
+	// Endpoint overrides the AWS endpoint (e.g. "http://minio:9000").
+	// Requests use path-style addressing when set.
+	Endpoint string
+
+	// Credentials; requests are sent unsigned when AccessKeyID is empty
+	// (public buckets only).
+	AccessKeyID     string
+	SecretAccessKey string
+	SessionToken    string
+}
+
+// ConfigFromEnv reads the standard AWS environment variables
+// (AWS_REGION, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY, AWS_SESSION_TOKEN)
+// plus S3_ENDPOINT for S3-compatible stores.
+func ConfigFromEnv() Config {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = os.Getenv("AWS_DEFAULT_REGION")
+	}
+	return Config{
+		Region:          region,
+		Endpoint:        os.Getenv("S3_ENDPOINT"),
+		AccessKeyID:     os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretAccessKey: os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken:    os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Client issues signed requests to S3.
+type Client struct {
+	cfg  Config
+	http *http.Client
+}
+
+// New creates a client. If httpClient is nil, http.DefaultClient is used.
+func New(cfg Config, httpClient *http.Client) *Client {
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+	if httpClient == nil {
+		httpClient = http.DefaultClient
+	}
+	return &Client{cfg: cfg, http: httpClient}
+}
+
+// ParseURL splits an "s3://bucket/key" URL into bucket and key.
+func ParseURL(raw string) (bucket, key string, err error) {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != "s3" {
+		return "", "", fmt.Errorf("not an s3 URL: %s", raw)
+	}
+	key = strings.TrimPrefix(u.Path, "/")
+	if u.Host == "" || key == "" {
+		return "", "", fmt.Errorf("s3 URL must be s3://bucket/key: %s", raw)
+	}
+	return u.Host, key, nil
+}
+
+// Get fetches an object. Extra headers (e.g. If-None-Match) are sent as-is.
+// The caller must close the response body. Non-2xx responses are returned
+// without error so callers can handle 304 and 404.
+func (c *Client) Get(ctx context.Context, bucket, key string, header http.Header) (*http.Response, error) {
+	req, err := c.newRequest(ctx, http.MethodGet, bucket, key)
+	if err != nil {
+		return nil, err
+	}
+	for k, vs := range header {
+		for _, v := range vs {
+			req.Header.Add(k, v)
+		}
+	}
+	c.sign(req, emptyPayloadHash, time.Now().UTC())
+	return c.http.Do(req)
+}
+
+// Put uploads an object.
+func (c *Client) Put(ctx context.Context, bucket, key string, data []byte, contentType string) error {
+	req, err := c.newRequest(ctx, http.MethodPut, bucket, key)
+	if err != nil {
+		return err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(data))
+	req.ContentLength = int64(len(data))
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	c.sign(req, hashHex(data), time.Now().UTC())
+	return c.do(req)
+}
+
+// Delete removes an object. Deleting a missing object is not an error.
+func (c *Client) Delete(ctx context.Context, bucket, key string) error {
+	req, err := c.newRequest(ctx, http.MethodDelete, bucket, key)
+	if err != nil {
+		return err
+	}
+	c.sign(req, emptyPayloadHash, time.Now().UTC())
+	return c.do(req)
+}
+
+// Object describes a stored object.
+type Object struct {
+	Key          string    `xml:"Key"`
+	Size         int64     `xml:"Size"`
+	LastModified time.Time `xml:"LastModified"`
+}
+
+// listResult is the ListObjectsV2 response body.
+type listResult struct {
+	Contents              []Object `xml:"Contents"`
+	IsTruncated           bool     `xml:"IsTruncated"`
+	NextContinuationToken string   `xml:"NextContinuationToken"`
+}
+
+// List returns all objects whose keys start with prefix, following pagination.
+func (c *Client) List(ctx context.Context, bucket, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		req, err := c.newRequest(ctx, http.MethodGet, bucket, "")
+		if err != nil {
+			return nil, err
+		}
+		query := url.Values{"list-type": {"2"}}
+		if prefix != "" {
+			query.Set("prefix", prefix)
+		}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req.URL.RawQuery = canonicalQuery(query)
+		c.sign(req, emptyPayloadHash, time.Now().UTC())
+
+		resp, err := c.http.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("s3 list %s: %s", bucket, resp.Status)
+		}
+
+		var result listResult
+		if err := xml.Unmarshal(body, &result); err != nil {
+			return nil, fmt.Errorf("s3 list %s: %w", bucket, err)
+		}
+		objects = append(objects, result.Contents...)
+		if !result.IsTruncated || result.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = result.NextContinuationToken
+	}
+}
+
+// do sends a request whose response body is not needed.
+func (c *Client) do(req *http.Request) error {
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("s3 %s %s: %s", req.Method, req.URL.Path, resp.Status)
+	}
+	return nil
+}
+
+// newRequest builds a request for an object URL. An empty key addresses
+// the bucket itself.
+func (c *Client) newRequest(ctx context.Context, method, bucket, key string) (*http.Request, error) {
+	var u *url.URL
+	if c.cfg.Endpoint != "" || strings.Contains(bucket, ".") {
+		// Path-style: custom endpoints, and buckets whose dots break TLS wildcard certs
+		base := c.cfg.Endpoint
+		if base == "" {
+			base = fmt.Sprintf("https://s3.%s.amazonaws.com", c.cfg.Region)
+		}
+		parsed, err := url.Parse(base)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint: %w", err)
+		}
+		u = parsed
+		u.Path = "/" + bucket + "/" + key
+	} else {
+		u = &url.URL{
+			Scheme: "https",
+			Host:   fmt.Sprintf("%s.s3.%s.amazonaws.com", bucket, c.cfg.Region),
+			Path:   "/" + key,
+		}
+	}
+	u.RawPath = encodePath(u.Path)
+
+	return http.NewRequestWithContext(ctx, method, u.String(), nil)
+}
+
+// sign adds AWS Signature Version 4 headers to the request.
+func (c *Client) sign(req *http.Request, payloadHash string, now time.Time) {
+	SignV4(req, c.cfg, "s3", payloadHash, now)
+}
+
+// SignV4 adds AWS Signature Version 4 headers for service to a request
+// whose body has the SHA-256 payloadHash (hex), so other AWS APIs can be
+// called with the same credentials. Nothing is added when cfg has no
+// AccessKeyID.
+func SignV4(req *http.Request, cfg Config, service, payloadHash string, now time.Time) {
+	if cfg.AccessKeyID == "" {
+		return
+	}
+	if cfg.Region == "" {
+		cfg.Region = "us-east-1"
+	}
+
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	if cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", cfg.SessionToken)
+	}
+
+	// Canonical headers: host plus all x-amz-* headers, lowercase and sorted
+	headers := map[string]string{"host": req.URL.Host}
+	for k := range req.Header {
+		lk := strings.ToLower(k)
+		if strings.HasPrefix(lk, "x-amz-") {
+			headers[lk] = strings.TrimSpace(req.Header.Get(k))
+		}
+	}
+	names := make([]string, 0, len(headers))
+	for k := range headers {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, k := range names {
+		canonicalHeaders.WriteString(k + ":" + headers[k] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		req.URL.EscapedPath(),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + cfg.Region + "/" + service + "/aws4_request"
+	stringToSign := strings.Join([]string{
+		"AWS4-HMAC-SHA256",
+		amzDate,
+		scope,
+		hashHex([]byte(canonicalRequest)),
+	}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+cfg.SecretAccessKey), date)
+	key = hmacSHA256(key, cfg.Region)
+	key = hmacSHA256(key, service)
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf(
+		"AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		cfg.AccessKeyID, scope, signedHeaders, signature,
+	))
+}
+
+// canonicalQuery encodes query parameters sorted by name, with values
+// URI-encoded per the SigV4 rules (url.Values.Encode uses "+" for spaces).
+func canonicalQuery(query url.Values) string {
+	names := make([]string, 0, len(query))
+	for k := range query {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	var parts []string
+	for _, k := range names {
+		for _, v := range query[k] {
+			parts = append(parts, uriEncode(k)+"="+uriEncode(v))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// encodePath URI-encodes each path segment per the SigV4 rules for S3.
+func encodePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, seg := range segments {
+		segments[i] = uriEncode(seg)
+	}
+	return strings.Join(segments, "/")
+}
+
+// uriEncode encodes everything except RFC 3986 unreserved characters.
+func uriEncode(s string) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		ch := s[i]
+		if (ch >= 'A' && ch <= 'Z') || (ch >= 'a' && ch <= 'z') || (ch >= '0' && ch <= '9') ||
+			ch == '-' || ch == '_' || ch == '.' || ch == '~' {
+			b.WriteByte(ch)
+		} else {
+			fmt.Fprintf(&b, "%%%02X", ch)
+		}
+	}
+	return b.String()
+}
+
+func hashHex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
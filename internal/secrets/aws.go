@@ -0,0 +1,62 @@
+package secrets
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+// awsSecret reads an AWS Secrets Manager secret (GetSecretValue), or a
+// key of its value when the value is a JSON object
+func (r *Resolver) awsSecret(ctx context.Context, id, key string) (string, error) {
+	payload, err := json.Marshal(map[string]string{"SecretId": id})
+	if err != nil {
+		return "", err
+	}
+	endpoint := r.cfg.AWSEndpoint
+	if endpoint == "" {
+		endpoint = fmt.Sprintf("https://secretsmanager.%s.amazonaws.com", r.cfg.AWS.Region)
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, strings.TrimSuffix(endpoint, "/")+"/", bytes.NewReader(payload))
+	if err != nil {
+		return "", fmt.Errorf("secrets manager: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", "secretsmanager.GetSecretValue")
+	sum := sha256.Sum256(payload)
+	s3.SignV4(req, r.cfg.AWS, "secretsmanager", hex.EncodeToString(sum[:]), time.Now().UTC())
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("secrets manager: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("secrets manager: get %s: %s: %s", id, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var body struct {
+		SecretString string `json:"SecretString"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("secrets manager: get %s: %w", id, err)
+	}
+	if key == "" {
+		return body.SecretString, nil
+	}
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(body.SecretString), &fields); err != nil {
+		return "", fmt.Errorf("secrets manager: %s is not a JSON object, cannot read key %s", id, key)
+	}
+	return pickField(fields, key, "secrets manager: "+id)
+}
@@ -0,0 +1,104 @@
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync/atomic"
+	"time"
+)
+
+// Secret is a resolved setting. Safe for concurrent use.
+type Secret struct {
+	name     string
+	ref      string
+	resolver *Resolver
+	path     string // File the value is written to, if any
+	value    atomic.Pointer[string]
+}
+
+// Static returns a secret with a fixed value
+func Static(name, value string) *Secret {
+	s := &Secret{name: name, ref: value}
+	s.value.Store(&value)
+	return s
+}
+
+// Name returns the setting the secret is for
+func (s *Secret) Name() string {
+	return s.name
+}
+
+// Value returns the current value
+func (s *Secret) Value() string {
+	return *s.value.Load()
+}
+
+// Refresh resolves the secret again and reports whether it changed. On
+// error the previous value is kept.
+func (s *Secret) Refresh(ctx context.Context) (bool, error) {
+	if s.resolver == nil || !IsRef(s.ref) {
+		return false, nil
+	}
+	v, err := s.resolver.Resolve(ctx, s.ref)
+	if err != nil {
+		return false, err
+	}
+	if v == s.Value() {
+		return false, nil
+	}
+	if s.path != "" {
+		if err := s.write(v); err != nil {
+			return false, err
+		}
+	}
+	s.value.Store(&v)
+	return true, nil
+}
+
+// write replaces the secret's file, readable only by its owner
+func (s *Secret) write(v string) error {
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(v), 0o600); err != nil {
+		return fmt.Errorf("secret %s: %w", s.name, err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("secret %s: %w", s.name, err)
+	}
+	return nil
+}
+
+// Watch refreshes secrets every interval until ctx is done. Failures are
+// logged and retried at the next interval, keeping the previous values.
+func Watch(ctx context.Context, interval time.Duration, secrets ...*Secret) {
+	var refs []*Secret
+	for _, s := range secrets {
+		if s != nil && s.resolver != nil && IsRef(s.ref) {
+			refs = append(refs, s)
+		}
+	}
+	if len(refs) == 0 || interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		for _, s := range refs {
+			rctx, cancel := context.WithTimeout(ctx, interval)
+			changed, err := s.Refresh(rctx)
+			cancel()
+			if err != nil {
+				slog.Warn("[Secrets] Refresh failed, keeping the current value", "secret", s.name, "error", err)
+			} else if changed {
+				slog.Info("[Secrets] Secret rotated", "secret", s.name)
+			}
+		}
+	}
+}
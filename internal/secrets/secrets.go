@@ -0,0 +1,198 @@
+// Package secrets loads secret settings (tokens, passwords, TLS keys)
+// from a secret store instead of the command line or environment, and
+// keeps them current as they are rotated.
+//
+// A setting whose value is a reference is resolved; any other value is
+// used as-is. References are:
+//
+//   - file:/run/secrets/debug-token - a mounted file (Kubernetes or
+//     Docker secret), surrounding whitespace trimmed
+//   - vault:secret/data/switchboard#debug_token - a field of a HashiCorp
+//     Vault KV secret (VAULT_ADDR, VAULT_TOKEN or VAULT_TOKEN_FILE,
+//     VAULT_NAMESPACE)
+//   - awssm:switchboard/prod#debug_token - an AWS Secrets Manager secret,
+//     or a key of its JSON value (AWS_REGION and the AWS_* credentials)
+//
+// Settings naming a file, such as TLS keys, may be references too: the
+// secret is written to a private file whose path replaces the setting,
+// and rewritten when it changes, so certificate reloading picks it up.
+package secrets
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+// Reference schemes
+const (
+	SchemeFile  = "file"
+	SchemeVault = "vault"
+	SchemeAWS   = "awssm"
+)
+
+// DefaultRefresh is how often secrets are resolved again
+const DefaultRefresh = 5 * time.Minute
+
+// Config holds the secret store connection settings.
+type Config struct {
+	VaultAddr      string // e.g. https://vault.example.com:8200
+	VaultToken     string
+	VaultTokenFile string // Read on every request, for tokens renewed by an agent
+	VaultNamespace string // Vault Enterprise namespace
+
+	// AWS credentials and region for Secrets Manager
+	AWS s3.Config
+
+	// AWSEndpoint overrides the Secrets Manager endpoint
+	AWSEndpoint string
+}
+
+// ConfigFromEnv reads VAULT_ADDR, VAULT_TOKEN, VAULT_TOKEN_FILE,
+// VAULT_NAMESPACE, the standard AWS variables and
+// SECRETSMANAGER_ENDPOINT.
+func ConfigFromEnv() Config {
+	return Config{
+		VaultAddr:      os.Getenv("VAULT_ADDR"),
+		VaultToken:     os.Getenv("VAULT_TOKEN"),
+		VaultTokenFile: os.Getenv("VAULT_TOKEN_FILE"),
+		VaultNamespace: os.Getenv("VAULT_NAMESPACE"),
+		AWS:            s3.ConfigFromEnv(),
+		AWSEndpoint:    os.Getenv("SECRETSMANAGER_ENDPOINT"),
+	}
+}
+
+// Resolver resolves references. Safe for concurrent use.
+type Resolver struct {
+	cfg  Config
+	http *http.Client
+
+	mu     sync.Mutex
+	tmpDir string // Created by Files when given no directory
+}
+
+// New creates a resolver. If httpClient is nil, a client with a 10s
+// timeout is used.
+func New(cfg Config, httpClient *http.Client) *Resolver {
+	if cfg.AWS.Region == "" {
+		cfg.AWS.Region = "us-east-1"
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+	return &Resolver{cfg: cfg, http: httpClient}
+}
+
+// IsRef reports whether a setting value is a reference
+func IsRef(v string) bool {
+	scheme, _, ok := strings.Cut(v, ":")
+	return ok && (scheme == SchemeFile || scheme == SchemeVault || scheme == SchemeAWS)
+}
+
+// Resolve returns the current value of a reference; other values are
+// returned unchanged.
+func (r *Resolver) Resolve(ctx context.Context, ref string) (string, error) {
+	scheme, rest, _ := strings.Cut(ref, ":")
+	switch {
+	case !IsRef(ref):
+		return ref, nil
+	case scheme == SchemeFile:
+		data, err := os.ReadFile(rest)
+		if err != nil {
+			return "", err
+		}
+		return strings.TrimSpace(string(data)), nil
+	case scheme == SchemeVault:
+		path, field, _ := strings.Cut(rest, "#")
+		return r.vault(ctx, path, field)
+	default:
+		id, key, _ := strings.Cut(rest, "#")
+		return r.awsSecret(ctx, id, key)
+	}
+}
+
+// Load resolves a setting and returns it as a secret that Watch keeps
+// current. Values that are not references never change.
+func (r *Resolver) Load(ctx context.Context, name, ref string) (*Secret, error) {
+	s := &Secret{name: name, ref: ref, resolver: r}
+	v, err := r.Resolve(ctx, ref)
+	if err != nil {
+		return nil, fmt.Errorf("secret %s: %w", name, err)
+	}
+	s.value.Store(&v)
+	return s, nil
+}
+
+// Files resolves settings naming files. A setting holding a vault: or
+// awssm: reference is written to a private file in dir (a temporary
+// directory removed by Close if empty) and replaced with its path; a
+// file: reference is replaced with the file's path. The returned secrets
+// rewrite their files when Watch sees a change.
+func (r *Resolver) Files(ctx context.Context, dir string, settings map[string]*string) ([]*Secret, error) {
+	var out []*Secret
+	for name, setting := range settings {
+		ref := *setting
+		if !IsRef(ref) {
+			continue
+		}
+		if path, ok := strings.CutPrefix(ref, SchemeFile+":"); ok {
+			*setting = path
+			continue
+		}
+		if dir == "" {
+			var err error
+			if dir, err = r.tempDir(); err != nil {
+				return nil, err
+			}
+		}
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return nil, fmt.Errorf("secrets: %w", err)
+		}
+		s, err := r.Load(ctx, name, ref)
+		if err != nil {
+			return nil, err
+		}
+		s.path = filepath.Join(dir, name)
+		if err := s.write(s.Value()); err != nil {
+			return nil, err
+		}
+		*setting = s.path
+		out = append(out, s)
+	}
+	return out, nil
+}
+
+// tempDir returns the resolver's private temporary directory, created on
+// first use
+func (r *Resolver) tempDir() (string, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tmpDir == "" {
+		dir, err := os.MkdirTemp("", "switchboard-secrets-")
+		if err != nil {
+			return "", fmt.Errorf("secrets: %w", err)
+		}
+		r.tmpDir = dir
+	}
+	return r.tmpDir, nil
+}
+
+// Close removes the temporary directory secret files were written to
+// when Files was given none.
+func (r *Resolver) Close() error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if r.tmpDir == "" {
+		return nil
+	}
+	err := os.RemoveAll(r.tmpDir)
+	r.tmpDir = ""
+	return err
+}
@@ -0,0 +1,88 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+func TestResolve(t *testing.T) {
+	var token atomic.Value
+	token.Store("v1")
+	vault := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Vault-Token") != "root" || r.URL.Path != "/v1/secret/data/switchboard" {
+			http.Error(w, "permission denied", http.StatusForbidden)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"data": map[string]any{"data": map[string]string{"debug_token": token.Load().(string), "other": "x"}},
+		})
+	}))
+	defer vault.Close()
+
+	aws := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]string
+		_ = json.NewDecoder(r.Body).Decode(&body)
+		if r.Header.Get("X-Amz-Target") != "secretsmanager.GetSecretValue" ||
+			!strings.Contains(r.Header.Get("Authorization"), "/secretsmanager/aws4_request") ||
+			body["SecretId"] != "switchboard/prod" {
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"SecretString": `{"smtp_password":"hunter2"}`})
+	}))
+	defer aws.Close()
+
+	dir := t.TempDir()
+	mounted := filepath.Join(dir, "mounted")
+	if err := os.WriteFile(mounted, []byte("from-file\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	r := New(Config{
+		VaultAddr:   vault.URL,
+		VaultToken:  "root",
+		AWS:         s3.Config{AccessKeyID: "AKID", SecretAccessKey: "secret"},
+		AWSEndpoint: aws.URL,
+	}, nil)
+	ctx := context.Background()
+
+	for ref, want := range map[string]string{
+		"literal":         "literal",
+		"file:" + mounted: "from-file",
+		"vault:secret/data/switchboard#debug_token": "v1",
+		"awssm:switchboard/prod#smtp_password":      "hunter2",
+		"awssm:switchboard/prod":                    `{"smtp_password":"hunter2"}`,
+	} {
+		got, err := r.Resolve(ctx, ref)
+		if err != nil || got != want {
+			t.Errorf("Resolve(%q) = %q, %v; want %q", ref, got, err, want)
+		}
+	}
+	if _, err := r.Resolve(ctx, "vault:secret/data/switchboard"); err == nil {
+		t.Error("field-less reference to a secret with two fields resolved")
+	}
+
+	// A file setting is materialized and rewritten on rotation
+	key := "vault:secret/data/switchboard#debug_token"
+	files, err := r.Files(ctx, filepath.Join(dir, "secrets"), map[string]*string{"tls-key": &key})
+	if err != nil || len(files) != 1 {
+		t.Fatalf("Files = %v, %v", files, err)
+	}
+	token.Store("v2")
+	if changed, err := files[0].Refresh(ctx); !changed || err != nil {
+		t.Fatalf("Refresh = %v, %v", changed, err)
+	}
+	data, err := os.ReadFile(key)
+	if err != nil || string(data) != "v2" {
+		t.Errorf("secret file = %q, %v; want v2", data, err)
+	}
+}
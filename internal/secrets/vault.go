@@ -0,0 +1,95 @@
+package secrets
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+)
+
+// vaultResponse is the body of a Vault read. KV version 2 nests the
+// secret's fields in data.data; version 1 has them in data.
+type vaultResponse struct {
+	Data map[string]json.RawMessage `json:"data"`
+}
+
+// vault reads a field of a KV secret. The field may be omitted when the
+// secret has a single one.
+func (r *Resolver) vault(ctx context.Context, path, field string) (string, error) {
+	if r.cfg.VaultAddr == "" {
+		return "", errors.New("vault: VAULT_ADDR not set")
+	}
+	token := r.cfg.VaultToken
+	if r.cfg.VaultTokenFile != "" {
+		data, err := os.ReadFile(r.cfg.VaultTokenFile)
+		if err != nil {
+			return "", fmt.Errorf("vault: token: %w", err)
+		}
+		token = strings.TrimSpace(string(data))
+	}
+
+	url := strings.TrimSuffix(r.cfg.VaultAddr, "/") + "/v1/" + strings.TrimPrefix(path, "/")
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	req.Header.Set("X-Vault-Token", token)
+	if r.cfg.VaultNamespace != "" {
+		req.Header.Set("X-Vault-Namespace", r.cfg.VaultNamespace)
+	}
+
+	resp, err := r.http.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("vault: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+		return "", fmt.Errorf("vault: read %s: %s: %s", path, resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	var body vaultResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("vault: read %s: %w", path, err)
+	}
+	fields := body.Data
+	if nested, ok := fields["data"]; ok {
+		var kv2 map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &kv2); err == nil {
+			fields = kv2
+		}
+	}
+	return pickField(fields, field, "vault: "+path)
+}
+
+// pickField returns a string field of a secret, or its only field when
+// field is empty
+func pickField(fields map[string]json.RawMessage, field, what string) (string, error) {
+	if field == "" {
+		if len(fields) != 1 {
+			names := make([]string, 0, len(fields))
+			for name := range fields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return "", fmt.Errorf("%s: name a field (#field) of %s", what, strings.Join(names, ", "))
+		}
+		for name := range fields {
+			field = name
+		}
+	}
+	raw, ok := fields[field]
+	if !ok {
+		return "", fmt.Errorf("%s: no field %s", what, field)
+	}
+	var v string
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return "", fmt.Errorf("%s: field %s is not a string", what, field)
+	}
+	return v, nil
+}
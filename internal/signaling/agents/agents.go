@@ -0,0 +1,303 @@
+// Package agents tracks the state of contact center agents: logged out,
+// available, on a call, or in wrap-up after one. Calls are only
+// distributed to available agents, by the outbound dialer and the
+// first_available dialplan action, so agents get their wrap-up time to
+// finish the last call's notes before the next one rings.
+//
+// Agents log in and out, and end or start wrap-up, through the API or
+// feature codes dialed from their phones. Whether they are on a call is
+// derived from the dialogs of the signaling server, so calls placed or
+// answered outside distribution count too.
+package agents
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// State is what an agent is doing
+type State string
+
+const (
+	StateLoggedOut State = "logged_out" // Not taking calls
+	StateAvailable State = "available"  // Waiting for a call
+	StateOnCall    State = "on_call"
+	StateWrapUp    State = "wrap_up" // After a call, until the wrap-up time passes
+)
+
+// DefaultWrapUp is the wrap-up time of agents when the config sets none
+const DefaultWrapUp = 10 * time.Second
+
+// pollInterval is how often agents' calls are checked against the dialogs
+const pollInterval = time.Second
+
+// Sentinel errors
+var (
+	ErrAgentNotFound = errors.New("agents: agent not found")
+	ErrInvalidState  = errors.New("agents: invalid state change")
+)
+
+// Agent is an agent of the config.
+type Agent struct {
+	ID   string `json:"id"` // Extension the agent takes calls at ("1001")
+	Name string `json:"name,omitempty"`
+
+	// WrapUp is the agent's wrap-up time in seconds (default: the
+	// config's; 0 disables it)
+	WrapUp *int `json:"wrap_up,omitempty"`
+}
+
+// Config is the on-disk form of the agents.
+type Config struct {
+	WrapUp *int    `json:"wrap_up,omitempty"` // Seconds of wrap-up after each call (default 10)
+	Agents []Agent `json:"agents"`
+}
+
+// Status is an agent and its current state.
+type Status struct {
+	ID          string     `json:"id"`
+	Name        string     `json:"name,omitempty"`
+	State       State      `json:"state"`
+	Since       time.Time  `json:"since"`                   // When the state was entered
+	WrapUpUntil *time.Time `json:"wrap_up_until,omitempty"` // In wrap-up
+	WrapUp      int        `json:"wrap_up"`                 // Seconds of wrap-up after each call
+	Calls       int        `json:"calls"`                   // Calls since logging in
+}
+
+// Presence tells whether an agent is on a call; dialog.Manager
+// implements it from its dialogs. UsersInCall returns every user InCall
+// reports true for, so a poll scans the dialogs once.
+type Presence interface {
+	InCall(user string) bool
+	UsersInCall() map[string]bool
+}
+
+// agent is an agent's state
+type agent struct {
+	status Status
+	wrapUp time.Duration
+}
+
+// Registry holds the state of the agents. Safe for concurrent use.
+type Registry struct {
+	presence Presence
+
+	mu     sync.Mutex
+	agents map[string]*agent
+}
+
+// Load reads agents from a JSON config file; a missing file has none.
+// Agents start logged out.
+func Load(path string, presence Presence) (*Registry, error) {
+	r := &Registry{presence: presence, agents: make(map[string]*agent)}
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read agents config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse agents config: %w", err)
+	}
+
+	wrapUp := DefaultWrapUp
+	if cfg.WrapUp != nil {
+		if *cfg.WrapUp < 0 {
+			return nil, fmt.Errorf("agents: wrap_up cannot be negative")
+		}
+		wrapUp = time.Duration(*cfg.WrapUp) * time.Second
+	}
+	now := time.Now()
+	for _, a := range cfg.Agents {
+		a.ID = strings.TrimPrefix(a.ID, "user/")
+		if a.ID == "" || strings.ContainsAny(a.ID, "/@:") {
+			return nil, fmt.Errorf("agents: invalid agent id %q", a.ID)
+		}
+		if _, ok := r.agents[a.ID]; ok {
+			return nil, fmt.Errorf("agents: duplicate agent %s", a.ID)
+		}
+		agentWrapUp := wrapUp
+		if a.WrapUp != nil {
+			if *a.WrapUp < 0 {
+				return nil, fmt.Errorf("agents: agent %s: wrap_up cannot be negative", a.ID)
+			}
+			agentWrapUp = time.Duration(*a.WrapUp) * time.Second
+		}
+		r.agents[a.ID] = &agent{
+			status: Status{
+				ID:     a.ID,
+				Name:   a.Name,
+				State:  StateLoggedOut,
+				Since:  now,
+				WrapUp: int(agentWrapUp / time.Second),
+			},
+			wrapUp: agentWrapUp,
+		}
+	}
+	return r, nil
+}
+
+// Run moves agents between available, on a call and wrap-up as their
+// dialogs start and end, until ctx is done.
+func (r *Registry) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			r.poll(now)
+		}
+	}
+}
+
+// poll updates the states of the logged in agents from their dialogs.
+func (r *Registry) poll(now time.Time) {
+	users := r.presence.UsersInCall()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, a := range r.agents {
+		if a.status.State == StateLoggedOut {
+			continue
+		}
+		inCall := users[a.status.ID]
+		switch {
+		case inCall && a.status.State != StateOnCall:
+			r.setLocked(a, StateOnCall, now)
+			a.status.Calls++
+		case !inCall && a.status.State == StateOnCall:
+			r.wrapUpLocked(a, a.wrapUp, now)
+		case a.status.State == StateWrapUp && !now.Before(*a.status.WrapUpUntil):
+			r.setLocked(a, StateAvailable, now)
+		}
+	}
+}
+
+// Available reports whether a call may be distributed to user: true for
+// users who are not agents, and for agents who are available and not on
+// a call.
+func (r *Registry) Available(user string) bool {
+	id := strings.TrimPrefix(user, "user/")
+	r.mu.Lock()
+	a, ok := r.agents[id]
+	available := ok && a.status.State == StateAvailable
+	r.mu.Unlock()
+	if !ok {
+		return true
+	}
+	return available && !r.presence.InCall(id)
+}
+
+// Connected marks an agent who answered a distributed call as on a call,
+// without waiting for the dialogs to be polled.
+func (r *Registry) Connected(user string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.agents[strings.TrimPrefix(user, "user/")]; ok && a.status.State != StateLoggedOut && a.status.State != StateOnCall {
+		r.setLocked(a, StateOnCall, time.Now())
+		a.status.Calls++
+	}
+}
+
+// Ended starts the wrap-up of an agent whose distributed call ended.
+func (r *Registry) Ended(user string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if a, ok := r.agents[strings.TrimPrefix(user, "user/")]; ok && a.status.State == StateOnCall {
+		r.wrapUpLocked(a, a.wrapUp, time.Now())
+	}
+}
+
+// SetState logs an agent in or out, ends their wrap-up (available), or
+// starts it (wrap_up; wrapUp 0 takes the agent's wrap-up time). Agents on
+// a call may only log out; the call continues.
+func (r *Registry) SetState(id string, state State, wrapUp time.Duration) (Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.agents[strings.TrimPrefix(id, "user/")]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrAgentNotFound, id)
+	}
+	now := time.Now()
+	switch state {
+	case StateLoggedOut:
+		r.setLocked(a, StateLoggedOut, now)
+	case StateAvailable, StateWrapUp:
+		if a.status.State == StateOnCall {
+			return Status{}, fmt.Errorf("%w: agent %s is on a call", ErrInvalidState, a.status.ID)
+		}
+		if a.status.State == StateLoggedOut {
+			a.status.Calls = 0
+		}
+		if state == StateAvailable {
+			r.setLocked(a, StateAvailable, now)
+			break
+		}
+		if wrapUp <= 0 {
+			wrapUp = a.wrapUp
+		}
+		r.wrapUpLocked(a, wrapUp, now)
+	default:
+		return Status{}, fmt.Errorf("%w: %q", ErrInvalidState, state)
+	}
+	slog.Info("[Agents] Agent state set", "agent", a.status.ID, "state", a.status.State)
+	return a.status, nil
+}
+
+// Status returns an agent's state.
+func (r *Registry) Status(id string) (Status, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	a, ok := r.agents[strings.TrimPrefix(id, "user/")]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrAgentNotFound, id)
+	}
+	return a.status, nil
+}
+
+// Statuses returns the states of all agents, by ID.
+func (r *Registry) Statuses() []Status {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	statuses := make([]Status, 0, len(r.agents))
+	for _, a := range r.agents {
+		statuses = append(statuses, a.status)
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].ID < statuses[j].ID })
+	return statuses
+}
+
+// setLocked changes an agent's state (must hold lock).
+func (r *Registry) setLocked(a *agent, state State, now time.Time) {
+	if a.status.State != state {
+		slog.Debug("[Agents] Agent state changed", "agent", a.status.ID, "from", a.status.State, "to", state)
+	}
+	a.status.State = state
+	a.status.Since = now
+	a.status.WrapUpUntil = nil
+}
+
+// wrapUpLocked puts an agent in wrap-up, or makes them available without
+// a wrap-up time (must hold lock).
+func (r *Registry) wrapUpLocked(a *agent, wrapUp time.Duration, now time.Time) {
+	if wrapUp <= 0 {
+		r.setLocked(a, StateAvailable, now)
+		return
+	}
+	r.setLocked(a, StateWrapUp, now)
+	until := now.Add(wrapUp)
+	a.status.WrapUpUntil = &until
+}
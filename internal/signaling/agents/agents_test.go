@@ -0,0 +1,88 @@
+package agents
+
+import (
+	"errors"
+	"maps"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// presence reports the users set to true as on a call
+type presence map[string]bool
+
+func (p presence) InCall(user string) bool { return p[user] }
+
+func (p presence) UsersInCall() map[string]bool { return maps.Clone(p) }
+
+func TestPoll(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "agents.json")
+	config := `{"wrap_up": 30, "agents": [{"id": "1001"}, {"id": "1002", "wrap_up": 0}]}`
+	if err := os.WriteFile(path, []byte(config), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	onCall := presence{}
+	r, err := Load(path, onCall)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+
+	if r.Available("1001") {
+		t.Error("logged out agent is available")
+	}
+	if !r.Available("1003") {
+		t.Error("user who is not an agent is not available")
+	}
+	if _, err := r.SetState("1003", StateAvailable, 0); !errors.Is(err, ErrAgentNotFound) {
+		t.Errorf("SetState of unknown agent = %v, want ErrAgentNotFound", err)
+	}
+	for _, id := range []string{"1001", "1002"} {
+		if _, err := r.SetState(id, StateAvailable, 0); err != nil {
+			t.Fatalf("SetState(%s): %v", id, err)
+		}
+	}
+	if !r.Available("user/1001") {
+		t.Error("logged in agent is not available")
+	}
+
+	// A call moves both agents on a call
+	now := time.Now()
+	onCall["1001"], onCall["1002"] = true, true
+	r.poll(now)
+	if s, _ := r.Status("1001"); s.State != StateOnCall || s.Calls != 1 {
+		t.Errorf("1001 = %s with %d calls, want on_call with 1", s.State, s.Calls)
+	}
+	if _, err := r.SetState("1001", StateAvailable, 0); !errors.Is(err, ErrInvalidState) {
+		t.Errorf("SetState available on a call = %v, want ErrInvalidState", err)
+	}
+
+	// Hanging up starts 1001's wrap-up; 1002 has none
+	delete(onCall, "1001")
+	delete(onCall, "1002")
+	r.poll(now.Add(time.Second))
+	if s, _ := r.Status("1001"); s.State != StateWrapUp {
+		t.Errorf("1001 = %s after the call, want wrap_up", s.State)
+	}
+	if r.Available("1001") {
+		t.Error("agent in wrap-up is available")
+	}
+	if s, _ := r.Status("1002"); s.State != StateAvailable {
+		t.Errorf("1002 = %s after the call, want available", s.State)
+	}
+
+	r.poll(now.Add(31 * time.Second))
+	if !r.Available("1001") {
+		t.Error("agent is not available after wrap-up")
+	}
+
+	// Logged out agents stay out through calls
+	if _, err := r.SetState("1002", StateLoggedOut, 0); err != nil {
+		t.Fatalf("SetState logged_out: %v", err)
+	}
+	onCall["1002"] = true
+	r.poll(now.Add(32 * time.Second))
+	if s, _ := r.Status("1002"); s.State != StateLoggedOut {
+		t.Errorf("1002 = %s, want logged_out", s.State)
+	}
+}
@@ -0,0 +1,132 @@
+// Package alerts notifies operators when the signaling server's health
+// crosses a threshold, for deployments without a metrics stack.
+//
+// The Engine periodically checks three rules: an RTP manager unhealthy for
+// longer than a number of minutes, the answer ratio of recent calls below a
+// percentage, and an RTP manager's port pool nearly full. An alert is sent
+// to every configured webhook and email recipient once when it fires and
+// once when it resolves.
+package alerts
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/mail"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Config is the on-disk form of the alert engine. Zero thresholds disable
+// their rule.
+type Config struct {
+	IntervalSeconds int `json:"interval_seconds,omitempty"` // Check interval (default 30)
+
+	// NodeUnhealthyMinutes fires when an RTP manager stays unhealthy this long
+	NodeUnhealthyMinutes int `json:"node_unhealthy_minutes,omitempty"`
+
+	// AnswerRatio fires when too few recent calls were answered
+	AnswerRatio *AnswerRatioRule `json:"answer_ratio,omitempty"`
+
+	// PortUsagePercent fires when an RTP manager has allocated this share of its ports
+	PortUsagePercent float64 `json:"port_usage_percent,omitempty"`
+
+	Webhooks []Webhook `json:"webhooks,omitempty"`
+	Email    *Email    `json:"email,omitempty"`
+}
+
+// AnswerRatioRule compares answered calls to all inbound call attempts
+// that ended within the window. The rule is only evaluated once the window
+// holds MinCalls calls, so a quiet night does not page anyone.
+type AnswerRatioRule struct {
+	MinPercent    float64 `json:"min_percent"`
+	WindowMinutes int     `json:"window_minutes,omitempty"` // Default 15
+	MinCalls      int     `json:"min_calls,omitempty"`      // Default 20
+}
+
+// Webhook receives alerts as a JSON POST.
+type Webhook struct {
+	URL     string            `json:"url"`
+	Headers map[string]string `json:"headers,omitempty"` // e.g. Authorization
+}
+
+// Email sends alerts through an SMTP relay. Authentication is used when
+// Username is set.
+type Email struct {
+	SMTPAddr string   `json:"smtp_addr"` // host:port
+	Username string   `json:"username,omitempty"`
+	Password string   `json:"password,omitempty"`
+	From     string   `json:"from"`
+	To       []string `json:"to"`
+}
+
+// Load reads and validates a JSON alert config file.
+func Load(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read alerts config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse alerts config: %w", err)
+	}
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Validate checks the config and fills in defaults.
+func (c *Config) Validate() error {
+	if c.IntervalSeconds < 0 || c.NodeUnhealthyMinutes < 0 {
+		return fmt.Errorf("alerts: intervals must not be negative")
+	}
+	if c.IntervalSeconds == 0 {
+		c.IntervalSeconds = 30
+	}
+	if c.PortUsagePercent < 0 || c.PortUsagePercent > 100 {
+		return fmt.Errorf("alerts: port_usage_percent must be between 0 and 100")
+	}
+	if r := c.AnswerRatio; r != nil {
+		if r.MinPercent <= 0 || r.MinPercent > 100 {
+			return fmt.Errorf("alerts: answer_ratio.min_percent must be between 0 and 100")
+		}
+		if r.WindowMinutes < 0 || r.MinCalls < 0 {
+			return fmt.Errorf("alerts: answer_ratio window and min_calls must not be negative")
+		}
+		if r.WindowMinutes == 0 {
+			r.WindowMinutes = 15
+		}
+		if r.MinCalls == 0 {
+			r.MinCalls = 20
+		}
+	}
+	for _, w := range c.Webhooks {
+		u, err := url.Parse(w.URL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return fmt.Errorf("alerts: invalid webhook url %q", w.URL)
+		}
+	}
+	if e := c.Email; e != nil {
+		if e.SMTPAddr == "" || len(e.To) == 0 {
+			return fmt.Errorf("alerts: email needs smtp_addr and to")
+		}
+		if _, err := mail.ParseAddress(e.From); err != nil {
+			return fmt.Errorf("alerts: invalid email from %q", e.From)
+		}
+		for _, to := range e.To {
+			if _, err := mail.ParseAddress(to); err != nil {
+				return fmt.Errorf("alerts: invalid email recipient %q", to)
+			}
+		}
+	}
+	if len(c.Webhooks) == 0 && c.Email == nil {
+		return fmt.Errorf("alerts: no webhooks or email configured")
+	}
+	return nil
+}
+
+// Interval returns the check interval.
+func (c *Config) Interval() time.Duration {
+	return time.Duration(c.IntervalSeconds) * time.Second
+}
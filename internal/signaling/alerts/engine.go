@@ -0,0 +1,237 @@
+package alerts
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// Rule names
+const (
+	RuleNodeUnhealthy = "node_unhealthy"
+	RuleAnswerRatio   = "answer_ratio"
+	RulePortUsage     = "port_usage"
+)
+
+// Alert states
+const (
+	StateFiring   = "firing"
+	StateResolved = "resolved"
+)
+
+// Alert is one firing or resolved notification. Webhooks receive it as JSON.
+type Alert struct {
+	Rule      string    `json:"rule"`
+	State     string    `json:"state"`
+	Target    string    `json:"target,omitempty"` // RTP manager node ID for per-node rules
+	Summary   string    `json:"summary"`
+	Value     float64   `json:"value"`
+	Threshold float64   `json:"threshold"`
+	Node      string    `json:"node"` // Signaling node that raised the alert
+	Time      time.Time `json:"time"`
+}
+
+// StatsSource reports RTP manager pool health; *mediaclient.Pool implements it.
+type StatsSource interface {
+	Stats() mediaclient.PoolStats
+}
+
+// callResult is an inbound call that ended, for the answer ratio.
+type callResult struct {
+	at       time.Time
+	answered bool
+}
+
+// Engine evaluates alert rules and sends notifications on state changes.
+type Engine struct {
+	cfg       *Config
+	node      string
+	pool      StatsSource
+	notifiers []Notifier
+
+	mu     sync.Mutex
+	calls  []callResult     // Oldest first, within the answer ratio window
+	firing map[string]Alert // Keyed by rule and target
+}
+
+// NewEngine creates an engine notifying the webhooks and email of cfg.
+// node identifies this signaling server in notifications.
+func NewEngine(cfg *Config, node string, pool StatsSource) *Engine {
+	return &Engine{
+		cfg:       cfg,
+		node:      node,
+		pool:      pool,
+		notifiers: notifiers(cfg),
+		firing:    make(map[string]Alert),
+	}
+}
+
+// Run checks the rules every interval until ctx is done. Ended calls are
+// read from hub when the answer ratio rule is configured.
+func (e *Engine) Run(ctx context.Context, hub *events.Hub) {
+	var callEvents <-chan events.Event
+	if e.cfg.AnswerRatio != nil && hub != nil {
+		sub := hub.Subscribe(1000)
+		defer sub.Close()
+		callEvents = sub.Events()
+	}
+
+	ticker := time.NewTicker(e.cfg.Interval())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-callEvents:
+			if !ok {
+				callEvents = nil
+				continue
+			}
+			if ended, isEnd := ev.(*events.CallEndedEvent); isEnd {
+				e.RecordCall(ended)
+			}
+		case <-ticker.C:
+			e.Check(ctx, time.Now())
+		}
+	}
+}
+
+// RecordCall adds an ended call to the answer ratio window. Only the
+// caller's leg counts, so a bridged call is one attempt.
+func (e *Engine) RecordCall(ev *events.CallEndedEvent) {
+	if ev.Leg == events.LegB {
+		return
+	}
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	e.calls = append(e.calls, callResult{
+		at:       ev.EventTime,
+		answered: ev.DispositionCode == events.DispositionAnswered,
+	})
+}
+
+// Check evaluates every rule at now and notifies on changes.
+func (e *Engine) Check(ctx context.Context, now time.Time) {
+	active := make(map[string]Alert)
+	evaluated := make(map[string]bool) // Rules with enough data to resolve
+
+	stats := e.pool.Stats()
+	for _, m := range stats.Members {
+		if limit := e.cfg.NodeUnhealthyMinutes; limit > 0 {
+			key := RuleNodeUnhealthy + "/" + m.NodeID
+			evaluated[key] = true
+			if !m.UnhealthySince.IsZero() {
+				down := now.Sub(m.UnhealthySince)
+				if down >= time.Duration(limit)*time.Minute {
+					active[key] = Alert{
+						Rule:      RuleNodeUnhealthy,
+						Target:    m.NodeID,
+						Summary:   fmt.Sprintf("RTP manager %s (%s) unhealthy for %s", m.NodeID, m.Address, down.Round(time.Second)),
+						Value:     down.Minutes(),
+						Threshold: float64(limit),
+					}
+				}
+			}
+		}
+		if limit := e.cfg.PortUsagePercent; limit > 0 && m.TotalPorts > 0 {
+			key := RulePortUsage + "/" + m.NodeID
+			evaluated[key] = true
+			used := 100 * float64(m.UsedPorts) / float64(m.TotalPorts)
+			if used >= limit {
+				active[key] = Alert{
+					Rule:      RulePortUsage,
+					Target:    m.NodeID,
+					Summary:   fmt.Sprintf("RTP manager %s port pool %.0f%% used (%d of %d)", m.NodeID, used, m.UsedPorts, m.TotalPorts),
+					Value:     used,
+					Threshold: limit,
+				}
+			}
+		}
+	}
+
+	if rule := e.cfg.AnswerRatio; rule != nil {
+		total, answered := e.windowCalls(now.Add(-time.Duration(rule.WindowMinutes) * time.Minute))
+		if total >= rule.MinCalls {
+			evaluated[RuleAnswerRatio] = true
+			ratio := 100 * float64(answered) / float64(total)
+			if ratio < rule.MinPercent {
+				active[RuleAnswerRatio] = Alert{
+					Rule:      RuleAnswerRatio,
+					Summary:   fmt.Sprintf("Answer ratio %.1f%% (%d of %d calls) in the last %d minutes", ratio, answered, total, rule.WindowMinutes),
+					Value:     ratio,
+					Threshold: rule.MinPercent,
+				}
+			}
+		}
+	}
+
+	for _, alert := range e.transitions(active, evaluated, now) {
+		e.notify(ctx, alert)
+	}
+}
+
+// windowCalls drops calls older than since and counts the rest.
+func (e *Engine) windowCalls(since time.Time) (total, answered int) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	drop := 0
+	for drop < len(e.calls) && e.calls[drop].at.Before(since) {
+		drop++
+	}
+	e.calls = e.calls[drop:]
+	for _, c := range e.calls {
+		if c.answered {
+			answered++
+		}
+	}
+	return len(e.calls), answered
+}
+
+// transitions updates the firing set and returns the alerts to send:
+// newly active ones as firing, and evaluated ones no longer active as
+// resolved. Alerts whose rule could not be evaluated keep their state.
+func (e *Engine) transitions(active map[string]Alert, evaluated map[string]bool, now time.Time) []Alert {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	var out []Alert
+	for key, alert := range active {
+		if _, ok := e.firing[key]; ok {
+			continue
+		}
+		alert.State = StateFiring
+		alert.Node = e.node
+		alert.Time = now
+		e.firing[key] = alert
+		out = append(out, alert)
+	}
+	for key, alert := range e.firing {
+		if _, ok := active[key]; ok || !evaluated[key] {
+			continue
+		}
+		delete(e.firing, key)
+		alert.State = StateResolved
+		alert.Summary = "Resolved: " + alert.Summary
+		alert.Time = now
+		out = append(out, alert)
+	}
+	return out
+}
+
+func (e *Engine) notify(ctx context.Context, alert Alert) {
+	slog.Warn("[Alerts] "+alert.Summary, "rule", alert.Rule, "state", alert.State, "target", alert.Target)
+	for _, n := range e.notifiers {
+		sendCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+		if err := n.Notify(sendCtx, alert); err != nil {
+			slog.Error("[Alerts] Notification failed", "notifier", n.Name(), "rule", alert.Rule, "error", err)
+		}
+		cancel()
+	}
+}
@@ -0,0 +1,161 @@
+package alerts
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+type fakePool struct {
+	stats mediaclient.PoolStats
+}
+
+func (p *fakePool) Stats() mediaclient.PoolStats { return p.stats }
+
+// webhookRecorder collects alerts posted to a test server.
+type webhookRecorder struct {
+	mu     sync.Mutex
+	alerts []Alert
+}
+
+func (r *webhookRecorder) take() []Alert {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	out := r.alerts
+	r.alerts = nil
+	return out
+}
+
+func newWebhook(t *testing.T) (*webhookRecorder, string) {
+	rec := &webhookRecorder{}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var a Alert
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			t.Errorf("decode alert: %v", err)
+		}
+		rec.mu.Lock()
+		rec.alerts = append(rec.alerts, a)
+		rec.mu.Unlock()
+	}))
+	t.Cleanup(srv.Close)
+	return rec, srv.URL
+}
+
+func TestEngineNodeAndPortRules(t *testing.T) {
+	rec, url := newWebhook(t)
+	cfg := &Config{
+		NodeUnhealthyMinutes: 5,
+		PortUsagePercent:     90,
+		Webhooks:             []Webhook{{URL: url}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	now := time.Now()
+	pool := &fakePool{stats: mediaclient.PoolStats{Members: []mediaclient.MemberStats{
+		{NodeID: "rtp-0", UnhealthySince: now.Add(-2 * time.Minute)},
+		{NodeID: "rtp-1", Healthy: true, TotalPorts: 100, UsedPorts: 95},
+	}}}
+	e := NewEngine(cfg, "sig-0", pool)
+	ctx := context.Background()
+
+	e.Check(ctx, now)
+	got := rec.take()
+	if len(got) != 1 || got[0].Rule != RulePortUsage || got[0].State != StateFiring || got[0].Target != "rtp-1" {
+		t.Fatalf("first check: %+v", got)
+	}
+
+	// Node down long enough; port alert already firing is not repeated
+	e.Check(ctx, now.Add(4*time.Minute))
+	got = rec.take()
+	if len(got) != 1 || got[0].Rule != RuleNodeUnhealthy || got[0].Node != "sig-0" {
+		t.Fatalf("second check: %+v", got)
+	}
+
+	pool.stats.Members[0] = mediaclient.MemberStats{NodeID: "rtp-0", Healthy: true}
+	pool.stats.Members[1].UsedPorts = 10
+	e.Check(ctx, now.Add(5*time.Minute))
+	got = rec.take()
+	if len(got) != 2 || got[0].State != StateResolved || got[1].State != StateResolved {
+		t.Fatalf("resolve: %+v", got)
+	}
+}
+
+func TestEngineAnswerRatio(t *testing.T) {
+	rec, url := newWebhook(t)
+	cfg := &Config{
+		AnswerRatio: &AnswerRatioRule{MinPercent: 50, MinCalls: 4},
+		Webhooks:    []Webhook{{URL: url}},
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Fatal(err)
+	}
+	e := NewEngine(cfg, "sig-0", &fakePool{})
+	ctx := context.Background()
+	now := time.Now()
+
+	record := func(disposition string, leg events.LegRole) {
+		ev := &events.CallEndedEvent{DispositionCode: disposition}
+		ev.EventTime = now
+		ev.Leg = leg
+		e.RecordCall(ev)
+	}
+	record(events.DispositionAnswered, events.LegA)
+	record(events.DispositionBusy, events.LegA)
+	record(events.DispositionFailed, events.LegA)
+	record(events.DispositionAnswered, events.LegB) // Not counted
+
+	// Three calls are below min_calls
+	e.Check(ctx, now)
+	if got := rec.take(); len(got) != 0 {
+		t.Fatalf("fired without enough calls: %+v", got)
+	}
+
+	record(events.DispositionNoAnswer, events.LegA)
+	e.Check(ctx, now)
+	got := rec.take()
+	if len(got) != 1 || got[0].Rule != RuleAnswerRatio || got[0].Value != 25 {
+		t.Fatalf("answer ratio: %+v", got)
+	}
+
+	// Calls age out of the window; the alert stays until enough new calls arrive
+	later := now.Add(20 * time.Minute)
+	e.Check(ctx, later)
+	if got := rec.take(); len(got) != 0 {
+		t.Fatalf("resolved without data: %+v", got)
+	}
+	now = later
+	for i := 0; i < 4; i++ {
+		record(events.DispositionAnswered, events.LegA)
+	}
+	e.Check(ctx, later)
+	got = rec.take()
+	if len(got) != 1 || got[0].State != StateResolved {
+		t.Fatalf("resolve: %+v", got)
+	}
+}
+
+func TestConfigValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  Config
+	}{
+		{"no notifiers", Config{PortUsagePercent: 90}},
+		{"bad url", Config{Webhooks: []Webhook{{URL: "ftp://x"}}}},
+		{"bad percent", Config{PortUsagePercent: 120, Webhooks: []Webhook{{URL: "http://x"}}}},
+		{"bad email", Config{Email: &Email{SMTPAddr: "mail:25", From: "ops", To: []string{"a@example.com"}}}},
+	}
+	for _, tt := range tests {
+		if err := tt.cfg.Validate(); err == nil {
+			t.Errorf("%s: expected error", tt.name)
+		}
+	}
+}
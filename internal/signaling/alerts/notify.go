@@ -0,0 +1,100 @@
+package alerts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/smtp"
+	"strings"
+	"time"
+)
+
+// Notifier delivers alerts.
+type Notifier interface {
+	Notify(ctx context.Context, alert Alert) error
+	Name() string
+}
+
+// notifiers returns a notifier per webhook plus one for email.
+func notifiers(cfg *Config) []Notifier {
+	var ns []Notifier
+	client := &http.Client{Timeout: 10 * time.Second}
+	for _, w := range cfg.Webhooks {
+		ns = append(ns, &webhookNotifier{hook: w, client: client})
+	}
+	if cfg.Email != nil {
+		ns = append(ns, &emailNotifier{cfg: *cfg.Email})
+	}
+	return ns
+}
+
+type webhookNotifier struct {
+	hook   Webhook
+	client *http.Client
+}
+
+func (n *webhookNotifier) Name() string {
+	return "webhook " + n.hook.URL
+}
+
+func (n *webhookNotifier) Notify(ctx context.Context, alert Alert) error {
+	body, err := json.Marshal(alert)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, n.hook.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range n.hook.Headers {
+		req.Header.Set(k, v)
+	}
+	resp, err := n.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned %s", resp.Status)
+	}
+	return nil
+}
+
+type emailNotifier struct {
+	cfg Email
+}
+
+func (n *emailNotifier) Name() string {
+	return "email " + strings.Join(n.cfg.To, ",")
+}
+
+// Notify sends a plain-text mail. net/smtp has no context support; the
+// relay is expected to answer promptly.
+func (n *emailNotifier) Notify(_ context.Context, alert Alert) error {
+	var auth smtp.Auth
+	if n.cfg.Username != "" {
+		host, _, err := net.SplitHostPort(n.cfg.SMTPAddr)
+		if err != nil {
+			return err
+		}
+		auth = smtp.PlainAuth("", n.cfg.Username, n.cfg.Password, host)
+	}
+
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", n.cfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(n.cfg.To, ", "))
+	fmt.Fprintf(&msg, "Subject: [switchboard] %s: %s\r\n", strings.ToUpper(alert.State), alert.Summary)
+	fmt.Fprintf(&msg, "Date: %s\r\n", alert.Time.Format(time.RFC1123Z))
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n\r\n")
+	fmt.Fprintf(&msg, "%s\r\n\r\nRule: %s\r\n", alert.Summary, alert.Rule)
+	if alert.Target != "" {
+		fmt.Fprintf(&msg, "Target: %s\r\n", alert.Target)
+	}
+	fmt.Fprintf(&msg, "Value: %.1f\r\nThreshold: %.1f\r\nNode: %s\r\n", alert.Value, alert.Threshold, alert.Node)
+
+	return smtp.SendMail(n.cfg.SMTPAddr, auth, n.cfg.From, n.cfg.To, msg.Bytes())
+}
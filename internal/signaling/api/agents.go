@@ -0,0 +1,95 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/agents"
+)
+
+// SetAgentsProvider enables the agent endpoints.
+func (s *Server) SetAgentsProvider(ap AgentsProvider) {
+	s.agents = ap
+}
+
+// handleAgents lists agents and their states
+// GET /api/v1/agents - List agents
+func (s *Server) handleAgents(w http.ResponseWriter, r *http.Request) {
+	if s.agents == nil {
+		http.Error(w, "Agents not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, map[string]interface{}{
+		"agents": s.agents.Statuses(),
+	})
+}
+
+// handleAgentByID gets or changes the state of an agent
+// GET /api/v1/agents/{id} - Get an agent's state
+// PUT /api/v1/agents/{id}/state - Log in or out, or start or end wrap-up
+func (s *Server) handleAgentByID(w http.ResponseWriter, r *http.Request) {
+	if s.agents == nil {
+		http.Error(w, "Agents not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/agents/")
+	id, suffix, _ := strings.Cut(path, "/")
+	if id == "" {
+		http.Error(w, "Agent ID required", http.StatusBadRequest)
+		return
+	}
+
+	switch {
+	case suffix == "" && r.Method == http.MethodGet:
+		status, err := s.agents.Status(id)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, status)
+	case suffix == "state" && (r.Method == http.MethodPut || r.Method == http.MethodPost):
+		s.setAgentState(w, r, id)
+	case suffix == "" || suffix == "state":
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+// setAgentState changes an agent's state from a request body of the form
+// {"state": "wrap_up", "wrap_up": 60}.
+func (s *Server) setAgentState(w http.ResponseWriter, r *http.Request, id string) {
+	var body struct {
+		State  agents.State `json:"state"`
+		WrapUp int          `json:"wrap_up,omitempty"` // Seconds, for wrap_up (default: the agent's)
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.WrapUp < 0 {
+		http.Error(w, "wrap_up cannot be negative", http.StatusBadRequest)
+		return
+	}
+
+	status, err := s.agents.SetState(id, body.State, time.Duration(body.WrapUp)*time.Second)
+	if err != nil {
+		code := http.StatusConflict
+		if errors.Is(err, agents.ErrAgentNotFound) {
+			code = http.StatusNotFound
+		}
+		http.Error(w, err.Error(), code)
+		return
+	}
+	slog.Info("[API] Agent state set", "agent", id, "state", status.State)
+	s.writeJSON(w, status)
+}
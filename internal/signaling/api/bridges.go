@@ -0,0 +1,243 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+)
+
+// bridgeStatsTimeout bounds the collection of packet counters from the
+// RTP managers
+const bridgeStatsTimeout = 2 * time.Second
+
+// BridgeRecord is an active B2BUA bridge with the packet counters the RTP
+// manager forwarding its media reports.
+type BridgeRecord struct {
+	ID            string `json:"id"`
+	State         string `json:"state"`
+	LegAID        string `json:"leg_a_id"`
+	LegBID        string `json:"leg_b_id"`
+	LegACallID    string `json:"leg_a_call_id,omitempty"`
+	LegBCallID    string `json:"leg_b_call_id,omitempty"`
+	Codec         string `json:"codec,omitempty"`
+	MediaBridgeID string `json:"media_bridge_id,omitempty"` // Empty while hold music plays
+	HeldBy        string `json:"held_by,omitempty"`         // "leg_a", "leg_b" or "local" while on hold
+	HeldLeg       string `json:"held_leg,omitempty"`        // The leg on hold, hearing the music
+	HeldAt        string `json:"held_at,omitempty"`
+	HoldMusic     bool   `json:"hold_music,omitempty"` // Music is played to the other leg
+	SessionAID    string `json:"session_a_id,omitempty"`
+	SessionBID    string `json:"session_b_id,omitempty"`
+	RTPManager    string `json:"rtp_manager,omitempty"` // Node forwarding the media
+	CreatedAt     string `json:"created_at"`
+	StartedAt     string `json:"started_at,omitempty"`
+	Duration      int    `json:"duration"` // Seconds since started, or created if not started yet
+	PacketsAToB   int64  `json:"packets_a_to_b"`
+	PacketsBToA   int64  `json:"packets_b_to_a"`
+	BytesAToB     int64  `json:"bytes_a_to_b"`
+	BytesBToA     int64  `json:"bytes_b_to_a"`
+
+	// Jitter buffers of the RTP manager, when it buffers
+	JitterAToB *JitterRecord `json:"jitter_a_to_b,omitempty"`
+	JitterBToA *JitterRecord `json:"jitter_b_to_a,omitempty"`
+}
+
+// JitterRecord holds the stats of the jitter buffer of one direction of a
+// bridge
+type JitterRecord struct {
+	SessionID     string `json:"session_id"` // Session whose received packets are buffered
+	Depth         int    `json:"depth"`
+	MaxDepth      int    `json:"max_depth"`
+	TargetDelayMs int64  `json:"target_delay_ms"`
+	JitterMs      int64  `json:"jitter_ms"`
+	Late          int64  `json:"late"`
+	Overflow      int64  `json:"overflow"`
+	Duplicates    int64  `json:"duplicates"`
+}
+
+// handleBridges lists the active bridges, oldest first
+// GET /api/v1/bridges
+func (s *Server) handleBridges(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	records := make([]*BridgeRecord, 0)
+	if s.calls == nil {
+		s.writeJSON(w, records)
+		return
+	}
+
+	bridges := s.calls.Bridges().List()
+	media := s.mediaBridges(r.Context(), len(bridges))
+	for _, b := range bridges {
+		records = append(records, bridgeRecord(b.Info(), media))
+	}
+	s.writeJSON(w, records)
+}
+
+// handleBridgeByID returns one active bridge, or puts one of its legs on
+// hold
+// GET /api/v1/bridges/{id}
+// POST /api/v1/bridges/{id}/hold - Put a leg on hold
+// DELETE /api/v1/bridges/{id}/hold - Take it off hold
+func (s *Server) handleBridgeByID(w http.ResponseWriter, r *http.Request) {
+	path, hold := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/bridges/"), "/hold")
+	id, err := url.PathUnescape(path)
+	if err != nil || id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Bridge ID required", http.StatusBadRequest)
+		return
+	}
+	if hold {
+		s.handleBridgeHold(w, r, id)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.calls == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	b, ok := s.calls.Bridges().Get(id)
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, bridgeRecord(b.Info(), s.mediaBridges(r.Context(), 1)))
+}
+
+// HoldRequest puts a leg of a bridge on hold
+type HoldRequest struct {
+	Leg   string `json:"leg"`             // "leg_a" or "leg_b"
+	Class string `json:"class,omitempty"` // Music-on-hold class; empty for the default
+}
+
+// handleBridgeHold puts a leg of a bridge on hold with music, or takes
+// it off hold, and returns the bridge
+func (s *Server) handleBridgeHold(w http.ResponseWriter, r *http.Request, id string) {
+	if s.calls == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPost:
+		var req HoldRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.calls.HoldLeg(r.Context(), id, req.Leg, req.Class); err != nil {
+			http.Error(w, err.Error(), holdErrorStatus(err))
+			return
+		}
+	case http.MethodDelete:
+		if err := s.calls.ResumeLeg(r.Context(), id); err != nil {
+			http.Error(w, err.Error(), holdErrorStatus(err))
+			return
+		}
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	b, ok := s.calls.Bridges().Get(id)
+	if !ok {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	s.writeJSON(w, bridgeRecord(b.Info(), s.mediaBridges(r.Context(), 1)))
+}
+
+func holdErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, b2bua.ErrBridgeNotFound), errors.Is(err, moh.ErrClassNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, b2bua.ErrInvalidState):
+		return http.StatusConflict
+	case errors.Is(err, b2bua.ErrUnknownLeg):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// mediaBridges returns the bridges on the RTP managers by media bridge
+// ID. The managers are not asked when there is no bridge to report.
+func (s *Server) mediaBridges(ctx context.Context, bridges int) map[string]mediaclient.BridgeInfo {
+	if bridges == 0 || s.rtpManagers == nil {
+		return nil
+	}
+	ctx, cancel := context.WithTimeout(ctx, bridgeStatsTimeout)
+	defer cancel()
+	return s.rtpManagers.ListBridges(ctx)
+}
+
+// bridgeRecord converts a B2BUA bridge for the API, with the counters of
+// its media bridge if the RTP manager reported it.
+func bridgeRecord(info *b2bua.BridgeInfo, media map[string]mediaclient.BridgeInfo) *BridgeRecord {
+	rec := &BridgeRecord{
+		ID:            info.ID,
+		State:         info.State.String(),
+		LegAID:        info.LegAID,
+		LegBID:        info.LegBID,
+		LegACallID:    info.LegACallID,
+		LegBCallID:    info.LegBCallID,
+		Codec:         info.Codec,
+		MediaBridgeID: info.MediaBridgeID,
+		HeldBy:        info.HeldBy,
+		HeldLeg:       info.HeldLeg,
+		HoldMusic:     info.HoldMusic,
+		CreatedAt:     info.CreatedAt.Format(time.RFC3339),
+	}
+	if !info.HeldAt.IsZero() {
+		rec.HeldAt = info.HeldAt.Format(time.RFC3339)
+	}
+
+	since := info.CreatedAt
+	if !info.StartedAt.IsZero() {
+		rec.StartedAt = info.StartedAt.Format(time.RFC3339)
+		since = info.StartedAt
+	}
+	rec.Duration = int(time.Since(since).Seconds())
+
+	if mb, ok := media[info.MediaBridgeID]; ok && info.MediaBridgeID != "" {
+		rec.SessionAID = mb.SessionAID
+		rec.SessionBID = mb.SessionBID
+		rec.RTPManager = mb.NodeID
+		rec.PacketsAToB = mb.PacketsAToB
+		rec.PacketsBToA = mb.PacketsBToA
+		rec.BytesAToB = mb.BytesAToB
+		rec.BytesBToA = mb.BytesBToA
+		rec.JitterAToB = jitterRecord(mb.JitterAToB)
+		rec.JitterBToA = jitterRecord(mb.JitterBToA)
+	}
+	return rec
+}
+
+// jitterRecord converts the jitter buffer stats of a bridge direction
+func jitterRecord(js *mediaclient.JitterStats) *JitterRecord {
+	if js == nil {
+		return nil
+	}
+	return &JitterRecord{
+		SessionID:     js.SessionID,
+		Depth:         js.Depth,
+		MaxDepth:      js.MaxDepth,
+		TargetDelayMs: js.TargetDelay.Milliseconds(),
+		JitterMs:      js.Jitter.Milliseconds(),
+		Late:          js.Late,
+		Overflow:      js.Overflow,
+		Duplicates:    js.Duplicates,
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/callback"
+)
+
+// SetCallbackProvider enables the callback endpoints.
+func (s *Server) SetCallbackProvider(cp CallbackProvider) {
+	s.callbacks = cp
+}
+
+// handleCallbacks lists or requests callbacks
+// GET /api/v1/callbacks[?extension=1001] - List callbacks, oldest first
+// POST /api/v1/callbacks - Request a callback
+func (s *Server) handleCallbacks(w http.ResponseWriter, r *http.Request) {
+	if s.callbacks == nil {
+		http.Error(w, "Callbacks not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, map[string]interface{}{
+			"callbacks": s.callbacks.List(r.URL.Query().Get("extension")),
+		})
+	case http.MethodPost:
+		var req callback.Request
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		cb, err := s.callbacks.Register(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		w.WriteHeader(http.StatusCreated)
+		s.writeJSON(w, cb)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCallbackByID gets or cancels a callback
+// GET /api/v1/callbacks/{id} - Get a callback
+// DELETE /api/v1/callbacks/{id} - Cancel a callback not yet connected
+func (s *Server) handleCallbackByID(w http.ResponseWriter, r *http.Request) {
+	if s.callbacks == nil {
+		http.Error(w, "Callbacks not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	id := strings.TrimPrefix(r.URL.Path, "/api/v1/callbacks/")
+	if id == "" || strings.Contains(id, "/") {
+		http.Error(w, "Callback ID required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		cb, ok := s.callbacks.Get(id)
+		if !ok {
+			http.Error(w, "Callback not found", http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, cb)
+	case http.MethodDelete:
+		cb, err := s.callbacks.Cancel(id)
+		if err != nil {
+			status := http.StatusConflict
+			if errors.Is(err, callback.ErrNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		slog.Info("[API] Callback canceled", "id", id)
+		s.writeJSON(w, cb)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
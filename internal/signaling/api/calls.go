@@ -0,0 +1,312 @@
+package api
+
+import (
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/originate"
+)
+
+// Call states in call records
+const (
+	CallStateRinging     = "ringing"
+	CallStateAnswered    = "answered"
+	CallStateBridged     = "bridged"
+	CallStateTerminating = "terminating"
+
+	// Test calls only, while their progress is kept
+	CallStateFailed = "failed"
+	CallStateEnded  = "ended"
+)
+
+// CallRecord is one logical call: the inbound leg, the legs dialed for
+// it and their media sessions, joined from the dialogs, the B2BUA and
+// the RTP manager pool.
+type CallRecord struct {
+	CallID    string    `json:"call_id"`           // A-leg Call-ID; the B-leg's for calls placed from here
+	State     string    `json:"state"`             // ringing, answered, bridged or terminating
+	HeldBy    string    `json:"held_by,omitempty"` // "leg_a" or "leg_b" while a bridged call is on hold
+	From      string    `json:"from,omitempty"`
+	To        string    `json:"to,omitempty"`
+	StartedAt string    `json:"started_at"`
+	Duration  int       `json:"duration"` // Seconds since started
+	ALeg      *CallLeg  `json:"a_leg,omitempty"`
+	BLegs     []CallLeg `json:"b_legs,omitempty"` // Several while a forked dial rings
+
+	// Progress of a test call placed with POST /api/v1/calls
+	Progress *originate.Status `json:"progress,omitempty"`
+
+	started time.Time
+}
+
+// CallLeg is one SIP leg of a call record
+type CallLeg struct {
+	CallID     string `json:"call_id"`
+	Direction  string `json:"direction"`
+	State      string `json:"state"`
+	RemoteURI  string `json:"remote_uri,omitempty"`
+	SessionID  string `json:"session_id,omitempty"`
+	RTPManager string `json:"rtp_manager,omitempty"` // Node holding the media session
+	RemoteRTP  string `json:"remote_rtp,omitempty"`  // Where the media is sent
+	Codec      string `json:"codec,omitempty"`
+	SIPCode    int    `json:"sip_code,omitempty"`
+	CreatedAt  string `json:"created_at"`
+	AnsweredAt string `json:"answered_at,omitempty"`
+}
+
+// SetCallsProvider adds the B2BUA's outbound legs to the call records.
+func (s *Server) SetCallsProvider(cp CallsProvider) {
+	s.calls = cp
+}
+
+// handleCalls lists active calls or places a test call
+// GET /api/v1/calls - List active calls
+// POST /api/v1/calls - Place a test call
+func (s *Server) handleCalls(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.callRecords())
+	case http.MethodPost:
+		s.handleOriginate(w, r)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCallByID returns the call one of whose legs has the Call-ID, or
+// the progress of a test call that has not started ringing or has ended
+// GET /api/v1/calls/{call_id}
+// POST /api/v1/calls/{call_id}/gather - Collect digits from the leg
+func (s *Server) handleCallByID(w http.ResponseWriter, r *http.Request) {
+	path, gather := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/calls/"), "/gather")
+	callID, err := url.PathUnescape(path)
+	if err != nil || callID == "" {
+		http.Error(w, "Call-ID required", http.StatusBadRequest)
+		return
+	}
+	if gather {
+		s.handleCallGather(w, r, callID)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	for _, call := range s.callRecords() {
+		if call.hasLeg(callID) {
+			s.writeJSON(w, call)
+			return
+		}
+	}
+	if s.originator != nil {
+		if status, ok := s.originator.Status(callID); ok {
+			s.writeJSON(w, progressCall(status))
+			return
+		}
+	}
+	http.Error(w, "Not found", http.StatusNotFound)
+}
+
+// progressCall is the record of a test call without a B2BUA leg: one
+// being placed, or one that failed or ended
+func progressCall(status originate.Status) *CallRecord {
+	state := CallStateRinging
+	switch status.State {
+	case originate.ProgressFailed:
+		state = CallStateFailed
+	case originate.ProgressEnded:
+		state = CallStateEnded
+	}
+	ended := time.Now()
+	if state != CallStateRinging {
+		ended = status.UpdatedAt
+	}
+	return &CallRecord{
+		CallID:    status.CallID,
+		State:     state,
+		To:        status.Target,
+		StartedAt: status.StartedAt.Format(time.RFC3339),
+		Duration:  int(ended.Sub(status.StartedAt).Seconds()),
+		Progress:  &status,
+		started:   status.StartedAt,
+	}
+}
+
+// callRecords joins the dialogs and outbound legs into one record per
+// call, oldest first. Outbound legs are listed under the inbound leg they
+// were dialed for; their own dialogs are not listed separately.
+func (s *Server) callRecords() []*CallRecord {
+	var legs []*b2bua.LegInfo
+	if s.calls != nil {
+		legs = s.calls.OutboundLegs()
+	}
+	outbound := make(map[string]bool, len(legs))
+	byALeg := make(map[string][]*b2bua.LegInfo)
+	for _, leg := range legs {
+		outbound[leg.CallID] = true
+		if leg.ALegCallID != "" {
+			byALeg[leg.ALegCallID] = append(byALeg[leg.ALegCallID], leg)
+		}
+	}
+
+	heldBy := make(map[string]string)
+	if s.calls != nil {
+		for _, b := range s.calls.Bridges().List() {
+			if held := b.HeldBy(); held != "" {
+				heldBy[b.LegA().CallID()] = held
+			}
+		}
+	}
+
+	records := make([]*CallRecord, 0)
+	seen := make(map[string]bool)
+	if s.dialogMgr != nil {
+		for _, d := range s.dialogMgr.List() {
+			if outbound[d.CallID] || d.GetState() == dialog.StateTerminated {
+				continue
+			}
+			call := s.dialogCall(d, byALeg[d.CallID])
+			call.HeldBy = heldBy[d.CallID]
+			seen[d.CallID] = true
+			records = append(records, call)
+		}
+	}
+
+	// Calls placed from here, and legs whose inbound leg is already gone
+	for _, leg := range legs {
+		if leg.ALegCallID != "" && seen[leg.ALegCallID] {
+			continue
+		}
+		b := s.outboundLeg(leg)
+		state := CallStateRinging
+		if leg.State == b2bua.LegStateAnswered {
+			state = CallStateAnswered
+		}
+		call := &CallRecord{
+			CallID:    leg.CallID,
+			State:     state,
+			From:      leg.FromURI,
+			To:        leg.ToURI,
+			StartedAt: b.CreatedAt,
+			Duration:  int(time.Since(leg.CreatedAt).Seconds()),
+			BLegs:     []CallLeg{b},
+			started:   leg.CreatedAt,
+		}
+		if s.originator != nil {
+			if status, ok := s.originator.Status(leg.CallID); ok {
+				call.Progress = &status
+			}
+		}
+		records = append(records, call)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].started.Before(records[j].started)
+	})
+	return records
+}
+
+// dialogCall builds the record of a call from its inbound dialog and the
+// legs dialed for it.
+func (s *Server) dialogCall(d *dialog.Dialog, legs []*b2bua.LegInfo) *CallRecord {
+	info := d.ToInfo()
+	call := &CallRecord{
+		CallID:    info.CallID,
+		From:      info.RemoteURI,
+		To:        info.LocalURI,
+		StartedAt: info.CreatedAt,
+		Duration:  info.Duration,
+		ALeg: &CallLeg{
+			CallID:     info.CallID,
+			Direction:  info.Direction,
+			State:      info.State,
+			RemoteURI:  info.RemoteContact,
+			SessionID:  info.SessionID,
+			RTPManager: s.sessionNode(info.SessionID),
+			RemoteRTP:  hostPort(info.RemoteAddr, info.RemotePort),
+			Codec:      info.Codec,
+			CreatedAt:  info.CreatedAt,
+		},
+		started: d.CreatedAt,
+	}
+
+	sort.Slice(legs, func(i, j int) bool {
+		return legs[i].CreatedAt.Before(legs[j].CreatedAt)
+	})
+	bridged := false
+	for _, leg := range legs {
+		call.BLegs = append(call.BLegs, s.outboundLeg(leg))
+		bridged = bridged || leg.State == b2bua.LegStateAnswered
+		if call.To == "" {
+			call.To = leg.ToURI
+		}
+	}
+
+	switch state := d.GetState(); {
+	case state == dialog.StateTerminating:
+		call.State = CallStateTerminating
+	case state != dialog.StateConfirmed:
+		call.State = CallStateRinging
+	case bridged:
+		call.State = CallStateBridged
+	default:
+		call.State = CallStateAnswered
+	}
+	return call
+}
+
+// outboundLeg converts a B2BUA leg for a call record
+func (s *Server) outboundLeg(leg *b2bua.LegInfo) CallLeg {
+	b := CallLeg{
+		CallID:     leg.CallID,
+		Direction:  strings.ToLower(leg.Direction.String()),
+		State:      leg.State.String(),
+		RemoteURI:  leg.RemoteURI,
+		SessionID:  leg.SessionID,
+		RTPManager: s.sessionNode(leg.SessionID),
+		RemoteRTP:  hostPort(leg.RemoteRTPAddr, leg.RemoteRTPPort),
+		Codec:      leg.NegotiatedCodec,
+		SIPCode:    leg.SIPCode,
+		CreatedAt:  leg.CreatedAt.Format(time.RFC3339),
+	}
+	if !leg.AnsweredAt.IsZero() {
+		b.AnsweredAt = leg.AnsweredAt.Format(time.RFC3339)
+	}
+	return b
+}
+
+// sessionNode returns the RTP manager holding a media session, if known
+func (s *Server) sessionNode(sessionID string) string {
+	if sessionID == "" || s.rtpManagers == nil {
+		return ""
+	}
+	return s.rtpManagers.SessionNode(sessionID)
+}
+
+// hasLeg reports whether one of the call's legs has the Call-ID
+func (c *CallRecord) hasLeg(callID string) bool {
+	if c.CallID == callID || (c.ALeg != nil && c.ALeg.CallID == callID) {
+		return true
+	}
+	for _, leg := range c.BLegs {
+		if leg.CallID == callID {
+			return true
+		}
+	}
+	return false
+}
+
+func hostPort(host string, port int) string {
+	if host == "" {
+		return ""
+	}
+	return net.JoinHostPort(host, strconv.Itoa(port))
+}
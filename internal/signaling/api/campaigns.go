@@ -0,0 +1,170 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/dialer"
+)
+
+// SetDialerProvider enables the outbound campaign endpoints.
+func (s *Server) SetDialerProvider(dp DialerProvider) {
+	s.dialer = dp
+}
+
+// handleCampaigns lists or creates outbound campaigns
+// GET /api/v1/campaigns - List campaigns with their progress
+// POST /api/v1/campaigns - Create or replace a campaign
+func (s *Server) handleCampaigns(w http.ResponseWriter, r *http.Request) {
+	if s.dialer == nil {
+		http.Error(w, "Dialer not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, map[string]interface{}{
+			"campaigns": s.dialer.Statuses(),
+		})
+	case http.MethodPost:
+		var c dialer.Campaign
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.putCampaign(w, c)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleCampaignByName manages a single outbound campaign
+// GET /api/v1/campaigns/{name} - Get campaign and progress
+// PUT /api/v1/campaigns/{name} - Create or replace campaign
+// DELETE /api/v1/campaigns/{name} - Delete campaign
+// GET /api/v1/campaigns/{name}/results - How the calls went
+// POST /api/v1/campaigns/{name}/start - Start or resume
+// POST /api/v1/campaigns/{name}/pause - Pause
+// POST /api/v1/campaigns/{name}/stop - Stop
+func (s *Server) handleCampaignByName(w http.ResponseWriter, r *http.Request) {
+	if s.dialer == nil {
+		http.Error(w, "Dialer not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path, op, _ := strings.Cut(strings.TrimPrefix(r.URL.Path, "/api/v1/campaigns/"), "/")
+	name, err := url.PathUnescape(path)
+	if err != nil || name == "" {
+		http.Error(w, "Campaign name required", http.StatusBadRequest)
+		return
+	}
+
+	switch op {
+	case "":
+	case "results":
+		if r.Method != http.MethodGet {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		results, err := s.dialer.Results(name)
+		if err != nil {
+			http.Error(w, err.Error(), campaignErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"campaign": name,
+			"results":  results,
+		})
+		return
+	case "start", "pause", "stop":
+		s.controlCampaign(w, r, name, op)
+		return
+	default:
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		c, err := s.dialer.Campaign(name)
+		if err != nil {
+			http.Error(w, err.Error(), campaignErrorStatus(err))
+			return
+		}
+		status, err := s.dialer.Status(name)
+		if err != nil {
+			http.Error(w, err.Error(), campaignErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"campaign": c,
+			"status":   status,
+		})
+	case http.MethodPut:
+		var c dialer.Campaign
+		if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		c.Name = name
+		s.putCampaign(w, c)
+	case http.MethodDelete:
+		if err := s.dialer.DeleteCampaign(name); err != nil {
+			http.Error(w, err.Error(), campaignErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message":  "Campaign deleted",
+			"campaign": name,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// controlCampaign starts, pauses or stops a campaign and returns its progress
+func (s *Server) controlCampaign(w http.ResponseWriter, r *http.Request, name, op string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	control := s.dialer.Start
+	switch op {
+	case "pause":
+		control = s.dialer.Pause
+	case "stop":
+		control = s.dialer.Stop
+	}
+	status, err := control(name)
+	if err != nil {
+		http.Error(w, err.Error(), campaignErrorStatus(err))
+		return
+	}
+	slog.Info("[API] Campaign "+op, "campaign", name, "state", status.State)
+	s.writeJSON(w, status)
+}
+
+func (s *Server) putCampaign(w http.ResponseWriter, c dialer.Campaign) {
+	if err := s.dialer.PutCampaign(c); err != nil {
+		slog.Error("[API] Failed to save campaign", "campaign", c.Name, "error", err)
+		http.Error(w, err.Error(), campaignErrorStatus(err))
+		return
+	}
+	s.writeJSON(w, c)
+}
+
+func campaignErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, dialer.ErrCampaignNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, dialer.ErrCampaignActive), errors.Is(err, dialer.ErrInvalidState):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,38 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sebas/switchboard/internal/version"
+)
+
+// ConfigResponse is the effective configuration of this server
+type ConfigResponse struct {
+	Version  string            `json:"version"`
+	Settings map[string]string `json:"settings"` // Secrets redacted
+	Files    map[string]string `json:"files"`    // SHA-256 of each configuration file by path setting
+}
+
+// SetConfigProvider enables the configuration endpoint.
+func (s *Server) SetConfigProvider(cp ConfigProvider) {
+	s.config = cp
+}
+
+// handleConfig returns the effective configuration, read-only
+// GET /api/v1/config
+func (s *Server) handleConfig(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.config == nil {
+		http.Error(w, "Configuration not available", http.StatusServiceUnavailable)
+		return
+	}
+
+	s.writeJSON(w, ConfigResponse{
+		Version:  version.Get(),
+		Settings: s.config.Settings(),
+		Files:    s.config.FileDigests(),
+	})
+}
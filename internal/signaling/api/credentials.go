@@ -0,0 +1,214 @@
+package api
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/credentials"
+)
+
+// maxImportBytes bounds a bulk credential import
+const maxImportBytes = 16 << 20
+
+// CredentialImport is the body of a bulk credential import
+type CredentialImport struct {
+	Users []credentials.Secret `json:"users"`
+}
+
+// SetCredentialsProvider enables the user credential endpoints.
+func (s *Server) SetCredentialsProvider(cp CredentialsProvider) {
+	s.credentials = cp
+}
+
+// handleUserPath routes the per-user endpoints and the credential
+// collection endpoints under /api/v1/users/
+func (s *Server) handleUserPath(w http.ResponseWriter, r *http.Request) {
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/users/")
+	switch {
+	case path == "credentials":
+		s.handleCredentials(w, r)
+	case path == "import":
+		s.handleCredentialImport(w, r)
+	case strings.HasSuffix(path, "/credentials"), strings.HasSuffix(path, "/credentials/verify"):
+		s.handleUserCredentials(w, r)
+	default:
+		s.handleUserFeatures(w, r)
+	}
+}
+
+// handleCredentials lists users with credentials, without their secrets
+// GET /api/v1/users/credentials
+func (s *Server) handleCredentials(w http.ResponseWriter, r *http.Request) {
+	if s.credentials == nil {
+		http.Error(w, "Credentials not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, s.credentials.List())
+}
+
+// handleCredentialImport sets many credentials at once, from JSON
+// ({"users": [...]}) or CSV with a user,password[,realm] header
+// POST /api/v1/users/import?replace=true - Also remove users not imported
+func (s *Server) handleCredentialImport(w http.ResponseWriter, r *http.Request) {
+	if s.credentials == nil {
+		http.Error(w, "Credentials not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	body := io.LimitReader(r.Body, maxImportBytes)
+	var secrets []credentials.Secret
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "text/csv" {
+		var err error
+		if secrets, err = parseCredentialCSV(body); err != nil {
+			http.Error(w, "Invalid CSV: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+	} else {
+		var imp CredentialImport
+		if err := json.NewDecoder(body).Decode(&imp); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		secrets = imp.Users
+	}
+
+	replace := r.URL.Query().Get("replace") == "true"
+	n, err := s.credentials.Import(secrets, replace)
+	if err != nil {
+		slog.Error("[API] Failed to import credentials", "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	slog.Info("[API] Credentials imported", "users", n, "replace", replace)
+	s.writeJSON(w, map[string]interface{}{
+		"message":  "Credentials imported",
+		"imported": n,
+		"replaced": replace,
+	})
+}
+
+// parseCredentialCSV reads user,password[,realm] rows after a header
+func parseCredentialCSV(r io.Reader) ([]credentials.Secret, error) {
+	records, err := csv.NewReader(r).ReadAll()
+	if err != nil {
+		return nil, err
+	}
+	if len(records) == 0 {
+		return nil, errors.New("empty")
+	}
+	cols := make(map[string]int)
+	for i, name := range records[0] {
+		cols[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	userCol, okUser := cols["user"]
+	passCol, okPass := cols["password"]
+	if !okUser || !okPass {
+		return nil, errors.New("header must name user and password columns")
+	}
+	realmCol, hasRealm := cols["realm"]
+
+	secrets := make([]credentials.Secret, 0, len(records)-1)
+	for _, rec := range records[1:] {
+		sec := credentials.Secret{User: rec[userCol], Password: rec[passCol]}
+		if hasRealm {
+			sec.Realm = rec[realmCol]
+		}
+		secrets = append(secrets, sec)
+	}
+	return secrets, nil
+}
+
+// handleUserCredentials manages a user's SIP credential
+// GET    /api/v1/users/{user}/credentials - Realm and algorithms, never secrets
+// PUT    /api/v1/users/{user}/credentials - Set from {"password"} or hashes
+// DELETE /api/v1/users/{user}/credentials - Remove; the user can no longer authenticate
+// POST   /api/v1/users/{user}/credentials/verify - Check {"password"}
+func (s *Server) handleUserCredentials(w http.ResponseWriter, r *http.Request) {
+	if s.credentials == nil {
+		http.Error(w, "Credentials not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/users/")
+	path, verify := strings.CutSuffix(path, "/verify")
+	path = strings.TrimSuffix(path, "/credentials")
+	user, err := url.PathUnescape(path)
+	if err != nil || user == "" || strings.Contains(user, "/") {
+		http.Error(w, "User required", http.StatusBadRequest)
+		return
+	}
+
+	if verify {
+		if r.Method != http.MethodPost {
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		var body struct {
+			Password string `json:"password"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"user":  user,
+			"valid": s.credentials.Verify(user, body.Password),
+		})
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		info, err := s.credentials.Get(user)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, info)
+	case http.MethodPut:
+		var secret credentials.Secret
+		if err := json.NewDecoder(r.Body).Decode(&secret); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		secret.User = user
+		info, err := s.credentials.Put(secret)
+		if err != nil {
+			slog.Error("[API] Failed to save credentials", "user", user, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.writeJSON(w, info)
+	case http.MethodDelete:
+		if err := s.credentials.Delete(user); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, credentials.ErrUserNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "Credentials removed",
+			"user":    user,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
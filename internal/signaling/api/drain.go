@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+)
+
+// drainEventResponse is a drain event in the API's event stream
+type drainEventResponse struct {
+	Type       string                 `json:"type"`
+	NodeID     string                 `json:"node_id"`
+	SessionID  string                 `json:"session_id,omitempty"`
+	TargetNode string                 `json:"target_node,omitempty"`
+	Error      string                 `json:"error,omitempty"`
+	Timestamp  string                 `json:"timestamp"`
+	Status     map[string]interface{} `json:"status"`
+}
+
+// handleDrainEvents streams a node's drain events as Server-Sent Events,
+// starting with its current status
+// GET /api/v1/rtpmanagers/{nodeId}/drain/events
+func (s *Server) handleDrainEvents(w http.ResponseWriter, r *http.Request, nodeID string) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+	if _, err := s.drainProvider.GetDrainStatus(nodeID); err != nil {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	sub := s.drainProvider.Subscribe(nodeID, 0)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(drainEventResponse{
+				Type:       event.Type,
+				NodeID:     event.NodeID,
+				SessionID:  event.SessionID,
+				TargetNode: event.TargetNode,
+				Error:      event.Error,
+				Timestamp:  event.Timestamp.Format(time.RFC3339),
+				Status:     drainStatusResponse(&event.Status),
+			})
+			if err != nil {
+				slog.Error("[API] Failed to encode drain event", "type", event.Type, "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type, data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
+// handleRetryDrainSession migrates again a session that failed to migrate
+// POST /api/v1/rtpmanagers/{nodeId}/drain/sessions/{sessionId}/retry
+func (s *Server) handleRetryDrainSession(w http.ResponseWriter, r *http.Request, nodeID, sessionID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if sessionID == "" {
+		http.Error(w, "Session ID required", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.drainProvider.RetrySession(nodeID, sessionID); err != nil {
+		slog.Warn("[API] Failed to retry session migration", "node_id", nodeID, "session_id", sessionID, "error", err)
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	w.WriteHeader(http.StatusAccepted)
+	s.writeJSON(w, map[string]interface{}{
+		"message":    "Migration retried",
+		"node_id":    nodeID,
+		"session_id": sessionID,
+	})
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// GatherRequest collects DTMF digits from one leg of a call
+type GatherRequest struct {
+	Prompt            string `json:"prompt,omitempty"`              // Audio file (default: dial tone)
+	MaxDigits         int    `json:"max_digits,omitempty"`          // Digits to collect (default: until # or timeout)
+	Timeout           int    `json:"timeout,omitempty"`             // Seconds to wait for the first digit after the prompt (default 5)
+	InterDigitTimeout int    `json:"inter_digit_timeout,omitempty"` // Seconds to wait for each further digit (default: timeout)
+	Pattern           string `json:"pattern,omitempty"`             // Regular expression the digits must match in full
+	Attempts          int    `json:"attempts,omitempty"`            // Prompts until the digits match (default 1)
+	InvalidPrompt     string `json:"invalid_prompt,omitempty"`      // Played after digits that do not match
+}
+
+// handleCallGather plays a prompt to a leg and returns the digits its
+// party presses; the request blocks until they are collected
+// POST /api/v1/calls/{call_id}/gather
+func (s *Server) handleCallGather(w http.ResponseWriter, r *http.Request, callID string) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.calls == nil {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+
+	var req GatherRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.MaxDigits < 0 || req.Timeout < 0 || req.InterDigitTimeout < 0 || req.Attempts < 0 {
+		http.Error(w, "Limits must not be negative", http.StatusBadRequest)
+		return
+	}
+	opts := b2bua.GatherOptions{
+		Prompt:            req.Prompt,
+		MaxDigits:         req.MaxDigits,
+		Timeout:           time.Duration(req.Timeout) * time.Second,
+		InterDigitTimeout: time.Duration(req.InterDigitTimeout) * time.Second,
+		Attempts:          req.Attempts,
+		InvalidPrompt:     req.InvalidPrompt,
+	}
+	if req.Pattern != "" {
+		pattern, err := b2bua.DigitPattern(req.Pattern)
+		if err != nil {
+			http.Error(w, "Invalid pattern: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		opts.Pattern = pattern
+	}
+
+	// The collection stops if the client goes away
+	result, err := s.calls.GatherLeg(r.Context(), callID, opts)
+	if err != nil {
+		slog.Info("[API] Gather failed", "call_id", callID, "error", err)
+		http.Error(w, err.Error(), gatherErrorStatus(err))
+		return
+	}
+	s.writeJSON(w, result)
+}
+
+func gatherErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, b2bua.ErrLegNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, b2bua.ErrLegNotAnswered), errors.Is(err, b2bua.ErrInvalidState):
+		return http.StatusConflict
+	default:
+		return http.StatusBadGateway
+	}
+}
@@ -0,0 +1,109 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/ivr"
+)
+
+// SetIVRProvider enables the IVR menu management endpoints.
+func (s *Server) SetIVRProvider(ip IVRProvider) {
+	s.ivr = ip
+}
+
+// handleIVRMenus lists or creates IVR menus
+// GET /api/v1/ivr/menus - List menus
+// POST /api/v1/ivr/menus - Create or replace a menu
+func (s *Server) handleIVRMenus(w http.ResponseWriter, r *http.Request) {
+	if s.ivr == nil {
+		http.Error(w, "IVR not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, map[string]interface{}{
+			"menus": s.ivr.Menus(),
+		})
+	case http.MethodPost:
+		var menu ivr.Menu
+		if err := json.NewDecoder(r.Body).Decode(&menu); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.putIVRMenu(w, menu)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleIVRMenuByName manages a single IVR menu
+// GET /api/v1/ivr/menus/{name} - Get menu
+// PUT /api/v1/ivr/menus/{name} - Create or replace menu
+// DELETE /api/v1/ivr/menus/{name} - Delete menu
+func (s *Server) handleIVRMenuByName(w http.ResponseWriter, r *http.Request) {
+	if s.ivr == nil {
+		http.Error(w, "IVR not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/v1/ivr/menus/"))
+	if err != nil || name == "" {
+		http.Error(w, "Menu name required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		menu, err := s.ivr.Menu(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, menu)
+	case http.MethodPut:
+		var menu ivr.Menu
+		if err := json.NewDecoder(r.Body).Decode(&menu); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		menu.Name = name
+		s.putIVRMenu(w, menu)
+	case http.MethodDelete:
+		if err := s.ivr.DeleteMenu(name); err != nil {
+			http.Error(w, err.Error(), ivrErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "Menu deleted",
+			"menu":    name,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) putIVRMenu(w http.ResponseWriter, menu ivr.Menu) {
+	if err := s.ivr.PutMenu(menu); err != nil {
+		slog.Error("[API] Failed to save IVR menu", "menu", menu.Name, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.writeJSON(w, menu)
+}
+
+func ivrErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, ivr.ErrMenuNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, ivr.ErrMenuInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/kpi"
+)
+
+// Defaults of the KPI report query
+const (
+	defaultKPISince  = 24 * time.Hour
+	defaultKPIBucket = time.Hour
+)
+
+// SetKPIProvider enables the per-route call KPIs.
+func (s *Server) SetKPIProvider(kp KPIProvider) {
+	s.kpis = kp
+}
+
+// handleKPIs reports ASR, NER, ACD and PDD per route in time buckets
+// GET /api/v1/kpis?since=24h&bucket=1h&route={route}
+func (s *Server) handleKPIs(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.kpis == nil {
+		http.Error(w, "KPIs not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	q := kpi.Query{Since: defaultKPISince, Bucket: defaultKPIBucket, Route: r.URL.Query().Get("route")}
+	if v := r.URL.Query().Get("since"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "Invalid since duration", http.StatusBadRequest)
+			return
+		}
+		q.Since = d
+	}
+	if v := r.URL.Query().Get("bucket"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d < kpi.Resolution {
+			http.Error(w, "Invalid bucket duration, at least 1m", http.StatusBadRequest)
+			return
+		}
+		q.Bucket = d
+	}
+
+	s.writeJSON(w, s.kpis.Report(time.Now(), q))
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// PickupRequest answers a ringing call from another phone
+type PickupRequest struct {
+	Extension string `json:"extension,omitempty"` // Directed pickup: a call ringing this extension
+	Group     string `json:"group,omitempty"`     // Group pickup: a call ringing this pickup group
+	Target    string `json:"target"`              // Who answers it ("user/1002" or a SIP URI)
+	Timeout   int    `json:"timeout,omitempty"`   // Seconds to ring the target (default: the dial timeout)
+}
+
+// handlePickup dials a target and, once it answers, hands it the call
+// ringing an extension or pickup group the longest
+// POST /api/v1/pickup
+func (s *Server) handlePickup(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.calls == nil {
+		http.Error(w, "Call pickup not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var req PickupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if req.Target == "" {
+		http.Error(w, "Target required", http.StatusBadRequest)
+		return
+	}
+	if (req.Extension == "") == (req.Group == "") {
+		http.Error(w, "Either extension or group required", http.StatusBadRequest)
+		return
+	}
+
+	callees := []string{req.Extension}
+	if req.Group != "" {
+		if s.features == nil {
+			http.Error(w, "User features not configured", http.StatusServiceUnavailable)
+			return
+		}
+		if callees = s.features.Group(req.Group); len(callees) == 0 {
+			http.Error(w, "Pickup group not found", http.StatusNotFound)
+			return
+		}
+	}
+
+	// The dial is canceled if the client goes away before the answer;
+	// the call picked up outlives the request
+	picked, err := s.calls.DialPickup(r.Context(), req.Target, time.Duration(req.Timeout)*time.Second, callees)
+	if err != nil {
+		slog.Info("[API] Pickup failed", "target", req.Target, "extension", req.Extension, "group", req.Group, "error", err)
+		http.Error(w, err.Error(), pickupErrorStatus(err))
+		return
+	}
+
+	slog.Info("[API] Call picked up", "call_id", picked.CallID, "callee", picked.Callee, "target", req.Target)
+	w.WriteHeader(http.StatusCreated)
+	s.writeJSON(w, map[string]string{
+		"call_id": picked.CallID,
+		"callee":  picked.Callee,
+		"target":  req.Target,
+	})
+}
+
+func pickupErrorStatus(err error) int {
+	if errors.Is(err, b2bua.ErrNoRingingCall) {
+		return http.StatusNotFound
+	}
+	return originateErrorStatus(err)
+}
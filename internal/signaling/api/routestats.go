@@ -0,0 +1,52 @@
+package api
+
+import (
+	"net/http"
+
+	"github.com/sebas/switchboard/internal/signaling/routestats"
+)
+
+// RouteStatsResponse lists the routing statistics
+type RouteStatsResponse struct {
+	Window string             `json:"window"` // Period of the recent counts
+	Routes []routestats.Stats `json:"routes"`
+}
+
+// SetRouteStatsProvider enables the per-rule and per-trunk routing
+// statistics.
+func (s *Server) SetRouteStatsProvider(rs RouteStatsProvider) {
+	s.routeStats = rs
+}
+
+// handleRouteStats lists or resets the routing statistics
+// GET    /api/v1/routing/stats?kind={rule|trunk}
+// DELETE /api/v1/routing/stats?kind={rule|trunk}&name={name}
+func (s *Server) handleRouteStats(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.routeStats == nil {
+		http.Error(w, "Routing statistics not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	kind := r.URL.Query().Get("kind")
+	switch kind {
+	case "", routestats.KindRule, routestats.KindTrunk:
+	default:
+		http.Error(w, "Invalid kind, must be rule or trunk", http.StatusBadRequest)
+		return
+	}
+
+	if r.Method == http.MethodDelete {
+		s.routeStats.Reset(kind, r.URL.Query().Get("name"))
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	s.writeJSON(w, RouteStatsResponse{
+		Window: s.routeStats.Window().String(),
+		Routes: s.routeStats.List(kind),
+	})
+}
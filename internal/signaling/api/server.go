@@ -2,7 +2,11 @@ package api
 
 import (
 	"context"
+	"crypto/tls"
 	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
@@ -10,10 +14,29 @@ import (
 	"sync"
 	"time"
 
+	"github.com/sebas/switchboard/internal/debug"
+	"github.com/sebas/switchboard/internal/health"
+	"github.com/sebas/switchboard/internal/logger"
+	"github.com/sebas/switchboard/internal/signaling/agents"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/callback"
+	"github.com/sebas/switchboard/internal/signaling/credentials"
+	"github.com/sebas/switchboard/internal/signaling/dialer"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
 	"github.com/sebas/switchboard/internal/signaling/drain"
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/features"
+	"github.com/sebas/switchboard/internal/signaling/ivr"
+	"github.com/sebas/switchboard/internal/signaling/kpi"
 	"github.com/sebas/switchboard/internal/signaling/location"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+	"github.com/sebas/switchboard/internal/signaling/originate"
+	"github.com/sebas/switchboard/internal/signaling/recording"
+	"github.com/sebas/switchboard/internal/signaling/routestats"
+	"github.com/sebas/switchboard/internal/signaling/screening"
+	"github.com/sebas/switchboard/internal/signaling/stasis"
+	"github.com/sebas/switchboard/internal/version"
 )
 
 // RegistrationProvider provides registration data for the API.
@@ -21,12 +44,26 @@ import (
 type RegistrationProvider interface {
 	GetAllRegistrations() map[string][]*location.Binding
 	GetAllBindings(aor string) []*location.Binding
+	RemoveBinding(aor, bindingID string) error
 }
 
 // RtpManagerProvider provides RTP manager pool stats for the API.
 // Implemented by mediaclient.Pool via StatsProvider interface.
 type RtpManagerProvider interface {
 	Stats() mediaclient.PoolStats
+	SessionNode(sessionID string) string
+	ListBridges(ctx context.Context) map[string]mediaclient.BridgeInfo
+}
+
+// CallsProvider provides the outbound legs and bridges of active calls
+// for the API. Implemented by b2bua.CallService.
+type CallsProvider interface {
+	OutboundLegs() []*b2bua.LegInfo
+	Bridges() b2bua.BridgeStore
+	HoldLeg(ctx context.Context, bridgeID, leg, class string) error
+	ResumeLeg(ctx context.Context, bridgeID string) error
+	DialPickup(ctx context.Context, target string, timeout time.Duration, callees []string) (*b2bua.PickedCall, error)
+	GatherLeg(ctx context.Context, callID string, opts b2bua.GatherOptions) (*b2bua.GatherResult, error)
 }
 
 // DrainProvider provides drain operations for the API.
@@ -35,16 +72,163 @@ type DrainProvider interface {
 	StartDrain(ctx context.Context, req drain.DrainRequest) (*drain.DrainStatus, error)
 	GetDrainStatus(nodeID string) (*drain.DrainStatus, error)
 	CancelDrain(nodeID string) error
+	RetrySession(nodeID, sessionID string) error
+	Subscribe(nodeID string, bufferSize int) *drain.Subscription
+}
+
+// MOHProvider manages music-on-hold classes for the API.
+// Implemented by moh.Registry.
+type MOHProvider interface {
+	Default() string
+	Classes() []moh.Class
+	Class(name string) (*moh.Class, error)
+	PutClass(class moh.Class) error
+	DeleteClass(name string) error
+	Assignments() []moh.Assignment
+	Assign(a moh.Assignment) error
+	Unassign(scope, key string) error
+}
+
+// IVRProvider manages IVR menus for the API.
+// Implemented by ivr.Store.
+type IVRProvider interface {
+	Menus() []ivr.Menu
+	Menu(name string) (*ivr.Menu, error)
+	PutMenu(menu ivr.Menu) error
+	DeleteMenu(name string) error
+}
+
+// DialerProvider manages outbound campaigns for the API.
+// Implemented by dialer.Dialer.
+type DialerProvider interface {
+	Statuses() []dialer.Status
+	Status(name string) (dialer.Status, error)
+	Campaign(name string) (*dialer.Campaign, error)
+	Results(name string) ([]dialer.Result, error)
+	PutCampaign(c dialer.Campaign) error
+	DeleteCampaign(name string) error
+	Start(name string) (dialer.Status, error)
+	Pause(name string) (dialer.Status, error)
+	Stop(name string) (dialer.Status, error)
+}
+
+// CallbackProvider manages callback requests for the API.
+// Implemented by callback.Manager.
+type CallbackProvider interface {
+	Register(req callback.Request) (callback.Callback, error)
+	Cancel(id string) (callback.Callback, error)
+	Get(id string) (callback.Callback, bool)
+	List(extension string) []callback.Callback
+}
+
+// AgentsProvider manages agent states for the API.
+// Implemented by agents.Registry.
+type AgentsProvider interface {
+	Statuses() []agents.Status
+	Status(id string) (agents.Status, error)
+	SetState(id string, state agents.State, wrapUp time.Duration) (agents.Status, error)
+}
+
+// ScreeningProvider manages inbound caller blocklists for the API.
+// Implemented by screening.Screener.
+type ScreeningProvider interface {
+	Lists() []screening.List
+	List(name string) (*screening.List, error)
+	PutList(list screening.List) error
+	DeleteList(name string) error
+	AddEntry(name string, entry screening.Entry) error
+	RemoveEntry(name, match, pattern string) error
+}
+
+// FeaturesProvider manages per-user call features for the provisioning API.
+// Implemented by features.Store.
+type FeaturesProvider interface {
+	Get(user string) features.Settings
+	List() []features.Settings
+	Put(settings features.Settings) error
+	Delete(user string) error
+	Group(group string) []string
+}
+
+// CredentialsProvider manages the SIP credentials of users for the
+// provisioning API. Implemented by credentials.Store.
+type CredentialsProvider interface {
+	Get(user string) (credentials.Info, error)
+	List() []credentials.Info
+	Put(secret credentials.Secret) (credentials.Info, error)
+	Import(secrets []credentials.Secret, replace bool) (int, error)
+	Delete(user string) error
+	Verify(user, password string) bool
+}
+
+// AppsProvider connects external call control applications.
+// Implemented by stasis.Registry.
+type AppsProvider interface {
+	Apps() []stasis.AppInfo
+	ServeWebSocket(w http.ResponseWriter, r *http.Request, app string)
+}
+
+// OriginateProvider places test calls for the API and reports their
+// progress. Implemented by originate.Originator.
+type OriginateProvider interface {
+	Originate(ctx context.Context, req originate.Request) (*originate.Call, error)
+	Start(req originate.Request) (originate.Status, error)
+	Status(callID string) (originate.Status, bool)
+}
+
+// EventsProvider streams call events for the API.
+// Implemented by events.Hub.
+type EventsProvider interface {
+	Subscribe(bufferSize int) *events.Subscription
+}
+
+// KPIProvider reports per-route call KPIs for the API.
+// Implemented by kpi.Collector.
+type KPIProvider interface {
+	Report(now time.Time, q kpi.Query) kpi.Report
+}
+
+// RouteStatsProvider reports per-rule and per-trunk call outcomes for the
+// API. Implemented by routestats.Tracker.
+type RouteStatsProvider interface {
+	List(kind string) []routestats.Stats
+	Reset(kind, name string) int
+	Window() time.Duration
+}
+
+// ConfigProvider reports the effective configuration for the API.
+// Implemented by config.Config.
+type ConfigProvider interface {
+	Settings() map[string]string
+	FileDigests() map[string]string
 }
 
 // Server provides HTTP API for the SIP proxy (headless, API only)
 type Server struct {
 	addr          string
 	httpServer    *http.Server
+	mux           *http.ServeMux
 	registrations RegistrationProvider
 	dialogMgr     dialog.DialogStore
 	rtpManagers   RtpManagerProvider
+	calls         CallsProvider
 	drainProvider DrainProvider
+	mohProvider   MOHProvider
+	ivr           IVRProvider
+	dialer        DialerProvider
+	callbacks     CallbackProvider
+	agents        AgentsProvider
+	screening     ScreeningProvider
+	features      FeaturesProvider
+	credentials   CredentialsProvider
+	recordings    recording.Store
+	apps          AppsProvider
+	originator    OriginateProvider
+	events        EventsProvider
+	kpis          KPIProvider
+	routeStats    RouteStatsProvider
+	config        ConfigProvider
+	health        *health.Checker
 	sessionsMu    sync.RWMutex
 	sessions      map[string]*SessionRecord
 	startTime     time.Time
@@ -67,12 +251,16 @@ func NewServer(addr string, registrations RegistrationProvider, dialogMgr dialog
 		registrations: registrations,
 		dialogMgr:     dialogMgr,
 		rtpManagers:   rtpManagers,
+		health:        health.NewChecker(),
 		sessions:      make(map[string]*SessionRecord),
 		startTime:     time.Now(),
 	}
 
 	mux := http.NewServeMux()
 
+	// Liveness and readiness probes (/healthz, /readyz)
+	s.health.Register(mux)
+
 	// Health and stats
 	mux.HandleFunc("/api/v1/health", s.handleHealth)
 	mux.HandleFunc("/api/v1/stats", s.handleStats)
@@ -85,6 +273,25 @@ func NewServer(addr string, registrations RegistrationProvider, dialogMgr dialog
 	mux.HandleFunc("/api/v1/dialogs", s.handleDialogs)
 	mux.HandleFunc("/api/v1/dialogs/", s.handleDialogByID)
 
+	// Calls (active call view, test calls, pickup)
+	mux.HandleFunc("/api/v1/calls", s.handleCalls)
+	mux.HandleFunc("/api/v1/calls/", s.handleCallByID)
+	mux.HandleFunc("/api/v1/pickup", s.handlePickup)
+	mux.HandleFunc("/api/v1/callbacks", s.handleCallbacks)
+	mux.HandleFunc("/api/v1/callbacks/", s.handleCallbackByID)
+	mux.HandleFunc("/api/v1/agents", s.handleAgents)
+	mux.HandleFunc("/api/v1/agents/", s.handleAgentByID)
+
+	// Bridges
+	mux.HandleFunc("/api/v1/bridges", s.handleBridges)
+	mux.HandleFunc("/api/v1/bridges/", s.handleBridgeByID)
+
+	// Per-route call KPIs
+	mux.HandleFunc("/api/v1/kpis", s.handleKPIs)
+
+	// Per-rule and per-trunk routing statistics
+	mux.HandleFunc("/api/v1/routing/stats", s.handleRouteStats)
+
 	// Sessions (RTP)
 	mux.HandleFunc("/api/v1/sessions", s.handleSessions)
 
@@ -92,9 +299,44 @@ func NewServer(addr string, registrations RegistrationProvider, dialogMgr dialog
 	mux.HandleFunc("/api/v1/rtpmanagers", s.handleRtpManagers)
 	mux.HandleFunc("/api/v1/rtpmanagers/", s.handleRtpManagerDrain)
 
+	// Music on hold
+	mux.HandleFunc("/api/v1/moh/classes", s.handleMOHClasses)
+	mux.HandleFunc("/api/v1/moh/classes/", s.handleMOHClassByName)
+	mux.HandleFunc("/api/v1/moh/assignments", s.handleMOHAssignments)
+	mux.HandleFunc("/api/v1/moh/assignments/", s.handleMOHAssignmentByKey)
+	mux.HandleFunc("/api/v1/ivr/menus", s.handleIVRMenus)
+	mux.HandleFunc("/api/v1/ivr/menus/", s.handleIVRMenuByName)
+
+	// Outbound campaigns
+	mux.HandleFunc("/api/v1/campaigns", s.handleCampaigns)
+	mux.HandleFunc("/api/v1/campaigns/", s.handleCampaignByName)
+
+	// Caller screening
+	mux.HandleFunc("/api/v1/screening/lists", s.handleScreeningLists)
+	mux.HandleFunc("/api/v1/screening/lists/", s.handleScreeningListByName)
+
+	// User call features (provisioning)
+	mux.HandleFunc("/api/v1/users", s.handleUsers)
+	mux.HandleFunc("/api/v1/users/", s.handleUserPath)
+
+	// Recordings
+	mux.HandleFunc("/api/v1/recordings", s.handleRecordings)
+	mux.HandleFunc("/api/v1/recordings/", s.handleRecordingByName)
+
+	// External applications
+	mux.HandleFunc("/api/v1/apps", s.handleApps)
+	mux.HandleFunc("/api/v1/apps/", s.handleAppConnect)
+
+	// Call events
+	mux.HandleFunc("/api/v1/events", s.handleEvents)
+
 	// Admin
+	mux.HandleFunc("/api/v1/config", s.handleConfig)
+	mux.HandleFunc("/api/v1/logging", s.handleLogging)
+	mux.HandleFunc("/api/v1/logging/modules/", s.handleLoggingModule)
 	mux.HandleFunc("/api/v1/shutdown", s.handleShutdown)
 
+	s.mux = mux
 	s.httpServer = &http.Server{
 		Addr:    addr,
 		Handler: mux,
@@ -103,6 +345,22 @@ func NewServer(addr string, registrations RegistrationProvider, dialogMgr dialog
 	return s
 }
 
+// EnableDebug serves the runtime diagnostics endpoints (/debug/pprof/,
+// /debug/vars, /debug/dump) to requests bearing the current token. An
+// empty token leaves them disabled. Must be called before Start.
+func (s *Server) EnableDebug(token func() string) {
+	if debug.RegisterFunc(s.mux, token) {
+		slog.Info("[API] Debug endpoints enabled", "path", "/debug/")
+	}
+}
+
+// EnableTLS serves the API over HTTPS with cfg, whose certificate is
+// looked up per connection so renewals apply without a restart. Must be
+// called before Start.
+func (s *Server) EnableTLS(cfg *tls.Config) {
+	s.httpServer.TLSConfig = cfg
+}
+
 // RecordSession records an active RTP session
 func (s *Server) RecordSession(callID string, clientAddr string, clientPort int, serverAddr string, serverPort int) {
 	s.sessionsMu.Lock()
@@ -128,9 +386,13 @@ func (s *Server) RemoveSession(callID string) {
 
 // Start begins listening for HTTP requests
 func (s *Server) Start() error {
-	slog.Info("[API] Starting HTTP API server", "addr", s.addr)
+	slog.Info("[API] Starting HTTP API server", "addr", s.addr, "tls", s.httpServer.TLSConfig != nil)
 	go func() {
-		if err := s.httpServer.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+		serve := s.httpServer.ListenAndServe
+		if s.httpServer.TLSConfig != nil {
+			serve = func() error { return s.httpServer.ListenAndServeTLS("", "") }
+		}
+		if err := serve(); err != nil && err != http.ErrServerClosed {
 			slog.Error("[API] Server error", "error", err)
 			panic(err)
 		}
@@ -148,11 +410,21 @@ func (s *Server) Stop() error {
 
 // --- Health & Stats ---
 
+// HealthChecker returns the readiness checks served on /readyz, for the
+// application to add its dependencies to.
+func (s *Server) HealthChecker() *health.Checker {
+	return s.health
+}
+
+// handleHealth reports that the process is up, like /healthz; kept for
+// the UI and existing clients. Use /readyz to tell whether the node can
+// take calls.
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
 	uptime := time.Since(s.startTime).Seconds()
 	response := map[string]interface{}{
-		"status": "ok",
-		"uptime": int64(uptime),
+		"status":  "ok",
+		"uptime":  int64(uptime),
+		"version": version.Get(),
 	}
 	s.writeJSON(w, response)
 }
@@ -204,9 +476,13 @@ func (s *Server) handleRegistrations(w http.ResponseWriter, r *http.Request) {
 		Expires      int      `json:"expires"`
 		ExpiresAt    string   `json:"expires_at"`
 		RegisteredAt string   `json:"registered_at"`
+		CreatedAt    string   `json:"created_at"`
 		QValue       float32  `json:"q,omitempty"`
 		UserAgent    string   `json:"user_agent,omitempty"`
 		InstanceID   string   `json:"instance_id,omitempty"`
+		RegID        int      `json:"reg_id,omitempty"`
+		Outbound     bool     `json:"outbound,omitempty"`
+		FlowToken    string   `json:"flow_token,omitempty"`
 		Path         []string `json:"path,omitempty"`
 	}
 
@@ -223,9 +499,13 @@ func (s *Server) handleRegistrations(w http.ResponseWriter, r *http.Request) {
 				Expires:      b.Expires,
 				ExpiresAt:    b.ExpiresAt.Format(time.RFC3339),
 				RegisteredAt: b.RegisteredAt.Format(time.RFC3339),
+				CreatedAt:    b.CreatedAt.Format(time.RFC3339),
 				QValue:       b.QValue,
 				UserAgent:    b.UserAgent,
 				InstanceID:   b.InstanceID,
+				RegID:        b.RegID,
+				Outbound:     b.Outbound,
+				FlowToken:    b.FlowToken,
 				Path:         b.Path,
 			})
 		}
@@ -235,7 +515,7 @@ func (s *Server) handleRegistrations(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleRegistrationByAOR(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -254,6 +534,21 @@ func (s *Server) handleRegistrationByAOR(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if r.Method == http.MethodDelete {
+		// DELETE /api/v1/registrations/{aor}?binding_id=... removes one
+		// binding; without binding_id every binding of the AOR is removed
+		if len(s.registrations.GetAllBindings(aor)) == 0 {
+			http.Error(w, "Not found", http.StatusNotFound)
+			return
+		}
+		if err := s.registrations.RemoveBinding(aor, r.URL.Query().Get("binding_id")); err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	bindings := s.registrations.GetAllBindings(aor)
 	if len(bindings) == 0 {
 		http.Error(w, "Not found", http.StatusNotFound)
@@ -282,7 +577,7 @@ func (s *Server) handleDialogs(w http.ResponseWriter, r *http.Request) {
 }
 
 func (s *Server) handleDialogByID(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodDelete {
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -318,6 +613,22 @@ func (s *Server) handleDialogByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if r.Method == http.MethodDelete {
+		// DELETE /api/v1/dialogs/{id} hangs up an answered call with BYE;
+		// the dialplan then ends and tears down any bridged leg
+		if dlg.GetState() != dialog.StateConfirmed {
+			http.Error(w, "Dialog not answered", http.StatusConflict)
+			return
+		}
+		if err := s.dialogMgr.Terminate(callID, dialog.ReasonAdminHangup); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		slog.Info("[API] Call hung up", "call_id", callID)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
 	s.writeJSON(w, dlg.ToInfo())
 }
 
@@ -378,7 +689,11 @@ func (s *Server) handleRtpManagers(w http.ResponseWriter, r *http.Request) {
 			"address":       m.Address,
 			"healthy":       m.Healthy,
 			"drain_state":   m.DrainState.String(),
+			"circuit":       m.Circuit.String(),
 			"session_count": m.SessionCount,
+			"ports_total":   m.TotalPorts,
+			"ports_used":    m.UsedPorts,
+			"version":       m.Version,
 		})
 	}
 
@@ -400,14 +715,18 @@ func (s *Server) SetDrainProvider(dp DrainProvider) {
 // POST /api/v1/rtpmanagers/{nodeId}/drain - Start drain
 // GET /api/v1/rtpmanagers/{nodeId}/drain - Get drain status
 // DELETE /api/v1/rtpmanagers/{nodeId}/drain - Cancel drain
+// GET /api/v1/rtpmanagers/{nodeId}/drain/events - Stream drain events
+// POST /api/v1/rtpmanagers/{nodeId}/drain/sessions/{sessionId}/retry - Retry a failed migration
 func (s *Server) handleRtpManagerDrain(w http.ResponseWriter, r *http.Request) {
 	// Parse node ID and endpoint from path
 	// Expected paths:
 	// - /api/v1/rtpmanagers/{nodeId}/drain
+	// - /api/v1/rtpmanagers/{nodeId}/drain/events
+	// - /api/v1/rtpmanagers/{nodeId}/drain/sessions/{sessionId}/retry
 	path := strings.TrimPrefix(r.URL.Path, "/api/v1/rtpmanagers/")
 	parts := strings.Split(path, "/")
 
-	if len(parts) != 2 || parts[1] != "drain" {
+	if len(parts) < 2 || parts[1] != "drain" {
 		http.Error(w, "Invalid path. Expected /api/v1/rtpmanagers/{nodeId}/drain", http.StatusNotFound)
 		return
 	}
@@ -423,6 +742,18 @@ func (s *Server) handleRtpManagerDrain(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	switch {
+	case len(parts) == 3 && parts[2] == "events":
+		s.handleDrainEvents(w, r, nodeID)
+		return
+	case len(parts) == 5 && parts[2] == "sessions" && parts[4] == "retry":
+		s.handleRetryDrainSession(w, r, nodeID, parts[3])
+		return
+	case len(parts) != 2:
+		http.Error(w, "Invalid path. Expected /api/v1/rtpmanagers/{nodeId}/drain", http.StatusNotFound)
+		return
+	}
+
 	switch r.Method {
 	case http.MethodPost:
 		s.handleStartDrain(w, r, nodeID)
@@ -484,6 +815,11 @@ func (s *Server) handleGetDrainStatus(w http.ResponseWriter, nodeID string) {
 		return
 	}
 
+	s.writeJSON(w, drainStatusResponse(status))
+}
+
+// drainStatusResponse converts a drain status for the API
+func drainStatusResponse(status *drain.DrainStatus) map[string]interface{} {
 	response := map[string]interface{}{
 		"node_id":          status.NodeID,
 		"state":            status.State.String(),
@@ -511,7 +847,11 @@ func (s *Server) handleGetDrainStatus(w http.ResponseWriter, nodeID string) {
 		response["errors"] = errors
 	}
 
-	s.writeJSON(w, response)
+	if len(status.Sessions) > 0 {
+		response["sessions"] = status.Sessions
+	}
+
+	return response
 }
 
 // handleCancelDrain cancels an in-progress drain
@@ -527,6 +867,684 @@ func (s *Server) handleCancelDrain(w http.ResponseWriter, nodeID string) {
 	})
 }
 
+// --- Music on Hold ---
+
+// SetMOHProvider enables the music-on-hold management endpoints.
+func (s *Server) SetMOHProvider(mp MOHProvider) {
+	s.mohProvider = mp
+}
+
+// handleMOHClasses lists or creates music-on-hold classes
+// GET /api/v1/moh/classes - List classes
+// POST /api/v1/moh/classes - Create or replace a class
+func (s *Server) handleMOHClasses(w http.ResponseWriter, r *http.Request) {
+	if s.mohProvider == nil {
+		http.Error(w, "Music on hold not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, map[string]interface{}{
+			"default": s.mohProvider.Default(),
+			"classes": s.mohProvider.Classes(),
+		})
+	case http.MethodPost:
+		var class moh.Class
+		if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.putMOHClass(w, class)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMOHClassByName manages a single music-on-hold class
+// GET /api/v1/moh/classes/{name} - Get class
+// PUT /api/v1/moh/classes/{name} - Create or replace class
+// DELETE /api/v1/moh/classes/{name} - Delete class (must be unassigned)
+func (s *Server) handleMOHClassByName(w http.ResponseWriter, r *http.Request) {
+	if s.mohProvider == nil {
+		http.Error(w, "Music on hold not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/v1/moh/classes/"))
+	if err != nil || name == "" {
+		http.Error(w, "Class name required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		class, err := s.mohProvider.Class(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, class)
+	case http.MethodPut:
+		var class moh.Class
+		if err := json.NewDecoder(r.Body).Decode(&class); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		class.Name = name
+		s.putMOHClass(w, class)
+	case http.MethodDelete:
+		if err := s.mohProvider.DeleteClass(name); err != nil {
+			http.Error(w, err.Error(), mohErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "Class deleted",
+			"class":   name,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) putMOHClass(w http.ResponseWriter, class moh.Class) {
+	if err := s.mohProvider.PutClass(class); err != nil {
+		slog.Error("[API] Failed to save MOH class", "class", class.Name, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	s.writeJSON(w, class)
+}
+
+// handleMOHAssignments lists or sets tenant and queue assignments
+// GET /api/v1/moh/assignments - List assignments
+// POST /api/v1/moh/assignments - Assign {"scope", "key", "class"}
+func (s *Server) handleMOHAssignments(w http.ResponseWriter, r *http.Request) {
+	if s.mohProvider == nil {
+		http.Error(w, "Music on hold not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.mohProvider.Assignments())
+	case http.MethodPost:
+		var a moh.Assignment
+		if err := json.NewDecoder(r.Body).Decode(&a); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.mohProvider.Assign(a); err != nil {
+			http.Error(w, err.Error(), mohErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, a)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleMOHAssignmentByKey removes an assignment
+// DELETE /api/v1/moh/assignments/{scope}/{key}
+func (s *Server) handleMOHAssignmentByKey(w http.ResponseWriter, r *http.Request) {
+	if s.mohProvider == nil {
+		http.Error(w, "Music on hold not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodDelete {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/moh/assignments/")
+	scope, key, ok := strings.Cut(path, "/")
+	if !ok || key == "" {
+		http.Error(w, "Invalid path. Expected /api/v1/moh/assignments/{scope}/{key}", http.StatusNotFound)
+		return
+	}
+	key, err := url.PathUnescape(key)
+	if err != nil {
+		http.Error(w, "Invalid key encoding", http.StatusBadRequest)
+		return
+	}
+
+	if err := s.mohProvider.Unassign(scope, key); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	s.writeJSON(w, map[string]interface{}{
+		"message": "Assignment removed",
+		"scope":   scope,
+		"key":     key,
+	})
+}
+
+func mohErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, moh.ErrClassNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, moh.ErrClassInUse):
+		return http.StatusConflict
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// --- Caller Screening ---
+
+// SetScreeningProvider enables the caller blocklist endpoints.
+func (s *Server) SetScreeningProvider(sp ScreeningProvider) {
+	s.screening = sp
+}
+
+// handleScreeningLists lists or creates blocklists
+// GET /api/v1/screening/lists - List blocklists
+// POST /api/v1/screening/lists - Create or replace a blocklist
+func (s *Server) handleScreeningLists(w http.ResponseWriter, r *http.Request) {
+	if s.screening == nil {
+		http.Error(w, "Caller screening not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.screening.Lists())
+	case http.MethodPost:
+		var list screening.List
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.putScreeningList(w, list)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// handleScreeningListByName manages a single blocklist and its entries
+// GET /api/v1/screening/lists/{name} - Get blocklist
+// PUT /api/v1/screening/lists/{name} - Create or replace blocklist
+// DELETE /api/v1/screening/lists/{name} - Delete blocklist
+// POST /api/v1/screening/lists/{name}/entries - Add entry {"match", "pattern", "comment"}
+// DELETE /api/v1/screening/lists/{name}/entries?match=prefix&pattern=1900 - Remove entry
+func (s *Server) handleScreeningListByName(w http.ResponseWriter, r *http.Request) {
+	if s.screening == nil {
+		http.Error(w, "Caller screening not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/api/v1/screening/lists/")
+	path, entries := strings.CutSuffix(path, "/entries")
+	name, err := url.PathUnescape(path)
+	if err != nil || name == "" {
+		http.Error(w, "List name required", http.StatusBadRequest)
+		return
+	}
+
+	if entries {
+		s.handleScreeningEntries(w, r, name)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		list, err := s.screening.List(name)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		s.writeJSON(w, list)
+	case http.MethodPut:
+		var list screening.List
+		if err := json.NewDecoder(r.Body).Decode(&list); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		list.Name = name
+		s.putScreeningList(w, list)
+	case http.MethodDelete:
+		if err := s.screening.DeleteList(name); err != nil {
+			http.Error(w, err.Error(), screeningErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "List deleted",
+			"list":    name,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) handleScreeningEntries(w http.ResponseWriter, r *http.Request, name string) {
+	switch r.Method {
+	case http.MethodPost:
+		var entry screening.Entry
+		if err := json.NewDecoder(r.Body).Decode(&entry); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := s.screening.AddEntry(name, entry); err != nil {
+			http.Error(w, err.Error(), screeningErrorStatus(err))
+			return
+		}
+		list, _ := s.screening.List(name)
+		s.writeJSON(w, list)
+	case http.MethodDelete:
+		q := r.URL.Query()
+		if err := s.screening.RemoveEntry(name, q.Get("match"), q.Get("pattern")); err != nil {
+			http.Error(w, err.Error(), screeningErrorStatus(err))
+			return
+		}
+		list, _ := s.screening.List(name)
+		s.writeJSON(w, list)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func (s *Server) putScreeningList(w http.ResponseWriter, list screening.List) {
+	if err := s.screening.PutList(list); err != nil {
+		slog.Error("[API] Failed to save screening list", "list", list.Name, "error", err)
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	saved, _ := s.screening.List(list.Name)
+	s.writeJSON(w, saved)
+}
+
+func screeningErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, screening.ErrListNotFound), errors.Is(err, screening.ErrEntryNotFound):
+		return http.StatusNotFound
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// --- User Features ---
+
+// SetFeaturesProvider enables the user call feature endpoints.
+func (s *Server) SetFeaturesProvider(fp FeaturesProvider) {
+	s.features = fp
+}
+
+// handleUsers lists users with call feature settings
+// GET /api/v1/users
+func (s *Server) handleUsers(w http.ResponseWriter, r *http.Request) {
+	if s.features == nil {
+		http.Error(w, "User features not configured", http.StatusServiceUnavailable)
+		return
+	}
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeJSON(w, s.features.List())
+}
+
+// handleUserFeatures manages a user's call features
+// GET /api/v1/users/{user}/features - Get settings (defaults if none stored)
+// PUT /api/v1/users/{user}/features - Replace settings
+// PATCH /api/v1/users/{user}/features - Update the given fields
+// DELETE /api/v1/users/{user}/features - Reset to defaults
+func (s *Server) handleUserFeatures(w http.ResponseWriter, r *http.Request) {
+	if s.features == nil {
+		http.Error(w, "User features not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	path, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/users/"), "/features")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+	user, err := url.PathUnescape(path)
+	if err != nil || user == "" || strings.Contains(user, "/") {
+		http.Error(w, "User required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		s.writeJSON(w, s.features.Get(user))
+	case http.MethodPut, http.MethodPatch:
+		// PATCH decodes onto the current settings so omitted fields are kept
+		settings := features.Settings{}
+		if r.Method == http.MethodPatch {
+			settings = s.features.Get(user)
+		}
+		if err := json.NewDecoder(r.Body).Decode(&settings); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		settings.User = user
+		if err := s.features.Put(settings); err != nil {
+			slog.Error("[API] Failed to save user features", "user", user, "error", err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.writeJSON(w, s.features.Get(user))
+	case http.MethodDelete:
+		if err := s.features.Delete(user); err != nil {
+			status := http.StatusBadRequest
+			if errors.Is(err, features.ErrUserNotFound) {
+				status = http.StatusNotFound
+			}
+			http.Error(w, err.Error(), status)
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "User features reset",
+			"user":    user,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+// --- Recordings ---
+
+// SetRecordingStore enables the recording endpoints.
+func (s *Server) SetRecordingStore(store recording.Store) {
+	s.recordings = store
+}
+
+// handleRecordings lists stored recordings
+// GET /api/v1/recordings?prefix=voicemail/
+func (s *Server) handleRecordings(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.recordings == nil {
+		http.Error(w, "Recording storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	objects, err := s.recordings.List(r.Context(), r.URL.Query().Get("prefix"))
+	if err != nil {
+		slog.Error("[API] Failed to list recordings", "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+
+	s.writeJSON(w, map[string]interface{}{
+		"backend":    s.recordings.Backend(),
+		"recordings": objects,
+	})
+}
+
+// handleRecordingByName downloads or deletes a recording
+// GET /api/v1/recordings/{name} - Download
+// DELETE /api/v1/recordings/{name} - Delete
+func (s *Server) handleRecordingByName(w http.ResponseWriter, r *http.Request) {
+	if s.recordings == nil {
+		http.Error(w, "Recording storage not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/v1/recordings/"))
+	if err != nil || name == "" {
+		http.Error(w, "Recording name required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		body, err := s.recordings.Open(r.Context(), name)
+		if err != nil {
+			http.Error(w, err.Error(), recordingErrorStatus(err))
+			return
+		}
+		defer body.Close()
+		w.Header().Set("Content-Type", "application/octet-stream")
+		if _, err := io.Copy(w, body); err != nil {
+			slog.Debug("[API] Recording download interrupted", "name", name, "error", err)
+		}
+	case http.MethodDelete:
+		if err := s.recordings.Delete(r.Context(), name); err != nil {
+			http.Error(w, err.Error(), recordingErrorStatus(err))
+			return
+		}
+		s.writeJSON(w, map[string]interface{}{
+			"message": "Recording deleted",
+			"name":    name,
+		})
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+	}
+}
+
+func recordingErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, recording.ErrNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, recording.ErrInvalidName):
+		return http.StatusBadRequest
+	default:
+		return http.StatusBadGateway
+	}
+}
+
+// --- External Applications ---
+
+// SetAppsProvider enables the external application endpoints.
+func (s *Server) SetAppsProvider(ap AppsProvider) {
+	s.apps = ap
+}
+
+// handleApps lists connected applications
+// GET /api/v1/apps
+func (s *Server) handleApps(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.apps == nil {
+		http.Error(w, "External applications not configured", http.StatusServiceUnavailable)
+		return
+	}
+	s.writeJSON(w, s.apps.Apps())
+}
+
+// handleAppConnect accepts an application's WebSocket connection
+// GET /api/v1/apps/{name}/ws
+func (s *Server) handleAppConnect(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.apps == nil {
+		http.Error(w, "External applications not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	name, ok := strings.CutSuffix(strings.TrimPrefix(r.URL.Path, "/api/v1/apps/"), "/ws")
+	if !ok || name == "" || strings.Contains(name, "/") {
+		http.Error(w, "Not found", http.StatusNotFound)
+		return
+	}
+	s.apps.ServeWebSocket(w, r, name)
+}
+
+// --- Test Calls ---
+
+// SetOriginateProvider enables the test call endpoint.
+func (s *Server) SetOriginateProvider(op OriginateProvider) {
+	s.originator = op
+}
+
+// handleOriginate places a test call and answers once it is answered or
+// has failed, or at once with its progress if async
+// POST /api/v1/calls
+func (s *Server) handleOriginate(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.originator == nil {
+		http.Error(w, "Test calls not configured", http.StatusServiceUnavailable)
+		return
+	}
+
+	var body struct {
+		Target   string `json:"target"`
+		CallerID string `json:"caller_id"`
+		Timeout  int    `json:"timeout"`
+		Duration int    `json:"duration"`
+		Tone     string `json:"tone"`
+		File     string `json:"file"`
+
+		CallbackURL string `json:"callback_url"`
+		Async       bool   `json:"async"`
+		Retry       struct {
+			Codes    []int    `json:"codes"`
+			Attempts int      `json:"attempts"`
+			Backoff  int      `json:"backoff"`
+			Targets  []string `json:"targets"`
+		} `json:"retry"`
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	if body.Target == "" {
+		http.Error(w, "Target required", http.StatusBadRequest)
+		return
+	}
+
+	req := originate.Request{
+		Target:      body.Target,
+		CallerID:    body.CallerID,
+		Timeout:     time.Duration(body.Timeout) * time.Second,
+		Duration:    time.Duration(body.Duration) * time.Second,
+		Tone:        body.Tone,
+		File:        body.File,
+		CallbackURL: body.CallbackURL,
+		Retry: originate.Retry{
+			Codes:    body.Retry.Codes,
+			Attempts: body.Retry.Attempts,
+			Backoff:  time.Duration(body.Retry.Backoff) * time.Second,
+			Targets:  body.Retry.Targets,
+		},
+	}
+	if body.Async {
+		status, err := s.originator.Start(req)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Info("[API] Test call started", "call_id", status.CallID, "target", status.Target)
+		w.WriteHeader(http.StatusAccepted)
+		s.writeJSON(w, status)
+		return
+	}
+
+	// The dial is canceled if the client goes away before the answer;
+	// the answered call outlives the request
+	call, err := s.originator.Originate(r.Context(), req)
+	if err != nil {
+		slog.Info("[API] Test call failed", "target", body.Target, "error", err)
+		http.Error(w, err.Error(), originateErrorStatus(err))
+		return
+	}
+
+	slog.Info("[API] Test call placed", "call_id", call.CallID, "target", call.Target)
+	w.WriteHeader(http.StatusCreated)
+	s.writeJSON(w, map[string]interface{}{
+		"call_id":  call.CallID,
+		"target":   call.Target,
+		"duration": int(call.Duration.Seconds()),
+	})
+}
+
+func originateErrorStatus(err error) int {
+	var dialErr *b2bua.DialError
+	switch {
+	case errors.Is(err, b2bua.ErrTargetNotFound), errors.Is(err, b2bua.ErrNoContacts):
+		return http.StatusNotFound
+	case errors.Is(err, b2bua.ErrDialTimeout), errors.Is(err, context.DeadlineExceeded):
+		return http.StatusGatewayTimeout
+	case errors.As(err, &dialErr):
+		return http.StatusBadGateway
+	default:
+		return http.StatusBadRequest
+	}
+}
+
+// --- Call Events ---
+
+// eventsHeartbeat is how often an idle event stream sends a comment, so
+// proxies and clients can tell it is alive
+const eventsHeartbeat = 15 * time.Second
+
+// SetEventsProvider enables the call event stream.
+func (s *Server) SetEventsProvider(ep EventsProvider) {
+	s.events = ep
+}
+
+// handleEvents streams call events as Server-Sent Events until the
+// client disconnects. Repeat ?type= to receive only some event types.
+// GET /api/v1/events
+func (s *Server) handleEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if s.events == nil {
+		http.Error(w, "Event stream not configured", http.StatusServiceUnavailable)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	wanted := make(map[events.EventType]bool)
+	for _, t := range r.URL.Query()["type"] {
+		wanted[events.EventType(t)] = true
+	}
+
+	sub := s.events.Subscribe(0)
+	defer sub.Close()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := io.WriteString(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case event, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			if len(wanted) > 0 && !wanted[event.Type()] {
+				continue
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				slog.Error("[API] Failed to encode event", "type", event.Type(), "error", err)
+				continue
+			}
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Type(), data); err != nil {
+				return
+			}
+		}
+		flusher.Flush()
+	}
+}
+
 // --- Admin ---
 
 func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
@@ -536,6 +1554,78 @@ func (s *Server) handleShutdown(w http.ResponseWriter, r *http.Request) {
 	s.writeJSON(w, response)
 }
 
+// --- Logging ---
+
+// handleLogging shows or changes the global log level
+// GET /api/v1/logging - Global level and module overrides
+// PUT /api/v1/logging - Set the global level: {"level": "info"}
+func (s *Server) handleLogging(w http.ResponseWriter, r *http.Request) {
+	switch r.Method {
+	case http.MethodGet:
+	case http.MethodPut:
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := logger.ValidateLevel(req.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.SetLevel(req.Level)
+		slog.Warn("[API] Log level changed", "level", req.Level)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeLogLevels(w)
+}
+
+// handleLoggingModule overrides the log level of one module, e.g. dialog
+// or b2bua, on the running process
+// PUT /api/v1/logging/modules/{module} - Set: {"level": "debug"}
+// DELETE /api/v1/logging/modules/{module} - Back to the global level
+func (s *Server) handleLoggingModule(w http.ResponseWriter, r *http.Request) {
+	module, err := url.PathUnescape(strings.TrimPrefix(r.URL.Path, "/api/v1/logging/modules/"))
+	if err != nil || module == "" || strings.Contains(module, "/") {
+		http.Error(w, "Module required", http.StatusBadRequest)
+		return
+	}
+
+	switch r.Method {
+	case http.MethodPut:
+		var req struct {
+			Level string `json:"level"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid JSON: "+err.Error(), http.StatusBadRequest)
+			return
+		}
+		if err := logger.SetModuleLevel(module, req.Level); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Warn("[API] Module log level changed", "module", module, "level", req.Level)
+	case http.MethodDelete:
+		logger.ClearModuleLevel(module)
+		slog.Warn("[API] Module log level reset", "module", module)
+	default:
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	s.writeLogLevels(w)
+}
+
+// writeLogLevels answers the global level and the module overrides
+func (s *Server) writeLogLevels(w http.ResponseWriter) {
+	s.writeJSON(w, map[string]interface{}{
+		"level":   logger.GetLevel(),
+		"modules": logger.ModuleLevels(),
+	})
+}
+
 // --- Helpers ---
 
 func (s *Server) writeJSON(w http.ResponseWriter, v interface{}) {
@@ -2,23 +2,65 @@ package app
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync/atomic"
 	"time"
 
 	"github.com/emiago/sipgo"
 	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/s3"
+	"github.com/sebas/switchboard/internal/signaling/agents"
+	"github.com/sebas/switchboard/internal/signaling/alerts"
 	"github.com/sebas/switchboard/internal/signaling/api"
 	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/callback"
+	"github.com/sebas/switchboard/internal/signaling/codecs"
 	"github.com/sebas/switchboard/internal/signaling/config"
+	"github.com/sebas/switchboard/internal/signaling/credentials"
+	"github.com/sebas/switchboard/internal/signaling/dialer"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
 	"github.com/sebas/switchboard/internal/signaling/dialplan"
 	"github.com/sebas/switchboard/internal/signaling/drain"
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/features"
+	"github.com/sebas/switchboard/internal/signaling/headerpolicy"
+	"github.com/sebas/switchboard/internal/signaling/identity"
+	"github.com/sebas/switchboard/internal/signaling/ivr"
+	"github.com/sebas/switchboard/internal/signaling/keepalive"
+	"github.com/sebas/switchboard/internal/signaling/kpi"
 	"github.com/sebas/switchboard/internal/signaling/location"
+	"github.com/sebas/switchboard/internal/signaling/loopdetect"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/metrics"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+	"github.com/sebas/switchboard/internal/signaling/originate"
+	"github.com/sebas/switchboard/internal/signaling/overload"
+	"github.com/sebas/switchboard/internal/signaling/recording"
+	"github.com/sebas/switchboard/internal/signaling/regevent"
+	"github.com/sebas/switchboard/internal/signaling/responsemap"
+	"github.com/sebas/switchboard/internal/signaling/routestats"
 	"github.com/sebas/switchboard/internal/signaling/routing"
+	"github.com/sebas/switchboard/internal/signaling/screening"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+	"github.com/sebas/switchboard/internal/signaling/stasis"
+	"github.com/sebas/switchboard/internal/signaling/topology"
+	"github.com/sebas/switchboard/internal/signaling/trunks"
+	"github.com/sebas/switchboard/internal/signaling/tts"
+	"github.com/sebas/switchboard/internal/statsd"
 )
 
+// APIListenAddr is where the REST API and health probes are served
+const APIListenAddr = "0.0.0.0:8080"
+
 type SwitchBoard struct {
 	ua              *sipgo.UserAgent
 	srv             *sipgo.Server
@@ -34,9 +76,45 @@ type SwitchBoard struct {
 	dialogMgr       dialog.DialogStore
 	transport       mediaclient.Transport
 	callService     b2bua.CallService
+	janitor         *recording.Janitor
+	alerts          *alerts.Engine
+	metrics         *metrics.Reporter
+	statsd          *statsd.Client
+	eventHub        *events.Hub
+	regEvents       *regevent.Notifier
+	middleware      *middleware.Chain
+	loops           *loopdetect.Detector
+	shedder         *overload.Shedder
+	dialer          *dialer.Dialer // nil unless a dialer config is set
+	callbacks       *callback.Manager
+	agents          *agents.Registry // nil unless an agents config is set
+	tls             *tlsCerts
+	secrets         *appSecrets
+	trunks          *trunks.Registry // nil unless a trunk file is configured
+	peers           *trunks.Peers    // Client certificates of SIP-TLS connections
+	listening       atomic.Bool      // SIP socket bound and served
 }
 
 func NewServer(cfg *config.Config) (*SwitchBoard, error) {
+	// Transaction timers are global to the SIP stack, so set them first
+	if err := cfg.Timers.Validate(); err != nil {
+		return nil, fmt.Errorf("invalid SIP timers: %w", err)
+	}
+	applySIPTimers(cfg.Timers)
+
+	// Settings held in a secret store: the debug token and TLS files
+	secretsCfg, err := loadSecrets(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load secrets: %w", err)
+	}
+
+	// Certificates of SIP-TLS, the HTTPS API and the RTP manager connections
+	tlsCfg, err := loadTLS(cfg)
+	if err != nil {
+		_ = secretsCfg.resolver.Close()
+		return nil, fmt.Errorf("invalid TLS settings: %w", err)
+	}
+
 	// Create SIP user agent, server, and client
 	ua, err := sipgo.NewUA()
 	if err != nil {
@@ -69,7 +147,7 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		Address: sip.Uri{
 			Scheme: "sip",
 			User:   "switchboard",
-			Host:   cfg.AdvertiseAddr,
+			Host:   sipaddr.Host(cfg.AdvertiseAddr),
 			Port:   cfg.Port,
 		},
 	}
@@ -78,6 +156,12 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		ContactHDR: contact,
 	}
 
+	// Reg event package (RFC 3680) subscriptions
+	regEvents := regevent.NewNotifier(locStore, uac, contact)
+
+	// Identifies this server in events, alerts and to the RTP managers
+	nodeID, _ := os.Hostname()
+
 	// Create RTP Manager pool (gRPC transport)
 	poolCfg := mediaclient.PoolConfig{
 		ConnectTimeout:      cfg.GRPCConnectTimeout,
@@ -86,6 +170,11 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		HealthCheckInterval: 5 * time.Second,
 		UnhealthyThreshold:  3,
 		HealthyThreshold:    2,
+		Breaker:             mediaclient.DefaultBreakerConfig(),
+		CreateAttempts:      3,
+		Owner:               nodeID,
+		ReconcileInterval:   cfg.MediaReconcileInterval,
+		TLS:                 tlsCfg.rtp,
 	}
 	// Prefer NodeAddresses (node=addr format) over legacy Addresses
 	if len(cfg.RTPManagerNodes) > 0 {
@@ -104,16 +193,51 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 
 	// Create dialog manager (single source of truth for call state)
 	dialogMgr := dialog.NewManager(uac, dialogUA)
+	dialogMgr.SetTimers(cfg.Timers.AckTimeout(), cfg.Timers.InviteTimeout())
+
+	// Per-network and per-family advertised addresses (split-horizon NAT, dual-stack)
+	var advertiser *advertise.Selector
+	if cfg.AdvertiseRules != "" || cfg.AdvertiseAddr6 != "" {
+		advertiser, err = advertise.Parse(cfg.AdvertiseAddr, cfg.AdvertiseRules)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("invalid advertise rules: %w", err)
+		}
+		advertiser.SetIPv6Default(cfg.AdvertiseAddr6)
+		dialogMgr.SetAdvertise(advertiser)
+		slog.Info("Advertise rules loaded", "rules", cfg.AdvertiseRules, "ipv6", cfg.AdvertiseAddr6)
+	}
+
+	// Service-Route (RFC 3608): registered clients send later requests
+	// through us, at the address we advertise to them
+	if cfg.ServiceRoute {
+		registerHandler.SetServiceRoute(func(peer string) sip.Uri {
+			route := contact.Address
+			if addr := advertiser.Select(peer); addr != "" {
+				route.Host = sipaddr.Host(addr)
+			}
+			route.UriParams = sip.NewParams()
+			route.UriParams.Add("lr", "")
+			return route
+		})
+	}
 
 	// Create API server with register handler, dialog manager, and RTP manager stats
 	// Pool implements mediaclient.StatsProvider which satisfies api.RtpManagerProvider
-	apiServer := api.NewServer("0.0.0.0:8080", registerHandler, dialogMgr, mediaTransport)
+	apiServer := api.NewServer(APIListenAddr, registerHandler, dialogMgr, mediaTransport)
+	apiServer.EnableDebug(secretsCfg.debugToken.Value)
+	if cfg.APITLS {
+		apiServer.EnableTLS(tlsCfg.sip.ServerConfig())
+	}
+	apiServer.SetConfigProvider(cfg)
 
 	// Create drain migrator and coordinator
 	localContact := sip.Uri{
 		Scheme: "sip",
 		User:   "switchboard",
-		Host:   cfg.AdvertiseAddr,
+		Host:   sipaddr.Host(cfg.AdvertiseAddr),
 		Port:   cfg.Port,
 	}
 	migrator := drain.NewMigrator(drain.MigratorConfig{
@@ -123,6 +247,8 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		Mode:          drain.DrainModeGraceful,
 	})
 	drainCoordinator := drain.NewCoordinator(mediaTransport, migrator)
+	mediaTransport.SetOnMemberRestart(drainCoordinator.Recover)
+	mediaTransport.SetOnDrainRequest(drainCoordinator.DrainRequested)
 	apiServer.SetDrainProvider(drainCoordinator)
 
 	// Load dialplan configuration
@@ -139,23 +265,291 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 	}
 	slog.Info("Dialplan loaded", "path", dialplanPath, "routes", dp.RouteCount())
 
-	// Create dialplan executor with default actions
-	executor := dialplan.NewExecutor(dp, dialplan.DefaultRegistry(), slog.Default())
+	// Create dialplan executor with default actions, plus "stasis" for
+	// external applications connected to the API
+	apps := stasis.NewRegistry()
+	apiServer.SetAppsProvider(apps)
+	actions := dialplan.DefaultRegistry()
+	actions.Register("stasis", apps.NewAction)
+	// Declarative IVR menus, run by "ivr" actions
+	if cfg.IVRConfigPath != "" {
+		menus, err := ivr.Load(cfg.IVRConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load IVR menus: %w", err)
+		}
+		actions.Register("ivr", menus.NewAction)
+		apiServer.SetIVRProvider(menus)
+		slog.Info("IVR enabled", "config", cfg.IVRConfigPath, "menus", len(menus.Menus()))
+	}
+	executor := dialplan.NewExecutor(dp, actions, slog.Default())
+	// Events are logged and streamed to API subscribers (switchboardctl events)
+	eventHub := events.NewHub()
+	apiServer.SetEventsProvider(eventHub)
+	callEvents := events.NewMultiPublisher(events.NewLoggingPublisher(slog.Default()), eventHub)
+	executor.SetPublisher(callEvents, events.NewBuilder(nodeID))
+
+	// Header manipulation rules for interop with carriers and devices
+	var policy *headerpolicy.Policy
+	var outboundPolicy b2bua.HeaderPolicy
+	if cfg.HeaderPolicyPath != "" {
+		policy, err = headerpolicy.Load(cfg.HeaderPolicyPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load header policy: %w", err)
+		}
+		outboundPolicy = policy
+		slog.Info("Header policy enabled", "config", cfg.HeaderPolicyPath, "rules", policy.Rules())
+	}
+
+	// Carriers identified by their SIP-TLS client certificate
+	var trunkRegistry *trunks.Registry
+	if cfg.TrunksConfigPath != "" {
+		trunkRegistry, err = trunks.Load(cfg.TrunksConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load trunks: %w", err)
+		}
+		if tlsCfg.sip == nil {
+			slog.Warn("Trunks configured without SIP-TLS, no trunk will be identified", "config", cfg.TrunksConfigPath)
+		}
+		slog.Info("Trunk identification enabled", "config", cfg.TrunksConfigPath, "trunks", len(trunkRegistry.Trunks()))
+
+		responseMaps := make(map[string]responsemap.Map)
+		for _, t := range trunkRegistry.Trunks() {
+			if len(t.ResponseMap) > 0 {
+				responseMaps[t.Name] = t.ResponseMap
+			}
+		}
+		executor.SetTrunkResponseMaps(responseMaps)
+	}
+
+	// Topology hiding of the SIP messages sent to peers, per trunk
+	hider, err := topology.NewHider(topology.Policy{
+		Hide:     cfg.HideTopology,
+		Internal: strings.Split(cfg.TopologyInternal, ","),
+	}, func(peer string) string {
+		if addr := advertiser.Select(peer); addr != "" {
+			return addr
+		}
+		return cfg.AdvertiseAddr
+	}, cfg.Port)
+	if err == nil && trunkRegistry != nil {
+		for _, t := range trunkRegistry.Trunks() {
+			if t.Topology == nil {
+				continue
+			}
+			if err = hider.AddTrunk(t.Name, t.Hosts, *t.Topology); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = ua.Close()
+		locStore.Close()
+		_ = mediaTransport.Close()
+		return nil, fmt.Errorf("invalid topology hiding: %w", err)
+	}
+	if hider.Hides() {
+		outboundPolicy = b2bua.HeaderPolicies{outboundPolicy, hider}
+		slog.Info("Topology hiding enabled", "default", cfg.HideTopology, "internal", cfg.TopologyInternal)
+	}
+
+	// User-Agent and Server headers of the messages sent, per tenant
+	headers, err := identity.Load(cfg.UserAgent, cfg.ServerHeader, cfg.IdentityConfigPath)
+	if err != nil {
+		_ = ua.Close()
+		locStore.Close()
+		_ = mediaTransport.Close()
+		return nil, fmt.Errorf("failed to load identity headers: %w", err)
+	}
+	var outboundIdentity b2bua.Identity
+	if headers.Enabled() {
+		outboundIdentity = headers
+		dialogMgr.SetIdentity(headers)
+		regEvents.SetIdentity(headers)
+		slog.Info("Identity headers enabled", "user_agent", cfg.UserAgent, "server", cfg.ServerHeader, "tenants", headers.Tenants())
+	}
+
+	// Digest authentication of registrations and calls from local users
+	var authenticator *credentials.Authenticator
+	if cfg.CredentialsPath != "" {
+		store, err := credentials.Load(cfg.CredentialsPath, realm)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load credentials: %w", err)
+		}
+		authenticator = credentials.NewAuthenticator(store, executor.IsEmergency)
+		apiServer.SetCredentialsProvider(store)
+		slog.Info("Digest authentication enabled", "config", cfg.CredentialsPath, "realm", realm, "users", len(store.List()))
+	}
+
+	// Refuses new calls while the server is overloaded
+	var shedder *overload.Shedder
+	var loadMonitor b2bua.LoadMonitor
+	overloadCfg := overload.Config{
+		MaxCPUPercent:       cfg.OverloadMaxCPU,
+		MaxDialogs:          cfg.OverloadMaxDialogs,
+		MaxOriginateLatency: cfg.OverloadMaxOriginateLatency,
+		RetryAfter:          cfg.OverloadRetryAfter,
+	}
+	if overloadCfg.Enabled() {
+		shedder = overload.New(overloadCfg, dialogMgr, executor.IsEmergency)
+		loadMonitor = shedder
+		slog.Info("Overload protection enabled",
+			"max_cpu", cfg.OverloadMaxCPU,
+			"max_dialogs", cfg.OverloadMaxDialogs,
+			"max_originate_latency", cfg.OverloadMaxOriginateLatency,
+		)
+	}
+
+	// Refuses calls routed back into this server too often
+	loops := loopdetect.New(cfg.MaxSpirals, cfg.Timers.InviteTimeout())
+
+	// Per-route ASR, NER, ACD and PDD of outbound legs
+	kpis := kpi.NewCollector(cfg.KPIRetention)
+	apiServer.SetKPIProvider(kpis)
+
+	// Success and failure codes per dialplan rule and trunk
+	routeStats := routestats.NewTracker(routestats.DefaultWindow)
+	apiServer.SetRouteStatsProvider(routeStats)
+	executor.SetRouteStats(routeStats)
+
+	// Music-on-hold classes, for the dialplan and for calls put on hold
+	var mohRegistry, holdMusic *moh.Registry
+	if cfg.MOHConfigPath != "" {
+		registry, err := moh.Load(cfg.MOHConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load music on hold: %w", err)
+		}
+		mohRegistry = registry
+		if cfg.HoldMOH {
+			holdMusic = registry
+		}
+	} else if cfg.HoldMOH {
+		slog.Warn("--hold-moh needs --moh-config; holds are passed on instead")
+	}
+
+	// Streams other than audio: rejected, or relayed between bridged legs
+	videoMode, err := sdpmedia.ParseMode(cfg.Video)
+	if err != nil {
+		_ = ua.Close()
+		locStore.Close()
+		_ = mediaTransport.Close()
+		return nil, fmt.Errorf("invalid --video: %w", err)
+	}
+	if videoMode == sdpmedia.Passthrough {
+		slog.Info("Video passthrough enabled")
+	}
+
+	// Codecs allowed per endpoint, trunk and tenant, most preferred first
+	defaultCodecs, err := codecs.Parse(cfg.Codecs)
+	var codecPolicies *codecs.Policies
+	if err == nil {
+		codecPolicies, err = codecs.Load(defaultCodecs, cfg.CodecsConfigPath)
+	}
+	if err == nil && trunkRegistry != nil {
+		for _, t := range trunkRegistry.Trunks() {
+			if len(t.Codecs) == 0 {
+				continue
+			}
+			if err = codecPolicies.AddTrunk(t.Name, t.Hosts, t.Codecs); err != nil {
+				break
+			}
+		}
+	}
+	if err != nil {
+		_ = ua.Close()
+		locStore.Close()
+		_ = mediaTransport.Close()
+		return nil, fmt.Errorf("invalid codec policy: %w", err)
+	}
+	slog.Info("Codec policy", "default", defaultCodecs, "config", cfg.CodecsConfigPath)
 
 	// Create B2BUA CallService for dial actions
 	callService := b2bua.NewCallService(b2bua.CallServiceConfig{
-		Client:        uac,
-		Resolver:      b2bua.DefaultResolver(locStore, cfg.AdvertiseAddr),
-		DialogManager: dialogMgr,
-		Transport:     mediaTransport,
-		LocalContact:  fmt.Sprintf("sip:switchboard@%s:%d", cfg.AdvertiseAddr, cfg.Port),
-		AdvertiseAddr: cfg.AdvertiseAddr,
-		Port:          cfg.Port,
+		Client:         uac,
+		Resolver:       b2bua.DefaultResolver(locStore, cfg.AdvertiseAddr),
+		DialogManager:  dialogMgr,
+		Transport:      mediaTransport,
+		LocalContact:   fmt.Sprintf("sip:switchboard@%s:%d", sipaddr.Host(cfg.AdvertiseAddr), cfg.Port),
+		AdvertiseAddr:  cfg.AdvertiseAddr,
+		Advertise:      advertiser,
+		Port:           cfg.Port,
+		Ringback:       cfg.Ringback,
+		EarlyMedia:     cfg.EarlyMedia,
+		Video:          videoMode,
+		ConfirmPrompt:  cfg.ConfirmPrompt,
+		ConfirmTimeout: cfg.ConfirmTimeout,
+		HoldMusic:      holdMusic,
+		Music:          mohRegistry,
+		HeaderPolicy:   outboundPolicy,
+		Identity:       outboundIdentity,
+		Codecs:         codecPolicies,
+		LoopDetector:   loops,
+		LoadMonitor:    loadMonitor,
+		KPIRecorder:    kpi.Recorders{kpis, routeStats},
+		InviteTimeout:  cfg.Timers.InviteTimeout(),
 	})
 
 	// Wire BridgeMapper to migrator for bridged call migration during drain
 	migrator.SetBridgeMapper(callService.GetBridgeMapper())
 
+	// Re-INVITEs in bridged calls are renegotiated end to end
+	dialogMgr.SetReINVITEHandler(callService.RelayReINVITE)
+
+	// Test calls placed through the API
+	originator := originate.New(callService, mediaTransport)
+	originator.SetPublisher(callEvents, events.NewBuilder(nodeID))
+	apiServer.SetOriginateProvider(originator)
+	apiServer.SetCallsProvider(callService)
+
+	// Callers called back once the extension they asked for is idle
+	callbacks := callback.New(callService, mediaTransport, dialogMgr)
+	apiServer.SetCallbackProvider(callbacks)
+
+	// Agent states: calls are distributed only to available agents
+	var agentStates *agents.Registry
+	if cfg.AgentsConfigPath != "" {
+		agentStates, err = agents.Load(cfg.AgentsConfigPath, dialogMgr)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load agents: %w", err)
+		}
+		apiServer.SetAgentsProvider(agentStates)
+		slog.Info("Agent states enabled", "config", cfg.AgentsConfigPath, "agents", len(agentStates.Statuses()))
+	}
+
+	// Outbound campaigns, paced against their free agents
+	var campaignDialer *dialer.Dialer
+	if cfg.DialerConfigPath != "" {
+		campaignDialer, err = dialer.Load(cfg.DialerConfigPath, callService, mediaTransport)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load dialer campaigns: %w", err)
+		}
+		if agentStates != nil {
+			campaignDialer.SetAgents(agentStates)
+		}
+		apiServer.SetDialerProvider(campaignDialer)
+		slog.Info("Dialer enabled", "config", cfg.DialerConfigPath, "campaigns", len(campaignDialer.Statuses()))
+	}
+
 	// Create SIP method handlers
 	inviteHandler := routing.NewInviteHandler(
 		mediaTransport,
@@ -167,6 +561,100 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		locStore,
 		callService,
 	)
+	inviteHandler.SetCodecs(codecPolicies)
+	if agentStates != nil {
+		inviteHandler.SetAgents(agentStates)
+	}
+	if cfg.TTSProvider != "" {
+		provider, err := tts.NewProvider(tts.Config{
+			Provider: cfg.TTSProvider,
+			URL:      cfg.TTSURL,
+			Command:  cfg.TTSCommand,
+		})
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to create TTS provider: %w", err)
+		}
+		inviteHandler.SetTTS(tts.NewEngine(provider, int64(cfg.TTSCacheSizeMB)<<20, cfg.TTSVoice))
+		slog.Info("TTS enabled", "provider", cfg.TTSProvider, "cache_mb", cfg.TTSCacheSizeMB)
+	}
+	if mohRegistry != nil {
+		inviteHandler.SetMOH(mohRegistry)
+		apiServer.SetMOHProvider(mohRegistry)
+		slog.Info("Music on hold enabled", "config", cfg.MOHConfigPath, "classes", len(mohRegistry.Classes()))
+	}
+	if cfg.ScreeningConfigPath != "" {
+		screener, err := screening.Load(cfg.ScreeningConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load caller screening: %w", err)
+		}
+		inviteHandler.SetScreener(screener)
+		apiServer.SetScreeningProvider(screener)
+		slog.Info("Caller screening enabled", "config", cfg.ScreeningConfigPath, "lists", len(screener.Lists()))
+	}
+	if cfg.FeaturesConfigPath != "" {
+		store, err := features.Load(cfg.FeaturesConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load user features: %w", err)
+		}
+		inviteHandler.SetFeatures(store)
+		executor.SetFeatures(store)
+		apiServer.SetFeaturesProvider(store)
+		slog.Info("User call features enabled", "config", cfg.FeaturesConfigPath, "users", len(store.List()))
+	}
+	var janitor *recording.Janitor
+	if cfg.RecordingBackend != "" {
+		store, policy, err := newRecordingStore(cfg)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, err
+		}
+		apiServer.SetRecordingStore(store)
+		if policy.Enabled() {
+			janitor = recording.NewJanitor(store, policy, 0)
+		}
+		slog.Info("Recording storage enabled", "backend", store.Backend(), "retention", cfg.RecordingRetention)
+	}
+	var alertEngine *alerts.Engine
+	if cfg.AlertsConfigPath != "" {
+		alertCfg, err := alerts.Load(cfg.AlertsConfigPath)
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to load alerts: %w", err)
+		}
+		alertEngine = alerts.NewEngine(alertCfg, nodeID, mediaTransport)
+		slog.Info("Alerting enabled", "config", cfg.AlertsConfigPath, "webhooks", len(alertCfg.Webhooks), "email", alertCfg.Email != nil)
+	}
+	var statsdClient *statsd.Client
+	var reporter *metrics.Reporter
+	if cfg.MetricsExporter != "" {
+		statsdClient, err = statsd.New(statsd.Config{
+			Addr:   cfg.StatsDAddr,
+			Flavor: cfg.MetricsExporter,
+			Prefix: cfg.StatsDPrefix,
+			Tags:   statsd.ParseTags(cfg.StatsDTags),
+		})
+		if err != nil {
+			_ = ua.Close()
+			locStore.Close()
+			_ = mediaTransport.Close()
+			return nil, fmt.Errorf("failed to start metrics exporter: %w", err)
+		}
+		reporter = metrics.NewReporter(statsdClient, mediaTransport, cfg.MetricsInterval)
+		slog.Info("Metrics exporter enabled", "exporter", cfg.MetricsExporter, "addr", cfg.StatsDAddr)
+	}
 	byeHandler := routing.NewBYEHandler(dialogMgr, callService)
 	ackHandler := routing.NewACKHandler(dialogMgr)
 	cancelHandler := routing.NewCANCELHandler(dialogMgr)
@@ -186,7 +674,24 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		dialogMgr:       dialogMgr,
 		transport:       mediaTransport,
 		callService:     callService,
+		janitor:         janitor,
+		alerts:          alertEngine,
+		metrics:         reporter,
+		statsd:          statsdClient,
+		eventHub:        eventHub,
+		regEvents:       regEvents,
+		middleware:      middleware.NewChain(),
+		loops:           loops,
+		shedder:         shedder,
+		dialer:          campaignDialer,
+		callbacks:       callbacks,
+		agents:          agentStates,
+		tls:             tlsCfg,
+		secrets:         secretsCfg,
+		trunks:          trunkRegistry,
+		peers:           trunks.NewPeers(),
 	}
+	proxy.addReadinessChecks(mediaTransport)
 
 	// Set up dialog termination callback to cleanup transport sessions and API records
 	dialogMgr.SetOnTerminated(func(d *dialog.Dialog) {
@@ -211,21 +716,100 @@ func NewServer(cfg *config.Config) (*SwitchBoard, error) {
 		}
 	})
 
-	// Register request handlers
-	uas.OnRequest(sip.REGISTER, proxy.handleRegister)
-	uas.OnRequest(sip.INVITE, proxy.handleINVITE)
-	uas.OnRequest(sip.BYE, proxy.handleBYE)
-	uas.OnRequest(sip.ACK, proxy.handleACK)
-	uas.OnRequest(sip.CANCEL, proxy.handleCANCEL)
+	// Answer the RTP managers' orphan checks and reconcile sessions with
+	// calls: a session is still in use while its dialog or outbound leg exists
+	mediaTransport.SetSessionLiveness(func(sc mediaclient.SessionCheck) bool {
+		if _, ok := dialogMgr.Get(sc.CallID); ok {
+			return true
+		}
+		if callService.HasLeg(sc.CallID) {
+			return true
+		}
+		_, ok := dialogMgr.FindBySessionID(sc.SessionID)
+		return ok
+	})
+
+	// Handle RTP inactivity reports from the RTP manager pool
+	mediaTransport.SetOnMediaTimeout(func(mt mediaclient.MediaTimeout) {
+		dlg, ok := dialogMgr.FindBySessionID(mt.SessionID)
+		if !ok {
+			slog.Debug("[App] Media timeout for unknown session", "session_id", mt.SessionID)
+			return
+		}
+		if !cfg.MediaTimeoutHangup {
+			return
+		}
+		// A held party sends no RTP (sendonly or inactive) until resumed
+		if heldBy := heldBy(callService.Bridges(), dlg.CallID); heldBy != "" {
+			slog.Info("[App] Ignoring media timeout of a held call",
+				"call_id", dlg.CallID,
+				"session_id", mt.SessionID,
+				"held_by", heldBy,
+			)
+			return
+		}
 
-	slog.Info("SIP handlers registered", "methods", "REGISTER, INVITE, BYE, ACK, CANCEL")
+		// Terminating this leg's dialog sends BYE; the B2BUA bridge then hangs up the peer leg
+		slog.Info("[App] Hanging up call after media timeout",
+			"call_id", dlg.CallID,
+			"session_id", mt.SessionID,
+			"idle", mt.Idle,
+		)
+		if err := dialogMgr.Terminate(dlg.CallID, dialog.ReasonTimeout); err != nil {
+			slog.Warn("[App] Failed to hang up timed out call", "call_id", dlg.CallID, "error", err)
+		}
+	})
+
+	if headers.Enabled() {
+		// First, so responses of every later middleware carry the Server header
+		proxy.Use(headers.Middleware(func(req *sip.Request) string {
+			return middleware.Annotation(req, trunks.Annotation)
+		}))
+	}
+	if shedder != nil {
+		proxy.Use(shedder.Middleware())
+	}
+	proxy.Use(loops.Middleware())
+	if trunkRegistry != nil {
+		proxy.Use(trunkRegistry.Middleware(proxy.peers, proxy.trunkCalls))
+	}
+	if hider.Hides() {
+		// Before the header policy, so headers its rules add are scrubbed too
+		proxy.Use(hider.Middleware(func(req *sip.Request) string {
+			return middleware.Annotation(req, trunks.Annotation)
+		}))
+	}
+	if authenticator != nil {
+		proxy.Use(authenticator.Middleware())
+	}
+	if policy != nil {
+		proxy.Use(policy.Middleware())
+	}
+
+	// Register request handlers behind the middleware chain
+	chain := proxy.middleware
+	uas.OnRequest(sip.REGISTER, chain.Wrap(proxy.handleRegister))
+	uas.OnRequest(sip.INVITE, chain.Wrap(proxy.handleINVITE))
+	uas.OnRequest(sip.BYE, chain.Wrap(proxy.handleBYE))
+	uas.OnRequest(sip.ACK, chain.Wrap(proxy.handleACK))
+	uas.OnRequest(sip.CANCEL, chain.Wrap(proxy.handleCANCEL))
+	uas.OnRequest(sip.SUBSCRIBE, chain.Wrap(proxy.handleSUBSCRIBE))
+
+	slog.Info("SIP handlers registered", "methods", "REGISTER, INVITE, BYE, ACK, CANCEL, SUBSCRIBE")
 	slog.Info("Configuration", "port", cfg.Port, "bind", cfg.BindAddr, "realm", realm)
 
 	return proxy, nil
 }
 
+// Use adds middleware that runs on every inbound SIP request before it
+// is routed, in the order added. Call it before Start.
+func (p *SwitchBoard) Use(middlewares ...middleware.Middleware) {
+	p.middleware.Use(middlewares...)
+	slog.Info("SIP middleware registered", "chain", p.middleware.Names())
+}
+
 func (p *SwitchBoard) Start(ctx context.Context) error {
-	listenAddr := fmt.Sprintf("%s:%d", p.config.BindAddr, p.config.Port)
+	listenAddr := net.JoinHostPort(p.config.BindAddr, strconv.Itoa(p.config.Port))
 	slog.Info("Starting SIP server", "listenAddr", listenAddr)
 
 	// Start API server
@@ -234,14 +818,84 @@ func (p *SwitchBoard) Start(ctx context.Context) error {
 		panic(err)
 	}
 
-	if err := p.srv.ListenAndServe(ctx, "udp", listenAddr); err != nil {
+	// Keep secrets and certificates current and serve SIP over TLS
+	p.secrets.run(ctx, p.config.SecretsRefresh)
+	p.tls.run(ctx, p.config)
+	if p.tls.sip != nil {
+		go p.serveTLS(ctx)
+	}
+
+	// Apply recording retention in the background
+	if p.janitor != nil {
+		go p.janitor.Run(ctx)
+	}
+	if p.alerts != nil {
+		go p.alerts.Run(ctx, p.eventHub)
+	}
+	if p.metrics != nil {
+		go p.metrics.Run(ctx, p.eventHub)
+	}
+	if p.shedder != nil {
+		go p.shedder.Run(ctx)
+	}
+	if p.dialer != nil {
+		go p.dialer.Run(ctx)
+	}
+	go p.callbacks.Run(ctx)
+	if p.agents != nil {
+		go p.agents.Run(ctx)
+	}
+
+	pc, err := net.ListenPacket("udp", listenAddr)
+	if err != nil {
 		slog.Error("Failed to bind to SIP port", "port", p.config.Port, "error", err)
 		panic(err)
 	}
+	p.listening.Store(true)
+	defer p.listening.Store(false)
+	go func() {
+		<-ctx.Done()
+		p.listening.Store(false)
+		_ = pc.Close()
+	}()
+
+	// Answer client keepalives before the SIP stack sees them
+	conn := keepalive.NewConn(pc)
+	if p.config.NATKeepalive > 0 {
+		slog.Info("Sending NAT keepalives to UDP bindings", "interval", p.config.NATKeepalive)
+		go keepalive.NewPinger(conn, p.locationStore, p.config.NATKeepalive).Run(ctx)
+	}
+
+	if err := p.srv.ServeUDP(conn); err != nil && ctx.Err() == nil {
+		slog.Error("SIP server stopped", "error", err)
+		panic(err)
+	}
 
 	return nil
 }
 
+// addReadinessChecks makes /readyz fail until the node can take calls:
+// the SIP socket is served, an RTP manager can take sessions and the
+// location store answers.
+func (p *SwitchBoard) addReadinessChecks(pool *mediaclient.Pool) {
+	checks := p.apiServer.HealthChecker()
+	checks.Add("sip", func(ctx context.Context) error {
+		if !p.listening.Load() {
+			return errors.New("SIP listener not started")
+		}
+		return nil
+	})
+	checks.Add("rtpmanagers", func(ctx context.Context) error {
+		for _, member := range pool.Stats().Members {
+			if member.Healthy && member.DrainState == mediaclient.StateActive {
+				return nil
+			}
+		}
+		return errors.New("no healthy RTP manager accepting sessions")
+	})
+	checks.Add("location", p.locationStore.Ping)
+}
+
 func (p *SwitchBoard) handleRegister(req *sip.Request, tx sip.ServerTransaction) {
 	if err := p.registerHandler.HandleRegister(req, tx); err != nil {
 		slog.Error("Error handling REGISTER", "error", err)
@@ -252,6 +906,10 @@ func (p *SwitchBoard) handleRegister(req *sip.Request, tx sip.ServerTransaction)
 	}
 }
 
+func (p *SwitchBoard) handleSUBSCRIBE(req *sip.Request, tx sip.ServerTransaction) {
+	p.regEvents.HandleSUBSCRIBE(req, tx)
+}
+
 func (p *SwitchBoard) handleINVITE(req *sip.Request, tx sip.ServerTransaction) {
 	p.inviteHandler.HandleINVITE(req, tx)
 }
@@ -268,12 +926,26 @@ func (p *SwitchBoard) handleCANCEL(req *sip.Request, tx sip.ServerTransaction) {
 	p.cancelHandler.HandleCANCEL(req, tx)
 }
 
+// trunkCalls counts the calls in progress from a trunk
+func (p *SwitchBoard) trunkCalls(trunk string) int {
+	n := 0
+	for _, d := range p.dialogMgr.List() {
+		if d.Direction != dialog.DirectionInbound || d.IsTerminated() || d.InviteRequest == nil {
+			continue
+		}
+		if middleware.Annotation(d.InviteRequest, trunks.Annotation) == trunk {
+			n++
+		}
+	}
+	return n
+}
+
 func (p *SwitchBoard) Close() error {
 	// Terminate all active dialogs gracefully
 	dialogs := p.dialogMgr.List()
 	for _, dlg := range dialogs {
 		if !dlg.IsTerminated() {
-			_ = p.dialogMgr.Terminate(dlg.CallID, dialog.ReasonLocalBYE)
+			_ = p.dialogMgr.Terminate(dlg.CallID, dialog.ReasonDrain)
 		}
 	}
 
@@ -292,11 +964,61 @@ func (p *SwitchBoard) Close() error {
 		p.locationStore.Close()
 	}
 
+	if p.loops != nil {
+		p.loops.Close()
+	}
+
 	if p.apiServer != nil {
 		_ = p.apiServer.Stop()
 	}
+	if p.statsd != nil {
+		_ = p.statsd.Close()
+	}
+	if p.secrets != nil {
+		_ = p.secrets.resolver.Close()
+	}
 	if p.ua != nil {
 		return p.ua.Close()
 	}
 	return nil
 }
+
+// applySIPTimers sets the transaction timers of the SIP stack. sipgo
+// derives its timers from T1, T2 and T4; Timer B and F are then replaced
+// by the configured transaction timeouts.
+func applySIPTimers(t config.SIPTimers) {
+	sip.SetTimers(t.T1, t.T2, t.T4)
+	sip.Timer_B = t.InviteTimeout()
+	sip.Timer_F = t.NonInviteTimeout()
+	slog.Info("SIP timers", "t1", t.T1, "t2", t.T2, "t4", t.T4,
+		"timer_b", sip.Timer_B, "timer_f", sip.Timer_F, "ack_timeout", t.AckTimeout())
+}
+
+// heldBy returns who holds the bridged call with a leg of the Call-ID,
+// or "" if it is not on hold
+func heldBy(bridges b2bua.BridgeStore, callID string) string {
+	for _, b := range bridges.List() {
+		if b.LegA().CallID() == callID || b.LegB().CallID() == callID {
+			return b.HeldBy()
+		}
+	}
+	return ""
+}
+
+// newRecordingStore creates the configured recording store and retention policy.
+func newRecordingStore(cfg *config.Config) (recording.Store, recording.RetentionPolicy, error) {
+	policy, err := recording.ParseRetention(cfg.RecordingRetention)
+	if err != nil {
+		return nil, recording.RetentionPolicy{}, err
+	}
+	store, err := recording.NewStore(recording.Config{
+		Backend: cfg.RecordingBackend,
+		Dir:     cfg.RecordingDir,
+		URL:     cfg.RecordingURL,
+		S3:      s3.ConfigFromEnv(),
+	})
+	if err != nil {
+		return nil, recording.RetentionPolicy{}, fmt.Errorf("failed to create recording store: %w", err)
+	}
+	return store, policy, nil
+}
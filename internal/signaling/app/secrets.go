@@ -0,0 +1,55 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebas/switchboard/internal/secrets"
+	"github.com/sebas/switchboard/internal/signaling/config"
+)
+
+// secretsTimeout bounds resolving the secret settings at startup
+const secretsTimeout = 30 * time.Second
+
+// appSecrets are the secret settings, kept current while the server runs
+type appSecrets struct {
+	resolver   *secrets.Resolver
+	debugToken *secrets.Secret
+	files      []*secrets.Secret // Written to files named by the TLS settings
+}
+
+// loadSecrets resolves the secret settings of cfg. TLS file settings
+// that are secret store references are replaced with the paths of the
+// files the secrets are written to, which certificate reloading watches.
+func loadSecrets(cfg *config.Config) (*appSecrets, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), secretsTimeout)
+	defer cancel()
+
+	s := &appSecrets{resolver: secrets.New(secrets.ConfigFromEnv(), nil)}
+	var err error
+	if s.debugToken, err = s.resolver.Load(ctx, "debug-token", cfg.DebugToken); err != nil {
+		return nil, err
+	}
+	s.files, err = s.resolver.Files(ctx, cfg.SecretsDir, map[string]*string{
+		"tls-cert":            &cfg.TLSCertFile,
+		"tls-key":             &cfg.TLSKeyFile,
+		"rtpmanager-tls-cert": &cfg.RTPManagerTLSCert,
+		"rtpmanager-tls-key":  &cfg.RTPManagerTLSKey,
+		"rtpmanager-tls-ca":   &cfg.RTPManagerTLSCA,
+	})
+	if err != nil {
+		_ = s.resolver.Close()
+		return nil, fmt.Errorf("TLS files: %w", err)
+	}
+	for _, f := range s.files {
+		slog.Info("[Secrets] Loaded from secret store", "secret", f.Name())
+	}
+	return s, nil
+}
+
+// run refreshes the secrets until ctx is done
+func (s *appSecrets) run(ctx context.Context, interval time.Duration) {
+	go secrets.Watch(ctx, interval, append([]*secrets.Secret{s.debugToken}, s.files...)...)
+}
@@ -0,0 +1,106 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+
+	"github.com/sebas/switchboard/internal/certs"
+	"github.com/sebas/switchboard/internal/signaling/config"
+)
+
+// tlsCerts holds the certificates of the SIP-TLS listener, the HTTPS API
+// and the connections to the RTP managers
+type tlsCerts struct {
+	sip   certs.Source   // nil if SIP-TLS is not configured
+	acme  *certs.ACME    // Set when sip is obtained through ACME
+	rtp   *tls.Config    // nil connects to the RTP managers in plaintext
+	files []*certs.Files // Reloaded when they change
+}
+
+// loadTLS reads the configured certificates
+func loadTLS(cfg *config.Config) (*tlsCerts, error) {
+	t := &tlsCerts{}
+	switch {
+	case cfg.TLSCertFile != "" && len(cfg.ACMEDomains) > 0:
+		return nil, errors.New("set --tls-cert or --acme-domains, not both")
+	case cfg.TLSCertFile != "":
+		f, err := certs.LoadFiles(cfg.TLSCertFile, cfg.TLSKeyFile, "")
+		if err != nil {
+			return nil, err
+		}
+		t.sip = f
+		t.files = append(t.files, f)
+	case len(cfg.ACMEDomains) > 0:
+		a, err := certs.NewACME(certs.ACMEConfig{
+			Domains:      cfg.ACMEDomains,
+			Email:        cfg.ACMEEmail,
+			CacheDir:     cfg.ACMECacheDir,
+			DirectoryURL: cfg.ACMEDirectory,
+		})
+		if err != nil {
+			return nil, err
+		}
+		t.sip, t.acme = a, a
+	}
+	if cfg.APITLS && t.sip == nil {
+		return nil, errors.New("--api-tls needs --tls-cert or --acme-domains")
+	}
+
+	if cfg.RTPManagerTLS() {
+		f, err := certs.LoadFiles(cfg.RTPManagerTLSCert, cfg.RTPManagerTLSKey, cfg.RTPManagerTLSCA)
+		if err != nil {
+			return nil, fmt.Errorf("RTP manager TLS: %w", err)
+		}
+		t.rtp = f.ClientConfig()
+		t.files = append(t.files, f)
+	}
+	return t, nil
+}
+
+// run keeps the certificates current until ctx is done
+func (t *tlsCerts) run(ctx context.Context, cfg *config.Config) {
+	for _, f := range t.files {
+		go f.Watch(ctx, cfg.CertReloadInterval)
+	}
+	if t.acme != nil {
+		go func() {
+			if err := t.acme.ServeChallenges(ctx, cfg.ACMEHTTPAddr); err != nil {
+				slog.Error("[TLS] ACME challenge server stopped", "addr", cfg.ACMEHTTPAddr, "error", err)
+			}
+		}()
+		go t.acme.Prefetch()
+	}
+}
+
+// serveTLS serves SIP over TLS until ctx is done
+func (p *SwitchBoard) serveTLS(ctx context.Context) {
+	listenAddr := net.JoinHostPort(p.config.BindAddr, strconv.Itoa(p.config.TLSPort))
+	cfg := p.tls.sip.ServerConfig()
+	if p.trunks != nil {
+		// Trunks are identified by their certificate; other clients need none
+		cfg = cfg.Clone()
+		cfg.ClientAuth = tls.RequestClientCert
+	}
+	l, err := tls.Listen("tcp", listenAddr, cfg)
+	if err != nil {
+		slog.Error("Failed to bind to SIP-TLS port", "port", p.config.TLSPort, "error", err)
+		return
+	}
+	if p.trunks != nil {
+		l = p.peers.Listen(l)
+	}
+	go func() {
+		<-ctx.Done()
+		_ = l.Close()
+	}()
+
+	slog.Info("Starting SIP-TLS server", "listenAddr", listenAddr)
+	if err := p.srv.ServeTLS(l); err != nil && ctx.Err() == nil {
+		slog.Error("SIP-TLS server stopped", "error", err)
+	}
+}
@@ -10,8 +10,13 @@ import (
 
 	"github.com/google/uuid"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
 )
 
+// HeldLocally is Bridge.HeldBy for a leg this server put on hold rather
+// than the other party
+const HeldLocally = "local"
+
 // Bridge connects two call legs for bidirectional media exchange.
 //
 // A Bridge is created after both legs reach Answered state.
@@ -57,6 +62,33 @@ type Bridge interface {
 	// Returns immediately if already terminated.
 	WaitForTermination(ctx context.Context) (TerminationCause, error)
 
+	// --- Hold ---
+
+	// Hold records that a leg ("leg_a" or "leg_b") put the call on hold.
+	// With music, media is unbridged and the files are played in a loop
+	// to the other leg until Resume; without, the hold was passed on to
+	// the other leg and is only tracked. If the music cannot be played the
+	// call is held without it. Holding a held call is a no-op.
+	Hold(ctx context.Context, by string, music []string) error
+
+	// HoldLeg records that this server put a leg ("leg_a" or "leg_b") on
+	// hold, e.g. for an operator. With music, media is unbridged and the
+	// files are played in a loop to that leg until Resume, or the leg is
+	// held without music if they cannot be played. HeldBy is then
+	// HeldLocally.
+	HoldLeg(ctx context.Context, leg string, music []string) error
+
+	// Resume ends the hold: the music is stopped and media bridged again.
+	Resume(ctx context.Context) error
+
+	// HeldBy returns the leg holding the call, HeldLocally, or "" if it
+	// is not on hold.
+	HeldBy() string
+
+	// HeldLeg returns the leg on hold, the one hearing the music, or ""
+	// if the call is not on hold.
+	HeldLeg() string
+
 	// --- Event Callbacks ---
 
 	// OnTerminated registers a callback for bridge termination.
@@ -70,17 +102,28 @@ type BridgeInfo struct {
 	ID string `json:"id"`
 
 	// Legs
-	LegAID string `json:"leg_a_id"`
-	LegBID string `json:"leg_b_id"`
+	LegAID     string `json:"leg_a_id"`
+	LegBID     string `json:"leg_b_id"`
+	LegACallID string `json:"leg_a_call_id,omitempty"`
+	LegBCallID string `json:"leg_b_call_id,omitempty"`
 
 	// State
 	State            BridgeState      `json:"state"`
 	TerminationCause TerminationCause `json:"termination_cause,omitempty"`
 	TerminatedBy     string           `json:"terminated_by,omitempty"` // "leg_a", "leg_b", or "local"
+	// Reason header (RFC 3326) of the BYE the leg that hung up sent
+	HangupReason sipreason.Reason `json:"hangup_reason,omitzero"`
 
 	// Media
 	Codec              string `json:"codec,omitempty"`
 	TranscodingEnabled bool   `json:"transcoding_enabled,omitempty"`
+	MediaBridgeID      string `json:"media_bridge_id,omitempty"` // Bridge ID on the RTP manager
+
+	// Hold
+	HeldBy    string    `json:"held_by,omitempty"`  // "leg_a", "leg_b" or "local" while on hold
+	HeldLeg   string    `json:"held_leg,omitempty"` // The other leg, or the one held locally
+	HeldAt    time.Time `json:"held_at,omitempty"`
+	HoldMusic bool      `json:"hold_music,omitempty"` // Music is played to the other leg
 
 	// Timing
 	CreatedAt    time.Time `json:"created_at"`
@@ -142,6 +185,7 @@ type bridgeImpl struct {
 	state            BridgeState
 	terminationCause TerminationCause
 	terminatedBy     string // "leg_a", "leg_b", or "local"
+	hangupReason     sipreason.Reason
 
 	// Media
 	codec              string
@@ -149,6 +193,14 @@ type bridgeImpl struct {
 	transport          mediaclient.Transport // RTP Manager transport for media bridging
 	mediaBridgeID      string                // RTP Manager bridge ID
 
+	// Hold
+	heldBy     string // "leg_a", "leg_b" or HeldLocally
+	heldLeg    string // "leg_a" or "leg_b"
+	heldAt     time.Time
+	holdMusic  string             // Session playing music on hold, if any
+	holdCancel context.CancelFunc // Ends the music's status stream
+	holdDone   chan struct{}
+
 	// Timing
 	createdAt    time.Time
 	startedAt    time.Time
@@ -261,11 +313,19 @@ func (b *bridgeImpl) Info() *BridgeInfo {
 		ID:                 b.id,
 		LegAID:             b.legA.ID(),
 		LegBID:             b.legB.ID(),
+		LegACallID:         b.legA.CallID(),
+		LegBCallID:         b.legB.CallID(),
 		State:              b.state,
 		TerminationCause:   b.terminationCause,
 		TerminatedBy:       b.terminatedBy,
+		HangupReason:       b.hangupReason,
 		Codec:              b.codec,
 		TranscodingEnabled: b.transcodingEnabled,
+		MediaBridgeID:      b.mediaBridgeID,
+		HeldBy:             b.heldBy,
+		HeldLeg:            b.heldLeg,
+		HeldAt:             b.heldAt,
+		HoldMusic:          b.holdMusic != "",
 		CreatedAt:          b.createdAt,
 		StartedAt:          b.startedAt,
 		TerminatedAt:       b.terminatedAt,
@@ -324,6 +384,9 @@ func (b *bridgeImpl) Start(ctx context.Context) error {
 
 	b.state = BridgeStateActive
 	b.startedAt = time.Now()
+	if b.codec = b.legB.Info().NegotiatedCodec; b.codec == "" {
+		b.codec = b.legA.Info().NegotiatedCodec
+	}
 
 	// Note: Leg termination monitoring is set up in NewBridge() to avoid race conditions
 	// where a leg terminates before Start() is called.
@@ -347,8 +410,15 @@ func (b *bridgeImpl) Stop(hangupLegs bool) error {
 	b.state = BridgeStateTerminating
 	mediaBridgeID := b.mediaBridgeID
 	transport := b.transport
+	holdCancel := b.holdCancel
+	b.holdMusic, b.holdCancel = "", nil
 	b.mu.Unlock()
 
+	// Hold music ends with the legs' sessions
+	if holdCancel != nil {
+		holdCancel()
+	}
+
 	// Unbridge media at RTP Manager level
 	if transport != nil && mediaBridgeID != "" {
 		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
@@ -455,6 +525,145 @@ func (b *bridgeImpl) Stop(hangupLegs bool) error {
 	return nil
 }
 
+// --- Hold ---
+
+func (b *bridgeImpl) Hold(ctx context.Context, by string, music []string) error {
+	held := "leg_b"
+	if by == "leg_b" {
+		held = "leg_a"
+	}
+	return b.hold(ctx, by, held, music)
+}
+
+func (b *bridgeImpl) HoldLeg(ctx context.Context, leg string, music []string) error {
+	if leg != "leg_a" && leg != "leg_b" {
+		return fmt.Errorf("%w: %q", ErrUnknownLeg, leg)
+	}
+	return b.hold(ctx, HeldLocally, leg, music)
+}
+
+// hold puts leg held on hold on behalf of by, playing the music to it
+func (b *bridgeImpl) hold(ctx context.Context, by, held string, music []string) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.state != BridgeStateActive {
+		return ErrInvalidState
+	}
+	if b.heldBy != "" {
+		return nil
+	}
+
+	if len(music) > 0 && b.transport != nil {
+		leg := b.legB
+		if held == "leg_a" {
+			leg = b.legA
+		}
+		if err := b.playHoldMusic(ctx, leg.SessionID(), music); err != nil {
+			// The leg is on hold either way; Resume must find it held
+			slog.Warn("[Bridge] Holding without music", "bridge_id", b.id, "held_leg", held, "error", err)
+		}
+	}
+	b.heldBy, b.heldLeg = by, held
+	b.heldAt = time.Now()
+
+	slog.Info("[Bridge] On hold",
+		"bridge_id", b.id,
+		"held_by", by,
+		"held_leg", held,
+		"music", b.holdMusic != "",
+	)
+	return nil
+}
+
+// playHoldMusic unbridges media and loops the music on a leg's session.
+// Caller must hold b.mu.
+func (b *bridgeImpl) playHoldMusic(ctx context.Context, sessionID string, music []string) error {
+	if b.mediaBridgeID != "" {
+		if err := b.transport.UnbridgeMedia(ctx, b.mediaBridgeID); err != nil {
+			return fmt.Errorf("unbridge media: %w", err)
+		}
+		b.mediaBridgeID = ""
+	}
+
+	playCtx, cancel := context.WithCancel(context.Background())
+	statusCh, err := b.transport.PlayAudio(playCtx, mediaclient.PlayRequest{
+		SessionID: sessionID,
+		AudioFile: music[0],
+		Playlist:  music[1:],
+		Loop:      true,
+	})
+	if err != nil {
+		cancel()
+		// Hold without music rather than leave the legs apart
+		if id, berr := b.transport.BridgeMedia(ctx, b.legA.SessionID(), b.legB.SessionID()); berr == nil {
+			b.mediaBridgeID = id
+		}
+		return fmt.Errorf("play hold music: %w", err)
+	}
+
+	b.holdMusic = sessionID
+	b.holdCancel = cancel
+	b.holdDone = make(chan struct{})
+	go func(done chan struct{}) {
+		defer close(done)
+		for range statusCh {
+		}
+	}(b.holdDone)
+	return nil
+}
+
+func (b *bridgeImpl) Resume(ctx context.Context) error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.heldBy == "" {
+		return nil
+	}
+	by := b.heldBy
+	b.heldBy, b.heldLeg = "", ""
+	b.heldAt = time.Time{}
+
+	if b.holdMusic != "" {
+		if err := b.transport.StopAudio(ctx, b.holdMusic); err != nil {
+			slog.Debug("[Bridge] Hold music stop",
+				"bridge_id", b.id,
+				"session_id", b.holdMusic,
+				"error", err,
+			)
+		}
+		b.holdCancel()
+		<-b.holdDone
+		b.holdMusic, b.holdCancel = "", nil
+
+		if b.state == BridgeStateActive {
+			bridgeID, err := b.transport.BridgeMedia(ctx, b.legA.SessionID(), b.legB.SessionID())
+			if err != nil {
+				return fmt.Errorf("bridge media: %w", err)
+			}
+			b.mediaBridgeID = bridgeID
+		}
+	}
+
+	slog.Info("[Bridge] Resumed",
+		"bridge_id", b.id,
+		"held_by", by,
+	)
+	return nil
+}
+
+func (b *bridgeImpl) HeldBy() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.heldBy
+}
+
+func (b *bridgeImpl) HeldLeg() string {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	return b.heldLeg
+}
+
 func (b *bridgeImpl) WaitForTermination(ctx context.Context) (TerminationCause, error) {
 	b.mu.RLock()
 	if b.state == BridgeStateTerminated {
@@ -501,6 +710,12 @@ func (b *bridgeImpl) handleLegTerminated(legName string, cause TerminationCause)
 		"cause", cause.String(),
 	)
 
+	leg := b.legA
+	if legName == "leg_b" {
+		leg = b.legB
+	}
+	reason := leg.RemoteReason()
+
 	b.mu.Lock()
 	if b.state == BridgeStateTerminated || b.state == BridgeStateTerminating {
 		slog.Debug("[Bridge] handleLegTerminated skipping - already terminating",
@@ -516,6 +731,7 @@ func (b *bridgeImpl) handleLegTerminated(legName string, cause TerminationCause)
 	b.state = BridgeStateTerminating
 	b.terminatedBy = legName
 	b.terminationCause = TerminationCauseBridgePeer
+	b.hangupReason = reason
 	b.mu.Unlock()
 
 	slog.Info("[Bridge] Leg terminated",
@@ -0,0 +1,95 @@
+package b2bua
+
+import (
+	"sort"
+	"sync"
+)
+
+// BridgeStore tracks the bridges in progress so they can be listed and
+// looked up outside the call flow that created them.
+//
+// Thread Safety: All methods are safe for concurrent use.
+type BridgeStore interface {
+	// Add tracks a bridge until it terminates.
+	Add(b Bridge)
+
+	// Remove stops tracking a bridge. Unknown IDs are ignored.
+	Remove(id string)
+
+	// Get returns the bridge with the ID, if tracked.
+	Get(id string) (Bridge, bool)
+
+	// List returns the tracked bridges, oldest first.
+	List() []Bridge
+
+	// Count returns the number of tracked bridges.
+	Count() int
+}
+
+// memBridgeStore is the in-memory BridgeStore.
+type memBridgeStore struct {
+	mu      sync.RWMutex
+	bridges map[string]Bridge
+}
+
+// NewBridgeStore creates an empty in-memory BridgeStore.
+func NewBridgeStore() BridgeStore {
+	return &memBridgeStore{bridges: make(map[string]Bridge)}
+}
+
+// Add implements BridgeStore.Add. The bridge is removed when it
+// terminates.
+func (s *memBridgeStore) Add(b Bridge) {
+	s.mu.Lock()
+	s.bridges[b.ID()] = b
+	s.mu.Unlock()
+
+	b.OnTerminated(func(TerminationCause) {
+		s.Remove(b.ID())
+	})
+	// Callbacks registered after termination never run
+	if b.GetState() == BridgeStateTerminated {
+		s.Remove(b.ID())
+	}
+}
+
+// Remove implements BridgeStore.Remove
+func (s *memBridgeStore) Remove(id string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.bridges, id)
+}
+
+// Get implements BridgeStore.Get
+func (s *memBridgeStore) Get(id string) (Bridge, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	b, ok := s.bridges[id]
+	return b, ok
+}
+
+// List implements BridgeStore.List
+func (s *memBridgeStore) List() []Bridge {
+	s.mu.RLock()
+	bridges := make([]Bridge, 0, len(s.bridges))
+	for _, b := range s.bridges {
+		bridges = append(bridges, b)
+	}
+	s.mu.RUnlock()
+
+	created := make(map[string]int64, len(bridges))
+	for _, b := range bridges {
+		created[b.ID()] = b.Info().CreatedAt.UnixNano()
+	}
+	sort.Slice(bridges, func(i, j int) bool {
+		return created[bridges[i].ID()] < created[bridges[j].ID()]
+	})
+	return bridges
+}
+
+// Count implements BridgeStore.Count
+func (s *memBridgeStore) Count() int {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return len(s.bridges)
+}
@@ -7,12 +7,16 @@ import (
 
 	"github.com/emiago/sipgo/sip"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
 )
 
 // callService is the concrete implementation of CallService.
 type callService struct {
 	cfg        CallServiceConfig
 	originator *Originator
+	bridges    BridgeStore
+	pickups    pickups
+	holds      holds
 }
 
 // NewCallService creates a new CallService instance.
@@ -21,19 +25,34 @@ func NewCallService(cfg CallServiceConfig) CallService {
 	if cfg.DefaultDialTimeout == 0 {
 		cfg.DefaultDialTimeout = 30 * time.Second
 	}
+	if cfg.ConfirmTimeout == 0 {
+		cfg.ConfirmTimeout = 10 * time.Second
+	}
 
 	origCfg := OriginatorConfig{
 		AdvertiseAddr: cfg.AdvertiseAddr,
+		Advertise:     cfg.Advertise,
 		Port:          cfg.Port,
 		Transport:     cfg.Transport,
 		Client:        cfg.Client,
 		LocalContact:  cfg.LocalContact,
 		DialogManager: cfg.DialogManager,
+		HeaderPolicy:  cfg.HeaderPolicy,
+		Identity:      cfg.Identity,
+		Codecs:        cfg.Codecs,
+		LoopDetector:  cfg.LoopDetector,
+		LoadMonitor:   cfg.LoadMonitor,
+		KPIRecorder:   cfg.KPIRecorder,
+		InviteTimeout: cfg.InviteTimeout,
+		Video:         cfg.Video,
 	}
 
 	return &callService{
 		cfg:        cfg,
 		originator: NewOriginator(origCfg),
+		bridges:    NewBridgeStore(),
+		pickups:    pickups{calls: make(map[string]*pickup)},
+		holds:      holds{bridges: make(map[string]bool)},
 	}
 }
 
@@ -66,7 +85,6 @@ func (s *callService) CreateOutboundLeg(ctx context.Context, target *LookupResul
 	result, err := s.originator.Originate(ctx, OriginateRequest{
 		Target:  target,
 		Timeout: s.cfg.DefaultDialTimeout,
-		Codecs:  []string{"0"}, // Default to PCMU
 	})
 	if err != nil {
 		return nil, err
@@ -91,7 +109,17 @@ func (s *callService) CreateBridge(legA, legB Leg, opts ...BridgeOption) (Bridge
 	if s.cfg.Transport != nil {
 		opts = append([]BridgeOption{WithTransport(s.cfg.Transport)}, opts...)
 	}
-	return NewBridge(legA, legB, opts...)
+	bridge, err := NewBridge(legA, legB, opts...)
+	if err != nil {
+		return nil, err
+	}
+	s.bridges.Add(bridge)
+	return bridge, nil
+}
+
+// Bridges returns the store of bridges created by CreateBridge.
+func (s *callService) Bridges() BridgeStore {
+	return s.bridges
 }
 
 // --- High-Level Operations ---
@@ -127,11 +155,14 @@ func (s *callService) Dial(ctx context.Context, target string, timeout time.Dura
 	origResult, err := s.originator.Originate(dialCtx, OriginateRequest{
 		Target:        result,
 		Timeout:       timeout,
-		Codecs:        []string{"0"},
 		CallerID:      legOpts.callerID,
 		CallerName:    legOpts.callerName,
 		ALegSessionID: legOpts.aLegSessionID,
 		ALegCallID:    legOpts.aLegCallID,
+		ALegInvite:    legOpts.aLegInvite,
+		OnProgress:    legOpts.onProgress,
+		Headers:       legOpts.headers,
+		CallID:        legOpts.callID,
 	})
 	if err != nil {
 		return nil, err
@@ -179,6 +210,16 @@ func (s *callService) DialAndBridge(ctx context.Context, legA Leg, target string
 		"timeout", timeout,
 	)
 
+	return s.dialAndBridge(ctx, legA, opts, func(ctx context.Context, opts []LegOption) (Leg, error) {
+		return s.Dial(ctx, target, timeout, opts...)
+	})
+}
+
+// dialAndBridge runs dial to get an answered B-leg, with ringback and
+// early media for the A-leg while it rings, then bridges the two legs and
+// blocks until the bridge terminates. While dial runs, the call may be
+// picked up (see Pickup); the picker's leg is bridged instead.
+func (s *callService) dialAndBridge(ctx context.Context, legA Leg, opts []LegOption, dial func(ctx context.Context, opts []LegOption) (Leg, error)) (*BridgeInfo, error) {
 	// Step 1: Dial target (pass through options for CallerID, etc.)
 	// Prepend A-leg session ID and Call-ID so B-leg:
 	// - Is created on the same RTP manager (for bridging)
@@ -187,7 +228,34 @@ func (s *callService) DialAndBridge(ctx context.Context, legA Leg, target string
 		WithALegSessionID(legA.SessionID()),
 		WithALegCallID(legA.CallID()),
 	}, opts...)
-	legB, err := s.Dial(ctx, target, timeout, opts...)
+	// Play ringback to the caller while the callee rings. If the callee
+	// sends early media instead, ringback stops and the callee's audio is
+	// relayed to the caller. Both end before the answered legs are bridged.
+	var rb *ringback
+	var early *earlyBridge
+	if s.cfg.Ringback {
+		rb = newRingback(s.cfg.Transport, legA.SessionID())
+	}
+	if s.cfg.EarlyMedia {
+		early = newEarlyBridge(s.cfg.Transport, legA.SessionID())
+	}
+	if rb != nil || early != nil {
+		opts = append(opts, WithProgressHandler(func(legB Leg, state LegState) {
+			if rb != nil {
+				rb.progress(legB, state)
+			}
+			if early != nil {
+				early.progress(legB, state)
+			}
+		}))
+	}
+	legB, picked, err := s.dialOrPickup(ctx, legA, opts, dial)
+	if rb != nil {
+		rb.stop()
+	}
+	if early != nil {
+		early.stop()
+	}
 	if err != nil {
 		return nil, err
 	}
@@ -195,8 +263,19 @@ func (s *callService) DialAndBridge(ctx context.Context, legA Leg, target string
 	slog.Info("[CallService] B leg answered",
 		"leg_a", legA.ID(),
 		"leg_b", legB.ID(),
+		"picked_up", picked != nil,
 	)
 
+	info, err := s.bridgeAnswered(ctx, legA, legB)
+	if picked != nil {
+		picked.result <- pickupResult{info: info, err: err}
+	}
+	return info, err
+}
+
+// bridgeAnswered bridges legA with legB, which answered for it, and
+// blocks until the bridge terminates.
+func (s *callService) bridgeAnswered(ctx context.Context, legA, legB Leg) (*BridgeInfo, error) {
 	// Step 2: Create bridge
 	bridge, err := s.CreateBridge(legA, legB, WithAutoHangup(true))
 	if err != nil {
@@ -216,6 +295,12 @@ func (s *callService) DialAndBridge(ctx context.Context, legA Leg, target string
 		"leg_b", legB.ID(),
 	)
 
+	// The A-leg was answered with its other streams rejected before the
+	// B-leg was dialed; offer it those the B-leg answered
+	if s.cfg.Video == sdpmedia.Passthrough {
+		s.relayAnswered(legA, legB)
+	}
+
 	// Step 4: Wait for bridge to terminate
 	// Use the A-leg's context for bridge wait, NOT the dial timeout context.
 	// The dial timeout (ctx) should only apply to the dial phase.
@@ -236,12 +321,6 @@ func (s *callService) DialAndBridge(ctx context.Context, legA Leg, target string
 	return bridge.Info(), nil
 }
 
-// --- Ring Group Support (Future) ---
-
-func (s *callService) DialParallel(ctx context.Context, targets []*LookupResult, timeout time.Duration, opts ...LegOption) (Leg, error) {
-	return nil, ErrNotImplemented
-}
-
 // --- B-leg BYE Handling ---
 
 // HandleIncomingBYE delegates to the originator to handle BYE for outbound legs.
@@ -249,6 +328,16 @@ func (s *callService) HandleIncomingBYE(req *sip.Request, tx sip.ServerTransacti
 	return s.originator.HandleIncomingBYE(req, tx)
 }
 
+// HasLeg reports whether the originator still tracks the outbound leg.
+func (s *callService) HasLeg(callID string) bool {
+	return s.originator.GetLegByCallID(callID) != nil
+}
+
+// OutboundLegs returns the outbound legs the originator tracks.
+func (s *callService) OutboundLegs() []*LegInfo {
+	return s.originator.Legs()
+}
+
 // GetBridgeMapper returns the originator as a BridgeMapper for drain migration.
 func (s *callService) GetBridgeMapper() BridgeMapper {
 	return s.originator
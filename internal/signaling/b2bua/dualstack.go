@@ -0,0 +1,146 @@
+package b2bua
+
+import (
+	"context"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+const (
+	// familyFallbackDelay is how long an INVITE may go unanswered before the
+	// next address family is tried (RFC 8305 style, lengthened for UDP SIP
+	// where the first response is a 100 Trying rather than a handshake)
+	familyFallbackDelay = time.Second
+
+	// resolveTimeout bounds the A/AAAA lookup for a dial target
+	resolveTimeout = 2 * time.Second
+
+	// familyMemory is how long a host's last responsive family is preferred
+	familyMemory = 10 * time.Minute
+)
+
+// familyPreference remembers which address family last answered for a
+// host, so later dials try it first.
+type familyPreference struct {
+	mu    sync.Mutex
+	hosts map[string]familyEntry
+}
+
+type familyEntry struct {
+	ipv6 bool
+	at   time.Time
+}
+
+func newFamilyPreference() *familyPreference {
+	return &familyPreference{hosts: make(map[string]familyEntry)}
+}
+
+// preferIPv6 reports whether host should be dialed over IPv6 first.
+// Hosts without a recent answer default to IPv6 (RFC 8305).
+func (f *familyPreference) preferIPv6(host string) bool {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if e, ok := f.hosts[host]; ok && time.Since(e.at) < familyMemory {
+		return e.ipv6
+	}
+	return true
+}
+
+// answered records that host responded on addr.
+func (f *familyPreference) answered(host, addr string) {
+	h, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return
+	}
+	ip := net.ParseIP(h)
+	if ip == nil {
+		return
+	}
+	f.mu.Lock()
+	f.hosts[host] = familyEntry{ipv6: ip.To4() == nil, at: time.Now()}
+	f.mu.Unlock()
+}
+
+// dialTargets returns the destinations to try for a request URI. IPv6
+// literals get an explicit destination (sipgo cannot resolve a bracketed
+// host). Hostnames with both A and AAAA records get addresses of both
+// families interleaved, preferred family first. Otherwise nil is returned
+// and the SIP stack resolves the host itself, including SRV.
+func (o *Originator) dialTargets(ctx context.Context, uri sip.Uri) []string {
+	port := uri.Port
+	if port == 0 {
+		transport := "udp"
+		if t, ok := uri.UriParams.Get("transport"); ok {
+			transport = t
+		}
+		port = sip.DefaultPort(transport)
+	}
+
+	host := strings.Trim(uri.Host, "[]")
+	if ip := net.ParseIP(host); ip != nil {
+		if ip.To4() == nil {
+			return []string{net.JoinHostPort(host, strconv.Itoa(port))}
+		}
+		return nil
+	}
+	if host == "" || uri.Port == 0 {
+		// Portless hostnames may use SRV records; leave them to the stack
+		return nil
+	}
+
+	lookupCtx, cancel := context.WithTimeout(ctx, resolveTimeout)
+	defer cancel()
+	addrs, err := net.DefaultResolver.LookupIPAddr(lookupCtx, host)
+	if err != nil {
+		return nil
+	}
+
+	var v4, v6 []string
+	for _, a := range addrs {
+		hostPort := net.JoinHostPort(a.IP.String(), strconv.Itoa(port))
+		if a.IP.To4() != nil {
+			v4 = append(v4, hostPort)
+		} else {
+			v6 = append(v6, hostPort)
+		}
+	}
+	if len(v4) == 0 || len(v6) == 0 {
+		return nil
+	}
+
+	first, second := v6, v4
+	if !o.families.preferIPv6(host) {
+		first, second = v4, v6
+	}
+	targets := make([]string, 0, len(addrs))
+	for i := 0; i < len(first) || i < len(second); i++ {
+		if i < len(first) {
+			targets = append(targets, first[i])
+		}
+		if i < len(second) {
+			targets = append(targets, second[i])
+		}
+	}
+	return targets
+}
+
+// addIPv6Via adds a Via with a bracketed sent-by host. sipgo fills Via from
+// the socket address, which it does not bracket, so IPv6 requests set it here.
+func addIPv6Via(req *sip.Request, host string, port int) {
+	via := &sip.ViaHeader{
+		ProtocolName:    "SIP",
+		ProtocolVersion: "2.0",
+		Transport:       req.Transport(),
+		Host:            host,
+		Port:            port,
+		Params:          sip.NewParams(),
+	}
+	via.Params.Add("branch", sip.GenerateBranchN(16))
+	via.Params.Add("rport", "")
+	req.PrependHeader(via)
+}
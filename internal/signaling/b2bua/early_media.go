@@ -0,0 +1,79 @@
+package b2bua
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// earlyBridge relays an outbound leg's early media to the A-leg before
+// answer. The media bridge is created when the B-leg reports early media
+// and removed when the dial ends; the answered legs are then bridged anew
+// against the endpoints from the 2xx.
+type earlyBridge struct {
+	transport mediaclient.Transport
+	sessionID string // A-leg media session
+
+	mu       sync.Mutex
+	bridgeID string
+	stopped  bool
+}
+
+func newEarlyBridge(transport mediaclient.Transport, sessionID string) *earlyBridge {
+	return &earlyBridge{
+		transport: transport,
+		sessionID: sessionID,
+	}
+}
+
+// progress is the B-leg progress handler.
+func (e *earlyBridge) progress(legB Leg, state LegState) {
+	if state != LegStateEarlyMedia {
+		return
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.bridgeID != "" || e.stopped || e.transport == nil || e.sessionID == "" || legB.SessionID() == "" {
+		return
+	}
+
+	bridgeID, err := e.transport.BridgeMedia(context.Background(), e.sessionID, legB.SessionID())
+	if err != nil {
+		slog.Warn("[CallService] Early media bridge failed",
+			"session_a", e.sessionID,
+			"session_b", legB.SessionID(),
+			"error", err,
+		)
+		return
+	}
+	e.bridgeID = bridgeID
+
+	slog.Info("[CallService] Early media bridged",
+		"bridge_id", bridgeID,
+		"session_a", e.sessionID,
+		"session_b", legB.SessionID(),
+	)
+}
+
+// stop removes the early media bridge. Later progress is ignored.
+func (e *earlyBridge) stop() {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.stopped = true
+	if e.bridgeID == "" {
+		return
+	}
+
+	if err := e.transport.UnbridgeMedia(context.Background(), e.bridgeID); err != nil {
+		slog.Warn("[CallService] Early media unbridge failed",
+			"bridge_id", e.bridgeID,
+			"error", err,
+		)
+	}
+	e.bridgeID = ""
+}
@@ -16,12 +16,21 @@ var (
 	// ErrLegNotAnswered indicates an operation requiring answered state.
 	ErrLegNotAnswered = errors.New("leg not in answered state")
 
+	// ErrLegNotFound indicates no leg of an active call has the Call-ID given.
+	ErrLegNotFound = errors.New("leg not found")
+
 	// ErrLegTerminated indicates the leg has already terminated.
 	ErrLegTerminated = errors.New("leg already terminated")
 
 	// ErrBridgeActive indicates the bridge is already active.
 	ErrBridgeActive = errors.New("bridge already active")
 
+	// ErrBridgeNotFound indicates no active bridge has the ID given.
+	ErrBridgeNotFound = errors.New("bridge not found")
+
+	// ErrUnknownLeg indicates a bridge leg other than "leg_a" or "leg_b".
+	ErrUnknownLeg = errors.New("unknown leg (want leg_a or leg_b)")
+
 	// ErrBridgeTerminated indicates the bridge has already terminated.
 	ErrBridgeTerminated = errors.New("bridge already terminated")
 
@@ -31,6 +40,15 @@ var (
 	// ErrDialCanceled indicates the dial was canceled.
 	ErrDialCanceled = errors.New("dial canceled")
 
+	// ErrAnsweredElsewhere cancels the legs of a fork another leg won.
+	ErrAnsweredElsewhere = errors.New("answered elsewhere")
+
+	// ErrNotConfirmed indicates the callee answered but did not accept the call.
+	ErrNotConfirmed = errors.New("call not accepted")
+
+	// ErrNoRingingCall indicates no call to pick up is ringing the callees.
+	ErrNoRingingCall = errors.New("no ringing call to pick up")
+
 	// ErrNotImplemented indicates a feature is not yet implemented.
 	ErrNotImplemented = errors.New("not implemented")
 
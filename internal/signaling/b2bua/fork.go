@@ -0,0 +1,232 @@
+package b2bua
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// confirmTone is played to callees that must accept a call when no
+// ConfirmPrompt is configured: a short beep every two seconds.
+const confirmTone = "1400/200,0/1800"
+
+// confirmDigit is the digit a callee presses to accept a call.
+const confirmDigit = "1"
+
+// ForkTarget is one destination of a parallel dial.
+type ForkTarget struct {
+	// Target is the dial target, as for Dial.
+	Target string
+
+	// Timeout is how long this target rings (0 or longer than the fork
+	// timeout: the fork timeout).
+	Timeout time.Duration
+
+	// Confirm requires the callee to press 1 after answering before the
+	// call is connected, so a mobile's voicemail cannot take the call.
+	Confirm bool
+
+	// Headers are added to this target's INVITE only, e.g. a Diversion
+	// header for follow-me destinations.
+	Headers map[string]string
+}
+
+// forkResult is the outcome of dialing one fork target.
+type forkResult struct {
+	index int
+	leg   Leg
+	err   error
+}
+
+func (s *callService) DialParallel(ctx context.Context, targets []ForkTarget, timeout time.Duration, opts ...LegOption) (Leg, error) {
+	if len(targets) == 0 {
+		return nil, &DialError{Cause: ErrNoContacts}
+	}
+	if timeout == 0 {
+		timeout = s.cfg.DefaultDialTimeout
+	}
+
+	var legOpts legOptions
+	for _, opt := range opts {
+		opt(&legOpts)
+	}
+
+	slog.Info("[CallService] Fork starting",
+		"targets", len(targets),
+		"timeout", timeout,
+	)
+
+	// Canceling the fork sends CANCEL to every leg still ringing; the
+	// cause tells them the call was answered elsewhere
+	timeoutCtx, cancelTimeout := context.WithTimeout(ctx, timeout)
+	defer cancelTimeout()
+	forkCtx, cancelFork := context.WithCancelCause(timeoutCtx)
+	defer cancelFork(nil)
+
+	var mu sync.Mutex
+	var winner Leg
+	claim := func(leg Leg) bool {
+		mu.Lock()
+		defer mu.Unlock()
+		if winner != nil || forkCtx.Err() != nil {
+			return false
+		}
+		winner = leg
+		cancelFork(ErrAnsweredElsewhere)
+		return true
+	}
+
+	results := make(chan forkResult, len(targets))
+	for i, target := range targets {
+		go func() {
+			leg, err := s.dialForkTarget(forkCtx, target, timeout, opts, claim)
+			results <- forkResult{index: i, leg: leg, err: err}
+		}()
+	}
+
+	errs := make([]error, len(targets))
+	for range targets {
+		result := <-results
+		if result.leg != nil {
+			if legOpts.aLegCallID != "" {
+				s.originator.associate(legOpts.aLegCallID, result.leg.CallID())
+			}
+			slog.Info("[CallService] Fork answered",
+				"target", targets[result.index].Target,
+				"leg_b", result.leg.ID(),
+			)
+			return result.leg, nil
+		}
+		errs[result.index] = result.err
+	}
+	return nil, errs[0]
+}
+
+// dialForkTarget dials one fork target and, once it has answered and
+// accepted the call, claims the fork for it. Legs that lose the fork
+// after answering are hung up.
+func (s *callService) dialForkTarget(ctx context.Context, target ForkTarget, timeout time.Duration, opts []LegOption, claim func(Leg) bool) (Leg, error) {
+	if target.Timeout > 0 {
+		timeout = min(timeout, target.Timeout)
+	}
+	if len(target.Headers) > 0 {
+		opts = append(slices.Clone(opts), WithHeaders(target.Headers))
+	}
+
+	leg, err := s.Dial(ctx, target.Target, timeout, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if target.Confirm && !s.confirm(ctx, leg) {
+		slog.Info("[CallService] Fork leg did not accept the call",
+			"target", target.Target,
+			"leg_b", leg.ID(),
+		)
+		_ = leg.Hangup(context.Background(), TerminationCauseRejected)
+		return nil, &DialError{Target: target.Target, Cause: ErrNotConfirmed}
+	}
+
+	if !claim(leg) {
+		// Another leg won, or the fork ended while this one was confirming
+		_ = leg.Hangup(context.Background(), TerminationCauseCancel)
+		return nil, &DialError{Target: target.Target, Cause: ErrDialCanceled}
+	}
+	return leg, nil
+}
+
+// confirm asks the callee of an answered leg to accept the call and
+// reports whether they pressed 1 within ConfirmTimeout. The prompt is
+// repeated until then.
+func (s *callService) confirm(ctx context.Context, leg Leg) bool {
+	sessionID := leg.SessionID()
+	if s.cfg.Transport == nil || sessionID == "" {
+		return false
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.cfg.ConfirmTimeout)
+	defer cancel()
+	// Give up as soon as the callee hangs up
+	defer context.AfterFunc(leg.Context(), cancel)()
+
+	for {
+		statusCh, err := s.playConfirmPrompt(ctx, sessionID)
+		if err != nil {
+			slog.Warn("[CallService] Confirm prompt failed",
+				"session_id", sessionID,
+				"error", err,
+			)
+			return false
+		}
+
+		accepted, completed := false, false
+	wait:
+		for status := range statusCh {
+			switch status.State {
+			case mediaclient.PlayStateDTMF:
+				if status.Digit == confirmDigit {
+					accepted = true
+					break wait
+				}
+			case mediaclient.PlayStateCompleted:
+				completed = true
+			}
+		}
+
+		if !completed {
+			// Release the session's port for the bridge before returning
+			if err := s.cfg.Transport.StopAudio(context.Background(), sessionID); err != nil {
+				slog.Debug("[CallService] Confirm prompt stop",
+					"session_id", sessionID,
+					"error", err,
+				)
+			}
+			for range statusCh {
+			}
+		}
+		if accepted || !completed || ctx.Err() != nil {
+			return accepted
+		}
+	}
+}
+
+func (s *callService) playConfirmPrompt(ctx context.Context, sessionID string) (<-chan mediaclient.PlayStatus, error) {
+	if s.cfg.ConfirmPrompt != "" {
+		return s.cfg.Transport.PlayAudio(ctx, mediaclient.PlayRequest{
+			SessionID:  sessionID,
+			AudioFile:  s.cfg.ConfirmPrompt,
+			ReportDTMF: true,
+		})
+	}
+	return s.cfg.Transport.PlayTone(ctx, mediaclient.ToneRequest{
+		SessionID:  sessionID,
+		Tone:       confirmTone,
+		ReportDTMF: true,
+	})
+}
+
+func (s *callService) DialParallelAndBridge(ctx context.Context, legA Leg, targets []ForkTarget, timeout time.Duration, opts ...LegOption) (*BridgeInfo, error) {
+	if timeout == 0 {
+		timeout = s.cfg.DefaultDialTimeout
+	}
+
+	// Verify A leg is answered
+	if legA.GetState() != LegStateAnswered {
+		return nil, ErrLegNotAnswered
+	}
+
+	slog.Info("[CallService] DialParallelAndBridge starting",
+		"leg_a", legA.ID(),
+		"leg_a_session", legA.SessionID(),
+		"targets", len(targets),
+		"timeout", timeout,
+	)
+
+	return s.dialAndBridge(ctx, legA, opts, func(ctx context.Context, opts []LegOption) (Leg, error) {
+		return s.DialParallel(ctx, targets, timeout, opts...)
+	})
+}
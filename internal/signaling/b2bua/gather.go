@@ -0,0 +1,235 @@
+package b2bua
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// DefaultGatherTimeout is how long Gather waits for the first digit once
+// the prompt has played, and between digits, unless told otherwise.
+const DefaultGatherTimeout = 5 * time.Second
+
+// GatherOptions describes a collection of DTMF digits.
+type GatherOptions struct {
+	// Prompt is played first; pressing a key interrupts it. Dial tone if
+	// empty.
+	Prompt string
+
+	// MaxDigits ends the collection once that many digits are pressed;
+	// zero collects until "#" or a timeout.
+	MaxDigits int
+
+	// Timeout is how long to wait for the first digit once the prompt has
+	// played. Default: DefaultGatherTimeout.
+	Timeout time.Duration
+
+	// InterDigitTimeout is how long to wait for each further digit.
+	// Default: Timeout.
+	InterDigitTimeout time.Duration
+
+	// Pattern, if set, must match the digits collected; other input is
+	// collected again. DigitPattern anchors an expression to all of them.
+	Pattern *regexp.Regexp
+
+	// Attempts is how many times the prompt is played until the digits
+	// match Pattern. Default: 1.
+	Attempts int
+
+	// InvalidPrompt is played after digits that do not match Pattern.
+	InvalidPrompt string
+}
+
+// GatherResult is the outcome of Gather.
+type GatherResult struct {
+	Digits   string `json:"digits"`   // Without the "#"; those of the last attempt if none was valid
+	Valid    bool   `json:"valid"`    // Whether Digits match the pattern (always, without one)
+	Attempts int    `json:"attempts"` // Prompts played
+}
+
+// DigitPattern compiles a regular expression that must match all of the
+// digits gathered, for GatherOptions.Pattern.
+func DigitPattern(expr string) (*regexp.Regexp, error) {
+	return regexp.Compile("^(?:" + expr + ")$")
+}
+
+// Gather plays the prompt to a media session and collects the digits the
+// remote party presses, from the DTMF events the RTP manager reports
+// during playback, until MaxDigits, "#" or a timeout. Digits not matching
+// the pattern are collected again until the attempts run out; the result
+// then has Valid false.
+func Gather(ctx context.Context, transport mediaclient.Transport, sessionID string, opts GatherOptions) (*GatherResult, error) {
+	if sessionID == "" {
+		return nil, fmt.Errorf("no RTP session established")
+	}
+	if opts.Timeout <= 0 {
+		opts.Timeout = DefaultGatherTimeout
+	}
+	if opts.InterDigitTimeout <= 0 {
+		opts.InterDigitTimeout = opts.Timeout
+	}
+	if opts.Attempts <= 0 {
+		opts.Attempts = 1
+	}
+
+	result := &GatherResult{}
+	for result.Attempts < opts.Attempts {
+		result.Attempts++
+		digits, err := collectDigits(ctx, transport, sessionID, opts)
+		if err != nil {
+			return nil, err
+		}
+		result.Digits = digits
+		if opts.Pattern == nil || opts.Pattern.MatchString(digits) {
+			result.Valid = true
+			return result, nil
+		}
+		slog.Debug("[CallService] Gathered digits do not match",
+			"session_id", sessionID,
+			"pattern", opts.Pattern.String(),
+			"attempt", result.Attempts,
+		)
+		if opts.InvalidPrompt != "" {
+			if err := playPrompt(ctx, transport, sessionID, opts.InvalidPrompt); err != nil {
+				return nil, err
+			}
+		}
+	}
+	return result, nil
+}
+
+// GatherLeg implements CallService.GatherLeg.
+func (s *callService) GatherLeg(ctx context.Context, callID string, opts GatherOptions) (*GatherResult, error) {
+	d, ok := s.cfg.DialogManager.Get(callID)
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrLegNotFound, callID)
+	}
+	if d.GetState() != dialog.StateConfirmed {
+		return nil, ErrLegNotAnswered
+	}
+	if b, _, _ := s.bridgedPeer(callID); b != nil {
+		return nil, fmt.Errorf("%w: leg is bridged (%s)", ErrInvalidState, b.ID())
+	}
+
+	// The collection ends with the call
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(d.Context(), cancel)
+	defer stop()
+
+	result, err := Gather(ctx, s.cfg.Transport, d.GetSessionID(), opts)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("[CallService] Digits gathered", "call_id", callID, "digits", len(result.Digits), "valid", result.Valid)
+	return result, nil
+}
+
+// collectDigits plays the prompt once and collects digits. The timeout
+// runs from the end of the prompt; the RTP manager only reports digits
+// while it is playing, so silence is played once the prompt has finished
+// or been interrupted.
+func collectDigits(ctx context.Context, transport mediaclient.Transport, sessionID string, opts GatherOptions) (string, error) {
+	playCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var statusCh <-chan mediaclient.PlayStatus
+	var err error
+	if opts.Prompt != "" {
+		statusCh, err = transport.PlayAudio(playCtx, mediaclient.PlayRequest{
+			SessionID:  sessionID,
+			AudioFile:  opts.Prompt,
+			ReportDTMF: true,
+		})
+	} else {
+		statusCh, err = transport.PlayTone(playCtx, mediaclient.ToneRequest{
+			SessionID:  sessionID,
+			Tone:       "dial",
+			ReportDTMF: true,
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("start prompt: %w", err)
+	}
+	defer func() {
+		_ = transport.StopAudio(context.Background(), sessionID)
+		for range statusCh {
+		}
+	}()
+
+	var digits strings.Builder
+	prompting := true
+	announcing := opts.Prompt != "" // The timeout runs from the end of the prompt
+	timer := time.NewTimer(opts.Timeout)
+	defer timer.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return "", ctx.Err()
+
+		case <-timer.C:
+			if announcing {
+				continue
+			}
+			return digits.String(), nil
+
+		case status, ok := <-statusCh:
+			switch {
+			case !ok:
+				// Prompt finished or interrupted: keep listening
+				if announcing {
+					announcing = false
+					timer.Reset(opts.Timeout)
+				}
+				prompting = false
+				statusCh, err = transport.PlayTone(playCtx, mediaclient.ToneRequest{
+					SessionID:  sessionID,
+					Tone:       "0",
+					ReportDTMF: true,
+				})
+				if err != nil {
+					return "", fmt.Errorf("listen for digits: %w", err)
+				}
+
+			case status.State == mediaclient.PlayStateError:
+				return "", status.Error
+
+			case status.State == mediaclient.PlayStateDTMF:
+				if status.Digit == "#" {
+					return digits.String(), nil
+				}
+				digits.WriteString(status.Digit)
+				if opts.MaxDigits > 0 && digits.Len() >= opts.MaxDigits {
+					return digits.String(), nil
+				}
+				timer.Reset(opts.InterDigitTimeout)
+				announcing = false
+				if prompting {
+					prompting = false
+					_ = transport.StopAudio(context.Background(), sessionID)
+				}
+			}
+		}
+	}
+}
+
+// playPrompt plays a file to the end.
+func playPrompt(ctx context.Context, transport mediaclient.Transport, sessionID, file string) error {
+	statusCh, err := transport.PlayAudio(ctx, mediaclient.PlayRequest{SessionID: sessionID, AudioFile: file})
+	if err != nil {
+		return fmt.Errorf("play %s: %w", file, err)
+	}
+	for status := range statusCh {
+		if status.State == mediaclient.PlayStateError {
+			return status.Error
+		}
+	}
+	return ctx.Err()
+}
@@ -0,0 +1,58 @@
+package b2bua_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/pkg/testkit"
+)
+
+func TestGather(t *testing.T) {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	media := testkit.NewTransport()
+	session, err := media.CreateSession(ctx, mediaclient.SessionInfo{CallID: "gather"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pattern, err := b2bua.DigitPattern(`1\d\d`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_ = media.SendDTMF("gather", "123")
+	result, err := b2bua.Gather(ctx, media, session.SessionID, b2bua.GatherOptions{MaxDigits: 3, Pattern: pattern})
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	if result.Digits != "123" || !result.Valid || result.Attempts != 1 {
+		t.Errorf("Gather = %+v, want 123, valid, 1 attempt", result)
+	}
+
+	_ = media.SendDTMF("gather", "1234#")
+	result, err = b2bua.Gather(ctx, media, session.SessionID, b2bua.GatherOptions{Pattern: pattern})
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	if result.Digits != "1234" || result.Valid {
+		t.Errorf("Gather = %+v, want 1234, not valid", result)
+	}
+
+	// The inter-digit timeout ends the collection well before the timeout
+	_ = media.SendDTMF("gather", "7")
+	started := time.Now()
+	result, err = b2bua.Gather(ctx, media, session.SessionID, b2bua.GatherOptions{
+		Timeout:           time.Second,
+		InterDigitTimeout: 20 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Gather: %v", err)
+	}
+	if result.Digits != "7" || time.Since(started) >= time.Second {
+		t.Errorf("Gather = %+v after %v, want 7 before the timeout", result, time.Since(started))
+	}
+}
@@ -0,0 +1,193 @@
+package b2bua
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+)
+
+// holds are the bridges a hold or resume is in progress on, so two API
+// requests cannot both re-INVITE a leg
+type holds struct {
+	mu      sync.Mutex
+	bridges map[string]bool
+}
+
+// start marks a hold or resume of a bridge in progress; false if one is
+// already.
+func (h *holds) start(bridgeID string) bool {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.bridges[bridgeID] {
+		return false
+	}
+	h.bridges[bridgeID] = true
+	return true
+}
+
+// done ends the hold or resume of a bridge.
+func (h *holds) done(bridgeID string) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.bridges, bridgeID)
+}
+
+// HoldLeg implements CallService.HoldLeg.
+func (s *callService) HoldLeg(ctx context.Context, bridgeID, leg, class string) error {
+	if !s.holds.start(bridgeID) {
+		return fmt.Errorf("%w: hold or resume in progress", ErrInvalidState)
+	}
+	defer s.holds.done(bridgeID)
+
+	b, d, err := s.bridgedLeg(bridgeID, leg)
+	if err != nil {
+		return err
+	}
+	if b.HeldBy() != "" {
+		return fmt.Errorf("%w: call on hold by %s", ErrInvalidState, b.HeldBy())
+	}
+
+	var music []string
+	if s.cfg.Music != nil {
+		c, err := s.cfg.Music.Resolve("", "")
+		if class != "" {
+			c, err = s.cfg.Music.Class(class)
+		}
+		if err == nil {
+			music, err = c.Playlist()
+		}
+		if err != nil {
+			return fmt.Errorf("music on hold: %w", err)
+		}
+	}
+
+	if err := s.reINVITEHold(ctx, d, dialog.HoldTypeSendOnly); err != nil {
+		return err
+	}
+	if err := b.HoldLeg(ctx, leg, music); err != nil {
+		// Take the leg off hold again rather than leave it one-way
+		if rerr := s.reINVITEHold(context.WithoutCancel(ctx), d, dialog.HoldTypeResume); rerr != nil {
+			slog.Warn("[B2BUA] Failed to take leg off hold after failed hold", "bridge_id", bridgeID, "call_id", d.CallID, "error", rerr)
+		}
+		return fmt.Errorf("hold: %w", err)
+	}
+	slog.Info("[B2BUA] Leg put on hold", "bridge_id", bridgeID, "leg", leg, "call_id", d.CallID, "music", len(music) > 0)
+	return nil
+}
+
+// ResumeLeg implements CallService.ResumeLeg.
+func (s *callService) ResumeLeg(ctx context.Context, bridgeID string) error {
+	if !s.holds.start(bridgeID) {
+		return fmt.Errorf("%w: hold or resume in progress", ErrInvalidState)
+	}
+	defer s.holds.done(bridgeID)
+
+	b, ok := s.bridges.Get(bridgeID)
+	if !ok || b.GetState() != BridgeStateActive {
+		return ErrBridgeNotFound
+	}
+	if b.HeldBy() != HeldLocally {
+		return fmt.Errorf("%w: no leg put on hold here", ErrInvalidState)
+	}
+	_, d, err := s.bridgedLeg(bridgeID, b.HeldLeg())
+	if err != nil {
+		return err
+	}
+
+	if err := s.reINVITEHold(ctx, d, dialog.HoldTypeResume); err != nil {
+		return err
+	}
+	if err := b.Resume(ctx); err != nil {
+		return fmt.Errorf("resume: %w", err)
+	}
+	slog.Info("[B2BUA] Leg taken off hold", "bridge_id", bridgeID, "call_id", d.CallID)
+	return nil
+}
+
+// bridgedLeg returns an active bridge and the dialog of one of its legs
+// ("leg_a" or "leg_b")
+func (s *callService) bridgedLeg(bridgeID, leg string) (Bridge, *dialog.Dialog, error) {
+	b, ok := s.bridges.Get(bridgeID)
+	if !ok || b.GetState() != BridgeStateActive {
+		return nil, nil, ErrBridgeNotFound
+	}
+	var l Leg
+	switch leg {
+	case "leg_a":
+		l = b.LegA()
+	case "leg_b":
+		l = b.LegB()
+	default:
+		return nil, nil, fmt.Errorf("%w: %q", ErrUnknownLeg, leg)
+	}
+	d := l.Dialog()
+	if d == nil {
+		return nil, nil, fmt.Errorf("%w: %s has no dialog", ErrInvalidState, leg)
+	}
+	return b, d, nil
+}
+
+// reINVITEHold sends a leg a re-INVITE offering its last SDP with the
+// direction of a hold type, and points its RTP session at the address it
+// answers with
+func (s *callService) reINVITEHold(ctx context.Context, d *dialog.Dialog, hold dialog.HoldType) error {
+	var contact sip.Uri
+	if err := sipaddr.ParseURI(s.cfg.LocalContact, &contact); err != nil {
+		return fmt.Errorf("invalid local contact: %w", err)
+	}
+	if d.IsReINVITEInProgress() {
+		return fmt.Errorf("%w: re-INVITE in progress", ErrInvalidState)
+	}
+
+	timeout := s.cfg.InviteTimeout
+	if timeout == 0 {
+		timeout = defaultInviteTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	result, err := s.cfg.DialogManager.SendReINVITE(ctx, d, contact, dialog.ReINVITEOptions{HoldType: hold})
+	if err != nil {
+		return fmt.Errorf("re-INVITE: %w", err)
+	}
+	if !result.Success {
+		return fmt.Errorf("re-INVITE rejected: %d %s", result.StatusCode, result.Reason)
+	}
+
+	if answer, addr, port, err := parseMedia(result.SDP); err == nil {
+		s.updateRemote(ctx, d, addr, port, firstFormat(answer))
+	}
+	return nil
+}
+
+// answerHeld answers an offer from either leg of a call put on hold with
+// HoldLeg, without involving the other leg: the held leg is kept from
+// sending, so it still hears the music
+func (s *callService) answerHeld(d *dialog.Dialog, b Bridge, side string, offerSDP *sdpmedia.Session, direction, addr string, port int) *dialog.ReINVITEResult {
+	answerDirection := sdpmedia.AnswerDirection(direction)
+	if side == b.HeldLeg() {
+		switch answerDirection {
+		case sdpmedia.SendRecv:
+			answerDirection = sdpmedia.SendOnly
+		case sdpmedia.RecvOnly:
+			answerDirection = sdpmedia.Inactive
+		}
+	}
+	answer, err := renegotiated(d.LocalSDP(), offerSDP, answerDirection)
+	if err == nil {
+		answer, err = s.answerOthers(answer, offerSDP.SDP, nil)
+	}
+	if err != nil {
+		slog.Warn("[B2BUA] Re-INVITE on hold not answered", "call_id", d.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+	ctx, cancel := context.WithTimeout(d.Context(), holdTimeout)
+	defer cancel()
+	s.updateRemote(ctx, d, addr, port, firstFormat(offerSDP))
+	return &dialog.ReINVITEResult{Success: true, StatusCode: int(sip.StatusOK), Reason: "OK", SDP: answer}
+}
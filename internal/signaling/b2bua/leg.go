@@ -3,12 +3,15 @@ package b2bua
 import (
 	"context"
 	"log/slog"
+	"maps"
 	"sync"
 	"sync/atomic"
 	"time"
 
+	"github.com/emiago/sipgo/sip"
 	"github.com/google/uuid"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
 )
 
 // Leg represents one side of a call in a B2BUA scenario.
@@ -42,6 +45,10 @@ type Leg interface {
 	// Returns TerminationCauseNone if not yet terminated.
 	GetTerminationCause() TerminationCause
 
+	// RemoteReason returns the Reason header (RFC 3326) of the BYE or
+	// CANCEL the remote party ended the leg with; zero if none.
+	RemoteReason() sipreason.Reason
+
 	// WaitForState blocks until the leg reaches the target state or context is canceled.
 	// Returns immediately if already in or past the target state.
 	// Returns error if the leg reaches a terminal state before the target.
@@ -94,9 +101,11 @@ type Leg interface {
 // LegInfo contains detailed information about a leg.
 type LegInfo struct {
 	// Identity
-	ID        string       `json:"id"`
-	CallID    string       `json:"call_id"`
-	Direction LegDirection `json:"direction"`
+	ID         string       `json:"id"`
+	CallID     string       `json:"call_id"`
+	Direction  LegDirection `json:"direction"`
+	ALegCallID string       `json:"a_leg_call_id,omitempty"` // Inbound leg an outbound leg was dialed for
+	Callee     string       `json:"callee,omitempty"`        // Registered user an outbound leg rings
 
 	// SIP addressing
 	LocalURI  string `json:"local_uri"`  // Our contact URI
@@ -171,9 +180,13 @@ type LegOption func(*legOptions)
 type legOptions struct {
 	callerID      string
 	callerName    string
-	onTeardown    func(Leg) // Called when leg is being torn down (before state change)
-	aLegSessionID string    // A-leg session ID for bridging on same RTP manager
-	aLegCallID    string    // A-leg Call-ID for BridgeMapper lookup (drain migration)
+	onTeardown    func(Leg)    // Called when leg is being torn down (before state change)
+	aLegSessionID string       // A-leg session ID for bridging on same RTP manager
+	aLegCallID    string       // A-leg Call-ID for BridgeMapper lookup (drain migration)
+	aLegInvite    *sip.Request // A-leg INVITE for Max-Forwards and loop detection
+	onProgress    func(Leg, LegState)
+	headers       map[string]string // Extra headers for the outbound INVITE
+	callID        string            // Call-ID of the outbound leg; generated if empty
 }
 
 // WithCallerID sets the caller ID (From URI user part) for outbound legs.
@@ -220,6 +233,43 @@ func WithALegCallID(callID string) LegOption {
 	}
 }
 
+// WithALegInvite sets the INVITE that started the A-leg. The B-leg's
+// INVITE carries its Max-Forwards minus one, and is recorded by the loop
+// detector as continuing it.
+func WithALegInvite(invite *sip.Request) LegOption {
+	return func(o *legOptions) {
+		o.aLegInvite = invite
+	}
+}
+
+// WithProgressHandler sets a callback invoked as an outbound leg progresses
+// before answer. It receives LegStateRinging for 180/181 (and 183 without
+// SDP) and LegStateEarlyMedia once the callee's early media is set up.
+func WithProgressHandler(fn func(Leg, LegState)) LegOption {
+	return func(o *legOptions) {
+		o.onProgress = fn
+	}
+}
+
+// WithCallID sets the Call-ID of the outbound leg instead of a generated
+// one, so callers can track the leg before it answers.
+func WithCallID(callID string) LegOption {
+	return func(o *legOptions) {
+		o.callID = callID
+	}
+}
+
+// WithHeaders adds headers to the outbound INVITE, e.g. Priority or
+// Geolocation for emergency calls. Repeated options are merged.
+func WithHeaders(headers map[string]string) LegOption {
+	return func(o *legOptions) {
+		if o.headers == nil {
+			o.headers = make(map[string]string, len(headers))
+		}
+		maps.Copy(o.headers, headers)
+	}
+}
+
 // --- Implementation ---
 
 // legImpl is the concrete implementation of the Leg interface.
@@ -227,9 +277,11 @@ type legImpl struct {
 	mu sync.RWMutex
 
 	// Identity
-	id        string
-	callID    string
-	direction LegDirection
+	id         string
+	callID     string
+	direction  LegDirection
+	aLegCallID string // Set by the originator for outbound legs
+	callee     string // Set by the originator for legs ringing a registered user
 
 	// SIP addressing
 	localURI  string
@@ -240,6 +292,7 @@ type legImpl struct {
 	// State
 	state            LegState
 	terminationCause TerminationCause
+	remoteReason     sipreason.Reason
 
 	// SIP dialog
 	dialog *dialog.Dialog
@@ -270,6 +323,13 @@ type legImpl struct {
 	remoteTag        string // Tag from To header in 200 OK
 	localTag         string // Our From tag
 
+	// RFC 5626 flow the 200 OK arrived on; in-dialog requests reuse it
+	// when the remote Contact carries the "ob" parameter
+	flowAddr string
+
+	// Route set from the 2xx Record-Route (RFC 3261 Section 12.1.2)
+	routeSet []sip.Uri
+
 	// Lifecycle - Using done channel pattern instead of storing context
 	// This follows Go best practices: contexts are for passing to functions,
 	// done channels are for signaling termination in long-lived objects
@@ -416,6 +476,16 @@ func (l *legImpl) GetTerminationCause() TerminationCause {
 	return l.terminationCause
 }
 
+func (l *legImpl) RemoteReason() sipreason.Reason {
+	l.mu.RLock()
+	reason, dlg := l.remoteReason, l.dialog
+	l.mu.RUnlock()
+	if reason.IsZero() && dlg != nil {
+		return dlg.RemoteReason()
+	}
+	return reason
+}
+
 func (l *legImpl) WaitForState(ctx context.Context, target LegState) error {
 	for {
 		l.mu.RLock()
@@ -488,6 +558,8 @@ func (l *legImpl) Info() *LegInfo {
 		ID:               l.id,
 		CallID:           l.callID,
 		Direction:        l.direction,
+		ALegCallID:       l.aLegCallID,
+		Callee:           l.callee,
 		LocalURI:         l.localURI,
 		RemoteURI:        l.remoteURI,
 		FromURI:          l.fromURI,
@@ -744,6 +816,14 @@ func (l *legImpl) SetTerminationCause(cause TerminationCause) {
 	l.terminationCause = cause
 }
 
+// SetRemoteReason records the Reason header of the BYE the remote party
+// sent.
+func (l *legImpl) SetRemoteReason(reason sipreason.Reason) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.remoteReason = reason
+}
+
 // SetOutboundDialogState stores the dialog state needed to send BYE for outbound legs.
 // This should be called when the 200 OK is received.
 // - remoteContactURI: Contact header from 200 OK (used as Request-URI in BYE)
@@ -761,6 +841,35 @@ func (l *legImpl) SetOutboundDialogState(remoteContactURI, remoteToURI, localFro
 	l.localTag = localTag
 }
 
+// SetFlow records the address of the outbound (RFC 5626) flow to send
+// in-dialog requests over.
+func (l *legImpl) SetFlow(addr string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.flowAddr = addr
+}
+
+// Flow returns the outbound flow address, or "" if the dialog does not use one.
+func (l *legImpl) Flow() string {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.flowAddr
+}
+
+// SetRouteSet records the dialog route set for in-dialog requests.
+func (l *legImpl) SetRouteSet(routes []sip.Uri) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.routeSet = routes
+}
+
+// RouteSet returns the dialog route set, or nil if there is none.
+func (l *legImpl) RouteSet() []sip.Uri {
+	l.mu.RLock()
+	defer l.mu.RUnlock()
+	return l.routeSet
+}
+
 // GetOutboundDialogState returns the dialog state for sending BYE.
 func (l *legImpl) GetOutboundDialogState() (remoteContactURI, remoteToURI, localFromURI, remoteTag, localTag string) {
 	l.mu.RLock()
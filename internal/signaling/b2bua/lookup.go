@@ -48,7 +48,8 @@ type LookupResult struct {
 	// Original is the raw target string that was looked up.
 	Original string
 
-	// Contacts contains the resolved SIP URIs, sorted by priority.
+	// Contacts contains every resolved SIP URI, most preferred first:
+	// highest q-value, then most recently registered.
 	// For user lookups: from location service bindings
 	// For gateway lookups: from gateway configuration
 	// For direct: single entry matching original
@@ -86,6 +87,20 @@ func (r *LookupResult) PrimaryContact() ResolvedContact {
 	return r.Contacts[0]
 }
 
+// PriorityGroups splits Contacts into groups of equal priority, most
+// preferred group first. Per RFC 3261 Section 16.6, contacts in a group may
+// be tried in parallel and groups are tried in sequence.
+func (r *LookupResult) PriorityGroups() [][]ResolvedContact {
+	var groups [][]ResolvedContact
+	for i, c := range r.Contacts {
+		if i == 0 || c.Priority != r.Contacts[i-1].Priority {
+			groups = append(groups, nil)
+		}
+		groups[len(groups)-1] = append(groups[len(groups)-1], c)
+	}
+	return groups
+}
+
 // HasContacts returns true if at least one contact was resolved.
 func (r *LookupResult) HasContacts() bool {
 	return len(r.Contacts) > 0
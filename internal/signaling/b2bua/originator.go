@@ -2,8 +2,13 @@ package b2bua
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"net"
+	"slices"
+	"strings"
 	"sync"
 	"time"
 
@@ -11,18 +16,39 @@ import (
 	"github.com/emiago/sipgo/sip"
 	"github.com/google/uuid"
 	psdp "github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/signaling/codecs"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/kpi"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+	"github.com/sebas/switchboard/internal/signaling/topology"
 )
 
+// defaultInviteTimeout is how long an INVITE is watched for further 2xx
+// responses once the dial has ended unless configured (64*T1, after which
+// the transaction is gone)
+const defaultInviteTimeout = 32 * time.Second
+
 // OriginatorConfig holds originator configuration.
 type OriginatorConfig struct {
 	AdvertiseAddr string
+	Advertise     *advertise.Selector // Per-network address; nil uses AdvertiseAddr
 	Port          int
 	Transport     mediaclient.Transport
 	Client        *sipgo.Client
 	LocalContact  string
 	DialogManager dialog.DialogStore // For registering outbound dialogs
+	HeaderPolicy  HeaderPolicy       // Header rules for INVITEs and their responses; may be nil
+	Identity      Identity           // User-Agent of requests sent; may be nil
+	Codecs        CodecPolicy        // Codecs offered per callee; nil offers PCMU
+	LoopDetector  LoopDetector       // Records sent INVITEs; may be nil
+	LoadMonitor   LoadMonitor        // Told the setup time of each leg; may be nil
+	KPIRecorder   KPIRecorder        // Told the outcome of each leg; may be nil
+	InviteTimeout time.Duration      // Timer B; zero uses 32 seconds
+	Video         sdpmedia.Mode      // Passthrough offers the A-leg's other streams to the B-leg
 }
 
 // OriginateRequest contains parameters for an outbound call.
@@ -33,7 +59,8 @@ type OriginateRequest struct {
 	// A-leg correlation
 	ALegCallID    string
 	ALegID        string
-	ALegSessionID string // A-leg RTP session ID (for bridging on same RTP manager)
+	ALegSessionID string       // A-leg RTP session ID (for bridging on same RTP manager)
+	ALegInvite    *sip.Request // A-leg INVITE (see WithALegInvite); may be nil
 
 	// Caller ID
 	CallerID   string
@@ -42,7 +69,16 @@ type OriginateRequest struct {
 	// Options
 	Timeout    time.Duration
 	EarlyMedia bool
-	Codecs     []string // Offered codecs (e.g., ["0", "8"] for PCMU, PCMA)
+	Codecs     []string // Offered codecs (e.g., ["0", "8"] for PCMU, PCMA); empty uses the callee's codec policy
+
+	// OnProgress is called on provisional responses (see WithProgressHandler)
+	OnProgress func(Leg, LegState)
+
+	// Headers are added to the INVITE (see WithHeaders)
+	Headers map[string]string
+
+	// CallID is the B-leg Call-ID (see WithCallID); generated if empty
+	CallID string
 }
 
 // OriginateResult contains the outcome of an originate attempt.
@@ -61,6 +97,7 @@ type Originator struct {
 	mu        sync.RWMutex
 	legs      map[string]*legImpl // Indexed by B-leg Call-ID
 	aToB      map[string]string   // A-leg Call-ID -> B-leg Call-ID mapping
+	families  *familyPreference   // Last answering address family per host
 }
 
 // NewOriginator creates a new Originator.
@@ -70,6 +107,7 @@ func NewOriginator(cfg OriginatorConfig) *Originator {
 		dialogMgr: cfg.DialogManager, // Store the interface
 		legs:      make(map[string]*legImpl),
 		aToB:      make(map[string]string),
+		families:  newFamilyPreference(),
 	}
 }
 
@@ -84,12 +122,16 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 			Error:     ErrNoContacts,
 		}, nil
 	}
+	start := time.Now()
 
 	// Get primary contact
 	contact := req.Target.PrimaryContact()
 
 	// Generate unique Call-ID for B leg
-	bLegCallID := generateCallID()
+	bLegCallID := req.CallID
+	if bLegCallID == "" {
+		bLegCallID = generateCallID()
+	}
 	localTag := generateTag()
 
 	// Create B leg
@@ -98,6 +140,10 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 		return nil, fmt.Errorf("create outbound leg: %w", err)
 	}
 	bleg := leg.(*legImpl)
+	bleg.aLegCallID = req.ALegCallID
+	if contact.Binding != nil {
+		bleg.callee = aorUser(contact.Binding.AOR)
+	}
 
 	// Set up teardown handler to send SIP BYE/CANCEL when bridge terminates this leg
 	bleg.SetTeardownHandler(func(l Leg) {
@@ -164,7 +210,10 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 
 		o.mu.Lock()
 		delete(o.legs, bLegCallID)
-		delete(o.aToB, req.ALegCallID)
+		// A forked call's A-leg maps to the winning leg, not to this one
+		if o.aToB[req.ALegCallID] == bLegCallID {
+			delete(o.aToB, req.ALegCallID)
+		}
 		o.mu.Unlock()
 		slog.Debug("[Originator] B-leg cleaned up",
 			"call_id", bLegCallID,
@@ -172,18 +221,49 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 		)
 	})
 
+	// Phones registered through an edge proxy or SBC are reached via the
+	// binding's Path (RFC 3327) rather than at their private contact
+	var routes []sip.Uri
+	if contact.Binding != nil && len(contact.Binding.Path) > 0 {
+		routes, err = parseRouteSet(contact.Binding.Path)
+		if err != nil {
+			return &OriginateResult{
+				Success:   false,
+				SIPCode:   500,
+				SIPReason: "Invalid Path",
+				Error:     err,
+			}, nil
+		}
+	}
+
+	// The callee's RTP address is unknown until it answers, so its SIP host
+	// (or the first hop of its Path) decides which address the RTP manager
+	// advertises
+	peerAddr := uriHost(contact.URI)
+	if len(routes) > 0 {
+		peerAddr = routes[0].Host
+	}
+
 	// Step 1: Create media session for B leg (pending remote - we don't know callee's RTP endpoint yet)
-	codecs := req.Codecs
-	if len(codecs) == 0 {
-		codecs = []string{"0"} // Default to PCMU
+	offered := req.Codecs
+	if len(offered) == 0 {
+		offered = o.offeredCodecs(contact, peerAddr)
+	}
+	if len(offered) == 0 {
+		return &OriginateResult{
+			Success:   false,
+			SIPCode:   488,
+			SIPReason: "Not Acceptable Here",
+			Error:     codecs.ErrNoAllowedCodec,
+		}, nil
 	}
 
 	// If A-leg session ID is provided, create B-leg on the same RTP manager for bridging
 	var sessionResult *mediaclient.SessionResult
 	if req.ALegSessionID != "" {
-		sessionResult, err = o.cfg.Transport.CreateSessionPendingRemoteOnNode(ctx, req.ALegSessionID, bLegCallID, codecs)
+		sessionResult, err = o.cfg.Transport.CreateSessionPendingRemoteOnNode(ctx, req.ALegSessionID, bLegCallID, peerAddr, offered)
 	} else {
-		sessionResult, err = o.cfg.Transport.CreateSessionPendingRemote(ctx, bLegCallID, codecs)
+		sessionResult, err = o.cfg.Transport.CreateSessionPendingRemote(ctx, bLegCallID, peerAddr, offered)
 	}
 	if err != nil {
 		return &OriginateResult{
@@ -208,7 +288,7 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 	}()
 
 	// Step 2: Build and send INVITE
-	inviteReq, err := o.buildINVITE(bleg, contact.URI, localTag, req, sessionResult.SDPBody)
+	inviteReq, err := o.buildINVITE(bleg, contact.URI, routes, localTag, req, sessionResult.SDPBody)
 	if err != nil {
 		return &OriginateResult{
 			Success:   false,
@@ -217,9 +297,26 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 			Error:     err,
 		}, nil
 	}
+	// Identity first, so header rules may still change it per peer
+	if o.cfg.Identity != nil {
+		o.cfg.Identity.SentRequest(inviteReq, peerAddr)
+	}
+	if o.cfg.HeaderPolicy != nil {
+		o.cfg.HeaderPolicy.SentRequest(inviteReq, peerAddr)
+	}
+	if o.cfg.LoadMonitor != nil {
+		o.cfg.LoadMonitor.ObserveOriginate(time.Since(start))
+	}
 
 	// Step 3: Send INVITE and handle response flow
-	result := o.executeINVITE(ctx, bleg, inviteReq, localTag, req.Timeout)
+	nextHop := inviteReq.Recipient
+	if len(routes) > 0 {
+		nextHop = routes[0]
+	}
+	targets := o.dialTargets(ctx, nextHop)
+	invited := time.Now()
+	result := o.executeINVITE(ctx, bleg, inviteReq, req, targets)
+	o.recordAttempt(bleg, kpiRoute(req.Target), invited, result)
 
 	// Mark success before returning to prevent defer cleanup
 	originateSuccess = result.Success
@@ -228,25 +325,92 @@ func (o *Originator) Originate(ctx context.Context, req OriginateRequest) (*Orig
 	return result, nil
 }
 
-// buildINVITE constructs the outbound INVITE request.
-func (o *Originator) buildINVITE(bleg *legImpl, targetURI, localTag string, req OriginateRequest, sdpBody []byte) (*sip.Request, error) {
+// recordAttempt passes the outcome of an INVITE to the KPI recorder.
+// Answered legs are recorded when they end, for their talk time.
+func (o *Originator) recordAttempt(bleg *legImpl, route string, invited time.Time, result *OriginateResult) {
+	if o.cfg.KPIRecorder == nil {
+		return
+	}
+	record := func() {
+		info := bleg.Info()
+		ended := info.TerminatedAt
+		if ended.IsZero() {
+			ended = time.Now()
+		}
+		o.cfg.KPIRecorder.RecordAttempt(kpi.Attempt{
+			Route:    route,
+			Started:  invited,
+			Ringing:  info.RingingAt,
+			Answered: info.AnsweredAt,
+			Ended:    ended,
+			SIPCode:  result.SIPCode,
+		})
+	}
+	if !result.Success {
+		record()
+		return
+	}
+
+	var once sync.Once
+	bleg.OnTerminated(func(TerminationCause) { once.Do(record) })
+	// Callbacks registered after termination never run
+	if bleg.GetState() == LegStateDestroyed {
+		once.Do(record)
+	}
+}
+
+// kpiRoute names the route an attempt is counted under: the gateway
+// dialed, the host of a SIP URI dialed directly (a trunk), or "user" for
+// all registered users.
+func kpiRoute(target *LookupResult) string {
+	switch target.Type {
+	case LookupResultTypeGateway:
+		return target.Original
+	case LookupResultTypeDirect:
+		if host := uriHost(target.PrimaryContact().URI); host != "" {
+			return host
+		}
+		return "direct"
+	}
+	return "user"
+}
+
+// buildINVITE constructs the outbound INVITE request. routes, when set,
+// become the Route headers and the first one is the next hop.
+func (o *Originator) buildINVITE(bleg *legImpl, targetURI string, routes []sip.Uri, localTag string, req OriginateRequest, sdpBody []byte) (*sip.Request, error) {
 	// Parse target URI
 	var requestURI sip.Uri
-	if err := sip.ParseUri(targetURI, &requestURI); err != nil {
+	if err := sipaddr.ParseURI(targetURI, &requestURI); err != nil {
 		return nil, fmt.Errorf("invalid target URI: %w", err)
 	}
 
 	invite := sip.NewRequest(sip.INVITE, requestURI)
+	nextHopHost := requestURI.Host
+	if len(routes) > 0 {
+		addRouteSet(invite, routes)
+		nextHopHost = routes[0].Host
+	}
+	localHost := o.localHostFor(nextHopHost)
+	if sipaddr.IsIPv6(localHost) {
+		addIPv6Via(invite, localHost, o.cfg.Port)
+	}
 
-	// Max-Forwards (RFC 3261 Section 8.1.1.6)
+	// Max-Forwards (RFC 3261 Section 8.1.1.6), one less than the A-leg's
+	// so that loops through other servers run out of hops (RFC 3261
+	// Section 16.6)
 	maxFwd := sip.MaxForwardsHeader(70)
+	if req.ALegInvite != nil {
+		if mf := req.ALegInvite.MaxForwards(); mf != nil && *mf > 0 {
+			maxFwd = *mf - 1
+		}
+	}
 	invite.AppendHeader(&maxFwd)
 
 	// From header - our identity with tag
 	fromURI := sip.Uri{
 		Scheme: "sip",
 		User:   req.CallerID,
-		Host:   o.cfg.AdvertiseAddr,
+		Host:   localHost,
 		Port:   o.cfg.Port,
 	}
 	fromParams := sip.NewParams()
@@ -260,7 +424,7 @@ func (o *Originator) buildINVITE(bleg *legImpl, targetURI, localTag string, req
 
 	// To header - their identity (no tag yet)
 	var toURI sip.Uri
-	_ = sip.ParseUri(targetURI, &toURI) // Error already handled during requestURI parsing above
+	_ = sipaddr.ParseURI(targetURI, &toURI) // Error already handled during requestURI parsing above
 	toHdr := &sip.ToHeader{
 		Address: toURI,
 		Params:  sip.NewParams(),
@@ -282,7 +446,7 @@ func (o *Originator) buildINVITE(bleg *legImpl, targetURI, localTag string, req
 	contactURI := sip.Uri{
 		Scheme: "sip",
 		User:   "switchboard",
-		Host:   o.cfg.AdvertiseAddr,
+		Host:   localHost,
 		Port:   o.cfg.Port,
 	}
 	contactHdr := &sip.ContactHeader{
@@ -290,27 +454,44 @@ func (o *Originator) buildINVITE(bleg *legImpl, targetURI, localTag string, req
 	}
 	invite.AppendHeader(contactHdr)
 
+	// Extra headers, in a stable order
+	for _, name := range slices.Sorted(maps.Keys(req.Headers)) {
+		invite.AppendHeader(sip.NewHeader(name, req.Headers[name]))
+	}
+
 	// Content-Type for SDP
 	contentType := sip.ContentTypeHeader("application/sdp")
 	invite.AppendHeader(&contentType)
 
 	// SDP body
-	invite.SetBody(sdpBody)
+	invite.SetBody(topology.HiddenSDP(o.offer(sdpBody, req)))
 
 	return invite, nil
 }
 
 // executeINVITE sends the INVITE and handles the complete response flow.
-func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *sip.Request, _ string, timeout time.Duration) *OriginateResult {
+// targets, when set, are destinations to try in order; the next one is
+// tried when the current one does not respond within familyFallbackDelay.
+func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *sip.Request, req OriginateRequest, targets []string) *OriginateResult {
 	// Transition to Ringing state (we're about to send INVITE)
 	_ = bleg.TransitionTo(LegStateCreated)
 
 	// Create timeout context
-	dialCtx, cancel := context.WithTimeout(ctx, timeout)
+	dialCtx, cancel := context.WithTimeout(ctx, req.Timeout)
 	defer cancel()
 
 	// Send INVITE via sipgo client transaction
+	host := strings.Trim(invite.Recipient.Host, "[]")
+	target := 0
+	if len(targets) > 0 {
+		invite.SetDestination(targets[0])
+	}
 	tx, err := o.cfg.Client.TransactionRequest(dialCtx, invite)
+	for err != nil && target+1 < len(targets) {
+		target++
+		invite = o.retarget(invite, targets[target])
+		tx, err = o.cfg.Client.TransactionRequest(dialCtx, invite)
+	}
 	if err != nil {
 		_ = bleg.TransitionTo(LegStateFailed)
 		bleg.SetSIPResponse(503, "Transaction failed")
@@ -321,20 +502,59 @@ func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *s
 			Error:     err,
 		}
 	}
+	o.sent(invite, req)
 
 	slog.Info("[Originate] INVITE sent",
 		"bleg_call_id", bleg.callID,
 		"target", invite.Recipient.String(),
+		"destination", invite.Destination(),
 	)
 
+	// Until something answers, fall back to the next address when one is silent
+	var fallback <-chan time.Time
+	if target+1 < len(targets) {
+		fallback = time.After(familyFallbackDelay)
+	}
+	answered := false
+
 	// Response handling loop
 	for {
 		select {
+		case <-fallback:
+			fallback = nil
+			if answered || target+1 >= len(targets) {
+				continue
+			}
+			target++
+			slog.Info("[Originate] No response, trying next address",
+				"bleg_call_id", bleg.callID,
+				"silent", invite.Destination(),
+				"next", targets[target],
+			)
+			next := o.retarget(invite, targets[target])
+			nextTx, err := o.cfg.Client.TransactionRequest(dialCtx, next)
+			if err != nil {
+				slog.Warn("[Originate] Fallback INVITE failed",
+					"bleg_call_id", bleg.callID,
+					"destination", targets[target],
+					"error", err,
+				)
+				continue
+			}
+			o.sent(next, req)
+			// The silent address may still answer: it is CANCELed if it
+			// rings, and a 2xx is released
+			o.watchLateAnswers(bleg, invite, tx, "", true)
+			invite, tx = next, nextTx
+			if target+1 < len(targets) {
+				fallback = time.After(familyFallbackDelay)
+			}
+
 		case <-dialCtx.Done():
 			// Timeout or cancellation
 			if ctx.Err() != nil {
 				// Parent context canceled (A leg hung up)
-				_ = o.sendCANCEL(bleg, invite, tx)
+				o.cancelINVITE(bleg, invite, tx, cancelReason(ctx, bleg))
 				_ = bleg.TransitionTo(LegStateFailed)
 				bleg.SetTerminationCause(TerminationCauseCancel)
 				return &OriginateResult{
@@ -345,7 +565,7 @@ func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *s
 				}
 			}
 			// Dial timeout
-			_ = o.sendCANCEL(bleg, invite, tx)
+			o.cancelINVITE(bleg, invite, tx, cancelReason(ctx, bleg))
 			_ = bleg.TransitionTo(LegStateFailed)
 			bleg.SetTerminationCause(TerminationCauseTimeout)
 			return &OriginateResult{
@@ -366,8 +586,27 @@ func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *s
 					Error:     fmt.Errorf("no response received"),
 				}
 			}
+			if o.cfg.HeaderPolicy != nil {
+				o.cfg.HeaderPolicy.ReceivedResponse(resp, responseHost(resp))
+			}
 
-			result := o.handleResponse(ctx, bleg, resp, invite, tx)
+			if !answered && len(targets) > 0 {
+				answered = true
+				o.families.answered(host, invite.Destination())
+			}
+
+			result := o.handleResponse(ctx, bleg, resp, invite, tx, req.OnProgress)
+			if result != nil && result.Success {
+				// A forking proxy may pass on 2xx responses from other
+				// branches too; only this one is kept
+				_, _, _, remoteTag, _ := bleg.GetOutboundDialogState()
+				o.watchLateAnswers(bleg, invite, tx, remoteTag, false)
+				if dialCtx.Err() != nil {
+					// Answered as the dial was given up: the 2xx and our
+					// CANCEL (or the A-leg's hangup) crossed
+					return o.hangupCrossedAnswer(ctx, bleg)
+				}
+			}
 			if result != nil {
 				return result
 			}
@@ -399,9 +638,31 @@ func (o *Originator) executeINVITE(ctx context.Context, bleg *legImpl, invite *s
 	}
 }
 
+// sent tells the loop detector (if any) about an INVITE sent for req.
+func (o *Originator) sent(invite *sip.Request, req OriginateRequest) {
+	if o.cfg.LoopDetector != nil {
+		o.cfg.LoopDetector.Sent(invite, req.ALegInvite)
+	}
+}
+
+// retarget copies an INVITE for another destination with a fresh Via branch.
+func (o *Originator) retarget(invite *sip.Request, destination string) *sip.Request {
+	next := invite.Clone()
+	next.SetBody(invite.Body()) // Clone does not copy the body
+	next.RemoveHeader("Via")
+	// A Via set before sending (IPv6, topology hiding) names our From host;
+	// one filled by sipgo from the socket address is filled again
+	if via, from := invite.Via(), invite.From(); via != nil && from != nil &&
+		(sipaddr.IsIPv6(via.Host) || via.Host == from.Address.Host) {
+		addIPv6Via(next, via.Host, via.Port)
+	}
+	next.SetDestination(destination)
+	return next
+}
+
 // handleResponse processes a SIP response.
 // Returns nil to continue waiting, or a Result to stop.
-func (o *Originator) handleResponse(ctx context.Context, bleg *legImpl, resp *sip.Response, invite *sip.Request, tx sip.ClientTransaction) *OriginateResult {
+func (o *Originator) handleResponse(ctx context.Context, bleg *legImpl, resp *sip.Response, invite *sip.Request, tx sip.ClientTransaction, onProgress func(Leg, LegState)) *OriginateResult {
 	statusCode := int(resp.StatusCode)
 
 	slog.Debug("[Originate] Response received",
@@ -420,6 +681,9 @@ func (o *Originator) handleResponse(ctx context.Context, bleg *legImpl, resp *si
 		// 180 Ringing / 181 Call Being Forwarded
 		_ = bleg.TransitionTo(LegStateRinging)
 		slog.Info("[Originate] Ringing", "bleg_call_id", bleg.callID)
+		if onProgress != nil {
+			onProgress(bleg, LegStateRinging)
+		}
 		return nil
 
 	case statusCode == 183:
@@ -427,15 +691,21 @@ func (o *Originator) handleResponse(ctx context.Context, bleg *legImpl, resp *si
 		_ = bleg.TransitionTo(LegStateEarlyMedia)
 
 		// Extract SDP for early media
+		progress := LegStateRinging
 		if resp.Body() != nil {
 			if err := o.extractRemoteMedia(ctx, bleg, resp); err != nil {
 				slog.Warn("[Originate] Early media setup failed",
 					"bleg_call_id", bleg.callID,
 					"error", err,
 				)
+			} else {
+				progress = LegStateEarlyMedia
 			}
 		}
 		slog.Info("[Originate] Early media", "bleg_call_id", bleg.callID)
+		if onProgress != nil {
+			onProgress(bleg, progress)
+		}
 		return nil
 
 	case statusCode >= 200 && statusCode < 300:
@@ -461,6 +731,145 @@ func (o *Originator) handleResponse(ctx context.Context, bleg *legImpl, resp *si
 	return nil
 }
 
+// hangupCrossedAnswer hangs up a leg that answered just as its dial was
+// canceled or timed out, sending BYE and releasing its media and dialog.
+func (o *Originator) hangupCrossedAnswer(ctx context.Context, bleg *legImpl) *OriginateResult {
+	slog.Info("[Originate] Answered after dial ended, hanging up",
+		"bleg_call_id", bleg.callID,
+	)
+	result := &OriginateResult{
+		Success:   false,
+		SIPCode:   408,
+		SIPReason: "Request Timeout",
+		Error:     context.DeadlineExceeded,
+	}
+	cause := TerminationCauseTimeout
+	if ctx.Err() != nil {
+		result.SIPCode, result.SIPReason, result.Error = 487, "Request Terminated", ctx.Err()
+		cause = TerminationCauseCancel
+	}
+	_ = bleg.Hangup(context.Background(), cause)
+	return result
+}
+
+// cancelINVITE sends CANCEL for a ringing INVITE. The callee may have
+// answered before the CANCEL arrived, in which case the INVITE still gets a
+// 2xx; per RFC 3261 Section 9.1 that dialog is acknowledged and then ended
+// with BYE so the callee is not left in an answered call. reason is the
+// Reason header of the CANCEL.
+func (o *Originator) cancelINVITE(bleg *legImpl, invite *sip.Request, tx sip.ClientTransaction, reason sipreason.Reason) {
+	if err := o.sendCANCEL(bleg, invite, tx, reason); err != nil {
+		slog.Warn("[Originate] CANCEL failed",
+			"bleg_call_id", bleg.callID,
+			"error", err,
+		)
+	}
+	o.watchLateAnswers(bleg, invite, tx, "", false)
+}
+
+// cancelReason returns the Reason header of the CANCEL ending a dial: the
+// call was answered by another leg of a fork, the caller hung up, or the
+// callee did not answer in time (ctx is the parent of the dial's context)
+func cancelReason(ctx context.Context, bleg *legImpl) sipreason.Reason {
+	cause := context.Cause(ctx)
+	switch {
+	case errors.Is(cause, ErrAnsweredElsewhere):
+		return sipreason.SIP(200, "Call completed elsewhere")
+	case cause != nil && !errors.Is(cause, context.DeadlineExceeded):
+		return sipreason.Q850(sipreason.CauseNormalClearing)
+	}
+	if state := bleg.GetState(); state == LegStateRinging || state == LegStateEarlyMedia {
+		return sipreason.Q850(sipreason.CauseNoAnswer)
+	}
+	return sipreason.Q850(sipreason.CauseNoUserResponding)
+}
+
+// watchLateAnswers watches an INVITE in the background for 2xx responses
+// after the dial has ended. A 2xx with the accepted To tag is a
+// retransmission and is acknowledged again. Any other 2xx established a
+// dialog nobody wants: one that crossed our CANCEL, or an extra branch of
+// a forking proxy (RFC 3261 Section 13.2.2.4). Each of those is
+// acknowledged and immediately ended with BYE. With cancel, the INVITE was
+// given up before any response and is CANCELed on its first provisional
+// response, the earliest a CANCEL may be sent (RFC 3261 Section 9.1).
+func (o *Originator) watchLateAnswers(bleg *legImpl, invite *sip.Request, tx sip.ClientTransaction, acceptedTag string, cancel bool) {
+	go func() {
+		released := make(map[string]*legImpl)
+		if acceptedTag != "" {
+			released[acceptedTag] = bleg
+		}
+
+		wait := o.cfg.InviteTimeout
+		if wait <= 0 {
+			wait = defaultInviteTimeout
+		}
+		timeout := time.NewTimer(wait)
+		defer timeout.Stop()
+		for {
+			select {
+			case resp := <-tx.Responses():
+				if resp == nil {
+					return
+				}
+				if cancel && resp.IsProvisional() {
+					cancel = false
+					if err := o.cancelRequest(bleg, invite, sipreason.Q850(sipreason.CauseNormalClearing)); err != nil {
+						slog.Warn("[Originate] CANCEL of abandoned INVITE failed",
+							"bleg_call_id", bleg.callID,
+							"destination", invite.Destination(),
+							"error", err,
+						)
+					}
+					continue
+				}
+				if !resp.IsSuccess() {
+					continue
+				}
+				var tag string
+				if to := resp.To(); to != nil {
+					tag, _ = to.Params.Get("tag")
+				}
+				if leg, ok := released[tag]; ok {
+					_ = o.sendACK(leg, resp, invite, tx)
+					continue
+				}
+				released[tag] = o.releaseLateAnswer(bleg, resp, invite, tx)
+			case <-tx.Done():
+				return
+			case <-timeout.C:
+				return
+			}
+		}
+	}()
+}
+
+// releaseLateAnswer acknowledges an unwanted 2xx and immediately sends BYE
+// for the dialog it established. The dialog is tracked on its own leg so
+// bleg keeps the dialog that was accepted, if any.
+func (o *Originator) releaseLateAnswer(bleg *legImpl, resp *sip.Response, invite *sip.Request, tx sip.ClientTransaction) *legImpl {
+	slog.Info("[Originate] Releasing unwanted 2xx with ACK and BYE",
+		"bleg_call_id", bleg.callID,
+		"status", resp.StatusCode,
+		"source", resp.Source(),
+	)
+	leg, _ := NewOutboundLeg(bleg.callID, bleg.toURI)
+	extra := leg.(*legImpl)
+	o.setOutboundDialogState(extra, resp, invite)
+	if err := o.sendACK(extra, resp, invite, tx); err != nil {
+		slog.Warn("[Originate] ACK for unwanted 2xx failed",
+			"bleg_call_id", bleg.callID,
+			"error", err,
+		)
+	}
+	if err := o.SendBYE(extra); err != nil {
+		slog.Warn("[Originate] BYE for unwanted 2xx failed",
+			"bleg_call_id", bleg.callID,
+			"error", err,
+		)
+	}
+	return extra
+}
+
 // handle2xx processes a successful response.
 func (o *Originator) handle2xx(ctx context.Context, bleg *legImpl, resp *sip.Response, invite *sip.Request, tx sip.ClientTransaction) *OriginateResult {
 	bleg.SetSIPResponse(int(resp.StatusCode), resp.Reason)
@@ -499,11 +908,51 @@ func (o *Originator) handle2xx(ctx context.Context, bleg *legImpl, resp *sip.Res
 
 	// Also store dialog state in legImpl for backwards compatibility
 	// (will be removed once migration is complete)
+	o.setOutboundDialogState(bleg, resp, invite)
+	remoteContactURI, remoteToURI, localFromURI, remoteTag, localTag := bleg.GetOutboundDialogState()
+
+	// Send ACK per RFC 3261 Section 13.2.2.4
+	if err := o.sendACK(bleg, resp, invite, tx); err != nil {
+		slog.Error("[Originate] Failed to send ACK",
+			"bleg_call_id", bleg.callID,
+			"error", err,
+		)
+		// Still mark as answered - ACK failure doesn't negate the 200 OK
+	}
+
+	_ = bleg.TransitionTo(LegStateAnswered)
+
+	slog.Info("[Originate] Call answered",
+		"bleg_call_id", bleg.callID,
+		"remote_addr", bleg.remoteRTPAddr,
+		"remote_port", bleg.remoteRTPPort,
+		"remote_contact", remoteContactURI,
+		"remote_to_uri", remoteToURI,
+		"local_from_uri", localFromURI,
+		"remote_tag", remoteTag,
+		"local_tag", localTag,
+	)
+
+	return &OriginateResult{
+		Success: true,
+		SIPCode: int(resp.StatusCode),
+	}
+}
+
+// setOutboundDialogState stores on the leg what in-dialog requests (ACK,
+// BYE) need from a 2xx: remote target, URIs, tags and route set.
+func (o *Originator) setOutboundDialogState(bleg *legImpl, resp *sip.Response, invite *sip.Request) {
 	var remoteContactURI, remoteToURI, localFromURI, remoteTag, localTag string
 
 	// Remote Contact from 200 OK - used as Request-URI for BYE
 	if contact := resp.Contact(); contact != nil {
 		remoteContactURI = contact.Address.String()
+
+		// RFC 5626 Section 5.4: an "ob" Contact asks for in-dialog
+		// requests over the flow the response arrived on
+		if _, ok := contact.Address.UriParams.Get("ob"); ok {
+			bleg.SetFlow(resp.Source())
+		}
 	}
 
 	// Remote To URI from INVITE - used as To header in BYE
@@ -534,31 +983,14 @@ func (o *Originator) handle2xx(ctx context.Context, bleg *legImpl, resp *sip.Res
 
 	bleg.SetOutboundDialogState(remoteContactURI, remoteToURI, localFromURI, remoteTag, localTag)
 
-	// Send ACK per RFC 3261 Section 13.2.2.4
-	if err := o.sendACK(bleg, resp, invite, tx); err != nil {
-		slog.Error("[Originate] Failed to send ACK",
-			"bleg_call_id", bleg.callID,
-			"error", err,
-		)
-		// Still mark as answered - ACK failure doesn't negate the 200 OK
-	}
-
-	_ = bleg.TransitionTo(LegStateAnswered)
-
-	slog.Info("[Originate] Call answered",
-		"bleg_call_id", bleg.callID,
-		"remote_addr", bleg.remoteRTPAddr,
-		"remote_port", bleg.remoteRTPPort,
-		"remote_contact", remoteContactURI,
-		"remote_to_uri", remoteToURI,
-		"local_from_uri", localFromURI,
-		"remote_tag", remoteTag,
-		"local_tag", localTag,
-	)
-
-	return &OriginateResult{
-		Success: true,
-		SIPCode: int(resp.StatusCode),
+	// RFC 3261 Section 12.1.2: the route set is the 2xx Record-Route, reversed
+	if rr := headerValues(resp, "Record-Route"); len(rr) > 0 {
+		if routeSet, err := parseRouteSet(rr); err == nil {
+			slices.Reverse(routeSet)
+			bleg.SetRouteSet(routeSet)
+		} else {
+			slog.Warn("[Originate] Ignoring invalid Record-Route", "bleg_call_id", bleg.callID, "error", err)
+		}
 	}
 }
 
@@ -595,10 +1027,12 @@ func (o *Originator) sendACK(bleg *legImpl, resp *sip.Response, invite *sip.Requ
 
 	// Build ACK request with correct Request-URI
 	ack := sip.NewRequest(sip.ACK, requestURI)
+	addRouteSet(ack, bleg.RouteSet())
 
 	// Copy From, Call-ID from INVITE (required for dialog matching)
 	sip.CopyHeaders("From", invite, ack)
 	sip.CopyHeaders("Call-ID", invite, ack)
+	sip.CopyHeaders("User-Agent", invite, ack)
 
 	// To header with tag from response (required for dialog identification)
 	if to := resp.To(); to != nil {
@@ -633,9 +1067,9 @@ func (o *Originator) sendACK(bleg *legImpl, resp *sip.Response, invite *sip.Requ
 				if rportStr, ok := via.Params.Get("rport"); ok {
 					_, _ = fmt.Sscanf(rportStr, "%d", &rport)
 				}
-				destAddr = fmt.Sprintf("%s:%d", received, rport)
+				destAddr = sipaddr.HostPort(received, rport)
 			} else {
-				destAddr = fmt.Sprintf("%s:%d", via.Host, via.Port)
+				destAddr = sipaddr.HostPort(via.Host, via.Port)
 			}
 		}
 	}
@@ -644,7 +1078,7 @@ func (o *Originator) sendACK(bleg *legImpl, resp *sip.Response, invite *sip.Requ
 		if port == 0 {
 			port = 5060
 		}
-		destAddr = fmt.Sprintf("%s:%d", requestURI.Host, port)
+		destAddr = sipaddr.HostPort(requestURI.Host, port)
 	}
 
 	// Set destination on request so transport layer knows where to send
@@ -680,10 +1114,16 @@ func (o *Originator) sendACK(bleg *legImpl, resp *sip.Response, invite *sip.Requ
 	return nil
 }
 
-// sendCANCEL sends a CANCEL for an in-progress INVITE.
-func (o *Originator) sendCANCEL(bleg *legImpl, invite *sip.Request, _ sip.ClientTransaction) error {
+// sendCANCEL sends a CANCEL for an in-progress INVITE, with a Reason
+// header (RFC 3326).
+func (o *Originator) sendCANCEL(bleg *legImpl, invite *sip.Request, _ sip.ClientTransaction, reason sipreason.Reason) error {
 	_ = bleg.TransitionTo(LegStateFailed)
+	return o.cancelRequest(bleg, invite, reason)
+}
 
+// cancelRequest sends a CANCEL for invite and waits for its response,
+// leaving the leg's state alone.
+func (o *Originator) cancelRequest(bleg *legImpl, invite *sip.Request, reason sipreason.Reason) error {
 	// Build CANCEL from original INVITE
 	cancelReq := sip.NewRequest(sip.CANCEL, invite.Recipient)
 
@@ -692,6 +1132,7 @@ func (o *Originator) sendCANCEL(bleg *legImpl, invite *sip.Request, _ sip.Client
 	sip.CopyHeaders("From", invite, cancelReq)
 	sip.CopyHeaders("To", invite, cancelReq)
 	sip.CopyHeaders("Call-ID", invite, cancelReq)
+	sip.CopyHeaders("User-Agent", invite, cancelReq)
 
 	// CSeq with same number but CANCEL method
 	if cseq := invite.CSeq(); cseq != nil {
@@ -704,12 +1145,18 @@ func (o *Originator) sendCANCEL(bleg *legImpl, invite *sip.Request, _ sip.Client
 
 	maxFwd := sip.MaxForwardsHeader(70)
 	cancelReq.AppendHeader(&maxFwd)
+	reason.Set(cancelReq)
 
 	// Send CANCEL
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	cancelTx, err := o.cfg.Client.TransactionRequest(ctx, cancelReq)
+	// The CANCEL goes where the INVITE went, and ClientRequestBuild keeps
+	// its CSeq equal to the INVITE's instead of incrementing it
+	if dest := invite.Destination(); dest != "" {
+		cancelReq.SetDestination(dest)
+	}
+	cancelTx, err := o.cfg.Client.TransactionRequest(ctx, cancelReq, sipgo.ClientRequestBuild)
 	if err != nil {
 		return fmt.Errorf("send CANCEL: %w", err)
 	}
@@ -753,7 +1200,7 @@ func (o *Originator) SendBYE(leg Leg) error {
 	// Build BYE request per RFC 3261 Section 15.1.1
 	// Request-URI is from Contact header in 200 OK
 	var requestURI sip.Uri
-	if err := sip.ParseUri(remoteContactURI, &requestURI); err != nil {
+	if err := sipaddr.ParseURI(remoteContactURI, &requestURI); err != nil {
 		slog.Error("[Originate] Failed to parse remote contact URI",
 			"bleg_call_id", bleg.callID,
 			"uri", remoteContactURI,
@@ -765,7 +1212,7 @@ func (o *Originator) SendBYE(leg Leg) error {
 	// To URI is from INVITE's To header (the original target)
 	var toURI sip.Uri
 	if remoteToURI != "" {
-		if err := sip.ParseUri(remoteToURI, &toURI); err != nil {
+		if err := sipaddr.ParseURI(remoteToURI, &toURI); err != nil {
 			slog.Warn("[Originate] Failed to parse remote To URI, using contact URI",
 				"bleg_call_id", bleg.callID,
 				"uri", remoteToURI,
@@ -780,7 +1227,7 @@ func (o *Originator) SendBYE(leg Leg) error {
 	// From URI is from INVITE's From header (must match for dialog identification)
 	var fromURI sip.Uri
 	if localFromURI != "" {
-		if err := sip.ParseUri(localFromURI, &fromURI); err != nil {
+		if err := sipaddr.ParseURI(localFromURI, &fromURI); err != nil {
 			slog.Warn("[Originate] Failed to parse local From URI, using default",
 				"bleg_call_id", bleg.callID,
 				"uri", localFromURI,
@@ -790,7 +1237,7 @@ func (o *Originator) SendBYE(leg Leg) error {
 			fromURI = sip.Uri{
 				Scheme: "sip",
 				User:   "switchboard",
-				Host:   o.cfg.AdvertiseAddr,
+				Host:   o.localHostFor(requestURI.Host),
 				Port:   o.cfg.Port,
 			}
 		}
@@ -799,7 +1246,7 @@ func (o *Originator) SendBYE(leg Leg) error {
 		fromURI = sip.Uri{
 			Scheme: "sip",
 			User:   "switchboard",
-			Host:   o.cfg.AdvertiseAddr,
+			Host:   o.localHostFor(requestURI.Host),
 			Port:   o.cfg.Port,
 		}
 	}
@@ -840,13 +1287,32 @@ func (o *Originator) SendBYE(leg Leg) error {
 	}
 	bye.AppendHeader(cseqHdr)
 
+	// Reason (RFC 3326) for the hangup
+	bleg.GetTerminationCause().Reason().Set(bye)
+
 	// Set destination address so sipgo uses the correct transport (listener socket on port 5060)
 	// The destination is derived from the Contact URI
 	port := requestURI.Port
 	if port == 0 {
 		port = 5060
 	}
-	destAddr := fmt.Sprintf("%s:%d", requestURI.Host, port)
+	destAddr := sipaddr.HostPort(requestURI.Host, port)
+	peer := requestURI.Host
+	if routeSet := bleg.RouteSet(); len(routeSet) > 0 {
+		addRouteSet(bye, routeSet)
+		hopPort := routeSet[0].Port
+		if hopPort == 0 {
+			hopPort = 5060
+		}
+		destAddr = sipaddr.HostPort(routeSet[0].Host, hopPort)
+		peer = routeSet[0].Host
+	}
+	if o.cfg.Identity != nil {
+		o.cfg.Identity.SentRequest(bye, peer)
+	}
+	if flow := bleg.Flow(); flow != "" {
+		destAddr = flow
+	}
 	bye.SetDestination(destAddr)
 
 	slog.Info("[Originate] Sending BYE",
@@ -902,31 +1368,66 @@ func (o *Originator) destroyMediaSession(ctx context.Context, bleg *legImpl) {
 	}
 }
 
-// extractRemoteMedia extracts the remote RTP endpoint from SDP.
+// offer returns the SDP offered to the B-leg: the RTP manager's, and in
+// passthrough mode the A-leg's streams other than audio too, laid out as
+// the A-leg offered them.
+func (o *Originator) offer(sdpBody []byte, req OriginateRequest) []byte {
+	if o.cfg.Video != sdpmedia.Passthrough || req.ALegInvite == nil {
+		return sdpBody
+	}
+	aLeg := &psdp.SessionDescription{}
+	if err := aLeg.Unmarshal(req.ALegInvite.Body()); err != nil || !slices.ContainsFunc(aLeg.MediaDescriptions, sdpmedia.IsRelayed) {
+		return sdpBody
+	}
+	offer, err := sdpmedia.Compose(sdpBody, aLeg, sdpmedia.Passthrough.Relay(aLeg))
+	if err != nil {
+		slog.Warn("[Originate] A-leg video not offered", "aleg_call_id", req.ALegCallID, "error", err)
+		return sdpBody
+	}
+	return offer
+}
+
+// offeredCodecs returns the payload types offered to a callee reached at
+// peer: the static ones of its codec policy, or PCMU without one.
+func (o *Originator) offeredCodecs(contact ResolvedContact, peer string) []string {
+	if o.cfg.Codecs == nil {
+		return []string{"0"}
+	}
+	endpoint := ""
+	if contact.Binding != nil {
+		endpoint = aorUser(contact.Binding.AOR)
+	}
+	policy := o.cfg.Codecs.Outbound(endpoint, peer)
+	if policy == nil {
+		return []string{"0"}
+	}
+	return policy.Payloads()
+}
+
+// extractRemoteMedia extracts the remote RTP endpoint from the audio of an
+// SDP answer.
 func (o *Originator) extractRemoteMedia(ctx context.Context, bleg *legImpl, resp *sip.Response) error {
 	if resp.Body() == nil {
 		return fmt.Errorf("no SDP in response")
 	}
 
-	sdpObj := &psdp.SessionDescription{}
-	if err := sdpObj.Unmarshal(resp.Body()); err != nil {
+	answer, err := sdpmedia.Parse(resp.Body())
+	if err != nil {
 		return fmt.Errorf("parse SDP: %w", err)
 	}
-
-	if len(sdpObj.MediaDescriptions) == 0 {
-		return fmt.Errorf("no media in SDP")
-	}
-
-	media := sdpObj.MediaDescriptions[0]
-	remotePort := media.MediaName.Port.Value
-
-	// Get address
-	var remoteAddr string
-	if media.ConnectionInformation != nil && media.ConnectionInformation.Address != nil {
-		remoteAddr = media.ConnectionInformation.Address.Address
-	} else if sdpObj.ConnectionInformation != nil && sdpObj.ConnectionInformation.Address != nil {
-		remoteAddr = sdpObj.ConnectionInformation.Address.Address
+	audio := answer.Audio()
+	if audio == nil {
+		return sdpmedia.ErrNoAudio
 	}
+	remoteAddr, remotePort := audio.Addr, audio.Port
+	codec, _ := audio.Codec()
+	slog.Debug("[Originate] Answered media",
+		"bleg_call_id", bleg.callID,
+		"codec", codec.String(),
+		"payload", codec.Payload,
+		"ptime", audio.Ptime,
+		"direction", audio.Direction,
+	)
 
 	bleg.SetRemoteMediaEndpoint(remoteAddr, remotePort)
 
@@ -952,6 +1453,16 @@ func (o *Originator) extractRemoteMedia(ctx context.Context, bleg *legImpl, resp
 	return nil
 }
 
+// associate maps an A-leg to the B-leg that won a fork, since every
+// forked leg registers itself for the A-leg when it is originated.
+func (o *Originator) associate(aLegCallID, bLegCallID string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if _, ok := o.legs[bLegCallID]; ok {
+		o.aToB[aLegCallID] = bLegCallID
+	}
+}
+
 // GetLegByALeg returns the B leg associated with an A leg.
 func (o *Originator) GetLegByALeg(aLegCallID string) Leg {
 	o.mu.RLock()
@@ -964,6 +1475,22 @@ func (o *Originator) GetLegByALeg(aLegCallID string) Leg {
 	return o.legs[bLegCallID]
 }
 
+// Legs returns the outbound legs in progress, ringing or answered.
+func (o *Originator) Legs() []*LegInfo {
+	o.mu.RLock()
+	legs := make([]*legImpl, 0, len(o.legs))
+	for _, leg := range o.legs {
+		legs = append(legs, leg)
+	}
+	o.mu.RUnlock()
+
+	infos := make([]*LegInfo, 0, len(legs))
+	for _, leg := range legs {
+		infos = append(infos, leg.Info())
+	}
+	return infos
+}
+
 // GetLegByCallID returns a B-leg by its Call-ID.
 // Returns nil if not found.
 func (o *Originator) GetLegByCallID(callID string) *legImpl {
@@ -1012,6 +1539,8 @@ func (o *Originator) HandleIncomingBYE(req *sip.Request, tx sip.ServerTransactio
 		"leg_state", bleg.GetState().String(),
 	)
 
+	bleg.SetRemoteReason(sipreason.FromRequest(req))
+
 	// Respond 200 OK
 	resp := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
 	if err := tx.Respond(resp); err != nil {
@@ -1067,3 +1596,39 @@ func generateCallID() string {
 func generateTag() string {
 	return uuid.New().String()[:8]
 }
+
+// localHostFor returns our host, formatted for SIP, as advertised to a peer host.
+func (o *Originator) localHostFor(host string) string {
+	if o.cfg.Advertise == nil {
+		return sipaddr.Host(o.cfg.AdvertiseAddr)
+	}
+	return sipaddr.Host(o.cfg.Advertise.Select(host))
+}
+
+// uriHost returns the host part of a SIP URI, or "" if it cannot be parsed.
+func uriHost(uri string) string {
+	var u sip.Uri
+	if err := sipaddr.ParseURI(uri, &u); err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// aorUser returns the user part of an AOR such as "sip:1001@example.com",
+// or the AOR itself if it has none.
+func aorUser(aor string) string {
+	var u sip.Uri
+	if err := sipaddr.ParseURI(aor, &u); err != nil || u.User == "" {
+		return aor
+	}
+	return u.User
+}
+
+// responseHost returns the host a response was received from.
+func responseHost(res *sip.Response) string {
+	host, _, err := net.SplitHostPort(res.Source())
+	if err != nil {
+		return res.Source()
+	}
+	return host
+}
@@ -0,0 +1,354 @@
+package b2bua_test
+
+import (
+	"bytes"
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/pkg/testkit"
+)
+
+// callee is a bare SIP UA that answers INVITEs as the test scripts it and
+// records the ACKs and BYEs it receives.
+type callee struct {
+	uri  string
+	conn *holdConn
+	acks chan *sip.Request
+	byes chan *sip.Request
+}
+
+// newCallee starts a callee. With holdCANCEL set, CANCELs are held back
+// from its SIP stack until released, so a 2xx sent in between crosses them.
+func newCallee(t *testing.T, holdCANCEL bool, onInvite func(c *callee, req *sip.Request, tx sip.ServerTransaction)) *callee {
+	t.Helper()
+	pc, err := net.ListenPacket("udp", net.JoinHostPort(testkit.Host, "0"))
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	port := pc.LocalAddr().(*net.UDPAddr).Port
+	ua, err := sipgo.NewUA(sipgo.WithUserAgent("callee"), sipgo.WithUserAgentHostname(testkit.Host))
+	if err != nil {
+		t.Fatalf("create user agent: %v", err)
+	}
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		t.Fatalf("create server: %v", err)
+	}
+
+	c := &callee{
+		uri:  (&sip.Uri{Scheme: "sip", User: "callee", Host: testkit.Host, Port: port}).String(),
+		conn: newHoldConn(pc, holdCANCEL),
+		acks: make(chan *sip.Request, 4),
+		byes: make(chan *sip.Request, 4),
+	}
+	srv.OnInvite(func(req *sip.Request, tx sip.ServerTransaction) { onInvite(c, req, tx) })
+	srv.OnAck(func(req *sip.Request, tx sip.ServerTransaction) { c.acks <- req })
+	srv.OnBye(func(req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil))
+		c.byes <- req
+	})
+	go func() { _ = srv.ServeUDP(c.conn) }()
+
+	t.Cleanup(func() {
+		_ = c.conn.Close()
+		_ = ua.Close()
+	})
+	return c
+}
+
+type packet struct {
+	data []byte
+	addr net.Addr
+}
+
+// holdConn passes packets through to the SIP stack, optionally holding
+// back CANCELs until they are released.
+type holdConn struct {
+	net.PacketConn
+	in   chan packet
+	held chan packet
+}
+
+func newHoldConn(pc net.PacketConn, holdCANCEL bool) *holdConn {
+	c := &holdConn{PacketConn: pc, in: make(chan packet, 16), held: make(chan packet, 4)}
+	go func() {
+		defer close(c.in)
+		buf := make([]byte, 65535)
+		for {
+			n, addr, err := pc.ReadFrom(buf)
+			if err != nil {
+				return
+			}
+			p := packet{data: append([]byte(nil), buf[:n]...), addr: addr}
+			if holdCANCEL && bytes.HasPrefix(p.data, []byte("CANCEL ")) {
+				c.held <- p
+				continue
+			}
+			c.in <- p
+		}
+	}()
+	return c
+}
+
+func (c *holdConn) ReadFrom(b []byte) (int, net.Addr, error) {
+	p, ok := <-c.in
+	if !ok {
+		return 0, nil, net.ErrClosed
+	}
+	return copy(b, p.data), p.addr, nil
+}
+
+// awaitCANCEL blocks until a CANCEL is held and returns a func that
+// delivers it to the SIP stack.
+func (c *callee) awaitCANCEL() (release func()) {
+	select {
+	case p := <-c.conn.held:
+		return func() { c.conn.in <- p }
+	case <-time.After(5 * time.Second):
+		return func() {}
+	}
+}
+
+// answer builds the callee's 200 OK for req.
+func (c *callee) answer(req *sip.Request) *sip.Response {
+	return c.answerAs(req, "callee")
+}
+
+// answerAs builds a 200 OK for req with To tag tag, as a forked branch would.
+func (c *callee) answerAs(req *sip.Request, tag string) *sip.Response {
+	resp := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", testkit.AudioSDP(testkit.Host, 40000, "PCMU"))
+	resp.To().Params.Add("tag", tag)
+	resp.AppendHeader(&sip.ContactHeader{Address: *req.Recipient.Clone()})
+	resp.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
+	return resp
+}
+
+func newOriginator(t *testing.T) *b2bua.Originator {
+	t.Helper()
+	ua, err := sipgo.NewUA(sipgo.WithUserAgent("switchboard"), sipgo.WithUserAgentHostname(testkit.Host))
+	if err != nil {
+		t.Fatalf("create user agent: %v", err)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname(testkit.Host))
+	if err != nil {
+		t.Fatalf("create client: %v", err)
+	}
+	t.Cleanup(func() { _ = ua.Close() })
+
+	return b2bua.NewOriginator(b2bua.OriginatorConfig{
+		AdvertiseAddr: testkit.Host,
+		Port:          5060,
+		Transport:     testkit.NewTransport(),
+		Client:        client,
+	})
+}
+
+func directTarget(uri string) *b2bua.LookupResult {
+	return &b2bua.LookupResult{
+		Type:     b2bua.LookupResultTypeDirect,
+		Original: uri,
+		Contacts: []b2bua.ResolvedContact{{URI: uri}},
+	}
+}
+
+func waitRequest(t *testing.T, ch <-chan *sip.Request, what string) *sip.Request {
+	t.Helper()
+	select {
+	case req := <-ch:
+		return req
+	case <-time.After(5 * time.Second):
+		t.Fatalf("callee received no %s", what)
+		return nil
+	}
+}
+
+func expectNoRequest(t *testing.T, ch <-chan *sip.Request, what string) {
+	t.Helper()
+	select {
+	case req := <-ch:
+		t.Errorf("callee received unexpected %s: %s", what, req.StartLine())
+	case <-time.After(500 * time.Millisecond):
+	}
+}
+
+func TestOriginateCANCELCrossing200(t *testing.T) {
+	// The callee answers after the caller gave up on the ringing leg but
+	// before the CANCEL reached it
+	c := newCallee(t, true, func(c *callee, req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusRinging, "Ringing", nil))
+		release := c.awaitCANCEL()
+		_ = tx.Respond(c.answer(req))
+		release()
+	})
+	o := newOriginator(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, err := o.Originate(ctx, b2bua.OriginateRequest{
+		Target:  directTarget(c.uri),
+		Timeout: 5 * time.Second,
+		OnProgress: func(leg b2bua.Leg, state b2bua.LegState) {
+			if state == b2bua.LegStateRinging {
+				cancel()
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Originate succeeded after cancel, want failure")
+	}
+	if result.SIPCode != 487 {
+		t.Errorf("SIPCode = %d, want 487", result.SIPCode)
+	}
+
+	ack := waitRequest(t, c.acks, "ACK")
+	bye := waitRequest(t, c.byes, "BYE")
+	if ack.CallID().Value() != bye.CallID().Value() {
+		t.Errorf("BYE Call-ID %q does not match ACK Call-ID %q", bye.CallID().Value(), ack.CallID().Value())
+	}
+	if tag, _ := bye.To().Params.Get("tag"); tag != "callee" {
+		t.Errorf("BYE To tag = %q, want %q", tag, "callee")
+	}
+}
+
+func TestOriginateTimeoutCrossing200(t *testing.T) {
+	// The callee answers after the dial timed out but before the CANCEL
+	// reached it
+	c := newCallee(t, true, func(c *callee, req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusRinging, "Ringing", nil))
+		release := c.awaitCANCEL()
+		_ = tx.Respond(c.answer(req))
+		release()
+	})
+	o := newOriginator(t)
+
+	result, err := o.Originate(context.Background(), b2bua.OriginateRequest{
+		Target:  directTarget(c.uri),
+		Timeout: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Originate succeeded after timeout, want failure")
+	}
+	if result.SIPCode != 408 {
+		t.Errorf("SIPCode = %d, want 408", result.SIPCode)
+	}
+
+	waitRequest(t, c.acks, "ACK")
+	waitRequest(t, c.byes, "BYE")
+}
+
+func TestOriginateCANCELRinging(t *testing.T) {
+	// A callee that never answers gets CANCEL and 487s; no dialog is
+	// established, so there is nothing to ACK or BYE
+	c := newCallee(t, false, func(c *callee, req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusRinging, "Ringing", nil))
+		<-tx.Done()
+	})
+	o := newOriginator(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	result, err := o.Originate(ctx, b2bua.OriginateRequest{
+		Target:  directTarget(c.uri),
+		Timeout: 5 * time.Second,
+		OnProgress: func(leg b2bua.Leg, state b2bua.LegState) {
+			if state == b2bua.LegStateRinging {
+				cancel()
+			}
+		},
+	})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if result.Success || result.SIPCode != 487 {
+		t.Errorf("result = %d success=%v, want 487 failure", result.SIPCode, result.Success)
+	}
+	expectNoRequest(t, c.byes, "BYE")
+}
+
+func TestOriginateCANCELReason(t *testing.T) {
+	// A dial that times out while ringing is canceled with Q.850 cause 19,
+	// no answer from user
+	cancels := make(chan []byte, 1)
+	c := newCallee(t, true, func(c *callee, req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusRinging, "Ringing", nil))
+		select {
+		case p := <-c.conn.held:
+			cancels <- p.data
+			c.conn.in <- p
+		case <-time.After(5 * time.Second):
+		}
+		<-tx.Done()
+	})
+	o := newOriginator(t)
+
+	result, err := o.Originate(context.Background(), b2bua.OriginateRequest{
+		Target:  directTarget(c.uri),
+		Timeout: 200 * time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if result.Success {
+		t.Fatal("Originate succeeded after timeout, want failure")
+	}
+	select {
+	case data := <-cancels:
+		if !bytes.Contains(data, []byte("\r\nReason: Q.850;cause=19;")) {
+			t.Errorf("CANCEL without Q.850 cause 19:\n%s", data)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("callee received no CANCEL")
+	}
+}
+
+func TestOriginateForked200(t *testing.T) {
+	// A forking proxy passes on 200 OKs from two branches; one is kept
+	// and the other is acknowledged and hung up
+	c := newCallee(t, false, func(c *callee, req *sip.Request, tx sip.ServerTransaction) {
+		_ = tx.Respond(c.answerAs(req, "branch-1"))
+		_ = tx.Respond(c.answerAs(req, "branch-2"))
+	})
+	o := newOriginator(t)
+
+	result, err := o.Originate(context.Background(), b2bua.OriginateRequest{
+		Target:  directTarget(c.uri),
+		Timeout: 5 * time.Second,
+	})
+	if err != nil {
+		t.Fatalf("Originate: %v", err)
+	}
+	if !result.Success {
+		t.Fatalf("Originate failed: %d %s", result.SIPCode, result.SIPReason)
+	}
+
+	acked := map[string]bool{}
+	for range 2 {
+		ack := waitRequest(t, c.acks, "ACK")
+		tag, _ := ack.To().Params.Get("tag")
+		acked[tag] = true
+	}
+	if !acked["branch-1"] || !acked["branch-2"] {
+		t.Errorf("ACKed branches = %v, want branch-1 and branch-2", acked)
+	}
+
+	// Either branch may be read first; the other one is hung up
+	_, _, _, kept, _ := result.Leg.(interface {
+		GetOutboundDialogState() (string, string, string, string, string)
+	}).GetOutboundDialogState()
+	bye := waitRequest(t, c.byes, "BYE")
+	if tag, _ := bye.To().Params.Get("tag"); tag == kept || !acked[tag] {
+		t.Errorf("BYE To tag = %q, want the branch other than the kept %q", tag, kept)
+	}
+	expectNoRequest(t, c.byes, "BYE")
+}
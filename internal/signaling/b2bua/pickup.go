@@ -0,0 +1,185 @@
+package b2bua
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sync"
+	"time"
+)
+
+// pickup is a call being dialed by dialAndBridge, which a party other
+// than its callees may answer with Pickup.
+type pickup struct {
+	picked chan Leg          // The picker's answered leg; buffered
+	result chan pickupResult // Outcome of the bridge with the picker; buffered
+}
+
+// pickupResult is the outcome of the bridge of a call picked up.
+type pickupResult struct {
+	info *BridgeInfo
+	err  error
+}
+
+// pickups are the calls that may be picked up, by A-leg Call-ID.
+type pickups struct {
+	mu    sync.Mutex
+	calls map[string]*pickup
+}
+
+// add makes the call of legA one that may be picked up.
+func (p *pickups) add(legA Leg) *pickup {
+	call := &pickup{
+		picked: make(chan Leg, 1),
+		result: make(chan pickupResult, 1),
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls[legA.CallID()] = call
+	return call
+}
+
+// take removes a call, so that it can be picked up or dialed only once,
+// and reports whether it was still there.
+func (p *pickups) take(aLegCallID string, call *pickup) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.calls[aLegCallID] != call {
+		return false
+	}
+	delete(p.calls, aLegCallID)
+	return true
+}
+
+// get returns the call of an A-leg, or nil.
+func (p *pickups) get(aLegCallID string) *pickup {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.calls[aLegCallID]
+}
+
+// PickedCall is a call answered with Pickup.
+type PickedCall struct {
+	// CallID is the Call-ID of the caller's leg
+	CallID string
+
+	// Callee is the user the call was ringing
+	Callee string
+
+	result chan pickupResult
+}
+
+// Wait blocks until the bridge of the call with the picker's leg
+// terminates, and returns its info. Call it at most once.
+func (c *PickedCall) Wait() (*BridgeInfo, error) {
+	r := <-c.result
+	return r.info, r.err
+}
+
+// RingingLegs returns the outbound legs ringing registered users, oldest
+// first, for calls that can be picked up: those dialed by DialAndBridge
+// or DialParallelAndBridge. With callees, only the legs ringing them.
+func (s *callService) RingingLegs(callees ...string) []*LegInfo {
+	var ringing []*LegInfo
+	for _, leg := range s.originator.Legs() {
+		if leg.State != LegStateRinging && leg.State != LegStateEarlyMedia || leg.Callee == "" {
+			continue
+		}
+		if len(callees) > 0 && !slices.Contains(callees, leg.Callee) {
+			continue
+		}
+		if s.pickups.get(leg.ALegCallID) != nil {
+			ringing = append(ringing, leg)
+		}
+	}
+	slices.SortFunc(ringing, func(a, b *LegInfo) int { return a.RingingAt.Compare(b.RingingAt) })
+	return ringing
+}
+
+// Pickup answers the call that has been ringing one of callees the
+// longest with leg, the answered leg of the party picking it up: the legs
+// ringing for the call are canceled with "Call completed elsewhere" and
+// the caller is bridged with leg instead. Returns once the call is handed
+// over; Wait on the result blocks until the bridge terminates. Returns
+// ErrNoRingingCall if no call to pick up rings any of callees.
+func (s *callService) Pickup(leg Leg, callees []string) (*PickedCall, error) {
+	if leg.GetState() != LegStateAnswered {
+		return nil, ErrLegNotAnswered
+	}
+	for _, ringing := range s.RingingLegs(callees...) {
+		call := s.pickups.get(ringing.ALegCallID)
+		if call == nil || ringing.ALegCallID == leg.CallID() || !s.pickups.take(ringing.ALegCallID, call) {
+			// Answered or picked up meanwhile
+			continue
+		}
+		call.picked <- leg
+		slog.Info("[CallService] Call picked up",
+			"aleg_call_id", ringing.ALegCallID,
+			"callee", ringing.Callee,
+			"leg", leg.ID(),
+		)
+		return &PickedCall{CallID: ringing.ALegCallID, Callee: ringing.Callee, result: call.result}, nil
+	}
+	return nil, ErrNoRingingCall
+}
+
+// DialPickup dials target and, once it answers, picks up a call ringing
+// one of callees with its leg as Pickup does. The target is dialed on the
+// RTP manager of the call, so that the two can be bridged. Returns
+// ErrNoRingingCall, without dialing, if no call rings any of callees.
+func (s *callService) DialPickup(ctx context.Context, target string, timeout time.Duration, callees []string) (*PickedCall, error) {
+	ringing := s.RingingLegs(callees...)
+	if len(ringing) == 0 {
+		return nil, ErrNoRingingCall
+	}
+	leg, err := s.Dial(ctx, target, timeout, WithALegSessionID(ringing[0].SessionID))
+	if err != nil {
+		return nil, err
+	}
+	picked, err := s.Pickup(leg, callees)
+	if err != nil {
+		_ = leg.Hangup(context.Background(), TerminationCauseCancel)
+		return nil, err
+	}
+	return picked, nil
+}
+
+// dialOrPickup runs dial for legA's call, which may be picked up
+// meanwhile: the dial is then canceled, and the picker's leg returned
+// instead with the call picked up. A callee that answers after the call
+// was picked up is hung up.
+func (s *callService) dialOrPickup(ctx context.Context, legA Leg, opts []LegOption, dial func(ctx context.Context, opts []LegOption) (Leg, error)) (Leg, *pickup, error) {
+	call := s.pickups.add(legA)
+
+	dialCtx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	type dialed struct {
+		leg Leg
+		err error
+	}
+	done := make(chan dialed, 1)
+	go func() {
+		leg, err := dial(dialCtx, opts)
+		done <- dialed{leg: leg, err: err}
+	}()
+
+	var result dialed
+	var picker Leg
+	select {
+	case result = <-done:
+		if s.pickups.take(legA.CallID(), call) {
+			return result.leg, nil, result.err
+		}
+		// Picked up as the dial ended
+		picker = <-call.picked
+	case picker = <-call.picked:
+		cancel(ErrAnsweredElsewhere)
+		result = <-done
+	}
+
+	if result.leg != nil {
+		_ = result.leg.Hangup(context.Background(), TerminationCauseCancel)
+	}
+	return picker, call, nil
+}
@@ -0,0 +1,75 @@
+package b2bua
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPickup(t *testing.T) {
+	s := &callService{
+		originator: NewOriginator(OriginatorConfig{}),
+		pickups:    pickups{calls: make(map[string]*pickup)},
+	}
+
+	legA, _ := NewOutboundLeg("caller", "sip:caller@192.0.2.1")
+	ringing, _ := NewOutboundLeg("ringing", "sip:1001@192.0.2.2")
+	bleg := ringing.(*legImpl)
+	bleg.aLegCallID, bleg.callee = "caller", "1001"
+	_ = bleg.TransitionTo(LegStateRinging)
+	s.originator.legs["ringing"] = bleg
+
+	picker, _ := NewOutboundLeg("picker", "sip:1002@192.0.2.3")
+	_ = picker.(*legImpl).TransitionTo(LegStateAnswered)
+
+	if _, err := s.Pickup(picker, []string{"1001"}); !errors.Is(err, ErrNoRingingCall) {
+		t.Fatalf("Pickup before the dial = %v, want ErrNoRingingCall", err)
+	}
+
+	canceled := make(chan error, 1)
+	type dialed struct {
+		leg    Leg
+		picked *pickup
+		err    error
+	}
+	done := make(chan dialed, 1)
+	go func() {
+		leg, picked, err := s.dialOrPickup(context.Background(), legA, nil, func(ctx context.Context, _ []LegOption) (Leg, error) {
+			<-ctx.Done()
+			canceled <- context.Cause(ctx)
+			return nil, ctx.Err()
+		})
+		done <- dialed{leg: leg, picked: picked, err: err}
+	}()
+
+	deadline := time.Now().Add(time.Second)
+	for len(s.RingingLegs("1001")) == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the call never became one to pick up")
+		}
+		time.Sleep(time.Millisecond)
+	}
+	if got := s.RingingLegs("1002"); len(got) != 0 {
+		t.Errorf("RingingLegs(1002) = %d legs, want none", len(got))
+	}
+
+	picked, err := s.Pickup(picker, []string{"1002", "1001"})
+	if err != nil {
+		t.Fatalf("Pickup: %v", err)
+	}
+	if picked.CallID != "caller" || picked.Callee != "1001" {
+		t.Errorf("picked %s ringing %s, want caller ringing 1001", picked.CallID, picked.Callee)
+	}
+	if cause := <-canceled; !errors.Is(cause, ErrAnsweredElsewhere) {
+		t.Errorf("dial canceled with %v, want ErrAnsweredElsewhere", cause)
+	}
+	result := <-done
+	if result.leg != picker || result.picked == nil || result.err != nil {
+		t.Errorf("dialOrPickup = %v, %v, %v; want the picker's leg", result.leg, result.picked, result.err)
+	}
+
+	if _, err := s.Pickup(picker, []string{"1001"}); !errors.Is(err, ErrNoRingingCall) {
+		t.Errorf("second Pickup = %v, want ErrNoRingingCall", err)
+	}
+}
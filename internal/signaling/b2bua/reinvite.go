@@ -0,0 +1,342 @@
+package b2bua
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+	psdp "github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+)
+
+// holdAddr is the connection address of an RFC 2543 hold
+const holdAddr = "0.0.0.0"
+
+// holdTimeout bounds starting or stopping music on hold
+const holdTimeout = 5 * time.Second
+
+// RelayReINVITE implements CallService.RelayReINVITE. The offer goes to
+// the other leg as our re-INVITE, with the formats and direction offered
+// and our media address on that side; its answer updates the other leg's
+// RTP session and is returned with our media address on this side, after
+// this leg's RTP session is pointed at the offered address. A rejection by
+// the other leg is returned as is, so both legs keep the session they had.
+//
+// Streams other than audio, such as video, are relayed to the other leg as
+// offered and answered with the other leg's answer when Video is
+// sdpmedia.Passthrough, and rejected otherwise.
+//
+// An offer putting the call on hold (sendonly, inactive or a 0.0.0.0
+// address) and the one taking it off hold are tracked on the bridge. With
+// HoldMusic, they are answered here instead and the other leg hears music,
+// with the streams other than audio rejected. While a leg is put on hold
+// with HoldLeg, offers from either leg are answered here.
+func (s *callService) RelayReINVITE(d *dialog.Dialog, offer []byte) *dialog.ReINVITEResult {
+	b, side, peer := s.bridgedPeer(d.CallID)
+	if b == nil {
+		return nil
+	}
+
+	offerSDP, addr, port, err := parseMedia(offer)
+	if err != nil {
+		slog.Warn("[B2BUA] Re-INVITE offer not relayed", "call_id", d.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+	direction := mediaDirection(offerSDP)
+	if b.HeldBy() == HeldLocally {
+		return s.answerHeld(d, b, side, offerSDP, direction, addr, port)
+	}
+	hold := direction == sdpmedia.SendOnly || direction == sdpmedia.Inactive
+	if s.cfg.HoldMusic != nil && (hold || b.HeldBy() == side) {
+		return s.holdLocally(d, b, side, offerSDP, direction, addr, port)
+	}
+
+	if peer.IsReINVITEInProgress() {
+		// The other leg is renegotiating too; let this one retry
+		return rejected(491, "Request Pending")
+	}
+	peerOffer, err := renegotiated(peer.LocalSDP(), offerSDP, direction)
+	if err == nil && s.cfg.Video == sdpmedia.Passthrough {
+		peerOffer, err = relayOthers(peerOffer, offerSDP.SDP)
+	}
+	if err != nil {
+		slog.Warn("[B2BUA] Re-INVITE offer not relayed", "call_id", d.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+
+	var contact sip.Uri
+	if err := sipaddr.ParseURI(s.cfg.LocalContact, &contact); err != nil {
+		slog.Error("[B2BUA] Invalid local contact", "contact", s.cfg.LocalContact, "error", err)
+		return rejected(sip.StatusInternalServerError, "Server Internal Error")
+	}
+
+	slog.Info("[B2BUA] Relaying re-INVITE to bridged leg",
+		"call_id", d.CallID,
+		"peer_call_id", peer.CallID,
+		"remote", fmt.Sprintf("%s:%d", addr, port),
+		"codecs", offerSDP.Audio().Payloads(),
+		"direction", direction,
+	)
+	timeout := s.cfg.InviteTimeout
+	if timeout == 0 {
+		timeout = defaultInviteTimeout
+	}
+	ctx, cancel := context.WithTimeout(d.Context(), timeout)
+	defer cancel()
+	result, err := s.cfg.DialogManager.SendReINVITE(ctx, peer, contact, dialog.ReINVITEOptions{SDP: peerOffer})
+	if err != nil {
+		slog.Warn("[B2BUA] Relayed re-INVITE failed", "call_id", d.CallID, "peer_call_id", peer.CallID, "error", err)
+		return rejected(sip.StatusInternalServerError, "Server Internal Error")
+	}
+	if !result.Success {
+		return result
+	}
+
+	answerSDP, peerAddr, peerPort, err := parseMedia(result.SDP)
+	if err != nil {
+		slog.Warn("[B2BUA] Relayed re-INVITE answer unusable", "peer_call_id", peer.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+	answer, err := renegotiated(d.LocalSDP(), answerSDP, mediaDirection(answerSDP))
+	if err == nil {
+		answer, err = s.answerOthers(answer, offerSDP.SDP, answerSDP.SDP)
+	}
+	if err != nil {
+		slog.Warn("[B2BUA] Relayed re-INVITE answer unusable", "peer_call_id", peer.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+
+	codec := firstFormat(answerSDP)
+	s.updateRemote(ctx, peer, peerAddr, peerPort, codec)
+	s.updateRemote(ctx, d, addr, port, codec)
+	switch {
+	case hold:
+		_ = b.Hold(ctx, side, nil)
+	case b.HeldBy() == side:
+		_ = b.Resume(ctx)
+	}
+
+	return &dialog.ReINVITEResult{Success: true, StatusCode: int(sip.StatusOK), Reason: "OK", SDP: answer}
+}
+
+// relayAnswered offers the A-leg of a call just bridged the streams other
+// than audio the B-leg answered, relayed from the B-leg's answer, by
+// re-INVITE. Nothing is sent if the B-leg accepted none.
+func (s *callService) relayAnswered(legA, legB Leg) {
+	a, b := legA.Dialog(), legB.Dialog()
+	if a == nil || b == nil || b.InviteResponse == nil {
+		return
+	}
+	answer := &psdp.SessionDescription{}
+	if err := answer.Unmarshal(b.InviteResponse.Body()); err != nil || !slices.ContainsFunc(answer.MediaDescriptions, sdpmedia.IsRelayed) {
+		return
+	}
+	local := &psdp.SessionDescription{}
+	if err := local.Unmarshal(a.LocalSDP()); err != nil {
+		slog.Warn("[B2BUA] Answered video not relayed", "call_id", a.CallID, "error", err)
+		return
+	}
+	local.Origin.SessionVersion++
+	body, err := local.Marshal()
+	if err == nil {
+		body, err = sdpmedia.Compose(body, local, sdpmedia.Passthrough.Relay(answer))
+	}
+	var contact sip.Uri
+	if err == nil {
+		err = sipaddr.ParseURI(s.cfg.LocalContact, &contact)
+	}
+	if err != nil {
+		slog.Warn("[B2BUA] Answered video not relayed", "call_id", a.CallID, "error", err)
+		return
+	}
+
+	timeout := s.cfg.InviteTimeout
+	if timeout == 0 {
+		timeout = defaultInviteTimeout
+	}
+	ctx, cancel := context.WithTimeout(a.Context(), timeout)
+	defer cancel()
+	result, err := s.cfg.DialogManager.SendReINVITE(ctx, a, contact, dialog.ReINVITEOptions{SDP: body})
+	switch {
+	case err != nil:
+		slog.Warn("[B2BUA] Answered video not relayed", "call_id", a.CallID, "error", err)
+	case !result.Success:
+		slog.Info("[B2BUA] Answered video declined", "call_id", a.CallID, "status", result.StatusCode)
+	default:
+		slog.Info("[B2BUA] Answered video relayed", "call_id", a.CallID, "peer_call_id", b.CallID)
+	}
+}
+
+// holdLocally answers a hold or resume offer without involving the other
+// leg, which hears the default music-on-hold class while the call is held
+func (s *callService) holdLocally(d *dialog.Dialog, b Bridge, side string, offerSDP *sdpmedia.Session, direction, addr string, port int) *dialog.ReINVITEResult {
+	ctx, cancel := context.WithTimeout(d.Context(), holdTimeout)
+	defer cancel()
+
+	if direction == sdpmedia.SendOnly || direction == sdpmedia.Inactive {
+		var music []string
+		class, err := s.cfg.HoldMusic.Resolve("", "")
+		if err == nil {
+			music, err = class.Playlist()
+		}
+		if err != nil {
+			slog.Warn("[B2BUA] No music on hold", "call_id", d.CallID, "error", err)
+		}
+		if err := b.Hold(ctx, side, music); err != nil {
+			slog.Warn("[B2BUA] Hold music failed", "call_id", d.CallID, "bridge_id", b.ID(), "error", err)
+		}
+	} else if err := b.Resume(ctx); err != nil {
+		slog.Error("[B2BUA] Resume failed", "call_id", d.CallID, "bridge_id", b.ID(), "error", err)
+		return rejected(sip.StatusInternalServerError, "Server Internal Error")
+	}
+
+	answer, err := renegotiated(d.LocalSDP(), offerSDP, sdpmedia.AnswerDirection(direction))
+	if err == nil {
+		answer, err = s.answerOthers(answer, offerSDP.SDP, nil)
+	}
+	if err != nil {
+		slog.Warn("[B2BUA] Hold not answered", "call_id", d.CallID, "error", err)
+		return rejected(sip.StatusNotAcceptableHere, "Not Acceptable Here")
+	}
+	s.updateRemote(ctx, d, addr, port, firstFormat(offerSDP))
+	return &dialog.ReINVITEResult{Success: true, StatusCode: int(sip.StatusOK), Reason: "OK", SDP: answer}
+}
+
+// bridgedPeer returns the active bridge with a leg in the dialog callID,
+// which leg that is ("leg_a" or "leg_b") and the other leg's dialog, or a
+// nil bridge if there is none
+func (s *callService) bridgedPeer(callID string) (Bridge, string, *dialog.Dialog) {
+	for _, b := range s.bridges.List() {
+		if b.GetState() != BridgeStateActive {
+			continue
+		}
+		var side string
+		var peer *dialog.Dialog
+		switch callID {
+		case b.LegA().CallID():
+			side, peer = "leg_a", b.LegB().Dialog()
+		case b.LegB().CallID():
+			side, peer = "leg_b", b.LegA().Dialog()
+		default:
+			continue
+		}
+		if peer != nil {
+			return b, side, peer
+		}
+	}
+	return nil, "", nil
+}
+
+// updateRemote points a leg's RTP session at the media address it
+// renegotiated. A 0.0.0.0 hold address keeps the previous one.
+func (s *callService) updateRemote(ctx context.Context, d *dialog.Dialog, addr string, port int, codec string) {
+	if addr == holdAddr || port == 0 {
+		return
+	}
+	d.SetMediaEndpoint(addr, port, codec)
+	sessionID := d.GetSessionID()
+	if sessionID == "" {
+		return
+	}
+	if err := s.cfg.Transport.UpdateSessionRemote(ctx, sessionID, addr, port); err != nil {
+		slog.Error("[B2BUA] Failed to update session remote endpoint",
+			"call_id", d.CallID,
+			"session_id", sessionID,
+			"remote", fmt.Sprintf("%s:%d", addr, port),
+			"error", err,
+		)
+	}
+}
+
+// firstFormat returns the preferred codec of an SDP's audio, passing over
+// telephone events and comfort noise
+func firstFormat(sdp *sdpmedia.Session) string {
+	codec, _ := sdp.Audio().Codec()
+	return codec.Payload
+}
+
+// relayOthers replaces the streams other than audio of our offer to the
+// other leg with those of the offer relayed, in passthrough mode
+func relayOthers(peerOffer []byte, offer *psdp.SessionDescription) ([]byte, error) {
+	shape := &psdp.SessionDescription{}
+	if err := shape.Unmarshal(peerOffer); err != nil {
+		return nil, fmt.Errorf("parse local SDP: %w", err)
+	}
+	return sdpmedia.Compose(peerOffer, shape, sdpmedia.Passthrough.Relay(offer))
+}
+
+// answerOthers lays out our answer to an offer like the offer: its
+// streams other than audio are those the other leg answered in
+// passthrough mode, and rejected otherwise or without a peerAnswer
+func (s *callService) answerOthers(answer []byte, offer, peerAnswer *psdp.SessionDescription) ([]byte, error) {
+	var others []*psdp.MediaDescription
+	if s.cfg.Video == sdpmedia.Passthrough && peerAnswer != nil {
+		others = sdpmedia.Passthrough.Relay(peerAnswer)
+		others = others[:min(len(others), len(sdpmedia.Others(offer)))]
+	}
+	return sdpmedia.Compose(answer, offer, others)
+}
+
+// rejected is a failed re-INVITE outcome
+func rejected(code sip.StatusCode, reason string) *dialog.ReINVITEResult {
+	return &dialog.ReINVITEResult{StatusCode: int(code), Reason: reason}
+}
+
+// parseMedia parses an SDP body and returns the RTP address and port of
+// its audio, the first audio media description
+func parseMedia(body []byte) (sdp *sdpmedia.Session, addr string, port int, err error) {
+	sdp, err = sdpmedia.Parse(body)
+	if err != nil {
+		return nil, "", 0, fmt.Errorf("parse SDP: %w", err)
+	}
+	audio := sdp.Audio()
+	if audio == nil {
+		return nil, "", 0, sdpmedia.ErrNoAudio
+	}
+	if audio.Addr == "" {
+		return nil, "", 0, errors.New("no connection address in SDP")
+	}
+	return sdp, audio.Addr, audio.Port, nil
+}
+
+// mediaDirection returns the direction of an SDP's audio: its own
+// attribute, the session's, or sendrecv. A sendrecv stream to 0.0.0.0 is
+// the RFC 2543 hold and counts as sendonly.
+func mediaDirection(sdp *sdpmedia.Session) string {
+	audio := sdp.Audio()
+	if audio.Direction == sdpmedia.SendRecv && audio.Addr == holdAddr {
+		return sdpmedia.SendOnly
+	}
+	return audio.Direction
+}
+
+// renegotiated returns our SDP toward one leg carrying the audio formats
+// of the other leg's SDP and the direction given: the audio's formats with
+// their rtpmap and fmtp attributes, its ptime and maxptime and its
+// direction are replaced, and the session version is incremented as RFC
+// 3264 Section 8 requires of a changed session. Media are relayed without
+// transcoding, so the packetization passes through too. The other streams
+// are left as they were.
+func renegotiated(local []byte, from *sdpmedia.Session, direction string) ([]byte, error) {
+	sdp, err := sdpmedia.Parse(local)
+	if err != nil {
+		return nil, fmt.Errorf("parse local SDP: %w", err)
+	}
+	audio, source := sdp.Audio(), from.Audio()
+	if audio == nil {
+		return nil, errors.New("no audio in local SDP")
+	}
+	audio.Formats = source.Formats
+	audio.Ptime, audio.Maxptime = source.Ptime, source.Maxptime
+	audio.Direction = direction
+	audio.Apply(sdp.SDP, sdpmedia.Audio(sdp.SDP))
+	sdp.SDP.Attributes = slices.DeleteFunc(sdp.SDP.Attributes, func(a psdp.Attribute) bool { return sdpmedia.IsDirection(a.Key) })
+	sdp.SDP.Origin.SessionVersion++
+	return sdp.SDP.Marshal()
+}
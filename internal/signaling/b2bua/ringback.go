@@ -0,0 +1,99 @@
+package b2bua
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// ringback plays a generated ringback tone on the A-leg's media session
+// while an outbound leg rings. It is driven by the B-leg's progress
+// callback and stopped when the callee sends early media or the dial ends.
+type ringback struct {
+	transport mediaclient.Transport
+	sessionID string
+
+	mu      sync.Mutex
+	playing bool
+	stopped bool
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newRingback(transport mediaclient.Transport, sessionID string) *ringback {
+	return &ringback{
+		transport: transport,
+		sessionID: sessionID,
+	}
+}
+
+// progress is the B-leg progress handler.
+func (r *ringback) progress(_ Leg, state LegState) {
+	switch state {
+	case LegStateRinging:
+		r.start()
+	case LegStateEarlyMedia:
+		// The callee is providing its own progress audio
+		r.stop()
+	}
+}
+
+func (r *ringback) start() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.playing || r.stopped || r.transport == nil || r.sessionID == "" {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	statusCh, err := r.transport.PlayTone(ctx, mediaclient.ToneRequest{
+		SessionID: r.sessionID,
+		Tone:      "ringback",
+	})
+	if err != nil {
+		cancel()
+		slog.Warn("[CallService] Ringback failed",
+			"session_id", r.sessionID,
+			"error", err,
+		)
+		return
+	}
+
+	r.playing = true
+	r.cancel = cancel
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		for range statusCh {
+		}
+	}()
+
+	slog.Debug("[CallService] Ringback started", "session_id", r.sessionID)
+}
+
+// stop ends the tone and waits for the RTP manager to release the session's
+// port, so the session can be bridged straight after. Later starts are ignored.
+func (r *ringback) stop() {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.stopped = true
+	if !r.playing {
+		return
+	}
+	r.playing = false
+
+	if err := r.transport.StopAudio(context.Background(), r.sessionID); err != nil {
+		slog.Debug("[CallService] Ringback stop",
+			"session_id", r.sessionID,
+			"error", err,
+		)
+	}
+	r.cancel()
+	<-r.done
+
+	slog.Debug("[CallService] Ringback stopped", "session_id", r.sessionID)
+}
@@ -0,0 +1,70 @@
+package b2bua
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+)
+
+// parseRouteSet parses Path or Record-Route header values into URIs, in
+// order. A value may hold several comma-separated name-addrs.
+func parseRouteSet(values []string) ([]sip.Uri, error) {
+	var routes []sip.Uri
+	for _, value := range values {
+		for _, entry := range splitNameAddrs(value) {
+			addr := entry
+			if start, end := strings.IndexByte(entry, '<'), strings.LastIndexByte(entry, '>'); start >= 0 && end > start {
+				addr = entry[start+1 : end]
+			}
+			var uri sip.Uri
+			if err := sipaddr.ParseURI(addr, &uri); err != nil {
+				return nil, fmt.Errorf("invalid route %q: %w", entry, err)
+			}
+			routes = append(routes, uri)
+		}
+	}
+	return routes, nil
+}
+
+// splitNameAddrs splits a header value on commas outside angle brackets.
+func splitNameAddrs(value string) []string {
+	var parts []string
+	depth, start := 0, 0
+	for i, c := range value {
+		switch c {
+		case '<':
+			depth++
+		case '>':
+			depth--
+		case ',':
+			if depth == 0 {
+				parts = append(parts, strings.TrimSpace(value[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if last := strings.TrimSpace(value[start:]); last != "" {
+		parts = append(parts, last)
+	}
+	return parts
+}
+
+// addRouteSet adds a Route header per hop. sipgo sends a request with Route
+// headers to the first hop.
+func addRouteSet(req *sip.Request, routes []sip.Uri) {
+	for _, r := range routes {
+		req.AppendHeader(&sip.RouteHeader{Address: r})
+	}
+}
+
+// headerValues returns the values of every header named name.
+func headerValues(msg sip.Message, name string) []string {
+	hdrs := msg.GetHeaders(name)
+	values := make([]string, 0, len(hdrs))
+	for _, h := range hdrs {
+		values = append(values, h.Value())
+	}
+	return values
+}
@@ -6,8 +6,13 @@ import (
 
 	"github.com/emiago/sipgo"
 	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/signaling/codecs"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/kpi"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
 )
 
 // CallService orchestrates B2BUA operations: lookup, origination, and bridging.
@@ -52,6 +57,10 @@ type CallService interface {
 	// Returns the bridge in Created state - call Start() to activate.
 	CreateBridge(legA, legB Leg, opts ...BridgeOption) (Bridge, error)
 
+	// Bridges returns the bridges created by CreateBridge that have not
+	// terminated yet.
+	Bridges() BridgeStore
+
 	// --- High-Level Operations ---
 
 	// Dial combines Lookup + CreateOutboundLeg + wait for answer.
@@ -66,13 +75,18 @@ type CallService interface {
 	// Accepts LegOption to pass CallerID, CallerName, etc. to the outbound leg.
 	DialAndBridge(ctx context.Context, legA Leg, target string, timeout time.Duration, opts ...LegOption) (*BridgeInfo, error)
 
-	// --- Ring Group Support (Future) ---
+	// --- Forking ---
+
+	// DialParallel rings several targets at once. The first leg to answer
+	// (and accept the call, for targets with Confirm) wins; the others are
+	// canceled or hung up. Returns the winning leg in Answered state. If
+	// every target fails, the first target's error is returned.
+	DialParallel(ctx context.Context, targets []ForkTarget, timeout time.Duration, opts ...LegOption) (Leg, error)
 
-	// DialParallel originates to multiple targets simultaneously.
-	// First answer wins, remaining legs are canceled.
-	// Returns the winning leg in Answered state.
-	// Not yet implemented - returns ErrNotImplemented.
-	DialParallel(ctx context.Context, targets []*LookupResult, timeout time.Duration, opts ...LegOption) (Leg, error)
+	// DialParallelAndBridge is DialAndBridge for a fork: given an answered
+	// A-leg, rings the targets with DialParallel and bridges the winner.
+	// Blocks until the bridge terminates.
+	DialParallelAndBridge(ctx context.Context, legA Leg, targets []ForkTarget, timeout time.Duration, opts ...LegOption) (*BridgeInfo, error)
 
 	// --- B-leg BYE Handling ---
 
@@ -82,6 +96,67 @@ type CallService interface {
 	// This should be called before the dialog manager's HandleIncomingBYE.
 	HandleIncomingBYE(req *sip.Request, tx sip.ServerTransaction) bool
 
+	// HasLeg reports whether an outbound (B) leg with this Call-ID is
+	// still in progress, including while it is ringing.
+	HasLeg(callID string) bool
+
+	// OutboundLegs returns the outbound (B) legs in progress, each with
+	// the Call-ID of the inbound leg it was dialed for, if any.
+	OutboundLegs() []*LegInfo
+
+	// --- Call Pickup ---
+
+	// RingingLegs returns the outbound legs ringing registered users for
+	// calls that can be picked up, those of DialAndBridge and
+	// DialParallelAndBridge, longest ringing first. With callees (user
+	// names), only the legs ringing them.
+	RingingLegs(callees ...string) []*LegInfo
+
+	// Pickup answers the call ringing one of callees the longest with leg,
+	// the answered leg of the party picking it up: the legs ringing for
+	// the call are canceled and its caller is bridged with leg. Returns
+	// once the call is handed over; PickedCall.Wait blocks until the
+	// bridge terminates. Returns ErrNoRingingCall if no call rings any of
+	// callees.
+	Pickup(leg Leg, callees []string) (*PickedCall, error)
+
+	// DialPickup is Pickup with the leg of target, dialed for the
+	// purpose, e.g. an operator's phone. Returns ErrNoRingingCall without
+	// dialing if no call rings any of callees.
+	DialPickup(ctx context.Context, target string, timeout time.Duration, callees []string) (*PickedCall, error)
+
+	// --- Hold ---
+
+	// HoldLeg puts one leg ("leg_a" or "leg_b") of an active bridge on
+	// hold, e.g. for an operator: the leg is sent a sendonly re-INVITE
+	// and hears the music of a music-on-hold class (the default if class
+	// is empty, silence without Music) until ResumeLeg. The other leg is
+	// not told. Returns ErrInvalidState if the call is on hold already or
+	// a hold or resume of the bridge is in progress.
+	HoldLeg(ctx context.Context, bridgeID, leg, class string) error
+
+	// ResumeLeg takes the leg put on hold with HoldLeg off hold with a
+	// sendrecv re-INVITE and bridges media again. Returns ErrInvalidState
+	// if no leg was put on hold with HoldLeg, or a hold or resume of the
+	// bridge is in progress.
+	ResumeLeg(ctx context.Context, bridgeID string) error
+
+	// --- Digit Collection ---
+
+	// GatherLeg plays a prompt to the answered leg with Call-ID callID
+	// and collects the DTMF digits its party presses (see Gather),
+	// replacing whatever was playing to it. Returns ErrLegNotFound for an
+	// unknown Call-ID, and ErrInvalidState while the leg is bridged.
+	GatherLeg(ctx context.Context, callID string, opts GatherOptions) (*GatherResult, error)
+
+	// --- Re-INVITE Relay ---
+
+	// RelayReINVITE renegotiates a re-INVITE's SDP offer, received in the
+	// dialog of one leg of an active bridge, with the other leg, so codec
+	// and media address changes reach both parties. Returns nil if the
+	// dialog is not bridged. Suits dialog.Manager.SetReINVITEHandler.
+	RelayReINVITE(d *dialog.Dialog, offer []byte) *dialog.ReINVITEResult
+
 	// --- Drain Support ---
 
 	// GetBridgeMapper returns the BridgeMapper interface for drain migration.
@@ -115,6 +190,10 @@ type CallServiceConfig struct {
 	// Required.
 	AdvertiseAddr string
 
+	// Advertise selects the address advertised to each destination
+	// network (optional; AdvertiseAddr is used for every peer when nil).
+	Advertise *advertise.Selector
+
 	// Port is the SIP listening port.
 	// Required.
 	Port int
@@ -126,9 +205,136 @@ type CallServiceConfig struct {
 	// Default: 30 seconds.
 	DefaultDialTimeout time.Duration
 
-	// EarlyMedia enables 183 Session Progress for early media.
-	// Default: true.
+	// EarlyMedia relays the B-leg's early media (183 with SDP) to the
+	// A-leg until answer, so announcements are heard before the call connects.
 	EarlyMedia bool
+
+	// Ringback plays a generated ringback tone to the A-leg while the
+	// B-leg rings without sending early media.
+	Ringback bool
+
+	// Video is what happens to streams other than audio, such as video:
+	// relayed between the bridged legs with sdpmedia.Passthrough, rejected
+	// with port 0 otherwise.
+	Video sdpmedia.Mode
+
+	// HoldMusic, if set, supplies the music played to the other party
+	// when one party of a bridge puts the call on hold (its default
+	// class); the hold is then answered here instead of being passed on.
+	HoldMusic *moh.Registry
+
+	// Music supplies the music of the holds placed with HoldLeg
+	// (optional).
+	Music *moh.Registry
+
+	// ConfirmPrompt is the audio file played to callees that must accept
+	// a forked call by pressing 1 (see ForkTarget.Confirm). A short beep
+	// is repeated instead when empty.
+	ConfirmPrompt string
+
+	// ConfirmTimeout is how long a callee has to accept a call.
+	// Default: 10 seconds.
+	ConfirmTimeout time.Duration
+
+	// HeaderPolicy rewrites the headers of outbound INVITEs and of the
+	// responses to them (optional).
+	HeaderPolicy HeaderPolicy
+
+	// Identity sets the User-Agent of the INVITEs, ACKs, CANCELs and
+	// BYEs of outbound legs (optional).
+	Identity Identity
+
+	// Codecs decides the codecs offered to each callee (optional; PCMU
+	// is offered without it).
+	Codecs CodecPolicy
+
+	// LoopDetector is told about every INVITE sent, so that it recognizes
+	// calls routed back into this server (optional).
+	LoopDetector LoopDetector
+
+	// LoadMonitor is told how long each outbound leg took to set up
+	// before its INVITE was sent (optional).
+	LoadMonitor LoadMonitor
+
+	// KPIRecorder is told the outcome of every outbound leg, for the
+	// per-route call KPIs and trunk statistics (optional).
+	KPIRecorder KPIRecorder
+
+	// InviteTimeout is RFC 3261 Timer B, how long an INVITE transaction
+	// lasts. Default: 32 seconds.
+	InviteTimeout time.Duration
+}
+
+// HeaderPolicy changes headers of the messages of outbound legs.
+// Implemented by headerpolicy.Policy.
+type HeaderPolicy interface {
+	// SentRequest is called before a request is sent to peer (its host).
+	SentRequest(req *sip.Request, peer string)
+
+	// ReceivedResponse is called when a response arrives from peer.
+	ReceivedResponse(res *sip.Response, peer string)
+}
+
+// Identity sets the identity headers of the requests of outbound legs.
+// Implemented by identity.Headers.
+type Identity interface {
+	// SentRequest is called before a request is sent to peer (its host).
+	SentRequest(req *sip.Request, peer string)
+}
+
+// CodecPolicy decides the codecs offered to the callees of outbound
+// legs. Implemented by codecs.Policies.
+type CodecPolicy interface {
+	// Outbound returns the codecs of a leg sent to peer (its host) to
+	// reach endpoint, a registered user ("" for other callees); nil
+	// allows every codec.
+	Outbound(endpoint, peer string) codecs.Policy
+}
+
+// HeaderPolicies applies several header policies in order, e.g. header
+// rules and then topology hiding. Nil entries are skipped.
+type HeaderPolicies []HeaderPolicy
+
+// SentRequest implements HeaderPolicy.
+func (p HeaderPolicies) SentRequest(req *sip.Request, peer string) {
+	for _, policy := range p {
+		if policy != nil {
+			policy.SentRequest(req, peer)
+		}
+	}
+}
+
+// ReceivedResponse implements HeaderPolicy.
+func (p HeaderPolicies) ReceivedResponse(res *sip.Response, peer string) {
+	for _, policy := range p {
+		if policy != nil {
+			policy.ReceivedResponse(res, peer)
+		}
+	}
+}
+
+// LoopDetector records outbound INVITEs. Implemented by
+// loopdetect.Detector.
+type LoopDetector interface {
+	// Sent is called after invite was sent. inbound is the INVITE of the
+	// call it continues, or nil.
+	Sent(invite, inbound *sip.Request)
+}
+
+// LoadMonitor watches how quickly outbound legs are set up. Implemented
+// by overload.Shedder.
+type LoadMonitor interface {
+	// ObserveOriginate is called with the time from the start of an
+	// originate to its INVITE being ready, media allocation included.
+	ObserveOriginate(d time.Duration)
+}
+
+// KPIRecorder counts outbound call attempts per route. Implemented by
+// kpi.Collector and routestats.Tracker; kpi.Recorders feeds both.
+type KPIRecorder interface {
+	// RecordAttempt is called once an attempt has failed, or once an
+	// answered attempt has ended.
+	RecordAttempt(a kpi.Attempt)
 }
 
 // Logger is a minimal logging interface.
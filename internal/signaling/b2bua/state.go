@@ -2,7 +2,11 @@
 // for call origination and bridging.
 package b2bua
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+)
 
 // LegState represents the current state of a call leg.
 type LegState int
@@ -161,3 +165,17 @@ func (c TerminationCause) String() string {
 		return fmt.Sprintf("Unknown(%d)", c)
 	}
 }
+
+// Reason returns the Reason header (RFC 3326) of the BYE that hangs up an
+// answered leg for c
+func (c TerminationCause) Reason() sipreason.Reason {
+	switch c {
+	case TerminationCauseRejected:
+		return sipreason.Q850(sipreason.CauseCallRejected)
+	case TerminationCauseTimeout:
+		return sipreason.Q850(sipreason.CauseRecoveryOnTimerExpiry)
+	case TerminationCauseError:
+		return sipreason.Q850(sipreason.CauseTemporaryFailure)
+	}
+	return sipreason.Q850(sipreason.CauseNormalClearing)
+}
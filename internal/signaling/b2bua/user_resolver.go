@@ -2,7 +2,6 @@ package b2bua
 
 import (
 	"context"
-	"sort"
 	"strings"
 
 	"github.com/sebas/switchboard/internal/signaling/location"
@@ -69,22 +68,21 @@ func (r *UserResolver) Resolve(ctx context.Context, target string) (*LookupResul
 		}
 	}
 
-	// Convert bindings to contacts
+	// RFC 5626 Section 7: fork to one flow per instance
+	bindings = oneFlowPerInstance(bindings)
+
+	// Convert bindings to contacts, most preferred first
+	location.SortByPriority(bindings)
 	contacts := make([]ResolvedContact, 0, len(bindings))
 	for _, b := range bindings {
 		contacts = append(contacts, ResolvedContact{
 			URI:       b.EffectiveContact(),
-			Priority:  b.QValue,
+			Priority:  b.Priority(),
 			Transport: b.Transport,
 			Binding:   b,
 		})
 	}
 
-	// Sort by priority (highest first)
-	sort.Slice(contacts, func(i, j int) bool {
-		return contacts[i].Priority > contacts[j].Priority
-	})
-
 	return &LookupResult{
 		Type:     LookupResultTypeUser,
 		Original: target,
@@ -126,6 +124,31 @@ func (r *UserResolver) lookupBindings(extension string) []*location.Binding {
 	return nil
 }
 
+// oneFlowPerInstance keeps the most recently registered outbound flow of
+// each instance, so a UA registered over several flows rings once.
+func oneFlowPerInstance(bindings []*location.Binding) []*location.Binding {
+	latest := make(map[string]*location.Binding)
+	for _, b := range bindings {
+		if !b.Outbound {
+			continue
+		}
+		if cur, ok := latest[b.InstanceID]; !ok || b.RegisteredAt.After(cur.RegisteredAt) {
+			latest[b.InstanceID] = b
+		}
+	}
+	if len(latest) == 0 {
+		return bindings
+	}
+
+	result := make([]*location.Binding, 0, len(bindings))
+	for _, b := range bindings {
+		if !b.Outbound || latest[b.InstanceID] == b {
+			result = append(result, b)
+		}
+	}
+	return result
+}
+
 // buildAOR constructs an AOR from an extension.
 func (r *UserResolver) buildAOR(extension string) string {
 	if strings.Contains(extension, "@") {
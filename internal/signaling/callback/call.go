@@ -0,0 +1,140 @@
+package callback
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// call calls a callback back: the extension is dialed, hears ringback
+// while the caller is dialed, and the two are bridged once the caller
+// answers. A callback whose extension or caller does not answer goes
+// back to waiting, or fails after its last attempt.
+func (m *Manager) call(ctx context.Context, cb *callback) {
+	req := cb.status.Request
+	id := cb.status.ID
+	ringTimeout := time.Duration(req.RingTimeout) * time.Second
+
+	// The extension sees the number it is calling back
+	extLeg, err := m.calls.Dial(ctx, "user/"+req.Extension, ringTimeout, b2bua.WithCallerID(req.Number))
+	if err != nil {
+		if ctx.Err() != nil {
+			return
+		}
+		slog.Info("[Callback] Extension did not answer", "id", id, "extension", req.Extension, "error", err)
+		m.update(func() {
+			m.away[req.Extension] = time.Now().Add(targetRetryDelay)
+			m.retryLocked(cb, time.Time{}, err)
+		})
+		return
+	}
+	defer func() {
+		if extLeg.Context().Err() == nil {
+			_ = extLeg.Hangup(context.Background(), b2bua.TerminationCauseNormal)
+		}
+	}()
+
+	// Hanging up the extension cancels the dial of the caller
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(extLeg.Context(), cancel)
+	defer stop()
+
+	stopRingback := m.ringback(ctx, extLeg.SessionID())
+	defer stopRingback()
+
+	var attempt int
+	m.update(func() {
+		cb.status.Dialed++
+		attempt = cb.status.Dialed
+	})
+	slog.Info("[Callback] Calling back", "id", id, "extension", req.Extension, "number", req.Number, "attempt", attempt)
+
+	// The caller is dialed on the RTP manager of the extension, so the
+	// two can be bridged
+	callerLeg, err := m.calls.Dial(ctx, req.Number, ringTimeout,
+		b2bua.WithCallerID(req.CallerID),
+		b2bua.WithALegSessionID(extLeg.SessionID()),
+	)
+	if err != nil {
+		if ctx.Err() != nil && extLeg.Context().Err() == nil {
+			return // Canceled
+		}
+		// The extension hanging up counts as an attempt too
+		slog.Info("[Callback] Caller did not answer", "id", id, "number", req.Number, "error", err)
+		m.update(func() { m.callerFailedLocked(cb, err) })
+		return
+	}
+
+	stopRingback()
+	bridge, err := m.calls.CreateBridge(extLeg, callerLeg)
+	if err == nil {
+		err = bridge.Start(context.Background())
+	}
+	if err != nil {
+		_ = callerLeg.Hangup(context.Background(), b2bua.TerminationCauseNormal)
+		slog.Warn("[Callback] Failed to bridge callback", "id", id, "error", err)
+		m.update(func() { m.finishLocked(cb, StateFailed, err) })
+		return
+	}
+	m.update(func() {
+		cb.status.CallID = callerLeg.CallID()
+		m.setLocked(cb, StateConnected)
+	})
+	slog.Info("[Callback] Callback connected", "id", id, "extension", req.Extension, "number", req.Number, "bridge_id", bridge.ID())
+
+	// The call continues if the callback is canceled or the server stops
+	_, _ = bridge.WaitForTermination(context.Background())
+	m.update(func() { m.finishLocked(cb, StateCompleted, nil) })
+}
+
+// callerFailedLocked dials the caller again after the retry delay, or
+// fails the callback after its last attempt or once it expired (must
+// hold lock).
+func (m *Manager) callerFailedLocked(cb *callback, err error) {
+	retryAt := time.Now().Add(time.Duration(cb.status.RetryDelay) * time.Second)
+	switch {
+	case cb.status.Dialed >= cb.status.Attempts:
+		m.finishLocked(cb, StateFailed, fmt.Errorf("no answer after %d attempts: %w", cb.status.Dialed, err))
+	case !retryAt.Before(cb.status.ExpiresAt):
+		m.finishLocked(cb, StateExpired, err)
+	default:
+		m.retryLocked(cb, retryAt, err)
+	}
+}
+
+// retryLocked puts a callback back to waiting until retryAt (must hold
+// lock).
+func (m *Manager) retryLocked(cb *callback, retryAt time.Time, err error) {
+	if !m.setLocked(cb, StatePending) {
+		return
+	}
+	cb.cancel()
+	cb.cancel = nil
+	cb.retryAt = retryAt
+	cb.status.Error = err.Error()
+}
+
+// ringback plays ringback to a session until the returned function is
+// called.
+func (m *Manager) ringback(ctx context.Context, sessionID string) (stop func()) {
+	statusCh, err := m.media.PlayTone(ctx, mediaclient.ToneRequest{SessionID: sessionID, Tone: "ringback"})
+	if err != nil {
+		slog.Warn("[Callback] Ringback failed", "session_id", sessionID, "error", err)
+		return func() {}
+	}
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		_ = m.media.StopAudio(context.Background(), sessionID)
+		for range statusCh {
+		}
+	}
+}
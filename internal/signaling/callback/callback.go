@@ -0,0 +1,329 @@
+// Package callback calls callers back. A callback request names an
+// extension and the caller's number; once the extension has no call in
+// progress, judged from the dialogs of the signaling server, the
+// extension is dialed, then the caller, and the two are bridged.
+//
+// A caller who does not answer is dialed again after a delay, up to the
+// request's attempts. Requests not called back before they expire, or
+// canceled through the API, are dropped.
+package callback
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// Defaults for Request fields left zero
+const (
+	DefaultExpiry      = time.Hour
+	DefaultAttempts    = 3
+	DefaultRetryDelay  = time.Minute
+	DefaultRingTimeout = 30 * time.Second
+
+	// MaxExpiry caps how long a request waits for its extension
+	MaxExpiry = 24 * time.Hour
+)
+
+// StatusRetention is how long a callback is kept once it is done
+const StatusRetention = 5 * time.Minute
+
+const (
+	// pollInterval is how often waiting callbacks check their extension
+	pollInterval = time.Second
+
+	// targetRetryDelay is how long an extension that did not answer a
+	// callback is not dialed again
+	targetRetryDelay = 30 * time.Second
+)
+
+// Sentinel errors
+var (
+	ErrNotFound = errors.New("callback: not found")
+	ErrDone     = errors.New("callback: already connected or done")
+)
+
+// State is how far a callback got
+type State string
+
+const (
+	StatePending   State = "pending"   // Waiting for the extension to be idle
+	StateDialing   State = "dialing"   // Dialing the extension, then the caller
+	StateConnected State = "connected" // Extension and caller bridged
+	StateCompleted State = "completed" // Connected call ended
+	StateFailed    State = "failed"    // Caller did not answer any attempt
+	StateExpired   State = "expired"   // Not called back in time
+	StateCanceled  State = "canceled"
+)
+
+// done reports whether the callback can progress no further
+func (s State) done() bool {
+	return s == StateCompleted || s == StateFailed || s == StateExpired || s == StateCanceled
+}
+
+// Request asks for a caller to be called back by an extension.
+type Request struct {
+	Extension string `json:"extension"` // Extension that calls back ("1001")
+	Number    string `json:"number"`    // Dial target of the caller ("15550001", "gateway/carrier", SIP URI)
+
+	CallerID    string `json:"caller_id,omitempty"`    // From user shown to the caller (default: the extension)
+	Expiry      int    `json:"expiry,omitempty"`       // Seconds to wait for the extension (default 3600)
+	Attempts    int    `json:"attempts,omitempty"`     // Times the caller is dialed (default 3)
+	RetryDelay  int    `json:"retry_delay,omitempty"`  // Seconds between attempts (default 60)
+	RingTimeout int    `json:"ring_timeout,omitempty"` // Seconds to ring each party (default 30)
+}
+
+// validate checks a request and fills in its defaults.
+func (r *Request) validate() error {
+	r.Extension = strings.TrimPrefix(r.Extension, "user/")
+	if r.Extension == "" || strings.ContainsAny(r.Extension, "/@:") {
+		return fmt.Errorf("invalid extension %q", r.Extension)
+	}
+	if r.Number == "" {
+		return errors.New("number required")
+	}
+	if r.Expiry < 0 || r.Attempts < 0 || r.RetryDelay < 0 || r.RingTimeout < 0 {
+		return errors.New("settings cannot be negative")
+	}
+	if time.Duration(r.Expiry)*time.Second > MaxExpiry {
+		return fmt.Errorf("expiry exceeds %s", MaxExpiry)
+	}
+	if r.CallerID == "" {
+		r.CallerID = r.Extension
+	}
+	if r.Expiry == 0 {
+		r.Expiry = int(DefaultExpiry / time.Second)
+	}
+	if r.Attempts == 0 {
+		r.Attempts = DefaultAttempts
+	}
+	if r.RetryDelay == 0 {
+		r.RetryDelay = int(DefaultRetryDelay / time.Second)
+	}
+	if r.RingTimeout == 0 {
+		r.RingTimeout = int(DefaultRingTimeout / time.Second)
+	}
+	return nil
+}
+
+// Callback is a callback request and how far it got
+type Callback struct {
+	ID string `json:"id"`
+	Request
+
+	State     State     `json:"state"`
+	Dialed    int       `json:"dialed"`            // Times the caller was dialed
+	CallID    string    `json:"call_id,omitempty"` // Caller's leg, once answered
+	Error     string    `json:"error,omitempty"`   // Why the last attempt failed
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Presence tells whether an extension is on a call; dialog.Manager
+// implements it from its dialogs.
+type Presence interface {
+	InCall(user string) bool
+}
+
+// callback is a request being called back
+type callback struct {
+	status  Callback
+	retryAt time.Time          // Not dialed before
+	cancel  context.CancelFunc // Set while dialing or connected
+}
+
+// Manager calls back the callers of its requests. Safe for concurrent use.
+type Manager struct {
+	calls    b2bua.CallService
+	media    mediaclient.Transport
+	presence Presence
+
+	mu        sync.Mutex
+	ctx       context.Context // Of Run; callbacks are dialed under it
+	callbacks map[string]*callback
+	away      map[string]time.Time // Extensions that did not answer, not dialed before
+}
+
+// New creates a manager dialing through calls, playing ringback through
+// media and waiting for extensions to be idle by presence.
+func New(calls b2bua.CallService, media mediaclient.Transport, presence Presence) *Manager {
+	return &Manager{
+		calls:     calls,
+		media:     media,
+		presence:  presence,
+		ctx:       context.Background(),
+		callbacks: make(map[string]*callback),
+		away:      make(map[string]time.Time),
+	}
+}
+
+// Run calls back waiting requests as their extensions become idle, until
+// ctx is done. Callbacks in progress are canceled when it returns.
+func (m *Manager) Run(ctx context.Context) {
+	m.mu.Lock()
+	m.ctx = ctx
+	m.mu.Unlock()
+
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			m.poll(now)
+		}
+	}
+}
+
+// Register adds a callback request, called back once its extension is
+// idle.
+func (m *Manager) Register(req Request) (Callback, error) {
+	if err := req.validate(); err != nil {
+		return Callback{}, err
+	}
+	now := time.Now()
+	cb := &callback{status: Callback{
+		ID:        uuid.New().String(),
+		Request:   req,
+		State:     StatePending,
+		CreatedAt: now,
+		ExpiresAt: now.Add(time.Duration(req.Expiry) * time.Second),
+		UpdatedAt: now,
+	}}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.callbacks[cb.status.ID] = cb
+	slog.Info("[Callback] Callback requested", "id", cb.status.ID, "extension", req.Extension, "number", req.Number, "expires_at", cb.status.ExpiresAt)
+	return cb.status, nil
+}
+
+// Cancel cancels a callback waiting for its extension or being dialed.
+func (m *Manager) Cancel(id string) (Callback, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cb, ok := m.callbacks[id]
+	if !ok {
+		return Callback{}, fmt.Errorf("%w: %s", ErrNotFound, id)
+	}
+	if cb.status.State != StatePending && cb.status.State != StateDialing {
+		return Callback{}, fmt.Errorf("%w: %s is %s", ErrDone, id, cb.status.State)
+	}
+	m.finishLocked(cb, StateCanceled, nil)
+	slog.Info("[Callback] Callback canceled", "id", id, "extension", cb.status.Extension)
+	return cb.status, nil
+}
+
+// Get returns a callback; done ones are kept for StatusRetention.
+func (m *Manager) Get(id string) (Callback, bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	cb, ok := m.callbacks[id]
+	if !ok {
+		return Callback{}, false
+	}
+	return cb.status, true
+}
+
+// List returns the callbacks, oldest first; with extension, only its.
+func (m *Manager) List(extension string) []Callback {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	list := make([]Callback, 0, len(m.callbacks))
+	for _, cb := range m.callbacks {
+		if extension == "" || cb.status.Extension == extension {
+			list = append(list, cb.status)
+		}
+	}
+	slices.SortFunc(list, func(a, b Callback) int { return a.CreatedAt.Compare(b.CreatedAt) })
+	return list
+}
+
+// poll expires the requests past their expiry and starts calling back
+// the oldest request of each idle extension.
+func (m *Manager) poll(now time.Time) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	busy := make(map[string]bool)
+	var pending []*callback
+	for _, cb := range m.callbacks {
+		switch cb.status.State {
+		case StatePending:
+			pending = append(pending, cb)
+		case StateDialing, StateConnected:
+			busy[cb.status.Extension] = true
+		}
+	}
+	slices.SortFunc(pending, func(a, b *callback) int { return a.status.CreatedAt.Compare(b.status.CreatedAt) })
+
+	for _, cb := range pending {
+		ext := cb.status.Extension
+		switch {
+		case !now.Before(cb.status.ExpiresAt):
+			m.finishLocked(cb, StateExpired, nil)
+			slog.Info("[Callback] Callback expired", "id", cb.status.ID, "extension", ext, "dialed", cb.status.Dialed)
+		case now.Before(cb.retryAt):
+			// Waiting to dial the caller again
+		case busy[ext] || now.Before(m.away[ext]) || m.presence.InCall(ext):
+			// Later requests wait for the oldest
+			busy[ext] = true
+		default:
+			busy[ext] = true
+			delete(m.away, ext)
+			ctx, cancel := context.WithCancel(m.ctx)
+			cb.cancel = cancel
+			m.setLocked(cb, StateDialing)
+			go m.call(ctx, cb)
+		}
+	}
+}
+
+// setLocked changes the state of a callback that is not done (must hold
+// lock).
+func (m *Manager) setLocked(cb *callback, state State) bool {
+	if cb.status.State.done() {
+		return false
+	}
+	cb.status.State = state
+	cb.status.UpdatedAt = time.Now()
+	return true
+}
+
+// finishLocked ends a callback, forgotten after StatusRetention (must
+// hold lock).
+func (m *Manager) finishLocked(cb *callback, state State, err error) {
+	if !m.setLocked(cb, state) {
+		return
+	}
+	if err != nil {
+		cb.status.Error = err.Error()
+	}
+	if cb.cancel != nil {
+		cb.cancel()
+	}
+	id := cb.status.ID
+	time.AfterFunc(StatusRetention, func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.callbacks, id)
+	})
+}
+
+// update changes a callback under the lock.
+func (m *Manager) update(change func()) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	change()
+}
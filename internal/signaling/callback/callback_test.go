@@ -0,0 +1,78 @@
+package callback
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// ringingCalls is a CallService whose dials ring until canceled
+type ringingCalls struct {
+	b2bua.CallService
+	dialed chan string
+}
+
+func (f *ringingCalls) Dial(ctx context.Context, target string, timeout time.Duration, opts ...b2bua.LegOption) (b2bua.Leg, error) {
+	f.dialed <- target
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+// presence reports the users set to true as on a call
+type presence map[string]bool
+
+func (p presence) InCall(user string) bool { return p[user] }
+
+func TestPoll(t *testing.T) {
+	calls := &ringingCalls{dialed: make(chan string, 10)}
+	onCall := presence{"1001": true}
+	m := New(calls, nil, onCall)
+
+	first, err := m.Register(Request{Extension: "user/1001", Number: "15550001"})
+	if err != nil {
+		t.Fatalf("Register: %v", err)
+	}
+	second, _ := m.Register(Request{Extension: "1001", Number: "15550002"})
+	expiring, _ := m.Register(Request{Extension: "1002", Number: "15550003", Expiry: 1})
+	if _, err := m.Register(Request{Extension: "1001"}); err == nil {
+		t.Error("Register without a number succeeded")
+	}
+
+	// 1001 is on a call: nothing is dialed, and 1002's request expires
+	now := time.Now()
+	m.poll(now.Add(2 * time.Second))
+	select {
+	case target := <-calls.dialed:
+		t.Fatalf("dialed %s while 1001 is on a call", target)
+	default:
+	}
+	if cb, _ := m.Get(expiring.ID); cb.State != StateExpired {
+		t.Errorf("state of expired callback = %s, want expired", cb.State)
+	}
+
+	// Once idle, 1001 calls back the oldest request only
+	delete(onCall, "1001")
+	m.poll(now.Add(3 * time.Second))
+	if target := <-calls.dialed; target != "user/1001" {
+		t.Errorf("dialed %s, want user/1001", target)
+	}
+	if cb, _ := m.Get(first.ID); cb.State != StateDialing {
+		t.Errorf("state of first callback = %s, want dialing", cb.State)
+	}
+	if cb, _ := m.Get(second.ID); cb.State != StatePending {
+		t.Errorf("state of second callback = %s, want pending", cb.State)
+	}
+
+	if cb, err := m.Cancel(first.ID); err != nil || cb.State != StateCanceled {
+		t.Errorf("Cancel = %s, %v, want canceled", cb.State, err)
+	}
+	if _, err := m.Cancel(first.ID); !errors.Is(err, ErrDone) {
+		t.Errorf("second Cancel = %v, want ErrDone", err)
+	}
+	if got := m.List("1001"); len(got) != 2 || got[0].ID != first.ID {
+		t.Errorf("List(1001) = %+v, want both 1001 callbacks, oldest first", got)
+	}
+}
@@ -0,0 +1,328 @@
+// Package codecs decides which audio codecs the calls of an endpoint,
+// trunk or tenant may use. The RTP manager only handles PCMU, so every
+// policy must allow it; the others decide which offers are accepted.
+//
+// A policy names codecs by their encoding as in a=rtpmap, case
+// insensitive ("PCMU", "PCMA", "G722", "telephone-event"). The policy of
+// a call leg is, first found: its endpoint's (a registered user, by user
+// name), its trunk's (see trunks.Trunk), its tenant's (groups of peers
+// matched by host, IP or CIDR, or by trunk), or the server-wide default:
+//
+//	{
+//	  "tenants": [
+//	    {
+//	      "name": "acme",
+//	      "peers": ["203.0.113.0/24", "sip.acme.example"],
+//	      "trunks": ["carrier-a"],
+//	      "codecs": ["PCMU", "telephone-event"]
+//	    }
+//	  ],
+//	  "endpoints": {
+//	    "1001": ["PCMU"]
+//	  }
+//	}
+//
+// The audio formats of an offer received are filtered by the caller's
+// policy before the RTP manager picks one, and outbound legs are
+// offered the static payload types of the callee's policy, so codecs
+// without one (opus) are only ever accepted. Telephone events and comfort
+// noise are kept only if the policy lists them.
+package codecs
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+)
+
+// ErrNoAllowedCodec is returned when a policy allows none of the codecs
+// of a leg
+var ErrNoAllowedCodec = errors.New("codecs: no codec allowed by the policy")
+
+// Policy is the codecs a call leg may use. A nil policy allows every
+// codec.
+type Policy []string
+
+// Parse parses a comma-separated list of codecs; empty is nil
+func Parse(s string) (Policy, error) {
+	var p Policy
+	for name := range strings.SplitSeq(s, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			p = append(p, name)
+		}
+	}
+	if err := p.validate(); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
+
+// validate checks that a policy names no codec twice and allows PCMU,
+// the one codec the RTP manager handles
+func (p Policy) validate() error {
+	for i, name := range p {
+		if strings.TrimSpace(name) == "" {
+			return errors.New("codecs: empty codec name")
+		}
+		if p[:i].rank(name) >= 0 {
+			return fmt.Errorf("codecs: %s listed twice", name)
+		}
+	}
+	if len(p) > 0 && p.rank("PCMU") < 0 {
+		return fmt.Errorf("codecs: %v: PCMU required, the RTP manager handles no other codec", []string(p))
+	}
+	return nil
+}
+
+// rank returns the position of an encoding in the policy, or -1
+func (p Policy) rank(encoding string) int {
+	return slices.IndexFunc(p, func(name string) bool { return strings.EqualFold(name, encoding) })
+}
+
+// Filter returns the formats the policy allows, in its order of
+// preference; formats of one codec keep their order. A nil policy allows
+// every format as it is.
+func (p Policy) Filter(formats []sdpmedia.Format) []sdpmedia.Format {
+	if p == nil {
+		return formats
+	}
+	allowed := make([]sdpmedia.Format, 0, len(formats))
+	for _, f := range formats {
+		if p.rank(f.Encoding) >= 0 {
+			allowed = append(allowed, f)
+		}
+	}
+	slices.SortStableFunc(allowed, func(a, b sdpmedia.Format) int {
+		return cmp.Compare(p.rank(a.Encoding), p.rank(b.Encoding))
+	})
+	return allowed
+}
+
+// Offered returns the payload types of an offered stream that the policy
+// allows, in its order of preference, or ErrNoAllowedCodec if it allows
+// no codec of the stream besides telephone events and comfort noise.
+func (p Policy) Offered(stream *sdpmedia.Stream) ([]string, error) {
+	allowed := p.Filter(stream.Formats)
+	if !slices.ContainsFunc(allowed, func(f sdpmedia.Format) bool { return !f.IsEvent() }) {
+		return nil, ErrNoAllowedCodec
+	}
+	payloads := make([]string, len(allowed))
+	for i, f := range allowed {
+		payloads[i] = f.Payload
+	}
+	return payloads, nil
+}
+
+// Payloads returns the static payload types of the policy's codecs, in
+// its order, as offered to outbound legs. Codecs without one are skipped.
+func (p Policy) Payloads() []string {
+	payloads := make([]string, 0, len(p))
+	for _, name := range p {
+		if payload, ok := sdpmedia.StaticPayload(name); ok {
+			payloads = append(payloads, payload)
+		}
+	}
+	return payloads
+}
+
+// Tenant is a group of peers with their own codecs
+type Tenant struct {
+	Name string `json:"name"`
+
+	// Peers are host names, IPs or CIDRs of the tenant's peers
+	Peers []string `json:"peers,omitempty"`
+
+	// Trunks are the names of trunks whose calls get the tenant's codecs
+	Trunks []string `json:"trunks,omitempty"`
+
+	Codecs Policy `json:"codecs"`
+
+	peers peers
+}
+
+// compile validates the tenant and parses its peers
+func (t *Tenant) compile() error {
+	if t.Name == "" {
+		return errors.New("codecs: tenant name required")
+	}
+	if len(t.Codecs) == 0 {
+		return fmt.Errorf("codecs: tenant %s: codecs required", t.Name)
+	}
+	if err := t.Codecs.validate(); err != nil {
+		return fmt.Errorf("tenant %s: %w", t.Name, err)
+	}
+	t.peers = parsePeers(t.Peers)
+	return nil
+}
+
+// peers are host names, IPs and CIDRs
+type peers struct {
+	hosts    []string
+	prefixes []netip.Prefix
+}
+
+// parsePeers parses host names, IPs and CIDRs
+func parsePeers(list []string) peers {
+	var p peers
+	for _, peer := range list {
+		peer = strings.Trim(strings.TrimSpace(peer), "[]")
+		if prefix, err := netip.ParsePrefix(peer); err == nil {
+			p.prefixes = append(p.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(peer); err == nil {
+			p.prefixes = append(p.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else if peer != "" {
+			p.hosts = append(p.hosts, strings.ToLower(peer))
+		}
+	}
+	return p
+}
+
+// match reports whether a peer host or IP is one of the peers
+func (p peers) match(peer string) bool {
+	peer = strings.ToLower(strings.Trim(peer, "[]"))
+	if addr, err := netip.ParseAddr(peer); err == nil {
+		addr = addr.Unmap()
+		return slices.ContainsFunc(p.prefixes, func(prefix netip.Prefix) bool { return prefix.Contains(addr) })
+	}
+	return slices.Contains(p.hosts, peer)
+}
+
+// trunk is a trunk with its own codecs
+type trunk struct {
+	name   string
+	hosts  peers
+	policy Policy
+}
+
+// Policies are the codec policies of the endpoints, trunks and tenants.
+// Immutable once trunks are added; safe for concurrent use.
+type Policies struct {
+	def       Policy
+	tenants   []Tenant
+	endpoints map[string]Policy
+	trunks    []trunk
+}
+
+// file is the on-disk format of the codec file
+type file struct {
+	Tenants   []Tenant          `json:"tenants"`
+	Endpoints map[string]Policy `json:"endpoints"`
+}
+
+// Load creates codec policies with the server-wide default and the
+// tenants and endpoints of a JSON file (none if path is empty).
+func Load(def Policy, path string) (*Policies, error) {
+	var f file
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("codecs: read %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("codecs: parse %s: %w", path, err)
+		}
+	}
+	return New(def, f.Tenants, f.Endpoints)
+}
+
+// New creates codec policies with the server-wide default (nil allows
+// every codec), tenants, matched in order, and endpoints by user name.
+func New(def Policy, tenants []Tenant, endpoints map[string]Policy) (*Policies, error) {
+	if err := def.validate(); err != nil {
+		return nil, err
+	}
+	seen := make(map[string]bool)
+	for i := range tenants {
+		if err := tenants[i].compile(); err != nil {
+			return nil, err
+		}
+		if seen[tenants[i].Name] {
+			return nil, fmt.Errorf("codecs: tenant %s defined twice", tenants[i].Name)
+		}
+		seen[tenants[i].Name] = true
+	}
+	for user, p := range endpoints {
+		if len(p) == 0 {
+			return nil, fmt.Errorf("codecs: endpoint %s: codecs required", user)
+		}
+		if err := p.validate(); err != nil {
+			return nil, fmt.Errorf("endpoint %s: %w", user, err)
+		}
+	}
+	return &Policies{def: def, tenants: tenants, endpoints: endpoints}, nil
+}
+
+// AddTrunk sets the codecs of a trunk, applied to its calls and to the
+// legs sent to its hosts (host names, IPs or CIDRs).
+func (p *Policies) AddTrunk(name string, hosts []string, policy Policy) error {
+	if name == "" {
+		return errors.New("codecs: trunk name required")
+	}
+	if err := policy.validate(); err != nil {
+		return fmt.Errorf("trunk %s: %w", name, err)
+	}
+	p.trunks = append(p.trunks, trunk{name: name, hosts: parsePeers(hosts), policy: policy})
+	return nil
+}
+
+// Default returns the server-wide policy
+func (p *Policies) Default() Policy {
+	return p.def
+}
+
+// Inbound returns the policy of a call received from peer (its source
+// host) and from trunk ("" for none), or else from endpoint, the caller's
+// user name.
+func (p *Policies) Inbound(endpoint, peer, trunkName string) Policy {
+	if trunkName == "" {
+		if policy, ok := p.endpoints[endpoint]; ok {
+			return policy
+		}
+	}
+	for _, t := range p.trunks {
+		if t.name == trunkName {
+			return t.policy
+		}
+	}
+	return p.tenant(peer, trunkName)
+}
+
+// Outbound returns the policy of a leg sent to peer (its host) to reach
+// endpoint, a registered user ("" for other callees). A trunk's policy
+// applies to its hosts.
+func (p *Policies) Outbound(endpoint, peer string) Policy {
+	if policy, ok := p.endpoints[endpoint]; ok {
+		return policy
+	}
+	for _, t := range p.trunks {
+		if t.hosts.match(peer) {
+			return t.policy
+		}
+	}
+	return p.tenant(peer, "")
+}
+
+// tenant returns the policy of the tenant of a trunk ("" for none), then
+// of a peer, or the default
+func (p *Policies) tenant(peer, trunkName string) Policy {
+	if trunkName != "" {
+		for i := range p.tenants {
+			if t := &p.tenants[i]; slices.Contains(t.Trunks, trunkName) {
+				return t.Codecs
+			}
+		}
+	}
+	for i := range p.tenants {
+		if t := &p.tenants[i]; t.peers.match(peer) {
+			return t.Codecs
+		}
+	}
+	return p.def
+}
@@ -0,0 +1,110 @@
+package codecs
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+)
+
+func TestPolicyOffered(t *testing.T) {
+	offer := &sdpmedia.Stream{Formats: []sdpmedia.Format{
+		{Payload: "111", Encoding: "opus", ClockRate: 48000, Channels: 2},
+		{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1},
+		{Payload: "8", Encoding: "PCMA", ClockRate: 8000, Channels: 1},
+		{Payload: "101", Encoding: "telephone-event", ClockRate: 8000, Channels: 1},
+	}}
+
+	tests := []struct {
+		policy Policy
+		want   []string
+		err    error
+	}{
+		{policy: nil, want: []string{"111", "0", "8", "101"}},
+		{policy: Policy{"pcma", "PCMU"}, want: []string{"8", "0"}},
+		{policy: Policy{"telephone-event", "PCMU"}, want: []string{"101", "0"}},
+		{policy: Policy{"G722", "telephone-event"}, err: ErrNoAllowedCodec},
+		{policy: Policy{"G722", "PCMU"}, want: []string{"0"}},
+	}
+	for _, tt := range tests {
+		got, err := tt.policy.Offered(offer)
+		if !errors.Is(err, tt.err) || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%v.Offered = %v, %v; want %v, %v", tt.policy, got, err, tt.want, tt.err)
+		}
+	}
+
+	if got, want := (Policy{"opus", "PCMA", "telephone-event", "PCMU"}).Payloads(), []string{"8", "0"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("Payloads = %v, want %v", got, want)
+	}
+}
+
+func TestParse(t *testing.T) {
+	p, err := Parse(" PCMA, PCMU ,")
+	if err != nil || !reflect.DeepEqual(p, Policy{"PCMA", "PCMU"}) {
+		t.Errorf("Parse = %v, %v", p, err)
+	}
+	if p, err := Parse(""); err != nil || p != nil {
+		t.Errorf("Parse(\"\") = %v, %v; want nil", p, err)
+	}
+	if _, err := Parse("PCMU,pcmu"); err == nil {
+		t.Error("Parse with a codec listed twice succeeded")
+	}
+	if _, err := Parse("PCMA"); err == nil {
+		t.Error("Parse without PCMU succeeded")
+	}
+}
+
+func TestPolicies(t *testing.T) {
+	policies, err := New(Policy{"PCMU"}, []Tenant{
+		{Name: "acme", Peers: []string{"203.0.113.0/24", "sip.acme.example"}, Codecs: Policy{"PCMU", "PCMA"}},
+		{Name: "carriers", Trunks: []string{"carrier-b"}, Codecs: Policy{"G729", "PCMU"}},
+	}, map[string]Policy{"1001": {"G722", "PCMU"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	if err := policies.AddTrunk("carrier-a", []string{"198.51.100.0/28"}, Policy{"PCMA", "PCMU"}); err != nil {
+		t.Fatalf("AddTrunk: %v", err)
+	}
+
+	inbound := []struct {
+		endpoint, peer, trunk string
+		want                  Policy
+	}{
+		{endpoint: "1001", peer: "203.0.113.9", want: Policy{"G722", "PCMU"}},
+		{endpoint: "1002", peer: "203.0.113.9", want: Policy{"PCMU", "PCMA"}},
+		{endpoint: "1001", peer: "192.0.2.1", trunk: "carrier-a", want: Policy{"PCMA", "PCMU"}},
+		{endpoint: "1001", peer: "203.0.113.9", trunk: "carrier-b", want: Policy{"G729", "PCMU"}},
+		{peer: "192.0.2.1", want: Policy{"PCMU"}},
+	}
+	for _, tt := range inbound {
+		if got := policies.Inbound(tt.endpoint, tt.peer, tt.trunk); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Inbound(%q, %q, %q) = %v, want %v", tt.endpoint, tt.peer, tt.trunk, got, tt.want)
+		}
+	}
+
+	outbound := []struct {
+		endpoint, peer string
+		want           Policy
+	}{
+		{endpoint: "1001", peer: "198.51.100.2", want: Policy{"G722", "PCMU"}},
+		{peer: "198.51.100.2", want: Policy{"PCMA", "PCMU"}},
+		{peer: "SIP.acme.example", want: Policy{"PCMU", "PCMA"}},
+		{peer: "192.0.2.1", want: Policy{"PCMU"}},
+	}
+	for _, tt := range outbound {
+		if got := policies.Outbound(tt.endpoint, tt.peer); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("Outbound(%q, %q) = %v, want %v", tt.endpoint, tt.peer, got, tt.want)
+		}
+	}
+
+	if _, err := New(nil, []Tenant{{Name: "empty"}}, nil); err == nil {
+		t.Error("New with a tenant without codecs succeeded")
+	}
+	if _, err := New(nil, nil, map[string]Policy{"1001": {}}); err == nil {
+		t.Error("New with an endpoint without codecs succeeded")
+	}
+	if _, err := New(nil, nil, map[string]Policy{"1001": {"PCMA"}}); err == nil {
+		t.Error("New with an endpoint without PCMU succeeded")
+	}
+}
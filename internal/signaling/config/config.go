@@ -17,6 +17,54 @@ type Config struct {
 	AdvertiseAddr string // Address to advertise in SIP headers
 	LogLevel      string
 
+	// Log output: format (text, json), optional rotated file and
+	// per-module level overrides ("dialog=debug,b2bua=warn")
+	LogFormat      string
+	LogFile        string
+	LogMaxSizeMB   int
+	LogRotateEvery time.Duration
+	LogMaxBackups  int
+	LogModules     string
+
+	// AdvertiseRules advertises other addresses to peers in given networks
+	// ("10.0.0.0/8=10.0.0.5,0.0.0.0/0=203.0.113.5")
+	AdvertiseRules string
+
+	// AdvertiseAddr6 is advertised to IPv6 peers ("auto" detects a global
+	// address; empty advertises AdvertiseAddr to every peer)
+	AdvertiseAddr6 string
+
+	// NATKeepalive is how often to ping UDP bindings so their NAT
+	// pinholes stay open; zero disables
+	NATKeepalive time.Duration
+
+	// SIP over TLS, served on TLSPort when a certificate is configured,
+	// either from files reloaded when they change or obtained through ACME.
+	// APITLS serves the HTTP API over HTTPS with the same certificate.
+	TLSPort            int
+	TLSCertFile        string
+	TLSKeyFile         string
+	APITLS             bool
+	CertReloadInterval time.Duration
+
+	// ACME issuance for the SIP-TLS and HTTPS API certificate; HTTP-01
+	// challenges are answered on ACMEHTTPAddr
+	ACMEDomains   []string
+	ACMEEmail     string
+	ACMECacheDir  string
+	ACMEDirectory string
+	ACMEHTTPAddr  string
+
+	// Mutual TLS to the RTP managers: this server's client certificate and
+	// the CA bundle their certificates are verified against
+	RTPManagerTLSCert string
+	RTPManagerTLSKey  string
+	RTPManagerTLSCA   string
+
+	// ServiceRoute adds a Service-Route header (RFC 3608) pointing at this
+	// server to 200 OK responses to REGISTER
+	ServiceRoute bool
+
 	// Dialplan settings
 	DialplanPath string // Path to dialplan.json config file
 
@@ -29,6 +77,150 @@ type Config struct {
 	GRPCConnectTimeout    time.Duration
 	GRPCKeepaliveInterval time.Duration
 	GRPCKeepaliveTimeout  time.Duration
+
+	// MediaTimeoutHangup sends BYE on both legs when an RTP manager reports
+	// that a call has stopped receiving RTP. When false, timeouts are only logged.
+	MediaTimeoutHangup bool
+
+	// MediaReconcileInterval is how often this server's media sessions on
+	// each RTP manager are compared with its calls (0 disables)
+	MediaReconcileInterval time.Duration
+
+	// Ringback plays a generated ringback tone to the caller while a dialed
+	// callee rings without sending early media.
+	Ringback bool
+
+	// EarlyMedia relays a dialed callee's early media (183 with SDP) to the
+	// caller before answer.
+	EarlyMedia bool
+
+	// Video is what happens to offered streams other than audio, such as
+	// video: "reject" answers them with port 0, "passthrough" relays them
+	// between the bridged legs without the RTP manager
+	Video string
+
+	// Codecs are the audio codecs calls may use (comma-separated encoding
+	// names, PCMU among them); empty allows every codec offered
+	// and offers PCMU
+	Codecs string
+
+	// CodecsConfigPath is the per-tenant and per-endpoint codec file;
+	// empty applies Codecs and the trunks' codecs only
+	CodecsConfigPath string
+
+	// Confirm-on-answer for follow-me destinations: the prompt played to the
+	// callee (a repeated beep if empty) and how long they have to press 1
+	ConfirmPrompt  string
+	ConfirmTimeout time.Duration
+
+	// Text-to-speech settings (dialplan say action)
+	TTSProvider    string // "http", "command", or empty to disable
+	TTSURL         string // Synthesis endpoint for the http provider
+	TTSCommand     string // Command line for the command provider
+	TTSVoice       string // Default voice
+	TTSCacheSizeMB int    // Rendered prompt cache size
+
+	// MOHConfigPath is the music-on-hold class file; empty disables MOH
+	MOHConfigPath string
+
+	// HoldMOH plays the default music-on-hold class to the other party when
+	// one party of a bridged call puts it on hold, instead of passing the
+	// hold on to them. Needs MOHConfigPath.
+	HoldMOH bool
+
+	// IVRConfigPath is the IVR menu file; empty disables the "ivr" action
+	IVRConfigPath string
+
+	// DialerConfigPath is the outbound campaign file; empty disables the dialer
+	DialerConfigPath string
+
+	// AgentsConfigPath is the agent file; empty disables agent states
+	AgentsConfigPath string
+
+	// ScreeningConfigPath is the inbound caller blocklist file; empty disables screening
+	ScreeningConfigPath string
+
+	// TrunksConfigPath is the trunk file identifying carriers by SIP-TLS
+	// client certificate; empty disables trunk identification
+	TrunksConfigPath string
+
+	// CredentialsPath is the hashed SIP credential file; empty disables
+	// digest authentication
+	CredentialsPath string
+
+	// FeaturesConfigPath is the per-user call feature file; empty disables user features
+	FeaturesConfigPath string
+
+	// HeaderPolicyPath is the header manipulation rule file; empty disables header rules
+	HeaderPolicyPath string
+
+	// HideTopology scrubs internal hosts, Via, Record-Route and Contact
+	// user parts from the SIP messages sent to peers; trunks may override
+	HideTopology bool
+
+	// TopologyInternal lists the internal host names, IPs and CIDRs that
+	// topology hiding scrubs (comma-separated)
+	TopologyInternal string
+
+	// UserAgent is the User-Agent of the SIP requests sent; empty sends none
+	UserAgent string
+
+	// ServerHeader is the Server header of the SIP responses sent; empty sends none
+	ServerHeader string
+
+	// IdentityConfigPath is the per-tenant User-Agent and Server header file;
+	// empty applies the server-wide values to every peer
+	IdentityConfigPath string
+
+	// AlertsConfigPath is the alert threshold and notification file; empty disables alerting
+	AlertsConfigPath string
+
+	// Timers are the SIP transaction and dialog timers
+	Timers SIPTimers
+
+	// MaxSpirals is how often a call may be routed back into this server
+	// with a different Request-URI before it is refused with 482
+	MaxSpirals int
+
+	// Overload protection: new calls are refused with 503 while any
+	// threshold is exceeded; zero disables a threshold
+	OverloadMaxCPU              float64       // Process CPU use, percent of all cores
+	OverloadMaxDialogs          int           // Dialogs held
+	OverloadMaxOriginateLatency time.Duration // Average outbound leg setup time
+	OverloadRetryAfter          time.Duration // Retry-After sent with the 503
+
+	// Recording storage settings
+	RecordingBackend   string // "local", "s3", "gcs", or empty to disable
+	RecordingDir       string // Directory for the local backend
+	RecordingURL       string // s3://bucket/prefix for the s3 and gcs backends
+	RecordingRetention string // Retention policy, e.g. "voicemail/=90d,30d"; empty keeps forever
+
+	// MetricsExporter pushes metrics to an agent: "statsd" or "dogstatsd";
+	// empty disables it
+	MetricsExporter string
+	StatsDAddr      string        // Agent address
+	StatsDPrefix    string        // Metric name prefix
+	StatsDTags      string        // Comma-separated tags added to every metric (dogstatsd)
+	MetricsInterval time.Duration // How often gauges are sampled
+
+	// KPIRetention is how long outbound call attempts are kept for the
+	// per-route KPIs of /api/v1/kpis
+	KPIRetention time.Duration
+
+	// DebugToken enables the pprof, expvar and dump endpoints on the API
+	// port for requests bearing it; empty disables them
+	DebugToken string
+
+	// Secret settings (DebugToken, TLS certificate and key files) may be
+	// references to a secret store (see package secrets), resolved again
+	// every SecretsRefresh. Referenced files are written to SecretsDir, a
+	// private temporary directory if empty.
+	SecretsDir     string
+	SecretsRefresh time.Duration
+
+	// SkipPreflight starts without the startup checks of ports, files and
+	// RTP manager reachability
+	SkipPreflight bool
 }
 
 // Load loads configuration from command line flags and environment variables
@@ -37,22 +229,103 @@ func Load() *Config {
 		GRPCConnectTimeout:    10 * time.Second,
 		GRPCKeepaliveInterval: 30 * time.Second,
 		GRPCKeepaliveTimeout:  10 * time.Second,
+		Timers:                DefaultSIPTimers(),
 	}
 
 	// Define flags
 	flag.IntVar(&cfg.Port, "port", 5060, "SIP listening port")
-	flag.StringVar(&cfg.BindAddr, "bind", "0.0.0.0", "SIP bind address")
+	flag.StringVar(&cfg.BindAddr, "bind", "0.0.0.0", "SIP bind address (\"::\" for dual-stack)")
 	flag.StringVar(&cfg.AdvertiseAddr, "advertise", "", "Address to advertise in SIP headers (auto-detected if not set)")
+	flag.StringVar(&cfg.AdvertiseAddr6, "advertise6", "", "IPv6 address to advertise to IPv6 peers (\"auto\" to detect)")
+	flag.StringVar(&cfg.AdvertiseRules, "advertise-rules", "", "Per-network SIP addresses, e.g. \"10.0.0.0/8=10.0.0.5,0.0.0.0/0=203.0.113.5\"")
+	flag.DurationVar(&cfg.NATKeepalive, "nat-keepalive", 0, "Interval for keepalives to UDP bindings behind NAT (e.g. 25s); 0 disables")
+	flag.IntVar(&cfg.TLSPort, "tls-port", 5061, "SIP-TLS listening port, served when a certificate is configured")
+	flag.StringVar(&cfg.TLSCertFile, "tls-cert", "", "PEM certificate for SIP-TLS and the HTTPS API, reloaded when it changes")
+	flag.StringVar(&cfg.TLSKeyFile, "tls-key", "", "PEM private key of --tls-cert")
+	flag.BoolVar(&cfg.APITLS, "api-tls", false, "Serve the HTTP API over HTTPS with the SIP-TLS certificate")
+	flag.DurationVar(&cfg.CertReloadInterval, "cert-reload-interval", time.Minute, "How often certificate files are checked for changes")
+	var acmeDomains string
+	flag.StringVar(&acmeDomains, "acme-domains", "", "Obtain the SIP-TLS and HTTPS API certificate through ACME for these comma-separated domains")
+	flag.StringVar(&cfg.ACMEEmail, "acme-email", "", "Contact email for the ACME account")
+	flag.StringVar(&cfg.ACMECacheDir, "acme-cache-dir", "acme-cache", "Directory keeping the ACME account and certificates")
+	flag.StringVar(&cfg.ACMEDirectory, "acme-directory", "", "ACME directory URL; empty uses Let's Encrypt")
+	flag.StringVar(&cfg.ACMEHTTPAddr, "acme-http-addr", ":80", "Address answering ACME HTTP-01 challenges")
+	flag.StringVar(&cfg.RTPManagerTLSCert, "rtpmanager-tls-cert", "", "Client certificate for mutual TLS to the RTP managers")
+	flag.StringVar(&cfg.RTPManagerTLSKey, "rtpmanager-tls-key", "", "Private key of --rtpmanager-tls-cert")
+	flag.StringVar(&cfg.RTPManagerTLSCA, "rtpmanager-tls-ca", "", "CA bundle verifying the RTP managers; setting it or a certificate enables TLS to them")
+	flag.BoolVar(&cfg.ServiceRoute, "service-route", true, "Add a Service-Route pointing at this server to REGISTER responses")
 	flag.StringVar(&cfg.LogLevel, "loglevel", "debug", "Log level (debug, info, warn, error)")
+	flag.StringVar(&cfg.LogFormat, "log-format", "text", "Log format (text, json)")
+	flag.StringVar(&cfg.LogFile, "log-file", "", "Log to this file instead of stdout")
+	flag.IntVar(&cfg.LogMaxSizeMB, "log-max-size-mb", 100, "Rotate the log file past this size in MB (0 disables)")
+	flag.DurationVar(&cfg.LogRotateEvery, "log-rotate-every", 24*time.Hour, "Rotate the log file after this long (0 disables)")
+	flag.IntVar(&cfg.LogMaxBackups, "log-max-backups", 7, "Rotated log files to keep (0 keeps all)")
+	flag.StringVar(&cfg.LogModules, "log-modules", "", "Per-module log levels, e.g. \"dialog=debug,b2bua=warn\"")
 	flag.StringVar(&cfg.DialplanPath, "dialplan", "resources/config/dialplan.json", "Path to dialplan configuration file")
 
 	var rtpManagerAddrs string
 	flag.StringVar(&rtpManagerAddrs, "rtpmanager", "localhost:9090", "RTP Manager gRPC addresses (comma-separated for multiple)")
+	flag.StringVar(&cfg.TTSProvider, "tts-provider", "", "Text-to-speech provider (http, command); empty disables")
+	flag.StringVar(&cfg.TTSURL, "tts-url", "", "Synthesis endpoint for the http TTS provider")
+	flag.StringVar(&cfg.TTSCommand, "tts-command", "espeak-ng --stdin --stdout -v {voice}", "Command line for the command TTS provider")
+	flag.StringVar(&cfg.TTSVoice, "tts-voice", "", "Default TTS voice")
+	flag.IntVar(&cfg.TTSCacheSizeMB, "tts-cache-mb", 32, "Rendered TTS prompt cache size in MB")
+	flag.StringVar(&cfg.MOHConfigPath, "moh-config", "", "Path to music-on-hold class file; empty disables")
+	flag.BoolVar(&cfg.HoldMOH, "hold-moh", false, "Play music on hold to the held party of a bridged call instead of passing the hold on")
+	flag.StringVar(&cfg.IVRConfigPath, "ivr-config", "", "Path to IVR menu file; empty disables")
+	flag.StringVar(&cfg.DialerConfigPath, "dialer-config", "", "Path to outbound campaign file; empty disables the dialer")
+	flag.StringVar(&cfg.AgentsConfigPath, "agents-config", "", "Path to agent file (wrap-up times); empty disables agent states")
+	flag.StringVar(&cfg.ScreeningConfigPath, "screening-config", "", "Path to inbound caller blocklist file; empty disables")
+	flag.StringVar(&cfg.TrunksConfigPath, "trunks-config", "", "Path to trunk file (TLS client certificates, limits); empty disables")
+	flag.StringVar(&cfg.CredentialsPath, "credentials-config", "", "Path to hashed SIP credential file; empty disables digest authentication")
+	flag.StringVar(&cfg.FeaturesConfigPath, "features-config", "", "Path to per-user call feature file; empty disables")
+	flag.StringVar(&cfg.HeaderPolicyPath, "header-policy", "", "Path to SIP header manipulation rule file; empty disables")
+	flag.BoolVar(&cfg.HideTopology, "hide-topology", false, "Scrub internal hosts, Via, Record-Route and Contact users from SIP messages sent to peers")
+	flag.StringVar(&cfg.TopologyInternal, "topology-internal", "", "Internal host names, IPs and CIDRs scrubbed by topology hiding (comma-separated)")
+	flag.StringVar(&cfg.UserAgent, "user-agent", "", "User-Agent header of SIP requests sent; empty sends none")
+	flag.StringVar(&cfg.ServerHeader, "server-header", "", "Server header of SIP responses sent; empty sends none")
+	flag.StringVar(&cfg.IdentityConfigPath, "identity-config", "", "Path to per-tenant User-Agent and Server header file; empty disables tenants")
+	flag.StringVar(&cfg.AlertsConfigPath, "alerts-config", "", "Path to alert threshold and notification file; empty disables")
+	flag.DurationVar(&cfg.Timers.T1, "sip-t1", cfg.Timers.T1, "SIP T1, the round-trip time estimate")
+	flag.DurationVar(&cfg.Timers.T2, "sip-t2", cfg.Timers.T2, "SIP T2, the maximum retransmission interval")
+	flag.DurationVar(&cfg.Timers.T4, "sip-t4", cfg.Timers.T4, "SIP T4, the maximum time a message remains in the network")
+	flag.DurationVar(&cfg.Timers.TimerB, "sip-timer-b", 0, "INVITE transaction timeout; 0 uses 64*T1")
+	flag.DurationVar(&cfg.Timers.TimerF, "sip-timer-f", 0, "Non-INVITE transaction timeout; 0 uses 64*T1")
+	flag.DurationVar(&cfg.Timers.ACKTimeout, "ack-timeout", 0, "How long an answered call waits for the caller's ACK; 0 uses 64*T1")
+	flag.IntVar(&cfg.MaxSpirals, "max-spirals", 3, "How often a call may be routed back into this server before 482 Loop Detected")
+	flag.Float64Var(&cfg.OverloadMaxCPU, "overload-max-cpu", 0, "Refuse new calls while CPU use exceeds this percentage of all cores; 0 disables")
+	flag.IntVar(&cfg.OverloadMaxDialogs, "overload-max-dialogs", 0, "Refuse new calls while this many dialogs are held; 0 disables")
+	flag.DurationVar(&cfg.OverloadMaxOriginateLatency, "overload-max-originate-latency", 0, "Refuse new calls while outbound legs take this long on average to set up; 0 disables")
+	flag.DurationVar(&cfg.OverloadRetryAfter, "overload-retry-after", 30*time.Second, "Retry-After sent with 503 responses to refused calls")
+	flag.StringVar(&cfg.RecordingBackend, "recording-backend", "", "Recording storage backend (local, s3, gcs); empty disables")
+	flag.StringVar(&cfg.RecordingDir, "recording-dir", "recordings", "Recording directory for the local backend")
+	flag.StringVar(&cfg.RecordingURL, "recording-url", "", "Bucket URL (s3://bucket/prefix) for the s3 and gcs backends")
+	flag.StringVar(&cfg.RecordingRetention, "recording-retention", "", "Recording retention, e.g. \"voicemail/=90d,30d\"; empty keeps forever")
+	flag.BoolVar(&cfg.Ringback, "ringback", true, "Play generated ringback to the caller while the callee rings")
+	flag.BoolVar(&cfg.EarlyMedia, "early-media", true, "Relay the callee's early media to the caller before answer")
+	flag.StringVar(&cfg.Video, "video", "reject", "Offered video and other non-audio streams: reject, or passthrough between bridged legs")
+	flag.StringVar(&cfg.Codecs, "codecs", "PCMU", "Audio codecs allowed, e.g. \"PCMU,PCMA\" (PCMU required); empty allows any offered")
+	flag.StringVar(&cfg.CodecsConfigPath, "codecs-config", "", "Path to per-tenant and per-endpoint codec file; empty disables")
+	flag.StringVar(&cfg.ConfirmPrompt, "confirm-prompt", "", "Audio file asking follow-me callees to press 1 to accept; empty plays a beep")
+	flag.DurationVar(&cfg.ConfirmTimeout, "confirm-timeout", 10*time.Second, "How long a follow-me callee has to accept a call")
+	flag.BoolVar(&cfg.MediaTimeoutHangup, "media-timeout-hangup", false, "Hang up calls reported as RTP-inactive by the RTP manager")
+	flag.DurationVar(&cfg.MediaReconcileInterval, "media-reconcile-interval", 5*time.Minute, "Reconcile media sessions on the RTP managers with calls this often (0 disables)")
+	flag.StringVar(&cfg.MetricsExporter, "metrics-exporter", "", "Push metrics to a StatsD agent (statsd, dogstatsd); empty disables")
+	flag.StringVar(&cfg.StatsDAddr, "statsd-addr", "127.0.0.1:8125", "StatsD agent address")
+	flag.StringVar(&cfg.StatsDPrefix, "statsd-prefix", "switchboard.signaling.", "Prefix for StatsD metric names")
+	flag.StringVar(&cfg.StatsDTags, "statsd-tags", "", "Tags added to every metric, e.g. \"env:prod,region:eu\" (dogstatsd only)")
+	flag.DurationVar(&cfg.MetricsInterval, "metrics-interval", 10*time.Second, "How often gauges are sent to the metrics exporter")
+	flag.DurationVar(&cfg.KPIRetention, "kpi-retention", 24*time.Hour, "How long call attempts are kept for the per-route KPIs")
+	flag.StringVar(&cfg.DebugToken, "debug-token", "", "Bearer token for the /debug/ diagnostics endpoints; empty disables them")
+	flag.StringVar(&cfg.SecretsDir, "secrets-dir", "", "Directory for TLS files resolved from a secret store; empty uses a private temporary directory")
+	flag.DurationVar(&cfg.SecretsRefresh, "secrets-refresh", 5*time.Minute, "How often secret store references are resolved again; 0 disables")
+	flag.BoolVar(&cfg.SkipPreflight, "skip-preflight", false, "Start without checking ports, files and RTP managers first")
 
 	flag.Parse()
 
 	// Parse RTP manager addresses
 	cfg.RTPManagerAddrs = parseAddressList(rtpManagerAddrs)
+	cfg.ACMEDomains = parseAddressList(acmeDomains)
 
 	// Override with environment variables if set
 	if port := os.Getenv("PORT"); port != "" {
@@ -70,9 +343,91 @@ func Load() *Config {
 	if cfg.AdvertiseAddr == "" || !isValidAddress(cfg.AdvertiseAddr) {
 		cfg.AdvertiseAddr = getPrimaryInterfaceIP()
 	}
+	if advertise6 := os.Getenv("ADVERTISE6"); advertise6 != "" {
+		cfg.AdvertiseAddr6 = advertise6
+	}
+	if cfg.AdvertiseAddr6 == "auto" {
+		cfg.AdvertiseAddr6 = getPrimaryInterfaceIPv6()
+	}
+	if rules := os.Getenv("ADVERTISE_RULES"); rules != "" {
+		cfg.AdvertiseRules = rules
+	}
+	if v := os.Getenv("NAT_KEEPALIVE"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.NATKeepalive = d
+		}
+	}
+	if v := os.Getenv("TLS_PORT"); v != "" {
+		if p, err := strconv.Atoi(v); err == nil {
+			cfg.TLSPort = p
+		}
+	}
+	if v := os.Getenv("TLS_CERT"); v != "" {
+		cfg.TLSCertFile = v
+	}
+	if v := os.Getenv("TLS_KEY"); v != "" {
+		cfg.TLSKeyFile = v
+	}
+	if v := os.Getenv("API_TLS"); v != "" {
+		cfg.APITLS, _ = strconv.ParseBool(v)
+	}
+	if v := os.Getenv("CERT_RELOAD_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.CertReloadInterval = d
+		}
+	}
+	if v := os.Getenv("ACME_DOMAINS"); v != "" {
+		cfg.ACMEDomains = parseAddressList(v)
+	}
+	if v := os.Getenv("ACME_EMAIL"); v != "" {
+		cfg.ACMEEmail = v
+	}
+	if v := os.Getenv("ACME_CACHE_DIR"); v != "" {
+		cfg.ACMECacheDir = v
+	}
+	if v := os.Getenv("ACME_DIRECTORY"); v != "" {
+		cfg.ACMEDirectory = v
+	}
+	if v := os.Getenv("ACME_HTTP_ADDR"); v != "" {
+		cfg.ACMEHTTPAddr = v
+	}
+	if v := os.Getenv("RTPMANAGER_TLS_CERT"); v != "" {
+		cfg.RTPManagerTLSCert = v
+	}
+	if v := os.Getenv("RTPMANAGER_TLS_KEY"); v != "" {
+		cfg.RTPManagerTLSKey = v
+	}
+	if v := os.Getenv("RTPMANAGER_TLS_CA"); v != "" {
+		cfg.RTPManagerTLSCA = v
+	}
+	if v := os.Getenv("SERVICE_ROUTE"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.ServiceRoute = b
+		}
+	}
 	if loglevel := os.Getenv("LOGLEVEL"); loglevel != "" {
 		cfg.LogLevel = loglevel
 	}
+	if v := os.Getenv("LOG_FORMAT"); v != "" {
+		cfg.LogFormat = v
+	}
+	if v := os.Getenv("LOG_FILE"); v != "" {
+		cfg.LogFile = v
+	}
+	if v := os.Getenv("LOG_MAX_SIZE_MB"); v != "" {
+		cfg.LogMaxSizeMB, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("LOG_ROTATE_EVERY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.LogRotateEvery = d
+		}
+	}
+	if v := os.Getenv("LOG_MAX_BACKUPS"); v != "" {
+		cfg.LogMaxBackups, _ = strconv.Atoi(v)
+	}
+	if v := os.Getenv("LOG_MODULES"); v != "" {
+		cfg.LogModules = v
+	}
 	if rtpmanager := os.Getenv("RTPMANAGER_ADDRS"); rtpmanager != "" {
 		// Try parsing as node=addr format first
 		nodeMap := parseNodeAddresses(rtpmanager)
@@ -85,10 +440,227 @@ func Load() *Config {
 	if dialplanPath := os.Getenv("DIALPLAN_PATH"); dialplanPath != "" {
 		cfg.DialplanPath = dialplanPath
 	}
+	if v := os.Getenv("TTS_PROVIDER"); v != "" {
+		cfg.TTSProvider = v
+	}
+	if v := os.Getenv("TTS_URL"); v != "" {
+		cfg.TTSURL = v
+	}
+	if v := os.Getenv("TTS_COMMAND"); v != "" {
+		cfg.TTSCommand = v
+	}
+	if v := os.Getenv("TTS_VOICE"); v != "" {
+		cfg.TTSVoice = v
+	}
+	if v := os.Getenv("TTS_CACHE_MB"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.TTSCacheSizeMB = n
+		}
+	}
+	if v := os.Getenv("MOH_CONFIG"); v != "" {
+		cfg.MOHConfigPath = v
+	}
+	if v := os.Getenv("HOLD_MOH"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.HoldMOH = b
+		}
+	}
+	if v := os.Getenv("IVR_CONFIG"); v != "" {
+		cfg.IVRConfigPath = v
+	}
+	if v := os.Getenv("DIALER_CONFIG"); v != "" {
+		cfg.DialerConfigPath = v
+	}
+	if v := os.Getenv("AGENTS_CONFIG"); v != "" {
+		cfg.AgentsConfigPath = v
+	}
+	if v := os.Getenv("SCREENING_CONFIG"); v != "" {
+		cfg.ScreeningConfigPath = v
+	}
+	if v := os.Getenv("TRUNKS_CONFIG"); v != "" {
+		cfg.TrunksConfigPath = v
+	}
+	if v := os.Getenv("CREDENTIALS_CONFIG"); v != "" {
+		cfg.CredentialsPath = v
+	}
+	if v := os.Getenv("FEATURES_CONFIG"); v != "" {
+		cfg.FeaturesConfigPath = v
+	}
+	if v := os.Getenv("HEADER_POLICY"); v != "" {
+		cfg.HeaderPolicyPath = v
+	}
+	if v := os.Getenv("HIDE_TOPOLOGY"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.HideTopology = b
+		}
+	}
+	if v := os.Getenv("TOPOLOGY_INTERNAL"); v != "" {
+		cfg.TopologyInternal = v
+	}
+	if v := os.Getenv("USER_AGENT"); v != "" {
+		cfg.UserAgent = v
+	}
+	if v := os.Getenv("SERVER_HEADER"); v != "" {
+		cfg.ServerHeader = v
+	}
+	if v := os.Getenv("IDENTITY_CONFIG"); v != "" {
+		cfg.IdentityConfigPath = v
+	}
+	if v := os.Getenv("ALERTS_CONFIG"); v != "" {
+		cfg.AlertsConfigPath = v
+	}
+	if v := os.Getenv("SIP_T1"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.T1 = d
+		}
+	}
+	if v := os.Getenv("SIP_T2"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.T2 = d
+		}
+	}
+	if v := os.Getenv("SIP_T4"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.T4 = d
+		}
+	}
+	if v := os.Getenv("SIP_TIMER_B"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.TimerB = d
+		}
+	}
+	if v := os.Getenv("SIP_TIMER_F"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.TimerF = d
+		}
+	}
+	if v := os.Getenv("ACK_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.Timers.ACKTimeout = d
+		}
+	}
+	if v := os.Getenv("MAX_SPIRALS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.MaxSpirals = n
+		}
+	}
+	if v := os.Getenv("OVERLOAD_MAX_CPU"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil {
+			cfg.OverloadMaxCPU = f
+		}
+	}
+	if v := os.Getenv("OVERLOAD_MAX_DIALOGS"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil {
+			cfg.OverloadMaxDialogs = n
+		}
+	}
+	if v := os.Getenv("OVERLOAD_MAX_ORIGINATE_LATENCY"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OverloadMaxOriginateLatency = d
+		}
+	}
+	if v := os.Getenv("OVERLOAD_RETRY_AFTER"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.OverloadRetryAfter = d
+		}
+	}
+	if v := os.Getenv("RECORDING_BACKEND"); v != "" {
+		cfg.RecordingBackend = v
+	}
+	if v := os.Getenv("RECORDING_DIR"); v != "" {
+		cfg.RecordingDir = v
+	}
+	if v := os.Getenv("RECORDING_URL"); v != "" {
+		cfg.RecordingURL = v
+	}
+	if v := os.Getenv("RECORDING_RETENTION"); v != "" {
+		cfg.RecordingRetention = v
+	}
+	if v := os.Getenv("MEDIA_TIMEOUT_HANGUP"); v != "" {
+		cfg.MediaTimeoutHangup, _ = strconv.ParseBool(v)
+	}
+	if v := os.Getenv("MEDIA_RECONCILE_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MediaReconcileInterval = d
+		}
+	}
+	if v := os.Getenv("RINGBACK"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.Ringback = b
+		}
+	}
+	if v := os.Getenv("EARLY_MEDIA"); v != "" {
+		if b, err := strconv.ParseBool(v); err == nil {
+			cfg.EarlyMedia = b
+		}
+	}
+	if v := os.Getenv("VIDEO"); v != "" {
+		cfg.Video = v
+	}
+	if v := os.Getenv("CODECS"); v != "" {
+		cfg.Codecs = v
+	}
+	if v := os.Getenv("CODECS_CONFIG"); v != "" {
+		cfg.CodecsConfigPath = v
+	}
+	if v := os.Getenv("CONFIRM_PROMPT"); v != "" {
+		cfg.ConfirmPrompt = v
+	}
+	if v := os.Getenv("CONFIRM_TIMEOUT"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.ConfirmTimeout = d
+		}
+	}
+	if v := os.Getenv("METRICS_EXPORTER"); v != "" {
+		cfg.MetricsExporter = v
+	}
+	if v := os.Getenv("STATSD_ADDR"); v != "" {
+		cfg.StatsDAddr = v
+	}
+	if v := os.Getenv("STATSD_PREFIX"); v != "" {
+		cfg.StatsDPrefix = v
+	}
+	if v := os.Getenv("STATSD_TAGS"); v != "" {
+		cfg.StatsDTags = v
+	}
+	if v := os.Getenv("METRICS_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.MetricsInterval = d
+		}
+	}
+	if v := os.Getenv("KPI_RETENTION"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.KPIRetention = d
+		}
+	}
+	if v := os.Getenv("DEBUG_TOKEN"); v != "" {
+		cfg.DebugToken = v
+	}
+	if v := os.Getenv("SECRETS_DIR"); v != "" {
+		cfg.SecretsDir = v
+	}
+	if v := os.Getenv("SECRETS_REFRESH"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.SecretsRefresh = d
+		}
+	}
+	if v := os.Getenv("SKIP_PREFLIGHT"); v != "" {
+		cfg.SkipPreflight, _ = strconv.ParseBool(v)
+	}
 
 	return cfg
 }
 
+// TLS reports whether SIP-TLS is served, with a certificate from files or ACME
+func (c *Config) TLS() bool {
+	return c.TLSCertFile != "" || len(c.ACMEDomains) > 0
+}
+
+// RTPManagerTLS reports whether the RTP managers are reached over TLS
+func (c *Config) RTPManagerTLS() bool {
+	return c.RTPManagerTLSCert != "" || c.RTPManagerTLSCA != ""
+}
+
 // parseAddressList parses a comma-separated list of addresses
 func parseAddressList(s string) []string {
 	if s == "" {
@@ -172,3 +744,31 @@ func getPrimaryInterfaceIP() string {
 
 	return "127.0.0.1"
 }
+
+// getPrimaryInterfaceIPv6 detects a global unicast IPv6 address, or "" if
+// the host has none
+func getPrimaryInterfaceIPv6() string {
+	interfaces, err := net.Interfaces()
+	if err != nil {
+		return ""
+	}
+
+	for _, iface := range interfaces {
+		if iface.Flags&net.FlagLoopback != 0 || iface.Flags&net.FlagUp == 0 {
+			continue
+		}
+
+		addrs, err := iface.Addrs()
+		if err != nil {
+			continue
+		}
+
+		for _, addr := range addrs {
+			if ipnet, ok := addr.(*net.IPNet); ok && ipnet.IP.To4() == nil && ipnet.IP.IsGlobalUnicast() {
+				return ipnet.IP.String()
+			}
+		}
+	}
+
+	return ""
+}
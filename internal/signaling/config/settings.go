@@ -0,0 +1,91 @@
+package config
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"os"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Redacted replaces the value of secret settings
+const Redacted = "[redacted]"
+
+// secretSettings are never shown, only whether they are set
+var secretSettings = map[string]bool{
+	"DebugToken": true,
+}
+
+// Settings returns the effective configuration as strings by field name,
+// nested fields joined with dots ("Timers.T1"). Secrets are redacted, as
+// are passwords in URLs, so the result can be shown to operators.
+func (c *Config) Settings() map[string]string {
+	settings := make(map[string]string)
+	flatten(settings, "", reflect.ValueOf(*c))
+	for name, value := range settings {
+		if value == "" {
+			continue
+		}
+		if secretSettings[name] {
+			settings[name] = Redacted
+		} else if u, err := url.Parse(value); err == nil && u.User != nil {
+			settings[name] = u.Redacted()
+		}
+	}
+	return settings
+}
+
+// flatten adds the fields of a struct to settings
+func flatten(settings map[string]string, prefix string, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field, value := t.Field(i), v.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name := prefix + field.Name
+		switch value.Kind() {
+		case reflect.Struct:
+			flatten(settings, name+".", value)
+		case reflect.Map:
+			pairs := make([]string, 0, value.Len())
+			iter := value.MapRange()
+			for iter.Next() {
+				pairs = append(pairs, fmt.Sprintf("%v=%v", iter.Key(), iter.Value()))
+			}
+			sort.Strings(pairs)
+			settings[name] = strings.Join(pairs, ",")
+		case reflect.Slice:
+			items := make([]string, value.Len())
+			for j := range items {
+				items[j] = fmt.Sprint(value.Index(j))
+			}
+			settings[name] = strings.Join(items, ",")
+		default:
+			settings[name] = fmt.Sprint(value)
+		}
+	}
+}
+
+// FileDigests returns the SHA-256 of each configuration file in use, by
+// the name of its path setting, to tell whether servers load the same
+// dialplan, blocklists and so on. Unreadable files are reported as such.
+func (c *Config) FileDigests() map[string]string {
+	digests := make(map[string]string)
+	for name, path := range c.Settings() {
+		if !strings.HasSuffix(name, "Path") || path == "" {
+			continue
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			digests[name] = "unreadable: " + err.Error()
+			continue
+		}
+		sum := sha256.Sum256(data)
+		digests[name] = "sha256:" + hex.EncodeToString(sum[:])
+	}
+	return digests
+}
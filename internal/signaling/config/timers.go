@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// SIPTimers are the SIP transaction and dialog timers (RFC 3261 Section
+// 17, Table 4). The defaults suit most networks; raise T1 for high-latency
+// links (satellite, long-haul trunks), lower it for a LAN.
+type SIPTimers struct {
+	T1 time.Duration // Round-trip time estimate
+	T2 time.Duration // Maximum retransmission interval for non-INVITE requests and INVITE responses
+	T4 time.Duration // Maximum time a message remains in the network
+
+	// TimerB and TimerF are the INVITE and non-INVITE transaction
+	// timeouts; zero uses 64*T1
+	TimerB time.Duration
+	TimerF time.Duration
+
+	// ACKTimeout is how long an answered call waits for the caller's ACK
+	// before it is torn down; zero uses 64*T1
+	ACKTimeout time.Duration
+}
+
+// DefaultSIPTimers returns the RFC 3261 defaults.
+func DefaultSIPTimers() SIPTimers {
+	return SIPTimers{
+		T1: 500 * time.Millisecond,
+		T2: 4 * time.Second,
+		T4: 5 * time.Second,
+	}
+}
+
+// InviteTimeout returns Timer B: how long an INVITE transaction lasts.
+func (t SIPTimers) InviteTimeout() time.Duration {
+	if t.TimerB > 0 {
+		return t.TimerB
+	}
+	return 64 * t.T1
+}
+
+// NonInviteTimeout returns Timer F: how long a non-INVITE transaction lasts.
+func (t SIPTimers) NonInviteTimeout() time.Duration {
+	if t.TimerF > 0 {
+		return t.TimerF
+	}
+	return 64 * t.T1
+}
+
+// AckTimeout returns how long an answered call waits for its ACK.
+func (t SIPTimers) AckTimeout() time.Duration {
+	if t.ACKTimeout > 0 {
+		return t.ACKTimeout
+	}
+	return 64 * t.T1
+}
+
+// Validate checks that the timers are in a range SIP can work with.
+func (t SIPTimers) Validate() error {
+	if t.T1 < 50*time.Millisecond || t.T1 > 10*time.Second {
+		return fmt.Errorf("T1 %s out of range (50ms to 10s)", t.T1)
+	}
+	if t.T2 < t.T1 || t.T2 > 60*time.Second {
+		return fmt.Errorf("T2 %s out of range (T1 to 60s)", t.T2)
+	}
+	if t.T4 < time.Second || t.T4 > 60*time.Second {
+		return fmt.Errorf("T4 %s out of range (1s to 60s)", t.T4)
+	}
+	// A transaction must outlast a few retransmissions to be of any use
+	timeouts := []struct {
+		name string
+		d    time.Duration
+	}{
+		{"Timer B", t.InviteTimeout()},
+		{"Timer F", t.NonInviteTimeout()},
+		{"ACK timeout", t.AckTimeout()},
+	}
+	for _, timeout := range timeouts {
+		if timeout.d < 4*t.T1 || timeout.d > 5*time.Minute {
+			return fmt.Errorf("%s %s out of range (4*T1 to 5m)", timeout.name, timeout.d)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,410 @@
+// Package credentials stores the SIP digest credentials of users as
+// hashes and authenticates requests against them.
+//
+// A password given to the store is never kept: it is reduced to its HA1
+// hashes (MD5 and SHA-256 of "user:realm:password", RFC 7616), which is
+// all a digest challenge needs, or to a bcrypt hash for credentials only
+// ever checked against a password presented in clear. The store is a
+// JSON file; plaintext passwords found in it are hashed on load and the
+// file rewritten.
+package credentials
+
+import (
+	"crypto/md5"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+// Digest algorithms (RFC 7616, RFC 8760)
+const (
+	AlgorithmMD5    = "MD5"
+	AlgorithmSHA256 = "SHA-256"
+)
+
+// Hash kinds a password is stored as
+const (
+	HashDigest = "digest" // HA1 for MD5 and SHA-256 (default)
+	HashBcrypt = "bcrypt" // bcrypt; cannot answer digest challenges
+)
+
+// Sentinel errors
+var (
+	ErrUserNotFound = errors.New("credentials: user not found")
+	ErrNoSecret     = errors.New("credentials: password or hash required")
+)
+
+// Credential is a user's stored secret, as kept in the credentials file.
+type Credential struct {
+	User      string    `json:"user"`
+	Realm     string    `json:"realm,omitempty"`      // Digest realm (default: the server's)
+	HA1       string    `json:"ha1,omitempty"`        // MD5(user:realm:password), hex
+	HA1SHA256 string    `json:"ha1_sha256,omitempty"` // SHA-256(user:realm:password), hex
+	Bcrypt    string    `json:"bcrypt,omitempty"`     // bcrypt of the password
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Password is only read: it is hashed on load and never written back
+	Password string `json:"password,omitempty"`
+}
+
+// Info describes a credential without its secrets.
+type Info struct {
+	User       string    `json:"user"`
+	Realm      string    `json:"realm"`
+	Algorithms []string  `json:"algorithms,omitempty"` // Digest algorithms the user can answer
+	Bcrypt     bool      `json:"bcrypt,omitempty"`
+	UpdatedAt  time.Time `json:"updated_at"`
+}
+
+// Secret sets a user's credential, from a password or from hashes
+// computed elsewhere (a migration from another registrar).
+type Secret struct {
+	User      string `json:"user"`
+	Realm     string `json:"realm,omitempty"`
+	Password  string `json:"password,omitempty"`
+	Hash      string `json:"hash,omitempty"` // How Password is stored (default: digest)
+	HA1       string `json:"ha1,omitempty"`
+	HA1SHA256 string `json:"ha1_sha256,omitempty"`
+	Bcrypt    string `json:"bcrypt,omitempty"`
+}
+
+// credential hashes a secret for the default realm
+func (sec Secret) credential(realm string, now time.Time) (Credential, error) {
+	if sec.User == "" || strings.ContainsAny(sec.User, ":/") {
+		return Credential{}, fmt.Errorf("credentials: invalid user %q", sec.User)
+	}
+	c := Credential{User: sec.User, Realm: sec.Realm, UpdatedAt: now}
+	if c.Realm == realm {
+		c.Realm = ""
+	}
+	digestRealm := realm
+	if sec.Realm != "" {
+		digestRealm = sec.Realm
+	}
+
+	if sec.Password != "" {
+		if sec.HA1 != "" || sec.HA1SHA256 != "" || sec.Bcrypt != "" {
+			return Credential{}, fmt.Errorf("credentials: user %s: set password or hashes, not both", sec.User)
+		}
+		switch sec.Hash {
+		case "", HashDigest:
+			c.HA1 = HA1(AlgorithmMD5, sec.User, digestRealm, sec.Password)
+			c.HA1SHA256 = HA1(AlgorithmSHA256, sec.User, digestRealm, sec.Password)
+		case HashBcrypt:
+			b, err := bcrypt.GenerateFromPassword([]byte(sec.Password), bcrypt.DefaultCost)
+			if err != nil {
+				return Credential{}, fmt.Errorf("credentials: user %s: %w", sec.User, err)
+			}
+			c.Bcrypt = string(b)
+		default:
+			return Credential{}, fmt.Errorf("credentials: user %s: unknown hash %q", sec.User, sec.Hash)
+		}
+		return c, nil
+	}
+
+	c.HA1 = strings.ToLower(sec.HA1)
+	c.HA1SHA256 = strings.ToLower(sec.HA1SHA256)
+	c.Bcrypt = sec.Bcrypt
+	if err := c.validate(); err != nil {
+		return Credential{}, err
+	}
+	return c, nil
+}
+
+// validate checks the stored hashes
+func (c *Credential) validate() error {
+	if c.HA1 == "" && c.HA1SHA256 == "" && c.Bcrypt == "" {
+		return fmt.Errorf("%w: user %s", ErrNoSecret, c.User)
+	}
+	if c.HA1 != "" && !isHex(c.HA1, md5.Size) {
+		return fmt.Errorf("credentials: user %s: ha1 is not an MD5 hex digest", c.User)
+	}
+	if c.HA1SHA256 != "" && !isHex(c.HA1SHA256, sha256.Size) {
+		return fmt.Errorf("credentials: user %s: ha1_sha256 is not a SHA-256 hex digest", c.User)
+	}
+	if c.Bcrypt != "" {
+		if _, err := bcrypt.Cost([]byte(c.Bcrypt)); err != nil {
+			return fmt.Errorf("credentials: user %s: bcrypt: %w", c.User, err)
+		}
+	}
+	return nil
+}
+
+// info describes the credential for the default realm
+func (c *Credential) info(realm string) Info {
+	in := Info{User: c.User, Realm: realm, Bcrypt: c.Bcrypt != "", UpdatedAt: c.UpdatedAt}
+	if c.Realm != "" {
+		in.Realm = c.Realm
+	}
+	if c.HA1SHA256 != "" {
+		in.Algorithms = append(in.Algorithms, AlgorithmSHA256)
+	}
+	if c.HA1 != "" {
+		in.Algorithms = append(in.Algorithms, AlgorithmMD5)
+	}
+	return in
+}
+
+// HA1 returns the digest HA1 of a password for algorithm as hex
+func HA1(algorithm, user, realm, password string) string {
+	return hexHash(algorithm, user+":"+realm+":"+password)
+}
+
+func hexHash(algorithm, s string) string {
+	var h hash.Hash
+	if algorithm == AlgorithmSHA256 {
+		h = sha256.New()
+	} else {
+		h = md5.New()
+	}
+	h.Write([]byte(s))
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+func isHex(s string, size int) bool {
+	if len(s) != size*2 {
+		return false
+	}
+	_, err := hex.DecodeString(s)
+	return err == nil
+}
+
+// Config is the on-disk form of the store.
+type Config struct {
+	Users []Credential `json:"users"`
+}
+
+// Store holds the credentials of users. Changes made through the
+// provisioning API are written back to the credentials file when one is
+// set. Safe for concurrent use.
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	realm string
+	users map[string]*Credential
+}
+
+// NewStore creates an empty store for a default realm.
+func NewStore(realm string) *Store {
+	return &Store{realm: realm, users: make(map[string]*Credential)}
+}
+
+// Load creates a store from a credentials file. A missing file yields an
+// empty store that is saved to path on the first change.
+func Load(path, realm string) (*Store, error) {
+	s := NewStore(realm)
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read credentials: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse credentials: %w", err)
+	}
+	hashed := false
+	for i := range cfg.Users {
+		c := cfg.Users[i]
+		if c.Password != "" {
+			// Plaintext from a hand-written file: hash it and rewrite
+			var err error
+			c, err = Secret{User: c.User, Realm: c.Realm, Password: c.Password}.credential(realm, time.Now().UTC())
+			if err != nil {
+				return nil, err
+			}
+			hashed = true
+		}
+		if err := c.validate(); err != nil {
+			return nil, err
+		}
+		if _, dup := s.users[c.User]; dup {
+			return nil, fmt.Errorf("credentials: user %s defined twice", c.User)
+		}
+		s.users[c.User] = &c
+	}
+	if hashed {
+		if err := s.saveLocked(); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// Realm returns the default realm
+func (s *Store) Realm() string {
+	return s.realm
+}
+
+// Get describes a user's credential.
+func (s *Store) Get(user string) (Info, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, ok := s.users[user]
+	if !ok {
+		return Info{}, fmt.Errorf("%w: %s", ErrUserNotFound, user)
+	}
+	return c.info(s.realm), nil
+}
+
+// List describes all credentials sorted by user.
+func (s *Store) List() []Info {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Info, 0, len(s.users))
+	for _, c := range s.users {
+		out = append(out, c.info(s.realm))
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].User < out[j].User })
+	return out
+}
+
+// Put adds or replaces a user's credential.
+func (s *Store) Put(sec Secret) (Info, error) {
+	c, err := sec.credential(s.realm, time.Now().UTC())
+	if err != nil {
+		return Info{}, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[c.User] = &c
+	return c.info(s.realm), s.saveLocked()
+}
+
+// Import adds or replaces many credentials at once. Nothing is stored
+// unless every secret is valid. With replace, users not imported are
+// removed.
+func (s *Store) Import(secrets []Secret, replace bool) (int, error) {
+	now := time.Now().UTC()
+	imported := make(map[string]*Credential, len(secrets))
+	for i, sec := range secrets {
+		c, err := sec.credential(s.realm, now)
+		if err != nil {
+			return 0, fmt.Errorf("entry %d: %w", i, err)
+		}
+		if _, dup := imported[c.User]; dup {
+			return 0, fmt.Errorf("entry %d: user %s imported twice", i, c.User)
+		}
+		imported[c.User] = &c
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if replace {
+		s.users = imported
+	} else {
+		for user, c := range imported {
+			s.users[user] = c
+		}
+	}
+	return len(imported), s.saveLocked()
+}
+
+// Delete removes a user's credential.
+func (s *Store) Delete(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user]; !ok {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, user)
+	}
+	delete(s.users, user)
+	return s.saveLocked()
+}
+
+// Has reports whether a user has a credential.
+func (s *Store) Has(user string) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	_, ok := s.users[user]
+	return ok
+}
+
+// digest returns a user's realm and HA1 for algorithm; ok is false if the
+// user cannot answer a digest challenge with it
+func (s *Store) digest(user, algorithm string) (realm, ha1 string, ok bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	c, found := s.users[user]
+	if !found {
+		return "", "", false
+	}
+	realm = s.realm
+	if c.Realm != "" {
+		realm = c.Realm
+	}
+	if algorithm == AlgorithmSHA256 {
+		return realm, c.HA1SHA256, c.HA1SHA256 != ""
+	}
+	return realm, c.HA1, c.HA1 != ""
+}
+
+// Verify reports whether password is a user's password.
+func (s *Store) Verify(user, password string) bool {
+	s.mu.RLock()
+	c, ok := s.users[user]
+	s.mu.RUnlock()
+	if !ok {
+		return false
+	}
+
+	if c.Bcrypt != "" {
+		return bcrypt.CompareHashAndPassword([]byte(c.Bcrypt), []byte(password)) == nil
+	}
+	realm := s.realm
+	if c.Realm != "" {
+		realm = c.Realm
+	}
+	if c.HA1SHA256 != "" {
+		return subtle.ConstantTimeCompare([]byte(HA1(AlgorithmSHA256, user, realm, password)), []byte(c.HA1SHA256)) == 1
+	}
+	return subtle.ConstantTimeCompare([]byte(HA1(AlgorithmMD5, user, realm, password)), []byte(c.HA1)) == 1
+}
+
+// saveLocked writes the store to its credentials file, if any (must hold
+// lock). The file is only readable by its owner.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	cfg := Config{Users: make([]Credential, 0, len(s.users))}
+	for _, c := range s.users {
+		cfg.Users = append(cfg.Users, *c)
+	}
+	sort.Slice(cfg.Users, func(i, j int) bool { return cfg.Users[i].User < cfg.Users[j].User })
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode credentials: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write credentials: %w", err)
+	}
+	return nil
+}
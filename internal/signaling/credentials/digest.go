@@ -0,0 +1,194 @@
+package credentials
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// DefaultNonceLifetime is how long a challenge's nonce is accepted
+const DefaultNonceLifetime = 5 * time.Minute
+
+// Digest authentication errors
+var (
+	ErrMalformed = errors.New("credentials: malformed authorization")
+	ErrStale     = errors.New("credentials: stale nonce")
+	ErrDenied    = errors.New("credentials: wrong credentials")
+)
+
+// Authenticator challenges requests and checks their digest responses
+// (RFC 3261 Section 22, RFC 8760). Nonces carry their issue time and a
+// MAC, so no state is kept between a challenge and its answer.
+type Authenticator struct {
+	store     *Store
+	key       []byte
+	lifetime  time.Duration
+	emergency func(destination string) bool
+}
+
+// NewAuthenticator returns an authenticator for the users of store.
+// emergency, when set, tells which destinations are emergency numbers;
+// calls to them are never challenged or refused.
+func NewAuthenticator(store *Store, emergency func(destination string) bool) *Authenticator {
+	key := make([]byte, 32)
+	_, _ = rand.Read(key)
+	return &Authenticator{store: store, key: key, lifetime: DefaultNonceLifetime, emergency: emergency}
+}
+
+// Store returns the credentials checked
+func (a *Authenticator) Store() *Store {
+	return a.store
+}
+
+// Challenges returns the challenge header values for a user, strongest
+// algorithm first. Unknown users are challenged as if they had an MD5
+// credential in the default realm, so challenges do not reveal which
+// users exist.
+func (a *Authenticator) Challenges(user string, stale bool, now time.Time) []string {
+	var out []string
+	nonce := a.nonce(now)
+	for _, alg := range []string{AlgorithmSHA256, AlgorithmMD5} {
+		realm, _, ok := a.store.digest(user, alg)
+		if !ok {
+			continue
+		}
+		out = append(out, challenge(realm, nonce, alg, stale))
+	}
+	if len(out) == 0 {
+		out = append(out, challenge(a.store.Realm(), nonce, AlgorithmMD5, stale))
+	}
+	return out
+}
+
+func challenge(realm, nonce, algorithm string, stale bool) string {
+	v := fmt.Sprintf(`Digest realm="%s", nonce="%s", algorithm=%s, qop="auth"`, realm, nonce, algorithm)
+	if stale {
+		v += ", stale=true"
+	}
+	return v
+}
+
+// Check verifies an Authorization or Proxy-Authorization header value
+// answering a challenge for a request of method, and returns the user it
+// authenticates. ErrStale means the credentials were right but the nonce
+// has expired; the client retries with a fresh one.
+func (a *Authenticator) Check(method, authorization string, now time.Time) (string, error) {
+	params, ok := parseDigest(authorization)
+	if !ok {
+		return "", ErrMalformed
+	}
+	user, nonce, uri, response := params["username"], params["nonce"], params["uri"], params["response"]
+	if user == "" || nonce == "" || uri == "" || response == "" {
+		return "", ErrMalformed
+	}
+	algorithm := params["algorithm"]
+	switch strings.ToUpper(algorithm) {
+	case "", AlgorithmMD5:
+		algorithm = AlgorithmMD5
+	case AlgorithmSHA256:
+		algorithm = AlgorithmSHA256
+	default:
+		return user, fmt.Errorf("%w: algorithm %s", ErrMalformed, algorithm)
+	}
+
+	realm, ha1, ok := a.store.digest(user, algorithm)
+	if !ok || params["realm"] != realm {
+		return user, ErrDenied
+	}
+
+	ha2 := hexHash(algorithm, method+":"+uri)
+	var expected string
+	switch qop := params["qop"]; qop {
+	case "":
+		expected = hexHash(algorithm, ha1+":"+nonce+":"+ha2)
+	case "auth":
+		expected = hexHash(algorithm, ha1+":"+nonce+":"+params["nc"]+":"+params["cnonce"]+":"+qop+":"+ha2)
+	default:
+		return user, fmt.Errorf("%w: qop %s", ErrMalformed, qop)
+	}
+	if subtle.ConstantTimeCompare([]byte(strings.ToLower(response)), []byte(expected)) != 1 {
+		return user, ErrDenied
+	}
+
+	issued, ok := a.nonceTime(nonce)
+	if !ok {
+		return user, ErrDenied
+	}
+	if age := now.Sub(issued); age < 0 || age > a.lifetime {
+		return user, ErrStale
+	}
+	return user, nil
+}
+
+// nonce is the issue time followed by its truncated HMAC
+func (a *Authenticator) nonce(now time.Time) string {
+	b := make([]byte, 8, 24)
+	binary.BigEndian.PutUint64(b, uint64(now.UnixNano()))
+	return base64.RawURLEncoding.EncodeToString(append(b, a.mac(b)...))
+}
+
+// nonceTime returns when a nonce was issued; ok is false if it was not
+// issued by this authenticator
+func (a *Authenticator) nonceTime(nonce string) (time.Time, bool) {
+	b, err := base64.RawURLEncoding.DecodeString(nonce)
+	if err != nil || len(b) != 24 {
+		return time.Time{}, false
+	}
+	if !hmac.Equal(b[8:], a.mac(b[:8])) {
+		return time.Time{}, false
+	}
+	return time.Unix(0, int64(binary.BigEndian.Uint64(b[:8]))), true
+}
+
+func (a *Authenticator) mac(b []byte) []byte {
+	m := hmac.New(sha256.New, a.key)
+	m.Write(b)
+	return m.Sum(nil)[:16]
+}
+
+// parseDigest splits the parameters of a Digest credential, unquoting
+// quoted values
+func parseDigest(v string) (map[string]string, bool) {
+	scheme, rest, ok := strings.Cut(strings.TrimSpace(v), " ")
+	if !ok || !strings.EqualFold(scheme, "Digest") {
+		return nil, false
+	}
+
+	params := make(map[string]string)
+	for rest = strings.TrimSpace(rest); rest != ""; {
+		name, after, ok := strings.Cut(rest, "=")
+		if !ok {
+			return nil, false
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		after = strings.TrimLeft(after, " \t")
+
+		var value string
+		if strings.HasPrefix(after, `"`) {
+			end := strings.IndexByte(after[1:], '"')
+			if end < 0 {
+				return nil, false
+			}
+			value, after = after[1:end+1], after[end+2:]
+		} else {
+			value, after, _ = strings.Cut(after, ",")
+			value = strings.TrimSpace(value)
+			after = "," + after
+		}
+		params[name] = value
+
+		after = strings.TrimLeft(after, " \t")
+		if after != "" && after != "," && !strings.HasPrefix(after, ",") {
+			return nil, false
+		}
+		rest = strings.TrimSpace(strings.TrimPrefix(after, ","))
+	}
+	return params, true
+}
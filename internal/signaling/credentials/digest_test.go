@@ -0,0 +1,94 @@
+package credentials
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// answer computes the Authorization value a phone sends for a challenge
+func answer(t *testing.T, challenge, user, password, method, uri string) string {
+	t.Helper()
+	params, ok := parseDigest(challenge)
+	if !ok {
+		t.Fatalf("unparsable challenge %q", challenge)
+	}
+	alg := params["algorithm"]
+	ha1 := HA1(alg, user, params["realm"], password)
+	ha2 := hexHash(alg, method+":"+uri)
+	response := hexHash(alg, ha1+":"+params["nonce"]+":00000001:c0ffee:auth:"+ha2)
+	return fmt.Sprintf(`Digest username="%s", realm="%s", nonce="%s", uri="%s", algorithm=%s, qop=auth, nc=00000001, cnonce="c0ffee", response="%s"`,
+		user, params["realm"], params["nonce"], uri, alg, response)
+}
+
+func TestDigest(t *testing.T) {
+	store := NewStore("pbx.example.com")
+	if _, err := store.Put(Secret{User: "1001", Password: "s3cret"}); err != nil {
+		t.Fatal(err)
+	}
+	a := NewAuthenticator(store, nil)
+	now := time.Now()
+
+	challenges := a.Challenges("1001", false, now)
+	if len(challenges) != 2 || !strings.Contains(challenges[0], "algorithm=SHA-256") {
+		t.Fatalf("challenges = %q, want SHA-256 then MD5", challenges)
+	}
+	for _, c := range challenges {
+		user, err := a.Check("REGISTER", answer(t, c, "1001", "s3cret", "REGISTER", "sip:pbx.example.com"), now)
+		if err != nil || user != "1001" {
+			t.Errorf("%s: Check = %q, %v", c, user, err)
+		}
+	}
+
+	if _, err := a.Check("REGISTER", answer(t, challenges[0], "1001", "guess", "REGISTER", "sip:pbx.example.com"), now); !errors.Is(err, ErrDenied) {
+		t.Errorf("wrong password: err = %v, want ErrDenied", err)
+	}
+	if _, err := a.Check("INVITE", answer(t, challenges[0], "1001", "s3cret", "REGISTER", "sip:pbx.example.com"), now); !errors.Is(err, ErrDenied) {
+		t.Errorf("replayed for another method: err = %v, want ErrDenied", err)
+	}
+	if _, err := a.Check("REGISTER", answer(t, challenges[0], "1001", "s3cret", "REGISTER", "sip:pbx.example.com"), now.Add(DefaultNonceLifetime+time.Second)); !errors.Is(err, ErrStale) {
+		t.Errorf("expired nonce: err = %v, want ErrStale", err)
+	}
+	if _, err := store.Put(Secret{User: "portal", Password: "s3cret", Hash: HashBcrypt}); err != nil {
+		t.Fatal(err)
+	}
+	if !a.canDigest("1001") || a.canDigest("portal") || a.canDigest("1002") {
+		t.Error("canDigest: want true for digest credentials only")
+	}
+
+	forged := NewAuthenticator(store, nil).Challenges("1001", false, now)[0]
+	if _, err := a.Check("REGISTER", answer(t, forged, "1001", "s3cret", "REGISTER", "sip:pbx.example.com"), now); !errors.Is(err, ErrDenied) {
+		t.Errorf("nonce of another server: err = %v, want ErrDenied", err)
+	}
+}
+
+func TestLoadHashesPlaintext(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "credentials.json")
+	store, err := Load(path, "pbx.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := store.Import([]Secret{
+		{User: "1001", Password: "one"},
+		{User: "1002", Password: "two", Hash: HashBcrypt},
+	}, false); err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded, err := Load(path, "pbx.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reloaded.Verify("1001", "one") || !reloaded.Verify("1002", "two") || reloaded.Verify("1002", "one") {
+		t.Error("reloaded credentials do not verify")
+	}
+	if info, _ := reloaded.Get("1002"); !info.Bcrypt || len(info.Algorithms) != 0 {
+		t.Errorf("bcrypt credential info = %+v", info)
+	}
+	if _, err := store.Import([]Secret{{User: "1003", Password: "x"}, {User: "bad:user", Password: "y"}}, true); err == nil || store.Has("1003") || !store.Has("1001") {
+		t.Error("invalid import was partly applied")
+	}
+}
@@ -0,0 +1,100 @@
+package credentials
+
+import (
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+	"github.com/sebas/switchboard/internal/signaling/trunks"
+)
+
+// Middleware authenticates REGISTERs, challenging them with 401, and
+// initial INVITEs whose caller (From user) has a digest credential,
+// challenging them with 407. Calls from other callers, bcrypt-only users
+// included, from identified trunks and to emergency numbers are passed
+// unchanged. The authenticated user must be the one registering or
+// calling; a credential for another user is refused with 403.
+func (a *Authenticator) Middleware() middleware.Middleware {
+	return middleware.Func("auth", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		var user, header, challengeHeader string
+		var status sip.StatusCode
+		var reason string
+		switch {
+		case req.Method == sip.REGISTER:
+			to := req.To()
+			if to == nil {
+				next(req, tx)
+				return
+			}
+			user = to.Address.User
+			if a.store.Has(user) && !a.canDigest(user) {
+				// Challenged like unknown users, but the challenge can never
+				// be answered
+				slog.Warn("[Auth] REGISTER from a user with a bcrypt-only credential", "user", user, "source", req.Source())
+			}
+			header, challengeHeader = "Authorization", "WWW-Authenticate"
+			status, reason = sip.StatusUnauthorized, "Unauthorized"
+		case req.Method == sip.INVITE:
+			from, to := req.From(), req.To()
+			if from == nil || to == nil || middleware.Annotation(req, trunks.Annotation) != "" {
+				next(req, tx)
+				return
+			}
+			if _, inDialog := to.Params.Get("tag"); inDialog || !a.canDigest(from.Address.User) {
+				next(req, tx)
+				return
+			}
+			if a.emergency != nil && a.emergency(to.Address.User) {
+				slog.Info("[Auth] Emergency call let through unauthenticated", "user", from.Address.User, "destination", to.Address.User, "source", req.Source())
+				next(req, tx)
+				return
+			}
+			user = from.Address.User
+			header, challengeHeader = "Proxy-Authorization", "Proxy-Authenticate"
+			status, reason = sip.StatusProxyAuthRequired, "Proxy Authentication Required"
+		default:
+			next(req, tx)
+			return
+		}
+
+		now := time.Now()
+		stale := false
+		if h := req.GetHeader(header); h != nil {
+			authUser, err := a.Check(string(req.Method), h.Value(), now)
+			switch {
+			case err == nil && authUser == user:
+				next(req, tx)
+				return
+			case err == nil:
+				slog.Warn("[Auth] Credentials of another user", "method", req.Method, "user", user, "auth_user", authUser, "source", req.Source())
+				middleware.Reject(req, tx, sip.StatusForbidden, "Forbidden")
+				return
+			case errors.Is(err, ErrStale):
+				stale = true
+			default:
+				slog.Info("[Auth] Authentication failed", "method", req.Method, "user", user, "source", req.Source(), "error", err)
+			}
+		}
+
+		res := sip.NewResponseFromRequest(req, status, reason, nil)
+		for _, v := range a.Challenges(user, stale, now) {
+			res.AppendHeader(sip.NewHeader(challengeHeader, v))
+		}
+		if err := tx.Respond(res); err != nil {
+			slog.Warn("[Auth] Failed to send challenge", "method", req.Method, "user", user, "error", err)
+		}
+	})
+}
+
+// canDigest reports whether a user has a credential that can answer a
+// digest challenge; bcrypt-only credentials cannot.
+func (a *Authenticator) canDigest(user string) bool {
+	for _, alg := range []string{AlgorithmSHA256, AlgorithmMD5} {
+		if _, _, ok := a.store.digest(user, alg); ok {
+			return true
+		}
+	}
+	return false
+}
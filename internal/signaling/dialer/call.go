@@ -0,0 +1,300 @@
+package dialer
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+const (
+	// agentRetryDelay is how long an agent who failed to answer is not
+	// dialed again
+	agentRetryDelay = 5 * time.Second
+
+	// agentPollInterval is how often a call waiting for an agent checks
+	// for agents whose retry delay is over
+	agentPollInterval = 500 * time.Millisecond
+
+	// detectGrace is how long machine detection may take beyond its
+	// analysis time before the call is treated as undecided
+	detectGrace = time.Second
+)
+
+// call calls a number of a campaign run, connects it to an agent if a
+// person answers, and records how it went.
+func (d *Dialer) call(c *campaign, r *run, number string, trunk int) {
+	result := Result{Number: number}
+	dialing := true
+	defer func() {
+		result.Time = time.Now()
+		d.mu.Lock()
+		defer d.mu.Unlock()
+		if dialing {
+			c.dialing--
+		}
+		r.results = append(r.results, result)
+		switch result.Outcome {
+		case OutcomeConnected:
+			r.stats.Connected++
+		case OutcomeAbandoned:
+			r.stats.Abandoned++
+		case OutcomeBusy, OutcomeNoAnswer, OutcomeFailed:
+			r.stats.Failed++
+		}
+	}()
+
+	leg, err := d.dial(r.ctx, &c.Campaign, number, trunk, &result)
+	if err != nil {
+		result.Outcome = dialOutcome(result.SIPCode, err)
+		slog.Info("[Dialer] Call failed", "campaign", c.Name, "number", number, "sip_code", result.SIPCode, "error", err)
+		return
+	}
+	result.CallID = leg.CallID()
+	d.count(func() { r.stats.Answered++ })
+	defer func() {
+		if leg.Context().Err() == nil {
+			_ = leg.Hangup(context.Background(), b2bua.TerminationCauseNormal)
+		}
+	}()
+
+	// The call ends with the campaign, or when the far end hangs up
+	ctx, cancel := context.WithCancel(r.ctx)
+	defer cancel()
+	stop := context.AfterFunc(leg.Context(), cancel)
+	defer stop()
+
+	if c.AMD != nil {
+		result.AnsweredBy = d.detect(ctx, c.AMD, leg)
+		machine := result.AnsweredBy == mediaclient.AnsweredByMachine ||
+			(result.AnsweredBy == mediaclient.AnsweredByUnknown && c.AMD.UnknownAsMachine)
+		if machine && c.AMD.Action != MachineConnect {
+			result.Outcome = OutcomeMachine
+			d.count(func() { r.stats.Machines++ })
+			if c.AMD.Action == MachineMessage {
+				if err := playFile(ctx, d.media, leg.SessionID(), c.AMD.Message); err != nil {
+					slog.Warn("[Dialer] Machine message failed", "campaign", c.Name, "call_id", leg.CallID(), "error", err)
+				}
+			}
+			slog.Info("[Dialer] Machine answered", "campaign", c.Name, "number", number, "call_id", leg.CallID(), "action", c.AMD.Action)
+			return
+		}
+		if ctx.Err() != nil {
+			result.Outcome = OutcomeHangup
+			if r.ctx.Err() != nil {
+				result.Outcome = OutcomeCanceled
+			}
+			return
+		}
+	}
+	d.count(func() { r.stats.Humans++ })
+
+	agent, bridge, err := d.connect(ctx, c, leg, number)
+	if err != nil {
+		result.Outcome = OutcomeAbandoned
+		if r.ctx.Err() != nil && leg.Context().Err() == nil {
+			result.Outcome = OutcomeCanceled
+		}
+		slog.Info("[Dialer] Call abandoned", "campaign", c.Name, "number", number, "call_id", leg.CallID(), "error", err)
+		if result.Outcome == OutcomeAbandoned && c.AbandonPrompt != "" && leg.Context().Err() == nil {
+			if err := playFile(leg.Context(), d.media, leg.SessionID(), c.AbandonPrompt); err != nil {
+				slog.Warn("[Dialer] Abandon prompt failed", "campaign", c.Name, "call_id", leg.CallID(), "error", err)
+			}
+		}
+		return
+	}
+	result.Outcome = OutcomeConnected
+	result.Agent = agent
+	slog.Info("[Dialer] Call connected to agent", "campaign", c.Name, "number", number, "agent", agent, "bridge_id", bridge.ID())
+
+	d.count(func() {
+		dialing = false
+		c.dialing--
+		c.talking++
+	})
+	// Talks continue when the campaign stops
+	_, _ = bridge.WaitForTermination(context.Background())
+	d.release(agent, 0)
+	d.count(func() { c.talking-- })
+}
+
+// count updates the progress of a campaign.
+func (d *Dialer) count(update func()) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	update()
+}
+
+// dial calls a number through the campaign's trunks, from trunk on, until
+// one answers or fails with a code other than a 5xx or no response. The
+// dial target and code of the last attempt are set on result.
+func (d *Dialer) dial(ctx context.Context, c *Campaign, number string, trunk int, result *Result) (b2bua.Leg, error) {
+	var err error
+	for i := range len(c.Trunks) {
+		result.Trunk = c.trunkTarget(trunk+i, number)
+		var leg b2bua.Leg
+		leg, err = d.calls.Dial(ctx, result.Trunk, c.ringTimeout(), b2bua.WithCallerID(c.callerID()))
+		if err == nil {
+			return leg, nil
+		}
+		result.SIPCode = sipCode(err)
+		if ctx.Err() != nil || (result.SIPCode != 0 && result.SIPCode/100 != 5) {
+			return nil, err
+		}
+		slog.Info("[Dialer] Trunk failed", "campaign", c.Name, "trunk", result.Trunk, "sip_code", result.SIPCode, "error", err)
+	}
+	return nil, err
+}
+
+// detect tells from its greeting whether a person or a machine answered
+// a call.
+func (d *Dialer) detect(ctx context.Context, amd *AMD, leg b2bua.Leg) mediaclient.AnsweredBy {
+	ctx, cancel := context.WithTimeout(ctx, amd.analysisTime()+detectGrace)
+	defer cancel()
+
+	// Silence is played so the RTP manager listens to the far end
+	statusCh, err := d.media.PlayTone(ctx, mediaclient.ToneRequest{
+		SessionID:     leg.SessionID(),
+		Tone:          "0",
+		DetectMachine: amd.params(),
+	})
+	if err != nil {
+		slog.Warn("[Dialer] Machine detection failed", "call_id", leg.CallID(), "error", err)
+		return mediaclient.AnsweredByUnknown
+	}
+	defer func() {
+		_ = d.media.StopAudio(context.Background(), leg.SessionID())
+		for range statusCh {
+		}
+	}()
+
+	for status := range statusCh {
+		switch status.State {
+		case mediaclient.PlayStateMachine:
+			slog.Debug("[Dialer] Machine detection", "call_id", leg.CallID(), "answered_by", status.AnsweredBy, "reason", status.MachineReason)
+			return status.AnsweredBy
+		case mediaclient.PlayStateError:
+			slog.Warn("[Dialer] Machine detection failed", "call_id", leg.CallID(), "error", status.Error)
+			return mediaclient.AnsweredByUnknown
+		}
+	}
+	return mediaclient.AnsweredByUnknown
+}
+
+// connect bridges a person who answered with the longest idle free agent
+// of the campaign, dialing agents until one answers or the abandon
+// timeout passes. Returns the agent, held until the bridge ends.
+func (d *Dialer) connect(ctx context.Context, c *campaign, leg b2bua.Leg, number string) (string, b2bua.Bridge, error) {
+	ctx, cancel := context.WithTimeout(ctx, c.abandonTimeout())
+	defer cancel()
+
+	stopMusic := func() {}
+	if c.HoldMusic != "" {
+		stopMusic = d.playLoop(ctx, leg.SessionID(), c.HoldMusic)
+	}
+	defer stopMusic()
+
+	for {
+		agent, released := d.acquire(c)
+		if agent == "" {
+			select {
+			case <-ctx.Done():
+				return "", nil, ctx.Err()
+			case <-released:
+			case <-time.After(agentPollInterval):
+			}
+			continue
+		}
+
+		// The agent is dialed on the RTP manager of the call, so the two
+		// can be bridged
+		agentLeg, err := d.calls.Dial(ctx, agent, c.agentTimeout(),
+			b2bua.WithCallerID(number),
+			b2bua.WithALegSessionID(leg.SessionID()),
+		)
+		if err != nil {
+			if ctx.Err() != nil {
+				d.release(agent, 0)
+				return "", nil, ctx.Err()
+			}
+			d.release(agent, agentRetryDelay)
+			slog.Info("[Dialer] Agent did not answer", "campaign", c.Name, "agent", agent, "error", err)
+			continue
+		}
+
+		stopMusic()
+		bridge, err := d.calls.CreateBridge(leg, agentLeg)
+		if err == nil {
+			err = bridge.Start(context.Background())
+		}
+		if err != nil {
+			_ = agentLeg.Hangup(context.Background(), b2bua.TerminationCauseNormal)
+			d.release(agent, 0)
+			return "", nil, err
+		}
+		d.connected(agent)
+		return agent, bridge, nil
+	}
+}
+
+// playLoop plays a file in a loop until the returned function is called.
+func (d *Dialer) playLoop(ctx context.Context, sessionID, file string) (stop func()) {
+	statusCh, err := d.media.PlayAudio(ctx, mediaclient.PlayRequest{SessionID: sessionID, AudioFile: file, Loop: true})
+	if err != nil {
+		slog.Warn("[Dialer] Hold music failed", "session_id", sessionID, "error", err)
+		return func() {}
+	}
+	stopped := false
+	return func() {
+		if stopped {
+			return
+		}
+		stopped = true
+		_ = d.media.StopAudio(context.Background(), sessionID)
+		for range statusCh {
+		}
+	}
+}
+
+// playFile plays a file to the end.
+func playFile(ctx context.Context, media mediaclient.Transport, sessionID, file string) error {
+	statusCh, err := media.PlayAudio(ctx, mediaclient.PlayRequest{SessionID: sessionID, AudioFile: file})
+	if err != nil {
+		return err
+	}
+	for status := range statusCh {
+		if status.State == mediaclient.PlayStateError {
+			return status.Error
+		}
+	}
+	return ctx.Err()
+}
+
+// sipCode returns the SIP code a dial failed with, 408 for a timeout, or
+// 0 without a response.
+func sipCode(err error) int {
+	var dialErr *b2bua.DialError
+	switch {
+	case errors.As(err, &dialErr) && dialErr.SIPCode > 0:
+		return dialErr.SIPCode
+	case errors.Is(err, b2bua.ErrDialTimeout), errors.Is(err, context.DeadlineExceeded):
+		return 408
+	}
+	return 0
+}
+
+// dialOutcome maps a failed dial to the outcome of its number.
+func dialOutcome(code int, err error) string {
+	switch {
+	case code == 486 || code == 600:
+		return OutcomeBusy
+	case code == 408 || code == 480 || code == 487:
+		return OutcomeNoAnswer
+	case errors.Is(err, context.Canceled):
+		return OutcomeCanceled
+	}
+	return OutcomeFailed
+}
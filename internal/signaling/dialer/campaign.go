@@ -0,0 +1,258 @@
+package dialer
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// NumberVariable is replaced by the number called in trunk dial targets
+const NumberVariable = "${number}"
+
+// Campaign defaults
+const (
+	DefaultCallsPerSecond = 1.0
+	DefaultRatio          = 1.0
+	DefaultMaxAbandonRate = 3.0 // Percent
+	DefaultRingTimeout    = 30 * time.Second
+	DefaultAgentTimeout   = 15 * time.Second
+	DefaultAbandonTimeout = 10 * time.Second
+	DefaultCallerID       = "switchboard"
+
+	// defaultAnalysisTime is the RTP manager's machine detection time
+	defaultAnalysisTime = 5 * time.Second
+)
+
+// Machine actions
+const (
+	MachineHangup  = "hangup"  // Hang up on answering machines
+	MachineMessage = "message" // Play the message to them, then hang up
+	MachineConnect = "connect" // Connect them to an agent like a person
+)
+
+// Sentinel errors
+var (
+	ErrCampaignNotFound = errors.New("dialer: campaign not found")
+	ErrCampaignActive   = errors.New("dialer: campaign is running or paused")
+	ErrInvalidState     = errors.New("dialer: invalid campaign state for operation")
+)
+
+// Campaign is an outbound calling campaign: its numbers are called
+// through its trunks and the people who answer are connected to its
+// agents.
+type Campaign struct {
+	Name    string   `json:"name"`
+	Numbers []string `json:"numbers"` // Called in order
+
+	// Trunks are dial targets with ${number}, e.g.
+	// "sip:${number}@carrier-a.example.net"; calls are spread across
+	// them, and moved to the next one when one fails with a 5xx or no
+	// response
+	Trunks []string `json:"trunks"`
+
+	// Agents are the dial targets ("user/1001") people who answer are
+	// connected to, longest idle first
+	Agents []string `json:"agents"`
+
+	CallerID       string  `json:"caller_id,omitempty"`        // From user of the calls (default "switchboard")
+	CallsPerSecond float64 `json:"calls_per_second,omitempty"` // Pace of new calls (default 1)
+	MaxConcurrent  int     `json:"max_concurrent,omitempty"`   // Calls in progress, including those with agents (0 = no limit)
+	Ratio          float64 `json:"ratio,omitempty"`            // Calls dialed per free agent (default 1)
+	MaxAbandonRate float64 `json:"max_abandon_rate,omitempty"` // Percent of people answering who may be abandoned before the ratio drops to 1 (default 3)
+	RingTimeout    int     `json:"ring_timeout,omitempty"`     // Seconds to ring each number (default 30)
+	AgentTimeout   int     `json:"agent_timeout,omitempty"`    // Seconds to ring an agent (default 15)
+	AbandonTimeout int     `json:"abandon_timeout,omitempty"`  // Seconds a person waits for an agent before being abandoned (default 10)
+	HoldMusic      string  `json:"hold_music,omitempty"`       // Played while an agent is connected (silence if empty)
+	AbandonPrompt  string  `json:"abandon_prompt,omitempty"`   // Played to abandoned calls before hanging up
+
+	// AMD detects answering machines before connecting agents (none if nil)
+	AMD *AMD `json:"amd,omitempty"`
+}
+
+// AMD configures answering machine detection. Timings are in
+// milliseconds; zero takes the RTP manager's default.
+type AMD struct {
+	Action  string `json:"action,omitempty"`  // "hangup" (default), "message" or "connect"
+	Message string `json:"message,omitempty"` // Played to machines with action "message"
+
+	// UnknownAsMachine treats calls the detector could not decide on as
+	// machines; by default they are connected like people
+	UnknownAsMachine bool `json:"unknown_as_machine,omitempty"`
+
+	InitialSilence       int `json:"initial_silence,omitempty"`        // Silence before any voice that makes the call a machine (2500)
+	Greeting             int `json:"greeting,omitempty"`               // Voice longer than this makes the call a machine (1500)
+	AfterGreetingSilence int `json:"after_greeting_silence,omitempty"` // Silence after the greeting that makes the call a person (800)
+	TotalAnalysis        int `json:"total_analysis,omitempty"`         // Analysis time after which no decision is made (5000)
+	MaximumWords         int `json:"maximum_words,omitempty"`          // Words that make the call a machine (3)
+}
+
+// Validate checks that the campaign is usable.
+func (c *Campaign) Validate() error {
+	if c.Name == "" || strings.Contains(c.Name, "/") {
+		return fmt.Errorf("dialer: invalid campaign name %q", c.Name)
+	}
+	if len(c.Numbers) == 0 {
+		return fmt.Errorf("dialer: campaign %s: numbers required", c.Name)
+	}
+	if len(c.Trunks) == 0 {
+		return fmt.Errorf("dialer: campaign %s: trunks required", c.Name)
+	}
+	for _, trunk := range c.Trunks {
+		if !strings.Contains(trunk, NumberVariable) {
+			return fmt.Errorf("dialer: campaign %s: trunk %q has no %s", c.Name, trunk, NumberVariable)
+		}
+	}
+	if len(c.Agents) == 0 {
+		return fmt.Errorf("dialer: campaign %s: agents required", c.Name)
+	}
+	if c.CallsPerSecond < 0 || c.MaxConcurrent < 0 || c.MaxAbandonRate < 0 ||
+		c.RingTimeout < 0 || c.AgentTimeout < 0 || c.AbandonTimeout < 0 {
+		return fmt.Errorf("dialer: campaign %s: settings cannot be negative", c.Name)
+	}
+	if c.Ratio != 0 && c.Ratio < 1 {
+		return fmt.Errorf("dialer: campaign %s: ratio must be at least 1", c.Name)
+	}
+	if a := c.AMD; a != nil {
+		if a.InitialSilence < 0 || a.Greeting < 0 || a.AfterGreetingSilence < 0 || a.TotalAnalysis < 0 || a.MaximumWords < 0 {
+			return fmt.Errorf("dialer: campaign %s: amd: settings cannot be negative", c.Name)
+		}
+		switch a.Action {
+		case "", MachineHangup, MachineConnect:
+		case MachineMessage:
+			if a.Message == "" {
+				return fmt.Errorf("dialer: campaign %s: amd: message required", c.Name)
+			}
+		default:
+			return fmt.Errorf("dialer: campaign %s: amd: invalid action %q", c.Name, a.Action)
+		}
+	}
+	return nil
+}
+
+func (c *Campaign) callerID() string {
+	if c.CallerID == "" {
+		return DefaultCallerID
+	}
+	return c.CallerID
+}
+
+// interval returns the time between two new calls.
+func (c *Campaign) interval() time.Duration {
+	cps := c.CallsPerSecond
+	if cps == 0 {
+		cps = DefaultCallsPerSecond
+	}
+	return time.Duration(float64(time.Second) / cps)
+}
+
+func (c *Campaign) ratio() float64 {
+	if c.Ratio == 0 {
+		return DefaultRatio
+	}
+	return c.Ratio
+}
+
+func (c *Campaign) maxAbandonRate() float64 {
+	if c.MaxAbandonRate == 0 {
+		return DefaultMaxAbandonRate
+	}
+	return c.MaxAbandonRate
+}
+
+func (c *Campaign) ringTimeout() time.Duration {
+	return seconds(c.RingTimeout, DefaultRingTimeout)
+}
+
+func (c *Campaign) agentTimeout() time.Duration {
+	return seconds(c.AgentTimeout, DefaultAgentTimeout)
+}
+
+func (c *Campaign) abandonTimeout() time.Duration {
+	return seconds(c.AbandonTimeout, DefaultAbandonTimeout)
+}
+
+// trunkTarget returns the dial target of a number through trunk i.
+func (c *Campaign) trunkTarget(i int, number string) string {
+	return strings.ReplaceAll(c.Trunks[i%len(c.Trunks)], NumberVariable, number)
+}
+
+func seconds(n int, def time.Duration) time.Duration {
+	if n == 0 {
+		return def
+	}
+	return time.Duration(n) * time.Second
+}
+
+// params returns the detection settings for the RTP manager.
+func (a *AMD) params() *mediaclient.MachineDetectionParams {
+	ms := func(n int) time.Duration { return time.Duration(n) * time.Millisecond }
+	return &mediaclient.MachineDetectionParams{
+		InitialSilence:       ms(a.InitialSilence),
+		Greeting:             ms(a.Greeting),
+		AfterGreetingSilence: ms(a.AfterGreetingSilence),
+		TotalAnalysis:        ms(a.TotalAnalysis),
+		MaximumWords:         a.MaximumWords,
+	}
+}
+
+// analysisTime returns how long the detector may take to decide.
+func (a *AMD) analysisTime() time.Duration {
+	if a.TotalAnalysis == 0 {
+		return defaultAnalysisTime
+	}
+	return time.Duration(a.TotalAnalysis) * time.Millisecond
+}
+
+// lines returns how many calls a campaign may have dialing, detecting or
+// waiting for an agent: ratio lines per free agent, within max
+// concurrent calls in all.
+func lines(free int, ratio float64, maxConcurrent, talking int) int {
+	n := int(math.Ceil(float64(free) * ratio))
+	if maxConcurrent > 0 {
+		n = min(n, maxConcurrent-talking)
+	}
+	return max(n, 0)
+}
+
+// Config is the on-disk form of the campaigns.
+type Config struct {
+	Campaigns []Campaign `json:"campaigns"`
+}
+
+// loadConfig reads campaigns from a JSON file; a missing file has none.
+func loadConfig(path string) ([]Campaign, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read dialer config: %w", err)
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse dialer config: %w", err)
+	}
+	return cfg.Campaigns, nil
+}
+
+// saveConfig writes campaigns to a JSON file.
+func saveConfig(path string, campaigns []Campaign) error {
+	data, err := json.MarshalIndent(Config{Campaigns: campaigns}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode dialer config: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write dialer config: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("write dialer config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,528 @@
+// Package dialer runs outbound calling campaigns.
+//
+// A campaign works through a list of numbers at a set pace, calling each
+// through a pool of trunks. Answered calls may go through answering
+// machine detection on the RTP manager; machines are hung up on or left
+// a message, and the people who answer are connected to the longest idle
+// of the campaign's agents. A person no agent is connected to within the
+// abandon timeout is abandoned.
+//
+// Pacing is predictive: a campaign keeps up to ratio calls dialing per
+// free agent, within its calls per second and concurrent call limits.
+// When the share of people abandoned rises above the campaign's maximum
+// abandon rate, the ratio drops to one call per free agent until it
+// falls back below.
+package dialer
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/agents"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// tickInterval is how often running campaigns are paced
+const tickInterval = 100 * time.Millisecond
+
+// State is where a campaign is
+type State string
+
+// States of a campaign
+const (
+	StateIdle      State = "idle"      // Never started since it was saved
+	StateRunning   State = "running"   // Calling its numbers
+	StatePaused    State = "paused"    // No new calls; calls in progress continue
+	StateStopped   State = "stopped"   // Stopped before the end of its numbers
+	StateCompleted State = "completed" // Every number called
+)
+
+// Outcomes of the call to a number
+const (
+	OutcomeConnected = "connected" // Answered by a person, connected to an agent
+	OutcomeAbandoned = "abandoned" // Answered by a person no agent was connected to in time
+	OutcomeMachine   = "machine"   // Answered by a machine
+	OutcomeBusy      = "busy"
+	OutcomeNoAnswer  = "no_answer"
+	OutcomeFailed    = "failed"
+	OutcomeHangup    = "hangup"   // Hung up during machine detection
+	OutcomeCanceled  = "canceled" // The campaign was stopped
+)
+
+// Result is how the call to a number went.
+type Result struct {
+	Number     string                 `json:"number"`
+	Outcome    string                 `json:"outcome"`
+	Trunk      string                 `json:"trunk"`             // Dial target of the last attempt
+	CallID     string                 `json:"call_id,omitempty"` // Of the answered call
+	SIPCode    int                    `json:"sip_code,omitempty"`
+	AnsweredBy mediaclient.AnsweredBy `json:"answered_by,omitempty"` // With AMD
+	Agent      string                 `json:"agent,omitempty"`       // Agent the call was connected to
+	Time       time.Time              `json:"time"`                  // When the call ended
+}
+
+// Stats counts the calls of a campaign run.
+type Stats struct {
+	Dialed    int `json:"dialed"`
+	Answered  int `json:"answered"`
+	Machines  int `json:"machines"`
+	Humans    int `json:"humans"` // Including calls detection could not decide on that were connected
+	Connected int `json:"connected"`
+	Abandoned int `json:"abandoned"`
+	Failed    int `json:"failed"` // Busy, not answered or failed
+}
+
+// AbandonRate returns the percentage of people who answered and were
+// abandoned.
+func (s Stats) AbandonRate() float64 {
+	if s.Humans == 0 {
+		return 0
+	}
+	return float64(s.Abandoned) * 100 / float64(s.Humans)
+}
+
+// Status is the progress of a campaign.
+type Status struct {
+	Name        string  `json:"name"`
+	State       State   `json:"state"`
+	Numbers     int     `json:"numbers"`
+	Called      int     `json:"called"`  // Numbers dialed so far
+	Dialing     int     `json:"dialing"` // Calls dialing, detecting machines or waiting for an agent
+	Talking     int     `json:"talking"` // Calls connected to agents
+	FreeAgents  int     `json:"free_agents"`
+	Ratio       float64 `json:"ratio"`     // Calls dialed per free agent now
+	Throttled   bool    `json:"throttled"` // Ratio lowered by the abandon rate
+	AbandonRate float64 `json:"abandon_rate"`
+	Stats       Stats   `json:"stats"`
+}
+
+// campaign is a campaign with its progress
+type campaign struct {
+	Campaign
+	state   State
+	dialing int // Calls dialing, detecting machines or waiting for an agent
+	talking int // Calls connected to agents
+	run     *run
+}
+
+// run is a campaign from a start with nothing called
+type run struct {
+	ctx      context.Context
+	cancel   context.CancelFunc // Ends the calls not connected to an agent
+	next     int                // Index of the next number
+	nextCall time.Time          // When the next call may be placed
+	stats    Stats
+	results  []Result
+}
+
+// agent is the availability of an agent, shared by campaigns
+type agent struct {
+	busy      bool
+	idleSince time.Time
+	retryAt   time.Time // Not dialed before, after failing to answer
+}
+
+// Dialer runs campaigns. Safe for concurrent use.
+type Dialer struct {
+	calls b2bua.CallService
+	media mediaclient.Transport
+	path  string
+
+	ctx  context.Context // Ends every campaign's calls when Run returns
+	stop context.CancelFunc
+
+	states *agents.Registry // Agent states; agents in none are always free
+
+	mu        sync.Mutex
+	campaigns map[string]*campaign
+	agents    map[string]*agent
+	released  chan struct{} // Closed and replaced when an agent is released
+}
+
+// New creates a dialer without campaigns, calling through calls and
+// detecting machines through media.
+func New(calls b2bua.CallService, media mediaclient.Transport) *Dialer {
+	ctx, stop := context.WithCancel(context.Background())
+	return &Dialer{
+		calls:     calls,
+		media:     media,
+		ctx:       ctx,
+		stop:      stop,
+		campaigns: make(map[string]*campaign),
+		agents:    make(map[string]*agent),
+		released:  make(chan struct{}),
+	}
+}
+
+// Load creates a dialer with the campaigns of a JSON config file. A
+// missing file yields a dialer without campaigns that saves them to path
+// on the first change. Campaigns start idle.
+func Load(path string, calls b2bua.CallService, media mediaclient.Transport) (*Dialer, error) {
+	d := New(calls, media)
+	d.path = path
+
+	campaigns, err := loadConfig(path)
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range campaigns {
+		if err := c.Validate(); err != nil {
+			return nil, err
+		}
+		d.campaigns[c.Name] = &campaign{Campaign: c, state: StateIdle}
+	}
+	return d, nil
+}
+
+// SetAgents sets the agent states respected when connecting calls: only
+// available agents are dialed, and agents go to wrap-up after each call.
+func (d *Dialer) SetAgents(registry *agents.Registry) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.states = registry
+}
+
+// Run paces the running campaigns until ctx is done, then ends the calls
+// of every campaign that are not connected to an agent.
+func (d *Dialer) Run(ctx context.Context) {
+	ticker := time.NewTicker(tickInterval)
+	defer ticker.Stop()
+	defer d.stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			d.pace(now)
+		}
+	}
+}
+
+// --- Campaign management ---
+
+// Campaign returns a campaign by name.
+func (d *Dialer) Campaign(name string) (*Campaign, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	cp := c.Campaign
+	return &cp, nil
+}
+
+// PutCampaign adds or replaces a campaign, idle. A running or paused
+// campaign cannot be replaced.
+func (d *Dialer) PutCampaign(c Campaign) error {
+	if err := c.Validate(); err != nil {
+		return err
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if old, ok := d.campaigns[c.Name]; ok && old.active() {
+		return fmt.Errorf("%w: %s", ErrCampaignActive, c.Name)
+	}
+	d.campaigns[c.Name] = &campaign{Campaign: c, state: StateIdle}
+	return d.saveLocked()
+}
+
+// DeleteCampaign removes a campaign that is not running or paused.
+func (d *Dialer) DeleteCampaign(name string) error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	if c.active() {
+		return fmt.Errorf("%w: %s", ErrCampaignActive, name)
+	}
+	delete(d.campaigns, name)
+	return d.saveLocked()
+}
+
+// --- Campaign control ---
+
+// Start starts a campaign from its first number, or resumes a paused
+// one. Returns ErrInvalidState if it is running.
+func (d *Dialer) Start(name string) (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	switch c.state {
+	case StateRunning:
+		return Status{}, fmt.Errorf("%w: %s is running", ErrInvalidState, name)
+	case StatePaused:
+		slog.Info("[Dialer] Campaign resumed", "campaign", name)
+	default:
+		ctx, cancel := context.WithCancel(d.ctx)
+		c.run = &run{ctx: ctx, cancel: cancel}
+		slog.Info("[Dialer] Campaign started", "campaign", name, "numbers", len(c.Numbers), "agents", len(c.Agents))
+	}
+	c.state = StateRunning
+	return d.statusLocked(c), nil
+}
+
+// Pause stops a running campaign from placing new calls; calls in
+// progress continue.
+func (d *Dialer) Pause(name string) (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	if c.state != StateRunning {
+		return Status{}, fmt.Errorf("%w: %s is %s", ErrInvalidState, name, c.state)
+	}
+	c.state = StatePaused
+	slog.Info("[Dialer] Campaign paused", "campaign", name)
+	return d.statusLocked(c), nil
+}
+
+// Stop ends a running or paused campaign: calls dialing, detecting
+// machines or waiting for an agent are hung up, calls connected to
+// agents continue. Starting it again starts from its first number.
+func (d *Dialer) Stop(name string) (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	if !c.active() {
+		return Status{}, fmt.Errorf("%w: %s is %s", ErrInvalidState, name, c.state)
+	}
+	c.state = StateStopped
+	c.run.cancel()
+	slog.Info("[Dialer] Campaign stopped", "campaign", name, "called", c.run.next, "numbers", len(c.Numbers))
+	return d.statusLocked(c), nil
+}
+
+// --- Progress ---
+
+// Status returns the progress of a campaign.
+func (d *Dialer) Status(name string) (Status, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return Status{}, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	return d.statusLocked(c), nil
+}
+
+// Statuses returns the progress of every campaign, sorted by name.
+func (d *Dialer) Statuses() []Status {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	statuses := make([]Status, 0, len(d.campaigns))
+	for _, c := range d.campaigns {
+		statuses = append(statuses, d.statusLocked(c))
+	}
+	sort.Slice(statuses, func(i, j int) bool { return statuses[i].Name < statuses[j].Name })
+	return statuses
+}
+
+// Results returns how the calls of a campaign's last run went, in the
+// order they ended.
+func (d *Dialer) Results(name string) ([]Result, error) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	c, ok := d.campaigns[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrCampaignNotFound, name)
+	}
+	if c.run == nil {
+		return []Result{}, nil
+	}
+	return append([]Result{}, c.run.results...), nil
+}
+
+func (d *Dialer) statusLocked(c *campaign) Status {
+	ratio, throttled := c.currentRatio()
+	status := Status{
+		Name:       c.Name,
+		State:      c.state,
+		Numbers:    len(c.Numbers),
+		Dialing:    c.dialing,
+		Talking:    c.talking,
+		FreeAgents: d.freeAgentsLocked(c, time.Now()),
+		Ratio:      ratio,
+		Throttled:  throttled,
+	}
+	if c.run != nil {
+		status.Called = c.run.next
+		status.Stats = c.run.stats
+		status.AbandonRate = c.run.stats.AbandonRate()
+	}
+	return status
+}
+
+// active reports whether the campaign is running or paused.
+func (c *campaign) active() bool {
+	return c.state == StateRunning || c.state == StatePaused
+}
+
+// currentRatio returns the calls to dial per free agent: the campaign's
+// ratio, or 1 while its abandon rate is above the maximum.
+func (c *campaign) currentRatio() (float64, bool) {
+	ratio := c.ratio()
+	if c.run != nil && ratio > 1 && c.run.stats.AbandonRate() > c.maxAbandonRate() {
+		return 1, true
+	}
+	return ratio, false
+}
+
+// --- Pacing ---
+
+// pace places the calls the running campaigns are due.
+func (d *Dialer) pace(now time.Time) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	for _, c := range d.campaigns {
+		if c.state != StateRunning {
+			continue
+		}
+		r := c.run
+		if r.next >= len(c.Numbers) {
+			if c.dialing == 0 && c.talking == 0 {
+				c.state = StateCompleted
+				r.cancel()
+				slog.Info("[Dialer] Campaign completed", "campaign", c.Name,
+					"dialed", r.stats.Dialed,
+					"connected", r.stats.Connected,
+					"abandon_rate", r.stats.AbandonRate(),
+				)
+			}
+			continue
+		}
+
+		// No credit for time the campaign could not dial
+		if r.nextCall.Before(now.Add(-tickInterval)) {
+			r.nextCall = now
+		}
+		ratio, _ := c.currentRatio()
+		for r.next < len(c.Numbers) && !r.nextCall.After(now) &&
+			c.dialing < lines(d.freeAgentsLocked(c, now), ratio, c.MaxConcurrent, c.talking) {
+			number := c.Numbers[r.next]
+			trunk := r.next
+			r.next++
+			r.nextCall = r.nextCall.Add(c.interval())
+			r.stats.Dialed++
+			c.dialing++
+			go d.call(c, r, number, trunk)
+		}
+	}
+}
+
+// --- Agents ---
+
+// freeAgentsLocked returns how many of the campaign's agents may be
+// connected to a call (must hold lock).
+func (d *Dialer) freeAgentsLocked(c *campaign, now time.Time) int {
+	free := 0
+	for _, target := range c.Agents {
+		if a := d.agents[target]; (a == nil || (!a.busy && !now.Before(a.retryAt))) && d.availableLocked(target) {
+			free++
+		}
+	}
+	return free
+}
+
+// acquire takes the campaign's longest idle free agent. Without one, it
+// returns "" and a channel closed when an agent is released.
+func (d *Dialer) acquire(c *campaign) (string, <-chan struct{}) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	now := time.Now()
+	var best string
+	var bestAgent *agent
+	for _, target := range c.Agents {
+		a := d.agents[target]
+		if a == nil {
+			a = &agent{}
+			d.agents[target] = a
+		}
+		if a.busy || now.Before(a.retryAt) || !d.availableLocked(target) {
+			continue
+		}
+		if bestAgent == nil || a.idleSince.Before(bestAgent.idleSince) {
+			best, bestAgent = target, a
+		}
+	}
+	if bestAgent == nil {
+		return "", d.released
+	}
+	bestAgent.busy = true
+	return best, nil
+}
+
+// availableLocked reports whether an agent's state lets them take a call
+// (must hold lock).
+func (d *Dialer) availableLocked(target string) bool {
+	return d.states == nil || d.states.Available(target)
+}
+
+// connected marks an agent as on a call once bridged.
+func (d *Dialer) connected(target string) {
+	d.mu.Lock()
+	states := d.states
+	d.mu.Unlock()
+	if states != nil {
+		states.Connected(target)
+	}
+}
+
+// release makes an agent free again, after retryAfter if it failed to
+// answer.
+func (d *Dialer) release(target string, retryAfter time.Duration) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	if d.states != nil {
+		d.states.Ended(target) // Wrap-up, if the agent was on the call
+	}
+
+	a := d.agents[target]
+	a.busy = false
+	a.idleSince = time.Now()
+	a.retryAt = a.idleSince.Add(retryAfter)
+	close(d.released)
+	d.released = make(chan struct{})
+}
+
+// saveLocked writes the campaigns to the config file, if any (must hold lock).
+func (d *Dialer) saveLocked() error {
+	if d.path == "" {
+		return nil
+	}
+	campaigns := make([]Campaign, 0, len(d.campaigns))
+	for _, c := range d.campaigns {
+		campaigns = append(campaigns, c.Campaign)
+	}
+	sort.Slice(campaigns, func(i, j int) bool { return campaigns[i].Name < campaigns[j].Name })
+	return saveConfig(d.path, campaigns)
+}
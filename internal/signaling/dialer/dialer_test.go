@@ -0,0 +1,102 @@
+package dialer
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// ringingCalls is a CallService whose dials ring until canceled
+type ringingCalls struct {
+	b2bua.CallService
+	dialed chan string
+}
+
+func (f *ringingCalls) Dial(ctx context.Context, target string, timeout time.Duration, opts ...b2bua.LegOption) (b2bua.Leg, error) {
+	f.dialed <- target
+	<-ctx.Done()
+	return nil, ctx.Err()
+}
+
+func TestPace(t *testing.T) {
+	calls := &ringingCalls{dialed: make(chan string, 10)}
+	d := New(calls, nil)
+	err := d.PutCampaign(Campaign{
+		Name:           "renewals",
+		Numbers:        []string{"15550001", "15550002", "15550003", "15550004", "15550005"},
+		Trunks:         []string{"sip:${number}@carrier-a.example.net", "sip:${number}@carrier-b.example.net"},
+		Agents:         []string{"user/1001", "user/1002"},
+		CallsPerSecond: 100,
+		Ratio:          1.5,
+	})
+	if err != nil {
+		t.Fatalf("PutCampaign: %v", err)
+	}
+	if _, err := d.Start("renewals"); err != nil {
+		t.Fatalf("Start: %v", err)
+	}
+
+	// 1.5 lines for each of the 2 free agents, though the pace allows more
+	now := time.Now()
+	d.pace(now)
+	d.pace(now.Add(tickInterval / 2))
+	var dialed []string
+	for range 3 {
+		dialed = append(dialed, <-calls.dialed)
+	}
+	slices.Sort(dialed)
+	want := []string{"sip:15550001@carrier-a.example.net", "sip:15550002@carrier-b.example.net", "sip:15550003@carrier-a.example.net"}
+	if !slices.Equal(dialed, want) {
+		t.Errorf("dialed %v, want %v", dialed, want)
+	}
+	if status, _ := d.Status("renewals"); status.Dialing != 3 || status.Called != 3 {
+		t.Fatalf("status after pacing = %+v, want 3 calls dialing", status)
+	}
+
+	if err := d.PutCampaign(Campaign{Name: "renewals", Numbers: []string{"1"}, Trunks: []string{"sip:${number}@x"}, Agents: []string{"1001"}}); !errors.Is(err, ErrCampaignActive) {
+		t.Errorf("PutCampaign while running = %v, want ErrCampaignActive", err)
+	}
+
+	// Abandoning 1 in 10 people is above the 3% default: one line per agent
+	d.count(func() {
+		stats := &d.campaigns["renewals"].run.stats
+		stats.Humans, stats.Abandoned = 10, 1
+	})
+	status, _ := d.Status("renewals")
+	if !status.Throttled || status.Ratio != 1 {
+		t.Errorf("status with a 10%% abandon rate = %+v, want throttled to ratio 1", status)
+	}
+
+	if _, err := d.Pause("renewals"); err != nil {
+		t.Fatalf("Pause: %v", err)
+	}
+	d.pace(now.Add(time.Second))
+	if status, _ := d.Status("renewals"); status.Called != 3 {
+		t.Errorf("called %d numbers while paused, want 3", status.Called)
+	}
+
+	// Stopping hangs up the calls still ringing
+	if _, err := d.Stop("renewals"); err != nil {
+		t.Fatalf("Stop: %v", err)
+	}
+	deadline := time.Now().Add(time.Second)
+	for {
+		results, _ := d.Results("renewals")
+		if len(results) == 3 {
+			for _, r := range results {
+				if r.Outcome != OutcomeCanceled {
+					t.Errorf("result %+v, want canceled", r)
+				}
+			}
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("%d results after Stop, want 3", len(results))
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
@@ -3,12 +3,18 @@ package dialog
 import (
 	"context"
 	"fmt"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/emiago/sipgo"
 	"github.com/emiago/sipgo/sip"
+	psdp "github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+	"github.com/sebas/switchboard/internal/signaling/topology"
 )
 
 // DialogDirection indicates whether we initiated or received the dialog
@@ -45,8 +51,23 @@ const (
 	HoldTypeRecvOnly
 	// HoldTypeInactive - a=inactive (both directions held)
 	HoldTypeInactive
+	// HoldTypeResume - a=sendrecv (taking the call off hold)
+	HoldTypeResume
 )
 
+// direction returns the SDP direction attribute of a hold type
+func (h HoldType) direction() string {
+	switch h {
+	case HoldTypeSendOnly:
+		return sdpmedia.SendOnly
+	case HoldTypeRecvOnly:
+		return sdpmedia.RecvOnly
+	case HoldTypeInactive:
+		return sdpmedia.Inactive
+	}
+	return sdpmedia.SendRecv
+}
+
 // ReINVITEOptions configures a re-INVITE request
 type ReINVITEOptions struct {
 	// SDP body (nil to keep existing SDP - for hold scenarios)
@@ -55,10 +76,30 @@ type ReINVITEOptions struct {
 	// Headers to add or replace (key is header name)
 	Headers map[string]string
 
-	// HoldType for call hold scenarios (modifies SDP direction attribute)
+	// HoldType for call hold scenarios (modifies SDP direction attribute
+	// of every stream not rejected; applied by SendReINVITE)
 	HoldType HoldType
 }
 
+// holdSDP returns an SDP offer with the direction of a hold type on every
+// stream not rejected, and its session version incremented (RFC 3264
+// Section 8)
+func holdSDP(body []byte, hold HoldType) ([]byte, error) {
+	sdp, err := sdpmedia.Parse(body)
+	if err != nil {
+		return nil, err
+	}
+	for i := range sdp.Streams {
+		if stream := &sdp.Streams[i]; stream.Port != 0 {
+			stream.Direction = hold.direction()
+			stream.Apply(sdp.SDP, sdp.SDP.MediaDescriptions[i])
+		}
+	}
+	sdp.SDP.Attributes = slices.DeleteFunc(sdp.SDP.Attributes, func(a psdp.Attribute) bool { return sdpmedia.IsDirection(a.Key) })
+	sdp.SDP.Origin.SessionVersion++
+	return sdp.SDP.Marshal()
+}
+
 // Dialog represents a SIP dialog with full lifecycle state tracking
 type Dialog struct {
 	mu sync.RWMutex
@@ -102,12 +143,18 @@ type Dialog struct {
 	// Re-INVITE state (prevent concurrent re-INVITEs)
 	reInviteInProgress atomic.Bool
 
+	// localSDP is the SDP we last sent once a re-INVITE changed it; until
+	// then it comes from the initial INVITE or 200 OK
+	localSDP []byte
+
 	// Lifecycle control
 	ctx    context.Context
 	cancel context.CancelFunc
 
 	// Termination info
 	TerminateReason TerminateReason
+	remoteReason    sipreason.Reason // Reason header of the BYE or CANCEL received
+	hangupReason    sipreason.Reason // Reason header of the BYE sent, if set
 }
 
 // NewDialog creates a new dialog from an incoming INVITE request
@@ -250,6 +297,33 @@ func (d *Dialog) SetMediaEndpoint(addr string, port int, codec string) {
 	d.Codec = codec
 }
 
+// SetLocalSDP records the SDP last sent to the remote party
+func (d *Dialog) SetLocalSDP(sdp []byte) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.localSDP = sdp
+}
+
+// LocalSDP returns the SDP last sent to the remote party: our INVITE's for
+// outbound dialogs, our 200 OK's for inbound ones, or the latest re-INVITE's
+func (d *Dialog) LocalSDP() []byte {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if d.localSDP != nil {
+		return d.localSDP
+	}
+	if d.Direction == DirectionOutbound {
+		if d.InviteRequest != nil {
+			return d.InviteRequest.Body()
+		}
+		return nil
+	}
+	if d.InviteResponse != nil {
+		return d.InviteResponse.Body()
+	}
+	return nil
+}
+
 // SetSessionID stores the transport session ID
 func (d *Dialog) SetSessionID(sessionID string) {
 	d.mu.Lock()
@@ -295,6 +369,33 @@ func (d *Dialog) Cancel() {
 	d.cancel()
 }
 
+// RemoteReason returns the Reason header (RFC 3326) of the BYE or CANCEL
+// that ended the dialog; zero if none was received
+func (d *Dialog) RemoteReason() sipreason.Reason {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.remoteReason
+}
+
+// SetHangupReason sets the Reason header of the BYE sent to end the
+// dialog, replacing the one of its terminate reason
+func (d *Dialog) SetHangupReason(reason sipreason.Reason) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.hangupReason = reason
+}
+
+// hangupReasonFor returns the Reason header of the BYE sent to end the
+// dialog for a terminate reason
+func (d *Dialog) hangupReasonFor(reason TerminateReason) sipreason.Reason {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	if !d.hangupReason.IsZero() {
+		return d.hangupReason
+	}
+	return reason.Reason()
+}
+
 // IsTerminated returns true if dialog is in terminal state
 func (d *Dialog) IsTerminated() bool {
 	d.mu.RLock()
@@ -309,6 +410,51 @@ func (d *Dialog) GetMediaEndpoint() (addr string, port int, codec string) {
 	return d.RemoteAddr, d.RemotePort, d.Codec
 }
 
+// flowDestination returns the address of the RFC 5626 flow in-dialog
+// requests must use, or "" when the remote Contact has no "ob" parameter.
+// Caller must hold the lock.
+func (d *Dialog) flowDestination() string {
+	var contact *sip.ContactHeader
+	var source string
+	if d.Direction == DirectionInbound {
+		contact, source = d.InviteRequest.Contact(), d.InviteRequest.Source()
+	} else if d.InviteResponse != nil {
+		contact, source = d.InviteResponse.Contact(), d.InviteResponse.Source()
+	}
+	if contact == nil {
+		return ""
+	}
+	if _, ok := contact.Address.UriParams.Get("ob"); !ok {
+		return ""
+	}
+	return source
+}
+
+// copyRouteSet adds the dialog route set to an in-dialog request. For
+// dialogs we initiated it is the Route set of our INVITE; for dialogs we
+// answered it is the INVITE's Record-Route, in order (RFC 3261 Section
+// 12.1.1). The inbound INVITE's own Route headers address us (e.g. via
+// Service-Route) and are not copied. Caller must hold the lock.
+func (d *Dialog) copyRouteSet(req *sip.Request) {
+	if d.Direction == DirectionOutbound {
+		sip.CopyHeaders("Route", d.InviteRequest, req)
+		return
+	}
+	for _, h := range d.InviteRequest.GetHeaders("Record-Route") {
+		if rr, ok := h.(*sip.RecordRouteHeader); ok {
+			req.AppendHeader(&sip.RouteHeader{Address: rr.Address})
+		}
+	}
+}
+
+// UsesFlow reports whether in-dialog requests must be sent over the
+// RFC 5626 flow the dialog was established on.
+func (d *Dialog) UsesFlow() bool {
+	d.mu.RLock()
+	defer d.mu.RUnlock()
+	return d.InviteRequest != nil && d.flowDestination() != ""
+}
+
 // BuildBYE constructs a BYE request for this dialog
 // Per RFC 3261 Section 12.2.1.1, in-dialog requests use the dialog's identifiers
 func (d *Dialog) BuildBYE(localContact sip.Uri) (*sip.Request, error) {
@@ -324,7 +470,7 @@ func (d *Dialog) BuildBYE(localContact sip.Uri) (*sip.Request, error) {
 	if d.Direction == DirectionOutbound {
 		// For outbound (UAC): use Remote Contact from 200 OK
 		if d.RemoteContactURI != "" {
-			if err := sip.ParseUri(d.RemoteContactURI, &recipient); err != nil {
+			if err := sipaddr.ParseURI(d.RemoteContactURI, &recipient); err != nil {
 				return nil, fmt.Errorf("cannot parse remote contact URI: %w", err)
 			}
 		} else if d.InviteResponse != nil && d.InviteResponse.Contact() != nil {
@@ -346,12 +492,12 @@ func (d *Dialog) BuildBYE(localContact sip.Uri) (*sip.Request, error) {
 	}
 
 	byeReq := sip.NewRequest(sip.BYE, recipient)
-
-	// Copy Route headers if present
-	if len(d.InviteRequest.GetHeaders("Route")) > 0 {
-		sip.CopyHeaders("Route", d.InviteRequest, byeReq)
+	if flow := d.flowDestination(); flow != "" {
+		byeReq.SetDestination(flow)
 	}
 
+	d.copyRouteSet(byeReq)
+
 	// Build From/To headers based on direction
 	if d.Direction == DirectionOutbound {
 		// For outbound (UAC): From/To same as our original INVITE
@@ -448,7 +594,7 @@ func (d *Dialog) BuildReINVITE(localContact sip.Uri, opts ReINVITEOptions) (*sip
 	if d.Direction == DirectionOutbound {
 		// For outbound (UAC): use Remote Contact from 200 OK
 		if d.RemoteContactURI != "" {
-			if err := sip.ParseUri(d.RemoteContactURI, &recipient); err != nil {
+			if err := sipaddr.ParseURI(d.RemoteContactURI, &recipient); err != nil {
 				d.reInviteInProgress.Store(false)
 				return nil, fmt.Errorf("cannot parse remote contact URI: %w", err)
 			}
@@ -471,12 +617,12 @@ func (d *Dialog) BuildReINVITE(localContact sip.Uri, opts ReINVITEOptions) (*sip
 	}
 
 	reInviteReq := sip.NewRequest(sip.INVITE, recipient)
-
-	// Copy Route headers if present
-	if len(d.InviteRequest.GetHeaders("Route")) > 0 {
-		sip.CopyHeaders("Route", d.InviteRequest, reInviteReq)
+	if flow := d.flowDestination(); flow != "" {
+		reInviteReq.SetDestination(flow)
 	}
 
+	d.copyRouteSet(reInviteReq)
+
 	// Build From/To headers based on direction
 	if d.Direction == DirectionOutbound {
 		// For outbound (UAC): From/To same as our original INVITE
@@ -553,7 +699,7 @@ func (d *Dialog) BuildReINVITE(localContact sip.Uri, opts ReINVITEOptions) (*sip
 
 	// Set SDP body if provided
 	if len(opts.SDP) > 0 {
-		reInviteReq.SetBody(opts.SDP)
+		reInviteReq.SetBody(topology.HiddenSDP(opts.SDP))
 		reInviteReq.AppendHeader(sip.NewHeader("Content-Type", "application/sdp"))
 	}
 
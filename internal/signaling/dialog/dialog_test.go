@@ -0,0 +1,44 @@
+package dialog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestHoldSDP(t *testing.T) {
+	local := strings.Join([]string{
+		"v=0",
+		"o=- 7 7 IN IP4 203.0.113.5",
+		"s=-",
+		"c=IN IP4 203.0.113.5",
+		"t=0 0",
+		"a=sendrecv",
+		"m=audio 20000 RTP/AVP 0",
+		"a=rtpmap:0 PCMU/8000",
+		"a=ptime:20",
+		"m=video 0 RTP/AVP 96",
+		"",
+	}, "\r\n")
+
+	for hold, want := range map[HoldType]string{
+		HoldTypeSendOnly: "a=sendonly",
+		HoldTypeInactive: "a=inactive",
+		HoldTypeResume:   "a=sendrecv",
+	} {
+		body, err := holdSDP([]byte(local), hold)
+		if err != nil {
+			t.Fatalf("holdSDP(%d): %v", hold, err)
+		}
+		got := string(body)
+		audio, video, _ := strings.Cut(got[strings.Index(got, "m=audio"):], "m=video")
+		if !strings.Contains(audio, want+"\r\n") || strings.Count(got, "a=") != 3 {
+			t.Errorf("holdSDP(%d) =\n%s\nwant the audio %s and no session direction", hold, got, want)
+		}
+		if video != " 0 RTP/AVP 96\r\n" {
+			t.Errorf("holdSDP(%d) changed the rejected video: %q", hold, video)
+		}
+		if !strings.Contains(got, "o=- 7 8 IN IP4") {
+			t.Errorf("holdSDP(%d) kept the session version:\n%s", hold, got)
+		}
+	}
+}
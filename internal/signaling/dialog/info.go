@@ -45,6 +45,7 @@ type Info struct {
 
 	// Termination (if applicable)
 	TerminateReason string `json:"terminate_reason,omitempty"`
+	RemoteReason    string `json:"remote_reason,omitempty"` // Reason header received
 }
 
 // ToInfo converts a Dialog to a JSON-serializable Info struct
@@ -66,6 +67,7 @@ func (d *Dialog) ToInfo() *Info {
 		RemotePort:      d.RemotePort,
 		Codec:           d.Codec,
 		TerminateReason: d.TerminateReason.String(),
+		RemoteReason:    d.remoteReason.String(),
 	}
 
 	// Construct dialog ID
@@ -1,6 +1,8 @@
 package dialog
 
 import (
+	"context"
+
 	"github.com/emiago/sipgo/sip"
 )
 
@@ -34,6 +36,13 @@ type DialogStore interface {
 	// HandleIncomingCANCEL processes a CANCEL request.
 	HandleIncomingCANCEL(req *sip.Request, tx sip.ServerTransaction) error
 
+	// HandleIncomingReINVITE processes an INVITE within an existing dialog.
+	HandleIncomingReINVITE(req *sip.Request, tx sip.ServerTransaction) error
+
+	// SendReINVITE sends a re-INVITE in a confirmed dialog and waits for
+	// its final response.
+	SendReINVITE(ctx context.Context, d *Dialog, localContact sip.Uri, opts ReINVITEOptions) (*ReINVITEResult, error)
+
 	// Terminate terminates a dialog and sends BYE if needed.
 	Terminate(callID string, reason TerminateReason) error
 
@@ -55,6 +64,9 @@ type DialogStore interface {
 	// SetOnTerminated sets the callback called when a dialog terminates.
 	SetOnTerminated(fn func(d *Dialog))
 
+	// SetReINVITEHandler sets the handler offered re-INVITEs that carry SDP.
+	SetReINVITEHandler(fn ReINVITEHandler)
+
 	// Close stops background cleanup goroutines and releases resources.
 	Close()
 }
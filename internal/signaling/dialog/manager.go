@@ -4,30 +4,44 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
 	"sync"
 	"time"
 
 	"github.com/emiago/sipgo"
 	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/advertise"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
 	"github.com/sebas/switchboard/internal/signaling/store"
+	"github.com/sebas/switchboard/internal/signaling/topology"
 )
 
 // Dialog TTL constants
 const (
 	// ActiveDialogTTL is the TTL for active dialogs (4 hours)
 	ActiveDialogTTL = 4 * time.Hour
-	// TerminatedDialogTTL is the TTL for terminated dialogs (for retransmissions, RFC 3261 Timer B)
+	// TerminatedDialogTTL is the default TTL for terminated dialogs (for retransmissions, RFC 3261 Timer B)
 	TerminatedDialogTTL = 32 * time.Second
+	// DefaultACKTimeout is how long an answered dialog waits for its ACK by default (64*T1)
+	DefaultACKTimeout = 32 * time.Second
 	// DialogCleanupInterval is how often the cleanup loop runs
 	DialogCleanupInterval = 10 * time.Second
+	// MaxGlareRetries is how often a re-INVITE answered with 491 Request
+	// Pending is retried before its 491 is returned to the caller
+	MaxGlareRetries = 3
 )
 
+// statusRequestPending is 491 Request Pending; sipgo has no constant for it
+const statusRequestPending = 491
+
 // Manager is the central registry for all active dialogs
 type Manager struct {
 	mu sync.RWMutex
 
-	// Dialog storage by Call-ID using TTLStore for automatic cleanup
-	dialogs *store.TTLStore[string, *Dialog]
+	// Dialog storage by Call-ID, sharded by Call-ID hash so that requests
+	// for different calls rarely wait on each other, with automatic cleanup
+	dialogs *store.ShardedTTLStore[string, *Dialog]
 
 	// SIP components for sending requests
 	sipClient *sipgo.Client
@@ -36,19 +50,38 @@ type Manager struct {
 	// Configuration
 	ackTimeout    time.Duration
 	cancelTimeout time.Duration
+	terminatedTTL time.Duration
+	advertise     *advertise.Selector // nil uses the DialogUA contact as is
 
 	// Callbacks
 	onTerminated func(d *Dialog)
+	onReINVITE   ReINVITEHandler
+
+	identity Identity // nil sends requests as built
+}
+
+// Identity sets the identity headers (User-Agent) of the requests the
+// manager sends. Implemented by identity.Headers.
+type Identity interface {
+	// SentRequest is called before a request is sent to peer (its host).
+	SentRequest(req *sip.Request, peer string)
 }
 
+// ReINVITEHandler negotiates the SDP offer of a re-INVITE received in d
+// on our behalf, e.g. with the other leg of a bridged call. It returns
+// the answer to send (Success with SDP) or the failure to reject the
+// re-INVITE with, or nil to leave the re-INVITE to the manager.
+type ReINVITEHandler func(d *Dialog, offer []byte) *ReINVITEResult
+
 // NewManager creates a new dialog manager
 func NewManager(client *sipgo.Client, dialogUA *sipgo.DialogUA) *Manager {
 	m := &Manager{
-		dialogs:       store.NewTTLStore[string, *Dialog](DialogCleanupInterval),
+		dialogs:       store.NewShardedTTLStore[string, *Dialog](DialogCleanupInterval),
 		sipClient:     client,
 		dialogUA:      dialogUA,
-		ackTimeout:    32 * time.Second, // RFC 3261 Timer B
+		ackTimeout:    DefaultACKTimeout,
 		cancelTimeout: 5 * time.Second,
+		terminatedTTL: TerminatedDialogTTL,
 	}
 
 	// Set eviction callback to log when dialogs are automatically removed
@@ -66,6 +99,13 @@ func (m *Manager) SetOnTerminated(fn func(d *Dialog)) {
 	m.onTerminated = fn
 }
 
+// SetReINVITEHandler sets the handler offered re-INVITEs that carry SDP
+func (m *Manager) SetReINVITEHandler(fn ReINVITEHandler) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onReINVITE = fn
+}
+
 // CreateFromInvite creates a new dialog from an incoming INVITE request
 func (m *Manager) CreateFromInvite(req *sip.Request, tx sip.ServerTransaction) (*Dialog, error) {
 	callID := ""
@@ -139,9 +179,41 @@ func (m *Manager) SendTrying(d *Dialog) error {
 	return nil
 }
 
+// SetAdvertise selects the Contact host of 200 OK responses by the
+// caller's network, for split-horizon deployments.
+func (m *Manager) SetAdvertise(selector *advertise.Selector) {
+	m.advertise = selector
+}
+
+// SetIdentity sets the identity headers of the BYEs, re-INVITEs and ACKs
+// the manager sends. Call it before the first dialog is created.
+func (m *Manager) SetIdentity(identity Identity) {
+	m.identity = identity
+}
+
+// stamp applies the identity headers to a request about to be sent
+func (m *Manager) stamp(req *sip.Request) {
+	if m.identity == nil {
+		return
+	}
+	peer := req.Recipient.Host
+	if route := req.Route(); route != nil {
+		peer = route.Address.Host
+	}
+	m.identity.SentRequest(req, peer)
+}
+
+// SetTimers sets how long answered dialogs wait for their ACK and how
+// long terminated dialogs are kept to absorb retransmissions (Timer B).
+// Call it before the first dialog is created.
+func (m *Manager) SetTimers(ackTimeout, terminatedTTL time.Duration) {
+	m.ackTimeout = ackTimeout
+	m.terminatedTTL = terminatedTTL
+}
+
 // SendProgress sends 183 Session Progress with SDP (early media)
 func (m *Manager) SendProgress(d *Dialog, sdpBody []byte) error {
-	progress := sip.NewResponseFromRequest(d.InviteRequest, sip.StatusCode(183), "Session Progress", sdpBody)
+	progress := sip.NewResponseFromRequest(d.InviteRequest, sip.StatusCode(183), "Session Progress", topology.HiddenSDP(sdpBody))
 	ct := sip.ContentTypeHeader("application/sdp")
 	progress.AppendHeader(&ct)
 
@@ -163,7 +235,7 @@ func (m *Manager) SendOK(d *Dialog, sdpBody []byte) error {
 	d.SetSession(session)
 
 	// Send 200 OK with SDP
-	if err := session.RespondSDP(sdpBody); err != nil {
+	if err := session.WriteResponse(m.okResponse(d.InviteRequest, sdpBody)); err != nil {
 		_ = session.Close()
 		return fmt.Errorf("failed to send 200 OK: %w", err)
 	}
@@ -183,6 +255,19 @@ func (m *Manager) SendOK(d *Dialog, sdpBody []byte) error {
 	return nil
 }
 
+// okResponse builds a 200 OK with SDP. With an advertise selector the
+// Contact host is the address advertised to the request's source;
+// otherwise sipgo adds the DialogUA contact.
+func (m *Manager) okResponse(req *sip.Request, sdpBody []byte) *sip.Response {
+	res := sip.NewSDPResponseFromRequest(req, topology.HiddenSDP(sdpBody))
+	if m.advertise != nil {
+		contact := m.dialogUA.ContactHDR.Clone()
+		contact.Address.Host = sipaddr.Host(m.advertise.Select(req.Source()))
+		res.AppendHeader(contact)
+	}
+	return res
+}
+
 // ConfirmWithACK confirms the dialog when ACK is received
 func (m *Manager) ConfirmWithACK(req *sip.Request, tx sip.ServerTransaction) error {
 	callID := ""
@@ -253,6 +338,10 @@ func (m *Manager) HandleIncomingBYE(req *sip.Request, tx sip.ServerTransaction)
 		}
 	}
 
+	d.mu.Lock()
+	d.remoteReason = sipreason.FromRequest(req)
+	d.mu.Unlock()
+
 	// Cancel the dialog context to stop media
 	d.Cancel()
 
@@ -294,12 +383,24 @@ func (m *Manager) HandleIncomingCANCEL(req *sip.Request, tx sip.ServerTransactio
 		slog.Error("[Dialog] Failed to respond to CANCEL", "call_id", callID, "error", err)
 	}
 
+	// The CANCEL crossed our 200 OK: the INVITE already has its final
+	// response, so the CANCEL has no effect (RFC 3261 Section 9.2). The
+	// caller ACKs the 200 and ends the call with BYE.
+	if state == StateWaitingACK {
+		slog.Info("[Dialog] CANCEL crossed 200 OK, awaiting ACK and BYE", "call_id", callID)
+		return nil
+	}
+
 	// Send 487 Request Terminated for the original INVITE
 	if d.Transaction != nil {
 		terminated := sip.NewResponseFromRequest(d.InviteRequest, 487, "Request Terminated", nil)
 		_ = d.Transaction.Respond(terminated)
 	}
 
+	d.mu.Lock()
+	d.remoteReason = sipreason.FromRequest(req)
+	d.mu.Unlock()
+
 	// Cancel context
 	d.Cancel()
 
@@ -310,6 +411,88 @@ func (m *Manager) HandleIncomingCANCEL(req *sip.Request, tx sip.ServerTransactio
 	return nil
 }
 
+// HandleIncomingReINVITE processes an INVITE within an existing dialog.
+// While our own re-INVITE is pending (or the initial INVITE is not yet
+// acknowledged) it is refused with 491 Request Pending, so both sides back
+// off and retry (RFC 3261 Section 14.2). An SDP offer is passed to the
+// ReINVITEHandler, if set, which answers it or rejects it. Otherwise the
+// session is kept as is: the answer repeats the SDP we last sent.
+func (m *Manager) HandleIncomingReINVITE(req *sip.Request, tx sip.ServerTransaction) error {
+	callID := ""
+	if req.CallID() != nil {
+		// Cast to string directly - .String() adds "Call-ID: " prefix
+		callID = string(*req.CallID())
+	}
+
+	d, exists := m.Get(callID)
+	if !exists || d.IsTerminated() {
+		resp := sip.NewResponseFromRequest(req, 481, "Call/Transaction Does Not Exist", nil)
+		_ = tx.Respond(resp)
+		return fmt.Errorf("dialog not found for re-INVITE: %s", callID)
+	}
+
+	// Our own re-INVITEs in this dialog are refused until this one is done
+	if state := d.GetState(); state != StateConfirmed || !d.reInviteInProgress.CompareAndSwap(false, true) {
+		slog.Info("[Dialog] Re-INVITE glare, responding 491",
+			"call_id", callID,
+			"state", state,
+			"local_reinvite", d.IsReINVITEInProgress())
+		resp := sip.NewResponseFromRequest(req, statusRequestPending, "Request Pending", nil)
+		if err := tx.Respond(resp); err != nil {
+			slog.Error("[Dialog] Failed to respond 491", "call_id", callID, "error", err)
+		}
+		return nil
+	}
+	defer d.CompleteReINVITE()
+
+	m.mu.RLock()
+	handler := m.onReINVITE
+	m.mu.RUnlock()
+	if offer := req.Body(); handler != nil && len(offer) > 0 {
+		// The handler may wait on another party; stop retransmissions
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusTrying, "Trying", nil))
+		if result := handler(d, offer); result != nil {
+			return m.answerReINVITE(d, req, tx, result)
+		}
+	}
+
+	sdp := d.LocalSDP()
+	if len(sdp) == 0 {
+		resp := sip.NewResponseFromRequest(req, sip.StatusNotAcceptableHere, "Not Acceptable Here", nil)
+		_ = tx.Respond(resp)
+		return fmt.Errorf("no local SDP to answer re-INVITE: %s", callID)
+	}
+	if err := tx.Respond(m.okResponse(req, sdp)); err != nil {
+		return fmt.Errorf("failed to answer re-INVITE: %w", err)
+	}
+
+	slog.Info("[Dialog] Re-INVITE answered with current session", "call_id", callID)
+	return nil
+}
+
+// answerReINVITE responds to a re-INVITE with the outcome of its
+// ReINVITEHandler
+func (m *Manager) answerReINVITE(d *Dialog, req *sip.Request, tx sip.ServerTransaction, result *ReINVITEResult) error {
+	if !result.Success || len(result.SDP) == 0 {
+		code, reason := result.StatusCode, result.Reason
+		if code < 300 {
+			code, reason = int(sip.StatusNotAcceptableHere), "Not Acceptable Here"
+		}
+		slog.Info("[Dialog] Re-INVITE rejected", "call_id", d.CallID, "status", code, "reason", reason)
+		if err := tx.Respond(sip.NewResponseFromRequest(req, sip.StatusCode(code), reason, nil)); err != nil {
+			return fmt.Errorf("failed to reject re-INVITE: %w", err)
+		}
+		return nil
+	}
+
+	if err := tx.Respond(m.okResponse(req, result.SDP)); err != nil {
+		return fmt.Errorf("failed to answer re-INVITE: %w", err)
+	}
+	d.SetLocalSDP(result.SDP)
+	slog.Info("[Dialog] Re-INVITE answered with renegotiated session", "call_id", d.CallID)
+	return nil
+}
+
 // Terminate terminates a dialog and sends BYE if needed
 func (m *Manager) Terminate(callID string, reason TerminateReason) error {
 	slog.Debug("[Dialog] Manager.Terminate called",
@@ -340,12 +523,13 @@ func (m *Manager) Terminate(callID string, reason TerminateReason) error {
 	}
 
 	// If confirmed, send BYE
-	if state == StateConfirmed && reason == ReasonLocalBYE {
+	if state == StateConfirmed && reason.sendsBYE() {
 		slog.Info("[Dialog] Manager.Terminate - sending BYE",
 			"call_id", callID,
 			"direction", d.Direction,
+			"reason", reason,
 		)
-		if err := m.sendBYE(d); err != nil {
+		if err := m.sendBYE(d, reason); err != nil {
 			slog.Error("[Dialog] Failed to send BYE", "call_id", callID, "error", err)
 		}
 	} else {
@@ -353,7 +537,7 @@ func (m *Manager) Terminate(callID string, reason TerminateReason) error {
 			"call_id", callID,
 			"state", state.String(),
 			"reason", reason,
-			"should_send", state == StateConfirmed && reason == ReasonLocalBYE,
+			"should_send", state == StateConfirmed && reason.sendsBYE(),
 		)
 	}
 
@@ -366,28 +550,24 @@ func (m *Manager) Terminate(callID string, reason TerminateReason) error {
 	return nil
 }
 
-// sendBYE sends a BYE request to terminate the dialog
-func (m *Manager) sendBYE(d *Dialog) error {
+// sendBYE sends a BYE request to terminate the dialog, with the Reason
+// header of the termination reason. BYEs are always built here: the sipgo
+// session's Bye cannot add headers, and addresses the Contact URI, which
+// is unreachable behind NAT for outbound (RFC 5626) flows.
+func (m *Manager) sendBYE(d *Dialog, reason TerminateReason) error {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel()
 
-	// For inbound dialogs with sipgo session, use the session's Bye method
-	if d.Session != nil && d.Direction == DirectionInbound {
-		if err := d.Session.Bye(ctx); err != nil {
-			return fmt.Errorf("failed to send BYE: %w", err)
-		}
-		slog.Info("[Dialog] BYE sent via session", "call_id", d.CallID)
-		return nil
-	}
-
-	// For outbound dialogs or dialogs without session, build and send BYE manually
 	localContact := sip.Uri{
 		Scheme: "sip",
 		User:   "switchboard",
 		Host:   "localhost", // Will be overwritten by Via
 	}
-	// Try to get a better local contact from the INVITE
-	if d.InviteRequest != nil {
+	// Try to get a better local contact from the INVITE, or from our 200 OK
+	// when we answered it
+	if d.Direction == DirectionInbound && d.InviteResponse != nil && d.InviteResponse.Contact() != nil {
+		localContact = d.InviteResponse.Contact().Address
+	} else if d.InviteRequest != nil {
 		if contact := d.InviteRequest.Contact(); contact != nil {
 			localContact = contact.Address
 		} else if from := d.InviteRequest.From(); from != nil {
@@ -400,6 +580,8 @@ func (m *Manager) sendBYE(d *Dialog) error {
 		return fmt.Errorf("failed to build BYE: %w", err)
 	}
 
+	d.hangupReasonFor(reason).Set(byeReq)
+	m.stamp(byeReq)
 	tx, err := m.sipClient.TransactionRequest(ctx, byeReq)
 	if err != nil {
 		return fmt.Errorf("failed to send BYE: %w", err)
@@ -447,9 +629,9 @@ func (m *Manager) terminate(d *Dialog, reason TerminateReason) {
 	}
 
 	// Update TTL to short duration for terminated dialogs (handles retransmissions per RFC 3261)
-	// TTLStore's cleanup loop will automatically remove it after TerminatedDialogTTL
-	m.dialogs.Set(d.CallID, d, TerminatedDialogTTL)
-	slog.Debug("[Dialog] Scheduled for cleanup", "call_id", d.CallID, "ttl", TerminatedDialogTTL)
+	// TTLStore's cleanup loop will automatically remove it after terminatedTTL
+	m.dialogs.Set(d.CallID, d, m.terminatedTTL)
+	slog.Debug("[Dialog] Scheduled for cleanup", "call_id", d.CallID, "ttl", m.terminatedTTL)
 }
 
 // watchACKTimeout watches for ACK timeout
@@ -521,8 +703,45 @@ type ReINVITEResult struct {
 }
 
 // SendReINVITE sends a re-INVITE request and waits for the response
-// Returns the result and handles ACK for 200 OK responses
+// Returns the result and handles ACK for 200 OK responses. A 491 Request
+// Pending means the remote party sent its own re-INVITE at the same time
+// (glare); the re-INVITE is then retried after the RFC 3261 Section 14.1
+// delay, up to MaxGlareRetries times.
 func (m *Manager) SendReINVITE(ctx context.Context, d *Dialog, localContact sip.Uri, opts ReINVITEOptions) (*ReINVITEResult, error) {
+	for attempt := 1; ; attempt++ {
+		result, err := m.sendReINVITE(ctx, d, localContact, opts)
+		if err != nil || result.StatusCode != statusRequestPending || attempt > MaxGlareRetries {
+			return result, err
+		}
+
+		delay := glareRetryDelay(d.Direction == DirectionOutbound)
+		slog.Info("[Dialog] Re-INVITE glare, retrying",
+			"call_id", d.CallID,
+			"attempt", attempt,
+			"delay", delay)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// glareRetryDelay returns how long to wait before retrying a re-INVITE
+// rejected with 491 (RFC 3261 Section 14.1): 2.1 to 4 seconds when we
+// generated the dialog's Call-ID, 0 to 2 seconds otherwise, in 10 ms units.
+// The split keeps both sides from retrying into each other again.
+func glareRetryDelay(ownsCallID bool) time.Duration {
+	if ownsCallID {
+		return 2100*time.Millisecond + time.Duration(rand.IntN(191))*10*time.Millisecond
+	}
+	return time.Duration(rand.IntN(201)) * 10 * time.Millisecond
+}
+
+// sendReINVITE sends one re-INVITE and waits for its final response
+func (m *Manager) sendReINVITE(ctx context.Context, d *Dialog, localContact sip.Uri, opts ReINVITEOptions) (*ReINVITEResult, error) {
 	if d.IsTerminated() {
 		return nil, fmt.Errorf("cannot send re-INVITE: dialog is terminated")
 	}
@@ -532,6 +751,19 @@ func (m *Manager) SendReINVITE(ctx context.Context, d *Dialog, localContact sip.
 		return nil, fmt.Errorf("cannot send re-INVITE: dialog not in confirmed state (state: %s)", state)
 	}
 
+	// A hold or resume offers the last SDP sent with its direction
+	if opts.HoldType != HoldTypeNone {
+		body := opts.SDP
+		if len(body) == 0 {
+			body = d.LocalSDP()
+		}
+		offer, err := holdSDP(body, opts.HoldType)
+		if err != nil {
+			return nil, fmt.Errorf("cannot build hold offer: %w", err)
+		}
+		opts.SDP = offer
+	}
+
 	// Build the re-INVITE request
 	reInviteReq, err := d.BuildReINVITE(localContact, opts)
 	if err != nil {
@@ -539,6 +771,7 @@ func (m *Manager) SendReINVITE(ctx context.Context, d *Dialog, localContact sip.
 	}
 
 	// Send the request using sipgo client
+	m.stamp(reInviteReq)
 	tx, err := m.sipClient.TransactionRequest(ctx, reInviteReq)
 	if err != nil {
 		d.CompleteReINVITE()
@@ -579,9 +812,13 @@ func (m *Manager) SendReINVITE(ctx context.Context, d *Dialog, localContact sip.
 					result.SDP = resp.Body()
 				}
 				result.Success = true
+				if len(opts.SDP) > 0 {
+					d.SetLocalSDP(opts.SDP)
+				}
 
 				// Send ACK for 200 OK (required for INVITE transactions)
 				ackReq := sip.NewAckRequest(reInviteReq, resp, nil)
+				m.stamp(ackReq)
 				if err := m.sipClient.WriteRequest(ackReq); err != nil {
 					slog.Warn("[Dialog] Failed to send ACK for re-INVITE 200 OK",
 						"call_id", d.CallID,
@@ -604,6 +841,7 @@ func (m *Manager) SendReINVITE(ctx context.Context, d *Dialog, localContact sip.
 
 				// Send ACK for error responses (also required per RFC 3261)
 				ackReq := sip.NewAckRequest(reInviteReq, resp, nil)
+				m.stamp(ackReq)
 				if err := m.sipClient.WriteRequest(ackReq); err != nil {
 					slog.Warn("[Dialog] Failed to send ACK for re-INVITE error response",
 						"call_id", d.CallID,
@@ -0,0 +1,53 @@
+package dialog
+
+// RemoteUser returns the user part of the endpoint at the far end of the
+// dialog: the From user of an inbound dialog (the caller), the To user of
+// an outbound one (the callee).
+func (d *Dialog) RemoteUser() string {
+	if d.InviteRequest == nil {
+		return ""
+	}
+	if d.Direction == DirectionOutbound {
+		if to := d.InviteRequest.To(); to != nil {
+			return to.Address.User
+		}
+		return ""
+	}
+	if from := d.InviteRequest.From(); from != nil {
+		return from.Address.User
+	}
+	return ""
+}
+
+// InCall reports whether user is the far end of a dialog that is not
+// terminated, placing or answering a call. Calls still ringing the user
+// have no dialog yet and do not count.
+func (m *Manager) InCall(user string) bool {
+	if user == "" {
+		return false
+	}
+	found := false
+	m.dialogs.ForEach(func(_ string, d *Dialog) bool {
+		if !d.IsTerminated() && d.RemoteUser() == user {
+			found = true
+			return false
+		}
+		return true
+	})
+	return found
+}
+
+// UsersInCall returns the users InCall reports true for, collected in a
+// single pass over the dialogs.
+func (m *Manager) UsersInCall() map[string]bool {
+	users := make(map[string]bool)
+	m.dialogs.ForEach(func(_ string, d *Dialog) bool {
+		if !d.IsTerminated() {
+			if user := d.RemoteUser(); user != "" {
+				users[user] = true
+			}
+		}
+		return true
+	})
+	return users
+}
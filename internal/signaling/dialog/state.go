@@ -1,6 +1,10 @@
 package dialog
 
-import "fmt"
+import (
+	"fmt"
+
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+)
 
 // CallState represents the lifecycle state of a SIP dialog
 type CallState int
@@ -79,10 +83,17 @@ const (
 	ReasonRemoteBYE
 	// ReasonCancel means CANCEL was received during early dialog
 	ReasonCancel
-	// ReasonTimeout means ACK or response timeout occurred
+	// ReasonTimeout means ACK or response timeout occurred, or the media
+	// stopped
 	ReasonTimeout
 	// ReasonError means an error occurred
 	ReasonError
+	// ReasonDrain means the node hung up while draining or shutting down
+	ReasonDrain
+	// ReasonAdminHangup means an operator hung up through the API
+	ReasonAdminHangup
+	// ReasonPeerHangup means the other leg of the bridged call hung up
+	ReasonPeerHangup
 )
 
 // String returns the string representation of the termination reason
@@ -98,7 +109,42 @@ func (r TerminateReason) String() string {
 		return "Timeout"
 	case ReasonError:
 		return "Error"
+	case ReasonDrain:
+		return "Drain"
+	case ReasonAdminHangup:
+		return "AdminHangup"
+	case ReasonPeerHangup:
+		return "PeerHangup"
 	default:
 		return fmt.Sprintf("Unknown(%d)", r)
 	}
 }
+
+// sendsBYE reports whether terminating an answered dialog for this reason
+// sends BYE
+func (r TerminateReason) sendsBYE() bool {
+	switch r {
+	case ReasonLocalBYE, ReasonTimeout, ReasonDrain, ReasonAdminHangup, ReasonPeerHangup:
+		return true
+	}
+	return false
+}
+
+// Reason returns the Reason header (RFC 3326) of the BYE sent for r
+func (r TerminateReason) Reason() sipreason.Reason {
+	switch r {
+	case ReasonTimeout:
+		return sipreason.Q850(sipreason.CauseRecoveryOnTimerExpiry)
+	case ReasonError:
+		return sipreason.Q850(sipreason.CauseTemporaryFailure)
+	case ReasonDrain:
+		return sipreason.SIP(503, "Service Unavailable")
+	case ReasonAdminHangup:
+		return sipreason.Reason{
+			Protocol: sipreason.ProtocolQ850,
+			Cause:    sipreason.CauseNormalClearing,
+			Text:     "Administrative hangup",
+		}
+	}
+	return sipreason.Q850(sipreason.CauseNormalClearing)
+}
@@ -53,7 +53,14 @@ func (r *ActionRegistry) Create(actionType string, rawConfig json.RawMessage) (A
 func DefaultRegistry() *ActionRegistry {
 	r := NewActionRegistry()
 	r.Register("play_audio", NewPlayAudioAction)
+	r.Register("play_tone", NewPlayToneAction)
+	r.Register("say", NewSayAction)
+	r.Register("music_on_hold", NewMusicOnHoldAction)
 	r.Register("dial", NewDialAction)
+	r.Register("pickup", NewPickupAction)
+	r.Register("first_available", NewFirstAvailableAction)
+	r.Register("gather", NewGatherAction)
 	r.Register("hangup", NewHangupAction)
+	r.Register("script", NewScriptAction)
 	return r
 }
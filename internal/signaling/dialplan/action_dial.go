@@ -14,6 +14,8 @@ const DefaultDialTimeout = 30 * time.Second
 type DialParams struct {
 	Target  string `json:"target"`  // "user/1001" or "sip:user@host:port"
 	Timeout int    `json:"timeout"` // Timeout in seconds (default: 30)
+
+	Headers map[string]string `json:"headers,omitempty"` // Extra INVITE headers
 }
 
 // DialAction initiates an outbound call and bridges on answer.
@@ -63,7 +65,7 @@ func (a *DialAction) Execute(ctx context.Context, session CallSession) error {
 	// - Wait for answer
 	// - Bridge media
 	// - Wait for BYE
-	if err := session.Dial(dialCtx, a.params.Target, timeout); err != nil {
+	if err := session.Dial(dialCtx, a.params.Target, timeout, a.params.Headers); err != nil {
 		return err
 	}
 
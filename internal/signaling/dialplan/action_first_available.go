@@ -0,0 +1,86 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// FirstAvailableParams defines parameters for first_available action.
+type FirstAvailableParams struct {
+	Members []string `json:"members"` // Extensions in order of preference ("1001" or "user/1001")
+	Timeout int      `json:"timeout"` // Ring timeout per member in seconds (default: 30)
+
+	Headers map[string]string `json:"headers,omitempty"` // Extra INVITE headers
+}
+
+// FirstAvailableAction dials the first member of a group who is not on a
+// call, judged from dialogs rather than registrations, so members already
+// talking or ringing are not rung again.
+type FirstAvailableAction struct {
+	params FirstAvailableParams
+}
+
+// NewFirstAvailableAction creates a first_available action from JSON config.
+func NewFirstAvailableAction(raw json.RawMessage) (Action, error) {
+	var params FirstAvailableParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse first_available params: %w", err)
+	}
+	if len(params.Members) == 0 {
+		return nil, fmt.Errorf("first_available: members required")
+	}
+	for i, member := range params.Members {
+		params.Members[i] = strings.TrimPrefix(member, "user/")
+		if params.Members[i] == "" {
+			return nil, fmt.Errorf("first_available: empty member")
+		}
+	}
+	if params.Timeout <= 0 {
+		params.Timeout = int(DefaultDialTimeout.Seconds())
+	}
+	return &FirstAvailableAction{params: params}, nil
+}
+
+// Type returns "first_available".
+func (a *FirstAvailableAction) Type() string {
+	return "first_available"
+}
+
+// Execute dials the available members in order until one answers, and
+// blocks until the call ends. When every member is on a call it fails
+// with 486 Busy Here; when none of those dialed answers, with the last
+// member's failure.
+func (a *FirstAvailableAction) Execute(ctx context.Context, session CallSession) error {
+	timeout := time.Duration(a.params.Timeout) * time.Second
+
+	var lastErr error
+	for _, member := range a.params.Members {
+		// Checked just before each dial: members may have hung up or
+		// taken calls while earlier ones rang
+		if !session.Available(member) {
+			continue
+		}
+
+		dialCtx, cancel := context.WithTimeout(ctx, timeout)
+		err := session.Dial(dialCtx, "user/"+member, timeout, a.params.Headers)
+		cancel()
+		var dialErr *DialError
+		if err == nil || !errors.As(err, &dialErr) || ctx.Err() != nil || session.IsTerminated() {
+			return err
+		}
+		lastErr = err
+	}
+	if lastErr != nil {
+		return lastErr
+	}
+	return &DialError{
+		Target:    "first_available",
+		SIPCode:   486,
+		SIPReason: "Busy Here",
+		Cause:     ErrNoneAvailable,
+	}
+}
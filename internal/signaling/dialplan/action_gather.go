@@ -0,0 +1,91 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// DefaultGatherVariable is the variable gather actions store digits in.
+const DefaultGatherVariable = "digits"
+
+// GatherParams defines parameters for gather action.
+type GatherParams struct {
+	Prompt            string `json:"prompt"`              // Audio file (default: dial tone)
+	MaxDigits         int    `json:"max_digits"`          // Digits to collect (default: until # or timeout)
+	Timeout           int    `json:"timeout"`             // Seconds to wait for the first digit after the prompt (default: 5)
+	InterDigitTimeout int    `json:"inter_digit_timeout"` // Seconds to wait for each further digit (default: timeout)
+	Pattern           string `json:"pattern"`             // Regular expression the digits must match in full
+	Attempts          int    `json:"attempts"`            // Prompts until the digits match (default: 1)
+	InvalidPrompt     string `json:"invalid_prompt"`      // Played after digits that do not match
+	Variable          string `json:"variable"`            // Variable to store the digits in (default: digits)
+	InvalidRoute      string `json:"invalid_route"`       // Route to continue with when no valid digits are gathered
+}
+
+// GatherAction collects DTMF digits from the caller into a variable later
+// actions can use.
+type GatherAction struct {
+	params  GatherParams
+	pattern *regexp.Regexp
+}
+
+// NewGatherAction creates a gather action from JSON config.
+func NewGatherAction(raw json.RawMessage) (Action, error) {
+	var params GatherParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("parse gather params: %w", err)
+		}
+	}
+	if params.MaxDigits < 0 || params.Timeout < 0 || params.InterDigitTimeout < 0 || params.Attempts < 0 {
+		return nil, fmt.Errorf("gather: limits must not be negative")
+	}
+	if params.Variable == "" {
+		params.Variable = DefaultGatherVariable
+	}
+
+	a := &GatherAction{params: params}
+	if params.Pattern != "" {
+		pattern, err := b2bua.DigitPattern(params.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("gather: pattern: %w", err)
+		}
+		a.pattern = pattern
+	}
+	return a, nil
+}
+
+// Type returns "gather".
+func (a *GatherAction) Type() string {
+	return "gather"
+}
+
+// Execute collects digits and stores them in the variable. If none match
+// the pattern, the call continues with the invalid route, or the action
+// fails with ErrNoValidDigits.
+func (a *GatherAction) Execute(ctx context.Context, session CallSession) error {
+	result, err := session.Gather(ctx, b2bua.GatherOptions{
+		Prompt:            a.params.Prompt,
+		MaxDigits:         a.params.MaxDigits,
+		Timeout:           time.Duration(a.params.Timeout) * time.Second,
+		InterDigitTimeout: time.Duration(a.params.InterDigitTimeout) * time.Second,
+		Pattern:           a.pattern,
+		Attempts:          a.params.Attempts,
+		InvalidPrompt:     a.params.InvalidPrompt,
+	})
+	if err != nil {
+		return err
+	}
+	if !result.Valid {
+		if a.params.InvalidRoute != "" {
+			return &RouteJump{RouteID: a.params.InvalidRoute}
+		}
+		return fmt.Errorf("%w: %d attempts", ErrNoValidDigits, result.Attempts)
+	}
+	session.SetVariable(a.params.Variable, result.Digits)
+	return nil
+}
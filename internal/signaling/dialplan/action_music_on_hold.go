@@ -0,0 +1,48 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// MusicOnHoldParams defines parameters for music_on_hold action.
+// Without a class, the queue's and then the tenant's assigned class is
+// used, falling back to the default class.
+type MusicOnHoldParams struct {
+	Class    string `json:"class"`
+	Tenant   string `json:"tenant"`
+	Queue    string `json:"queue"`
+	Duration int    `json:"duration"` // Duration in seconds (default: until hangup)
+}
+
+// MusicOnHoldAction plays a music-on-hold class to the caller.
+type MusicOnHoldAction struct {
+	params MusicOnHoldParams
+}
+
+// NewMusicOnHoldAction creates a music_on_hold action from JSON config.
+func NewMusicOnHoldAction(raw json.RawMessage) (Action, error) {
+	var params MusicOnHoldParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("parse music_on_hold params: %w", err)
+		}
+	}
+	if params.Duration < 0 {
+		return nil, fmt.Errorf("music_on_hold: duration must not be negative")
+	}
+	return &MusicOnHoldAction{params: params}, nil
+}
+
+// Type returns "music_on_hold".
+func (a *MusicOnHoldAction) Type() string {
+	return "music_on_hold"
+}
+
+// Execute plays hold music and blocks until the duration elapses or the call ends.
+func (a *MusicOnHoldAction) Execute(ctx context.Context, session CallSession) error {
+	duration := time.Duration(a.params.Duration) * time.Second
+	return session.MusicOnHold(ctx, a.params.Class, a.params.Tenant, a.params.Queue, duration)
+}
@@ -0,0 +1,54 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/features"
+)
+
+// pickupBusyDuration is how long a caller with nothing to pick up hears
+// busy tone.
+const pickupBusyDuration = 3 * time.Second
+
+// PickupParams defines parameters for pickup action.
+type PickupParams struct {
+	Extension string `json:"extension"` // Extension whose ringing call to answer; empty: the caller's pickup group
+}
+
+// PickupAction answers a call ringing another extension (directed
+// pickup) or the caller's pickup group (group pickup).
+type PickupAction struct {
+	params PickupParams
+}
+
+// NewPickupAction creates a pickup action from JSON config.
+func NewPickupAction(raw json.RawMessage) (Action, error) {
+	var params PickupParams
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &params); err != nil {
+			return nil, fmt.Errorf("parse pickup params: %w", err)
+		}
+	}
+	return &PickupAction{params: params}, nil
+}
+
+// Type returns "pickup".
+func (a *PickupAction) Type() string {
+	return "pickup"
+}
+
+// Execute picks up the call and blocks until it ends. If no call rings,
+// or the caller is in no pickup group, the caller hears busy tone and the
+// route continues.
+func (a *PickupAction) Execute(ctx context.Context, session CallSession) error {
+	err := session.Pickup(ctx, a.params.Extension)
+	if errors.Is(err, b2bua.ErrNoRingingCall) || errors.Is(err, features.ErrNoPickupGroup) {
+		return session.PlayTone(ctx, "busy", "", pickupBusyDuration)
+	}
+	return err
+}
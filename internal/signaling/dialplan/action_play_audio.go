@@ -7,8 +7,10 @@ import (
 )
 
 // PlayAudioParams defines parameters for play_audio action.
+// Files are played after File as one gapless prompt.
 type PlayAudioParams struct {
-	File string `json:"file"`
+	File  string   `json:"file"`
+	Files []string `json:"files"`
 }
 
 // PlayAudioAction plays an audio file to the caller.
@@ -22,8 +24,8 @@ func NewPlayAudioAction(raw json.RawMessage) (Action, error) {
 	if err := json.Unmarshal(raw, &params); err != nil {
 		return nil, fmt.Errorf("parse play_audio params: %w", err)
 	}
-	if params.File == "" {
-		return nil, fmt.Errorf("play_audio: file required")
+	if params.File == "" && len(params.Files) == 0 {
+		return nil, fmt.Errorf("play_audio: file or files required")
 	}
 	return &PlayAudioAction{params: params}, nil
 }
@@ -35,5 +37,13 @@ func (a *PlayAudioAction) Type() string {
 
 // Execute plays the audio file and blocks until completion.
 func (a *PlayAudioAction) Execute(ctx context.Context, session CallSession) error {
-	return session.PlayAudio(ctx, a.params.File)
+	if len(a.params.Files) == 0 {
+		return session.PlayAudio(ctx, a.params.File)
+	}
+
+	files := a.params.Files
+	if a.params.File != "" {
+		files = append([]string{a.params.File}, files...)
+	}
+	return session.PlayPlaylist(ctx, files)
 }
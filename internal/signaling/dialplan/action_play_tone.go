@@ -0,0 +1,46 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// PlayToneParams defines parameters for play_tone action.
+type PlayToneParams struct {
+	Tone     string `json:"tone"`     // "ringback", "busy", "congestion", "dial", "dtmf:123" or custom spec
+	Country  string `json:"country"`  // Optional tone plan (e.g. "uk")
+	Duration int    `json:"duration"` // Duration in seconds (default: until hangup)
+}
+
+// PlayToneAction plays a generated tone to the caller.
+type PlayToneAction struct {
+	params PlayToneParams
+}
+
+// NewPlayToneAction creates a play_tone action from JSON config.
+func NewPlayToneAction(raw json.RawMessage) (Action, error) {
+	var params PlayToneParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse play_tone params: %w", err)
+	}
+	if params.Tone == "" {
+		return nil, fmt.Errorf("play_tone: tone required")
+	}
+	if params.Duration < 0 {
+		return nil, fmt.Errorf("play_tone: duration must not be negative")
+	}
+	return &PlayToneAction{params: params}, nil
+}
+
+// Type returns "play_tone".
+func (a *PlayToneAction) Type() string {
+	return "play_tone"
+}
+
+// Execute plays the tone and blocks until it ends.
+func (a *PlayToneAction) Execute(ctx context.Context, session CallSession) error {
+	duration := time.Duration(a.params.Duration) * time.Second
+	return session.PlayTone(ctx, a.params.Tone, a.params.Country, duration)
+}
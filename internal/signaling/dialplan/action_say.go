@@ -0,0 +1,40 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+)
+
+// SayParams defines parameters for say action.
+type SayParams struct {
+	Text  string `json:"text"`
+	Voice string `json:"voice"` // Optional provider-specific voice
+}
+
+// SayAction speaks text to the caller using text-to-speech.
+type SayAction struct {
+	params SayParams
+}
+
+// NewSayAction creates a say action from JSON config.
+func NewSayAction(raw json.RawMessage) (Action, error) {
+	var params SayParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse say params: %w", err)
+	}
+	if params.Text == "" {
+		return nil, fmt.Errorf("say: text required")
+	}
+	return &SayAction{params: params}, nil
+}
+
+// Type returns "say".
+func (a *SayAction) Type() string {
+	return "say"
+}
+
+// Execute synthesizes the text and blocks until playback completes.
+func (a *SayAction) Execute(ctx context.Context, session CallSession) error {
+	return session.Say(ctx, a.params.Text, a.params.Voice)
+}
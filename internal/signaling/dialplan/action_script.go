@@ -0,0 +1,229 @@
+package dialplan
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	lua "github.com/yuin/gopher-lua"
+)
+
+// ScriptParams defines parameters for the script action.
+type ScriptParams struct {
+	File   string `json:"file"`   // Lua script file, read for every call
+	Source string `json:"source"` // Inline Lua source, instead of file
+}
+
+// ScriptAction runs a Lua script that controls the call through the
+// global "call" table: it can inspect the caller and INVITE headers, play
+// prompts, collect digits, dial, and continue with another route.
+type ScriptAction struct {
+	params ScriptParams
+}
+
+// NewScriptAction creates a script action from JSON config.
+func NewScriptAction(raw json.RawMessage) (Action, error) {
+	var params ScriptParams
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse script params: %w", err)
+	}
+	if (params.File == "") == (params.Source == "") {
+		return nil, fmt.Errorf("script: exactly one of file or source required")
+	}
+	return &ScriptAction{params: params}, nil
+}
+
+// Type returns "script".
+func (a *ScriptAction) Type() string {
+	return "script"
+}
+
+// Execute runs the script to completion. Errors of call operations end
+// the script and are returned as they are; call.route() returns a RouteJump.
+func (a *ScriptAction) Execute(ctx context.Context, session CallSession) error {
+	L := newScriptState()
+	defer L.Close()
+	L.SetContext(ctx)
+
+	run := &scriptRun{ctx: ctx, session: session}
+	L.SetGlobal("call", run.callTable(L))
+
+	var err error
+	name := a.params.File
+	if name != "" {
+		err = L.DoFile(name)
+	} else {
+		name = "inline"
+		err = L.DoString(a.params.Source)
+	}
+
+	switch {
+	case run.failed != nil:
+		return run.failed
+	case run.jump != "":
+		return &RouteJump{RouteID: run.jump}
+	case err != nil:
+		return fmt.Errorf("script %s: %w", name, err)
+	}
+	return nil
+}
+
+// newScriptState creates a Lua state with only the libraries that cannot
+// reach the file system or the process: base (without file loading),
+// table, string and math.
+func newScriptState() *lua.LState {
+	L := lua.NewState(lua.Options{SkipOpenLibs: true})
+	for _, lib := range []struct {
+		name string
+		open lua.LGFunction
+	}{
+		{lua.BaseLibName, lua.OpenBase},
+		{lua.TabLibName, lua.OpenTable},
+		{lua.StringLibName, lua.OpenString},
+		{lua.MathLibName, lua.OpenMath},
+	} {
+		L.Push(L.NewFunction(lib.open))
+		L.Push(lua.LString(lib.name))
+		L.Call(1, 0)
+	}
+	for _, name := range []string{"dofile", "loadfile", "require"} {
+		L.SetGlobal(name, lua.LNil)
+	}
+	return L
+}
+
+// scriptRun is the state of one script execution.
+type scriptRun struct {
+	ctx     context.Context
+	session CallSession
+
+	failed error  // Error of a call operation that ended the script
+	jump   string // Route to continue with, set by call.route()
+}
+
+// callTable builds the "call" table scripts use.
+func (r *scriptRun) callTable(L *lua.LState) *lua.LTable {
+	t := L.NewTable()
+	L.SetField(t, "id", lua.LString(r.session.CallID()))
+	L.SetField(t, "destination", lua.LString(r.session.Destination()))
+	L.SetField(t, "caller_id", lua.LString(r.session.CallerID()))
+	L.SetField(t, "caller_name", lua.LString(r.session.CallerName()))
+	L.SetFuncs(t, map[string]lua.LGFunction{
+		"header":  r.header,
+		"play":    r.play,
+		"tone":    r.tone,
+		"say":     r.say,
+		"collect": r.collect,
+		"dial":    r.dial,
+		"hangup":  r.hangup,
+		"route":   r.route,
+		"log":     r.log,
+	})
+	return t
+}
+
+// fail ends the script with the error of a call operation.
+func (r *scriptRun) fail(L *lua.LState, err error) int {
+	r.failed = err
+	L.RaiseError("%s", err.Error())
+	return 0
+}
+
+// call.header(name) -> value or nil
+func (r *scriptRun) header(L *lua.LState) int {
+	value := r.session.Header(L.CheckString(1))
+	if value == "" {
+		L.Push(lua.LNil)
+	} else {
+		L.Push(lua.LString(value))
+	}
+	return 1
+}
+
+// call.play(file)
+func (r *scriptRun) play(L *lua.LState) int {
+	if err := r.session.PlayAudio(r.ctx, L.CheckString(1)); err != nil {
+		return r.fail(L, err)
+	}
+	return 0
+}
+
+// call.tone(tone, seconds)
+func (r *scriptRun) tone(L *lua.LState) int {
+	duration := time.Duration(L.OptInt(2, 0)) * time.Second
+	if err := r.session.PlayTone(r.ctx, L.CheckString(1), "", duration); err != nil {
+		return r.fail(L, err)
+	}
+	return 0
+}
+
+// call.say(text)
+func (r *scriptRun) say(L *lua.LState) int {
+	if err := r.session.Say(r.ctx, L.CheckString(1), ""); err != nil {
+		return r.fail(L, err)
+	}
+	return 0
+}
+
+// call.collect(prompt, max_digits, timeout_seconds) -> digits
+func (r *scriptRun) collect(L *lua.LState) int {
+	prompt := L.OptString(1, "")
+	maxDigits := L.OptInt(2, 1)
+	timeout := time.Duration(L.OptInt(3, 5)) * time.Second
+	digits, err := r.session.CollectDigits(r.ctx, prompt, maxDigits, timeout)
+	if err != nil {
+		return r.fail(L, err)
+	}
+	L.Push(lua.LString(digits))
+	return 1
+}
+
+// call.dial(target, timeout_seconds) -> true, or false, SIP code, reason.
+// A failed dial does not end the script, so it can try something else.
+func (r *scriptRun) dial(L *lua.LState) int {
+	target := L.CheckString(1)
+	timeout := time.Duration(L.OptInt(2, 30)) * time.Second
+	err := r.session.Dial(r.ctx, target, timeout, nil)
+	if err == nil {
+		L.Push(lua.LTrue)
+		return 1
+	}
+	if r.ctx.Err() != nil {
+		return r.fail(L, err)
+	}
+
+	L.Push(lua.LFalse)
+	var dialErr *DialError
+	if errors.As(err, &dialErr) && dialErr.SIPCode > 0 {
+		L.Push(lua.LNumber(dialErr.SIPCode))
+		L.Push(lua.LString(dialErr.SIPReason))
+	} else {
+		L.Push(lua.LNumber(0))
+		L.Push(lua.LString(err.Error()))
+	}
+	return 3
+}
+
+// call.hangup(reason)
+func (r *scriptRun) hangup(L *lua.LState) int {
+	if err := r.session.Hangup(L.OptString(1, "script")); err != nil {
+		return r.fail(L, err)
+	}
+	return 0
+}
+
+// call.route(route_id) ends the script and continues with that route.
+func (r *scriptRun) route(L *lua.LState) int {
+	r.jump = L.CheckString(1)
+	L.RaiseError("continuing with route %s", r.jump)
+	return 0
+}
+
+// call.log(message)
+func (r *scriptRun) log(L *lua.LState) int {
+	slog.Info("[Script] "+L.CheckString(1), "call_id", r.session.CallID())
+	return 0
+}
@@ -0,0 +1,123 @@
+package dialplan
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/features"
+)
+
+// Authorization collected before calls to a destination class are routed
+const (
+	CollectAccountCode = "account_code" // An account code, recorded in the CDR for billing
+	CollectPIN         = "pin"          // The caller's PIN from their user features
+)
+
+// Account code collection defaults
+const (
+	DefaultCodeMaxDigits = 12
+	DefaultCodeTimeout   = 5 * time.Second
+	DefaultCodeAttempts  = 3
+)
+
+// DestinationClass groups destination numbers, such as international or
+// premium-rate numbers, that calls are restricted by. The first class
+// matching a destination applies.
+type DestinationClass struct {
+	Name    string   `json:"name"`
+	Numbers []string `json:"numbers"`           // Exact numbers or "prefix*"
+	Collect string   `json:"collect,omitempty"` // "account_code", "pin" or empty
+
+	// Requires is the class of service callers need ("national" or
+	// "international"); empty permits every caller
+	Requires string `json:"requires,omitempty"`
+}
+
+// Validate checks the destination class.
+func (c *DestinationClass) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("name required")
+	}
+	if err := validateNumbers(c.Numbers); err != nil {
+		return fmt.Errorf("class %s: %w", c.Name, err)
+	}
+	switch c.Collect {
+	case "", CollectAccountCode, CollectPIN:
+	default:
+		return fmt.Errorf("class %s: invalid collect %q", c.Name, c.Collect)
+	}
+	if c.Requires != "" && !features.ValidClass(c.Requires) {
+		return fmt.Errorf("class %s: invalid requires %q", c.Name, c.Requires)
+	}
+	return nil
+}
+
+// Match reports whether destination belongs to the class.
+func (c *DestinationClass) Match(destination string) bool {
+	return matchNumber(c.Numbers, destination)
+}
+
+// AccountCodeConfig controls how account codes and PINs are collected.
+// Callers get Attempts tries; the call is hung up if none is valid.
+type AccountCodeConfig struct {
+	Codes     []string `json:"codes,omitempty"`      // Valid account codes; empty accepts any
+	Prompt    string   `json:"prompt,omitempty"`     // Audio file asking for the account code (default: dial tone)
+	PINPrompt string   `json:"pin_prompt,omitempty"` // Audio file asking for the PIN (default: dial tone)
+	MaxDigits int      `json:"max_digits,omitempty"` // Digits collected at most; "#" ends entry early (default: 12)
+	Timeout   int      `json:"timeout,omitempty"`    // Seconds to wait for each digit (default: 5)
+	Attempts  int      `json:"attempts,omitempty"`   // Default: 3
+}
+
+// Validate checks the configuration and fills in defaults.
+func (c *AccountCodeConfig) Validate() error {
+	for _, code := range c.Codes {
+		if code == "" || strings.Trim(code, "0123456789*") != "" {
+			return fmt.Errorf("invalid account code %q", code)
+		}
+	}
+	if c.MaxDigits <= 0 {
+		c.MaxDigits = DefaultCodeMaxDigits
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = int(DefaultCodeTimeout.Seconds())
+	}
+	if c.Attempts <= 0 {
+		c.Attempts = DefaultCodeAttempts
+	}
+	return nil
+}
+
+// validCode reports whether code is an accepted account code.
+func (c *AccountCodeConfig) validCode(code string) bool {
+	return len(c.Codes) == 0 || slices.Contains(c.Codes, code)
+}
+
+// validateNumbers checks a list of exact numbers and "prefix*" patterns.
+func validateNumbers(numbers []string) error {
+	if len(numbers) == 0 {
+		return fmt.Errorf("at least one number required")
+	}
+	for _, n := range numbers {
+		if strings.TrimSuffix(n, "*") == "" {
+			return fmt.Errorf("invalid number %q", n)
+		}
+	}
+	return nil
+}
+
+// matchNumber reports whether destination is one of numbers, which are
+// exact numbers or "prefix*" patterns.
+func matchNumber(numbers []string, destination string) bool {
+	for _, n := range numbers {
+		if prefix, ok := strings.CutSuffix(n, "*"); ok {
+			if strings.HasPrefix(destination, prefix) {
+				return true
+			}
+		} else if destination == n {
+			return true
+		}
+	}
+	return false
+}
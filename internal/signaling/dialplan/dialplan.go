@@ -12,6 +12,12 @@ import (
 type Config struct {
 	Version string  `json:"version"`
 	Routes  []Route `json:"routes"`
+
+	Emergency *EmergencyConfig `json:"emergency,omitempty"`
+
+	// Destination classes and the account codes or PINs they require
+	Classes      []DestinationClass `json:"classes,omitempty"`
+	AccountCodes *AccountCodeConfig `json:"account_codes,omitempty"`
 }
 
 // Dialplan provides thread-safe access to routing configuration.
@@ -20,6 +26,11 @@ type Dialplan struct {
 	routes atomic.Pointer[RouteList]
 	path   string
 	logger *slog.Logger
+
+	emergency atomic.Pointer[EmergencyConfig] // nil when no emergency class is configured
+
+	classes      atomic.Pointer[[]DestinationClass]
+	accountCodes atomic.Pointer[AccountCodeConfig] // Defaults when not configured
 }
 
 // New creates a new Dialplan from a JSON config file.
@@ -40,14 +51,65 @@ func New(path string, logger *slog.Logger) (*Dialplan, error) {
 	return d, nil
 }
 
-// Match finds the first matching route for the destination.
+// Match finds the first matching route for the destination, for a call
+// from trunk ("" if not from a trunk).
 // Thread-safe: uses atomic load for lock-free reads.
-func (d *Dialplan) Match(destination string) (*Route, bool) {
+func (d *Dialplan) Match(destination, trunk string) (*Route, bool) {
 	routes := d.routes.Load()
 	if routes == nil {
 		return nil, false
 	}
-	return routes.Match(destination)
+	return routes.Match(destination, trunk)
+}
+
+// Route returns the route with the given ID, enabled or not.
+func (d *Dialplan) Route(id string) (*Route, bool) {
+	routes := d.routes.Load()
+	if routes == nil {
+		return nil, false
+	}
+	for _, route := range *routes {
+		if route.ID == id {
+			return route, true
+		}
+	}
+	return nil, false
+}
+
+// Emergency returns the emergency class if destination is an emergency
+// number. Admission checks (call limits, authentication) must let these
+// calls through.
+func (d *Dialplan) Emergency(destination string) (*EmergencyConfig, bool) {
+	cfg := d.emergency.Load()
+	if cfg == nil || !cfg.Match(destination) {
+		return nil, false
+	}
+	return cfg, true
+}
+
+// IsEmergency reports whether destination is an emergency number.
+func (d *Dialplan) IsEmergency(destination string) bool {
+	_, ok := d.Emergency(destination)
+	return ok
+}
+
+// Class returns the destination class of destination, if any.
+func (d *Dialplan) Class(destination string) (*DestinationClass, bool) {
+	classes := d.classes.Load()
+	if classes == nil {
+		return nil, false
+	}
+	for i := range *classes {
+		if class := &(*classes)[i]; class.Match(destination) {
+			return class, true
+		}
+	}
+	return nil, false
+}
+
+// AccountCodes returns how account codes and PINs are collected.
+func (d *Dialplan) AccountCodes() *AccountCodeConfig {
+	return d.accountCodes.Load()
 }
 
 // Reload reloads configuration from the file.
@@ -73,11 +135,32 @@ func (d *Dialplan) Reload() error {
 		routes = append(routes, route)
 	}
 
+	if cfg.Emergency != nil {
+		if err := cfg.Emergency.Validate(); err != nil {
+			return fmt.Errorf("emergency: %w", err)
+		}
+	}
+
+	for i := range cfg.Classes {
+		if err := cfg.Classes[i].Validate(); err != nil {
+			return fmt.Errorf("classes: %w", err)
+		}
+	}
+	if cfg.AccountCodes == nil {
+		cfg.AccountCodes = &AccountCodeConfig{}
+	}
+	if err := cfg.AccountCodes.Validate(); err != nil {
+		return fmt.Errorf("account_codes: %w", err)
+	}
+
 	// Sort by priority
 	routes.Sort()
 
 	// Atomic swap
 	d.routes.Store(&routes)
+	d.emergency.Store(cfg.Emergency)
+	d.classes.Store(&cfg.Classes)
+	d.accountCodes.Store(cfg.AccountCodes)
 
 	d.logger.Info("[Dialplan] Loaded routes",
 		"path", d.path,
@@ -0,0 +1,60 @@
+package dialplan
+
+import (
+	"fmt"
+	"strings"
+)
+
+// DefaultEmergencyPriority is the Priority header value (RFC 3261
+// Section 20.26) sent on emergency calls unless the config overrides it.
+const DefaultEmergencyPriority = "emergency"
+
+// EmergencyConfig defines the emergency number class. Calls to these
+// numbers are matched before any route, dialed through the designated
+// trunks in order and raise an operator alert.
+type EmergencyConfig struct {
+	Numbers []string          `json:"numbers"`           // Exact numbers or "prefix*"
+	Trunks  []string          `json:"trunks"`            // Dial targets, tried in order
+	Headers map[string]string `json:"headers,omitempty"` // Location info headers (e.g. Geolocation)
+	Timeout int               `json:"timeout,omitempty"` // Per-trunk dial timeout in seconds (default: 30)
+}
+
+// Validate checks the emergency configuration.
+func (c *EmergencyConfig) Validate() error {
+	if err := validateNumbers(c.Numbers); err != nil {
+		return err
+	}
+	if len(c.Trunks) == 0 {
+		return fmt.Errorf("at least one trunk required")
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = int(DefaultDialTimeout.Seconds())
+	}
+	return nil
+}
+
+// Match reports whether destination is an emergency number.
+func (c *EmergencyConfig) Match(destination string) bool {
+	return matchNumber(c.Numbers, destination)
+}
+
+// dialHeaders returns the headers to send on emergency INVITEs.
+func (c *EmergencyConfig) dialHeaders() map[string]string {
+	headers := make(map[string]string, len(c.Headers)+1)
+	for name, value := range c.Headers {
+		headers[name] = value
+	}
+	if !hasHeader(headers, "Priority") {
+		headers["Priority"] = DefaultEmergencyPriority
+	}
+	return headers
+}
+
+func hasHeader(headers map[string]string, name string) bool {
+	for h := range headers {
+		if strings.EqualFold(h, name) {
+			return true
+		}
+	}
+	return false
+}
@@ -8,12 +8,20 @@ import (
 
 // Sentinel errors for error checking with errors.Is
 var (
-	ErrNoRouteMatch    = errors.New("no matching route")
-	ErrSessionCanceled = errors.New("session canceled")
-	ErrActionNotFound  = errors.New("unknown action type")
-	ErrUserNotFound    = errors.New("user not registered")
-	ErrDialTimeout     = errors.New("dial timeout")
-	ErrDialRejected    = errors.New("dial rejected")
+	ErrNoRouteMatch     = errors.New("no matching route")
+	ErrSessionCanceled  = errors.New("session canceled")
+	ErrActionNotFound   = errors.New("unknown action type")
+	ErrUserNotFound     = errors.New("user not registered")
+	ErrDialTimeout      = errors.New("dial timeout")
+	ErrDialRejected     = errors.New("dial rejected")
+	ErrTTSNotConfigured = errors.New("text-to-speech not configured")
+	ErrMOHNotConfigured = errors.New("music on hold not configured")
+	ErrNotAuthorized    = errors.New("no valid account code or PIN")
+	ErrRestricted       = errors.New("class of service does not permit destination")
+	ErrRouteNotFound    = errors.New("route not found")
+	ErrTooManyJumps     = errors.New("too many route jumps")
+	ErrNoValidDigits    = errors.New("no valid digits gathered")
+	ErrNoneAvailable    = errors.New("no group member available")
 )
 
 // ExecutionError captures partial execution state.
@@ -41,9 +49,16 @@ type DialError struct {
 	SIPCode   int // 0 if not a SIP error
 	SIPReason string
 	Cause     error
+
+	// Upstream is the code the target answered with when a response map
+	// replaced it with SIPCode (0 if not mapped)
+	Upstream int
 }
 
 func (e *DialError) Error() string {
+	if e.Upstream > 0 {
+		return fmt.Sprintf("dial %s: SIP %d %s (mapped from %d)", e.Target, e.SIPCode, e.SIPReason, e.Upstream)
+	}
 	if e.SIPCode > 0 {
 		return fmt.Sprintf("dial %s: SIP %d %s", e.Target, e.SIPCode, e.SIPReason)
 	}
@@ -53,3 +68,13 @@ func (e *DialError) Error() string {
 func (e *DialError) Unwrap() error {
 	return e.Cause
 }
+
+// RouteJump is returned by an action to continue the call with another
+// route instead of the rest of the current one.
+type RouteJump struct {
+	RouteID string
+}
+
+func (e *RouteJump) Error() string {
+	return "jump to route " + e.RouteID
+}
@@ -3,9 +3,17 @@ package dialplan
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/features"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+	"github.com/sebas/switchboard/internal/signaling/responsemap"
+	"github.com/sebas/switchboard/internal/signaling/trunks"
 )
 
 // Executor runs dialplan routes.
@@ -13,6 +21,27 @@ type Executor struct {
 	dialplan *Dialplan
 	registry *ActionRegistry
 	logger   *slog.Logger
+
+	// Operator alerts and CDR events (optional)
+	publisher events.Publisher
+	events    *events.Builder
+
+	// Caller PINs for PIN-protected destination classes (optional)
+	features *features.Store
+
+	// Per-route outcome counts (optional)
+	routeStats RouteRecorder
+
+	// Response maps of the trunks calls come from (optional)
+	trunkResponses map[string]responsemap.Map
+}
+
+// RouteRecorder counts the outcome of the calls routed by each route.
+// Implemented by routestats.Tracker.
+type RouteRecorder interface {
+	// RecordRoute counts a call routed by the route with the ID, ended
+	// with a final SIP code (2xx if it completed normally).
+	RecordRoute(routeID string, sipCode int)
 }
 
 // NewExecutor creates a new executor.
@@ -31,28 +60,337 @@ func NewExecutor(dialplan *Dialplan, registry *ActionRegistry, logger *slog.Logg
 	}
 }
 
+// SetPublisher sets where operator alerts such as emergency calls are published.
+func (e *Executor) SetPublisher(publisher events.Publisher, builder *events.Builder) {
+	e.publisher = publisher
+	e.events = builder
+}
+
+// SetFeatures sets the user features holding caller PINs and classes of
+// service. Without them no caller is restricted.
+func (e *Executor) SetFeatures(store *features.Store) {
+	e.features = store
+}
+
+// SetRouteStats sets where the outcome of each routed call is counted.
+func (e *Executor) SetRouteStats(recorder RouteRecorder) {
+	e.routeStats = recorder
+}
+
+// SetTrunkResponseMaps sets the response maps of trunks by name, which
+// map the failure codes of dials for the trunks' calls.
+func (e *Executor) SetTrunkResponseMaps(maps map[string]responsemap.Map) {
+	e.trunkResponses = maps
+}
+
+// IsEmergency reports whether destination is an emergency number.
+func (e *Executor) IsEmergency(destination string) bool {
+	return e.dialplan.IsEmergency(destination)
+}
+
+// Restricted returns the destination class of destination if caller's
+// class of service does not permit calling it. Emergency numbers are
+// never restricted.
+func (e *Executor) Restricted(caller, destination string) (*DestinationClass, bool) {
+	if e.dialplan.IsEmergency(destination) {
+		return nil, false
+	}
+	class, ok := e.dialplan.Class(destination)
+	if !ok || e.permitted(caller, class) {
+		return nil, false
+	}
+	return class, true
+}
+
+// permitted reports whether caller's class of service allows calls to class.
+func (e *Executor) permitted(caller string, class *DestinationClass) bool {
+	if class.Requires == "" || e.features == nil {
+		return true
+	}
+	settings := e.features.Get(caller)
+	return settings.Permits(class.Requires)
+}
+
 // Execute matches and runs the dialplan for an incoming call.
+// Emergency numbers are handled before any route is matched. Calls to a
+// destination class that requires an account code or PIN, or that the
+// caller's class of service does not permit without their PIN, collect it
+// before the route runs, and a CDR event is published when it ends. A
+// dial failure is mapped by the response map of the route, then of the
+// caller's trunk.
 // Returns ErrNoRouteMatch if no route matches.
 // Returns ExecutionError if an action fails (with partial execution info).
 func (e *Executor) Execute(ctx context.Context, session CallSession) error {
 	destination := session.Destination()
 
+	if emergency, ok := e.dialplan.Emergency(destination); ok {
+		return e.ExecuteEmergency(ctx, session, emergency)
+	}
+
 	// Find matching route
-	route, found := e.dialplan.Match(destination)
+	trunk := session.Header(middleware.AnnotationPrefix + trunks.Annotation)
+	route, found := e.dialplan.Match(destination, trunk)
 	if !found {
 		e.logger.Warn("[Dialplan] No route match",
 			"call_id", session.CallID(),
 			"destination", destination,
+			"trunk", trunk,
 		)
 		return ErrNoRouteMatch
 	}
 
-	return e.ExecuteRoute(ctx, session, route)
+	started := time.Now()
+	var className, accountCode string
+	var err error
+	if class, ok := e.dialplan.Class(destination); ok {
+		className = class.Name
+		accountCode, err = e.authorize(ctx, session, class)
+		if err != nil {
+			err = &ExecutionError{
+				RouteID:      route.ID,
+				TotalSteps:   len(route.Actions),
+				FailedAction: "authorize",
+				Cause:        err,
+			}
+		}
+	}
+	if err == nil {
+		err = e.ExecuteRoute(ctx, session, route)
+	}
+	e.mapResponse(ctx, session, route, trunk, err)
+
+	e.publishCallEnded(session, started, className, accountCode, err)
+	if e.routeStats != nil {
+		e.routeStats.RecordRoute(route.ID, routeSIPCode(err))
+	}
+	return err
+}
+
+// mapResponse applies the response map of the route, then of the trunk,
+// to a dial failure: it replaces the failure code the caller is told and
+// plays the rule's announcement to the caller.
+func (e *Executor) mapResponse(ctx context.Context, session CallSession, route *Route, trunk string, err error) {
+	var dialErr *DialError
+	if !errors.As(err, &dialErr) || dialErr.SIPCode < 400 {
+		return
+	}
+	rule, ok := route.ResponseMap.Lookup(dialErr.SIPCode)
+	if !ok {
+		rule, ok = e.trunkResponses[trunk].Lookup(dialErr.SIPCode)
+	}
+	if !ok {
+		return
+	}
+
+	code, reason := rule.Apply(dialErr.SIPCode, dialErr.SIPReason)
+	e.logger.Info("[Dialplan] Mapping dial failure",
+		"call_id", session.CallID(),
+		"route_id", route.ID,
+		"trunk", trunk,
+		"sip_code", dialErr.SIPCode,
+		"mapped_code", code,
+		"announcement", rule.Announcement,
+	)
+	if code != dialErr.SIPCode {
+		dialErr.Upstream = dialErr.SIPCode
+		dialErr.SIPCode, dialErr.SIPReason = code, reason
+	}
+
+	if rule.Announcement != "" && !session.IsTerminated() {
+		if err := session.PlayAudio(ctx, rule.Announcement); err != nil {
+			e.logger.Warn("[Dialplan] Failure announcement failed",
+				"call_id", session.CallID(),
+				"announcement", rule.Announcement,
+				"error", err,
+			)
+		}
+	}
+}
+
+// authorize checks the caller may call a destination class, collecting
+// the PIN that overrides their class of service and the account code or
+// PIN the class requires. Returns the account code to record. PINs are
+// checked against the caller's user features and never recorded.
+func (e *Executor) authorize(ctx context.Context, session CallSession, class *DestinationClass) (string, error) {
+	collect := class.Collect
+	if !e.permitted(session.CallerID(), class) {
+		e.logger.Info("[Dialplan] Class of service restricts destination, collecting override PIN",
+			"call_id", session.CallID(),
+			"caller_id", session.CallerID(),
+			"class", class.Name,
+			"requires", class.Requires,
+		)
+		if _, err := e.collect(ctx, session, class, CollectPIN); err != nil {
+			return "", fmt.Errorf("%w: %w", ErrRestricted, err)
+		}
+		if collect == CollectPIN {
+			return "", nil
+		}
+	}
+	if collect == "" {
+		return "", nil
+	}
+	return e.collect(ctx, session, class, collect)
+}
+
+// collect prompts for an account code or the caller's PIN until a valid
+// one is entered or the attempts run out, and returns the account code.
+func (e *Executor) collect(ctx context.Context, session CallSession, class *DestinationClass, what string) (string, error) {
+	cfg := e.dialplan.AccountCodes()
+	prompt := cfg.Prompt
+	if what == CollectPIN {
+		prompt = cfg.PINPrompt
+	}
+
+	for attempt := 1; attempt <= cfg.Attempts; attempt++ {
+		digits, err := session.CollectDigits(ctx, prompt, cfg.MaxDigits, time.Duration(cfg.Timeout)*time.Second)
+		if err != nil {
+			return "", err
+		}
+
+		switch {
+		case what == CollectPIN && e.checkPIN(session.CallerID(), digits):
+			e.logger.Info("[Dialplan] PIN accepted",
+				"call_id", session.CallID(),
+				"class", class.Name,
+			)
+			return "", nil
+		case what == CollectAccountCode && digits != "" && cfg.validCode(digits):
+			e.logger.Info("[Dialplan] Account code accepted",
+				"call_id", session.CallID(),
+				"class", class.Name,
+				"account_code", digits,
+			)
+			return digits, nil
+		}
+
+		e.logger.Warn("[Dialplan] Invalid account code or PIN",
+			"call_id", session.CallID(),
+			"caller_id", session.CallerID(),
+			"class", class.Name,
+			"attempt", attempt,
+		)
+		if attempt < cfg.Attempts {
+			_ = session.PlayTone(ctx, "congestion", "", time.Second)
+		}
+	}
+
+	return "", fmt.Errorf("%w: class %s", ErrNotAuthorized, class.Name)
+}
+
+// checkPIN reports whether pin is the caller's PIN.
+func (e *Executor) checkPIN(user, pin string) bool {
+	if e.features == nil || user == "" {
+		return false
+	}
+	settings := e.features.Get(user)
+	return settings.CheckPIN(pin)
+}
+
+// publishCallEnded publishes the CDR event for a routed call. Durations
+// are measured from the start of routing, after the caller was answered.
+func (e *Executor) publishCallEnded(session CallSession, started time.Time, class, accountCode string, err error) {
+	if e.publisher == nil || e.events == nil {
+		return
+	}
+
+	reason, disposition := callOutcome(err)
+	detail := ""
+	if err != nil {
+		detail = err.Error()
+	}
+	event := e.events.CallEnded(session.CallID(), session.CallID()).
+		Leg(events.LegA).
+		Reason(reason, detail).
+		Disposition(disposition).
+		Durations(0, 0, 0, time.Since(started)).
+		Account(class, accountCode)
+	if reason := session.HangupReason(); !reason.IsZero() {
+		event.HangupReason(reason.String(), reason.Q850Cause())
+	}
+	var dialErr *DialError
+	switch {
+	case errors.Is(err, ErrRestricted):
+		event.SIPResponse(403, "Forbidden")
+	case errors.As(err, &dialErr) && dialErr.SIPCode > 0:
+		event.SIPResponse(dialErr.SIPCode, dialErr.SIPReason)
+	}
+	e.publisher.PublishAsync(event.Build())
+}
+
+// callOutcome maps a route's result to a CDR end reason and disposition.
+func callOutcome(err error) (events.EndReason, string) {
+	var dialErr *DialError
+	switch {
+	case err == nil:
+		return events.EndReasonNormal, events.DispositionAnswered
+	case errors.Is(err, ErrSessionCanceled), errors.Is(err, context.Canceled):
+		return events.EndReasonCanceled, events.DispositionCanceled
+	case errors.Is(err, ErrNotAuthorized):
+		return events.EndReasonRejected, events.DispositionFailed
+	case errors.As(err, &dialErr) && isBusy(dialErr.SIPCode):
+		return events.EndReasonBusy, events.DispositionBusy
+	case errors.As(err, &dialErr) && isNoAnswer(dialErr):
+		return events.EndReasonNoAnswer, events.DispositionNoAnswer
+	case errors.As(err, &dialErr) && dialErr.SIPCode >= 400:
+		return events.EndReasonRejected, events.DispositionFailed
+	}
+	return events.EndReasonError, events.DispositionFailed
+}
+
+// routeSIPCode maps a route's result to the final SIP code counted in its
+// statistics: 200 when it completed, the dial failure's response, 487
+// when the caller hung up, 403 when refused and 500 otherwise.
+func routeSIPCode(err error) int {
+	var dialErr *DialError
+	switch {
+	case err == nil:
+		return 200
+	case errors.Is(err, ErrSessionCanceled), errors.Is(err, context.Canceled):
+		return 487
+	case errors.Is(err, ErrRestricted), errors.Is(err, ErrNotAuthorized):
+		return 403
+	case errors.As(err, &dialErr) && dialErr.SIPCode > 0:
+		return dialErr.SIPCode
+	case errors.As(err, &dialErr) && isNoAnswer(dialErr):
+		return 408
+	}
+	return 500
 }
 
+// maxRouteJumps bounds how often a call may jump between routes, so
+// routes that jump to each other cannot loop forever.
+const maxRouteJumps = 16
+
 // ExecuteRoute runs a specific route's actions.
 // Useful when you want to run a specific route without matching.
+// An action returning a RouteJump continues the call with that route.
 func (e *Executor) ExecuteRoute(ctx context.Context, session CallSession, route *Route) error {
+	for jumps := 0; ; jumps++ {
+		err := e.executeActions(ctx, session, route)
+		var jump *RouteJump
+		if !errors.As(err, &jump) {
+			return err
+		}
+
+		next, ok := e.dialplan.Route(jump.RouteID)
+		switch {
+		case !ok:
+			return fmt.Errorf("route %s: %w: %s", route.ID, ErrRouteNotFound, jump.RouteID)
+		case jumps >= maxRouteJumps:
+			return fmt.Errorf("route %s: %w", route.ID, ErrTooManyJumps)
+		}
+		e.logger.Info("[Dialplan] Jumping to route",
+			"from", route.ID,
+			"to", next.ID,
+			"call_id", session.CallID(),
+		)
+		route = next
+	}
+}
+
+// executeActions runs a route's actions in order.
+func (e *Executor) executeActions(ctx context.Context, session CallSession, route *Route) error {
 	e.logger.Info("[Dialplan] Executing route",
 		"route_id", route.ID,
 		"route_name", route.Name,
@@ -107,6 +445,10 @@ func (e *Executor) ExecuteRoute(ctx context.Context, session CallSession, route
 
 		// Execute the action
 		if err := action.Execute(ctx, session); err != nil {
+			var jump *RouteJump
+			if errors.As(err, &jump) {
+				return jump
+			}
 			e.logger.Warn("[Dialplan] Action failed",
 				"action", action.Type(),
 				"step", i+1,
@@ -137,11 +479,71 @@ func (e *Executor) ExecuteRoute(ctx context.Context, session CallSession, route
 	return nil
 }
 
+// ExecuteEmergency raises an operator alert and dials the designated
+// trunks in order, with the configured location headers, until one answers.
+func (e *Executor) ExecuteEmergency(ctx context.Context, session CallSession, cfg *EmergencyConfig) error {
+	e.logger.Error("[Emergency] Emergency call",
+		"call_id", session.CallID(),
+		"destination", session.Destination(),
+		"caller_id", session.CallerID(),
+		"trunks", cfg.Trunks,
+	)
+	if e.publisher != nil && e.events != nil {
+		e.publisher.PublishAsync(e.events.CallEmergency(session.CallID(), session.CallID(), session.Destination()).
+			From(events.Endpoint{User: session.CallerID()}).
+			Trunks(cfg.Trunks).
+			Build())
+	}
+
+	headers := cfg.dialHeaders()
+	var lastErr error
+	for i, trunk := range cfg.Trunks {
+		if ctx.Err() != nil || session.IsTerminated() {
+			return &ExecutionError{
+				RouteID:        "emergency",
+				CompletedSteps: i,
+				TotalSteps:     len(cfg.Trunks),
+				FailedAction:   "session_check",
+				Cause:          ErrSessionCanceled,
+			}
+		}
+
+		params, err := json.Marshal(DialParams{Target: trunk, Timeout: cfg.Timeout, Headers: headers})
+		if err != nil {
+			return fmt.Errorf("emergency dial params: %w", err)
+		}
+		action, err := e.registry.Create("dial", e.substituteVars(params, session))
+		if err != nil {
+			return fmt.Errorf("create emergency dial: %w", err)
+		}
+
+		lastErr = action.Execute(ctx, session)
+		if lastErr == nil {
+			return nil
+		}
+		e.logger.Error("[Emergency] Trunk failed",
+			"call_id", session.CallID(),
+			"trunk", trunk,
+			"attempt", i+1,
+			"error", lastErr,
+		)
+	}
+
+	return &ExecutionError{
+		RouteID:        "emergency",
+		CompletedSteps: len(cfg.Trunks) - 1,
+		TotalSteps:     len(cfg.Trunks),
+		FailedAction:   "dial",
+		Cause:          lastErr,
+	}
+}
+
 // substituteVars replaces ${variable} placeholders in the params JSON with session values.
 // Supported variables:
 //   - ${destination} - dialed number (To URI user part)
 //   - ${caller_id} - caller number (From URI user part)
 //   - ${call_id} - SIP Call-ID
+//   - ${name} - variables set by earlier actions, such as gather's digits
 func (e *Executor) substituteVars(params json.RawMessage, session CallSession) json.RawMessage {
 	if len(params) == 0 {
 		return params
@@ -156,6 +558,13 @@ func (e *Executor) substituteVars(params json.RawMessage, session CallSession) j
 		"${call_id}":     session.CallID(),
 	}
 
+	// Variables set by earlier actions (gather); built-ins win
+	for name, value := range session.Variables() {
+		if _, ok := vars["${"+name+"}"]; !ok {
+			vars["${"+name+"}"] = value
+		}
+	}
+
 	// Replace all variables
 	for placeholder, value := range vars {
 		s = strings.ReplaceAll(s, placeholder, value)
@@ -3,8 +3,11 @@ package dialplan
 import (
 	"encoding/json"
 	"fmt"
+	"slices"
 	"sort"
 	"strings"
+
+	"github.com/sebas/switchboard/internal/signaling/responsemap"
 )
 
 // Route represents a matched route with pattern and actions.
@@ -16,6 +19,14 @@ type Route struct {
 	Enabled  bool           `json:"enabled"`
 	Actions  []ActionConfig `json:"actions"`
 
+	// Trunks restricts the route to calls from these trunks, identified by
+	// their TLS client certificate; empty matches calls from anywhere
+	Trunks []string `json:"trunks,omitempty"`
+
+	// ResponseMap maps the failure codes of the route's dials to what the
+	// caller is told, before the map of the caller's trunk
+	ResponseMap responsemap.Map `json:"response_map,omitempty"`
+
 	// Compiled pattern info (not exported, built on validation)
 	isDefault bool
 	isPrefix  bool
@@ -40,6 +51,9 @@ func (r *Route) Validate() error {
 	if len(r.Actions) == 0 {
 		return fmt.Errorf("at least one action required")
 	}
+	if err := r.ResponseMap.Validate(); err != nil {
+		return err
+	}
 
 	// Compile pattern
 	if r.Pattern == "*" {
@@ -54,11 +68,15 @@ func (r *Route) Validate() error {
 	return nil
 }
 
-// Match checks if a destination matches this route's pattern.
-func (r *Route) Match(destination string) bool {
+// Match checks if a destination matches this route's pattern, for a
+// call from trunk ("" if not from a trunk).
+func (r *Route) Match(destination, trunk string) bool {
 	if !r.Enabled {
 		return false
 	}
+	if len(r.Trunks) > 0 && !slices.Contains(r.Trunks, trunk) {
+		return false
+	}
 
 	if r.isDefault {
 		return true
@@ -81,10 +99,11 @@ func (r RouteList) Sort() {
 	sort.Sort(r)
 }
 
-// Match finds the first matching route for a destination.
-func (r RouteList) Match(destination string) (*Route, bool) {
+// Match finds the first matching route for a destination, for a call
+// from trunk ("" if not from a trunk).
+func (r RouteList) Match(destination, trunk string) (*Route, bool) {
 	for _, route := range r {
-		if route.Match(destination) {
+		if route.Match(destination, trunk) {
 			return route, true
 		}
 	}
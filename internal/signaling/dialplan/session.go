@@ -1,17 +1,25 @@
 package dialplan
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"strings"
 	"sync"
 	"time"
 
+	"github.com/sebas/switchboard/internal/signaling/agents"
 	"github.com/sebas/switchboard/internal/signaling/b2bua"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/features"
 	"github.com/sebas/switchboard/internal/signaling/location"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+	"github.com/sebas/switchboard/internal/signaling/tts"
 )
 
 // CallSession provides actions access to call state and operations.
@@ -21,23 +29,77 @@ type CallSession interface {
 	CallID() string
 	Destination() string // Dialed number (To URI user part)
 	CallerID() string    // Caller number (From URI user part)
+	CallerName() string  // Caller display name (may be empty)
+
+	// Header returns a header of the caller's INVITE ("" if absent).
+	Header(name string) string
 
 	// Context returns the call's context. Canceled on BYE or timeout.
 	Context() context.Context
 
 	// Media operations
 	PlayAudio(ctx context.Context, file string) error
+	// PlayPlaylist plays several files back-to-back without gaps,
+	// completing once after the last one.
+	PlayPlaylist(ctx context.Context, files []string) error
 	StopAudio() error
 
+	// PlayTone plays a generated tone ("ringback", "busy", "dtmf:123", or a
+	// custom spec). A zero duration plays cadenced tones until hangup.
+	PlayTone(ctx context.Context, tone, country string, duration time.Duration) error
+
+	// Say synthesizes text with the configured TTS provider and plays it.
+	// voice may be empty for the default voice.
+	Say(ctx context.Context, text, voice string) error
+
+	// MusicOnHold plays a music-on-hold class, repeating it until duration
+	// elapses (zero: until hangup). An empty class is resolved from the
+	// queue and tenant assignments, falling back to the default class.
+	MusicOnHold(ctx context.Context, class, tenant, queue string, duration time.Duration) error
+
+	// CollectDigits plays prompt (dial tone if empty) and collects in-band
+	// DTMF digits until "#", maxDigits digits, or timeout passes without a
+	// digit once the prompt has played. Pressing a key interrupts the
+	// prompt. Returns the digits collected, without the "#".
+	CollectDigits(ctx context.Context, prompt string, maxDigits int, timeout time.Duration) (string, error)
+
+	// Gather collects DTMF digits as CollectDigits does, with an
+	// inter-digit timeout and a pattern the digits are collected again
+	// until they match (see b2bua.Gather).
+	Gather(ctx context.Context, opts b2bua.GatherOptions) (*b2bua.GatherResult, error)
+
+	// SetVariable sets a variable the parameters of the route's later
+	// actions can use as ${name}.
+	SetVariable(name, value string)
+
+	// Variables returns the variables set with SetVariable.
+	Variables() map[string]string
+
 	// B2BUA operations (for dial action)
 	// Dial initiates an outbound call to the target.
 	// target can be "user/extension" or "sip:user@host:port"
+	// headers are added to the outbound INVITE (may be nil)
 	// Returns error if dial fails (timeout, rejected, user not found)
-	Dial(ctx context.Context, target string, timeout time.Duration) error
+	Dial(ctx context.Context, target string, timeout time.Duration, headers map[string]string) error
+
+	// Pickup answers a call ringing extension, or with extension empty
+	// the caller's pickup group, and bridges it with the caller until
+	// either hangs up. Returns b2bua.ErrNoRingingCall if none rings.
+	Pickup(ctx context.Context, extension string) error
+
+	// Available reports whether user can take a call: they have no dialog
+	// in progress, placing or answering a call, no call rings them, and
+	// they are not an agent who is logged out or in wrap-up.
+	Available(user string) bool
 
 	// Termination
 	Hangup(reason string) error
 
+	// HangupReason returns the Reason header (RFC 3326) received when the
+	// call ended: with the caller's BYE or CANCEL, or the BYE of the party
+	// it was last bridged to. Zero if none.
+	HangupReason() sipreason.Reason
+
 	// State queries
 	IsTerminated() bool
 }
@@ -60,11 +122,17 @@ type sessionImpl struct {
 	dialogMgr   *dialog.Manager
 	locStore    location.LocationStore
 	callService b2bua.CallService
+	tts         *tts.Engine
+	moh         *moh.Registry
+	features    *features.Store
+	agents      *agents.Registry
 	logger      *slog.Logger
 
 	// Session state
 	sessionID  string
 	terminated bool
+	peerReason sipreason.Reason // Reason of the bridged party's BYE
+	variables  map[string]string
 }
 
 // SessionConfig contains dependencies for creating a CallSession.
@@ -74,6 +142,10 @@ type SessionConfig struct {
 	DialogMgr   *dialog.Manager
 	LocStore    location.LocationStore
 	CallService b2bua.CallService
+	TTS         *tts.Engine      // Optional; Say fails with ErrTTSNotConfigured when nil
+	MOH         *moh.Registry    // Optional; MusicOnHold fails with ErrMOHNotConfigured when nil
+	Features    *features.Store  // Optional; user features (DND) are not applied when nil
+	Agents      *agents.Registry // Optional; agent states are not respected when nil
 	Logger      *slog.Logger
 	Destination string
 	CallerID    string // From header user part (phone number/extension)
@@ -101,6 +173,10 @@ func NewSession(cfg SessionConfig) CallSession {
 		dialogMgr:   cfg.DialogMgr,
 		locStore:    cfg.LocStore,
 		callService: cfg.CallService,
+		tts:         cfg.TTS,
+		moh:         cfg.MOH,
+		features:    cfg.Features,
+		agents:      cfg.Agents,
 		logger:      cfg.Logger,
 		sessionID:   cfg.Dialog.GetSessionID(),
 	}
@@ -109,8 +185,19 @@ func NewSession(cfg SessionConfig) CallSession {
 func (s *sessionImpl) CallID() string           { return s.callID }
 func (s *sessionImpl) Destination() string      { return s.destination }
 func (s *sessionImpl) CallerID() string         { return s.callerID }
+func (s *sessionImpl) CallerName() string       { return s.callerName }
 func (s *sessionImpl) Context() context.Context { return s.ctx }
 
+func (s *sessionImpl) Header(name string) string {
+	if s.dialog.InviteRequest == nil {
+		return ""
+	}
+	if h := s.dialog.InviteRequest.GetHeader(name); h != nil {
+		return h.Value()
+	}
+	return ""
+}
+
 func (s *sessionImpl) IsTerminated() bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
@@ -119,6 +206,16 @@ func (s *sessionImpl) IsTerminated() bool {
 
 // PlayAudio plays an audio file and blocks until completion.
 func (s *sessionImpl) PlayAudio(ctx context.Context, file string) error {
+	return s.PlayPlaylist(ctx, []string{file})
+}
+
+// PlayPlaylist plays audio files as one gapless playback and blocks until completion.
+func (s *sessionImpl) PlayPlaylist(ctx context.Context, files []string) error {
+	if len(files) == 0 {
+		return fmt.Errorf("no audio files to play")
+	}
+	file := strings.Join(files, ",")
+
 	s.mu.Lock()
 	sessionID := s.sessionID
 	s.mu.Unlock()
@@ -135,7 +232,8 @@ func (s *sessionImpl) PlayAudio(ctx context.Context, file string) error {
 	// Create play request
 	playReq := mediaclient.PlayRequest{
 		SessionID: sessionID,
-		AudioFile: file,
+		AudioFile: files[0],
+		Playlist:  files[1:],
 		Loop:      false,
 	}
 
@@ -145,6 +243,40 @@ func (s *sessionImpl) PlayAudio(ctx context.Context, file string) error {
 		return fmt.Errorf("start playback: %w", err)
 	}
 
+	return s.waitPlayback(statusCh, file)
+}
+
+// PlayTone plays a generated tone and blocks until it ends.
+func (s *sessionImpl) PlayTone(ctx context.Context, tone, country string, duration time.Duration) error {
+	s.mu.Lock()
+	sessionID := s.sessionID
+	s.mu.Unlock()
+
+	if sessionID == "" {
+		return fmt.Errorf("no RTP session established")
+	}
+
+	s.logger.Debug("[Session] Playing tone",
+		"call_id", s.callID,
+		"tone", tone,
+		"duration", duration,
+	)
+
+	statusCh, err := s.transport.PlayTone(ctx, mediaclient.ToneRequest{
+		SessionID: sessionID,
+		Tone:      tone,
+		Country:   country,
+		Duration:  duration,
+	})
+	if err != nil {
+		return fmt.Errorf("start tone: %w", err)
+	}
+
+	return s.waitPlayback(statusCh, "tone:"+tone)
+}
+
+// waitPlayback blocks until a playback completes, fails or is stopped.
+func (s *sessionImpl) waitPlayback(statusCh <-chan mediaclient.PlayStatus, file string) error {
 	// Wait for completion or cancellation
 	for status := range statusCh {
 		switch status.State {
@@ -173,6 +305,148 @@ func (s *sessionImpl) PlayAudio(ctx context.Context, file string) error {
 	return nil
 }
 
+// CollectDigits implements CallSession.CollectDigits.
+func (s *sessionImpl) CollectDigits(ctx context.Context, prompt string, maxDigits int, timeout time.Duration) (string, error) {
+	result, err := s.Gather(ctx, b2bua.GatherOptions{Prompt: prompt, MaxDigits: maxDigits, Timeout: timeout})
+	if err != nil {
+		return "", err
+	}
+	return result.Digits, nil
+}
+
+// Gather implements CallSession.Gather.
+func (s *sessionImpl) Gather(ctx context.Context, opts b2bua.GatherOptions) (*b2bua.GatherResult, error) {
+	s.mu.Lock()
+	sessionID := s.sessionID
+	s.mu.Unlock()
+
+	return b2bua.Gather(ctx, s.transport, sessionID, opts)
+}
+
+// SetVariable implements CallSession.SetVariable.
+func (s *sessionImpl) SetVariable(name, value string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.variables == nil {
+		s.variables = make(map[string]string)
+	}
+	s.variables[name] = value
+}
+
+// Variables implements CallSession.Variables.
+func (s *sessionImpl) Variables() map[string]string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return maps.Clone(s.variables)
+}
+
+// Say synthesizes text and plays it through the streaming injection API.
+// Blocks until playback completes.
+func (s *sessionImpl) Say(ctx context.Context, text, voice string) error {
+	s.mu.Lock()
+	sessionID := s.sessionID
+	s.mu.Unlock()
+
+	if sessionID == "" {
+		return fmt.Errorf("no RTP session established")
+	}
+	if s.tts == nil {
+		return ErrTTSNotConfigured
+	}
+
+	audio, err := s.tts.Synthesize(ctx, text, voice)
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("[Session] Saying text",
+		"call_id", s.callID,
+		"chars", len(text),
+		"duration", audio.Duration(),
+	)
+
+	injector, err := s.transport.InjectAudio(ctx, mediaclient.InjectRequest{
+		SessionID:  sessionID,
+		Encoding:   mediaclient.AudioEncodingPCM16,
+		SampleRate: audio.SampleRate,
+	})
+	if err != nil {
+		return fmt.Errorf("start injection: %w", err)
+	}
+
+	// Send in 1s chunks; the RTP manager paces playout to real time
+	chunk := audio.SampleRate * 2
+	for off := 0; off < len(audio.PCM); off += chunk {
+		end := min(off+chunk, len(audio.PCM))
+		if err := injector.Write(audio.PCM[off:end]); err != nil {
+			break // Close reports the cause
+		}
+	}
+
+	if _, err := injector.Close(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		s.logger.Warn("[Session] Say failed",
+			"call_id", s.callID,
+			"error", err,
+		)
+		return err
+	}
+
+	return nil
+}
+
+// MusicOnHold plays a music-on-hold class in passes until duration elapses
+// or the call ends. Shuffled classes are reshuffled on each pass.
+func (s *sessionImpl) MusicOnHold(ctx context.Context, class, tenant, queue string, duration time.Duration) error {
+	if s.moh == nil {
+		return ErrMOHNotConfigured
+	}
+
+	var c *moh.Class
+	var err error
+	if class != "" {
+		c, err = s.moh.Class(class)
+	} else {
+		c, err = s.moh.Resolve(tenant, queue)
+	}
+	if err != nil {
+		return err
+	}
+
+	s.logger.Debug("[Session] Music on hold",
+		"call_id", s.callID,
+		"class", c.Name,
+		"duration", duration,
+	)
+
+	holdCtx := ctx
+	if duration > 0 {
+		var cancel context.CancelFunc
+		holdCtx, cancel = context.WithTimeout(ctx, duration)
+		defer cancel()
+	}
+
+	for {
+		files, err := c.Playlist()
+		if err != nil {
+			return err
+		}
+		err = s.PlayPlaylist(holdCtx, files)
+		if holdCtx.Err() != nil {
+			_ = s.StopAudio()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return nil // Duration elapsed
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
 // StopAudio stops any ongoing audio playback.
 func (s *sessionImpl) StopAudio() error {
 	s.mu.Lock()
@@ -188,13 +462,39 @@ func (s *sessionImpl) StopAudio() error {
 
 // Dial initiates an outbound call and bridges on answer.
 // Uses the B2BUA CallService for full dial and bridge functionality.
-func (s *sessionImpl) Dial(ctx context.Context, target string, timeout time.Duration) error {
+func (s *sessionImpl) Dial(ctx context.Context, target string, timeout time.Duration, headers map[string]string) error {
 	s.logger.Info("[Session] Dial action",
 		"call_id", s.callID,
 		"target", target,
 		"timeout", timeout,
 	)
 
+	// Apply the called user's Do Not Disturb and forwarding settings
+	if s.features != nil && !strings.HasPrefix(target, "sip:") {
+		return s.dialUser(ctx, target, timeout, headers)
+	}
+	return s.dial(ctx, target, timeout, headers)
+}
+
+// dial dials the target and bridges it with the caller.
+func (s *sessionImpl) dial(ctx context.Context, target string, timeout time.Duration, headers map[string]string) error {
+	return s.bridge(target, headers, func(aLeg b2bua.Leg, opts ...b2bua.LegOption) (*b2bua.BridgeInfo, error) {
+		return s.callService.DialAndBridge(ctx, aLeg, target, timeout, opts...)
+	})
+}
+
+// dialFork rings several targets at once and bridges the first to answer
+// (and accept the call, for targets with Confirm) with the caller.
+func (s *sessionImpl) dialFork(ctx context.Context, targets []b2bua.ForkTarget, timeout time.Duration, headers map[string]string) error {
+	return s.bridge(targets[0].Target, headers, func(aLeg b2bua.Leg, opts ...b2bua.LegOption) (*b2bua.BridgeInfo, error) {
+		return s.callService.DialParallelAndBridge(ctx, aLeg, targets, timeout, opts...)
+	})
+}
+
+// bridge adopts the caller's leg and runs dialAndBridge with the caller ID
+// and headers of the outbound INVITE. Dial failures are returned as
+// *DialError for target.
+func (s *sessionImpl) bridge(target string, headers map[string]string, dialAndBridge func(aLeg b2bua.Leg, opts ...b2bua.LegOption) (*b2bua.BridgeInfo, error)) error {
 	// Check if CallService is configured
 	if s.callService == nil {
 		// Fall back to basic resolution for diagnostics
@@ -217,6 +517,63 @@ func (s *sessionImpl) Dial(ctx context.Context, target string, timeout time.Dura
 		}
 	}
 
+	aLeg, err := s.adoptLeg()
+	if err != nil {
+		return &DialError{
+			Target: target,
+			Cause:  err,
+		}
+	}
+
+	s.logger.Info("[Session] A-leg adopted",
+		"call_id", s.callID,
+		"leg_id", aLeg.ID(),
+	)
+
+	// Use DialAndBridge (or its fork variant) for the complete B2BUA flow
+	// This will: lookup target, create B-leg, wait for answer, bridge media, wait for termination
+	// Pass CallerID from the inbound call to set the From header on the outbound INVITE
+	callerName := s.callerName
+	if callerName == "" {
+		callerName = s.callerID // Fallback to callerID if no display name
+	}
+	bridgeInfo, err := dialAndBridge(aLeg,
+		b2bua.WithCallerID(s.callerID),
+		b2bua.WithCallerName(callerName),
+		b2bua.WithHeaders(headers),
+		b2bua.WithALegInvite(s.dialog.InviteRequest),
+	)
+	if err != nil {
+		// Extract SIP code from DialError if available
+		if dialErr, ok := err.(*b2bua.DialError); ok {
+			return &DialError{
+				Target:    target,
+				SIPCode:   dialErr.SIPCode,
+				SIPReason: dialErr.SIPReason,
+				Cause:     dialErr.Cause,
+			}
+		}
+		return &DialError{
+			Target: target,
+			Cause:  err,
+		}
+	}
+
+	s.logger.Info("[Session] Bridge terminated",
+		"call_id", s.callID,
+		"bridge_id", bridgeInfo.ID,
+		"duration", bridgeInfo.Duration(),
+		"hangup_reason", bridgeInfo.HangupReason.String(),
+	)
+	s.mu.Lock()
+	s.peerReason = bridgeInfo.HangupReason
+	s.mu.Unlock()
+
+	return nil
+}
+
+// adoptLeg adopts the caller's dialog as the inbound leg of a bridge.
+func (s *sessionImpl) adoptLeg() (b2bua.Leg, error) {
 	// Adopt the A-leg (inbound dialog) as a B2BUA leg
 	// The teardown handler is called when the A-leg is hung up (e.g., when B hangs up and bridge terminates)
 	// It sends BYE to the caller via the dialog manager
@@ -243,7 +600,11 @@ func (s *sessionImpl) Dial(ctx context.Context, target string, timeout time.Dura
 					"call_id", s.callID,
 					"dialog_state", dialogState.String(),
 				)
-				if err := s.dialogMgr.Terminate(s.callID, dialog.ReasonLocalBYE); err != nil {
+				reason := dialog.ReasonLocalBYE
+				if cause == b2bua.TerminationCauseBridgePeer {
+					reason = dialog.ReasonPeerHangup
+				}
+				if err := s.dialogMgr.Terminate(s.callID, reason); err != nil {
 					s.logger.Warn("[Session] A-leg teardown BYE failed",
 						"call_id", s.callID,
 						"error", err,
@@ -263,53 +624,210 @@ func (s *sessionImpl) Dial(ctx context.Context, target string, timeout time.Dura
 		}),
 	)
 	if err != nil {
+		return nil, fmt.Errorf("adopt inbound leg: %w", err)
+	}
+	return aLeg, nil
+}
+
+// dialUser dials a user target, applying the user's features: calls are
+// diverted without ringing the user for unconditional forwarding and Do
+// Not Disturb, and forwarded when the user is busy or does not answer.
+// Forwarded calls carry a Diversion header (RFC 5806) and are not
+// forwarded again. Follow-me destinations ring with the user's phones.
+func (s *sessionImpl) dialUser(ctx context.Context, target string, timeout time.Duration, headers map[string]string) error {
+	user := strings.TrimPrefix(target, "user/")
+	settings := s.features.Get(user)
+
+	if divert, reason := settings.Divert(); divert != "" {
+		return s.forward(user, divert, reason, timeout, headers)
+	}
+	if settings.DND {
+		s.logger.Info("[Session] User has Do Not Disturb on",
+			"call_id", s.callID,
+			"user", user,
+		)
 		return &DialError{
-			Target: target,
-			Cause:  fmt.Errorf("adopt inbound leg: %w", err),
+			Target:    target,
+			SIPCode:   486,
+			SIPReason: "Busy Here",
+			Cause:     fmt.Errorf("user %s has Do Not Disturb on", user),
 		}
 	}
 
-	s.logger.Info("[Session] A-leg adopted",
+	var err error
+	if len(settings.FollowMe) > 0 {
+		err = s.followMe(ctx, user, target, &settings, timeout, headers)
+	} else {
+		err = s.dial(ctx, target, settings.RingTimeout(timeout), headers)
+	}
+	var dialErr *DialError
+	if err == nil || s.ctx.Err() != nil || !errors.As(err, &dialErr) {
+		return err
+	}
+	switch {
+	case settings.ForwardBusy != "" && isBusy(dialErr.SIPCode):
+		return s.forward(user, settings.ForwardBusy, features.ReasonBusy, timeout, headers)
+	case settings.ForwardNoAnswer != "" && isNoAnswer(dialErr):
+		return s.forward(user, settings.ForwardNoAnswer, features.ReasonNoAnswer, timeout, headers)
+	}
+	return err
+}
+
+// followMe rings the user's phones together with their follow-me
+// destinations or, in sequential mode, one after another. Whether the call
+// is then forwarded depends on how ringing the user's phones failed.
+func (s *sessionImpl) followMe(ctx context.Context, user, target string, settings *features.Settings, timeout time.Duration, headers map[string]string) error {
+	ringTimeout := settings.RingTimeout(timeout)
+	diverted := map[string]string{"Diversion": s.diversion(user, features.ReasonFollowMe)}
+	targets := []b2bua.ForkTarget{{Target: target, Timeout: ringTimeout}}
+	for _, dest := range settings.FollowMe {
+		targets = append(targets, b2bua.ForkTarget{
+			Target:  dest.Target,
+			Timeout: time.Duration(dest.Timeout) * time.Second,
+			Confirm: dest.Confirm,
+			Headers: diverted,
+		})
+	}
+
+	s.logger.Info("[Session] Follow-me",
 		"call_id", s.callID,
-		"leg_id", aLeg.ID(),
+		"user", user,
+		"destinations", len(settings.FollowMe),
+		"mode", cmp.Or(settings.FollowMeMode, features.FollowMeSimultaneous),
 	)
 
-	// Use DialAndBridge for the complete B2BUA flow
-	// This will: lookup target, create B-leg, wait for answer, bridge media, wait for termination
-	// Pass CallerID from the inbound call to set the From header on the outbound INVITE
-	callerName := s.callerName
-	if callerName == "" {
-		callerName = s.callerID // Fallback to callerID if no display name
+	if settings.FollowMeMode != features.FollowMeSequential {
+		return s.dialFork(ctx, targets, ringTimeout, headers)
 	}
-	bridgeInfo, err := s.callService.DialAndBridge(ctx, aLeg, target, timeout,
-		b2bua.WithCallerID(s.callerID),
-		b2bua.WithCallerName(callerName),
+
+	userErr := s.dial(ctx, target, ringTimeout, headers)
+	if userErr == nil {
+		return nil
+	}
+	for _, next := range targets[1:] {
+		if s.ctx.Err() != nil {
+			break
+		}
+		ring := cmp.Or(next.Timeout, ringTimeout)
+		ctx, cancel := context.WithTimeout(s.ctx, ring)
+		err := s.dialFork(ctx, []b2bua.ForkTarget{next}, ring, headers)
+		cancel()
+		if err == nil {
+			return nil
+		}
+	}
+	return userErr
+}
+
+// forward dials a user's forwarding target with a full dial timeout of its
+// own, independent of the time spent ringing the user.
+func (s *sessionImpl) forward(user, target, reason string, timeout time.Duration, headers map[string]string) error {
+	s.logger.Info("[Session] Forwarding call",
+		"call_id", s.callID,
+		"user", user,
+		"target", target,
+		"reason", reason,
 	)
-	if err != nil {
-		// Extract SIP code from DialError if available
-		if dialErr, ok := err.(*b2bua.DialError); ok {
-			return &DialError{
-				Target:    target,
-				SIPCode:   dialErr.SIPCode,
-				SIPReason: dialErr.SIPReason,
-				Cause:     dialErr.Cause,
-			}
+
+	forwarded := make(map[string]string, len(headers)+1)
+	for name, value := range headers {
+		forwarded[name] = value
+	}
+	forwarded["Diversion"] = s.diversion(user, reason)
+
+	ctx, cancel := context.WithTimeout(s.ctx, timeout)
+	defer cancel()
+	return s.dial(ctx, target, timeout, forwarded)
+}
+
+// diversion builds the Diversion header value for a call forwarded by user.
+func (s *sessionImpl) diversion(user, reason string) string {
+	host := "localhost"
+	if req := s.dialog.InviteRequest; req != nil && req.Recipient.Host != "" {
+		host = req.Recipient.Host
+	}
+	return fmt.Sprintf("<sip:%s@%s>;reason=%s;counter=1", user, host, reason)
+}
+
+// isBusy reports whether a dial failure status means the user is busy.
+func isBusy(code int) bool {
+	return code == 486 || code == 600
+}
+
+// isNoAnswer reports whether a dial failure means the user did not answer:
+// the ring timeout expired or their phones are unavailable.
+func isNoAnswer(err *DialError) bool {
+	switch err.SIPCode {
+	case 408, 480, 487:
+		return true
+	}
+	return errors.Is(err.Cause, context.DeadlineExceeded)
+}
+
+// Pickup answers a call ringing extension, or one ringing the caller's
+// pickup group, and bridges it with the caller.
+func (s *sessionImpl) Pickup(ctx context.Context, extension string) error {
+	if s.callService == nil {
+		return fmt.Errorf("B2BUA CallService not configured")
+	}
+	callees := []string{extension}
+	if extension == "" {
+		if s.features == nil {
+			return features.ErrNoPickupGroup
 		}
-		return &DialError{
-			Target: target,
-			Cause:  err,
+		group, err := s.features.PickupGroup(s.callerID)
+		if err != nil {
+			return err
 		}
+		callees = group
 	}
 
+	aLeg, err := s.adoptLeg()
+	if err != nil {
+		return err
+	}
+	picked, err := s.callService.Pickup(aLeg, callees)
+	if err != nil {
+		s.logger.Info("[Session] Nothing to pick up",
+			"call_id", s.callID,
+			"callees", callees,
+		)
+		return err
+	}
+	s.logger.Info("[Session] Call picked up",
+		"call_id", s.callID,
+		"picked_call_id", picked.CallID,
+		"callee", picked.Callee,
+	)
+
+	bridgeInfo, err := picked.Wait()
+	if err != nil {
+		return err
+	}
 	s.logger.Info("[Session] Bridge terminated",
 		"call_id", s.callID,
 		"bridge_id", bridgeInfo.ID,
 		"duration", bridgeInfo.Duration(),
+		"hangup_reason", bridgeInfo.HangupReason.String(),
 	)
-
+	s.mu.Lock()
+	s.peerReason = bridgeInfo.HangupReason
+	s.mu.Unlock()
 	return nil
 }
 
+// Available reports whether user has no dialog in progress, no call
+// ringing them and, for agents, is available.
+func (s *sessionImpl) Available(user string) bool {
+	if s.dialogMgr != nil && s.dialogMgr.InCall(user) {
+		return false
+	}
+	if s.agents != nil && !s.agents.Available(user) {
+		return false
+	}
+	return s.callService == nil || len(s.callService.RingingLegs(user)) == 0
+}
+
 // resolveTarget resolves a dial target to a contact URI.
 // Supports:
 //   - "user/extension" -> lookup in location service
@@ -358,6 +876,16 @@ func (s *sessionImpl) lookupUser(extension string) (string, error) {
 	return "", ErrUserNotFound
 }
 
+// HangupReason returns the Reason header received when the call ended.
+func (s *sessionImpl) HangupReason() sipreason.Reason {
+	if reason := s.dialog.RemoteReason(); !reason.IsZero() {
+		return reason
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.peerReason
+}
+
 // Hangup terminates the call.
 func (s *sessionImpl) Hangup(reason string) error {
 	s.mu.Lock()
@@ -24,21 +24,36 @@ type Coordinator struct {
 
 	// Active drains by node ID
 	activeDrains map[string]*drainOperation
+
+	// Drain event subscribers, guarded separately so events can be
+	// published while a drain is updated
+	subsMu sync.Mutex
+	subs   map[*Subscription]struct{}
 }
 
 // drainOperation tracks a single node's drain progress
 type drainOperation struct {
 	status    DrainStatus
+	sessions  map[string]int // Session ID -> index in status.Sessions
 	cancel    context.CancelFunc
 	completed chan struct{}
 }
 
+// snapshot returns a copy of the status. Caller holds the coordinator lock.
+func (op *drainOperation) snapshot() DrainStatus {
+	status := op.status
+	status.Errors = append([]SessionError(nil), op.status.Errors...)
+	status.Sessions = append([]SessionProgress(nil), op.status.Sessions...)
+	return status
+}
+
 // NewCoordinator creates a new drain coordinator
 func NewCoordinator(pool *mediaclient.Pool, migrator *Migrator) *Coordinator {
 	return &Coordinator{
 		pool:         pool,
 		migrator:     migrator,
 		activeDrains: make(map[string]*drainOperation),
+		subs:         make(map[*Subscription]struct{}),
 	}
 }
 
@@ -75,12 +90,19 @@ func (c *Coordinator) StartDrain(ctx context.Context, req DrainRequest) (*DrainS
 			StartedAt:     time.Now(),
 			TotalSessions: len(sessions),
 		},
+		sessions:  make(map[string]int, len(sessions)),
 		cancel:    cancel,
 		completed: make(chan struct{}),
 	}
+	for i, sessionID := range sessions {
+		op.sessions[sessionID] = i
+		op.status.Sessions = append(op.status.Sessions, SessionProgress{SessionID: sessionID, State: SessionPending})
+	}
 
 	c.activeDrains[req.NodeID] = op
+	status := op.snapshot()
 	c.mu.Unlock()
+	c.publishOp(op, DrainEvent{Type: EventStarted})
 
 	slog.Info("[DrainCoordinator] Drain started",
 		"node_id", req.NodeID,
@@ -91,7 +113,21 @@ func (c *Coordinator) StartDrain(ctx context.Context, req DrainRequest) (*DrainS
 	// Start drain in background
 	go c.runDrain(drainCtx, op, req.NodeID, sessions)
 
-	return &op.status, nil
+	return &status, nil
+}
+
+// DrainRequested starts a graceful drain of a node that asked for one
+// itself, as from its pre-stop hook. Suitable for Pool.SetOnDrainRequest.
+func (c *Coordinator) DrainRequested(nodeID string) {
+	_, err := c.StartDrain(context.Background(), DrainRequest{
+		NodeID: nodeID,
+		Mode:   DrainModeGraceful,
+	})
+	if err != nil {
+		slog.Warn("[DrainCoordinator] Failed to start requested drain",
+			"node_id", nodeID,
+			"error", err)
+	}
 }
 
 // runDrain executes the drain process
@@ -121,9 +157,6 @@ func (c *Coordinator) runDrain(ctx context.Context, op *drainOperation, nodeID s
 	sem := semaphore.NewWeighted(MaxConcurrentMigrations)
 	g, gCtx := errgroup.WithContext(ctx)
 
-	var migratedCount, failedCount int
-	var countMu sync.Mutex
-
 	for _, sessionID := range sessions {
 		sessionID := sessionID // capture for goroutine
 
@@ -133,6 +166,9 @@ func (c *Coordinator) runDrain(ctx context.Context, op *drainOperation, nodeID s
 				slog.Warn("[DrainCoordinator] Semaphore acquire failed",
 					"session_id", sessionID,
 					"error", err)
+				// Not attempted; left to be retried
+				c.setSession(op, sessionID, SessionFailed, err)
+				c.publishOp(op, DrainEvent{Type: EventSessionFailed, SessionID: sessionID, Error: err.Error()})
 				return err
 			}
 			defer sem.Release(1)
@@ -142,36 +178,10 @@ func (c *Coordinator) runDrain(ctx context.Context, op *drainOperation, nodeID s
 				"target_node", targetNodeID)
 
 			// Attempt migration
+			c.setSession(op, sessionID, SessionMigrating, nil)
+			c.publishOp(op, DrainEvent{Type: EventSessionMigrating, SessionID: sessionID, TargetNode: targetNodeID})
 			err := c.migrator.MigrateSession(gCtx, sessionID, targetNodeID)
-
-			countMu.Lock()
-			if err != nil {
-				// Check if this is a B-leg that was skipped (will be migrated with A-leg)
-				if err == ErrSkipBLeg {
-					slog.Debug("[DrainCoordinator] B-leg session skipped (migrated with A-leg)",
-						"session_id", sessionID)
-					// Don't count as failed - it will be migrated with its A-leg
-				} else {
-					failedCount++
-					op.status.FailedCount = failedCount
-					op.status.Errors = append(op.status.Errors, SessionError{
-						SessionID: sessionID,
-						Error:     err.Error(),
-						Timestamp: time.Now(),
-					})
-					slog.Warn("[DrainCoordinator] Session migration failed",
-						"session_id", sessionID,
-						"target_node", targetNodeID,
-						"error", err)
-				}
-			} else {
-				migratedCount++
-				op.status.MigratedCount = migratedCount
-				slog.Info("[DrainCoordinator] Session migrated successfully",
-					"session_id", sessionID,
-					"target_node", targetNodeID)
-			}
-			countMu.Unlock()
+			c.migrationDone(op, sessionID, targetNodeID, err)
 
 			// In graceful mode, continue even if one fails
 			// In aggressive mode, we could return the error to stop
@@ -194,15 +204,136 @@ func (c *Coordinator) runDrain(ctx context.Context, op *drainOperation, nodeID s
 	if len(remaining) == 0 {
 		c.completeDrain(nodeID, op)
 	} else {
+		c.mu.RLock()
+		migrated, failed := op.status.MigratedCount, op.status.FailedCount
+		canceled := op.status.State != mediaclient.StateDraining
+		c.mu.RUnlock()
+		if canceled {
+			return
+		}
 		slog.Warn("[DrainCoordinator] Drain incomplete, sessions remaining",
 			"node_id", nodeID,
 			"remaining", len(remaining),
-			"migrated", migratedCount,
-			"failed", failedCount)
-		// Keep node in draining state - operator can check status
+			"migrated", migrated,
+			"failed", failed)
+		// Keep node in draining state - operator can check status or
+		// retry the failed sessions
+		c.publishOp(op, DrainEvent{Type: EventIncomplete})
 	}
 }
 
+// migrationDone records the outcome of a session's migration
+func (c *Coordinator) migrationDone(op *drainOperation, sessionID, targetNodeID string, err error) {
+	switch {
+	case err == ErrSkipBLeg:
+		// Don't count as failed - it is migrated with its A-leg
+		slog.Debug("[DrainCoordinator] B-leg session skipped (migrated with A-leg)",
+			"session_id", sessionID)
+		c.setSession(op, sessionID, SessionSkipped, nil)
+		c.publishOp(op, DrainEvent{Type: EventSessionMigrated, SessionID: sessionID, TargetNode: targetNodeID})
+	case err != nil:
+		slog.Warn("[DrainCoordinator] Session migration failed",
+			"session_id", sessionID,
+			"target_node", targetNodeID,
+			"error", err)
+		c.setSession(op, sessionID, SessionFailed, err)
+		c.publishOp(op, DrainEvent{Type: EventSessionFailed, SessionID: sessionID, TargetNode: targetNodeID, Error: err.Error()})
+	default:
+		slog.Info("[DrainCoordinator] Session migrated successfully",
+			"session_id", sessionID,
+			"target_node", targetNodeID)
+		c.setSession(op, sessionID, SessionMigrated, nil)
+		c.publishOp(op, DrainEvent{Type: EventSessionMigrated, SessionID: sessionID, TargetNode: targetNodeID})
+	}
+}
+
+// setSession records a session's migration state and counts its outcome.
+// A failed session leaves the failed count when it is retried.
+func (c *Coordinator) setSession(op *drainOperation, sessionID, state string, err error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.setSessionLocked(op, sessionID, state, err)
+}
+
+func (c *Coordinator) setSessionLocked(op *drainOperation, sessionID, state string, err error) {
+	i, ok := op.sessions[sessionID]
+	if !ok {
+		return
+	}
+	p := &op.status.Sessions[i]
+	if p.State == SessionFailed {
+		op.status.FailedCount--
+	}
+	p.State = state
+	p.Error = ""
+	switch state {
+	case SessionMigrated:
+		op.status.MigratedCount++
+	case SessionFailed:
+		op.status.FailedCount++
+		p.Error = err.Error()
+		op.status.Errors = append(op.status.Errors, SessionError{
+			SessionID: sessionID,
+			Error:     err.Error(),
+			Timestamp: time.Now(),
+		})
+	}
+}
+
+// retryTimeout bounds the migration of a retried session
+const retryTimeout = 30 * time.Second
+
+// RetrySession migrates again a session that failed to migrate during a
+// drain that has not ended. The migration runs in the background; its
+// outcome is published as drain events, and the drain completes once no
+// session is left on the node.
+func (c *Coordinator) RetrySession(nodeID, sessionID string) error {
+	c.mu.Lock()
+	op, exists := c.activeDrains[nodeID]
+	if !exists || op.status.State != mediaclient.StateDraining {
+		c.mu.Unlock()
+		return fmt.Errorf("no drain in progress for node %s", nodeID)
+	}
+	i, ok := op.sessions[sessionID]
+	if !ok || op.status.Sessions[i].State != SessionFailed {
+		c.mu.Unlock()
+		return fmt.Errorf("session %s has no failed migration on node %s", sessionID, nodeID)
+	}
+	c.setSessionLocked(op, sessionID, SessionMigrating, nil)
+	c.mu.Unlock()
+
+	targetNodeID, err := c.findTargetNode(nodeID)
+	if err != nil {
+		err = fmt.Errorf("no healthy target node: %w", err)
+		c.migrationDone(op, sessionID, "", err)
+		return err
+	}
+
+	slog.Info("[DrainCoordinator] Retrying session migration",
+		"node_id", nodeID,
+		"session_id", sessionID,
+		"target_node", targetNodeID)
+	c.publishOp(op, DrainEvent{Type: EventSessionMigrating, SessionID: sessionID, TargetNode: targetNodeID})
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), retryTimeout)
+		defer cancel()
+		err := c.migrator.MigrateSession(ctx, sessionID, targetNodeID)
+		c.migrationDone(op, sessionID, targetNodeID, err)
+
+		// Once the drain's own run has ended, complete it here if this
+		// was the last session
+		<-op.completed
+		c.mu.RLock()
+		draining := op.status.State == mediaclient.StateDraining
+		c.mu.RUnlock()
+		if draining && len(c.pool.SessionsOnNode(nodeID)) == 0 {
+			c.completeDrain(nodeID, op)
+		}
+	}()
+	return nil
+}
+
 // findTargetNode finds a healthy, active node to migrate sessions to
 func (c *Coordinator) findTargetNode(excludeNodeID string) (string, error) {
 	stats := c.pool.Stats()
@@ -229,12 +360,14 @@ func (c *Coordinator) completeDrain(nodeID string, op *drainOperation) {
 
 	c.mu.Lock()
 	op.status.State = mediaclient.StateDisabled
+	migrated, failed := op.status.MigratedCount, op.status.FailedCount
 	c.mu.Unlock()
 
 	slog.Info("[DrainCoordinator] Drain completed successfully",
 		"node_id", nodeID,
-		"migrated", op.status.MigratedCount,
-		"failed", op.status.FailedCount)
+		"migrated", migrated,
+		"failed", failed)
+	c.publishOp(op, DrainEvent{Type: EventCompleted})
 }
 
 // failDrain records drain failure
@@ -258,6 +391,7 @@ func (c *Coordinator) failDrain(nodeID string, op *drainOperation, err error) {
 		Timestamp: time.Now(),
 	})
 	c.mu.Unlock()
+	c.publishOp(op, DrainEvent{Type: EventFailed, Error: err.Error()})
 }
 
 // GetDrainStatus returns the current status of a drain operation
@@ -289,17 +423,17 @@ func (c *Coordinator) GetDrainStatus(nodeID string) (*DrainStatus, error) {
 	}
 
 	// Return copy of status
-	statusCopy := op.status
+	statusCopy := op.snapshot()
 	return &statusCopy, nil
 }
 
 // CancelDrain cancels an in-progress drain and returns node to active
 func (c *Coordinator) CancelDrain(nodeID string) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
 
 	op, exists := c.activeDrains[nodeID]
 	if !exists {
+		c.mu.Unlock()
 		return fmt.Errorf("no drain in progress for node %s", nodeID)
 	}
 
@@ -308,14 +442,19 @@ func (c *Coordinator) CancelDrain(nodeID string) error {
 
 	// Return node to active state
 	if err := c.pool.CancelDrain(nodeID); err != nil {
+		c.mu.Unlock()
 		return fmt.Errorf("failed to cancel drain: %w", err)
 	}
 
 	// Remove from active drains
 	delete(c.activeDrains, nodeID)
+	op.status.State = mediaclient.StateActive
+	status := op.snapshot()
+	c.mu.Unlock()
 
 	slog.Info("[DrainCoordinator] Drain canceled",
 		"node_id", nodeID)
+	c.publish(DrainEvent{Type: EventCanceled, NodeID: nodeID, Timestamp: time.Now(), Status: status})
 
 	return nil
 }
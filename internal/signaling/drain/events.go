@@ -0,0 +1,116 @@
+package drain
+
+import "time"
+
+// Drain event types
+const (
+	EventStatus           = "status"            // Current status, sent first to every subscriber
+	EventStarted          = "started"           // Drain started
+	EventSessionMigrating = "session_migrating" // Session migration (or retry) started
+	EventSessionMigrated  = "session_migrated"  // Session moved to the target node
+	EventSessionFailed    = "session_failed"    // Session migration failed; it can be retried
+	EventCompleted        = "completed"         // Node drained and disabled
+	EventIncomplete       = "incomplete"        // Migrations done but sessions remain on the node
+	EventFailed           = "failed"            // Drain aborted, node back to active
+	EventCanceled         = "canceled"          // Drain canceled by the operator
+)
+
+// Per-session migration states
+const (
+	SessionPending   = "pending"
+	SessionMigrating = "migrating"
+	SessionMigrated  = "migrated"
+	SessionFailed    = "failed"
+	SessionSkipped   = "skipped" // B-leg moved with its A-leg
+)
+
+// SessionProgress is the migration state of one session of a drain
+type SessionProgress struct {
+	SessionID string `json:"session_id"`
+	State     string `json:"state"`
+	Error     string `json:"error,omitempty"`
+}
+
+// DrainEvent reports a step of a drain, with the drain's status after it
+type DrainEvent struct {
+	Type       string
+	NodeID     string
+	SessionID  string // Session events only
+	TargetNode string // Session events only
+	Error      string
+	Timestamp  time.Time
+	Status     DrainStatus
+}
+
+// Subscription receives the events of the drains of one node, or of every
+// node. A subscriber that falls behind loses events rather than slowing
+// the drain.
+type Subscription struct {
+	c      *Coordinator
+	nodeID string
+	ch     chan DrainEvent
+}
+
+// Subscribe registers a subscriber to the drain events of nodeID (every
+// node when empty), buffering up to bufferSize events (100 if not
+// positive). The first event is the node's current status. Close the
+// subscription when done.
+func (c *Coordinator) Subscribe(nodeID string, bufferSize int) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+	sub := &Subscription{c: c, nodeID: nodeID, ch: make(chan DrainEvent, bufferSize)}
+
+	// Held across the status so no event is published in between
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	if nodeID != "" {
+		if status, err := c.GetDrainStatus(nodeID); err == nil {
+			sub.ch <- DrainEvent{Type: EventStatus, NodeID: nodeID, Timestamp: time.Now(), Status: *status}
+		}
+	}
+	c.subs[sub] = struct{}{}
+	return sub
+}
+
+// Events returns the channel of drain events. It is closed when the
+// subscription is closed.
+func (s *Subscription) Events() <-chan DrainEvent {
+	return s.ch
+}
+
+// Close unsubscribes. Safe to call more than once.
+func (s *Subscription) Close() {
+	s.c.subsMu.Lock()
+	defer s.c.subsMu.Unlock()
+	if _, ok := s.c.subs[s]; ok {
+		delete(s.c.subs, s)
+		close(s.ch)
+	}
+}
+
+// publish sends an event to the subscribers of its node
+func (c *Coordinator) publish(event DrainEvent) {
+	c.subsMu.Lock()
+	defer c.subsMu.Unlock()
+	for sub := range c.subs {
+		if sub.nodeID != "" && sub.nodeID != event.NodeID {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+		}
+	}
+}
+
+// publishOp publishes an event of a drain operation with a snapshot of its
+// status
+func (c *Coordinator) publishOp(op *drainOperation, event DrainEvent) {
+	c.mu.RLock()
+	event.Status = op.snapshot()
+	c.mu.RUnlock()
+	event.NodeID = event.Status.NodeID
+	event.Timestamp = time.Now()
+	c.publish(event)
+}
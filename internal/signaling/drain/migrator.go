@@ -9,6 +9,7 @@ import (
 	"github.com/sebas/switchboard/internal/signaling/b2bua"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
 )
 
 // SessionMigrator handles migration of a single session to a new RTP manager
@@ -138,9 +139,10 @@ func (m *Migrator) migrateIVRCall(ctx context.Context, dlg *dialog.Dialog, sessi
 		"new_session_id", newSession.SessionID,
 		"target_node", targetNodeID)
 
-	// Build re-INVITE options with new SDP
+	// Build re-INVITE options with new SDP, keeping the streams the RTP
+	// manager does not anchor
 	reInviteOpts := dialog.ReINVITEOptions{
-		SDP: newSession.SDPBody,
+		SDP: sdpmedia.Reoffer(newSession.SDPBody, dlg.LocalSDP()),
 	}
 
 	// Send re-INVITE to the client
@@ -166,7 +168,7 @@ func (m *Migrator) migrateIVRCall(ctx context.Context, dlg *dialog.Dialog, sessi
 
 		if m.mode == DrainModeAggressive {
 			// In aggressive mode, terminate the call
-			_ = m.dialogMgr.Terminate(dlg.CallID, dialog.ReasonLocalBYE)
+			_ = m.dialogMgr.Terminate(dlg.CallID, dialog.ReasonDrain)
 			return fmt.Errorf("re-INVITE rejected (%d %s), call terminated",
 				result.StatusCode, result.Reason)
 		}
@@ -245,11 +247,11 @@ func (m *Migrator) migrateBridgedCall(ctx context.Context, dlgA, dlgB *dialog.Di
 
 	// Step 2: Send re-INVITE to both legs via dialog manager
 	resultA, errA := m.dialogMgr.SendReINVITE(ctx, dlgA, m.localContact, dialog.ReINVITEOptions{
-		SDP: newSessionA.SDPBody,
+		SDP: sdpmedia.Reoffer(newSessionA.SDPBody, dlgA.LocalSDP()),
 	})
 
 	resultB, errB := m.dialogMgr.SendReINVITE(ctx, dlgB, m.localContact, dialog.ReINVITEOptions{
-		SDP: newSessionB.SDPBody,
+		SDP: sdpmedia.Reoffer(newSessionB.SDPBody, dlgB.LocalSDP()),
 	})
 
 	// Step 3: Check if both succeeded
@@ -271,8 +273,8 @@ func (m *Migrator) migrateBridgedCall(ctx context.Context, dlgA, dlgB *dialog.Di
 
 		if m.mode == DrainModeAggressive {
 			// In aggressive mode, terminate the call
-			_ = m.dialogMgr.Terminate(dlgA.CallID, dialog.ReasonLocalBYE)
-			_ = m.dialogMgr.Terminate(dlgB.CallID, dialog.ReasonLocalBYE)
+			_ = m.dialogMgr.Terminate(dlgA.CallID, dialog.ReasonDrain)
+			_ = m.dialogMgr.Terminate(dlgB.CallID, dialog.ReasonDrain)
 		}
 
 		// Build error message
@@ -309,8 +311,8 @@ func (m *Migrator) migrateBridgedCall(ctx context.Context, dlgA, dlgB *dialog.Di
 		// This is critical - the calls are migrated but not bridged
 		// In aggressive mode, terminate the calls
 		if m.mode == DrainModeAggressive {
-			_ = m.dialogMgr.Terminate(dlgA.CallID, dialog.ReasonLocalBYE)
-			_ = m.dialogMgr.Terminate(dlgB.CallID, dialog.ReasonLocalBYE)
+			_ = m.dialogMgr.Terminate(dlgA.CallID, dialog.ReasonDrain)
+			_ = m.dialogMgr.Terminate(dlgB.CallID, dialog.ReasonDrain)
 		}
 		return fmt.Errorf("failed to re-establish bridge: %w", err)
 	}
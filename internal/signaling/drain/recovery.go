@@ -0,0 +1,110 @@
+package drain
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"golang.org/x/sync/semaphore"
+)
+
+// RecoveryTimeout bounds the re-creation of sessions lost in an RTP
+// manager restart
+const RecoveryTimeout = 2 * time.Minute
+
+// Recover re-creates the sessions lost when an RTP manager restarted. Each
+// call is moved to a healthy node with a re-INVITE, as a drain would move
+// it; calls that cannot be moved are hung up rather than left without
+// media. Runs in the background; suitable for Pool.SetOnMemberRestart.
+func (c *Coordinator) Recover(nodeID string, sessions []string) {
+	go c.runRecovery(nodeID, sessions)
+}
+
+// runRecovery migrates the lost sessions of a restarted node
+func (c *Coordinator) runRecovery(nodeID string, sessions []string) {
+	ctx, cancel := context.WithTimeout(context.Background(), RecoveryTimeout)
+	defer cancel()
+
+	// The restarted node itself is a valid target once it answers again
+	targetNodeID, err := c.findTargetNode("")
+	if err != nil {
+		slog.Error("[DrainCoordinator] No node to recover sessions on, hanging up their calls",
+			"node_id", nodeID,
+			"sessions", len(sessions),
+			"error", err)
+		for _, sessionID := range sessions {
+			c.migrator.hangup(sessionID)
+		}
+		return
+	}
+
+	slog.Info("[DrainCoordinator] Recovering sessions of restarted RTP manager",
+		"node_id", nodeID,
+		"target_node", targetNodeID,
+		"sessions", len(sessions))
+
+	sem := semaphore.NewWeighted(MaxConcurrentMigrations)
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	var recovered, failed int
+
+	for _, sessionID := range sessions {
+		if err := sem.Acquire(ctx, 1); err != nil {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sem.Release(1)
+
+			err := c.migrator.MigrateSession(ctx, sessionID, targetNodeID)
+			if errors.Is(err, ErrSkipBLeg) {
+				return
+			}
+
+			mu.Lock()
+			if err != nil {
+				failed++
+			} else {
+				recovered++
+			}
+			mu.Unlock()
+
+			if err != nil {
+				slog.Warn("[DrainCoordinator] Session recovery failed, hanging up call",
+					"session_id", sessionID,
+					"target_node", targetNodeID,
+					"error", err)
+				c.migrator.hangup(sessionID)
+			}
+		}()
+	}
+	wg.Wait()
+
+	slog.Info("[DrainCoordinator] Session recovery finished",
+		"node_id", nodeID,
+		"target_node", targetNodeID,
+		"recovered", recovered,
+		"failed", failed)
+}
+
+// hangup ends the call using a session whose media could not be recovered.
+// When the call is bridged, the bridge hangs up the other leg. A session
+// without a call is only released.
+func (m *Migrator) hangup(sessionID string) {
+	dlg, found := m.dialogMgr.FindBySessionID(sessionID)
+	if !found {
+		_ = m.pool.DestroySession(context.Background(), sessionID, mediaclient.TerminateReasonError)
+		return
+	}
+	if err := m.dialogMgr.Terminate(dlg.CallID, dialog.ReasonDrain); err != nil {
+		slog.Warn("[Migrator] Failed to hang up call",
+			"call_id", dlg.CallID,
+			"session_id", sessionID,
+			"error", err)
+	}
+}
@@ -44,6 +44,7 @@ type DrainStatus struct {
 	MigratedCount   int                    `json:"migrated_count"`
 	FailedCount     int                    `json:"failed_count"`
 	Errors          []SessionError         `json:"errors,omitempty"`
+	Sessions        []SessionProgress      `json:"sessions,omitempty"`
 }
 
 // SessionError records an error during session migration
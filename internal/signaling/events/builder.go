@@ -304,6 +304,12 @@ func (cb *CallEndedBuilder) HangupSource(source string) *CallEndedBuilder {
 	return cb
 }
 
+func (cb *CallEndedBuilder) HangupReason(reason string, q850Cause int) *CallEndedBuilder {
+	cb.event.HangupReason = reason
+	cb.event.Q850Cause = q850Cause
+	return cb
+}
+
 func (cb *CallEndedBuilder) Durations(setup, ring, talk, total time.Duration) *CallEndedBuilder {
 	cb.event.SetupDurationMs = setup.Milliseconds()
 	cb.event.RingDurationMs = ring.Milliseconds()
@@ -322,6 +328,12 @@ func (cb *CallEndedBuilder) Disposition(code string) *CallEndedBuilder {
 	return cb
 }
 
+func (cb *CallEndedBuilder) Account(class, accountCode string) *CallEndedBuilder {
+	cb.event.DestinationClass = class
+	cb.event.AccountCode = accountCode
+	return cb
+}
+
 func (cb *CallEndedBuilder) MediaStats(sent, received, lost uint64, jitterMs int) *CallEndedBuilder {
 	cb.event.PacketsSent = sent
 	cb.event.PacketsReceived = received
@@ -330,6 +342,11 @@ func (cb *CallEndedBuilder) MediaStats(sent, received, lost uint64, jitterMs int
 	return cb
 }
 
+func (cb *CallEndedBuilder) Attempt(n int) *CallEndedBuilder {
+	cb.event.Attempt = n
+	return cb
+}
+
 func (cb *CallEndedBuilder) Leg(leg LegRole) *CallEndedBuilder {
 	cb.event.Leg = leg
 	return cb
@@ -343,3 +360,32 @@ func (cb *CallEndedBuilder) Bridge(bridgeID string) *CallEndedBuilder {
 func (cb *CallEndedBuilder) Build() *CallEndedEvent {
 	return cb.event
 }
+
+// CallEmergencyBuilder constructs CallEmergencyEvent.
+type CallEmergencyBuilder struct {
+	event *CallEmergencyEvent
+}
+
+// CallEmergency starts building a CallEmergencyEvent.
+func (b *Builder) CallEmergency(callUUID, sipCallID, destination string) *CallEmergencyBuilder {
+	return &CallEmergencyBuilder{
+		event: &CallEmergencyEvent{
+			BaseEvent:   b.newBase(CallEmergency, callUUID, sipCallID),
+			Destination: destination,
+		},
+	}
+}
+
+func (cb *CallEmergencyBuilder) From(e Endpoint) *CallEmergencyBuilder {
+	cb.event.From = e
+	return cb
+}
+
+func (cb *CallEmergencyBuilder) Trunks(trunks []string) *CallEmergencyBuilder {
+	cb.event.Trunks = trunks
+	return cb
+}
+
+func (cb *CallEmergencyBuilder) Build() *CallEmergencyEvent {
+	return cb.event
+}
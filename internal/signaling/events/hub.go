@@ -0,0 +1,112 @@
+package events
+
+import (
+	"context"
+	"sync"
+)
+
+// Hub is a Publisher that fans events out to live subscribers, e.g. the
+// API's event stream. Events published while nobody is subscribed are
+// discarded. A subscriber that falls behind loses events rather than
+// slowing the publisher.
+type Hub struct {
+	mu     sync.Mutex
+	subs   map[*Subscription]struct{}
+	closed bool
+}
+
+// Subscription receives the events published to a Hub.
+type Subscription struct {
+	hub     *Hub
+	ch      chan Event
+	dropped int64 // Guarded by hub.mu
+}
+
+// NewHub creates a hub without subscribers.
+func NewHub() *Hub {
+	return &Hub{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscribe registers a subscriber buffering up to bufferSize events
+// (100 if not positive). Close the subscription when done.
+func (h *Hub) Subscribe(bufferSize int) *Subscription {
+	if bufferSize <= 0 {
+		bufferSize = 100
+	}
+	sub := &Subscription{hub: h, ch: make(chan Event, bufferSize)}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		close(sub.ch)
+		return sub
+	}
+	h.subs[sub] = struct{}{}
+	return sub
+}
+
+// Subscribers returns the number of active subscriptions.
+func (h *Hub) Subscribers() int {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return len(h.subs)
+}
+
+func (h *Hub) Publish(ctx context.Context, event Event) error {
+	h.PublishAsync(event)
+	return nil
+}
+
+func (h *Hub) PublishAsync(event Event) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for sub := range h.subs {
+		select {
+		case sub.ch <- event:
+		default:
+			sub.dropped++
+		}
+	}
+}
+
+func (h *Hub) Flush(ctx context.Context) error {
+	return nil
+}
+
+// Close ends every subscription.
+func (h *Hub) Close() error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil
+	}
+	h.closed = true
+	for sub := range h.subs {
+		close(sub.ch)
+		delete(h.subs, sub)
+	}
+	return nil
+}
+
+// Events returns the channel of published events. It is closed when the
+// subscription or the hub is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns the number of events lost because the buffer was full.
+func (s *Subscription) Dropped() int64 {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	return s.dropped
+}
+
+// Close unsubscribes. Safe to call more than once.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	if _, ok := s.hub.subs[s]; ok {
+		delete(s.hub.subs, s)
+		close(s.ch)
+	}
+}
@@ -10,6 +10,7 @@ import "fmt"
 //   switchboard.cdr.rated                         - Post-rating CDR stream
 //   switchboard.registrations.<endpoint>          - SIP registration events
 //   switchboard.sessions.<session_id>.media       - RTP session events
+//   switchboard.alerts.emergency                  - Emergency call alerts
 //
 // Wildcard subscriptions:
 //   switchboard.calls.>                           - All call events
@@ -38,6 +39,10 @@ const (
 
 	// Media session subjects
 	SubjectSessions = SubjectPrefix + ".sessions"
+
+	// Operator alert subjects
+	SubjectAlerts          = SubjectPrefix + ".alerts"
+	SubjectAlertsEmergency = SubjectAlerts + ".emergency"
 )
 
 // BuildCallSubject builds a subject for a specific call event.
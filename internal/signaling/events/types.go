@@ -22,6 +22,8 @@ const (
 	CallBridged EventType = "call.bridged"
 	// CallEnded fires when call terminates (any reason)
 	CallEnded EventType = "call.ended"
+	// CallEmergency fires when a call to an emergency number is received
+	CallEmergency EventType = "call.emergency"
 )
 
 // EndReason explains why a call ended
@@ -194,6 +196,9 @@ type CallEndedEvent struct {
 	SIPResponseReason string `json:"sip_response_reason,omitempty"`
 	// Who initiated the hangup
 	HangupSource string `json:"hangup_source,omitempty"` // "local", "remote", "system"
+	// Reason header (RFC 3326) received with the hangup, and its Q.850 cause
+	HangupReason string `json:"hangup_reason,omitempty"`
+	Q850Cause    int    `json:"q850_cause,omitempty"`
 	// CDR-ready duration fields (in milliseconds)
 	SetupDurationMs int64 `json:"setup_duration_ms"` // INVITE to 200 OK
 	RingDurationMs  int64 `json:"ring_duration_ms"`  // First ring to answer
@@ -202,6 +207,12 @@ type CallEndedEvent struct {
 	// Billing/CDR fields
 	BillableDurationMs int64  `json:"billable_duration_ms"` // Talk time for billing
 	DispositionCode    string `json:"disposition_code"`     // ANSWERED, NO_ANSWER, BUSY, etc.
+	// Attempt of an originated call, from 1; the events of all its
+	// redials share call_uuid
+	Attempt int `json:"attempt,omitempty"`
+	// Billing allocation for restricted destination classes
+	DestinationClass string `json:"destination_class,omitempty"` // e.g. "international"
+	AccountCode      string `json:"account_code,omitempty"`      // Account code entered before dialing
 	// Final media stats
 	PacketsSent     uint64 `json:"packets_sent,omitempty"`
 	PacketsReceived uint64 `json:"packets_received,omitempty"`
@@ -209,6 +220,21 @@ type CallEndedEvent struct {
 	JitterMs        int    `json:"jitter_ms,omitempty"`
 }
 
+// CallEmergencyEvent alerts operators to a call to an emergency number.
+// It publishes to the alerts subject rather than the per-call hierarchy so
+// that a single subscription sees every emergency call.
+type CallEmergencyEvent struct {
+	BaseEvent
+	Destination string   `json:"destination"` // Dialed emergency number
+	From        Endpoint `json:"from"`
+	Trunks      []string `json:"trunks"` // Designated trunks, in dial order
+}
+
+// Subject returns the emergency alerts subject.
+func (e *CallEmergencyEvent) Subject() string {
+	return SubjectAlertsEmergency
+}
+
 // Disposition codes for CDR
 const (
 	DispositionAnswered = "ANSWERED"
@@ -0,0 +1,500 @@
+// Package features stores per-user call feature settings, such as
+// anonymous call rejection, Do Not Disturb, call forwarding, follow-me and
+// class of service, keyed by extension (the user part of the AOR), and the
+// feature codes users dial to change them.
+package features
+
+import (
+	"cmp"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Anonymous call actions
+const (
+	AnonymousReject    = "reject"    // 433 Anonymity Disallowed
+	AnonymousVoicemail = "voicemail" // Send the caller to the user's voicemail
+)
+
+// Diversion reasons (RFC 5806), sent in the Diversion header of forwarded calls
+const (
+	ReasonUnconditional = "unconditional"
+	ReasonBusy          = "user-busy"
+	ReasonNoAnswer      = "no-answer"
+	ReasonDND           = "do-not-disturb"
+	ReasonFollowMe      = "follow-me"
+)
+
+// Follow-me modes
+const (
+	FollowMeSimultaneous = "simultaneous" // Ring every destination with the user's phones
+	FollowMeSequential   = "sequential"   // Ring the user's phones, then each destination in turn
+)
+
+// Classes of service, from most to least restricted. Users may call
+// destination classes that require their class or a more restricted one.
+const (
+	ClassInternal      = "internal"      // Internal extensions only
+	ClassNational      = "national"      // Internal and national numbers
+	ClassInternational = "international" // Unrestricted (default)
+)
+
+var classRanks = map[string]int{
+	ClassInternal:      0,
+	ClassNational:      1,
+	ClassInternational: 2,
+}
+
+// ValidClass reports whether class is a class of service.
+func ValidClass(class string) bool {
+	_, ok := classRanks[class]
+	return ok
+}
+
+// DefaultNoAnswerTimeout is how long the user's phones ring before a call
+// is forwarded on no answer.
+const DefaultNoAnswerTimeout = 20 * time.Second
+
+// Feature code actions. The "_on" forwarding codes take the forwarding
+// target as digits dialed after the code (e.g. *721002), and directed
+// pickup the extension whose ringing call to answer (e.g. **1001).
+const (
+	CodeDNDOn          = "dnd_on"
+	CodeDNDOff         = "dnd_off"
+	CodeCFUOn          = "cfu_on"
+	CodeCFUOff         = "cfu_off"
+	CodeCFBOn          = "cfb_on"
+	CodeCFBOff         = "cfb_off"
+	CodeCFNAOn         = "cfna_on"
+	CodeCFNAOff        = "cfna_off"
+	CodePickup         = "pickup"          // Group pickup: a call ringing the caller's pickup group
+	CodePickupDirected = "pickup_directed" // Directed pickup: a call ringing one extension
+	CodeAgentLogin     = "agent_login"     // Agent logs in, or ends wrap-up
+	CodeAgentLogout    = "agent_logout"
+	CodeAgentWrapUp    = "agent_wrap_up" // Agent starts wrap-up
+)
+
+// DefaultCodes are the feature codes used when the config defines none.
+var DefaultCodes = map[string]string{
+	"*78": CodeDNDOn,
+	"*79": CodeDNDOff,
+	"*72": CodeCFUOn,
+	"*73": CodeCFUOff,
+	"*90": CodeCFBOn,
+	"*91": CodeCFBOff,
+	"*92": CodeCFNAOn,
+	"*93": CodeCFNAOff,
+	"*8":  CodePickup,
+	"**":  CodePickupDirected,
+	"*61": CodeAgentLogin,
+	"*62": CodeAgentLogout,
+	"*63": CodeAgentWrapUp,
+}
+
+// Sentinel errors
+var (
+	ErrUserNotFound   = errors.New("features: user not found")
+	ErrTargetRequired = errors.New("features: forwarding target required")
+	ErrNoPickupGroup  = errors.New("features: user in no pickup group")
+)
+
+// Settings are the call features of one user.
+type Settings struct {
+	User string `json:"user"`
+
+	// Anonymous call rejection (ACR): calls with Privacy: id or an
+	// anonymous From are rejected or sent to voicemail
+	RejectAnonymous bool   `json:"reject_anonymous,omitempty"`
+	AnonymousAction string `json:"anonymous_action,omitempty"` // "reject" (default) or "voicemail"
+
+	// Voicemail is the dial target of the user's voicemail
+	// ("user/vm-1001" or "sip:1001@voicemail.example.net")
+	Voicemail string `json:"voicemail,omitempty"`
+
+	// DND (Do Not Disturb) skips ringing the user's phones and sends
+	// calls straight to the divert target
+	DND bool `json:"dnd,omitempty"`
+
+	// Call forwarding targets; empty disables. Forwarded calls are not
+	// forwarded again.
+	ForwardAlways   string `json:"forward_always,omitempty"`    // CFU: instead of ringing the user
+	ForwardBusy     string `json:"forward_busy,omitempty"`      // CFB: when the user is busy
+	ForwardNoAnswer string `json:"forward_no_answer,omitempty"` // CFNA: when the user does not answer
+	NoAnswerTimeout int    `json:"no_answer_timeout,omitempty"` // Seconds to ring before CFNA (default: 20)
+
+	// Follow-me: further destinations that ring along with the user's
+	// phones, all at once or one after another
+	FollowMe     []FollowMeTarget `json:"follow_me,omitempty"`
+	FollowMeMode string           `json:"follow_me_mode,omitempty"` // "simultaneous" (default) or "sequential"
+
+	// PIN authorizes calls to destination classes that require one, and
+	// calls the class of service does not permit (digits only; empty:
+	// such calls are refused)
+	PIN string `json:"pin,omitempty"`
+
+	// ClassOfService limits the destinations the user may call:
+	// "internal", "national" or "international" (default, unrestricted)
+	ClassOfService string `json:"class_of_service,omitempty"`
+
+	// PickupGroup is the group of users whose ringing calls the user may
+	// answer with group pickup, and who may answer the user's
+	PickupGroup string `json:"pickup_group,omitempty"`
+}
+
+// FollowMeTarget is a follow-me destination.
+type FollowMeTarget struct {
+	Target  string `json:"target"`            // Dial target ("user/1002" or "sip:+15551234567@carrier.example.net")
+	Timeout int    `json:"timeout,omitempty"` // Seconds to ring (default: the user's ring time, which also caps it when ringing simultaneously)
+	Confirm bool   `json:"confirm,omitempty"` // Callee must press 1 to accept, so their voicemail cannot take the call
+}
+
+// Divert returns where calls go without ringing the user's phones:
+// the unconditional forwarding target or, with Do Not Disturb on, the
+// busy forwarding target or voicemail. Returns "" if the user is rung.
+func (s *Settings) Divert() (target, reason string) {
+	if s.ForwardAlways != "" {
+		return s.ForwardAlways, ReasonUnconditional
+	}
+	if s.DND {
+		return cmp.Or(s.ForwardBusy, s.Voicemail), ReasonDND
+	}
+	return "", ""
+}
+
+// RingTimeout returns how long to ring the user before forwarding on no
+// answer, capped at the dial timeout.
+func (s *Settings) RingTimeout(dialTimeout time.Duration) time.Duration {
+	if s.ForwardNoAnswer == "" {
+		return dialTimeout
+	}
+	timeout := DefaultNoAnswerTimeout
+	if s.NoAnswerTimeout > 0 {
+		timeout = time.Duration(s.NoAnswerTimeout) * time.Second
+	}
+	return min(timeout, dialTimeout)
+}
+
+// CheckPIN reports whether pin is the user's PIN.
+func (s *Settings) CheckPIN(pin string) bool {
+	return s.PIN != "" && subtle.ConstantTimeCompare([]byte(s.PIN), []byte(pin)) == 1
+}
+
+// Permits reports whether the user's class of service allows calls to
+// destinations that require the class required.
+func (s *Settings) Permits(required string) bool {
+	return classRanks[cmp.Or(s.ClassOfService, ClassInternational)] >= classRanks[required]
+}
+
+// Validate checks the settings.
+func (s *Settings) Validate() error {
+	if s.User == "" {
+		return fmt.Errorf("features: user required")
+	}
+	switch s.AnonymousAction {
+	case "", AnonymousReject:
+	case AnonymousVoicemail:
+		if s.RejectAnonymous && s.Voicemail == "" {
+			return fmt.Errorf("features: user %s: voicemail target required for anonymous_action %q", s.User, s.AnonymousAction)
+		}
+	default:
+		return fmt.Errorf("features: user %s: invalid anonymous_action %q", s.User, s.AnonymousAction)
+	}
+	if s.NoAnswerTimeout < 0 {
+		return fmt.Errorf("features: user %s: no_answer_timeout must not be negative", s.User)
+	}
+	switch s.FollowMeMode {
+	case "", FollowMeSimultaneous, FollowMeSequential:
+	default:
+		return fmt.Errorf("features: user %s: invalid follow_me_mode %q", s.User, s.FollowMeMode)
+	}
+	if s.ClassOfService != "" && !ValidClass(s.ClassOfService) {
+		return fmt.Errorf("features: user %s: invalid class_of_service %q", s.User, s.ClassOfService)
+	}
+	if strings.Trim(s.PIN, "0123456789") != "" {
+		return fmt.Errorf("features: user %s: pin must be digits", s.User)
+	}
+	for _, dest := range s.FollowMe {
+		if dest.Target == "" {
+			return fmt.Errorf("features: user %s: follow-me target required", s.User)
+		}
+		if dest.Timeout < 0 {
+			return fmt.Errorf("features: user %s: follow-me timeout must not be negative", s.User)
+		}
+	}
+	return nil
+}
+
+// Config is the on-disk form of the store.
+type Config struct {
+	Codes map[string]string `json:"codes,omitempty"` // Dialed code -> action (default: DefaultCodes)
+	Users []Settings        `json:"users"`
+}
+
+// Store holds per-user feature settings. Changes made through the
+// provisioning API are written back to the config file when one is set.
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	codes map[string]string
+	users map[string]*Settings
+}
+
+// NewStore creates an empty store.
+func NewStore() *Store {
+	return &Store{codes: DefaultCodes, users: make(map[string]*Settings)}
+}
+
+// Load creates a store from a JSON config file. A missing file yields an
+// empty store that is saved to path on the first change.
+func Load(path string) (*Store, error) {
+	s := NewStore()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read features config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse features config: %w", err)
+	}
+	if len(cfg.Codes) > 0 {
+		for code, action := range cfg.Codes {
+			if err := validateCode(code, action); err != nil {
+				return nil, err
+			}
+		}
+		s.codes = cfg.Codes
+	}
+	for i := range cfg.Users {
+		settings := cfg.Users[i]
+		if err := settings.Validate(); err != nil {
+			return nil, err
+		}
+		s.users[settings.User] = &settings
+	}
+	return s, nil
+}
+
+// Get returns a user's settings. Users without settings have every
+// feature off.
+func (s *Store) Get(user string) Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if settings, ok := s.users[user]; ok {
+		return *settings
+	}
+	return Settings{User: user}
+}
+
+// Code returns the action of a dialed feature code and, for forwarding
+// codes, the digits dialed after it.
+func (s *Store) Code(dialed string) (action, arg string, ok bool) {
+	if action, ok := s.codes[dialed]; ok {
+		return action, "", true
+	}
+	for code, action := range s.codes {
+		if !takesTarget(action) {
+			continue
+		}
+		if arg, ok := strings.CutPrefix(dialed, code); ok {
+			return action, arg, true
+		}
+	}
+	return "", "", false
+}
+
+// PickupGroup returns the users of a pickup group other than user, sorted.
+// Returns ErrNoPickupGroup if user is in no pickup group.
+func (s *Store) PickupGroup(user string) ([]string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	settings, ok := s.users[user]
+	if !ok || settings.PickupGroup == "" {
+		return nil, fmt.Errorf("%w: %s", ErrNoPickupGroup, user)
+	}
+	return s.groupLocked(settings.PickupGroup, user), nil
+}
+
+// Group returns the users of a pickup group, sorted.
+func (s *Store) Group(group string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.groupLocked(group, "")
+}
+
+// groupLocked returns the users of a pickup group but one (must hold lock).
+func (s *Store) groupLocked(group, except string) []string {
+	var users []string
+	for name, settings := range s.users {
+		if settings.PickupGroup == group && name != except {
+			users = append(users, name)
+		}
+	}
+	sort.Strings(users)
+	return users
+}
+
+// IsPickup reports whether a feature code action picks up a call rather
+// than changing the user's settings.
+func IsPickup(action string) bool {
+	return action == CodePickup || action == CodePickupDirected
+}
+
+// IsAgent reports whether a feature code action changes the user's agent
+// state rather than their settings.
+func IsAgent(action string) bool {
+	return action == CodeAgentLogin || action == CodeAgentLogout || action == CodeAgentWrapUp
+}
+
+// ApplyCode performs a feature code action for a user and returns the
+// updated settings. Forwarding codes forward to "user/<arg>". Pickup
+// and agent codes change nothing.
+func (s *Store) ApplyCode(user, action, arg string) (Settings, error) {
+	if IsPickup(action) || IsAgent(action) {
+		return s.Get(user), nil
+	}
+	if takesTarget(action) && arg == "" {
+		return Settings{}, fmt.Errorf("%w: %s", ErrTargetRequired, action)
+	}
+	target := "user/" + arg
+	return s.Update(user, func(settings *Settings) {
+		switch action {
+		case CodeDNDOn:
+			settings.DND = true
+		case CodeDNDOff:
+			settings.DND = false
+		case CodeCFUOn:
+			settings.ForwardAlways = target
+		case CodeCFUOff:
+			settings.ForwardAlways = ""
+		case CodeCFBOn:
+			settings.ForwardBusy = target
+		case CodeCFBOff:
+			settings.ForwardBusy = ""
+		case CodeCFNAOn:
+			settings.ForwardNoAnswer = target
+		case CodeCFNAOff:
+			settings.ForwardNoAnswer = ""
+		}
+	})
+}
+
+// List returns all stored settings sorted by user.
+func (s *Store) List() []Settings {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	out := make([]Settings, 0, len(s.users))
+	for _, settings := range s.users {
+		out = append(out, *settings)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].User < out[j].User })
+	return out
+}
+
+// Put adds or replaces a user's settings.
+func (s *Store) Put(settings Settings) error {
+	if err := settings.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.users[settings.User] = &settings
+	return s.saveLocked()
+}
+
+// Update applies fn to a user's settings (starting from defaults for
+// users without settings) and stores the result.
+func (s *Store) Update(user string, fn func(*Settings)) (Settings, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	settings := Settings{User: user}
+	if current, ok := s.users[user]; ok {
+		settings = *current
+	}
+	fn(&settings)
+	settings.User = user
+	if err := settings.Validate(); err != nil {
+		return Settings{}, err
+	}
+	s.users[user] = &settings
+	return settings, s.saveLocked()
+}
+
+// Delete removes a user's settings.
+func (s *Store) Delete(user string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.users[user]; !ok {
+		return fmt.Errorf("%w: %s", ErrUserNotFound, user)
+	}
+	delete(s.users, user)
+	return s.saveLocked()
+}
+
+// saveLocked writes the store to its config file, if any (must hold lock).
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	cfg := Config{Users: make([]Settings, 0, len(s.users))}
+	if !maps.Equal(s.codes, DefaultCodes) {
+		cfg.Codes = s.codes
+	}
+	for _, settings := range s.users {
+		cfg.Users = append(cfg.Users, *settings)
+	}
+	sort.Slice(cfg.Users, func(i, j int) bool { return cfg.Users[i].User < cfg.Users[j].User })
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode features config: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write features config: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write features config: %w", err)
+	}
+	return nil
+}
+
+func validateCode(code, action string) error {
+	if code == "" {
+		return fmt.Errorf("features: empty feature code")
+	}
+	switch action {
+	case CodeDNDOn, CodeDNDOff,
+		CodeCFUOn, CodeCFUOff, CodeCFBOn, CodeCFBOff, CodeCFNAOn, CodeCFNAOff,
+		CodePickup, CodePickupDirected,
+		CodeAgentLogin, CodeAgentLogout, CodeAgentWrapUp:
+		return nil
+	default:
+		return fmt.Errorf("features: code %s: invalid action %q", code, action)
+	}
+}
+
+func takesTarget(action string) bool {
+	return action == CodeCFUOn || action == CodeCFBOn || action == CodeCFNAOn || action == CodePickupDirected
+}
@@ -0,0 +1,323 @@
+// Package headerpolicy adds, removes and rewrites SIP headers of messages
+// crossing the B2BUA, from declarative rules, so that interop tweaks for a
+// carrier or device need no code changes.
+//
+// A rule applies to received or sent requests or responses, optionally
+// only for some methods, peers (host, IP or CIDR of the other side) and
+// messages with a header matching a regular expression:
+//
+//	{
+//	  "rules": [
+//	    {
+//	      "name": "carrier-a",
+//	      "direction": "out",
+//	      "methods": ["INVITE"],
+//	      "peer": "203.0.113.0/24",
+//	      "actions": [
+//	        {"op": "remove", "header": "X-Tenant"},
+//	        {"op": "set", "header": "P-Early-Media", "value": "supported"}
+//	      ]
+//	    }
+//	  ]
+//	}
+//
+// Received requests and the responses sent to them are handled by the
+// Middleware; requests sent on outbound legs and their responses by the
+// B2BUA originator. Rules run in file order.
+package headerpolicy
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// Directions
+const (
+	DirectionIn  = "in"  // Messages received
+	DirectionOut = "out" // Messages sent
+)
+
+// Message kinds
+const (
+	MessageRequest  = "request"
+	MessageResponse = "response"
+)
+
+// Action operations
+const (
+	OpAdd     = "add"     // Append a header, keeping existing ones
+	OpSet     = "set"     // Replace all values of a header
+	OpRemove  = "remove"  // Remove all values of a header
+	OpRewrite = "rewrite" // Replace regular expression matches in each value
+)
+
+// ErrProtectedHeader is returned for actions on headers that carry
+// transaction and dialog state, which a policy cannot change.
+var ErrProtectedHeader = errors.New("protected header")
+
+// protectedHeaders are the headers the SIP stack builds and parses itself.
+var protectedHeaders = []string{
+	"via", "from", "to", "call-id", "cseq", "contact", "route",
+	"record-route", "max-forwards", "content-length", "content-type",
+}
+
+// message is the header access shared by requests and responses.
+type message interface {
+	GetHeaders(name string) []sip.Header
+	AppendHeader(header sip.Header)
+	RemoveHeader(name string) bool
+}
+
+// Action changes one header.
+type Action struct {
+	Op      string `json:"op"`
+	Header  string `json:"header"`
+	Value   string `json:"value,omitempty"`   // add, set
+	Match   string `json:"match,omitempty"`   // rewrite: regular expression
+	Replace string `json:"replace,omitempty"` // rewrite: replacement, may use $1
+
+	re *regexp.Regexp
+}
+
+// Rule applies actions to the messages matching all of its conditions.
+type Rule struct {
+	Name      string   `json:"name"`
+	Direction string   `json:"direction"`         // "in" or "out"
+	Message   string   `json:"message,omitempty"` // "request" (default) or "response"
+	Methods   []string `json:"methods,omitempty"` // Request methods (CSeq method for responses); empty: any
+	Peer      string   `json:"peer,omitempty"`    // Host, IP or CIDR of the other side; empty: any
+
+	// Header and Matches limit the rule to messages with the header; with
+	// Matches, to messages where a value matches the regular expression.
+	Header  string `json:"header,omitempty"`
+	Matches string `json:"matches,omitempty"`
+
+	Actions []Action `json:"actions"`
+
+	prefix  netip.Prefix
+	matches *regexp.Regexp
+}
+
+// compile validates the rule and prepares it for matching.
+func (r *Rule) compile() error {
+	if r.Name == "" {
+		return errors.New("headerpolicy: rule name required")
+	}
+	if r.Direction != DirectionIn && r.Direction != DirectionOut {
+		return fmt.Errorf("headerpolicy: rule %s: direction must be %q or %q", r.Name, DirectionIn, DirectionOut)
+	}
+	switch r.Message {
+	case "":
+		r.Message = MessageRequest
+	case MessageRequest, MessageResponse:
+	default:
+		return fmt.Errorf("headerpolicy: rule %s: message must be %q or %q", r.Name, MessageRequest, MessageResponse)
+	}
+	for i, m := range r.Methods {
+		r.Methods[i] = strings.ToUpper(m)
+	}
+	if r.Peer != "" {
+		if prefix, err := netip.ParsePrefix(r.Peer); err == nil {
+			r.prefix = prefix
+		}
+	}
+	if r.Matches != "" {
+		if r.Header == "" {
+			return fmt.Errorf("headerpolicy: rule %s: matches requires header", r.Name)
+		}
+		re, err := regexp.Compile(r.Matches)
+		if err != nil {
+			return fmt.Errorf("headerpolicy: rule %s: %w", r.Name, err)
+		}
+		r.matches = re
+	}
+	if len(r.Actions) == 0 {
+		return fmt.Errorf("headerpolicy: rule %s: actions required", r.Name)
+	}
+	for i := range r.Actions {
+		if err := r.Actions[i].compile(); err != nil {
+			return fmt.Errorf("headerpolicy: rule %s: %w", r.Name, err)
+		}
+	}
+	return nil
+}
+
+func (a *Action) compile() error {
+	if a.Header == "" {
+		return errors.New("action header required")
+	}
+	if slices.Contains(protectedHeaders, strings.ToLower(a.Header)) {
+		return fmt.Errorf("%w: %s", ErrProtectedHeader, a.Header)
+	}
+	switch a.Op {
+	case OpAdd, OpSet, OpRemove:
+	case OpRewrite:
+		re, err := regexp.Compile(a.Match)
+		if err != nil || a.Match == "" {
+			return fmt.Errorf("rewrite %s: invalid match %q", a.Header, a.Match)
+		}
+		a.re = re
+	default:
+		return fmt.Errorf("unknown action op %q", a.Op)
+	}
+	return nil
+}
+
+// applies reports whether the rule matches a message.
+func (r *Rule) applies(direction, kind, method, peer string, msg message) bool {
+	if r.Direction != direction || r.Message != kind {
+		return false
+	}
+	if len(r.Methods) > 0 && !slices.Contains(r.Methods, method) {
+		return false
+	}
+	if r.Peer != "" && !r.matchPeer(peer) {
+		return false
+	}
+	if r.Header != "" {
+		values := msg.GetHeaders(r.Header)
+		if len(values) == 0 {
+			return false
+		}
+		if r.matches != nil && !slices.ContainsFunc(values, func(h sip.Header) bool {
+			return r.matches.MatchString(h.Value())
+		}) {
+			return false
+		}
+	}
+	return true
+}
+
+// matchPeer matches a peer host or IP against the rule's host, IP or CIDR.
+func (r *Rule) matchPeer(peer string) bool {
+	peer = strings.Trim(peer, "[]")
+	if r.prefix.IsValid() {
+		addr, err := netip.ParseAddr(peer)
+		return err == nil && r.prefix.Contains(addr.Unmap())
+	}
+	return strings.EqualFold(strings.Trim(r.Peer, "[]"), peer)
+}
+
+// apply runs the rule's actions on a message.
+func (r *Rule) apply(msg message) {
+	for _, a := range r.Actions {
+		switch a.Op {
+		case OpAdd:
+			msg.AppendHeader(sip.NewHeader(a.Header, a.Value))
+		case OpSet:
+			removeAll(msg, a.Header)
+			msg.AppendHeader(sip.NewHeader(a.Header, a.Value))
+		case OpRemove:
+			removeAll(msg, a.Header)
+		case OpRewrite:
+			values := msg.GetHeaders(a.Header)
+			rewritten := make([]string, len(values))
+			for i, h := range values {
+				rewritten[i] = a.re.ReplaceAllString(h.Value(), a.Replace)
+			}
+			removeAll(msg, a.Header)
+			for _, v := range rewritten {
+				msg.AppendHeader(sip.NewHeader(a.Header, v))
+			}
+		}
+	}
+}
+
+func removeAll(msg message, name string) {
+	for msg.RemoveHeader(name) {
+	}
+}
+
+// Policy is a list of header rules. It is immutable once loaded and safe
+// for concurrent use.
+type Policy struct {
+	rules []Rule
+}
+
+// file is the on-disk format of a policy.
+type file struct {
+	Rules []Rule `json:"rules"`
+}
+
+// Load reads a policy from a JSON file.
+func Load(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("headerpolicy: read %s: %w", path, err)
+	}
+	var f file
+	if err := json.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("headerpolicy: parse %s: %w", path, err)
+	}
+	return New(f.Rules)
+}
+
+// New creates a policy from rules.
+func New(rules []Rule) (*Policy, error) {
+	for i := range rules {
+		if err := rules[i].compile(); err != nil {
+			return nil, err
+		}
+	}
+	return &Policy{rules: rules}, nil
+}
+
+// Rules returns the number of rules.
+func (p *Policy) Rules() int {
+	return len(p.rules)
+}
+
+// ReceivedRequest applies "in" request rules to a request from peer.
+func (p *Policy) ReceivedRequest(req *sip.Request, peer string) {
+	p.applyRequest(DirectionIn, req, peer)
+}
+
+// SentRequest applies "out" request rules to a request about to be sent to peer.
+func (p *Policy) SentRequest(req *sip.Request, peer string) {
+	p.applyRequest(DirectionOut, req, peer)
+}
+
+// ReceivedResponse applies "in" response rules to a response from peer.
+func (p *Policy) ReceivedResponse(res *sip.Response, peer string) {
+	p.applyResponse(DirectionIn, res, peer)
+}
+
+// SentResponse applies "out" response rules to a response about to be sent to peer.
+func (p *Policy) SentResponse(res *sip.Response, peer string) {
+	p.applyResponse(DirectionOut, res, peer)
+}
+
+func (p *Policy) applyRequest(direction string, req *sip.Request, peer string) {
+	for i := range p.rules {
+		if p.rules[i].applies(direction, MessageRequest, string(req.Method), peer, req) {
+			p.rules[i].apply(req)
+		}
+	}
+}
+
+func (p *Policy) applyResponse(direction string, res *sip.Response, peer string) {
+	method := ""
+	if cseq := res.CSeq(); cseq != nil {
+		method = string(cseq.MethodName)
+	}
+	for i := range p.rules {
+		if p.rules[i].applies(direction, MessageResponse, method, peer, res) {
+			p.rules[i].apply(res)
+		}
+	}
+}
+
+// has reports whether any rule applies to the direction and message kind.
+func (p *Policy) has(direction, kind string) bool {
+	return slices.ContainsFunc(p.rules, func(r Rule) bool {
+		return r.Direction == direction && r.Message == kind
+	})
+}
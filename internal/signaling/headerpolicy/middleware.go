@@ -0,0 +1,47 @@
+package headerpolicy
+
+import (
+	"net"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+)
+
+// Middleware applies "in" request rules to received requests and "out"
+// response rules to the responses sent on their transactions.
+func (p *Policy) Middleware() middleware.Middleware {
+	wrapResponses := p.has(DirectionOut, MessageResponse)
+	return middleware.Func("header-policy", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		peer := sourceHost(req)
+		p.ReceivedRequest(req, peer)
+		// Only wrap when needed: the SIP stack special-cases its own
+		// transaction type for INVITEs canceled before they are answered
+		if wrapResponses && tx != nil {
+			tx = &policyTx{ServerTransaction: tx, policy: p, peer: peer}
+		}
+		next(req, tx)
+	})
+}
+
+// policyTx applies "out" response rules to the responses of a server
+// transaction.
+type policyTx struct {
+	sip.ServerTransaction
+	policy *Policy
+	peer   string
+}
+
+// Respond implements sip.ServerTransaction.
+func (t *policyTx) Respond(res *sip.Response) error {
+	t.policy.SentResponse(res, t.peer)
+	return t.ServerTransaction.Respond(res)
+}
+
+// sourceHost returns the host a request was received from.
+func sourceHost(req *sip.Request) string {
+	host, _, err := net.SplitHostPort(req.Source())
+	if err != nil {
+		return req.Source()
+	}
+	return host
+}
@@ -0,0 +1,206 @@
+// Package identity sets the User-Agent of the SIP requests and the Server
+// header of the SIP responses this server sends, so deployments choose
+// what carriers see of the SIP stack; some fingerprint and block stacks
+// by these headers.
+//
+// The server-wide values (empty: header not sent) may be replaced for
+// tenants, groups of peers matched by host, IP or CIDR, or by the trunk
+// a request came from:
+//
+//	{
+//	  "tenants": [
+//	    {
+//	      "name": "acme",
+//	      "peers": ["203.0.113.0/24", "sip.acme.example"],
+//	      "trunks": ["carrier-a"],
+//	      "user_agent": "AcmeVoice/2.1",
+//	      "server": ""
+//	    }
+//	  ]
+//	}
+//
+// A value of "" suppresses the header for the tenant; an absent value
+// keeps the server-wide one.
+package identity
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"slices"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// Tenant is a group of peers with their own identity
+type Tenant struct {
+	Name string `json:"name"`
+
+	// Peers are host names, IPs or CIDRs of the tenant's peers
+	Peers []string `json:"peers,omitempty"`
+
+	// Trunks are the names of trunks whose requests are answered with the
+	// tenant's Server header
+	Trunks []string `json:"trunks,omitempty"`
+
+	// UserAgent and Server replace the server-wide values; "" suppresses
+	// the header and nil keeps the server-wide value
+	UserAgent *string `json:"user_agent,omitempty"`
+	Server    *string `json:"server,omitempty"`
+
+	hosts    []string
+	prefixes []netip.Prefix
+}
+
+// compile validates the tenant and parses its peers
+func (t *Tenant) compile() error {
+	if t.Name == "" {
+		return errors.New("identity: tenant name required")
+	}
+	for _, peer := range t.Peers {
+		peer = strings.Trim(strings.TrimSpace(peer), "[]")
+		if prefix, err := netip.ParsePrefix(peer); err == nil {
+			t.prefixes = append(t.prefixes, prefix.Masked())
+		} else if addr, err := netip.ParseAddr(peer); err == nil {
+			t.prefixes = append(t.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+		} else if peer != "" {
+			t.hosts = append(t.hosts, strings.ToLower(peer))
+		}
+	}
+	return nil
+}
+
+// matchPeer reports whether a peer host or IP is one of the tenant's
+func (t *Tenant) matchPeer(peer string) bool {
+	peer = strings.ToLower(strings.Trim(peer, "[]"))
+	if addr, err := netip.ParseAddr(peer); err == nil {
+		addr = addr.Unmap()
+		return slices.ContainsFunc(t.prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+	}
+	return slices.Contains(t.hosts, peer)
+}
+
+// Headers sets the identity headers of messages sent. It is immutable
+// once created and safe for concurrent use.
+type Headers struct {
+	userAgent string
+	server    string
+	tenants   []Tenant
+}
+
+// file is the on-disk format of the tenant file
+type file struct {
+	Tenants []Tenant `json:"tenants"`
+}
+
+// Load creates identity headers with the server-wide values and the
+// tenants of a JSON file (none if path is empty).
+func Load(userAgent, server, path string) (*Headers, error) {
+	var f file
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("identity: read %s: %w", path, err)
+		}
+		if err := json.Unmarshal(data, &f); err != nil {
+			return nil, fmt.Errorf("identity: parse %s: %w", path, err)
+		}
+	}
+	return New(userAgent, server, f.Tenants)
+}
+
+// New creates identity headers with the server-wide values (empty: not
+// sent) and tenants, matched in order.
+func New(userAgent, server string, tenants []Tenant) (*Headers, error) {
+	seen := make(map[string]bool)
+	for i := range tenants {
+		if err := tenants[i].compile(); err != nil {
+			return nil, err
+		}
+		if seen[tenants[i].Name] {
+			return nil, fmt.Errorf("identity: tenant %s defined twice", tenants[i].Name)
+		}
+		seen[tenants[i].Name] = true
+	}
+	return &Headers{userAgent: userAgent, server: server, tenants: tenants}, nil
+}
+
+// Enabled reports whether any message gets or loses an identity header
+func (h *Headers) Enabled() bool {
+	return h.userAgent != "" || h.server != "" || slices.ContainsFunc(h.tenants, func(t Tenant) bool {
+		return t.UserAgent != nil || t.Server != nil
+	})
+}
+
+// Tenants returns the number of tenants
+func (h *Headers) Tenants() int {
+	return len(h.tenants)
+}
+
+// UserAgent returns the User-Agent sent to peer ("" for none)
+func (h *Headers) UserAgent(peer string) string {
+	for i := range h.tenants {
+		if t := &h.tenants[i]; t.UserAgent != nil && t.matchPeer(peer) {
+			return *t.UserAgent
+		}
+	}
+	return h.userAgent
+}
+
+// Server returns the Server header sent to peer on a request from trunk
+// ("" for none): the tenant of the trunk, then of the peer, wins.
+func (h *Headers) Server(peer, trunk string) string {
+	if trunk != "" {
+		for i := range h.tenants {
+			if t := &h.tenants[i]; t.Server != nil && slices.Contains(t.Trunks, trunk) {
+				return *t.Server
+			}
+		}
+	}
+	for i := range h.tenants {
+		if t := &h.tenants[i]; t.Server != nil && t.matchPeer(peer) {
+			return *t.Server
+		}
+	}
+	return h.server
+}
+
+// SentRequest sets the User-Agent of a request about to be sent to peer
+// (its host), or removes it.
+func (h *Headers) SentRequest(req *sip.Request, peer string) {
+	set(req, "User-Agent", h.UserAgent(peer))
+}
+
+// SentResponse sets the Server header of a response about to be sent to
+// peer (its host) on a request from trunk ("" for none), or removes it.
+func (h *Headers) SentResponse(res *sip.Response, peer, trunk string) {
+	set(res, "Server", h.Server(peer, trunk))
+}
+
+// message is the header access shared by requests and responses
+type message interface {
+	Headers() []sip.Header
+	AppendHeader(header sip.Header)
+	RemoveHeader(name string) bool
+}
+
+// set replaces all values of a header with value, or removes them all if
+// value is empty
+func set(msg message, name, value string) {
+	var names []string
+	for _, hdr := range msg.Headers() {
+		if strings.EqualFold(hdr.Name(), name) && !slices.Contains(names, hdr.Name()) {
+			names = append(names, hdr.Name())
+		}
+	}
+	for _, n := range names {
+		for msg.RemoveHeader(n) {
+		}
+	}
+	if value != "" {
+		msg.AppendHeader(sip.NewHeader(name, value))
+	}
+}
@@ -0,0 +1,65 @@
+package identity
+
+import (
+	"testing"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+func ptr(s string) *string { return &s }
+
+func TestHeadersTenants(t *testing.T) {
+	h, err := New("Switchboard/1.0", "Switchboard", []Tenant{
+		{Name: "acme", Peers: []string{"203.0.113.0/24", "SIP.acme.example"}, UserAgent: ptr("AcmeVoice/2.1"), Server: ptr("")},
+		{Name: "carrier", Trunks: []string{"carrier-a"}, Server: ptr("Carrier-SBC")},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !h.Enabled() {
+		t.Fatal("Enabled() = false")
+	}
+
+	tests := []struct {
+		peer, trunk   string
+		agent, server string
+	}{
+		{"203.0.113.7", "", "AcmeVoice/2.1", ""},
+		{"sip.acme.example", "", "AcmeVoice/2.1", ""},
+		{"198.51.100.1", "", "Switchboard/1.0", "Switchboard"},
+		{"203.0.113.7", "carrier-a", "AcmeVoice/2.1", "Carrier-SBC"},
+	}
+	for _, tt := range tests {
+		if got := h.UserAgent(tt.peer); got != tt.agent {
+			t.Errorf("UserAgent(%s) = %q, want %q", tt.peer, got, tt.agent)
+		}
+		if got := h.Server(tt.peer, tt.trunk); got != tt.server {
+			t.Errorf("Server(%s, %s) = %q, want %q", tt.peer, tt.trunk, got, tt.server)
+		}
+	}
+
+	if _, err := New("", "", []Tenant{{Name: "a"}, {Name: "a"}}); err == nil {
+		t.Error("duplicate tenant accepted")
+	}
+	if h, _ := New("", "", nil); h.Enabled() {
+		t.Error("empty identity enabled")
+	}
+}
+
+func TestHeadersSet(t *testing.T) {
+	h, err := New("Switchboard/1.0", "", []Tenant{{Name: "quiet", Peers: []string{"192.0.2.1"}, UserAgent: ptr("")}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := sip.NewRequest(sip.OPTIONS, sip.Uri{Scheme: "sip", Host: "198.51.100.1"})
+	req.AppendHeader(sip.NewHeader("user-agent", "sipgo"))
+	h.SentRequest(req, "198.51.100.1")
+	if got := req.GetHeaders("User-Agent"); len(got) != 1 || got[0].Value() != "Switchboard/1.0" {
+		t.Errorf("User-Agent = %v, want the server-wide value once", got)
+	}
+	h.SentRequest(req, "192.0.2.1")
+	if req.GetHeader("User-Agent") != nil {
+		t.Error("User-Agent not suppressed for tenant")
+	}
+}
@@ -0,0 +1,48 @@
+package identity
+
+import (
+	"net"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+)
+
+// Middleware sets the Server header of the responses sent to received
+// requests. trunk returns the trunk a request came from ("" for none); it
+// is asked when responding, so the middleware may run before the one
+// identifying trunks.
+func (h *Headers) Middleware(trunk func(req *sip.Request) string) middleware.Middleware {
+	return middleware.Func("identity", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		// Like header policy responses, this hides the SIP stack's own
+		// transaction type, which it checks only to end dialogs canceled
+		// before answer; the dialog manager handles CANCEL itself
+		if tx != nil {
+			tx = &identityTx{ServerTransaction: tx, headers: h, req: req, trunk: trunk}
+		}
+		next(req, tx)
+	})
+}
+
+// identityTx sets the Server header of the responses of a server
+// transaction
+type identityTx struct {
+	sip.ServerTransaction
+	headers *Headers
+	req     *sip.Request
+	trunk   func(req *sip.Request) string
+}
+
+// Respond implements sip.ServerTransaction.
+func (t *identityTx) Respond(res *sip.Response) error {
+	t.headers.SentResponse(res, sourceHost(t.req), t.trunk(t.req))
+	return t.ServerTransaction.Respond(res)
+}
+
+// sourceHost returns the host a request was received from
+func sourceHost(req *sip.Request) string {
+	host, _, err := net.SplitHostPort(req.Source())
+	if err != nil {
+		return req.Source()
+	}
+	return host
+}
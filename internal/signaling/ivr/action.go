@@ -0,0 +1,133 @@
+package ivr
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+
+	"github.com/sebas/switchboard/internal/signaling/dialplan"
+)
+
+// Params defines parameters for the ivr action.
+type Params struct {
+	Menu string `json:"menu"` // Menu to start with
+}
+
+// Action runs a caller through IVR menus until an option dials, jumps to
+// a route or hangs up.
+type Action struct {
+	store  *Store
+	params Params
+}
+
+// NewAction is the dialplan action factory for "ivr" actions; register
+// it with the executor's action registry.
+func (s *Store) NewAction(raw json.RawMessage) (dialplan.Action, error) {
+	var params Params
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse ivr params: %w", err)
+	}
+	if params.Menu == "" {
+		return nil, fmt.Errorf("ivr: menu required")
+	}
+	return &Action{store: s, params: params}, nil
+}
+
+// Type returns "ivr".
+func (a *Action) Type() string {
+	return "ivr"
+}
+
+// Execute plays menus and follows the options the caller picks. Menus are
+// looked up as they are entered, so changes apply to calls in progress.
+// A dial blocks until the call ends; a route option returns a
+// dialplan.RouteJump.
+func (a *Action) Execute(ctx context.Context, session dialplan.CallSession) error {
+	var previous []string // Menus to go back to
+	name := a.params.Menu
+
+	for {
+		menu, err := a.store.Menu(name)
+		if err != nil {
+			return err
+		}
+		opt, err := a.choose(ctx, session, menu)
+		if err != nil {
+			return err
+		}
+		slog.Info("[IVR] Option taken",
+			"call_id", session.CallID(),
+			"menu", menu.Name,
+			"action", opt.Action,
+			"target", opt.Target,
+		)
+
+		switch opt.Action {
+		case ActionMenu:
+			previous = append(previous, name)
+			name = opt.Target
+		case ActionBack:
+			// Back from the first menu plays it again
+			if n := len(previous); n > 0 {
+				name, previous = previous[n-1], previous[:n-1]
+			}
+		case ActionRepeat:
+		case ActionDial:
+			return a.dial(ctx, session, opt)
+		case ActionRoute:
+			return &dialplan.RouteJump{RouteID: opt.Target}
+		default:
+			return session.Hangup("ivr_hangup")
+		}
+	}
+}
+
+// choose plays the menu's prompt until the caller presses a key bound to
+// an option, or the attempts run out and the menu's fallback is taken.
+func (a *Action) choose(ctx context.Context, session dialplan.CallSession, menu *Menu) (Option, error) {
+	var fallback *Option
+	for attempt := 1; attempt <= menu.attempts(); attempt++ {
+		key, err := session.CollectDigits(ctx, menu.Prompt, 1, menu.timeout())
+		if err != nil {
+			return Option{}, err
+		}
+		if opt, ok := menu.Options[key]; ok {
+			return opt, nil
+		}
+
+		prompt := menu.TimeoutPrompt
+		fallback = menu.OnTimeout
+		if key != "" {
+			prompt, fallback = menu.InvalidPrompt, menu.OnInvalid
+			slog.Info("[IVR] Invalid key",
+				"call_id", session.CallID(),
+				"menu", menu.Name,
+				"key", key,
+				"attempt", attempt,
+			)
+		}
+		if prompt != "" {
+			if err := session.PlayAudio(ctx, prompt); err != nil {
+				slog.Warn("[IVR] Prompt failed", "call_id", session.CallID(), "prompt", prompt, "error", err)
+			}
+		}
+	}
+	if fallback == nil {
+		return Option{Action: ActionHangup}, nil
+	}
+	return *fallback, nil
+}
+
+// dial dials an option's target as the dial action does.
+func (a *Action) dial(ctx context.Context, session dialplan.CallSession, opt Option) error {
+	raw, err := json.Marshal(dialplan.DialParams{Target: opt.Target, Timeout: opt.Timeout})
+	if err != nil {
+		return err
+	}
+	action, err := dialplan.NewDialAction(raw)
+	if err != nil {
+		return err
+	}
+	return action.Execute(ctx, session)
+}
@@ -0,0 +1,288 @@
+// Package ivr manages declarative IVR menus.
+//
+// A Menu plays a prompt and maps the key the caller presses to an Option:
+// another menu, a dial to an extension or SIP URI, a jump to a dialplan
+// route (a queue's, for instance), going back or hanging up. Keys that
+// match no option and callers who press nothing are re-prompted until
+// the menu's attempts run out. Menus run in the dialplan through the
+// "ivr" action, so simple auto-attendants need no external application.
+package ivr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Option actions
+const (
+	ActionMenu   = "menu"   // Go to the menu named by Target
+	ActionDial   = "dial"   // Dial Target ("user/1001" or a SIP URI)
+	ActionRoute  = "route"  // Continue with the dialplan route Target
+	ActionBack   = "back"   // Return to the previous menu
+	ActionRepeat = "repeat" // Play the menu again
+	ActionHangup = "hangup" // End the call
+)
+
+// Menu defaults
+const (
+	DefaultTimeout  = 5 * time.Second
+	DefaultAttempts = 3
+)
+
+// Keys are the DTMF keys an option can be bound to. "#" ends digit
+// collection, so it cannot select an option.
+const Keys = "0123456789*"
+
+// Sentinel errors
+var (
+	ErrMenuNotFound = errors.New("ivr: menu not found")
+	ErrMenuInUse    = errors.New("ivr: menu is an option of another menu")
+)
+
+// Option is what a key, or running out of attempts, does.
+type Option struct {
+	Action  string `json:"action"`
+	Target  string `json:"target,omitempty"`  // Menu name, dial target or route ID
+	Timeout int    `json:"timeout,omitempty"` // Seconds to ring a dial target (default: the dial action's)
+}
+
+// Validate checks that the option is usable.
+func (o *Option) Validate() error {
+	switch o.Action {
+	case ActionMenu, ActionDial, ActionRoute:
+		if o.Target == "" {
+			return fmt.Errorf("%s: target required", o.Action)
+		}
+	case ActionBack, ActionRepeat, ActionHangup:
+	default:
+		return fmt.Errorf("invalid action %q", o.Action)
+	}
+	return nil
+}
+
+// Menu is a named IVR menu.
+type Menu struct {
+	Name          string            `json:"name"`
+	Prompt        string            `json:"prompt"`                   // Lists the options; pressing a key interrupts it
+	Options       map[string]Option `json:"options"`                  // By key: "0"-"9" or "*"
+	Timeout       int               `json:"timeout,omitempty"`        // Seconds to wait for a key after the prompt (default 5)
+	Attempts      int               `json:"attempts,omitempty"`       // Times the prompt is played before giving up (default 3)
+	InvalidPrompt string            `json:"invalid_prompt,omitempty"` // Played when a key matches no option
+	TimeoutPrompt string            `json:"timeout_prompt,omitempty"` // Played when no key is pressed
+	OnInvalid     *Option           `json:"on_invalid,omitempty"`     // Taken when the last attempt was an invalid key (default: hang up)
+	OnTimeout     *Option           `json:"on_timeout,omitempty"`     // Taken when no key was pressed on the last attempt (default: hang up)
+}
+
+// Validate checks that the menu is usable. Menus named by options are
+// checked by the store.
+func (m *Menu) Validate() error {
+	if m.Name == "" {
+		return fmt.Errorf("ivr: menu name required")
+	}
+	if m.Prompt == "" {
+		return fmt.Errorf("ivr: menu %s: prompt required", m.Name)
+	}
+	if len(m.Options) == 0 {
+		return fmt.Errorf("ivr: menu %s: options required", m.Name)
+	}
+	if m.Timeout < 0 || m.Attempts < 0 {
+		return fmt.Errorf("ivr: menu %s: timeout and attempts cannot be negative", m.Name)
+	}
+	for key, opt := range m.Options {
+		if len(key) != 1 || !strings.Contains(Keys, key) {
+			return fmt.Errorf("ivr: menu %s: invalid key %q (use one of %s)", m.Name, key, Keys)
+		}
+		if err := opt.Validate(); err != nil {
+			return fmt.Errorf("ivr: menu %s: key %s: %w", m.Name, key, err)
+		}
+	}
+	if m.OnInvalid != nil {
+		if err := m.OnInvalid.Validate(); err != nil {
+			return fmt.Errorf("ivr: menu %s: on_invalid: %w", m.Name, err)
+		}
+	}
+	if m.OnTimeout != nil {
+		if err := m.OnTimeout.Validate(); err != nil {
+			return fmt.Errorf("ivr: menu %s: on_timeout: %w", m.Name, err)
+		}
+	}
+	return nil
+}
+
+// timeout returns how long to wait for a key after the prompt.
+func (m *Menu) timeout() time.Duration {
+	if m.Timeout == 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(m.Timeout) * time.Second
+}
+
+// attempts returns how many times the prompt is played.
+func (m *Menu) attempts() int {
+	if m.Attempts == 0 {
+		return DefaultAttempts
+	}
+	return m.Attempts
+}
+
+// targets returns the menus the menu's options go to.
+func (m *Menu) targets() []string {
+	var names []string
+	for _, opt := range m.Options {
+		if opt.Action == ActionMenu {
+			names = append(names, opt.Target)
+		}
+	}
+	for _, opt := range []*Option{m.OnInvalid, m.OnTimeout} {
+		if opt != nil && opt.Action == ActionMenu {
+			names = append(names, opt.Target)
+		}
+	}
+	return names
+}
+
+// Config is the on-disk form of the store.
+type Config struct {
+	Menus []Menu `json:"menus"`
+}
+
+// Store holds IVR menus. Changes made through the management API are
+// written back to the config file when one is set.
+type Store struct {
+	mu    sync.RWMutex
+	path  string
+	menus map[string]*Menu
+}
+
+// NewStore creates an empty store.
+func NewStore() *Store {
+	return &Store{menus: make(map[string]*Menu)}
+}
+
+// Load creates a store from a JSON config file. A missing file yields an
+// empty store that is saved to path on the first change. Every menu an
+// option goes to must be in the file.
+func Load(path string) (*Store, error) {
+	s := NewStore()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read ivr config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse ivr config: %w", err)
+	}
+	for i := range cfg.Menus {
+		menu := cfg.Menus[i]
+		if err := menu.Validate(); err != nil {
+			return nil, err
+		}
+		s.menus[menu.Name] = &menu
+	}
+	for _, menu := range s.menus {
+		for _, target := range menu.targets() {
+			if _, ok := s.menus[target]; !ok {
+				return nil, fmt.Errorf("%w: %s (from menu %s)", ErrMenuNotFound, target, menu.Name)
+			}
+		}
+	}
+	return s, nil
+}
+
+// Menu returns a menu by name.
+func (s *Store) Menu(name string) (*Menu, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	menu, ok := s.menus[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrMenuNotFound, name)
+	}
+	m := *menu
+	return &m, nil
+}
+
+// Menus returns all menus sorted by name.
+func (s *Store) Menus() []Menu {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.menusLocked()
+}
+
+// PutMenu adds or replaces a menu. The menus its options go to must
+// exist, or be the menu itself.
+func (s *Store) PutMenu(menu Menu) error {
+	if err := menu.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	for _, target := range menu.targets() {
+		if _, ok := s.menus[target]; !ok && target != menu.Name {
+			return fmt.Errorf("%w: %s", ErrMenuNotFound, target)
+		}
+	}
+	s.menus[menu.Name] = &menu
+	return s.saveLocked()
+}
+
+// DeleteMenu removes a menu. Menus other menus go to cannot be removed.
+func (s *Store) DeleteMenu(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.menus[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrMenuNotFound, name)
+	}
+	for _, menu := range s.menus {
+		if menu.Name != name && slices.Contains(menu.targets(), name) {
+			return fmt.Errorf("%w: %s (from menu %s)", ErrMenuInUse, name, menu.Name)
+		}
+	}
+	delete(s.menus, name)
+	return s.saveLocked()
+}
+
+func (s *Store) menusLocked() []Menu {
+	menus := make([]Menu, 0, len(s.menus))
+	for _, m := range s.menus {
+		menus = append(menus, *m)
+	}
+	sort.Slice(menus, func(i, j int) bool { return menus[i].Name < menus[j].Name })
+	return menus
+}
+
+// saveLocked writes the store to its config file, if any (must hold lock).
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(Config{Menus: s.menusLocked()}, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode ivr config: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write ivr config: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write ivr config: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,60 @@
+package ivr
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "ivr.json")
+	write := func(data string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	write(`{"menus": [{"name": "main", "prompt": "main.wav", "options": {"1": {"action": "menu", "target": "sales"}}}]}`)
+	if _, err := Load(path); !errors.Is(err, ErrMenuNotFound) {
+		t.Fatalf("Load with a missing submenu = %v, want ErrMenuNotFound", err)
+	}
+
+	write(`{"menus": [{"name": "main", "prompt": "main.wav", "options": {"#": {"action": "hangup"}}}]}`)
+	if _, err := Load(path); err == nil {
+		t.Fatal("Load with key # succeeded")
+	}
+
+	write(`{"menus": [
+		{"name": "main", "prompt": "main.wav", "options": {"1": {"action": "menu", "target": "sales"}, "0": {"action": "dial", "target": "user/1000"}}},
+		{"name": "sales", "prompt": "sales.wav", "options": {"*": {"action": "back"}, "1": {"action": "route", "target": "sales-queue"}}}
+	]}`)
+	store, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if menus := store.Menus(); len(menus) != 2 || menus[0].Name != "main" {
+		t.Fatalf("Menus() = %+v", menus)
+	}
+
+	if err := store.PutMenu(Menu{Name: "support", Prompt: "support.wav", Options: map[string]Option{"1": {Action: ActionDial}}}); err == nil {
+		t.Error("PutMenu with a dial option without target succeeded")
+	}
+	if err := store.PutMenu(Menu{Name: "support", Prompt: "support.wav", Options: map[string]Option{"1": {Action: ActionMenu, Target: "billing"}}}); !errors.Is(err, ErrMenuNotFound) {
+		t.Errorf("PutMenu with a missing submenu = %v, want ErrMenuNotFound", err)
+	}
+	if err := store.DeleteMenu("sales"); !errors.Is(err, ErrMenuInUse) {
+		t.Errorf("DeleteMenu(sales) = %v, want ErrMenuInUse", err)
+	}
+	if err := store.DeleteMenu("main"); err != nil {
+		t.Fatalf("DeleteMenu(main): %v", err)
+	}
+	reloaded, err := Load(path)
+	if err != nil {
+		t.Fatalf("reload: %v", err)
+	}
+	if _, err := reloaded.Menu("main"); !errors.Is(err, ErrMenuNotFound) {
+		t.Errorf("deleted menu reloaded: %v", err)
+	}
+}
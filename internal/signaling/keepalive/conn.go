@@ -0,0 +1,67 @@
+// Package keepalive answers client NAT keepalives on the SIP socket and
+// sends server keepalives to UDP bindings behind NAT (RFC 5626 section 3.5).
+package keepalive
+
+import (
+	"bytes"
+	"log/slog"
+	"net"
+)
+
+var (
+	crlf       = []byte("\r\n")
+	doubleCRLF = []byte("\r\n\r\n")
+)
+
+// Conn wraps the SIP UDP socket and consumes keepalives before the SIP
+// stack sees them. A double-CRLF ping is answered with a single CRLF pong
+// and a STUN Binding request with a Binding success response (RFC 5626
+// section 4.4.2); a lone CRLF pong is dropped.
+type Conn struct {
+	net.PacketConn
+}
+
+// NewConn wraps a UDP socket.
+func NewConn(pc net.PacketConn) *Conn {
+	return &Conn{PacketConn: pc}
+}
+
+// ReadFrom returns the next datagram that is not a keepalive.
+func (c *Conn) ReadFrom(b []byte) (int, net.Addr, error) {
+	for {
+		n, addr, err := c.PacketConn.ReadFrom(b)
+		if err != nil || !c.handleKeepalive(b[:n], addr) {
+			return n, addr, err
+		}
+	}
+}
+
+// Ping sends a double-CRLF keepalive to addr.
+func (c *Conn) Ping(addr net.Addr) error {
+	_, err := c.PacketConn.WriteTo(doubleCRLF, addr)
+	return err
+}
+
+// handleKeepalive answers data if it is a keepalive and reports whether it was.
+func (c *Conn) handleKeepalive(data []byte, addr net.Addr) bool {
+	if len(data) > 0 && len(data) <= len(doubleCRLF) && len(bytes.Trim(data, "\r\n")) == 0 {
+		if bytes.Equal(data, doubleCRLF) {
+			if _, err := c.PacketConn.WriteTo(crlf, addr); err != nil {
+				slog.Debug("[Keepalive] Failed to send pong", "addr", addr, "error", err)
+			}
+		}
+		return true
+	}
+
+	if isSTUNBindingRequest(data) {
+		udpAddr, ok := addr.(*net.UDPAddr)
+		if !ok {
+			return true
+		}
+		if _, err := c.PacketConn.WriteTo(stunBindingResponse(data, udpAddr), addr); err != nil {
+			slog.Debug("[Keepalive] Failed to answer STUN binding", "addr", addr, "error", err)
+		}
+		return true
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package keepalive
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/location"
+)
+
+// Pinger periodically sends keepalives to UDP bindings so NAT pinholes
+// stay open between registrations.
+type Pinger struct {
+	conn     *Conn
+	store    location.LocationStore
+	interval time.Duration
+}
+
+// NewPinger creates a pinger that sends over conn every interval.
+func NewPinger(conn *Conn, store location.LocationStore, interval time.Duration) *Pinger {
+	return &Pinger{conn: conn, store: store, interval: interval}
+}
+
+// Run pings every interval until ctx is done.
+func (p *Pinger) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			p.PingAll()
+		}
+	}
+}
+
+// PingAll sends one keepalive to each distinct UDP binding source and
+// returns how many were sent.
+func (p *Pinger) PingAll() int {
+	sent := 0
+	seen := make(map[string]bool)
+	for _, b := range p.store.List() {
+		if !strings.EqualFold(b.Transport, "UDP") || b.ReceivedIP == "" || b.ReceivedPort == 0 {
+			continue
+		}
+		hostPort := net.JoinHostPort(b.ReceivedIP, strconv.Itoa(b.ReceivedPort))
+		if seen[hostPort] {
+			continue
+		}
+		seen[hostPort] = true
+
+		addr, err := net.ResolveUDPAddr("udp", hostPort)
+		if err != nil {
+			continue
+		}
+		if err := p.conn.Ping(addr); err != nil {
+			slog.Debug("[Keepalive] Ping failed", "addr", hostPort, "error", err)
+			continue
+		}
+		sent++
+	}
+	if sent > 0 {
+		slog.Debug("[Keepalive] Pinged UDP bindings", "count", sent)
+	}
+	return sent
+}
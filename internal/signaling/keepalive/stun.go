@@ -0,0 +1,60 @@
+package keepalive
+
+import (
+	"encoding/binary"
+	"net"
+)
+
+// STUN (RFC 5389) constants for the Binding keepalive
+const (
+	stunHeaderLen          = 20
+	stunMagicCookie        = 0x2112A442
+	stunBindingRequest     = 0x0001
+	stunBindingSuccessResp = 0x0101
+	stunXorMappedAddr      = 0x0020
+	stunFamilyIPv4         = 0x01
+	stunFamilyIPv6         = 0x02
+)
+
+// isSTUNBindingRequest reports whether data is a STUN Binding request.
+// SIP messages start with a letter, so the first two bits being zero and
+// the magic cookie are enough to tell them apart.
+func isSTUNBindingRequest(data []byte) bool {
+	if len(data) < stunHeaderLen || data[0]&0xC0 != 0 {
+		return false
+	}
+	return binary.BigEndian.Uint16(data[0:2]) == stunBindingRequest &&
+		binary.BigEndian.Uint32(data[4:8]) == stunMagicCookie &&
+		int(binary.BigEndian.Uint16(data[2:4]))+stunHeaderLen == len(data)
+}
+
+// stunBindingResponse builds the success response to req, reporting addr as
+// the XOR-MAPPED-ADDRESS.
+func stunBindingResponse(req []byte, addr *net.UDPAddr) []byte {
+	ip := addr.IP.To4()
+	family := byte(stunFamilyIPv4)
+	if ip == nil {
+		ip = addr.IP.To16()
+		family = stunFamilyIPv6
+	}
+
+	// XOR key: magic cookie followed by the transaction ID
+	key := make([]byte, 16)
+	copy(key, req[4:stunHeaderLen])
+
+	value := make([]byte, 4+len(ip))
+	value[1] = family
+	binary.BigEndian.PutUint16(value[2:4], uint16(addr.Port)^uint16(stunMagicCookie>>16))
+	for i := range ip {
+		value[4+i] = ip[i] ^ key[i]
+	}
+
+	res := make([]byte, stunHeaderLen+4+len(value))
+	binary.BigEndian.PutUint16(res[0:2], stunBindingSuccessResp)
+	binary.BigEndian.PutUint16(res[2:4], uint16(4+len(value)))
+	copy(res[4:stunHeaderLen], req[4:stunHeaderLen])
+	binary.BigEndian.PutUint16(res[20:22], stunXorMappedAddr)
+	binary.BigEndian.PutUint16(res[22:24], uint16(len(value)))
+	copy(res[24:], value)
+	return res
+}
@@ -0,0 +1,304 @@
+// Package kpi computes the call KPIs carriers report per route from the
+// outcome of outbound call attempts:
+//
+//   - ASR, answer-seizure ratio: answered attempts over all attempts
+//   - NER, network effectiveness ratio: attempts that reached the callee
+//     (answered, busy, declined or not answered) over all attempts, so that
+//     callee behaviour does not count against the route
+//   - ACD, average call duration: talk time over answered attempts
+//   - PDD, post-dial delay: from the INVITE to ringing, or to the answer or
+//     failure response when the callee did not ring (RFC 6076 Session
+//     Request Delay)
+//
+// Attempts are counted per minute of their start and kept for a retention
+// period; reports aggregate them into larger buckets.
+package kpi
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Resolution is the smallest bucket attempts are counted in
+const Resolution = time.Minute
+
+// DefaultRetention is how long attempts are kept unless configured
+const DefaultRetention = 24 * time.Hour
+
+// Attempt is one outbound call attempt
+type Attempt struct {
+	Route    string    // Trunk host, gateway or "user", e.g. "sip.carrier.net"
+	Started  time.Time // INVITE sent
+	Ringing  time.Time // First 180/183; zero if the callee never rang
+	Answered time.Time // 2xx; zero if not answered
+	Ended    time.Time // Final failure response, or hangup of an answered call
+	SIPCode  int       // Final response; 487 for attempts canceled by the caller
+}
+
+// effective reports whether the attempt reached the callee: it was
+// answered, or the callee was busy, declined or did not answer while
+// ringing (ITU-T E.425; the SIP codes of RFC 6076 SEER).
+func (a *Attempt) effective() bool {
+	if !a.Answered.IsZero() {
+		return true
+	}
+	switch a.SIPCode {
+	case 480, 486, 600, 603:
+		return true
+	case 408, 487:
+		return !a.Ringing.IsZero()
+	}
+	return false
+}
+
+// postDialDelay returns the attempt's post-dial delay, if it has one.
+// Attempts ended locally without a ringing or response (timeouts, caller
+// cancels) have none.
+func (a *Attempt) postDialDelay() (time.Duration, bool) {
+	switch {
+	case !a.Ringing.IsZero():
+		return a.Ringing.Sub(a.Started), true
+	case !a.Answered.IsZero():
+		return a.Answered.Sub(a.Started), true
+	case a.SIPCode >= 400 && a.SIPCode != 408 && a.SIPCode != 487:
+		return a.Ended.Sub(a.Started), true
+	}
+	return 0, false
+}
+
+// Recorders passes each attempt on to several recorders, such as the
+// collector and per-trunk routing statistics.
+type Recorders []interface{ RecordAttempt(a Attempt) }
+
+// RecordAttempt records an attempt with every recorder
+func (rs Recorders) RecordAttempt(a Attempt) {
+	for _, r := range rs {
+		r.RecordAttempt(a)
+	}
+}
+
+// KPIs are the figures of a route over a period
+type KPIs struct {
+	Attempts  int     `json:"attempts"`
+	Answered  int     `json:"answered"`
+	Effective int     `json:"effective"` // Answered, busy, declined or not answered
+	ASR       float64 `json:"asr"`       // Percent
+	NER       float64 `json:"ner"`       // Percent
+	ACD       float64 `json:"acd"`       // Seconds
+	PDD       float64 `json:"pdd_ms"`    // Milliseconds
+}
+
+// Bucket holds the KPIs of the attempts started in one period
+type Bucket struct {
+	Start time.Time `json:"start"`
+	KPIs
+}
+
+// RouteReport holds a route's KPIs over a report's whole period and per
+// bucket. Buckets without attempts are left out.
+type RouteReport struct {
+	Route   string   `json:"route"`
+	Total   KPIs     `json:"total"`
+	Buckets []Bucket `json:"buckets"`
+}
+
+// Report holds the KPIs of every route with attempts in a period
+type Report struct {
+	Since  time.Time     `json:"since"`
+	Until  time.Time     `json:"until"`
+	Bucket string        `json:"bucket"`
+	Routes []RouteReport `json:"routes"`
+}
+
+// Query selects what a report covers
+type Query struct {
+	Since  time.Duration // How far back; at most the retention
+	Bucket time.Duration // Bucket size, a multiple of Resolution
+	Route  string        // Only this route; all routes when empty
+}
+
+// counters accumulate attempts
+type counters struct {
+	attempts   int
+	answered   int
+	effective  int
+	talk       time.Duration
+	pdd        time.Duration
+	pddSamples int
+}
+
+func (c *counters) record(a *Attempt) {
+	c.attempts++
+	if !a.Answered.IsZero() {
+		c.answered++
+		if a.Ended.After(a.Answered) {
+			c.talk += a.Ended.Sub(a.Answered)
+		}
+	}
+	if a.effective() {
+		c.effective++
+	}
+	if d, ok := a.postDialDelay(); ok && d >= 0 {
+		c.pdd += d
+		c.pddSamples++
+	}
+}
+
+func (c *counters) add(o *counters) {
+	c.attempts += o.attempts
+	c.answered += o.answered
+	c.effective += o.effective
+	c.talk += o.talk
+	c.pdd += o.pdd
+	c.pddSamples += o.pddSamples
+}
+
+func (c *counters) kpis() KPIs {
+	k := KPIs{Attempts: c.attempts, Answered: c.answered, Effective: c.effective}
+	if c.attempts > 0 {
+		k.ASR = percent(c.answered, c.attempts)
+		k.NER = percent(c.effective, c.attempts)
+	}
+	if c.answered > 0 {
+		k.ACD = (c.talk / time.Duration(c.answered)).Seconds()
+	}
+	if c.pddSamples > 0 {
+		k.PDD = float64((c.pdd / time.Duration(c.pddSamples)).Milliseconds())
+	}
+	return k
+}
+
+func percent(n, total int) float64 {
+	return float64(int(float64(n)*10000/float64(total)+0.5)) / 100
+}
+
+// Collector counts attempts per route and minute.
+// Thread Safety: All methods are safe for concurrent use.
+type Collector struct {
+	retention time.Duration
+
+	mu     sync.Mutex
+	slots  map[int64]map[string]*counters // Minute (Unix seconds) -> route
+	pruned int64                          // Minute of the last pruning
+}
+
+// NewCollector creates a collector keeping attempts for retention
+// (DefaultRetention if not positive).
+func NewCollector(retention time.Duration) *Collector {
+	if retention <= 0 {
+		retention = DefaultRetention
+	}
+	return &Collector{
+		retention: retention,
+		slots:     make(map[int64]map[string]*counters),
+	}
+}
+
+// Retention returns how long attempts are kept
+func (c *Collector) Retention() time.Duration {
+	return c.retention
+}
+
+// RecordAttempt counts an attempt that ended. Attempts started before the
+// retention period are ignored.
+func (c *Collector) RecordAttempt(a Attempt) {
+	now := time.Now()
+	if a.Route == "" || a.Started.Before(now.Add(-c.retention)) {
+		return
+	}
+	slot := a.Started.Truncate(Resolution).Unix()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.prune(now)
+	routes := c.slots[slot]
+	if routes == nil {
+		routes = make(map[string]*counters)
+		c.slots[slot] = routes
+	}
+	cnt := routes[a.Route]
+	if cnt == nil {
+		cnt = &counters{}
+		routes[a.Route] = cnt
+	}
+	cnt.record(&a)
+}
+
+// prune drops the minutes past the retention, at most once a minute.
+func (c *Collector) prune(now time.Time) {
+	minute := now.Truncate(Resolution).Unix()
+	if minute == c.pruned {
+		return
+	}
+	c.pruned = minute
+	oldest := now.Add(-c.retention).Truncate(Resolution).Unix()
+	for slot := range c.slots {
+		if slot < oldest {
+			delete(c.slots, slot)
+		}
+	}
+}
+
+// Report aggregates the attempts started in the q.Since before now into
+// buckets of q.Bucket, aligned in UTC (hourly buckets start on the hour).
+func (c *Collector) Report(now time.Time, q Query) Report {
+	if q.Since <= 0 || q.Since > c.retention {
+		q.Since = c.retention
+	}
+	if q.Bucket < Resolution {
+		q.Bucket = Resolution
+	}
+	q.Bucket = q.Bucket.Truncate(Resolution)
+	since := now.Add(-q.Since)
+	first := since.Truncate(Resolution).Unix()
+
+	totals := make(map[string]*counters)
+	buckets := make(map[string]map[int64]*counters) // Route -> bucket start
+
+	c.mu.Lock()
+	for slot, routes := range c.slots {
+		if slot < first {
+			continue
+		}
+		start := time.Unix(slot, 0).Truncate(q.Bucket).Unix()
+		for route, cnt := range routes {
+			if q.Route != "" && route != q.Route {
+				continue
+			}
+			if totals[route] == nil {
+				totals[route] = &counters{}
+				buckets[route] = make(map[int64]*counters)
+			}
+			totals[route].add(cnt)
+			b := buckets[route][start]
+			if b == nil {
+				b = &counters{}
+				buckets[route][start] = b
+			}
+			b.add(cnt)
+		}
+	}
+	c.mu.Unlock()
+
+	report := Report{
+		Since:  since.UTC(),
+		Until:  now.UTC(),
+		Bucket: q.Bucket.String(),
+		Routes: make([]RouteReport, 0, len(totals)),
+	}
+	for route, total := range totals {
+		rr := RouteReport{Route: route, Total: total.kpis()}
+		for start, cnt := range buckets[route] {
+			rr.Buckets = append(rr.Buckets, Bucket{Start: time.Unix(start, 0).UTC(), KPIs: cnt.kpis()})
+		}
+		sort.Slice(rr.Buckets, func(i, j int) bool {
+			return rr.Buckets[i].Start.Before(rr.Buckets[j].Start)
+		})
+		report.Routes = append(report.Routes, rr)
+	}
+	sort.Slice(report.Routes, func(i, j int) bool {
+		return report.Routes[i].Route < report.Routes[j].Route
+	})
+	return report
+}
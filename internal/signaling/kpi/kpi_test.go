@@ -0,0 +1,51 @@
+package kpi
+
+import (
+	"testing"
+	"time"
+)
+
+func TestReportKPIs(t *testing.T) {
+	c := NewCollector(time.Hour)
+	now := time.Now().Truncate(time.Hour).Add(30 * time.Minute)
+	at := func(min int) time.Time { return now.Add(time.Duration(-min) * time.Minute) }
+
+	// Answered after 2s of ringing, 60s talk
+	c.RecordAttempt(Attempt{Route: "gateway/a", Started: at(20), Ringing: at(20).Add(2 * time.Second),
+		Answered: at(20).Add(5 * time.Second), Ended: at(19).Add(5 * time.Second), SIPCode: 200})
+	// Busy after 1s: effective, PDD 1s
+	c.RecordAttempt(Attempt{Route: "gateway/a", Started: at(10), Ended: at(10).Add(time.Second), SIPCode: 486})
+	// Carrier failure after 3s: not effective, PDD 3s
+	c.RecordAttempt(Attempt{Route: "gateway/a", Started: at(5), Ended: at(5).Add(3 * time.Second), SIPCode: 503})
+	// Canceled before ringing: no PDD, not effective
+	c.RecordAttempt(Attempt{Route: "gateway/a", Started: at(1), Ended: at(1).Add(time.Second), SIPCode: 487})
+	c.RecordAttempt(Attempt{Route: "gateway/b", Started: at(1), Ended: at(1), SIPCode: 404})
+
+	r := c.Report(now, Query{Since: time.Hour, Bucket: 15 * time.Minute, Route: "gateway/a"})
+	if len(r.Routes) != 1 || r.Routes[0].Route != "gateway/a" {
+		t.Fatalf("routes = %+v, want gateway/a only", r.Routes)
+	}
+	got := r.Routes[0].Total
+	want := KPIs{Attempts: 4, Answered: 1, Effective: 2, ASR: 25, NER: 50, ACD: 60, PDD: 2000}
+	if got != want {
+		t.Fatalf("total = %+v, want %+v", got, want)
+	}
+
+	// Started at :10, then :20, :25 and :29
+	if n := len(r.Routes[0].Buckets); n != 2 {
+		t.Fatalf("buckets = %+v, want 2", r.Routes[0].Buckets)
+	}
+	first, second := r.Routes[0].Buckets[0], r.Routes[0].Buckets[1]
+	if !first.Start.Before(second.Start) || first.Attempts != 1 || second.Attempts != 3 {
+		t.Fatalf("buckets = %+v, want 1 then 3 attempts", r.Routes[0].Buckets)
+	}
+}
+
+func TestRecordIgnoresExpiredAttempts(t *testing.T) {
+	c := NewCollector(time.Hour)
+	c.RecordAttempt(Attempt{Route: "gateway/a", Started: time.Now().Add(-2 * time.Hour), SIPCode: 503})
+	c.RecordAttempt(Attempt{Started: time.Now(), SIPCode: 503})
+	if r := c.Report(time.Now(), Query{}); len(r.Routes) != 0 {
+		t.Fatalf("routes = %+v, want none", r.Routes)
+	}
+}
@@ -5,6 +5,10 @@ import (
 	"crypto/sha256"
 	"encoding/hex"
 	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -44,6 +48,11 @@ type Binding struct {
 	// Instance ID (RFC 5626 GRUU support)
 	InstanceID string `json:"instance_id,omitempty"` // +sip.instance parameter
 
+	// SIP Outbound (RFC 5626) - flow the REGISTER arrived on
+	RegID     int    `json:"reg_id,omitempty"`     // reg-id Contact parameter
+	Outbound  bool   `json:"outbound,omitempty"`   // Requests must reuse the registration flow
+	FlowToken string `json:"flow_token,omitempty"` // Identifies the flow (transport + source address)
+
 	// Priority
 	QValue float32 `json:"q,omitempty"` // q-value for contact priority (0.0-1.0)
 
@@ -51,6 +60,7 @@ type Binding struct {
 	Expires      int       `json:"expires"`       // TTL in seconds
 	ExpiresAt    time.Time `json:"expires_at"`    // Absolute expiration time
 	RegisteredAt time.Time `json:"registered_at"` // When this binding was created/updated
+	CreatedAt    time.Time `json:"created_at"`    // When this binding was first registered
 
 	// RFC 3261 validation
 	CallID string `json:"call_id"` // Call-ID from REGISTER (for update validation)
@@ -74,6 +84,41 @@ func GenerateBindingID(contactURI, instanceID string) string {
 	return hex.EncodeToString(hash[:8]) // 16 char hex string
 }
 
+// GenerateFlowBindingID creates the binding ID for an outbound (RFC 5626)
+// registration. The instance-id and reg-id pair identifies the flow, so a
+// REGISTER over a new connection replaces the old flow instead of adding one.
+func GenerateFlowBindingID(instanceID string, regID int) string {
+	hash := sha256.Sum256([]byte(instanceID + ";reg-id=" + strconv.Itoa(regID)))
+	return hex.EncodeToString(hash[:8])
+}
+
+// GenerateFlowToken identifies the flow a request arrived on by its
+// transport and source address.
+func GenerateFlowToken(transport, ip string, port int) string {
+	hash := sha256.Sum256([]byte(strings.ToUpper(transport) + "|" + net.JoinHostPort(ip, strconv.Itoa(port))))
+	return hex.EncodeToString(hash[:8])
+}
+
+// Priority returns the binding's q-value, defaulting to 1.0 when the
+// client did not specify one (RFC 3261 Section 20.10).
+func (b *Binding) Priority() float32 {
+	if b.QValue <= 0 {
+		return 1.0
+	}
+	return b.QValue
+}
+
+// SortByPriority orders bindings most preferred first: highest q-value,
+// then most recently registered.
+func SortByPriority(bindings []*Binding) {
+	sort.SliceStable(bindings, func(i, j int) bool {
+		if pi, pj := bindings[i].Priority(), bindings[j].Priority(); pi != pj {
+			return pi > pj
+		}
+		return bindings[i].RegisteredAt.After(bindings[j].RegisteredAt)
+	})
+}
+
 // IsExpired returns true if the binding has expired
 func (b *Binding) IsExpired() bool {
 	return time.Now().After(b.ExpiresAt)
@@ -89,19 +134,25 @@ func (b *Binding) TTL() time.Duration {
 }
 
 // EffectiveContact returns the best URI to use for routing.
-// Uses received IP/port if behind NAT, otherwise Contact URI.
+// Uses received IP/port if behind NAT, otherwise Contact URI. Bindings
+// registered through a proxy (Path) always use the Contact URI.
 // Always preserves the user part from the ContactURI.
 func (b *Binding) EffectiveContact() string {
+	// Behind an edge proxy (Path), the received address is the proxy's;
+	// the request goes to the registered Contact via the Path route set
+	if len(b.Path) > 0 {
+		return b.ContactURI
+	}
+
 	// If we have received info, use received IP/port for NAT traversal
 	// but preserve the user part from ContactURI
 	if b.ReceivedIP != "" && b.ReceivedPort > 0 {
+		hostPort := net.JoinHostPort(b.ReceivedIP, strconv.Itoa(b.ReceivedPort))
 		user := extractUserFromURI(b.ContactURI)
 		if user != "" {
-			return fmt.Sprintf("sip:%s@%s:%d;transport=%s",
-				user, b.ReceivedIP, b.ReceivedPort, b.Transport)
+			return fmt.Sprintf("sip:%s@%s;transport=%s", user, hostPort, b.Transport)
 		}
-		return fmt.Sprintf("sip:%s:%d;transport=%s",
-			b.ReceivedIP, b.ReceivedPort, b.Transport)
+		return fmt.Sprintf("sip:%s;transport=%s", hostPort, b.Transport)
 	}
 	return b.ContactURI
 }
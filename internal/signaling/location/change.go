@@ -0,0 +1,29 @@
+package location
+
+// ChangeEvent is what happened to a binding, named after the contact
+// events of the reg event package (RFC 3680 section 5.3).
+type ChangeEvent string
+
+const (
+	// ChangeCreated - a new binding was registered
+	ChangeCreated ChangeEvent = "created"
+	// ChangeRefreshed - an existing binding was re-registered
+	ChangeRefreshed ChangeEvent = "refreshed"
+	// ChangeUnregistered - the client removed the binding (Expires: 0)
+	ChangeUnregistered ChangeEvent = "unregistered"
+	// ChangeExpired - the binding was not refreshed in time
+	ChangeExpired ChangeEvent = "expired"
+	// ChangeRejected - the binding was removed administratively
+	ChangeRejected ChangeEvent = "rejected"
+)
+
+// Change describes a binding change.
+type Change struct {
+	Event   ChangeEvent
+	Binding *Binding
+}
+
+// Active reports whether the binding still exists after the change.
+func (c Change) Active() bool {
+	return c.Event == ChangeCreated || c.Event == ChangeRefreshed
+}
@@ -1,6 +1,8 @@
 // Package location manages SIP user location bindings (REGISTER).
 package location
 
+import "context"
+
 // LocationStore defines the interface for SIP location/registration storage.
 // This allows for different implementations (in-memory, Redis, database, etc.)
 // and enables proper dependency injection for testing.
@@ -15,8 +17,16 @@ type LocationStore interface {
 	// Otherwise, removes only the specific binding identified by bindingID.
 	Unregister(aor string, bindingID string, isWildcard bool) error
 
-	// Lookup returns all active (non-expired) bindings for an AOR.
-	// Returns nil if no bindings exist.
+	// Evict removes a binding administratively (all bindings of the AOR
+	// if bindingID is empty).
+	Evict(aor string, bindingID string) error
+
+	// OnChange registers a callback for binding changes.
+	OnChange(fn func(Change))
+
+	// Lookup returns all active (non-expired) bindings for an AOR, ordered
+	// by q-value (highest first, default 1.0) and then by most recent
+	// registration. Returns nil if no bindings exist.
 	Lookup(aor string) []*Binding
 
 	// LookupOne returns the highest priority non-expired binding for an AOR.
@@ -42,12 +52,17 @@ type LocationStore interface {
 	// LookupByUser searches for bindings where the AOR's user part matches the given user.
 	// This is useful when the exact domain/port in the AOR is unknown.
 	// For example, LookupByUser("1000") would match "sip:1000@domain.com:5060".
+	// Results are ordered like Lookup.
 	LookupByUser(user string) []*Binding
 
 	// MinExpires returns the minimum allowed expires value in seconds.
 	// This is used for the Min-Expires header in 423 responses per RFC 3261.
 	MinExpires() int
 
+	// Ping reports whether the store can serve lookups, for readiness
+	// probes. Stores backed by an external service check it is reachable.
+	Ping(ctx context.Context) error
+
 	// Close releases resources used by the store.
 	Close()
 }
@@ -1,6 +1,7 @@
 package location
 
 import (
+	"context"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -29,6 +30,10 @@ type Store struct {
 	defaultExpires int // Default TTL in seconds
 	maxExpires     int // Maximum allowed TTL
 	minExpires     int // Minimum allowed TTL
+
+	// Change listeners (reg event package, etc.)
+	listenersMu sync.RWMutex
+	listeners   []func(Change)
 }
 
 // StoreConfig contains location store configuration
@@ -51,12 +56,45 @@ func DefaultStoreConfig() StoreConfig {
 
 // NewStore creates a new location store
 func NewStore(cfg StoreConfig) *Store {
-	return &Store{
+	s := &Store{
 		bindings:       store.NewTTLStore[string, map[string]*Binding](cfg.CleanupInterval),
 		defaultExpires: cfg.DefaultExpires,
 		maxExpires:     cfg.MaxExpires,
 		minExpires:     cfg.MinExpires,
 	}
+	s.bindings.SetOnEvict(func(_ string, bindingsMap map[string]*Binding) {
+		for _, b := range bindingsMap {
+			s.notify(Change{Event: ChangeExpired, Binding: b})
+		}
+	})
+	return s
+}
+
+// OnChange registers fn to be called after a binding is created, refreshed
+// or removed. fn runs synchronously and must not block.
+func (s *Store) OnChange(fn func(Change)) {
+	s.listenersMu.Lock()
+	defer s.listenersMu.Unlock()
+	s.listeners = append(s.listeners, fn)
+}
+
+func (s *Store) notify(c Change) {
+	s.listenersMu.RLock()
+	defer s.listenersMu.RUnlock()
+	for _, fn := range s.listeners {
+		fn(c)
+	}
+}
+
+// pruneExpired removes expired bindings from bindingsMap, reporting each.
+// Caller must hold s.mu.
+func (s *Store) pruneExpired(bindingsMap map[string]*Binding) {
+	for id, b := range bindingsMap {
+		if b.IsExpired() {
+			delete(bindingsMap, id)
+			s.notify(Change{Event: ChangeExpired, Binding: b})
+		}
+	}
 }
 
 // Register adds or updates a binding for an AOR.
@@ -88,7 +126,11 @@ func (s *Store) Register(binding *Binding) (*Binding, error) {
 
 	// Generate binding ID if not set
 	if binding.BindingID == "" {
-		binding.BindingID = GenerateBindingID(binding.ContactURI, binding.InstanceID)
+		if binding.Outbound {
+			binding.BindingID = GenerateFlowBindingID(binding.InstanceID, binding.RegID)
+		} else {
+			binding.BindingID = GenerateBindingID(binding.ContactURI, binding.InstanceID)
+		}
 	}
 
 	// Set timing
@@ -103,11 +145,17 @@ func (s *Store) Register(binding *Binding) (*Binding, error) {
 		bindingsMap = make(map[string]*Binding)
 	}
 
+	s.pruneExpired(bindingsMap)
+
 	// Check CSeq for existing binding with same Call-ID
+	event := ChangeCreated
+	binding.CreatedAt = now
 	if existing, ok := bindingsMap[binding.BindingID]; ok {
 		if !existing.ValidateCSeq(binding.CallID, binding.CSeq) {
 			return nil, fmt.Errorf("invalid CSeq: must be higher than %d for same Call-ID", existing.CSeq)
 		}
+		event = ChangeRefreshed
+		binding.CreatedAt = existing.CreatedAt
 	}
 
 	// Store the binding
@@ -130,7 +178,9 @@ func (s *Store) Register(binding *Binding) (*Binding, error) {
 		"binding_id", binding.BindingID,
 		"expires", expires,
 		"transport", binding.Transport,
+		"outbound", binding.Outbound,
 	)
+	s.notify(Change{Event: event, Binding: binding})
 
 	return binding, nil
 }
@@ -138,13 +188,27 @@ func (s *Store) Register(binding *Binding) (*Binding, error) {
 // Unregister removes a binding.
 // If bindingID is empty and contactURI is "*", removes all bindings for the AOR.
 func (s *Store) Unregister(aor string, bindingID string, isWildcard bool) error {
+	return s.remove(aor, bindingID, isWildcard, ChangeUnregistered)
+}
+
+// Evict removes a binding administratively. Subscribers to the AOR's
+// registration state see the binding as rejected.
+func (s *Store) Evict(aor string, bindingID string) error {
+	return s.remove(aor, bindingID, bindingID == "", ChangeRejected)
+}
+
+func (s *Store) remove(aor string, bindingID string, isWildcard bool, event ChangeEvent) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	if isWildcard {
 		// Remove all bindings for this AOR
+		bindingsMap, _ := s.bindings.Get(aor)
 		s.bindings.Delete(aor)
-		slog.Info("[LOCATION] Unregistered all bindings", "aor", aor)
+		for _, b := range bindingsMap {
+			s.notify(Change{Event: event, Binding: b})
+		}
+		slog.Info("[LOCATION] Unregistered all bindings", "aor", aor, "event", event)
 		return nil
 	}
 
@@ -155,11 +219,13 @@ func (s *Store) Unregister(aor string, bindingID string, isWildcard bool) error
 	}
 
 	// Remove specific binding
-	if _, ok := bindingsMap[bindingID]; !ok {
+	removed, ok := bindingsMap[bindingID]
+	if !ok {
 		return fmt.Errorf("binding not found: %s", bindingID)
 	}
 
 	delete(bindingsMap, bindingID)
+	s.pruneExpired(bindingsMap)
 
 	if len(bindingsMap) == 0 {
 		// No more bindings, remove the AOR entry
@@ -174,12 +240,13 @@ func (s *Store) Unregister(aor string, bindingID string, isWildcard bool) error
 		}
 		s.bindings.Set(aor, bindingsMap, maxTTL)
 	}
+	s.notify(Change{Event: event, Binding: removed})
 
-	slog.Info("[LOCATION] Unregistered", "aor", aor, "binding_id", bindingID)
+	slog.Info("[LOCATION] Unregistered", "aor", aor, "binding_id", bindingID, "event", event)
 	return nil
 }
 
-// Lookup returns all active bindings for an AOR
+// Lookup returns all active bindings for an AOR, most preferred first
 func (s *Store) Lookup(aor string) []*Binding {
 	bindingsMap, exists := s.bindings.Get(aor)
 	if !exists {
@@ -194,6 +261,7 @@ func (s *Store) Lookup(aor string) []*Binding {
 		}
 	}
 
+	SortByPriority(result)
 	return result
 }
 
@@ -203,23 +271,7 @@ func (s *Store) LookupOne(aor string) *Binding {
 	if len(bindings) == 0 {
 		return nil
 	}
-
-	// Find highest q-value (default is 1.0 if not specified)
-	var best *Binding
-	bestQ := float32(-1)
-
-	for _, b := range bindings {
-		q := b.QValue
-		if q == 0 {
-			q = 1.0 // RFC 3261: default q is 1.0
-		}
-		if q > bestQ {
-			bestQ = q
-			best = b
-		}
-	}
-
-	return best
+	return bindings[0]
 }
 
 // List returns all active bindings across all AORs
@@ -306,6 +358,7 @@ func (s *Store) LookupByUser(user string) []*Binding {
 		}
 	}
 
+	SortByPriority(result)
 	return result
 }
 
@@ -334,6 +387,11 @@ func extractUserFromAOR(aor string) string {
 	return s[:atIdx]
 }
 
+// Ping implements LocationStore.Ping; the in-memory store is always reachable.
+func (s *Store) Ping(ctx context.Context) error {
+	return nil
+}
+
 // Close stops the cleanup goroutine
 func (s *Store) Close() {
 	s.bindings.Close()
@@ -0,0 +1,156 @@
+// Package loopdetect stops calls that are routed back into this server
+// from circling forever.
+//
+// Three checks run on inbound INVITEs that start a call:
+//
+//   - Max-Forwards: an INVITE with no hops left is refused with 483 Too
+//     Many Hops. Outbound legs carry the caller's Max-Forwards minus one
+//     (see b2bua.WithALegInvite), so a loop through other servers runs out
+//     of hops even when nothing else catches it.
+//   - Loops: every INVITE the B2BUA sends is recorded by its Via branch.
+//     An INVITE that arrives with one of those branches in its Via headers
+//     has been here before. If its Request-URI is the one the original
+//     call arrived with, the dialplan would route it the same way again,
+//     so it is refused with 482 Loop Detected.
+//   - Spirals: a call that comes back with a different Request-URI (a
+//     dialplan rule forwarding to another number on this server, say) is
+//     a spiral and is allowed up to MaxSpirals times before 482.
+package loopdetect
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+	"github.com/sebas/switchboard/internal/signaling/store"
+)
+
+// SpiralAnnotation is the annotation holding how often a call has come
+// back to this server (see middleware.Annotation).
+const SpiralAnnotation = "Spiral"
+
+// sipgo has no constants for these
+const (
+	statusLoopDetected = 482
+	statusTooManyHops  = 483
+)
+
+// hop is an INVITE the B2BUA sent for a call.
+type hop struct {
+	inboundURI string // Request-URI the call arrived with
+	spiral     int    // Times the call had come back before this hop
+}
+
+// Detector records outbound INVITEs and recognizes them when they return.
+type Detector struct {
+	maxSpirals int
+	sentTTL    time.Duration // Lifetime of an INVITE transaction (Timer B)
+	sent       *store.TTLStore[string, hop]
+}
+
+// New creates a detector allowing maxSpirals returns per call. Sent
+// INVITEs are remembered for inviteTimeout, the lifetime of their
+// transaction (RFC 3261 Timer B).
+func New(maxSpirals int, inviteTimeout time.Duration) *Detector {
+	return &Detector{
+		maxSpirals: maxSpirals,
+		sentTTL:    inviteTimeout,
+		sent:       store.NewTTLStore[string, hop](10 * time.Second),
+	}
+}
+
+// Close stops the cleanup of recorded INVITEs.
+func (d *Detector) Close() {
+	d.sent.Close()
+}
+
+// Sent records an INVITE after it was sent. inbound is the INVITE of the
+// call it continues (nil for calls started by this server).
+func (d *Detector) Sent(invite, inbound *sip.Request) {
+	via := invite.Via()
+	if via == nil {
+		return
+	}
+	branch, ok := via.Params.Get("branch")
+	if !ok || branch == "" {
+		return
+	}
+	h := hop{}
+	if inbound != nil {
+		h.inboundURI = uriKey(inbound.Recipient)
+		h.spiral, _ = strconv.Atoi(middleware.Annotation(inbound, SpiralAnnotation))
+	}
+	d.sent.Set(branch, h, d.sentTTL)
+}
+
+// Middleware checks inbound INVITEs that start a call. Spirals that are
+// let through are annotated with their count (SpiralAnnotation).
+func (d *Detector) Middleware() middleware.Middleware {
+	return middleware.Func("loop-detect", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		if req.Method != sip.INVITE {
+			next(req, tx)
+			return
+		}
+		if to := req.To(); to != nil {
+			if _, inDialog := to.Params.Get("tag"); inDialog {
+				next(req, tx)
+				return
+			}
+		}
+
+		if mf := req.MaxForwards(); mf != nil && *mf == 0 {
+			slog.Warn("[Loop] INVITE out of hops", "call_id", callID(req), "from", req.Source())
+			middleware.Reject(req, tx, statusTooManyHops, "Too Many Hops")
+			return
+		}
+
+		if h, ok := d.returning(req); ok {
+			spiral := h.spiral + 1
+			switch {
+			case h.inboundURI != "" && h.inboundURI == uriKey(req.Recipient):
+				slog.Warn("[Loop] INVITE looped back unchanged", "call_id", callID(req), "request_uri", req.Recipient.String())
+				middleware.Reject(req, tx, statusLoopDetected, "Loop Detected")
+				return
+			case spiral > d.maxSpirals:
+				slog.Warn("[Loop] Too many spirals", "call_id", callID(req), "spirals", spiral, "max", d.maxSpirals)
+				middleware.Reject(req, tx, statusLoopDetected, "Loop Detected")
+				return
+			}
+			slog.Info("[Loop] Spiral", "call_id", callID(req), "spirals", spiral, "request_uri", req.Recipient.String())
+			middleware.Annotate(req, SpiralAnnotation, strconv.Itoa(spiral))
+		}
+		next(req, tx)
+	})
+}
+
+// returning finds the hop of an INVITE this server sent among the Via
+// headers of req.
+func (d *Detector) returning(req *sip.Request) (hop, bool) {
+	for _, hdr := range req.GetHeaders("Via") {
+		via, ok := hdr.(*sip.ViaHeader)
+		if !ok {
+			continue
+		}
+		if branch, ok := via.Params.Get("branch"); ok {
+			if h, ok := d.sent.Get(branch); ok {
+				return h, true
+			}
+		}
+	}
+	return hop{}, false
+}
+
+// uriKey identifies a Request-URI for routing: user and host, without
+// port or parameters.
+func uriKey(u sip.Uri) string {
+	return u.User + "@" + u.Host
+}
+
+func callID(req *sip.Request) string {
+	if h := req.CallID(); h != nil {
+		return h.Value()
+	}
+	return ""
+}
@@ -0,0 +1,192 @@
+package mediaclient
+
+import (
+	"sync"
+	"time"
+)
+
+// CircuitState is the state of a pool member's circuit breaker
+type CircuitState int
+
+const (
+	// CircuitClosed - member takes new sessions
+	CircuitClosed CircuitState = iota
+	// CircuitOpen - member is skipped for new sessions until its next probe
+	CircuitOpen
+	// CircuitHalfOpen - one probe session is in flight
+	CircuitHalfOpen
+)
+
+// String returns the string representation of CircuitState
+func (s CircuitState) String() string {
+	switch s {
+	case CircuitClosed:
+		return "closed"
+	case CircuitOpen:
+		return "open"
+	case CircuitHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures the per-member circuit breaker. The health
+// check only notices a node that stops answering; the breaker also takes
+// a node out of rotation when it answers but fails to create sessions.
+type BreakerConfig struct {
+	// FailureRatio of the recent CreateSession calls on a member that
+	// opens its circuit (0.5 = half failed); 0 disables the breaker
+	FailureRatio float64
+
+	// Window is how many recent CreateSession calls the ratio is taken over
+	Window int
+
+	// MinRequests is how many calls the window needs before it can open
+	MinRequests int
+
+	// OpenDuration is how long an open circuit skips the member before a
+	// new session is let through as a probe
+	OpenDuration time.Duration
+}
+
+// DefaultBreakerConfig returns sensible defaults
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureRatio: 0.5,
+		Window:       20,
+		MinRequests:  10,
+		OpenDuration: 30 * time.Second,
+	}
+}
+
+// breaker tracks the outcome of recent CreateSession calls on one member.
+// While open, the member gets no new sessions; once OpenDuration has
+// passed, one session is let through as a probe, which closes the
+// circuit if it succeeds and opens it again if not.
+type breaker struct {
+	cfg BreakerConfig
+
+	mu       sync.Mutex
+	state    CircuitState
+	results  []bool // Ring of recent outcomes, true for failures
+	next     int    // Next slot in results
+	count    int    // Outcomes in results
+	failures int    // Failures in results
+	until    time.Time
+}
+
+func newBreaker(cfg BreakerConfig) *breaker {
+	if cfg.Window <= 0 {
+		cfg.Window = 20
+	}
+	if cfg.MinRequests <= 0 || cfg.MinRequests > cfg.Window {
+		cfg.MinRequests = cfg.Window
+	}
+	return &breaker{cfg: cfg, results: make([]bool, cfg.Window)}
+}
+
+// State returns the circuit state
+func (b *breaker) State() CircuitState {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// available reports whether the member can be chosen for a new session:
+// its circuit is closed, or open and due for a probe.
+func (b *breaker) available(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state == CircuitClosed || (b.state == CircuitOpen && !now.Before(b.until))
+}
+
+// acquire claims the member for a new session. An open circuit that is
+// due for a probe turns half-open; the caller's session is the probe and
+// its outcome must be reported with record or release.
+func (b *breaker) acquire(now time.Time) bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case CircuitClosed:
+		return true
+	case CircuitOpen:
+		if now.Before(b.until) {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		return true
+	default:
+		return false
+	}
+}
+
+// record adds the outcome of a CreateSession call and returns the new
+// state and whether it changed.
+func (b *breaker) record(failed bool, now time.Time) (CircuitState, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.cfg.FailureRatio <= 0 {
+		return b.state, false
+	}
+
+	switch b.state {
+	case CircuitHalfOpen:
+		if failed {
+			b.open(now)
+		} else {
+			b.reset()
+		}
+		return b.state, true
+	case CircuitOpen:
+		// Sessions pinned to the member (bridging, migration) still run
+		// while it is open; they do not decide when it closes
+		return b.state, false
+	}
+
+	if b.count == len(b.results) && b.results[b.next] {
+		b.failures--
+	}
+	if b.count < len(b.results) {
+		b.count++
+	}
+	b.results[b.next] = failed
+	b.next = (b.next + 1) % len(b.results)
+	if failed {
+		b.failures++
+	}
+
+	if b.count >= b.cfg.MinRequests && float64(b.failures) >= b.cfg.FailureRatio*float64(b.count) {
+		b.open(now)
+		return b.state, true
+	}
+	return b.state, false
+}
+
+// release gives up a probe whose outcome says nothing about the member
+// (the caller canceled it), so that the next session probes instead.
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.state == CircuitHalfOpen {
+		b.state = CircuitOpen
+	}
+}
+
+// Ratio returns the failures and calls in the window
+func (b *breaker) Ratio() (failures, count int) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.failures, b.count
+}
+
+func (b *breaker) open(now time.Time) {
+	b.state = CircuitOpen
+	b.until = now.Add(b.cfg.OpenDuration)
+}
+
+func (b *breaker) reset() {
+	b.state = CircuitClosed
+	b.next, b.count, b.failures = 0, 0, 0
+	clear(b.results)
+}
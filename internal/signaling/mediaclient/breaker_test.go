@@ -0,0 +1,92 @@
+package mediaclient
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBreakerOpensOnFailureRatio(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureRatio: 0.5, Window: 10, MinRequests: 4, OpenDuration: time.Minute})
+	now := time.Now()
+
+	// Too few calls to judge
+	for range 3 {
+		b.record(true, now)
+	}
+	if b.State() != CircuitClosed {
+		t.Fatalf("state after 3 calls = %s, want closed", b.State())
+	}
+
+	if state, changed := b.record(false, now); !changed || state != CircuitOpen {
+		t.Fatalf("record = %s, %v; want open, true", state, changed)
+	}
+	if b.available(now) || b.acquire(now) {
+		t.Fatal("open circuit taken before its probe is due")
+	}
+}
+
+func TestBreakerWindowForgetsOldFailures(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureRatio: 0.5, Window: 4, MinRequests: 4, OpenDuration: time.Minute})
+	now := time.Now()
+
+	b.record(true, now)
+	for range 6 {
+		b.record(false, now)
+	}
+	b.record(true, now)
+	if failures, calls := b.Ratio(); failures != 1 || calls != 4 {
+		t.Fatalf("Ratio() = %d, %d; want 1, 4", failures, calls)
+	}
+	if b.State() != CircuitClosed {
+		t.Fatalf("state = %s, want closed", b.State())
+	}
+}
+
+func TestBreakerProbe(t *testing.T) {
+	b := newBreaker(BreakerConfig{FailureRatio: 0.5, Window: 2, MinRequests: 2, OpenDuration: time.Minute})
+	now := time.Now()
+	b.record(true, now)
+	b.record(true, now)
+
+	// A failed probe reopens the circuit for another OpenDuration
+	now = now.Add(time.Minute)
+	if !b.acquire(now) {
+		t.Fatal("probe not allowed after OpenDuration")
+	}
+	if b.acquire(now) {
+		t.Fatal("second probe allowed while one is in flight")
+	}
+	if state, _ := b.record(true, now); state != CircuitOpen {
+		t.Fatalf("state after failed probe = %s, want open", state)
+	}
+	if b.acquire(now.Add(time.Second)) {
+		t.Fatal("probe allowed right after a failed probe")
+	}
+
+	// A canceled probe lets the next call probe
+	now = now.Add(time.Minute)
+	b.acquire(now)
+	b.release()
+	if !b.acquire(now) {
+		t.Fatal("probe not allowed after a released probe")
+	}
+
+	// A successful probe closes it with a fresh window
+	if state, changed := b.record(false, now); !changed || state != CircuitClosed {
+		t.Fatalf("record = %s, %v; want closed, true", state, changed)
+	}
+	if failures, calls := b.Ratio(); failures != 0 || calls != 0 {
+		t.Fatalf("Ratio() after close = %d, %d; want 0, 0", failures, calls)
+	}
+}
+
+func TestBreakerDisabled(t *testing.T) {
+	b := newBreaker(BreakerConfig{})
+	now := time.Now()
+	for range 50 {
+		b.record(true, now)
+	}
+	if !b.acquire(now) {
+		t.Fatal("disabled breaker refused a call")
+	}
+}
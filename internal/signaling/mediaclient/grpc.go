@@ -2,15 +2,19 @@ package mediaclient
 
 import (
 	"context"
+	"crypto/tls"
 	"fmt"
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
 	"google.golang.org/grpc/keepalive"
+	"google.golang.org/grpc/metadata"
 
 	rtpv1 "github.com/sebas/switchboard/pkg/rtpmanager/v1"
 )
@@ -21,6 +25,13 @@ type GRPCConfig struct {
 	ConnectTimeout    time.Duration
 	KeepaliveInterval time.Duration
 	KeepaliveTimeout  time.Duration
+
+	// Owner identifies this signaling server to the RTP manager, which
+	// asks it about sessions it may have lost track of
+	Owner string
+
+	// TLS secures the connection; nil connects in plaintext
+	TLS *tls.Config
 }
 
 // DefaultGRPCConfig returns sensible defaults
@@ -40,13 +51,31 @@ type GRPCTransport struct {
 	mu            sync.RWMutex
 	ready         bool
 	callToSession map[string]string // callID -> sessionID mapping
+	owner         string
+
+	// Session IDs to report as orphaned, and of media timeouts received, on
+	// the next health check
+	orphanMu sync.Mutex
+	orphaned []string
+	acked    []string
+
+	// Port pool usage and build version from the last health check
+	totalPorts     atomic.Int32
+	allocatedPorts atomic.Int32
+	version        atomic.Value // string
+	instance       atomic.Value // string; changes when the RTP manager restarts
+	drainRequested atomic.Bool  // The RTP manager asked to be drained
 }
 
 // NewGRPCTransport creates a new gRPC transport client.
 // Uses grpc.NewClient which establishes connection lazily on first RPC.
 func NewGRPCTransport(cfg GRPCConfig) (*GRPCTransport, error) {
+	creds := insecure.NewCredentials()
+	if cfg.TLS != nil {
+		creds = credentials.NewTLS(cfg.TLS)
+	}
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(creds),
 		grpc.WithKeepaliveParams(keepalive.ClientParameters{
 			Time:                cfg.KeepaliveInterval,
 			Timeout:             cfg.KeepaliveTimeout,
@@ -66,6 +95,7 @@ func NewGRPCTransport(cfg GRPCConfig) (*GRPCTransport, error) {
 		client:        rtpv1.NewRTPManagerServiceClient(conn),
 		ready:         true,
 		callToSession: make(map[string]string),
+		owner:         cfg.Owner,
 	}
 
 	// Start connection state monitor for keepalive visibility
@@ -99,6 +129,7 @@ func (t *GRPCTransport) CreateSession(ctx context.Context, info SessionInfo) (*S
 		RemoteAddr:    info.RemoteAddr,
 		RemotePort:    int32(info.RemotePort),
 		OfferedCodecs: info.OfferedCodecs,
+		Owner:         t.owner,
 	}
 
 	resp, err := t.client.CreateSession(ctx, req)
@@ -107,6 +138,9 @@ func (t *GRPCTransport) CreateSession(ctx context.Context, info SessionInfo) (*S
 	}
 
 	if resp.Status != nil && resp.Status.State == rtpv1.SessionState_SESSION_STATE_ERROR {
+		if resp.Status.Rejected {
+			return nil, &NegotiationError{Message: resp.Status.ErrorMessage}
+		}
 		return nil, fmt.Errorf("session creation failed: %s", resp.Status.ErrorMessage)
 	}
 
@@ -152,9 +186,12 @@ func (t *GRPCTransport) DestroySession(ctx context.Context, sessionID string, re
 // PlayAudio implements Transport.PlayAudio
 func (t *GRPCTransport) PlayAudio(ctx context.Context, req PlayRequest) (<-chan PlayStatus, error) {
 	grpcReq := &rtpv1.PlayAudioRequest{
-		SessionId: req.SessionID,
-		FilePath:  req.AudioFile,
-		Loop:      req.Loop,
+		SessionId:  req.SessionID,
+		FilePath:   req.AudioFile,
+		Playlist:   req.Playlist,
+		StartMs:    int32(req.StartAt / time.Millisecond),
+		Loop:       req.Loop,
+		ReportDtmf: req.ReportDTMF,
 	}
 
 	stream, err := t.client.PlayAudio(ctx, grpcReq)
@@ -162,6 +199,44 @@ func (t *GRPCTransport) PlayAudio(ctx context.Context, req PlayRequest) (<-chan
 		return nil, fmt.Errorf("PlayAudio RPC failed: %w", err)
 	}
 
+	return playbackStatus(stream, req.SessionID, req.OnComplete), nil
+}
+
+// PlayTone implements Transport.PlayTone
+func (t *GRPCTransport) PlayTone(ctx context.Context, req ToneRequest) (<-chan PlayStatus, error) {
+	grpcReq := &rtpv1.PlayToneRequest{
+		SessionId:  req.SessionID,
+		Tone:       req.Tone,
+		Country:    req.Country,
+		DurationMs: int32(req.Duration / time.Millisecond),
+		ReportDtmf: req.ReportDTMF,
+	}
+	if p := req.DetectMachine; p != nil {
+		ms := func(d time.Duration) int32 { return int32(d / time.Millisecond) }
+		grpcReq.DetectMachine = true
+		grpcReq.MachineParams = &rtpv1.MachineDetectionParams{
+			InitialSilenceMs:       ms(p.InitialSilence),
+			GreetingMs:             ms(p.Greeting),
+			AfterGreetingSilenceMs: ms(p.AfterGreetingSilence),
+			TotalAnalysisMs:        ms(p.TotalAnalysis),
+			MinWordLengthMs:        ms(p.MinWordLength),
+			BetweenWordsSilenceMs:  ms(p.BetweenWordsSilence),
+			MaximumWords:           int32(p.MaximumWords),
+			SilenceThreshold:       int32(p.SilenceThreshold),
+		}
+	}
+
+	stream, err := t.client.PlayTone(ctx, grpcReq)
+	if err != nil {
+		return nil, fmt.Errorf("PlayTone RPC failed: %w", err)
+	}
+
+	return playbackStatus(stream, req.SessionID, nil), nil
+}
+
+// playbackStatus relays a playback event stream as PlayStatus updates.
+// The channel is closed when playback ends.
+func playbackStatus(stream grpc.ServerStreamingClient[rtpv1.PlaybackEvent], sessionID string, onComplete func(sessionID string)) <-chan PlayStatus {
 	statusCh := make(chan PlayStatus, 10)
 
 	go func() {
@@ -174,7 +249,7 @@ func (t *GRPCTransport) PlayAudio(ctx context.Context, req PlayRequest) (<-chan
 			}
 			if err != nil {
 				statusCh <- PlayStatus{
-					SessionID: req.SessionID,
+					SessionID: sessionID,
 					State:     PlayStateError,
 					Error:     err,
 				}
@@ -188,17 +263,35 @@ func (t *GRPCTransport) PlayAudio(ctx context.Context, req PlayRequest) (<-chan
 				status.State = PlayStateStarted
 			case *rtpv1.PlaybackEvent_Progress:
 				status.State = PlayStateProgress
+				status.Position = time.Duration(e.Progress.PositionMs) * time.Millisecond
+				status.Duration = time.Duration(e.Progress.DurationMs) * time.Millisecond
+				status.Paused = e.Progress.Paused
 			case *rtpv1.PlaybackEvent_Completed:
 				status.State = PlayStateCompleted
 				statusCh <- status
-				if req.OnComplete != nil {
-					req.OnComplete(req.SessionID)
+				if onComplete != nil {
+					onComplete(sessionID)
 				}
 				return
 			case *rtpv1.PlaybackEvent_Stopped:
 				status.State = PlayStateStopped
+				status.Position = time.Duration(e.Stopped.PositionMs) * time.Millisecond
 				statusCh <- status
 				return
+			case *rtpv1.PlaybackEvent_Dtmf:
+				status.State = PlayStateDTMF
+				status.Digit = e.Dtmf.Digit
+			case *rtpv1.PlaybackEvent_Machine:
+				status.State = PlayStateMachine
+				status.MachineReason = e.Machine.Reason
+				switch e.Machine.AnsweredBy {
+				case rtpv1.AnsweredBy_ANSWERED_BY_HUMAN:
+					status.AnsweredBy = AnsweredByHuman
+				case rtpv1.AnsweredBy_ANSWERED_BY_MACHINE:
+					status.AnsweredBy = AnsweredByMachine
+				default:
+					status.AnsweredBy = AnsweredByUnknown
+				}
 			case *rtpv1.PlaybackEvent_Error:
 				status.State = PlayStateError
 				status.Error = fmt.Errorf("%s: %s", e.Error.Code, e.Error.Message)
@@ -208,7 +301,125 @@ func (t *GRPCTransport) PlayAudio(ctx context.Context, req PlayRequest) (<-chan
 		}
 	}()
 
-	return statusCh, nil
+	return statusCh
+}
+
+// InjectAudio implements Transport.InjectAudio
+func (t *GRPCTransport) InjectAudio(ctx context.Context, req InjectRequest) (AudioInjector, error) {
+	encoding := rtpv1.AudioEncoding_AUDIO_ENCODING_PCM_S16LE
+	if req.Encoding == AudioEncodingPCMU {
+		encoding = rtpv1.AudioEncoding_AUDIO_ENCODING_PCMU
+	}
+
+	stream, err := t.client.InjectAudio(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("InjectAudio RPC failed: %w", err)
+	}
+
+	// First message selects the session and format
+	if err := stream.Send(&rtpv1.InjectAudioRequest{
+		SessionId:  req.SessionID,
+		Encoding:   encoding,
+		SampleRate: int32(req.SampleRate),
+	}); err != nil {
+		return nil, fmt.Errorf("InjectAudio RPC failed: %w", err)
+	}
+
+	return &grpcInjector{stream: stream, sessionID: req.SessionID}, nil
+}
+
+// grpcInjector implements AudioInjector over a client stream
+type grpcInjector struct {
+	stream    rtpv1.RTPManagerService_InjectAudioClient
+	sessionID string
+}
+
+// Write implements AudioInjector.Write
+func (i *grpcInjector) Write(data []byte) error {
+	if err := i.stream.Send(&rtpv1.InjectAudioRequest{Payload: data}); err != nil {
+		if err == io.EOF {
+			// Server ended the stream early; the real error comes from Close
+			return fmt.Errorf("injection ended by RTP manager")
+		}
+		return err
+	}
+	return nil
+}
+
+// Close implements AudioInjector.Close
+func (i *grpcInjector) Close() (*InjectResult, error) {
+	resp, err := i.stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("InjectAudio RPC failed: %w", err)
+	}
+
+	if resp.Status != nil && resp.Status.State == rtpv1.SessionState_SESSION_STATE_ERROR {
+		return nil, fmt.Errorf("injection failed: %s", resp.Status.ErrorMessage)
+	}
+
+	return &InjectResult{
+		SessionID:  i.sessionID,
+		FramesSent: int(resp.FramesSent),
+		Duration:   time.Duration(resp.DurationMs) * time.Millisecond,
+	}, nil
+}
+
+// CaptureAudio implements Transport.CaptureAudio
+func (t *GRPCTransport) CaptureAudio(ctx context.Context, req CaptureRequest) (<-chan AudioFrame, error) {
+	grpcReq := &rtpv1.CaptureAudioRequest{
+		SessionId: req.SessionID,
+		Direction: rtpv1.CaptureDirection_CAPTURE_DIRECTION_BOTH,
+		Encoding:  rtpv1.AudioEncoding_AUDIO_ENCODING_PCM_S16LE,
+	}
+	switch req.Direction {
+	case CaptureInbound:
+		grpcReq.Direction = rtpv1.CaptureDirection_CAPTURE_DIRECTION_INBOUND
+	case CaptureOutbound:
+		grpcReq.Direction = rtpv1.CaptureDirection_CAPTURE_DIRECTION_OUTBOUND
+	}
+	if req.Encoding == AudioEncodingPCMU {
+		grpcReq.Encoding = rtpv1.AudioEncoding_AUDIO_ENCODING_PCMU
+	}
+
+	stream, err := t.client.CaptureAudio(ctx, grpcReq)
+	if err != nil {
+		return nil, fmt.Errorf("CaptureAudio RPC failed: %w", err)
+	}
+
+	frameCh := make(chan AudioFrame, 50)
+
+	go func() {
+		defer close(frameCh)
+
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				if err != io.EOF && ctx.Err() == nil {
+					slog.Warn("[gRPC] CaptureAudio stream failed", "session_id", req.SessionID, "error", err)
+				}
+				return
+			}
+
+			frame := AudioFrame{
+				SessionID:      msg.SessionId,
+				Direction:      CaptureInbound,
+				SequenceNumber: uint16(msg.SequenceNumber),
+				Timestamp:      msg.Timestamp,
+				Payload:        msg.Payload,
+			}
+			if msg.Direction == rtpv1.CaptureDirection_CAPTURE_DIRECTION_OUTBOUND {
+				frame.Direction = CaptureOutbound
+			}
+
+			select {
+			case frameCh <- frame:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return frameCh, nil
 }
 
 // StopAudio implements Transport.StopAudio
@@ -221,8 +432,42 @@ func (t *GRPCTransport) StopAudio(ctx context.Context, sessionID string) error {
 	return err
 }
 
+// ControlPlayback implements Transport.ControlPlayback
+func (t *GRPCTransport) ControlPlayback(ctx context.Context, sessionID string, control PlaybackControl, offset time.Duration) (*PlaybackPosition, error) {
+	var grpcControl rtpv1.PlaybackControl
+	switch control {
+	case PlaybackPause:
+		grpcControl = rtpv1.PlaybackControl_PLAYBACK_CONTROL_PAUSE
+	case PlaybackResume:
+		grpcControl = rtpv1.PlaybackControl_PLAYBACK_CONTROL_RESUME
+	case PlaybackSeek:
+		grpcControl = rtpv1.PlaybackControl_PLAYBACK_CONTROL_SEEK
+	case PlaybackSkip:
+		grpcControl = rtpv1.PlaybackControl_PLAYBACK_CONTROL_SKIP
+	}
+
+	resp, err := t.client.ControlPlayback(ctx, &rtpv1.ControlPlaybackRequest{
+		SessionId:  sessionID,
+		Control:    grpcControl,
+		PositionMs: int32(offset / time.Millisecond),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("ControlPlayback RPC failed: %w", err)
+	}
+
+	if resp.Status != nil && resp.Status.State == rtpv1.SessionState_SESSION_STATE_ERROR {
+		return nil, fmt.Errorf("ControlPlayback failed: %s", resp.Status.ErrorMessage)
+	}
+
+	return &PlaybackPosition{
+		Position: time.Duration(resp.PositionMs) * time.Millisecond,
+		Duration: time.Duration(resp.DurationMs) * time.Millisecond,
+		Paused:   resp.Paused,
+	}, nil
+}
+
 // CreateSessionPendingRemote implements Transport.CreateSessionPendingRemote
-func (t *GRPCTransport) CreateSessionPendingRemote(ctx context.Context, callID string, codecs []string) (*SessionResult, error) {
+func (t *GRPCTransport) CreateSessionPendingRemote(ctx context.Context, callID, peerAddr string, codecs []string) (*SessionResult, error) {
 	// For B2BUA B-leg, we create a session without a remote endpoint
 	// The remote endpoint will be set later via UpdateSessionRemote
 	req := &rtpv1.CreateSessionRequest{
@@ -230,6 +475,8 @@ func (t *GRPCTransport) CreateSessionPendingRemote(ctx context.Context, callID s
 		RemoteAddr:    "", // Empty - to be set later
 		RemotePort:    0,  // Empty - to be set later
 		OfferedCodecs: codecs,
+		PeerAddr:      peerAddr,
+		Owner:         t.owner,
 	}
 
 	resp, err := t.client.CreateSession(ctx, req)
@@ -238,6 +485,9 @@ func (t *GRPCTransport) CreateSessionPendingRemote(ctx context.Context, callID s
 	}
 
 	if resp.Status != nil && resp.Status.State == rtpv1.SessionState_SESSION_STATE_ERROR {
+		if resp.Status.Rejected {
+			return nil, &NegotiationError{Message: resp.Status.ErrorMessage}
+		}
 		return nil, fmt.Errorf("session creation failed: %s", resp.Status.ErrorMessage)
 	}
 
@@ -257,9 +507,9 @@ func (t *GRPCTransport) CreateSessionPendingRemote(ctx context.Context, callID s
 
 // CreateSessionPendingRemoteOnNode implements Transport.CreateSessionPendingRemoteOnNode
 // For single transport, this just delegates to CreateSessionPendingRemote (no multi-node concept)
-func (t *GRPCTransport) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID string, codecs []string) (*SessionResult, error) {
+func (t *GRPCTransport) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID, peerAddr string, codecs []string) (*SessionResult, error) {
 	// Single transport - ignore peerSessionID, we only have one node
-	return t.CreateSessionPendingRemote(ctx, callID, codecs)
+	return t.CreateSessionPendingRemote(ctx, callID, peerAddr, codecs)
 }
 
 // UpdateSessionRemote implements Transport.UpdateSessionRemote
@@ -336,6 +586,244 @@ func (t *GRPCTransport) Ready() bool {
 	return err == nil && resp.Healthy
 }
 
+// ListSessions returns the sessions this server created on the RTP
+// manager, as checks of whether they are still in use.
+func (t *GRPCTransport) ListSessions(ctx context.Context) ([]SessionCheck, error) {
+	resp, err := t.client.ListSessions(ctx, &rtpv1.ListSessionsRequest{Owner: t.owner})
+	if err != nil {
+		return nil, fmt.Errorf("ListSessions RPC failed: %w", err)
+	}
+	sessions := make([]SessionCheck, 0, len(resp.Sessions))
+	for _, s := range resp.Sessions {
+		sessions = append(sessions, SessionCheck{
+			SessionID: s.SessionId,
+			CallID:    s.CallId,
+			Age:       time.Duration(s.AgeSeconds) * time.Second,
+		})
+	}
+	return sessions, nil
+}
+
+// ListBridges returns the bridges active on the RTP manager with their
+// forwarding counters.
+func (t *GRPCTransport) ListBridges(ctx context.Context) ([]BridgeInfo, error) {
+	resp, err := t.client.ListBridges(ctx, &rtpv1.ListBridgesRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("ListBridges RPC failed: %w", err)
+	}
+	bridges := make([]BridgeInfo, 0, len(resp.Bridges))
+	for _, b := range resp.Bridges {
+		bridges = append(bridges, BridgeInfo{
+			BridgeID:    b.BridgeId,
+			SessionAID:  b.SessionAId,
+			SessionBID:  b.SessionBId,
+			PacketsAToB: b.PacketsAToB,
+			PacketsBToA: b.PacketsBToA,
+			BytesAToB:   b.BytesAToB,
+			BytesBToA:   b.BytesBToA,
+			JitterAToB:  jitterStats(b.JitterAToB),
+			JitterBToA:  jitterStats(b.JitterBToA),
+		})
+	}
+	return bridges, nil
+}
+
+// jitterStats converts the stats of a bridge direction's jitter buffer;
+// nil when the RTP manager does not buffer
+func jitterStats(js *rtpv1.JitterBufferStats) *JitterStats {
+	if js == nil {
+		return nil
+	}
+	return &JitterStats{
+		SessionID:   js.SessionId,
+		Depth:       int(js.Depth),
+		MaxDepth:    int(js.MaxDepth),
+		TargetDelay: time.Duration(js.TargetDelayMs) * time.Millisecond,
+		Jitter:      time.Duration(js.JitterMs) * time.Millisecond,
+		Late:        js.Late,
+		Overflow:    js.Overflow,
+		Duplicates:  js.Duplicates,
+	}
+}
+
+// SubscribeEvents streams the events of the sessions this server created
+// on the RTP manager. The channel is closed when ctx is done or the stream
+// breaks.
+func (t *GRPCTransport) SubscribeEvents(ctx context.Context) (<-chan SessionEvent, error) {
+	stream, err := t.client.SubscribeEvents(ctx, &rtpv1.SubscribeEventsRequest{Owner: t.owner})
+	if err != nil {
+		return nil, fmt.Errorf("SubscribeEvents RPC failed: %w", err)
+	}
+	// The RTP manager sends headers once subscribed; one without the RPC
+	// ends the stream without any, with the error left for Recv
+	if md, err := stream.Header(); err != nil || md == nil {
+		if err == nil {
+			_, err = stream.Recv()
+		}
+		return nil, fmt.Errorf("SubscribeEvents RPC failed: %w", err)
+	}
+
+	events := make(chan SessionEvent, 64)
+	go func() {
+		defer close(events)
+		for {
+			msg, err := stream.Recv()
+			if err != nil {
+				return
+			}
+			ev, ok := sessionEvent(msg)
+			if !ok {
+				continue
+			}
+			select {
+			case events <- ev:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return events, nil
+}
+
+// sessionEvent converts a streamed event; false for unknown event types.
+func sessionEvent(msg *rtpv1.SessionEvent) (SessionEvent, bool) {
+	ev := SessionEvent{
+		SessionID: msg.SessionId,
+		CallID:    msg.CallId,
+		Time:      time.UnixMilli(msg.TimestampMs),
+	}
+	switch e := msg.Event.(type) {
+	case *rtpv1.SessionEvent_Created:
+		ev.Type = EventSessionCreated
+	case *rtpv1.SessionEvent_Destroyed:
+		ev.Type = EventSessionDestroyed
+		ev.Reason = TerminateReason(e.Destroyed.Reason)
+	case *rtpv1.SessionEvent_PlaybackFinished:
+		ev.Type = EventPlaybackFinished
+		ev.Outcome = e.PlaybackFinished.Outcome
+		ev.Position = time.Duration(e.PlaybackFinished.PositionMs) * time.Millisecond
+		ev.Error = e.PlaybackFinished.Error
+	case *rtpv1.SessionEvent_Dtmf:
+		ev.Type = EventDTMF
+		ev.Digit = e.Dtmf.Digit
+	case *rtpv1.SessionEvent_MediaTimeout:
+		ev.Type = EventMediaTimeout
+		ev.Idle = time.Duration(e.MediaTimeout.IdleSeconds) * time.Second
+	case *rtpv1.SessionEvent_QualityAlert:
+		ev.Type = EventQualityAlert
+		ev.Metric = e.QualityAlert.Metric
+		ev.Value = e.QualityAlert.Value
+		ev.Threshold = e.QualityAlert.Threshold
+	default:
+		return ev, false
+	}
+	return ev, true
+}
+
+// ReportOrphaned queues session IDs, from checks returned by Health, that
+// this server no longer uses. They are sent with the next health check.
+func (t *GRPCTransport) ReportOrphaned(sessionIDs ...string) {
+	t.orphanMu.Lock()
+	t.orphaned = append(t.orphaned, sessionIDs...)
+	t.orphanMu.Unlock()
+}
+
+// Health checks the RTP manager and returns the media timeouts of this
+// server's sessions it has detected, and the sessions it asks this server
+// about. Media timeouts are acknowledged with the next call, and returned
+// again only if that call does not reach the manager; session checks are
+// reported once. Callers must handle both even when the manager is
+// otherwise healthy.
+func (t *GRPCTransport) Health() (bool, []MediaTimeout, []SessionCheck) {
+	t.mu.RLock()
+	defer t.mu.RUnlock()
+
+	if !t.ready || t.conn == nil {
+		return false, nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Second)
+	defer cancel()
+
+	t.orphanMu.Lock()
+	orphaned, acked := t.orphaned, t.acked
+	t.orphaned = nil
+	t.orphanMu.Unlock()
+
+	var header metadata.MD
+	resp, err := t.client.Health(ctx, &rtpv1.HealthRequest{
+		Owner:              t.owner,
+		OrphanedSessionIds: orphaned,
+		AckedMediaTimeouts: acked,
+	}, grpc.Header(&header))
+	if err != nil {
+		if len(orphaned) > 0 {
+			t.ReportOrphaned(orphaned...)
+		}
+		return false, nil, nil
+	}
+	if v := header.Get(rtpv1.VersionHeader); len(v) > 0 {
+		t.version.Store(v[0])
+	}
+	if resp.InstanceId != "" {
+		t.instance.Store(resp.InstanceId)
+	}
+	t.drainRequested.Store(resp.DrainRequested)
+
+	t.totalPorts.Store(resp.TotalPorts)
+	t.allocatedPorts.Store(resp.AllocatedPorts)
+
+	var timeouts []MediaTimeout
+	acked = nil
+	for _, mt := range resp.MediaTimeouts {
+		timeouts = append(timeouts, MediaTimeout{
+			SessionID: mt.SessionId,
+			CallID:    mt.CallId,
+			Idle:      time.Duration(mt.IdleSeconds) * time.Second,
+		})
+		acked = append(acked, mt.SessionId)
+	}
+	t.orphanMu.Lock()
+	t.acked = acked
+	t.orphanMu.Unlock()
+	var checks []SessionCheck
+	for _, sc := range resp.SessionChecks {
+		checks = append(checks, SessionCheck{
+			SessionID: sc.SessionId,
+			CallID:    sc.CallId,
+			Age:       time.Duration(sc.AgeSeconds) * time.Second,
+		})
+	}
+	return resp.Healthy, timeouts, checks
+}
+
+// PortUsage returns the RTP port pool size and allocated ports reported by
+// the last health check.
+func (t *GRPCTransport) PortUsage() (total, allocated int) {
+	return int(t.totalPorts.Load()), int(t.allocatedPorts.Load())
+}
+
+// Version returns the RTP manager's build version from the last health
+// check, or "" if it did not report one.
+func (t *GRPCTransport) Version() string {
+	v, _ := t.version.Load().(string)
+	return v
+}
+
+// InstanceID returns the RTP manager's instance ID from the last health
+// check, or "" if it did not report one. It changes when the manager
+// restarts.
+func (t *GRPCTransport) InstanceID() string {
+	v, _ := t.instance.Load().(string)
+	return v
+}
+
+// DrainRequested reports whether the RTP manager asked, as of the last
+// health check, to have its sessions moved elsewhere.
+func (t *GRPCTransport) DrainRequested() bool {
+	return t.drainRequested.Load()
+}
+
 // Close implements Transport.Close
 func (t *GRPCTransport) Close() error {
 	t.mu.Lock()
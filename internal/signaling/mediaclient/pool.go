@@ -2,11 +2,17 @@ package mediaclient
 
 import (
 	"context"
+	"crypto/tls"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"sync"
 	"sync/atomic"
 	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // DrainState represents the lifecycle state of a pool member
@@ -50,6 +56,20 @@ type PoolConfig struct {
 	HealthCheckInterval time.Duration
 	UnhealthyThreshold  int // Number of failed health checks before marking unhealthy
 	HealthyThreshold    int // Number of successful health checks before marking healthy
+	Breaker             BreakerConfig
+	CreateAttempts      int // Members tried for a new session before giving up; 0 means 1
+
+	// Owner identifies this signaling server to the RTP managers (e.g.
+	// its hostname), so they can ask it about sessions it lost track of
+	Owner string
+
+	// ReconcileInterval is how often the sessions this server created on
+	// each member are compared with its calls (0 disables; needs Owner
+	// and SetSessionLiveness)
+	ReconcileInterval time.Duration
+
+	// TLS secures the connections to the members; nil connects in plaintext
+	TLS *tls.Config
 }
 
 // DefaultPoolConfig returns sensible defaults
@@ -61,6 +81,8 @@ func DefaultPoolConfig() PoolConfig {
 		HealthCheckInterval: 5 * time.Second,
 		UnhealthyThreshold:  3,
 		HealthyThreshold:    2,
+		Breaker:             DefaultBreakerConfig(),
+		CreateAttempts:      3,
 	}
 }
 
@@ -73,6 +95,14 @@ type poolMember struct {
 	drainState   atomic.Uint32 // DrainState
 	failCount    atomic.Int32
 	successCount atomic.Int32
+	sessionCount atomic.Int64 // Sessions owned, kept by sessionIndex
+	unhealthyAt  atomic.Int64 // Unix nanos when marked unhealthy, 0 while healthy
+	totalPorts   atomic.Int32 // Port pool size from the last health check
+	usedPorts    atomic.Int32 // Allocated ports from the last health check
+	version      atomic.Value // string; build version from the last health check
+	breaker      *breaker     // Skips the member while CreateSession keeps failing
+	instance     atomic.Value // string; instance ID from the last health check
+	drainAsked   atomic.Bool  // The member's drain request was passed on
 }
 
 // DrainState returns the current drain state
@@ -89,11 +119,15 @@ func (m *poolMember) SetDrainState(state DrainState) {
 type Pool struct {
 	mu             sync.RWMutex
 	members        []*poolMember
-	membersByID    map[string]*poolMember         // nodeID -> member (fast lookup)
-	sessionToNode  map[string]string              // sessionID -> nodeID (affinity)
-	nodeToSessions map[string]map[string]struct{} // nodeID -> set of sessionIDs (reverse index)
-	nextIndex      atomic.Uint64                  // for round-robin
+	membersByID    map[string]*poolMember // nodeID -> member (fast lookup)
+	sessions       *sessionIndex          // sessionID -> member (affinity), without p.mu
+	nextIndex      atomic.Uint64          // for round-robin
 	config         PoolConfig
+	onMediaTimeout func(MediaTimeout) // called for each RTP inactivity report
+	liveness       func(SessionCheck) bool
+	onRestart      func(nodeID string, sessions []string)
+	onDrainRequest func(nodeID string)
+	onEvent        func(SessionEvent)
 	stopCh         chan struct{}
 	wg             sync.WaitGroup
 }
@@ -114,12 +148,11 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 	}
 
 	p := &Pool{
-		members:        make([]*poolMember, 0, len(nodeAddresses)),
-		membersByID:    make(map[string]*poolMember, len(nodeAddresses)),
-		sessionToNode:  make(map[string]string),
-		nodeToSessions: make(map[string]map[string]struct{}),
-		config:         cfg,
-		stopCh:         make(chan struct{}),
+		members:     make([]*poolMember, 0, len(nodeAddresses)),
+		membersByID: make(map[string]*poolMember, len(nodeAddresses)),
+		sessions:    newSessionIndex(),
+		config:      cfg,
+		stopCh:      make(chan struct{}),
 	}
 
 	// Create connections to all RTP managers
@@ -127,6 +160,8 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 		ConnectTimeout:    cfg.ConnectTimeout,
 		KeepaliveInterval: cfg.KeepaliveInterval,
 		KeepaliveTimeout:  cfg.KeepaliveTimeout,
+		Owner:             cfg.Owner,
+		TLS:               cfg.TLS,
 	}
 
 	for nodeID, addr := range nodeAddresses {
@@ -138,8 +173,10 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 			member := &poolMember{
 				id:      nodeID,
 				address: addr,
+				breaker: newBreaker(cfg.Breaker),
 			}
 			member.healthy.Store(false)
+			member.unhealthyAt.Store(time.Now().UnixNano())
 			p.members = append(p.members, member)
 			p.membersByID[nodeID] = member
 			continue
@@ -149,6 +186,7 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 			id:        nodeID,
 			address:   addr,
 			transport: transport,
+			breaker:   newBreaker(cfg.Breaker),
 		}
 		member.healthy.Store(true)
 		p.members = append(p.members, member)
@@ -167,9 +205,18 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 		return nil, fmt.Errorf("no healthy RTP managers available")
 	}
 
-	// Start health checker
+	// Start health checker and event watchers
 	p.wg.Add(1)
 	go p.healthChecker()
+	for _, member := range p.members {
+		p.wg.Add(1)
+		go p.watchEvents(member)
+	}
+
+	if cfg.ReconcileInterval > 0 && cfg.Owner != "" {
+		p.wg.Add(1)
+		go p.reconciler()
+	}
 
 	slog.Info("[Pool] RTP manager pool initialized",
 		"total", len(p.members),
@@ -179,6 +226,52 @@ func NewPool(cfg PoolConfig) (*Pool, error) {
 	return p, nil
 }
 
+// SetOnMediaTimeout sets the callback invoked when an RTP manager reports
+// a session that has stopped receiving RTP. Reports arrive with health checks.
+func (p *Pool) SetOnMediaTimeout(fn func(MediaTimeout)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onMediaTimeout = fn
+}
+
+// SetOnSessionEvent sets the callback invoked for each event streamed by
+// the RTP managers (see SessionEvent). Media timeouts also go to the
+// SetOnMediaTimeout callback. fn must not block.
+func (p *Pool) SetOnSessionEvent(fn func(SessionEvent)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onEvent = fn
+}
+
+// SetOnMemberRestart sets the callback invoked when a member reports a new
+// instance ID: the RTP manager restarted and the sessions it held for this
+// server, passed to fn, are gone. fn should re-create them elsewhere and
+// must not block.
+func (p *Pool) SetOnMemberRestart(fn func(nodeID string, sessions []string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onRestart = fn
+}
+
+// SetOnDrainRequest sets the callback invoked when a member asks to be
+// drained, e.g. from a pre-stop hook. It is called once per request, and
+// only while the member is active. fn must not block.
+func (p *Pool) SetOnDrainRequest(fn func(nodeID string)) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.onDrainRequest = fn
+}
+
+// SetSessionLiveness sets the check for sessions an RTP manager asks about
+// because they were never bridged or given a remote endpoint. Sessions the
+// pool does not track are always reported as orphaned; tracked ones are
+// when fn returns false (the call that owned them is gone).
+func (p *Pool) SetSessionLiveness(fn func(SessionCheck) bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.liveness = fn
+}
+
 // healthChecker periodically checks health of all members
 func (p *Pool) healthChecker() {
 	defer p.wg.Done()
@@ -208,6 +301,7 @@ func (p *Pool) checkAllHealth() {
 			// Mark healthy after threshold consecutive successes
 			if !member.healthy.Load() && int(newSuccess) >= p.config.HealthyThreshold {
 				member.healthy.Store(true)
+				member.unhealthyAt.Store(0)
 				slog.Info("[Pool] RTP manager marked healthy", "address", member.address)
 			}
 		} else {
@@ -217,6 +311,7 @@ func (p *Pool) checkAllHealth() {
 			// Mark unhealthy after threshold consecutive failures
 			if member.healthy.Load() && int(newFail) >= p.config.UnhealthyThreshold {
 				member.healthy.Store(false)
+				member.unhealthyAt.Store(time.Now().UnixNano())
 				slog.Warn("[Pool] RTP manager marked unhealthy", "address", member.address)
 			}
 		}
@@ -232,6 +327,8 @@ func (p *Pool) checkMemberHealth(member *poolMember) bool {
 			ConnectTimeout:    p.config.ConnectTimeout,
 			KeepaliveInterval: p.config.KeepaliveInterval,
 			KeepaliveTimeout:  p.config.KeepaliveTimeout,
+			Owner:             p.config.Owner,
+			TLS:               p.config.TLS,
 		}
 		transport, err := NewGRPCTransport(grpcCfg)
 		if err != nil {
@@ -241,21 +338,280 @@ func (p *Pool) checkMemberHealth(member *poolMember) bool {
 		slog.Info("[Pool] Reconnected to RTP manager", "address", member.address)
 	}
 
-	return member.transport.Ready()
+	healthy, timeouts, checks := member.transport.Health()
+	total, allocated := member.transport.PortUsage()
+	member.totalPorts.Store(int32(total))
+	member.usedPorts.Store(int32(allocated))
+	if v := member.transport.Version(); v != "" {
+		member.version.Store(v)
+	}
+	if id := member.transport.InstanceID(); id != "" {
+		if prev, _ := member.instance.Swap(id).(string); prev != "" && prev != id {
+			p.memberRestarted(member)
+		}
+	}
+	if member.transport.DrainRequested() {
+		p.drainRequested(member)
+	} else {
+		member.drainAsked.Store(false)
+	}
+	for _, mt := range timeouts {
+		p.mediaTimedOut(member, mt)
+	}
+	if len(checks) > 0 {
+		p.answerSessionChecks(member, checks)
+	}
+	return healthy
+}
+
+// mediaTimedOut handles a media timeout reported by a member, streamed or
+// with a health check.
+func (p *Pool) mediaTimedOut(member *poolMember, mt MediaTimeout) {
+	slog.Warn("[Pool] RTP manager reported media timeout",
+		"node_id", member.id,
+		"session_id", mt.SessionID,
+		"call_id", mt.CallID,
+		"idle", mt.Idle,
+	)
+
+	p.mu.RLock()
+	fn := p.onMediaTimeout
+	p.mu.RUnlock()
+	if fn != nil {
+		fn(mt)
+	}
+}
+
+// watchEvents keeps an event stream open to a member and dispatches its
+// events until the pool is closed. Streams are reopened after a health
+// check interval; RTP managers without the stream report media timeouts
+// with health checks only.
+func (p *Pool) watchEvents(member *poolMember) {
+	defer p.wg.Done()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.stopCh
+		cancel()
+	}()
+
+	for {
+		if transport := member.transport; transport != nil && member.healthy.Load() {
+			events, err := transport.SubscribeEvents(ctx)
+			if err != nil {
+				slog.Debug("[Pool] Event stream unavailable", "node_id", member.id, "error", err)
+			} else {
+				slog.Debug("[Pool] Event stream opened", "node_id", member.id)
+				for ev := range events {
+					p.dispatchEvent(member, ev)
+				}
+				slog.Debug("[Pool] Event stream closed", "node_id", member.id)
+			}
+		}
+
+		select {
+		case <-p.stopCh:
+			return
+		case <-time.After(p.config.HealthCheckInterval):
+		}
+	}
+}
+
+// dispatchEvent hands a streamed event to the callbacks.
+func (p *Pool) dispatchEvent(member *poolMember, ev SessionEvent) {
+	ev.NodeID = member.id
+	switch ev.Type {
+	case EventMediaTimeout:
+		p.mediaTimedOut(member, MediaTimeout{SessionID: ev.SessionID, CallID: ev.CallID, Idle: ev.Idle})
+	case EventQualityAlert:
+		slog.Warn("[Pool] RTP manager reported poor media quality",
+			"node_id", member.id,
+			"session_id", ev.SessionID,
+			"call_id", ev.CallID,
+			"metric", ev.Metric,
+			"value", ev.Value,
+			"threshold", ev.Threshold,
+		)
+	}
+
+	p.mu.RLock()
+	fn := p.onEvent
+	p.mu.RUnlock()
+	if fn != nil {
+		fn(ev)
+	}
+}
+
+// memberRestarted hands the sessions a restarted member lost to the
+// restart callback.
+func (p *Pool) memberRestarted(member *poolMember) {
+	sessions := p.sessions.onMember(member)
+	slog.Warn("[Pool] RTP manager restarted, its sessions were lost",
+		"node_id", member.id,
+		"address", member.address,
+		"sessions", len(sessions),
+	)
+	if len(sessions) == 0 {
+		return
+	}
+
+	p.mu.RLock()
+	fn := p.onRestart
+	p.mu.RUnlock()
+	if fn != nil {
+		fn(member.id, sessions)
+	}
+}
+
+// drainRequested passes a member's request to be drained on to the drain
+// request callback, once.
+func (p *Pool) drainRequested(member *poolMember) {
+	if member.DrainState() != StateActive || member.drainAsked.Swap(true) {
+		return
+	}
+	slog.Info("[Pool] RTP manager requested drain",
+		"node_id", member.id,
+		"address", member.address,
+		"sessions", member.sessionCount.Load(),
+	)
+
+	p.mu.RLock()
+	fn := p.onDrainRequest
+	p.mu.RUnlock()
+	if fn != nil {
+		fn(member.id)
+	}
+}
+
+// answerSessionChecks reports the checked sessions that are no longer in
+// use back to the member, which destroys them.
+func (p *Pool) answerSessionChecks(member *poolMember, checks []SessionCheck) {
+	p.mu.RLock()
+	live := p.liveness
+	p.mu.RUnlock()
+
+	var orphaned []string
+	for _, sc := range checks {
+		owner, tracked := p.sessions.get(sc.SessionID)
+		if tracked && owner == member && (live == nil || live(sc)) {
+			continue
+		}
+		slog.Warn("[Pool] Reporting orphaned media session",
+			"node_id", member.id,
+			"session_id", sc.SessionID,
+			"call_id", sc.CallID,
+			"age", sc.Age,
+			"tracked", tracked,
+		)
+		if tracked {
+			p.untrackSession(sc.SessionID)
+		}
+		orphaned = append(orphaned, sc.SessionID)
+	}
+	if len(orphaned) > 0 {
+		member.transport.ReportOrphaned(orphaned...)
+	}
+}
+
+// reconcileMinAge keeps reconciliation away from sessions whose call is
+// still being set up and may not be known to the liveness check yet.
+const reconcileMinAge = 30 * time.Second
+
+// reconciler periodically reconciles sessions with calls
+func (p *Pool) reconciler() {
+	defer p.wg.Done()
+
+	ticker := time.NewTicker(p.config.ReconcileInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-p.stopCh:
+			return
+		case <-ticker.C:
+			p.reconcile()
+		}
+	}
+}
+
+// reconcile lists the sessions this server created on each healthy member
+// and compares them with its calls. Sessions whose call is gone are
+// destroyed; live ones the pool does not track on that member (lost
+// through a failed call or a signaling restart) are tracked again so that
+// later operations reach them.
+func (p *Pool) reconcile() {
+	p.mu.RLock()
+	live := p.liveness
+	p.mu.RUnlock()
+	if live == nil {
+		return
+	}
+
+	for _, member := range p.members {
+		if !member.healthy.Load() || member.transport == nil {
+			continue
+		}
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		sessions, err := member.transport.ListSessions(ctx)
+		if err != nil {
+			cancel()
+			slog.Warn("[Pool] Failed to list sessions for reconciliation", "node_id", member.id, "error", err)
+			continue
+		}
+
+		var destroyed, repaired int
+		for _, sc := range sessions {
+			if sc.Age < reconcileMinAge {
+				continue
+			}
+			tracked, ok := p.sessions.get(sc.SessionID)
+			if live(sc) {
+				if !ok || tracked != member {
+					p.trackSession(sc.SessionID, member)
+					repaired++
+				}
+				continue
+			}
+			if err := member.transport.DestroySession(ctx, sc.SessionID, TerminateReasonNormal); err != nil {
+				slog.Warn("[Pool] Failed to destroy orphaned session", "node_id", member.id, "session_id", sc.SessionID, "error", err)
+				continue
+			}
+			if ok && tracked == member {
+				p.untrackSession(sc.SessionID)
+			}
+			slog.Debug("[Pool] Destroyed orphaned session", "node_id", member.id, "session_id", sc.SessionID, "call_id", sc.CallID, "age", sc.Age)
+			destroyed++
+		}
+		cancel()
+
+		if destroyed > 0 || repaired > 0 {
+			slog.Info("[Pool] Reconciled sessions with RTP manager",
+				"node_id", member.id,
+				"sessions", len(sessions),
+				"destroyed", destroyed,
+				"repaired", repaired,
+			)
+		}
+	}
 }
 
 // ErrNoAvailableMembers is returned when no RTP managers are available for new sessions
 var ErrNoAvailableMembers = fmt.Errorf("no available RTP managers")
 
-// selectMember picks a healthy, active member using round-robin
-func (p *Pool) selectMember() (*poolMember, error) {
+// selectMember picks a healthy, active member using round-robin, other
+// than those already tried. The outcome of the session created on it must
+// be passed to recordResult.
+func (p *Pool) selectMember(tried ...*poolMember) (*poolMember, error) {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	// Filter to healthy, active members only (skip draining/disabled)
+	// Filter to healthy, active members only (skip draining/disabled and
+	// open circuits not yet due for a probe)
+	now := time.Now()
 	availableMembers := make([]*poolMember, 0)
 	for _, m := range p.members {
-		if m.healthy.Load() && m.transport != nil && m.DrainState() == StateActive {
+		if m.healthy.Load() && m.transport != nil && m.DrainState() == StateActive && m.breaker.available(now) && !slices.Contains(tried, m) {
 			availableMembers = append(availableMembers, m)
 		}
 	}
@@ -264,23 +620,109 @@ func (p *Pool) selectMember() (*poolMember, error) {
 		return nil, ErrNoAvailableMembers
 	}
 
-	// Round-robin selection
-	idx := p.nextIndex.Add(1) % uint64(len(availableMembers))
-	return availableMembers[idx], nil
+	// Round-robin selection; a member whose probe another call took is
+	// passed over
+	idx := p.nextIndex.Add(1)
+	for i := range uint64(len(availableMembers)) {
+		m := availableMembers[(idx+i)%uint64(len(availableMembers))]
+		if m.breaker.acquire(now) {
+			return m, nil
+		}
+	}
+	return nil, ErrNoAvailableMembers
 }
 
-// getMemberForSession returns the member that owns a session (affinity)
-func (p *Pool) getMemberForSession(sessionID string) (*poolMember, bool) {
-	p.mu.RLock()
-	nodeID, ok := p.sessionToNode[sessionID]
-	p.mu.RUnlock()
+// createOnAnyMember runs create on a selected member, failing over to the
+// next member on errors up to CreateAttempts members. Errors that are not
+// the member's fault, such as a rejected offer, are returned at once. The
+// error of the last attempt is returned, or ErrNoAvailableMembers if none
+// was made.
+func (p *Pool) createOnAnyMember(ctx context.Context, op string, create func(*poolMember) (*SessionResult, error)) (*SessionResult, *poolMember, error) {
+	attempts := max(p.config.CreateAttempts, 1)
+	var tried []*poolMember
+	var lastErr error
+	for range attempts {
+		member, err := p.selectMember(tried...)
+		if err != nil {
+			if lastErr != nil {
+				return nil, nil, lastErr
+			}
+			return nil, nil, err
+		}
+		tried = append(tried, member)
 
-	if !ok {
-		return nil, false
+		result, err := create(member)
+		p.recordResult(ctx, member, err)
+		if err == nil {
+			return result, member, nil
+		}
+		lastErr = fmt.Errorf("%s on %s failed: %w", op, member.address, err)
+		if ctx.Err() != nil || !nodeFailure(err) {
+			break
+		}
+		slog.Warn("[Pool] Session creation failed, trying next RTP manager",
+			"op", op,
+			"node_id", member.id,
+			"attempt", len(tried),
+			"error", err,
+		)
 	}
+	return nil, nil, lastErr
+}
 
-	member := p.GetMemberByID(nodeID)
-	return member, member != nil
+// recordResult feeds the outcome of a session creation to the member's
+// circuit breaker. Calls given up by the caller, and errors that are not
+// the member's fault, do not count.
+func (p *Pool) recordResult(ctx context.Context, member *poolMember, err error) {
+	if err != nil && (ctx.Err() != nil || !nodeFailure(err)) {
+		member.breaker.release()
+		return
+	}
+	if err != nil {
+		// Mark member as potentially unhealthy
+		member.failCount.Add(1)
+	}
+	state, changed := member.breaker.record(err != nil, time.Now())
+	if !changed {
+		return
+	}
+	switch state {
+	case CircuitOpen:
+		failures, calls := member.breaker.Ratio()
+		slog.Warn("[Pool] RTP manager circuit opened, skipping it for new sessions",
+			"node_id", member.id,
+			"address", member.address,
+			"failures", failures,
+			"calls", calls,
+			"probe_in", p.config.Breaker.OpenDuration,
+		)
+	case CircuitClosed:
+		slog.Info("[Pool] RTP manager circuit closed", "node_id", member.id, "address", member.address)
+	}
+}
+
+// nodeFailure reports whether a session creation error is the member's
+// fault: it could not be reached or answered in time, or it refused the
+// session for lack of resources such as free ports. Offers it rejects,
+// and calls the RPC refuses for their arguments, would fail on any member.
+func nodeFailure(err error) bool {
+	var rejected *NegotiationError
+	if errors.As(err, &rejected) {
+		return false
+	}
+	if s, ok := status.FromError(err); ok {
+		switch s.Code() {
+		case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted:
+			return true
+		}
+		return false
+	}
+	return true
+}
+
+// getMemberForSession returns the member that owns a session (affinity)
+func (p *Pool) getMemberForSession(sessionID string) (*poolMember, bool) {
+	return p.sessions.get(sessionID)
 }
 
 // GetMemberByID returns the member for a specific node ID
@@ -290,50 +732,32 @@ func (p *Pool) GetMemberByID(nodeID string) *poolMember {
 	return p.membersByID[nodeID]
 }
 
-// trackSession adds session tracking in both directions (requires lock held)
-func (p *Pool) trackSession(sessionID, nodeID string) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	p.sessionToNode[sessionID] = nodeID
-
-	if p.nodeToSessions[nodeID] == nil {
-		p.nodeToSessions[nodeID] = make(map[string]struct{})
-	}
-	p.nodeToSessions[nodeID][sessionID] = struct{}{}
+// trackSession records the member that owns a session
+func (p *Pool) trackSession(sessionID string, member *poolMember) {
+	p.sessions.add(sessionID, member)
 }
 
-// untrackSession removes session tracking in both directions (requires lock held)
+// untrackSession forgets a session
 func (p *Pool) untrackSession(sessionID string) {
-	p.mu.Lock()
-	defer p.mu.Unlock()
-
-	if nodeID, ok := p.sessionToNode[sessionID]; ok {
-		delete(p.sessionToNode, sessionID)
-		if sessions, exists := p.nodeToSessions[nodeID]; exists {
-			delete(sessions, sessionID)
-			if len(sessions) == 0 {
-				delete(p.nodeToSessions, nodeID)
-			}
-		}
-	}
+	p.sessions.remove(sessionID)
 }
 
 // SessionsOnNode returns all session IDs on a specific node
 func (p *Pool) SessionsOnNode(nodeID string) []string {
-	p.mu.RLock()
-	defer p.mu.RUnlock()
-
-	sessions, ok := p.nodeToSessions[nodeID]
-	if !ok {
+	member := p.GetMemberByID(nodeID)
+	if member == nil || member.sessionCount.Load() == 0 {
 		return nil
 	}
+	return p.sessions.onMember(member)
+}
 
-	result := make([]string, 0, len(sessions))
-	for sessionID := range sessions {
-		result = append(result, sessionID)
+// SessionNode returns the node ID of the member holding a session, or ""
+// if the session is not tracked
+func (p *Pool) SessionNode(sessionID string) string {
+	if member, ok := p.sessions.get(sessionID); ok {
+		return member.id
 	}
-	return result
+	return ""
 }
 
 // StartDrain initiates drain for a node, marking it as draining
@@ -408,12 +832,12 @@ func (p *Pool) CreateSessionOnNode(ctx context.Context, nodeID string, info Sess
 	}
 
 	result, err := member.transport.CreateSession(ctx, info)
+	p.recordResult(ctx, member, err)
 	if err != nil {
-		member.failCount.Add(1)
 		return nil, fmt.Errorf("CreateSession on %s failed: %w", member.address, err)
 	}
 
-	p.trackSession(result.SessionID, member.id)
+	p.trackSession(result.SessionID, member)
 
 	slog.Debug("[Pool] Session created on specific node",
 		"session_id", result.SessionID,
@@ -436,22 +860,18 @@ func (p *Pool) ListNodes() []string {
 	return nodes
 }
 
-// CreateSession implements Transport.CreateSession with load balancing
+// CreateSession implements Transport.CreateSession with load balancing,
+// failing over to the next member when creation fails
 func (p *Pool) CreateSession(ctx context.Context, info SessionInfo) (*SessionResult, error) {
-	member, err := p.selectMember()
+	result, member, err := p.createOnAnyMember(ctx, "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		return m.transport.CreateSession(ctx, info)
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	result, err := member.transport.CreateSession(ctx, info)
-	if err != nil {
-		// Mark member as potentially unhealthy
-		member.failCount.Add(1)
-		return nil, fmt.Errorf("CreateSession on %s failed: %w", member.address, err)
-	}
-
-	// Track session affinity (both directions)
-	p.trackSession(result.SessionID, member.id)
+	// Track session affinity
+	p.trackSession(result.SessionID, member)
 
 	slog.Debug("[Pool] Session created",
 		"session_id", result.SessionID,
@@ -471,7 +891,7 @@ func (p *Pool) DestroySession(ctx context.Context, sessionID string, reason Term
 
 	err := member.transport.DestroySession(ctx, sessionID, reason)
 
-	// Remove affinity tracking (both directions)
+	// Remove affinity tracking
 	p.untrackSession(sessionID)
 
 	return err
@@ -487,6 +907,16 @@ func (p *Pool) PlayAudio(ctx context.Context, req PlayRequest) (<-chan PlayStatu
 	return member.transport.PlayAudio(ctx, req)
 }
 
+// PlayTone implements Transport.PlayTone with affinity
+func (p *Pool) PlayTone(ctx context.Context, req ToneRequest) (<-chan PlayStatus, error) {
+	member, ok := p.getMemberForSession(req.SessionID)
+	if !ok {
+		return nil, fmt.Errorf("no RTP manager found for session %s", req.SessionID)
+	}
+
+	return member.transport.PlayTone(ctx, req)
+}
+
 // StopAudio implements Transport.StopAudio with affinity
 func (p *Pool) StopAudio(ctx context.Context, sessionID string) error {
 	member, ok := p.getMemberForSession(sessionID)
@@ -497,21 +927,48 @@ func (p *Pool) StopAudio(ctx context.Context, sessionID string) error {
 	return member.transport.StopAudio(ctx, sessionID)
 }
 
-// CreateSessionPendingRemote implements Transport.CreateSessionPendingRemote with load balancing
-func (p *Pool) CreateSessionPendingRemote(ctx context.Context, callID string, codecs []string) (*SessionResult, error) {
-	member, err := p.selectMember()
-	if err != nil {
-		return nil, err
+// ControlPlayback implements Transport.ControlPlayback with affinity
+func (p *Pool) ControlPlayback(ctx context.Context, sessionID string, control PlaybackControl, offset time.Duration) (*PlaybackPosition, error) {
+	member, ok := p.getMemberForSession(sessionID)
+	if !ok {
+		return nil, fmt.Errorf("no RTP manager found for session %s", sessionID)
+	}
+
+	return member.transport.ControlPlayback(ctx, sessionID, control, offset)
+}
+
+// InjectAudio implements Transport.InjectAudio with affinity
+func (p *Pool) InjectAudio(ctx context.Context, req InjectRequest) (AudioInjector, error) {
+	member, ok := p.getMemberForSession(req.SessionID)
+	if !ok {
+		return nil, fmt.Errorf("no RTP manager found for session %s", req.SessionID)
+	}
+
+	return member.transport.InjectAudio(ctx, req)
+}
+
+// CaptureAudio implements Transport.CaptureAudio with affinity
+func (p *Pool) CaptureAudio(ctx context.Context, req CaptureRequest) (<-chan AudioFrame, error) {
+	member, ok := p.getMemberForSession(req.SessionID)
+	if !ok {
+		return nil, fmt.Errorf("no RTP manager found for session %s", req.SessionID)
 	}
 
-	result, err := member.transport.CreateSessionPendingRemote(ctx, callID, codecs)
+	return member.transport.CaptureAudio(ctx, req)
+}
+
+// CreateSessionPendingRemote implements Transport.CreateSessionPendingRemote
+// with load balancing, failing over to the next member when creation fails
+func (p *Pool) CreateSessionPendingRemote(ctx context.Context, callID, peerAddr string, codecs []string) (*SessionResult, error) {
+	result, member, err := p.createOnAnyMember(ctx, "CreateSessionPendingRemote", func(m *poolMember) (*SessionResult, error) {
+		return m.transport.CreateSessionPendingRemote(ctx, callID, peerAddr, codecs)
+	})
 	if err != nil {
-		member.failCount.Add(1)
-		return nil, fmt.Errorf("CreateSessionPendingRemote on %s failed: %w", member.address, err)
+		return nil, err
 	}
 
-	// Track session affinity (both directions)
-	p.trackSession(result.SessionID, member.id)
+	// Track session affinity
+	p.trackSession(result.SessionID, member)
 
 	slog.Debug("[Pool] Session created (pending remote)",
 		"session_id", result.SessionID,
@@ -524,7 +981,7 @@ func (p *Pool) CreateSessionPendingRemote(ctx context.Context, callID string, co
 
 // CreateSessionPendingRemoteOnNode creates a session on the same node as a peer session.
 // Used for B2BUA B-leg to ensure both legs are on the same RTP manager for bridging.
-func (p *Pool) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID string, codecs []string) (*SessionResult, error) {
+func (p *Pool) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID, peerAddr string, codecs []string) (*SessionResult, error) {
 	// Find which node the peer session is on
 	member, ok := p.getMemberForSession(peerSessionID)
 	if !ok {
@@ -533,18 +990,18 @@ func (p *Pool) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSession
 			"peer_session_id", peerSessionID,
 			"call_id", callID,
 		)
-		return p.CreateSessionPendingRemote(ctx, callID, codecs)
+		return p.CreateSessionPendingRemote(ctx, callID, peerAddr, codecs)
 	}
 
 	// Create session on the same node
-	result, err := member.transport.CreateSessionPendingRemote(ctx, callID, codecs)
+	result, err := member.transport.CreateSessionPendingRemote(ctx, callID, peerAddr, codecs)
+	p.recordResult(ctx, member, err)
 	if err != nil {
-		member.failCount.Add(1)
 		return nil, fmt.Errorf("CreateSessionPendingRemote on %s failed: %w", member.address, err)
 	}
 
 	// Track session affinity
-	p.trackSession(result.SessionID, member.id)
+	p.trackSession(result.SessionID, member)
 
 	slog.Debug("[Pool] Session created on same node as peer",
 		"session_id", result.SessionID,
@@ -610,6 +1067,32 @@ func (p *Pool) UnbridgeMedia(ctx context.Context, bridgeID string) error {
 	return fmt.Errorf("bridge not found on any RTP manager: %s", bridgeID)
 }
 
+// ListBridges returns the bridges active on the healthy members, keyed by
+// bridge ID. Members that fail to answer are left out.
+func (p *Pool) ListBridges(ctx context.Context) map[string]BridgeInfo {
+	p.mu.RLock()
+	members := make([]*poolMember, len(p.members))
+	copy(members, p.members)
+	p.mu.RUnlock()
+
+	bridges := make(map[string]BridgeInfo)
+	for _, member := range members {
+		if member.transport == nil || !member.healthy.Load() {
+			continue
+		}
+		list, err := member.transport.ListBridges(ctx)
+		if err != nil {
+			slog.Warn("[Pool] Failed to list bridges", "node_id", member.id, "error", err)
+			continue
+		}
+		for _, b := range list {
+			b.NodeID = member.id
+			bridges[b.BridgeID] = b
+		}
+	}
+	return bridges
+}
+
 // Ready implements Transport.Ready
 func (p *Pool) Ready() bool {
 	p.mu.RLock()
@@ -650,22 +1133,24 @@ func (p *Pool) Stats() PoolStats {
 
 	stats := PoolStats{
 		TotalMembers:   len(p.members),
-		ActiveSessions: len(p.sessionToNode),
+		ActiveSessions: p.sessions.len(),
 		Members:        make([]MemberStats, 0, len(p.members)),
 	}
 
 	for _, m := range p.members {
-		sessionCount := 0
-		if sessions, ok := p.nodeToSessions[m.id]; ok {
-			sessionCount = len(sessions)
-		}
-
 		memberStats := MemberStats{
 			NodeID:       m.id,
 			Address:      m.address,
 			Healthy:      m.healthy.Load(),
 			DrainState:   m.DrainState(),
-			SessionCount: sessionCount,
+			SessionCount: int(m.sessionCount.Load()),
+			TotalPorts:   int(m.totalPorts.Load()),
+			UsedPorts:    int(m.usedPorts.Load()),
+			Circuit:      m.breaker.State(),
+		}
+		memberStats.Version, _ = m.version.Load().(string)
+		if at := m.unhealthyAt.Load(); at != 0 && !memberStats.Healthy {
+			memberStats.UnhealthySince = time.Unix(0, at)
 		}
 		if memberStats.Healthy && memberStats.DrainState == StateActive {
 			stats.HealthyMembers++
@@ -691,4 +1176,10 @@ type MemberStats struct {
 	Healthy      bool
 	DrainState   DrainState
 	SessionCount int
+	TotalPorts   int    // RTP port pool size, 0 until reported
+	UsedPorts    int    // Allocated RTP ports
+	Version      string // Build version reported by the RTP manager
+	Circuit      CircuitState
+
+	UnhealthySince time.Time // Zero unless marked unhealthy by health checks
 }
@@ -0,0 +1,163 @@
+package mediaclient
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// testPool returns a pool of healthy members that never connect.
+func testPool(attempts int, ids ...string) *Pool {
+	p := &Pool{
+		membersByID: make(map[string]*poolMember),
+		sessions:    newSessionIndex(),
+		config:      PoolConfig{Breaker: DefaultBreakerConfig(), CreateAttempts: attempts},
+	}
+	for _, id := range ids {
+		m := &poolMember{id: id, address: id + ":9090", transport: &GRPCTransport{}, breaker: newBreaker(p.config.Breaker)}
+		m.healthy.Store(true)
+		p.members = append(p.members, m)
+		p.membersByID[id] = m
+	}
+	return p
+}
+
+func TestCreateFailsOver(t *testing.T) {
+	p := testPool(3, "a", "b", "c")
+	var tried []string
+	result, member, err := p.createOnAnyMember(context.Background(), "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		tried = append(tried, m.id)
+		if len(tried) < 3 {
+			return nil, errors.New("unavailable")
+		}
+		return &SessionResult{SessionID: "s1"}, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.SessionID != "s1" || member.id != tried[2] {
+		t.Fatalf("got session %q on %s, want s1 on %s", result.SessionID, member.id, tried[2])
+	}
+	if tried[0] == tried[1] || tried[1] == tried[2] || tried[0] == tried[2] {
+		t.Fatalf("members tried = %v, want three different ones", tried)
+	}
+}
+
+func TestCreateGivesUpAfterAttempts(t *testing.T) {
+	p := testPool(2, "a", "b", "c")
+	calls := 0
+	_, _, err := p.createOnAnyMember(context.Background(), "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		calls++
+		return nil, errors.New("unavailable")
+	})
+	if err == nil || calls != 2 {
+		t.Fatalf("err = %v after %d calls; want an error after 2", err, calls)
+	}
+}
+
+func TestCreateStopsWhenCanceled(t *testing.T) {
+	p := testPool(3, "a", "b")
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	_, _, err := p.createOnAnyMember(ctx, "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		calls++
+		cancel()
+		return nil, context.Canceled
+	})
+	if !errors.Is(err, context.Canceled) || calls != 1 {
+		t.Fatalf("err = %v after %d calls; want context.Canceled after 1", err, calls)
+	}
+}
+
+func TestCreateReturnsRejectionAtOnce(t *testing.T) {
+	p := testPool(3, "a", "b", "c")
+	calls := 0
+	_, _, err := p.createOnAnyMember(context.Background(), "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		calls++
+		return nil, &NegotiationError{Message: "no supported codec offered (PCMU required)"}
+	})
+	var rejected *NegotiationError
+	if !errors.As(err, &rejected) || calls != 1 {
+		t.Fatalf("err = %v after %d calls; want a NegotiationError after 1", err, calls)
+	}
+}
+
+func TestCreateWithoutMembers(t *testing.T) {
+	p := testPool(3)
+	_, _, err := p.createOnAnyMember(context.Background(), "CreateSession", func(m *poolMember) (*SessionResult, error) {
+		t.Fatal("create called without members")
+		return nil, nil
+	})
+	if !errors.Is(err, ErrNoAvailableMembers) {
+		t.Fatalf("err = %v, want ErrNoAvailableMembers", err)
+	}
+}
+
+func TestDrainRequestedOnce(t *testing.T) {
+	p := testPool(3, "a")
+	var asked []string
+	p.SetOnDrainRequest(func(nodeID string) { asked = append(asked, nodeID) })
+
+	m := p.membersByID["a"]
+	p.drainRequested(m)
+	p.drainRequested(m)
+	if len(asked) != 1 || asked[0] != "a" {
+		t.Fatalf("drain requests passed on = %v, want [a]", asked)
+	}
+
+	// Draining members are not asked again once the request is withdrawn
+	m.drainAsked.Store(false)
+	m.SetDrainState(StateDraining)
+	p.drainRequested(m)
+	if len(asked) != 1 {
+		t.Fatalf("drain request passed on for a draining member: %v", asked)
+	}
+}
+
+// Offers a member rejects say nothing about the member: a trunk offering
+// no supported codec must not open every circuit.
+func TestCodecRejectionKeepsCircuitClosed(t *testing.T) {
+	p := testPool(1, "a")
+	m := p.members[0]
+	rejected := &NegotiationError{Message: "no supported codec offered (PCMU required)"}
+	for range 2 * p.config.Breaker.Window {
+		if !m.breaker.acquire(time.Now()) {
+			t.Fatal("member not available")
+		}
+		p.recordResult(context.Background(), m, fmt.Errorf("CreateSession on a failed: %w", rejected))
+	}
+	if m.breaker.State() != CircuitClosed || m.failCount.Load() != 0 {
+		t.Fatalf("after codec rejections: circuit %s, %d failures; want closed, 0", m.breaker.State(), m.failCount.Load())
+	}
+
+	// An RTP manager that stops answering still opens it
+	for range p.config.Breaker.MinRequests {
+		m.breaker.acquire(time.Now())
+		p.recordResult(context.Background(), m, status.Error(codes.Unavailable, "connection refused"))
+	}
+	if m.breaker.State() != CircuitOpen {
+		t.Fatalf("after unavailable errors: circuit %s, want open", m.breaker.State())
+	}
+}
+
+func TestNodeFailure(t *testing.T) {
+	for _, tc := range []struct {
+		err  error
+		want bool
+	}{
+		{errors.New("session creation failed: failed to allocate ports"), true},
+		{fmt.Errorf("CreateSession RPC failed: %w", status.Error(codes.Unavailable, "down")), true},
+		{fmt.Errorf("CreateSession RPC failed: %w", status.Error(codes.DeadlineExceeded, "slow")), true},
+		{fmt.Errorf("CreateSession RPC failed: %w", status.Error(codes.InvalidArgument, "bad")), false},
+		{&NegotiationError{Message: "no supported codec offered"}, false},
+	} {
+		if got := nodeFailure(tc.err); got != tc.want {
+			t.Errorf("nodeFailure(%v) = %v, want %v", tc.err, got, tc.want)
+		}
+	}
+}
@@ -0,0 +1,98 @@
+package mediaclient
+
+import (
+	"hash/maphash"
+	"sync"
+	"sync/atomic"
+)
+
+// sessionShards is the number of shards of a sessionIndex
+const sessionShards = 64
+
+// sessionIndex maps session IDs to the pool member that owns them. It is
+// read on every media operation, so it is split into shards by session ID
+// hash, each with its own lock, and counts sessions per member and in
+// total with atomics instead of a reverse index. Listing the sessions of
+// one member scans all shards; only drains do that.
+type sessionIndex struct {
+	seed   maphash.Seed
+	shards [sessionShards]sessionShard
+	total  atomic.Int64
+}
+
+type sessionShard struct {
+	mu       sync.RWMutex
+	sessions map[string]*poolMember
+}
+
+func newSessionIndex() *sessionIndex {
+	x := &sessionIndex{seed: maphash.MakeSeed()}
+	for i := range x.shards {
+		x.shards[i].sessions = make(map[string]*poolMember)
+	}
+	return x
+}
+
+func (x *sessionIndex) shard(sessionID string) *sessionShard {
+	return &x.shards[maphash.String(x.seed, sessionID)%sessionShards]
+}
+
+// get returns the member owning a session.
+func (x *sessionIndex) get(sessionID string) (*poolMember, bool) {
+	s := x.shard(sessionID)
+	s.mu.RLock()
+	m, ok := s.sessions[sessionID]
+	s.mu.RUnlock()
+	return m, ok
+}
+
+// add records that member owns a session.
+func (x *sessionIndex) add(sessionID string, member *poolMember) {
+	s := x.shard(sessionID)
+	s.mu.Lock()
+	prev, existed := s.sessions[sessionID]
+	s.sessions[sessionID] = member
+	s.mu.Unlock()
+
+	if existed {
+		prev.sessionCount.Add(-1)
+	} else {
+		x.total.Add(1)
+	}
+	member.sessionCount.Add(1)
+}
+
+// remove forgets a session.
+func (x *sessionIndex) remove(sessionID string) {
+	s := x.shard(sessionID)
+	s.mu.Lock()
+	m, ok := s.sessions[sessionID]
+	delete(s.sessions, sessionID)
+	s.mu.Unlock()
+
+	if ok {
+		m.sessionCount.Add(-1)
+		x.total.Add(-1)
+	}
+}
+
+// onMember returns the IDs of the sessions a member owns.
+func (x *sessionIndex) onMember(member *poolMember) []string {
+	result := make([]string, 0, member.sessionCount.Load())
+	for i := range x.shards {
+		s := &x.shards[i]
+		s.mu.RLock()
+		for sessionID, m := range s.sessions {
+			if m == member {
+				result = append(result, sessionID)
+			}
+		}
+		s.mu.RUnlock()
+	}
+	return result
+}
+
+// len returns the number of sessions.
+func (x *sessionIndex) len() int {
+	return int(x.total.Load())
+}
@@ -0,0 +1,92 @@
+package mediaclient
+
+import (
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestSessionIndex(t *testing.T) {
+	x := newSessionIndex()
+	a, b := &poolMember{id: "a"}, &poolMember{id: "b"}
+
+	for i := range 10 {
+		x.add("a-"+strconv.Itoa(i), a)
+	}
+	x.add("b-0", b)
+	x.add("a-0", b) // Re-tracked on another member (migration)
+
+	if m, ok := x.get("a-0"); !ok || m != b {
+		t.Fatalf("get(a-0) = %v, %v; want member b", m, ok)
+	}
+	if n := a.sessionCount.Load(); n != 9 {
+		t.Fatalf("member a has %d sessions, want 9", n)
+	}
+	if n := len(x.onMember(b)); n != 2 {
+		t.Fatalf("onMember(b) returned %d sessions, want 2", n)
+	}
+
+	x.remove("b-0")
+	x.remove("b-0")
+	if n := b.sessionCount.Load(); n != 1 {
+		t.Fatalf("member b has %d sessions, want 1", n)
+	}
+	if n := x.len(); n != 10 {
+		t.Fatalf("len() = %d, want 10", n)
+	}
+}
+
+// mutexIndex is the single-lock index sessionIndex replaced, for comparison.
+type mutexIndex struct {
+	mu       sync.RWMutex
+	sessions map[string]*poolMember
+}
+
+func (x *mutexIndex) get(sessionID string) (*poolMember, bool) {
+	x.mu.RLock()
+	defer x.mu.RUnlock()
+	m, ok := x.sessions[sessionID]
+	return m, ok
+}
+
+func (x *mutexIndex) add(sessionID string, member *poolMember) {
+	x.mu.Lock()
+	defer x.mu.Unlock()
+	x.sessions[sessionID] = member
+}
+
+// Every media operation looks up its session; a call adds one.
+func benchmarkIndex(b *testing.B, add func(string, *poolMember), get func(string) (*poolMember, bool)) {
+	const sessions = 20000
+	member := &poolMember{id: "rtpmanager-0"}
+	ids := make([]string, sessions)
+	for i := range ids {
+		ids[i] = "session-" + strconv.Itoa(i)
+		add(ids[i], member)
+	}
+	var next atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(7919))
+		for pb.Next() {
+			i++
+			id := ids[i%sessions]
+			if i%20 == 0 {
+				add(id, member)
+			} else {
+				get(id)
+			}
+		}
+	})
+}
+
+func BenchmarkMutexSessionIndex(b *testing.B) {
+	x := &mutexIndex{sessions: make(map[string]*poolMember)}
+	benchmarkIndex(b, x.add, x.get)
+}
+
+func BenchmarkShardedSessionIndex(b *testing.B) {
+	x := newSessionIndex()
+	benchmarkIndex(b, x.add, x.get)
+}
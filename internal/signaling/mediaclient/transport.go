@@ -2,6 +2,7 @@ package mediaclient
 
 import (
 	"context"
+	"time"
 )
 
 // SessionInfo contains parameters for creating a media session
@@ -21,14 +22,64 @@ type SessionResult struct {
 	SelectedCodec string // Negotiated codec
 }
 
+// NegotiationError is returned when an RTP manager rejects the offer of a
+// session, e.g. because it names no codec the RTP manager handles. The
+// RTP manager is not at fault, and any other would reject it too.
+type NegotiationError struct {
+	Message string
+}
+
+// Error returns the error message.
+func (e *NegotiationError) Error() string {
+	return "session rejected: " + e.Message
+}
+
 // PlayRequest contains audio playback parameters
 type PlayRequest struct {
 	SessionID  string
 	AudioFile  string
+	Playlist   []string      // Further files played gaplessly after AudioFile
+	StartAt    time.Duration // Offset into the audio, e.g. a position reported by a stop
 	Loop       bool
+	ReportDTMF bool                   // Report digits the remote party presses (PlayStateDTMF)
 	OnComplete func(sessionID string) // Called when playback completes
 }
 
+// ToneRequest contains generated tone parameters
+type ToneRequest struct {
+	SessionID  string
+	Tone       string        // Plan tone name ("ringback", "busy", ...), "dtmf:<digits>" or custom spec
+	Country    string        // Tone plan; the RTP manager default if empty
+	Duration   time.Duration // 0 repeats cadenced tones until stopped
+	ReportDTMF bool          // Report digits the remote party presses (PlayStateDTMF)
+
+	// DetectMachine reports whether a person or an answering machine
+	// answered, from the remote party's greeting (PlayStateMachine)
+	DetectMachine *MachineDetectionParams
+}
+
+// MachineDetectionParams tunes answering machine detection; zero fields
+// take the RTP manager's defaults
+type MachineDetectionParams struct {
+	InitialSilence       time.Duration // Silence before any voice that makes the call a machine (2.5s)
+	Greeting             time.Duration // Voice longer than this makes the call a machine (1.5s)
+	AfterGreetingSilence time.Duration // Silence after the greeting that makes the call a person (800ms)
+	TotalAnalysis        time.Duration // Analysis time after which no decision is made (5s)
+	MinWordLength        time.Duration // Shortest voice counted as a word (100ms)
+	BetweenWordsSilence  time.Duration // Silence that separates words (50ms)
+	MaximumWords         int           // Words that make the call a machine (3)
+	SilenceThreshold     int           // Average 16-bit sample magnitude below which audio is silence (256)
+}
+
+// AnsweredBy is the result of answering machine detection
+type AnsweredBy string
+
+const (
+	AnsweredByHuman   AnsweredBy = "human"
+	AnsweredByMachine AnsweredBy = "machine"
+	AnsweredByUnknown AnsweredBy = "unknown" // No decision within the analysis time
+)
+
 // PlayState represents the state of playback
 type PlayState int
 
@@ -38,13 +89,99 @@ const (
 	PlayStateCompleted
 	PlayStateStopped
 	PlayStateError
+	PlayStateDTMF    // A digit was received; playback continues
+	PlayStateMachine // Machine detection decided; playback continues
 )
 
 // PlayStatus represents playback progress
 type PlayStatus struct {
 	SessionID string
 	State     PlayState
+	Position  time.Duration // Set on progress and stopped updates
+	Duration  time.Duration // Set on progress updates
+	Paused    bool
+	Digit     string // Set on DTMF updates
 	Error     error
+
+	// Set on machine detection updates
+	AnsweredBy    AnsweredBy
+	MachineReason string // What decided it, e.g. "long_greeting"
+}
+
+// PlaybackControl selects a playback control operation
+type PlaybackControl int
+
+const (
+	PlaybackQuery  PlaybackControl = iota // Report position only
+	PlaybackPause                         // Suspend playback
+	PlaybackResume                        // Continue paused playback
+	PlaybackSeek                          // Jump to an absolute offset
+	PlaybackSkip                          // Jump relative to the current position
+)
+
+// PlaybackPosition reports where an active playback is
+type PlaybackPosition struct {
+	Position time.Duration
+	Duration time.Duration
+	Paused   bool
+}
+
+// AudioEncoding identifies the format of injected audio
+type AudioEncoding int
+
+const (
+	AudioEncodingPCM16 AudioEncoding = iota // Signed 16-bit little-endian mono PCM
+	AudioEncodingPCMU                       // G.711 µ-law at 8000 Hz
+)
+
+// InjectRequest contains live audio injection parameters
+type InjectRequest struct {
+	SessionID  string
+	Encoding   AudioEncoding
+	SampleRate int // PCM sample rate in Hz (default 8000)
+}
+
+// InjectResult summarizes a completed injection
+type InjectResult struct {
+	SessionID  string
+	FramesSent int
+	Duration   time.Duration
+}
+
+// AudioInjector streams live audio (e.g. TTS output) into a session.
+// Audio is played out in real time; Write blocks when the remote buffer is full.
+type AudioInjector interface {
+	// Write sends a chunk of audio in the requested encoding
+	Write(data []byte) error
+
+	// Close ends the stream and waits until all audio has been played
+	Close() (*InjectResult, error)
+}
+
+// CaptureDirection selects which audio to capture relative to a session
+type CaptureDirection int
+
+const (
+	CaptureBoth     CaptureDirection = iota
+	CaptureInbound                   // Audio received from the session's remote party
+	CaptureOutbound                  // Audio sent to the session's remote party
+)
+
+// CaptureRequest contains live audio capture parameters.
+// Capture is available while the session is bridged.
+type CaptureRequest struct {
+	SessionID string
+	Direction CaptureDirection
+	Encoding  AudioEncoding // PCM16 (decoded) or PCMU (raw payload)
+}
+
+// AudioFrame is one captured frame of audio
+type AudioFrame struct {
+	SessionID      string
+	Direction      CaptureDirection // CaptureInbound or CaptureOutbound
+	SequenceNumber uint16           // RTP sequence number of the source packet
+	Timestamp      uint32           // RTP timestamp of the source packet
+	Payload        []byte
 }
 
 // TerminateReason indicates why a session was terminated
@@ -63,6 +200,82 @@ type BridgeInfo struct {
 	BridgeID   string
 	SessionAID string
 	SessionBID string
+	NodeID     string // RTP manager forwarding the bridge's media
+
+	// Forwarded packets and bytes in each direction
+	PacketsAToB int64
+	PacketsBToA int64
+	BytesAToB   int64
+	BytesBToA   int64
+
+	// Jitter buffer of each direction (nil when buffering is disabled)
+	JitterAToB *JitterStats
+	JitterBToA *JitterStats
+}
+
+// JitterStats describes the jitter buffer of one direction of a bridge
+type JitterStats struct {
+	SessionID   string // Session whose received packets are buffered
+	Depth       int    // Packets currently buffered
+	MaxDepth    int
+	TargetDelay time.Duration
+	Jitter      time.Duration // RFC 3550 interarrival jitter estimate
+	Late        int64         // Dropped for arriving after their playout slot
+	Overflow    int64         // Dropped because the buffer was full
+	Duplicates  int64
+}
+
+// MediaTimeout reports a session that stopped receiving RTP.
+// Raised by the RTP manager's inactivity monitor and delivered on health checks.
+type MediaTimeout struct {
+	SessionID string
+	CallID    string
+	Idle      time.Duration // How long the session had been silent when detected
+}
+
+// SessionEventType identifies a SessionEvent
+type SessionEventType string
+
+const (
+	EventSessionCreated   SessionEventType = "session_created"
+	EventSessionDestroyed SessionEventType = "session_destroyed"
+	EventPlaybackFinished SessionEventType = "playback_finished"
+	EventDTMF             SessionEventType = "dtmf"
+	EventMediaTimeout     SessionEventType = "media_timeout"
+	EventQualityAlert     SessionEventType = "quality_alert"
+)
+
+// SessionEvent is an event streamed by an RTP manager as it happens.
+// Only the fields of its Type are set.
+type SessionEvent struct {
+	Type      SessionEventType
+	NodeID    string // RTP manager it came from; set by the pool
+	SessionID string
+	CallID    string
+	Time      time.Time
+
+	Reason   TerminateReason // EventSessionDestroyed
+	Outcome  string          // EventPlaybackFinished: completed, stopped or error
+	Position time.Duration   // EventPlaybackFinished
+	Error    string          // EventPlaybackFinished
+	Digit    string          // EventDTMF
+	Idle     time.Duration   // EventMediaTimeout
+
+	// EventQualityAlert: the metric ("jitter", in milliseconds), its value
+	// and the threshold it crossed
+	Metric    string
+	Value     float64
+	Threshold float64
+}
+
+// SessionCheck asks whether a session that was never bridged or given a
+// remote endpoint is still in use. Raised by the RTP manager's orphan
+// reaper and delivered on health checks; sessions reported back as
+// orphaned are destroyed.
+type SessionCheck struct {
+	SessionID string
+	CallID    string
+	Age       time.Duration
 }
 
 // StatsProvider provides pool statistics (optional interface)
@@ -78,11 +291,13 @@ type Transport interface {
 
 	// CreateSessionPendingRemote allocates resources without remote endpoint.
 	// Used for B2BUA B-leg where remote is set later via UpdateSessionRemote.
-	CreateSessionPendingRemote(ctx context.Context, callID string, codecs []string) (*SessionResult, error)
+	// peerAddr (the SIP destination) lets the RTP manager choose which
+	// address to advertise; it may be empty.
+	CreateSessionPendingRemote(ctx context.Context, callID, peerAddr string, codecs []string) (*SessionResult, error)
 
 	// CreateSessionPendingRemoteOnNode creates a session on the same node as another session.
 	// Used for B2BUA B-leg to ensure bridging is possible (both legs on same RTP manager).
-	CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID string, codecs []string) (*SessionResult, error)
+	CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID, peerAddr string, codecs []string) (*SessionResult, error)
 
 	// UpdateSessionRemote updates the remote endpoint for a session.
 	// Used when SDP answer arrives after session creation (B2BUA scenario).
@@ -94,9 +309,25 @@ type Transport interface {
 	// PlayAudio streams audio, returning a channel for status updates
 	PlayAudio(ctx context.Context, req PlayRequest) (<-chan PlayStatus, error)
 
+	// PlayTone plays a generated tone, returning a channel for status updates.
+	// StopAudio ends it.
+	PlayTone(ctx context.Context, req ToneRequest) (<-chan PlayStatus, error)
+
 	// StopAudio cancels ongoing playback
 	StopAudio(ctx context.Context, sessionID string) error
 
+	// ControlPlayback pauses, resumes or seeks ongoing playback.
+	// offset is used by PlaybackSeek and PlaybackSkip.
+	ControlPlayback(ctx context.Context, sessionID string, control PlaybackControl, offset time.Duration) (*PlaybackPosition, error)
+
+	// InjectAudio opens a live audio stream into a session.
+	// StopAudio also cancels an active injection.
+	InjectAudio(ctx context.Context, req InjectRequest) (AudioInjector, error)
+
+	// CaptureAudio streams audio flowing through a bridged session.
+	// The channel is closed when the bridge ends or ctx is canceled.
+	CaptureAudio(ctx context.Context, req CaptureRequest) (<-chan AudioFrame, error)
+
 	// BridgeMedia connects two sessions for bidirectional RTP relay.
 	// Returns a bridge ID for later unbridging.
 	BridgeMedia(ctx context.Context, sessionAID, sessionBID string) (string, error)
@@ -0,0 +1,132 @@
+// Package metrics pushes signaling server metrics to a StatsD agent.
+//
+// Call metrics are derived from the events Hub; RTP manager pool gauges
+// are sampled on an interval.
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/statsd"
+)
+
+// StatsSource reports RTP manager pool health; *mediaclient.Pool implements it.
+type StatsSource interface {
+	Stats() mediaclient.PoolStats
+}
+
+// Reporter sends call counters and timings as events arrive, and RTP
+// manager pool gauges every interval.
+type Reporter struct {
+	client   *statsd.Client
+	pool     StatsSource
+	interval time.Duration
+}
+
+// NewReporter creates a reporter. interval defaults to 10 seconds.
+func NewReporter(client *statsd.Client, pool StatsSource, interval time.Duration) *Reporter {
+	if interval <= 0 {
+		interval = 10 * time.Second
+	}
+	return &Reporter{client: client, pool: pool, interval: interval}
+}
+
+// Run reports until ctx is done.
+func (r *Reporter) Run(ctx context.Context, hub *events.Hub) {
+	sub := hub.Subscribe(1000)
+	defer sub.Close()
+
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	var dropped int64
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case ev, ok := <-sub.Events():
+			if !ok {
+				return
+			}
+			r.Event(ev)
+		case <-ticker.C:
+			r.Sample()
+			if d := sub.Dropped(); d > dropped {
+				r.client.Count("metrics.events_dropped", d-dropped)
+				dropped = d
+			}
+		}
+	}
+}
+
+// Event records the metrics carried by a call event.
+func (r *Reporter) Event(ev events.Event) {
+	switch e := ev.(type) {
+	case *events.CallReceivedEvent:
+		r.client.Incr("calls.received", "direction:"+string(e.Direction))
+	case *events.CallAnsweredEvent:
+		r.client.Incr("calls.answered", leg(e.Leg))
+		r.client.Timing("calls.setup_time", ms(e.SetupDurationMs), leg(e.Leg))
+		if e.RingDurationMs > 0 {
+			r.client.Timing("calls.ring_time", ms(e.RingDurationMs), leg(e.Leg))
+		}
+	case *events.CallEndedEvent:
+		tags := []string{leg(e.Leg), "disposition:" + e.DispositionCode, "reason:" + string(e.EndReason)}
+		r.client.Incr("calls.ended", tags...)
+		if e.TalkDurationMs > 0 {
+			r.client.Timing("calls.talk_time", ms(e.TalkDurationMs), leg(e.Leg))
+		}
+		if e.PacketsReceived > 0 || e.PacketsLost > 0 {
+			r.client.Count("media.packets_received", int64(e.PacketsReceived), leg(e.Leg))
+			r.client.Count("media.packets_sent", int64(e.PacketsSent), leg(e.Leg))
+			r.client.Count("media.packets_lost", int64(e.PacketsLost), leg(e.Leg))
+			r.client.Timing("media.jitter", ms(int64(e.JitterMs)), leg(e.Leg))
+		}
+	case *events.CallEmergencyEvent:
+		r.client.Incr("calls.emergency")
+	}
+}
+
+// Sample reports RTP manager pool gauges.
+func (r *Reporter) Sample() {
+	stats := r.pool.Stats()
+	r.client.Gauge("pool.members", float64(stats.TotalMembers))
+	r.client.Gauge("pool.members_healthy", float64(stats.HealthyMembers))
+	r.client.Gauge("pool.sessions", float64(stats.ActiveSessions))
+
+	var used, total int
+	for _, m := range stats.Members {
+		used += m.UsedPorts
+		total += m.TotalPorts
+		if r.client.Tagged() {
+			node := "rtp_node:" + m.NodeID
+			r.client.Gauge("pool.node.sessions", float64(m.SessionCount), node)
+			r.client.Gauge("pool.node.ports_used", float64(m.UsedPorts), node)
+			r.client.Gauge("pool.node.ports_total", float64(m.TotalPorts), node)
+			r.client.Gauge("pool.node.healthy", boolGauge(m.Healthy), node)
+		}
+	}
+	r.client.Gauge("pool.ports_used", float64(used))
+	r.client.Gauge("pool.ports_total", float64(total))
+}
+
+func leg(l events.LegRole) string {
+	if l == "" {
+		return "leg:A"
+	}
+	return "leg:" + string(l)
+}
+
+func ms(n int64) time.Duration {
+	return time.Duration(n) * time.Millisecond
+}
+
+func boolGauge(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
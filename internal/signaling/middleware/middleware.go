@@ -0,0 +1,187 @@
+// Package middleware runs pre-routing hooks on inbound SIP requests.
+//
+// Middleware sees every request before the method handler (REGISTER,
+// INVITE, BYE, ...) and may modify it, annotate it for later stages,
+// reject it by responding, or pass it on by calling next. Middleware runs
+// in the order it was added; the first added is the outermost:
+//
+//	chain := middleware.NewChain()
+//	chain.Use(middleware.Recover(), middleware.Func("tenant", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+//		if req.Method == sip.INVITE && req.GetHeader("X-Tenant") == nil {
+//			middleware.Reject(req, tx, sip.StatusForbidden, "Forbidden - tenant required")
+//			return
+//		}
+//		next(req, tx)
+//	}))
+//	srv.OnRequest(sip.INVITE, chain.Wrap(handleINVITE))
+//
+// ACK has no transaction to respond on: middleware that stops an ACK
+// simply drops it.
+package middleware
+
+import (
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strings"
+	"sync"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// AnnotationPrefix starts the names of the headers Annotate sets.
+const AnnotationPrefix = "X-Switchboard-"
+
+// Handler handles an inbound SIP request, as registered with sipgo.Server.OnRequest.
+type Handler = sipgo.RequestHandler
+
+// Middleware is a pre-routing hook for inbound SIP requests.
+type Middleware interface {
+	// Name identifies the middleware in logs.
+	Name() string
+
+	// Handle processes the request and calls next to continue, or
+	// responds on tx (see Reject) without calling next to stop it.
+	Handle(req *sip.Request, tx sip.ServerTransaction, next Handler)
+}
+
+// funcMiddleware adapts a function to Middleware.
+type funcMiddleware struct {
+	name string
+	fn   func(req *sip.Request, tx sip.ServerTransaction, next Handler)
+}
+
+func (m *funcMiddleware) Name() string { return m.name }
+
+func (m *funcMiddleware) Handle(req *sip.Request, tx sip.ServerTransaction, next Handler) {
+	m.fn(req, tx, next)
+}
+
+// Func returns a middleware running fn.
+func Func(name string, fn func(req *sip.Request, tx sip.ServerTransaction, next Handler)) Middleware {
+	return &funcMiddleware{name: name, fn: fn}
+}
+
+// Chain is an ordered list of middleware. Safe for concurrent use;
+// middleware added with Use applies to the next request.
+type Chain struct {
+	mu          sync.RWMutex
+	middlewares []Middleware
+}
+
+// NewChain creates an empty chain.
+func NewChain() *Chain {
+	return &Chain{}
+}
+
+// Use appends middleware to the chain.
+func (c *Chain) Use(middlewares ...Middleware) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.middlewares = append(c.middlewares, middlewares...)
+}
+
+// Names returns the names of the middleware in order.
+func (c *Chain) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	names := make([]string, len(c.middlewares))
+	for i, m := range c.middlewares {
+		names[i] = m.Name()
+	}
+	return names
+}
+
+// Wrap returns a handler that runs the chain, then h. Annotation headers
+// sent by the client are removed first, so only middleware can set them.
+func (c *Chain) Wrap(h Handler) Handler {
+	return func(req *sip.Request, tx sip.ServerTransaction) {
+		stripAnnotations(req)
+
+		c.mu.RLock()
+		middlewares := c.middlewares
+		c.mu.RUnlock()
+		run(middlewares, h, req, tx)
+	}
+}
+
+// run calls the first middleware with a next that runs the rest.
+func run(middlewares []Middleware, h Handler, req *sip.Request, tx sip.ServerTransaction) {
+	if len(middlewares) == 0 {
+		h(req, tx)
+		return
+	}
+	middlewares[0].Handle(req, tx, func(req *sip.Request, tx sip.ServerTransaction) {
+		run(middlewares[1:], h, req, tx)
+	})
+}
+
+// Reject responds to the request with a final status. It does nothing
+// for ACK, which cannot be answered.
+func Reject(req *sip.Request, tx sip.ServerTransaction, status sip.StatusCode, reason string) {
+	if req.IsAck() || tx == nil {
+		return
+	}
+	res := sip.NewResponseFromRequest(req, status, reason, nil)
+	if err := tx.Respond(res); err != nil {
+		slog.Warn("[Middleware] Failed to send rejection", "method", req.Method, "status", status, "error", err)
+	}
+}
+
+// Annotate sets the header AnnotationPrefix+name on the request, replacing
+// an earlier value. Handlers and the dialplan read annotations as headers
+// (Annotation, or call.header() in scripts); they are not copied to
+// outbound legs.
+func Annotate(req *sip.Request, name, value string) {
+	header := AnnotationPrefix + name
+	req.RemoveHeader(header)
+	req.AppendHeader(sip.NewHeader(header, value))
+}
+
+// stripAnnotations removes annotation headers from a request.
+func stripAnnotations(req *sip.Request) {
+	var names []string
+	for _, h := range req.Headers() {
+		if len(h.Name()) > len(AnnotationPrefix) && strings.EqualFold(h.Name()[:len(AnnotationPrefix)], AnnotationPrefix) {
+			names = append(names, h.Name())
+		}
+	}
+	for _, name := range names {
+		req.RemoveHeader(name)
+	}
+}
+
+// Annotation returns an annotation set with Annotate ("" if absent).
+func Annotation(req *sip.Request, name string) string {
+	if h := req.GetHeader(AnnotationPrefix + name); h != nil {
+		return h.Value()
+	}
+	return ""
+}
+
+// Recover answers requests whose later middleware or handler panics with
+// 500 Server Error instead of crashing the server.
+func Recover() Middleware {
+	return Func("recover", func(req *sip.Request, tx sip.ServerTransaction, next Handler) {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("[Middleware] Panic handling request",
+					"method", req.Method,
+					"call_id", callID(req),
+					"panic", fmt.Sprint(r),
+					"stack", string(debug.Stack()),
+				)
+				Reject(req, tx, sip.StatusInternalServerError, "Server Error")
+			}
+		}()
+		next(req, tx)
+	})
+}
+
+func callID(req *sip.Request) string {
+	if h := req.CallID(); h != nil {
+		return h.Value()
+	}
+	return ""
+}
@@ -0,0 +1,344 @@
+// Package moh manages music-on-hold classes.
+//
+// A Class names a set of audio sources: a directory of WAV files and/or an
+// explicit list of files or http(s)/s3 URLs, played in sequence or shuffled.
+// Classes are assigned to tenants and queues; Resolve picks the class for a
+// caller, falling back to the default class. Paths are resolved by the RTP
+// manager, so directories must be visible to both signaling and RTP managers.
+package moh
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Play orders
+const (
+	OrderSequential = "sequential"
+	OrderShuffle    = "shuffle"
+)
+
+// Assignment scopes
+const (
+	ScopeTenant = "tenant"
+	ScopeQueue  = "queue"
+)
+
+// DefaultClass is the class name used when nothing else is assigned
+const DefaultClass = "default"
+
+// Sentinel errors
+var (
+	ErrClassNotFound = errors.New("moh: class not found")
+	ErrClassInUse    = errors.New("moh: class is in use")
+	ErrNoAudio       = errors.New("moh: class has no audio")
+)
+
+// Class is a named music-on-hold source.
+type Class struct {
+	Name      string   `json:"name"`
+	Directory string   `json:"directory,omitempty"` // WAV files, played in name order
+	Files     []string `json:"files,omitempty"`     // Files or URLs, played after Directory
+	Order     string   `json:"order,omitempty"`     // "sequential" (default) or "shuffle"
+}
+
+// Validate checks that the class is usable.
+func (c *Class) Validate() error {
+	if c.Name == "" {
+		return fmt.Errorf("moh: class name required")
+	}
+	if c.Directory == "" && len(c.Files) == 0 {
+		return fmt.Errorf("moh: class %s: directory or files required", c.Name)
+	}
+	switch c.Order {
+	case "", OrderSequential, OrderShuffle:
+	default:
+		return fmt.Errorf("moh: class %s: invalid order %q", c.Name, c.Order)
+	}
+	return nil
+}
+
+// Playlist returns one pass over the class's audio in play order.
+// Shuffled classes are reshuffled on every call.
+func (c *Class) Playlist() ([]string, error) {
+	var files []string
+	if c.Directory != "" {
+		entries, err := os.ReadDir(c.Directory)
+		if err != nil {
+			return nil, fmt.Errorf("moh: class %s: %w", c.Name, err)
+		}
+		for _, e := range entries {
+			if !e.IsDir() && strings.EqualFold(filepath.Ext(e.Name()), ".wav") {
+				files = append(files, filepath.Join(c.Directory, e.Name()))
+			}
+		}
+		sort.Strings(files)
+	}
+	files = append(files, c.Files...)
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoAudio, c.Name)
+	}
+	if c.Order == OrderShuffle {
+		rand.Shuffle(len(files), func(i, j int) { files[i], files[j] = files[j], files[i] })
+	}
+	return files, nil
+}
+
+// Assignment maps a tenant or queue to a class.
+type Assignment struct {
+	Scope string `json:"scope"` // "tenant" or "queue"
+	Key   string `json:"key"`
+	Class string `json:"class"`
+}
+
+// Config is the on-disk form of the registry.
+type Config struct {
+	Default     string       `json:"default,omitempty"`
+	Classes     []Class      `json:"classes"`
+	Assignments []Assignment `json:"assignments,omitempty"`
+}
+
+// Registry holds music-on-hold classes and their assignments.
+// Changes made through the management API are written back to the
+// config file when one is set.
+type Registry struct {
+	mu          sync.RWMutex
+	path        string
+	defaultName string
+	classes     map[string]*Class
+	assignments map[string]string // "scope:key" -> class name
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		defaultName: DefaultClass,
+		classes:     make(map[string]*Class),
+		assignments: make(map[string]string),
+	}
+}
+
+// Load creates a registry from a JSON config file. A missing file yields
+// an empty registry that is saved to path on the first change.
+func Load(path string) (*Registry, error) {
+	r := NewRegistry()
+	r.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read moh config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse moh config: %w", err)
+	}
+	if cfg.Default != "" {
+		r.defaultName = cfg.Default
+	}
+	for i := range cfg.Classes {
+		class := cfg.Classes[i]
+		if err := class.Validate(); err != nil {
+			return nil, err
+		}
+		r.classes[class.Name] = &class
+	}
+	for _, a := range cfg.Assignments {
+		if err := validScope(a.Scope); err != nil {
+			return nil, err
+		}
+		if _, ok := r.classes[a.Class]; !ok {
+			return nil, fmt.Errorf("%w: %s (assigned to %s %s)", ErrClassNotFound, a.Class, a.Scope, a.Key)
+		}
+		r.assignments[assignmentKey(a.Scope, a.Key)] = a.Class
+	}
+	return r, nil
+}
+
+// Class returns a class by name.
+func (r *Registry) Class(name string) (*Class, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	class, ok := r.classes[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrClassNotFound, name)
+	}
+	c := *class
+	return &c, nil
+}
+
+// Classes returns all classes sorted by name.
+func (r *Registry) Classes() []Class {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	classes := make([]Class, 0, len(r.classes))
+	for _, c := range r.classes {
+		classes = append(classes, *c)
+	}
+	sort.Slice(classes, func(i, j int) bool { return classes[i].Name < classes[j].Name })
+	return classes
+}
+
+// Default returns the name of the default class.
+func (r *Registry) Default() string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.defaultName
+}
+
+// Resolve picks the class for a queue and tenant: the queue's assignment,
+// then the tenant's, then the default class. Empty keys are skipped.
+func (r *Registry) Resolve(tenant, queue string) (*Class, error) {
+	r.mu.RLock()
+	name := r.defaultName
+	if class, ok := r.assignments[assignmentKey(ScopeQueue, queue)]; ok && queue != "" {
+		name = class
+	} else if class, ok := r.assignments[assignmentKey(ScopeTenant, tenant)]; ok && tenant != "" {
+		name = class
+	}
+	r.mu.RUnlock()
+
+	return r.Class(name)
+}
+
+// PutClass adds or replaces a class.
+func (r *Registry) PutClass(class Class) error {
+	if err := class.Validate(); err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.classes[class.Name] = &class
+	return r.saveLocked()
+}
+
+// DeleteClass removes a class. The default class and assigned classes
+// cannot be removed.
+func (r *Registry) DeleteClass(name string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	class, ok := r.classes[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrClassNotFound, name)
+	}
+	if name == r.defaultName {
+		return fmt.Errorf("%w: %s (default)", ErrClassInUse, name)
+	}
+	for key, assigned := range r.assignments {
+		if assigned == name {
+			return fmt.Errorf("%w: %s (%s)", ErrClassInUse, name, key)
+		}
+	}
+	delete(r.classes, name)
+	if err := r.saveLocked(); err != nil {
+		// Still on disk: keep serving it
+		r.classes[name] = class
+		return err
+	}
+	return nil
+}
+
+// Assignments returns all assignments sorted by scope and key.
+func (r *Registry) Assignments() []Assignment {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	return r.assignmentsLocked()
+}
+
+// Assign maps a tenant or queue to a class.
+func (r *Registry) Assign(a Assignment) error {
+	if err := validScope(a.Scope); err != nil {
+		return err
+	}
+	if a.Key == "" {
+		return fmt.Errorf("moh: assignment key required")
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.classes[a.Class]; !ok {
+		return fmt.Errorf("%w: %s", ErrClassNotFound, a.Class)
+	}
+	r.assignments[assignmentKey(a.Scope, a.Key)] = a.Class
+	return r.saveLocked()
+}
+
+// Unassign removes a tenant or queue assignment.
+func (r *Registry) Unassign(scope, key string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.assignments, assignmentKey(scope, key))
+	return r.saveLocked()
+}
+
+func (r *Registry) assignmentsLocked() []Assignment {
+	out := make([]Assignment, 0, len(r.assignments))
+	for k, class := range r.assignments {
+		scope, key, _ := strings.Cut(k, ":")
+		out = append(out, Assignment{Scope: scope, Key: key, Class: class})
+	}
+	slices.SortFunc(out, func(a, b Assignment) int {
+		return strings.Compare(a.Scope+":"+a.Key, b.Scope+":"+b.Key)
+	})
+	return out
+}
+
+// saveLocked writes the registry to its config file, if any (must hold lock).
+func (r *Registry) saveLocked() error {
+	if r.path == "" {
+		return nil
+	}
+
+	cfg := Config{
+		Default:     r.defaultName,
+		Classes:     make([]Class, 0, len(r.classes)),
+		Assignments: r.assignmentsLocked(),
+	}
+	for _, c := range r.classes {
+		cfg.Classes = append(cfg.Classes, *c)
+	}
+	sort.Slice(cfg.Classes, func(i, j int) bool { return cfg.Classes[i].Name < cfg.Classes[j].Name })
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode moh config: %w", err)
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write moh config: %w", err)
+	}
+	if err := os.Rename(tmp, r.path); err != nil {
+		return fmt.Errorf("write moh config: %w", err)
+	}
+	return nil
+}
+
+func validScope(scope string) error {
+	if scope != ScopeTenant && scope != ScopeQueue {
+		return fmt.Errorf("moh: invalid scope %q (use %q or %q)", scope, ScopeTenant, ScopeQueue)
+	}
+	return nil
+}
+
+func assignmentKey(scope, key string) string {
+	return scope + ":" + key
+}
@@ -0,0 +1,78 @@
+package originate
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/events"
+)
+
+// SetPublisher sets where the CDR event of each attempt of a call is
+// published.
+func (o *Originator) SetPublisher(publisher events.Publisher, builder *events.Builder) {
+	o.publisher = publisher
+	o.events = builder
+}
+
+// publishFailed publishes the CDR event of an attempt that failed. The
+// events of all attempts of a call share its call_uuid, the Call-ID of
+// its first attempt.
+func (o *Originator) publishFailed(callID, attemptID string, attempt int, started time.Time, err error) {
+	if o.publisher == nil || o.events == nil {
+		return
+	}
+	code, reason := failure(err)
+	endReason, disposition := failureOutcome(code, err)
+	event := o.events.CallEnded(callID, attemptID).
+		Leg(events.LegB).
+		Attempt(attempt).
+		Reason(endReason, err.Error()).
+		Disposition(disposition).
+		Durations(0, 0, 0, time.Since(started))
+	if code > 0 {
+		event.SIPResponse(code, reason)
+	}
+	o.publisher.PublishAsync(event.Build())
+}
+
+// publishEnded publishes the CDR event of the answered attempt of a call
+// once it ends, hung up by the far end if remote.
+func (o *Originator) publishEnded(callID string, attempt int, leg b2bua.Leg, started, answered time.Time, remote bool) {
+	if o.publisher == nil || o.events == nil {
+		return
+	}
+	source := "local"
+	if remote {
+		source = "remote"
+	}
+	talk := time.Since(answered)
+	event := o.events.CallEnded(callID, leg.CallID()).
+		Leg(events.LegB).
+		Attempt(attempt).
+		Reason(events.EndReasonNormal, "").
+		Disposition(events.DispositionAnswered).
+		HangupSource(source).
+		Durations(answered.Sub(started), 0, talk, time.Since(started)).
+		BillableDuration(talk)
+	if reason := leg.RemoteReason(); remote && !reason.IsZero() {
+		event.HangupReason(reason.String(), reason.Q850Cause())
+	}
+	o.publisher.PublishAsync(event.Build())
+}
+
+// failureOutcome maps a failed attempt to a CDR end reason and disposition
+func failureOutcome(code int, err error) (events.EndReason, string) {
+	switch {
+	case code == 486 || code == 600:
+		return events.EndReasonBusy, events.DispositionBusy
+	case code == 408 || code == 480 || code == 487:
+		return events.EndReasonNoAnswer, events.DispositionNoAnswer
+	case code >= 400:
+		return events.EndReasonRejected, events.DispositionFailed
+	case errors.Is(err, context.Canceled):
+		return events.EndReasonCanceled, events.DispositionCanceled
+	}
+	return events.EndReasonError, events.DispositionFailed
+}
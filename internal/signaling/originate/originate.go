@@ -0,0 +1,236 @@
+// Package originate places test calls from the signaling server. A call
+// dials a target, plays a tone or an audio file once answered, and hangs
+// up after a hold time, so operators can check a route, trunk or phone
+// without a second endpoint.
+//
+// The progress of each call (trying, ringing, early media, answered,
+// failed or ended) is kept for the API and may be posted to a callback
+// URL as it changes. Calls may be redialed when they fail with some
+// codes; the CDR events of all attempts share the call's call_uuid.
+package originate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/events"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// Defaults for Request fields left zero
+const (
+	DefaultTone     = "1004" // Continuous 1004 Hz test tone
+	DefaultDuration = 10 * time.Second
+	DefaultTimeout  = 30 * time.Second
+
+	// MaxDuration caps how long a test call is held
+	MaxDuration = time.Hour
+)
+
+// DefaultCallerID is the From user of test calls without a caller ID
+const DefaultCallerID = "switchboard"
+
+// Request describes a test call.
+type Request struct {
+	Target   string        // Dial target ("1001", "user/1001", "gateway/carrier", SIP URI)
+	CallerID string        // From user; DefaultCallerID if empty
+	Timeout  time.Duration // Ring time
+	Duration time.Duration // How long the answered call is held
+	Tone     string        // Tone played when answered; DefaultTone if File is empty too
+	File     string        // Audio file played instead of a tone
+
+	// CallbackURL receives the call's Status as a JSON POST on every
+	// progress change (none if empty)
+	CallbackURL string
+
+	// Retry redials the call when it fails with some codes
+	Retry Retry
+}
+
+// Call is an answered test call.
+type Call struct {
+	CallID   string // SIP Call-ID of the dialed leg
+	Target   string
+	Duration time.Duration // How long the call will be held
+}
+
+// Originator places test calls. Safe for concurrent use.
+type Originator struct {
+	service b2bua.CallService
+	media   mediaclient.Transport
+
+	// CDR events of each attempt (optional)
+	publisher events.Publisher
+	events    *events.Builder
+
+	mu    sync.Mutex
+	calls map[string]*tracked // Progress by the Call-ID of each attempt
+}
+
+// New creates an originator dialing through calls and playing through media.
+func New(calls b2bua.CallService, media mediaclient.Transport) *Originator {
+	return &Originator{service: calls, media: media, calls: make(map[string]*tracked)}
+}
+
+// Originate dials the target and blocks until it answers or the dial
+// fails; a failed dial returns the *b2bua.DialError. The answered call
+// is played to and hung up in the background.
+func (o *Originator) Originate(ctx context.Context, req Request) (*Call, error) {
+	callID, err := o.start(&req)
+	if err != nil {
+		return nil, err
+	}
+	return o.dial(ctx, callID, req)
+}
+
+// Start places a call in the background and returns its status at once;
+// its progress is then known by Status and posted to its callback URL.
+func (o *Originator) Start(req Request) (Status, error) {
+	callID, err := o.start(&req)
+	if err != nil {
+		return Status{}, err
+	}
+	status, _ := o.Status(callID)
+	go func() {
+		if _, err := o.dial(context.Background(), callID, req); err != nil {
+			slog.Info("[TestCall] Test call failed", "call_id", callID, "target", req.Target, "error", err)
+		}
+	}()
+	return status, nil
+}
+
+// start checks a request, fills in its defaults and starts tracking its
+// call. Returns the Call-ID of the call.
+func (o *Originator) start(req *Request) (string, error) {
+	if req.Target == "" {
+		return "", errors.New("target required")
+	}
+	if req.CallbackURL != "" {
+		u, err := url.Parse(req.CallbackURL)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return "", fmt.Errorf("invalid callback URL %q", req.CallbackURL)
+		}
+	}
+	if req.CallerID == "" {
+		req.CallerID = DefaultCallerID
+	}
+	if req.Timeout <= 0 {
+		req.Timeout = DefaultTimeout
+	}
+	if req.Duration <= 0 {
+		req.Duration = DefaultDuration
+	}
+	if req.Duration > MaxDuration {
+		return "", fmt.Errorf("duration exceeds %s", MaxDuration)
+	}
+	if req.Tone == "" && req.File == "" {
+		req.Tone = DefaultTone
+	}
+	if err := req.Retry.validate(); err != nil {
+		return "", err
+	}
+
+	callID := uuid.New().String()
+	o.track(callID, req.Target, req.CallbackURL)
+	return callID, nil
+}
+
+// dial dials a started call until it answers or fails, redialing the
+// failures its retry policy covers, and holds the answered call in the
+// background.
+func (o *Originator) dial(ctx context.Context, callID string, req Request) (*Call, error) {
+	attemptID := callID
+	for attempt := 1; ; attempt++ {
+		target := req.Retry.target(attempt, req.Target)
+		started := time.Now()
+		leg, err := o.service.Dial(ctx, target, req.Timeout,
+			b2bua.WithCallerID(req.CallerID),
+			b2bua.WithCallID(attemptID),
+			b2bua.WithProgressHandler(func(_ b2bua.Leg, state b2bua.LegState) {
+				progress := ProgressRinging
+				if state == b2bua.LegStateEarlyMedia {
+					progress = ProgressEarlyMedia
+				}
+				o.progress(callID, progress, 0, "", nil)
+			}),
+		)
+		if err == nil {
+			o.progress(callID, ProgressAnswered, 200, "OK", nil)
+			slog.Info("[TestCall] Test call answered", "call_id", leg.CallID(), "target", target, "attempt", attempt)
+
+			go o.hold(callID, attempt, leg, req, started)
+			return &Call{CallID: leg.CallID(), Target: target, Duration: req.Duration}, nil
+		}
+
+		code, reason := failure(err)
+		o.publishFailed(callID, attemptID, attempt, started, err)
+		if !req.Retry.redials(attempt, code) || ctx.Err() != nil {
+			o.progress(callID, ProgressFailed, code, reason, err)
+			return nil, err
+		}
+
+		wait := req.Retry.wait(attempt)
+		attemptID = uuid.New().String()
+		next := req.Retry.target(attempt+1, req.Target)
+		o.retrying(callID, code, reason, err, attemptID, next)
+		slog.Info("[TestCall] Redialing test call",
+			"call_id", callID,
+			"sip_code", code,
+			"attempt", attempt+1,
+			"target", next,
+			"backoff", wait,
+		)
+		select {
+		case <-ctx.Done():
+			o.progress(callID, ProgressFailed, code, reason, err)
+			return nil, err
+		case <-time.After(wait):
+		}
+		o.redialing(callID)
+	}
+}
+
+// hold plays to an answered leg until the duration elapses or the far
+// end hangs up, then hangs up and publishes the CDR event of the attempt.
+func (o *Originator) hold(callID string, attempt int, leg b2bua.Leg, req Request, started time.Time) {
+	answered := time.Now()
+	ctx, cancel := context.WithTimeout(leg.Context(), req.Duration)
+	defer cancel()
+
+	var status <-chan mediaclient.PlayStatus
+	var err error
+	if req.File != "" {
+		status, err = o.media.PlayAudio(ctx, mediaclient.PlayRequest{SessionID: leg.SessionID(), AudioFile: req.File, Loop: true})
+	} else {
+		status, err = o.media.PlayTone(ctx, mediaclient.ToneRequest{SessionID: leg.SessionID(), Tone: req.Tone})
+	}
+	if err != nil {
+		slog.Warn("[TestCall] Failed to start playback", "call_id", leg.CallID(), "error", err)
+	} else {
+		go func() {
+			for range status {
+			}
+		}()
+	}
+
+	<-ctx.Done()
+	defer o.progress(callID, ProgressEnded, 0, "", nil)
+	if leg.Context().Err() != nil {
+		slog.Info("[TestCall] Test call hung up by far end", "call_id", leg.CallID())
+		o.publishEnded(callID, attempt, leg, started, answered, true)
+		return
+	}
+	defer o.publishEnded(callID, attempt, leg, started, answered, false)
+	if err := leg.Hangup(context.Background(), b2bua.TerminationCauseNormal); err != nil {
+		slog.Warn("[TestCall] Failed to hang up test call", "call_id", leg.CallID(), "error", err)
+		return
+	}
+	slog.Info("[TestCall] Test call ended", "call_id", leg.CallID())
+}
@@ -0,0 +1,246 @@
+package originate
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// Progress is how far an originated call got
+type Progress string
+
+// Progress of an originated call, in order
+const (
+	ProgressTrying     Progress = "trying"      // INVITE being sent
+	ProgressRinging    Progress = "ringing"     // 180/181, or 183 without early media
+	ProgressEarlyMedia Progress = "early_media" // 183 with the callee's early media
+	ProgressAnswered   Progress = "answered"
+	ProgressRetrying   Progress = "retrying" // Waiting to redial after a failed attempt
+	ProgressFailed     Progress = "failed"   // Dial failed, with its SIP code if any
+	ProgressEnded      Progress = "ended"    // Answered call hung up
+)
+
+// done reports whether the call can progress no further
+func (p Progress) done() bool {
+	return p == ProgressFailed || p == ProgressEnded
+}
+
+// StatusRetention is how long the status of a failed or ended call is
+// kept after it ends
+const StatusRetention = 5 * time.Minute
+
+// callbackTimeout bounds each progress callback request
+const callbackTimeout = 10 * time.Second
+
+// callbackQueue is how many progress callbacks of a call may wait for
+// delivery; further ones are dropped
+const callbackQueue = 8
+
+// Status is the progress of an originated call, returned by the API and
+// posted to its callback URL on every change.
+type Status struct {
+	CallID    string    `json:"call_id"` // Call-ID of the first attempt
+	Target    string    `json:"target"`
+	State     Progress  `json:"state"`
+	SIPCode   int       `json:"sip_code,omitempty"`
+	SIPReason string    `json:"sip_reason,omitempty"`
+	Error     string    `json:"error,omitempty"` // Why the dial failed
+	StartedAt time.Time `json:"started_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+
+	// Attempts are the dials of the call, the current one last
+	Attempts []Attempt `json:"attempts"`
+}
+
+// Attempt is one dial of an originated call
+type Attempt struct {
+	CallID    string `json:"call_id"`
+	Target    string `json:"target"`
+	SIPCode   int    `json:"sip_code,omitempty"` // Final response, once known
+	SIPReason string `json:"sip_reason,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// tracked is an originated call whose progress is kept
+type tracked struct {
+	status    Status
+	callIDs   []string    // Call-IDs of the attempts
+	callbacks chan Status // nil without a callback URL
+}
+
+// copyStatus returns a copy of the status that shares nothing with it
+func (t *tracked) copyStatus() Status {
+	status := t.status
+	status.Attempts = slices.Clone(status.Attempts)
+	return status
+}
+
+// track starts keeping the progress of a call, posting it to callbackURL
+// if not empty.
+func (o *Originator) track(callID, target, callbackURL string) {
+	now := time.Now()
+	t := &tracked{
+		status: Status{
+			CallID:    callID,
+			Target:    target,
+			State:     ProgressTrying,
+			StartedAt: now,
+			UpdatedAt: now,
+			Attempts:  []Attempt{{CallID: callID, Target: target}},
+		},
+		callIDs: []string{callID},
+	}
+	if callbackURL != "" {
+		t.callbacks = make(chan Status, callbackQueue)
+		go o.deliver(callbackURL, t.callbacks)
+		t.callbacks <- t.copyStatus()
+	}
+
+	o.mu.Lock()
+	o.calls[callID] = t
+	o.mu.Unlock()
+}
+
+// progress records a call's progress. Within an attempt progress never
+// goes back, and a repeated state is not reported again. A final
+// response is recorded with the current attempt too.
+func (o *Originator) progress(callID string, state Progress, sipCode int, sipReason string, err error) {
+	o.update(callID, func(t *tracked) bool {
+		if t.status.State == state || (state == ProgressRinging && t.status.State == ProgressEarlyMedia) {
+			return false
+		}
+		t.status.State = state
+		t.status.SIPCode, t.status.SIPReason = sipCode, sipReason
+		if err != nil {
+			t.status.Error = err.Error()
+		}
+		if state == ProgressAnswered || state == ProgressFailed {
+			t.attempt().SIPCode, t.attempt().SIPReason = sipCode, sipReason
+			if err != nil {
+				t.attempt().Error = err.Error()
+			}
+		}
+		return true
+	})
+}
+
+// retrying records that a call's current attempt failed with a code and
+// that the call is redialed to target with a new Call-ID.
+func (o *Originator) retrying(callID string, sipCode int, sipReason string, err error, nextCallID, target string) {
+	o.update(callID, func(t *tracked) bool {
+		current := t.attempt()
+		current.SIPCode, current.SIPReason, current.Error = sipCode, sipReason, err.Error()
+		t.status.State = ProgressRetrying
+		t.status.SIPCode, t.status.SIPReason, t.status.Error = sipCode, sipReason, err.Error()
+		t.status.Attempts = append(t.status.Attempts, Attempt{CallID: nextCallID, Target: target})
+		t.callIDs = append(t.callIDs, nextCallID)
+		o.calls[nextCallID] = t
+		return true
+	})
+}
+
+// redialing records that a call's next attempt is being dialed
+func (o *Originator) redialing(callID string) {
+	o.update(callID, func(t *tracked) bool {
+		t.status.State = ProgressTrying
+		t.status.SIPCode, t.status.SIPReason, t.status.Error = 0, "", ""
+		return true
+	})
+}
+
+// attempt returns the current attempt of a call
+func (t *tracked) attempt() *Attempt {
+	return &t.status.Attempts[len(t.status.Attempts)-1]
+}
+
+// update changes the status of a call that has not failed or ended with
+// change, which reports whether it changed anything, and posts the new
+// status to the callback URL. The status of a call that failed or ended
+// is forgotten after StatusRetention.
+func (o *Originator) update(callID string, change func(t *tracked) bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	t, ok := o.calls[callID]
+	if !ok || t.status.State.done() || !change(t) {
+		return
+	}
+	t.status.UpdatedAt = time.Now()
+	state := t.status.State
+	slog.Debug("[TestCall] Progress", "call_id", callID, "state", state, "sip_code", t.status.SIPCode)
+
+	if t.callbacks != nil {
+		select {
+		case t.callbacks <- t.copyStatus():
+		default:
+			slog.Warn("[TestCall] Progress callback queue full, dropping", "call_id", callID, "state", state)
+		}
+		if state.done() {
+			close(t.callbacks)
+		}
+	}
+	if state.done() {
+		time.AfterFunc(StatusRetention, func() {
+			o.mu.Lock()
+			defer o.mu.Unlock()
+			for _, id := range t.callIDs {
+				delete(o.calls, id)
+			}
+		})
+	}
+}
+
+// Status returns the progress of a call placed by the originator, by the
+// Call-ID of any of its attempts. It is kept until StatusRetention after
+// the call fails or ends.
+func (o *Originator) Status(callID string) (Status, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	t, ok := o.calls[callID]
+	if !ok {
+		return Status{}, false
+	}
+	return t.copyStatus(), true
+}
+
+// deliver posts a call's progress to its callback URL in order, until
+// the call fails or ends.
+func (o *Originator) deliver(url string, callbacks <-chan Status) {
+	client := &http.Client{Timeout: callbackTimeout}
+	for status := range callbacks {
+		if err := postStatus(client, url, status); err != nil {
+			slog.Warn("[TestCall] Progress callback failed",
+				"call_id", status.CallID,
+				"state", status.State,
+				"url", url,
+				"error", err,
+			)
+		}
+	}
+}
+
+// postStatus posts a status as JSON
+func postStatus(client *http.Client, url string, status Status) error {
+	body, err := json.Marshal(status)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(context.Background(), http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("callback returned %s", resp.Status)
+	}
+	return nil
+}
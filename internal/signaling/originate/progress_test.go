@@ -0,0 +1,89 @@
+package originate
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestProgressCallbacks(t *testing.T) {
+	posted := make(chan Status, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var status Status
+		if err := json.NewDecoder(r.Body).Decode(&status); err != nil {
+			t.Errorf("decode callback: %v", err)
+		}
+		posted <- status
+	}))
+	defer srv.Close()
+
+	o := New(nil, nil)
+	o.track("call-1", "1001", srv.URL)
+	o.progress("call-1", ProgressEarlyMedia, 0, "", nil)
+	o.progress("call-1", ProgressRinging, 0, "", nil) // never goes back
+	o.progress("call-1", ProgressFailed, 486, "Busy Here", errors.New("busy"))
+	o.progress("call-1", ProgressEnded, 0, "", nil) // already failed
+
+	want := []Progress{ProgressTrying, ProgressEarlyMedia, ProgressFailed}
+	for _, state := range want {
+		select {
+		case status := <-posted:
+			if status.State != state {
+				t.Fatalf("callback state = %s, want %s", status.State, state)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no callback for %s", state)
+		}
+	}
+
+	status, ok := o.Status("call-1")
+	if !ok || status.State != ProgressFailed || status.SIPCode != 486 || status.Error != "busy" {
+		t.Errorf("Status() = %+v, %v", status, ok)
+	}
+	if _, ok := o.Status("call-2"); ok {
+		t.Error("Status() found an unknown call")
+	}
+}
+
+func TestRetry(t *testing.T) {
+	r := Retry{Codes: []int{480, 503}, Attempts: 2, Targets: []string{"gateway/b", "gateway/c"}}
+	if err := r.validate(); err != nil {
+		t.Fatal(err)
+	}
+	if !r.redials(1, 503) || !r.redials(2, 480) || r.redials(3, 503) || r.redials(1, 486) {
+		t.Error("redials() does not follow codes and attempts")
+	}
+	if got := []string{r.target(1, "1001"), r.target(2, "1001"), r.target(3, "1001"), r.target(4, "1001")}; !slices.Equal(got, []string{"1001", "gateway/b", "gateway/c", "gateway/b"}) {
+		t.Errorf("target() = %v", got)
+	}
+	if r.wait(1) != DefaultRetryBackoff || r.wait(3) != 4*DefaultRetryBackoff || r.wait(20) != MaxRetryBackoff {
+		t.Errorf("wait() = %s, %s, %s", r.wait(1), r.wait(3), r.wait(20))
+	}
+	for _, bad := range []Retry{{Attempts: 1}, {Codes: []int{200}, Attempts: 1}, {Codes: []int{503}, Attempts: MaxRetries + 1}} {
+		if err := bad.validate(); err == nil {
+			t.Errorf("validate(%+v) succeeded", bad)
+		}
+	}
+
+	o := New(nil, nil)
+	o.track("call-1", "1001", "")
+	o.retrying("call-1", 503, "Service Unavailable", errors.New("unavailable"), "call-2", "gateway/b")
+	o.redialing("call-1")
+	o.progress("call-1", ProgressAnswered, 200, "OK", nil)
+
+	status, ok := o.Status("call-2")
+	if !ok || status.CallID != "call-1" || status.State != ProgressAnswered {
+		t.Fatalf("Status(call-2) = %+v, %v", status, ok)
+	}
+	want := []Attempt{
+		{CallID: "call-1", Target: "1001", SIPCode: 503, SIPReason: "Service Unavailable", Error: "unavailable"},
+		{CallID: "call-2", Target: "gateway/b", SIPCode: 200, SIPReason: "OK"},
+	}
+	if !slices.Equal(status.Attempts, want) {
+		t.Errorf("Attempts = %+v, want %+v", status.Attempts, want)
+	}
+}
@@ -0,0 +1,99 @@
+package originate
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+)
+
+// Redial limits
+const (
+	// MaxRetries caps the redials of a call after its first attempt
+	MaxRetries = 10
+
+	// DefaultRetryBackoff is the wait before the first redial when the
+	// policy sets none; it doubles after each redial
+	DefaultRetryBackoff = 5 * time.Second
+
+	// MaxRetryBackoff caps the wait before a redial
+	MaxRetryBackoff = 5 * time.Minute
+)
+
+// Retry redials a call that failed with one of some failure codes. The
+// zero Retry never redials.
+type Retry struct {
+	// Codes are the failure codes redialed, e.g. 480 and 503; 408 covers
+	// a ring time running out
+	Codes []int
+
+	// Attempts is how often the call is redialed after its first attempt
+	Attempts int
+
+	// Backoff is the wait before the first redial, doubled after each
+	Backoff time.Duration
+
+	// Targets are alternate targets dialed by the redials in turn; the
+	// request's target is redialed if empty
+	Targets []string
+}
+
+// validate checks a policy and fills in its defaults
+func (r *Retry) validate() error {
+	switch {
+	case r.Attempts < 0 || r.Attempts > MaxRetries:
+		return fmt.Errorf("retry attempts must be 0-%d", MaxRetries)
+	case r.Attempts > 0 && len(r.Codes) == 0:
+		return errors.New("retry codes required")
+	case r.Backoff < 0:
+		return errors.New("retry backoff must not be negative")
+	}
+	for _, code := range r.Codes {
+		if code < 400 || code > 699 {
+			return fmt.Errorf("retry code %d is not a failure code", code)
+		}
+	}
+	if r.Backoff == 0 {
+		r.Backoff = DefaultRetryBackoff
+	}
+	return nil
+}
+
+// redials reports whether an attempt (1 for the first) that failed with
+// a code is redialed
+func (r *Retry) redials(attempt, code int) bool {
+	return attempt <= r.Attempts && slices.Contains(r.Codes, code)
+}
+
+// wait returns the wait before redialing after an attempt
+func (r *Retry) wait(attempt int) time.Duration {
+	backoff := r.Backoff
+	for i := 1; i < attempt && backoff < MaxRetryBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, MaxRetryBackoff)
+}
+
+// target returns the target of an attempt
+func (r *Retry) target(attempt int, target string) string {
+	if attempt == 1 || len(r.Targets) == 0 {
+		return target
+	}
+	return r.Targets[(attempt-2)%len(r.Targets)]
+}
+
+// failure returns the failure code and reason phrase of a failed dial: its
+// SIP response, 408 if the ring time ran out, and 0 otherwise
+func failure(err error) (int, string) {
+	var dialErr *b2bua.DialError
+	switch {
+	case errors.As(err, &dialErr) && dialErr.SIPCode > 0:
+		return dialErr.SIPCode, dialErr.SIPReason
+	case errors.Is(err, b2bua.ErrDialTimeout), errors.Is(err, context.DeadlineExceeded):
+		return 408, "Request Timeout"
+	}
+	return 0, ""
+}
@@ -0,0 +1,17 @@
+//go:build linux
+
+package overload
+
+import (
+	"syscall"
+	"time"
+)
+
+// processCPU returns the CPU time used by this process so far.
+func processCPU() (time.Duration, bool) {
+	var ru syscall.Rusage
+	if err := syscall.Getrusage(syscall.RUSAGE_SELF, &ru); err != nil {
+		return 0, false
+	}
+	return time.Duration(ru.Utime.Nano() + ru.Stime.Nano()), true
+}
@@ -0,0 +1,11 @@
+//go:build !linux
+
+package overload
+
+import "time"
+
+// processCPU is not implemented on this platform; the CPU threshold is
+// never reached.
+func processCPU() (time.Duration, bool) {
+	return 0, false
+}
@@ -0,0 +1,235 @@
+// Package overload sheds new calls when the signaling server is
+// overloaded, so that the calls already up keep their quality.
+//
+// Three signals are checked, each against its own threshold (zero
+// disables it):
+//
+//   - CPU: the process's CPU use, as a percentage of all cores, sampled
+//     every second.
+//   - Dialogs: the number of dialogs held by the dialog manager.
+//   - Originate latency: the average time the B2BUA took to set up an
+//     outbound leg (media allocation included) before sending its INVITE,
+//     over the last ten seconds. It grows when the RTP managers or the
+//     server itself fall behind.
+//
+// While any signal is over its threshold, INVITEs that start a call are
+// refused with 503 Service Unavailable and a Retry-After header, so that
+// well-behaved peers back off or fail over (RFC 3261 Section 21.5.4).
+// In-dialog requests and emergency calls are always let through.
+package overload
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+)
+
+// Reasons for shedding, as logged
+const (
+	ReasonCPU       = "cpu"
+	ReasonDialogs   = "dialogs"
+	ReasonOriginate = "originate_latency"
+)
+
+const (
+	sampleInterval = time.Second
+	latencyWindow  = 10 // Seconds of originate latency averaged
+)
+
+// Config holds the shedding thresholds. A zero threshold is not checked.
+type Config struct {
+	MaxCPUPercent       float64       // Process CPU use, in percent of all cores
+	MaxDialogs          int           // Dialogs held by the dialog manager
+	MaxOriginateLatency time.Duration // Average setup time of outbound legs
+	RetryAfter          time.Duration // Sent in Retry-After; default 30 seconds
+}
+
+// Enabled reports whether any threshold is set.
+func (c Config) Enabled() bool {
+	return c.MaxCPUPercent > 0 || c.MaxDialogs > 0 || c.MaxOriginateLatency > 0
+}
+
+// DialogCounter reports the number of dialogs; dialog.DialogStore implements it.
+type DialogCounter interface {
+	Count() int
+}
+
+// latencyBucket sums the originate latencies of one sample interval.
+type latencyBucket struct {
+	sum time.Duration
+	n   int
+}
+
+// Shedder tracks the load signals and refuses new calls while any is
+// over its threshold. Safe for concurrent use.
+type Shedder struct {
+	cfg       Config
+	dialogs   DialogCounter
+	emergency func(destination string) bool
+
+	rejected atomic.Int64
+	shedding atomic.Bool
+
+	mu      sync.Mutex
+	reason  string
+	cpu     float64
+	latency time.Duration
+	buckets [latencyWindow]latencyBucket
+	current int // Index of the bucket being filled
+}
+
+// New creates a shedder. emergency, when set, tells which destinations
+// are emergency numbers; calls to them are never refused.
+func New(cfg Config, dialogs DialogCounter, emergency func(destination string) bool) *Shedder {
+	if cfg.RetryAfter <= 0 {
+		cfg.RetryAfter = 30 * time.Second
+	}
+	return &Shedder{cfg: cfg, dialogs: dialogs, emergency: emergency}
+}
+
+// Run samples CPU use and originate latency every second until ctx is done.
+func (s *Shedder) Run(ctx context.Context) {
+	ticker := time.NewTicker(sampleInterval)
+	defer ticker.Stop()
+
+	lastCPU, _ := processCPU()
+	lastAt := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case now := <-ticker.C:
+			cpu, ok := processCPU()
+			var percent float64
+			if ok {
+				percent = 100 * float64(cpu-lastCPU) / float64(now.Sub(lastAt)) / float64(runtime.NumCPU())
+				lastCPU, lastAt = cpu, now
+			}
+			s.sample(percent)
+		}
+	}
+}
+
+// ObserveOriginate records how long an outbound leg took to set up. The
+// B2BUA calls it (see b2bua.CallServiceConfig.LoadMonitor).
+func (s *Shedder) ObserveOriginate(d time.Duration) {
+	s.mu.Lock()
+	b := &s.buckets[s.current]
+	b.sum += d
+	b.n++
+	s.mu.Unlock()
+}
+
+// sample updates CPU use and originate latency, starts the next latency
+// bucket and re-evaluates the thresholds.
+func (s *Shedder) sample(cpuPercent float64) {
+	s.mu.Lock()
+	var sum time.Duration
+	var n int
+	for _, b := range s.buckets {
+		sum += b.sum
+		n += b.n
+	}
+	s.latency = 0
+	if n > 0 {
+		s.latency = sum / time.Duration(n)
+	}
+	s.cpu = cpuPercent
+	s.current = (s.current + 1) % latencyWindow
+	s.buckets[s.current] = latencyBucket{}
+	s.mu.Unlock()
+
+	s.evaluate()
+}
+
+// evaluate compares the signals to their thresholds and logs changes
+// between shedding and normal operation.
+func (s *Shedder) evaluate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	reason, value, limit := s.overLocked()
+	was := s.shedding.Load()
+	switch {
+	case reason != "" && (!was || reason != s.reason):
+		slog.Warn("[Overload] Shedding new calls", "reason", reason, "value", value, "threshold", limit)
+	case reason == "" && was:
+		slog.Info("[Overload] Load back to normal, accepting new calls", "rejected", s.rejected.Load())
+	}
+	s.reason = reason
+	s.shedding.Store(reason != "")
+}
+
+// overLocked returns the first signal over its threshold, or "".
+func (s *Shedder) overLocked() (reason, value, threshold string) {
+	if s.cfg.MaxCPUPercent > 0 && s.cpu >= s.cfg.MaxCPUPercent {
+		return ReasonCPU, fmt.Sprintf("%.0f%%", s.cpu), fmt.Sprintf("%.0f%%", s.cfg.MaxCPUPercent)
+	}
+	if s.cfg.MaxDialogs > 0 && s.dialogs != nil {
+		if n := s.dialogs.Count(); n >= s.cfg.MaxDialogs {
+			return ReasonDialogs, strconv.Itoa(n), strconv.Itoa(s.cfg.MaxDialogs)
+		}
+	}
+	if s.cfg.MaxOriginateLatency > 0 && s.latency >= s.cfg.MaxOriginateLatency {
+		return ReasonOriginate, s.latency.String(), s.cfg.MaxOriginateLatency.String()
+	}
+	return "", "", ""
+}
+
+// overloaded reports whether a new call should be refused. The dialog
+// limit is checked on every call, the other signals once per sample.
+func (s *Shedder) overloaded() bool {
+	if s.shedding.Load() {
+		return true
+	}
+	if s.cfg.MaxDialogs > 0 && s.dialogs != nil && s.dialogs.Count() >= s.cfg.MaxDialogs {
+		s.evaluate()
+		return true
+	}
+	return false
+}
+
+// Middleware refuses INVITEs that start a call with 503 and Retry-After
+// while the server is overloaded.
+func (s *Shedder) Middleware() middleware.Middleware {
+	return middleware.Func("overload", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		if req.Method != sip.INVITE || !s.overloaded() {
+			next(req, tx)
+			return
+		}
+		to := req.To()
+		if to != nil {
+			if _, inDialog := to.Params.Get("tag"); inDialog {
+				next(req, tx)
+				return
+			}
+			if s.emergency != nil && s.emergency(to.Address.User) {
+				next(req, tx)
+				return
+			}
+		}
+
+		s.rejected.Add(1)
+		slog.Debug("[Overload] New call refused", "call_id", callID(req), "from", req.Source())
+		res := sip.NewResponseFromRequest(req, sip.StatusServiceUnavailable, "Service Unavailable", nil)
+		res.AppendHeader(sip.NewHeader("Retry-After", strconv.Itoa(int(s.cfg.RetryAfter.Seconds()))))
+		if err := tx.Respond(res); err != nil {
+			slog.Warn("[Overload] Failed to send 503", "call_id", callID(req), "error", err)
+		}
+	})
+}
+
+func callID(req *sip.Request) string {
+	if h := req.CallID(); h != nil {
+		return h.Value()
+	}
+	return ""
+}
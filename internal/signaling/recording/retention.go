@@ -0,0 +1,154 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RetentionRule keeps recordings under Prefix for MaxAge.
+type RetentionRule struct {
+	Prefix string
+	MaxAge time.Duration
+}
+
+// RetentionPolicy decides when recordings expire. The longest matching
+// rule prefix wins; Default applies otherwise. A zero age keeps forever.
+type RetentionPolicy struct {
+	Default time.Duration
+	Rules   []RetentionRule
+}
+
+// ParseRetention parses a policy such as "30d" or "voicemail/=90d,calls/=30d,7d":
+// comma-separated prefix=age rules, with a bare age as the default.
+// Ages are Go durations, plus a "d" suffix for days.
+func ParseRetention(spec string) (RetentionPolicy, error) {
+	var policy RetentionPolicy
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		prefix, age, isRule := strings.Cut(part, "=")
+		if !isRule {
+			age = prefix
+		}
+		d, err := parseAge(age)
+		if err != nil {
+			return RetentionPolicy{}, fmt.Errorf("recording: invalid retention %q: %w", part, err)
+		}
+		if isRule {
+			policy.Rules = append(policy.Rules, RetentionRule{Prefix: prefix, MaxAge: d})
+		} else {
+			policy.Default = d
+		}
+	}
+	return policy, nil
+}
+
+func parseAge(s string) (time.Duration, error) {
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return 0, fmt.Errorf("invalid days %q", s)
+		}
+		return time.Duration(n) * 24 * time.Hour, nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid age %q", s)
+	}
+	return d, nil
+}
+
+// MaxAge returns how long a recording is kept (0: forever).
+func (p RetentionPolicy) MaxAge(name string) time.Duration {
+	age := p.Default
+	matched := -1
+	for _, r := range p.Rules {
+		if strings.HasPrefix(name, r.Prefix) && len(r.Prefix) > matched {
+			age = r.MaxAge
+			matched = len(r.Prefix)
+		}
+	}
+	return age
+}
+
+// Enabled reports whether any recordings can expire.
+func (p RetentionPolicy) Enabled() bool {
+	if p.Default > 0 {
+		return true
+	}
+	for _, r := range p.Rules {
+		if r.MaxAge > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Janitor periodically deletes recordings past their retention.
+type Janitor struct {
+	store    Store
+	policy   RetentionPolicy
+	interval time.Duration
+}
+
+// NewJanitor creates a janitor. interval defaults to one hour.
+func NewJanitor(store Store, policy RetentionPolicy, interval time.Duration) *Janitor {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &Janitor{store: store, policy: policy, interval: interval}
+}
+
+// Run sweeps immediately and then every interval until ctx is done.
+func (j *Janitor) Run(ctx context.Context) {
+	ticker := time.NewTicker(j.interval)
+	defer ticker.Stop()
+
+	for {
+		if _, err := j.Sweep(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("[Recording] Retention sweep failed", "backend", j.store.Backend(), "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Sweep deletes expired recordings and returns how many were removed.
+func (j *Janitor) Sweep(ctx context.Context) (int, error) {
+	if !j.policy.Enabled() {
+		return 0, nil
+	}
+
+	objects, err := j.store.List(ctx, "")
+	if err != nil {
+		return 0, err
+	}
+
+	now := time.Now()
+	deleted := 0
+	for _, o := range objects {
+		maxAge := j.policy.MaxAge(o.Name)
+		if maxAge <= 0 || now.Sub(o.Modified) < maxAge {
+			continue
+		}
+		if err := j.store.Delete(ctx, o.Name); err != nil {
+			slog.Warn("[Recording] Failed to delete expired recording", "name", o.Name, "error", err)
+			continue
+		}
+		deleted++
+	}
+
+	if deleted > 0 {
+		slog.Info("[Recording] Retention sweep", "backend", j.store.Backend(), "deleted", deleted, "scanned", len(objects))
+	}
+	return deleted, nil
+}
@@ -0,0 +1,103 @@
+package recording
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+// S3Store keeps recordings in an S3 bucket under a key prefix.
+type S3Store struct {
+	client  *s3.Client
+	bucket  string
+	prefix  string // "" or ending in "/"
+	backend string
+}
+
+// NewS3Store creates a store for bucket. Recording names are appended to prefix.
+func NewS3Store(client *s3.Client, bucket, prefix string) *S3Store {
+	return &S3Store{
+		client:  client,
+		bucket:  bucket,
+		prefix:  prefix,
+		backend: "s3",
+	}
+}
+
+// Backend returns "s3", or "gcs" when created for Google Cloud Storage.
+func (s *S3Store) Backend() string { return s.backend }
+
+// Put uploads a recording.
+func (s *S3Store) Put(ctx context.Context, name string, data []byte) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Put(ctx, s.bucket, key, data, contentType(name)); err != nil {
+		return fmt.Errorf("recording: %w", err)
+	}
+	return nil
+}
+
+// Open downloads a recording.
+func (s *S3Store) Open(ctx context.Context, name string) (io.ReadCloser, error) {
+	key, err := s.key(name)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := s.client.Get(ctx, s.bucket, key, nil)
+	if err != nil {
+		return nil, fmt.Errorf("recording: %w", err)
+	}
+	switch resp.StatusCode {
+	case http.StatusOK:
+		return resp.Body, nil
+	case http.StatusNotFound:
+		resp.Body.Close()
+		return nil, fmt.Errorf("%w: %s", ErrNotFound, name)
+	default:
+		resp.Body.Close()
+		return nil, fmt.Errorf("recording: get %s: %s", name, resp.Status)
+	}
+}
+
+// Delete removes a recording.
+func (s *S3Store) Delete(ctx context.Context, name string) error {
+	key, err := s.key(name)
+	if err != nil {
+		return err
+	}
+	if err := s.client.Delete(ctx, s.bucket, key); err != nil {
+		return fmt.Errorf("recording: %w", err)
+	}
+	return nil
+}
+
+// List returns recordings under the store prefix whose names start with prefix.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	listed, err := s.client.List(ctx, s.bucket, s.prefix+prefix)
+	if err != nil {
+		return nil, fmt.Errorf("recording: %w", err)
+	}
+	objects := make([]Object, 0, len(listed))
+	for _, o := range listed {
+		objects = append(objects, Object{
+			Name:     strings.TrimPrefix(o.Key, s.prefix),
+			Size:     o.Size,
+			Modified: o.LastModified,
+		})
+	}
+	return objects, nil
+}
+
+func (s *S3Store) key(name string) (string, error) {
+	name, err := cleanName(name)
+	if err != nil {
+		return "", err
+	}
+	return s.prefix + name, nil
+}
@@ -0,0 +1,150 @@
+// Package recording stores call recordings and voicemail outside the
+// signaling node, so they survive node replacement.
+//
+// A Store holds recordings by name (a slash-separated path such as
+// "2026/01/15/call-123.wav"). Implementations: LocalStore (a directory,
+// typically a shared or persistent volume) and S3Store (Amazon S3 or an
+// S3-compatible service such as MinIO or Google Cloud Storage's XML API).
+// A Janitor applies RetentionPolicy rules to delete expired recordings.
+package recording
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/s3"
+)
+
+// Sentinel errors
+var (
+	ErrNotFound    = errors.New("recording: not found")
+	ErrInvalidName = errors.New("recording: invalid name")
+)
+
+// Object describes a stored recording.
+type Object struct {
+	Name     string    `json:"name"`
+	Size     int64     `json:"size"`
+	Modified time.Time `json:"modified"`
+}
+
+// Store persists recordings.
+type Store interface {
+	// Backend identifies the implementation ("local", "s3", "gcs")
+	Backend() string
+
+	// Put stores a recording, replacing any with the same name
+	Put(ctx context.Context, name string, data []byte) error
+
+	// Open returns a recording's contents. The caller must close it.
+	Open(ctx context.Context, name string) (io.ReadCloser, error)
+
+	// Delete removes a recording. Deleting a missing recording is not an error.
+	Delete(ctx context.Context, name string) error
+
+	// List returns recordings whose names start with prefix
+	List(ctx context.Context, prefix string) ([]Object, error)
+}
+
+// Config selects and configures a store.
+type Config struct {
+	// Backend is "local", "s3" or "gcs"
+	Backend string
+
+	// Dir is the recording directory for the local backend
+	Dir string
+
+	// URL is "s3://bucket/prefix" (or "gs://bucket/prefix") for the
+	// s3 and gcs backends
+	URL string
+
+	// S3 holds credentials and the endpoint for the s3 and gcs backends.
+	// gcs uses HMAC keys as the access key pair.
+	S3 s3.Config
+}
+
+// gcsEndpoint is Google Cloud Storage's S3-compatible XML API
+const gcsEndpoint = "https://storage.googleapis.com"
+
+// NewStore creates the store named in cfg.
+func NewStore(cfg Config) (Store, error) {
+	switch cfg.Backend {
+	case "local":
+		return NewLocalStore(cfg.Dir)
+	case "s3", "gcs":
+		bucket, prefix, err := parseBucketURL(cfg.URL)
+		if err != nil {
+			return nil, err
+		}
+		s3cfg := cfg.S3
+		if cfg.Backend == "gcs" {
+			if s3cfg.Endpoint == "" {
+				s3cfg.Endpoint = gcsEndpoint
+			}
+			if s3cfg.Region == "" {
+				s3cfg.Region = "auto"
+			}
+		}
+		store := NewS3Store(s3.New(s3cfg, &http.Client{Timeout: 60 * time.Second}), bucket, prefix)
+		store.backend = cfg.Backend
+		return store, nil
+	default:
+		return nil, fmt.Errorf("recording: unknown backend %q", cfg.Backend)
+	}
+}
+
+// parseBucketURL splits "s3://bucket/prefix" or "gs://bucket/prefix".
+func parseBucketURL(raw string) (bucket, prefix string, err error) {
+	rest, ok := strings.CutPrefix(raw, "s3://")
+	if !ok {
+		rest, ok = strings.CutPrefix(raw, "gs://")
+	}
+	if !ok {
+		return "", "", fmt.Errorf("recording: bucket URL must be s3://bucket[/prefix]: %q", raw)
+	}
+	bucket, prefix, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("recording: bucket URL must be s3://bucket[/prefix]: %q", raw)
+	}
+	if prefix != "" && !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	return bucket, prefix, nil
+}
+
+// cleanName validates a recording name. Names are relative slash paths
+// without "." or ".." elements.
+func cleanName(name string) (string, error) {
+	if name == "" || strings.HasPrefix(name, "/") || strings.Contains(name, "\\") {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	if path.Clean(name) != name {
+		return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+	}
+	for _, elem := range strings.Split(name, "/") {
+		if elem == "." || elem == ".." {
+			return "", fmt.Errorf("%w: %q", ErrInvalidName, name)
+		}
+	}
+	return name, nil
+}
+
+// contentType guesses a recording's MIME type from its extension.
+func contentType(name string) string {
+	switch strings.ToLower(path.Ext(name)) {
+	case ".wav":
+		return "audio/wav"
+	case ".mp3":
+		return "audio/mpeg"
+	case ".json":
+		return "application/json"
+	default:
+		return "application/octet-stream"
+	}
+}
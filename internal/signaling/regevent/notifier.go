@@ -0,0 +1,360 @@
+// Package regevent implements the SIP reg event package (RFC 3680):
+// subscribers to an AOR are notified when its bindings are created,
+// refreshed, unregistered, expire or are removed administratively.
+package regevent
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/location"
+)
+
+const (
+	// EventPackage is the Event header value of the reg event package
+	EventPackage = "reg"
+
+	// defaultExpires is the subscription duration when SUBSCRIBE has no Expires
+	defaultExpires = 3600
+
+	// maxExpires caps requested subscription durations
+	maxExpires = 3600
+
+	// notifyTimeout bounds how long a NOTIFY waits for its final response
+	notifyTimeout = 10 * time.Second
+)
+
+// subscription is a reg event dialog with one subscriber.
+type subscription struct {
+	aor         string // AOR as subscribed (Request-URI)
+	user        string // User part, matched against binding AORs
+	callID      string
+	localTag    string
+	remoteTag   string
+	localURI    sip.Uri // Our identity (To of the SUBSCRIBE)
+	remoteURI   sip.Uri // Subscriber identity (From of the SUBSCRIBE)
+	target      sip.Uri // Subscriber Contact
+	destination string  // Where the SUBSCRIBE came from
+	cseq        uint32
+	version     int
+	timer       *time.Timer
+}
+
+// Notifier handles reg event SUBSCRIBE requests and sends NOTIFYs for
+// binding changes.
+type Notifier struct {
+	store    location.LocationStore
+	client   *sipgo.Client
+	contact  sip.ContactHeader
+	identity Identity // nil sends NOTIFYs as built
+
+	mu   sync.Mutex
+	subs map[string]*subscription // Call-ID -> subscription
+}
+
+// NewNotifier creates a notifier and subscribes it to store changes.
+func NewNotifier(store location.LocationStore, client *sipgo.Client, contact sip.ContactHeader) *Notifier {
+	n := &Notifier{
+		store:   store,
+		client:  client,
+		contact: contact,
+		subs:    make(map[string]*subscription),
+	}
+	store.OnChange(n.onChange)
+	return n
+}
+
+// Identity sets the identity headers (User-Agent) of the NOTIFYs sent.
+// Implemented by identity.Headers.
+type Identity interface {
+	// SentRequest is called before a request is sent to peer (its host).
+	SentRequest(req *sip.Request, peer string)
+}
+
+// SetIdentity sets the identity headers of NOTIFYs. Call it before the
+// first SUBSCRIBE.
+func (n *Notifier) SetIdentity(identity Identity) {
+	n.identity = identity
+}
+
+// Count returns the number of active subscriptions.
+func (n *Notifier) Count() int {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	return len(n.subs)
+}
+
+// HandleSUBSCRIBE creates, refreshes or ends a subscription.
+func (n *Notifier) HandleSUBSCRIBE(req *sip.Request, tx sip.ServerTransaction) {
+	event := req.GetHeader("Event")
+	if event == nil || !strings.EqualFold(eventType(event.Value()), EventPackage) {
+		res := sip.NewResponseFromRequest(req, 489, "Bad Event", nil)
+		res.AppendHeader(sip.NewHeader("Allow-Events", EventPackage))
+		n.respond(tx, res)
+		return
+	}
+	if !acceptsReginfo(req) {
+		n.respond(tx, sip.NewResponseFromRequest(req, sip.StatusNotAcceptable, "Not Acceptable", nil))
+		return
+	}
+	if req.CallID() == nil || req.From() == nil || req.To() == nil || req.Contact() == nil {
+		n.respond(tx, sip.NewResponseFromRequest(req, sip.StatusBadRequest, "Missing dialog headers", nil))
+		return
+	}
+
+	expires := defaultExpires
+	if h := req.GetHeader("Expires"); h != nil {
+		if v, err := strconv.Atoi(strings.TrimSpace(h.Value())); err == nil && v >= 0 {
+			expires = min(v, maxExpires)
+		}
+	}
+
+	callID := string(*req.CallID())
+	_, inDialog := req.To().Params.Get("tag")
+
+	n.mu.Lock()
+	sub, exists := n.subs[callID]
+	if inDialog && !exists {
+		n.mu.Unlock()
+		n.respond(tx, sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Subscription Does Not Exist", nil))
+		return
+	}
+
+	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+	res.AppendHeader(sip.NewHeader("Expires", strconv.Itoa(expires)))
+	res.AppendHeader(n.contact.Clone())
+
+	if !exists {
+		remoteTag, _ := req.From().Params.Get("tag")
+		localTag, _ := res.To().Params.Get("tag")
+		sub = &subscription{
+			aor:         req.Recipient.Addr(),
+			user:        req.Recipient.User,
+			callID:      callID,
+			localTag:    localTag,
+			remoteTag:   remoteTag,
+			localURI:    req.To().Address,
+			remoteURI:   req.From().Address,
+			destination: req.Source(),
+		}
+		n.subs[callID] = sub
+	}
+	sub.target = req.Contact().Address
+	n.schedule(sub, expires)
+	n.mu.Unlock()
+
+	n.respond(tx, res)
+
+	if expires == 0 {
+		slog.Info("[RegEvent] Unsubscribed", "aor", sub.aor, "call_id", callID)
+		n.terminate(sub, "timeout")
+		return
+	}
+
+	slog.Info("[RegEvent] Subscribed", "aor", sub.aor, "call_id", callID, "expires", expires)
+	n.notifyFull(sub, expires)
+}
+
+// schedule (re)arms the subscription expiry timer. Caller must hold n.mu.
+func (n *Notifier) schedule(sub *subscription, expires int) {
+	if sub.timer != nil {
+		sub.timer.Stop()
+	}
+	if expires == 0 {
+		return
+	}
+	sub.timer = time.AfterFunc(time.Duration(expires)*time.Second, func() {
+		slog.Debug("[RegEvent] Subscription expired", "aor", sub.aor, "call_id", sub.callID)
+		n.terminate(sub, "timeout")
+	})
+}
+
+// onChange sends a partial-state NOTIFY to subscribers of the binding's user.
+func (n *Notifier) onChange(change location.Change) {
+	user := aorUser(change.Binding.AOR)
+
+	n.mu.Lock()
+	var targets []*subscription
+	for _, sub := range n.subs {
+		if sub.user == user {
+			targets = append(targets, sub)
+		}
+	}
+	n.mu.Unlock()
+	if len(targets) == 0 {
+		return
+	}
+
+	// The store reports changes after applying them
+	remaining := len(n.store.LookupByUser(user))
+	for _, sub := range targets {
+		n.mu.Lock()
+		body := partialState(sub.version, sub.aor, change, remaining)
+		sub.version++
+		n.mu.Unlock()
+		n.send(sub, body, "active")
+	}
+}
+
+// notifyFull sends the complete registration state of the subscribed AOR.
+func (n *Notifier) notifyFull(sub *subscription, expires int) {
+	bindings := n.store.LookupByUser(sub.user)
+
+	n.mu.Lock()
+	body := fullState(sub.version, sub.aor, bindings)
+	sub.version++
+	n.mu.Unlock()
+
+	n.send(sub, body, "active;expires="+strconv.Itoa(expires))
+}
+
+// terminate ends a subscription with a final NOTIFY.
+func (n *Notifier) terminate(sub *subscription, reason string) {
+	n.mu.Lock()
+	if n.subs[sub.callID] != sub {
+		n.mu.Unlock()
+		return
+	}
+	delete(n.subs, sub.callID)
+	if sub.timer != nil {
+		sub.timer.Stop()
+	}
+	n.mu.Unlock()
+
+	bindings := n.store.LookupByUser(sub.user)
+	n.mu.Lock()
+	body := fullState(sub.version, sub.aor, bindings)
+	sub.version++
+	n.mu.Unlock()
+
+	n.send(sub, body, "terminated;reason="+reason)
+}
+
+// send delivers a NOTIFY in the background. A subscriber that answers 481
+// or does not answer at all loses its subscription.
+func (n *Notifier) send(sub *subscription, body []byte, state string) {
+	n.mu.Lock()
+	sub.cseq++
+	req := n.buildNOTIFY(sub, body, state)
+	n.mu.Unlock()
+	if n.identity != nil {
+		n.identity.SentRequest(req, sub.target.Host)
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+		defer cancel()
+
+		tx, err := n.client.TransactionRequest(ctx, req)
+		if err != nil {
+			slog.Warn("[RegEvent] Failed to send NOTIFY", "aor", sub.aor, "error", err)
+			return
+		}
+		defer tx.Terminate()
+
+		for {
+			select {
+			case res := <-tx.Responses():
+				if res.IsProvisional() {
+					continue
+				}
+				if res.StatusCode == sip.StatusCallTransactionDoesNotExists {
+					n.drop(sub)
+				}
+				return
+			case <-tx.Done():
+				n.drop(sub)
+				return
+			case <-ctx.Done():
+				n.drop(sub)
+				return
+			}
+		}
+	}()
+}
+
+// drop forgets a subscription without notifying the subscriber.
+func (n *Notifier) drop(sub *subscription) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if n.subs[sub.callID] == sub {
+		delete(n.subs, sub.callID)
+		if sub.timer != nil {
+			sub.timer.Stop()
+		}
+		slog.Info("[RegEvent] Subscriber unreachable, subscription removed", "aor", sub.aor, "call_id", sub.callID)
+	}
+}
+
+// buildNOTIFY builds an in-dialog NOTIFY. Caller must hold n.mu.
+func (n *Notifier) buildNOTIFY(sub *subscription, body []byte, state string) *sip.Request {
+	req := sip.NewRequest(sip.NOTIFY, sub.target)
+	req.SetDestination(sub.destination)
+
+	fromParams := sip.NewParams()
+	fromParams.Add("tag", sub.localTag)
+	req.AppendHeader(&sip.FromHeader{Address: sub.localURI, Params: fromParams})
+
+	toParams := sip.NewParams()
+	toParams.Add("tag", sub.remoteTag)
+	req.AppendHeader(&sip.ToHeader{Address: sub.remoteURI, Params: toParams})
+
+	callID := sip.CallIDHeader(sub.callID)
+	req.AppendHeader(&callID)
+	req.AppendHeader(&sip.CSeqHeader{SeqNo: sub.cseq, MethodName: sip.NOTIFY})
+	maxFwd := sip.MaxForwardsHeader(70)
+	req.AppendHeader(&maxFwd)
+	req.AppendHeader(n.contact.Clone())
+	req.AppendHeader(sip.NewHeader("Event", EventPackage))
+	req.AppendHeader(sip.NewHeader("Subscription-State", state))
+	req.AppendHeader(sip.NewHeader("Content-Type", ContentType))
+	req.SetBody(body)
+	return req
+}
+
+func (n *Notifier) respond(tx sip.ServerTransaction, res *sip.Response) {
+	if err := tx.Respond(res); err != nil {
+		slog.Error("[RegEvent] Failed to send response", "error", err)
+	}
+}
+
+// eventType strips parameters from an Event header value.
+func eventType(value string) string {
+	t, _, _ := strings.Cut(value, ";")
+	return strings.TrimSpace(t)
+}
+
+// acceptsReginfo reports whether the subscriber accepts reginfo bodies.
+// A SUBSCRIBE without Accept implies the package default (RFC 6665).
+func acceptsReginfo(req *sip.Request) bool {
+	accepts := req.GetHeaders("Accept")
+	if len(accepts) == 0 {
+		return true
+	}
+	for _, h := range accepts {
+		for _, t := range strings.Split(h.Value(), ",") {
+			t, _, _ = strings.Cut(t, ";")
+			switch strings.ToLower(strings.TrimSpace(t)) {
+			case ContentType, "application/*", "*/*":
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// aorUser extracts the user part of an AOR such as "sip:1000@domain:5060".
+func aorUser(aor string) string {
+	s := strings.TrimPrefix(strings.TrimPrefix(aor, "sips:"), "sip:")
+	s = strings.Trim(s, "<>")
+	if user, _, ok := strings.Cut(s, "@"); ok {
+		return user
+	}
+	return s
+}
@@ -0,0 +1,107 @@
+package regevent
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"time"
+
+	"github.com/sebas/switchboard/internal/bufpool"
+	"github.com/sebas/switchboard/internal/signaling/location"
+)
+
+// ContentType is the MIME type of reg event package bodies.
+const ContentType = "application/reginfo+xml"
+
+// reginfo is the RFC 3680 registration information document.
+type reginfo struct {
+	XMLName       xml.Name       `xml:"urn:ietf:params:xml:ns:reginfo reginfo"`
+	Version       int            `xml:"version,attr"`
+	State         string         `xml:"state,attr"` // "full" or "partial"
+	Registrations []registration `xml:"registration"`
+}
+
+type registration struct {
+	AOR      string    `xml:"aor,attr"`
+	ID       string    `xml:"id,attr"`
+	State    string    `xml:"state,attr"` // "init", "active" or "terminated"
+	Contacts []contact `xml:"contact"`
+}
+
+type contact struct {
+	ID      string `xml:"id,attr"`
+	State   string `xml:"state,attr"` // "active" or "terminated"
+	Event   string `xml:"event,attr"`
+	Expires int    `xml:"expires,attr,omitempty"`
+	Q       string `xml:"q,attr,omitempty"`
+	CallID  string `xml:"callid,attr,omitempty"`
+	CSeq    uint32 `xml:"cseq,attr,omitempty"`
+	URI     string `xml:"uri"`
+}
+
+// fullState describes every active binding of aor.
+func fullState(version int, aor string, bindings []*location.Binding) []byte {
+	reg := registration{AOR: aor, ID: registrationID(aor), State: "init"}
+	for _, b := range bindings {
+		reg.Contacts = append(reg.Contacts, contactFor(b, "active", "registered"))
+	}
+	if len(reg.Contacts) > 0 {
+		reg.State = "active"
+	}
+	return marshal(reginfo{Version: version, State: "full", Registrations: []registration{reg}})
+}
+
+// partialState describes a single binding change. remaining is the number
+// of bindings the AOR still has afterwards.
+func partialState(version int, aor string, change location.Change, remaining int) []byte {
+	state := "terminated"
+	if change.Active() {
+		state = "active"
+	}
+	reg := registration{
+		AOR:      aor,
+		ID:       registrationID(aor),
+		State:    "terminated",
+		Contacts: []contact{contactFor(change.Binding, state, string(change.Event))},
+	}
+	if remaining > 0 {
+		reg.State = "active"
+	}
+	return marshal(reginfo{Version: version, State: "partial", Registrations: []registration{reg}})
+}
+
+func contactFor(b *location.Binding, state, event string) contact {
+	c := contact{
+		ID:     b.BindingID,
+		State:  state,
+		Event:  event,
+		CallID: b.CallID,
+		CSeq:   b.CSeq,
+		URI:    b.ContactURI,
+	}
+	if state == "active" {
+		c.Expires = int(b.TTL().Round(time.Second).Seconds())
+	}
+	if b.QValue > 0 {
+		c.Q = fmt.Sprintf("%.3g", b.QValue)
+	}
+	return c
+}
+
+// registrationID derives a stable registration id from the AOR.
+func registrationID(aor string) string {
+	hash := sha256.Sum256([]byte(aor))
+	return hex.EncodeToString(hash[:4])
+}
+
+func marshal(doc reginfo) []byte {
+	buf := bufpool.GetBuffer()
+	defer bufpool.PutBuffer(buf)
+
+	// The document only holds strings and numbers, so encoding cannot fail
+	buf.WriteString(xml.Header)
+	_ = xml.NewEncoder(buf).Encode(doc)
+	return bytes.Clone(buf.Bytes())
+}
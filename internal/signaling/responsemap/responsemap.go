@@ -0,0 +1,128 @@
+// Package responsemap normalizes the SIP failure codes of dial targets
+// into what the caller is told: a carrier's 503 into 480, so the caller's
+// own carrier does not fail over, or a 404 into an announcement. Maps are
+// set per trunk and per dialplan route:
+//
+//	"response_map": [
+//	  {"match": ["503"], "code": 480},
+//	  {"match": ["404", "484"], "announcement": "/sounds/not-in-service.wav"},
+//	  {"match": ["5xx"], "code": 480, "reason": "Carrier Unavailable"}
+//	]
+//
+// Rules are tried in order and the first matching one applies.
+package responsemap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Rule maps failure codes to the code the caller is told and an
+// announcement played before hanging up
+type Rule struct {
+	// Match lists the codes matched, exact ("503") or by class ("5xx")
+	Match []string `json:"match"`
+
+	// Code replaces the failure code (0 keeps it)
+	Code int `json:"code,omitempty"`
+
+	// Reason is the reason phrase of Code; empty uses the standard one
+	Reason string `json:"reason,omitempty"`
+
+	// Announcement is an audio file played to the caller first
+	Announcement string `json:"announcement,omitempty"`
+}
+
+// matches reports whether the rule matches a failure code
+func (r *Rule) matches(code int) bool {
+	for _, m := range r.Match {
+		m = strings.ToLower(strings.TrimSpace(m))
+		if class, ok := strings.CutSuffix(m, "xx"); ok {
+			if class == strconv.Itoa(code/100) {
+				return true
+			}
+		} else if m == strconv.Itoa(code) {
+			return true
+		}
+	}
+	return false
+}
+
+// Map is an ordered list of rules. The zero Map maps nothing.
+type Map []Rule
+
+// Validate checks the rules of a map
+func (m Map) Validate() error {
+	for i, r := range m {
+		if len(r.Match) == 0 {
+			return fmt.Errorf("response map rule %d: match required", i+1)
+		}
+		for _, match := range r.Match {
+			if !validMatch(strings.ToLower(strings.TrimSpace(match))) {
+				return fmt.Errorf("response map rule %d: invalid match %q (want a 4xx-6xx code or class)", i+1, match)
+			}
+		}
+		if r.Code != 0 && (r.Code < 400 || r.Code > 699) {
+			return fmt.Errorf("response map rule %d: code %d is not a failure code", i+1, r.Code)
+		}
+		if r.Code == 0 && r.Announcement == "" {
+			return fmt.Errorf("response map rule %d: code or announcement required", i+1)
+		}
+	}
+	return nil
+}
+
+// validMatch reports whether a match is a failure code or class
+func validMatch(m string) bool {
+	if class, ok := strings.CutSuffix(m, "xx"); ok {
+		return class == "4" || class == "5" || class == "6"
+	}
+	code, err := strconv.Atoi(m)
+	return err == nil && code >= 400 && code <= 699
+}
+
+// Lookup returns the first rule matching a failure code
+func (m Map) Lookup(code int) (Rule, bool) {
+	for i := range m {
+		if m[i].matches(code) {
+			return m[i], true
+		}
+	}
+	return Rule{}, false
+}
+
+// Apply returns the code and reason phrase the caller is told for a
+// failure code and phrase
+func (r Rule) Apply(code int, reason string) (int, string) {
+	switch {
+	case r.Code == 0:
+		return code, reason
+	case r.Reason != "":
+		return r.Code, r.Reason
+	case r.Code == code:
+		return code, reason
+	}
+	return r.Code, reasonPhrase[r.Code]
+}
+
+// reasonPhrase is the standard reason phrase of common failure codes
+var reasonPhrase = map[int]string{
+	400: "Bad Request",
+	403: "Forbidden",
+	404: "Not Found",
+	408: "Request Timeout",
+	410: "Gone",
+	480: "Temporarily Unavailable",
+	484: "Address Incomplete",
+	486: "Busy Here",
+	487: "Request Terminated",
+	488: "Not Acceptable Here",
+	500: "Server Internal Error",
+	502: "Bad Gateway",
+	503: "Service Unavailable",
+	504: "Server Time-out",
+	600: "Busy Everywhere",
+	603: "Decline",
+	604: "Does Not Exist Anywhere",
+}
@@ -0,0 +1,48 @@
+package responsemap
+
+import "testing"
+
+func TestMapLookup(t *testing.T) {
+	m := Map{
+		{Match: []string{"503"}, Code: 480},
+		{Match: []string{"404"}, Announcement: "not-in-service.wav"},
+		{Match: []string{"5XX"}, Code: 480, Reason: "Carrier Unavailable"},
+	}
+	if err := m.Validate(); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		code, want int
+		reason     string
+	}{
+		{503, 480, "Temporarily Unavailable"},
+		{404, 404, "Not Found"},
+		{502, 480, "Carrier Unavailable"},
+	}
+	for _, tt := range tests {
+		rule, ok := m.Lookup(tt.code)
+		if !ok {
+			t.Errorf("Lookup(%d) found no rule", tt.code)
+			continue
+		}
+		if code, reason := rule.Apply(tt.code, "Not Found"); code != tt.want || reason != tt.reason {
+			t.Errorf("Apply(%d) = %d %q, want %d %q", tt.code, code, reason, tt.want, tt.reason)
+		}
+	}
+	if _, ok := m.Lookup(486); ok {
+		t.Error("Lookup(486) matched")
+	}
+
+	for _, bad := range []Map{
+		{{Code: 480}},
+		{{Match: []string{"200"}, Code: 480}},
+		{{Match: []string{"3xx"}, Code: 480}},
+		{{Match: []string{"503"}, Code: 200}},
+		{{Match: []string{"503"}}},
+	} {
+		if err := bad.Validate(); err == nil {
+			t.Errorf("Validate(%+v) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,220 @@
+// Package routestats counts the outcome of calls per dialplan rule and per
+// trunk, with the distribution of their failure codes, so that a bad route
+// (such as a trunk answering every INVITE with 503) stands out.
+//
+// Outcomes are counted since the start or the last reset, and over a
+// recent window so that a route that just went bad is not hidden by its
+// history.
+package routestats
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/kpi"
+)
+
+// Kinds of routes
+const (
+	KindRule  = "rule"  // Dialplan route, by ID
+	KindTrunk = "trunk" // Outbound destination of a B2BUA leg, by KPI route
+)
+
+// DefaultWindow is the recent window used when none is given
+const DefaultWindow = 15 * time.Minute
+
+// Window counts the outcomes of a recent period
+type Window struct {
+	Attempts    int64   `json:"attempts"`
+	Failed      int64   `json:"failed"`
+	FailureRate float64 `json:"failure_rate"` // Percent of attempts, cancels excluded
+}
+
+// Stats are the outcomes of one route
+type Stats struct {
+	Kind            string        `json:"kind"`
+	Name            string        `json:"name"`
+	Attempts        int64         `json:"attempts"`
+	Succeeded       int64         `json:"succeeded"`
+	Failed          int64         `json:"failed"`
+	Canceled        int64         `json:"canceled"` // By the caller (487), neither success nor failure
+	FailureRate     float64       `json:"failure_rate"`
+	FailureCodes    map[int]int64 `json:"failure_codes,omitempty"`
+	Recent          Window        `json:"recent"`
+	LastSuccess     time.Time     `json:"last_success"` // Zero if never
+	LastFailure     time.Time     `json:"last_failure"` // Zero if never
+	LastFailureCode int           `json:"last_failure_code,omitempty"`
+}
+
+// slot counts the outcomes of one minute of the recent window
+type slot struct {
+	minute   int64
+	attempts int64
+	failed   int64
+	canceled int64
+}
+
+// entry accumulates the outcomes of one route
+type entry struct {
+	Stats
+	recent []slot // Ring of per-minute slots
+}
+
+type key struct {
+	kind string
+	name string
+}
+
+// Tracker counts route outcomes.
+// Thread Safety: All methods are safe for concurrent use.
+type Tracker struct {
+	window time.Duration
+
+	mu     sync.Mutex
+	routes map[key]*entry
+	now    func() time.Time
+}
+
+// NewTracker creates a tracker with a recent window of window (rounded up
+// to whole minutes; DefaultWindow if not positive).
+func NewTracker(window time.Duration) *Tracker {
+	if window <= 0 {
+		window = DefaultWindow
+	}
+	return &Tracker{
+		window: window,
+		routes: make(map[key]*entry),
+		now:    time.Now,
+	}
+}
+
+// Window returns the recent window
+func (t *Tracker) Window() time.Duration {
+	return t.window
+}
+
+// Record counts a call on a route ended with a final SIP code: 2xx
+// succeeded, 487 was canceled by the caller, anything else failed.
+func (t *Tracker) Record(kind, name string, code int) {
+	if name == "" {
+		return
+	}
+	now := t.now()
+	minute := now.Truncate(time.Minute).Unix()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	e := t.routes[key{kind, name}]
+	if e == nil {
+		e = &entry{
+			Stats:  Stats{Kind: kind, Name: name, FailureCodes: make(map[int]int64)},
+			recent: make([]slot, t.slots()),
+		}
+		t.routes[key{kind, name}] = e
+	}
+	s := &e.recent[int(minute/60)%len(e.recent)]
+	if s.minute != minute {
+		*s = slot{minute: minute}
+	}
+
+	e.Attempts++
+	s.attempts++
+	switch {
+	case code >= 200 && code < 300:
+		e.Succeeded++
+		e.LastSuccess = now
+	case code == 487:
+		e.Canceled++
+		s.canceled++
+	default:
+		e.Failed++
+		s.failed++
+		e.FailureCodes[code]++
+		e.LastFailure = now
+		e.LastFailureCode = code
+	}
+}
+
+// RecordAttempt counts an outbound leg on its trunk. Implements
+// b2bua.KPIRecorder.
+func (t *Tracker) RecordAttempt(a kpi.Attempt) {
+	code := a.SIPCode
+	if !a.Answered.IsZero() {
+		code = 200
+	}
+	t.Record(KindTrunk, a.Route, code)
+}
+
+// RecordRoute counts a call routed by a dialplan rule. Implements
+// dialplan.RouteRecorder.
+func (t *Tracker) RecordRoute(routeID string, code int) {
+	t.Record(KindRule, routeID, code)
+}
+
+// List returns the stats of the routes of a kind (all kinds when empty),
+// sorted by kind and name.
+func (t *Tracker) List(kind string) []Stats {
+	oldest := t.now().Add(-t.window).Truncate(time.Minute).Unix()
+
+	t.mu.Lock()
+	list := make([]Stats, 0, len(t.routes))
+	for k, e := range t.routes {
+		if kind != "" && k.kind != kind {
+			continue
+		}
+		st := e.Stats
+		st.FailureCodes = make(map[int]int64, len(e.FailureCodes))
+		for code, n := range e.FailureCodes {
+			st.FailureCodes[code] = n
+		}
+		var canceled int64
+		for _, s := range e.recent {
+			if s.minute > oldest {
+				st.Recent.Attempts += s.attempts
+				st.Recent.Failed += s.failed
+				canceled += s.canceled
+			}
+		}
+		st.FailureRate = rate(st.Failed, st.Attempts-st.Canceled)
+		st.Recent.FailureRate = rate(st.Recent.Failed, st.Recent.Attempts-canceled)
+		list = append(list, st)
+	}
+	t.mu.Unlock()
+
+	sort.Slice(list, func(i, j int) bool {
+		if list[i].Kind != list[j].Kind {
+			return list[i].Kind < list[j].Kind
+		}
+		return list[i].Name < list[j].Name
+	})
+	return list
+}
+
+// Reset forgets the stats of a route, of every route of a kind when name
+// is empty, or of every route when both are empty. Returns how many routes
+// were reset.
+func (t *Tracker) Reset(kind, name string) int {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	n := 0
+	for k := range t.routes {
+		if (kind == "" || k.kind == kind) && (name == "" || k.name == name) {
+			delete(t.routes, k)
+			n++
+		}
+	}
+	return n
+}
+
+// slots returns the number of minutes in the recent window
+func (t *Tracker) slots() int {
+	return int((t.window + time.Minute - 1) / time.Minute)
+}
+
+func rate(n, total int64) float64 {
+	if total <= 0 {
+		return 0
+	}
+	return float64(int64(float64(n)*10000/float64(total)+0.5)) / 100
+}
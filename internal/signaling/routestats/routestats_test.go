@@ -0,0 +1,50 @@
+package routestats
+
+import (
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/kpi"
+)
+
+func TestTrackerCountsOutcomes(t *testing.T) {
+	tr := NewTracker(15 * time.Minute)
+	now := time.Now().Truncate(time.Hour)
+	tr.now = func() time.Time { return now }
+
+	// An hour ago: one answered, two 503s
+	tr.RecordAttempt(kpi.Attempt{Route: "sip.carrier.net", Answered: now, SIPCode: 200})
+	tr.RecordAttempt(kpi.Attempt{Route: "sip.carrier.net", SIPCode: 503})
+	tr.RecordAttempt(kpi.Attempt{Route: "sip.carrier.net", SIPCode: 503})
+
+	// Now: a 404 and a cancel
+	now = now.Add(time.Hour)
+	tr.RecordAttempt(kpi.Attempt{Route: "sip.carrier.net", SIPCode: 404})
+	tr.RecordAttempt(kpi.Attempt{Route: "sip.carrier.net", SIPCode: 487})
+	tr.RecordRoute("outbound", 200)
+
+	list := tr.List(KindTrunk)
+	if len(list) != 1 {
+		t.Fatalf("trunks = %+v, want one", list)
+	}
+	st := list[0]
+	if st.Attempts != 5 || st.Succeeded != 1 || st.Failed != 3 || st.Canceled != 1 {
+		t.Fatalf("counts = %+v, want 5 attempts, 1 succeeded, 3 failed, 1 canceled", st)
+	}
+	if st.FailureCodes[503] != 2 || st.FailureCodes[404] != 1 || st.LastFailureCode != 404 {
+		t.Fatalf("failure codes = %v (last %d), want 503x2, 404x1 (last 404)", st.FailureCodes, st.LastFailureCode)
+	}
+	if st.FailureRate != 75 {
+		t.Fatalf("failure rate = %v, want 75", st.FailureRate)
+	}
+	if st.Recent != (Window{Attempts: 2, Failed: 1, FailureRate: 100}) {
+		t.Fatalf("recent = %+v, want the last two attempts only", st.Recent)
+	}
+
+	if n := len(tr.List("")); n != 2 {
+		t.Fatalf("all routes = %d, want 2", n)
+	}
+	if n := tr.Reset(KindRule, ""); n != 1 || len(tr.List(KindRule)) != 0 {
+		t.Fatalf("reset rules = %d, want the rule gone", n)
+	}
+}
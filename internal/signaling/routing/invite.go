@@ -1,7 +1,9 @@
 package routing
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"log/slog"
@@ -9,12 +11,21 @@ import (
 	"time"
 
 	"github.com/emiago/sipgo/sip"
-	psdp "github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/signaling/agents"
 	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/codecs"
 	"github.com/sebas/switchboard/internal/signaling/dialog"
 	"github.com/sebas/switchboard/internal/signaling/dialplan"
+	"github.com/sebas/switchboard/internal/signaling/features"
 	"github.com/sebas/switchboard/internal/signaling/location"
 	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+	"github.com/sebas/switchboard/internal/signaling/moh"
+	"github.com/sebas/switchboard/internal/signaling/screening"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+	"github.com/sebas/switchboard/internal/signaling/sipreason"
+	"github.com/sebas/switchboard/internal/signaling/trunks"
+	"github.com/sebas/switchboard/internal/signaling/tts"
 )
 
 // SessionRecorder records session info for the API
@@ -32,6 +43,12 @@ type InviteHandler struct {
 	executor        *dialplan.Executor
 	locStore        location.LocationStore
 	callService     b2bua.CallService
+	tts             *tts.Engine
+	moh             *moh.Registry
+	screener        *screening.Screener
+	features        *features.Store
+	codecs          *codecs.Policies
+	agents          *agents.Registry
 }
 
 // NewInviteHandler creates a new INVITE handler
@@ -57,10 +74,124 @@ func NewInviteHandler(
 	}
 }
 
+// SetTTS sets the text-to-speech engine used by dialplan say actions
+func (h *InviteHandler) SetTTS(engine *tts.Engine) {
+	h.tts = engine
+}
+
+// SetMOH sets the music-on-hold classes used by dialplan music_on_hold actions
+func (h *InviteHandler) SetMOH(registry *moh.Registry) {
+	h.moh = registry
+}
+
+// SetScreener sets the blocklists checked before the dialplan runs
+func (h *InviteHandler) SetScreener(screener *screening.Screener) {
+	h.screener = screener
+}
+
+// SetFeatures sets the per-user call features applied to the called user
+func (h *InviteHandler) SetFeatures(store *features.Store) {
+	h.features = store
+}
+
+// SetCodecs sets the codec policies that filter and order the codecs
+// offered by callers
+func (h *InviteHandler) SetCodecs(policies *codecs.Policies) {
+	h.codecs = policies
+}
+
+// SetAgents sets the agent states changed by agent feature codes and
+// respected by first_available actions
+func (h *InviteHandler) SetAgents(registry *agents.Registry) {
+	h.agents = registry
+}
+
 // HandleINVITE processes incoming INVITE requests
 func (h *InviteHandler) HandleINVITE(req *sip.Request, tx sip.ServerTransaction) {
 	slog.Info("Received INVITE", "from", req.From(), "to", req.To(), "call_id", req.CallID())
 
+	// A To tag means a re-INVITE within an existing dialog
+	if _, inDialog := req.To().Params.Get("tag"); inDialog {
+		if err := h.dialogMgr.HandleIncomingReINVITE(req, tx); err != nil {
+			slog.Warn("Failed to handle re-INVITE", "call_id", req.CallID(), "error", err)
+		}
+		return
+	}
+
+	// Screen the caller before any dialog or media is set up
+	var override *dialplan.Route
+	if verdict := h.screen(req); verdict != nil {
+		switch verdict.Action {
+		case screening.ActionReject:
+			decline := sip.NewResponseFromRequest(req, sip.StatusGlobalDecline, "Decline", nil)
+			if err := tx.Respond(decline); err != nil {
+				slog.Error("Failed to send 603 Decline", "error", err)
+			}
+			return
+		case screening.ActionDrop:
+			return
+		case screening.ActionAnnouncement:
+			override = announcementRoute(verdict)
+		}
+	}
+
+	// Feature codes dialed from the phone (e.g. *78 Do Not Disturb on)
+	if override == nil && h.features != nil {
+		if action, arg, ok := h.features.Code(h.extractDestination(req)); ok && features.IsPickup(action) {
+			if action == features.CodePickupDirected && arg == "" {
+				_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusAddressIncomplete, "Address Incomplete", nil))
+				return
+			}
+			override = pickupRoute(arg)
+		} else if ok && features.IsAgent(action) {
+			if err := h.applyAgentCode(req, action); err != nil {
+				slog.Warn("[Agents] Agent feature code refused", "action", action, "call_id", req.CallID(), "error", err)
+				_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusForbidden, "Forbidden", nil))
+				return
+			}
+			override = featureCodeRoute(action)
+		} else if ok {
+			if err := h.applyFeatureCode(req, action, arg); err != nil {
+				slog.Error("[Features] Feature code failed", "action", action, "call_id", req.CallID(), "error", err)
+				failed := sip.NewResponseFromRequest(req, sip.StatusInternalServerError, "Server Internal Error", nil)
+				if errors.Is(err, features.ErrTargetRequired) {
+					failed = sip.NewResponseFromRequest(req, sip.StatusAddressIncomplete, "Address Incomplete", nil)
+				}
+				_ = tx.Respond(failed)
+				return
+			}
+			override = featureCodeRoute(action)
+		}
+	}
+
+	// Apply the called user's anonymous call rejection
+	if override == nil {
+		settings, anonymous := h.rejectAnonymous(req)
+		if anonymous {
+			if settings.AnonymousAction != features.AnonymousVoicemail {
+				// sipgo has no constant for 433 (RFC 5079)
+				reject := sip.NewResponseFromRequest(req, 433, "Anonymity Disallowed", nil)
+				if err := tx.Respond(reject); err != nil {
+					slog.Error("Failed to send 433 Anonymity Disallowed", "error", err)
+				}
+				return
+			}
+			override = voicemailRoute(settings)
+		}
+	}
+
+	// Refuse destinations the caller's class of service does not permit,
+	// unless they have a PIN to override it once answered
+	if override == nil {
+		if class, restricted := h.restricted(req); restricted {
+			forbidden := sip.NewResponseFromRequest(req, sip.StatusForbidden, "Forbidden - "+class.Name+" calls not permitted", nil)
+			if err := tx.Respond(forbidden); err != nil {
+				slog.Error("Failed to send 403 Forbidden", "error", err)
+			}
+			return
+		}
+	}
+
 	// Create dialog via manager
 	dlg, err := h.dialogMgr.CreateFromInvite(req, tx)
 	if err != nil {
@@ -92,13 +223,31 @@ func (h *InviteHandler) HandleINVITE(req *sip.Request, tx sip.ServerTransaction)
 		return
 	}
 
+	// Keep the offered codecs the caller's policy allows, in its order
+	allowedCodecs, err := h.allowedCodecs(req, offeredCodecs)
+	if err != nil {
+		slog.Warn("[Codecs] No offered codec allowed", "call_id", req.CallID(), "offered", offeredCodecs)
+		notAcceptable := sip.NewResponseFromRequest(req, sip.StatusNotAcceptableHere, "Not Acceptable Here", nil)
+		_ = tx.Respond(notAcceptable)
+		_ = h.dialogMgr.Terminate(dlg.CallID, dialog.ReasonError)
+		return
+	}
+
 	// Create media session via transport (this returns SDP)
 	sessionResult, err := h.transport.CreateSession(context.Background(), mediaclient.SessionInfo{
 		CallID:        dlg.CallID,
 		RemoteAddr:    clientAddr,
 		RemotePort:    clientPort,
-		OfferedCodecs: offeredCodecs,
+		OfferedCodecs: allowedCodecs,
 	})
+	var rejected *mediaclient.NegotiationError
+	if errors.As(err, &rejected) {
+		slog.Warn("[Codecs] Offer rejected by the RTP manager", "call_id", req.CallID(), "offered", allowedCodecs, "error", err)
+		notAcceptable := sip.NewResponseFromRequest(req, sip.StatusNotAcceptableHere, "Not Acceptable Here", nil)
+		_ = tx.Respond(notAcceptable)
+		_ = h.dialogMgr.Terminate(dlg.CallID, dialog.ReasonError)
+		return
+	}
 	if err != nil {
 		slog.Error("Failed to create media session", "error", err)
 		notAcceptable := sip.NewResponseFromRequest(req, sip.StatusNotAcceptable, "Not Acceptable - "+err.Error(), nil)
@@ -116,8 +265,13 @@ func (h *InviteHandler) HandleINVITE(req *sip.Request, tx sip.ServerTransaction)
 		h.sessionRecorder.RecordSession(dlg.CallID, clientAddr, clientPort, sessionResult.LocalAddr, sessionResult.LocalPort)
 	}
 
+	// Answer every stream offered: the RTP manager's answer covers audio
+	// only, and the callee is not dialed yet, so anything else offered is
+	// rejected for now (it may be relayed once the call is bridged)
+	answer := layoutAnswer(req.Body(), sessionResult.SDPBody)
+
 	// Send 183 Session Progress with SDP (early media)
-	if err := h.dialogMgr.SendProgress(dlg, sessionResult.SDPBody); err != nil {
+	if err := h.dialogMgr.SendProgress(dlg, answer); err != nil {
 		slog.Error("Failed to send 183 Session Progress", "error", err)
 	}
 
@@ -127,7 +281,7 @@ func (h *InviteHandler) HandleINVITE(req *sip.Request, tx sip.ServerTransaction)
 	time.Sleep(500 * time.Millisecond)
 
 	// Send 200 OK (this also creates the sipgo session)
-	if err := h.dialogMgr.SendOK(dlg, sessionResult.SDPBody); err != nil {
+	if err := h.dialogMgr.SendOK(dlg, answer); err != nil {
 		slog.Error("Failed to send 200 OK", "error", err)
 		_ = h.transport.DestroySession(context.Background(), sessionResult.SessionID, mediaclient.TerminateReasonError)
 		_ = h.dialogMgr.Terminate(dlg.CallID, dialog.ReasonError)
@@ -140,46 +294,280 @@ func (h *InviteHandler) HandleINVITE(req *sip.Request, tx sip.ServerTransaction)
 	destination := h.extractDestination(req)
 
 	// Execute dialplan
-	go h.executeDialplan(dlg, destination)
+	go h.executeDialplan(dlg, destination, override)
+}
+
+// screen checks the caller against the blocklists. Emergency calls are
+// never screened. Returns nil if the call may proceed.
+func (h *InviteHandler) screen(req *sip.Request) *screening.Verdict {
+	if h.screener == nil {
+		return nil
+	}
+	caller := h.extractCallerID(req)
+	if caller == "" {
+		return nil
+	}
+	verdict, blocked := h.screener.Screen(caller)
+	if !blocked {
+		return nil
+	}
+	if h.executor.IsEmergency(h.extractDestination(req)) {
+		slog.Warn("[Screening] Blocked caller dialed an emergency number, not screening",
+			"caller", caller,
+			"list", verdict.List,
+			"call_id", req.CallID(),
+		)
+		return nil
+	}
+	slog.Info("[Screening] Caller blocked",
+		"caller", caller,
+		"list", verdict.List,
+		"pattern", verdict.Entry.Pattern,
+		"action", verdict.Action,
+		"call_id", req.CallID(),
+	)
+	return verdict
+}
+
+// announcementRoute builds the route played to a caller blocked by an
+// announcement list: the announcement, then hangup.
+func announcementRoute(verdict *screening.Verdict) *dialplan.Route {
+	play, _ := json.Marshal(map[string]string{"file": verdict.Announcement})
+	hangup, _ := json.Marshal(map[string]string{"reason": "blocked by " + verdict.List})
+	return &dialplan.Route{
+		ID:      "screening:" + verdict.List,
+		Name:    "Blocked caller announcement",
+		Enabled: true,
+		Actions: []dialplan.ActionConfig{
+			{Type: "play_audio", Params: play},
+			{Type: "hangup", Params: hangup},
+		},
+	}
+}
+
+// rejectAnonymous reports whether the called user rejects anonymous
+// calls and this call is anonymous. Emergency calls are never rejected.
+func (h *InviteHandler) rejectAnonymous(req *sip.Request) (features.Settings, bool) {
+	if h.features == nil {
+		return features.Settings{}, false
+	}
+	destination := h.extractDestination(req)
+	settings := h.features.Get(destination)
+	if !settings.RejectAnonymous || !isAnonymous(req) {
+		return settings, false
+	}
+	if h.executor.IsEmergency(destination) {
+		return settings, false
+	}
+	slog.Info("[Features] Anonymous call rejected",
+		"user", destination,
+		"action", cmp.Or(settings.AnonymousAction, features.AnonymousReject),
+		"call_id", req.CallID(),
+	)
+	return settings, true
+}
+
+// restricted reports whether the caller's class of service does not
+// permit the destination and they have no PIN to override it with.
+func (h *InviteHandler) restricted(req *sip.Request) (*dialplan.DestinationClass, bool) {
+	caller := h.extractCallerID(req)
+	class, restricted := h.executor.Restricted(caller, h.extractDestination(req))
+	if !restricted {
+		return nil, false
+	}
+	var settings features.Settings
+	if h.features != nil {
+		settings = h.features.Get(caller)
+	}
+	if settings.PIN != "" {
+		return nil, false
+	}
+	slog.Info("[Features] Call refused by class of service",
+		"caller_id", caller,
+		"class", class.Name,
+		"requires", class.Requires,
+		"class_of_service", cmp.Or(settings.ClassOfService, features.ClassInternational),
+		"call_id", req.CallID(),
+	)
+	return class, true
+}
+
+// applyFeatureCode performs a feature code action for the calling user.
+func (h *InviteHandler) applyFeatureCode(req *sip.Request, action, arg string) error {
+	caller := h.extractCallerID(req)
+	if caller == "" {
+		return fmt.Errorf("no caller user")
+	}
+	settings, err := h.features.ApplyCode(caller, action, arg)
+	if err != nil {
+		return err
+	}
+	slog.Info("[Features] Feature code applied",
+		"user", caller,
+		"action", action,
+		"dnd", settings.DND,
+		"forward_always", settings.ForwardAlways,
+		"forward_busy", settings.ForwardBusy,
+		"forward_no_answer", settings.ForwardNoAnswer,
+		"call_id", req.CallID(),
+	)
+	return nil
+}
+
+// applyAgentCode changes the agent state of the calling user.
+func (h *InviteHandler) applyAgentCode(req *sip.Request, action string) error {
+	if h.agents == nil {
+		return fmt.Errorf("no agents configured")
+	}
+	state := agents.StateAvailable
+	switch action {
+	case features.CodeAgentLogout:
+		state = agents.StateLoggedOut
+	case features.CodeAgentWrapUp:
+		state = agents.StateWrapUp
+	}
+	_, err := h.agents.SetState(h.extractCallerID(req), state, 0)
+	return err
+}
+
+// featureCodeConfirmTone is the stutter tone played after a feature code.
+const featureCodeConfirmTone = "350+440/100,0/100"
+
+// featureCodeRoute builds the route that confirms a feature code to the
+// caller: a short stutter tone, then hangup.
+func featureCodeRoute(action string) *dialplan.Route {
+	tone, _ := json.Marshal(dialplan.PlayToneParams{Tone: featureCodeConfirmTone, Duration: 1})
+	hangup, _ := json.Marshal(map[string]string{"reason": "feature code " + action})
+	return &dialplan.Route{
+		ID:      "features:code:" + action,
+		Name:    "Feature code confirmation",
+		Enabled: true,
+		Actions: []dialplan.ActionConfig{
+			{Type: "play_tone", Params: tone},
+			{Type: "hangup", Params: hangup},
+		},
+	}
+}
+
+// pickupRoute builds the route of a pickup feature code: the call ringing
+// extension, or the caller's pickup group if empty, is answered, then the
+// caller is hung up once it ends.
+func pickupRoute(extension string) *dialplan.Route {
+	pickup, _ := json.Marshal(dialplan.PickupParams{Extension: extension})
+	hangup, _ := json.Marshal(map[string]string{"reason": "pickup"})
+	return &dialplan.Route{
+		ID:      "features:pickup",
+		Name:    "Call pickup",
+		Enabled: true,
+		Actions: []dialplan.ActionConfig{
+			{Type: "pickup", Params: pickup},
+			{Type: "hangup", Params: hangup},
+		},
+	}
+}
+
+// isAnonymous reports whether the caller withheld their identity: a
+// Privacy header requesting "id" (RFC 3325) or an anonymous From (RFC 3323).
+func isAnonymous(req *sip.Request) bool {
+	if privacy := req.GetHeader("Privacy"); privacy != nil {
+		for _, value := range strings.FieldsFunc(privacy.Value(), func(r rune) bool { return r == ';' || r == ',' }) {
+			if strings.EqualFold(strings.TrimSpace(value), "id") {
+				return true
+			}
+		}
+	}
+	from := req.From()
+	if from == nil {
+		return false
+	}
+	return strings.EqualFold(from.Address.User, "anonymous") ||
+		strings.EqualFold(from.Address.Host, "anonymous.invalid")
+}
+
+// voicemailRoute builds the route that sends a rejected anonymous caller
+// to the called user's voicemail.
+func voicemailRoute(settings features.Settings) *dialplan.Route {
+	dial, _ := json.Marshal(dialplan.DialParams{Target: settings.Voicemail})
+	return &dialplan.Route{
+		ID:      "features:acr:" + settings.User,
+		Name:    "Anonymous caller to voicemail",
+		Enabled: true,
+		Actions: []dialplan.ActionConfig{
+			{Type: "dial", Params: dial},
+		},
+	}
 }
 
 // extractSDPInfo parses SDP to get client endpoint and offered codecs
 func (h *InviteHandler) extractSDPInfo(req *sip.Request) (clientAddr string, clientPort int, codecs []string, err error) {
-	callID := req.CallID()
-
 	if req.Body() == nil {
 		return "", 0, nil, fmt.Errorf("no SDP body in INVITE")
 	}
 
-	// Parse SDP
-	sdpObj := &psdp.SessionDescription{}
-	if err := sdpObj.Unmarshal(req.Body()); err != nil {
-		return "", 0, nil, fmt.Errorf("failed to parse SDP: %w", err)
+	clientAddr, clientPort, codecs, err = ParseOffer(req.Body())
+	if err != nil {
+		return "", 0, nil, err
 	}
 
-	if len(sdpObj.MediaDescriptions) == 0 {
-		return "", 0, nil, fmt.Errorf("no media descriptions in SDP")
-	}
+	slog.Info("[SDP] Parsed media", "callID", req.CallID(), "port", clientPort, "codecs", codecs)
+	return clientAddr, clientPort, codecs, nil
+}
 
-	// Get first media (audio)
-	mediaDesc := sdpObj.MediaDescriptions[0]
-	clientPort = mediaDesc.MediaName.Port.Value
-	codecs = mediaDesc.MediaName.Formats
+// allowedCodecs returns the offered payload types the codec policy of the
+// caller allows, in its order of preference; all of them without
+// policies. An error means none is allowed.
+func (h *InviteHandler) allowedCodecs(req *sip.Request, offered []string) ([]string, error) {
+	if h.codecs == nil {
+		return offered, nil
+	}
+	sourceIP, _ := parseSourceAddr(req.Source())
+	policy := h.codecs.Inbound(h.extractCallerID(req), sourceIP, middleware.Annotation(req, trunks.Annotation))
+	if policy == nil {
+		return offered, nil
+	}
+	offer, err := sdpmedia.Parse(req.Body())
+	if err != nil || offer.Audio() == nil {
+		return offered, nil
+	}
+	allowed, err := policy.Offered(offer.Audio())
+	if err != nil {
+		return nil, err
+	}
+	slog.Debug("[Codecs] Offered codecs filtered", "call_id", req.CallID(), "policy", policy, "allowed", allowed)
+	return allowed, nil
+}
 
-	slog.Info("[SDP] Parsed media", "callID", callID, "media", mediaDesc.MediaName.Media, "port", clientPort, "codecs", codecs)
+// layoutAnswer returns the answer to an offer from the RTP manager's: its
+// audio negotiated with the offered audio's formats, ptime and direction,
+// laid out like the offer with the other streams rejected. An answer that
+// cannot be negotiated is sent as is.
+func layoutAnswer(offer, rtpAnswer []byte) []byte {
+	answer, err := sdpmedia.Answer(offer, rtpAnswer)
+	if err != nil {
+		slog.Warn("[SDP] RTP manager answer sent as is", "error", err)
+		return rtpAnswer
+	}
+	return answer
+}
 
-	// Get client address from SDP connection information
-	if mediaDesc.ConnectionInformation != nil && mediaDesc.ConnectionInformation.Address != nil {
-		clientAddr = mediaDesc.ConnectionInformation.Address.Address
-	} else if sdpObj.ConnectionInformation != nil && sdpObj.ConnectionInformation.Address != nil {
-		clientAddr = sdpObj.ConnectionInformation.Address.Address
+// ParseOffer returns the RTP address, port and offered payload types of
+// the first audio stream of an SDP offer, the one the RTP manager
+// anchors.
+func ParseOffer(body []byte) (addr string, port int, codecs []string, err error) {
+	offer, err := sdpmedia.Parse(body)
+	if err != nil {
+		return "", 0, nil, fmt.Errorf("failed to parse SDP: %w", err)
 	}
 
-	if clientAddr == "" {
+	audio := offer.Audio()
+	if audio == nil {
+		return "", 0, nil, fmt.Errorf("no audio media description in SDP")
+	}
+	if audio.Addr == "" {
 		return "", 0, nil, fmt.Errorf("no client address in SDP")
 	}
 
-	return clientAddr, clientPort, codecs, nil
+	return audio.Addr, audio.Port, audio.Payloads(), nil
 }
 
 // extractDestination extracts the destination from the To header.
@@ -220,8 +608,9 @@ func (h *InviteHandler) extractCallerName(req *sip.Request) string {
 	return ""
 }
 
-// executeDialplan runs the dialplan for the call.
-func (h *InviteHandler) executeDialplan(dlg *dialog.Dialog, destination string) {
+// executeDialplan runs the dialplan for the call, or the override route
+// (blocked caller announcement, anonymous caller voicemail) when set.
+func (h *InviteHandler) executeDialplan(dlg *dialog.Dialog, destination string, override *dialplan.Route) {
 	callerID := ""
 	callerName := ""
 	if dlg.InviteRequest != nil {
@@ -236,6 +625,10 @@ func (h *InviteHandler) executeDialplan(dlg *dialog.Dialog, destination string)
 		DialogMgr:   h.dialogMgr,
 		LocStore:    h.locStore,
 		CallService: h.callService,
+		TTS:         h.tts,
+		MOH:         h.moh,
+		Features:    h.features,
+		Agents:      h.agents,
 		Logger:      slog.Default(),
 		Destination: destination,
 		CallerID:    callerID,
@@ -243,7 +636,12 @@ func (h *InviteHandler) executeDialplan(dlg *dialog.Dialog, destination string)
 	})
 
 	// Execute dialplan
-	err := h.executor.Execute(dlg.Context(), session)
+	var err error
+	if override != nil {
+		err = h.executor.ExecuteRoute(dlg.Context(), session, override)
+	} else {
+		err = h.executor.Execute(dlg.Context(), session)
+	}
 	if err != nil {
 		if !errors.Is(err, context.Canceled) {
 			slog.Error("[Routing] Dialplan execution failed",
@@ -254,6 +652,12 @@ func (h *InviteHandler) executeDialplan(dlg *dialog.Dialog, destination string)
 		}
 	}
 
+	// A failed dial tells the caller its (mapped) failure code
+	var dialErr *dialplan.DialError
+	if errors.As(err, &dialErr) && dialErr.SIPCode >= 400 {
+		dlg.SetHangupReason(sipreason.SIP(dialErr.SIPCode, dialErr.SIPReason))
+	}
+
 	// Terminate dialog after dialplan completes (if not already terminated)
 	if !dlg.IsTerminated() {
 		slog.Info("[Routing] Dialplan complete, terminating dialog", "call_id", dlg.CallID)
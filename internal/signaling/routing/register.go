@@ -10,6 +10,7 @@ import (
 
 	"github.com/emiago/sipgo/sip"
 	"github.com/sebas/switchboard/internal/signaling/location"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
 )
 
 // StatusIntervalTooBrief is the SIP status code 423 per RFC 3261.
@@ -22,6 +23,10 @@ const StatusIntervalTooBrief sip.StatusCode = 423
 type RegisterHandler struct {
 	locationStore location.LocationStore
 	realm         string
+
+	// serviceRoute returns the Service-Route URI (RFC 3608) to give a
+	// registering peer; nil omits the header
+	serviceRoute func(peer string) sip.Uri
 }
 
 // NewRegisterHandler creates a new REGISTER handler.
@@ -32,6 +37,12 @@ func NewRegisterHandler(locationStore location.LocationStore, realm string) *Reg
 	}
 }
 
+// SetServiceRoute enables the Service-Route header in 200 OK responses.
+// route returns the URI for the peer at the given source address.
+func (h *RegisterHandler) SetServiceRoute(route func(peer string) sip.Uri) {
+	h.serviceRoute = route
+}
+
 // HandleRegister processes a REGISTER request.
 func (h *RegisterHandler) HandleRegister(req *sip.Request, tx sip.ServerTransaction) error {
 	slog.Debug("[REGISTER] Processing", "from", req.Source())
@@ -75,6 +86,9 @@ func (h *RegisterHandler) HandleRegister(req *sip.Request, tx sip.ServerTransact
 	// Extract contacts from request
 	contacts := req.GetHeaders("Contact")
 
+	// RFC 5626: the UA supports outbound if it lists the option tag
+	supportsOutbound := hasOptionTag(req, "Supported", "outbound")
+
 	// Check for wildcard unregister: Contact: *
 	// RFC 3261 Section 10.3 Step 6: If Contact: * is present, there must be
 	// no other Contact headers and Expires must be 0.
@@ -122,10 +136,20 @@ func (h *RegisterHandler) HandleRegister(req *sip.Request, tx sip.ServerTransact
 
 		contactURI := contact.Address.String()
 		expires := h.getExpires(req, contact)
+		instanceID := h.extractInstanceID(contact)
+
+		// RFC 5626 Section 6: a Contact with both +sip.instance and reg-id
+		// registers a flow; later requests must reuse the connection it
+		// arrived on.
+		regID, hasRegID := h.extractRegID(contact)
+		outbound := supportsOutbound && hasRegID && instanceID != ""
 
 		// Expires: 0 = unregister this contact
 		if expires == 0 {
-			bindingID := location.GenerateBindingID(contactURI, h.extractInstanceID(contact))
+			bindingID := location.GenerateBindingID(contactURI, instanceID)
+			if outbound {
+				bindingID = location.GenerateFlowBindingID(instanceID, regID)
+			}
 			if err := h.locationStore.Unregister(aor, bindingID, false); err != nil {
 				slog.Debug("[REGISTER] Unregister failed", "error", err)
 			}
@@ -139,13 +163,18 @@ func (h *RegisterHandler) HandleRegister(req *sip.Request, tx sip.ServerTransact
 			ReceivedIP:   receivedIP,
 			ReceivedPort: receivedPort,
 			Transport:    sipTransport,
-			InstanceID:   h.extractInstanceID(contact),
+			InstanceID:   instanceID,
 			QValue:       h.extractQValue(contact),
 			Expires:      expires,
 			CallID:       callID,
 			CSeq:         cseq,
 			UserAgent:    userAgent,
 		}
+		if outbound {
+			binding.RegID = regID
+			binding.Outbound = true
+			binding.FlowToken = location.GenerateFlowToken(sipTransport, receivedIP, receivedPort)
+		}
 
 		// Extract Path headers if present
 		pathHdrs := req.GetHeaders("Path")
@@ -170,7 +199,7 @@ func (h *RegisterHandler) HandleRegister(req *sip.Request, tx sip.ServerTransact
 	}
 
 	// Send 200 OK with current bindings
-	return h.sendOKWithBindings(tx, req, aor, lastBinding)
+	return h.sendOKWithBindings(tx, req, aor, lastBinding, supportsOutbound)
 }
 
 // getExpires extracts expiration time from request.
@@ -209,6 +238,32 @@ func (h *RegisterHandler) extractInstanceID(contact *sip.ContactHeader) string {
 	return ""
 }
 
+// extractRegID extracts the reg-id parameter (RFC 5626) from Contact.
+func (h *RegisterHandler) extractRegID(contact *sip.ContactHeader) (int, bool) {
+	if contact == nil || contact.Params == nil {
+		return 0, false
+	}
+	if regStr, ok := contact.Params.Get("reg-id"); ok {
+		if regID, err := strconv.Atoi(regStr); err == nil && regID > 0 {
+			return regID, true
+		}
+	}
+	return 0, false
+}
+
+// hasOptionTag reports whether an option-tag header (Supported, Require)
+// lists tag.
+func hasOptionTag(req *sip.Request, header, tag string) bool {
+	for _, hdr := range req.GetHeaders(header) {
+		for _, t := range strings.Split(hdr.Value(), ",") {
+			if strings.EqualFold(strings.TrimSpace(t), tag) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
 // extractQValue extracts q parameter from Contact.
 func (h *RegisterHandler) extractQValue(contact *sip.ContactHeader) float32 {
 	if contact == nil || contact.Params == nil {
@@ -286,12 +341,17 @@ func (h *RegisterHandler) sendQueryResponse(tx sip.ServerTransaction, req *sip.R
 }
 
 // sendOKWithBindings sends 200 OK with updated binding info.
-func (h *RegisterHandler) sendOKWithBindings(tx sip.ServerTransaction, req *sip.Request, aor string, _ *location.Binding) error {
+func (h *RegisterHandler) sendOKWithBindings(tx sip.ServerTransaction, req *sip.Request, aor string, last *location.Binding, supportsOutbound bool) error {
 	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
 
 	// Add received/rport to Via per RFC 3581 for NAT traversal
 	h.addViaParams(res, req)
 
+	// RFC 5626 Section 6: confirm the flow was registered as an outbound flow
+	if supportsOutbound && last != nil && last.Outbound {
+		res.AppendHeader(sip.NewHeader("Require", "outbound"))
+	}
+
 	// Add Date header per RFC 3261 recommendation
 	h.addDateHeader(res)
 
@@ -301,6 +361,12 @@ func (h *RegisterHandler) sendOKWithBindings(tx sip.ServerTransaction, req *sip.
 		h.addContactHeader(res, b)
 	}
 
+	// RFC 3608: route the client's later requests through this server
+	if h.serviceRoute != nil && len(bindings) > 0 {
+		route := h.serviceRoute(req.Source())
+		res.AppendHeader(sip.NewHeader("Service-Route", "<"+route.String()+">"))
+	}
+
 	if err := tx.Respond(res); err != nil {
 		slog.Error("[REGISTER] Failed to send OK response", "error", err)
 		return err
@@ -314,7 +380,7 @@ func (h *RegisterHandler) sendOKWithBindings(tx sip.ServerTransaction, req *sip.
 func (h *RegisterHandler) addContactHeader(res *sip.Response, b *location.Binding) {
 	// Parse the contact URI
 	var uri sip.Uri
-	if err := sip.ParseUri(b.ContactURI, &uri); err != nil {
+	if err := sipaddr.ParseURI(b.ContactURI, &uri); err != nil {
 		slog.Debug("[REGISTER] Failed to parse contact URI", "uri", b.ContactURI, "error", err)
 		return
 	}
@@ -327,6 +393,14 @@ func (h *RegisterHandler) addContactHeader(res *sip.Response, b *location.Bindin
 	// Add expires parameter
 	contactHdr.Params.Add("expires", fmt.Sprintf("%d", b.Expires))
 
+	// Echo the outbound flow identity so the UA can match its Contact
+	if b.InstanceID != "" {
+		contactHdr.Params.Add("+sip.instance", "\"<"+b.InstanceID+">\"")
+	}
+	if b.Outbound {
+		contactHdr.Params.Add("reg-id", strconv.Itoa(b.RegID))
+	}
+
 	res.AppendHeader(contactHdr)
 }
 
@@ -358,6 +432,12 @@ func (h *RegisterHandler) GetAllRegistrations() map[string][]*location.Binding {
 	return h.locationStore.ListByAOR()
 }
 
+// RemoveBinding removes a binding administratively, or every binding of
+// the AOR when bindingID is empty.
+func (h *RegisterHandler) RemoveBinding(aor, bindingID string) error {
+	return h.locationStore.Evict(aor, bindingID)
+}
+
 // ListAll returns all bindings.
 func (h *RegisterHandler) ListAll() []*location.Binding {
 	return h.locationStore.List()
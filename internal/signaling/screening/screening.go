@@ -0,0 +1,332 @@
+// Package screening blocks unwanted inbound callers before the dialplan runs.
+//
+// A List holds caller number entries (exact numbers, prefixes or regular
+// expressions) and the action taken when one matches: reject with 603
+// Decline, drop the INVITE silently, or answer with an announcement and
+// hang up. Lists are evaluated in name order; the first match wins.
+package screening
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Entry match types
+const (
+	MatchExact  = "exact"
+	MatchPrefix = "prefix"
+	MatchRegex  = "regex"
+)
+
+// List actions
+const (
+	ActionReject       = "reject"       // 603 Decline
+	ActionDrop         = "drop"         // No response; the caller's transaction times out
+	ActionAnnouncement = "announcement" // Answer, play Announcement, hang up
+)
+
+// Sentinel errors
+var (
+	ErrListNotFound  = errors.New("screening: list not found")
+	ErrEntryNotFound = errors.New("screening: entry not found")
+)
+
+// Entry matches caller numbers (From URI user part).
+type Entry struct {
+	Match   string `json:"match,omitempty"` // "exact" (default), "prefix" or "regex"
+	Pattern string `json:"pattern"`
+	Comment string `json:"comment,omitempty"`
+
+	re *regexp.Regexp // Compiled pattern for "regex"
+}
+
+// compile validates the entry and prepares it for matching.
+func (e *Entry) compile() error {
+	if e.Pattern == "" {
+		return fmt.Errorf("screening: entry pattern required")
+	}
+	switch e.Match {
+	case "":
+		e.Match = MatchExact
+	case MatchExact, MatchPrefix:
+	case MatchRegex:
+		re, err := regexp.Compile(e.Pattern)
+		if err != nil {
+			return fmt.Errorf("screening: entry %q: %w", e.Pattern, err)
+		}
+		e.re = re
+	default:
+		return fmt.Errorf("screening: entry %q: invalid match %q", e.Pattern, e.Match)
+	}
+	return nil
+}
+
+// Matches reports whether the caller number matches the entry.
+func (e *Entry) Matches(caller string) bool {
+	switch e.Match {
+	case MatchPrefix:
+		return strings.HasPrefix(caller, e.Pattern)
+	case MatchRegex:
+		return e.re != nil && e.re.MatchString(caller)
+	default:
+		return caller == e.Pattern
+	}
+}
+
+// List is a named blocklist with the action applied to matching callers.
+type List struct {
+	Name         string  `json:"name"`
+	Action       string  `json:"action"`
+	Announcement string  `json:"announcement,omitempty"` // Audio file for "announcement"
+	Entries      []Entry `json:"entries"`
+}
+
+// Validate checks the list and compiles its entries.
+func (l *List) Validate() error {
+	if l.Name == "" {
+		return fmt.Errorf("screening: list name required")
+	}
+	switch l.Action {
+	case ActionReject, ActionDrop:
+	case ActionAnnouncement:
+		if l.Announcement == "" {
+			return fmt.Errorf("screening: list %s: announcement file required", l.Name)
+		}
+	default:
+		return fmt.Errorf("screening: list %s: invalid action %q", l.Name, l.Action)
+	}
+	for i := range l.Entries {
+		if err := l.Entries[i].compile(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Verdict is the outcome of screening a blocked caller.
+type Verdict struct {
+	List         string
+	Action       string
+	Announcement string
+	Entry        Entry
+}
+
+// Config is the on-disk form of the screener.
+type Config struct {
+	Lists []List `json:"lists"`
+}
+
+// Screener holds blocklists. Changes made through the management API are
+// written back to the config file when one is set.
+type Screener struct {
+	mu    sync.RWMutex
+	path  string
+	lists map[string]*List
+}
+
+// NewScreener creates a screener with no lists.
+func NewScreener() *Screener {
+	return &Screener{lists: make(map[string]*List)}
+}
+
+// Load creates a screener from a JSON config file. A missing file yields
+// an empty screener that is saved to path on the first change.
+func Load(path string) (*Screener, error) {
+	s := NewScreener()
+	s.path = path
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read screening config: %w", err)
+	}
+
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse screening config: %w", err)
+	}
+	for i := range cfg.Lists {
+		list := cfg.Lists[i]
+		if err := list.Validate(); err != nil {
+			return nil, err
+		}
+		s.lists[list.Name] = &list
+	}
+	return s, nil
+}
+
+// Screen checks a caller number against all lists in name order.
+// Returns false if the caller is not blocked.
+func (s *Screener) Screen(caller string) (*Verdict, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, list := range s.sortedLocked() {
+		for _, e := range list.Entries {
+			if e.Matches(caller) {
+				return &Verdict{
+					List:         list.Name,
+					Action:       list.Action,
+					Announcement: list.Announcement,
+					Entry:        e,
+				}, true
+			}
+		}
+	}
+	return nil, false
+}
+
+// List returns a list by name.
+func (s *Screener) List(name string) (*List, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	list, ok := s.lists[name]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	return copyList(list), nil
+}
+
+// Lists returns all lists sorted by name.
+func (s *Screener) Lists() []List {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	sorted := s.sortedLocked()
+	lists := make([]List, 0, len(sorted))
+	for _, l := range sorted {
+		lists = append(lists, *copyList(l))
+	}
+	return lists
+}
+
+// PutList adds or replaces a list.
+func (s *Screener) PutList(list List) error {
+	if err := list.Validate(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.lists[list.Name] = &list
+	return s.saveLocked()
+}
+
+// DeleteList removes a list.
+func (s *Screener) DeleteList(name string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.lists[name]; !ok {
+		return fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	delete(s.lists, name)
+	return s.saveLocked()
+}
+
+// AddEntry adds an entry to a list. An entry with the same match and
+// pattern is replaced.
+func (s *Screener) AddEntry(name string, entry Entry) error {
+	if err := entry.compile(); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	updated := copyList(list)
+	if i := indexEntry(updated.Entries, entry.Match, entry.Pattern); i >= 0 {
+		updated.Entries[i] = entry
+	} else {
+		updated.Entries = append(updated.Entries, entry)
+	}
+	s.lists[name] = updated
+	return s.saveLocked()
+}
+
+// RemoveEntry removes the entry with the given match and pattern.
+func (s *Screener) RemoveEntry(name, match, pattern string) error {
+	if match == "" {
+		match = MatchExact
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	list, ok := s.lists[name]
+	if !ok {
+		return fmt.Errorf("%w: %s", ErrListNotFound, name)
+	}
+	i := indexEntry(list.Entries, match, pattern)
+	if i < 0 {
+		return fmt.Errorf("%w: %s %s", ErrEntryNotFound, match, pattern)
+	}
+	updated := copyList(list)
+	updated.Entries = append(updated.Entries[:i], updated.Entries[i+1:]...)
+	s.lists[name] = updated
+	return s.saveLocked()
+}
+
+// sortedLocked returns the lists in name order (must hold lock).
+func (s *Screener) sortedLocked() []*List {
+	lists := make([]*List, 0, len(s.lists))
+	for _, l := range s.lists {
+		lists = append(lists, l)
+	}
+	sort.Slice(lists, func(i, j int) bool { return lists[i].Name < lists[j].Name })
+	return lists
+}
+
+// saveLocked writes the screener to its config file, if any (must hold lock).
+func (s *Screener) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	cfg := Config{Lists: make([]List, 0, len(s.lists))}
+	for _, l := range s.sortedLocked() {
+		cfg.Lists = append(cfg.Lists, *l)
+	}
+
+	data, err := json.MarshalIndent(cfg, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode screening config: %w", err)
+	}
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("write screening config: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("write screening config: %w", err)
+	}
+	return nil
+}
+
+func copyList(l *List) *List {
+	c := *l
+	c.Entries = append([]Entry(nil), l.Entries...)
+	return &c
+}
+
+func indexEntry(entries []Entry, match, pattern string) int {
+	for i, e := range entries {
+		if e.Match == match && e.Pattern == pattern {
+			return i
+		}
+	}
+	return -1
+}
@@ -0,0 +1,403 @@
+package sdpmedia
+
+import (
+	"cmp"
+	"errors"
+	"fmt"
+	"net"
+	"slices"
+	"strconv"
+	"strings"
+
+	psdp "github.com/pion/sdp/v3"
+)
+
+// Directions of a stream (RFC 3264 Section 5.1)
+const (
+	SendRecv = "sendrecv"
+	SendOnly = "sendonly"
+	RecvOnly = "recvonly"
+	Inactive = "inactive"
+)
+
+// ErrNoCommonFormat is returned when an answerer supports none of the
+// formats offered
+var ErrNoCommonFormat = errors.New("sdpmedia: no common format")
+
+// Format is a payload format of a stream: its payload type, what its
+// rtpmap attribute (or the static assignment of RFC 3551) names, and its
+// fmtp parameters
+type Format struct {
+	Payload   string // "0", "101"; "*" and the like for protocols other than RTP
+	Encoding  string // "PCMU", "telephone-event"; empty if unknown
+	ClockRate int
+	Channels  int    // 1 unless the rtpmap says otherwise
+	Params    string // fmtp parameters, e.g. "0-16"
+}
+
+// String returns the rtpmap value of the format without its payload type,
+// e.g. "PCMU/8000"
+func (f Format) String() string {
+	s := f.Encoding + "/" + strconv.Itoa(f.ClockRate)
+	if f.Channels > 1 {
+		s += "/" + strconv.Itoa(f.Channels)
+	}
+	return s
+}
+
+// matches reports whether two formats are the same codec, whatever their
+// payload types
+func (f Format) matches(g Format) bool {
+	return f.Encoding != "" && strings.EqualFold(f.Encoding, g.Encoding) && f.ClockRate == g.ClockRate && f.Channels == g.Channels
+}
+
+// IsEvent reports whether a format carries something other than media:
+// telephone events (RFC 4733) or comfort noise (RFC 3389)
+func (f Format) IsEvent() bool {
+	return strings.EqualFold(f.Encoding, "telephone-event") || strings.EqualFold(f.Encoding, "CN")
+}
+
+// staticFormats are the static payload types of RFC 3551 Section 6 that
+// may be offered without an rtpmap attribute
+var staticFormats = map[string]Format{
+	"0":  {Encoding: "PCMU", ClockRate: 8000, Channels: 1},
+	"3":  {Encoding: "GSM", ClockRate: 8000, Channels: 1},
+	"4":  {Encoding: "G723", ClockRate: 8000, Channels: 1},
+	"8":  {Encoding: "PCMA", ClockRate: 8000, Channels: 1},
+	"9":  {Encoding: "G722", ClockRate: 8000, Channels: 1},
+	"13": {Encoding: "CN", ClockRate: 8000, Channels: 1},
+	"18": {Encoding: "G729", ClockRate: 8000, Channels: 1},
+}
+
+// StaticPayload returns the static payload type of an encoding, e.g. "8"
+// for PCMA, false if it has none
+func StaticPayload(encoding string) (string, bool) {
+	for payload, f := range staticFormats {
+		if strings.EqualFold(f.Encoding, encoding) {
+			return payload, true
+		}
+	}
+	return "", false
+}
+
+// Stream is what one media description of an SDP says: its media, port,
+// connection address, formats in order of preference, packetization and
+// direction
+type Stream struct {
+	Media     string // "audio", "video"
+	Port      int    // 0 if rejected
+	Proto     string // "RTP/AVP"
+	Addr      string // Its own connection address, or the session's
+	Formats   []Format
+	Direction string // The stream's, the session's, or sendrecv
+	Ptime     int    // Milliseconds; 0 if not given
+	Maxptime  int    // Milliseconds; 0 if not given
+	RTCPMux   bool   // RFC 5761
+
+	// Attributes are the stream's other attributes, kept as they are
+	Attributes []psdp.Attribute
+}
+
+// Session is a parsed SDP body
+type Session struct {
+	SDP     *psdp.SessionDescription
+	Streams []Stream // One per media description, in order
+}
+
+// Parse parses an SDP body into its streams
+func Parse(body []byte) (*Session, error) {
+	sdp := &psdp.SessionDescription{}
+	if err := sdp.Unmarshal(body); err != nil {
+		return nil, fmt.Errorf("sdpmedia: %w", err)
+	}
+	s := &Session{SDP: sdp, Streams: make([]Stream, 0, len(sdp.MediaDescriptions))}
+	for _, media := range sdp.MediaDescriptions {
+		s.Streams = append(s.Streams, parseStream(sdp, media))
+	}
+	return s, nil
+}
+
+// Audio returns the first audio stream of a session, the one the RTP
+// manager anchors, or nil if there is none
+func (s *Session) Audio() *Stream {
+	for i := range s.Streams {
+		if s.Streams[i].Media == "audio" {
+			return &s.Streams[i]
+		}
+	}
+	return nil
+}
+
+// parseStream reads the stream of a media description
+func parseStream(sdp *psdp.SessionDescription, media *psdp.MediaDescription) Stream {
+	s := Stream{
+		Media:     media.MediaName.Media,
+		Port:      media.MediaName.Port.Value,
+		Proto:     strings.Join(media.MediaName.Protos, "/"),
+		Addr:      Address(sdp, media),
+		Direction: SendRecv,
+	}
+	for _, a := range sdp.Attributes {
+		if IsDirection(a.Key) {
+			s.Direction = a.Key
+		}
+	}
+
+	formats := make(map[string]*Format, len(media.MediaName.Formats))
+	for _, pt := range media.MediaName.Formats {
+		f := Format{Payload: pt}
+		if static, ok := staticFormats[pt]; ok {
+			f.Encoding, f.ClockRate, f.Channels = static.Encoding, static.ClockRate, static.Channels
+		}
+		s.Formats = append(s.Formats, f)
+	}
+	for i := range s.Formats {
+		formats[s.Formats[i].Payload] = &s.Formats[i]
+	}
+
+	for _, a := range media.Attributes {
+		if !s.parseAttribute(a, formats) {
+			s.Attributes = append(s.Attributes, a)
+		}
+	}
+	return s
+}
+
+// parseAttribute reads an attribute of the stream into it, reporting
+// whether it did. Malformed rtpmap, fmtp and ptime attributes and those of
+// payload types not offered are kept as they are, rather than failing the
+// call over a stream detail.
+func (s *Stream) parseAttribute(a psdp.Attribute, formats map[string]*Format) bool {
+	switch {
+	case a.Key == "rtpmap":
+		pt, rtpmap, _ := strings.Cut(a.Value, " ")
+		f, ok := formats[pt]
+		parts := strings.Split(strings.TrimSpace(rtpmap), "/")
+		if !ok || len(parts) < 2 || len(parts) > 3 {
+			return false
+		}
+		clock, err := strconv.Atoi(parts[1])
+		if err != nil || clock <= 0 {
+			return false
+		}
+		channels := 1
+		if len(parts) == 3 {
+			if channels, err = strconv.Atoi(parts[2]); err != nil || channels <= 0 {
+				return false
+			}
+		}
+		f.Encoding, f.ClockRate, f.Channels = parts[0], clock, channels
+	case a.Key == "fmtp":
+		pt, params, _ := strings.Cut(a.Value, " ")
+		f, ok := formats[pt]
+		if !ok {
+			return false
+		}
+		f.Params = strings.TrimSpace(params)
+	case a.Key == "ptime" || a.Key == "maxptime":
+		ms, err := strconv.ParseFloat(strings.TrimSpace(a.Value), 64)
+		if err != nil || ms < 1 {
+			return false
+		}
+		if a.Key == "ptime" {
+			s.Ptime = int(ms)
+		} else {
+			s.Maxptime = int(ms)
+		}
+	case a.Key == "rtcp-mux":
+		s.RTCPMux = true
+	case IsDirection(a.Key):
+		s.Direction = a.Key
+	default:
+		return false
+	}
+	return true
+}
+
+// IsDirection reports whether an attribute key is a direction
+func IsDirection(key string) bool {
+	return key == SendRecv || key == SendOnly || key == RecvOnly || key == Inactive
+}
+
+// Payloads returns the payload types of a stream, in order of preference
+func (s *Stream) Payloads() []string {
+	pts := make([]string, len(s.Formats))
+	for i, f := range s.Formats {
+		pts[i] = f.Payload
+	}
+	return pts
+}
+
+// Codec returns the preferred format of a stream that carries media
+// rather than telephone events or comfort noise, false if there is none
+func (s *Stream) Codec() (Format, bool) {
+	i := slices.IndexFunc(s.Formats, func(f Format) bool { return !f.IsEvent() })
+	if i < 0 {
+		return Format{}, false
+	}
+	return s.Formats[i], true
+}
+
+// AnswerDirection returns the direction answering an offered one (RFC 3264
+// Section 6.1)
+func AnswerDirection(offered string) string {
+	switch offered {
+	case SendOnly:
+		return RecvOnly
+	case RecvOnly:
+		return SendOnly
+	case "":
+		return SendRecv
+	}
+	return offered
+}
+
+// Negotiate returns the stream answering an offered one by an answerer
+// whose capabilities are local (RFC 3264 Section 6.1): local's port,
+// address and protocol; the formats both support in local's order of
+// preference, each with the offered payload type and fmtp parameters; the
+// telephone events and comfort noise only at the clock rate of a codec
+// chosen; local's ptime unless the offer's maxptime is lower (the offer's
+// if local has none); the direction answering the offered one that local
+// allows; and rtcp-mux if both support it.
+func Negotiate(offer, local *Stream) (*Stream, error) {
+	answer := &Stream{
+		Media:      local.Media,
+		Port:       local.Port,
+		Proto:      local.Proto,
+		Addr:       local.Addr,
+		Direction:  narrower(AnswerDirection(offer.Direction), local.Direction),
+		Ptime:      local.Ptime,
+		Maxptime:   local.Maxptime,
+		RTCPMux:    offer.RTCPMux && local.RTCPMux,
+		Attributes: local.Attributes,
+	}
+	if answer.Ptime == 0 {
+		answer.Ptime = offer.Ptime
+	}
+	if offer.Maxptime > 0 && answer.Ptime > offer.Maxptime {
+		answer.Ptime = offer.Maxptime
+	}
+
+	var clocks []int
+	for _, l := range local.Formats {
+		if l.IsEvent() {
+			continue
+		}
+		if i := slices.IndexFunc(offer.Formats, l.matches); i >= 0 {
+			answer.Formats = append(answer.Formats, offer.Formats[i])
+			clocks = append(clocks, l.ClockRate)
+		}
+	}
+	if len(answer.Formats) == 0 {
+		return nil, ErrNoCommonFormat
+	}
+	for _, l := range local.Formats {
+		if !l.IsEvent() || !slices.Contains(clocks, l.ClockRate) {
+			continue
+		}
+		if i := slices.IndexFunc(offer.Formats, l.matches); i >= 0 {
+			answer.Formats = append(answer.Formats, offer.Formats[i])
+		}
+	}
+	return answer, nil
+}
+
+// narrower returns the direction both of two directions allow
+func narrower(a, b string) string {
+	send := sends(a) && sends(b)
+	recv := receives(a) && receives(b)
+	switch {
+	case send && recv:
+		return SendRecv
+	case send:
+		return SendOnly
+	case recv:
+		return RecvOnly
+	}
+	return Inactive
+}
+
+// sends and receives report what a direction allows; empty is sendrecv
+func sends(direction string) bool    { return direction != RecvOnly && direction != Inactive }
+func receives(direction string) bool { return direction != SendOnly && direction != Inactive }
+
+// Apply writes a stream into a media description, replacing its port,
+// formats and attributes. The connection line is set only when the
+// stream's address differs from the session's.
+func (s *Stream) Apply(sdp *psdp.SessionDescription, media *psdp.MediaDescription) {
+	media.MediaName.Media = s.Media
+	media.MediaName.Port = psdp.RangedPort{Value: s.Port}
+	if s.Proto != "" {
+		media.MediaName.Protos = strings.Split(s.Proto, "/")
+	}
+	media.MediaName.Formats = s.Payloads()
+	if c := sdp.ConnectionInformation; s.Addr != "" && (c == nil || c.Address == nil || c.Address.Address != s.Addr) {
+		media.ConnectionInformation = &psdp.ConnectionInformation{
+			NetworkType: "IN",
+			AddressType: addressType(s.Addr),
+			Address:     &psdp.Address{Address: s.Addr},
+		}
+	}
+
+	attrs := make([]psdp.Attribute, 0, 2*len(s.Formats)+len(s.Attributes)+4)
+	for _, f := range s.Formats {
+		if f.Encoding != "" {
+			attrs = append(attrs, psdp.NewAttribute("rtpmap", f.Payload+" "+f.String()))
+		}
+	}
+	for _, f := range s.Formats {
+		if f.Params != "" {
+			attrs = append(attrs, psdp.NewAttribute("fmtp", f.Payload+" "+f.Params))
+		}
+	}
+	if s.Ptime > 0 {
+		attrs = append(attrs, psdp.NewAttribute("ptime", strconv.Itoa(s.Ptime)))
+	}
+	if s.Maxptime > 0 {
+		attrs = append(attrs, psdp.NewAttribute("maxptime", strconv.Itoa(s.Maxptime)))
+	}
+	attrs = append(attrs, psdp.NewPropertyAttribute(cmp.Or(s.Direction, SendRecv)))
+	if s.RTCPMux {
+		attrs = append(attrs, psdp.NewPropertyAttribute("rtcp-mux"))
+	}
+	media.Attributes = append(attrs, s.Attributes...)
+}
+
+// Answer returns the answer to an offer built from local, the RTP
+// manager's answer to the offer's first audio stream: that stream
+// negotiated with the offered one (see Negotiate), laid out like the offer
+// with the other streams rejected (see Compose).
+func Answer(offer, local []byte) ([]byte, error) {
+	o, err := Parse(offer)
+	if err != nil {
+		return nil, err
+	}
+	l, err := Parse(local)
+	if err != nil {
+		return nil, err
+	}
+	offered, anchored := o.Audio(), l.Audio()
+	if offered == nil || anchored == nil {
+		return nil, ErrNoAudio
+	}
+	answer, err := Negotiate(offered, anchored)
+	if err != nil {
+		return nil, err
+	}
+	answer.Apply(l.SDP, Audio(l.SDP))
+	body, err := l.SDP.Marshal()
+	if err != nil {
+		return nil, fmt.Errorf("sdpmedia: %w", err)
+	}
+	return Compose(body, o.SDP, nil)
+}
+
+// addressType returns the SDP address type ("IP4" or "IP6") of an
+// address. Hostnames are assumed to be IPv4.
+func addressType(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return "IP6"
+	}
+	return "IP4"
+}
@@ -0,0 +1,241 @@
+package sdpmedia
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+		want []Stream // Attributes are not compared
+	}{
+		{
+			name: "static payload types without rtpmap",
+			body: sdpLines(
+				"v=0", "o=- 1 1 IN IP4 198.51.100.7", "s=-", "c=IN IP4 198.51.100.7", "t=0 0",
+				"m=audio 40000 RTP/AVP 8 0 18",
+			),
+			want: []Stream{{
+				Media: "audio", Port: 40000, Proto: "RTP/AVP", Addr: "198.51.100.7", Direction: SendRecv,
+				Formats: []Format{
+					{Payload: "8", Encoding: "PCMA", ClockRate: 8000, Channels: 1},
+					{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1},
+					{Payload: "18", Encoding: "G729", ClockRate: 8000, Channels: 1},
+				},
+			}},
+		},
+		{
+			name: "dynamic payload types, fmtp, ptime and rtcp-mux",
+			body: sdpLines(
+				"v=0", "o=- 1 1 IN IP4 198.51.100.7", "s=-", "c=IN IP4 198.51.100.7", "t=0 0",
+				"m=audio 40000 RTP/SAVP 111 0 101",
+				"a=rtpmap:111 opus/48000/2",
+				"a=fmtp:111 minptime=10;useinbandfec=1",
+				"a=rtpmap:101 telephone-event/8000",
+				"a=fmtp:101 0-16",
+				"a=ptime:20",
+				"a=maxptime:40",
+				"a=rtcp-mux",
+				"a=crypto:1 AES_CM_128_HMAC_SHA1_80 inline:key",
+			),
+			want: []Stream{{
+				Media: "audio", Port: 40000, Proto: "RTP/SAVP", Addr: "198.51.100.7", Direction: SendRecv,
+				Ptime: 20, Maxptime: 40, RTCPMux: true,
+				Formats: []Format{
+					{Payload: "111", Encoding: "opus", ClockRate: 48000, Channels: 2, Params: "minptime=10;useinbandfec=1"},
+					{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1},
+					{Payload: "101", Encoding: "telephone-event", ClockRate: 8000, Channels: 1, Params: "0-16"},
+				},
+			}},
+		},
+		{
+			name: "session direction, media address and fractional ptime",
+			body: sdpLines(
+				"v=0", "o=- 1 1 IN IP4 198.51.100.7", "s=-", "c=IN IP4 198.51.100.7", "t=0 0",
+				"a=sendonly",
+				"m=audio 40000 RTP/AVP 0",
+				"c=IN IP4 0.0.0.0",
+				"a=ptime:22.5",
+				"m=audio 40002 RTP/AVP 8",
+				"a=inactive",
+			),
+			want: []Stream{
+				{
+					Media: "audio", Port: 40000, Proto: "RTP/AVP", Addr: "0.0.0.0", Direction: SendOnly, Ptime: 22,
+					Formats: []Format{{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1}},
+				},
+				{
+					Media: "audio", Port: 40002, Proto: "RTP/AVP", Addr: "198.51.100.7", Direction: Inactive,
+					Formats: []Format{{Payload: "8", Encoding: "PCMA", ClockRate: 8000, Channels: 1}},
+				},
+			},
+		},
+		{
+			name: "video and a non-RTP stream",
+			body: offer,
+			want: []Stream{
+				{
+					Media: "video", Port: 51000, Proto: "RTP/AVP", Addr: "198.51.100.7", Direction: SendRecv,
+					Formats: []Format{{Payload: "96", Encoding: "H264", ClockRate: 90000, Channels: 1}},
+				},
+				{
+					Media: "audio", Port: 40000, Proto: "RTP/AVP", Addr: "198.51.100.7", Direction: SendRecv,
+					Formats: []Format{
+						{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1},
+						{Payload: "8", Encoding: "PCMA", ClockRate: 8000, Channels: 1},
+					},
+				},
+				{
+					Media: "application", Port: 0, Proto: "UDP/BFCP", Addr: "198.51.100.7", Direction: SendRecv,
+					Formats: []Format{{Payload: "*"}},
+				},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s, err := Parse(tt.body)
+			if err != nil {
+				t.Fatalf("Parse: %v", err)
+			}
+			for i := range s.Streams {
+				s.Streams[i].Attributes = nil
+			}
+			if !reflect.DeepEqual(s.Streams, tt.want) {
+				t.Errorf("streams = %+v\nwant %+v", s.Streams, tt.want)
+			}
+		})
+	}
+
+	// Malformed details are kept as they are instead of failing the call
+	for _, attr := range []string{"rtpmap:0 PCMU", "rtpmap:0 PCMU/fast", "rtpmap:0 PCMU/8000/0", "rtpmap:9 G722/8000", "fmtp:9 x=1", "ptime:0", "maxptime:x"} {
+		body := sdpLines("v=0", "o=- 1 1 IN IP4 198.51.100.7", "s=-", "c=IN IP4 198.51.100.7", "t=0 0", "m=audio 40000 RTP/AVP 0", "a="+attr)
+		s, err := Parse(body)
+		if err != nil {
+			t.Fatalf("Parse with %q: %v", attr, err)
+		}
+		audio := s.Audio()
+		pcmu := Format{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1}
+		if len(audio.Formats) != 1 || audio.Formats[0] != pcmu || audio.Ptime != 0 || audio.Maxptime != 0 {
+			t.Errorf("Parse with %q = %+v", attr, audio)
+		}
+		if len(audio.Attributes) != 1 || audio.Attributes[0].String() != attr {
+			t.Errorf("Parse with %q kept %v", attr, audio.Attributes)
+		}
+	}
+	if _, err := Parse([]byte("not sdp")); err == nil {
+		t.Error("Parse(not sdp) succeeded")
+	}
+}
+
+func TestNegotiate(t *testing.T) {
+	pcmu := Format{Payload: "0", Encoding: "PCMU", ClockRate: 8000, Channels: 1}
+	pcma := Format{Payload: "8", Encoding: "PCMA", ClockRate: 8000, Channels: 1}
+	opus := Format{Payload: "111", Encoding: "opus", ClockRate: 48000, Channels: 2, Params: "useinbandfec=1"}
+	event := Format{Payload: "101", Encoding: "telephone-event", ClockRate: 8000, Channels: 1, Params: "0-16"}
+	event48 := Format{Payload: "110", Encoding: "telephone-event", ClockRate: 48000, Channels: 1}
+	local := Stream{
+		Media: "audio", Port: 20000, Proto: "RTP/AVP", Addr: "203.0.113.5", Direction: SendRecv, Ptime: 20, RTCPMux: true,
+		Formats: []Format{pcmu, {Payload: "96", Encoding: "telephone-event", ClockRate: 8000, Channels: 1}},
+	}
+
+	tests := []struct {
+		name  string
+		offer Stream
+		local *Stream
+		want  Stream
+		err   error
+	}{
+		{
+			name:  "common codec with the offered payload type",
+			offer: Stream{Formats: []Format{opus, pcma, pcmu, event48, event}, Direction: SendRecv, RTCPMux: true},
+			want: Stream{
+				Media: "audio", Port: 20000, Proto: "RTP/AVP", Addr: "203.0.113.5", Direction: SendRecv, Ptime: 20, RTCPMux: true,
+				Formats: []Format{pcmu, event},
+			},
+		},
+		{
+			name:  "sendonly answered recvonly, ptime capped by maxptime, no rtcp-mux",
+			offer: Stream{Formats: []Format{pcmu}, Direction: SendOnly, Maxptime: 10},
+			want: Stream{
+				Media: "audio", Port: 20000, Proto: "RTP/AVP", Addr: "203.0.113.5", Direction: RecvOnly, Ptime: 10,
+				Formats: []Format{pcmu},
+			},
+		},
+		{
+			name:  "offered ptime without a local one",
+			offer: Stream{Formats: []Format{pcma, pcmu}, Direction: RecvOnly, Ptime: 30},
+			local: &Stream{Media: "audio", Port: 20000, Direction: RecvOnly, Formats: []Format{pcma}},
+			want:  Stream{Media: "audio", Port: 20000, Direction: Inactive, Ptime: 30, Formats: []Format{pcma}},
+		},
+		{
+			name:  "telephone events alone",
+			offer: Stream{Formats: []Format{opus, event}},
+			err:   ErrNoCommonFormat,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			l := tt.local
+			if l == nil {
+				l = &local
+			}
+			got, err := Negotiate(&tt.offer, l)
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("Negotiate error = %v, want %v", err, tt.err)
+			}
+			if err == nil && !reflect.DeepEqual(*got, tt.want) {
+				t.Errorf("Negotiate = %+v\nwant %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAnswer(t *testing.T) {
+	offer := sdpLines(
+		"v=0", "o=alice 1 1 IN IP4 198.51.100.7", "s=-", "c=IN IP4 198.51.100.7", "t=0 0",
+		"m=video 51000 RTP/AVP 96",
+		"a=rtpmap:96 H264/90000",
+		"m=audio 40000 RTP/AVP 8 0 101",
+		"a=rtpmap:101 telephone-event/8000",
+		"a=fmtp:101 0-16",
+		"a=ptime:30",
+		"a=sendonly",
+	)
+	rtpAnswer := sdpLines(
+		"v=0", "o=switchboard 1 1 IN IP4 203.0.113.5", "s=Switchboard Media Session", "c=IN IP4 203.0.113.5", "t=0 0",
+		"m=audio 20000 RTP/AVP 0",
+		"a=rtpmap:0 PCMU/8000",
+		"a=ptime:20",
+		"a=sendrecv",
+		"a=rtcp-mux",
+	)
+	body, err := Answer(offer, rtpAnswer)
+	if err != nil {
+		t.Fatalf("Answer: %v", err)
+	}
+	got := string(body)
+	want := strings.Join([]string{
+		"m=video 0 RTP/AVP 96",
+		"m=audio 20000 RTP/AVP 0",
+		"a=rtpmap:0 PCMU/8000",
+		"a=ptime:20",
+		"a=recvonly",
+		"",
+	}, "\r\n")
+	if !strings.HasSuffix(got, want) {
+		t.Errorf("Answer =\n%s\nwant it to end with\n%s", got, want)
+	}
+	if strings.Contains(got, "rtcp-mux") || strings.Contains(got, "telephone-event") {
+		t.Errorf("Answer has attributes not negotiated:\n%s", got)
+	}
+
+	pcmaOnly := strings.Replace(string(offer), "RTP/AVP 8 0 101", "RTP/AVP 8", 1)
+	if _, err := Answer([]byte(pcmaOnly), rtpAnswer); !errors.Is(err, ErrNoCommonFormat) {
+		t.Errorf("Answer without PCMU = %v, want ErrNoCommonFormat", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Package sdpmedia negotiates SDP offers and answers and lays out bodies
+// that carry more than one media stream. The RTP manager anchors the
+// first audio stream of a call and nothing else, so every other stream
+// offered, video above all, is either rejected with port 0 (RFC 3264
+// Section 6) or, in passthrough mode, relayed between the bridged legs as
+// their endpoints describe it, the media flowing between the endpoints
+// directly.
+//
+// The streams other than audio of one leg correspond in order to the
+// other leg's: the caller's first video is the first video the callee is
+// offered.
+package sdpmedia
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	psdp "github.com/pion/sdp/v3"
+)
+
+// Mode is what happens to streams other than audio
+type Mode string
+
+// Modes
+const (
+	Reject      Mode = "reject"      // Answered with port 0
+	Passthrough Mode = "passthrough" // Relayed between the bridged legs
+)
+
+// ParseMode parses a mode name; empty is Reject
+func ParseMode(s string) (Mode, error) {
+	switch m := Mode(strings.ToLower(strings.TrimSpace(s))); m {
+	case "":
+		return Reject, nil
+	case Reject, Passthrough:
+		return m, nil
+	}
+	return "", fmt.Errorf("sdpmedia: unknown mode %q (want reject or passthrough)", s)
+}
+
+// ErrNoAudio is returned for an SDP body without an audio stream
+var ErrNoAudio = errors.New("sdpmedia: no audio media in SDP")
+
+// IsAudio reports whether a media description is an audio stream
+func IsAudio(media *psdp.MediaDescription) bool {
+	return media.MediaName.Media == "audio"
+}
+
+// IsRelayed reports whether a media description is a stream relayed
+// between endpoints rather than anchored: one other than audio that is
+// not rejected
+func IsRelayed(media *psdp.MediaDescription) bool {
+	return !IsAudio(media) && media.MediaName.Port.Value != 0
+}
+
+// Audio returns the first audio media description of an SDP, the stream
+// the RTP manager anchors, or nil if there is none
+func Audio(sdp *psdp.SessionDescription) *psdp.MediaDescription {
+	for _, media := range sdp.MediaDescriptions {
+		if IsAudio(media) {
+			return media
+		}
+	}
+	return nil
+}
+
+// Address returns the connection address of a media description of an
+// SDP: its own, or the session's
+func Address(sdp *psdp.SessionDescription, media *psdp.MediaDescription) string {
+	if c := media.ConnectionInformation; c != nil && c.Address != nil {
+		return c.Address.Address
+	}
+	if c := sdp.ConnectionInformation; c != nil && c.Address != nil {
+		return c.Address.Address
+	}
+	return ""
+}
+
+// Others returns the media descriptions of an SDP other than its first
+// audio one, in order
+func Others(sdp *psdp.SessionDescription) []*psdp.MediaDescription {
+	audio := Audio(sdp)
+	others := make([]*psdp.MediaDescription, 0, len(sdp.MediaDescriptions))
+	for _, media := range sdp.MediaDescriptions {
+		if media != audio {
+			others = append(others, media)
+		}
+	}
+	return others
+}
+
+// Relay returns the media descriptions of an SDP other than its first
+// audio one as passed on to the other leg: in passthrough mode as the SDP
+// describes them, each with a connection line of its own; rejected
+// otherwise, and when rejected already.
+func (m Mode) Relay(sdp *psdp.SessionDescription) []*psdp.MediaDescription {
+	others := Others(sdp)
+	for i, media := range others {
+		if m != Passthrough || media.MediaName.Port.Value == 0 {
+			others[i] = Rejected(media)
+			continue
+		}
+		relayed := *media
+		if relayed.ConnectionInformation == nil && sdp.ConnectionInformation != nil {
+			c := *sdp.ConnectionInformation
+			relayed.ConnectionInformation = &c
+		}
+		others[i] = &relayed
+	}
+	return others
+}
+
+// Rejected returns the media description rejecting one: the same media,
+// protocol and formats with port 0
+func Rejected(media *psdp.MediaDescription) *psdp.MediaDescription {
+	return &psdp.MediaDescription{
+		MediaName: psdp.MediaName{
+			Media:   media.MediaName.Media,
+			Port:    psdp.RangedPort{Value: 0},
+			Protos:  media.MediaName.Protos,
+			Formats: media.MediaName.Formats,
+		},
+	}
+}
+
+// Compose returns local, an SDP whose first audio stream is the one the
+// RTP manager anchors, laid out like shape: one media description per
+// one of shape's, in its order, with local's audio in place of shape's
+// first audio and the others taken in order from others, or rejected once
+// others run out. Others left over are appended, as a re-offer may add
+// streams (RFC 3264 Section 8.4). local's own other media are dropped. A
+// nil shape puts local's audio first.
+func Compose(local []byte, shape *psdp.SessionDescription, others []*psdp.MediaDescription) ([]byte, error) {
+	sdp := &psdp.SessionDescription{}
+	if err := sdp.Unmarshal(local); err != nil {
+		return nil, fmt.Errorf("sdpmedia: %w", err)
+	}
+	audio := Audio(sdp)
+	if audio == nil {
+		return nil, ErrNoAudio
+	}
+
+	media := []*psdp.MediaDescription{audio}
+	placed := false
+	if shape != nil {
+		media = media[:0]
+		for _, m := range shape.MediaDescriptions {
+			switch {
+			case !placed && IsAudio(m):
+				media = append(media, audio)
+				placed = true
+			case len(others) > 0:
+				media = append(media, others[0])
+				others = others[1:]
+			default:
+				media = append(media, Rejected(m))
+			}
+		}
+	}
+	if shape != nil && !placed {
+		media = append([]*psdp.MediaDescription{audio}, media...)
+	}
+	sdp.MediaDescriptions = append(media, others...)
+	return sdp.Marshal()
+}
+
+// Reoffer returns body, a new offer for a dialog whose last SDP was
+// previous, keeping previous's streams other than audio where they were:
+// a re-offer may not drop a stream (RFC 3264 Section 8). Bodies that do
+// not parse are returned unchanged.
+func Reoffer(body, previous []byte) []byte {
+	prev := &psdp.SessionDescription{}
+	if len(previous) == 0 || prev.Unmarshal(previous) != nil || len(prev.MediaDescriptions) < 2 {
+		return body
+	}
+	offer, err := Compose(body, prev, Others(prev))
+	if err != nil {
+		return body
+	}
+	return offer
+}
@@ -0,0 +1,130 @@
+package sdpmedia
+
+import (
+	"strings"
+	"testing"
+
+	psdp "github.com/pion/sdp/v3"
+)
+
+func sdpLines(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+// offer is a caller's offer with video before audio
+var offer = sdpLines(
+	"v=0",
+	"o=alice 1 1 IN IP4 198.51.100.7",
+	"s=-",
+	"c=IN IP4 198.51.100.7",
+	"t=0 0",
+	"m=video 51000 RTP/AVP 96",
+	"a=rtpmap:96 H264/90000",
+	"m=audio 40000 RTP/AVP 0 8",
+	"m=application 0 UDP/BFCP *",
+)
+
+// rtpAnswer is the RTP manager's audio-only answer
+var rtpAnswer = sdpLines(
+	"v=0",
+	"o=- 7 7 IN IP4 203.0.113.5",
+	"s=-",
+	"c=IN IP4 203.0.113.5",
+	"t=0 0",
+	"m=audio 20000 RTP/AVP 0",
+	"a=sendrecv",
+)
+
+func parse(t *testing.T, body []byte) *psdp.SessionDescription {
+	t.Helper()
+	sdp := &psdp.SessionDescription{}
+	if err := sdp.Unmarshal(body); err != nil {
+		t.Fatalf("Unmarshal: %v", err)
+	}
+	return sdp
+}
+
+// mLines returns the m= lines of an SDP body
+func mLines(body []byte) []string {
+	var lines []string
+	for _, line := range strings.Split(string(body), "\r\n") {
+		if strings.HasPrefix(line, "m=") {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+func TestCompose(t *testing.T) {
+	offerSDP := parse(t, offer)
+	if got := Audio(offerSDP).MediaName.Port.Value; got != 40000 {
+		t.Errorf("Audio() port = %d, want 40000", got)
+	}
+
+	tests := []struct {
+		name   string
+		others []*psdp.MediaDescription
+		want   []string
+		has    string // A line the answer must contain
+	}{
+		{
+			name: "rejected",
+			want: []string{"m=video 0 RTP/AVP 96", "m=audio 20000 RTP/AVP 0", "m=application 0 UDP/BFCP *"},
+		},
+		{
+			name:   "relayed",
+			others: Passthrough.Relay(offerSDP),
+			want:   []string{"m=video 51000 RTP/AVP 96", "m=audio 20000 RTP/AVP 0", "m=application 0 UDP/BFCP *"},
+			has:    "c=IN IP4 198.51.100.7",
+		},
+		{
+			name:   "rejected in reject mode",
+			others: Reject.Relay(offerSDP),
+			want:   []string{"m=video 0 RTP/AVP 96", "m=audio 20000 RTP/AVP 0", "m=application 0 UDP/BFCP *"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, err := Compose(rtpAnswer, offerSDP, tt.others)
+			if err != nil {
+				t.Fatalf("Compose: %v", err)
+			}
+			if got := mLines(body); strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("m= lines = %q, want %q", got, tt.want)
+			}
+			if tt.has != "" && !strings.Contains(string(body), tt.has) {
+				t.Errorf("%q missing in:\n%s", tt.has, body)
+			}
+		})
+	}
+
+	if _, err := Compose(sdpLines("v=0", "o=- 1 1 IN IP4 203.0.113.5", "s=-", "t=0 0", "m=video 5000 RTP/AVP 96"), offerSDP, nil); err != ErrNoAudio {
+		t.Errorf("Compose without audio = %v, want ErrNoAudio", err)
+	}
+}
+
+func TestReoffer(t *testing.T) {
+	previous, err := Compose(rtpAnswer, parse(t, offer), nil)
+	if err != nil {
+		t.Fatalf("Compose: %v", err)
+	}
+	moved := strings.Replace(string(rtpAnswer), "20000", "30000", 1)
+	want := []string{"m=video 0 RTP/AVP 96", "m=audio 30000 RTP/AVP 0", "m=application 0 UDP/BFCP *"}
+	if got := mLines(Reoffer([]byte(moved), previous)); strings.Join(got, "|") != strings.Join(want, "|") {
+		t.Errorf("Reoffer m= lines = %q, want %q", got, want)
+	}
+	if got := Reoffer([]byte(moved), rtpAnswer); string(got) != moved {
+		t.Errorf("Reoffer of an audio-only dialog changed the offer:\n%s", got)
+	}
+}
+
+func TestParseMode(t *testing.T) {
+	for in, want := range map[string]Mode{"": Reject, "reject": Reject, " Passthrough ": Passthrough} {
+		if got, err := ParseMode(in); err != nil || got != want {
+			t.Errorf("ParseMode(%q) = %q, %v; want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseMode("transcode"); err == nil {
+		t.Error("ParseMode(transcode) succeeded")
+	}
+}
@@ -0,0 +1,52 @@
+// Package sipaddr formats and parses hosts in SIP URIs and headers,
+// including bracketed IPv6 literals (RFC 3261 section 25.1) that the
+// sipgo URI parser does not accept.
+package sipaddr
+
+import (
+	"net"
+	"strconv"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// Host formats an address for the host part of a SIP URI or Via.
+// IPv6 literals are bracketed; anything else is returned unchanged.
+func Host(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return "[" + addr + "]"
+	}
+	return addr
+}
+
+// IsIPv6 reports whether a host, bracketed or not, is an IPv6 literal.
+func IsIPv6(host string) bool {
+	ip := net.ParseIP(strings.Trim(host, "[]"))
+	return ip != nil && ip.To4() == nil
+}
+
+// ParseURI parses a SIP URI, accepting bracketed IPv6 hosts such as
+// sip:alice@[2001:db8::1]:5060. The bracketed form is kept in Host so the
+// URI serializes correctly.
+func ParseURI(s string, uri *sip.Uri) error {
+	start := strings.IndexByte(s, '[')
+	end := strings.IndexByte(s, ']')
+	if start < 0 || end < start {
+		return sip.ParseUri(s, uri)
+	}
+
+	// Parse with a placeholder host, then restore the literal
+	const placeholder = "ipv6.invalid"
+	if err := sip.ParseUri(s[:start]+placeholder+s[end+1:], uri); err != nil {
+		return err
+	}
+	uri.Host = s[start : end+1]
+	return nil
+}
+
+// HostPort joins a host, bracketed or not, and a port into a destination
+// address such as "[2001:db8::1]:5060".
+func HostPort(host string, port int) string {
+	return net.JoinHostPort(strings.Trim(host, "[]"), strconv.Itoa(port))
+}
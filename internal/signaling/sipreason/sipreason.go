@@ -0,0 +1,162 @@
+// Package sipreason builds and parses Reason headers (RFC 3326), which say
+// why a call ended as a Q.850 or SIP cause. switchboard sends one with
+// every BYE and CANCEL and records the one received in the CDR.
+package sipreason
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+// Protocols of a Reason header
+const (
+	ProtocolQ850 = "Q.850"
+	ProtocolSIP  = "SIP"
+)
+
+// Q.850 causes sent by switchboard
+const (
+	CauseNormalClearing        = 16
+	CauseNoUserResponding      = 18
+	CauseNoAnswer              = 19
+	CauseCallRejected          = 21
+	CauseTemporaryFailure      = 41
+	CauseRecoveryOnTimerExpiry = 102
+)
+
+// causeText is the Q.850 text of the causes sent
+var causeText = map[int]string{
+	CauseNormalClearing:        "Normal call clearing",
+	CauseNoUserResponding:      "No user responding",
+	CauseNoAnswer:              "No answer from user (user alerted)",
+	CauseCallRejected:          "Call rejected",
+	CauseTemporaryFailure:      "Temporary failure",
+	CauseRecoveryOnTimerExpiry: "Recovery on timer expiry",
+}
+
+// Reason is the value of a Reason header. The zero Reason is no reason.
+type Reason struct {
+	Protocol string `json:"protocol"`
+	Cause    int    `json:"cause"`
+	Text     string `json:"text,omitempty"`
+}
+
+// Q850 returns the Reason of a Q.850 cause, with its standard text
+func Q850(cause int) Reason {
+	return Reason{Protocol: ProtocolQ850, Cause: cause, Text: causeText[cause]}
+}
+
+// SIP returns the Reason of a SIP status code
+func SIP(code int, text string) Reason {
+	return Reason{Protocol: ProtocolSIP, Cause: code, Text: text}
+}
+
+// IsZero reports whether r is no reason
+func (r Reason) IsZero() bool {
+	return r.Protocol == ""
+}
+
+// Q850Cause returns the cause of a Q.850 reason, 0 for any other
+func (r Reason) Q850Cause() int {
+	if r.Protocol != ProtocolQ850 {
+		return 0
+	}
+	return r.Cause
+}
+
+// String returns the header value, e.g. Q.850;cause=16;text="Normal call clearing"
+func (r Reason) String() string {
+	if r.IsZero() {
+		return ""
+	}
+	s := fmt.Sprintf("%s;cause=%d", r.Protocol, r.Cause)
+	if r.Text != "" {
+		s += ";text=" + strconv.Quote(r.Text)
+	}
+	return s
+}
+
+// Set replaces the Reason headers of a request with r; the zero Reason
+// only removes them
+func (r Reason) Set(req *sip.Request) {
+	for req.GetHeader("Reason") != nil {
+		req.RemoveHeader(req.GetHeader("Reason").Name())
+	}
+	if !r.IsZero() {
+		req.AppendHeader(sip.NewHeader("Reason", r.String()))
+	}
+}
+
+// Parse parses a single Reason header value
+func Parse(value string) (Reason, error) {
+	params := splitOutside(value, ';')
+	r := Reason{Protocol: strings.TrimSpace(params[0])}
+	if r.Protocol == "" {
+		return Reason{}, fmt.Errorf("sipreason: no protocol in %q", value)
+	}
+	hasCause := false
+	for _, param := range params[1:] {
+		name, val, _ := strings.Cut(param, "=")
+		val = strings.TrimSpace(val)
+		switch strings.ToLower(strings.TrimSpace(name)) {
+		case "cause":
+			cause, err := strconv.Atoi(val)
+			if err != nil {
+				return Reason{}, fmt.Errorf("sipreason: cause %q: %w", val, err)
+			}
+			r.Cause, hasCause = cause, true
+		case "text":
+			if text, err := strconv.Unquote(val); err == nil {
+				val = text
+			}
+			r.Text = strings.Trim(val, `"`)
+		}
+	}
+	if !hasCause {
+		return Reason{}, fmt.Errorf("sipreason: no cause in %q", value)
+	}
+	return r, nil
+}
+
+// FromRequest returns the reason a request carries: its Q.850 reason if it
+// has one, otherwise the first it has. Values that do not parse are
+// skipped; the zero Reason means none.
+func FromRequest(req *sip.Request) Reason {
+	var first Reason
+	for _, hdr := range req.GetHeaders("Reason") {
+		for _, value := range splitOutside(hdr.Value(), ',') {
+			r, err := Parse(value)
+			if err != nil {
+				continue
+			}
+			if r.Protocol == ProtocolQ850 {
+				return r
+			}
+			if first.IsZero() {
+				first = r
+			}
+		}
+	}
+	return first
+}
+
+// splitOutside splits s at sep outside quoted strings
+func splitOutside(s string, sep byte) []string {
+	var parts []string
+	quoted, start := false, 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '\\' && quoted:
+			i++
+		case s[i] == '"':
+			quoted = !quoted
+		case s[i] == sep && !quoted:
+			parts = append(parts, s[start:i])
+			start = i + 1
+		}
+	}
+	return append(parts, s[start:])
+}
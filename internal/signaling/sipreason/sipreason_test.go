@@ -0,0 +1,49 @@
+package sipreason
+
+import (
+	"testing"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		value string
+		want  Reason
+	}{
+		{`Q.850;cause=16;text="Normal call clearing"`, Q850(CauseNormalClearing)},
+		{`SIP ; cause=200 ; text="Call completed elsewhere"`, SIP(200, "Call completed elsewhere")},
+		{`Q.850;cause=17`, Reason{Protocol: ProtocolQ850, Cause: 17}},
+		{`Q.850;CAUSE=31;text=unquoted`, Reason{Protocol: ProtocolQ850, Cause: 31, Text: "unquoted"}},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.value)
+		if err != nil || got != tt.want {
+			t.Errorf("Parse(%q) = %+v, %v; want %+v", tt.value, got, err, tt.want)
+		}
+	}
+	for _, value := range []string{"", "Q.850", "Q.850;cause=x"} {
+		if _, err := Parse(value); err == nil {
+			t.Errorf("Parse(%q) succeeded", value)
+		}
+	}
+}
+
+func TestFromRequest(t *testing.T) {
+	req := sip.NewRequest(sip.BYE, sip.Uri{Scheme: "sip", Host: "198.51.100.1"})
+	if r := FromRequest(req); !r.IsZero() {
+		t.Errorf("FromRequest() = %+v without Reason header", r)
+	}
+
+	req.AppendHeader(sip.NewHeader("Reason", `SIP;cause=480;text="Gone, really"`))
+	req.AppendHeader(sip.NewHeader("reason", `bogus, Q.850;cause=18`))
+	if r := FromRequest(req); r.Q850Cause() != 18 {
+		t.Errorf("FromRequest() = %+v, want the Q.850 reason", r)
+	}
+
+	Q850(CauseNoAnswer).Set(req)
+	hdrs := req.GetHeaders("Reason")
+	if len(hdrs) != 1 || hdrs[0].Value() != `Q.850;cause=19;text="No answer from user (user alerted)"` {
+		t.Errorf("Reason headers after Set = %v", hdrs)
+	}
+}
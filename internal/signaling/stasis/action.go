@@ -0,0 +1,170 @@
+package stasis
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/dialplan"
+)
+
+// Params defines parameters for the stasis action.
+type Params struct {
+	App  string   `json:"app"`  // Application to hand the call to
+	Args []string `json:"args"` // Passed to the application in stasis_start
+}
+
+// Action hands the call to an external application until it returns the
+// call to the dialplan or the call ends.
+type Action struct {
+	registry *Registry
+	params   Params
+}
+
+// NewAction is the dialplan action factory for "stasis" actions; register
+// it with the executor's action registry.
+func (r *Registry) NewAction(raw json.RawMessage) (dialplan.Action, error) {
+	var params Params
+	if err := json.Unmarshal(raw, &params); err != nil {
+		return nil, fmt.Errorf("parse stasis params: %w", err)
+	}
+	if params.App == "" {
+		return nil, fmt.Errorf("stasis: app required")
+	}
+	return &Action{registry: r, params: params}, nil
+}
+
+// Type returns "stasis".
+func (a *Action) Type() string {
+	return "stasis"
+}
+
+// Execute holds the call in stasis and runs the application's commands
+// one at a time. A continue with a route returns a dialplan.RouteJump.
+func (a *Action) Execute(ctx context.Context, session dialplan.CallSession) error {
+	conn := a.registry.pick(a.params.App)
+	if conn == nil {
+		return fmt.Errorf("%w: %s", ErrAppNotConnected, a.params.App)
+	}
+
+	callID := session.CallID()
+	call := conn.enter(callID)
+	defer conn.leave(callID)
+
+	err := conn.send(Event{
+		Type:        EventStasisStart,
+		CallID:      callID,
+		App:         a.params.App,
+		Destination: session.Destination(),
+		CallerID:    session.CallerID(),
+		CallerName:  session.CallerName(),
+		Args:        a.params.Args,
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %s: %w", ErrAppDisconnected, a.params.App, err)
+	}
+	slog.Info("[Stasis] Call entered stasis", "call_id", callID, "app", a.params.App)
+
+	for {
+		select {
+		case <-ctx.Done():
+			a.end(conn, callID, EndHangup)
+			return ctx.Err()
+		case <-conn.done:
+			slog.Warn("[Stasis] Application disconnected with call in stasis", "call_id", callID, "app", a.params.App)
+			return fmt.Errorf("%w: %s", ErrAppDisconnected, a.params.App)
+		case cmd := <-call.commands:
+			switch cmd.Command {
+			case CommandHangup:
+				reason := cmd.Reason
+				if reason == "" {
+					reason = "stasis_hangup"
+				}
+				err := session.Hangup(reason)
+				conn.respond(cmd, err)
+				a.end(conn, callID, EndHangup)
+				return err
+			case CommandContinue:
+				conn.respond(cmd, nil)
+				a.end(conn, callID, EndContinue)
+				if cmd.Route != "" {
+					return &dialplan.RouteJump{RouteID: cmd.Route}
+				}
+				return nil
+			default:
+				_ = conn.send(a.run(ctx, session, cmd))
+			}
+		}
+	}
+}
+
+// end sends stasis_end for the call.
+func (a *Action) end(conn *appConn, callID, reason string) {
+	_ = conn.send(Event{Type: EventStasisEnd, CallID: callID, Reason: reason})
+	slog.Info("[Stasis] Call left stasis", "call_id", callID, "app", a.params.App, "reason", reason)
+}
+
+// run executes a media or bridge command and returns its response.
+// Failed commands do not end stasis; the application decides what next.
+func (a *Action) run(ctx context.Context, session dialplan.CallSession, cmd Command) Event {
+	resp := Event{Type: EventResponse, CallID: cmd.CallID, ID: cmd.ID}
+
+	var err error
+	switch cmd.Command {
+	case CommandAnswer:
+		// The call was answered before the dialplan ran
+	case CommandPlay:
+		if cmd.File == "" {
+			err = errors.New("play: file required")
+			break
+		}
+		err = session.PlayAudio(ctx, cmd.File)
+	case CommandTone:
+		if cmd.Tone == "" {
+			err = errors.New("tone: tone required")
+			break
+		}
+		err = session.PlayTone(ctx, cmd.Tone, "", time.Duration(cmd.Seconds)*time.Second)
+	case CommandSay:
+		if cmd.Text == "" {
+			err = errors.New("say: text required")
+			break
+		}
+		err = session.Say(ctx, cmd.Text, cmd.Voice)
+	case CommandGather:
+		maxDigits := cmd.MaxDigits
+		if maxDigits <= 0 {
+			maxDigits = 1
+		}
+		timeout := 5 * time.Second
+		if cmd.Seconds > 0 {
+			timeout = time.Duration(cmd.Seconds) * time.Second
+		}
+		resp.Digits, err = session.CollectDigits(ctx, cmd.Prompt, maxDigits, timeout)
+	case CommandBridge:
+		if cmd.Target == "" {
+			err = errors.New("bridge: target required")
+			break
+		}
+		timeout := 30 * time.Second
+		if cmd.Seconds > 0 {
+			timeout = time.Duration(cmd.Seconds) * time.Second
+		}
+		err = session.Dial(ctx, cmd.Target, timeout, nil)
+		var dialErr *dialplan.DialError
+		if errors.As(err, &dialErr) {
+			resp.SIPCode = dialErr.SIPCode
+			resp.SIPReason = dialErr.SIPReason
+		}
+	default:
+		err = fmt.Errorf("unknown command %q", cmd.Command)
+	}
+
+	if err != nil {
+		resp.Error = err.Error()
+	}
+	return resp
+}
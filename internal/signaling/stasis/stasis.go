@@ -0,0 +1,308 @@
+// Package stasis hands calls to external applications.
+//
+// An application connects to the signaling API over WebSocket
+// (GET /api/v1/apps/{name}/ws) and exchanges JSON text messages. When the
+// dialplan runs a "stasis" action for the application, the call is held in
+// stasis: the application receives a stasis_start event and drives the call
+// with commands (play, tone, say, gather, bridge, hangup) until it sends
+// continue, hangs up, or the caller hangs up, which end stasis with a
+// stasis_end event. Each command is answered with a response event once
+// it has completed.
+//
+// Several connections may serve one application; calls are handed to them
+// in turn. A call whose application disconnects is hung up.
+package stasis
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/gobwas/ws"
+	"github.com/gobwas/ws/wsutil"
+)
+
+// Event types sent to applications
+const (
+	EventStasisStart = "stasis_start" // A call entered stasis
+	EventStasisEnd   = "stasis_end"   // A call left stasis
+	EventResponse    = "response"     // A command completed
+)
+
+// Commands applications send
+const (
+	CommandAnswer   = "answer"   // Accepted for compatibility; calls are answered before the dialplan runs
+	CommandPlay     = "play"     // Play an audio file
+	CommandTone     = "tone"     // Play a tone
+	CommandSay      = "say"      // Speak text with text-to-speech
+	CommandGather   = "gather"   // Play a prompt and collect DTMF digits
+	CommandBridge   = "bridge"   // Dial a target and bridge it with the caller
+	CommandHangup   = "hangup"   // Hang up the call and end stasis
+	CommandContinue = "continue" // Return the call to the dialplan and end stasis
+)
+
+// stasis_end reasons
+const (
+	EndContinue = "continue" // The application sent continue
+	EndHangup   = "hangup"   // The application or the caller hung up
+)
+
+// Sentinel errors
+var (
+	ErrAppNotConnected = errors.New("stasis: application not connected")
+	ErrAppDisconnected = errors.New("stasis: application disconnected")
+)
+
+// Event is a message sent to an application.
+type Event struct {
+	Type   string `json:"type"`
+	CallID string `json:"call_id"`
+
+	// stasis_start
+	App         string   `json:"app,omitempty"`
+	Destination string   `json:"destination,omitempty"`
+	CallerID    string   `json:"caller_id,omitempty"`
+	CallerName  string   `json:"caller_name,omitempty"`
+	Args        []string `json:"args,omitempty"`
+
+	// response
+	ID        string `json:"id,omitempty"`    // ID of the command
+	Error     string `json:"error,omitempty"` // Empty on success
+	Digits    string `json:"digits,omitempty"`
+	SIPCode   int    `json:"sip_code,omitempty"` // Failed bridge: final response of the dialed leg
+	SIPReason string `json:"sip_reason,omitempty"`
+
+	// stasis_end
+	Reason string `json:"reason,omitempty"`
+}
+
+// Command is a message from an application.
+type Command struct {
+	ID      string `json:"id,omitempty"` // Echoed in the response
+	CallID  string `json:"call_id"`
+	Command string `json:"command"`
+
+	File      string `json:"file,omitempty"`       // play
+	Tone      string `json:"tone,omitempty"`       // tone
+	Text      string `json:"text,omitempty"`       // say
+	Voice     string `json:"voice,omitempty"`      // say
+	Prompt    string `json:"prompt,omitempty"`     // gather (dial tone if empty)
+	MaxDigits int    `json:"max_digits,omitempty"` // gather; default 1
+	Target    string `json:"target,omitempty"`     // bridge
+	Route     string `json:"route,omitempty"`      // continue: route to continue with (optional)
+	Reason    string `json:"reason,omitempty"`     // hangup
+
+	// Seconds is the tone duration, the gather inter-digit timeout
+	// (default 5) or the bridge ring timeout (default 30).
+	Seconds int `json:"seconds,omitempty"`
+}
+
+// AppInfo describes a connected application.
+type AppInfo struct {
+	Name        string `json:"name"`
+	Connections int    `json:"connections"`
+	Calls       int    `json:"calls"` // Calls in stasis
+}
+
+// Registry tracks the connected applications. Safe for concurrent use.
+type Registry struct {
+	mu   sync.Mutex
+	apps map[string][]*appConn
+	next map[string]int // Round-robin position per application
+}
+
+// NewRegistry creates an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		apps: make(map[string][]*appConn),
+		next: make(map[string]int),
+	}
+}
+
+// Apps returns the connected applications, sorted by name.
+func (r *Registry) Apps() []AppInfo {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	apps := make([]AppInfo, 0, len(r.apps))
+	for name, conns := range r.apps {
+		info := AppInfo{Name: name, Connections: len(conns)}
+		for _, c := range conns {
+			info.Calls += c.callCount()
+		}
+		apps = append(apps, info)
+	}
+	sort.Slice(apps, func(i, j int) bool { return apps[i].Name < apps[j].Name })
+	return apps
+}
+
+// ServeWebSocket upgrades an HTTP request to a WebSocket connection for
+// the application and serves it until it closes.
+func (r *Registry) ServeWebSocket(w http.ResponseWriter, req *http.Request, app string) {
+	netConn, _, _, err := ws.UpgradeHTTP(req, w)
+	if err != nil {
+		slog.Warn("[Stasis] WebSocket upgrade failed", "app", app, "error", err)
+		return
+	}
+
+	c := &appConn{
+		app:   app,
+		conn:  netConn,
+		calls: make(map[string]*stasisCall),
+		done:  make(chan struct{}),
+	}
+	r.add(c)
+	slog.Info("[Stasis] Application connected", "app", app, "remote", netConn.RemoteAddr())
+
+	c.readLoop()
+
+	r.remove(c)
+	slog.Info("[Stasis] Application disconnected", "app", app, "remote", netConn.RemoteAddr())
+}
+
+func (r *Registry) add(c *appConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.apps[c.app] = append(r.apps[c.app], c)
+}
+
+func (r *Registry) remove(c *appConn) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conns := r.apps[c.app]
+	for i, other := range conns {
+		if other == c {
+			conns = append(conns[:i], conns[i+1:]...)
+			break
+		}
+	}
+	if len(conns) == 0 {
+		delete(r.apps, c.app)
+		delete(r.next, c.app)
+	} else {
+		r.apps[c.app] = conns
+	}
+}
+
+// pick returns the next connection of an application, or nil.
+func (r *Registry) pick(app string) *appConn {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	conns := r.apps[app]
+	if len(conns) == 0 {
+		return nil
+	}
+	c := conns[r.next[app]%len(conns)]
+	r.next[app]++
+	return c
+}
+
+// appConn is one WebSocket connection of an application.
+type appConn struct {
+	app  string
+	conn net.Conn
+
+	writeMu sync.Mutex
+
+	mu    sync.Mutex
+	calls map[string]*stasisCall // By Call-ID
+
+	done chan struct{} // Closed when the connection closes
+}
+
+// maxPendingCommands is how many commands may queue for one call while
+// an earlier one runs.
+const maxPendingCommands = 16
+
+// stasisCall is a call held in stasis by a connection.
+type stasisCall struct {
+	commands chan Command
+}
+
+// send writes an event to the application.
+func (c *appConn) send(event Event) error {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("encode event: %w", err)
+	}
+	c.writeMu.Lock()
+	defer c.writeMu.Unlock()
+	_ = c.conn.SetWriteDeadline(time.Now().Add(10 * time.Second))
+	return wsutil.WriteServerText(c.conn, data)
+}
+
+// respond sends the response to a command.
+func (c *appConn) respond(cmd Command, err error) {
+	event := Event{Type: EventResponse, CallID: cmd.CallID, ID: cmd.ID}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	_ = c.send(event)
+}
+
+// readLoop dispatches commands to the calls they are for until the
+// connection closes.
+func (c *appConn) readLoop() {
+	defer func() {
+		close(c.done)
+		_ = c.conn.Close()
+	}()
+
+	for {
+		data, op, err := wsutil.ReadClientData(c.conn)
+		if err != nil {
+			return
+		}
+		if op != ws.OpText {
+			continue
+		}
+
+		var cmd Command
+		if err := json.Unmarshal(data, &cmd); err != nil {
+			c.respond(cmd, fmt.Errorf("invalid command: %w", err))
+			continue
+		}
+
+		c.mu.Lock()
+		call := c.calls[cmd.CallID]
+		c.mu.Unlock()
+		if call == nil {
+			c.respond(cmd, fmt.Errorf("call %q not in stasis", cmd.CallID))
+			continue
+		}
+
+		select {
+		case call.commands <- cmd:
+		default:
+			c.respond(cmd, errors.New("too many pending commands"))
+		}
+	}
+}
+
+// enter holds a call in stasis on this connection.
+func (c *appConn) enter(callID string) *stasisCall {
+	call := &stasisCall{commands: make(chan Command, maxPendingCommands)}
+	c.mu.Lock()
+	c.calls[callID] = call
+	c.mu.Unlock()
+	return call
+}
+
+// leave releases a call from stasis.
+func (c *appConn) leave(callID string) {
+	c.mu.Lock()
+	delete(c.calls, callID)
+	c.mu.Unlock()
+}
+
+func (c *appConn) callCount() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return len(c.calls)
+}
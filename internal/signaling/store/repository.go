@@ -48,6 +48,10 @@ type CDR struct {
 	BridgeID      string    `json:"bridge_id,omitempty"`
 	RecordingPath string    `json:"recording_path,omitempty"`
 	Metadata      string    `json:"metadata,omitempty"` // JSON blob for custom fields
+
+	// Billing allocation for restricted destination classes
+	DestinationClass string `json:"destination_class,omitempty"`
+	AccountCode      string `json:"account_code,omitempty"`
 }
 
 // CDRFilter specifies query criteria for CDR lookups.
@@ -0,0 +1,144 @@
+package store
+
+import (
+	"hash/maphash"
+	"sync"
+	"time"
+)
+
+// DefaultShards is the shard count of NewShardedTTLStore.
+const DefaultShards = 64
+
+// ShardedTTLStore is a TTLStore split into shards by key hash, each with
+// its own lock, so that concurrent access to different keys rarely
+// contends. One goroutine cleans up all shards. Iteration (All, ForEach,
+// Len) visits the shards one after another and is not a snapshot.
+type ShardedTTLStore[K comparable, V any] struct {
+	seed     maphash.Seed
+	shards   []*TTLStore[K, V]
+	stopCh   chan struct{}
+	stopOnce sync.Once
+	interval time.Duration
+}
+
+// NewShardedTTLStore creates a sharded TTL store with DefaultShards shards.
+func NewShardedTTLStore[K comparable, V any](cleanupInterval time.Duration) *ShardedTTLStore[K, V] {
+	return NewShardedTTLStoreN[K, V](cleanupInterval, DefaultShards)
+}
+
+// NewShardedTTLStoreN creates a sharded TTL store with n shards.
+func NewShardedTTLStoreN[K comparable, V any](cleanupInterval time.Duration, n int) *ShardedTTLStore[K, V] {
+	if n < 1 {
+		n = 1
+	}
+	s := &ShardedTTLStore[K, V]{
+		seed:     maphash.MakeSeed(),
+		shards:   make([]*TTLStore[K, V], n),
+		stopCh:   make(chan struct{}),
+		interval: cleanupInterval,
+	}
+	for i := range s.shards {
+		s.shards[i] = &TTLStore[K, V]{
+			items:  make(map[K]*Entry[V]),
+			stopCh: make(chan struct{}),
+		}
+	}
+	go s.cleanupLoop()
+	return s
+}
+
+// shard returns the shard holding key.
+func (s *ShardedTTLStore[K, V]) shard(key K) *TTLStore[K, V] {
+	return s.shards[maphash.Comparable(s.seed, key)%uint64(len(s.shards))]
+}
+
+// SetOnEvict sets the callback function called when items are evicted during cleanup.
+func (s *ShardedTTLStore[K, V]) SetOnEvict(fn func(key K, value V)) {
+	for _, shard := range s.shards {
+		shard.SetOnEvict(fn)
+	}
+}
+
+// Set stores a value with the given TTL
+func (s *ShardedTTLStore[K, V]) Set(key K, value V, ttl time.Duration) {
+	s.shard(key).Set(key, value, ttl)
+}
+
+// Get retrieves a value by key. Returns the value and true if found and not expired.
+func (s *ShardedTTLStore[K, V]) Get(key K) (V, bool) {
+	return s.shard(key).Get(key)
+}
+
+// Delete removes a key from the store
+func (s *ShardedTTLStore[K, V]) Delete(key K) bool {
+	return s.shard(key).Delete(key)
+}
+
+// Has returns true if the key exists and is not expired
+func (s *ShardedTTLStore[K, V]) Has(key K) bool {
+	return s.shard(key).Has(key)
+}
+
+// Update modifies the value for an existing key and optionally refreshes TTL
+func (s *ShardedTTLStore[K, V]) Update(key K, fn func(V) V, newTTL *time.Duration) bool {
+	return s.shard(key).Update(key, fn, newTTL)
+}
+
+// Len returns the number of non-expired items
+func (s *ShardedTTLStore[K, V]) Len() int {
+	count := 0
+	for _, shard := range s.shards {
+		count += shard.Len()
+	}
+	return count
+}
+
+// All returns all non-expired entries as a map
+func (s *ShardedTTLStore[K, V]) All() map[K]V {
+	result := make(map[K]V)
+	s.ForEach(func(key K, value V) bool {
+		result[key] = value
+		return true
+	})
+	return result
+}
+
+// ForEach iterates over all non-expired items. Each shard is locked while
+// it is visited, so fn must not modify the store.
+func (s *ShardedTTLStore[K, V]) ForEach(fn func(key K, value V) bool) {
+	for _, shard := range s.shards {
+		more := true
+		shard.ForEach(func(key K, value V) bool {
+			more = fn(key, value)
+			return more
+		})
+		if !more {
+			return
+		}
+	}
+}
+
+// Close stops the cleanup goroutine and clears the store
+func (s *ShardedTTLStore[K, V]) Close() {
+	s.stopOnce.Do(func() { close(s.stopCh) })
+	for _, shard := range s.shards {
+		shard.Clear()
+	}
+}
+
+// cleanupLoop periodically removes expired entries from every shard
+func (s *ShardedTTLStore[K, V]) cleanupLoop() {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for _, shard := range s.shards {
+				shard.cleanup()
+			}
+		case <-s.stopCh:
+			return
+		}
+	}
+}
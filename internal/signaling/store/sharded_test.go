@@ -0,0 +1,97 @@
+package store
+
+import (
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestShardedTTLStore(t *testing.T) {
+	s := NewShardedTTLStoreN[string, int](time.Hour, 8)
+	defer s.Close()
+
+	for i := range 100 {
+		s.Set(strconv.Itoa(i), i, time.Minute)
+	}
+	s.Set("expired", -1, -time.Second)
+
+	if v, ok := s.Get("42"); !ok || v != 42 {
+		t.Fatalf("Get(42) = %d, %v", v, ok)
+	}
+	if _, ok := s.Get("expired"); ok {
+		t.Fatal("expired entry returned")
+	}
+	if n := s.Len(); n != 100 {
+		t.Fatalf("Len() = %d, want 100", n)
+	}
+	if n := len(s.All()); n != 100 {
+		t.Fatalf("len(All()) = %d, want 100", n)
+	}
+	if !s.Delete("42") || s.Has("42") {
+		t.Fatal("Delete(42) did not remove the entry")
+	}
+
+	visited := 0
+	s.ForEach(func(string, int) bool {
+		visited++
+		return visited < 10
+	})
+	if visited != 10 {
+		t.Fatalf("ForEach visited %d entries after stop, want 10", visited)
+	}
+}
+
+func TestShardedTTLStoreEvicts(t *testing.T) {
+	s := NewShardedTTLStoreN[string, int](time.Hour, 4)
+	defer s.Close()
+
+	var evicted atomic.Int32
+	s.SetOnEvict(func(string, int) { evicted.Add(1) })
+	for i := range 10 {
+		s.Set(strconv.Itoa(i), i, -time.Second)
+	}
+	for _, shard := range s.shards {
+		shard.cleanup()
+	}
+	if n := evicted.Load(); n != 10 {
+		t.Fatalf("evicted %d entries, want 10", n)
+	}
+}
+
+// The dialog manager's access pattern: mostly lookups of one Call-ID per
+// request, with a write per new call.
+func benchmarkStore(b *testing.B, set func(string, int), get func(string) (int, bool)) {
+	const dialogs = 50000
+	keys := make([]string, dialogs)
+	for i := range keys {
+		keys[i] = "call-" + strconv.Itoa(i) + "@10.0.0.1"
+		set(keys[i], i)
+	}
+	var next atomic.Uint64
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		i := int(next.Add(7919))
+		for pb.Next() {
+			i++
+			key := keys[i%dialogs]
+			if i%10 == 0 {
+				set(key, i)
+			} else {
+				get(key)
+			}
+		}
+	})
+}
+
+func BenchmarkTTLStoreParallel(b *testing.B) {
+	s := NewTTLStore[string, int](time.Hour)
+	defer s.Close()
+	benchmarkStore(b, func(k string, v int) { s.Set(k, v, time.Hour) }, s.Get)
+}
+
+func BenchmarkShardedTTLStoreParallel(b *testing.B) {
+	s := NewShardedTTLStore[string, int](time.Hour)
+	defer s.Close()
+	benchmarkStore(b, func(k string, v int) { s.Set(k, v, time.Hour) }, s.Get)
+}
@@ -2,6 +2,7 @@
 package store
 
 import (
+	"container/heap"
 	"sync"
 	"time"
 )
@@ -10,6 +11,8 @@ import (
 type Entry[T any] struct {
 	Value     T
 	ExpiresAt time.Time
+
+	index int // Position in the store's expiry heap
 }
 
 // IsExpired returns true if the entry has expired
@@ -27,9 +30,12 @@ func (e *Entry[T]) TTL() time.Duration {
 }
 
 // TTLStore is a generic in-memory store with TTL support and automatic cleanup.
+// Entries are also kept in a min-heap by expiry, so cleanup only touches
+// the entries that have expired rather than scanning the whole store.
 type TTLStore[K comparable, V any] struct {
 	mu       sync.RWMutex
 	items    map[K]*Entry[V]
+	expiry   expiryHeap[K, V]
 	stopCh   chan struct{}
 	interval time.Duration
 	onEvict  func(key K, value V) // Optional callback called when items are evicted
@@ -70,13 +76,7 @@ func (s *TTLStore[K, V]) SetOnEvict(fn func(key K, value V)) {
 
 // Set stores a value with the given TTL
 func (s *TTLStore[K, V]) Set(key K, value V, ttl time.Duration) {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	s.items[key] = &Entry[V]{
-		Value:     value,
-		ExpiresAt: time.Now().Add(ttl),
-	}
+	s.SetWithExpiry(key, value, time.Now().Add(ttl))
 }
 
 // SetWithExpiry stores a value with an absolute expiration time
@@ -84,10 +84,15 @@ func (s *TTLStore[K, V]) SetWithExpiry(key K, value V, expiresAt time.Time) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	s.items[key] = &Entry[V]{
+	if old, exists := s.items[key]; exists {
+		heap.Remove(&s.expiry, old.index)
+	}
+	entry := &Entry[V]{
 		Value:     value,
 		ExpiresAt: expiresAt,
 	}
+	s.items[key] = entry
+	heap.Push(&s.expiry, expiryItem[K, V]{key: key, entry: entry})
 }
 
 // Get retrieves a value by key. Returns the value and true if found and not expired.
@@ -120,8 +125,9 @@ func (s *TTLStore[K, V]) Delete(key K) bool {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if _, exists := s.items[key]; exists {
+	if entry, exists := s.items[key]; exists {
 		delete(s.items, key)
+		heap.Remove(&s.expiry, entry.index)
 		return true
 	}
 	return false
@@ -202,6 +208,7 @@ func (s *TTLStore[K, V]) Refresh(key K, ttl time.Duration) bool {
 		return false
 	}
 	entry.ExpiresAt = time.Now().Add(ttl)
+	heap.Fix(&s.expiry, entry.index)
 	return true
 }
 
@@ -218,6 +225,7 @@ func (s *TTLStore[K, V]) Update(key K, fn func(V) V, newTTL *time.Duration) bool
 	entry.Value = fn(entry.Value)
 	if newTTL != nil {
 		entry.ExpiresAt = time.Now().Add(*newTTL)
+		heap.Fix(&s.expiry, entry.index)
 	}
 	return true
 }
@@ -227,6 +235,7 @@ func (s *TTLStore[K, V]) Clear() {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 	s.items = make(map[K]*Entry[V])
+	s.expiry = nil
 }
 
 // Close stops the cleanup goroutine and clears the store
@@ -252,21 +261,14 @@ func (s *TTLStore[K, V]) cleanupLoop() {
 
 // cleanup removes all expired entries and calls the eviction callback if set
 func (s *TTLStore[K, V]) cleanup() {
-	// Collect expired entries while holding lock
+	// Pop expired entries off the heap while holding lock
 	s.mu.Lock()
-	var expired []struct {
-		key   K
-		value V
-	}
-
-	for key, entry := range s.items {
-		if entry.IsExpired() {
-			expired = append(expired, struct {
-				key   K
-				value V
-			}{key, entry.Value})
-			delete(s.items, key)
-		}
+	var expired []expiryItem[K, V]
+	now := time.Now()
+	for len(s.expiry) > 0 && now.After(s.expiry[0].entry.ExpiresAt) {
+		item := heap.Pop(&s.expiry).(expiryItem[K, V])
+		delete(s.items, item.key)
+		expired = append(expired, item)
 	}
 	onEvict := s.onEvict
 	s.mu.Unlock()
@@ -274,7 +276,45 @@ func (s *TTLStore[K, V]) cleanup() {
 	// Call eviction callbacks outside of the critical section to avoid deadlocks
 	if onEvict != nil {
 		for _, e := range expired {
-			onEvict(e.key, e.value)
+			onEvict(e.key, e.entry.Value)
 		}
 	}
 }
+
+// expiryItem is an entry in the expiry heap.
+type expiryItem[K comparable, V any] struct {
+	key   K
+	entry *Entry[V]
+}
+
+// expiryHeap orders entries by expiry, soonest first (container/heap).
+// Each entry records its position so it can be moved or removed when its
+// expiry changes or it is deleted.
+type expiryHeap[K comparable, V any] []expiryItem[K, V]
+
+func (h expiryHeap[K, V]) Len() int { return len(h) }
+
+func (h expiryHeap[K, V]) Less(i, j int) bool {
+	return h[i].entry.ExpiresAt.Before(h[j].entry.ExpiresAt)
+}
+
+func (h expiryHeap[K, V]) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].entry.index = i
+	h[j].entry.index = j
+}
+
+func (h *expiryHeap[K, V]) Push(x any) {
+	item := x.(expiryItem[K, V])
+	item.entry.index = len(*h)
+	*h = append(*h, item)
+}
+
+func (h *expiryHeap[K, V]) Pop() any {
+	old := *h
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = expiryItem[K, V]{} // Release the entry
+	*h = old[:n-1]
+	return item
+}
@@ -0,0 +1,59 @@
+package store
+
+import (
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+)
+
+func TestTTLStoreCleanupFollowsExpiry(t *testing.T) {
+	s := NewTTLStore[string, int](time.Hour)
+	defer s.Close()
+
+	var evicted []string
+	s.SetOnEvict(func(key string, _ int) { evicted = append(evicted, key) })
+
+	s.Set("expired", 1, -time.Second)
+	s.Set("refreshed", 2, -time.Second)
+	s.Refresh("refreshed", time.Minute)
+	s.Set("overwritten", 3, time.Minute)
+	s.Set("overwritten", 3, -time.Second)
+	s.Set("deleted", 4, -time.Second)
+	s.Delete("deleted")
+	s.Set("live", 5, time.Minute)
+	ttl := -time.Second
+	s.Set("updated", 6, time.Minute)
+	s.Update("updated", func(v int) int { return v + 1 }, &ttl)
+
+	s.cleanup()
+
+	slices.Sort(evicted)
+	if want := []string{"expired", "overwritten", "updated"}; !slices.Equal(evicted, want) {
+		t.Fatalf("evicted %v, want %v", evicted, want)
+	}
+	if n := len(s.items); n != 2 {
+		t.Fatalf("%d entries left, want 2", n)
+	}
+	if n := len(s.expiry); n != 2 {
+		t.Fatalf("%d entries in the expiry heap, want 2", n)
+	}
+}
+
+// Cleanup with many live entries and a few expiring each round, as with
+// six-figure dialog and binding counts.
+func BenchmarkTTLStoreCleanup(b *testing.B) {
+	const live = 200000
+	s := NewTTLStore[string, int](time.Hour)
+	defer s.Close()
+	for i := range live {
+		s.Set("live-"+strconv.Itoa(i), i, time.Hour)
+	}
+	b.ResetTimer()
+	for i := range b.N {
+		for j := range 10 {
+			s.Set("expired-"+strconv.Itoa(i*10+j), j, -time.Second)
+		}
+		s.cleanup()
+	}
+}
@@ -0,0 +1,45 @@
+package topology
+
+import (
+	"net"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+)
+
+// Middleware scrubs the responses sent to received requests. trunk
+// returns the trunk a request came from ("" for none).
+func (h *Hider) Middleware(trunk func(req *sip.Request) string) middleware.Middleware {
+	return middleware.Func("topology", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		name := trunk(req)
+		// Only wrap when needed: the SIP stack special-cases its own
+		// transaction type for INVITEs canceled before they are answered
+		if tx != nil && h.trunkPolicy(name).Hide {
+			tx = &hiderTx{ServerTransaction: tx, hider: h, peer: sourceHost(req), trunk: name}
+		}
+		next(req, tx)
+	})
+}
+
+// hiderTx scrubs the responses of a server transaction
+type hiderTx struct {
+	sip.ServerTransaction
+	hider *Hider
+	peer  string
+	trunk string
+}
+
+// Respond implements sip.ServerTransaction.
+func (t *hiderTx) Respond(res *sip.Response) error {
+	t.hider.SentResponse(res, t.peer, t.trunk)
+	return t.ServerTransaction.Respond(res)
+}
+
+// sourceHost returns the host a request was received from
+func sourceHost(req *sip.Request) string {
+	host, _, err := net.SplitHostPort(req.Source())
+	if err != nil {
+		return req.Source()
+	}
+	return host
+}
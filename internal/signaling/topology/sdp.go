@@ -0,0 +1,170 @@
+// Package topology keeps switchboard's internal network out of the
+// messages it sends to its peers.
+//
+// Every SDP body sent on either leg of a call passes through HideSDP, so
+// it names no address but the media address the RTP manager advertised,
+// and those of streams relayed between endpoints (see sdpmedia).
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"slices"
+	"strings"
+
+	psdp "github.com/pion/sdp/v3"
+	"github.com/sebas/switchboard/internal/signaling/sdpmedia"
+)
+
+// OriginUser is the user name of the origin line of SDP bodies sent
+const OriginUser = "switchboard"
+
+// ErrNoAddress is returned for an SDP body that names no media address
+var ErrNoAddress = errors.New("topology: no media address in SDP")
+
+// HideSDP rewrites an SDP body so that the only address it names is addr:
+// the origin line, the session and media connection lines and the rtcp
+// attributes all carry addr, with the address type to match. An empty addr
+// keeps the connection address of the first media description not relayed
+// (or the session's, or the origin's when on hold), the address the RTP
+// manager advertised. Details of the network behind it are dropped: the
+// origin user name, the session information, URI, email and phone lines,
+// media titles and ICE attributes. Hold addresses (0.0.0.0 or ::) are
+// kept, and so are streams relayed between endpoints, which carry the
+// other endpoint's address by design.
+func HideSDP(body []byte, addr string) ([]byte, error) {
+	sdp := &psdp.SessionDescription{}
+	if err := sdp.Unmarshal(body); err != nil {
+		return nil, fmt.Errorf("topology: %w", err)
+	}
+	if addr == "" {
+		addr = mediaAddr(sdp)
+	}
+	if addr == "" {
+		return nil, ErrNoAddress
+	}
+	addrType := AddressType(addr)
+
+	sdp.Origin.Username = OriginUser
+	sdp.Origin.NetworkType = "IN"
+	sdp.Origin.AddressType = addrType
+	sdp.Origin.UnicastAddress = addr
+	sdp.SessionInformation = nil
+	sdp.URI = nil
+	sdp.EmailAddress = nil
+	sdp.PhoneNumber = nil
+
+	hideConnection(sdp.ConnectionInformation, addr, addrType)
+	sdp.Attributes = hideAttributes(sdp.Attributes, addr, addrType)
+	uncovered := false
+	for _, media := range sdp.MediaDescriptions {
+		if sdpmedia.IsRelayed(media) {
+			continue
+		}
+		media.MediaTitle = nil
+		hideConnection(media.ConnectionInformation, addr, addrType)
+		media.Attributes = hideAttributes(media.Attributes, addr, addrType)
+		uncovered = uncovered || media.ConnectionInformation == nil
+	}
+	if uncovered && sdp.ConnectionInformation == nil {
+		sdp.ConnectionInformation = &psdp.ConnectionInformation{
+			NetworkType: "IN",
+			AddressType: addrType,
+			Address:     &psdp.Address{Address: addr},
+		}
+	}
+	return sdp.Marshal()
+}
+
+// HiddenSDP returns an SDP body about to be sent with HideSDP applied
+// keeping its own media address. Empty bodies and bodies HideSDP rejects
+// are returned unchanged.
+func HiddenSDP(body []byte) []byte {
+	if len(body) == 0 {
+		return body
+	}
+	hidden, err := HideSDP(body, "")
+	if err != nil {
+		slog.Warn("[Topology] SDP sent unchanged", "error", err)
+		return body
+	}
+	return hidden
+}
+
+// AddressType returns the SDP address type ("IP4" or "IP6") of an address.
+// Hostnames are assumed to be IPv4.
+func AddressType(addr string) string {
+	if ip := net.ParseIP(addr); ip != nil && ip.To4() == nil {
+		return "IP6"
+	}
+	return "IP4"
+}
+
+// mediaAddr returns the first connection address of an SDP that is not a
+// hold address, looking at the media descriptions not relayed first
+func mediaAddr(sdp *psdp.SessionDescription) string {
+	conns := make([]*psdp.ConnectionInformation, 0, len(sdp.MediaDescriptions)+1)
+	for _, media := range sdp.MediaDescriptions {
+		if !sdpmedia.IsRelayed(media) {
+			conns = append(conns, media.ConnectionInformation)
+		}
+	}
+	conns = append(conns, sdp.ConnectionInformation)
+	for _, c := range conns {
+		if c != nil && c.Address != nil && c.Address.Address != "" && !isHold(c.Address.Address) {
+			return c.Address.Address
+		}
+	}
+	// On hold, only the origin line still names the advertised address
+	if addr := sdp.Origin.UnicastAddress; addr != "" && !isHold(addr) {
+		return addr
+	}
+	return ""
+}
+
+// hideConnection points a connection line at addr, unless it is a hold
+func hideConnection(c *psdp.ConnectionInformation, addr, addrType string) {
+	if c == nil || (c.Address != nil && isHold(c.Address.Address)) {
+		return
+	}
+	c.NetworkType = "IN"
+	c.AddressType = addrType
+	c.Address = &psdp.Address{Address: addr}
+}
+
+// hideAttributes drops ICE attributes, which list the addresses of every
+// interface, and points the address of rtcp attributes (RFC 3605) at addr
+func hideAttributes(attrs []psdp.Attribute, addr, addrType string) []psdp.Attribute {
+	attrs = slices.DeleteFunc(attrs, func(a psdp.Attribute) bool {
+		switch a.Key {
+		case "candidate", "remote-candidates", "end-of-candidates":
+			return true
+		}
+		return strings.HasPrefix(a.Key, "ice-")
+	})
+	for i, a := range attrs {
+		if a.Key != "rtcp" {
+			continue
+		}
+		if port, rest, ok := strings.Cut(a.Value, " "); ok && !isHold(lastField(rest)) {
+			attrs[i].Value = port + " IN " + addrType + " " + addr
+		}
+	}
+	return attrs
+}
+
+// isHold reports whether an address is a hold address
+func isHold(addr string) bool {
+	return addr == "0.0.0.0" || addr == "::"
+}
+
+// lastField returns the last whitespace-separated field of s
+func lastField(s string) string {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return ""
+	}
+	return fields[len(fields)-1]
+}
@@ -0,0 +1,174 @@
+package topology
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func sdpLines(lines ...string) []byte {
+	return []byte(strings.Join(lines, "\r\n") + "\r\n")
+}
+
+func TestHideSDP(t *testing.T) {
+	tests := []struct {
+		name string
+		body []byte
+		addr string
+		want []string // Lines the result must contain
+		bad  []string // Substrings it must not contain
+	}{
+		{
+			name: "multiple media lines",
+			body: sdpLines(
+				"v=0",
+				"o=alice 42 7 IN IP4 10.1.2.3",
+				"s=call",
+				"i=lab phone behind rtp-3.internal",
+				"u=http://rtp-3.internal/",
+				"e=ops@internal",
+				"c=IN IP4 10.1.2.3",
+				"t=0 0",
+				"m=audio 40000 RTP/AVP 0 101",
+				"i=audio via eth1",
+				"c=IN IP4 10.1.2.4",
+				"a=rtpmap:0 PCMU/8000",
+				"a=rtcp:40001 IN IP4 10.1.2.4",
+				"a=candidate:1 1 UDP 2130706431 10.1.2.4 40000 typ host",
+				"a=ice-ufrag:abcd",
+				"a=sendrecv",
+				"m=video 0 RTP/AVP 96",
+				"c=IN IP4 172.16.0.9",
+				"a=rtpmap:96 H264/90000",
+				"a=rtcp:40003",
+			),
+			addr: "203.0.113.5",
+			want: []string{
+				"o=switchboard 42 7 IN IP4 203.0.113.5",
+				"c=IN IP4 203.0.113.5",
+				"m=audio 40000 RTP/AVP 0 101",
+				"a=rtcp:40001 IN IP4 203.0.113.5",
+				"m=video 0 RTP/AVP 96",
+				"a=rtcp:40003",
+				"a=rtpmap:96 H264/90000",
+				"a=sendrecv",
+			},
+			bad: []string{"10.1.2.", "172.16.", "internal", "candidate", "ice-", "i=", "u=", "e="},
+		},
+		{
+			name: "IPv6 offer keeps its media address",
+			body: sdpLines(
+				"v=0",
+				"o=- 1 1 IN IP4 192.168.1.10",
+				"s=-",
+				"t=0 0",
+				"m=audio 5004 RTP/AVP 8",
+				"c=IN IP6 2001:db8::10",
+				"a=rtcp:5005 IN IP6 fd00::1",
+			),
+			want: []string{
+				"o=switchboard 1 1 IN IP6 2001:db8::10",
+				"c=IN IP6 2001:db8::10",
+				"a=rtcp:5005 IN IP6 2001:db8::10",
+			},
+			bad: []string{"192.168.", "fd00::"},
+		},
+		{
+			name: "IPv4 session rewritten to an IPv6 address",
+			body: sdpLines(
+				"v=0",
+				"o=- 1 1 IN IP4 10.0.0.5",
+				"s=-",
+				"c=IN IP4 10.0.0.5",
+				"t=0 0",
+				"m=audio 5004 RTP/AVP 0",
+			),
+			addr: "2001:db8::5",
+			want: []string{
+				"o=switchboard 1 1 IN IP6 2001:db8::5",
+				"c=IN IP6 2001:db8::5",
+			},
+			bad: []string{"10.0.0.5", "IP4"},
+		},
+		{
+			name: "relayed video keeps the other endpoint's address",
+			body: sdpLines(
+				"v=0",
+				"o=- 1 1 IN IP4 10.0.0.5",
+				"s=-",
+				"c=IN IP4 10.0.0.5",
+				"t=0 0",
+				"m=video 51000 RTP/AVP 96",
+				"c=IN IP4 198.51.100.7",
+				"a=rtpmap:96 H264/90000",
+				"m=audio 5004 RTP/AVP 0",
+			),
+			want: []string{
+				"o=switchboard 1 1 IN IP4 10.0.0.5",
+				"c=IN IP4 10.0.0.5",
+				"m=video 51000 RTP/AVP 96",
+				"c=IN IP4 198.51.100.7",
+			},
+		},
+		{
+			name: "hold address kept",
+			body: sdpLines(
+				"v=0",
+				"o=- 1 2 IN IP4 10.0.0.5",
+				"s=-",
+				"c=IN IP4 0.0.0.0",
+				"t=0 0",
+				"m=audio 5004 RTP/AVP 0",
+				"a=sendonly",
+			),
+			addr: "203.0.113.5",
+			want: []string{
+				"o=switchboard 1 2 IN IP4 203.0.113.5",
+				"c=IN IP4 0.0.0.0",
+			},
+			bad: []string{"10.0.0.5"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := HideSDP(tt.body, tt.addr)
+			if err != nil {
+				t.Fatalf("HideSDP: %v", err)
+			}
+			got := string(out)
+			lines := strings.Split(got, "\r\n")
+			for _, want := range tt.want {
+				found := false
+				for _, line := range lines {
+					found = found || line == want
+				}
+				if !found {
+					t.Errorf("missing %q in:\n%s", want, got)
+				}
+			}
+			for _, bad := range tt.bad {
+				if strings.Contains(got, bad) {
+					t.Errorf("%q not hidden in:\n%s", bad, got)
+				}
+			}
+
+			// Hiding is idempotent
+			again, err := HideSDP(out, "")
+			if err != nil || string(again) != got {
+				t.Errorf("second pass = %q, %v", again, err)
+			}
+		})
+	}
+}
+
+func TestHideSDPNoAddress(t *testing.T) {
+	body := sdpLines("v=0", "o=- 1 1 IN IP4 0.0.0.0", "s=-", "t=0 0", "m=audio 5004 RTP/AVP 0")
+	if _, err := HideSDP(body, ""); !errors.Is(err, ErrNoAddress) {
+		t.Errorf("err = %v, want ErrNoAddress", err)
+	}
+	out, err := HideSDP(body, "203.0.113.5")
+	if err != nil || !strings.Contains(string(out), "c=IN IP4 203.0.113.5") {
+		t.Errorf("HideSDP = %q, %v; want a session connection line", out, err)
+	}
+}
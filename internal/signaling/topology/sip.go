@@ -0,0 +1,330 @@
+package topology
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+)
+
+// Policy is how much of the signaling topology a peer sees. The zero
+// Policy leaves SIP headers as they are.
+type Policy struct {
+	// Hide scrubs the SIP headers of messages sent to the peer
+	Hide bool `json:"hide"`
+
+	// Internal lists host names, IPs and CIDRs of the internal network
+	// (proxies, load balancers, other nodes). Header values naming them
+	// are removed or rewritten to the advertised address. This server's
+	// host name is always internal.
+	Internal []string `json:"internal,omitempty"`
+
+	// ContactUser replaces the user part of Contact URIs; empty removes it
+	ContactUser string `json:"contact_user,omitempty"`
+}
+
+// compiledPolicy is a Policy ready for matching
+type compiledPolicy struct {
+	Policy
+	names    []string
+	prefixes []netip.Prefix
+}
+
+// compile validates p; extra are more internal host names
+func (p Policy) compile(extra ...string) (*compiledPolicy, error) {
+	c := &compiledPolicy{Policy: p}
+	for _, entry := range slices.Concat(p.Internal, extra) {
+		entry = strings.Trim(strings.TrimSpace(entry), "[]")
+		switch {
+		case entry == "":
+		case strings.Contains(entry, "/"):
+			prefix, err := netip.ParsePrefix(entry)
+			if err != nil {
+				return nil, fmt.Errorf("topology: internal %q: %w", entry, err)
+			}
+			c.prefixes = append(c.prefixes, prefix.Masked())
+		default:
+			if addr, err := netip.ParseAddr(entry); err == nil {
+				c.prefixes = append(c.prefixes, netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen()))
+				continue
+			}
+			c.names = append(c.names, strings.ToLower(entry))
+		}
+	}
+	return c, nil
+}
+
+// internal reports whether a host, bracketed or not, is internal
+func (c *compiledPolicy) internal(host string) bool {
+	host = strings.Trim(host, "[]")
+	if addr, err := netip.ParseAddr(host); err == nil {
+		addr = addr.Unmap()
+		return slices.ContainsFunc(c.prefixes, func(p netip.Prefix) bool { return p.Contains(addr) })
+	}
+	return slices.Contains(c.names, strings.ToLower(host))
+}
+
+// hostToken matches the host names, IPv4 addresses and bracketed IPv6
+// addresses in a header value
+var hostToken = regexp.MustCompile(`\[[0-9A-Fa-f:.]+\]|[A-Za-z0-9](?:[A-Za-z0-9.-]*[A-Za-z0-9])?`)
+
+// rewrite replaces the internal hosts named in a header value with host
+func (c *compiledPolicy) rewrite(value, host string) string {
+	return hostToken.ReplaceAllStringFunc(value, func(token string) string {
+		if c.internal(token) {
+			return host
+		}
+		return token
+	})
+}
+
+// typedHeaders are the headers the SIP stack parses, which are scrubbed
+// field by field instead of rewritten as text
+var typedHeaders = []string{
+	"via", "from", "to", "call-id", "cseq", "contact", "route",
+	"record-route", "max-forwards", "content-length", "content-type",
+}
+
+// message is the header access shared by requests and responses
+type message interface {
+	Headers() []sip.Header
+	GetHeaders(name string) []sip.Header
+	AppendHeader(header sip.Header)
+	RemoveHeader(name string) bool
+}
+
+// Hider scrubs the SIP messages sent to peers whose Policy hides the
+// topology. Immutable once trunks are added; safe for concurrent use.
+type Hider struct {
+	def       *compiledPolicy
+	trunks    map[string]*compiledPolicy
+	peers     []peerPolicy
+	advertise func(peer string) string
+	port      int
+	self      string
+}
+
+// peerPolicy is the policy of a trunk's servers, for requests sent to them
+type peerPolicy struct {
+	host   string
+	prefix netip.Prefix
+	policy *compiledPolicy
+}
+
+// NewHider creates a hider applying def to peers of no trunk. advertise
+// returns the address advertised to a peer (host or IP) and port is the
+// SIP port.
+func NewHider(def Policy, advertise func(peer string) string, port int) (*Hider, error) {
+	self, _ := os.Hostname()
+	h := &Hider{
+		trunks:    make(map[string]*compiledPolicy),
+		advertise: advertise,
+		port:      port,
+		self:      self,
+	}
+	var err error
+	if h.def, err = def.compile(self); err != nil {
+		return nil, err
+	}
+	return h, nil
+}
+
+// AddTrunk sets the policy of a trunk, applied to the responses to its
+// requests and to requests sent to its hosts (host names, IPs or CIDRs).
+func (h *Hider) AddTrunk(name string, hosts []string, p Policy) error {
+	if name == "" {
+		return errors.New("topology: trunk name required")
+	}
+	c, err := p.compile(h.self)
+	if err != nil {
+		return fmt.Errorf("trunk %s: %w", name, err)
+	}
+	h.trunks[name] = c
+	for _, host := range hosts {
+		pp := peerPolicy{host: strings.ToLower(strings.Trim(host, "[]")), policy: c}
+		if prefix, err := netip.ParsePrefix(host); err == nil {
+			pp.prefix = prefix.Masked()
+		} else if addr, err := netip.ParseAddr(pp.host); err == nil {
+			pp.prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		h.peers = append(h.peers, pp)
+	}
+	return nil
+}
+
+// Hides reports whether the topology is hidden from any peer
+func (h *Hider) Hides() bool {
+	if h.def.Hide {
+		return true
+	}
+	for _, c := range h.trunks {
+		if c.Hide {
+			return true
+		}
+	}
+	return false
+}
+
+// peerPolicy returns the policy for requests sent to a peer host
+func (h *Hider) peerPolicy(peer string) *compiledPolicy {
+	host := strings.ToLower(strings.Trim(peer, "[]"))
+	addr, addrErr := netip.ParseAddr(host)
+	for _, pp := range h.peers {
+		if pp.prefix.IsValid() {
+			if addrErr == nil && pp.prefix.Contains(addr.Unmap()) {
+				return pp.policy
+			}
+		} else if pp.host == host {
+			return pp.policy
+		}
+	}
+	return h.def
+}
+
+// trunkPolicy returns the policy for responses to requests from a trunk
+// ("" for none)
+func (h *Hider) trunkPolicy(trunk string) *compiledPolicy {
+	if c, ok := h.trunks[trunk]; ok {
+		return c
+	}
+	return h.def
+}
+
+// SentRequest scrubs a request about to be sent to peer (its host):
+// Record-Route is removed, internal Vias are replaced by one naming the
+// advertised address, and Contact and the other headers are scrubbed.
+func (h *Hider) SentRequest(req *sip.Request, peer string) {
+	c := h.peerPolicy(peer)
+	if !c.Hide {
+		return
+	}
+	host := sipaddr.Host(h.advertise(peer))
+	removeAll(req, "Record-Route")
+
+	vias := req.GetHeaders("Via")
+	kept := slices.DeleteFunc(slices.Clone(vias), func(hdr sip.Header) bool {
+		via, ok := hdr.(*sip.ViaHeader)
+		return ok && c.internal(via.Host)
+	})
+	if len(kept) != len(vias) {
+		removeAll(req, "Via")
+		for _, hdr := range kept {
+			req.AppendHeader(hdr)
+		}
+	}
+	// Without a Via the SIP stack adds one naming this host's own IP
+	if len(kept) == 0 {
+		via := &sip.ViaHeader{
+			ProtocolName:    "SIP",
+			ProtocolVersion: "2.0",
+			Transport:       req.Transport(),
+			Host:            host,
+			Port:            h.port,
+			Params:          sip.NewParams(),
+		}
+		via.Params.Add("branch", sip.GenerateBranchN(16))
+		via.Params.Add("rport", "")
+		req.PrependHeader(via)
+	}
+
+	if from := req.From(); from != nil && c.internal(from.Address.Host) {
+		from.Address.Host = host
+	}
+	c.scrub(req, host)
+}
+
+// ReceivedResponse does nothing; it completes the header policy
+// interface of the B2BUA originator.
+func (h *Hider) ReceivedResponse(*sip.Response, string) {}
+
+// SentResponse scrubs a response about to be sent to peer (its host) on a
+// request from trunk ("" for none): Record-Route entries naming internal
+// hosts are removed, and Contact and the other headers are scrubbed. Via
+// is kept, as the response is routed by it.
+func (h *Hider) SentResponse(res *sip.Response, peer, trunk string) {
+	c := h.trunkPolicy(trunk)
+	if !c.Hide {
+		return
+	}
+	routes := res.GetHeaders("Record-Route")
+	kept := slices.DeleteFunc(slices.Clone(routes), func(hdr sip.Header) bool {
+		rr, ok := hdr.(*sip.RecordRouteHeader)
+		return ok && c.internal(rr.Address.Host)
+	})
+	if len(kept) != len(routes) {
+		removeAll(res, "Record-Route")
+		for _, hdr := range kept {
+			res.AppendHeader(hdr)
+		}
+	}
+	c.scrub(res, sipaddr.Host(h.advertise(peer)))
+}
+
+// scrub sets the user part of Contact URIs and points Contact hosts and
+// the values of untyped headers that name internal hosts at host
+func (c *compiledPolicy) scrub(msg message, host string) {
+	// Contacts are replaced with copies: the stack may share its own
+	contacts := msg.GetHeaders("Contact")
+	scrubbed := make([]sip.Header, 0, len(contacts))
+	for _, hdr := range contacts {
+		if contact, ok := hdr.(*sip.ContactHeader); ok {
+			contact = contact.Clone()
+			contact.Address.User = c.ContactUser
+			if c.internal(contact.Address.Host) {
+				contact.Address.Host = host
+			}
+			hdr = contact
+		}
+		scrubbed = append(scrubbed, hdr)
+	}
+	if len(scrubbed) > 0 {
+		removeAll(msg, "Contact")
+		for _, hdr := range scrubbed {
+			msg.AppendHeader(hdr)
+		}
+	}
+
+	var names []string
+	for _, hdr := range msg.Headers() {
+		name := strings.ToLower(hdr.Name())
+		if slices.Contains(typedHeaders, name) || slices.Contains(names, name) {
+			continue
+		}
+		if c.rewrite(hdr.Value(), host) != hdr.Value() {
+			names = append(names, name)
+		}
+	}
+	for _, name := range names {
+		values := msg.GetHeaders(name)
+		rewritten := make([]string, len(values))
+		for i, hdr := range values {
+			rewritten[i] = c.rewrite(hdr.Value(), host)
+		}
+		canonical := values[0].Name()
+		removeAll(msg, name)
+		for _, v := range rewritten {
+			msg.AppendHeader(sip.NewHeader(canonical, v))
+		}
+	}
+}
+
+// removeAll removes every value of a header, whatever the case of its
+// name (RemoveHeader matches names exactly)
+func removeAll(msg message, name string) {
+	var names []string
+	for _, hdr := range msg.Headers() {
+		if strings.EqualFold(hdr.Name(), name) && !slices.Contains(names, hdr.Name()) {
+			names = append(names, hdr.Name())
+		}
+	}
+	for _, n := range names {
+		for msg.RemoveHeader(n) {
+		}
+	}
+}
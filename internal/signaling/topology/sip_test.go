@@ -0,0 +1,90 @@
+package topology
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/emiago/sipgo/sip"
+)
+
+func newTestHider(t *testing.T) *Hider {
+	t.Helper()
+	h, err := NewHider(Policy{}, func(string) string { return "203.0.113.5" }, 5060)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = h.AddTrunk("carrier", []string{"198.51.100.0/24"}, Policy{
+		Hide:     true,
+		Internal: []string{"10.0.0.0/8", "lb1.internal"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return h
+}
+
+func newTestInvite() *sip.Request {
+	req := sip.NewRequest(sip.INVITE, sip.Uri{Scheme: "sip", User: "bob", Host: "198.51.100.7"})
+	req.AppendHeader(&sip.FromHeader{Address: sip.Uri{Scheme: "sip", User: "alice", Host: "10.0.0.9"}, Params: sip.NewParams()})
+	req.AppendHeader(&sip.ToHeader{Address: sip.Uri{Scheme: "sip", User: "bob", Host: "198.51.100.7"}, Params: sip.NewParams()})
+	callID := sip.CallIDHeader("test-call")
+	req.AppendHeader(&callID)
+	req.AppendHeader(&sip.CSeqHeader{SeqNo: 1, MethodName: sip.INVITE})
+	req.AppendHeader(&sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "switchboard", Host: "10.0.0.9", Port: 5060}})
+	req.AppendHeader(&sip.RecordRouteHeader{Address: sip.Uri{Scheme: "sip", Host: "lb1.internal", UriParams: sip.NewParams()}})
+	req.AppendHeader(sip.NewHeader("Diversion", "<sip:100@lb1.internal>;reason=unconditional"))
+	return req
+}
+
+func TestHiderSentRequest(t *testing.T) {
+	h := newTestHider(t)
+
+	req := newTestInvite()
+	h.SentRequest(req, "198.51.100.7")
+	msg := req.String()
+	if strings.Contains(msg, "10.0.0.9") || strings.Contains(msg, "lb1.internal") {
+		t.Errorf("internal host not hidden:\n%s", msg)
+	}
+	if req.GetHeader("Record-Route") != nil {
+		t.Error("Record-Route not removed")
+	}
+	if via := req.Via(); via == nil || via.Host != "203.0.113.5" || via.Port != 5060 {
+		t.Errorf("Via = %v, want the advertised address", via)
+	}
+	if contact := req.Contact(); contact == nil || contact.Address.User != "" || contact.Address.Host != "203.0.113.5" {
+		t.Errorf("Contact = %v, want no user and the advertised host", contact)
+	}
+	if got := req.GetHeader("Diversion").Value(); got != "<sip:100@203.0.113.5>;reason=unconditional" {
+		t.Errorf("Diversion = %q", got)
+	}
+
+	// Peers of no trunk get the default policy, which hides nothing
+	req = newTestInvite()
+	before := req.String()
+	h.SentRequest(req, "192.0.2.1")
+	if req.String() != before {
+		t.Errorf("request to other peer changed:\n%s", req.String())
+	}
+}
+
+func TestHiderSentResponse(t *testing.T) {
+	h := newTestHider(t)
+
+	req := newTestInvite()
+	req.PrependHeader(&sip.ViaHeader{ProtocolName: "SIP", ProtocolVersion: "2.0", Transport: "UDP", Host: "198.51.100.7", Port: 5060, Params: sip.NewParams()})
+	req.AppendHeader(&sip.RecordRouteHeader{Address: sip.Uri{Scheme: "sip", Host: "198.51.100.1", UriParams: sip.NewParams()}})
+	res := sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil)
+	res.AppendHeader(&sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "switchboard", Host: "10.0.0.9", Port: 5060}})
+
+	h.SentResponse(res, "198.51.100.7", "carrier")
+	if via := res.Via(); via == nil || via.Host != "198.51.100.7" {
+		t.Errorf("Via = %v, want the request's", via)
+	}
+	routes := res.GetHeaders("Record-Route")
+	if len(routes) != 1 || !strings.Contains(routes[0].Value(), "198.51.100.1") {
+		t.Errorf("Record-Route = %v, want only the external route", routes)
+	}
+	if contact := res.Contact(); contact == nil || contact.Address.User != "" || contact.Address.Host != "203.0.113.5" {
+		t.Errorf("Contact = %v", contact)
+	}
+}
@@ -0,0 +1,60 @@
+package trunks
+
+import (
+	"log/slog"
+	"time"
+
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/middleware"
+)
+
+// Middleware annotates requests received on a SIP-TLS connection whose
+// certificate identifies a trunk, and refuses INVITEs starting a call
+// over the trunk's limits with 503. calls counts the calls in progress
+// from a trunk. Requests from unidentified peers pass unchanged.
+func (r *Registry) Middleware(peers *Peers, calls func(trunk string) int) middleware.Middleware {
+	return middleware.Func("trunks", func(req *sip.Request, tx sip.ServerTransaction, next middleware.Handler) {
+		chain := peers.Certificates(req.Source())
+		if chain == nil {
+			next(req, tx)
+			return
+		}
+		trunk, ok := r.Identify(chain)
+		if !ok {
+			next(req, tx)
+			return
+		}
+		middleware.Annotate(req, Annotation, trunk.Name)
+
+		if req.Method != sip.INVITE {
+			next(req, tx)
+			return
+		}
+		if to := req.To(); to != nil {
+			if _, inDialog := to.Params.Get("tag"); inDialog {
+				next(req, tx)
+				return
+			}
+		}
+
+		if trunk.MaxChannels > 0 && calls != nil && calls(trunk.Name) >= trunk.MaxChannels {
+			slog.Info("[Trunks] Channel limit reached, call refused", "trunk", trunk.Name, "max_channels", trunk.MaxChannels, "call_id", callID(req))
+			middleware.Reject(req, tx, sip.StatusServiceUnavailable, "Service Unavailable - Trunk Channels Exhausted")
+			return
+		}
+		if !r.allowCall(trunk, time.Now()) {
+			slog.Info("[Trunks] Call rate limit reached, call refused", "trunk", trunk.Name, "max_cps", trunk.MaxCPS, "call_id", callID(req))
+			middleware.Reject(req, tx, sip.StatusServiceUnavailable, "Service Unavailable - Trunk Call Rate Exceeded")
+			return
+		}
+		slog.Debug("[Trunks] Call from trunk", "trunk", trunk.Name, "call_id", callID(req))
+		next(req, tx)
+	})
+}
+
+func callID(req *sip.Request) string {
+	if h := req.CallID(); h != nil {
+		return h.Value()
+	}
+	return ""
+}
@@ -0,0 +1,81 @@
+package trunks
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sync"
+)
+
+// Peers records the client certificates of TLS connections by remote
+// address, the source of the requests received on them. Safe for
+// concurrent use.
+type Peers struct {
+	mu    sync.RWMutex
+	chain map[string][]*x509.Certificate
+}
+
+// NewPeers returns an empty record
+func NewPeers() *Peers {
+	return &Peers{chain: make(map[string][]*x509.Certificate)}
+}
+
+// Listen wraps a TLS listener so the certificates of its connections are
+// recorded once their handshake completes, and forgotten when they close.
+// Connections of l must be *tls.Conn.
+func (p *Peers) Listen(l net.Listener) net.Listener {
+	return &peerListener{Listener: l, peers: p}
+}
+
+// Certificates returns the certificate chain, leaf first, presented on
+// the connection from addr; nil if none was
+func (p *Peers) Certificates(addr string) []*x509.Certificate {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.chain[addr]
+}
+
+type peerListener struct {
+	net.Listener
+	peers *Peers
+}
+
+func (l *peerListener) Accept() (net.Conn, error) {
+	conn, err := l.Listener.Accept()
+	if err != nil {
+		return nil, err
+	}
+	tlsConn, ok := conn.(*tls.Conn)
+	if !ok {
+		return conn, nil
+	}
+	return &peerConn{Conn: tlsConn, peers: l.peers, addr: conn.RemoteAddr().String()}, nil
+}
+
+// peerConn records its peer's certificates after the first read, which
+// completes the handshake before any request is parsed
+type peerConn struct {
+	*tls.Conn
+	peers    *Peers
+	addr     string
+	recorded sync.Once
+}
+
+func (c *peerConn) Read(b []byte) (int, error) {
+	n, err := c.Conn.Read(b)
+	c.recorded.Do(func() {
+		if chain := c.ConnectionState().PeerCertificates; len(chain) > 0 {
+			c.peers.mu.Lock()
+			c.peers.chain[c.addr] = chain
+			c.peers.mu.Unlock()
+		}
+	})
+	return n, err
+}
+
+func (c *peerConn) Close() error {
+	c.peers.mu.Lock()
+	delete(c.peers.chain, c.addr)
+	c.peers.mu.Unlock()
+	return c.Conn.Close()
+}
@@ -0,0 +1,250 @@
+// Package trunks identifies calls from trunk providers by the client
+// certificate of their SIP-TLS connection, so calls from a carrier can be
+// routed and limited by trunk.
+//
+// A trunk's certificate is accepted if it is pinned (its SHA-256
+// fingerprint is listed), or if it chains to the trunk's CA bundle (the
+// system roots if none) and names one of the trunk's hosts. With both
+// pins and names, both must hold. INVITEs from an identified trunk carry
+// the X-Switchboard-Trunk annotation, which dialplan routes can match on
+// (Route.Trunks), and are refused with 503 over the trunk's channel or
+// call rate limit. A trunk may hide the signaling topology differently
+// from other peers (see topology.Policy), have its own map of the
+// failure codes its calls are told (see responsemap), and its own codecs
+// (see codecs.Policy).
+package trunks
+
+import (
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/codecs"
+	"github.com/sebas/switchboard/internal/signaling/responsemap"
+	"github.com/sebas/switchboard/internal/signaling/topology"
+)
+
+// Annotation is the annotation naming the trunk a call came from (see
+// middleware.Annotation)
+const Annotation = "Trunk"
+
+// Config is the trunk file
+type Config struct {
+	Trunks []Trunk `json:"trunks"`
+}
+
+// Trunk is a trunk provider sending calls to this server
+type Trunk struct {
+	Name string      `json:"name"`
+	TLS  TLSIdentity `json:"tls"`
+
+	// MaxChannels limits the calls in progress from the trunk (0 = unlimited)
+	MaxChannels int `json:"max_channels,omitempty"`
+
+	// MaxCPS limits new calls per second from the trunk (0 = unlimited)
+	MaxCPS int `json:"max_cps,omitempty"`
+
+	// Hosts are the trunk's servers (host names, IPs or CIDRs), so that
+	// requests sent to them get the trunk's topology hiding and codecs
+	Hosts []string `json:"hosts,omitempty"`
+
+	// Topology overrides the server's topology hiding for the trunk
+	Topology *topology.Policy `json:"topology,omitempty"`
+
+	// ResponseMap maps the failure codes of dials for the trunk's calls to
+	// what the trunk is told
+	ResponseMap responsemap.Map `json:"response_map,omitempty"`
+
+	// Codecs are the codecs of the trunk's calls, most preferred first;
+	// empty uses the tenant's or the server's
+	Codecs codecs.Policy `json:"codecs,omitempty"`
+
+	roots        *x509.CertPool
+	fingerprints map[string]bool
+}
+
+// TLSIdentity is how a trunk's client certificate is recognized
+type TLSIdentity struct {
+	// Fingerprints pins certificates by SHA-256, as hex with or without
+	// colons ("sha256:" prefix optional)
+	Fingerprints []string `json:"fingerprints,omitempty"`
+
+	// Names are host names the certificate must be issued for, in a DNS
+	// subject alternative name or the common name
+	Names []string `json:"names,omitempty"`
+
+	// CA is a PEM bundle the certificate must chain to when Names is set;
+	// empty uses the system roots
+	CA string `json:"ca,omitempty"`
+}
+
+// compile validates the trunk and loads its CA bundle
+func (t *Trunk) compile() error {
+	if t.Name == "" {
+		return errors.New("trunk name required")
+	}
+	if len(t.TLS.Fingerprints) == 0 && len(t.TLS.Names) == 0 {
+		return fmt.Errorf("trunk %s: tls needs fingerprints or names", t.Name)
+	}
+	if t.MaxChannels < 0 || t.MaxCPS < 0 {
+		return fmt.Errorf("trunk %s: limits must not be negative", t.Name)
+	}
+	if err := t.ResponseMap.Validate(); err != nil {
+		return fmt.Errorf("trunk %s: %w", t.Name, err)
+	}
+	t.fingerprints = make(map[string]bool, len(t.TLS.Fingerprints))
+	for _, fp := range t.TLS.Fingerprints {
+		norm := normalizeFingerprint(fp)
+		if len(norm) != sha256.Size*2 {
+			return fmt.Errorf("trunk %s: fingerprint %q is not a SHA-256", t.Name, fp)
+		}
+		t.fingerprints[norm] = true
+	}
+	if t.TLS.CA != "" {
+		pem, err := os.ReadFile(t.TLS.CA)
+		if err != nil {
+			return fmt.Errorf("trunk %s: %w", t.Name, err)
+		}
+		t.roots = x509.NewCertPool()
+		if !t.roots.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("trunk %s: no certificate in %s", t.Name, t.TLS.CA)
+		}
+	}
+	return nil
+}
+
+// Matches reports whether a peer's certificate chain, leaf first,
+// identifies the trunk
+func (t *Trunk) Matches(chain []*x509.Certificate) bool {
+	if len(chain) == 0 {
+		return false
+	}
+	leaf := chain[0]
+	if len(t.fingerprints) > 0 && !t.fingerprints[Fingerprint(leaf)] {
+		return false
+	}
+	if len(t.TLS.Names) == 0 {
+		return true
+	}
+
+	// Carriers often present their server certificate as client
+	// certificate, so any extended key usage is accepted
+	opts := x509.VerifyOptions{
+		Roots:         t.roots,
+		Intermediates: x509.NewCertPool(),
+		KeyUsages:     []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	}
+	for _, cert := range chain[1:] {
+		opts.Intermediates.AddCert(cert)
+	}
+	if _, err := leaf.Verify(opts); err != nil {
+		return false
+	}
+	for _, name := range t.TLS.Names {
+		if leaf.VerifyHostname(name) == nil || strings.EqualFold(leaf.Subject.CommonName, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// Fingerprint returns the SHA-256 of a certificate as lowercase hex
+func Fingerprint(cert *x509.Certificate) string {
+	sum := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(sum[:])
+}
+
+func normalizeFingerprint(fp string) string {
+	fp = strings.TrimPrefix(strings.ToLower(strings.TrimSpace(fp)), "sha256:")
+	return strings.ReplaceAll(fp, ":", "")
+}
+
+// Registry holds the trunks and their call rates. Safe for concurrent use.
+type Registry struct {
+	trunks []*Trunk
+
+	mu    sync.Mutex
+	rates map[string]*rate
+}
+
+// rate counts the calls of the current second
+type rate struct {
+	second int64
+	calls  int
+}
+
+// Load reads the trunk file
+func Load(path string) (*Registry, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var cfg Config
+	if err := json.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("parse %s: %w", path, err)
+	}
+	return New(cfg.Trunks)
+}
+
+// New returns a registry of trunks
+func New(trunks []Trunk) (*Registry, error) {
+	r := &Registry{rates: make(map[string]*rate)}
+	seen := make(map[string]bool)
+	for i := range trunks {
+		t := trunks[i]
+		if err := t.compile(); err != nil {
+			return nil, err
+		}
+		if seen[t.Name] {
+			return nil, fmt.Errorf("trunk %s defined twice", t.Name)
+		}
+		seen[t.Name] = true
+		r.trunks = append(r.trunks, &t)
+	}
+	return r, nil
+}
+
+// Trunks returns the trunks in file order
+func (r *Registry) Trunks() []*Trunk {
+	return r.trunks
+}
+
+// Identify returns the first trunk a peer's certificate chain identifies
+func (r *Registry) Identify(chain []*x509.Certificate) (*Trunk, bool) {
+	for _, t := range r.trunks {
+		if t.Matches(chain) {
+			return t, true
+		}
+	}
+	return nil, false
+}
+
+// allowCall counts a new call against the trunk's calls per second and
+// reports whether it is within the limit
+func (r *Registry) allowCall(t *Trunk, now time.Time) bool {
+	if t.MaxCPS == 0 {
+		return true
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	rt := r.rates[t.Name]
+	if rt == nil {
+		rt = &rate{}
+		r.rates[t.Name] = rt
+	}
+	if sec := now.Unix(); rt.second != sec {
+		rt.second, rt.calls = sec, 0
+	}
+	if rt.calls >= t.MaxCPS {
+		return false
+	}
+	rt.calls++
+	return true
+}
@@ -0,0 +1,99 @@
+package tts
+
+import (
+	"container/list"
+	"sync"
+)
+
+// CacheStats holds prompt cache statistics.
+type CacheStats struct {
+	Entries int
+	Bytes   int64
+	Hits    int64
+	Misses  int64
+}
+
+// Cache is an LRU cache of rendered prompts bounded by total PCM size.
+type Cache struct {
+	mu       sync.Mutex
+	maxBytes int64
+	bytes    int64
+	order    *list.List               // front = most recently used
+	entries  map[string]*list.Element // key -> element holding *cacheEntry
+	hits     int64
+	misses   int64
+}
+
+// cacheEntry is a single cached prompt.
+type cacheEntry struct {
+	key   string
+	audio *Audio
+}
+
+// NewCache creates a cache holding up to maxBytes of audio.
+// A cache with maxBytes <= 0 stores nothing.
+func NewCache(maxBytes int64) *Cache {
+	return &Cache{
+		maxBytes: maxBytes,
+		order:    list.New(),
+		entries:  make(map[string]*list.Element),
+	}
+}
+
+// Get returns a cached prompt and marks it recently used.
+func (c *Cache) Get(key string) (*Audio, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	elem, ok := c.entries[key]
+	if !ok {
+		c.misses++
+		return nil, false
+	}
+	c.hits++
+	c.order.MoveToFront(elem)
+	return elem.Value.(*cacheEntry).audio, true
+}
+
+// Put stores a prompt, evicting least recently used prompts to stay within budget.
+// Prompts larger than the whole budget are not cached.
+func (c *Cache) Put(key string, audio *Audio) {
+	size := int64(len(audio.PCM))
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if size > c.maxBytes {
+		return
+	}
+
+	if elem, ok := c.entries[key]; ok {
+		old := elem.Value.(*cacheEntry)
+		c.bytes += size - int64(len(old.audio.PCM))
+		old.audio = audio
+		c.order.MoveToFront(elem)
+	} else {
+		c.entries[key] = c.order.PushFront(&cacheEntry{key: key, audio: audio})
+		c.bytes += size
+	}
+
+	for c.bytes > c.maxBytes {
+		oldest := c.order.Back()
+		entry := oldest.Value.(*cacheEntry)
+		c.order.Remove(oldest)
+		delete(c.entries, entry.key)
+		c.bytes -= int64(len(entry.audio.PCM))
+	}
+}
+
+// Stats returns a snapshot of cache statistics.
+func (c *Cache) Stats() CacheStats {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return CacheStats{
+		Entries: len(c.entries),
+		Bytes:   c.bytes,
+		Hits:    c.hits,
+		Misses:  c.misses,
+	}
+}
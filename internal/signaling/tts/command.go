@@ -0,0 +1,66 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// CommandProvider synthesizes speech by running a local command.
+//
+// Text is written to the command's stdin and a WAV file is read from its
+// stdout, e.g. "espeak-ng --stdin --stdout -v {voice}".
+type CommandProvider struct {
+	args    []string
+	timeout time.Duration
+}
+
+// NewCommandProvider creates a provider for the given command line.
+func NewCommandProvider(command string, timeout time.Duration) (*CommandProvider, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, fmt.Errorf("tts command provider: command required")
+	}
+	return &CommandProvider{args: args, timeout: timeout}, nil
+}
+
+// Name returns "command".
+func (p *CommandProvider) Name() string {
+	return "command"
+}
+
+// Synthesize implements Provider.Synthesize.
+func (p *CommandProvider) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	args := make([]string, 0, len(p.args))
+	for _, arg := range p.args {
+		if strings.Contains(arg, "{voice}") {
+			if req.Voice == "" {
+				// Drop a bare placeholder so the command uses its default voice
+				if arg == "{voice}" && len(args) > 0 && strings.HasPrefix(args[len(args)-1], "-") {
+					args = args[:len(args)-1]
+				}
+				continue
+			}
+			arg = strings.ReplaceAll(arg, "{voice}", req.Voice)
+		}
+		args = append(args, arg)
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, args[0], args[1:]...)
+	cmd.Stdin = strings.NewReader(req.Text)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%s: %w: %s", args[0], err, strings.TrimSpace(stderr.String()))
+	}
+
+	return decodeWAV(stdout.Bytes())
+}
@@ -0,0 +1,98 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxResponseBytes bounds the audio accepted from a synthesis service (~5 min at 16 kHz)
+const maxResponseBytes = 10 << 20
+
+// HTTPProvider synthesizes speech via an HTTP service.
+//
+// The provider POSTs {"text", "voice", "sample_rate"} as JSON and accepts
+// either a WAV body (audio/wav) or raw PCM (audio/L16;rate=N).
+type HTTPProvider struct {
+	url    string
+	client *http.Client
+}
+
+// httpSynthesisRequest is the JSON body sent to the synthesis service.
+type httpSynthesisRequest struct {
+	Text       string `json:"text"`
+	Voice      string `json:"voice,omitempty"`
+	SampleRate int    `json:"sample_rate"`
+}
+
+// NewHTTPProvider creates a provider for the given synthesis endpoint.
+func NewHTTPProvider(url string, timeout time.Duration) (*HTTPProvider, error) {
+	if url == "" {
+		return nil, fmt.Errorf("tts http provider: URL required")
+	}
+	return &HTTPProvider{
+		url:    url,
+		client: &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Name returns "http".
+func (p *HTTPProvider) Name() string {
+	return "http"
+}
+
+// Synthesize implements Provider.Synthesize.
+func (p *HTTPProvider) Synthesize(ctx context.Context, req Request) (*Audio, error) {
+	body, err := json.Marshal(httpSynthesisRequest{
+		Text:       req.Text,
+		Voice:      req.Voice,
+		SampleRate: SampleRate,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Accept", "audio/wav, audio/L16")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, maxResponseBytes))
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("synthesis service returned %s", resp.Status)
+	}
+
+	mediaType, params, _ := mime.ParseMediaType(resp.Header.Get("Content-Type"))
+	if strings.EqualFold(mediaType, "audio/L16") {
+		// RFC 2586 L16 is big-endian; convert to little-endian
+		rate, err := strconv.Atoi(params["rate"])
+		if err != nil || rate <= 0 {
+			rate = SampleRate
+		}
+		pcm := make([]byte, len(data)&^1)
+		for i := 0; i+1 < len(data); i += 2 {
+			pcm[i], pcm[i+1] = data[i+1], data[i]
+		}
+		return &Audio{SampleRate: rate, PCM: pcm}, nil
+	}
+
+	return decodeWAV(data)
+}
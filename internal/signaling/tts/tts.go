@@ -0,0 +1,155 @@
+// Package tts provides text-to-speech synthesis for dialplan prompts.
+//
+// A Provider renders text to audio (via an HTTP service or a local command
+// such as espeak-ng). The Engine normalizes provider output to 8 kHz mono
+// PCM and caches rendered prompts so repeated phrases are synthesized once.
+package tts
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// SampleRate is the rate of audio returned by the Engine (narrowband telephony)
+const SampleRate = 8000
+
+// Sentinel errors
+var (
+	ErrEmptyText       = errors.New("tts: empty text")
+	ErrUnknownProvider = errors.New("tts: unknown provider")
+)
+
+// Request describes text to synthesize.
+type Request struct {
+	Text  string
+	Voice string // Provider-specific voice name; empty for provider default
+}
+
+// Audio is synthesized speech as 16-bit little-endian mono PCM.
+type Audio struct {
+	SampleRate int
+	PCM        []byte
+}
+
+// Duration returns the playback length of the audio.
+func (a *Audio) Duration() time.Duration {
+	if a.SampleRate == 0 {
+		return 0
+	}
+	samples := len(a.PCM) / 2
+	return time.Duration(samples) * time.Second / time.Duration(a.SampleRate)
+}
+
+// Provider renders text to audio.
+// Implementations: HTTPProvider, CommandProvider
+type Provider interface {
+	// Name identifies the provider (used in cache keys and logs)
+	Name() string
+
+	// Synthesize renders the request to PCM audio at any sample rate
+	Synthesize(ctx context.Context, req Request) (*Audio, error)
+}
+
+// Config selects and configures a provider.
+type Config struct {
+	// Provider is "http" or "command"
+	Provider string
+
+	// URL is the synthesis endpoint for the http provider
+	URL string
+
+	// Command is the command line for the command provider.
+	// Text is written to stdin; WAV is read from stdout.
+	// "{voice}" in an argument is replaced with the requested voice.
+	Command string
+
+	// Timeout bounds a single synthesis.
+	// Default: 10s
+	Timeout time.Duration
+}
+
+// NewProvider creates the provider named in cfg.
+func NewProvider(cfg Config) (Provider, error) {
+	if cfg.Timeout <= 0 {
+		cfg.Timeout = 10 * time.Second
+	}
+
+	switch cfg.Provider {
+	case "http":
+		return NewHTTPProvider(cfg.URL, cfg.Timeout)
+	case "command":
+		return NewCommandProvider(cfg.Command, cfg.Timeout)
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnknownProvider, cfg.Provider)
+	}
+}
+
+// Engine synthesizes speech through a provider with a prompt cache.
+type Engine struct {
+	provider     Provider
+	cache        *Cache
+	defaultVoice string
+}
+
+// NewEngine creates an engine. cacheBytes bounds the prompt cache (0 disables caching).
+func NewEngine(provider Provider, cacheBytes int64, defaultVoice string) *Engine {
+	return &Engine{
+		provider:     provider,
+		cache:        NewCache(cacheBytes),
+		defaultVoice: defaultVoice,
+	}
+}
+
+// Synthesize returns 8 kHz mono PCM for text, rendering it on a cache miss.
+func (e *Engine) Synthesize(ctx context.Context, text, voice string) (*Audio, error) {
+	if text == "" {
+		return nil, ErrEmptyText
+	}
+	if voice == "" {
+		voice = e.defaultVoice
+	}
+
+	key := cacheKey(e.provider.Name(), voice, text)
+	if audio, ok := e.cache.Get(key); ok {
+		slog.Debug("[TTS] Cache hit", "provider", e.provider.Name(), "voice", voice, "chars", len(text))
+		return audio, nil
+	}
+
+	start := time.Now()
+	audio, err := e.provider.Synthesize(ctx, Request{Text: text, Voice: voice})
+	if err != nil {
+		return nil, fmt.Errorf("tts %s: %w", e.provider.Name(), err)
+	}
+
+	audio = &Audio{
+		SampleRate: SampleRate,
+		PCM:        resample(audio.PCM, audio.SampleRate, SampleRate),
+	}
+	e.cache.Put(key, audio)
+
+	slog.Info("[TTS] Synthesized prompt",
+		"provider", e.provider.Name(),
+		"voice", voice,
+		"chars", len(text),
+		"duration", audio.Duration(),
+		"elapsed", time.Since(start),
+	)
+
+	return audio, nil
+}
+
+// CacheStats returns the prompt cache statistics.
+func (e *Engine) CacheStats() CacheStats {
+	return e.cache.Stats()
+}
+
+// cacheKey identifies a rendered prompt.
+func cacheKey(provider, voice, text string) string {
+	sum := sha256.Sum256([]byte(provider + "\x00" + voice + "\x00" + text))
+	return hex.EncodeToString(sum[:])
+}
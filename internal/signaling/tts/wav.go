@@ -0,0 +1,101 @@
+package tts
+
+import (
+	"encoding/binary"
+	"fmt"
+)
+
+// decodeWAV extracts 16-bit PCM from a WAV file, downmixing to mono.
+// Streaming writers (e.g. espeak-ng --stdout) may leave the data chunk size
+// unset, so a size of 0 or one overrunning the buffer means "to end of file".
+func decodeWAV(data []byte) (*Audio, error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, fmt.Errorf("not a WAV file")
+	}
+
+	var (
+		channels   int
+		sampleRate int
+		haveFmt    bool
+	)
+
+	pos := 12
+	for pos+8 <= len(data) {
+		chunkID := string(data[pos : pos+4])
+		chunkSize := int(binary.LittleEndian.Uint32(data[pos+4 : pos+8]))
+		body := pos + 8
+
+		switch chunkID {
+		case "fmt ":
+			if chunkSize < 16 || body+16 > len(data) {
+				return nil, fmt.Errorf("truncated fmt chunk")
+			}
+			format := binary.LittleEndian.Uint16(data[body:])
+			channels = int(binary.LittleEndian.Uint16(data[body+2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(data[body+4:]))
+			bits := binary.LittleEndian.Uint16(data[body+14:])
+			if format != 1 || bits != 16 {
+				return nil, fmt.Errorf("only 16-bit PCM WAV is supported (format %d, %d bits)", format, bits)
+			}
+			if channels < 1 || channels > 2 {
+				return nil, fmt.Errorf("unsupported number of channels: %d", channels)
+			}
+			haveFmt = true
+
+		case "data":
+			if !haveFmt {
+				return nil, fmt.Errorf("data chunk before fmt chunk")
+			}
+			end := body + chunkSize
+			if chunkSize == 0 || end > len(data) {
+				end = len(data)
+			}
+			pcm := data[body:end]
+			if channels == 2 {
+				pcm = downmix(pcm)
+			}
+			return &Audio{SampleRate: sampleRate, PCM: pcm[:len(pcm)&^1]}, nil
+		}
+
+		pos = body + chunkSize + chunkSize&1 // chunks are word aligned
+	}
+
+	return nil, fmt.Errorf("data chunk not found in WAV file")
+}
+
+// downmix averages interleaved stereo 16-bit samples to mono.
+func downmix(stereo []byte) []byte {
+	mono := make([]byte, len(stereo)/4*2)
+	for i := 0; i+4 <= len(stereo); i += 4 {
+		left := int32(int16(binary.LittleEndian.Uint16(stereo[i:])))
+		right := int32(int16(binary.LittleEndian.Uint16(stereo[i+2:])))
+		binary.LittleEndian.PutUint16(mono[i/2:], uint16(int16((left+right)/2)))
+	}
+	return mono
+}
+
+// resample converts 16-bit mono PCM between sample rates using linear interpolation.
+func resample(pcm []byte, from, to int) []byte {
+	if from == to || from <= 0 || len(pcm) < 4 {
+		return pcm
+	}
+
+	inSamples := len(pcm) / 2
+	outSamples := int(int64(inSamples) * int64(to) / int64(from))
+	out := make([]byte, outSamples*2)
+	ratio := float64(from) / float64(to)
+
+	for i := 0; i < outSamples; i++ {
+		srcPos := float64(i) * ratio
+		idx := int(srcPos)
+		frac := srcPos - float64(idx)
+
+		s1 := float64(int16(binary.LittleEndian.Uint16(pcm[idx*2:])))
+		s2 := s1
+		if idx+1 < inSamples {
+			s2 = float64(int16(binary.LittleEndian.Uint16(pcm[(idx+1)*2:])))
+		}
+		binary.LittleEndian.PutUint16(out[i*2:], uint16(int16(s1*(1-frac)+s2*frac)))
+	}
+	return out
+}
@@ -0,0 +1,187 @@
+// Package statsd pushes metrics to a StatsD or DogStatsD agent over UDP.
+//
+// Metrics are buffered and sent in batches of newline-separated lines that
+// fit a single datagram, either when the buffer is full or on each flush
+// interval. Sends never block the caller; if the agent is down, metrics
+// are lost.
+package statsd
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Protocol flavors
+const (
+	FlavorStatsD    = "statsd"    // Plain StatsD; tags are dropped
+	FlavorDogStatsD = "dogstatsd" // Datadog extension with |#tags
+)
+
+// maxPacket keeps datagrams below a typical Ethernet MTU.
+const maxPacket = 1432
+
+// Config configures a Client.
+type Config struct {
+	Addr          string        // Agent address (host:port)
+	Flavor        string        // FlavorStatsD or FlavorDogStatsD
+	Prefix        string        // Prepended to every metric name, e.g. "switchboard."
+	Tags          []string      // Added to every metric (DogStatsD only), e.g. "env:prod"
+	FlushInterval time.Duration // Default 1s
+}
+
+// Client buffers metrics and writes them to the agent. Safe for
+// concurrent use.
+type Client struct {
+	conn   net.Conn
+	prefix string
+	tags   string // Global tags joined with commas; empty for plain StatsD
+	dog    bool
+
+	mu  sync.Mutex
+	buf []byte
+
+	stop chan struct{}
+	done chan struct{}
+}
+
+// New creates a client and starts its flush loop. Close stops it.
+func New(cfg Config) (*Client, error) {
+	switch cfg.Flavor {
+	case FlavorStatsD, FlavorDogStatsD:
+	default:
+		return nil, fmt.Errorf("statsd: unknown flavor %q", cfg.Flavor)
+	}
+	conn, err := net.Dial("udp", cfg.Addr)
+	if err != nil {
+		return nil, fmt.Errorf("statsd: %w", err)
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = time.Second
+	}
+
+	c := &Client{
+		conn:   conn,
+		prefix: cfg.Prefix,
+		dog:    cfg.Flavor == FlavorDogStatsD,
+		buf:    make([]byte, 0, maxPacket),
+		stop:   make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+	if c.dog && len(cfg.Tags) > 0 {
+		c.tags = strings.Join(cfg.Tags, ",")
+	}
+	go c.flushLoop(cfg.FlushInterval)
+	return c, nil
+}
+
+// ParseTags splits a comma-separated tag list, dropping empty entries.
+func ParseTags(spec string) []string {
+	var tags []string
+	for _, t := range strings.Split(spec, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			tags = append(tags, t)
+		}
+	}
+	return tags
+}
+
+// Tagged reports whether tags are sent. Per-instance metrics should be
+// skipped otherwise, as they would overwrite each other.
+func (c *Client) Tagged() bool {
+	return c.dog
+}
+
+// Count adds n to a counter.
+func (c *Client) Count(name string, n int64, tags ...string) {
+	c.write(name, strconv.AppendInt(nil, n, 10), "c", tags)
+}
+
+// Incr adds one to a counter.
+func (c *Client) Incr(name string, tags ...string) {
+	c.Count(name, 1, tags...)
+}
+
+// Gauge sets a gauge.
+func (c *Client) Gauge(name string, value float64, tags ...string) {
+	c.write(name, strconv.AppendFloat(nil, value, 'f', -1, 64), "g", tags)
+}
+
+// Timing records a duration in milliseconds.
+func (c *Client) Timing(name string, d time.Duration, tags ...string) {
+	c.write(name, strconv.AppendFloat(nil, float64(d)/float64(time.Millisecond), 'f', -1, 64), "ms", tags)
+}
+
+// write appends one metric line, flushing first if it would not fit.
+func (c *Client) write(name string, value []byte, kind string, tags []string) {
+	line := make([]byte, 0, 64)
+	line = append(line, c.prefix...)
+	line = append(line, name...)
+	line = append(line, ':')
+	line = append(line, value...)
+	line = append(line, '|')
+	line = append(line, kind...)
+	if c.dog && (c.tags != "" || len(tags) > 0) {
+		line = append(line, "|#"...)
+		line = append(line, c.tags...)
+		for i, t := range tags {
+			if i > 0 || c.tags != "" {
+				line = append(line, ',')
+			}
+			line = append(line, t...)
+		}
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.buf) > 0 && len(c.buf)+1+len(line) > maxPacket {
+		c.flushLocked()
+	}
+	if len(c.buf) > 0 {
+		c.buf = append(c.buf, '\n')
+	}
+	c.buf = append(c.buf, line...)
+}
+
+// Flush sends buffered metrics.
+func (c *Client) Flush() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.flushLocked()
+}
+
+func (c *Client) flushLocked() {
+	if len(c.buf) == 0 {
+		return
+	}
+	if _, err := c.conn.Write(c.buf); err != nil {
+		slog.Debug("[StatsD] Send failed", "error", err)
+	}
+	c.buf = c.buf[:0]
+}
+
+func (c *Client) flushLoop(interval time.Duration) {
+	defer close(c.done)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.stop:
+			return
+		case <-ticker.C:
+			c.Flush()
+		}
+	}
+}
+
+// Close flushes remaining metrics and closes the socket.
+func (c *Client) Close() error {
+	close(c.stop)
+	<-c.done
+	c.Flush()
+	return c.conn.Close()
+}
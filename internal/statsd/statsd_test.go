@@ -0,0 +1,101 @@
+package statsd
+
+import (
+	"net"
+	"strings"
+	"testing"
+	"time"
+)
+
+func listen(t *testing.T) *net.UDPConn {
+	t.Helper()
+	conn, err := net.ListenUDP("udp", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+	return conn
+}
+
+func receive(t *testing.T, conn *net.UDPConn) string {
+	t.Helper()
+	buf := make([]byte, 65536)
+	_ = conn.SetReadDeadline(time.Now().Add(2 * time.Second))
+	n, err := conn.Read(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(buf[:n])
+}
+
+func TestClientFormats(t *testing.T) {
+	tests := []struct {
+		flavor string
+		want   []string
+	}{
+		{FlavorStatsD, []string{
+			"sb.calls:1|c",
+			"sb.active:2.5|g",
+			"sb.setup:1500|ms",
+		}},
+		{FlavorDogStatsD, []string{
+			"sb.calls:1|c|#env:test,disposition:BUSY",
+			"sb.active:2.5|g|#env:test",
+			"sb.setup:1500|ms|#env:test",
+		}},
+	}
+	for _, tt := range tests {
+		agent := listen(t)
+		c, err := New(Config{
+			Addr:          agent.LocalAddr().String(),
+			Flavor:        tt.flavor,
+			Prefix:        "sb.",
+			Tags:          []string{"env:test"},
+			FlushInterval: time.Hour,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		c.Incr("calls", "disposition:BUSY")
+		c.Gauge("active", 2.5)
+		c.Timing("setup", 1500*time.Millisecond)
+		c.Flush()
+
+		if got := receive(t, agent); got != strings.Join(tt.want, "\n") {
+			t.Errorf("%s:\n got %q\nwant %q", tt.flavor, got, strings.Join(tt.want, "\n"))
+		}
+		_ = c.Close()
+	}
+}
+
+func TestClientSplitsPackets(t *testing.T) {
+	agent := listen(t)
+	c, err := New(Config{Addr: agent.LocalAddr().String(), Flavor: FlavorStatsD, FlushInterval: time.Hour})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	for i := 0; i < 200; i++ {
+		c.Incr("some.long.metric.name.for.splitting")
+	}
+	c.Flush()
+
+	lines := 0
+	for lines < 200 {
+		pkt := receive(t, agent)
+		if len(pkt) > maxPacket {
+			t.Fatalf("packet of %d bytes exceeds %d", len(pkt), maxPacket)
+		}
+		lines += strings.Count(pkt, "\n") + 1
+	}
+	if lines != 200 {
+		t.Errorf("received %d lines, want 200", lines)
+	}
+}
+
+func TestNewRejectsUnknownFlavor(t *testing.T) {
+	if _, err := New(Config{Addr: "127.0.0.1:8125", Flavor: "graphite"}); err == nil {
+		t.Error("expected error")
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"flag"
 	"os"
 	"strings"
+	"time"
 )
 
 // Backend represents a signaling server instance
@@ -21,8 +22,27 @@ type Config struct {
 	// Backend signaling servers
 	Backends []Backend
 
+	// Backend discovery, in addition to Backends: the SRV records of a DNS
+	// name, or the ready pods matching a Kubernetes label selector (their
+	// API on DiscoverPort). Discovered backends come and go as the
+	// discovery, repeated every DiscoverInterval, finds them.
+	DiscoverSRV          string
+	DiscoverK8sSelector  string
+	DiscoverK8sNamespace string // Namespace of the UI pod if empty
+	DiscoverPort         int
+	DiscoverInterval     time.Duration
+
 	// Log level
 	LogLevel string
+
+	// DebugToken enables the /debug/ diagnostics endpoints for requests
+	// bearing it; empty disables them. It may be a reference to a secret
+	// store (see package secrets), resolved again every SecretsRefresh.
+	DebugToken     string
+	SecretsRefresh time.Duration
+
+	// SkipPreflight starts without checking the HTTP port and backends
+	SkipPreflight bool
 }
 
 // Load loads configuration from command line flags and environment variables
@@ -33,13 +53,28 @@ func Load() *Config {
 	flag.IntVar(&cfg.Port, "port", 3000, "UI HTTP server port")
 	flag.StringVar(&cfg.BindAddr, "bind", "0.0.0.0", "UI bind address")
 	flag.StringVar(&cfg.LogLevel, "loglevel", "info", "Log level (debug, info, warn, error)")
+	flag.StringVar(&cfg.DebugToken, "debug-token", "", "Bearer token for the /debug/ diagnostics endpoints; empty disables them")
+	flag.DurationVar(&cfg.SecretsRefresh, "secrets-refresh", 5*time.Minute, "How often secret store references are resolved again; 0 disables")
+	flag.BoolVar(&cfg.SkipPreflight, "skip-preflight", false, "Start without checking the HTTP port and backends first")
 
 	var backends string
 	flag.StringVar(&backends, "backends", "http://localhost:8080", "Comma-separated list of signaling server addresses (name=addr or just addr)")
+	flag.StringVar(&cfg.DiscoverSRV, "discover-srv", "", "Discover backends from the SRV records of this name, e.g. _switchboard-api._tcp.example.com")
+	flag.StringVar(&cfg.DiscoverK8sSelector, "discover-k8s-selector", "", "Discover backends from the ready pods matching this Kubernetes label selector")
+	flag.StringVar(&cfg.DiscoverK8sNamespace, "discover-k8s-namespace", "", "Namespace of the discovered pods; empty uses the UI's own")
+	flag.IntVar(&cfg.DiscoverPort, "discover-port", 8080, "API port of the discovered Kubernetes pods")
+	flag.DurationVar(&cfg.DiscoverInterval, "discover-interval", 30*time.Second, "How often backends are discovered again")
 
 	flag.Parse()
 
-	// Parse backend addresses
+	// Parse backend addresses. The default backend only applies without
+	// discovery.
+	backendsSet := false
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "backends" {
+			backendsSet = true
+		}
+	})
 	cfg.Backends = parseBackends(backends)
 
 	// Override with environment variables if set
@@ -57,10 +92,48 @@ func Load() *Config {
 	if envBackends := os.Getenv("UI_BACKENDS"); envBackends != "" {
 		cfg.Backends = parseBackends(envBackends)
 	}
+	if v := os.Getenv("UI_DISCOVER_SRV"); v != "" {
+		cfg.DiscoverSRV = v
+	}
+	if v := os.Getenv("UI_DISCOVER_K8S_SELECTOR"); v != "" {
+		cfg.DiscoverK8sSelector = v
+	}
+	if v := os.Getenv("UI_DISCOVER_K8S_NAMESPACE"); v != "" {
+		cfg.DiscoverK8sNamespace = v
+	}
+	if v := os.Getenv("UI_DISCOVER_PORT"); v != "" {
+		if p := stringToInt(v); p > 0 {
+			cfg.DiscoverPort = p
+		}
+	}
+	if v := os.Getenv("UI_DISCOVER_INTERVAL"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil && d > 0 {
+			cfg.DiscoverInterval = d
+		}
+	}
+	if cfg.Discovery() && !backendsSet && os.Getenv("UI_BACKENDS") == "" {
+		cfg.Backends = nil
+	}
+	if token := os.Getenv("UI_DEBUG_TOKEN"); token != "" {
+		cfg.DebugToken = token
+	}
+	if v := os.Getenv("UI_SECRETS_REFRESH"); v != "" {
+		if d, err := time.ParseDuration(v); err == nil {
+			cfg.SecretsRefresh = d
+		}
+	}
+	if skip := os.Getenv("UI_SKIP_PREFLIGHT"); skip == "true" || skip == "1" {
+		cfg.SkipPreflight = true
+	}
 
 	return cfg
 }
 
+// Discovery reports whether backends are discovered
+func (c *Config) Discovery() bool {
+	return c.DiscoverSRV != "" || c.DiscoverK8sSelector != ""
+}
+
 // parseBackends parses a comma-separated list of backend addresses
 // Format: "name=http://host:port" or "http://host:port" (name auto-generated)
 func parseBackends(s string) []Backend {
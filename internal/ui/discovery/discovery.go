@@ -0,0 +1,84 @@
+// Package discovery finds the signaling backends of the UI server in DNS or
+// Kubernetes, so backends come and go without restarting the UI.
+package discovery
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/ui/config"
+)
+
+// Discoverer lists the signaling backends currently available
+type Discoverer interface {
+	Discover(ctx context.Context) ([]config.Backend, error)
+}
+
+// New returns the discoverer configured in cfg, or nil if backends are
+// not discovered
+func New(cfg *config.Config) (Discoverer, error) {
+	switch {
+	case cfg.DiscoverSRV != "" && cfg.DiscoverK8sSelector != "":
+		return nil, errors.New("discover backends from DNS SRV or Kubernetes, not both")
+	case cfg.DiscoverSRV != "":
+		return &SRV{Name: cfg.DiscoverSRV}, nil
+	case cfg.DiscoverK8sSelector != "":
+		return NewKubernetes(cfg.DiscoverK8sNamespace, cfg.DiscoverK8sSelector, cfg.DiscoverPort)
+	}
+	return nil, nil
+}
+
+// Watch discovers backends now and then every interval until ctx is done,
+// calling fn whenever they changed. A failed discovery keeps the backends
+// found last, so a DNS or API server outage does not empty the dashboard.
+func Watch(ctx context.Context, d Discoverer, interval time.Duration, fn func([]config.Backend)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	var last []config.Backend
+	first := true
+	for {
+		backends, err := d.Discover(ctx)
+		switch {
+		case err != nil:
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Warn("[Discovery] Backend discovery failed, keeping the last backends found", "error", err)
+		case first || !slices.Equal(backends, last):
+			slog.Info("[Discovery] Backends discovered", "count", len(backends))
+			fn(backends)
+			last, first = backends, false
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// backendNames names backends by the first label of their host
+// ("signaling-0" for "signaling-0.signaling.switchboard.svc"), or by the
+// full host when first labels collide
+func backendNames(hosts []string) []string {
+	seen := make(map[string]int)
+	for _, h := range hosts {
+		label, _, _ := strings.Cut(h, ".")
+		seen[label]++
+	}
+	names := make([]string, len(hosts))
+	for i, h := range hosts {
+		label, _, _ := strings.Cut(h, ".")
+		names[i] = label
+		if seen[label] > 1 {
+			names[i] = h
+		}
+	}
+	return names
+}
@@ -0,0 +1,106 @@
+package discovery
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/sebas/switchboard/internal/ui/config"
+)
+
+const pods = `{"items": [
+	{"metadata": {"name": "signaling-1"}, "status": {"phase": "Running", "podIP": "10.0.0.2", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "signaling-0"}, "status": {"phase": "Running", "podIP": "10.0.0.1", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "signaling-2"}, "status": {"phase": "Running", "podIP": "10.0.0.3", "conditions": [{"type": "Ready", "status": "False"}]}},
+	{"metadata": {"name": "signaling-3", "deletionTimestamp": "2026-01-15T10:00:00Z"}, "status": {"phase": "Running", "podIP": "10.0.0.4", "conditions": [{"type": "Ready", "status": "True"}]}},
+	{"metadata": {"name": "signaling-4"}, "status": {"phase": "Pending"}}
+]}`
+
+func TestKubernetesDiscover(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v1/namespaces/voice/pods" || r.URL.Query().Get("labelSelector") != "app=signaling" {
+			http.NotFound(w, r)
+			return
+		}
+		if r.Header.Get("Authorization") != "Bearer t0ken" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(pods))
+	}))
+	defer srv.Close()
+
+	token := filepath.Join(t.TempDir(), "token")
+	if err := os.WriteFile(token, []byte("t0ken\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	k := &Kubernetes{APIServer: srv.URL, Namespace: "voice", Selector: "app=signaling", Port: 8080, TokenFile: token, Client: srv.Client()}
+
+	backends, err := k.Discover(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []config.Backend{
+		{Name: "signaling-0", Address: "http://10.0.0.1:8080"},
+		{Name: "signaling-1", Address: "http://10.0.0.2:8080"},
+	}
+	if !reflect.DeepEqual(backends, want) {
+		t.Errorf("backends = %+v, want %+v", backends, want)
+	}
+}
+
+// fakeDiscoverer returns its results in turn, then the last one forever
+type fakeDiscoverer struct {
+	results [][]config.Backend
+	errs    []error
+	calls   int
+}
+
+func (f *fakeDiscoverer) Discover(ctx context.Context) ([]config.Backend, error) {
+	i := min(f.calls, len(f.results)-1)
+	f.calls++
+	return f.results[i], f.errs[i]
+}
+
+func TestWatchReportsChangesOnly(t *testing.T) {
+	a := []config.Backend{{Name: "a", Address: "http://a:8080"}}
+	ab := append(a, config.Backend{Name: "b", Address: "http://b:8080"})
+	d := &fakeDiscoverer{
+		results: [][]config.Backend{a, a, nil, ab},
+		errs:    []error{nil, nil, errors.New("dns down"), nil},
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var got [][]config.Backend
+	go Watch(ctx, d, time.Millisecond, func(b []config.Backend) {
+		got = append(got, b)
+		if len(got) == 2 {
+			cancel()
+		}
+	})
+	select {
+	case <-ctx.Done():
+	case <-time.After(2 * time.Second):
+		cancel()
+		t.Fatal("backends never changed")
+	}
+
+	// Unchanged and failed discoveries are not reported
+	if !reflect.DeepEqual(got, [][]config.Backend{a, ab}) {
+		t.Errorf("reported %+v", got)
+	}
+}
+
+func TestBackendNames(t *testing.T) {
+	got := backendNames([]string{"sig-0.sig.voice.svc.cluster.local", "sip1.dc1.example.com", "sip1.dc2.example.com"})
+	want := []string{"sig-0", "sip1.dc1.example.com", "sip1.dc2.example.com"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("names = %v, want %v", got, want)
+	}
+}
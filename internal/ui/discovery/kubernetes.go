@@ -0,0 +1,147 @@
+package discovery
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/sebas/switchboard/internal/ui/config"
+)
+
+// In-cluster service account files
+const (
+	serviceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+	tokenFile         = serviceAccountDir + "/token"
+	caFile            = serviceAccountDir + "/ca.crt"
+	namespaceFile     = serviceAccountDir + "/namespace"
+)
+
+// Kubernetes discovers backends from the ready pods matching a label
+// selector, through the API server the UI pod runs under. The service
+// account needs to get and list pods in the namespace.
+type Kubernetes struct {
+	APIServer string // e.g. https://10.96.0.1:443
+	Namespace string
+	Selector  string
+	Port      int          // API port of the signaling pods
+	TokenFile string       // Read on every request, as tokens are rotated
+	Client    *http.Client // Trusting the cluster CA
+}
+
+// NewKubernetes returns a discoverer for the cluster the UI runs in. An
+// empty namespace is the UI pod's own.
+func NewKubernetes(namespace, selector string, port int) (*Kubernetes, error) {
+	host, apiPort := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+	if host == "" || apiPort == "" {
+		return nil, fmt.Errorf("kubernetes discovery needs to run in a pod (KUBERNETES_SERVICE_HOST is not set)")
+	}
+	if namespace == "" {
+		data, err := os.ReadFile(namespaceFile)
+		if err != nil {
+			return nil, fmt.Errorf("read pod namespace: %w", err)
+		}
+		namespace = strings.TrimSpace(string(data))
+	}
+
+	ca, err := os.ReadFile(caFile)
+	if err != nil {
+		return nil, fmt.Errorf("read cluster CA: %w", err)
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(ca) {
+		return nil, fmt.Errorf("no certificate in %s", caFile)
+	}
+
+	return &Kubernetes{
+		APIServer: "https://" + net.JoinHostPort(host, apiPort),
+		Namespace: namespace,
+		Selector:  selector,
+		Port:      port,
+		TokenFile: tokenFile,
+		Client: &http.Client{
+			Timeout:   10 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{RootCAs: pool}},
+		},
+	}, nil
+}
+
+// podList is the part of a Kubernetes PodList used for discovery
+type podList struct {
+	Items []struct {
+		Metadata struct {
+			Name              string  `json:"name"`
+			DeletionTimestamp *string `json:"deletionTimestamp"`
+		} `json:"metadata"`
+		Status struct {
+			Phase      string `json:"phase"`
+			PodIP      string `json:"podIP"`
+			Conditions []struct {
+				Type   string `json:"type"`
+				Status string `json:"status"`
+			} `json:"conditions"`
+		} `json:"status"`
+	} `json:"items"`
+}
+
+// Discover lists the pods matching the selector. Pods that are not ready,
+// or are terminating, are left out.
+func (k *Kubernetes) Discover(ctx context.Context) ([]config.Backend, error) {
+	u := fmt.Sprintf("%s/api/v1/namespaces/%s/pods?labelSelector=%s",
+		k.APIServer, url.PathEscape(k.Namespace), url.QueryEscape(k.Selector))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	if k.TokenFile != "" {
+		token, err := os.ReadFile(k.TokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("read service account token: %w", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := k.Client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("list pods: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("list pods: %s", resp.Status)
+	}
+	var pods podList
+	if err := json.NewDecoder(resp.Body).Decode(&pods); err != nil {
+		return nil, fmt.Errorf("decode pods: %w", err)
+	}
+
+	backends := make([]config.Backend, 0, len(pods.Items))
+	for _, p := range pods.Items {
+		if p.Metadata.DeletionTimestamp != nil || p.Status.Phase != "Running" || p.Status.PodIP == "" {
+			continue
+		}
+		ready := false
+		for _, c := range p.Status.Conditions {
+			if c.Type == "Ready" {
+				ready = c.Status == "True"
+			}
+		}
+		if !ready {
+			continue
+		}
+		backends = append(backends, config.Backend{
+			Name:    p.Metadata.Name,
+			Address: "http://" + net.JoinHostPort(p.Status.PodIP, strconv.Itoa(k.Port)),
+		})
+	}
+	sort.Slice(backends, func(i, j int) bool { return backends[i].Name < backends[j].Name })
+	return backends, nil
+}
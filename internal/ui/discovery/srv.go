@@ -0,0 +1,53 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/sebas/switchboard/internal/ui/config"
+)
+
+// SRV discovers backends from the SRV records of a DNS name, such as
+// "_switchboard-api._tcp.example.com" or the records of a headless
+// Kubernetes service. Every target is a backend's API at the record's port.
+type SRV struct {
+	Name     string
+	Resolver *net.Resolver // net.DefaultResolver if nil
+}
+
+// Discover looks the SRV records up
+func (s *SRV) Discover(ctx context.Context) ([]config.Backend, error) {
+	resolver := s.Resolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+	_, records, err := resolver.LookupSRV(ctx, "", "", s.Name)
+	if err != nil {
+		return nil, fmt.Errorf("lookup SRV %s: %w", s.Name, err)
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		if records[i].Target != records[j].Target {
+			return records[i].Target < records[j].Target
+		}
+		return records[i].Port < records[j].Port
+	})
+	hosts := make([]string, len(records))
+	for i, r := range records {
+		hosts[i] = strings.TrimSuffix(r.Target, ".")
+	}
+	names := backendNames(hosts)
+
+	backends := make([]config.Backend, 0, len(records))
+	for i, r := range records {
+		backends = append(backends, config.Backend{
+			Name:    names[i],
+			Address: "http://" + net.JoinHostPort(hosts[i], strconv.Itoa(int(r.Port))),
+		})
+	}
+	return backends, nil
+}
@@ -0,0 +1,129 @@
+package server
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/sebas/switchboard/pkg/client"
+)
+
+// handleConfigPartial renders the configuration of every backend side by
+// side, settings that differ between backends highlighted
+func (s *Server) handleConfigPartial(w http.ResponseWriter, r *http.Request) {
+	data := s.buildConfigView(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderConfig(w, data); err != nil {
+		slog.Error("[UI] Failed to render config partial", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// buildConfigView fetches the configuration of every backend concurrently.
+// It is only built for the configuration section, which is not refreshed
+// with the rest of the dashboard.
+func (s *Server) buildConfigView(ctx context.Context) ConfigViewData {
+	clients := s.backendClients()
+	backends := make([]ConfigBackendData, len(clients))
+	configs := make([]*types.ConfigResponse, len(clients))
+
+	var wg sync.WaitGroup
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c *client.Client) {
+			defer wg.Done()
+			backends[i].Name = c.Name()
+			cfg, err := c.Config(ctx)
+			if err != nil {
+				slog.Debug("[UI] Backend config fetch failed", "backend", c.Name(), "error", err)
+				backends[i].Error = err.Error()
+				return
+			}
+			backends[i].Version = cfg.Version
+			configs[i] = cfg
+		}(i, c)
+	}
+	wg.Wait()
+
+	settings := make([]map[string]string, len(configs))
+	files := make([]map[string]string, len(configs))
+	for i, cfg := range configs {
+		if cfg != nil {
+			settings[i], files[i] = cfg.Settings, cfg.Files
+		}
+	}
+
+	data := ConfigViewData{
+		Backends: backends,
+		Settings: diffConfig(settings),
+		Files:    diffConfig(files),
+	}
+	for _, rows := range [][]ConfigRowData{data.Settings, data.Files} {
+		for _, row := range rows {
+			if row.Differs {
+				data.Differences++
+			}
+		}
+	}
+	return data
+}
+
+// diffConfig lines up the values of every setting across backends, nil
+// for unreachable ones. A setting differs when reachable backends do not
+// all have the same value; the values other than the most common one are
+// marked as odd.
+func diffConfig(configs []map[string]string) []ConfigRowData {
+	names := make(map[string]struct{})
+	for _, cfg := range configs {
+		for name := range cfg {
+			names[name] = struct{}{}
+		}
+	}
+
+	rows := make([]ConfigRowData, 0, len(names))
+	for name := range names {
+		row := ConfigRowData{Name: name, Values: make([]ConfigValueData, len(configs))}
+		counts := make(map[string]int)
+		var common string
+		for i, cfg := range configs {
+			if cfg == nil {
+				row.Values[i].Unavailable = true
+				continue
+			}
+			v, ok := cfg[name]
+			row.Values[i] = ConfigValueData{Value: v, Missing: !ok}
+			key := v
+			if !ok {
+				key = "\x00missing"
+			}
+			counts[key]++
+			// Ties go to the first backend
+			if counts[key] > counts[common] {
+				common = key
+			}
+		}
+		row.Differs = len(counts) > 1
+		if row.Differs {
+			for i, v := range row.Values {
+				if v.Unavailable {
+					continue
+				}
+				key := v.Value
+				if v.Missing {
+					key = "\x00missing"
+				}
+				row.Values[i].Odd = key != common
+			}
+		}
+		rows = append(rows, row)
+	}
+
+	sort.Slice(rows, func(i, j int) bool {
+		return strings.ToLower(rows[i].Name) < strings.ToLower(rows[j].Name)
+	})
+	return rows
+}
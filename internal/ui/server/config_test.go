@@ -0,0 +1,33 @@
+package server
+
+import "testing"
+
+func TestDiffConfig(t *testing.T) {
+	rows := diffConfig([]map[string]string{
+		{"Port": "5060", "Ringback": "true", "MaxSpirals": "3"},
+		{"Port": "5060", "Ringback": "false", "MaxSpirals": "3"},
+		nil, // Unreachable
+		{"Port": "5060", "Ringback": "true"},
+	})
+
+	byName := make(map[string]ConfigRowData)
+	for _, r := range rows {
+		byName[r.Name] = r
+	}
+	if rows[0].Name != "MaxSpirals" || len(rows) != 3 {
+		t.Fatalf("rows not sorted by name: %+v", rows)
+	}
+	if port := byName["Port"]; port.Differs || !port.Values[2].Unavailable {
+		t.Errorf("Port = %+v", port)
+	}
+
+	// The odd value out is the one against the majority
+	ringback := byName["Ringback"]
+	if !ringback.Differs || ringback.Values[0].Odd || !ringback.Values[1].Odd || ringback.Values[3].Odd {
+		t.Errorf("Ringback = %+v", ringback)
+	}
+	spirals := byName["MaxSpirals"]
+	if !spirals.Differs || !spirals.Values[3].Missing || !spirals.Values[3].Odd {
+		t.Errorf("MaxSpirals = %+v", spirals)
+	}
+}
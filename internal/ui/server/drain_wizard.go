@@ -0,0 +1,139 @@
+package server
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"net/http"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+)
+
+// handleDrainWizard shows the progress step of the drain wizard. POST
+// starts the drain first, with the mode chosen in the first step; GET
+// reopens the wizard for a drain in progress.
+func (s *Server) handleDrainWizard(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	server := r.URL.Query().Get("server")
+	nodeID := r.URL.Query().Get("nodeId")
+	if server == "" || nodeID == "" {
+		http.Error(w, "Missing server or nodeId", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	data := DrainWizardData{Server: server, NodeID: nodeID}
+	if r.Method == http.MethodPost {
+		data.Mode = r.URL.Query().Get("mode")
+		if _, err := targetClient.StartDrain(r.Context(), nodeID, data.Mode); err != nil {
+			slog.Error("[UI] Failed to start drain", "server", server, "nodeId", nodeID, "error", err)
+			w.Header().Set("Content-Type", "text/html; charset=utf-8")
+			w.WriteHeader(http.StatusInternalServerError)
+			_, _ = fmt.Fprintf(w, `<div class="text-red-400 text-sm">Failed to start drain: %s</div>`, html.EscapeString(err.Error()))
+			return
+		}
+		w.Header().Set("HX-Trigger", "drainStarted")
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderDrainWizard(w, data); err != nil {
+		slog.Error("[UI] Failed to render drain wizard", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// drainStreamError is sent to the wizard when the backend's drain event
+// stream cannot be read
+type drainStreamError struct {
+	Type  string `json:"type"` // Always "stream_error"
+	Error string `json:"error"`
+}
+
+// handleDrainEvents relays a node's drain events from its backend to the
+// wizard as Server-Sent Events
+func (s *Server) handleDrainEvents(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	server := r.URL.Query().Get("server")
+	nodeID := r.URL.Query().Get("nodeId")
+	if server == "" || nodeID == "" {
+		http.Error(w, "Missing server or nodeId", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		http.Error(w, "Streaming not supported", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	send := func(v any) {
+		data, err := json.Marshal(v)
+		if err != nil {
+			return
+		}
+		_, _ = fmt.Fprintf(w, "data: %s\n\n", data)
+		flusher.Flush()
+	}
+
+	err := targetClient.DrainEvents(r.Context(), nodeID, func(event types.DrainEvent) {
+		send(event)
+	})
+	if err != nil && r.Context().Err() == nil {
+		slog.Debug("[UI] Drain event stream failed", "server", server, "nodeId", nodeID, "error", err)
+		send(drainStreamError{Type: "stream_error", Error: err.Error()})
+	}
+}
+
+// handleDrainRetry retries the migration of a session that failed during
+// a drain. Its progress is reported through the drain event stream.
+func (s *Server) handleDrainRetry(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	server := r.URL.Query().Get("server")
+	nodeID := r.URL.Query().Get("nodeId")
+	sessionID := r.URL.Query().Get("sessionId")
+	if server == "" || nodeID == "" || sessionID == "" {
+		http.Error(w, "Missing server, nodeId or sessionId", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	if err := targetClient.RetryDrainSession(r.Context(), nodeID, sessionID); err != nil {
+		slog.Error("[UI] Failed to retry session migration", "server", server, "nodeId", nodeID, "sessionId", sessionID, "error", err)
+		http.Error(w, err.Error(), http.StatusBadGateway)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
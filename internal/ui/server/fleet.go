@@ -0,0 +1,136 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/sebas/switchboard/internal/version"
+	"github.com/sebas/switchboard/pkg/client"
+)
+
+// handleFleet returns the health of every backend and RTP manager as one
+// JSON document for external monitors. It answers 503 when no backend is
+// reachable, 200 otherwise; check "status" for degraded fleets.
+func (s *Server) handleFleet(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), 5*time.Second)
+	defer cancel()
+	fleet := s.buildFleet(ctx)
+
+	w.Header().Set("Content-Type", "application/json")
+	if fleet.Status == types.FleetDown {
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}
+	if err := json.NewEncoder(w).Encode(fleet); err != nil {
+		slog.Error("[UI] Failed to encode fleet", "error", err)
+	}
+}
+
+// buildFleet queries every backend concurrently
+func (s *Server) buildFleet(ctx context.Context) types.FleetResponse {
+	clients := s.backendClients()
+	fleet := types.FleetResponse{
+		GeneratedAt: time.Now().UTC().Format(time.RFC3339),
+		UI: types.FleetUI{
+			Version: version.Get(),
+			Uptime:  int64(time.Since(s.startTime).Seconds()),
+		},
+		Backends:    make([]types.FleetBackend, len(clients)),
+		RtpManagers: make([]types.FleetRtpManager, 0),
+	}
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	for i, c := range clients {
+		wg.Add(1)
+		go func(i int, c *client.Client) {
+			defer wg.Done()
+			backend, managers := fetchFleetBackend(ctx, c)
+			mu.Lock()
+			fleet.Backends[i] = backend
+			fleet.RtpManagers = append(fleet.RtpManagers, managers...)
+			mu.Unlock()
+		}(i, c)
+	}
+	wg.Wait()
+
+	sort.Slice(fleet.RtpManagers, func(i, j int) bool {
+		a, b := fleet.RtpManagers[i], fleet.RtpManagers[j]
+		if a.Backend != b.Backend {
+			return a.Backend < b.Backend
+		}
+		return a.NodeID < b.NodeID
+	})
+
+	t := &fleet.Totals
+	t.Backends = len(fleet.Backends)
+	for _, b := range fleet.Backends {
+		if b.Error == "" {
+			t.BackendsUp++
+		}
+		t.ActiveDialogs += b.ActiveDialogs
+		t.ActiveSessions += b.ActiveSessions
+	}
+	t.RtpManagers = len(fleet.RtpManagers)
+	for _, m := range fleet.RtpManagers {
+		if m.Healthy {
+			t.RtpManagersHealthy++
+		}
+	}
+
+	switch {
+	case t.BackendsUp == 0:
+		fleet.Status = types.FleetDown
+	case t.BackendsUp < t.Backends || t.RtpManagersHealthy < t.RtpManagers:
+		fleet.Status = types.FleetDegraded
+	default:
+		fleet.Status = types.FleetOK
+	}
+	return fleet
+}
+
+// fetchFleetBackend returns one backend's health, call counts and RTP managers
+func fetchFleetBackend(ctx context.Context, c *client.Client) (types.FleetBackend, []types.FleetRtpManager) {
+	backend := types.FleetBackend{
+		Name:    c.Name(),
+		Address: c.BaseURL(),
+		Status:  "offline",
+	}
+
+	health, err := c.Health(ctx)
+	if err != nil {
+		backend.Error = err.Error()
+		return backend, nil
+	}
+	backend.Status = health.Status
+	backend.Version = health.Version
+	backend.Uptime = health.Uptime
+
+	if stats, err := c.Stats(ctx); err != nil {
+		slog.Debug("[UI] Backend stats fetch failed", "backend", c.Name(), "error", err)
+	} else {
+		backend.ActiveDialogs = stats.ActiveDialogs
+		backend.ActiveSessions = stats.ActiveSessions
+	}
+
+	pool, err := c.RtpManagers(ctx)
+	if err != nil {
+		slog.Debug("[UI] Backend rtpmanagers fetch failed", "backend", c.Name(), "error", err)
+		return backend, nil
+	}
+	managers := make([]types.FleetRtpManager, 0, len(pool.Members))
+	for _, m := range pool.Members {
+		managers = append(managers, types.FleetRtpManager{Backend: c.Name(), RtpManager: m})
+	}
+	return backend, managers
+}
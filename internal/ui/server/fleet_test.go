@@ -0,0 +1,61 @@
+package server
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/sebas/switchboard/pkg/client"
+)
+
+func fakeBackend(t *testing.T, healthy bool) *httptest.Server {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/api/v1/health", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"status":"ok","uptime":60,"version":"v1.2.0"}`))
+	})
+	mux.HandleFunc("/api/v1/stats", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(`{"active_sessions":3,"active_dialogs":6}`))
+	})
+	mux.HandleFunc("/api/v1/rtpmanagers", func(w http.ResponseWriter, r *http.Request) {
+		if healthy {
+			_, _ = w.Write([]byte(`{"members":[{"node_id":"rtp-0","healthy":true,"drain_state":"active","version":"v1.2.0"}]}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"members":[{"node_id":"rtp-1","healthy":false,"drain_state":"draining"}]}`))
+	})
+	srv := httptest.NewServer(mux)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestBuildFleet(t *testing.T) {
+	a := fakeBackend(t, true)
+	b := fakeBackend(t, false)
+	s := &Server{startTime: time.Now(), clients: []*client.Client{
+		client.NewClient("a", a.URL),
+		client.NewClient("b", b.URL),
+	}}
+
+	fleet := s.buildFleet(context.Background())
+	if fleet.Status != types.FleetDegraded {
+		t.Errorf("status = %q, want degraded", fleet.Status)
+	}
+	want := types.FleetTotals{Backends: 2, BackendsUp: 2, RtpManagers: 2, RtpManagersHealthy: 1, ActiveDialogs: 12, ActiveSessions: 6}
+	if fleet.Totals != want {
+		t.Errorf("totals = %+v, want %+v", fleet.Totals, want)
+	}
+	if fleet.Backends[0].Version != "v1.2.0" || fleet.RtpManagers[1].DrainState != "draining" {
+		t.Errorf("unexpected fleet: %+v", fleet)
+	}
+
+	// Unreachable backends take the fleet down
+	a.Close()
+	b.Close()
+	fleet = s.buildFleet(context.Background())
+	if fleet.Status != types.FleetDown || fleet.Backends[0].Error == "" {
+		t.Errorf("fleet with no backends: %+v", fleet)
+	}
+}
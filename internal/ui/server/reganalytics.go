@@ -0,0 +1,108 @@
+package server
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Registrations expiring within this are listed as expiring soon
+const regExpiringSoon = time.Minute
+
+// User agent products charted before the rest are grouped as "Other"
+const regTopUserAgents = 8
+
+// regExpiryBuckets are the expiry intervals charted, by upper bound in
+// seconds
+var regExpiryBuckets = []struct {
+	label string
+	max   int
+}{
+	{"Up to 1m", 60},
+	{"1m - 5m", 300},
+	{"5m - 30m", 1800},
+	{"30m - 1h", 3600},
+	{"Over 1h", math.MaxInt},
+}
+
+// registrationAnalytics breaks registrations down by transport, user agent
+// and expiry interval, and lists those about to expire or never refreshed.
+// A binding is never refreshed once half of its interval went by without
+// a re-REGISTER; clients normally refresh well before that.
+func registrationAnalytics(regs []RegistrationData) RegistrationAnalytics {
+	a := RegistrationAnalytics{Total: len(regs)}
+	if len(regs) == 0 {
+		return a
+	}
+
+	transports := make(map[string]int)
+	userAgents := make(map[string]int)
+	expiry := make([]int, len(regExpiryBuckets))
+	for _, r := range regs {
+		transports[strings.ToUpper(r.Transport)]++
+		userAgents[userAgentProduct(r.UserAgent)]++
+		for i, b := range regExpiryBuckets {
+			if r.Expires <= b.max {
+				expiry[i]++
+				break
+			}
+		}
+
+		w := RegistrationWarning{
+			RegistrationData: r,
+			ExpiringSoon:     r.ExpiresIn < regExpiringSoon,
+			NeverRefreshed:   !r.Refreshed && r.ExpiresIn < time.Duration(r.Expires)*time.Second/2,
+		}
+		if w.ExpiringSoon || w.NeverRefreshed {
+			a.Warnings = append(a.Warnings, w)
+		}
+	}
+
+	a.Transports = shares(transports, len(regs), 0)
+	a.UserAgents = shares(userAgents, len(regs), regTopUserAgents)
+	for i, b := range regExpiryBuckets {
+		a.Expiry = append(a.Expiry, ShareData{Label: b.label, Count: expiry[i], Percent: expiry[i] * 100 / len(regs)})
+	}
+	sort.SliceStable(a.Warnings, func(i, j int) bool {
+		return a.Warnings[i].ExpiresIn < a.Warnings[j].ExpiresIn
+	})
+	return a
+}
+
+// shares sorts counts most common first, grouping all but the top ones as
+// "Other" (no grouping if top is 0)
+func shares(counts map[string]int, total, top int) []ShareData {
+	out := make([]ShareData, 0, len(counts))
+	for label, n := range counts {
+		out = append(out, ShareData{Label: label, Count: n})
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Count != out[j].Count {
+			return out[i].Count > out[j].Count
+		}
+		return out[i].Label < out[j].Label
+	})
+	if top > 0 && len(out) > top {
+		other := ShareData{Label: "Other"}
+		for _, s := range out[top:] {
+			other.Count += s.Count
+		}
+		out = append(out[:top], other)
+	}
+	for i := range out {
+		out[i].Percent = out[i].Count * 100 / total
+	}
+	return out
+}
+
+// userAgentProduct returns the product of a User-Agent, without its
+// version: "Linphone/5.2.0 (belle-sip/5.2.0)" is "Linphone"
+func userAgentProduct(ua string) string {
+	product, _, _ := strings.Cut(strings.TrimSpace(ua), " ")
+	product, _, _ = strings.Cut(product, "/")
+	if product == "" {
+		return "Unknown"
+	}
+	return product
+}
@@ -0,0 +1,50 @@
+package server
+
+import (
+	"testing"
+	"time"
+)
+
+func TestRegistrationAnalytics(t *testing.T) {
+	regs := []RegistrationData{
+		{AOR: "sip:1001@a", Transport: "udp", UserAgent: "Linphone/5.2.0 (belle-sip/5.2.0)", Expires: 3600, ExpiresIn: 50 * time.Minute, Refreshed: true},
+		{AOR: "sip:1002@a", Transport: "UDP", UserAgent: "Linphone/4.4", Expires: 3600, ExpiresIn: 30 * time.Second, Refreshed: true},
+		{AOR: "sip:1003@a", Transport: "tls", UserAgent: "Yealink SIP-T46S 66.85.0.5", Expires: 600, ExpiresIn: 4 * time.Minute},
+		{AOR: "sip:1004@a", Transport: "tcp", Expires: 60, ExpiresIn: 50 * time.Second},
+	}
+
+	a := registrationAnalytics(regs)
+	if a.Total != 4 {
+		t.Errorf("total = %d, want 4", a.Total)
+	}
+	if a.Transports[0] != (ShareData{Label: "UDP", Count: 2, Percent: 50}) {
+		t.Errorf("transports = %+v", a.Transports)
+	}
+	if a.UserAgents[0].Label != "Linphone" || a.UserAgents[0].Count != 2 || a.UserAgents[1].Label != "Unknown" {
+		t.Errorf("user agents = %+v", a.UserAgents)
+	}
+	if a.Expiry[0].Count != 1 || a.Expiry[2].Count != 1 || a.Expiry[3].Count != 2 {
+		t.Errorf("expiry = %+v", a.Expiry)
+	}
+
+	// Most urgent first; 1001 is fine, 1003 is past half its interval
+	// without a refresh
+	var aors []string
+	for _, w := range a.Warnings {
+		aors = append(aors, w.AOR)
+	}
+	if len(aors) != 3 || aors[0] != "sip:1002@a" || aors[1] != "sip:1004@a" || aors[2] != "sip:1003@a" {
+		t.Fatalf("warnings = %v", aors)
+	}
+	if a.Warnings[2].ExpiringSoon || !a.Warnings[2].NeverRefreshed {
+		t.Errorf("warning for 1003 = %+v", a.Warnings[2])
+	}
+}
+
+func TestSharesGroupsOther(t *testing.T) {
+	counts := map[string]int{"a": 5, "b": 3, "c": 1, "d": 1}
+	got := shares(counts, 10, 2)
+	if len(got) != 3 || got[2] != (ShareData{Label: "Other", Count: 2, Percent: 20}) {
+		t.Errorf("shares = %+v", got)
+	}
+}
@@ -3,23 +3,36 @@ package server
 import (
 	"context"
 	"fmt"
+	"html"
 	"log/slog"
 	"net/http"
+	"slices"
+	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
-	"github.com/sebas/switchboard/internal/ui/client"
+	types "github.com/sebas/switchboard/api/types/v1"
+	"github.com/sebas/switchboard/internal/debug"
+	"github.com/sebas/switchboard/internal/secrets"
 	"github.com/sebas/switchboard/internal/ui/config"
+	"github.com/sebas/switchboard/pkg/client"
 )
 
 // Server provides the UI HTTP server that aggregates data from multiple backends
 type Server struct {
 	config     *config.Config
 	httpServer *http.Server
-	clients    []*client.Client
 	templates  *Templates
 	startTime  time.Time
+
+	// Configured backends first, then discovered ones
+	clientsMu  sync.RWMutex
+	clients    []*client.Client
+	discovered map[string]bool // Names of discovered backends
+
+	debugToken *secrets.Secret
 }
 
 // NewServer creates a new UI server
@@ -30,12 +43,7 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	}
 
 	// Create clients for each backend
-	s.clients = make([]*client.Client, 0, len(cfg.Backends))
-	for _, backend := range cfg.Backends {
-		c := client.NewClient(backend.Name, backend.Address)
-		s.clients = append(s.clients, c)
-		slog.Info("[UI] Added backend", "name", backend.Name, "address", backend.Address)
-	}
+	s.SetBackends(nil)
 
 	// Initialize templates
 	var err error
@@ -52,18 +60,50 @@ func NewServer(cfg *config.Config) (*Server, error) {
 	mux.HandleFunc("/admin/partials/stats", s.handleStatsPartial)
 	mux.HandleFunc("/admin/partials/backends", s.handleBackendsPartial)
 	mux.HandleFunc("/admin/partials/registrations", s.handleRegistrationsPartial)
+	mux.HandleFunc("/admin/partials/reganalytics", s.handleRegAnalyticsPartial)
 	mux.HandleFunc("/admin/partials/dialogs", s.handleDialogsPartial)
 	mux.HandleFunc("/admin/partials/sessions", s.handleSessionsPartial)
 	mux.HandleFunc("/admin/partials/rtpmanagers", s.handleRtpManagersPartial)
+	mux.HandleFunc("/admin/partials/blocklists", s.handleBlocklistsPartial)
+	mux.HandleFunc("/admin/partials/users", s.handleUsersPartial)
+	mux.HandleFunc("/admin/partials/routes", s.handleRoutesPartial)
+	mux.HandleFunc("/admin/partials/config", s.handleConfigPartial)
 
 	// RTP Manager drain control endpoints
 	mux.HandleFunc("/admin/rtpmanagers/drain-modal", s.handleDrainModal)
 	mux.HandleFunc("/admin/rtpmanagers/drain", s.handleDrain)
 	mux.HandleFunc("/admin/rtpmanagers/cancel-drain", s.handleCancelDrain)
+	mux.HandleFunc("/admin/rtpmanagers/drain-wizard", s.handleDrainWizard)
+	mux.HandleFunc("/admin/rtpmanagers/drain-events", s.handleDrainEvents)
+	mux.HandleFunc("/admin/rtpmanagers/drain-retry", s.handleDrainRetry)
+
+	// Caller blocklist management
+	mux.HandleFunc("/admin/blocklists/add", s.handleBlocklistAdd)
+	mux.HandleFunc("/admin/blocklists/remove", s.handleBlocklistRemove)
+
+	// User call features
+	mux.HandleFunc("/admin/users/dnd", s.handleUserDND)
+
+	// Routing statistics
+	mux.HandleFunc("/admin/routes/reset", s.handleRouteReset)
 
 	// Health check
 	mux.HandleFunc("/health", s.handleHealth)
 
+	// Aggregated fleet health for external monitors
+	mux.HandleFunc("/api/v1/fleet", s.handleFleet)
+
+	// Runtime diagnostics, when a token is configured
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	s.debugToken, err = secrets.New(secrets.ConfigFromEnv(), nil).Load(ctx, "debug-token", cfg.DebugToken)
+	if err != nil {
+		return nil, err
+	}
+	if debug.RegisterFunc(mux, s.debugToken.Value) {
+		slog.Info("[UI] Debug endpoints enabled", "path", "/debug/")
+	}
+
 	addr := fmt.Sprintf("%s:%d", cfg.BindAddr, cfg.Port)
 	s.httpServer = &http.Server{
 		Addr:    addr,
@@ -91,6 +131,66 @@ func (s *Server) Stop() error {
 	return s.httpServer.Shutdown(ctx)
 }
 
+// WatchSecrets keeps the debug token current while it is rotated in its
+// secret store, until ctx is done
+func (s *Server) WatchSecrets(ctx context.Context, interval time.Duration) {
+	secrets.Watch(ctx, interval, s.debugToken)
+}
+
+// SetBackends replaces the discovered backends. Configured backends are
+// kept, and win over a discovered backend of the same name; clients of
+// backends that did not change are reused.
+func (s *Server) SetBackends(discovered []config.Backend) {
+	s.clientsMu.Lock()
+	defer s.clientsMu.Unlock()
+
+	current := make(map[string]*client.Client, len(s.clients))
+	for _, c := range s.clients {
+		current[c.Name()] = c
+	}
+
+	clients := make([]*client.Client, 0, len(s.config.Backends)+len(discovered))
+	names := make(map[string]bool)
+	s.discovered = make(map[string]bool)
+	for i, backend := range slices.Concat(s.config.Backends, discovered) {
+		if names[backend.Name] {
+			continue
+		}
+		names[backend.Name] = true
+		if i >= len(s.config.Backends) {
+			s.discovered[backend.Name] = true
+		}
+
+		c, ok := current[backend.Name]
+		if !ok || c.BaseURL() != strings.TrimSuffix(backend.Address, "/") {
+			c = client.NewClient(backend.Name, backend.Address)
+			slog.Info("[UI] Added backend", "name", backend.Name, "address", backend.Address)
+		}
+		delete(current, backend.Name)
+		clients = append(clients, c)
+	}
+	for name := range current {
+		slog.Info("[UI] Removed backend", "name", name)
+	}
+	s.clients = clients
+}
+
+// isDiscovered reports whether a backend was discovered rather than
+// configured
+func (s *Server) isDiscovered(name string) bool {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	return s.discovered[name]
+}
+
+// backendClients returns the clients of the current backends. The slice
+// is replaced, never modified, so callers may range over it unlocked.
+func (s *Server) backendClients() []*client.Client {
+	s.clientsMu.RLock()
+	defer s.clientsMu.RUnlock()
+	return s.clients
+}
+
 // handleHealth returns the health status of the UI server
 func (s *Server) handleHealth(w http.ResponseWriter, _ *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
@@ -142,6 +242,16 @@ func (s *Server) handleRegistrationsPartial(w http.ResponseWriter, r *http.Reque
 	}
 }
 
+// handleRegAnalyticsPartial renders the registration analytics partial for HTMX
+func (s *Server) handleRegAnalyticsPartial(w http.ResponseWriter, r *http.Request) {
+	data := s.buildTemplateData(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderRegAnalytics(w, data); err != nil {
+		slog.Error("[UI] Failed to render registration analytics partial", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
 // handleDialogsPartial renders the dialogs table partial for HTMX
 func (s *Server) handleDialogsPartial(w http.ResponseWriter, r *http.Request) {
 	data := s.buildTemplateData(r.Context())
@@ -172,10 +282,26 @@ func (s *Server) handleRtpManagersPartial(w http.ResponseWriter, r *http.Request
 	}
 }
 
+// handleBlocklistsPartial renders the caller blocklists partial for HTMX
+func (s *Server) handleBlocklistsPartial(w http.ResponseWriter, r *http.Request) {
+	s.renderBlocklists(w, r)
+}
+
+// handleUsersPartial renders the user call features partial for HTMX
+func (s *Server) handleUsersPartial(w http.ResponseWriter, r *http.Request) {
+	s.renderUsers(w, r)
+}
+
+// handleRoutesPartial renders the routing statistics partial for HTMX
+func (s *Server) handleRoutesPartial(w http.ResponseWriter, r *http.Request) {
+	s.renderRoutes(w, r)
+}
+
 // buildTemplateData fetches data from all backends and aggregates it
 func (s *Server) buildTemplateData(ctx context.Context) TemplateData {
 	uptime := time.Since(s.startTime)
 	uptimeStr := formatUptime(uptime)
+	clients := s.backendClients()
 
 	data := TemplateData{
 		Title: "Switchboard Admin",
@@ -184,19 +310,23 @@ func (s *Server) buildTemplateData(ctx context.Context) TemplateData {
 			Uptime: uptimeStr,
 		},
 		Stats:         StatsData{},
-		Backends:      make([]BackendData, 0, len(s.clients)),
+		Backends:      make([]BackendData, 0, len(clients)),
 		RtpManagers:   make([]RtpManagerData, 0),
 		Registrations: make([]RegistrationData, 0),
 		Dialogs:       make([]DialogData, 0),
 		Sessions:      make([]SessionData, 0),
-		MultiBackend:  len(s.clients) > 1,
+		Blocklists:    make([]BlocklistData, 0),
+		Users:         make([]UserData, 0),
+		Routes:        make([]RouteStatsData, 0),
+		MultiBackend:  len(clients) > 1,
+		Discovery:     s.config.Discovery(),
 	}
 
 	// Fetch data from all backends concurrently
 	var wg sync.WaitGroup
 	var mu sync.Mutex
 
-	for _, c := range s.clients {
+	for _, c := range clients {
 		wg.Add(1)
 		go func(c *client.Client) {
 			defer wg.Done()
@@ -205,6 +335,13 @@ func (s *Server) buildTemplateData(ctx context.Context) TemplateData {
 	}
 
 	wg.Wait()
+
+	data.RegAnalytics = registrationAnalytics(data.Registrations)
+
+	// Routes failing the most recently first, so bad routes stand out
+	sort.SliceStable(data.Routes, func(i, j int) bool {
+		return data.Routes[i].Recent.FailureRate > data.Routes[j].Recent.FailureRate
+	})
 	return data
 }
 
@@ -216,6 +353,7 @@ func (s *Server) fetchBackendData(ctx context.Context, c *client.Client, data *T
 		Address: c.BaseURL(),
 		Status:  "offline",
 	}
+	backendData.Discovered = s.isDiscovered(backendName)
 
 	// Fetch health
 	health, err := c.Health(ctx)
@@ -257,6 +395,7 @@ func (s *Server) fetchBackendData(ctx context.Context, c *client.Client, data *T
 				ttlStr = formatDuration(int(ttl.Seconds()))
 			}
 			registeredAt, _ := time.Parse(time.RFC3339, r.RegisteredAt)
+			createdAt, _ := time.Parse(time.RFC3339, r.CreatedAt)
 
 			data.Registrations = append(data.Registrations, RegistrationData{
 				Server:       backendName,
@@ -269,6 +408,8 @@ func (s *Server) fetchBackendData(ctx context.Context, c *client.Client, data *T
 				TTL:          ttlStr,
 				UserAgent:    r.UserAgent,
 				RegisteredAt: registeredAt.Format("15:04:05"),
+				ExpiresIn:    ttl,
+				Refreshed:    createdAt.IsZero() || registeredAt.After(createdAt),
 			})
 		}
 		mu.Unlock()
@@ -327,7 +468,10 @@ func (s *Server) fetchBackendData(ctx context.Context, c *client.Client, data *T
 		mu.Lock()
 		for _, m := range rtpManagers.Members {
 			status := "Unhealthy"
-			if m.Healthy {
+			switch {
+			case m.Healthy && m.Circuit == "open":
+				status = "Circuit open"
+			case m.Healthy:
 				status = "Healthy"
 			}
 			data.RtpManagers = append(data.RtpManagers, RtpManagerData{
@@ -343,11 +487,75 @@ func (s *Server) fetchBackendData(ctx context.Context, c *client.Client, data *T
 		mu.Unlock()
 	}
 
+	// Fetch caller blocklists (unavailable when screening is disabled)
+	lists, err := c.ScreeningLists(ctx)
+	if err != nil {
+		slog.Debug("[UI] Backend screening lists fetch failed", "backend", backendName, "error", err)
+	} else {
+		mu.Lock()
+		for _, l := range lists {
+			data.Blocklists = append(data.Blocklists, BlocklistData{
+				Server:       backendName,
+				Name:         l.Name,
+				Action:       l.Action,
+				Announcement: l.Announcement,
+				Entries:      l.Entries,
+			})
+		}
+		mu.Unlock()
+	}
+
+	// Fetch user call features (unavailable when user features are disabled)
+	users, err := c.Users(ctx)
+	if err != nil {
+		slog.Debug("[UI] Backend users fetch failed", "backend", backendName, "error", err)
+	} else {
+		mu.Lock()
+		for _, u := range users {
+			data.Users = append(data.Users, UserData{Server: backendName, UserFeatures: u})
+		}
+		mu.Unlock()
+	}
+
+	// Fetch routing statistics
+	routes, err := c.RouteStats(ctx, "")
+	if err != nil {
+		slog.Debug("[UI] Backend routing stats fetch failed", "backend", backendName, "error", err)
+	} else {
+		mu.Lock()
+		for _, rs := range routes.Routes {
+			data.Routes = append(data.Routes, routeStatsData(backendName, rs))
+		}
+		mu.Unlock()
+	}
+
 	mu.Lock()
 	data.Backends = append(data.Backends, backendData)
 	mu.Unlock()
 }
 
+// Recent failure rates (percent) from which a route is shown as degraded
+// or failing
+const (
+	routeDegradedRate = 20
+	routeFailingRate  = 50
+)
+
+// routeStatsData converts a route's statistics for display
+func routeStatsData(server string, rs types.RouteStats) RouteStatsData {
+	d := RouteStatsData{Server: server, RouteStats: rs, Health: "ok"}
+	switch {
+	case rs.Recent.FailureRate >= routeFailingRate:
+		d.Health = "failing"
+	case rs.Recent.FailureRate >= routeDegradedRate:
+		d.Health = "degraded"
+	}
+	if t, err := time.Parse(time.RFC3339, rs.LastFailure); err == nil && !t.IsZero() {
+		d.LastFailureAgo = formatUptime(time.Since(t))
+	}
+	return d
+}
+
 // formatUptime formats a duration for display
 func formatUptime(d time.Duration) string {
 	days := int(d.Hours()) / 24
@@ -426,13 +634,7 @@ func (s *Server) handleDrain(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find the client for the specified server
-	var targetClient *client.Client
-	for _, c := range s.clients {
-		if c.Name() == server {
-			targetClient = c
-			break
-		}
-	}
+	targetClient := s.clientFor(server)
 
 	if targetClient == nil {
 		http.Error(w, "Server not found", http.StatusNotFound)
@@ -476,13 +678,7 @@ func (s *Server) handleCancelDrain(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// Find the client for the specified server
-	var targetClient *client.Client
-	for _, c := range s.clients {
-		if c.Name() == server {
-			targetClient = c
-			break
-		}
-	}
+	targetClient := s.clientFor(server)
 
 	if targetClient == nil {
 		http.Error(w, "Server not found", http.StatusNotFound)
@@ -508,3 +704,184 @@ func (s *Server) handleCancelDrain(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Failed to render template", http.StatusInternalServerError)
 	}
 }
+
+// handleBlocklistAdd adds an entry to a caller blocklist
+func (s *Server) handleBlocklistAdd(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	server := r.FormValue("server")
+	list := r.FormValue("list")
+	entry := types.ScreeningEntry{
+		Match:   r.FormValue("match"),
+		Pattern: strings.TrimSpace(r.FormValue("pattern")),
+		Comment: r.FormValue("comment"),
+	}
+	if server == "" || list == "" || entry.Pattern == "" {
+		http.Error(w, "Missing server, list or pattern", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	if err := targetClient.AddScreeningEntry(r.Context(), list, entry); err != nil {
+		slog.Error("[UI] Failed to add blocklist entry", "server", server, "list", list, "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, `<div class="text-red-400 text-sm">Failed to add entry: %s</div>`, html.EscapeString(err.Error()))
+		return
+	}
+
+	s.renderBlocklists(w, r)
+}
+
+// handleBlocklistRemove removes an entry from a caller blocklist
+func (s *Server) handleBlocklistRemove(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	q := r.URL.Query()
+	server := q.Get("server")
+	list := q.Get("list")
+	if server == "" || list == "" {
+		http.Error(w, "Missing server or list", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	if err := targetClient.RemoveScreeningEntry(r.Context(), list, q.Get("match"), q.Get("pattern")); err != nil {
+		slog.Error("[UI] Failed to remove blocklist entry", "server", server, "list", list, "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, `<div class="text-red-400 text-sm">Failed to remove entry: %s</div>`, html.EscapeString(err.Error()))
+		return
+	}
+
+	s.renderBlocklists(w, r)
+}
+
+// renderBlocklists renders the current blocklists from all backends
+func (s *Server) renderBlocklists(w http.ResponseWriter, r *http.Request) {
+	data := s.buildTemplateData(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderBlocklists(w, data); err != nil {
+		slog.Error("[UI] Failed to render blocklists partial", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// handleUserDND turns a user's Do Not Disturb on or off. Parameters come
+// from the query (row buttons) or the form.
+func (s *Server) handleUserDND(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "Invalid form", http.StatusBadRequest)
+		return
+	}
+
+	server := r.FormValue("server")
+	user := strings.TrimSpace(r.FormValue("user"))
+	if server == "" || user == "" {
+		http.Error(w, "Missing server or user", http.StatusBadRequest)
+		return
+	}
+	on := r.FormValue("dnd") == "on"
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	if err := targetClient.SetDND(r.Context(), user, on); err != nil {
+		slog.Error("[UI] Failed to set DND", "server", server, "user", user, "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, `<div class="text-red-400 text-sm">Failed to set Do Not Disturb: %s</div>`, html.EscapeString(err.Error()))
+		return
+	}
+
+	s.renderUsers(w, r)
+}
+
+// renderUsers renders the current user call features from all backends
+func (s *Server) renderUsers(w http.ResponseWriter, r *http.Request) {
+	data := s.buildTemplateData(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderUsers(w, data); err != nil {
+		slog.Error("[UI] Failed to render users partial", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// handleRouteReset clears the statistics of a dialplan rule or trunk
+func (s *Server) handleRouteReset(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+
+	server := r.URL.Query().Get("server")
+	kind := r.URL.Query().Get("kind")
+	name := r.URL.Query().Get("name")
+	if server == "" || kind == "" || name == "" {
+		http.Error(w, "Missing server, kind or name", http.StatusBadRequest)
+		return
+	}
+
+	targetClient := s.clientFor(server)
+	if targetClient == nil {
+		http.Error(w, "Server not found", http.StatusNotFound)
+		return
+	}
+
+	if err := targetClient.ResetRouteStats(r.Context(), kind, name); err != nil {
+		slog.Error("[UI] Failed to reset routing stats", "server", server, "kind", kind, "name", name, "error", err)
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		_, _ = fmt.Fprintf(w, `<div class="text-red-400 text-sm">Failed to reset routing stats: %s</div>`, html.EscapeString(err.Error()))
+		return
+	}
+
+	s.renderRoutes(w, r)
+}
+
+// renderRoutes renders the current routing statistics from all backends
+func (s *Server) renderRoutes(w http.ResponseWriter, r *http.Request) {
+	data := s.buildTemplateData(r.Context())
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	if err := s.templates.RenderRoutes(w, data); err != nil {
+		slog.Error("[UI] Failed to render routes partial", "error", err)
+		http.Error(w, "Failed to render template", http.StatusInternalServerError)
+	}
+}
+
+// clientFor returns the client for a backend by name, or nil
+func (s *Server) clientFor(name string) *client.Client {
+	for _, c := range s.backendClients() {
+		if c.Name() == name {
+			return c
+		}
+	}
+	return nil
+}
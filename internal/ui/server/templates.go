@@ -4,6 +4,9 @@ import (
 	"embed"
 	"html/template"
 	"io"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
 )
 
 //go:embed templates/*.html
@@ -11,14 +14,20 @@ var templatesFS embed.FS
 
 // Templates holds all parsed templates
 type Templates struct {
-	dashboard          *template.Template
-	statsPartial       *template.Template
-	backendsPartial    *template.Template
-	rtpmanagersPartial *template.Template
-	regsPartial        *template.Template
-	dialogPartial      *template.Template
-	sessPartial        *template.Template
-	drainModalPartial  *template.Template
+	dashboard           *template.Template
+	statsPartial        *template.Template
+	backendsPartial     *template.Template
+	rtpmanagersPartial  *template.Template
+	regsPartial         *template.Template
+	dialogPartial       *template.Template
+	sessPartial         *template.Template
+	drainModalPartial   *template.Template
+	drainWizardPartial  *template.Template
+	blocklistsPartial   *template.Template
+	usersPartial        *template.Template
+	routesPartial       *template.Template
+	regAnalyticsPartial *template.Template
+	configPartial       *template.Template
 }
 
 // TemplateData holds data for rendering templates
@@ -29,9 +38,14 @@ type TemplateData struct {
 	Backends      []BackendData
 	RtpManagers   []RtpManagerData
 	Registrations []RegistrationData
+	RegAnalytics  RegistrationAnalytics
 	Dialogs       []DialogData
 	Sessions      []SessionData
+	Blocklists    []BlocklistData
+	Users         []UserData
+	Routes        []RouteStatsData
 	MultiBackend  bool // true if multiple backends configured
+	Discovery     bool // true if backends are discovered, so their number changes
 }
 
 // HealthData holds health information
@@ -50,10 +64,11 @@ type StatsData struct {
 
 // BackendData holds backend server information
 type BackendData struct {
-	Name    string
-	Address string
-	Status  string
-	Uptime  string
+	Name       string
+	Address    string
+	Status     string
+	Uptime     string
+	Discovered bool // Found by DNS SRV or Kubernetes discovery
 }
 
 // RegistrationData holds registration info for display
@@ -68,6 +83,33 @@ type RegistrationData struct {
 	TTL          string
 	UserAgent    string
 	RegisteredAt string
+	ExpiresIn    time.Duration
+	Refreshed    bool // Re-registered since its first REGISTER; true when unknown
+}
+
+// RegistrationAnalytics breaks registrations from all backends down for
+// the analytics charts
+type RegistrationAnalytics struct {
+	Total      int
+	Transports []ShareData
+	UserAgents []ShareData // By product, the least common grouped as "Other"
+	Expiry     []ShareData // By registered expiry interval
+	Warnings   []RegistrationWarning
+}
+
+// ShareData is one bar of an analytics chart
+type ShareData struct {
+	Label   string
+	Count   int
+	Percent int // Of all registrations
+}
+
+// RegistrationWarning is a registration about to expire or never
+// refreshed, most urgent first
+type RegistrationWarning struct {
+	RegistrationData
+	ExpiringSoon   bool
+	NeverRefreshed bool
 }
 
 // DialogData holds dialog info for display
@@ -103,13 +145,67 @@ type RtpManagerData struct {
 	NodeID            string // RTP manager node ID (e.g., "rtpmanager-0")
 	Address           string // RTP manager address (e.g., "localhost:9090")
 	Healthy           bool
-	Status            string // "Healthy" or "Unhealthy"
+	Status            string // "Healthy", "Unhealthy" or "Circuit open"
 	DrainState        string // "active", "draining", or "disabled"
 	SessionCount      int    // Number of active sessions on this node
 	InitialSessions   int    // Initial session count when drain started (for progress)
 	RemainingSessions int    // Remaining sessions during drain
 }
 
+// BlocklistData holds a caller blocklist for display
+type BlocklistData struct {
+	Server       string // Backend server name
+	Name         string
+	Action       string // "reject", "drop" or "announcement"
+	Announcement string
+	Entries      []types.ScreeningEntry
+}
+
+// UserData holds a user's call features for display
+type UserData struct {
+	Server string // Backend server name
+	types.UserFeatures
+}
+
+// RouteStatsData holds a dialplan rule's or trunk's call outcomes for display
+type RouteStatsData struct {
+	Server string // Backend server name
+	types.RouteStats
+	Health         string // "ok", "degraded" or "failing" by the recent failure rate
+	LastFailureAgo string // Empty if the route never failed
+}
+
+// ConfigViewData holds the configuration of every backend for display
+type ConfigViewData struct {
+	Backends    []ConfigBackendData
+	Settings    []ConfigRowData
+	Files       []ConfigRowData // Configuration file digests
+	Differences int             // Rows that differ between backends
+}
+
+// ConfigBackendData is a column of the configuration view
+type ConfigBackendData struct {
+	Name    string
+	Version string
+	Error   string // Set if the configuration could not be fetched
+}
+
+// ConfigRowData is a setting with its value on each backend, in the
+// order of the backends
+type ConfigRowData struct {
+	Name    string
+	Values  []ConfigValueData
+	Differs bool
+}
+
+// ConfigValueData is a setting's value on one backend
+type ConfigValueData struct {
+	Value       string
+	Missing     bool // The backend does not have the setting (older version)
+	Unavailable bool // The backend's configuration could not be fetched
+	Odd         bool // Differs from the value most backends have
+}
+
 // DrainModalData holds data for the drain confirmation modal
 type DrainModalData struct {
 	Server       string
@@ -118,6 +214,13 @@ type DrainModalData struct {
 	SessionCount int
 }
 
+// DrainWizardData holds data for the drain progress wizard
+type DrainWizardData struct {
+	Server string
+	NodeID string
+	Mode   string // Empty when reopened for a drain already running
+}
+
 // DrainResultData holds the result of a drain operation for HTMX response
 type DrainResultData struct {
 	Success bool
@@ -174,6 +277,36 @@ func NewTemplates() (*Templates, error) {
 		return nil, err
 	}
 
+	t.drainWizardPartial, err = template.New("drain_wizard.html").ParseFS(templatesFS, "templates/drain_wizard.html")
+	if err != nil {
+		return nil, err
+	}
+
+	t.blocklistsPartial, err = template.New("blocklists.html").ParseFS(templatesFS, "templates/blocklists.html")
+	if err != nil {
+		return nil, err
+	}
+
+	t.usersPartial, err = template.New("users.html").ParseFS(templatesFS, "templates/users.html")
+	if err != nil {
+		return nil, err
+	}
+
+	t.routesPartial, err = template.New("routes.html").ParseFS(templatesFS, "templates/routes.html")
+	if err != nil {
+		return nil, err
+	}
+
+	t.regAnalyticsPartial, err = template.New("reganalytics.html").ParseFS(templatesFS, "templates/reganalytics.html")
+	if err != nil {
+		return nil, err
+	}
+
+	t.configPartial, err = template.New("config.html").ParseFS(templatesFS, "templates/config.html")
+	if err != nil {
+		return nil, err
+	}
+
 	return t, nil
 }
 
@@ -216,3 +349,33 @@ func (t *Templates) RenderSessions(w io.Writer, data TemplateData) error {
 func (t *Templates) RenderDrainModal(w io.Writer, data DrainModalData) error {
 	return t.drainModalPartial.Execute(w, data)
 }
+
+// RenderDrainWizard renders the drain progress wizard
+func (t *Templates) RenderDrainWizard(w io.Writer, data DrainWizardData) error {
+	return t.drainWizardPartial.Execute(w, data)
+}
+
+// RenderBlocklists renders the caller blocklists partial
+func (t *Templates) RenderBlocklists(w io.Writer, data TemplateData) error {
+	return t.blocklistsPartial.Execute(w, data)
+}
+
+// RenderUsers renders the user call features partial
+func (t *Templates) RenderUsers(w io.Writer, data TemplateData) error {
+	return t.usersPartial.Execute(w, data)
+}
+
+// RenderRoutes renders the routing statistics partial
+func (t *Templates) RenderRoutes(w io.Writer, data TemplateData) error {
+	return t.routesPartial.Execute(w, data)
+}
+
+// RenderRegAnalytics renders the registration analytics partial
+func (t *Templates) RenderRegAnalytics(w io.Writer, data TemplateData) error {
+	return t.regAnalyticsPartial.Execute(w, data)
+}
+
+// RenderConfig renders the configuration view partial
+func (t *Templates) RenderConfig(w io.Writer, data ConfigViewData) error {
+	return t.configPartial.Execute(w, data)
+}
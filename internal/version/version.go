@@ -0,0 +1,52 @@
+// Package version reports the build version of the switchboard binaries.
+package version
+
+import (
+	"runtime/debug"
+	"sync"
+)
+
+// Version is set at build time:
+//
+//	go build -ldflags "-X github.com/sebas/switchboard/internal/version.Version=v1.2.0"
+//
+// The Makefile sets it from git describe.
+var Version = "dev"
+
+var (
+	once     sync.Once
+	resolved string
+)
+
+// Get returns Version, or for development builds the VCS revision Go
+// embedded in the binary, e.g. "dev-1a2b3c4d5e6f" or "dev-1a2b3c4d5e6f-dirty".
+func Get() string {
+	once.Do(func() {
+		resolved = Version
+		if Version != "dev" {
+			return
+		}
+		info, ok := debug.ReadBuildInfo()
+		if !ok {
+			return
+		}
+		var rev, dirty string
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				rev = s.Value
+			case "vcs.modified":
+				if s.Value == "true" {
+					dirty = "-dirty"
+				}
+			}
+		}
+		if len(rev) > 12 {
+			rev = rev[:12]
+		}
+		if rev != "" {
+			resolved = Version + "-" + rev + dirty
+		}
+	})
+	return resolved
+}
@@ -0,0 +1,652 @@
+// Package client is a Go client for the signaling server HTTP API. It is
+// used by the switchboard UI and is the supported way for other programs
+// to query and control signaling servers.
+//
+// Response types are defined in api/types/v1. Requests the server answers
+// with an error status return an *APIError.
+package client
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	types "github.com/sebas/switchboard/api/types/v1"
+)
+
+// Drain modes for StartDrain
+const (
+	DrainGraceful   = "graceful"   // Wait for playback, keep failed sessions on the node
+	DrainAggressive = "aggressive" // Terminate sessions that fail to migrate
+)
+
+// DefaultTimeout is the HTTP timeout of clients created by NewClient
+const DefaultTimeout = 10 * time.Second
+
+// APIError is returned when the signaling server answers with an error status
+type APIError struct {
+	StatusCode int
+	Message    string // Error text from the response body
+}
+
+func (e *APIError) Error() string {
+	if e.Message == "" {
+		return fmt.Sprintf("unexpected status: %d", e.StatusCode)
+	}
+	return fmt.Sprintf("unexpected status: %d: %s", e.StatusCode, e.Message)
+}
+
+// IsNotFound reports whether err is an APIError with status 404 Not Found
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
+}
+
+// Client is an HTTP client for a signaling server API. Safe for concurrent use.
+type Client struct {
+	name       string
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewClient creates a new signaling API client. name identifies the
+// server to callers that talk to several (e.g. "signaling-1"); baseURL is
+// the API address, e.g. "http://localhost:8080".
+func NewClient(name, baseURL string) *Client {
+	return &Client{
+		name:    name,
+		baseURL: strings.TrimSuffix(baseURL, "/"),
+		httpClient: &http.Client{
+			Timeout: DefaultTimeout,
+		},
+	}
+}
+
+// SetHTTPClient replaces the HTTP client, e.g. for TLS settings or another timeout
+func (c *Client) SetHTTPClient(httpClient *http.Client) {
+	c.httpClient = httpClient
+}
+
+// SetToken sets a bearer token sent with every request, e.g. the server's
+// debug token for Dump
+func (c *Client) SetToken(token string) {
+	c.token = token
+}
+
+// Name returns the backend name
+func (c *Client) Name() string {
+	return c.name
+}
+
+// BaseURL returns the backend base URL
+func (c *Client) BaseURL() string {
+	return c.baseURL
+}
+
+// --- Health and stats ---
+
+// Health fetches health status from the signaling server
+func (c *Client) Health(ctx context.Context) (*types.HealthResponse, error) {
+	var health types.HealthResponse
+	if err := c.getJSON(ctx, "/api/v1/health", "health", &health); err != nil {
+		return nil, err
+	}
+	return &health, nil
+}
+
+// Stats fetches statistics from the signaling server
+func (c *Client) Stats(ctx context.Context) (*types.StatsResponse, error) {
+	var stats types.StatsResponse
+	if err := c.getJSON(ctx, "/api/v1/stats", "stats", &stats); err != nil {
+		return nil, err
+	}
+	return &stats, nil
+}
+
+// --- Registrations ---
+
+// Registrations fetches all registrations from the signaling server
+func (c *Client) Registrations(ctx context.Context) ([]types.Registration, error) {
+	var regs []types.Registration
+	if err := c.getJSON(ctx, "/api/v1/registrations", "registrations", &regs); err != nil {
+		return nil, err
+	}
+	return regs, nil
+}
+
+// Bindings fetches the bindings of one AOR (e.g. "sip:1001@switchboard.local")
+func (c *Client) Bindings(ctx context.Context, aor string) ([]types.Registration, error) {
+	var regs []types.Registration
+	if err := c.getJSON(ctx, "/api/v1/registrations/"+url.PathEscape(aor), "bindings", &regs); err != nil {
+		return nil, err
+	}
+	return regs, nil
+}
+
+// RemoveBinding removes a binding of an AOR; an empty bindingID removes
+// every binding of the AOR
+func (c *Client) RemoveBinding(ctx context.Context, aor, bindingID string) error {
+	path := "/api/v1/registrations/" + url.PathEscape(aor)
+	if bindingID != "" {
+		path += "?" + url.Values{"binding_id": {bindingID}}.Encode()
+	}
+	return c.send(ctx, http.MethodDelete, path, nil, "", nil)
+}
+
+// --- Dialogs and call control ---
+
+// Dialogs fetches all dialogs from the signaling server
+func (c *Client) Dialogs(ctx context.Context) ([]types.Dialog, error) {
+	var dialogs []types.Dialog
+	if err := c.getJSON(ctx, "/api/v1/dialogs", "dialogs", &dialogs); err != nil {
+		return nil, err
+	}
+	return dialogs, nil
+}
+
+// Dialog fetches one dialog by Call-ID
+func (c *Client) Dialog(ctx context.Context, callID string) (*types.Dialog, error) {
+	var dlg types.Dialog
+	if err := c.getJSON(ctx, "/api/v1/dialogs/"+url.PathEscape(callID), "dialog", &dlg); err != nil {
+		return nil, err
+	}
+	return &dlg, nil
+}
+
+// Calls fetches the active calls, one record per call with its legs
+func (c *Client) Calls(ctx context.Context) ([]types.Call, error) {
+	var calls []types.Call
+	if err := c.getJSON(ctx, "/api/v1/calls", "calls", &calls); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// Call fetches the call one of whose legs has the Call-ID
+func (c *Client) Call(ctx context.Context, callID string) (*types.Call, error) {
+	var call types.Call
+	if err := c.getJSON(ctx, "/api/v1/calls/"+url.PathEscape(callID), "call", &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// Bridges fetches the active B2BUA bridges with their packet counters
+func (c *Client) Bridges(ctx context.Context) ([]types.Bridge, error) {
+	var bridges []types.Bridge
+	if err := c.getJSON(ctx, "/api/v1/bridges", "bridges", &bridges); err != nil {
+		return nil, err
+	}
+	return bridges, nil
+}
+
+// Bridge fetches one active bridge by ID
+func (c *Client) Bridge(ctx context.Context, id string) (*types.Bridge, error) {
+	var bridge types.Bridge
+	if err := c.getJSON(ctx, "/api/v1/bridges/"+url.PathEscape(id), "bridge", &bridge); err != nil {
+		return nil, err
+	}
+	return &bridge, nil
+}
+
+// KPIs fetches the per-route call KPIs of the last since, in buckets of
+// bucket. Zero durations use the server's defaults (24h, 1h); an empty
+// route reports every route.
+func (c *Client) KPIs(ctx context.Context, since, bucket time.Duration, route string) (*types.KPIReport, error) {
+	q := url.Values{}
+	if since > 0 {
+		q.Set("since", since.String())
+	}
+	if bucket > 0 {
+		q.Set("bucket", bucket.String())
+	}
+	if route != "" {
+		q.Set("route", route)
+	}
+	path := "/api/v1/kpis"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	var report types.KPIReport
+	if err := c.getJSON(ctx, path, "KPIs", &report); err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
+
+// RouteStats fetches the call outcomes per dialplan rule and trunk. Kind
+// is "rule", "trunk" or empty for both.
+func (c *Client) RouteStats(ctx context.Context, kind string) (*types.RouteStatsList, error) {
+	path := "/api/v1/routing/stats"
+	if kind != "" {
+		path += "?kind=" + url.QueryEscape(kind)
+	}
+	var list types.RouteStatsList
+	if err := c.getJSON(ctx, path, "routing stats", &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+// ResetRouteStats forgets the statistics of a route, of every route of a
+// kind when name is empty, or of all routes when both are empty.
+func (c *Client) ResetRouteStats(ctx context.Context, kind, name string) error {
+	q := url.Values{}
+	if kind != "" {
+		q.Set("kind", kind)
+	}
+	if name != "" {
+		q.Set("name", name)
+	}
+	path := "/api/v1/routing/stats"
+	if len(q) > 0 {
+		path += "?" + q.Encode()
+	}
+	return c.send(ctx, http.MethodDelete, path, nil, "", nil)
+}
+
+// Config fetches the signaling server's effective configuration, secrets
+// redacted
+func (c *Client) Config(ctx context.Context) (*types.ConfigResponse, error) {
+	var cfg types.ConfigResponse
+	if err := c.getJSON(ctx, "/api/v1/config", "config", &cfg); err != nil {
+		return nil, err
+	}
+	return &cfg, nil
+}
+
+// Hangup ends an answered call by sending BYE on its dialog
+func (c *Client) Hangup(ctx context.Context, callID string) error {
+	return c.send(ctx, http.MethodDelete, "/api/v1/dialogs/"+url.PathEscape(callID), nil, "", nil)
+}
+
+// Sessions fetches all RTP sessions from the signaling server
+func (c *Client) Sessions(ctx context.Context) ([]types.Session, error) {
+	var sessions []types.Session
+	if err := c.getJSON(ctx, "/api/v1/sessions", "sessions", &sessions); err != nil {
+		return nil, err
+	}
+	return sessions, nil
+}
+
+// Originate places a test call that plays a tone or file once answered
+// and hangs up after the requested duration. It returns once the call is
+// answered; a failed dial returns an *APIError with the SIP failure.
+func (c *Client) Originate(ctx context.Context, req types.OriginateRequest) (*types.OriginateResponse, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	var call types.OriginateResponse
+	if err := c.send(ctx, http.MethodPost, "/api/v1/calls", body, "call", &call); err != nil {
+		return nil, err
+	}
+	return &call, nil
+}
+
+// StartCall places a test call like Originate but returns at once with
+// its progress; follow it with Call or the request's callback URL.
+func (c *Client) StartCall(ctx context.Context, req types.OriginateRequest) (*types.CallProgress, error) {
+	req.Async = true
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("encode request: %w", err)
+	}
+	var progress types.CallProgress
+	if err := c.send(ctx, http.MethodPost, "/api/v1/calls", body, "call", &progress); err != nil {
+		return nil, err
+	}
+	return &progress, nil
+}
+
+// --- Events ---
+
+// Events streams call events, calling fn for each, until ctx is canceled
+// or the server ends the stream. eventTypes limits the stream to those
+// types (e.g. "call.ended"); all events are sent when it is empty. The
+// client timeout does not apply to the stream.
+func (c *Client) Events(ctx context.Context, eventTypes []string, fn func(types.Event)) error {
+	path := "/api/v1/events"
+	if len(eventTypes) > 0 {
+		path += "?" + url.Values{"type": eventTypes}.Encode()
+	}
+	return c.stream(ctx, path, func(data []byte) error {
+		var event types.Event
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		event.Raw = data
+		fn(event)
+		return nil
+	})
+}
+
+// stream reads a Server-Sent Events stream, calling fn with the data of
+// each event, until ctx is canceled, the server ends the stream or fn
+// fails. The client timeout does not apply.
+func (c *Client) stream(ctx context.Context, path string, fn func(data []byte) error) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	c.authorize(req)
+
+	stream := *c.httpClient
+	stream.Timeout = 0
+	resp, err := stream.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	// Server-Sent Events: "data:" lines up to a blank line make one event
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64<<10), 1<<20)
+	var data []byte
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			if len(data) > 0 {
+				if err := fn(data); err != nil {
+					return err
+				}
+				data = nil
+			}
+			continue
+		}
+		if rest, ok := bytes.CutPrefix(line, []byte("data:")); ok {
+			data = append(data, bytes.TrimPrefix(rest, []byte(" "))...)
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("read events: %w", err)
+	}
+	return nil
+}
+
+// --- RTP managers and drain ---
+
+// RtpManagers fetches RTP manager pool status from the signaling server
+func (c *Client) RtpManagers(ctx context.Context) (*types.RtpManagersResponse, error) {
+	var managers types.RtpManagersResponse
+	if err := c.getJSON(ctx, "/api/v1/rtpmanagers", "rtpmanagers", &managers); err != nil {
+		return nil, err
+	}
+	return &managers, nil
+}
+
+// StartDrain initiates a drain operation on an RTP manager node. mode is
+// DrainGraceful or DrainAggressive. The returned status only has the node,
+// mode and session count set; poll GetDrainStatus for progress.
+func (c *Client) StartDrain(ctx context.Context, nodeID, mode string) (*types.DrainStatus, error) {
+	path := fmt.Sprintf("/api/v1/rtpmanagers/%s/drain?%s", url.PathEscape(nodeID), url.Values{"mode": {mode}}.Encode())
+	var status types.DrainStatus
+	if err := c.send(ctx, http.MethodPost, path, nil, "drain status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// GetDrainStatus fetches the current drain status for an RTP manager node
+func (c *Client) GetDrainStatus(ctx context.Context, nodeID string) (*types.DrainStatus, error) {
+	path := fmt.Sprintf("/api/v1/rtpmanagers/%s/drain", url.PathEscape(nodeID))
+	var status types.DrainStatus
+	if err := c.getJSON(ctx, path, "drain status", &status); err != nil {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// CancelDrain cancels an in-progress drain operation
+func (c *Client) CancelDrain(ctx context.Context, nodeID string) error {
+	path := fmt.Sprintf("/api/v1/rtpmanagers/%s/drain", url.PathEscape(nodeID))
+	return c.send(ctx, http.MethodDelete, path, nil, "", nil)
+}
+
+// DrainEvents streams the drain events of an RTP manager node, calling fn
+// for each, until ctx is canceled or the server ends the stream. The first
+// event has type "status" and the node's current drain status. The client
+// timeout does not apply to the stream.
+func (c *Client) DrainEvents(ctx context.Context, nodeID string, fn func(types.DrainEvent)) error {
+	path := fmt.Sprintf("/api/v1/rtpmanagers/%s/drain/events", url.PathEscape(nodeID))
+	return c.stream(ctx, path, func(data []byte) error {
+		var event types.DrainEvent
+		if err := json.Unmarshal(data, &event); err != nil {
+			return fmt.Errorf("decode drain event: %w", err)
+		}
+		fn(event)
+		return nil
+	})
+}
+
+// RetryDrainSession migrates again a session that failed to migrate during
+// a drain. The outcome is reported by DrainEvents.
+func (c *Client) RetryDrainSession(ctx context.Context, nodeID, sessionID string) error {
+	path := fmt.Sprintf("/api/v1/rtpmanagers/%s/drain/sessions/%s/retry", url.PathEscape(nodeID), url.PathEscape(sessionID))
+	return c.send(ctx, http.MethodPost, path, nil, "", nil)
+}
+
+// --- Screening ---
+
+// ScreeningLists fetches caller blocklists from the signaling server
+func (c *Client) ScreeningLists(ctx context.Context) ([]types.ScreeningList, error) {
+	var lists []types.ScreeningList
+	if err := c.getJSON(ctx, "/api/v1/screening/lists", "screening lists", &lists); err != nil {
+		return nil, err
+	}
+	return lists, nil
+}
+
+// AddScreeningEntry adds an entry to a caller blocklist
+func (c *Client) AddScreeningEntry(ctx context.Context, list string, entry types.ScreeningEntry) error {
+	body, err := json.Marshal(entry)
+	if err != nil {
+		return fmt.Errorf("encode entry: %w", err)
+	}
+	path := fmt.Sprintf("/api/v1/screening/lists/%s/entries", url.PathEscape(list))
+	return c.send(ctx, http.MethodPost, path, body, "", nil)
+}
+
+// RemoveScreeningEntry removes an entry from a caller blocklist
+func (c *Client) RemoveScreeningEntry(ctx context.Context, list, match, pattern string) error {
+	q := url.Values{"match": {match}, "pattern": {pattern}}
+	path := fmt.Sprintf("/api/v1/screening/lists/%s/entries?%s", url.PathEscape(list), q.Encode())
+	return c.send(ctx, http.MethodDelete, path, nil, "", nil)
+}
+
+// --- User features ---
+
+// Users fetches users with call feature settings from the signaling server
+func (c *Client) Users(ctx context.Context) ([]types.UserFeatures, error) {
+	var users []types.UserFeatures
+	if err := c.getJSON(ctx, "/api/v1/users", "users", &users); err != nil {
+		return nil, err
+	}
+	return users, nil
+}
+
+// UserFeatures fetches a user's call features (defaults for users without
+// stored settings)
+func (c *Client) UserFeatures(ctx context.Context, user string) (*types.UserFeatures, error) {
+	var settings types.UserFeatures
+	if err := c.getJSON(ctx, userFeaturesPath(user), "user features", &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// UpdateUserFeatures changes only the given fields of a user's call
+// features, e.g. {"forward_always": "user/1003"}, and returns the saved settings
+func (c *Client) UpdateUserFeatures(ctx context.Context, user string, changes map[string]any) (*types.UserFeatures, error) {
+	body, err := json.Marshal(changes)
+	if err != nil {
+		return nil, fmt.Errorf("encode user features: %w", err)
+	}
+	var settings types.UserFeatures
+	if err := c.send(ctx, http.MethodPatch, userFeaturesPath(user), body, "user features", &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
+
+// SetDND turns a user's Do Not Disturb on or off
+func (c *Client) SetDND(ctx context.Context, user string, on bool) error {
+	_, err := c.UpdateUserFeatures(ctx, user, map[string]any{"dnd": on})
+	return err
+}
+
+func userFeaturesPath(user string) string {
+	return fmt.Sprintf("/api/v1/users/%s/features", url.PathEscape(user))
+}
+
+// --- Logging ---
+
+// LogLevels fetches the global log level and the module overrides
+func (c *Client) LogLevels(ctx context.Context) (*types.LogLevels, error) {
+	var levels types.LogLevels
+	if err := c.getJSON(ctx, "/api/v1/logging", "log levels", &levels); err != nil {
+		return nil, err
+	}
+	return &levels, nil
+}
+
+// SetLogLevel sets the global log level (debug, info, warn, error)
+func (c *Client) SetLogLevel(ctx context.Context, level string) (*types.LogLevels, error) {
+	return c.sendLogLevel(ctx, http.MethodPut, "/api/v1/logging", level)
+}
+
+// SetModuleLogLevel overrides the log level of one module, e.g. "dialog"
+// or "b2bua", until reset
+func (c *Client) SetModuleLogLevel(ctx context.Context, module, level string) (*types.LogLevels, error) {
+	return c.sendLogLevel(ctx, http.MethodPut, logModulePath(module), level)
+}
+
+// ResetModuleLogLevel removes a module override, so the module logs at the
+// global level again
+func (c *Client) ResetModuleLogLevel(ctx context.Context, module string) (*types.LogLevels, error) {
+	var levels types.LogLevels
+	if err := c.send(ctx, http.MethodDelete, logModulePath(module), nil, "log levels", &levels); err != nil {
+		return nil, err
+	}
+	return &levels, nil
+}
+
+func (c *Client) sendLogLevel(ctx context.Context, method, path, level string) (*types.LogLevels, error) {
+	body, err := json.Marshal(map[string]string{"level": level})
+	if err != nil {
+		return nil, fmt.Errorf("encode log level: %w", err)
+	}
+	var levels types.LogLevels
+	if err := c.send(ctx, method, path, body, "log levels", &levels); err != nil {
+		return nil, err
+	}
+	return &levels, nil
+}
+
+func logModulePath(module string) string {
+	return "/api/v1/logging/modules/" + url.PathEscape(module)
+}
+
+// --- Diagnostics ---
+
+// Dump kinds for Dump
+const (
+	DumpGoroutine = "goroutine" // Full goroutine stacks as text
+	DumpHeap      = "heap"      // Heap profile in pprof format
+)
+
+// Dump takes a goroutine or heap dump on the server and copies it to w.
+// The server must have debug endpoints enabled and the client its token
+// (see SetToken).
+func (c *Client) Dump(ctx context.Context, kind string, w io.Writer) error {
+	path := "/debug/dump?" + url.Values{"kind": {kind}}.Encode()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return fmt.Errorf("read %s dump: %w", kind, err)
+	}
+	return nil
+}
+
+// --- Transport ---
+
+// authorize adds the bearer token, if any, to req
+func (c *Client) authorize(req *http.Request) {
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+}
+
+// getJSON performs an HTTP GET request and decodes the response into out
+func (c *Client) getJSON(ctx context.Context, path, what string, out any) error {
+	return c.send(ctx, http.MethodGet, path, nil, what, out)
+}
+
+// send performs an HTTP request, with a JSON body when body is not nil,
+// and decodes the response into out when out is not nil. Any 2xx status
+// is success.
+func (c *Client) send(ctx context.Context, method, path string, body []byte, what string, out any) error {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	c.authorize(req)
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return &APIError{StatusCode: resp.StatusCode, Message: strings.TrimSpace(string(msg))}
+	}
+
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode %s: %w", what, err)
+	}
+	return nil
+}
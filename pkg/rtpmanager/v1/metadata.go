@@ -0,0 +1,6 @@
+package rtpmanagerv1
+
+// VersionHeader is the gRPC response header carrying the RTP manager's
+// build version. It is set on every unary response, so clients read it
+// from Health without a dedicated RPC.
+const VersionHeader = "x-switchboard-version"
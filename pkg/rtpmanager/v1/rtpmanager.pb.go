@@ -21,6 +21,224 @@ const (
 	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
 )
 
+// Who answered
+type AnsweredBy int32
+
+const (
+	AnsweredBy_ANSWERED_BY_UNSPECIFIED AnsweredBy = 0
+	AnsweredBy_ANSWERED_BY_HUMAN       AnsweredBy = 1
+	AnsweredBy_ANSWERED_BY_MACHINE     AnsweredBy = 2
+	// No decision within the analysis time
+	AnsweredBy_ANSWERED_BY_UNKNOWN AnsweredBy = 3
+)
+
+// Enum value maps for AnsweredBy.
+var (
+	AnsweredBy_name = map[int32]string{
+		0: "ANSWERED_BY_UNSPECIFIED",
+		1: "ANSWERED_BY_HUMAN",
+		2: "ANSWERED_BY_MACHINE",
+		3: "ANSWERED_BY_UNKNOWN",
+	}
+	AnsweredBy_value = map[string]int32{
+		"ANSWERED_BY_UNSPECIFIED": 0,
+		"ANSWERED_BY_HUMAN":       1,
+		"ANSWERED_BY_MACHINE":     2,
+		"ANSWERED_BY_UNKNOWN":     3,
+	}
+)
+
+func (x AnsweredBy) Enum() *AnsweredBy {
+	p := new(AnsweredBy)
+	*p = x
+	return p
+}
+
+func (x AnsweredBy) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AnsweredBy) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[0].Descriptor()
+}
+
+func (AnsweredBy) Type() protoreflect.EnumType {
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[0]
+}
+
+func (x AnsweredBy) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AnsweredBy.Descriptor instead.
+func (AnsweredBy) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{0}
+}
+
+type PlaybackControl int32
+
+const (
+	// Report position without changing playback
+	PlaybackControl_PLAYBACK_CONTROL_UNSPECIFIED PlaybackControl = 0
+	PlaybackControl_PLAYBACK_CONTROL_PAUSE       PlaybackControl = 1
+	PlaybackControl_PLAYBACK_CONTROL_RESUME      PlaybackControl = 2
+	// Jump to position_ms from the start
+	PlaybackControl_PLAYBACK_CONTROL_SEEK PlaybackControl = 3
+	// Jump by position_ms relative to the current position (may be negative)
+	PlaybackControl_PLAYBACK_CONTROL_SKIP PlaybackControl = 4
+)
+
+// Enum value maps for PlaybackControl.
+var (
+	PlaybackControl_name = map[int32]string{
+		0: "PLAYBACK_CONTROL_UNSPECIFIED",
+		1: "PLAYBACK_CONTROL_PAUSE",
+		2: "PLAYBACK_CONTROL_RESUME",
+		3: "PLAYBACK_CONTROL_SEEK",
+		4: "PLAYBACK_CONTROL_SKIP",
+	}
+	PlaybackControl_value = map[string]int32{
+		"PLAYBACK_CONTROL_UNSPECIFIED": 0,
+		"PLAYBACK_CONTROL_PAUSE":       1,
+		"PLAYBACK_CONTROL_RESUME":      2,
+		"PLAYBACK_CONTROL_SEEK":        3,
+		"PLAYBACK_CONTROL_SKIP":        4,
+	}
+)
+
+func (x PlaybackControl) Enum() *PlaybackControl {
+	p := new(PlaybackControl)
+	*p = x
+	return p
+}
+
+func (x PlaybackControl) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (PlaybackControl) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[1].Descriptor()
+}
+
+func (PlaybackControl) Type() protoreflect.EnumType {
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[1]
+}
+
+func (x PlaybackControl) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use PlaybackControl.Descriptor instead.
+func (PlaybackControl) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{1}
+}
+
+type AudioEncoding int32
+
+const (
+	AudioEncoding_AUDIO_ENCODING_UNSPECIFIED AudioEncoding = 0
+	// Signed 16-bit little-endian mono PCM at sample_rate (resampled to 8 kHz)
+	AudioEncoding_AUDIO_ENCODING_PCM_S16LE AudioEncoding = 1
+	// G.711 µ-law at 8 kHz, sent without transcoding
+	AudioEncoding_AUDIO_ENCODING_PCMU AudioEncoding = 2
+)
+
+// Enum value maps for AudioEncoding.
+var (
+	AudioEncoding_name = map[int32]string{
+		0: "AUDIO_ENCODING_UNSPECIFIED",
+		1: "AUDIO_ENCODING_PCM_S16LE",
+		2: "AUDIO_ENCODING_PCMU",
+	}
+	AudioEncoding_value = map[string]int32{
+		"AUDIO_ENCODING_UNSPECIFIED": 0,
+		"AUDIO_ENCODING_PCM_S16LE":   1,
+		"AUDIO_ENCODING_PCMU":        2,
+	}
+)
+
+func (x AudioEncoding) Enum() *AudioEncoding {
+	p := new(AudioEncoding)
+	*p = x
+	return p
+}
+
+func (x AudioEncoding) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (AudioEncoding) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[2].Descriptor()
+}
+
+func (AudioEncoding) Type() protoreflect.EnumType {
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[2]
+}
+
+func (x AudioEncoding) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use AudioEncoding.Descriptor instead.
+func (AudioEncoding) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{2}
+}
+
+type CaptureDirection int32
+
+const (
+	// Treated as BOTH
+	CaptureDirection_CAPTURE_DIRECTION_UNSPECIFIED CaptureDirection = 0
+	// Audio received from the session's remote party
+	CaptureDirection_CAPTURE_DIRECTION_INBOUND CaptureDirection = 1
+	// Audio sent to the session's remote party
+	CaptureDirection_CAPTURE_DIRECTION_OUTBOUND CaptureDirection = 2
+	CaptureDirection_CAPTURE_DIRECTION_BOTH     CaptureDirection = 3
+)
+
+// Enum value maps for CaptureDirection.
+var (
+	CaptureDirection_name = map[int32]string{
+		0: "CAPTURE_DIRECTION_UNSPECIFIED",
+		1: "CAPTURE_DIRECTION_INBOUND",
+		2: "CAPTURE_DIRECTION_OUTBOUND",
+		3: "CAPTURE_DIRECTION_BOTH",
+	}
+	CaptureDirection_value = map[string]int32{
+		"CAPTURE_DIRECTION_UNSPECIFIED": 0,
+		"CAPTURE_DIRECTION_INBOUND":     1,
+		"CAPTURE_DIRECTION_OUTBOUND":    2,
+		"CAPTURE_DIRECTION_BOTH":        3,
+	}
+)
+
+func (x CaptureDirection) Enum() *CaptureDirection {
+	p := new(CaptureDirection)
+	*p = x
+	return p
+}
+
+func (x CaptureDirection) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (CaptureDirection) Descriptor() protoreflect.EnumDescriptor {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[3].Descriptor()
+}
+
+func (CaptureDirection) Type() protoreflect.EnumType {
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[3]
+}
+
+func (x CaptureDirection) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use CaptureDirection.Descriptor instead.
+func (CaptureDirection) EnumDescriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{3}
+}
+
 type SessionState int32
 
 const (
@@ -67,11 +285,11 @@ func (x SessionState) String() string {
 }
 
 func (SessionState) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[0].Descriptor()
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[4].Descriptor()
 }
 
 func (SessionState) Type() protoreflect.EnumType {
-	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[0]
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[4]
 }
 
 func (x SessionState) Number() protoreflect.EnumNumber {
@@ -80,7 +298,7 @@ func (x SessionState) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use SessionState.Descriptor instead.
 func (SessionState) EnumDescriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{0}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{4}
 }
 
 type TerminateReason int32
@@ -125,11 +343,11 @@ func (x TerminateReason) String() string {
 }
 
 func (TerminateReason) Descriptor() protoreflect.EnumDescriptor {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[1].Descriptor()
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[5].Descriptor()
 }
 
 func (TerminateReason) Type() protoreflect.EnumType {
-	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[1]
+	return &file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes[5]
 }
 
 func (x TerminateReason) Number() protoreflect.EnumNumber {
@@ -138,7 +356,7 @@ func (x TerminateReason) Number() protoreflect.EnumNumber {
 
 // Deprecated: Use TerminateReason.Descriptor instead.
 func (TerminateReason) EnumDescriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{1}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{5}
 }
 
 type CreateSessionRequest struct {
@@ -150,6 +368,13 @@ type CreateSessionRequest struct {
 	RemotePort int32  `protobuf:"varint,3,opt,name=remote_port,json=remotePort,proto3" json:"remote_port,omitempty"`
 	// Codecs offered by remote party (payload type strings: "0", "8", etc.)
 	OfferedCodecs []string `protobuf:"bytes,4,rep,name=offered_codecs,json=offeredCodecs,proto3" json:"offered_codecs,omitempty"`
+	// Peer address used to choose the advertised address when remote_addr
+	// is not known yet (e.g. the SIP destination of an outbound leg)
+	PeerAddr string `protobuf:"bytes,5,opt,name=peer_addr,json=peerAddr,proto3" json:"peer_addr,omitempty"`
+	// Signaling node that owns the session. If set, the owner is asked
+	// through Health whether the session is still in use once it has gone
+	// unbridged for a while (see SessionCheck).
+	Owner         string `protobuf:"bytes,6,opt,name=owner,proto3" json:"owner,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -212,6 +437,20 @@ func (x *CreateSessionRequest) GetOfferedCodecs() []string {
 	return nil
 }
 
+func (x *CreateSessionRequest) GetPeerAddr() string {
+	if x != nil {
+		return x.PeerAddr
+	}
+	return ""
+}
+
+func (x *CreateSessionRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
 type CreateSessionResponse struct {
 	state protoimpl.MessageState `protogen:"open.v1"`
 	// Unique session ID for subsequent calls
@@ -406,10 +645,18 @@ func (x *DestroySessionResponse) GetStatus() *SessionStatus {
 }
 
 type PlayAudioRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	FilePath      string                 `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
-	Loop          bool                   `protobuf:"varint,3,opt,name=loop,proto3" json:"loop,omitempty"`
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FilePath  string                 `protobuf:"bytes,2,opt,name=file_path,json=filePath,proto3" json:"file_path,omitempty"`
+	Loop      bool                   `protobuf:"varint,3,opt,name=loop,proto3" json:"loop,omitempty"`
+	// Further segments played after file_path without gaps, reported as a
+	// single playback (e.g. "you have" + "three" + "messages")
+	Playlist []string `protobuf:"bytes,4,rep,name=playlist,proto3" json:"playlist,omitempty"`
+	// Start playback this far into the audio, e.g. to restart a long
+	// announcement where it was stopped
+	StartMs int32 `protobuf:"varint,5,opt,name=start_ms,json=startMs,proto3" json:"start_ms,omitempty"`
+	// Report in-band DTMF digits received from the remote party during playback
+	ReportDtmf    bool `protobuf:"varint,6,opt,name=report_dtmf,json=reportDtmf,proto3" json:"report_dtmf,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -465,35 +712,63 @@ func (x *PlayAudioRequest) GetLoop() bool {
 	return false
 }
 
-type PlaybackEvent struct {
+func (x *PlayAudioRequest) GetPlaylist() []string {
+	if x != nil {
+		return x.Playlist
+	}
+	return nil
+}
+
+func (x *PlayAudioRequest) GetStartMs() int32 {
+	if x != nil {
+		return x.StartMs
+	}
+	return 0
+}
+
+func (x *PlayAudioRequest) GetReportDtmf() bool {
+	if x != nil {
+		return x.ReportDtmf
+	}
+	return false
+}
+
+type PlayToneRequest struct {
 	state     protoimpl.MessageState `protogen:"open.v1"`
 	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	// Types that are valid to be assigned to Event:
-	//
-	//	*PlaybackEvent_Started
-	//	*PlaybackEvent_Progress
-	//	*PlaybackEvent_Completed
-	//	*PlaybackEvent_Error
-	//	*PlaybackEvent_Stopped
-	Event         isPlaybackEvent_Event `protobuf_oneof:"event"`
+	// Tone name from the plan ("ringback", "busy", "congestion", "dial",
+	// "callwaiting"), "dtmf:<digits>", or a custom spec such as
+	// "440+480/2000,0/4000"
+	Tone string `protobuf:"bytes,2,opt,name=tone,proto3" json:"tone,omitempty"`
+	// Country tone plan (e.g. "us", "uk", "de"); the server default if empty
+	Country string `protobuf:"bytes,3,opt,name=country,proto3" json:"country,omitempty"`
+	// Stop after this long; 0 repeats cadenced tones until stopped
+	DurationMs int32 `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	// Report in-band DTMF digits received from the remote party during playback
+	ReportDtmf bool `protobuf:"varint,5,opt,name=report_dtmf,json=reportDtmf,proto3" json:"report_dtmf,omitempty"`
+	// Analyze the audio received from the remote party during playback and
+	// report whether a person or an answering machine answered, once
+	DetectMachine bool `protobuf:"varint,6,opt,name=detect_machine,json=detectMachine,proto3" json:"detect_machine,omitempty"`
+	// Detection settings; defaults apply to zero fields
+	MachineParams *MachineDetectionParams `protobuf:"bytes,7,opt,name=machine_params,json=machineParams,proto3" json:"machine_params,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlaybackEvent) Reset() {
-	*x = PlaybackEvent{}
+func (x *PlayToneRequest) Reset() {
+	*x = PlayToneRequest{}
 	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackEvent) String() string {
+func (x *PlayToneRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackEvent) ProtoMessage() {}
+func (*PlayToneRequest) ProtoMessage() {}
 
-func (x *PlaybackEvent) ProtoReflect() protoreflect.Message {
+func (x *PlayToneRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -505,126 +780,100 @@ func (x *PlaybackEvent) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackEvent.ProtoReflect.Descriptor instead.
-func (*PlaybackEvent) Descriptor() ([]byte, []int) {
+// Deprecated: Use PlayToneRequest.ProtoReflect.Descriptor instead.
+func (*PlayToneRequest) Descriptor() ([]byte, []int) {
 	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *PlaybackEvent) GetSessionId() string {
+func (x *PlayToneRequest) GetSessionId() string {
 	if x != nil {
 		return x.SessionId
 	}
 	return ""
 }
 
-func (x *PlaybackEvent) GetEvent() isPlaybackEvent_Event {
+func (x *PlayToneRequest) GetTone() string {
 	if x != nil {
-		return x.Event
+		return x.Tone
 	}
-	return nil
+	return ""
 }
 
-func (x *PlaybackEvent) GetStarted() *PlaybackStarted {
+func (x *PlayToneRequest) GetCountry() string {
 	if x != nil {
-		if x, ok := x.Event.(*PlaybackEvent_Started); ok {
-			return x.Started
-		}
+		return x.Country
 	}
-	return nil
+	return ""
 }
 
-func (x *PlaybackEvent) GetProgress() *PlaybackProgress {
+func (x *PlayToneRequest) GetDurationMs() int32 {
 	if x != nil {
-		if x, ok := x.Event.(*PlaybackEvent_Progress); ok {
-			return x.Progress
-		}
+		return x.DurationMs
 	}
-	return nil
+	return 0
 }
 
-func (x *PlaybackEvent) GetCompleted() *PlaybackCompleted {
+func (x *PlayToneRequest) GetReportDtmf() bool {
 	if x != nil {
-		if x, ok := x.Event.(*PlaybackEvent_Completed); ok {
-			return x.Completed
-		}
+		return x.ReportDtmf
 	}
-	return nil
+	return false
 }
 
-func (x *PlaybackEvent) GetError() *PlaybackError {
+func (x *PlayToneRequest) GetDetectMachine() bool {
 	if x != nil {
-		if x, ok := x.Event.(*PlaybackEvent_Error); ok {
-			return x.Error
-		}
+		return x.DetectMachine
 	}
-	return nil
+	return false
 }
 
-func (x *PlaybackEvent) GetStopped() *PlaybackStopped {
+func (x *PlayToneRequest) GetMachineParams() *MachineDetectionParams {
 	if x != nil {
-		if x, ok := x.Event.(*PlaybackEvent_Stopped); ok {
-			return x.Stopped
-		}
+		return x.MachineParams
 	}
 	return nil
 }
 
-type isPlaybackEvent_Event interface {
-	isPlaybackEvent_Event()
-}
-
-type PlaybackEvent_Started struct {
-	Started *PlaybackStarted `protobuf:"bytes,2,opt,name=started,proto3,oneof"`
-}
-
-type PlaybackEvent_Progress struct {
-	Progress *PlaybackProgress `protobuf:"bytes,3,opt,name=progress,proto3,oneof"`
-}
-
-type PlaybackEvent_Completed struct {
-	Completed *PlaybackCompleted `protobuf:"bytes,4,opt,name=completed,proto3,oneof"`
-}
-
-type PlaybackEvent_Error struct {
-	Error *PlaybackError `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
-}
-
-type PlaybackEvent_Stopped struct {
-	Stopped *PlaybackStopped `protobuf:"bytes,6,opt,name=stopped,proto3,oneof"`
-}
-
-func (*PlaybackEvent_Started) isPlaybackEvent_Event() {}
-
-func (*PlaybackEvent_Progress) isPlaybackEvent_Event() {}
-
-func (*PlaybackEvent_Completed) isPlaybackEvent_Event() {}
-
-func (*PlaybackEvent_Error) isPlaybackEvent_Event() {}
-
-func (*PlaybackEvent_Stopped) isPlaybackEvent_Event() {}
-
-type PlaybackStarted struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	TotalFrames   int32                  `protobuf:"varint,1,opt,name=total_frames,json=totalFrames,proto3" json:"total_frames,omitempty"`
-	DurationMs    int32                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *PlaybackStarted) Reset() {
-	*x = PlaybackStarted{}
+// Answering machine detection settings, in milliseconds. A greeting is
+// timed by the voice and silence in the received audio: a person says a
+// few short words and waits, a machine talks longer or says more.
+type MachineDetectionParams struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Silence before any voice after which the call is a machine (default 2500)
+	InitialSilenceMs int32 `protobuf:"varint,1,opt,name=initial_silence_ms,json=initialSilenceMs,proto3" json:"initial_silence_ms,omitempty"`
+	// Voice longer than this makes the call a machine (default 1500)
+	GreetingMs int32 `protobuf:"varint,2,opt,name=greeting_ms,json=greetingMs,proto3" json:"greeting_ms,omitempty"`
+	// Silence after the greeting that makes the call a person (default 800)
+	AfterGreetingSilenceMs int32 `protobuf:"varint,3,opt,name=after_greeting_silence_ms,json=afterGreetingSilenceMs,proto3" json:"after_greeting_silence_ms,omitempty"`
+	// Analysis time after which no decision is made (default 5000)
+	TotalAnalysisMs int32 `protobuf:"varint,4,opt,name=total_analysis_ms,json=totalAnalysisMs,proto3" json:"total_analysis_ms,omitempty"`
+	// Shortest voice counted as a word (default 100)
+	MinWordLengthMs int32 `protobuf:"varint,5,opt,name=min_word_length_ms,json=minWordLengthMs,proto3" json:"min_word_length_ms,omitempty"`
+	// Silence that separates words (default 50)
+	BetweenWordsSilenceMs int32 `protobuf:"varint,6,opt,name=between_words_silence_ms,json=betweenWordsSilenceMs,proto3" json:"between_words_silence_ms,omitempty"`
+	// Words that make the call a machine (default 3)
+	MaximumWords int32 `protobuf:"varint,7,opt,name=maximum_words,json=maximumWords,proto3" json:"maximum_words,omitempty"`
+	// Average sample magnitude (16-bit PCM) below which a frame is silence
+	// (default 256)
+	SilenceThreshold int32 `protobuf:"varint,8,opt,name=silence_threshold,json=silenceThreshold,proto3" json:"silence_threshold,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *MachineDetectionParams) Reset() {
+	*x = MachineDetectionParams{}
 	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackStarted) String() string {
+func (x *MachineDetectionParams) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackStarted) ProtoMessage() {}
+func (*MachineDetectionParams) ProtoMessage() {}
 
-func (x *PlaybackStarted) ProtoReflect() protoreflect.Message {
+func (x *MachineDetectionParams) ProtoReflect() protoreflect.Message {
 	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -636,48 +885,1541 @@ func (x *PlaybackStarted) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackStarted.ProtoReflect.Descriptor instead.
-func (*PlaybackStarted) Descriptor() ([]byte, []int) {
+// Deprecated: Use MachineDetectionParams.ProtoReflect.Descriptor instead.
+func (*MachineDetectionParams) Descriptor() ([]byte, []int) {
 	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *PlaybackStarted) GetTotalFrames() int32 {
+func (x *MachineDetectionParams) GetInitialSilenceMs() int32 {
 	if x != nil {
-		return x.TotalFrames
+		return x.InitialSilenceMs
 	}
 	return 0
 }
 
-func (x *PlaybackStarted) GetDurationMs() int32 {
+func (x *MachineDetectionParams) GetGreetingMs() int32 {
 	if x != nil {
-		return x.DurationMs
+		return x.GreetingMs
 	}
 	return 0
 }
 
-type PlaybackProgress struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	FramesSent      int32                  `protobuf:"varint,1,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
-	PercentComplete float32                `protobuf:"fixed32,2,opt,name=percent_complete,json=percentComplete,proto3" json:"percent_complete,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
-}
+func (x *MachineDetectionParams) GetAfterGreetingSilenceMs() int32 {
+	if x != nil {
+		return x.AfterGreetingSilenceMs
+	}
+	return 0
+}
+
+func (x *MachineDetectionParams) GetTotalAnalysisMs() int32 {
+	if x != nil {
+		return x.TotalAnalysisMs
+	}
+	return 0
+}
+
+func (x *MachineDetectionParams) GetMinWordLengthMs() int32 {
+	if x != nil {
+		return x.MinWordLengthMs
+	}
+	return 0
+}
+
+func (x *MachineDetectionParams) GetBetweenWordsSilenceMs() int32 {
+	if x != nil {
+		return x.BetweenWordsSilenceMs
+	}
+	return 0
+}
+
+func (x *MachineDetectionParams) GetMaximumWords() int32 {
+	if x != nil {
+		return x.MaximumWords
+	}
+	return 0
+}
+
+func (x *MachineDetectionParams) GetSilenceThreshold() int32 {
+	if x != nil {
+		return x.SilenceThreshold
+	}
+	return 0
+}
+
+type PlaybackEvent struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//
+	//	*PlaybackEvent_Started
+	//	*PlaybackEvent_Progress
+	//	*PlaybackEvent_Completed
+	//	*PlaybackEvent_Error
+	//	*PlaybackEvent_Stopped
+	//	*PlaybackEvent_Dtmf
+	//	*PlaybackEvent_Machine
+	Event         isPlaybackEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaybackEvent) Reset() {
+	*x = PlaybackEvent{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackEvent) ProtoMessage() {}
+
+func (x *PlaybackEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackEvent.ProtoReflect.Descriptor instead.
+func (*PlaybackEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *PlaybackEvent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *PlaybackEvent) GetEvent() isPlaybackEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetStarted() *PlaybackStarted {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Started); ok {
+			return x.Started
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetProgress() *PlaybackProgress {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Progress); ok {
+			return x.Progress
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetCompleted() *PlaybackCompleted {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Completed); ok {
+			return x.Completed
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetError() *PlaybackError {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Error); ok {
+			return x.Error
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetStopped() *PlaybackStopped {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Stopped); ok {
+			return x.Stopped
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetDtmf() *DTMFReceived {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Dtmf); ok {
+			return x.Dtmf
+		}
+	}
+	return nil
+}
+
+func (x *PlaybackEvent) GetMachine() *MachineDetected {
+	if x != nil {
+		if x, ok := x.Event.(*PlaybackEvent_Machine); ok {
+			return x.Machine
+		}
+	}
+	return nil
+}
+
+type isPlaybackEvent_Event interface {
+	isPlaybackEvent_Event()
+}
+
+type PlaybackEvent_Started struct {
+	Started *PlaybackStarted `protobuf:"bytes,2,opt,name=started,proto3,oneof"`
+}
+
+type PlaybackEvent_Progress struct {
+	Progress *PlaybackProgress `protobuf:"bytes,3,opt,name=progress,proto3,oneof"`
+}
+
+type PlaybackEvent_Completed struct {
+	Completed *PlaybackCompleted `protobuf:"bytes,4,opt,name=completed,proto3,oneof"`
+}
+
+type PlaybackEvent_Error struct {
+	Error *PlaybackError `protobuf:"bytes,5,opt,name=error,proto3,oneof"`
+}
+
+type PlaybackEvent_Stopped struct {
+	Stopped *PlaybackStopped `protobuf:"bytes,6,opt,name=stopped,proto3,oneof"`
+}
+
+type PlaybackEvent_Dtmf struct {
+	Dtmf *DTMFReceived `protobuf:"bytes,7,opt,name=dtmf,proto3,oneof"`
+}
+
+type PlaybackEvent_Machine struct {
+	Machine *MachineDetected `protobuf:"bytes,8,opt,name=machine,proto3,oneof"`
+}
+
+func (*PlaybackEvent_Started) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Progress) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Completed) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Error) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Stopped) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Dtmf) isPlaybackEvent_Event() {}
+
+func (*PlaybackEvent_Machine) isPlaybackEvent_Event() {}
+
+type PlaybackStarted struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	TotalFrames   int32                  `protobuf:"varint,1,opt,name=total_frames,json=totalFrames,proto3" json:"total_frames,omitempty"`
+	DurationMs    int32                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaybackStarted) Reset() {
+	*x = PlaybackStarted{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackStarted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackStarted) ProtoMessage() {}
+
+func (x *PlaybackStarted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackStarted.ProtoReflect.Descriptor instead.
+func (*PlaybackStarted) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *PlaybackStarted) GetTotalFrames() int32 {
+	if x != nil {
+		return x.TotalFrames
+	}
+	return 0
+}
+
+func (x *PlaybackStarted) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type PlaybackProgress struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	FramesSent      int32                  `protobuf:"varint,1,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	PercentComplete float32                `protobuf:"fixed32,2,opt,name=percent_complete,json=percentComplete,proto3" json:"percent_complete,omitempty"`
+	PositionMs      int32                  `protobuf:"varint,3,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	DurationMs      int32                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Paused          bool                   `protobuf:"varint,5,opt,name=paused,proto3" json:"paused,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PlaybackProgress) Reset() {
+	*x = PlaybackProgress{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackProgress) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackProgress) ProtoMessage() {}
+
+func (x *PlaybackProgress) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackProgress.ProtoReflect.Descriptor instead.
+func (*PlaybackProgress) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *PlaybackProgress) GetFramesSent() int32 {
+	if x != nil {
+		return x.FramesSent
+	}
+	return 0
+}
+
+func (x *PlaybackProgress) GetPercentComplete() float32 {
+	if x != nil {
+		return x.PercentComplete
+	}
+	return 0
+}
+
+func (x *PlaybackProgress) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+func (x *PlaybackProgress) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *PlaybackProgress) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+type PlaybackCompleted struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	TotalFramesSent int32                  `protobuf:"varint,1,opt,name=total_frames_sent,json=totalFramesSent,proto3" json:"total_frames_sent,omitempty"`
+	DurationMs      int32                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *PlaybackCompleted) Reset() {
+	*x = PlaybackCompleted{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackCompleted) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackCompleted) ProtoMessage() {}
+
+func (x *PlaybackCompleted) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackCompleted.ProtoReflect.Descriptor instead.
+func (*PlaybackCompleted) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *PlaybackCompleted) GetTotalFramesSent() int32 {
+	if x != nil {
+		return x.TotalFramesSent
+	}
+	return 0
+}
+
+func (x *PlaybackCompleted) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type PlaybackError struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
+	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaybackError) Reset() {
+	*x = PlaybackError{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackError) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackError) ProtoMessage() {}
+
+func (x *PlaybackError) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackError.ProtoReflect.Descriptor instead.
+func (*PlaybackError) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *PlaybackError) GetCode() string {
+	if x != nil {
+		return x.Code
+	}
+	return ""
+}
+
+func (x *PlaybackError) GetMessage() string {
+	if x != nil {
+		return x.Message
+	}
+	return ""
+}
+
+// A DTMF digit received from the session's remote party, reported when
+// the key press is first detected
+type DTMFReceived struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Digit         string                 `protobuf:"bytes,1,opt,name=digit,proto3" json:"digit,omitempty"` // "0"-"9", "*", "#", "A"-"D"
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DTMFReceived) Reset() {
+	*x = DTMFReceived{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DTMFReceived) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DTMFReceived) ProtoMessage() {}
+
+func (x *DTMFReceived) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DTMFReceived.ProtoReflect.Descriptor instead.
+func (*DTMFReceived) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *DTMFReceived) GetDigit() string {
+	if x != nil {
+		return x.Digit
+	}
+	return ""
+}
+
+// The result of answering machine detection, reported once per playback
+type MachineDetected struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	AnsweredBy AnsweredBy             `protobuf:"varint,1,opt,name=answered_by,json=answeredBy,proto3,enum=rtpmanager.v1.AnsweredBy" json:"answered_by,omitempty"`
+	// What decided it: "initial_silence", "long_greeting", "max_words",
+	// "after_greeting_silence" or "max_analysis_time"
+	Reason string `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	// Audio analyzed until the decision
+	DurationMs    int32 `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MachineDetected) Reset() {
+	*x = MachineDetected{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MachineDetected) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MachineDetected) ProtoMessage() {}
+
+func (x *MachineDetected) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MachineDetected.ProtoReflect.Descriptor instead.
+func (*MachineDetected) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *MachineDetected) GetAnsweredBy() AnsweredBy {
+	if x != nil {
+		return x.AnsweredBy
+	}
+	return AnsweredBy_ANSWERED_BY_UNSPECIFIED
+}
+
+func (x *MachineDetected) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *MachineDetected) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+type PlaybackStopped struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
+	FramesSent    int32                  `protobuf:"varint,2,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	PositionMs    int32                  `protobuf:"varint,3,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PlaybackStopped) Reset() {
+	*x = PlaybackStopped{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PlaybackStopped) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PlaybackStopped) ProtoMessage() {}
+
+func (x *PlaybackStopped) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PlaybackStopped.ProtoReflect.Descriptor instead.
+func (*PlaybackStopped) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *PlaybackStopped) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+func (x *PlaybackStopped) GetFramesSent() int32 {
+	if x != nil {
+		return x.FramesSent
+	}
+	return 0
+}
+
+func (x *PlaybackStopped) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+type StopAudioRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopAudioRequest) Reset() {
+	*x = StopAudioRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAudioRequest) ProtoMessage() {}
+
+func (x *StopAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAudioRequest.ProtoReflect.Descriptor instead.
+func (*StopAudioRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *StopAudioRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+type StopAudioResponse struct {
+	state      protoimpl.MessageState `protogen:"open.v1"`
+	SessionId  string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	WasPlaying bool                   `protobuf:"varint,2,opt,name=was_playing,json=wasPlaying,proto3" json:"was_playing,omitempty"`
+	// Position the playback was stopped at; pass as start_ms to resume
+	PositionMs    int32 `protobuf:"varint,3,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StopAudioResponse) Reset() {
+	*x = StopAudioResponse{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StopAudioResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StopAudioResponse) ProtoMessage() {}
+
+func (x *StopAudioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StopAudioResponse.ProtoReflect.Descriptor instead.
+func (*StopAudioResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *StopAudioResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *StopAudioResponse) GetWasPlaying() bool {
+	if x != nil {
+		return x.WasPlaying
+	}
+	return false
+}
+
+func (x *StopAudioResponse) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+type ControlPlaybackRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Control       PlaybackControl        `protobuf:"varint,2,opt,name=control,proto3,enum=rtpmanager.v1.PlaybackControl" json:"control,omitempty"`
+	PositionMs    int32                  `protobuf:"varint,3,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlPlaybackRequest) Reset() {
+	*x = ControlPlaybackRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlPlaybackRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlPlaybackRequest) ProtoMessage() {}
+
+func (x *ControlPlaybackRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlPlaybackRequest.ProtoReflect.Descriptor instead.
+func (*ControlPlaybackRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ControlPlaybackRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ControlPlaybackRequest) GetControl() PlaybackControl {
+	if x != nil {
+		return x.Control
+	}
+	return PlaybackControl_PLAYBACK_CONTROL_UNSPECIFIED
+}
+
+func (x *ControlPlaybackRequest) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+type ControlPlaybackResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Paused        bool                   `protobuf:"varint,2,opt,name=paused,proto3" json:"paused,omitempty"`
+	PositionMs    int32                  `protobuf:"varint,3,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	DurationMs    int32                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Status        *SessionStatus         `protobuf:"bytes,5,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ControlPlaybackResponse) Reset() {
+	*x = ControlPlaybackResponse{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ControlPlaybackResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ControlPlaybackResponse) ProtoMessage() {}
+
+func (x *ControlPlaybackResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ControlPlaybackResponse.ProtoReflect.Descriptor instead.
+func (*ControlPlaybackResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *ControlPlaybackResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ControlPlaybackResponse) GetPaused() bool {
+	if x != nil {
+		return x.Paused
+	}
+	return false
+}
+
+func (x *ControlPlaybackResponse) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+func (x *ControlPlaybackResponse) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *ControlPlaybackResponse) GetStatus() *SessionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type InjectAudioRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Required on the first message, ignored afterwards
+	SessionId string        `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Encoding  AudioEncoding `protobuf:"varint,2,opt,name=encoding,proto3,enum=rtpmanager.v1.AudioEncoding" json:"encoding,omitempty"`
+	// Sample rate for PCM input in Hz (default 8000)
+	SampleRate int32 `protobuf:"varint,3,opt,name=sample_rate,json=sampleRate,proto3" json:"sample_rate,omitempty"`
+	// Audio data; may be any length, frames are split on the server
+	Payload       []byte `protobuf:"bytes,4,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InjectAudioRequest) Reset() {
+	*x = InjectAudioRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InjectAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InjectAudioRequest) ProtoMessage() {}
+
+func (x *InjectAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InjectAudioRequest.ProtoReflect.Descriptor instead.
+func (*InjectAudioRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *InjectAudioRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *InjectAudioRequest) GetEncoding() AudioEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return AudioEncoding_AUDIO_ENCODING_UNSPECIFIED
+}
+
+func (x *InjectAudioRequest) GetSampleRate() int32 {
+	if x != nil {
+		return x.SampleRate
+	}
+	return 0
+}
+
+func (x *InjectAudioRequest) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type InjectAudioResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	FramesSent    int32                  `protobuf:"varint,2,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	DurationMs    int32                  `protobuf:"varint,3,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Status        *SessionStatus         `protobuf:"bytes,4,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *InjectAudioResponse) Reset() {
+	*x = InjectAudioResponse{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *InjectAudioResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*InjectAudioResponse) ProtoMessage() {}
+
+func (x *InjectAudioResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use InjectAudioResponse.ProtoReflect.Descriptor instead.
+func (*InjectAudioResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *InjectAudioResponse) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *InjectAudioResponse) GetFramesSent() int32 {
+	if x != nil {
+		return x.FramesSent
+	}
+	return 0
+}
+
+func (x *InjectAudioResponse) GetDurationMs() int32 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *InjectAudioResponse) GetStatus() *SessionStatus {
+	if x != nil {
+		return x.Status
+	}
+	return nil
+}
+
+type CaptureAudioRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Direction CaptureDirection       `protobuf:"varint,2,opt,name=direction,proto3,enum=rtpmanager.v1.CaptureDirection" json:"direction,omitempty"`
+	// Output encoding; UNSPECIFIED and PCM_S16LE deliver decoded 8 kHz PCM,
+	// PCMU delivers the RTP payload as received
+	Encoding      AudioEncoding `protobuf:"varint,3,opt,name=encoding,proto3,enum=rtpmanager.v1.AudioEncoding" json:"encoding,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureAudioRequest) Reset() {
+	*x = CaptureAudioRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureAudioRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureAudioRequest) ProtoMessage() {}
+
+func (x *CaptureAudioRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureAudioRequest.ProtoReflect.Descriptor instead.
+func (*CaptureAudioRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *CaptureAudioRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *CaptureAudioRequest) GetDirection() CaptureDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return CaptureDirection_CAPTURE_DIRECTION_UNSPECIFIED
+}
+
+func (x *CaptureAudioRequest) GetEncoding() AudioEncoding {
+	if x != nil {
+		return x.Encoding
+	}
+	return AudioEncoding_AUDIO_ENCODING_UNSPECIFIED
+}
+
+type AudioFrame struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Direction CaptureDirection       `protobuf:"varint,2,opt,name=direction,proto3,enum=rtpmanager.v1.CaptureDirection" json:"direction,omitempty"`
+	// RTP header fields of the source packet, for ordering and loss detection
+	SequenceNumber uint32 `protobuf:"varint,3,opt,name=sequence_number,json=sequenceNumber,proto3" json:"sequence_number,omitempty"`
+	Timestamp      uint32 `protobuf:"varint,4,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	Payload        []byte `protobuf:"bytes,5,opt,name=payload,proto3" json:"payload,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *AudioFrame) Reset() {
+	*x = AudioFrame{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AudioFrame) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AudioFrame) ProtoMessage() {}
+
+func (x *AudioFrame) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AudioFrame.ProtoReflect.Descriptor instead.
+func (*AudioFrame) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *AudioFrame) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *AudioFrame) GetDirection() CaptureDirection {
+	if x != nil {
+		return x.Direction
+	}
+	return CaptureDirection_CAPTURE_DIRECTION_UNSPECIFIED
+}
+
+func (x *AudioFrame) GetSequenceNumber() uint32 {
+	if x != nil {
+		return x.SequenceNumber
+	}
+	return 0
+}
+
+func (x *AudioFrame) GetTimestamp() uint32 {
+	if x != nil {
+		return x.Timestamp
+	}
+	return 0
+}
+
+func (x *AudioFrame) GetPayload() []byte {
+	if x != nil {
+		return x.Payload
+	}
+	return nil
+}
+
+type ListSessionsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only sessions created by this signaling node; all sessions when empty
+	Owner         string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsRequest) Reset() {
+	*x = ListSessionsRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsRequest) ProtoMessage() {}
+
+func (x *ListSessionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsRequest.ProtoReflect.Descriptor instead.
+func (*ListSessionsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *ListSessionsRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+type ListSessionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Sessions      []*SessionSummary      `protobuf:"bytes,1,rep,name=sessions,proto3" json:"sessions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListSessionsResponse) Reset() {
+	*x = ListSessionsResponse{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListSessionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListSessionsResponse) ProtoMessage() {}
+
+func (x *ListSessionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListSessionsResponse.ProtoReflect.Descriptor instead.
+func (*ListSessionsResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *ListSessionsResponse) GetSessions() []*SessionSummary {
+	if x != nil {
+		return x.Sessions
+	}
+	return nil
+}
+
+type SessionSummary struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CallId        string                 `protobuf:"bytes,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	State         SessionState           `protobuf:"varint,3,opt,name=state,proto3,enum=rtpmanager.v1.SessionState" json:"state,omitempty"`
+	Owner         string                 `protobuf:"bytes,4,opt,name=owner,proto3" json:"owner,omitempty"`
+	AgeSeconds    int32                  `protobuf:"varint,5,opt,name=age_seconds,json=ageSeconds,proto3" json:"age_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionSummary) Reset() {
+	*x = SessionSummary{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionSummary) ProtoMessage() {}
+
+func (x *SessionSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionSummary.ProtoReflect.Descriptor instead.
+func (*SessionSummary) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *SessionSummary) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionSummary) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *SessionSummary) GetState() SessionState {
+	if x != nil {
+		return x.State
+	}
+	return SessionState_SESSION_STATE_UNSPECIFIED
+}
+
+func (x *SessionSummary) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *SessionSummary) GetAgeSeconds() int32 {
+	if x != nil {
+		return x.AgeSeconds
+	}
+	return 0
+}
+
+type ListBridgesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBridgesRequest) Reset() {
+	*x = ListBridgesRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBridgesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBridgesRequest) ProtoMessage() {}
+
+func (x *ListBridgesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBridgesRequest.ProtoReflect.Descriptor instead.
+func (*ListBridgesRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{26}
+}
+
+type ListBridgesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Bridges       []*BridgeSummary       `protobuf:"bytes,1,rep,name=bridges,proto3" json:"bridges,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListBridgesResponse) Reset() {
+	*x = ListBridgesResponse{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListBridgesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListBridgesResponse) ProtoMessage() {}
+
+func (x *ListBridgesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListBridgesResponse.ProtoReflect.Descriptor instead.
+func (*ListBridgesResponse) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *ListBridgesResponse) GetBridges() []*BridgeSummary {
+	if x != nil {
+		return x.Bridges
+	}
+	return nil
+}
+
+type BridgeSummary struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	BridgeId    string                 `protobuf:"bytes,1,opt,name=bridge_id,json=bridgeId,proto3" json:"bridge_id,omitempty"`
+	SessionAId  string                 `protobuf:"bytes,2,opt,name=session_a_id,json=sessionAId,proto3" json:"session_a_id,omitempty"`
+	SessionBId  string                 `protobuf:"bytes,3,opt,name=session_b_id,json=sessionBId,proto3" json:"session_b_id,omitempty"`
+	PacketsAToB int64                  `protobuf:"varint,4,opt,name=packets_a_to_b,json=packetsAToB,proto3" json:"packets_a_to_b,omitempty"` // Forwarded from session A to session B
+	PacketsBToA int64                  `protobuf:"varint,5,opt,name=packets_b_to_a,json=packetsBToA,proto3" json:"packets_b_to_a,omitempty"`
+	BytesAToB   int64                  `protobuf:"varint,6,opt,name=bytes_a_to_b,json=bytesAToB,proto3" json:"bytes_a_to_b,omitempty"`
+	BytesBToA   int64                  `protobuf:"varint,7,opt,name=bytes_b_to_a,json=bytesBToA,proto3" json:"bytes_b_to_a,omitempty"`
+	// Jitter buffer of each direction; unset when buffering is disabled
+	JitterAToB    *JitterBufferStats `protobuf:"bytes,8,opt,name=jitter_a_to_b,json=jitterAToB,proto3" json:"jitter_a_to_b,omitempty"`
+	JitterBToA    *JitterBufferStats `protobuf:"bytes,9,opt,name=jitter_b_to_a,json=jitterBToA,proto3" json:"jitter_b_to_a,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BridgeSummary) Reset() {
+	*x = BridgeSummary{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BridgeSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BridgeSummary) ProtoMessage() {}
+
+func (x *BridgeSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BridgeSummary.ProtoReflect.Descriptor instead.
+func (*BridgeSummary) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *BridgeSummary) GetBridgeId() string {
+	if x != nil {
+		return x.BridgeId
+	}
+	return ""
+}
+
+func (x *BridgeSummary) GetSessionAId() string {
+	if x != nil {
+		return x.SessionAId
+	}
+	return ""
+}
+
+func (x *BridgeSummary) GetSessionBId() string {
+	if x != nil {
+		return x.SessionBId
+	}
+	return ""
+}
 
-func (x *PlaybackProgress) Reset() {
-	*x = PlaybackProgress{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[7]
+func (x *BridgeSummary) GetPacketsAToB() int64 {
+	if x != nil {
+		return x.PacketsAToB
+	}
+	return 0
+}
+
+func (x *BridgeSummary) GetPacketsBToA() int64 {
+	if x != nil {
+		return x.PacketsBToA
+	}
+	return 0
+}
+
+func (x *BridgeSummary) GetBytesAToB() int64 {
+	if x != nil {
+		return x.BytesAToB
+	}
+	return 0
+}
+
+func (x *BridgeSummary) GetBytesBToA() int64 {
+	if x != nil {
+		return x.BytesBToA
+	}
+	return 0
+}
+
+func (x *BridgeSummary) GetJitterAToB() *JitterBufferStats {
+	if x != nil {
+		return x.JitterAToB
+	}
+	return nil
+}
+
+func (x *BridgeSummary) GetJitterBToA() *JitterBufferStats {
+	if x != nil {
+		return x.JitterBToA
+	}
+	return nil
+}
+
+type JitterBufferStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"` // Session whose received packets are buffered
+	Depth         int32                  `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`                         // Packets currently buffered
+	MaxDepth      int32                  `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`   // Highest depth observed
+	TargetDelayMs int32                  `protobuf:"varint,4,opt,name=target_delay_ms,json=targetDelayMs,proto3" json:"target_delay_ms,omitempty"`
+	JitterMs      int32                  `protobuf:"varint,5,opt,name=jitter_ms,json=jitterMs,proto3" json:"jitter_ms,omitempty"` // RFC 3550 interarrival jitter estimate
+	Late          int64                  `protobuf:"varint,6,opt,name=late,proto3" json:"late,omitempty"`                         // Dropped for arriving after their playout slot
+	Overflow      int64                  `protobuf:"varint,7,opt,name=overflow,proto3" json:"overflow,omitempty"`                 // Dropped because the buffer was full
+	Duplicates    int64                  `protobuf:"varint,8,opt,name=duplicates,proto3" json:"duplicates,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *JitterBufferStats) Reset() {
+	*x = JitterBufferStats{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[29]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackProgress) String() string {
+func (x *JitterBufferStats) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackProgress) ProtoMessage() {}
+func (*JitterBufferStats) ProtoMessage() {}
 
-func (x *PlaybackProgress) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[7]
+func (x *JitterBufferStats) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[29]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -688,48 +2430,91 @@ func (x *PlaybackProgress) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackProgress.ProtoReflect.Descriptor instead.
-func (*PlaybackProgress) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{7}
+// Deprecated: Use JitterBufferStats.ProtoReflect.Descriptor instead.
+func (*JitterBufferStats) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{29}
 }
 
-func (x *PlaybackProgress) GetFramesSent() int32 {
+func (x *JitterBufferStats) GetSessionId() string {
 	if x != nil {
-		return x.FramesSent
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *JitterBufferStats) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
 	}
 	return 0
 }
 
-func (x *PlaybackProgress) GetPercentComplete() float32 {
+func (x *JitterBufferStats) GetMaxDepth() int32 {
 	if x != nil {
-		return x.PercentComplete
+		return x.MaxDepth
 	}
 	return 0
 }
 
-type PlaybackCompleted struct {
-	state           protoimpl.MessageState `protogen:"open.v1"`
-	TotalFramesSent int32                  `protobuf:"varint,1,opt,name=total_frames_sent,json=totalFramesSent,proto3" json:"total_frames_sent,omitempty"`
-	DurationMs      int32                  `protobuf:"varint,2,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
-	unknownFields   protoimpl.UnknownFields
-	sizeCache       protoimpl.SizeCache
+func (x *JitterBufferStats) GetTargetDelayMs() int32 {
+	if x != nil {
+		return x.TargetDelayMs
+	}
+	return 0
 }
 
-func (x *PlaybackCompleted) Reset() {
-	*x = PlaybackCompleted{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[8]
+func (x *JitterBufferStats) GetJitterMs() int32 {
+	if x != nil {
+		return x.JitterMs
+	}
+	return 0
+}
+
+func (x *JitterBufferStats) GetLate() int64 {
+	if x != nil {
+		return x.Late
+	}
+	return 0
+}
+
+func (x *JitterBufferStats) GetOverflow() int64 {
+	if x != nil {
+		return x.Overflow
+	}
+	return 0
+}
+
+func (x *JitterBufferStats) GetDuplicates() int64 {
+	if x != nil {
+		return x.Duplicates
+	}
+	return 0
+}
+
+type SubscribeEventsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Only events of sessions created by this signaling node; all events
+	// when empty
+	Owner         string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SubscribeEventsRequest) Reset() {
+	*x = SubscribeEventsRequest{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[30]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackCompleted) String() string {
+func (x *SubscribeEventsRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackCompleted) ProtoMessage() {}
+func (*SubscribeEventsRequest) ProtoMessage() {}
 
-func (x *PlaybackCompleted) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[8]
+func (x *SubscribeEventsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[30]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -740,48 +2525,212 @@ func (x *PlaybackCompleted) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackCompleted.ProtoReflect.Descriptor instead.
-func (*PlaybackCompleted) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{8}
+// Deprecated: Use SubscribeEventsRequest.ProtoReflect.Descriptor instead.
+func (*SubscribeEventsRequest) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{30}
 }
 
-func (x *PlaybackCompleted) GetTotalFramesSent() int32 {
+func (x *SubscribeEventsRequest) GetOwner() string {
 	if x != nil {
-		return x.TotalFramesSent
+		return x.Owner
 	}
-	return 0
+	return ""
 }
 
-func (x *PlaybackCompleted) GetDurationMs() int32 {
+type SessionEvent struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	SessionId   string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CallId      string                 `protobuf:"bytes,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	TimestampMs int64                  `protobuf:"varint,3,opt,name=timestamp_ms,json=timestampMs,proto3" json:"timestamp_ms,omitempty"` // Unix time in milliseconds
+	// Types that are valid to be assigned to Event:
+	//
+	//	*SessionEvent_Created
+	//	*SessionEvent_Destroyed
+	//	*SessionEvent_PlaybackFinished
+	//	*SessionEvent_Dtmf
+	//	*SessionEvent_MediaTimeout
+	//	*SessionEvent_QualityAlert
+	Event         isSessionEvent_Event `protobuf_oneof:"event"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionEvent) Reset() {
+	*x = SessionEvent{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionEvent) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionEvent) ProtoMessage() {}
+
+func (x *SessionEvent) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[31]
 	if x != nil {
-		return x.DurationMs
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionEvent.ProtoReflect.Descriptor instead.
+func (*SessionEvent) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *SessionEvent) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionEvent) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *SessionEvent) GetTimestampMs() int64 {
+	if x != nil {
+		return x.TimestampMs
 	}
 	return 0
 }
 
-type PlaybackError struct {
+func (x *SessionEvent) GetEvent() isSessionEvent_Event {
+	if x != nil {
+		return x.Event
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetCreated() *SessionCreated {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_Created); ok {
+			return x.Created
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetDestroyed() *SessionDestroyed {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_Destroyed); ok {
+			return x.Destroyed
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetPlaybackFinished() *PlaybackFinished {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_PlaybackFinished); ok {
+			return x.PlaybackFinished
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetDtmf() *DTMFReceived {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_Dtmf); ok {
+			return x.Dtmf
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetMediaTimeout() *MediaTimeout {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_MediaTimeout); ok {
+			return x.MediaTimeout
+		}
+	}
+	return nil
+}
+
+func (x *SessionEvent) GetQualityAlert() *QualityAlert {
+	if x != nil {
+		if x, ok := x.Event.(*SessionEvent_QualityAlert); ok {
+			return x.QualityAlert
+		}
+	}
+	return nil
+}
+
+type isSessionEvent_Event interface {
+	isSessionEvent_Event()
+}
+
+type SessionEvent_Created struct {
+	Created *SessionCreated `protobuf:"bytes,4,opt,name=created,proto3,oneof"`
+}
+
+type SessionEvent_Destroyed struct {
+	Destroyed *SessionDestroyed `protobuf:"bytes,5,opt,name=destroyed,proto3,oneof"`
+}
+
+type SessionEvent_PlaybackFinished struct {
+	PlaybackFinished *PlaybackFinished `protobuf:"bytes,6,opt,name=playback_finished,json=playbackFinished,proto3,oneof"`
+}
+
+type SessionEvent_Dtmf struct {
+	Dtmf *DTMFReceived `protobuf:"bytes,7,opt,name=dtmf,proto3,oneof"`
+}
+
+type SessionEvent_MediaTimeout struct {
+	MediaTimeout *MediaTimeout `protobuf:"bytes,8,opt,name=media_timeout,json=mediaTimeout,proto3,oneof"`
+}
+
+type SessionEvent_QualityAlert struct {
+	QualityAlert *QualityAlert `protobuf:"bytes,9,opt,name=quality_alert,json=qualityAlert,proto3,oneof"`
+}
+
+func (*SessionEvent_Created) isSessionEvent_Event() {}
+
+func (*SessionEvent_Destroyed) isSessionEvent_Event() {}
+
+func (*SessionEvent_PlaybackFinished) isSessionEvent_Event() {}
+
+func (*SessionEvent_Dtmf) isSessionEvent_Event() {}
+
+func (*SessionEvent_MediaTimeout) isSessionEvent_Event() {}
+
+func (*SessionEvent_QualityAlert) isSessionEvent_Event() {}
+
+type SessionCreated struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Code          string                 `protobuf:"bytes,1,opt,name=code,proto3" json:"code,omitempty"`
-	Message       string                 `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	LocalAddr     string                 `protobuf:"bytes,1,opt,name=local_addr,json=localAddr,proto3" json:"local_addr,omitempty"`
+	LocalPort     int32                  `protobuf:"varint,2,opt,name=local_port,json=localPort,proto3" json:"local_port,omitempty"`
+	Codec         string                 `protobuf:"bytes,3,opt,name=codec,proto3" json:"codec,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlaybackError) Reset() {
-	*x = PlaybackError{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[9]
+func (x *SessionCreated) Reset() {
+	*x = SessionCreated{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[32]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackError) String() string {
+func (x *SessionCreated) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackError) ProtoMessage() {}
+func (*SessionCreated) ProtoMessage() {}
 
-func (x *PlaybackError) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[9]
+func (x *SessionCreated) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[32]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -792,48 +2741,54 @@ func (x *PlaybackError) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackError.ProtoReflect.Descriptor instead.
-func (*PlaybackError) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{9}
+// Deprecated: Use SessionCreated.ProtoReflect.Descriptor instead.
+func (*SessionCreated) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{32}
 }
 
-func (x *PlaybackError) GetCode() string {
+func (x *SessionCreated) GetLocalAddr() string {
 	if x != nil {
-		return x.Code
+		return x.LocalAddr
 	}
 	return ""
 }
 
-func (x *PlaybackError) GetMessage() string {
+func (x *SessionCreated) GetLocalPort() int32 {
 	if x != nil {
-		return x.Message
+		return x.LocalPort
+	}
+	return 0
+}
+
+func (x *SessionCreated) GetCodec() string {
+	if x != nil {
+		return x.Codec
 	}
 	return ""
 }
 
-type PlaybackStopped struct {
+type SessionDestroyed struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Reason        string                 `protobuf:"bytes,1,opt,name=reason,proto3" json:"reason,omitempty"`
-	FramesSent    int32                  `protobuf:"varint,2,opt,name=frames_sent,json=framesSent,proto3" json:"frames_sent,omitempty"`
+	Reason        TerminateReason        `protobuf:"varint,1,opt,name=reason,proto3,enum=rtpmanager.v1.TerminateReason" json:"reason,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *PlaybackStopped) Reset() {
-	*x = PlaybackStopped{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[10]
+func (x *SessionDestroyed) Reset() {
+	*x = SessionDestroyed{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[33]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *PlaybackStopped) String() string {
+func (x *SessionDestroyed) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*PlaybackStopped) ProtoMessage() {}
+func (*SessionDestroyed) ProtoMessage() {}
 
-func (x *PlaybackStopped) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[10]
+func (x *SessionDestroyed) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[33]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -844,47 +2799,43 @@ func (x *PlaybackStopped) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use PlaybackStopped.ProtoReflect.Descriptor instead.
-func (*PlaybackStopped) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{10}
-}
-
-func (x *PlaybackStopped) GetReason() string {
-	if x != nil {
-		return x.Reason
-	}
-	return ""
+// Deprecated: Use SessionDestroyed.ProtoReflect.Descriptor instead.
+func (*SessionDestroyed) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{33}
 }
 
-func (x *PlaybackStopped) GetFramesSent() int32 {
+func (x *SessionDestroyed) GetReason() TerminateReason {
 	if x != nil {
-		return x.FramesSent
+		return x.Reason
 	}
-	return 0
+	return TerminateReason_TERMINATE_REASON_UNSPECIFIED
 }
 
-type StopAudioRequest struct {
+// PlaybackFinished reports the end of a PlayAudio or PlayTone stream
+type PlaybackFinished struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Outcome       string                 `protobuf:"bytes,1,opt,name=outcome,proto3" json:"outcome,omitempty"` // "completed", "stopped" or "error"
+	PositionMs    int32                  `protobuf:"varint,2,opt,name=position_ms,json=positionMs,proto3" json:"position_ms,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopAudioRequest) Reset() {
-	*x = StopAudioRequest{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[11]
+func (x *PlaybackFinished) Reset() {
+	*x = PlaybackFinished{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[34]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopAudioRequest) String() string {
+func (x *PlaybackFinished) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopAudioRequest) ProtoMessage() {}
+func (*PlaybackFinished) ProtoMessage() {}
 
-func (x *StopAudioRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[11]
+func (x *PlaybackFinished) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[34]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -895,41 +2846,58 @@ func (x *StopAudioRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopAudioRequest.ProtoReflect.Descriptor instead.
-func (*StopAudioRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{11}
+// Deprecated: Use PlaybackFinished.ProtoReflect.Descriptor instead.
+func (*PlaybackFinished) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{34}
 }
 
-func (x *StopAudioRequest) GetSessionId() string {
+func (x *PlaybackFinished) GetOutcome() string {
 	if x != nil {
-		return x.SessionId
+		return x.Outcome
 	}
 	return ""
 }
 
-type StopAudioResponse struct {
+func (x *PlaybackFinished) GetPositionMs() int32 {
+	if x != nil {
+		return x.PositionMs
+	}
+	return 0
+}
+
+func (x *PlaybackFinished) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+// QualityAlert reports a bridged session whose media crossed a quality
+// threshold. Raised once until the value falls back below the threshold.
+type QualityAlert struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
-	WasPlaying    bool                   `protobuf:"varint,2,opt,name=was_playing,json=wasPlaying,proto3" json:"was_playing,omitempty"`
+	Metric        string                 `protobuf:"bytes,1,opt,name=metric,proto3" json:"metric,omitempty"` // "jitter" (milliseconds)
+	Value         float64                `protobuf:"fixed64,2,opt,name=value,proto3" json:"value,omitempty"`
+	Threshold     float64                `protobuf:"fixed64,3,opt,name=threshold,proto3" json:"threshold,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StopAudioResponse) Reset() {
-	*x = StopAudioResponse{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[12]
+func (x *QualityAlert) Reset() {
+	*x = QualityAlert{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[35]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StopAudioResponse) String() string {
+func (x *QualityAlert) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StopAudioResponse) ProtoMessage() {}
+func (*QualityAlert) ProtoMessage() {}
 
-func (x *StopAudioResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[12]
+func (x *QualityAlert) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[35]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -940,34 +2908,49 @@ func (x *StopAudioResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StopAudioResponse.ProtoReflect.Descriptor instead.
-func (*StopAudioResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{12}
+// Deprecated: Use QualityAlert.ProtoReflect.Descriptor instead.
+func (*QualityAlert) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{35}
 }
 
-func (x *StopAudioResponse) GetSessionId() string {
+func (x *QualityAlert) GetMetric() string {
 	if x != nil {
-		return x.SessionId
+		return x.Metric
 	}
 	return ""
 }
 
-func (x *StopAudioResponse) GetWasPlaying() bool {
+func (x *QualityAlert) GetValue() float64 {
 	if x != nil {
-		return x.WasPlaying
+		return x.Value
 	}
-	return false
+	return 0
+}
+
+func (x *QualityAlert) GetThreshold() float64 {
+	if x != nil {
+		return x.Threshold
+	}
+	return 0
 }
 
 type HealthRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Signaling node calling; session checks for its sessions are returned
+	Owner string `protobuf:"bytes,1,opt,name=owner,proto3" json:"owner,omitempty"`
+	// Sessions from the previous response's session_checks that the owner
+	// no longer knows; they are destroyed. The others are still in use.
+	OrphanedSessionIds []string `protobuf:"bytes,2,rep,name=orphaned_session_ids,json=orphanedSessionIds,proto3" json:"orphaned_session_ids,omitempty"`
+	// Session IDs of the previous response's media_timeouts, received by
+	// the owner. Media timeouts are returned until acknowledged.
+	AckedMediaTimeouts []string `protobuf:"bytes,3,rep,name=acked_media_timeouts,json=ackedMediaTimeouts,proto3" json:"acked_media_timeouts,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
 }
 
 func (x *HealthRequest) Reset() {
 	*x = HealthRequest{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[13]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[36]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -979,7 +2962,7 @@ func (x *HealthRequest) String() string {
 func (*HealthRequest) ProtoMessage() {}
 
 func (x *HealthRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[13]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[36]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -992,7 +2975,28 @@ func (x *HealthRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
 func (*HealthRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{13}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *HealthRequest) GetOwner() string {
+	if x != nil {
+		return x.Owner
+	}
+	return ""
+}
+
+func (x *HealthRequest) GetOrphanedSessionIds() []string {
+	if x != nil {
+		return x.OrphanedSessionIds
+	}
+	return nil
+}
+
+func (x *HealthRequest) GetAckedMediaTimeouts() []string {
+	if x != nil {
+		return x.AckedMediaTimeouts
+	}
+	return nil
 }
 
 type HealthResponse struct {
@@ -1000,13 +3004,33 @@ type HealthResponse struct {
 	Healthy        bool                   `protobuf:"varint,1,opt,name=healthy,proto3" json:"healthy,omitempty"`
 	ActiveSessions int32                  `protobuf:"varint,2,opt,name=active_sessions,json=activeSessions,proto3" json:"active_sessions,omitempty"`
 	AvailablePorts int32                  `protobuf:"varint,3,opt,name=available_ports,json=availablePorts,proto3" json:"available_ports,omitempty"`
+	// Sessions that hit the RTP inactivity timeout since the last Health call.
+	// Each timeout is reported once.
+	MediaTimeouts []*MediaTimeout `protobuf:"bytes,4,rep,name=media_timeouts,json=mediaTimeouts,proto3" json:"media_timeouts,omitempty"`
+	// Port allocation pressure (counts are RTP/RTCP pairs)
+	TotalPorts      int32 `protobuf:"varint,5,opt,name=total_ports,json=totalPorts,proto3" json:"total_ports,omitempty"`
+	AllocatedPorts  int32 `protobuf:"varint,6,opt,name=allocated_ports,json=allocatedPorts,proto3" json:"allocated_ports,omitempty"`
+	BusyPorts       int32 `protobuf:"varint,7,opt,name=busy_ports,json=busyPorts,proto3" json:"busy_ports,omitempty"`                   // Skipped: in use by another process
+	PortConflicts   int64 `protobuf:"varint,8,opt,name=port_conflicts,json=portConflicts,proto3" json:"port_conflicts,omitempty"`       // Pairs found in use by another process
+	PortExhaustions int64 `protobuf:"varint,9,opt,name=port_exhaustions,json=portExhaustions,proto3" json:"port_exhaustions,omitempty"` // Allocations that failed for lack of ports
+	// Sessions of the calling owner that were never bridged or updated
+	// within the orphan grace period. The owner answers with
+	// orphaned_session_ids on its next Health call.
+	SessionChecks []*SessionCheck `protobuf:"bytes,10,rep,name=session_checks,json=sessionChecks,proto3" json:"session_checks,omitempty"`
+	// Random ID chosen when the RTP manager starts. A new value tells
+	// signaling the manager restarted and the sessions it held are gone.
+	InstanceId string `protobuf:"bytes,11,opt,name=instance_id,json=instanceId,proto3" json:"instance_id,omitempty"`
+	// Set once the RTP manager was asked to drain (e.g. by a pre-stop hook
+	// on POST /drain). Signaling servers move their sessions off the node,
+	// as if the drain had been started through their API.
+	DrainRequested bool `protobuf:"varint,12,opt,name=drain_requested,json=drainRequested,proto3" json:"drain_requested,omitempty"`
 	unknownFields  protoimpl.UnknownFields
 	sizeCache      protoimpl.SizeCache
 }
 
 func (x *HealthResponse) Reset() {
 	*x = HealthResponse{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[14]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[37]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1018,7 +3042,7 @@ func (x *HealthResponse) String() string {
 func (*HealthResponse) ProtoMessage() {}
 
 func (x *HealthResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[14]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[37]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1031,7 +3055,7 @@ func (x *HealthResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
 func (*HealthResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{14}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{37}
 }
 
 func (x *HealthResponse) GetHealthy() bool {
@@ -1055,17 +3079,207 @@ func (x *HealthResponse) GetAvailablePorts() int32 {
 	return 0
 }
 
-type SessionStatus struct {
+func (x *HealthResponse) GetMediaTimeouts() []*MediaTimeout {
+	if x != nil {
+		return x.MediaTimeouts
+	}
+	return nil
+}
+
+func (x *HealthResponse) GetTotalPorts() int32 {
+	if x != nil {
+		return x.TotalPorts
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetAllocatedPorts() int32 {
+	if x != nil {
+		return x.AllocatedPorts
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetBusyPorts() int32 {
+	if x != nil {
+		return x.BusyPorts
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetPortConflicts() int64 {
+	if x != nil {
+		return x.PortConflicts
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetPortExhaustions() int64 {
+	if x != nil {
+		return x.PortExhaustions
+	}
+	return 0
+}
+
+func (x *HealthResponse) GetSessionChecks() []*SessionCheck {
+	if x != nil {
+		return x.SessionChecks
+	}
+	return nil
+}
+
+func (x *HealthResponse) GetInstanceId() string {
+	if x != nil {
+		return x.InstanceId
+	}
+	return ""
+}
+
+func (x *HealthResponse) GetDrainRequested() bool {
+	if x != nil {
+		return x.DrainRequested
+	}
+	return false
+}
+
+// SessionCheck asks the owner whether a session is still in use.
+type SessionCheck struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CallId        string                 `protobuf:"bytes,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	AgeSeconds    int32                  `protobuf:"varint,3,opt,name=age_seconds,json=ageSeconds,proto3" json:"age_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SessionCheck) Reset() {
+	*x = SessionCheck{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SessionCheck) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SessionCheck) ProtoMessage() {}
+
+func (x *SessionCheck) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SessionCheck.ProtoReflect.Descriptor instead.
+func (*SessionCheck) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *SessionCheck) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *SessionCheck) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *SessionCheck) GetAgeSeconds() int32 {
+	if x != nil {
+		return x.AgeSeconds
+	}
+	return 0
+}
+
+// MediaTimeout reports a session that has received no RTP within the
+// configured inactivity timeout (e.g. the endpoint lost power mid-call).
+type MediaTimeout struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	State         SessionState           `protobuf:"varint,1,opt,name=state,proto3,enum=rtpmanager.v1.SessionState" json:"state,omitempty"`
-	ErrorMessage  string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	CallId        string                 `protobuf:"bytes,2,opt,name=call_id,json=callId,proto3" json:"call_id,omitempty"`
+	IdleSeconds   int32                  `protobuf:"varint,3,opt,name=idle_seconds,json=idleSeconds,proto3" json:"idle_seconds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *MediaTimeout) Reset() {
+	*x = MediaTimeout{}
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *MediaTimeout) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*MediaTimeout) ProtoMessage() {}
+
+func (x *MediaTimeout) ProtoReflect() protoreflect.Message {
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use MediaTimeout.ProtoReflect.Descriptor instead.
+func (*MediaTimeout) Descriptor() ([]byte, []int) {
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *MediaTimeout) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *MediaTimeout) GetCallId() string {
+	if x != nil {
+		return x.CallId
+	}
+	return ""
+}
+
+func (x *MediaTimeout) GetIdleSeconds() int32 {
+	if x != nil {
+		return x.IdleSeconds
+	}
+	return 0
+}
+
+type SessionStatus struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	State        SessionState           `protobuf:"varint,1,opt,name=state,proto3,enum=rtpmanager.v1.SessionState" json:"state,omitempty"`
+	ErrorMessage string                 `protobuf:"bytes,2,opt,name=error_message,json=errorMessage,proto3" json:"error_message,omitempty"`
+	// Set with SESSION_STATE_ERROR when the request itself is refused, such
+	// as an offer without a supported codec: another RTP manager would
+	// refuse it too
+	Rejected      bool `protobuf:"varint,3,opt,name=rejected,proto3" json:"rejected,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SessionStatus) Reset() {
 	*x = SessionStatus{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[15]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[40]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1077,7 +3291,7 @@ func (x *SessionStatus) String() string {
 func (*SessionStatus) ProtoMessage() {}
 
 func (x *SessionStatus) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[15]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[40]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1090,7 +3304,7 @@ func (x *SessionStatus) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SessionStatus.ProtoReflect.Descriptor instead.
 func (*SessionStatus) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{15}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{40}
 }
 
 func (x *SessionStatus) GetState() SessionState {
@@ -1107,6 +3321,13 @@ func (x *SessionStatus) GetErrorMessage() string {
 	return ""
 }
 
+func (x *SessionStatus) GetRejected() bool {
+	if x != nil {
+		return x.Rejected
+	}
+	return false
+}
+
 type UpdateSessionRemoteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	SessionId     string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
@@ -1118,7 +3339,7 @@ type UpdateSessionRemoteRequest struct {
 
 func (x *UpdateSessionRemoteRequest) Reset() {
 	*x = UpdateSessionRemoteRequest{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[16]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[41]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1130,7 +3351,7 @@ func (x *UpdateSessionRemoteRequest) String() string {
 func (*UpdateSessionRemoteRequest) ProtoMessage() {}
 
 func (x *UpdateSessionRemoteRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[16]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[41]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1143,7 +3364,7 @@ func (x *UpdateSessionRemoteRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateSessionRemoteRequest.ProtoReflect.Descriptor instead.
 func (*UpdateSessionRemoteRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{16}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{41}
 }
 
 func (x *UpdateSessionRemoteRequest) GetSessionId() string {
@@ -1177,7 +3398,7 @@ type UpdateSessionRemoteResponse struct {
 
 func (x *UpdateSessionRemoteResponse) Reset() {
 	*x = UpdateSessionRemoteResponse{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[17]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[42]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1189,7 +3410,7 @@ func (x *UpdateSessionRemoteResponse) String() string {
 func (*UpdateSessionRemoteResponse) ProtoMessage() {}
 
 func (x *UpdateSessionRemoteResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[17]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[42]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1202,7 +3423,7 @@ func (x *UpdateSessionRemoteResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UpdateSessionRemoteResponse.ProtoReflect.Descriptor instead.
 func (*UpdateSessionRemoteResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{17}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{42}
 }
 
 func (x *UpdateSessionRemoteResponse) GetSessionId() string {
@@ -1231,7 +3452,7 @@ type BridgeMediaRequest struct {
 
 func (x *BridgeMediaRequest) Reset() {
 	*x = BridgeMediaRequest{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[18]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[43]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1243,7 +3464,7 @@ func (x *BridgeMediaRequest) String() string {
 func (*BridgeMediaRequest) ProtoMessage() {}
 
 func (x *BridgeMediaRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[18]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[43]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1256,7 +3477,7 @@ func (x *BridgeMediaRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BridgeMediaRequest.ProtoReflect.Descriptor instead.
 func (*BridgeMediaRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{18}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{43}
 }
 
 func (x *BridgeMediaRequest) GetSessionAId() string {
@@ -1284,7 +3505,7 @@ type BridgeMediaResponse struct {
 
 func (x *BridgeMediaResponse) Reset() {
 	*x = BridgeMediaResponse{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[19]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[44]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1296,7 +3517,7 @@ func (x *BridgeMediaResponse) String() string {
 func (*BridgeMediaResponse) ProtoMessage() {}
 
 func (x *BridgeMediaResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[19]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[44]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1309,7 +3530,7 @@ func (x *BridgeMediaResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use BridgeMediaResponse.ProtoReflect.Descriptor instead.
 func (*BridgeMediaResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{19}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{44}
 }
 
 func (x *BridgeMediaResponse) GetBridgeId() string {
@@ -1337,7 +3558,7 @@ type UnbridgeMediaRequest struct {
 
 func (x *UnbridgeMediaRequest) Reset() {
 	*x = UnbridgeMediaRequest{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[20]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[45]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1349,7 +3570,7 @@ func (x *UnbridgeMediaRequest) String() string {
 func (*UnbridgeMediaRequest) ProtoMessage() {}
 
 func (x *UnbridgeMediaRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[20]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[45]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1362,7 +3583,7 @@ func (x *UnbridgeMediaRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnbridgeMediaRequest.ProtoReflect.Descriptor instead.
 func (*UnbridgeMediaRequest) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{20}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{45}
 }
 
 func (x *UnbridgeMediaRequest) GetBridgeId() string {
@@ -1389,7 +3610,7 @@ type UnbridgeMediaResponse struct {
 
 func (x *UnbridgeMediaResponse) Reset() {
 	*x = UnbridgeMediaResponse{}
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[21]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[46]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -1401,7 +3622,7 @@ func (x *UnbridgeMediaResponse) String() string {
 func (*UnbridgeMediaResponse) ProtoMessage() {}
 
 func (x *UnbridgeMediaResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[21]
+	mi := &file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[46]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1414,7 +3635,7 @@ func (x *UnbridgeMediaResponse) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use UnbridgeMediaResponse.ProtoReflect.Descriptor instead.
 func (*UnbridgeMediaResponse) Descriptor() ([]byte, []int) {
-	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{21}
+	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP(), []int{46}
 }
 
 func (x *UnbridgeMediaResponse) GetBridgeId() string {
@@ -1435,14 +3656,16 @@ var File_api_proto_rtpmanager_v1_rtpmanager_proto protoreflect.FileDescriptor
 
 const file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc = "" +
 	"\n" +
-	"(api/proto/rtpmanager/v1/rtpmanager.proto\x12\rrtpmanager.v1\"\x98\x01\n" +
+	"(api/proto/rtpmanager/v1/rtpmanager.proto\x12\rrtpmanager.v1\"\xcb\x01\n" +
 	"\x14CreateSessionRequest\x12\x17\n" +
 	"\acall_id\x18\x01 \x01(\tR\x06callId\x12\x1f\n" +
 	"\vremote_addr\x18\x02 \x01(\tR\n" +
 	"remoteAddr\x12\x1f\n" +
 	"\vremote_port\x18\x03 \x01(\x05R\n" +
 	"remotePort\x12%\n" +
-	"\x0eoffered_codecs\x18\x04 \x03(\tR\rofferedCodecs\"\xec\x01\n" +
+	"\x0eoffered_codecs\x18\x04 \x03(\tR\rofferedCodecs\x12\x1b\n" +
+	"\tpeer_addr\x18\x05 \x01(\tR\bpeerAddr\x12\x14\n" +
+	"\x05owner\x18\x06 \x01(\tR\x05owner\"\xec\x01\n" +
 	"\x15CreateSessionResponse\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1d\n" +
@@ -1460,12 +3683,37 @@ const file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc = "" +
 	"\x16DestroySessionResponse\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x124\n" +
-	"\x06status\x18\x02 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status\"b\n" +
+	"\x06status\x18\x02 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status\"\xba\x01\n" +
 	"\x10PlayAudioRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1b\n" +
 	"\tfile_path\x18\x02 \x01(\tR\bfilePath\x12\x12\n" +
-	"\x04loop\x18\x03 \x01(\bR\x04loop\"\xe6\x02\n" +
+	"\x04loop\x18\x03 \x01(\bR\x04loop\x12\x1a\n" +
+	"\bplaylist\x18\x04 \x03(\tR\bplaylist\x12\x19\n" +
+	"\bstart_ms\x18\x05 \x01(\x05R\astartMs\x12\x1f\n" +
+	"\vreport_dtmf\x18\x06 \x01(\bR\n" +
+	"reportDtmf\"\x95\x02\n" +
+	"\x0fPlayToneRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x12\n" +
+	"\x04tone\x18\x02 \x01(\tR\x04tone\x12\x18\n" +
+	"\acountry\x18\x03 \x01(\tR\acountry\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x05R\n" +
+	"durationMs\x12\x1f\n" +
+	"\vreport_dtmf\x18\x05 \x01(\bR\n" +
+	"reportDtmf\x12%\n" +
+	"\x0edetect_machine\x18\x06 \x01(\bR\rdetectMachine\x12L\n" +
+	"\x0emachine_params\x18\a \x01(\v2%.rtpmanager.v1.MachineDetectionParamsR\rmachineParams\"\x86\x03\n" +
+	"\x16MachineDetectionParams\x12,\n" +
+	"\x12initial_silence_ms\x18\x01 \x01(\x05R\x10initialSilenceMs\x12\x1f\n" +
+	"\vgreeting_ms\x18\x02 \x01(\x05R\n" +
+	"greetingMs\x129\n" +
+	"\x19after_greeting_silence_ms\x18\x03 \x01(\x05R\x16afterGreetingSilenceMs\x12*\n" +
+	"\x11total_analysis_ms\x18\x04 \x01(\x05R\x0ftotalAnalysisMs\x12+\n" +
+	"\x12min_word_length_ms\x18\x05 \x01(\x05R\x0fminWordLengthMs\x127\n" +
+	"\x18between_words_silence_ms\x18\x06 \x01(\x05R\x15betweenWordsSilenceMs\x12#\n" +
+	"\rmaximum_words\x18\a \x01(\x05R\fmaximumWords\x12+\n" +
+	"\x11silence_threshold\x18\b \x01(\x05R\x10silenceThreshold\"\xd5\x03\n" +
 	"\rPlaybackEvent\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12:\n" +
@@ -1473,43 +3721,205 @@ const file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc = "" +
 	"\bprogress\x18\x03 \x01(\v2\x1f.rtpmanager.v1.PlaybackProgressH\x00R\bprogress\x12@\n" +
 	"\tcompleted\x18\x04 \x01(\v2 .rtpmanager.v1.PlaybackCompletedH\x00R\tcompleted\x124\n" +
 	"\x05error\x18\x05 \x01(\v2\x1c.rtpmanager.v1.PlaybackErrorH\x00R\x05error\x12:\n" +
-	"\astopped\x18\x06 \x01(\v2\x1e.rtpmanager.v1.PlaybackStoppedH\x00R\astoppedB\a\n" +
+	"\astopped\x18\x06 \x01(\v2\x1e.rtpmanager.v1.PlaybackStoppedH\x00R\astopped\x121\n" +
+	"\x04dtmf\x18\a \x01(\v2\x1b.rtpmanager.v1.DTMFReceivedH\x00R\x04dtmf\x12:\n" +
+	"\amachine\x18\b \x01(\v2\x1e.rtpmanager.v1.MachineDetectedH\x00R\amachineB\a\n" +
 	"\x05event\"U\n" +
 	"\x0fPlaybackStarted\x12!\n" +
 	"\ftotal_frames\x18\x01 \x01(\x05R\vtotalFrames\x12\x1f\n" +
 	"\vduration_ms\x18\x02 \x01(\x05R\n" +
-	"durationMs\"^\n" +
+	"durationMs\"\xb8\x01\n" +
 	"\x10PlaybackProgress\x12\x1f\n" +
 	"\vframes_sent\x18\x01 \x01(\x05R\n" +
 	"framesSent\x12)\n" +
-	"\x10percent_complete\x18\x02 \x01(\x02R\x0fpercentComplete\"`\n" +
+	"\x10percent_complete\x18\x02 \x01(\x02R\x0fpercentComplete\x12\x1f\n" +
+	"\vposition_ms\x18\x03 \x01(\x05R\n" +
+	"positionMs\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x05R\n" +
+	"durationMs\x12\x16\n" +
+	"\x06paused\x18\x05 \x01(\bR\x06paused\"`\n" +
 	"\x11PlaybackCompleted\x12*\n" +
 	"\x11total_frames_sent\x18\x01 \x01(\x05R\x0ftotalFramesSent\x12\x1f\n" +
 	"\vduration_ms\x18\x02 \x01(\x05R\n" +
 	"durationMs\"=\n" +
 	"\rPlaybackError\x12\x12\n" +
 	"\x04code\x18\x01 \x01(\tR\x04code\x12\x18\n" +
-	"\amessage\x18\x02 \x01(\tR\amessage\"J\n" +
+	"\amessage\x18\x02 \x01(\tR\amessage\"$\n" +
+	"\fDTMFReceived\x12\x14\n" +
+	"\x05digit\x18\x01 \x01(\tR\x05digit\"\x86\x01\n" +
+	"\x0fMachineDetected\x12:\n" +
+	"\vanswered_by\x18\x01 \x01(\x0e2\x19.rtpmanager.v1.AnsweredByR\n" +
+	"answeredBy\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x05R\n" +
+	"durationMs\"k\n" +
 	"\x0fPlaybackStopped\x12\x16\n" +
 	"\x06reason\x18\x01 \x01(\tR\x06reason\x12\x1f\n" +
 	"\vframes_sent\x18\x02 \x01(\x05R\n" +
-	"framesSent\"1\n" +
+	"framesSent\x12\x1f\n" +
+	"\vposition_ms\x18\x03 \x01(\x05R\n" +
+	"positionMs\"1\n" +
 	"\x10StopAudioRequest\x12\x1d\n" +
 	"\n" +
-	"session_id\x18\x01 \x01(\tR\tsessionId\"S\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\"t\n" +
 	"\x11StopAudioResponse\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
 	"\vwas_playing\x18\x02 \x01(\bR\n" +
-	"wasPlaying\"\x0f\n" +
-	"\rHealthRequest\"|\n" +
+	"wasPlaying\x12\x1f\n" +
+	"\vposition_ms\x18\x03 \x01(\x05R\n" +
+	"positionMs\"\x92\x01\n" +
+	"\x16ControlPlaybackRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x128\n" +
+	"\acontrol\x18\x02 \x01(\x0e2\x1e.rtpmanager.v1.PlaybackControlR\acontrol\x12\x1f\n" +
+	"\vposition_ms\x18\x03 \x01(\x05R\n" +
+	"positionMs\"\xc8\x01\n" +
+	"\x17ControlPlaybackResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x16\n" +
+	"\x06paused\x18\x02 \x01(\bR\x06paused\x12\x1f\n" +
+	"\vposition_ms\x18\x03 \x01(\x05R\n" +
+	"positionMs\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x05R\n" +
+	"durationMs\x124\n" +
+	"\x06status\x18\x05 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status\"\xa8\x01\n" +
+	"\x12InjectAudioRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x128\n" +
+	"\bencoding\x18\x02 \x01(\x0e2\x1c.rtpmanager.v1.AudioEncodingR\bencoding\x12\x1f\n" +
+	"\vsample_rate\x18\x03 \x01(\x05R\n" +
+	"sampleRate\x12\x18\n" +
+	"\apayload\x18\x04 \x01(\fR\apayload\"\xac\x01\n" +
+	"\x13InjectAudioResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
+	"\vframes_sent\x18\x02 \x01(\x05R\n" +
+	"framesSent\x12\x1f\n" +
+	"\vduration_ms\x18\x03 \x01(\x05R\n" +
+	"durationMs\x124\n" +
+	"\x06status\x18\x04 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status\"\xad\x01\n" +
+	"\x13CaptureAudioRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12=\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x1f.rtpmanager.v1.CaptureDirectionR\tdirection\x128\n" +
+	"\bencoding\x18\x03 \x01(\x0e2\x1c.rtpmanager.v1.AudioEncodingR\bencoding\"\xcb\x01\n" +
+	"\n" +
+	"AudioFrame\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12=\n" +
+	"\tdirection\x18\x02 \x01(\x0e2\x1f.rtpmanager.v1.CaptureDirectionR\tdirection\x12'\n" +
+	"\x0fsequence_number\x18\x03 \x01(\rR\x0esequenceNumber\x12\x1c\n" +
+	"\ttimestamp\x18\x04 \x01(\rR\ttimestamp\x12\x18\n" +
+	"\apayload\x18\x05 \x01(\fR\apayload\"+\n" +
+	"\x13ListSessionsRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\"Q\n" +
+	"\x14ListSessionsResponse\x129\n" +
+	"\bsessions\x18\x01 \x03(\v2\x1d.rtpmanager.v1.SessionSummaryR\bsessions\"\xb2\x01\n" +
+	"\x0eSessionSummary\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\tR\x06callId\x121\n" +
+	"\x05state\x18\x03 \x01(\x0e2\x1b.rtpmanager.v1.SessionStateR\x05state\x12\x14\n" +
+	"\x05owner\x18\x04 \x01(\tR\x05owner\x12\x1f\n" +
+	"\vage_seconds\x18\x05 \x01(\x05R\n" +
+	"ageSeconds\"\x14\n" +
+	"\x12ListBridgesRequest\"M\n" +
+	"\x13ListBridgesResponse\x126\n" +
+	"\abridges\x18\x01 \x03(\v2\x1c.rtpmanager.v1.BridgeSummaryR\abridges\"\x86\x03\n" +
+	"\rBridgeSummary\x12\x1b\n" +
+	"\tbridge_id\x18\x01 \x01(\tR\bbridgeId\x12 \n" +
+	"\fsession_a_id\x18\x02 \x01(\tR\n" +
+	"sessionAId\x12 \n" +
+	"\fsession_b_id\x18\x03 \x01(\tR\n" +
+	"sessionBId\x12#\n" +
+	"\x0epackets_a_to_b\x18\x04 \x01(\x03R\vpacketsAToB\x12#\n" +
+	"\x0epackets_b_to_a\x18\x05 \x01(\x03R\vpacketsBToA\x12\x1f\n" +
+	"\fbytes_a_to_b\x18\x06 \x01(\x03R\tbytesAToB\x12\x1f\n" +
+	"\fbytes_b_to_a\x18\a \x01(\x03R\tbytesBToA\x12C\n" +
+	"\rjitter_a_to_b\x18\b \x01(\v2 .rtpmanager.v1.JitterBufferStatsR\n" +
+	"jitterAToB\x12C\n" +
+	"\rjitter_b_to_a\x18\t \x01(\v2 .rtpmanager.v1.JitterBufferStatsR\n" +
+	"jitterBToA\"\xfa\x01\n" +
+	"\x11JitterBufferStats\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\x12\x1b\n" +
+	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\x12&\n" +
+	"\x0ftarget_delay_ms\x18\x04 \x01(\x05R\rtargetDelayMs\x12\x1b\n" +
+	"\tjitter_ms\x18\x05 \x01(\x05R\bjitterMs\x12\x12\n" +
+	"\x04late\x18\x06 \x01(\x03R\x04late\x12\x1a\n" +
+	"\boverflow\x18\a \x01(\x03R\boverflow\x12\x1e\n" +
+	"\n" +
+	"duplicates\x18\b \x01(\x03R\n" +
+	"duplicates\".\n" +
+	"\x16SubscribeEventsRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\"\xf9\x03\n" +
+	"\fSessionEvent\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\tR\x06callId\x12!\n" +
+	"\ftimestamp_ms\x18\x03 \x01(\x03R\vtimestampMs\x129\n" +
+	"\acreated\x18\x04 \x01(\v2\x1d.rtpmanager.v1.SessionCreatedH\x00R\acreated\x12?\n" +
+	"\tdestroyed\x18\x05 \x01(\v2\x1f.rtpmanager.v1.SessionDestroyedH\x00R\tdestroyed\x12N\n" +
+	"\x11playback_finished\x18\x06 \x01(\v2\x1f.rtpmanager.v1.PlaybackFinishedH\x00R\x10playbackFinished\x121\n" +
+	"\x04dtmf\x18\a \x01(\v2\x1b.rtpmanager.v1.DTMFReceivedH\x00R\x04dtmf\x12B\n" +
+	"\rmedia_timeout\x18\b \x01(\v2\x1b.rtpmanager.v1.MediaTimeoutH\x00R\fmediaTimeout\x12B\n" +
+	"\rquality_alert\x18\t \x01(\v2\x1b.rtpmanager.v1.QualityAlertH\x00R\fqualityAlertB\a\n" +
+	"\x05event\"d\n" +
+	"\x0eSessionCreated\x12\x1d\n" +
+	"\n" +
+	"local_addr\x18\x01 \x01(\tR\tlocalAddr\x12\x1d\n" +
+	"\n" +
+	"local_port\x18\x02 \x01(\x05R\tlocalPort\x12\x14\n" +
+	"\x05codec\x18\x03 \x01(\tR\x05codec\"J\n" +
+	"\x10SessionDestroyed\x126\n" +
+	"\x06reason\x18\x01 \x01(\x0e2\x1e.rtpmanager.v1.TerminateReasonR\x06reason\"c\n" +
+	"\x10PlaybackFinished\x12\x18\n" +
+	"\aoutcome\x18\x01 \x01(\tR\aoutcome\x12\x1f\n" +
+	"\vposition_ms\x18\x02 \x01(\x05R\n" +
+	"positionMs\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"Z\n" +
+	"\fQualityAlert\x12\x16\n" +
+	"\x06metric\x18\x01 \x01(\tR\x06metric\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x01R\x05value\x12\x1c\n" +
+	"\tthreshold\x18\x03 \x01(\x01R\tthreshold\"\x89\x01\n" +
+	"\rHealthRequest\x12\x14\n" +
+	"\x05owner\x18\x01 \x01(\tR\x05owner\x120\n" +
+	"\x14orphaned_session_ids\x18\x02 \x03(\tR\x12orphanedSessionIds\x120\n" +
+	"\x14acked_media_timeouts\x18\x03 \x03(\tR\x12ackedMediaTimeouts\"\x89\x04\n" +
 	"\x0eHealthResponse\x12\x18\n" +
 	"\ahealthy\x18\x01 \x01(\bR\ahealthy\x12'\n" +
 	"\x0factive_sessions\x18\x02 \x01(\x05R\x0eactiveSessions\x12'\n" +
-	"\x0favailable_ports\x18\x03 \x01(\x05R\x0eavailablePorts\"g\n" +
+	"\x0favailable_ports\x18\x03 \x01(\x05R\x0eavailablePorts\x12B\n" +
+	"\x0emedia_timeouts\x18\x04 \x03(\v2\x1b.rtpmanager.v1.MediaTimeoutR\rmediaTimeouts\x12\x1f\n" +
+	"\vtotal_ports\x18\x05 \x01(\x05R\n" +
+	"totalPorts\x12'\n" +
+	"\x0fallocated_ports\x18\x06 \x01(\x05R\x0eallocatedPorts\x12\x1d\n" +
+	"\n" +
+	"busy_ports\x18\a \x01(\x05R\tbusyPorts\x12%\n" +
+	"\x0eport_conflicts\x18\b \x01(\x03R\rportConflicts\x12)\n" +
+	"\x10port_exhaustions\x18\t \x01(\x03R\x0fportExhaustions\x12B\n" +
+	"\x0esession_checks\x18\n" +
+	" \x03(\v2\x1b.rtpmanager.v1.SessionCheckR\rsessionChecks\x12\x1f\n" +
+	"\vinstance_id\x18\v \x01(\tR\n" +
+	"instanceId\x12'\n" +
+	"\x0fdrain_requested\x18\f \x01(\bR\x0edrainRequested\"g\n" +
+	"\fSessionCheck\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\tR\x06callId\x12\x1f\n" +
+	"\vage_seconds\x18\x03 \x01(\x05R\n" +
+	"ageSeconds\"i\n" +
+	"\fMediaTimeout\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x17\n" +
+	"\acall_id\x18\x02 \x01(\tR\x06callId\x12!\n" +
+	"\fidle_seconds\x18\x03 \x01(\x05R\vidleSeconds\"\x83\x01\n" +
 	"\rSessionStatus\x121\n" +
 	"\x05state\x18\x01 \x01(\x0e2\x1b.rtpmanager.v1.SessionStateR\x05state\x12#\n" +
-	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\"}\n" +
+	"\rerror_message\x18\x02 \x01(\tR\ferrorMessage\x12\x1a\n" +
+	"\brejected\x18\x03 \x01(\bR\brejected\"}\n" +
 	"\x1aUpdateSessionRemoteRequest\x12\x1d\n" +
 	"\n" +
 	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x1f\n" +
@@ -1535,7 +3945,28 @@ const file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc = "" +
 	"session_id\x18\x02 \x01(\tR\tsessionId\"j\n" +
 	"\x15UnbridgeMediaResponse\x12\x1b\n" +
 	"\tbridge_id\x18\x01 \x01(\tR\bbridgeId\x124\n" +
-	"\x06status\x18\x02 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status*\xd6\x01\n" +
+	"\x06status\x18\x02 \x01(\v2\x1c.rtpmanager.v1.SessionStatusR\x06status*r\n" +
+	"\n" +
+	"AnsweredBy\x12\x1b\n" +
+	"\x17ANSWERED_BY_UNSPECIFIED\x10\x00\x12\x15\n" +
+	"\x11ANSWERED_BY_HUMAN\x10\x01\x12\x17\n" +
+	"\x13ANSWERED_BY_MACHINE\x10\x02\x12\x17\n" +
+	"\x13ANSWERED_BY_UNKNOWN\x10\x03*\xa2\x01\n" +
+	"\x0fPlaybackControl\x12 \n" +
+	"\x1cPLAYBACK_CONTROL_UNSPECIFIED\x10\x00\x12\x1a\n" +
+	"\x16PLAYBACK_CONTROL_PAUSE\x10\x01\x12\x1b\n" +
+	"\x17PLAYBACK_CONTROL_RESUME\x10\x02\x12\x19\n" +
+	"\x15PLAYBACK_CONTROL_SEEK\x10\x03\x12\x19\n" +
+	"\x15PLAYBACK_CONTROL_SKIP\x10\x04*f\n" +
+	"\rAudioEncoding\x12\x1e\n" +
+	"\x1aAUDIO_ENCODING_UNSPECIFIED\x10\x00\x12\x1c\n" +
+	"\x18AUDIO_ENCODING_PCM_S16LE\x10\x01\x12\x17\n" +
+	"\x13AUDIO_ENCODING_PCMU\x10\x02*\x90\x01\n" +
+	"\x10CaptureDirection\x12!\n" +
+	"\x1dCAPTURE_DIRECTION_UNSPECIFIED\x10\x00\x12\x1d\n" +
+	"\x19CAPTURE_DIRECTION_INBOUND\x10\x01\x12\x1e\n" +
+	"\x1aCAPTURE_DIRECTION_OUTBOUND\x10\x02\x12\x1a\n" +
+	"\x16CAPTURE_DIRECTION_BOTH\x10\x03*\xd6\x01\n" +
 	"\fSessionState\x12\x1d\n" +
 	"\x19SESSION_STATE_UNSPECIFIED\x10\x00\x12\x19\n" +
 	"\x15SESSION_STATE_CREATED\x10\x01\x12\x18\n" +
@@ -1550,16 +3981,24 @@ const file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc = "" +
 	"\x14TERMINATE_REASON_BYE\x10\x02\x12\x1b\n" +
 	"\x17TERMINATE_REASON_CANCEL\x10\x03\x12\x1a\n" +
 	"\x16TERMINATE_REASON_ERROR\x10\x04\x12\x1c\n" +
-	"\x18TERMINATE_REASON_TIMEOUT\x10\x052\xd3\x05\n" +
+	"\x18TERMINATE_REASON_TIMEOUT\x10\x052\xb2\n" +
+	"\n" +
 	"\x11RTPManagerService\x12Z\n" +
 	"\rCreateSession\x12#.rtpmanager.v1.CreateSessionRequest\x1a$.rtpmanager.v1.CreateSessionResponse\x12]\n" +
 	"\x0eDestroySession\x12$.rtpmanager.v1.DestroySessionRequest\x1a%.rtpmanager.v1.DestroySessionResponse\x12L\n" +
-	"\tPlayAudio\x12\x1f.rtpmanager.v1.PlayAudioRequest\x1a\x1c.rtpmanager.v1.PlaybackEvent0\x01\x12N\n" +
-	"\tStopAudio\x12\x1f.rtpmanager.v1.StopAudioRequest\x1a .rtpmanager.v1.StopAudioResponse\x12E\n" +
+	"\tPlayAudio\x12\x1f.rtpmanager.v1.PlayAudioRequest\x1a\x1c.rtpmanager.v1.PlaybackEvent0\x01\x12J\n" +
+	"\bPlayTone\x12\x1e.rtpmanager.v1.PlayToneRequest\x1a\x1c.rtpmanager.v1.PlaybackEvent0\x01\x12N\n" +
+	"\tStopAudio\x12\x1f.rtpmanager.v1.StopAudioRequest\x1a .rtpmanager.v1.StopAudioResponse\x12`\n" +
+	"\x0fControlPlayback\x12%.rtpmanager.v1.ControlPlaybackRequest\x1a&.rtpmanager.v1.ControlPlaybackResponse\x12V\n" +
+	"\vInjectAudio\x12!.rtpmanager.v1.InjectAudioRequest\x1a\".rtpmanager.v1.InjectAudioResponse(\x01\x12O\n" +
+	"\fCaptureAudio\x12\".rtpmanager.v1.CaptureAudioRequest\x1a\x19.rtpmanager.v1.AudioFrame0\x01\x12E\n" +
 	"\x06Health\x12\x1c.rtpmanager.v1.HealthRequest\x1a\x1d.rtpmanager.v1.HealthResponse\x12l\n" +
 	"\x13UpdateSessionRemote\x12).rtpmanager.v1.UpdateSessionRemoteRequest\x1a*.rtpmanager.v1.UpdateSessionRemoteResponse\x12T\n" +
 	"\vBridgeMedia\x12!.rtpmanager.v1.BridgeMediaRequest\x1a\".rtpmanager.v1.BridgeMediaResponse\x12Z\n" +
-	"\rUnbridgeMedia\x12#.rtpmanager.v1.UnbridgeMediaRequest\x1a$.rtpmanager.v1.UnbridgeMediaResponseB=Z;github.com/sebas/switchboard/pkg/rtpmanager/v1;rtpmanagerv1b\x06proto3"
+	"\rUnbridgeMedia\x12#.rtpmanager.v1.UnbridgeMediaRequest\x1a$.rtpmanager.v1.UnbridgeMediaResponse\x12W\n" +
+	"\fListSessions\x12\".rtpmanager.v1.ListSessionsRequest\x1a#.rtpmanager.v1.ListSessionsResponse\x12W\n" +
+	"\x0fSubscribeEvents\x12%.rtpmanager.v1.SubscribeEventsRequest\x1a\x1b.rtpmanager.v1.SessionEvent0\x01\x12T\n" +
+	"\vListBridges\x12!.rtpmanager.v1.ListBridgesRequest\x1a\".rtpmanager.v1.ListBridgesResponseB=Z;github.com/sebas/switchboard/pkg/rtpmanager/v1;rtpmanagerv1b\x06proto3"
 
 var (
 	file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescOnce sync.Once
@@ -1573,68 +4012,136 @@ func file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescGZIP() []byte {
 	return file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDescData
 }
 
-var file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
-var file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes = make([]protoimpl.MessageInfo, 22)
+var file_api_proto_rtpmanager_v1_rtpmanager_proto_enumTypes = make([]protoimpl.EnumInfo, 6)
+var file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes = make([]protoimpl.MessageInfo, 47)
 var file_api_proto_rtpmanager_v1_rtpmanager_proto_goTypes = []any{
-	(SessionState)(0),                   // 0: rtpmanager.v1.SessionState
-	(TerminateReason)(0),                // 1: rtpmanager.v1.TerminateReason
-	(*CreateSessionRequest)(nil),        // 2: rtpmanager.v1.CreateSessionRequest
-	(*CreateSessionResponse)(nil),       // 3: rtpmanager.v1.CreateSessionResponse
-	(*DestroySessionRequest)(nil),       // 4: rtpmanager.v1.DestroySessionRequest
-	(*DestroySessionResponse)(nil),      // 5: rtpmanager.v1.DestroySessionResponse
-	(*PlayAudioRequest)(nil),            // 6: rtpmanager.v1.PlayAudioRequest
-	(*PlaybackEvent)(nil),               // 7: rtpmanager.v1.PlaybackEvent
-	(*PlaybackStarted)(nil),             // 8: rtpmanager.v1.PlaybackStarted
-	(*PlaybackProgress)(nil),            // 9: rtpmanager.v1.PlaybackProgress
-	(*PlaybackCompleted)(nil),           // 10: rtpmanager.v1.PlaybackCompleted
-	(*PlaybackError)(nil),               // 11: rtpmanager.v1.PlaybackError
-	(*PlaybackStopped)(nil),             // 12: rtpmanager.v1.PlaybackStopped
-	(*StopAudioRequest)(nil),            // 13: rtpmanager.v1.StopAudioRequest
-	(*StopAudioResponse)(nil),           // 14: rtpmanager.v1.StopAudioResponse
-	(*HealthRequest)(nil),               // 15: rtpmanager.v1.HealthRequest
-	(*HealthResponse)(nil),              // 16: rtpmanager.v1.HealthResponse
-	(*SessionStatus)(nil),               // 17: rtpmanager.v1.SessionStatus
-	(*UpdateSessionRemoteRequest)(nil),  // 18: rtpmanager.v1.UpdateSessionRemoteRequest
-	(*UpdateSessionRemoteResponse)(nil), // 19: rtpmanager.v1.UpdateSessionRemoteResponse
-	(*BridgeMediaRequest)(nil),          // 20: rtpmanager.v1.BridgeMediaRequest
-	(*BridgeMediaResponse)(nil),         // 21: rtpmanager.v1.BridgeMediaResponse
-	(*UnbridgeMediaRequest)(nil),        // 22: rtpmanager.v1.UnbridgeMediaRequest
-	(*UnbridgeMediaResponse)(nil),       // 23: rtpmanager.v1.UnbridgeMediaResponse
+	(AnsweredBy)(0),                     // 0: rtpmanager.v1.AnsweredBy
+	(PlaybackControl)(0),                // 1: rtpmanager.v1.PlaybackControl
+	(AudioEncoding)(0),                  // 2: rtpmanager.v1.AudioEncoding
+	(CaptureDirection)(0),               // 3: rtpmanager.v1.CaptureDirection
+	(SessionState)(0),                   // 4: rtpmanager.v1.SessionState
+	(TerminateReason)(0),                // 5: rtpmanager.v1.TerminateReason
+	(*CreateSessionRequest)(nil),        // 6: rtpmanager.v1.CreateSessionRequest
+	(*CreateSessionResponse)(nil),       // 7: rtpmanager.v1.CreateSessionResponse
+	(*DestroySessionRequest)(nil),       // 8: rtpmanager.v1.DestroySessionRequest
+	(*DestroySessionResponse)(nil),      // 9: rtpmanager.v1.DestroySessionResponse
+	(*PlayAudioRequest)(nil),            // 10: rtpmanager.v1.PlayAudioRequest
+	(*PlayToneRequest)(nil),             // 11: rtpmanager.v1.PlayToneRequest
+	(*MachineDetectionParams)(nil),      // 12: rtpmanager.v1.MachineDetectionParams
+	(*PlaybackEvent)(nil),               // 13: rtpmanager.v1.PlaybackEvent
+	(*PlaybackStarted)(nil),             // 14: rtpmanager.v1.PlaybackStarted
+	(*PlaybackProgress)(nil),            // 15: rtpmanager.v1.PlaybackProgress
+	(*PlaybackCompleted)(nil),           // 16: rtpmanager.v1.PlaybackCompleted
+	(*PlaybackError)(nil),               // 17: rtpmanager.v1.PlaybackError
+	(*DTMFReceived)(nil),                // 18: rtpmanager.v1.DTMFReceived
+	(*MachineDetected)(nil),             // 19: rtpmanager.v1.MachineDetected
+	(*PlaybackStopped)(nil),             // 20: rtpmanager.v1.PlaybackStopped
+	(*StopAudioRequest)(nil),            // 21: rtpmanager.v1.StopAudioRequest
+	(*StopAudioResponse)(nil),           // 22: rtpmanager.v1.StopAudioResponse
+	(*ControlPlaybackRequest)(nil),      // 23: rtpmanager.v1.ControlPlaybackRequest
+	(*ControlPlaybackResponse)(nil),     // 24: rtpmanager.v1.ControlPlaybackResponse
+	(*InjectAudioRequest)(nil),          // 25: rtpmanager.v1.InjectAudioRequest
+	(*InjectAudioResponse)(nil),         // 26: rtpmanager.v1.InjectAudioResponse
+	(*CaptureAudioRequest)(nil),         // 27: rtpmanager.v1.CaptureAudioRequest
+	(*AudioFrame)(nil),                  // 28: rtpmanager.v1.AudioFrame
+	(*ListSessionsRequest)(nil),         // 29: rtpmanager.v1.ListSessionsRequest
+	(*ListSessionsResponse)(nil),        // 30: rtpmanager.v1.ListSessionsResponse
+	(*SessionSummary)(nil),              // 31: rtpmanager.v1.SessionSummary
+	(*ListBridgesRequest)(nil),          // 32: rtpmanager.v1.ListBridgesRequest
+	(*ListBridgesResponse)(nil),         // 33: rtpmanager.v1.ListBridgesResponse
+	(*BridgeSummary)(nil),               // 34: rtpmanager.v1.BridgeSummary
+	(*JitterBufferStats)(nil),           // 35: rtpmanager.v1.JitterBufferStats
+	(*SubscribeEventsRequest)(nil),      // 36: rtpmanager.v1.SubscribeEventsRequest
+	(*SessionEvent)(nil),                // 37: rtpmanager.v1.SessionEvent
+	(*SessionCreated)(nil),              // 38: rtpmanager.v1.SessionCreated
+	(*SessionDestroyed)(nil),            // 39: rtpmanager.v1.SessionDestroyed
+	(*PlaybackFinished)(nil),            // 40: rtpmanager.v1.PlaybackFinished
+	(*QualityAlert)(nil),                // 41: rtpmanager.v1.QualityAlert
+	(*HealthRequest)(nil),               // 42: rtpmanager.v1.HealthRequest
+	(*HealthResponse)(nil),              // 43: rtpmanager.v1.HealthResponse
+	(*SessionCheck)(nil),                // 44: rtpmanager.v1.SessionCheck
+	(*MediaTimeout)(nil),                // 45: rtpmanager.v1.MediaTimeout
+	(*SessionStatus)(nil),               // 46: rtpmanager.v1.SessionStatus
+	(*UpdateSessionRemoteRequest)(nil),  // 47: rtpmanager.v1.UpdateSessionRemoteRequest
+	(*UpdateSessionRemoteResponse)(nil), // 48: rtpmanager.v1.UpdateSessionRemoteResponse
+	(*BridgeMediaRequest)(nil),          // 49: rtpmanager.v1.BridgeMediaRequest
+	(*BridgeMediaResponse)(nil),         // 50: rtpmanager.v1.BridgeMediaResponse
+	(*UnbridgeMediaRequest)(nil),        // 51: rtpmanager.v1.UnbridgeMediaRequest
+	(*UnbridgeMediaResponse)(nil),       // 52: rtpmanager.v1.UnbridgeMediaResponse
 }
 var file_api_proto_rtpmanager_v1_rtpmanager_proto_depIdxs = []int32{
-	17, // 0: rtpmanager.v1.CreateSessionResponse.status:type_name -> rtpmanager.v1.SessionStatus
-	1,  // 1: rtpmanager.v1.DestroySessionRequest.reason:type_name -> rtpmanager.v1.TerminateReason
-	17, // 2: rtpmanager.v1.DestroySessionResponse.status:type_name -> rtpmanager.v1.SessionStatus
-	8,  // 3: rtpmanager.v1.PlaybackEvent.started:type_name -> rtpmanager.v1.PlaybackStarted
-	9,  // 4: rtpmanager.v1.PlaybackEvent.progress:type_name -> rtpmanager.v1.PlaybackProgress
-	10, // 5: rtpmanager.v1.PlaybackEvent.completed:type_name -> rtpmanager.v1.PlaybackCompleted
-	11, // 6: rtpmanager.v1.PlaybackEvent.error:type_name -> rtpmanager.v1.PlaybackError
-	12, // 7: rtpmanager.v1.PlaybackEvent.stopped:type_name -> rtpmanager.v1.PlaybackStopped
-	0,  // 8: rtpmanager.v1.SessionStatus.state:type_name -> rtpmanager.v1.SessionState
-	17, // 9: rtpmanager.v1.UpdateSessionRemoteResponse.status:type_name -> rtpmanager.v1.SessionStatus
-	17, // 10: rtpmanager.v1.BridgeMediaResponse.status:type_name -> rtpmanager.v1.SessionStatus
-	17, // 11: rtpmanager.v1.UnbridgeMediaResponse.status:type_name -> rtpmanager.v1.SessionStatus
-	2,  // 12: rtpmanager.v1.RTPManagerService.CreateSession:input_type -> rtpmanager.v1.CreateSessionRequest
-	4,  // 13: rtpmanager.v1.RTPManagerService.DestroySession:input_type -> rtpmanager.v1.DestroySessionRequest
-	6,  // 14: rtpmanager.v1.RTPManagerService.PlayAudio:input_type -> rtpmanager.v1.PlayAudioRequest
-	13, // 15: rtpmanager.v1.RTPManagerService.StopAudio:input_type -> rtpmanager.v1.StopAudioRequest
-	15, // 16: rtpmanager.v1.RTPManagerService.Health:input_type -> rtpmanager.v1.HealthRequest
-	18, // 17: rtpmanager.v1.RTPManagerService.UpdateSessionRemote:input_type -> rtpmanager.v1.UpdateSessionRemoteRequest
-	20, // 18: rtpmanager.v1.RTPManagerService.BridgeMedia:input_type -> rtpmanager.v1.BridgeMediaRequest
-	22, // 19: rtpmanager.v1.RTPManagerService.UnbridgeMedia:input_type -> rtpmanager.v1.UnbridgeMediaRequest
-	3,  // 20: rtpmanager.v1.RTPManagerService.CreateSession:output_type -> rtpmanager.v1.CreateSessionResponse
-	5,  // 21: rtpmanager.v1.RTPManagerService.DestroySession:output_type -> rtpmanager.v1.DestroySessionResponse
-	7,  // 22: rtpmanager.v1.RTPManagerService.PlayAudio:output_type -> rtpmanager.v1.PlaybackEvent
-	14, // 23: rtpmanager.v1.RTPManagerService.StopAudio:output_type -> rtpmanager.v1.StopAudioResponse
-	16, // 24: rtpmanager.v1.RTPManagerService.Health:output_type -> rtpmanager.v1.HealthResponse
-	19, // 25: rtpmanager.v1.RTPManagerService.UpdateSessionRemote:output_type -> rtpmanager.v1.UpdateSessionRemoteResponse
-	21, // 26: rtpmanager.v1.RTPManagerService.BridgeMedia:output_type -> rtpmanager.v1.BridgeMediaResponse
-	23, // 27: rtpmanager.v1.RTPManagerService.UnbridgeMedia:output_type -> rtpmanager.v1.UnbridgeMediaResponse
-	20, // [20:28] is the sub-list for method output_type
-	12, // [12:20] is the sub-list for method input_type
-	12, // [12:12] is the sub-list for extension type_name
-	12, // [12:12] is the sub-list for extension extendee
-	0,  // [0:12] is the sub-list for field type_name
+	46, // 0: rtpmanager.v1.CreateSessionResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	5,  // 1: rtpmanager.v1.DestroySessionRequest.reason:type_name -> rtpmanager.v1.TerminateReason
+	46, // 2: rtpmanager.v1.DestroySessionResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	12, // 3: rtpmanager.v1.PlayToneRequest.machine_params:type_name -> rtpmanager.v1.MachineDetectionParams
+	14, // 4: rtpmanager.v1.PlaybackEvent.started:type_name -> rtpmanager.v1.PlaybackStarted
+	15, // 5: rtpmanager.v1.PlaybackEvent.progress:type_name -> rtpmanager.v1.PlaybackProgress
+	16, // 6: rtpmanager.v1.PlaybackEvent.completed:type_name -> rtpmanager.v1.PlaybackCompleted
+	17, // 7: rtpmanager.v1.PlaybackEvent.error:type_name -> rtpmanager.v1.PlaybackError
+	20, // 8: rtpmanager.v1.PlaybackEvent.stopped:type_name -> rtpmanager.v1.PlaybackStopped
+	18, // 9: rtpmanager.v1.PlaybackEvent.dtmf:type_name -> rtpmanager.v1.DTMFReceived
+	19, // 10: rtpmanager.v1.PlaybackEvent.machine:type_name -> rtpmanager.v1.MachineDetected
+	0,  // 11: rtpmanager.v1.MachineDetected.answered_by:type_name -> rtpmanager.v1.AnsweredBy
+	1,  // 12: rtpmanager.v1.ControlPlaybackRequest.control:type_name -> rtpmanager.v1.PlaybackControl
+	46, // 13: rtpmanager.v1.ControlPlaybackResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	2,  // 14: rtpmanager.v1.InjectAudioRequest.encoding:type_name -> rtpmanager.v1.AudioEncoding
+	46, // 15: rtpmanager.v1.InjectAudioResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	3,  // 16: rtpmanager.v1.CaptureAudioRequest.direction:type_name -> rtpmanager.v1.CaptureDirection
+	2,  // 17: rtpmanager.v1.CaptureAudioRequest.encoding:type_name -> rtpmanager.v1.AudioEncoding
+	3,  // 18: rtpmanager.v1.AudioFrame.direction:type_name -> rtpmanager.v1.CaptureDirection
+	31, // 19: rtpmanager.v1.ListSessionsResponse.sessions:type_name -> rtpmanager.v1.SessionSummary
+	4,  // 20: rtpmanager.v1.SessionSummary.state:type_name -> rtpmanager.v1.SessionState
+	34, // 21: rtpmanager.v1.ListBridgesResponse.bridges:type_name -> rtpmanager.v1.BridgeSummary
+	35, // 22: rtpmanager.v1.BridgeSummary.jitter_a_to_b:type_name -> rtpmanager.v1.JitterBufferStats
+	35, // 23: rtpmanager.v1.BridgeSummary.jitter_b_to_a:type_name -> rtpmanager.v1.JitterBufferStats
+	38, // 24: rtpmanager.v1.SessionEvent.created:type_name -> rtpmanager.v1.SessionCreated
+	39, // 25: rtpmanager.v1.SessionEvent.destroyed:type_name -> rtpmanager.v1.SessionDestroyed
+	40, // 26: rtpmanager.v1.SessionEvent.playback_finished:type_name -> rtpmanager.v1.PlaybackFinished
+	18, // 27: rtpmanager.v1.SessionEvent.dtmf:type_name -> rtpmanager.v1.DTMFReceived
+	45, // 28: rtpmanager.v1.SessionEvent.media_timeout:type_name -> rtpmanager.v1.MediaTimeout
+	41, // 29: rtpmanager.v1.SessionEvent.quality_alert:type_name -> rtpmanager.v1.QualityAlert
+	5,  // 30: rtpmanager.v1.SessionDestroyed.reason:type_name -> rtpmanager.v1.TerminateReason
+	45, // 31: rtpmanager.v1.HealthResponse.media_timeouts:type_name -> rtpmanager.v1.MediaTimeout
+	44, // 32: rtpmanager.v1.HealthResponse.session_checks:type_name -> rtpmanager.v1.SessionCheck
+	4,  // 33: rtpmanager.v1.SessionStatus.state:type_name -> rtpmanager.v1.SessionState
+	46, // 34: rtpmanager.v1.UpdateSessionRemoteResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	46, // 35: rtpmanager.v1.BridgeMediaResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	46, // 36: rtpmanager.v1.UnbridgeMediaResponse.status:type_name -> rtpmanager.v1.SessionStatus
+	6,  // 37: rtpmanager.v1.RTPManagerService.CreateSession:input_type -> rtpmanager.v1.CreateSessionRequest
+	8,  // 38: rtpmanager.v1.RTPManagerService.DestroySession:input_type -> rtpmanager.v1.DestroySessionRequest
+	10, // 39: rtpmanager.v1.RTPManagerService.PlayAudio:input_type -> rtpmanager.v1.PlayAudioRequest
+	11, // 40: rtpmanager.v1.RTPManagerService.PlayTone:input_type -> rtpmanager.v1.PlayToneRequest
+	21, // 41: rtpmanager.v1.RTPManagerService.StopAudio:input_type -> rtpmanager.v1.StopAudioRequest
+	23, // 42: rtpmanager.v1.RTPManagerService.ControlPlayback:input_type -> rtpmanager.v1.ControlPlaybackRequest
+	25, // 43: rtpmanager.v1.RTPManagerService.InjectAudio:input_type -> rtpmanager.v1.InjectAudioRequest
+	27, // 44: rtpmanager.v1.RTPManagerService.CaptureAudio:input_type -> rtpmanager.v1.CaptureAudioRequest
+	42, // 45: rtpmanager.v1.RTPManagerService.Health:input_type -> rtpmanager.v1.HealthRequest
+	47, // 46: rtpmanager.v1.RTPManagerService.UpdateSessionRemote:input_type -> rtpmanager.v1.UpdateSessionRemoteRequest
+	49, // 47: rtpmanager.v1.RTPManagerService.BridgeMedia:input_type -> rtpmanager.v1.BridgeMediaRequest
+	51, // 48: rtpmanager.v1.RTPManagerService.UnbridgeMedia:input_type -> rtpmanager.v1.UnbridgeMediaRequest
+	29, // 49: rtpmanager.v1.RTPManagerService.ListSessions:input_type -> rtpmanager.v1.ListSessionsRequest
+	36, // 50: rtpmanager.v1.RTPManagerService.SubscribeEvents:input_type -> rtpmanager.v1.SubscribeEventsRequest
+	32, // 51: rtpmanager.v1.RTPManagerService.ListBridges:input_type -> rtpmanager.v1.ListBridgesRequest
+	7,  // 52: rtpmanager.v1.RTPManagerService.CreateSession:output_type -> rtpmanager.v1.CreateSessionResponse
+	9,  // 53: rtpmanager.v1.RTPManagerService.DestroySession:output_type -> rtpmanager.v1.DestroySessionResponse
+	13, // 54: rtpmanager.v1.RTPManagerService.PlayAudio:output_type -> rtpmanager.v1.PlaybackEvent
+	13, // 55: rtpmanager.v1.RTPManagerService.PlayTone:output_type -> rtpmanager.v1.PlaybackEvent
+	22, // 56: rtpmanager.v1.RTPManagerService.StopAudio:output_type -> rtpmanager.v1.StopAudioResponse
+	24, // 57: rtpmanager.v1.RTPManagerService.ControlPlayback:output_type -> rtpmanager.v1.ControlPlaybackResponse
+	26, // 58: rtpmanager.v1.RTPManagerService.InjectAudio:output_type -> rtpmanager.v1.InjectAudioResponse
+	28, // 59: rtpmanager.v1.RTPManagerService.CaptureAudio:output_type -> rtpmanager.v1.AudioFrame
+	43, // 60: rtpmanager.v1.RTPManagerService.Health:output_type -> rtpmanager.v1.HealthResponse
+	48, // 61: rtpmanager.v1.RTPManagerService.UpdateSessionRemote:output_type -> rtpmanager.v1.UpdateSessionRemoteResponse
+	50, // 62: rtpmanager.v1.RTPManagerService.BridgeMedia:output_type -> rtpmanager.v1.BridgeMediaResponse
+	52, // 63: rtpmanager.v1.RTPManagerService.UnbridgeMedia:output_type -> rtpmanager.v1.UnbridgeMediaResponse
+	30, // 64: rtpmanager.v1.RTPManagerService.ListSessions:output_type -> rtpmanager.v1.ListSessionsResponse
+	37, // 65: rtpmanager.v1.RTPManagerService.SubscribeEvents:output_type -> rtpmanager.v1.SessionEvent
+	33, // 66: rtpmanager.v1.RTPManagerService.ListBridges:output_type -> rtpmanager.v1.ListBridgesResponse
+	52, // [52:67] is the sub-list for method output_type
+	37, // [37:52] is the sub-list for method input_type
+	37, // [37:37] is the sub-list for extension type_name
+	37, // [37:37] is the sub-list for extension extendee
+	0,  // [0:37] is the sub-list for field type_name
 }
 
 func init() { file_api_proto_rtpmanager_v1_rtpmanager_proto_init() }
@@ -1642,20 +4149,30 @@ func file_api_proto_rtpmanager_v1_rtpmanager_proto_init() {
 	if File_api_proto_rtpmanager_v1_rtpmanager_proto != nil {
 		return
 	}
-	file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[5].OneofWrappers = []any{
+	file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[7].OneofWrappers = []any{
 		(*PlaybackEvent_Started)(nil),
 		(*PlaybackEvent_Progress)(nil),
 		(*PlaybackEvent_Completed)(nil),
 		(*PlaybackEvent_Error)(nil),
 		(*PlaybackEvent_Stopped)(nil),
+		(*PlaybackEvent_Dtmf)(nil),
+		(*PlaybackEvent_Machine)(nil),
+	}
+	file_api_proto_rtpmanager_v1_rtpmanager_proto_msgTypes[31].OneofWrappers = []any{
+		(*SessionEvent_Created)(nil),
+		(*SessionEvent_Destroyed)(nil),
+		(*SessionEvent_PlaybackFinished)(nil),
+		(*SessionEvent_Dtmf)(nil),
+		(*SessionEvent_MediaTimeout)(nil),
+		(*SessionEvent_QualityAlert)(nil),
 	}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc), len(file_api_proto_rtpmanager_v1_rtpmanager_proto_rawDesc)),
-			NumEnums:      2,
-			NumMessages:   22,
+			NumEnums:      6,
+			NumMessages:   47,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
@@ -22,11 +22,18 @@ const (
 	RTPManagerService_CreateSession_FullMethodName       = "/rtpmanager.v1.RTPManagerService/CreateSession"
 	RTPManagerService_DestroySession_FullMethodName      = "/rtpmanager.v1.RTPManagerService/DestroySession"
 	RTPManagerService_PlayAudio_FullMethodName           = "/rtpmanager.v1.RTPManagerService/PlayAudio"
+	RTPManagerService_PlayTone_FullMethodName            = "/rtpmanager.v1.RTPManagerService/PlayTone"
 	RTPManagerService_StopAudio_FullMethodName           = "/rtpmanager.v1.RTPManagerService/StopAudio"
+	RTPManagerService_ControlPlayback_FullMethodName     = "/rtpmanager.v1.RTPManagerService/ControlPlayback"
+	RTPManagerService_InjectAudio_FullMethodName         = "/rtpmanager.v1.RTPManagerService/InjectAudio"
+	RTPManagerService_CaptureAudio_FullMethodName        = "/rtpmanager.v1.RTPManagerService/CaptureAudio"
 	RTPManagerService_Health_FullMethodName              = "/rtpmanager.v1.RTPManagerService/Health"
 	RTPManagerService_UpdateSessionRemote_FullMethodName = "/rtpmanager.v1.RTPManagerService/UpdateSessionRemote"
 	RTPManagerService_BridgeMedia_FullMethodName         = "/rtpmanager.v1.RTPManagerService/BridgeMedia"
 	RTPManagerService_UnbridgeMedia_FullMethodName       = "/rtpmanager.v1.RTPManagerService/UnbridgeMedia"
+	RTPManagerService_ListSessions_FullMethodName        = "/rtpmanager.v1.RTPManagerService/ListSessions"
+	RTPManagerService_SubscribeEvents_FullMethodName     = "/rtpmanager.v1.RTPManagerService/SubscribeEvents"
+	RTPManagerService_ListBridges_FullMethodName         = "/rtpmanager.v1.RTPManagerService/ListBridges"
 )
 
 // RTPManagerServiceClient is the client API for RTPManagerService service.
@@ -45,9 +52,27 @@ type RTPManagerServiceClient interface {
 	// PlayAudio starts audio playback to the remote endpoint.
 	// Returns a stream of events including progress and completion.
 	PlayAudio(ctx context.Context, in *PlayAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlaybackEvent], error)
+	// PlayTone plays a generated call progress tone, DTMF string or custom
+	// tone. Returns the same event stream as PlayAudio; StopAudio and
+	// ControlPlayback apply.
+	PlayTone(ctx context.Context, in *PlayToneRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlaybackEvent], error)
 	// StopAudio immediately stops any active playback for a session.
 	StopAudio(ctx context.Context, in *StopAudioRequest, opts ...grpc.CallOption) (*StopAudioResponse, error)
+	// ControlPlayback pauses, resumes or seeks the active playback for a
+	// session and reports its position.
+	ControlPlayback(ctx context.Context, in *ControlPlaybackRequest, opts ...grpc.CallOption) (*ControlPlaybackResponse, error)
+	// InjectAudio plays a live stream of audio frames into a session.
+	// The first message selects the session and audio format; subsequent
+	// messages carry payload only. Audio is paced to real time and sent to the
+	// remote endpoint as RTP. The response is returned once the client closes
+	// the stream and all buffered audio has been sent.
+	InjectAudio(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[InjectAudioRequest, InjectAudioResponse], error)
+	// CaptureAudio streams audio frames flowing through a bridged session
+	// in real time (e.g. for speech recognition or live monitoring).
+	// The stream ends when the bridge is torn down or the client cancels.
+	CaptureAudio(ctx context.Context, in *CaptureAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioFrame], error)
 	// Health checks if the service is operational.
+	// Also delivers RTP inactivity timeouts detected since the previous call.
 	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
 	// UpdateSessionRemote updates the remote endpoint for an existing session.
 	// Used for B2BUA: B-leg session is created without remote info, then updated
@@ -60,6 +85,17 @@ type RTPManagerServiceClient interface {
 	// UnbridgeMedia disconnects two bridged sessions.
 	// Each session continues to exist but packets are no longer forwarded.
 	UnbridgeMedia(ctx context.Context, in *UnbridgeMediaRequest, opts ...grpc.CallOption) (*UnbridgeMediaResponse, error)
+	// ListSessions returns the sessions held by the RTP manager. Signaling
+	// uses it to reconcile its calls with the media sessions on each node.
+	ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error)
+	// SubscribeEvents streams session events (created, destroyed, playback
+	// finished, DTMF, media timeout, quality alerts) as they happen, until
+	// the client cancels. Media timeouts delivered on a stream are not
+	// repeated in Health.
+	SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SessionEvent], error)
+	// ListBridges returns the active media bridges with their packet and
+	// byte counters.
+	ListBridges(ctx context.Context, in *ListBridgesRequest, opts ...grpc.CallOption) (*ListBridgesResponse, error)
 }
 
 type rTPManagerServiceClient struct {
@@ -109,6 +145,25 @@ func (c *rTPManagerServiceClient) PlayAudio(ctx context.Context, in *PlayAudioRe
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type RTPManagerService_PlayAudioClient = grpc.ServerStreamingClient[PlaybackEvent]
 
+func (c *rTPManagerServiceClient) PlayTone(ctx context.Context, in *PlayToneRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[PlaybackEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RTPManagerService_ServiceDesc.Streams[1], RTPManagerService_PlayTone_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[PlayToneRequest, PlaybackEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_PlayToneClient = grpc.ServerStreamingClient[PlaybackEvent]
+
 func (c *rTPManagerServiceClient) StopAudio(ctx context.Context, in *StopAudioRequest, opts ...grpc.CallOption) (*StopAudioResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StopAudioResponse)
@@ -119,6 +174,48 @@ func (c *rTPManagerServiceClient) StopAudio(ctx context.Context, in *StopAudioRe
 	return out, nil
 }
 
+func (c *rTPManagerServiceClient) ControlPlayback(ctx context.Context, in *ControlPlaybackRequest, opts ...grpc.CallOption) (*ControlPlaybackResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ControlPlaybackResponse)
+	err := c.cc.Invoke(ctx, RTPManagerService_ControlPlayback_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rTPManagerServiceClient) InjectAudio(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[InjectAudioRequest, InjectAudioResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RTPManagerService_ServiceDesc.Streams[2], RTPManagerService_InjectAudio_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[InjectAudioRequest, InjectAudioResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_InjectAudioClient = grpc.ClientStreamingClient[InjectAudioRequest, InjectAudioResponse]
+
+func (c *rTPManagerServiceClient) CaptureAudio(ctx context.Context, in *CaptureAudioRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[AudioFrame], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RTPManagerService_ServiceDesc.Streams[3], RTPManagerService_CaptureAudio_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CaptureAudioRequest, AudioFrame]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_CaptureAudioClient = grpc.ServerStreamingClient[AudioFrame]
+
 func (c *rTPManagerServiceClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(HealthResponse)
@@ -159,6 +256,45 @@ func (c *rTPManagerServiceClient) UnbridgeMedia(ctx context.Context, in *Unbridg
 	return out, nil
 }
 
+func (c *rTPManagerServiceClient) ListSessions(ctx context.Context, in *ListSessionsRequest, opts ...grpc.CallOption) (*ListSessionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListSessionsResponse)
+	err := c.cc.Invoke(ctx, RTPManagerService_ListSessions_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *rTPManagerServiceClient) SubscribeEvents(ctx context.Context, in *SubscribeEventsRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[SessionEvent], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &RTPManagerService_ServiceDesc.Streams[4], RTPManagerService_SubscribeEvents_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[SubscribeEventsRequest, SessionEvent]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_SubscribeEventsClient = grpc.ServerStreamingClient[SessionEvent]
+
+func (c *rTPManagerServiceClient) ListBridges(ctx context.Context, in *ListBridgesRequest, opts ...grpc.CallOption) (*ListBridgesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListBridgesResponse)
+	err := c.cc.Invoke(ctx, RTPManagerService_ListBridges_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RTPManagerServiceServer is the server API for RTPManagerService service.
 // All implementations must embed UnimplementedRTPManagerServiceServer
 // for forward compatibility.
@@ -175,9 +311,27 @@ type RTPManagerServiceServer interface {
 	// PlayAudio starts audio playback to the remote endpoint.
 	// Returns a stream of events including progress and completion.
 	PlayAudio(*PlayAudioRequest, grpc.ServerStreamingServer[PlaybackEvent]) error
+	// PlayTone plays a generated call progress tone, DTMF string or custom
+	// tone. Returns the same event stream as PlayAudio; StopAudio and
+	// ControlPlayback apply.
+	PlayTone(*PlayToneRequest, grpc.ServerStreamingServer[PlaybackEvent]) error
 	// StopAudio immediately stops any active playback for a session.
 	StopAudio(context.Context, *StopAudioRequest) (*StopAudioResponse, error)
+	// ControlPlayback pauses, resumes or seeks the active playback for a
+	// session and reports its position.
+	ControlPlayback(context.Context, *ControlPlaybackRequest) (*ControlPlaybackResponse, error)
+	// InjectAudio plays a live stream of audio frames into a session.
+	// The first message selects the session and audio format; subsequent
+	// messages carry payload only. Audio is paced to real time and sent to the
+	// remote endpoint as RTP. The response is returned once the client closes
+	// the stream and all buffered audio has been sent.
+	InjectAudio(grpc.ClientStreamingServer[InjectAudioRequest, InjectAudioResponse]) error
+	// CaptureAudio streams audio frames flowing through a bridged session
+	// in real time (e.g. for speech recognition or live monitoring).
+	// The stream ends when the bridge is torn down or the client cancels.
+	CaptureAudio(*CaptureAudioRequest, grpc.ServerStreamingServer[AudioFrame]) error
 	// Health checks if the service is operational.
+	// Also delivers RTP inactivity timeouts detected since the previous call.
 	Health(context.Context, *HealthRequest) (*HealthResponse, error)
 	// UpdateSessionRemote updates the remote endpoint for an existing session.
 	// Used for B2BUA: B-leg session is created without remote info, then updated
@@ -190,6 +344,17 @@ type RTPManagerServiceServer interface {
 	// UnbridgeMedia disconnects two bridged sessions.
 	// Each session continues to exist but packets are no longer forwarded.
 	UnbridgeMedia(context.Context, *UnbridgeMediaRequest) (*UnbridgeMediaResponse, error)
+	// ListSessions returns the sessions held by the RTP manager. Signaling
+	// uses it to reconcile its calls with the media sessions on each node.
+	ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error)
+	// SubscribeEvents streams session events (created, destroyed, playback
+	// finished, DTMF, media timeout, quality alerts) as they happen, until
+	// the client cancels. Media timeouts delivered on a stream are not
+	// repeated in Health.
+	SubscribeEvents(*SubscribeEventsRequest, grpc.ServerStreamingServer[SessionEvent]) error
+	// ListBridges returns the active media bridges with their packet and
+	// byte counters.
+	ListBridges(context.Context, *ListBridgesRequest) (*ListBridgesResponse, error)
 	mustEmbedUnimplementedRTPManagerServiceServer()
 }
 
@@ -209,9 +374,21 @@ func (UnimplementedRTPManagerServiceServer) DestroySession(context.Context, *Des
 func (UnimplementedRTPManagerServiceServer) PlayAudio(*PlayAudioRequest, grpc.ServerStreamingServer[PlaybackEvent]) error {
 	return status.Error(codes.Unimplemented, "method PlayAudio not implemented")
 }
+func (UnimplementedRTPManagerServiceServer) PlayTone(*PlayToneRequest, grpc.ServerStreamingServer[PlaybackEvent]) error {
+	return status.Error(codes.Unimplemented, "method PlayTone not implemented")
+}
 func (UnimplementedRTPManagerServiceServer) StopAudio(context.Context, *StopAudioRequest) (*StopAudioResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method StopAudio not implemented")
 }
+func (UnimplementedRTPManagerServiceServer) ControlPlayback(context.Context, *ControlPlaybackRequest) (*ControlPlaybackResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ControlPlayback not implemented")
+}
+func (UnimplementedRTPManagerServiceServer) InjectAudio(grpc.ClientStreamingServer[InjectAudioRequest, InjectAudioResponse]) error {
+	return status.Error(codes.Unimplemented, "method InjectAudio not implemented")
+}
+func (UnimplementedRTPManagerServiceServer) CaptureAudio(*CaptureAudioRequest, grpc.ServerStreamingServer[AudioFrame]) error {
+	return status.Error(codes.Unimplemented, "method CaptureAudio not implemented")
+}
 func (UnimplementedRTPManagerServiceServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method Health not implemented")
 }
@@ -224,6 +401,15 @@ func (UnimplementedRTPManagerServiceServer) BridgeMedia(context.Context, *Bridge
 func (UnimplementedRTPManagerServiceServer) UnbridgeMedia(context.Context, *UnbridgeMediaRequest) (*UnbridgeMediaResponse, error) {
 	return nil, status.Error(codes.Unimplemented, "method UnbridgeMedia not implemented")
 }
+func (UnimplementedRTPManagerServiceServer) ListSessions(context.Context, *ListSessionsRequest) (*ListSessionsResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListSessions not implemented")
+}
+func (UnimplementedRTPManagerServiceServer) SubscribeEvents(*SubscribeEventsRequest, grpc.ServerStreamingServer[SessionEvent]) error {
+	return status.Error(codes.Unimplemented, "method SubscribeEvents not implemented")
+}
+func (UnimplementedRTPManagerServiceServer) ListBridges(context.Context, *ListBridgesRequest) (*ListBridgesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListBridges not implemented")
+}
 func (UnimplementedRTPManagerServiceServer) mustEmbedUnimplementedRTPManagerServiceServer() {}
 func (UnimplementedRTPManagerServiceServer) testEmbeddedByValue()                           {}
 
@@ -292,6 +478,17 @@ func _RTPManagerService_PlayAudio_Handler(srv interface{}, stream grpc.ServerStr
 // This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
 type RTPManagerService_PlayAudioServer = grpc.ServerStreamingServer[PlaybackEvent]
 
+func _RTPManagerService_PlayTone_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(PlayToneRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RTPManagerServiceServer).PlayTone(m, &grpc.GenericServerStream[PlayToneRequest, PlaybackEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_PlayToneServer = grpc.ServerStreamingServer[PlaybackEvent]
+
 func _RTPManagerService_StopAudio_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StopAudioRequest)
 	if err := dec(in); err != nil {
@@ -310,6 +507,42 @@ func _RTPManagerService_StopAudio_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RTPManagerService_ControlPlayback_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ControlPlaybackRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPManagerServiceServer).ControlPlayback(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPManagerService_ControlPlayback_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPManagerServiceServer).ControlPlayback(ctx, req.(*ControlPlaybackRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RTPManagerService_InjectAudio_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(RTPManagerServiceServer).InjectAudio(&grpc.GenericServerStream[InjectAudioRequest, InjectAudioResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_InjectAudioServer = grpc.ClientStreamingServer[InjectAudioRequest, InjectAudioResponse]
+
+func _RTPManagerService_CaptureAudio_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureAudioRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RTPManagerServiceServer).CaptureAudio(m, &grpc.GenericServerStream[CaptureAudioRequest, AudioFrame]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_CaptureAudioServer = grpc.ServerStreamingServer[AudioFrame]
+
 func _RTPManagerService_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(HealthRequest)
 	if err := dec(in); err != nil {
@@ -382,6 +615,53 @@ func _RTPManagerService_UnbridgeMedia_Handler(srv interface{}, ctx context.Conte
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RTPManagerService_ListSessions_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListSessionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPManagerServiceServer).ListSessions(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPManagerService_ListSessions_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPManagerServiceServer).ListSessions(ctx, req.(*ListSessionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RTPManagerService_SubscribeEvents_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(SubscribeEventsRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(RTPManagerServiceServer).SubscribeEvents(m, &grpc.GenericServerStream[SubscribeEventsRequest, SessionEvent]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type RTPManagerService_SubscribeEventsServer = grpc.ServerStreamingServer[SessionEvent]
+
+func _RTPManagerService_ListBridges_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListBridgesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RTPManagerServiceServer).ListBridges(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RTPManagerService_ListBridges_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RTPManagerServiceServer).ListBridges(ctx, req.(*ListBridgesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RTPManagerService_ServiceDesc is the grpc.ServiceDesc for RTPManagerService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -401,6 +681,10 @@ var RTPManagerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "StopAudio",
 			Handler:    _RTPManagerService_StopAudio_Handler,
 		},
+		{
+			MethodName: "ControlPlayback",
+			Handler:    _RTPManagerService_ControlPlayback_Handler,
+		},
 		{
 			MethodName: "Health",
 			Handler:    _RTPManagerService_Health_Handler,
@@ -417,6 +701,14 @@ var RTPManagerService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "UnbridgeMedia",
 			Handler:    _RTPManagerService_UnbridgeMedia_Handler,
 		},
+		{
+			MethodName: "ListSessions",
+			Handler:    _RTPManagerService_ListSessions_Handler,
+		},
+		{
+			MethodName: "ListBridges",
+			Handler:    _RTPManagerService_ListBridges_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -424,6 +716,26 @@ var RTPManagerService_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _RTPManagerService_PlayAudio_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "PlayTone",
+			Handler:       _RTPManagerService_PlayTone_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "InjectAudio",
+			Handler:       _RTPManagerService_InjectAudio_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "CaptureAudio",
+			Handler:       _RTPManagerService_CaptureAudio_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "SubscribeEvents",
+			Handler:       _RTPManagerService_SubscribeEvents_Handler,
+			ServerStreams: true,
+		},
 	},
 	Metadata: "api/proto/rtpmanager/v1/rtpmanager.proto",
 }
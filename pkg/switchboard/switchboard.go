@@ -0,0 +1,234 @@
+// Package switchboard embeds switchboard call control in other Go
+// programs: the dialog manager, the RTP manager pool, and the B2BUA call
+// service with its originator and bridges, wired as the signaling server
+// wires them.
+//
+// The embedding program owns the sipgo user agent and server. It answers
+// INVITEs it wants to handle with Engine.Answer, dials and bridges with
+// Engine.Calls, and lets Engine.Register handle the in-dialog requests:
+//
+//	engine, err := switchboard.New(switchboard.Config{
+//		UserAgent:     ua,
+//		AdvertiseAddr: "10.0.0.2",
+//		Port:          5060,
+//		RTPManagers:   map[string]string{"rtpmanager-0": "10.0.0.3:9090"},
+//	})
+//	engine.Register(srv)
+//	srv.OnInvite(func(req *sip.Request, tx sip.ServerTransaction) {
+//		legA, err := engine.Answer(context.Background(), req, tx)
+//		...
+//		engine.Calls().DialAndBridge(ctx, legA, "sip:1002@10.0.0.6", 30*time.Second)
+//	})
+//
+// The API of this package is stable; the internal packages behind it are not.
+package switchboard
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+	"github.com/sebas/switchboard/internal/signaling/routing"
+	"github.com/sebas/switchboard/internal/signaling/sipaddr"
+)
+
+// Config configures an Engine.
+type Config struct {
+	// UserAgent sends and receives SIP. Required. The caller starts its
+	// listeners and closes it after the engine.
+	UserAgent *sipgo.UserAgent
+
+	// AdvertiseAddr is the address put in Contact headers. Required.
+	AdvertiseAddr string
+
+	// Port is the SIP listening port. Required.
+	Port int
+
+	// RTPManagers maps RTP manager node IDs to gRPC addresses. Required.
+	RTPManagers map[string]string
+
+	// Resolver resolves dial targets (optional; SIP URIs only by default).
+	Resolver Resolver
+
+	// DialTimeout is the ring time when a dial has none.
+	// Default: 30 seconds.
+	DialTimeout time.Duration
+
+	// Ringback plays ringback to the A-leg while a dialed leg rings.
+	Ringback bool
+
+	// EarlyMedia relays a dialed leg's early media to the A-leg.
+	EarlyMedia bool
+}
+
+// Engine is an embedded call control instance. Safe for concurrent use.
+type Engine struct {
+	client  *sipgo.Client
+	dialogs *dialog.Manager
+	media   *mediaclient.Pool
+	calls   b2bua.CallService
+
+	bye    *routing.BYEHandler
+	ack    *routing.ACKHandler
+	cancel *routing.CANCELHandler
+}
+
+// New creates an engine and connects to the RTP managers.
+func New(cfg Config) (*Engine, error) {
+	if cfg.UserAgent == nil {
+		return nil, errors.New("switchboard: user agent required")
+	}
+	if cfg.AdvertiseAddr == "" || cfg.Port == 0 {
+		return nil, errors.New("switchboard: advertise address and port required")
+	}
+	if len(cfg.RTPManagers) == 0 {
+		return nil, errors.New("switchboard: at least one RTP manager required")
+	}
+	if cfg.Resolver == nil {
+		cfg.Resolver = NewDirectResolver()
+	}
+
+	client, err := sipgo.NewClient(cfg.UserAgent)
+	if err != nil {
+		return nil, fmt.Errorf("switchboard: create client: %w", err)
+	}
+
+	poolCfg := mediaclient.DefaultPoolConfig()
+	poolCfg.NodeAddresses = cfg.RTPManagers
+	media, err := mediaclient.NewPool(poolCfg)
+	if err != nil {
+		_ = client.Close()
+		return nil, fmt.Errorf("switchboard: connect RTP managers: %w", err)
+	}
+
+	contact := sip.Uri{
+		Scheme: "sip",
+		User:   "switchboard",
+		Host:   sipaddr.Host(cfg.AdvertiseAddr),
+		Port:   cfg.Port,
+	}
+	dialogs := dialog.NewManager(client, &sipgo.DialogUA{
+		Client:     client,
+		ContactHDR: sip.ContactHeader{Address: contact},
+	})
+
+	// Release the media session of every call that ends
+	dialogs.SetOnTerminated(func(d *dialog.Dialog) {
+		if sessionID := d.GetSessionID(); sessionID != "" {
+			if err := media.DestroySession(context.Background(), sessionID, mediaclient.TerminateReasonNormal); err != nil {
+				slog.Warn("[Switchboard] Failed to destroy session", "session_id", sessionID, "error", err)
+			}
+		}
+	})
+
+	calls := b2bua.NewCallService(b2bua.CallServiceConfig{
+		Client:             client,
+		Resolver:           cfg.Resolver,
+		DialogManager:      dialogs,
+		Transport:          media,
+		LocalContact:       contact.String(),
+		AdvertiseAddr:      cfg.AdvertiseAddr,
+		Port:               cfg.Port,
+		DefaultDialTimeout: cfg.DialTimeout,
+		Ringback:           cfg.Ringback,
+		EarlyMedia:         cfg.EarlyMedia,
+	})
+
+	return &Engine{
+		client:  client,
+		dialogs: dialogs,
+		media:   media,
+		calls:   calls,
+		bye:     routing.NewBYEHandler(dialogs, calls),
+		ack:     routing.NewACKHandler(dialogs),
+		cancel:  routing.NewCANCELHandler(dialogs),
+	}, nil
+}
+
+// Calls returns the call service for dialing and bridging.
+func (e *Engine) Calls() CallService {
+	return e.calls
+}
+
+// Dialogs returns the dialog manager.
+func (e *Engine) Dialogs() DialogStore {
+	return e.dialogs
+}
+
+// Media returns the RTP manager pool, for playing audio and tones to legs.
+func (e *Engine) Media() MediaTransport {
+	return e.media
+}
+
+// Register installs the engine's BYE, ACK and CANCEL handlers on srv.
+// INVITE handling stays with the caller (see Answer).
+func (e *Engine) Register(srv *sipgo.Server) {
+	srv.OnRequest(sip.BYE, e.bye.HandleBYE)
+	srv.OnRequest(sip.ACK, e.ack.HandleACK)
+	srv.OnRequest(sip.CANCEL, e.cancel.HandleCANCEL)
+}
+
+// Answer accepts an incoming INVITE: it creates the dialog and a media
+// session for the offered SDP, sends 200 OK and returns the answered
+// A-leg. Hanging up the leg sends BYE to the caller. On error a final
+// response has been sent when the call could be refused.
+func (e *Engine) Answer(ctx context.Context, req *sip.Request, tx sip.ServerTransaction) (Leg, error) {
+	dlg, err := e.dialogs.CreateFromInvite(req, tx)
+	if err != nil {
+		return nil, fmt.Errorf("switchboard: create dialog: %w", err)
+	}
+	if err := e.dialogs.SendTrying(dlg); err != nil {
+		_ = e.dialogs.Terminate(dlg.CallID, dialog.ReasonError)
+		return nil, fmt.Errorf("switchboard: send 100 Trying: %w", err)
+	}
+
+	addr, port, codecs, err := routing.ParseOffer(req.Body())
+	if err != nil {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusNotAcceptable, "Not Acceptable - invalid SDP", nil))
+		_ = e.dialogs.Terminate(dlg.CallID, dialog.ReasonError)
+		return nil, fmt.Errorf("switchboard: %w", err)
+	}
+
+	session, err := e.media.CreateSession(ctx, mediaclient.SessionInfo{
+		CallID:        dlg.CallID,
+		RemoteAddr:    addr,
+		RemotePort:    port,
+		OfferedCodecs: codecs,
+	})
+	if err != nil {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusNotAcceptable, "Not Acceptable - "+err.Error(), nil))
+		_ = e.dialogs.Terminate(dlg.CallID, dialog.ReasonError)
+		return nil, fmt.Errorf("switchboard: create media session: %w", err)
+	}
+	dlg.SetSessionID(session.SessionID)
+	dlg.SetMediaEndpoint(addr, port, session.SelectedCodec)
+
+	if err := e.dialogs.SendOK(dlg, session.SDPBody); err != nil {
+		_ = e.dialogs.Terminate(dlg.CallID, dialog.ReasonError)
+		return nil, fmt.Errorf("switchboard: send 200 OK: %w", err)
+	}
+
+	return e.calls.AdoptInboundLeg(dlg, session.SessionID,
+		b2bua.WithTeardownHandler(func(leg b2bua.Leg) {
+			// The caller hung up themselves when they sent BYE
+			if leg.GetTerminationCause() != b2bua.TerminationCauseRemoteBYE && !dlg.IsTerminated() {
+				_ = e.dialogs.Terminate(dlg.CallID, dialog.ReasonLocalBYE)
+			}
+		}),
+	)
+}
+
+// Close stops the engine and disconnects from the RTP managers. Calls in
+// progress are not hung up.
+func (e *Engine) Close() error {
+	e.dialogs.Close()
+	_ = e.client.Close()
+	return e.media.Close()
+}
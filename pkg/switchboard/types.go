@@ -0,0 +1,109 @@
+package switchboard
+
+import (
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// The call control API. These are the types the signaling server itself
+// uses; their methods are documented on the aliased types.
+type (
+	// CallService resolves targets, dials outbound legs and bridges legs.
+	CallService = b2bua.CallService
+
+	// Leg is one side of a call (inbound A-leg or outbound B-leg).
+	Leg = b2bua.Leg
+
+	// LegState is the lifecycle state of a leg.
+	LegState = b2bua.LegState
+
+	// LegOption configures a dialed leg (caller ID, headers, callbacks).
+	LegOption = b2bua.LegOption
+
+	// TerminationCause is why a leg ended.
+	TerminationCause = b2bua.TerminationCause
+
+	// Bridge connects the media of two answered legs.
+	Bridge = b2bua.Bridge
+
+	// BridgeInfo is the outcome of a bridged call, with timing.
+	BridgeInfo = b2bua.BridgeInfo
+
+	// ForkTarget is one destination of a parallel dial.
+	ForkTarget = b2bua.ForkTarget
+
+	// Resolver resolves dial targets to SIP URIs.
+	Resolver = b2bua.Resolver
+
+	// LookupResult is a resolved dial target.
+	LookupResult = b2bua.LookupResult
+
+	// DialError describes a failed dial, with the SIP response if any.
+	DialError = b2bua.DialError
+
+	// Dialog is a SIP dialog (one call's signaling state).
+	Dialog = dialog.Dialog
+
+	// DialogStore tracks inbound and outbound dialogs.
+	DialogStore = dialog.DialogStore
+
+	// MediaTransport controls media sessions on the RTP managers.
+	MediaTransport = mediaclient.Transport
+)
+
+// Leg states
+const (
+	LegStateCreated    = b2bua.LegStateCreated
+	LegStateRinging    = b2bua.LegStateRinging
+	LegStateEarlyMedia = b2bua.LegStateEarlyMedia
+	LegStateAnswered   = b2bua.LegStateAnswered
+	LegStateFailed     = b2bua.LegStateFailed
+	LegStateDestroyed  = b2bua.LegStateDestroyed
+)
+
+// Termination causes for Leg.Hangup
+const (
+	TerminationCauseNormal   = b2bua.TerminationCauseNormal
+	TerminationCauseCancel   = b2bua.TerminationCauseCancel
+	TerminationCauseRejected = b2bua.TerminationCauseRejected
+	TerminationCauseTimeout  = b2bua.TerminationCauseTimeout
+	TerminationCauseError    = b2bua.TerminationCauseError
+)
+
+// Sentinel errors for use with errors.Is.
+var (
+	ErrTargetNotFound = b2bua.ErrTargetNotFound
+	ErrNoContacts     = b2bua.ErrNoContacts
+	ErrLegNotAnswered = b2bua.ErrLegNotAnswered
+	ErrLegTerminated  = b2bua.ErrLegTerminated
+	ErrDialTimeout    = b2bua.ErrDialTimeout
+	ErrDialCanceled   = b2bua.ErrDialCanceled
+	ErrNotConfirmed   = b2bua.ErrNotConfirmed
+)
+
+// WithCallerID sets the caller ID (From user) of a dialed leg.
+func WithCallerID(callerID string) LegOption {
+	return b2bua.WithCallerID(callerID)
+}
+
+// WithCallerName sets the caller display name of a dialed leg.
+func WithCallerName(callerName string) LegOption {
+	return b2bua.WithCallerName(callerName)
+}
+
+// WithHeaders adds headers to a dialed leg's INVITE.
+func WithHeaders(headers map[string]string) LegOption {
+	return b2bua.WithHeaders(headers)
+}
+
+// WithProgressHandler is called as a dialed leg rings and answers.
+func WithProgressHandler(fn func(Leg, LegState)) LegOption {
+	return b2bua.WithProgressHandler(fn)
+}
+
+// NewDirectResolver returns a resolver for SIP URI targets
+// ("sip:1001@10.0.0.5:5060"), which need no lookup.
+func NewDirectResolver() Resolver {
+	return b2bua.NewDirectResolver()
+}
@@ -0,0 +1,319 @@
+package testkit
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+)
+
+// Answer scripts how an endpoint responds to the calls it receives.
+type Answer struct {
+	// Status is the final response; 0 answers with 200 OK.
+	Status sip.StatusCode
+
+	// Reason is the reason phrase of a rejection (e.g. "Busy Here").
+	Reason string
+
+	// Delay is how long the endpoint rings before the final response.
+	Delay time.Duration
+
+	// NoAnswer rings until the call is canceled.
+	NoAnswer bool
+}
+
+// Call is a call placed or received by an endpoint.
+type Call struct {
+	// ID is the Call-ID of the endpoint's leg.
+	ID string
+
+	// Request is the INVITE sent or received.
+	Request *sip.Request
+
+	mu     sync.Mutex
+	status sip.StatusCode
+	uac    *sipgo.DialogClientSession
+	uas    *sipgo.DialogServerSession
+	ended  chan struct{}
+	once   sync.Once
+}
+
+func newCall(req *sip.Request) *Call {
+	return &Call{ID: req.CallID().Value(), Request: req, ended: make(chan struct{})}
+}
+
+// Status returns the final response of the INVITE (0 while ringing).
+func (c *Call) Status() sip.StatusCode {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.status
+}
+
+func (c *Call) setStatus(status sip.StatusCode) {
+	c.mu.Lock()
+	c.status = status
+	c.mu.Unlock()
+}
+
+// Answered reports whether the call was answered.
+func (c *Call) Answered() bool {
+	status := c.Status()
+	return status >= 200 && status < 300
+}
+
+// Ended is closed when the call ends: rejected, canceled, or hung up by either side.
+func (c *Call) Ended() <-chan struct{} {
+	return c.ended
+}
+
+// WaitEnded waits for the call to end.
+func (c *Call) WaitEnded(ctx context.Context) error {
+	select {
+	case <-c.ended:
+		return nil
+	case <-ctx.Done():
+		return fmt.Errorf("testkit: call %s not ended: %w", c.ID, ctx.Err())
+	}
+}
+
+func (c *Call) end() {
+	c.once.Do(func() { close(c.ended) })
+}
+
+// Hangup sends BYE for an answered call.
+func (c *Call) Hangup(ctx context.Context) error {
+	c.mu.Lock()
+	uac, uas := c.uac, c.uas
+	c.mu.Unlock()
+
+	var err error
+	switch {
+	case uac != nil:
+		err = uac.Bye(ctx)
+	case uas != nil:
+		err = uas.Bye(ctx)
+	default:
+		return fmt.Errorf("testkit: call %s not answered", c.ID)
+	}
+	c.end()
+	return err
+}
+
+// Endpoint is a scripted SIP phone for a user, listening on a random loopback port.
+type Endpoint struct {
+	// User is the user part of the endpoint's address of record.
+	User string
+
+	h       *Harness
+	port    int
+	client  *sipgo.Client
+	dialogs *sipgo.DialogClient
+	server  *sipgo.DialogServer
+
+	mu       sync.Mutex
+	answer   Answer
+	calls    map[string]*Call
+	incoming chan *Call
+}
+
+// NewEndpoint starts an endpoint for user and registers it with the
+// harness. Endpoints answer calls with 200 OK until OnCall changes it.
+func (h *Harness) NewEndpoint(user string) *Endpoint {
+	h.tb.Helper()
+
+	conn, port := listen(h.tb)
+	ua, srv, client := newUA(h.tb, user)
+	contact := sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: user, Host: Host, Port: port}}
+
+	e := &Endpoint{
+		User:     user,
+		h:        h,
+		port:     port,
+		client:   client,
+		dialogs:  sipgo.NewDialogClient(client, contact),
+		server:   sipgo.NewDialogServer(client, contact),
+		calls:    make(map[string]*Call),
+		incoming: make(chan *Call, 16),
+	}
+	srv.OnInvite(e.handleInvite)
+	srv.OnAck(e.handleAck)
+	srv.OnBye(e.handleBye)
+	go func() { _ = srv.ServeUDP(conn) }()
+
+	h.tb.Cleanup(func() {
+		_ = conn.Close()
+		_ = ua.Close()
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := e.Register(ctx); err != nil {
+		h.tb.Fatalf("testkit: %v", err)
+	}
+	return e
+}
+
+// Contact returns the endpoint's contact URI.
+func (e *Endpoint) Contact() sip.Uri {
+	return sip.Uri{Scheme: "sip", User: e.User, Host: Host, Port: e.port}
+}
+
+// Register binds the endpoint's contact to its address of record.
+func (e *Endpoint) Register(ctx context.Context) error {
+	aor := sip.Uri{Scheme: "sip", User: e.User, Host: Host}
+	req := sip.NewRequest(sip.REGISTER, sip.Uri{Scheme: "sip", Host: Host, Port: e.h.port})
+	req.AppendHeader(&sip.ToHeader{Address: aor})
+	from := &sip.FromHeader{Address: aor, Params: sip.NewParams()}
+	from.Params.Add("tag", sip.GenerateTagN(16))
+	req.AppendHeader(from)
+	req.AppendHeader(&sip.ContactHeader{Address: e.Contact()})
+	req.AppendHeader(sip.NewHeader("Expires", "3600"))
+
+	res, err := e.client.Do(ctx, req)
+	if err != nil {
+		return fmt.Errorf("register %s: %w", e.User, err)
+	}
+	if !res.IsSuccess() {
+		return fmt.Errorf("register %s: %s", e.User, res.StartLine())
+	}
+	return nil
+}
+
+// OnCall scripts the response to calls received from now on.
+func (e *Endpoint) OnCall(answer Answer) {
+	e.mu.Lock()
+	e.answer = answer
+	e.mu.Unlock()
+}
+
+// Dial calls destination through the harness and waits for the final
+// response. A rejected call is returned with its status and already ended;
+// an error means no final response was received.
+func (e *Endpoint) Dial(ctx context.Context, destination string, headers ...sip.Header) (*Call, error) {
+	headers = append(headers, sip.NewHeader("Content-Type", "application/sdp"))
+	session, err := e.dialogs.Invite(ctx, e.h.URI(destination), AudioSDP(Host, e.port+1, "PCMU"), headers...)
+	if err != nil {
+		return nil, fmt.Errorf("testkit: invite %s: %w", destination, err)
+	}
+	call := newCall(session.InviteRequest)
+
+	err = session.WaitAnswer(ctx, sipgo.AnswerOptions{})
+	var rejected *sipgo.ErrDialogResponse
+	if errors.As(err, &rejected) {
+		call.setStatus(rejected.Res.StatusCode)
+		call.end()
+		return call, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("testkit: call %s: %w", destination, err)
+	}
+	if err := session.Ack(ctx); err != nil {
+		return nil, fmt.Errorf("testkit: ack %s: %w", destination, err)
+	}
+
+	call.mu.Lock()
+	call.status = session.InviteResponse.StatusCode
+	call.uac = session
+	call.mu.Unlock()
+	e.track(call)
+	return call, nil
+}
+
+// WaitCall waits for the next call the endpoint receives.
+func (e *Endpoint) WaitCall(ctx context.Context) (*Call, error) {
+	select {
+	case call := <-e.incoming:
+		return call, nil
+	case <-ctx.Done():
+		return nil, fmt.Errorf("testkit: %s received no call: %w", e.User, ctx.Err())
+	}
+}
+
+func (e *Endpoint) track(call *Call) {
+	e.mu.Lock()
+	e.calls[call.ID] = call
+	e.mu.Unlock()
+}
+
+func (e *Endpoint) lookup(callID string) *Call {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.calls[callID]
+}
+
+func (e *Endpoint) handleInvite(req *sip.Request, tx sip.ServerTransaction) {
+	session, err := e.server.ReadInvite(req, tx)
+	if err != nil {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusBadRequest, "Bad Request", nil))
+		return
+	}
+	call := newCall(req)
+	e.track(call)
+	select {
+	case e.incoming <- call:
+	default:
+	}
+
+	e.mu.Lock()
+	answer := e.answer
+	e.mu.Unlock()
+
+	_ = session.Respond(sip.StatusRinging, "Ringing", nil)
+	if answer.NoAnswer {
+		<-tx.Done()
+		call.setStatus(sip.StatusRequestTerminated)
+		call.end()
+		return
+	}
+	select {
+	case <-time.After(answer.Delay):
+	case <-tx.Done():
+		call.setStatus(sip.StatusRequestTerminated)
+		call.end()
+		return
+	}
+
+	if answer.Status != 0 && answer.Status != sip.StatusOK {
+		_ = session.Respond(answer.Status, answer.Reason, nil)
+		call.setStatus(answer.Status)
+		call.end()
+		return
+	}
+	if err := session.RespondSDP(AudioSDP(Host, e.port+1, "PCMU")); err != nil {
+		call.end()
+		return
+	}
+	call.mu.Lock()
+	call.status = sip.StatusOK
+	call.uas = session
+	call.mu.Unlock()
+}
+
+func (e *Endpoint) handleAck(req *sip.Request, tx sip.ServerTransaction) {
+	_ = e.server.ReadAck(req, tx)
+}
+
+func (e *Endpoint) handleBye(req *sip.Request, tx sip.ServerTransaction) {
+	call := e.lookup(req.CallID().Value())
+	if call == nil {
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusCallTransactionDoesNotExists, "Call/Transaction Does Not Exist", nil))
+		return
+	}
+	call.mu.Lock()
+	uac, uas := call.uac, call.uas
+	call.mu.Unlock()
+
+	switch {
+	case uac != nil:
+		_ = uac.ReadBye(req, tx)
+	case uas != nil:
+		_ = uas.ReadBye(req, tx)
+	default:
+		_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusOK, "OK", nil))
+	}
+	call.end()
+}
@@ -0,0 +1,226 @@
+// Package testkit runs dialplans against scripted SIP endpoints without
+// RTP managers, for integration tests of dialplans and call flows.
+//
+// A Harness is a signaling server on the loopback interface wired as the
+// real one (REGISTER, INVITE, BYE, ACK, CANCEL handlers, dialog manager,
+// dialplan executor, B2BUA) but with an in-memory media Transport that
+// records what is played and lets tests press DTMF digits. Endpoints are
+// SIP phones the test scripts: they register, place calls, and answer,
+// reject or ignore the calls they receive.
+//
+//	func TestSupportLine(t *testing.T) {
+//		h := testkit.New(t, testkit.Config{DialplanFile: "dialplan.json"})
+//		agent := h.NewEndpoint("1001")
+//		caller := h.NewEndpoint("2000")
+//
+//		call := caller.Dial(t.Context(), "500")
+//		h.Media.SendDTMF(call.ID, "1")
+//		incoming := agent.WaitCall(t.Context())
+//		if !h.Media.Bridged(call.ID, incoming.ID) { ... }
+//		call.Hangup(t.Context())
+//	}
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/emiago/sipgo"
+	"github.com/emiago/sipgo/sip"
+	"github.com/sebas/switchboard/internal/signaling/b2bua"
+	"github.com/sebas/switchboard/internal/signaling/dialog"
+	"github.com/sebas/switchboard/internal/signaling/dialplan"
+	"github.com/sebas/switchboard/internal/signaling/location"
+	"github.com/sebas/switchboard/internal/signaling/routing"
+)
+
+// Host is the loopback address harnesses and endpoints listen on.
+const Host = "127.0.0.1"
+
+// Config configures a Harness.
+type Config struct {
+	// Dialplan is the dialplan JSON. One of Dialplan and DialplanFile is required.
+	Dialplan string
+
+	// DialplanFile is a dialplan file.
+	DialplanFile string
+
+	// Actions registers additional dialplan actions (optional).
+	Actions func(*dialplan.ActionRegistry)
+
+	// Ringback plays ringback to callers while a dialed endpoint rings.
+	Ringback bool
+}
+
+// Harness is a signaling server for tests, listening on a random loopback port.
+type Harness struct {
+	// Media is the fake media transport of all calls.
+	Media *Transport
+
+	tb      testing.TB
+	port    int
+	ua      *sipgo.UserAgent
+	dialogs *dialog.Manager
+	store   location.LocationStore
+}
+
+// New starts a harness; it is stopped when the test ends.
+func New(tb testing.TB, cfg Config) *Harness {
+	tb.Helper()
+
+	path := cfg.DialplanFile
+	if path == "" {
+		if cfg.Dialplan == "" {
+			tb.Fatal("testkit: Dialplan or DialplanFile required")
+		}
+		path = filepath.Join(tb.TempDir(), "dialplan.json")
+		if err := os.WriteFile(path, []byte(cfg.Dialplan), 0o644); err != nil {
+			tb.Fatalf("testkit: write dialplan: %v", err)
+		}
+	}
+	dp, err := dialplan.New(path, slog.Default())
+	if err != nil {
+		tb.Fatalf("testkit: load dialplan: %v", err)
+	}
+
+	conn, port := listen(tb)
+	ua, srv, client := newUA(tb, "switchboard")
+
+	h := &Harness{
+		Media: NewTransport(),
+		tb:    tb,
+		port:  port,
+		ua:    ua,
+		store: location.NewStore(location.DefaultStoreConfig()),
+	}
+
+	contact := sip.ContactHeader{Address: sip.Uri{Scheme: "sip", User: "switchboard", Host: Host, Port: port}}
+	h.dialogs = dialog.NewManager(client, &sipgo.DialogUA{Client: client, ContactHDR: contact})
+	h.dialogs.SetOnTerminated(func(d *dialog.Dialog) {
+		if sessionID := d.GetSessionID(); sessionID != "" {
+			_ = h.Media.DestroySession(context.Background(), sessionID, 0)
+		}
+	})
+
+	calls := b2bua.NewCallService(b2bua.CallServiceConfig{
+		Client:        client,
+		Resolver:      b2bua.DefaultResolver(h.store, Host),
+		DialogManager: h.dialogs,
+		Transport:     h.Media,
+		LocalContact:  contact.Address.String(),
+		AdvertiseAddr: Host,
+		Port:          port,
+		Ringback:      cfg.Ringback,
+	})
+
+	actions := dialplan.DefaultRegistry()
+	if cfg.Actions != nil {
+		cfg.Actions(actions)
+	}
+	executor := dialplan.NewExecutor(dp, actions, slog.Default())
+
+	register := routing.NewRegisterHandler(h.store, Host)
+	invite := routing.NewInviteHandler(h.Media, Host, port, h.dialogs, noRecorder{}, executor, h.store, calls)
+	bye := routing.NewBYEHandler(h.dialogs, calls)
+	ack := routing.NewACKHandler(h.dialogs)
+	cancel := routing.NewCANCELHandler(h.dialogs)
+
+	srv.OnRequest(sip.REGISTER, func(req *sip.Request, tx sip.ServerTransaction) {
+		if err := register.HandleRegister(req, tx); err != nil {
+			_ = tx.Respond(sip.NewResponseFromRequest(req, sip.StatusInternalServerError, "Server Error", nil))
+		}
+	})
+	srv.OnRequest(sip.INVITE, invite.HandleINVITE)
+	srv.OnRequest(sip.BYE, bye.HandleBYE)
+	srv.OnRequest(sip.ACK, ack.HandleACK)
+	srv.OnRequest(sip.CANCEL, cancel.HandleCANCEL)
+
+	go func() { _ = srv.ServeUDP(conn) }()
+
+	tb.Cleanup(func() {
+		for _, d := range h.dialogs.List() {
+			if !d.IsTerminated() {
+				_ = h.dialogs.Terminate(d.CallID, dialog.ReasonLocalBYE)
+			}
+		}
+		h.dialogs.Close()
+		h.store.Close()
+		_ = conn.Close()
+		_ = ua.Close()
+	})
+	return h
+}
+
+// Addr returns the harness SIP address ("127.0.0.1:port").
+func (h *Harness) Addr() string {
+	return net.JoinHostPort(Host, fmt.Sprint(h.port))
+}
+
+// URI returns the SIP URI of a destination on the harness.
+func (h *Harness) URI(user string) sip.Uri {
+	return sip.Uri{Scheme: "sip", User: user, Host: Host, Port: h.port}
+}
+
+// ActiveCalls returns the number of dialogs not yet terminated.
+func (h *Harness) ActiveCalls() int {
+	n := 0
+	for _, d := range h.dialogs.List() {
+		if !d.IsTerminated() {
+			n++
+		}
+	}
+	return n
+}
+
+// WaitIdle waits until no dialogs are active, failing the test after timeout.
+func (h *Harness) WaitIdle(timeout time.Duration) {
+	h.tb.Helper()
+	deadline := time.Now().Add(timeout)
+	for h.ActiveCalls() > 0 {
+		if time.Now().After(deadline) {
+			h.tb.Fatalf("testkit: %d calls still active after %s", h.ActiveCalls(), timeout)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
+
+// noRecorder discards the API session records of the invite handler.
+type noRecorder struct{}
+
+func (noRecorder) RecordSession(callID, clientAddr string, clientPort int, serverAddr string, serverPort int) {
+}
+
+// listen opens a UDP socket on a random loopback port.
+func listen(tb testing.TB) (net.PacketConn, int) {
+	tb.Helper()
+	conn, err := net.ListenPacket("udp", net.JoinHostPort(Host, "0"))
+	if err != nil {
+		tb.Fatalf("testkit: listen: %v", err)
+	}
+	return conn, conn.LocalAddr().(*net.UDPAddr).Port
+}
+
+// newUA creates a user agent named user with a server and a client; the
+// client sends from the server socket once it is served.
+func newUA(tb testing.TB, user string) (*sipgo.UserAgent, *sipgo.Server, *sipgo.Client) {
+	tb.Helper()
+	ua, err := sipgo.NewUA(sipgo.WithUserAgent(user), sipgo.WithUserAgentHostname(Host))
+	if err != nil {
+		tb.Fatalf("testkit: create user agent: %v", err)
+	}
+	srv, err := sipgo.NewServer(ua)
+	if err != nil {
+		tb.Fatalf("testkit: create server: %v", err)
+	}
+	client, err := sipgo.NewClient(ua, sipgo.WithClientHostname(Host))
+	if err != nil {
+		tb.Fatalf("testkit: create client: %v", err)
+	}
+	return ua, srv, client
+}
@@ -0,0 +1,412 @@
+package testkit
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/sebas/switchboard/internal/signaling/mediaclient"
+)
+
+// Session is a snapshot of a media session of the fake transport.
+type Session struct {
+	ID         string
+	CallID     string
+	RemoteAddr string
+	RemotePort int
+	Codec      string
+	Played     []string // Files and tones played, in order ("tone:busy" for tones)
+	Destroyed  bool
+}
+
+// Transport is an in-memory mediaclient.Transport: it creates sessions
+// with a valid SDP answer, records what is played, completes playback
+// after PlayDuration, and reports digits queued with SendDTMF. Safe for
+// concurrent use.
+type Transport struct {
+	// PlayDuration is how long each file plays. Zero completes at once.
+	PlayDuration time.Duration
+
+	// AnsweredBy is reported at once to tones that detect answering
+	// machines. Default: mediaclient.AnsweredByHuman.
+	AnsweredBy mediaclient.AnsweredBy
+
+	mu       sync.Mutex
+	sessions map[string]*session // By session ID
+	order    []string            // Session IDs in creation order
+	bridges  map[string][2]string
+	nextPort atomic.Int32
+	nextID   atomic.Int64
+}
+
+type session struct {
+	Session
+	dtmf   chan string // Queued digits for reporting plays
+	player *player     // Active playback
+}
+
+type player struct {
+	stop chan struct{}
+	once sync.Once
+}
+
+func (p *player) halt() {
+	p.once.Do(func() { close(p.stop) })
+}
+
+// NewTransport creates an empty fake transport.
+func NewTransport() *Transport {
+	t := &Transport{
+		sessions: make(map[string]*session),
+		bridges:  make(map[string][2]string),
+	}
+	t.nextPort.Store(20000)
+	return t
+}
+
+// --- Inspection ---
+
+// Sessions returns the sessions created so far, oldest first.
+func (t *Transport) Sessions() []Session {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	sessions := make([]Session, 0, len(t.order))
+	for _, id := range t.order {
+		sessions = append(sessions, t.sessions[id].snapshot())
+	}
+	return sessions
+}
+
+// SessionByCallID returns the most recent session of a call.
+func (t *Transport) SessionByCallID(callID string) (Session, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, id := range slices.Backward(t.order) {
+		if s := t.sessions[id]; s.CallID == callID {
+			return s.snapshot(), true
+		}
+	}
+	return Session{}, false
+}
+
+// Played returns what was played to a call, in order.
+func (t *Transport) Played(callID string) []string {
+	s, _ := t.SessionByCallID(callID)
+	return s.Played
+}
+
+// Bridged reports whether the sessions of two calls are bridged.
+func (t *Transport) Bridged(callIDA, callIDB string) bool {
+	a, okA := t.SessionByCallID(callIDA)
+	b, okB := t.SessionByCallID(callIDB)
+	if !okA || !okB {
+		return false
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	for _, pair := range t.bridges {
+		if pair == [2]string{a.ID, b.ID} || pair == [2]string{b.ID, a.ID} {
+			return true
+		}
+	}
+	return false
+}
+
+// SendDTMF queues digits as if the remote party of the call pressed them.
+// They are reported to the next playback that asks for digits, as
+// CollectDigits and the menu actions do.
+func (t *Transport) SendDTMF(callID, digits string) error {
+	t.mu.Lock()
+	var target *session
+	for _, id := range slices.Backward(t.order) {
+		if s := t.sessions[id]; s.CallID == callID && !s.Destroyed {
+			target = s
+			break
+		}
+	}
+	t.mu.Unlock()
+	if target == nil {
+		return fmt.Errorf("testkit: no session for call %s", callID)
+	}
+	for _, d := range digits {
+		target.dtmf <- string(d)
+	}
+	return nil
+}
+
+func (s *session) snapshot() Session {
+	snap := s.Session
+	snap.Played = slices.Clone(s.Played)
+	return snap
+}
+
+// --- mediaclient.Transport ---
+
+// CreateSession implements mediaclient.Transport.
+func (t *Transport) CreateSession(ctx context.Context, info mediaclient.SessionInfo) (*mediaclient.SessionResult, error) {
+	codec := "0"
+	if len(info.OfferedCodecs) > 0 {
+		codec = info.OfferedCodecs[0]
+	}
+	return t.create(info.CallID, info.RemoteAddr, info.RemotePort, codec), nil
+}
+
+// CreateSessionPendingRemote implements mediaclient.Transport.
+func (t *Transport) CreateSessionPendingRemote(ctx context.Context, callID, peerAddr string, codecs []string) (*mediaclient.SessionResult, error) {
+	codec := "0"
+	if len(codecs) > 0 {
+		codec = codecs[0]
+	}
+	return t.create(callID, "", 0, codec), nil
+}
+
+// CreateSessionPendingRemoteOnNode implements mediaclient.Transport.
+func (t *Transport) CreateSessionPendingRemoteOnNode(ctx context.Context, peerSessionID, callID, peerAddr string, codecs []string) (*mediaclient.SessionResult, error) {
+	return t.CreateSessionPendingRemote(ctx, callID, peerAddr, codecs)
+}
+
+func (t *Transport) create(callID, remoteAddr string, remotePort int, codec string) *mediaclient.SessionResult {
+	id := fmt.Sprintf("session-%d", t.nextID.Add(1))
+	port := int(t.nextPort.Add(2))
+
+	t.mu.Lock()
+	t.sessions[id] = &session{
+		Session: Session{
+			ID:         id,
+			CallID:     callID,
+			RemoteAddr: remoteAddr,
+			RemotePort: remotePort,
+			Codec:      codec,
+		},
+		dtmf: make(chan string, 64),
+	}
+	t.order = append(t.order, id)
+	t.mu.Unlock()
+
+	return &mediaclient.SessionResult{
+		SessionID:     id,
+		LocalAddr:     "127.0.0.1",
+		LocalPort:     port,
+		SDPBody:       AudioSDP("127.0.0.1", port, codec),
+		SelectedCodec: codec,
+	}
+}
+
+// UpdateSessionRemote implements mediaclient.Transport.
+func (t *Transport) UpdateSessionRemote(ctx context.Context, sessionID, remoteAddr string, remotePort int) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("testkit: unknown session %s", sessionID)
+	}
+	s.RemoteAddr = remoteAddr
+	s.RemotePort = remotePort
+	return nil
+}
+
+// DestroySession implements mediaclient.Transport.
+func (t *Transport) DestroySession(ctx context.Context, sessionID string, reason mediaclient.TerminateReason) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("testkit: unknown session %s", sessionID)
+	}
+	s.Destroyed = true
+	if s.player != nil {
+		s.player.halt()
+	}
+	return nil
+}
+
+// PlayAudio implements mediaclient.Transport. Looped playback lasts
+// until stopped.
+func (t *Transport) PlayAudio(ctx context.Context, req mediaclient.PlayRequest) (<-chan mediaclient.PlayStatus, error) {
+	names := append([]string{req.AudioFile}, req.Playlist...)
+	duration := t.PlayDuration * time.Duration(len(names))
+	ch, err := t.play(ctx, req.SessionID, names, duration, req.Loop, req.ReportDTMF, "")
+	if err == nil && req.OnComplete != nil {
+		// OnComplete runs on completion only, not on stop
+		inner := ch
+		out := make(chan mediaclient.PlayStatus, cap(inner))
+		go func() {
+			defer close(out)
+			for st := range inner {
+				out <- st
+				if st.State == mediaclient.PlayStateCompleted {
+					req.OnComplete(req.SessionID)
+				}
+			}
+		}()
+		ch = out
+	}
+	return ch, err
+}
+
+// PlayTone implements mediaclient.Transport. Tones without a duration
+// last until stopped.
+func (t *Transport) PlayTone(ctx context.Context, req mediaclient.ToneRequest) (<-chan mediaclient.PlayStatus, error) {
+	var answeredBy mediaclient.AnsweredBy
+	if req.DetectMachine != nil {
+		answeredBy = t.AnsweredBy
+		if answeredBy == "" {
+			answeredBy = mediaclient.AnsweredByHuman
+		}
+	}
+	return t.play(ctx, req.SessionID, []string{"tone:" + req.Tone}, req.Duration, req.Duration == 0, req.ReportDTMF, answeredBy)
+}
+
+// play records names and runs a playback of the session, reporting
+// answeredBy first if set.
+func (t *Transport) play(ctx context.Context, sessionID string, names []string, duration time.Duration, untilStopped, reportDTMF bool, answeredBy mediaclient.AnsweredBy) (<-chan mediaclient.PlayStatus, error) {
+	t.mu.Lock()
+	s, ok := t.sessions[sessionID]
+	if !ok || s.Destroyed {
+		t.mu.Unlock()
+		return nil, fmt.Errorf("testkit: unknown session %s", sessionID)
+	}
+	s.Played = append(s.Played, names...)
+	if s.player != nil {
+		s.player.halt()
+	}
+	p := &player{stop: make(chan struct{})}
+	s.player = p
+	t.mu.Unlock()
+
+	ch := make(chan mediaclient.PlayStatus, 4)
+	go func() {
+		defer close(ch)
+		send := func(st mediaclient.PlayStatus) {
+			st.SessionID = sessionID
+			select {
+			case ch <- st:
+			case <-ctx.Done():
+			}
+		}
+		send(mediaclient.PlayStatus{State: mediaclient.PlayStateStarted})
+		if answeredBy != "" {
+			send(mediaclient.PlayStatus{State: mediaclient.PlayStateMachine, AnsweredBy: answeredBy, MachineReason: "testkit"})
+		}
+
+		var done <-chan time.Time
+		if !untilStopped {
+			done = time.After(duration)
+		}
+		var dtmf <-chan string
+		if reportDTMF {
+			dtmf = s.dtmf
+		}
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-p.stop:
+				send(mediaclient.PlayStatus{State: mediaclient.PlayStateStopped})
+				return
+			case <-done:
+				send(mediaclient.PlayStatus{State: mediaclient.PlayStateCompleted})
+				return
+			case digit := <-dtmf:
+				send(mediaclient.PlayStatus{State: mediaclient.PlayStateDTMF, Digit: digit})
+			}
+		}
+	}()
+	return ch, nil
+}
+
+// StopAudio implements mediaclient.Transport.
+func (t *Transport) StopAudio(ctx context.Context, sessionID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s, ok := t.sessions[sessionID]
+	if !ok {
+		return fmt.Errorf("testkit: unknown session %s", sessionID)
+	}
+	if s.player != nil {
+		s.player.halt()
+		s.player = nil
+	}
+	return nil
+}
+
+// ControlPlayback implements mediaclient.Transport; positions are always zero.
+func (t *Transport) ControlPlayback(ctx context.Context, sessionID string, control mediaclient.PlaybackControl, offset time.Duration) (*mediaclient.PlaybackPosition, error) {
+	return &mediaclient.PlaybackPosition{}, nil
+}
+
+// InjectAudio implements mediaclient.Transport; injected audio is discarded.
+func (t *Transport) InjectAudio(ctx context.Context, req mediaclient.InjectRequest) (mediaclient.AudioInjector, error) {
+	t.mu.Lock()
+	if s, ok := t.sessions[req.SessionID]; ok {
+		s.Played = append(s.Played, "inject")
+	}
+	t.mu.Unlock()
+	return &discardInjector{sessionID: req.SessionID}, nil
+}
+
+type discardInjector struct {
+	sessionID string
+	frames    int
+}
+
+func (d *discardInjector) Write(data []byte) error {
+	d.frames++
+	return nil
+}
+
+func (d *discardInjector) Close() (*mediaclient.InjectResult, error) {
+	return &mediaclient.InjectResult{SessionID: d.sessionID, FramesSent: d.frames}, nil
+}
+
+// CaptureAudio implements mediaclient.Transport; no frames are captured.
+func (t *Transport) CaptureAudio(ctx context.Context, req mediaclient.CaptureRequest) (<-chan mediaclient.AudioFrame, error) {
+	ch := make(chan mediaclient.AudioFrame)
+	go func() {
+		<-ctx.Done()
+		close(ch)
+	}()
+	return ch, nil
+}
+
+// BridgeMedia implements mediaclient.Transport.
+func (t *Transport) BridgeMedia(ctx context.Context, sessionAID, sessionBID string) (string, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	id := fmt.Sprintf("bridge-%d", t.nextID.Add(1))
+	t.bridges[id] = [2]string{sessionAID, sessionBID}
+	return id, nil
+}
+
+// UnbridgeMedia implements mediaclient.Transport.
+func (t *Transport) UnbridgeMedia(ctx context.Context, bridgeID string) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.bridges, bridgeID)
+	return nil
+}
+
+// Ready implements mediaclient.Transport.
+func (t *Transport) Ready() bool {
+	return true
+}
+
+// Close implements mediaclient.Transport.
+func (t *Transport) Close() error {
+	return nil
+}
+
+// AudioSDP returns an SDP offer or answer for one audio stream.
+func AudioSDP(addr string, port int, codec string) []byte {
+	return fmt.Appendf(nil, "v=0\r\n"+
+		"o=- 1 1 IN IP4 %s\r\n"+
+		"s=-\r\n"+
+		"c=IN IP4 %s\r\n"+
+		"t=0 0\r\n"+
+		"m=audio %d RTP/AVP %s\r\n", addr, addr, port, codec)
+}
+
+var _ mediaclient.Transport = (*Transport)(nil)